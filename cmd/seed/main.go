@@ -393,8 +393,9 @@ func (g *DataGenerator) finalizeData(ctx context.Context) error {
 func (g *DataGenerator) cleanExistingData(ctx context.Context) error {
 	collections := []string{
 		"users", "posts", "comments", "likes", "follows", "stories", "story_views", "story_highlights",
-		"groups", "group_members", "group_invites", "conversations", "messages",
-		"notifications", "reports", "media", "hashtags", "mentions", "blocks",
+		"groups", "group_members", "group_invites", "group_invite_links", "conversations", "messages",
+		"notifications", "reports", "media", "hashtags", "mentions", "blocks", "account_delegations", "post_edits", "user_warnings",
+		"status_samples", "incidents",
 	}
 
 	for _, collection := range collections {
@@ -460,6 +461,7 @@ func (g *DataGenerator) generateAndFetchHashtags(ctx context.Context, genConfig
 	}
 
 	hashtags := make([]interface{}, 0, len(popularTags))
+	categorizer := models.NewHashtagCategorizer(config.GetConfig().Hashtags.CategoryKeywords)
 
 	for _, tag := range popularTags {
 		hashtag := models.Hashtag{
@@ -469,7 +471,7 @@ func (g *DataGenerator) generateAndFetchHashtags(ctx context.Context, genConfig
 			},
 			Tag:          tag,
 			DisplayTag:   tag,
-			Category:     randomHashtagCategory(),
+			Category:     categorizer.Categorize(tag),
 			Language:     "en",
 			PostsCount:   int64(rand.Intn(1000) + 10),
 			StoriesCount: int64(rand.Intn(500) + 5),
@@ -1812,32 +1814,39 @@ func (g *DataGenerator) generateReports(ctx context.Context, genConfig Generatio
 }
 
 func (g *DataGenerator) generateUserBlocks(ctx context.Context, genConfig GenerationConfig) error {
-	// Update user documents with blocked users
+	collection := g.db.Collection("blocked_users")
+
+	// Blocks are stored one document per pair in the blocked_users collection
+	var blocks []interface{}
 	for _, user := range g.users {
 		if rand.Float64() < 0.1 { // 10% of users have blocked someone
 			blockCount := rand.Intn(3) + 1 // 1-3 blocked users
-			blockedUsers := make([]primitive.ObjectID, 0, blockCount)
+			seen := make(map[primitive.ObjectID]bool)
 
-			for i := 0; i < blockCount && len(blockedUsers) < len(g.users)-1; i++ {
+			for i := 0; i < blockCount && len(seen) < len(g.users)-1; i++ {
 				blockedUser := g.users[rand.Intn(len(g.users))]
-				if blockedUser.ID != user.ID {
-					blockedUsers = append(blockedUsers, blockedUser.ID)
+				if blockedUser.ID == user.ID || seen[blockedUser.ID] {
+					continue
 				}
-			}
+				seen[blockedUser.ID] = true
 
-			if len(blockedUsers) > 0 {
-				_, err := g.db.Collection("users").UpdateOne(
-					ctx,
-					bson.M{"_id": user.ID},
-					bson.M{"$set": bson.M{"blocked_users": blockedUsers}},
-				)
-				if err != nil {
-					log.Printf("Failed to update blocked users for %s: %v", user.Username, err)
+				block := models.BlockedUser{
+					BlockerID: user.ID,
+					BlockedID: blockedUser.ID,
+					IsActive:  true,
 				}
+				block.BeforeCreate()
+				blocks = append(blocks, block)
 			}
 		}
 	}
 
+	if len(blocks) > 0 {
+		if _, err := collection.InsertMany(ctx, blocks); err != nil {
+			return fmt.Errorf("failed to insert user blocks: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -2295,14 +2304,6 @@ func randomSource() string {
 	return sources[0]
 }
 
-func randomHashtagCategory() string {
-	categories := []string{
-		"general", "technology", "entertainment", "sports", "business",
-		"lifestyle", "travel", "food", "fashion", "music", "art",
-	}
-	return categories[rand.Intn(len(categories))]
-}
-
 func randomMediaCategory() string {
 	categories := []string{
 		"profile", "post", "story", "message", "group", "event", "general",
@@ -0,0 +1,462 @@
+// cmd/server/admin_commands.go
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"social-media-api/internal/config"
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// adminCommand is an operational subcommand for one-off fixes that would
+// otherwise mean editing the database by hand. Commands go through the same
+// services as the admin HTTP handlers and are recorded in the audit log.
+type adminCommand struct {
+	Usage       string
+	Description string
+	Run         func(cli *adminCLI, args []string) error
+}
+
+// adminCommands returns the operational subcommands keyed by name
+func adminCommands() map[string]adminCommand {
+	return map[string]adminCommand{
+		"promote-user": {
+			Usage:       "promote-user --email <email> [--role admin] [--yes]",
+			Description: "Grant a user the moderator, admin or super_admin role",
+			Run:         runPromoteUser,
+		},
+		"demote-user": {
+			Usage:       "demote-user --email <email> [--yes]",
+			Description: "Reset a user's role to user",
+			Run:         runDemoteUser,
+		},
+		"reset-2fa": {
+			Usage:       "reset-2fa --email <email> [--yes]",
+			Description: "Disable two-factor authentication and discard backup codes",
+			Run:         runResetTwoFactor,
+		},
+		"verify-email": {
+			Usage:       "verify-email --email <email> [--yes]",
+			Description: "Mark a user's email address as verified",
+			Run:         runVerifyEmail,
+		},
+		"purge-feed-cache": {
+			Usage:       "purge-feed-cache [--user <id>] [--yes]",
+			Description: "Delete cached feeds for one user, or for everyone",
+			Run:         runPurgeFeedCache,
+		},
+		"recount-stats": {
+			Usage:       "recount-stats --user <id> [--yes]",
+			Description: "Recompute a user's follower, following and post counts",
+			Run:         runRecountStats,
+		},
+		"unlock-account": {
+			Usage:       "unlock-account --email <email> [--yes]",
+			Description: "Reactivate a suspended or deactivated account",
+			Run:         runUnlockAccount,
+		},
+	}
+}
+
+// adminCLI carries what every admin command needs
+type adminCLI struct {
+	ctx          context.Context
+	adminService *services.AdminService
+	feedService  *services.FeedService
+	in           *bufio.Reader
+	out          io.Writer
+	actor        string // "cli:<hostname>", recorded on audit log entries
+	assumeYes    bool
+}
+
+// runAdminCommand initializes configuration and the database, runs the named
+// command and returns the process exit code
+func runAdminCommand(name string, args []string) int {
+	command, ok := adminCommands()[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", name)
+		printAdminUsage(os.Stderr)
+		return 2
+	}
+
+	if err := godotenv.Load(); err != nil {
+		fmt.Fprintln(os.Stderr, "No .env file found, using environment variables")
+	}
+	cfg := config.MustLoad()
+
+	config.InitDB()
+	defer config.Disconnect()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	cli := &adminCLI{
+		ctx: ctx,
+		adminService: services.NewAdminService(config.DB, services.AdminQueryPolicy{
+			MaxTime:      cfg.AdminQueries.MaxTime,
+			MaxResults:   cfg.AdminQueries.MaxResults,
+			MaxRangeDays: cfg.AdminQueries.AnalyticsMaxRange,
+		}),
+		feedService: services.NewFeedService(nil, services.FeedInjectionPolicy{}, cfg.Feed.DefaultAlgorithm, services.NetworkTrendingPolicy{}),
+		in:          bufio.NewReader(os.Stdin),
+		out:         os.Stdout,
+		actor:       cliActor(),
+	}
+
+	if err := command.Run(cli, args); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s failed: %v\n", name, err)
+		return 1
+	}
+	return 0
+}
+
+// printAdminUsage lists the available admin commands
+func printAdminUsage(w io.Writer) {
+	commands := adminCommands()
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "Admin commands:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-60s %s\n", commands[name].Usage, commands[name].Description)
+	}
+}
+
+func cliActor() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "unknown"
+	}
+	return "cli:" + hostname
+}
+
+// newCommandFlags creates a flag set with the --yes flag every command accepts
+func (cli *adminCLI) newCommandFlags(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	flags.BoolVar(&cli.assumeYes, "yes", false, "skip the confirmation prompt")
+	return flags
+}
+
+// confirm asks the operator to approve an action unless --yes was passed
+func (cli *adminCLI) confirm(action string) error {
+	if cli.assumeYes {
+		return nil
+	}
+
+	fmt.Fprintf(cli.out, "%s. Continue? [y/N]: ", action)
+	answer, _ := cli.in.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return errors.New("aborted by operator")
+	}
+}
+
+// audit records a completed command in the audit log
+func (cli *adminCLI) audit(action, targetType string, targetID primitive.ObjectID, oldValues, newValues map[string]interface{}) error {
+	entry := &models.AuditLog{
+		Action:     action,
+		ActorType:  "cli",
+		ActorName:  cli.actor,
+		TargetType: targetType,
+		TargetID:   targetID,
+		OldValues:  oldValues,
+		NewValues:  newValues,
+		UserAgent:  "social-media-api cli",
+	}
+
+	if err := cli.adminService.CreateAuditLog(cli.ctx, entry); err != nil {
+		return fmt.Errorf("change applied but audit log entry failed: %w", err)
+	}
+	return nil
+}
+
+// findUserByEmail resolves the --email flag to a user
+func (cli *adminCLI) findUserByEmail(email string) (*models.User, error) {
+	if email == "" {
+		return nil, errors.New("--email is required")
+	}
+
+	user, err := cli.adminService.GetUserByEmail(cli.ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", email, err)
+	}
+	return user, nil
+}
+
+func runPromoteUser(cli *adminCLI, args []string) error {
+	flags := cli.newCommandFlags("promote-user")
+	email := flags.String("email", "", "email of the user to promote")
+	role := flags.String("role", string(models.RoleAdmin), "role to grant (moderator, admin, super_admin)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	newRole := models.UserRole(*role)
+	switch newRole {
+	case models.RoleModerator, models.RoleAdmin, models.RoleSuperAdmin:
+	case models.RoleUser:
+		return errors.New("use demote-user to remove elevated roles")
+	default:
+		return fmt.Errorf("invalid role %q: must be moderator, admin or super_admin", *role)
+	}
+
+	user, err := cli.findUserByEmail(*email)
+	if err != nil {
+		return err
+	}
+	if user.Role == newRole {
+		return fmt.Errorf("%s already has the %s role", user.Email, newRole)
+	}
+
+	if err := cli.confirm(fmt.Sprintf("Change role of %s (%s) from %s to %s", user.Username, user.Email, user.Role, newRole)); err != nil {
+		return err
+	}
+
+	if err := cli.adminService.UpdateUserRole(cli.ctx, user.ID.Hex(), newRole); err != nil {
+		return err
+	}
+
+	if err := cli.audit("cli.promote_user", "user", user.ID,
+		map[string]interface{}{"role": user.Role},
+		map[string]interface{}{"role": newRole}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cli.out, "✅ %s is now %s\n", user.Email, newRole)
+	return nil
+}
+
+func runDemoteUser(cli *adminCLI, args []string) error {
+	flags := cli.newCommandFlags("demote-user")
+	email := flags.String("email", "", "email of the user to demote")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	user, err := cli.findUserByEmail(*email)
+	if err != nil {
+		return err
+	}
+	if user.Role == models.RoleUser {
+		return fmt.Errorf("%s already has the user role", user.Email)
+	}
+
+	if err := cli.confirm(fmt.Sprintf("Change role of %s (%s) from %s to %s", user.Username, user.Email, user.Role, models.RoleUser)); err != nil {
+		return err
+	}
+
+	if err := cli.adminService.UpdateUserRole(cli.ctx, user.ID.Hex(), models.RoleUser); err != nil {
+		return err
+	}
+
+	if err := cli.audit("cli.demote_user", "user", user.ID,
+		map[string]interface{}{"role": user.Role},
+		map[string]interface{}{"role": models.RoleUser}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cli.out, "✅ %s is now %s\n", user.Email, models.RoleUser)
+	return nil
+}
+
+func runResetTwoFactor(cli *adminCLI, args []string) error {
+	flags := cli.newCommandFlags("reset-2fa")
+	email := flags.String("email", "", "email of the user")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	user, err := cli.findUserByEmail(*email)
+	if err != nil {
+		return err
+	}
+	if !user.TwoFactorEnabled && user.TwoFactorSecret == "" && len(user.BackupCodes) == 0 {
+		return fmt.Errorf("%s does not have two-factor authentication set up", user.Email)
+	}
+
+	if err := cli.confirm(fmt.Sprintf("Disable two-factor authentication for %s (%s)", user.Username, user.Email)); err != nil {
+		return err
+	}
+
+	if err := cli.adminService.ResetTwoFactor(cli.ctx, user.ID.Hex()); err != nil {
+		return err
+	}
+
+	if err := cli.audit("cli.reset_2fa", "user", user.ID,
+		map[string]interface{}{"two_factor_enabled": user.TwoFactorEnabled},
+		map[string]interface{}{"two_factor_enabled": false}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cli.out, "✅ Two-factor authentication reset for %s\n", user.Email)
+	return nil
+}
+
+func runVerifyEmail(cli *adminCLI, args []string) error {
+	flags := cli.newCommandFlags("verify-email")
+	email := flags.String("email", "", "email of the user")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	user, err := cli.findUserByEmail(*email)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return fmt.Errorf("%s is already verified", user.Email)
+	}
+
+	if err := cli.confirm(fmt.Sprintf("Mark %s (%s) as email-verified", user.Email, user.Username)); err != nil {
+		return err
+	}
+
+	if err := cli.adminService.VerifyUserEmail(cli.ctx, user.ID.Hex()); err != nil {
+		return err
+	}
+
+	if err := cli.audit("cli.verify_email", "user", user.ID,
+		map[string]interface{}{"email_verified": false},
+		map[string]interface{}{"email_verified": true}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cli.out, "✅ %s is now verified\n", user.Email)
+	return nil
+}
+
+func runPurgeFeedCache(cli *adminCLI, args []string) error {
+	flags := cli.newCommandFlags("purge-feed-cache")
+	userIDStr := flags.String("user", "", "only purge this user's cached feeds")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var userID *primitive.ObjectID
+	targetID := primitive.NilObjectID
+	scope := "all users"
+	if *userIDStr != "" {
+		id, err := primitive.ObjectIDFromHex(*userIDStr)
+		if err != nil {
+			return fmt.Errorf("invalid user ID: %s", *userIDStr)
+		}
+		userID = &id
+		targetID = id
+		scope = "user " + id.Hex()
+	}
+
+	if err := cli.confirm("Purge cached feeds for " + scope); err != nil {
+		return err
+	}
+
+	deleted, err := cli.feedService.ClearFeedCache(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := cli.audit("cli.purge_feed_cache", "feed_cache", targetID, nil,
+		map[string]interface{}{"scope": scope, "deleted": deleted}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cli.out, "✅ Deleted %d cached feeds for %s\n", deleted, scope)
+	return nil
+}
+
+func runRecountStats(cli *adminCLI, args []string) error {
+	flags := cli.newCommandFlags("recount-stats")
+	userIDStr := flags.String("user", "", "ID of the user to recount")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *userIDStr == "" {
+		return errors.New("--user is required")
+	}
+	user, err := cli.adminService.GetUserByID(cli.ctx, *userIDStr)
+	if err != nil {
+		return fmt.Errorf("user %s not found: %w", *userIDStr, err)
+	}
+
+	if err := cli.confirm(fmt.Sprintf("Recount follower, following and post counts for %s", user.Username)); err != nil {
+		return err
+	}
+
+	counts, err := cli.adminService.RecountUserStats(cli.ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	oldValues := map[string]interface{}{
+		"followers_count": user.FollowersCount,
+		"following_count": user.FollowingCount,
+		"posts_count":     user.PostsCount,
+	}
+	newValues := map[string]interface{}{}
+	for field, value := range counts {
+		newValues[field] = value
+	}
+
+	userID, _ := primitive.ObjectIDFromHex(user.ID)
+	if err := cli.audit("cli.recount_stats", "user", userID, oldValues, newValues); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cli.out, "✅ Recounted stats for %s\n", user.Username)
+	for _, field := range []string{"followers_count", "following_count", "posts_count"} {
+		fmt.Fprintf(cli.out, "   %-16s %d -> %d\n", field, oldValues[field], counts[field])
+	}
+	return nil
+}
+
+func runUnlockAccount(cli *adminCLI, args []string) error {
+	flags := cli.newCommandFlags("unlock-account")
+	email := flags.String("email", "", "email of the user to unlock")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	user, err := cli.findUserByEmail(*email)
+	if err != nil {
+		return err
+	}
+	if user.IsActive && !user.IsSuspended {
+		return fmt.Errorf("%s is not locked", user.Email)
+	}
+
+	if err := cli.confirm(fmt.Sprintf("Reactivate %s (%s)", user.Username, user.Email)); err != nil {
+		return err
+	}
+
+	if err := cli.adminService.UpdateUserStatus(cli.ctx, user.ID.Hex(), true, false); err != nil {
+		return err
+	}
+
+	if err := cli.audit("cli.unlock_account", "user", user.ID,
+		map[string]interface{}{"is_active": user.IsActive, "is_suspended": user.IsSuspended},
+		map[string]interface{}{"is_active": true, "is_suspended": false}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cli.out, "✅ %s is unlocked\n", user.Email)
+	return nil
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// newTestAdminCLI wires the admin commands to the harness database the way
+// runAdminCommand wires them to the configured one. input answers the
+// confirmation prompt.
+func newTestAdminCLI(h *testutil.Harness, input string) (*adminCLI, *bytes.Buffer) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	h.T.Cleanup(cancel)
+
+	out := &bytes.Buffer{}
+	return &adminCLI{
+		ctx:          ctx,
+		adminService: services.NewAdminService(h.DB, services.AdminQueryPolicy{MaxTime: time.Minute, MaxResults: 1000, MaxRangeDays: 365}),
+		feedService:  services.NewFeedService(nil, services.FeedInjectionPolicy{}, "chronological", services.NetworkTrendingPolicy{}),
+		in:           bufio.NewReader(strings.NewReader(input)),
+		out:          out,
+		actor:        "cli:test-host",
+	}, out
+}
+
+// runCommand runs an admin command by name
+func runCommand(cli *adminCLI, name string, args ...string) error {
+	return adminCommands()[name].Run(cli, args)
+}
+
+func TestAdminCommandsSmoke(t *testing.T) {
+	h := testutil.NewHarness(t)
+
+	promoted := h.CreateUser()
+	demoted := h.CreateUser(testutil.WithRole(models.RoleModerator))
+	twoFactor := h.CreateUser()
+	unverified := h.CreateUser()
+	locked := h.CreateUser()
+	miscounted := h.CreateUser()
+	cached := h.CreateUser()
+	other := h.CreateUser()
+
+	users := h.DB.Collection("users")
+	setFields := func(user *models.User, fields bson.M) {
+		t.Helper()
+		if _, err := users.UpdateOne(h.Context(), bson.M{"_id": user.ID}, bson.M{"$set": fields}); err != nil {
+			t.Fatalf("failed to update %s: %v", user.Username, err)
+		}
+	}
+	setFields(twoFactor, bson.M{"two_factor_enabled": true, "two_factor_secret": "JBSWY3DPEHPK3PXP", "backup_codes": []string{"a", "b"}})
+	setFields(unverified, bson.M{"email_verified": false})
+	setFields(locked, bson.M{"is_active": false, "is_suspended": true})
+	setFields(miscounted, bson.M{"followers_count": 40, "following_count": 7, "posts_count": 12})
+
+	h.CreatePost(miscounted)
+	h.CreateFollow(other, miscounted, models.FollowStatusAccepted)
+	for _, user := range []*models.User{cached, cached, other} {
+		if _, err := h.DB.Collection("feed_cache").InsertOne(h.Context(), bson.M{"user_id": user.ID, "created_at": time.Now()}); err != nil {
+			t.Fatalf("failed to seed feed cache: %v", err)
+		}
+	}
+
+	tests := []struct {
+		command string
+		args    []string
+		check   func(t *testing.T)
+	}{
+		{"promote-user", []string{"--email", promoted.Email, "--role", "admin"}, func(t *testing.T) {
+			if role := h.ReloadUser(promoted.ID).Role; role != models.RoleAdmin {
+				t.Errorf("role = %q, want %q", role, models.RoleAdmin)
+			}
+		}},
+		{"demote-user", []string{"--email", demoted.Email}, func(t *testing.T) {
+			if role := h.ReloadUser(demoted.ID).Role; role != models.RoleUser {
+				t.Errorf("role = %q, want %q", role, models.RoleUser)
+			}
+		}},
+		{"reset-2fa", []string{"--email", twoFactor.Email}, func(t *testing.T) {
+			user := h.ReloadUser(twoFactor.ID)
+			if user.TwoFactorEnabled || user.TwoFactorSecret != "" || len(user.BackupCodes) != 0 {
+				t.Errorf("two-factor still set up: enabled=%v secret=%q codes=%d", user.TwoFactorEnabled, user.TwoFactorSecret, len(user.BackupCodes))
+			}
+		}},
+		{"verify-email", []string{"--email", unverified.Email}, func(t *testing.T) {
+			if !h.ReloadUser(unverified.ID).EmailVerified {
+				t.Error("email not verified")
+			}
+		}},
+		{"unlock-account", []string{"--email", locked.Email}, func(t *testing.T) {
+			if user := h.ReloadUser(locked.ID); !user.IsActive || user.IsSuspended {
+				t.Errorf("active=%v suspended=%v, want an active unsuspended account", user.IsActive, user.IsSuspended)
+			}
+		}},
+		{"recount-stats", []string{"--user", miscounted.ID.Hex()}, func(t *testing.T) {
+			user := h.ReloadUser(miscounted.ID)
+			if user.FollowersCount != 1 || user.FollowingCount != 0 || user.PostsCount != 1 {
+				t.Errorf("counts = %d/%d/%d, want 1/0/1", user.FollowersCount, user.FollowingCount, user.PostsCount)
+			}
+		}},
+		{"purge-feed-cache", []string{"--user", cached.ID.Hex()}, func(t *testing.T) {
+			if got := h.Count("feed_cache", bson.M{"user_id": cached.ID}); got != 0 {
+				t.Errorf("cached feeds of the user = %d, want 0", got)
+			}
+			if got := h.Count("feed_cache", bson.M{"user_id": other.ID}); got != 1 {
+				t.Errorf("cached feeds of another user = %d, want 1", got)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			cli, out := newTestAdminCLI(h, "")
+			if err := runCommand(cli, tt.command, append(tt.args, "--yes")...); err != nil {
+				t.Fatalf("%s: %v", tt.command, err)
+			}
+			if !strings.Contains(out.String(), "✅") {
+				t.Errorf("output = %q, want a confirmation", out.String())
+			}
+			tt.check(t)
+
+			action := "cli." + strings.ReplaceAll(tt.command, "-", "_")
+			if got := h.Count("audit_logs", bson.M{"action": action, "actor_type": "cli", "actor_name": "cli:test-host"}); got != 1 {
+				t.Errorf("audit log entries for %s = %d, want 1", action, got)
+			}
+		})
+	}
+
+	if len(tests) != len(adminCommands()) {
+		t.Errorf("smoke test covers %d commands, want all %d", len(tests), len(adminCommands()))
+	}
+}
+
+func TestAdminCommandConfirmation(t *testing.T) {
+	h := testutil.NewHarness(t)
+	user := h.CreateUser()
+
+	cli, out := newTestAdminCLI(h, "n\n")
+	err := runCommand(cli, "promote-user", "--email", user.Email)
+	if err == nil || err.Error() != "aborted by operator" {
+		t.Fatalf("declined promote-user error = %v, want \"aborted by operator\"", err)
+	}
+	if !strings.Contains(out.String(), "Continue? [y/N]") {
+		t.Errorf("output = %q, want a confirmation prompt", out.String())
+	}
+	if role := h.ReloadUser(user.ID).Role; role != models.RoleUser {
+		t.Errorf("role after declining = %q, want %q", role, models.RoleUser)
+	}
+	if got := h.Count("audit_logs", bson.M{}); got != 0 {
+		t.Errorf("audit log entries after declining = %d, want 0", got)
+	}
+
+	cli, _ = newTestAdminCLI(h, "y\n")
+	if err := runCommand(cli, "promote-user", "--email", user.Email, "--role", "moderator"); err != nil {
+		t.Fatalf("confirmed promote-user: %v", err)
+	}
+	if role := h.ReloadUser(user.ID).Role; role != models.RoleModerator {
+		t.Errorf("role after confirming = %q, want %q", role, models.RoleModerator)
+	}
+}
+
+func TestAdminCommandFailures(t *testing.T) {
+	h := testutil.NewHarness(t)
+	user := h.CreateUser()
+
+	tests := []struct {
+		name    string
+		command string
+		args    []string
+	}{
+		{"unknown email", "verify-email", []string{"--email", "nobody@example.com"}},
+		{"missing email", "reset-2fa", nil},
+		{"invalid role", "promote-user", []string{"--email", user.Email, "--role", "owner"}},
+		{"role already held", "demote-user", []string{"--email", user.Email}},
+		{"already verified", "verify-email", []string{"--email", user.Email}},
+		{"not locked", "unlock-account", []string{"--email", user.Email}},
+		{"missing user", "recount-stats", nil},
+		{"invalid user ID", "purge-feed-cache", []string{"--user", "not-an-id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli, _ := newTestAdminCLI(h, "")
+			if err := runCommand(cli, tt.command, append(tt.args, "--yes")...); err == nil {
+				t.Errorf("%s %v succeeded, want an error", tt.command, tt.args)
+			}
+		})
+	}
+
+	if got := h.Count("audit_logs", bson.M{}); got != 0 {
+		t.Errorf("audit log entries after failed commands = %d, want 0", got)
+	}
+}
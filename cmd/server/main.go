@@ -2,18 +2,27 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
 	"social-media-api/internal/config"
+	"social-media-api/internal/emails"
 	"social-media-api/internal/middleware"
+	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
 	"social-media-api/internal/routes"
 	"social-media-api/internal/services"
+	"social-media-api/internal/storage"
+	"social-media-api/internal/translation"
+	"social-media-api/internal/utils"
+	"social-media-api/internal/websocket"
 	"social-media-api/migrations"
 
 	"github.com/gin-gonic/gin"
@@ -38,6 +47,19 @@ func main() {
 		config.Disconnect()
 	}()
 
+	// Page sizes of list endpoints
+	utils.ConfigurePagination(cfg.Server.DefaultPageSize, cfg.Server.MaxPageSize)
+
+	// Words the mention and hashtag parser skips as hashtags
+	utils.ConfigureHashtagStopWords(cfg.Hashtags.StopWords)
+
+	// Heavy reads go to secondaries when read routing is enabled
+	repository.ConfigureReadRouting(repository.ReadRoutingPolicy{
+		Enabled:              cfg.Database.ReadRoutingEnabled,
+		MaxStaleness:         cfg.Database.ReadMaxStaleness,
+		ReadYourWritesWindow: cfg.Database.ReadYourWritesWindow,
+	})
+
 	// Run database migrations
 	log.Println("Running database migrations...")
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -48,6 +70,11 @@ func main() {
 	}
 	log.Println("Migrations completed successfully")
 
+	// Flag ID arrays on documents that could grow without limit
+	for _, issue := range models.CheckSliceBounds(models.PersistedModels()...) {
+		log.Printf("⚠️  Unbounded array field: %s", issue)
+	}
+
 	// Set Gin mode based on configuration
 	gin.SetMode(cfg.Server.Mode)
 
@@ -59,7 +86,10 @@ func main() {
 		config.DB,
 		cfg.JWT.SecretKey,
 		cfg.JWT.RefreshSecretKey,
+		services.DelegationService,
+		services.ImpersonationService,
 	)
+	authMiddleware.SetSessionPolicies(newSessionPolicies(cfg))
 
 	// Initialize behavior tracking middleware
 	behaviorMiddleware := middleware.NewBehaviorTrackingMiddleware(services.BehaviorService)
@@ -68,7 +98,7 @@ func main() {
 	middleware.InitValidator()
 
 	// Create API router with all dependencies
-	apiRouter := routes.NewAPIRouter(services, authMiddleware, behaviorMiddleware, config.DB, cfg.JWT.SecretKey, cfg.JWT.RefreshSecretKey)
+	apiRouter := routes.NewAPIRouter(services, authMiddleware, behaviorMiddleware, newAccountMaturityPolicies(cfg), config.DB, cfg.JWT.SecretKey, cfg.JWT.RefreshSecretKey)
 
 	// Initialize Gin router
 	router := gin.New()
@@ -116,37 +146,171 @@ func initializeServices(cfg *config.Config) *routes.Services {
 
 	// Initialize core services first (no dependencies)
 	authService := services.NewAuthService(cfg.JWT.SecretKey, cfg.JWT.RefreshSecretKey)
-	adminService := services.NewAdminService(config.DB)
-	userService := services.NewUserService()
-	postService := services.NewPostService()
-	commentService := services.NewCommentService()
-	followService := services.NewFollowService()
-	messageService := services.NewMessageService()
-	conversationService := services.NewConversationService()
-	storyService := services.NewStoryService()
+	authService.SetSessionPolicies(newSessionPolicies(cfg))
+	adminService := services.NewAdminService(config.DB, services.AdminQueryPolicy{
+		MaxTime:      cfg.AdminQueries.MaxTime,
+		MaxResults:   cfg.AdminQueries.MaxResults,
+		MaxRangeDays: cfg.AdminQueries.AnalyticsMaxRange,
+	})
+	limitsService := services.NewLimitsService(config.DB, cfg.Limits.CacheTTL)
+	userService := services.NewUserService(config.DB, services.AbuseScorePolicy{
+		Window:              cfg.Moderation.AbuseScoreWindow,
+		ReportWeight:        cfg.Moderation.AbuseScoreReportWeight,
+		FollowChurnWeight:   cfg.Moderation.AbuseScoreFollowChurnWeight,
+		DuplicateWeight:     cfg.Moderation.AbuseScoreDuplicateWeight,
+		MessageReportWeight: cfg.Moderation.AbuseScoreMessageReportWeight,
+	}, limitsService)
+	if cfg.Features.EnableAbuseScoreJob {
+		userService.StartAbuseScoreJob(cfg.Moderation.AbuseScoreInterval)
+	}
+	// Files new hashtags under a category by keyword
+	hashtagCategorizer := models.NewHashtagCategorizer(cfg.Hashtags.CategoryKeywords)
+	// Screens posts, comments and messages for links to blocklisted domains
+	linkBlocklistService := services.NewLinkBlocklistService(config.DB, services.LinkBlocklistPolicy{
+		CacheTTL:          cfg.Moderation.LinkBlocklistCacheTTL,
+		ResolveShorteners: cfg.Moderation.LinkResolveShorteners,
+		Shorteners:        cfg.Moderation.LinkShortenerDomains,
+		ResolveTimeout:    cfg.Moderation.LinkResolveTimeout,
+	})
+	if err := linkBlocklistService.SeedDomains(cfg.Moderation.LinkBlocklistDomains); err != nil {
+		log.Printf("⚠️  Failed to seed the link blocklist: %v", err)
+	}
+	postService := services.NewPostService(config.DB, services.DuplicateContentPolicy{
+		Window:              cfg.Moderation.DuplicatePostWindow,
+		MinLength:           cfg.Moderation.DuplicatePostMinLength,
+		ExemptPhrases:       cfg.Moderation.DuplicatePostExemptPhrases,
+		CoordinatedAccounts: cfg.Moderation.CoordinatedSpamAccounts,
+	}, limitsService, hashtagCategorizer, linkBlocklistService)
+	postService.SetBulkDeletePolicy(newBulkDeletePolicy(cfg))
+	postService.SetLinkPreviewService(services.NewLinkPreviewService(services.LinkPreviewPolicy{
+		Enabled:  cfg.LinkPreview.Enabled,
+		MaxLinks: cfg.LinkPreview.MaxLinks,
+		Timeout:  cfg.LinkPreview.Timeout,
+		MaxBytes: cfg.LinkPreview.MaxBytes,
+		CacheTTL: cfg.LinkPreview.CacheTTL,
+	}))
+	postService.ResumeBulkDeleteJobs()
+	if cfg.Features.EnablePostAutoDeleteJob {
+		postService.StartAutoDeleteJob(services.AutoDeleteCheckInterval)
+	}
+	if cfg.Features.EnablePostOrphanCheckJob {
+		postService.StartOrphanCheckJob(services.PostOrphanCheckInterval)
+	}
+	messageService := services.NewMessageService(limitsService, linkBlocklistService, services.MessageMediaPolicy{
+		AllowedTypes:        cfg.Messaging.MediaAllowedTypes,
+		MaxPerMessage:       cfg.Messaging.MaxMediaPerMessage,
+		MaxMessageSize:      cfg.Messaging.MaxMessageMediaSize,
+		MaxConversationSize: cfg.Messaging.MaxConversationMediaSize,
+	}, newAccountMaturityPolicies(cfg).MessageStrangers)
+
+	// Chat WebSocket hub; its connection registry is the primary presence source
+	chatHubConfig := websocket.DefaultHubConfig()
+	chatHubConfig.AllowedOrigins = cfg.Security.AllowedOrigins
+	chatHub := websocket.NewHub(chatHubConfig)
+	chatMessageHandler := websocket.NewMessageHandler(config.DB, chatHub)
+	chatMessageHandler.SetLinkScreener(linkBlocklistService)
+	chatMessageHandler.SetMessageService(messageService)
+	chatHub.SetMessageHandler(chatMessageHandler)
+	chatHub.SetNotificationHandler(websocket.NewNotificationHandler(config.DB, chatHub))
+	presenceService := services.NewPresenceService(config.DB, chatHub, services.PresencePolicy{
+		OnlineWindow: cfg.Messaging.PresenceOnlineWindow,
+		AwayWindow:   cfg.Messaging.PresenceAwayWindow,
+		Debounce:     cfg.Messaging.PresenceDebounce,
+		IndexTTL:     cfg.Messaging.PresenceIndexTTL,
+	})
+	chatHub.SetPresenceListener(presenceService)
+	go chatHub.Run()
+
+	conversationService := services.NewConversationService(cfg.Messaging.MaxConversationParticipants, services.ConversationActivityPolicy{
+		Window:   cfg.Messaging.ActivityWindow,
+		CacheTTL: cfg.Messaging.ActivityCacheTTL,
+	}, newAccountMaturityPolicies(cfg).MessageStrangers, presenceService)
+	storyService := services.NewStoryService(limitsService)
 	searchService := services.NewSearchService()
 	likeService := services.NewLikeService()
-	reportService := services.NewReportService()
 
 	// Initialize behavior and analytics services (NEW)
 	log.Println("📊 Initializing behavior tracking services...")
 	behaviorService := services.NewUserBehaviorService()
-	analyticsService := services.NewAnalyticsService()
+	analyticsService := services.NewAnalyticsService(services.AudienceActivityPolicy{
+		MinFollowers:   int64(cfg.Insights.AudienceMinFollowers),
+		IncludePremium: cfg.Insights.AudienceForPremium,
+		SampleSize:     cfg.Insights.AudienceSampleSize,
+	})
+	if cfg.Features.EnableAudienceJob {
+		analyticsService.StartAudienceJob(services.AudienceRefreshInterval)
+	}
 
 	// Initialize feed service with behavior service dependency (UPDATED)
 	log.Println("🤖 Initializing AI-powered feed service...")
-	feedService := services.NewFeedService()
+	boostedPostService := services.NewBoostedPostService(config.DB, services.BoostPolicy{
+		DefaultDailyFrequencyCap: cfg.Boosts.DefaultDailyFrequencyCap,
+		AdFreePlans:              cfg.Boosts.AdFreePlans,
+	})
+	feedService := services.NewFeedService(boostedPostService, services.FeedInjectionPolicy{
+		Interval:      cfg.FeedInjection.Interval,
+		Slots:         cfg.FeedInjection.Slots,
+		SessionWindow: cfg.FeedInjection.SessionWindow,
+	}, cfg.Feed.DefaultAlgorithm, services.NetworkTrendingPolicy{
+		Window:      cfg.Feed.NetworkTrendingWindow,
+		MinEngagers: cfg.Feed.NetworkTrendingMinEngagers,
+		Limit:       cfg.Feed.NetworkTrendingLimit,
+		CacheTTL:    cfg.Feed.NetworkTrendingCacheTTL,
+	})
+
+	// Load and validate email templates; in development any broken template stops startup
+	supportEmail := cfg.Email.ReplyTo
+	if supportEmail == "" {
+		supportEmail = cfg.Email.FromEmail
+	}
+	emailTemplates, err := emails.NewRegistry(emails.Options{
+		AppName:      cfg.Email.FromName,
+		AppURL:       cfg.External.FrontendURL,
+		SupportEmail: supportEmail,
+		Strict:       cfg.IsDevelopment(),
+	})
+	if err != nil {
+		log.Fatalf("❌ Invalid email templates: %v", err)
+	}
 
 	// Initialize email service with SMTP configuration
+	unsubscribeSecret := cfg.Email.UnsubscribeSecret
+	if unsubscribeSecret == "" {
+		unsubscribeSecret = cfg.JWT.SecretKey
+	}
 	emailService := services.NewEmailService(
+		config.DB,
 		cfg.Email.SMTPHost,
 		cfg.Email.SMTPPort,
 		cfg.Email.SMTPUser,
 		cfg.Email.SMTPPassword,
 		cfg.Email.FromEmail,
 		cfg.Email.FromName,
+		emailTemplates,
+		services.EmailPolicy{
+			RateWindow:        cfg.Email.RateLimitWindow,
+			PerRecipientLimit: cfg.Email.RateLimitPerRecipient,
+			GlobalLimit:       cfg.Email.RateLimitGlobal,
+			UnsubscribeSecret: unsubscribeSecret,
+			AppURL:            cfg.External.FrontendURL,
+			APIURL:            cfg.External.APIURL,
+		},
 	)
 
+	// Scheduled reactivation welcomes users back by email, so it starts once email is available
+	if cfg.Features.EnableReactivationJob {
+		userService.StartReactivationJob(services.ReactivationCheckInterval, emailService)
+	}
+
+	digestService := services.NewDigestService(config.DB, emailService, services.DigestPolicy{
+		Cadence:       cfg.Email.DigestCadence,
+		InactiveAfter: cfg.Email.DigestInactiveAfter,
+		TopPosts:      cfg.Email.DigestTopPosts,
+	})
+	if cfg.Features.EnableDigestJob {
+		digestService.StartDigestJob(services.DigestCheckInterval)
+	}
+
 	// Initialize push service with Firebase/APNS configuration
 	pushService := services.NewPushService(
 		cfg.External.FirebaseServerKey,
@@ -159,39 +323,234 @@ func initializeServices(cfg *config.Config) *routes.Services {
 
 	// Initialize notification service (depends on email and push services)
 	notificationService := services.NewNotificationService(emailService, pushService)
+	postService.SetNotificationService(notificationService)  // Photo tags notify the tagged users
+	storyService.SetNotificationService(notificationService) // Screenshots of stories notify the author
+
+	// Initialize follow service (follow-many batches its notifications)
+	followService := services.NewFollowService(config.DB, notificationService)
+
+	// Initialize comment service (held comments notify post authors on approval)
+	commentService := services.NewCommentService(services.CommentHoldPolicy{
+		MinAccountAge:       cfg.Moderation.CommentHoldMinAccountAge,
+		MinApprovedComments: int64(cfg.Moderation.CommentHoldMinApprovedComments),
+		StrikeLimit:         int64(cfg.Moderation.CommentStrikeLimit),
+	}, services.CommentFloodPolicy{
+		Window:               cfg.Moderation.CommentRateWindow,
+		Limit:                cfg.Moderation.CommentRateLimit,
+		PerPostLimit:         cfg.Moderation.CommentRateLimitPerPost,
+		VerifiedLimit:        cfg.Moderation.CommentRateLimitVerified,
+		VerifiedPerPostLimit: cfg.Moderation.CommentRateLimitPerPostVerified,
+		DuplicateWindow:      cfg.Moderation.CommentDuplicateWindow,
+		DuplicateMinLength:   cfg.Moderation.CommentDuplicateMinLength,
+	}, int64(cfg.Moderation.QuickReplyLimitPerPost), cfg.Comments.ReplyPreview, notificationService, linkBlocklistService)
+	commentService.SetBulkDeletePolicy(newBulkDeletePolicy(cfg))
+	commentService.ResumeBulkDeleteJobs()
+
+	// Initialize warning service (strikes escalate to suspensions past the configured thresholds)
+	warningService := services.NewWarningService(config.DB, notificationService, services.WarningPolicy{
+		SuspendStrikes:  cfg.Moderation.WarningSuspendStrikes,
+		SuspendDuration: cfg.Moderation.WarningSuspendDuration,
+		BanStrikes:      cfg.Moderation.WarningBanStrikes,
+		StrikeExpiry:    cfg.Moderation.WarningStrikeExpiry,
+	})
+	if cfg.Features.EnableStrikeExpiryJob {
+		warningService.StartStrikeExpiryJob(services.StrikeExpiryCheckInterval)
+	}
+
+	// Initialize status service (records health samples for the public status page)
+	statusService := services.NewStatusService(config.DB, services.StatusPolicy{
+		SampleInterval: cfg.Status.SampleInterval,
+		SlowThreshold:  cfg.Status.SlowThreshold,
+		CacheTTL:       cfg.Status.CacheTTL,
+		WebhookURL:     cfg.Status.WebhookURL,
+		WebhookSecret:  cfg.External.WebhookSecret,
+	})
+	statusService.StartRecorder()
+
+	// Initialize challenge service (proof-of-work for route groups under attack)
+	challengeSecret := cfg.Challenge.Secret
+	if challengeSecret == "" {
+		challengeSecret = cfg.JWT.SecretKey
+	}
+	challengeService := services.NewChallengeService(config.DB, services.ChallengePolicy{
+		Enabled:              cfg.Features.EnablePowChallenge,
+		Secret:               challengeSecret,
+		TTL:                  cfg.Challenge.TTL,
+		BaseDifficulty:       cfg.Challenge.BaseDifficulty,
+		MaxDifficulty:        cfg.Challenge.MaxDifficulty,
+		TrustedAccountAge:    cfg.Challenge.TrustedAccountAge,
+		SignupThreshold:      cfg.Challenge.SignupThreshold,
+		FailedLoginThreshold: cfg.Challenge.FailedLoginThreshold,
+		PerIPThreshold:       cfg.Challenge.PerIPThreshold,
+		SettingsTTL:          cfg.Challenge.SettingsTTL,
+	})
+
+	// Initialize report service (resolution actions notify content owners, warnings become strikes)
+	reportService := services.NewReportService(notificationService, warningService)
+
+	// Initialize delegation service (delegated tokens are signed with the access token secret)
+	delegationService := services.NewDelegationService(
+		config.DB,
+		notificationService,
+		cfg.JWT.SecretKey,
+		cfg.JWT.DelegatedTokenDuration,
+		cfg.JWT.DelegationCacheTTL,
+	)
+
+	// Initialize impersonation service (impersonation tokens are signed with the access token secret)
+	impersonationService := services.NewImpersonationService(config.DB, notificationService, cfg.JWT.SecretKey, services.ImpersonationPolicy{
+		TokenTTL:   cfg.JWT.ImpersonationTokenDuration,
+		NotifyUser: cfg.Security.ImpersonationNotifyUser,
+	})
 
-	// Initialize media service with upload configuration
+	// Initialize media service with upload configuration and the cold storage tier
 	mediaService := services.NewMediaService(
 		cfg.Upload.UploadPath,
 		cfg.Upload.LocalURL,
+		cfg.Upload.RequireImageAltText,
+		newMediaTieredStorage(cfg),
+		services.MediaTieringPolicy{
+			ColdAfter:          cfg.MediaTiering.ColdAfter,
+			HotCategories:      cfg.MediaTiering.HotCategories,
+			RestoreRequests:    cfg.MediaTiering.RestoreRequests,
+			HotCostPerGBMonth:  cfg.MediaTiering.HotCostPerGBMonth,
+			ColdCostPerGBMonth: cfg.MediaTiering.ColdCostPerGBMonth,
+		},
+		services.MediaOutputPolicy{
+			Encoders:      newImageEncoders(cfg),
+			Quality:       cfg.MediaOutput.Quality,
+			KeepOriginal:  cfg.MediaOutput.KeepOriginal,
+			EncodeTimeout: cfg.MediaOutput.EncodeTimeout,
+
+			MaxResolution:      cfg.MediaOutput.MaxResolution,
+			KeepFullResolution: cfg.MediaOutput.KeepFullResolution,
+		},
+		newScanService(cfg),
+		notificationService,
 	)
+	if cfg.Features.EnableOrphanCleanupJob {
+		mediaService.StartOrphanCleanup(services.OrphanCleanupInterval)
+	}
+	mediaService.StartStorageTiering(services.MediaTieringInterval, cfg.Features.EnableMediaTieringJob)
 
 	// Initialize group service (depends on database and notification service)
-	groupService := services.NewGroupService(config.DB, notificationService)
+	groupService := services.NewGroupService(config.DB, notificationService, cfg.Groups.HighlightsThreshold, cfg.External.FrontendURL)
+
+	// Initialize group library service with the configured storage quotas
+	groupLibraryService := services.NewGroupLibraryService(config.DB, groupService, mediaService, services.GroupLibraryPolicy{
+		QuotaBytes:        cfg.Groups.LibraryQuotaBytes,
+		PremiumQuotaBytes: cfg.Groups.PremiumLibraryQuotaBytes,
+	})
+
+	// Initialize translation service with the configured provider
+	translationProvider, err := translation.NewProvider(translation.Config{
+		Provider: cfg.Translation.Provider,
+		URL:      cfg.Translation.URL,
+		APIKey:   cfg.Translation.APIKey,
+		Timeout:  cfg.Translation.Timeout,
+	})
+	if err != nil {
+		log.Printf("⚠️  Failed to initialize translation provider, falling back to noop: %v", err)
+		translationProvider = translation.NewNoopProvider()
+	}
+	translationService := services.NewTranslationService(config.DB, translationProvider, postService, cfg.Translation.MaxTextLength)
+
+	// Initialize survey service (depends on database and notification service)
+	surveyService := services.NewSurveyService(config.DB, notificationService)
+
+	// Initialize reaction type service (loads the reaction catalog)
+	reactionTypeService := services.NewReactionTypeService(config.DB)
+
+	// Initialize suggestion service; the refresh job precomputes suggestion lists
+	suggestionService := services.NewSuggestionService(config.DB)
+	if cfg.Features.EnableSuggestionJob {
+		suggestionService.StartRefresher(services.SuggestionRefreshInterval)
+	}
+
+	// Initialize setup checklist service (emits completion events to behavior analytics)
+	setupChecklistService := services.NewSetupChecklistService(config.DB, behaviorService)
+
+	// Initialize content calendar service (creator planning view)
+	contentCalendarService := services.NewContentCalendarService(config.DB)
+
+	// Initialize analytics export service; exports cut short by a restart pick up where they stopped
+	analyticsExportService := services.NewAnalyticsExportService(config.DB, cfg.AnalyticsExport.Dir, cfg.AnalyticsExport.TTL)
+	analyticsExportService.ResumeInterruptedJobs()
+
+	// Initialize moderation queue service (merges every pending moderation source)
+	moderationQueueService := services.NewModerationQueueService()
+
+	// Initialize hashtag service; the related job recomputes hashtag co-occurrence daily
+	hashtagService := services.NewHashtagService(config.DB, hashtagCategorizer)
+	if cfg.Features.EnableRelatedHashtagsJob {
+		hashtagService.StartRelatedJob(services.RelatedHashtagsRefreshInterval)
+	}
+
+	// Initialize mention suggestion service (comment @-mention autocomplete)
+	mentionSuggestionService := services.NewMentionSuggestionService(config.DB, postService)
+
+	// Initialize retention service; the nightly job deletes data past its retention period
+	retentionService := services.NewRetentionService(config.DB, newRetentionPolicy(cfg))
+	if cfg.Features.EnableRetentionJob {
+		retentionService.StartRetentionJob(services.RetentionInterval)
+	}
+
+	// Initialize saved search service; the alert job reruns saved searches for new posts
+	savedSearchService := services.NewSavedSearchService(config.DB, searchService, notificationService, services.SavedSearchPolicy{
+		MaxPerUser:    cfg.SavedSearches.MaxPerUser,
+		AlertInterval: cfg.SavedSearches.AlertInterval,
+	})
+	if cfg.Features.EnableSavedSearchAlerts {
+		savedSearchService.StartAlertJob(services.SavedSearchAlertCheckInterval)
+	}
 
 	log.Println("✅ All services initialized successfully")
 
 	return &routes.Services{
-		AuthService:         authService,
-		AdminService:        adminService,
-		UserService:         userService,
-		PostService:         postService,
-		CommentService:      commentService,
-		FollowService:       followService,
-		MessageService:      messageService,
-		ConversationService: conversationService,
-		StoryService:        storyService,
-		GroupService:        groupService,
-		FeedService:         feedService,
-		SearchService:       searchService,
-		NotificationService: notificationService,
-		MediaService:        mediaService,
-		LikeService:         likeService,
-		ReportService:       reportService,
-		EmailService:        emailService,
-		PushService:         pushService,
-		BehaviorService:     behaviorService,  // NEW
-		AnalyticsService:    analyticsService, // NEW
+		AuthService:              authService,
+		AdminService:             adminService,
+		UserService:              userService,
+		PostService:              postService,
+		CommentService:           commentService,
+		FollowService:            followService,
+		MessageService:           messageService,
+		ConversationService:      conversationService,
+		PresenceService:          presenceService,
+		ChatHub:                  chatHub,
+		StoryService:             storyService,
+		GroupService:             groupService,
+		GroupLibraryService:      groupLibraryService,
+		FeedService:              feedService,
+		BoostedPostService:       boostedPostService,
+		LimitsService:            limitsService,
+		SearchService:            searchService,
+		SavedSearchService:       savedSearchService,
+		ImpersonationService:     impersonationService,
+		NotificationService:      notificationService,
+		MediaService:             mediaService,
+		LikeService:              likeService,
+		ReportService:            reportService,
+		WarningService:           warningService,
+		StatusService:            statusService,
+		ChallengeService:         challengeService,
+		ContentCalendarService:   contentCalendarService,
+		AnalyticsExportService:   analyticsExportService,
+		ModerationQueueService:   moderationQueueService,
+		HashtagService:           hashtagService,
+		LinkBlocklistService:     linkBlocklistService,
+		MentionSuggestionService: mentionSuggestionService,
+		RetentionService:         retentionService,
+		DelegationService:        delegationService,
+		EmailService:             emailService,
+		DigestService:            digestService,
+		PushService:              pushService,
+		BehaviorService:          behaviorService,  // NEW
+		AnalyticsService:         analyticsService, // NEW
+		TranslationService:       translationService,
+		SurveyService:            surveyService,
+		ReactionTypeService:      reactionTypeService,
+		SuggestionService:        suggestionService,
+		SetupChecklistService:    setupChecklistService,
 	}
 }
 
@@ -202,6 +561,13 @@ func setupGlobalMiddleware(router *gin.Engine, cfg *config.Config, behaviorMiddl
 	// Recovery middleware
 	router.Use(gin.Recovery())
 
+	// Real client IP, trusting forwarding headers only from configured proxies
+	clientIPs, err := utils.NewClientIPResolver(cfg.Server.TrustedProxies)
+	if err != nil {
+		log.Fatalf("❌ Invalid TRUSTED_PROXIES: %v", err)
+	}
+	router.Use(middleware.RealClientIP(clientIPs))
+
 	// CORS middleware
 	router.Use(middleware.CORS())
 
@@ -275,9 +641,11 @@ func setupGlobalMiddleware(router *gin.Engine, cfg *config.Config, behaviorMiddl
 		log.Println("✅ Behavior tracking middleware configured")
 	}
 
-	// Trusted proxies configuration
-	if len(cfg.Server.TrustedProxies) > 0 {
-		router.SetTrustedProxies(cfg.Server.TrustedProxies)
+	// Trusted proxies configuration. Gin trusts every proxy unless told
+	// otherwise, so c.ClientIP() is kept in line with the resolver even when
+	// no proxies are configured.
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("❌ Invalid TRUSTED_PROXIES: %v", err)
 	}
 }
 
@@ -302,6 +670,57 @@ func setupGracefulShutdown(server *http.Server, cfg *config.Config, services *ro
 		// Example: flush pending analytics data, close connections, etc.
 	}
 
+	if services.SuggestionService != nil {
+		services.SuggestionService.StopRefresher()
+	}
+
+	if services.MediaService != nil {
+		services.MediaService.StopOrphanCleanup()
+		services.MediaService.StopStorageTiering()
+	}
+
+	if services.UserService != nil {
+		services.UserService.StopReactivationJob()
+		services.UserService.StopAbuseScoreJob()
+	}
+
+	if services.PostService != nil {
+		services.PostService.StopAutoDeleteJob()
+		services.PostService.StopOrphanCheckJob()
+	}
+
+	if services.DigestService != nil {
+		services.DigestService.StopDigestJob()
+	}
+
+	if services.AnalyticsService != nil {
+		services.AnalyticsService.StopAudienceJob()
+	}
+
+	if services.WarningService != nil {
+		services.WarningService.StopStrikeExpiryJob()
+	}
+
+	if services.HashtagService != nil {
+		services.HashtagService.StopRelatedJob()
+	}
+
+	if services.RetentionService != nil {
+		services.RetentionService.StopRetentionJob()
+	}
+
+	if services.SavedSearchService != nil {
+		services.SavedSearchService.StopAlertJob()
+	}
+
+	if services.StatusService != nil {
+		services.StatusService.StopRecorder()
+	}
+
+	if services.ChatHub != nil {
+		services.ChatHub.Shutdown()
+	}
+
 	// Shutdown server gracefully
 	if err := server.Shutdown(ctx); err != nil {
 		log.Printf("Server forced to shutdown: %v", err)
@@ -310,6 +729,71 @@ func setupGracefulShutdown(server *http.Server, cfg *config.Config, services *ro
 	}
 }
 
+// newScanService picks the malware scanner for uploads, which passes every
+// file when scanning is disabled
+func newScanService(cfg *config.Config) services.ScanService {
+	if !cfg.Upload.VirusScanEnabled {
+		return services.NoopScanService{}
+	}
+	log.Printf("🛡️  Scanning uploads with clamd at %s", cfg.Upload.ClamAVAddress)
+	return services.NewClamAVScanService(cfg.Upload.ClamAVAddress, cfg.Upload.VirusScanTimeout)
+}
+
+// newImageEncoders returns the JPEG fallback encoder and one for each
+// configured modern format whose tool is installed
+func newImageEncoders(cfg *config.Config) []services.ImageEncoder {
+	encoders := []services.ImageEncoder{services.JPEGEncoder{}}
+	for _, format := range cfg.MediaOutput.Formats {
+		var encoder *services.CommandEncoder
+		switch format {
+		case "webp":
+			encoder = services.NewWebPEncoder(cfg.MediaOutput.CwebpPath)
+		case "avif":
+			encoder = services.NewAVIFEncoder(cfg.MediaOutput.AvifencPath)
+		default:
+			continue
+		}
+		if !encoder.Available() {
+			log.Printf("⚠️  %s media output disabled: encoder not found", format)
+			continue
+		}
+		encoders = append(encoders, encoder)
+	}
+	return encoders
+}
+
+// newMediaTieredStorage builds the hot and cold tiers for media originals.
+// Tiering is disabled when the cold tier cannot be set up.
+func newMediaTieredStorage(cfg *config.Config) *storage.TieredStorage {
+	hot, err := storage.NewLocalProvider(storage.StorageConfig{LocalPath: cfg.Upload.UploadPath})
+	if err != nil {
+		log.Printf("⚠️  Media storage tiering disabled: %v", err)
+		return nil
+	}
+
+	var cold storage.StorageProvider
+	switch cfg.MediaTiering.ColdProvider {
+	case "s3":
+		cold, err = storage.NewS3Provider(storage.StorageConfig{
+			Region:    cfg.AWS.Region,
+			Bucket:    cfg.AWS.S3Bucket,
+			AccessKey: cfg.AWS.AccessKeyID,
+			SecretKey: cfg.AWS.SecretAccessKey,
+			Endpoint:  cfg.AWS.S3Endpoint,
+			CDNDomain: cfg.AWS.CloudFrontURL,
+			Options:   map[string]string{"storage_class": cfg.MediaTiering.ColdS3StorageClass},
+		})
+	default:
+		cold, err = storage.NewLocalProvider(storage.StorageConfig{LocalPath: cfg.MediaTiering.ColdPath})
+	}
+	if err != nil {
+		log.Printf("⚠️  Media storage tiering disabled: %v", err)
+		return nil
+	}
+
+	return storage.NewTieredStorage(hot, cold)
+}
+
 // generateRequestID generates a unique request ID
 func generateRequestID() string {
 	return fmt.Sprintf("%d", time.Now().UnixNano())
@@ -362,7 +846,16 @@ func setupDevelopmentRoutes(router *gin.Engine, cfg *config.Config) {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
 			}
-			c.JSON(http.StatusOK, gin.H{"migrations": status})
+
+			backfillRunner := migrations.NewBackfillRunner(config.DB)
+			backfillRunner.RegisterBackfills(migrations.InitializeBackfills())
+
+			backfills, err := backfillRunner.GetBackfillStatus(ctx)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"migrations": status, "backfills": backfills})
 		})
 
 		// Health check with detailed info including behavior tracking (ENHANCED)
@@ -480,6 +973,11 @@ func runMigrationCommand() {
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
 		case "migrate":
+			if len(os.Args) > 2 && (os.Args[2] == "--backfill" || os.Args[2] == "-backfill") {
+				runBackfillCommand(os.Args[2:])
+				return
+			}
+
 			config.InitDB()
 			defer config.Disconnect()
 
@@ -517,44 +1015,146 @@ func runMigrationCommand() {
 			config.InitDB()
 			defer config.Disconnect()
 
-			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			// Deleting in batches can take a while on large collections
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
 			defer cancel()
 
 			log.Println("📊 Cleaning up old behavior data...")
-			services.NewUserBehaviorService()
 
-			// Cleanup old sessions (older than 30 days)
-			cutoffDate := time.Now().AddDate(0, 0, -30)
-
-			collections := []string{"user_sessions", "content_engagements", "user_journeys"}
-			for _, collName := range collections {
-				result, err := config.DB.Collection(collName).DeleteMany(ctx, map[string]interface{}{
-					"created_at": map[string]interface{}{"$lt": cutoffDate},
-				})
-				if err != nil {
-					log.Printf("Error cleaning up %s: %v", collName, err)
+			// Runs the behavior categories of the retention registry, with
+			// the same periods, legal holds and safety valve as the nightly job
+			retentionService := services.NewRetentionService(config.DB, newRetentionPolicy(config.GetConfig()))
+			runs, err := retentionService.Run(ctx, services.BehaviorRetentionCategories...)
+			if err != nil {
+				log.Fatalf("Behavior data cleanup failed: %v", err)
+			}
+			for _, run := range runs {
+				if run.LastError != "" {
+					log.Printf("Error cleaning up %s (%s): %s", run.Category, run.LastOutcome, run.LastError)
 				} else {
-					log.Printf("Cleaned up %d records from %s", result.DeletedCount, collName)
+					log.Printf("Cleaned up %d records from %s (%s)", run.LastDeleted, run.Category, run.LastOutcome)
 				}
 			}
 
 			log.Println("✅ Behavior data cleanup completed")
 			return
 
-		// NEW: Export behavior analytics command
+		// Export behavior analytics to CSV or NDJSON files
 		case "export-analytics":
-			config.InitDB()
-			defer config.Disconnect()
+			runAnalyticsExportCommand(os.Args[2:])
+			return
+		}
+	}
+}
 
-			_, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-			defer cancel()
+// runBackfillCommand runs a long-running data backfill outside the startup path:
+//
+//	go run cmd/server/main.go migrate --backfill <id> [--dry-run] [--batch-size 500] [--rate 1000] [--restart]
+func runBackfillCommand(args []string) {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	backfillID := flags.String("backfill", "", "ID of the backfill to run")
+	dryRun := flags.Bool("dry-run", false, "count affected documents without writing")
+	batchSize := flags.Int("batch-size", 500, "documents processed per batch")
+	rate := flags.Int("rate", 1000, "maximum documents per second (0 disables throttling)")
+	restart := flags.Bool("restart", false, "ignore saved progress and start from the beginning")
+	flags.Parse(args)
+
+	backfills := migrations.InitializeBackfills()
+	if *backfillID == "" {
+		log.Println("Usage: go run main.go migrate --backfill <id> [--dry-run] [--batch-size N] [--rate N] [--restart]")
+		log.Println("Available backfills:")
+		for _, backfill := range backfills {
+			log.Printf("  %s: %s", backfill.ID, backfill.Description)
+		}
+		os.Exit(1)
+	}
 
-			log.Println("📊 Exporting behavior analytics...")
-			// Add analytics export logic here
-			log.Println("✅ Analytics export completed")
-			return
+	config.InitDB()
+	defer config.Disconnect()
+
+	runner := migrations.NewBackfillRunner(config.DB)
+	runner.RegisterBackfills(backfills)
+
+	// Stop cleanly on Ctrl+C; progress is saved and the next run resumes
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	progress, err := runner.RunBackfill(ctx, *backfillID, migrations.BackfillOptions{
+		BatchSize:    *batchSize,
+		MaxPerSecond: *rate,
+		DryRun:       *dryRun,
+		Restart:      *restart,
+	})
+	if err != nil {
+		log.Fatalf("Backfill failed: %v", err)
+	}
+	log.Printf("Backfill %s: %s (%d processed, %d modified)", progress.ID, progress.Status, progress.Processed, progress.Modified)
+}
+
+// runAnalyticsExportCommand writes the behavior analytics collections to
+// files with the schema documented in models.AnalyticsExportColumns:
+//
+//	go run main.go export-analytics --from 2024-01-01 --to 2024-02-01 --format ndjson --anonymize
+//
+// Dates are UTC days, from inclusive and to exclusive. An interrupted export
+// resumes when run again with the same options and output directory.
+func runAnalyticsExportCommand(args []string) {
+	flags := flag.NewFlagSet("export-analytics", flag.ExitOnError)
+	fromFlag := flags.String("from", "", "first day to export, YYYY-MM-DD (default 30 days before --to)")
+	toFlag := flags.String("to", "", "day the export stops before, YYYY-MM-DD (default today)")
+	format := flags.String("format", models.AnalyticsExportCSV, "output format: csv or ndjson")
+	outDir := flags.String("out", "", "output directory (default ./exports/analytics-<from>-<to>)")
+	anonymize := flags.Bool("anonymize", false, "replace user and session IDs with consistent hashes")
+	restart := flags.Bool("restart", false, "discard an interrupted export in the output directory")
+	flags.Parse(args)
+
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if *toFlag != "" {
+		parsed, err := time.Parse("2006-01-02", *toFlag)
+		if err != nil {
+			log.Fatalf("Invalid --to date %q: expected YYYY-MM-DD", *toFlag)
 		}
+		to = parsed
 	}
+	from := to.AddDate(0, 0, -30)
+	if *fromFlag != "" {
+		parsed, err := time.Parse("2006-01-02", *fromFlag)
+		if err != nil {
+			log.Fatalf("Invalid --from date %q: expected YYYY-MM-DD", *fromFlag)
+		}
+		from = parsed
+	}
+	if *outDir == "" {
+		*outDir = filepath.Join("exports", fmt.Sprintf("analytics-%s-%s", from.Format("20060102"), to.Format("20060102")))
+	}
+
+	config.InitDB()
+	defer config.Disconnect()
+
+	cfg := config.Load()
+	exportService := services.NewAnalyticsExportService(config.DB, cfg.AnalyticsExport.Dir, cfg.AnalyticsExport.TTL)
+
+	// Stop cleanly on Ctrl+C; progress is saved and the next run resumes
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("📊 Exporting behavior analytics from %s to %s into %s...", from.Format("2006-01-02"), to.Format("2006-01-02"), *outDir)
+	manifest, err := exportService.Export(ctx, models.AnalyticsExportOptions{
+		From:      from,
+		To:        to,
+		Format:    *format,
+		Anonymize: *anonymize,
+		OutputDir: *outDir,
+		Restart:   *restart,
+	})
+	if err != nil {
+		log.Fatalf("Analytics export failed: %v", err)
+	}
+
+	for _, file := range manifest.Files {
+		log.Printf("  %s: %d rows (%d excluded by opt-out)", file.File, file.Rows, file.Excluded)
+	}
+	log.Println("✅ Analytics export completed")
 }
 
 func init() {
@@ -564,38 +1164,99 @@ func init() {
 		case "migrate", "rollback", "cleanup-behavior", "export-analytics":
 			runMigrationCommand()
 			os.Exit(0)
+		case "help", "--help", "-h":
+			printAdminUsage(os.Stdout)
+			os.Exit(0)
+		}
+
+		if _, ok := adminCommands()[os.Args[1]]; ok {
+			os.Exit(runAdminCommand(os.Args[1], os.Args[2:]))
 		}
 	}
 }
 
-// Additional utility functions for behavior tracking
-
-// cleanupBehaviorData removes old behavior tracking data
-func cleanupBehaviorData(ctx context.Context) error {
-	cutoffDate := time.Now().AddDate(0, 0, -30) // 30 days ago
-
-	collections := []string{
-		"user_sessions",
-		"content_engagements",
-		"user_journeys",
-		"recommendation_events",
-		"feed_cache",
+// newAccountMaturityPolicies builds the policy of each action new accounts
+// are held back from
+func newAccountMaturityPolicies(cfg *config.Config) models.AccountMaturityPolicies {
+	moderation := cfg.Moderation
+	policy := func(action string, minAge time.Duration, requireEmail bool) models.AccountMaturityPolicy {
+		return models.AccountMaturityPolicy{
+			Action:               action,
+			MinAccountAge:        minAge,
+			RequireVerifiedEmail: requireEmail,
+			ExemptVerified:       moderation.MaturityExemptVerified,
+			ExemptPremium:        moderation.MaturityExemptPremium,
+		}
 	}
 
-	for _, collName := range collections {
-		filter := map[string]interface{}{
-			"created_at": map[string]interface{}{"$lt": cutoffDate},
-		}
+	return models.AccountMaturityPolicies{
+		MessageStrangers: policy(models.MaturityActionMessageStrangers, moderation.MaturityMessageStrangersMinAge, moderation.MaturityMessageStrangersRequireEmail),
+		Report:           policy(models.MaturityActionReport, moderation.MaturityReportMinAge, moderation.MaturityReportRequireEmail),
+		CreateGroup:      policy(models.MaturityActionCreateGroup, moderation.MaturityCreateGroupMinAge, moderation.MaturityCreateGroupRequireEmail),
+	}
+}
 
-		result, err := config.DB.Collection(collName).DeleteMany(ctx, filter)
-		if err != nil {
-			return fmt.Errorf("error cleaning up %s: %w", collName, err)
+// newSessionPolicies builds the session lifetime of each role from the JWT
+// settings
+func newSessionPolicies(cfg *config.Config) models.SessionPolicies {
+	policies := models.SessionPolicies{
+		Default: models.SessionPolicy{
+			AccessTokenDuration: cfg.JWT.AccessTokenDuration,
+			SlidingWindow:       cfg.JWT.SlidingWindow,
+			MaxSessionAge:       cfg.JWT.MaxSessionAge,
+		},
+		Roles:                make(map[models.UserRole]models.SessionPolicy),
+		RefreshTokenDuration: cfg.JWT.RefreshTokenDuration,
+	}
+	for role, session := range cfg.JWT.RoleSessions {
+		policies.Roles[models.UserRole(role)] = models.SessionPolicy{
+			AccessTokenDuration: session.AccessTokenDuration,
+			SlidingWindow:       session.SlidingWindow,
+			MaxSessionAge:       session.MaxSessionAge,
 		}
+	}
+	return policies
+}
 
-		log.Printf("Cleaned up %d old records from %s", result.DeletedCount, collName)
+// newBulkDeletePolicy builds the caps on users bulk deleting their own posts
+// and comments
+func newBulkDeletePolicy(cfg *config.Config) services.BulkDeletePolicy {
+	return services.BulkDeletePolicy{
+		MaxIDs:    cfg.BulkDelete.MaxIDs,
+		MaxItems:  cfg.BulkDelete.MaxItems,
+		SyncLimit: cfg.BulkDelete.SyncLimit,
+		JobTTL:    cfg.BulkDelete.JobTTL,
 	}
+}
 
-	return nil
+// newRetentionPolicy builds the retention worker's policy from the
+// configured default periods
+func newRetentionPolicy(cfg *config.Config) services.RetentionPolicy {
+	day := 24 * time.Hour
+	retention := cfg.Retention
+
+	return services.RetentionPolicy{
+		Periods: map[string]time.Duration{
+			models.RetentionBehaviorSessions:     time.Duration(retention.UserSessionsDays) * day,
+			models.RetentionUserJourneys:         time.Duration(retention.UserJourneysDays) * day,
+			models.RetentionEngagementEvents:     time.Duration(retention.ContentEngagementsDays) * day,
+			models.RetentionRecommendationEvents: time.Duration(retention.RecommendationEventsDays) * day,
+			models.RetentionFeedCache:            time.Duration(retention.FeedCacheGraceHours) * time.Hour,
+			models.RetentionNotifications:        time.Duration(retention.NotificationsDays) * day,
+			models.RetentionAuditLogs:            time.Duration(retention.AuditLogsDays) * day,
+			models.RetentionResolvedReports:      time.Duration(retention.ResolvedReportsDays) * day,
+			models.RetentionLoginHistory:         time.Duration(retention.LoginHistoryDays) * day,
+			models.RetentionIdempotencyKeys:      time.Duration(retention.IdempotencyKeysDays) * day,
+			models.RetentionStatusSamples:        time.Duration(retention.StatusSamplesDays) * day,
+			models.RetentionFeedAlgorithmLogs:    time.Duration(retention.FeedAlgorithmLogsDays) * day,
+		},
+		MaxDeleteFraction:  retention.MaxDeleteFraction,
+		SafetyMinDocuments: retention.SafetyMinDocuments,
+		BatchSize:          retention.BatchSize,
+		SyncTTL: func(ctx context.Context, collection, field string, period time.Duration) error {
+			return migrations.SyncTTLIndex(ctx, config.DB, collection, field, period)
+		},
+	}
 }
 
 // logStartupBanner prints a nice startup banner
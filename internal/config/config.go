@@ -48,6 +48,69 @@ type Config struct {
 	// Monitoring
 	Monitoring MonitoringConfig `json:"monitoring"`
 
+	// Translation
+	Translation TranslationConfig `json:"translation"`
+
+	// Messaging
+	Messaging MessagingConfig `json:"messaging"`
+
+	// Moderation
+	Moderation ModerationConfig `json:"moderation"`
+
+	// Creator insights
+	Insights InsightsConfig `json:"insights"`
+
+	// Data retention
+	Retention RetentionConfig `json:"retention"`
+
+	// Group resource libraries
+	Groups GroupsConfig `json:"groups"`
+
+	// Admin and analytics query limits
+	AdminQueries AdminQueryConfig `json:"admin_queries"`
+
+	// Boosted post delivery
+	Boosts BoostConfig `json:"boosts"`
+
+	// Recommended and sponsored posts injected into home feeds
+	FeedInjection FeedInjectionConfig `json:"feed_injection"`
+
+	// Feed algorithm selection
+	Feed FeedConfig `json:"feed"`
+
+	// Per-tier content and usage limits
+	Limits LimitsConfig `json:"limits"`
+
+	// Media storage tiering
+	MediaTiering MediaTieringConfig `json:"media_tiering"`
+
+	// Public status page
+	Status StatusConfig `json:"status"`
+
+	// Proof-of-work challenges for write endpoints under attack
+	Challenge ChallengeConfig `json:"challenge"`
+
+	// Analytics export configuration
+	AnalyticsExport AnalyticsExportConfig `json:"analytics_export"`
+
+	// Hashtag categorization
+	Hashtags HashtagsConfig `json:"hashtags"`
+
+	// Saved searches and search alerts
+	SavedSearches SavedSearchConfig `json:"saved_searches"`
+
+	// Comment threads
+	Comments CommentsConfig `json:"comments"`
+
+	// Users bulk deleting their own posts and comments
+	BulkDelete BulkDeleteConfig `json:"bulk_delete"`
+
+	// Image variant formats and quality
+	MediaOutput MediaOutputConfig `json:"media_output"`
+
+	// Link previews shown while composing posts
+	LinkPreview LinkPreviewConfig `json:"link_preview"`
+
 	// Environment
 	Environment string `json:"environment"`
 }
@@ -62,6 +125,9 @@ type ServerConfig struct {
 	ShutdownTimeout time.Duration `json:"shutdown_timeout"`
 	MaxRequestSize  int64         `json:"max_request_size"`
 	TrustedProxies  []string      `json:"trusted_proxies"`
+
+	DefaultPageSize int `json:"default_page_size"` // Page size of list endpoints when the client sends no limit
+	MaxPageSize     int `json:"max_page_size"`     // Larger limits are clamped to this
 }
 
 // DatabaseConfig contains database-related configuration
@@ -73,6 +139,10 @@ type DatabaseConfig struct {
 	MaxConnIdleTime time.Duration `json:"max_conn_idle_time"`
 	ConnectTimeout  time.Duration `json:"connect_timeout"`
 	ServerTimeout   time.Duration `json:"server_timeout"`
+
+	ReadRoutingEnabled   bool          `json:"read_routing_enabled"`    // Send heavy reads to secondaries; off for single-node deployments
+	ReadMaxStaleness     time.Duration `json:"read_max_staleness"`      // How far behind a secondary may be and still serve reads
+	ReadYourWritesWindow time.Duration `json:"read_your_writes_window"` // How long a user's heavy reads stay on the primary after they write
 }
 
 // RedisConfig contains Redis-related configuration
@@ -106,6 +176,31 @@ type JWTConfig struct {
 	RefreshTokenDuration time.Duration `json:"refresh_token_duration"`
 	Issuer               string        `json:"issuer"`
 	Algorithm            string        `json:"algorithm"`
+	// Delegated tokens are short-lived and never refreshed; delegates switch
+	// into the managed account again when one expires
+	DelegatedTokenDuration time.Duration `json:"delegated_token_duration"`
+	// How long a delegation lookup is trusted before it is re-read; revoking
+	// on this instance takes effect immediately
+	DelegationCacheTTL time.Duration `json:"delegation_cache_ttl"`
+	// Impersonation tokens are short-lived and never refreshed; support
+	// starts a new session when one expires
+	ImpersonationTokenDuration time.Duration `json:"impersonation_token_duration"`
+
+	// Requests made when the access token has less than this left get a
+	// fresh one in the X-Refreshed-Token header (0 disables sliding expiry).
+	// Requires MaxSessionAge.
+	SlidingWindow time.Duration `json:"sliding_window"`
+	// No session outlives this from sign-in, however active (0 disables)
+	MaxSessionAge time.Duration `json:"max_session_age"`
+	// Roles with their own session lifetimes, e.g. shorter admin sessions
+	RoleSessions map[string]SessionConfig `json:"role_sessions"`
+}
+
+// SessionConfig is the session lifetime of one role
+type SessionConfig struct {
+	AccessTokenDuration time.Duration `json:"access_token_duration"`
+	SlidingWindow       time.Duration `json:"sliding_window"`
+	MaxSessionAge       time.Duration `json:"max_session_age"`
 }
 
 // EmailConfig contains email-related configuration
@@ -119,6 +214,17 @@ type EmailConfig struct {
 	ReplyTo      string `json:"reply_to"`
 	UseTLS       bool   `json:"use_tls"`
 	UseSSL       bool   `json:"use_ssl"`
+
+	// Sends counted per window, per recipient and in total; a limit of 0 disables it
+	RateLimitWindow       time.Duration `json:"rate_limit_window"`
+	RateLimitPerRecipient int64         `json:"rate_limit_per_recipient"`
+	RateLimitGlobal       int64         `json:"rate_limit_global"`
+	UnsubscribeSecret     string        `json:"-"` // Signs unsubscribe links; defaults to the JWT secret
+
+	// Activity digest for users who opted in and stopped logging in
+	DigestCadence       time.Duration `json:"digest_cadence"`
+	DigestInactiveAfter time.Duration `json:"digest_inactive_after"`
+	DigestTopPosts      int           `json:"digest_top_posts"`
 }
 
 // UploadConfig contains file upload configuration
@@ -133,6 +239,14 @@ type UploadConfig struct {
 	TempPath        string   `json:"temp_path"`
 	UseS3           bool     `json:"use_s3"`
 	LocalURL        string   `json:"local_url"`
+
+	// Accessibility
+	RequireImageAltText bool `json:"require_image_alt_text"` // Reject image uploads without alt text
+
+	// Malware scanning of uploads through clamd
+	VirusScanEnabled bool          `json:"virus_scan_enabled"`
+	ClamAVAddress    string        `json:"clamav_address"`
+	VirusScanTimeout time.Duration `json:"virus_scan_timeout"`
 }
 
 // AWSConfig contains AWS-related configuration
@@ -181,6 +295,10 @@ type SecurityConfig struct {
 	EnableHTTPS          bool     `json:"enable_https"`
 	HSTSEnabled          bool     `json:"hsts_enabled"`
 	HSTSMaxAge           int      `json:"hsts_max_age"`
+
+	// Whether users are notified when support impersonates them: always,
+	// never, or optional to let the admin decide per session
+	ImpersonationNotifyUser string `json:"impersonation_notify_user"`
 }
 
 // FeatureFlags contains feature toggle configuration
@@ -199,6 +317,20 @@ type FeatureFlags struct {
 	EnableFileUploads        bool `json:"enable_file_uploads"`
 	EnableVideoUploads       bool `json:"enable_video_uploads"`
 	EnableAudioUploads       bool `json:"enable_audio_uploads"`
+	EnableSuggestionJob      bool `json:"enable_suggestion_job"`        // Run the follow suggestion refresh on this instance
+	EnableAudienceJob        bool `json:"enable_audience_job"`          // Run the nightly audience activity aggregation on this instance
+	EnableOrphanCleanupJob   bool `json:"enable_orphan_cleanup_job"`    // Remove files behind deleted media on this instance
+	EnableReactivationJob    bool `json:"enable_reactivation_job"`      // Restore temporarily deactivated accounts on schedule on this instance
+	EnableAbuseScoreJob      bool `json:"enable_abuse_score_job"`       // Recompute moderator abuse scores on this instance
+	EnableMediaTieringJob    bool `json:"enable_media_tiering_job"`     // Move unread media originals to cold storage on this instance
+	EnableStrikeExpiryJob    bool `json:"enable_strike_expiry_job"`     // Expire warning strikes and lift strike suspensions on this instance
+	EnableRelatedHashtagsJob bool `json:"enable_related_hashtags_job"`  // Recompute related hashtags daily on this instance
+	EnableRetentionJob       bool `json:"enable_retention_job"`         // Delete data past its retention period nightly on this instance
+	EnableSavedSearchAlerts  bool `json:"enable_saved_search_alerts"`   // Rerun saved searches and notify users of new matches on this instance
+	EnablePostAutoDeleteJob  bool `json:"enable_post_auto_delete_job"`  // Delete posts past their authors' auto-delete period on this instance
+	EnablePostOrphanCheckJob bool `json:"enable_post_orphan_check_job"` // Finish deleting the comments, likes, mentions and reports of deleted posts on this instance
+	EnablePowChallenge       bool `json:"enable_pow_challenge"`         // Let route groups demand proof-of-work when risk is elevated
+	EnableDigestJob          bool `json:"enable_digest_job"`            // Email activity digests to inactive users from this instance
 }
 
 // ExternalConfig contains external service configuration
@@ -237,25 +369,296 @@ type MonitoringConfig struct {
 	TracingSampleRate float64 `json:"tracing_sample_rate"`
 }
 
+// TranslationConfig contains machine translation configuration
+type TranslationConfig struct {
+	Provider      string        `json:"provider"` // noop, libretranslate
+	URL           string        `json:"url"`
+	APIKey        string        `json:"api_key"`
+	Timeout       time.Duration `json:"timeout"`
+	MaxTextLength int           `json:"max_text_length"`
+}
+
+// MessagingConfig contains conversation limits
+type MessagingConfig struct {
+	MaxConversationParticipants int `json:"max_conversation_participants"`
+
+	PresenceOnlineWindow time.Duration `json:"presence_online_window"` // Activity this recent counts as online without a WebSocket
+	PresenceAwayWindow   time.Duration `json:"presence_away_window"`   // Activity this recent counts as away
+	PresenceDebounce     time.Duration `json:"presence_debounce"`      // How long a transition must hold before it is announced
+	PresenceIndexTTL     time.Duration `json:"presence_index_ttl"`     // How long cached conversation membership is trusted
+
+	ActivityWindow   time.Duration `json:"activity_window"`    // Messages this recent rank the most_active inbox sort
+	ActivityCacheTTL time.Duration `json:"activity_cache_ttl"` // How long a conversation's activity count is cached
+
+	MediaAllowedTypes        []string `json:"media_allowed_types"`         // MIME types messages may attach; "image/*" allows a whole family
+	MaxMediaPerMessage       int      `json:"max_media_per_message"`       // Attachments per message
+	MaxMessageMediaSize      int64    `json:"max_message_media_size"`      // Bytes of media per message
+	MaxConversationMediaSize int64    `json:"max_conversation_media_size"` // Bytes of media one sender may send a conversation in any 24 hours; 0 disables
+}
+
+// ModerationConfig contains comment spam hold and duplicate post thresholds
+type ModerationConfig struct {
+	CommentHoldMinAccountAge       time.Duration `json:"comment_hold_min_account_age"`
+	CommentHoldMinApprovedComments int           `json:"comment_hold_min_approved_comments"`
+	CommentStrikeLimit             int           `json:"comment_strike_limit"`       // 0 disables auto-restriction
+	QuickReplyLimitPerPost         int           `json:"quick_reply_limit_per_post"` // Quick replies one user may leave on a post
+
+	// Comment flood limits: comments one user may leave per CommentRateWindow
+	// anywhere and on a single post, with higher limits for verified accounts.
+	// 0 disables a limit.
+	CommentRateWindow               time.Duration `json:"comment_rate_window"`
+	CommentRateLimit                int           `json:"comment_rate_limit"`
+	CommentRateLimitPerPost         int           `json:"comment_rate_limit_per_post"`
+	CommentRateLimitVerified        int           `json:"comment_rate_limit_verified"`
+	CommentRateLimitPerPostVerified int           `json:"comment_rate_limit_per_post_verified"`
+	CommentDuplicateWindow          time.Duration `json:"comment_duplicate_window"`     // 0 disables repeated comment detection
+	CommentDuplicateMinLength       int           `json:"comment_duplicate_min_length"` // Shorter comments may be repeated
+
+	DuplicatePostWindow        time.Duration `json:"duplicate_post_window"`         // 0 disables duplicate detection
+	DuplicatePostMinLength     int           `json:"duplicate_post_min_length"`     // Shorter posts are never treated as duplicates
+	DuplicatePostExemptPhrases []string      `json:"duplicate_post_exempt_phrases"` // Common phrases anyone may repeat
+	CoordinatedSpamAccounts    int           `json:"coordinated_spam_accounts"`     // Accounts posting the same content before it's reported
+
+	AbuseScoreWindow              time.Duration `json:"abuse_score_window"`   // How far back the abuse score looks
+	AbuseScoreInterval            time.Duration `json:"abuse_score_interval"` // How often the abuse score job recomputes scores
+	AbuseScoreReportWeight        float64       `json:"abuse_score_report_weight"`
+	AbuseScoreFollowChurnWeight   float64       `json:"abuse_score_follow_churn_weight"`
+	AbuseScoreDuplicateWeight     float64       `json:"abuse_score_duplicate_weight"`
+	AbuseScoreMessageReportWeight float64       `json:"abuse_score_message_report_weight"`
+
+	WarningSuspendStrikes  int           `json:"warning_suspend_strikes"`  // Active strikes that suspend temporarily, 0 disables
+	WarningSuspendDuration time.Duration `json:"warning_suspend_duration"` // How long a strike suspension lasts
+	WarningBanStrikes      int           `json:"warning_ban_strikes"`      // Active strikes that suspend permanently, 0 disables
+	WarningStrikeExpiry    time.Duration `json:"warning_strike_expiry"`    // How long a strike counts, 0 means forever
+
+	LinkBlocklistDomains  []string      `json:"link_blocklist_domains"`   // Domains seeded onto the link blocklist, "*.example.com" covers subdomains
+	LinkBlocklistCacheTTL time.Duration `json:"link_blocklist_cache_ttl"` // How long each instance caches the blocklist
+	LinkResolveShorteners bool          `json:"link_resolve_shorteners"`  // Follow shortened links to screen where they lead
+	LinkShortenerDomains  []string      `json:"link_shortener_domains"`
+	LinkResolveTimeout    time.Duration `json:"link_resolve_timeout"`
+
+	// Account maturity: how old an account must be, and whether it needs a
+	// verified email, before each gated action. 0 and false leave it open.
+	MaturityMessageStrangersMinAge       time.Duration `json:"maturity_message_strangers_min_age"`
+	MaturityMessageStrangersRequireEmail bool          `json:"maturity_message_strangers_require_email"`
+	MaturityReportMinAge                 time.Duration `json:"maturity_report_min_age"`
+	MaturityReportRequireEmail           bool          `json:"maturity_report_require_email"`
+	MaturityCreateGroupMinAge            time.Duration `json:"maturity_create_group_min_age"`
+	MaturityCreateGroupRequireEmail      bool          `json:"maturity_create_group_require_email"`
+	MaturityExemptVerified               bool          `json:"maturity_exempt_verified"` // Verified badge skips the gates
+	MaturityExemptPremium                bool          `json:"maturity_exempt_premium"`  // Active premium skips the gates
+}
+
+// InsightsConfig contains creator audience insight eligibility and cost limits
+type InsightsConfig struct {
+	AudienceMinFollowers int  `json:"audience_min_followers"`
+	AudienceForPremium   bool `json:"audience_for_premium"` // Premium accounts qualify regardless of follower count
+	AudienceSampleSize   int  `json:"audience_sample_size"` // Larger audiences are sampled down to this many followers
+}
+
+// RetentionConfig holds the default retention period of each data category
+// and the limits of the nightly retention worker. Admins can override the
+// periods in the settings store.
+type RetentionConfig struct {
+	UserSessionsDays         int `json:"user_sessions_days"`
+	ContentEngagementsDays   int `json:"content_engagements_days"`
+	RecommendationEventsDays int `json:"recommendation_events_days"`
+	FeedCacheGraceHours      int `json:"feed_cache_grace_hours"` // Kept this long past expires_at
+	UserJourneysDays         int `json:"user_journeys_days"`
+	NotificationsDays        int `json:"notifications_days"`
+	AuditLogsDays            int `json:"audit_logs_days"`
+	ResolvedReportsDays      int `json:"resolved_reports_days"`
+	LoginHistoryDays         int `json:"login_history_days"`
+	IdempotencyKeysDays      int `json:"idempotency_keys_days"`
+	StatusSamplesDays        int `json:"status_samples_days"`
+	FeedAlgorithmLogsDays    int `json:"feed_algorithm_logs_days"`
+
+	// A run leaves a collection alone rather than delete more than this
+	// fraction of it, unless the collection holds fewer than
+	// SafetyMinDocuments documents
+	MaxDeleteFraction  float64 `json:"max_delete_fraction"`
+	SafetyMinDocuments int64   `json:"safety_min_documents"`
+	BatchSize          int     `json:"batch_size"`
+}
+
+// GroupsConfig contains group resource library storage limits and member notification defaults
+type GroupsConfig struct {
+	LibraryQuotaBytes        int64 `json:"library_quota_bytes"`         // Storage ceiling for a group's file library
+	PremiumLibraryQuotaBytes int64 `json:"premium_library_quota_bytes"` // Ceiling for premium groups
+	HighlightsThreshold      int64 `json:"highlights_threshold"`        // Members joining a group this large default to highlights-only notifications
+}
+
+// BoostConfig contains boosted post delivery settings
+type BoostConfig struct {
+	DefaultDailyFrequencyCap int      `json:"default_daily_frequency_cap"` // Deliveries per user per campaign per day unless the campaign sets its own
+	AdFreePlans              []string `json:"ad_free_plans"`               // Subscription plans that never receive boosted posts
+}
+
+// FeedConfig contains feed algorithm and feed section settings
+type FeedConfig struct {
+	DefaultAlgorithm string `json:"default_algorithm"` // Serves users who haven't picked an algorithm: chronological, standard, behavior or hybrid
+
+	NetworkTrendingWindow      time.Duration `json:"network_trending_window"`       // How far back engagement from followed accounts counts
+	NetworkTrendingMinEngagers int           `json:"network_trending_min_engagers"` // Followed accounts that must engage with a post before it trends
+	NetworkTrendingLimit       int           `json:"network_trending_limit"`        // Posts in the section; 0 disables it
+	NetworkTrendingCacheTTL    time.Duration `json:"network_trending_cache_ttl"`    // How long each user's section is cached
+}
+
+// FeedInjectionConfig contains settings for injecting non-followed posts
+// into home feeds
+type FeedInjectionConfig struct {
+	Interval      int           `json:"interval"`       // Every Nth feed item is injected; 0 disables injection
+	Slots         []string      `json:"slots"`          // "recommended" and "sponsored", in the order injected slots take turns
+	SessionWindow time.Duration `json:"session_window"` // An injected post isn't injected again for the user within this window
+}
+
+// LimitsConfig contains per-tier limit settings. The limits themselves are
+// edited by admins and stored in the database.
+type LimitsConfig struct {
+	CacheTTL time.Duration `json:"cache_ttl"` // How long tier limits are cached before an admin change is picked up
+}
+
+// MediaTieringConfig controls moving rarely read media originals to cheaper storage
+type MediaTieringConfig struct {
+	ColdAfter          time.Duration `json:"cold_after"`            // Originals unread this long move to the cold tier
+	ColdProvider       string        `json:"cold_provider"`         // local or s3
+	ColdPath           string        `json:"cold_path"`             // Archive directory for the local cold tier
+	ColdS3StorageClass string        `json:"cold_s3_storage_class"` // Storage class for the S3 cold tier
+	HotCategories      []string      `json:"hot_categories"`        // Media categories that always stay hot
+	RestoreRequests    int           `json:"restore_requests"`      // Requests for a cold original in one day that bring it back to hot
+	HotCostPerGBMonth  float64       `json:"hot_cost_per_gb_month"`
+	ColdCostPerGBMonth float64       `json:"cold_cost_per_gb_month"`
+}
+
+// StatusConfig controls the uptime recorder behind the public status page
+type StatusConfig struct {
+	SampleInterval time.Duration `json:"sample_interval"` // How often each instance records API and database samples
+	SlowThreshold  time.Duration `json:"slow_threshold"`  // Database pings slower than this count as degraded
+	CacheTTL       time.Duration `json:"cache_ttl"`       // How long the public status page is served from memory
+	WebhookURL     string        `json:"webhook_url"`     // Receives incident changes posted with notify; empty disables
+}
+
+// ChallengeConfig configures the adaptive proof-of-work challenge. The
+// thresholds are the rates at which a route group is considered at risk;
+// each doubling past a threshold raises the risk level by one.
+type ChallengeConfig struct {
+	Secret               string        `json:"-"`                      // Signs challenges; defaults to the JWT secret
+	TTL                  time.Duration `json:"ttl"`                    // How long a challenge can be solved
+	BaseDifficulty       int           `json:"base_difficulty"`        // Leading zero bits at risk level 1
+	MaxDifficulty        int           `json:"max_difficulty"`         // Leading zero bits never exceeded
+	TrustedAccountAge    time.Duration `json:"trusted_account_age"`    // Accounts this old skip challenges
+	SignupThreshold      int64         `json:"signup_threshold"`       // Signups per 10 minutes
+	FailedLoginThreshold int64         `json:"failed_login_threshold"` // Failed logins per minute on this instance
+	PerIPThreshold       int64         `json:"per_ip_threshold"`       // Writes per minute from one IP to one route group
+	SettingsTTL          time.Duration `json:"settings_ttl"`           // How long admin overrides are cached
+}
+
+// AnalyticsExportConfig configures the admin behavior analytics exports
+type AnalyticsExportConfig struct {
+	Dir string        `json:"dir"` // Where export jobs write their files
+	TTL time.Duration `json:"ttl"` // How long finished exports can be downloaded
+}
+
+// HashtagsConfig configures automatic hashtag categorization. A hashtag gets
+// the category of the keyword it equals, else of the longest keyword it
+// starts or ends with, else "general".
+type HashtagsConfig struct {
+	CategoryKeywords map[string][]string `json:"category_keywords"` // Category -> keywords
+	StopWords        []string            `json:"stop_words"`        // Words never parsed as hashtags
+}
+
+// SavedSearchConfig contains saved search limits and alert settings
+type SavedSearchConfig struct {
+	MaxPerUser    int           `json:"max_per_user"`   // Saved searches one user may keep
+	AlertInterval time.Duration `json:"alert_interval"` // How often saved searches with alerts are rerun for new posts
+}
+
+// CommentsConfig contains comment thread settings
+type CommentsConfig struct {
+	ReplyPreview int `json:"reply_preview"` // Replies listed under each top-level comment; the rest load on demand
+}
+
+// BulkDeleteConfig caps how much of their own content users can delete in
+// one request
+type BulkDeleteConfig struct {
+	MaxIDs    int           `json:"max_ids"`    // IDs one request may list
+	MaxItems  int64         `json:"max_items"`  // Items one request may delete
+	SyncLimit int64         `json:"sync_limit"` // Larger deletions run as a background job
+	JobTTL    time.Duration `json:"job_ttl"`    // How long job records are kept for status checks
+}
+
+// MediaOutputConfig controls the variants produced for image uploads. Every
+// variant is written as JPEG, which all clients display, and in each of the
+// modern formats listed.
+type MediaOutputConfig struct {
+	Formats       []string       `json:"formats"`        // Modern formats produced next to the JPEG fallback: webp, avif, or none
+	Quality       map[string]int `json:"quality"`        // Encoding quality (1-100) per variant: thumbnail, small, medium, large
+	KeepOriginal  bool           `json:"keep_original"`  // When false, JPEG originals are replaced by the large variant
+	CwebpPath     string         `json:"cwebp_path"`     // cwebp binary used for WebP
+	AvifencPath   string         `json:"avifenc_path"`   // avifenc binary used for AVIF
+	EncodeTimeout time.Duration  `json:"encode_timeout"` // Bounds one encoder run
+
+	// Longest edge of stored image originals; larger uploads are downscaled (0 disables)
+	MaxResolution      int  `json:"max_resolution"`
+	KeepFullResolution bool `json:"keep_full_resolution"` // Downscale only the variant source, not the stored original
+}
+
+// LinkPreviewConfig controls the link previews fetched for post drafts
+type LinkPreviewConfig struct {
+	Enabled  bool          `json:"enabled"`
+	MaxLinks int           `json:"max_links"` // Links previewed per draft; the rest are listed unfetched
+	Timeout  time.Duration `json:"timeout"`   // Bounds fetching one page
+	MaxBytes int64         `json:"max_bytes"` // Page bytes read looking for preview tags
+	CacheTTL time.Duration `json:"cache_ttl"` // How long each instance caches a fetched preview
+}
+
+// AdminQueryConfig bounds the cost of admin and analytics database queries
+type AdminQueryConfig struct {
+	MaxTime           time.Duration `json:"max_time"`            // Server-side time limit (maxTimeMS) per query
+	MaxResults        int           `json:"max_results"`         // Documents one query may return before the result is truncated
+	AnalyticsMaxRange int           `json:"analytics_max_range"` // Widest analytics date range in days; longer periods go through exports
+}
+
 // Global config instance
 var AppConfig *Config
 
 // Load loads configuration from environment variables
 func Load() *Config {
 	config := &Config{
-		Server:      loadServerConfig(),
-		Database:    loadDatabaseConfig(),
-		Redis:       loadRedisConfig(),
-		JWT:         loadJWTConfig(),
-		Email:       loadEmailConfig(),
-		Upload:      loadUploadConfig(),
-		AWS:         loadAWSConfig(),
-		RateLimit:   loadRateLimitConfig(),
-		Security:    loadSecurityConfig(),
-		Features:    loadFeatureFlags(),
-		External:    loadExternalConfig(),
-		Monitoring:  loadMonitoringConfig(),
-		Environment: getEnv("ENVIRONMENT", "development"),
+		Server:          loadServerConfig(),
+		Database:        loadDatabaseConfig(),
+		Redis:           loadRedisConfig(),
+		JWT:             loadJWTConfig(),
+		Email:           loadEmailConfig(),
+		Upload:          loadUploadConfig(),
+		AWS:             loadAWSConfig(),
+		RateLimit:       loadRateLimitConfig(),
+		Security:        loadSecurityConfig(),
+		Features:        loadFeatureFlags(),
+		External:        loadExternalConfig(),
+		Monitoring:      loadMonitoringConfig(),
+		Translation:     loadTranslationConfig(),
+		Messaging:       loadMessagingConfig(),
+		Moderation:      loadModerationConfig(),
+		Insights:        loadInsightsConfig(),
+		Retention:       loadRetentionConfig(),
+		Groups:          loadGroupsConfig(),
+		AdminQueries:    loadAdminQueryConfig(),
+		Boosts:          loadBoostConfig(),
+		FeedInjection:   loadFeedInjectionConfig(),
+		Feed:            loadFeedConfig(),
+		Limits:          loadLimitsConfig(),
+		MediaTiering:    loadMediaTieringConfig(),
+		Status:          loadStatusConfig(),
+		Challenge:       loadChallengeConfig(),
+		AnalyticsExport: loadAnalyticsExportConfig(),
+		Hashtags:        loadHashtagsConfig(),
+		SavedSearches:   loadSavedSearchConfig(),
+		Comments:        loadCommentsConfig(),
+		BulkDelete:      loadBulkDeleteConfig(),
+		MediaOutput:     loadMediaOutputConfig(),
+		LinkPreview:     loadLinkPreviewConfig(),
+		Environment:     getEnv("ENVIRONMENT", "development"),
 	}
 
 	AppConfig = config
@@ -273,6 +676,8 @@ func loadServerConfig() ServerConfig {
 		ShutdownTimeout: getEnvDuration("SERVER_SHUTDOWN_TIMEOUT", 5*time.Second),
 		MaxRequestSize:  getEnvInt64("MAX_REQUEST_SIZE", 32<<20), // 32MB
 		TrustedProxies:  getEnvStringSlice("TRUSTED_PROXIES", []string{}),
+		DefaultPageSize: getEnvInt("DEFAULT_PAGE_SIZE", 20),
+		MaxPageSize:     getEnvInt("MAX_PAGE_SIZE", 100),
 	}
 }
 
@@ -286,6 +691,10 @@ func loadDatabaseConfig() DatabaseConfig {
 		MaxConnIdleTime: getEnvDuration("MONGO_MAX_CONN_IDLE_TIME", 30*time.Minute),
 		ConnectTimeout:  getEnvDuration("MONGO_CONNECT_TIMEOUT", 10*time.Second),
 		ServerTimeout:   getEnvDuration("MONGO_SERVER_TIMEOUT", 10*time.Second),
+
+		ReadRoutingEnabled:   getEnvBool("MONGO_READ_ROUTING_ENABLED", false),
+		ReadMaxStaleness:     getEnvDuration("MONGO_READ_MAX_STALENESS", 90*time.Second),
+		ReadYourWritesWindow: getEnvDuration("MONGO_READ_YOUR_WRITES_WINDOW", 90*time.Second),
 	}
 }
 
@@ -316,14 +725,56 @@ func loadRedisConfig() RedisConfig {
 
 // loadJWTConfig loads JWT configuration
 func loadJWTConfig() JWTConfig {
-	return JWTConfig{
+	jwtConfig := JWTConfig{
 		SecretKey:            getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
 		RefreshSecretKey:     getEnv("JWT_REFRESH_SECRET", "your-refresh-secret-key-change-in-production"),
 		AccessTokenDuration:  getEnvDuration("JWT_ACCESS_DURATION", 24*time.Hour),
 		RefreshTokenDuration: getEnvDuration("JWT_REFRESH_DURATION", 30*24*time.Hour),
 		Issuer:               getEnv("JWT_ISSUER", "social-media-api"),
 		Algorithm:            getEnv("JWT_ALGORITHM", "HS256"),
+
+		DelegatedTokenDuration: getEnvDuration("JWT_DELEGATED_DURATION", time.Hour),
+		DelegationCacheTTL:     getEnvDuration("DELEGATION_CACHE_TTL", 30*time.Second),
+
+		ImpersonationTokenDuration: getEnvDuration("JWT_IMPERSONATION_DURATION", 30*time.Minute),
+
+		SlidingWindow: getEnvDuration("JWT_SLIDING_WINDOW", 0),
+		MaxSessionAge: getEnvDuration("JWT_SESSION_MAX_AGE", 0),
 	}
+	jwtConfig.RoleSessions = loadRoleSessionConfigs(SessionConfig{
+		AccessTokenDuration: jwtConfig.AccessTokenDuration,
+		SlidingWindow:       jwtConfig.SlidingWindow,
+		MaxSessionAge:       jwtConfig.MaxSessionAge,
+	})
+	return jwtConfig
+}
+
+// loadRoleSessionConfigs loads the roles that override the session
+// settings, e.g. JWT_ADMIN_SESSION_MAX_AGE. Whatever a role leaves unset
+// falls back to the defaults.
+func loadRoleSessionConfigs(defaults SessionConfig) map[string]SessionConfig {
+	roles := make(map[string]SessionConfig)
+	for _, role := range []string{"user", "moderator", "admin", "super_admin"} {
+		prefix := "JWT_" + strings.ToUpper(role) + "_"
+		keys := []string{prefix + "ACCESS_DURATION", prefix + "SLIDING_WINDOW", prefix + "SESSION_MAX_AGE"}
+
+		overridden := false
+		for _, key := range keys {
+			if os.Getenv(key) != "" {
+				overridden = true
+			}
+		}
+		if !overridden {
+			continue
+		}
+
+		roles[role] = SessionConfig{
+			AccessTokenDuration: getEnvDuration(keys[0], defaults.AccessTokenDuration),
+			SlidingWindow:       getEnvDuration(keys[1], defaults.SlidingWindow),
+			MaxSessionAge:       getEnvDuration(keys[2], defaults.MaxSessionAge),
+		}
+	}
+	return roles
 }
 
 // loadEmailConfig loads email configuration
@@ -338,6 +789,15 @@ func loadEmailConfig() EmailConfig {
 		ReplyTo:      getEnv("REPLY_TO_EMAIL", ""),
 		UseTLS:       getEnvBool("SMTP_USE_TLS", true),
 		UseSSL:       getEnvBool("SMTP_USE_SSL", false),
+
+		RateLimitWindow:       getEnvDuration("EMAIL_RATE_LIMIT_WINDOW", 1*time.Hour),
+		RateLimitPerRecipient: int64(getEnvInt("EMAIL_RATE_LIMIT_PER_RECIPIENT", 10)),
+		RateLimitGlobal:       int64(getEnvInt("EMAIL_RATE_LIMIT_GLOBAL", 5000)),
+		UnsubscribeSecret:     getEnv("EMAIL_UNSUBSCRIBE_SECRET", ""),
+
+		DigestCadence:       getEnvDuration("EMAIL_DIGEST_CADENCE", 7*24*time.Hour),
+		DigestInactiveAfter: getEnvDuration("EMAIL_DIGEST_INACTIVE_AFTER", 72*time.Hour),
+		DigestTopPosts:      getEnvInt("EMAIL_DIGEST_TOP_POSTS", 3),
 	}
 }
 
@@ -354,6 +814,12 @@ func loadUploadConfig() UploadConfig {
 		TempPath:        getEnv("TEMP_PATH", "./temp"),
 		UseS3:           getEnvBool("USE_S3", false),
 		LocalURL:        getEnv("LOCAL_UPLOAD_URL", "http://localhost:8080/uploads"),
+
+		RequireImageAltText: getEnvBool("REQUIRE_IMAGE_ALT_TEXT", false),
+
+		VirusScanEnabled: getEnvBool("VIRUS_SCAN_ENABLED", false),
+		ClamAVAddress:    getEnv("CLAMAV_ADDRESS", "localhost:3310"),
+		VirusScanTimeout: getEnvDuration("VIRUS_SCAN_TIMEOUT", 30*time.Second),
 	}
 }
 
@@ -408,6 +874,8 @@ func loadSecurityConfig() SecurityConfig {
 		EnableHTTPS:          getEnvBool("ENABLE_HTTPS", false),
 		HSTSEnabled:          getEnvBool("HSTS_ENABLED", false),
 		HSTSMaxAge:           getEnvInt("HSTS_MAX_AGE", 31536000), // 1 year
+
+		ImpersonationNotifyUser: getEnv("IMPERSONATION_NOTIFY_USER", "always"),
 	}
 }
 
@@ -428,6 +896,20 @@ func loadFeatureFlags() FeatureFlags {
 		EnableFileUploads:        getEnvBool("ENABLE_FILE_UPLOADS", true),
 		EnableVideoUploads:       getEnvBool("ENABLE_VIDEO_UPLOADS", true),
 		EnableAudioUploads:       getEnvBool("ENABLE_AUDIO_UPLOADS", true),
+		EnableSuggestionJob:      getEnvBool("ENABLE_SUGGESTION_JOB", true),
+		EnableAudienceJob:        getEnvBool("ENABLE_AUDIENCE_JOB", true),
+		EnableOrphanCleanupJob:   getEnvBool("ENABLE_ORPHAN_CLEANUP_JOB", true),
+		EnableReactivationJob:    getEnvBool("ENABLE_REACTIVATION_JOB", true),
+		EnableAbuseScoreJob:      getEnvBool("ENABLE_ABUSE_SCORE_JOB", true),
+		EnableMediaTieringJob:    getEnvBool("ENABLE_MEDIA_TIERING_JOB", true),
+		EnableStrikeExpiryJob:    getEnvBool("ENABLE_STRIKE_EXPIRY_JOB", true),
+		EnableRelatedHashtagsJob: getEnvBool("ENABLE_RELATED_HASHTAGS_JOB", true),
+		EnableRetentionJob:       getEnvBool("ENABLE_RETENTION_JOB", true),
+		EnableSavedSearchAlerts:  getEnvBool("ENABLE_SAVED_SEARCH_ALERTS", true),
+		EnablePostAutoDeleteJob:  getEnvBool("ENABLE_POST_AUTO_DELETE_JOB", true),
+		EnablePostOrphanCheckJob: getEnvBool("ENABLE_POST_ORPHAN_CHECK_JOB", true),
+		EnablePowChallenge:       getEnvBool("ENABLE_POW_CHALLENGE", true),
+		EnableDigestJob:          getEnvBool("ENABLE_DIGEST_JOB", true),
 	}
 }
 
@@ -471,6 +953,305 @@ func loadMonitoringConfig() MonitoringConfig {
 	}
 }
 
+// loadTranslationConfig loads machine translation configuration
+func loadTranslationConfig() TranslationConfig {
+	return TranslationConfig{
+		Provider:      getEnv("TRANSLATION_PROVIDER", "noop"),
+		URL:           getEnv("TRANSLATION_URL", ""),
+		APIKey:        getEnv("TRANSLATION_API_KEY", ""),
+		Timeout:       getEnvDuration("TRANSLATION_TIMEOUT", 10*time.Second),
+		MaxTextLength: getEnvInt("TRANSLATION_MAX_TEXT_LENGTH", 5000),
+	}
+}
+
+// loadMessagingConfig loads conversation limits
+func loadMessagingConfig() MessagingConfig {
+	return MessagingConfig{
+		MaxConversationParticipants: getEnvInt("MAX_CONVERSATION_PARTICIPANTS", 256),
+		PresenceOnlineWindow:        getEnvDuration("PRESENCE_ONLINE_WINDOW", 5*time.Minute),
+		PresenceAwayWindow:          getEnvDuration("PRESENCE_AWAY_WINDOW", 30*time.Minute),
+		PresenceDebounce:            getEnvDuration("PRESENCE_DEBOUNCE", 10*time.Second),
+		PresenceIndexTTL:            getEnvDuration("PRESENCE_INDEX_TTL", 10*time.Minute),
+		ActivityWindow:              getEnvDuration("CONVERSATION_ACTIVITY_WINDOW", 24*time.Hour),
+		ActivityCacheTTL:            getEnvDuration("CONVERSATION_ACTIVITY_CACHE_TTL", time.Minute),
+		MediaAllowedTypes: getEnvStringSlice("MESSAGE_MEDIA_ALLOWED_TYPES", []string{
+			"image/jpeg", "image/png", "image/gif", "image/webp",
+			"video/mp4", "video/quicktime", "video/webm",
+			"audio/mpeg", "audio/mp4", "audio/aac", "audio/ogg", "audio/wav",
+			"application/pdf", "text/plain",
+		}),
+		MaxMediaPerMessage:       getEnvInt("MESSAGE_MAX_MEDIA", 10),
+		MaxMessageMediaSize:      getEnvInt64("MESSAGE_MAX_MEDIA_SIZE", 100<<20),    // 100MB
+		MaxConversationMediaSize: getEnvInt64("CONVERSATION_MAX_MEDIA_SIZE", 1<<30), // 1GB
+	}
+}
+
+// loadModerationConfig loads comment spam hold and duplicate post thresholds
+func loadModerationConfig() ModerationConfig {
+	return ModerationConfig{
+		CommentHoldMinAccountAge:       getEnvDuration("COMMENT_HOLD_MIN_ACCOUNT_AGE", 72*time.Hour),
+		CommentHoldMinApprovedComments: getEnvInt("COMMENT_HOLD_MIN_APPROVED_COMMENTS", 3),
+		CommentStrikeLimit:             getEnvInt("COMMENT_STRIKE_LIMIT", 3),
+		QuickReplyLimitPerPost:         getEnvInt("QUICK_REPLY_LIMIT_PER_POST", 10),
+		DuplicatePostWindow:            getEnvDuration("DUPLICATE_POST_WINDOW", 24*time.Hour),
+		DuplicatePostMinLength:         getEnvInt("DUPLICATE_POST_MIN_LENGTH", 25),
+		DuplicatePostExemptPhrases:     getEnvStringSlice("DUPLICATE_POST_EXEMPT_PHRASES", nil),
+		CoordinatedSpamAccounts:        getEnvInt("COORDINATED_SPAM_ACCOUNTS", 5),
+		AbuseScoreWindow:               getEnvDuration("ABUSE_SCORE_WINDOW", 30*24*time.Hour),
+		AbuseScoreInterval:             getEnvDuration("ABUSE_SCORE_INTERVAL", time.Hour),
+		AbuseScoreReportWeight:         getEnvFloat64("ABUSE_SCORE_REPORT_WEIGHT", 10),
+		AbuseScoreFollowChurnWeight:    getEnvFloat64("ABUSE_SCORE_FOLLOW_CHURN_WEIGHT", 0.5),
+		AbuseScoreDuplicateWeight:      getEnvFloat64("ABUSE_SCORE_DUPLICATE_WEIGHT", 5),
+		AbuseScoreMessageReportWeight:  getEnvFloat64("ABUSE_SCORE_MESSAGE_REPORT_WEIGHT", 8),
+		WarningSuspendStrikes:          getEnvInt("WARNING_SUSPEND_STRIKES", 3),
+		WarningSuspendDuration:         getEnvDuration("WARNING_SUSPEND_DURATION", 7*24*time.Hour),
+		WarningBanStrikes:              getEnvInt("WARNING_BAN_STRIKES", 5),
+		WarningStrikeExpiry:            getEnvDuration("WARNING_STRIKE_EXPIRY", 90*24*time.Hour),
+		LinkBlocklistDomains:           getEnvStringSlice("LINK_BLOCKLIST_DOMAINS", nil),
+		LinkBlocklistCacheTTL:          getEnvDuration("LINK_BLOCKLIST_CACHE_TTL", time.Minute),
+		LinkResolveShorteners:          getEnvBool("LINK_RESOLVE_SHORTENERS", false),
+		LinkShortenerDomains: getEnvStringSlice("LINK_SHORTENER_DOMAINS", []string{
+			"bit.ly", "tinyurl.com", "t.co", "goo.gl", "ow.ly", "is.gd", "buff.ly", "cutt.ly", "rebrand.ly", "shorturl.at",
+		}),
+		LinkResolveTimeout: getEnvDuration("LINK_RESOLVE_TIMEOUT", 3*time.Second),
+
+		CommentRateWindow:               getEnvDuration("COMMENT_RATE_WINDOW", 10*time.Minute),
+		CommentRateLimit:                getEnvInt("COMMENT_RATE_LIMIT", 30),
+		CommentRateLimitPerPost:         getEnvInt("COMMENT_RATE_LIMIT_PER_POST", 10),
+		CommentRateLimitVerified:        getEnvInt("COMMENT_RATE_LIMIT_VERIFIED", 90),
+		CommentRateLimitPerPostVerified: getEnvInt("COMMENT_RATE_LIMIT_PER_POST_VERIFIED", 30),
+		CommentDuplicateWindow:          getEnvDuration("COMMENT_DUPLICATE_WINDOW", time.Hour),
+		CommentDuplicateMinLength:       getEnvInt("COMMENT_DUPLICATE_MIN_LENGTH", 10),
+
+		MaturityMessageStrangersMinAge:       getEnvDuration("ACCOUNT_MATURITY_MESSAGE_STRANGERS_MIN_AGE", 24*time.Hour),
+		MaturityMessageStrangersRequireEmail: getEnvBool("ACCOUNT_MATURITY_MESSAGE_STRANGERS_REQUIRE_EMAIL", false),
+		MaturityReportMinAge:                 getEnvDuration("ACCOUNT_MATURITY_REPORT_MIN_AGE", time.Hour),
+		MaturityReportRequireEmail:           getEnvBool("ACCOUNT_MATURITY_REPORT_REQUIRE_EMAIL", false),
+		MaturityCreateGroupMinAge:            getEnvDuration("ACCOUNT_MATURITY_CREATE_GROUP_MIN_AGE", 72*time.Hour),
+		MaturityCreateGroupRequireEmail:      getEnvBool("ACCOUNT_MATURITY_CREATE_GROUP_REQUIRE_EMAIL", true),
+		MaturityExemptVerified:               getEnvBool("ACCOUNT_MATURITY_EXEMPT_VERIFIED", true),
+		MaturityExemptPremium:                getEnvBool("ACCOUNT_MATURITY_EXEMPT_PREMIUM", true),
+	}
+}
+
+// loadInsightsConfig loads creator audience insight settings
+func loadInsightsConfig() InsightsConfig {
+	return InsightsConfig{
+		AudienceMinFollowers: getEnvInt("AUDIENCE_INSIGHTS_MIN_FOLLOWERS", 100),
+		AudienceForPremium:   getEnvBool("AUDIENCE_INSIGHTS_FOR_PREMIUM", true),
+		AudienceSampleSize:   getEnvInt("AUDIENCE_INSIGHTS_SAMPLE_SIZE", 5000),
+	}
+}
+
+// loadRetentionConfig loads default retention periods and worker limits
+func loadRetentionConfig() RetentionConfig {
+	return RetentionConfig{
+		UserSessionsDays:         getEnvInt("RETENTION_USER_SESSIONS_DAYS", 90),
+		ContentEngagementsDays:   getEnvInt("RETENTION_CONTENT_ENGAGEMENTS_DAYS", 90),
+		RecommendationEventsDays: getEnvInt("RETENTION_RECOMMENDATION_EVENTS_DAYS", 180),
+		FeedCacheGraceHours:      getEnvInt("RETENTION_FEED_CACHE_GRACE_HOURS", 0),
+		UserJourneysDays:         getEnvInt("RETENTION_USER_JOURNEYS_DAYS", 30),
+		NotificationsDays:        getEnvInt("RETENTION_NOTIFICATIONS_DAYS", 180),
+		AuditLogsDays:            getEnvInt("RETENTION_AUDIT_LOGS_DAYS", 730),
+		ResolvedReportsDays:      getEnvInt("RETENTION_RESOLVED_REPORTS_DAYS", 365),
+		LoginHistoryDays:         getEnvInt("RETENTION_LOGIN_HISTORY_DAYS", 90),
+		IdempotencyKeysDays:      getEnvInt("RETENTION_IDEMPOTENCY_KEYS_DAYS", 1),
+		StatusSamplesDays:        getEnvInt("RETENTION_STATUS_SAMPLES_DAYS", 90), // The status page reports 90 days of history
+		FeedAlgorithmLogsDays:    getEnvInt("RETENTION_FEED_ALGORITHM_LOGS_DAYS", 90),
+		MaxDeleteFraction:        getEnvFloat64("RETENTION_MAX_DELETE_FRACTION", 0.25),
+		SafetyMinDocuments:       getEnvInt64("RETENTION_SAFETY_MIN_DOCUMENTS", 1000),
+		BatchSize:                getEnvInt("RETENTION_BATCH_SIZE", 1000),
+	}
+}
+
+// loadGroupsConfig loads group library storage limits and member notification defaults
+func loadGroupsConfig() GroupsConfig {
+	return GroupsConfig{
+		LibraryQuotaBytes:        getEnvInt64("GROUP_LIBRARY_QUOTA_BYTES", 1<<30),          // 1GB
+		PremiumLibraryQuotaBytes: getEnvInt64("GROUP_LIBRARY_PREMIUM_QUOTA_BYTES", 10<<30), // 10GB
+		HighlightsThreshold:      getEnvInt64("GROUP_NOTIFICATION_HIGHLIGHTS_THRESHOLD", 1000),
+	}
+}
+
+// loadAdminQueryConfig loads admin and analytics query limits
+func loadAdminQueryConfig() AdminQueryConfig {
+	return AdminQueryConfig{
+		MaxTime:           getEnvDuration("ADMIN_QUERY_MAX_TIME", 10*time.Second),
+		MaxResults:        getEnvInt("ADMIN_QUERY_MAX_RESULTS", 1000),
+		AnalyticsMaxRange: getEnvInt("ADMIN_ANALYTICS_MAX_RANGE_DAYS", 365),
+	}
+}
+
+// loadBoostConfig loads boosted post delivery settings
+func loadBoostConfig() BoostConfig {
+	return BoostConfig{
+		DefaultDailyFrequencyCap: getEnvInt("BOOST_DEFAULT_DAILY_FREQUENCY_CAP", 2),
+		AdFreePlans:              getEnvStringSlice("BOOST_AD_FREE_PLANS", []string{"premium"}),
+	}
+}
+
+// loadFeedInjectionConfig loads feed injection settings
+func loadFeedInjectionConfig() FeedInjectionConfig {
+	return FeedInjectionConfig{
+		Interval:      getEnvInt("FEED_INJECTION_INTERVAL", 5),
+		Slots:         getEnvStringSlice("FEED_INJECTION_SLOTS", []string{"recommended", "sponsored"}),
+		SessionWindow: getEnvDuration("FEED_INJECTION_SESSION_WINDOW", 30*time.Minute),
+	}
+}
+
+// loadFeedConfig loads feed algorithm and feed section settings
+func loadFeedConfig() FeedConfig {
+	return FeedConfig{
+		DefaultAlgorithm: getEnv("FEED_DEFAULT_ALGORITHM", "standard"),
+
+		NetworkTrendingWindow:      getEnvDuration("FEED_NETWORK_TRENDING_WINDOW", 48*time.Hour),
+		NetworkTrendingMinEngagers: getEnvInt("FEED_NETWORK_TRENDING_MIN_ENGAGERS", 2),
+		NetworkTrendingLimit:       getEnvInt("FEED_NETWORK_TRENDING_LIMIT", 20),
+		NetworkTrendingCacheTTL:    getEnvDuration("FEED_NETWORK_TRENDING_CACHE_TTL", 10*time.Minute),
+	}
+}
+
+// loadLimitsConfig loads per-tier limit settings
+func loadLimitsConfig() LimitsConfig {
+	return LimitsConfig{
+		CacheTTL: getEnvDuration("LIMITS_CACHE_TTL", time.Minute),
+	}
+}
+
+// loadMediaTieringConfig loads media storage tiering settings
+func loadMediaTieringConfig() MediaTieringConfig {
+	return MediaTieringConfig{
+		ColdAfter:          time.Duration(getEnvInt("MEDIA_COLD_AFTER_DAYS", 90)) * 24 * time.Hour,
+		ColdProvider:       getEnv("MEDIA_COLD_PROVIDER", "local"),
+		ColdPath:           getEnv("MEDIA_COLD_PATH", "./uploads-archive"),
+		ColdS3StorageClass: getEnv("MEDIA_COLD_S3_STORAGE_CLASS", "GLACIER_IR"),
+		HotCategories:      getEnvStringSlice("MEDIA_HOT_CATEGORIES", []string{"profile", "cover"}),
+		RestoreRequests:    getEnvInt("MEDIA_COLD_RESTORE_REQUESTS", 20),
+		HotCostPerGBMonth:  getEnvFloat64("MEDIA_HOT_COST_PER_GB_MONTH", 0.023),
+		ColdCostPerGBMonth: getEnvFloat64("MEDIA_COLD_COST_PER_GB_MONTH", 0.004),
+	}
+}
+
+// loadMediaOutputConfig loads image variant format and quality settings
+func loadMediaOutputConfig() MediaOutputConfig {
+	return MediaOutputConfig{
+		Formats: getEnvStringSlice("MEDIA_OUTPUT_FORMATS", []string{"webp"}),
+		Quality: map[string]int{
+			"thumbnail": getEnvInt("MEDIA_QUALITY_THUMBNAIL", 70),
+			"small":     getEnvInt("MEDIA_QUALITY_SMALL", 75),
+			"medium":    getEnvInt("MEDIA_QUALITY_MEDIUM", 80),
+			"large":     getEnvInt("MEDIA_QUALITY_LARGE", 82),
+		},
+		KeepOriginal:  getEnvBool("MEDIA_KEEP_ORIGINAL", true),
+		CwebpPath:     getEnv("MEDIA_CWEBP_PATH", "cwebp"),
+		AvifencPath:   getEnv("MEDIA_AVIFENC_PATH", "avifenc"),
+		EncodeTimeout: getEnvDuration("MEDIA_ENCODE_TIMEOUT", 30*time.Second),
+
+		MaxResolution:      getEnvInt("MEDIA_MAX_RESOLUTION", 2048),
+		KeepFullResolution: getEnvBool("MEDIA_KEEP_FULL_RESOLUTION", false),
+	}
+}
+
+// loadStatusConfig loads status page recorder settings
+func loadStatusConfig() StatusConfig {
+	return StatusConfig{
+		SampleInterval: getEnvDuration("STATUS_SAMPLE_INTERVAL", time.Minute),
+		SlowThreshold:  getEnvDuration("STATUS_SLOW_THRESHOLD", 500*time.Millisecond),
+		CacheTTL:       getEnvDuration("STATUS_CACHE_TTL", time.Minute),
+		WebhookURL:     getEnv("STATUS_WEBHOOK_URL", ""),
+	}
+}
+
+// loadChallengeConfig loads proof-of-work challenge configuration
+func loadChallengeConfig() ChallengeConfig {
+	return ChallengeConfig{
+		Secret:               getEnv("POW_CHALLENGE_SECRET", ""),
+		TTL:                  getEnvDuration("POW_CHALLENGE_TTL", 2*time.Minute),
+		BaseDifficulty:       getEnvInt("POW_BASE_DIFFICULTY", 16),
+		MaxDifficulty:        getEnvInt("POW_MAX_DIFFICULTY", 22),
+		TrustedAccountAge:    getEnvDuration("POW_TRUSTED_ACCOUNT_AGE", 30*24*time.Hour),
+		SignupThreshold:      int64(getEnvInt("POW_SIGNUP_THRESHOLD", 100)),
+		FailedLoginThreshold: int64(getEnvInt("POW_FAILED_LOGIN_THRESHOLD", 30)),
+		PerIPThreshold:       int64(getEnvInt("POW_PER_IP_THRESHOLD", 10)),
+		SettingsTTL:          getEnvDuration("POW_SETTINGS_TTL", 10*time.Second),
+	}
+}
+
+// loadAnalyticsExportConfig loads analytics export configuration
+func loadAnalyticsExportConfig() AnalyticsExportConfig {
+	return AnalyticsExportConfig{
+		Dir: getEnv("ANALYTICS_EXPORT_DIR", "./exports/analytics"),
+		TTL: getEnvDuration("ANALYTICS_EXPORT_TTL", 7*24*time.Hour),
+	}
+}
+
+// loadSavedSearchConfig loads saved search settings
+func loadSavedSearchConfig() SavedSearchConfig {
+	return SavedSearchConfig{
+		MaxPerUser:    getEnvInt("SAVED_SEARCH_MAX_PER_USER", 25),
+		AlertInterval: getEnvDuration("SAVED_SEARCH_ALERT_INTERVAL", time.Hour),
+	}
+}
+
+// loadCommentsConfig loads comment thread settings
+func loadCommentsConfig() CommentsConfig {
+	return CommentsConfig{
+		ReplyPreview: getEnvInt("COMMENT_REPLY_PREVIEW", 3),
+	}
+}
+
+// loadBulkDeleteConfig loads the caps on bulk deleting one's own content
+func loadBulkDeleteConfig() BulkDeleteConfig {
+	return BulkDeleteConfig{
+		MaxIDs:    getEnvInt("BULK_DELETE_MAX_IDS", 100),
+		MaxItems:  int64(getEnvInt("BULK_DELETE_MAX_ITEMS", 5000)),
+		SyncLimit: int64(getEnvInt("BULK_DELETE_SYNC_LIMIT", 50)),
+		JobTTL:    getEnvDuration("BULK_DELETE_JOB_TTL", 7*24*time.Hour),
+	}
+}
+
+// loadLinkPreviewConfig loads link preview fetching settings
+func loadLinkPreviewConfig() LinkPreviewConfig {
+	return LinkPreviewConfig{
+		Enabled:  getEnvBool("LINK_PREVIEW_ENABLED", true),
+		MaxLinks: getEnvInt("LINK_PREVIEW_MAX_LINKS", 3),
+		Timeout:  getEnvDuration("LINK_PREVIEW_TIMEOUT", 3*time.Second),
+		MaxBytes: int64(getEnvInt("LINK_PREVIEW_MAX_BYTES", 512*1024)),
+		CacheTTL: getEnvDuration("LINK_PREVIEW_CACHE_TTL", time.Hour),
+	}
+}
+
+// loadHashtagsConfig loads hashtag categorization configuration
+func loadHashtagsConfig() HashtagsConfig {
+	return HashtagsConfig{
+		CategoryKeywords: getEnvKeywordMap("HASHTAG_CATEGORY_KEYWORDS", map[string][]string{
+			"entertainment": {"movie", "film", "tv", "netflix", "celebrity", "series", "cinema", "comedy"},
+			"sports":        {"sport", "football", "soccer", "nba", "nfl", "basketball", "tennis", "cricket", "worldcup", "olympics"},
+			"news":          {"news", "breaking", "headline", "update"},
+			"technology":    {"tech", "ai", "coding", "programming", "developer", "golang", "startup", "software", "gadget"},
+			"business":      {"business", "marketing", "entrepreneur", "finance", "invest", "crypto", "stocks"},
+			"lifestyle":     {"lifestyle", "selfcare", "mood", "motivation", "inspiration", "home"},
+			"travel":        {"travel", "vacation", "wanderlust", "trip", "beach", "adventure"},
+			"food":          {"food", "recipe", "cooking", "foodie", "vegan", "dinner", "coffee", "baking"},
+			"fashion":       {"fashion", "style", "ootd", "outfit", "beauty", "makeup"},
+			"art":           {"art", "drawing", "painting", "design", "illustration"},
+			"music":         {"music", "song", "concert", "album", "hiphop", "jazz", "rock"},
+			"health":        {"health", "wellness", "mentalhealth", "nutrition", "yoga"},
+			"education":     {"education", "learning", "study", "school", "university", "teacher"},
+			"politics":      {"politics", "election", "vote", "government", "policy"},
+			"science":       {"science", "space", "physics", "biology", "research", "nasa"},
+			"nature":        {"nature", "wildlife", "garden", "climate", "sunset", "forest"},
+			"photography":   {"photo", "photography", "camera", "portrait"},
+			"fitness":       {"fitness", "gym", "workout", "running", "training"},
+			"gaming":        {"gaming", "gamer", "game", "esports", "xbox", "playstation", "nintendo"},
+		}),
+		StopWords: getEnvStringSlice("HASHTAG_STOP_WORDS", []string{
+			"a", "an", "and", "are", "as", "at", "be", "but", "by", "for", "if", "in",
+			"is", "it", "of", "on", "or", "so", "the", "to", "was", "we", "with",
+		}),
+	}
+}
+
 // getEnvInt gets environment variable as integer with default value
 func getEnvInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
@@ -545,6 +1326,32 @@ func getEnvStringSlice(key string, defaultValue []string) []string {
 	return defaultValue
 }
 
+// getEnvKeywordMap gets environment variable as a keyword map with default
+// value. The format is "key:word|word,key:word"; when set it replaces the
+// default map entirely.
+func getEnvKeywordMap(key string, defaultValue map[string][]string) map[string][]string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	keywords := make(map[string][]string)
+	for _, entry := range strings.Split(value, ",") {
+		name, words, ok := strings.Cut(entry, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			log.Printf("Warning: Invalid entry in %s: %q, ignoring it", key, entry)
+			continue
+		}
+		for _, word := range strings.Split(words, "|") {
+			if word = strings.TrimSpace(word); word != "" {
+				keywords[name] = append(keywords[name], word)
+			}
+		}
+	}
+	return keywords
+}
+
 // Validate validates the configuration
 func (c *Config) Validate() error {
 	if c.JWT.SecretKey == "your-secret-key-change-in-production" {
@@ -559,6 +1366,249 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("database URI is required")
 	}
 
+	sessions := map[string]SessionConfig{"default": {
+		AccessTokenDuration: c.JWT.AccessTokenDuration,
+		SlidingWindow:       c.JWT.SlidingWindow,
+		MaxSessionAge:       c.JWT.MaxSessionAge,
+	}}
+	for role, session := range c.JWT.RoleSessions {
+		sessions[role] = session
+	}
+	for role, session := range sessions {
+		if session.AccessTokenDuration < time.Minute {
+			return fmt.Errorf("the %s access token duration must be at least 1m", role)
+		}
+		if session.SlidingWindow < 0 || session.SlidingWindow >= session.AccessTokenDuration {
+			return fmt.Errorf("the %s sliding window must be shorter than its access token duration", role)
+		}
+		if session.MaxSessionAge < 0 {
+			return fmt.Errorf("the %s session max age must not be negative", role)
+		}
+		// Without an absolute limit a sliding session could be extended forever
+		if session.SlidingWindow > 0 && session.MaxSessionAge == 0 {
+			return fmt.Errorf("the %s sliding window needs a session max age", role)
+		}
+	}
+
+	if c.JWT.ImpersonationTokenDuration < time.Minute || c.JWT.ImpersonationTokenDuration > 4*time.Hour {
+		return fmt.Errorf("JWT_IMPERSONATION_DURATION must be between 1m and 4h")
+	}
+	switch c.Security.ImpersonationNotifyUser {
+	case "always", "never", "optional":
+	default:
+		return fmt.Errorf("IMPERSONATION_NOTIFY_USER must be always, never or optional")
+	}
+
+	if c.Email.RateLimitPerRecipient < 0 || c.Email.RateLimitGlobal < 0 {
+		return fmt.Errorf("EMAIL_RATE_LIMIT_PER_RECIPIENT and EMAIL_RATE_LIMIT_GLOBAL must not be negative")
+	}
+	if (c.Email.RateLimitPerRecipient > 0 || c.Email.RateLimitGlobal > 0) && c.Email.RateLimitWindow <= 0 {
+		return fmt.Errorf("EMAIL_RATE_LIMIT_WINDOW must be positive when an email rate limit is set")
+	}
+	if c.Email.DigestCadence < 24*time.Hour || c.Email.DigestInactiveAfter < 0 || c.Email.DigestTopPosts < 0 {
+		return fmt.Errorf("EMAIL_DIGEST_CADENCE must be at least 24h and EMAIL_DIGEST_INACTIVE_AFTER and EMAIL_DIGEST_TOP_POSTS must not be negative")
+	}
+
+	if c.Server.DefaultPageSize < 1 {
+		return fmt.Errorf("DEFAULT_PAGE_SIZE must be at least 1")
+	}
+	if c.Server.MaxPageSize < c.Server.DefaultPageSize {
+		return fmt.Errorf("MAX_PAGE_SIZE must be at least DEFAULT_PAGE_SIZE")
+	}
+	// MongoDB rejects a maxStalenessSeconds below 90, and a secondary can be
+	// that far behind, so the author's window must cover it
+	if c.Database.ReadRoutingEnabled {
+		if c.Database.ReadMaxStaleness < 90*time.Second {
+			return fmt.Errorf("MONGO_READ_MAX_STALENESS must be at least 90s")
+		}
+		if c.Database.ReadYourWritesWindow < c.Database.ReadMaxStaleness {
+			return fmt.Errorf("MONGO_READ_YOUR_WRITES_WINDOW must be at least MONGO_READ_MAX_STALENESS")
+		}
+	}
+
+	// Participants are stored on the conversation document, bounded at 500
+	if c.Messaging.MaxConversationParticipants < 2 || c.Messaging.MaxConversationParticipants > 500 {
+		return fmt.Errorf("MAX_CONVERSATION_PARTICIPANTS must be between 2 and 500")
+	}
+	if c.Messaging.PresenceOnlineWindow <= 0 || c.Messaging.PresenceAwayWindow < c.Messaging.PresenceOnlineWindow {
+		return fmt.Errorf("PRESENCE_ONLINE_WINDOW must be positive and no longer than PRESENCE_AWAY_WINDOW")
+	}
+	if c.Messaging.PresenceDebounce < 0 || c.Messaging.PresenceIndexTTL <= 0 {
+		return fmt.Errorf("PRESENCE_DEBOUNCE must not be negative and PRESENCE_INDEX_TTL must be positive")
+	}
+	if c.Messaging.ActivityWindow <= 0 || c.Messaging.ActivityCacheTTL < 0 {
+		return fmt.Errorf("CONVERSATION_ACTIVITY_WINDOW must be positive and CONVERSATION_ACTIVITY_CACHE_TTL must not be negative")
+	}
+	if c.Upload.VirusScanEnabled && (c.Upload.ClamAVAddress == "" || c.Upload.VirusScanTimeout <= 0) {
+		return fmt.Errorf("CLAMAV_ADDRESS and a positive VIRUS_SCAN_TIMEOUT are required when VIRUS_SCAN_ENABLED is set")
+	}
+
+	if len(c.Messaging.MediaAllowedTypes) == 0 {
+		return fmt.Errorf("MESSAGE_MEDIA_ALLOWED_TYPES must list at least one type")
+	}
+	if c.Messaging.MaxMediaPerMessage < 1 || c.Messaging.MaxMessageMediaSize <= 0 || c.Messaging.MaxConversationMediaSize < 0 {
+		return fmt.Errorf("MESSAGE_MAX_MEDIA and MESSAGE_MAX_MEDIA_SIZE must be positive and CONVERSATION_MAX_MEDIA_SIZE must not be negative")
+	}
+
+	if c.Moderation.CommentRateWindow <= 0 {
+		return fmt.Errorf("COMMENT_RATE_WINDOW must be positive")
+	}
+	if c.Moderation.CommentRateLimit < 0 || c.Moderation.CommentRateLimitPerPost < 0 ||
+		c.Moderation.CommentRateLimitVerified < 0 || c.Moderation.CommentRateLimitPerPostVerified < 0 {
+		return fmt.Errorf("COMMENT_RATE_LIMIT values must not be negative")
+	}
+	if c.Moderation.CommentDuplicateWindow < 0 {
+		return fmt.Errorf("COMMENT_DUPLICATE_WINDOW must not be negative")
+	}
+	if c.Moderation.DuplicatePostWindow < 0 {
+		return fmt.Errorf("DUPLICATE_POST_WINDOW must not be negative")
+	}
+	if c.Moderation.CoordinatedSpamAccounts < 2 {
+		return fmt.Errorf("COORDINATED_SPAM_ACCOUNTS must be at least 2")
+	}
+	if c.Moderation.AbuseScoreWindow <= 0 || c.Moderation.AbuseScoreInterval <= 0 {
+		return fmt.Errorf("ABUSE_SCORE_WINDOW and ABUSE_SCORE_INTERVAL must be positive")
+	}
+	if c.Moderation.AbuseScoreReportWeight < 0 || c.Moderation.AbuseScoreFollowChurnWeight < 0 ||
+		c.Moderation.AbuseScoreDuplicateWeight < 0 || c.Moderation.AbuseScoreMessageReportWeight < 0 {
+		return fmt.Errorf("ABUSE_SCORE_*_WEIGHT values must not be negative")
+	}
+	if c.Moderation.LinkResolveShorteners && c.Moderation.LinkResolveTimeout <= 0 {
+		return fmt.Errorf("LINK_RESOLVE_TIMEOUT must be positive when LINK_RESOLVE_SHORTENERS is on")
+	}
+	if c.Moderation.MaturityMessageStrangersMinAge < 0 || c.Moderation.MaturityReportMinAge < 0 || c.Moderation.MaturityCreateGroupMinAge < 0 {
+		return fmt.Errorf("ACCOUNT_MATURITY_*_MIN_AGE values must not be negative")
+	}
+
+	if c.Insights.AudienceSampleSize < 1 {
+		return fmt.Errorf("AUDIENCE_INSIGHTS_SAMPLE_SIZE must be at least 1")
+	}
+
+	if c.Retention.UserSessionsDays < 1 || c.Retention.ContentEngagementsDays < 1 || c.Retention.RecommendationEventsDays < 1 ||
+		c.Retention.UserJourneysDays < 1 || c.Retention.NotificationsDays < 1 || c.Retention.AuditLogsDays < 1 ||
+		c.Retention.ResolvedReportsDays < 1 || c.Retention.LoginHistoryDays < 1 || c.Retention.IdempotencyKeysDays < 1 ||
+		c.Retention.StatusSamplesDays < 1 || c.Retention.FeedAlgorithmLogsDays < 1 {
+		return fmt.Errorf("RETENTION_*_DAYS must be at least 1")
+	}
+	if c.Retention.MaxDeleteFraction <= 0 || c.Retention.MaxDeleteFraction > 1 {
+		return fmt.Errorf("RETENTION_MAX_DELETE_FRACTION must be greater than 0 and at most 1")
+	}
+	if c.Retention.SafetyMinDocuments < 0 {
+		return fmt.Errorf("RETENTION_SAFETY_MIN_DOCUMENTS must not be negative")
+	}
+	if c.Retention.BatchSize < 1 {
+		return fmt.Errorf("RETENTION_BATCH_SIZE must be at least 1")
+	}
+	if c.Retention.FeedCacheGraceHours < 0 {
+		return fmt.Errorf("RETENTION_FEED_CACHE_GRACE_HOURS must not be negative")
+	}
+	// Activity insights and audience heatmaps look back 30 days
+	if c.Retention.UserSessionsDays < 30 || c.Retention.ContentEngagementsDays < 30 {
+		log.Println("Warning: Behavior retention under 30 days leaves activity insights with partial data")
+	}
+
+	if c.Groups.LibraryQuotaBytes < 1 || c.Groups.PremiumLibraryQuotaBytes < c.Groups.LibraryQuotaBytes {
+		return fmt.Errorf("GROUP_LIBRARY_QUOTA_BYTES must be positive and no larger than GROUP_LIBRARY_PREMIUM_QUOTA_BYTES")
+	}
+
+	if c.Groups.HighlightsThreshold < 0 {
+		return fmt.Errorf("GROUP_NOTIFICATION_HIGHLIGHTS_THRESHOLD cannot be negative")
+	}
+
+	if c.AdminQueries.MaxTime <= 0 {
+		return fmt.Errorf("ADMIN_QUERY_MAX_TIME must be positive")
+	}
+	if c.AdminQueries.MaxResults < 1 || c.AdminQueries.AnalyticsMaxRange < 1 {
+		return fmt.Errorf("ADMIN_QUERY_MAX_RESULTS and ADMIN_ANALYTICS_MAX_RANGE_DAYS must be at least 1")
+	}
+
+	if c.Boosts.DefaultDailyFrequencyCap < 1 {
+		return fmt.Errorf("BOOST_DEFAULT_DAILY_FREQUENCY_CAP must be at least 1")
+	}
+
+	if c.FeedInjection.Interval != 0 && c.FeedInjection.Interval < 2 {
+		return fmt.Errorf("FEED_INJECTION_INTERVAL must be 0 or at least 2")
+	}
+	for _, slot := range c.FeedInjection.Slots {
+		if slot != "recommended" && slot != "sponsored" {
+			return fmt.Errorf("FEED_INJECTION_SLOTS may only list recommended and sponsored, got %q", slot)
+		}
+	}
+	if c.FeedInjection.SessionWindow <= 0 {
+		return fmt.Errorf("FEED_INJECTION_SESSION_WINDOW must be positive")
+	}
+
+	switch c.Feed.DefaultAlgorithm {
+	case "chronological", "standard", "behavior", "hybrid":
+	default:
+		return fmt.Errorf("FEED_DEFAULT_ALGORITHM must be chronological, standard, behavior or hybrid")
+	}
+	if c.Feed.NetworkTrendingLimit < 0 || c.Feed.NetworkTrendingCacheTTL < 0 {
+		return fmt.Errorf("FEED_NETWORK_TRENDING_LIMIT and FEED_NETWORK_TRENDING_CACHE_TTL must not be negative")
+	}
+	if c.Feed.NetworkTrendingLimit > 0 && (c.Feed.NetworkTrendingWindow <= 0 || c.Feed.NetworkTrendingMinEngagers < 1) {
+		return fmt.Errorf("FEED_NETWORK_TRENDING_WINDOW must be positive and FEED_NETWORK_TRENDING_MIN_ENGAGERS at least 1")
+	}
+
+	if c.SavedSearches.MaxPerUser < 1 {
+		return fmt.Errorf("SAVED_SEARCH_MAX_PER_USER must be at least 1")
+	}
+	if c.SavedSearches.AlertInterval < 5*time.Minute {
+		return fmt.Errorf("SAVED_SEARCH_ALERT_INTERVAL must be at least 5m")
+	}
+
+	if c.Comments.ReplyPreview < 0 || c.Comments.ReplyPreview > 20 {
+		return fmt.Errorf("COMMENT_REPLY_PREVIEW must be between 0 and 20")
+	}
+
+	if c.BulkDelete.MaxIDs < 1 || c.BulkDelete.MaxItems < 1 || c.BulkDelete.SyncLimit < 0 {
+		return fmt.Errorf("BULK_DELETE_MAX_IDS and BULK_DELETE_MAX_ITEMS must be at least 1 and BULK_DELETE_SYNC_LIMIT must not be negative")
+	}
+	if c.BulkDelete.JobTTL < time.Hour {
+		return fmt.Errorf("BULK_DELETE_JOB_TTL must be at least 1h")
+	}
+
+	for _, format := range c.MediaOutput.Formats {
+		if format != "webp" && format != "avif" && format != "none" {
+			return fmt.Errorf("MEDIA_OUTPUT_FORMATS may only list webp and avif, or be none")
+		}
+	}
+	for variant, quality := range c.MediaOutput.Quality {
+		if quality < 1 || quality > 100 {
+			return fmt.Errorf("MEDIA_QUALITY_%s must be between 1 and 100", strings.ToUpper(variant))
+		}
+	}
+	if c.MediaOutput.EncodeTimeout <= 0 {
+		return fmt.Errorf("MEDIA_ENCODE_TIMEOUT must be positive")
+	}
+	// Below the large variant the variants would be upscaled from the original
+	if c.MediaOutput.MaxResolution != 0 && c.MediaOutput.MaxResolution < 1600 {
+		return fmt.Errorf("MEDIA_MAX_RESOLUTION must be 0 or at least 1600")
+	}
+
+	if c.LinkPreview.Enabled {
+		if c.LinkPreview.MaxLinks < 1 || c.LinkPreview.MaxBytes < 1024 {
+			return fmt.Errorf("LINK_PREVIEW_MAX_LINKS must be at least 1 and LINK_PREVIEW_MAX_BYTES at least 1024")
+		}
+		if c.LinkPreview.Timeout <= 0 || c.LinkPreview.CacheTTL <= 0 {
+			return fmt.Errorf("LINK_PREVIEW_TIMEOUT and LINK_PREVIEW_CACHE_TTL must be positive")
+		}
+	}
+
+	if c.Limits.CacheTTL <= 0 {
+		return fmt.Errorf("LIMITS_CACHE_TTL must be positive")
+	}
+
+	if c.MediaTiering.ColdAfter < 24*time.Hour {
+		return fmt.Errorf("MEDIA_COLD_AFTER_DAYS must be at least 1")
+	}
+	if c.MediaTiering.ColdProvider != "local" && c.MediaTiering.ColdProvider != "s3" {
+		return fmt.Errorf("MEDIA_COLD_PROVIDER must be local or s3")
+	}
+	if c.MediaTiering.RestoreRequests < 1 {
+		return fmt.Errorf("MEDIA_COLD_RESTORE_REQUESTS must be at least 1")
+	}
+
 	if c.Environment == "production" {
 		if c.JWT.SecretKey == "your-secret-key-change-in-production" {
 			return fmt.Errorf("JWT secret key must be set in production")
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateSlidingWindowNeedsMaxSessionAge(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{
+			name: "sliding disabled",
+			env:  map[string]string{},
+		},
+		{
+			name:    "default sliding without max age",
+			env:     map[string]string{"JWT_SLIDING_WINDOW": "15m"},
+			wantErr: "default sliding window needs a session max age",
+		},
+		{
+			name: "default sliding with max age",
+			env:  map[string]string{"JWT_SLIDING_WINDOW": "15m", "JWT_SESSION_MAX_AGE": "720h"},
+		},
+		{
+			name:    "role sliding without max age",
+			env:     map[string]string{"JWT_ADMIN_SLIDING_WINDOW": "5m"},
+			wantErr: "admin sliding window needs a session max age",
+		},
+		{
+			name:    "role clears the inherited max age",
+			env:     map[string]string{"JWT_SLIDING_WINDOW": "15m", "JWT_SESSION_MAX_AGE": "720h", "JWT_MODERATOR_SESSION_MAX_AGE": "0"},
+			wantErr: "moderator sliding window needs a session max age",
+		},
+		{
+			name: "role sliding with its own max age",
+			env:  map[string]string{"JWT_ADMIN_SLIDING_WINDOW": "5m", "JWT_ADMIN_SESSION_MAX_AGE": "8h"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			err := Load().Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadRoleSessionConfigsInheritsDefaults(t *testing.T) {
+	t.Setenv("JWT_SLIDING_WINDOW", "15m")
+	t.Setenv("JWT_SESSION_MAX_AGE", "720h")
+	t.Setenv("JWT_ADMIN_SESSION_MAX_AGE", "8h")
+
+	jwt := loadJWTConfig()
+	admin, ok := jwt.RoleSessions["admin"]
+	if !ok {
+		t.Fatalf("RoleSessions has no admin entry: %v", jwt.RoleSessions)
+	}
+	if admin.MaxSessionAge != 8*time.Hour || admin.SlidingWindow != 15*time.Minute {
+		t.Errorf("admin session = %+v, want an 8h max age and the default 15m sliding window", admin)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sync"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -16,6 +17,10 @@ import (
 var (
 	DB     *mongo.Database
 	Client *mongo.Client
+
+	// transactionSupport caches, per client, whether the deployment accepts
+	// multi-document transactions
+	transactionSupport sync.Map
 )
 
 // InitDB initializes MongoDB Atlas connection with optimized settings
@@ -158,3 +163,45 @@ func Disconnect() {
 		Client.Disconnect(ctx)
 	}
 }
+
+// SupportsTransactions reports whether the deployment behind db is a replica
+// set or sharded cluster. Standalone servers reject multi-document transactions.
+func SupportsTransactions(ctx context.Context, db *mongo.Database) bool {
+	client := db.Client()
+	if cached, ok := transactionSupport.Load(client); ok {
+		return cached.(bool)
+	}
+
+	var hello bson.M
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		// Don't cache: the server may simply be unreachable right now
+		return false
+	}
+
+	_, isReplicaSet := hello["setName"]
+	isMongos := hello["msg"] == "isdbgrid"
+	supported := isReplicaSet || isMongos
+
+	transactionSupport.Store(client, supported)
+	return supported
+}
+
+// RunInTransaction executes fn inside a multi-document transaction when the
+// deployment supports it. On a standalone server fn runs directly against ctx
+// and the returned bool is false, so the caller can compensate on failure.
+func RunInTransaction(ctx context.Context, db *mongo.Database, fn func(ctx context.Context) error) (bool, error) {
+	if !SupportsTransactions(ctx, db) {
+		return false, fn(ctx)
+	}
+
+	session, err := db.Client().StartSession()
+	if err != nil {
+		return false, fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
+	return true, err
+}
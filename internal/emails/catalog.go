@@ -0,0 +1,172 @@
+// internal/emails/catalog.go
+package emails
+
+// Email types. Each has an HTML and a text template per language under
+// templates/<language>/<type>.{html,txt}; English is required for every type.
+const (
+	TypeWelcome            = "welcome"
+	TypeEmailVerification  = "email_verification"
+	TypePasswordReset      = "password_reset"
+	TypePasswordChanged    = "password_changed"
+	TypeNotification       = "notification"
+	TypeDigest             = "digest"
+	TypeExportReady        = "export_ready"
+	TypeAccountSuspended   = "account_suspended"
+	TypeAccountReactivated = "account_reactivated"
+	TypeBroadcast          = "broadcast"
+	TypeGroupInvite        = "group_invite"
+	TypeEventInvite        = "event_invite"
+	TypeEventReminder      = "event_reminder"
+	TypeSecurityAlert      = "security_alert"
+)
+
+// Spec describes one email type: the variables callers must pass when
+// sending it, and a sample payload used to validate its templates at startup
+// and to render admin previews
+type Spec struct {
+	Description string
+	Variables   []string
+	Sample      map[string]interface{}
+
+	// Unsubscribe adds the unsubscribe link to the footer, for emails users can opt out of
+	Unsubscribe bool
+}
+
+// DigestItem is one entry in a digest email
+type DigestItem struct {
+	Title   string
+	Summary string
+	URL     string
+}
+
+// catalog lists every email type the service sends
+var catalog = map[string]Spec{
+	TypeWelcome: {
+		Description: "Sent after registration, with the email verification link",
+		Variables:   []string{"Name", "VerificationToken"},
+		Sample: map[string]interface{}{
+			"Name":              "Alex",
+			"VerificationToken": "sample-verification-token",
+		},
+	},
+	TypeEmailVerification: {
+		Description: "Email address verification link",
+		Variables:   []string{"Name", "VerificationToken"},
+		Sample: map[string]interface{}{
+			"Name":              "Alex",
+			"VerificationToken": "sample-verification-token",
+		},
+	},
+	TypePasswordReset: {
+		Description: "Password reset link",
+		Variables:   []string{"Name", "ResetToken"},
+		Sample: map[string]interface{}{
+			"Name":       "Alex",
+			"ResetToken": "sample-reset-token",
+		},
+	},
+	TypePasswordChanged: {
+		Description: "Confirmation that the account password was changed",
+		Variables:   []string{"Name"},
+		Sample: map[string]interface{}{
+			"Name": "Alex",
+		},
+	},
+	TypeNotification: {
+		Description: "Email copy of an in-app notification",
+		Variables:   []string{"Title", "Message", "ActionText", "TargetURL"},
+		Sample: map[string]interface{}{
+			"Title":      "Sam commented on your post",
+			"Message":    "Sam: Great photo!",
+			"ActionText": "View comment",
+			"TargetURL":  "/posts/sample",
+		},
+		Unsubscribe: true,
+	},
+	TypeDigest: {
+		Description: "Daily or weekly summary of activity",
+		Variables:   []string{"Name", "Period", "Items"},
+		Sample: map[string]interface{}{
+			"Name":   "Alex",
+			"Period": "weekly",
+			"Items": []DigestItem{
+				{Title: "Sam posted for the first time in a while", Summary: "Back from the mountains!", URL: "/posts/sample-1"},
+				{Title: "Your post is trending", Summary: "42 people liked your photo", URL: "/posts/sample-2"},
+			},
+		},
+		Unsubscribe: true,
+	},
+	TypeExportReady: {
+		Description: "A requested data export is ready to download",
+		Variables:   []string{"Name", "DownloadURL", "ExpiresAt"},
+		Sample: map[string]interface{}{
+			"Name":        "Alex",
+			"DownloadURL": "https://example.com/exports/sample.zip",
+			"ExpiresAt":   "January 2, 2026 15:04 UTC",
+		},
+	},
+	TypeAccountSuspended: {
+		Description: "Notice that the account has been suspended",
+		Variables:   []string{"Name", "Reason"},
+		Sample: map[string]interface{}{
+			"Name":   "Alex",
+			"Reason": "Repeated spam reports",
+		},
+	},
+	TypeAccountReactivated: {
+		Description: "Welcome back when a temporarily deactivated account reactivates on schedule",
+		Variables:   []string{"Name"},
+		Sample: map[string]interface{}{
+			"Name": "Alex",
+		},
+	},
+	TypeBroadcast: {
+		Description: "Announcement sent by admins to many users",
+		Variables:   []string{"Name", "Title", "Message"},
+		Sample: map[string]interface{}{
+			"Name":    "Alex",
+			"Title":   "Scheduled maintenance",
+			"Message": "We'll be down for maintenance on Sunday from 02:00 to 03:00 UTC.",
+		},
+		Unsubscribe: true,
+	},
+	TypeGroupInvite: {
+		Description: "Invitation to join a group",
+		Variables:   []string{"Name", "InviterName", "GroupName", "GroupURL"},
+		Sample: map[string]interface{}{
+			"Name":        "Alex",
+			"InviterName": "Sam",
+			"GroupName":   "Weekend Hikers",
+			"GroupURL":    "/groups/sample",
+		},
+	},
+	TypeEventInvite: {
+		Description: "Invitation to an event",
+		Variables:   []string{"Name", "InviterName", "EventTitle", "EventURL"},
+		Sample: map[string]interface{}{
+			"Name":        "Alex",
+			"InviterName": "Sam",
+			"EventTitle":  "Summer Meetup",
+			"EventURL":    "/events/sample",
+		},
+	},
+	TypeEventReminder: {
+		Description: "Reminder for an upcoming event",
+		Variables:   []string{"Name", "EventTitle", "StartsAt", "EventURL"},
+		Sample: map[string]interface{}{
+			"Name":       "Alex",
+			"EventTitle": "Summer Meetup",
+			"StartsAt":   "January 2, 2026 15:04 UTC",
+			"EventURL":   "/events/sample",
+		},
+	},
+	TypeSecurityAlert: {
+		Description: "Security event on the account, such as a new login",
+		Variables:   []string{"Name", "AlertType", "Details"},
+		Sample: map[string]interface{}{
+			"Name":      "Alex",
+			"AlertType": "New login",
+			"Details":   "A new login from Chrome on Windows in Berlin, Germany.",
+		},
+	},
+}
@@ -0,0 +1,340 @@
+// internal/emails/registry.go
+package emails
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"social-media-api/internal/translation"
+)
+
+// DefaultLanguage is used when an email has no variant in the recipient's language
+const DefaultLanguage = "en"
+
+// Variables set by the registry for every email; callers don't pass them
+var sharedVariables = []string{"AppName", "AppURL", "SupportEmail", "Year", "UnsubscribeURL"}
+
+//go:embed templates
+var templateFiles embed.FS
+
+// Options configures a Registry
+type Options struct {
+	AppName      string
+	AppURL       string // Base URL of the web app, used for links
+	SupportEmail string
+
+	// Strict turns template and variable problems into errors instead of
+	// logging them and falling back. Use it in development so mistakes are
+	// caught before they reach production sends.
+	Strict bool
+}
+
+// Rendered is a rendered email ready to send
+type Rendered struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+// TypeInfo describes an email type for the admin template listing
+type TypeInfo struct {
+	Type        string   `json:"type"`
+	Description string   `json:"description"`
+	Variables   []string `json:"variables"`
+	Languages   []string `json:"languages"`
+}
+
+// variant holds the parsed templates of one email type in one language
+type variant struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+// Registry holds the parsed email templates, keyed by type and language
+type Registry struct {
+	options  Options
+	variants map[string]map[string]*variant
+}
+
+// NewRegistry parses the embedded templates and validates them by rendering
+// each with its type's sample payload. In strict mode any problem is
+// returned as an error. Otherwise broken variants are logged and left out,
+// so those emails fall back to English.
+func NewRegistry(options Options) (*Registry, error) {
+	r := &Registry{
+		options:  options,
+		variants: make(map[string]map[string]*variant),
+	}
+
+	var problems []error
+	report := func(err error) {
+		if options.Strict {
+			problems = append(problems, err)
+			return
+		}
+		log.Printf("Email templates: %v", err)
+	}
+
+	for emailType, spec := range catalog {
+		if err := spec.checkVariables(spec.Sample); err != nil {
+			report(fmt.Errorf("%s sample payload: %w", emailType, err))
+		}
+	}
+
+	languages, err := fs.ReadDir(templateFiles, "templates")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dir := range languages {
+		if !dir.IsDir() {
+			continue
+		}
+		language := dir.Name()
+
+		files, err := fs.ReadDir(templateFiles, path.Join("templates", language))
+		if err != nil {
+			return nil, err
+		}
+
+		emailTypes := make(map[string]bool)
+		for _, file := range files {
+			emailTypes[strings.TrimSuffix(file.Name(), path.Ext(file.Name()))] = true
+		}
+
+		for emailType := range emailTypes {
+			if emailType == "layout" {
+				continue
+			}
+
+			spec, ok := catalog[emailType]
+			if !ok {
+				report(fmt.Errorf("%s/%s: unknown email type", language, emailType))
+				continue
+			}
+
+			v, err := parseVariant(language, emailType)
+			if err == nil {
+				_, err = v.render(r.templateData(spec, spec.Sample, ""))
+			}
+			if err != nil {
+				report(fmt.Errorf("%s/%s: %w", language, emailType, err))
+				continue
+			}
+
+			if r.variants[emailType] == nil {
+				r.variants[emailType] = make(map[string]*variant)
+			}
+			r.variants[emailType][language] = v
+		}
+	}
+
+	for emailType := range catalog {
+		if r.variants[emailType][DefaultLanguage] == nil {
+			report(fmt.Errorf("%s: no valid %s template", emailType, DefaultLanguage))
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
+
+	return r, nil
+}
+
+// Render renders an email in the given language, falling back to English.
+// vars must hold exactly the variables declared for the email type.
+// unsubscribeURL is the recipient's own unsubscribe link for emails they can
+// opt out of; when empty those emails link to the notification settings.
+func (r *Registry) Render(emailType, language string, vars map[string]interface{}, unsubscribeURL string) (*Rendered, error) {
+	spec, ok := catalog[emailType]
+	if !ok {
+		return nil, fmt.Errorf("unknown email type %q", emailType)
+	}
+
+	if err := spec.checkVariables(vars); err != nil {
+		if r.options.Strict {
+			return nil, fmt.Errorf("%s: %w", emailType, err)
+		}
+		log.Printf("Email %s: %v", emailType, err)
+		vars = spec.fillMissing(vars)
+	}
+
+	return r.render(emailType, language, r.templateData(spec, vars, unsubscribeURL))
+}
+
+// Unsubscribable reports whether recipients can opt out of an email type.
+// Emails that can't be opted out of are transactional.
+func (r *Registry) Unsubscribable(emailType string) bool {
+	return catalog[emailType].Unsubscribe
+}
+
+// Preview renders an email with its sample payload, for review without sending
+func (r *Registry) Preview(emailType, language string) (*Rendered, error) {
+	spec, ok := catalog[emailType]
+	if !ok {
+		return nil, fmt.Errorf("unknown email type %q", emailType)
+	}
+
+	return r.render(emailType, language, r.templateData(spec, spec.Sample, ""))
+}
+
+// Types lists the email types with their variables and available languages
+func (r *Registry) Types() []TypeInfo {
+	types := make([]TypeInfo, 0, len(catalog))
+	for emailType, spec := range catalog {
+		languages := make([]string, 0, len(r.variants[emailType]))
+		for language := range r.variants[emailType] {
+			languages = append(languages, language)
+		}
+		sort.Strings(languages)
+
+		types = append(types, TypeInfo{
+			Type:        emailType,
+			Description: spec.Description,
+			Variables:   spec.Variables,
+			Languages:   languages,
+		})
+	}
+
+	sort.Slice(types, func(i, j int) bool { return types[i].Type < types[j].Type })
+	return types
+}
+
+// Helper methods
+
+func (r *Registry) render(emailType, language string, data map[string]interface{}) (*Rendered, error) {
+	language = translation.NormalizeLanguage(language)
+
+	if v := r.variants[emailType][language]; v != nil && language != DefaultLanguage {
+		rendered, err := v.render(data)
+		if err == nil || r.options.Strict {
+			return rendered, err
+		}
+		log.Printf("Email %s in %s failed to render, falling back to %s: %v", emailType, language, DefaultLanguage, err)
+	}
+
+	v := r.variants[emailType][DefaultLanguage]
+	if v == nil {
+		return nil, fmt.Errorf("no template for email type %q", emailType)
+	}
+	return v.render(data)
+}
+
+// templateData adds the shared variables to the caller's variables
+func (r *Registry) templateData(spec Spec, vars map[string]interface{}, unsubscribeURL string) map[string]interface{} {
+	data := make(map[string]interface{}, len(vars)+len(sharedVariables))
+	for key, value := range vars {
+		data[key] = value
+	}
+
+	data["AppName"] = r.options.AppName
+	data["AppURL"] = strings.TrimRight(r.options.AppURL, "/")
+	data["SupportEmail"] = r.options.SupportEmail
+	data["Year"] = time.Now().Year()
+	data["UnsubscribeURL"] = ""
+	if spec.Unsubscribe {
+		data["UnsubscribeURL"] = unsubscribeURL
+		if unsubscribeURL == "" {
+			data["UnsubscribeURL"] = data["AppURL"].(string) + "/settings/notifications"
+		}
+	}
+
+	return data
+}
+
+// parseVariant parses an email type's templates in one language, wrapped in
+// that language's layout or the shared one
+func parseVariant(language, emailType string) (*variant, error) {
+	html, err := htmltemplate.New("layout.html").Option("missingkey=error").ParseFS(templateFiles,
+		layoutPath(language, "layout.html"), path.Join("templates", language, emailType+".html"))
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := texttemplate.New("layout.txt").Option("missingkey=error").ParseFS(templateFiles,
+		layoutPath(language, "layout.txt"), path.Join("templates", language, emailType+".txt"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &variant{html: html, text: text}, nil
+}
+
+// layoutPath returns the language's own layout file if it has one
+func layoutPath(language, name string) string {
+	localized := path.Join("templates", language, name)
+	if _, err := fs.Stat(templateFiles, localized); err == nil {
+		return localized
+	}
+	return path.Join("templates", name)
+}
+
+func (v *variant) render(data map[string]interface{}) (*Rendered, error) {
+	var subject, text, html bytes.Buffer
+
+	if err := v.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+	if err := v.text.ExecuteTemplate(&text, "layout.txt", data); err != nil {
+		return nil, err
+	}
+	if err := v.html.ExecuteTemplate(&html, "layout.html", data); err != nil {
+		return nil, err
+	}
+
+	return &Rendered{
+		Subject: strings.TrimSpace(subject.String()),
+		HTML:    html.String(),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+	}, nil
+}
+
+// checkVariables reports variables that are missing from or not declared for the email type
+func (s Spec) checkVariables(vars map[string]interface{}) error {
+	declared := make(map[string]bool, len(s.Variables))
+	var missing, unexpected []string
+
+	for _, name := range s.Variables {
+		declared[name] = true
+		if _, ok := vars[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	for name := range vars {
+		if !declared[name] {
+			unexpected = append(unexpected, name)
+		}
+	}
+
+	if len(missing) == 0 && len(unexpected) == 0 {
+		return nil
+	}
+
+	sort.Strings(unexpected)
+	return fmt.Errorf("variables don't match the template: missing %v, unexpected %v", missing, unexpected)
+}
+
+// fillMissing returns vars with missing declared variables set to empty strings
+func (s Spec) fillMissing(vars map[string]interface{}) map[string]interface{} {
+	filled := make(map[string]interface{}, len(s.Variables))
+	for key, value := range vars {
+		filled[key] = value
+	}
+	for _, name := range s.Variables {
+		if _, ok := filled[name]; !ok {
+			filled[name] = ""
+		}
+	}
+	return filled
+}
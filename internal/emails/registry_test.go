@@ -0,0 +1,182 @@
+package emails
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+	"testing"
+)
+
+func newTestRegistry(t *testing.T, strict bool) *Registry {
+	t.Helper()
+
+	r, err := NewRegistry(Options{
+		AppName:      "Social Media",
+		AppURL:       "https://example.com/",
+		SupportEmail: "support@example.com",
+		Strict:       strict,
+	})
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	return r
+}
+
+// templateLanguages returns the languages with templates for an email type
+func templateLanguages(t *testing.T, emailType string) []string {
+	t.Helper()
+
+	dirs, err := fs.ReadDir(templateFiles, "templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var languages []string
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		if _, err := fs.Stat(templateFiles, path.Join("templates", dir.Name(), emailType+".html")); err == nil {
+			languages = append(languages, dir.Name())
+		}
+	}
+	return languages
+}
+
+// TestRenderEveryTemplate renders every email type in every language it has
+// with its sample payload, so a template referring to an undeclared or
+// misspelled variable fails here rather than on a production send
+func TestRenderEveryTemplate(t *testing.T) {
+	r := newTestRegistry(t, true)
+
+	for emailType, spec := range catalog {
+		languages := templateLanguages(t, emailType)
+		if len(languages) == 0 {
+			t.Errorf("%s has no templates", emailType)
+		}
+
+		for _, language := range languages {
+			t.Run(emailType+"/"+language, func(t *testing.T) {
+				rendered, err := r.Render(emailType, language, spec.Sample, "")
+				if err != nil {
+					t.Fatalf("Render: %v", err)
+				}
+				if rendered.Subject == "" || rendered.HTML == "" || strings.TrimSpace(rendered.Text) == "" {
+					t.Fatalf("rendered an empty part: %+v", rendered)
+				}
+
+				all := rendered.Subject + "\n" + rendered.Text + "\n" + rendered.HTML
+				if strings.Contains(all, "<no value>") {
+					t.Errorf("rendered output contains <no value>:\n%s", all)
+				}
+				for name, value := range spec.Sample {
+					if s, ok := value.(string); ok && !strings.Contains(strings.ToLower(rendered.Subject+rendered.Text), strings.ToLower(s)) {
+						t.Errorf("%s %q doesn't appear in the subject or text", name, s)
+					}
+				}
+				if !strings.Contains(rendered.Text, "support@example.com") || !strings.Contains(rendered.HTML, "support@example.com") {
+					t.Error("layout footer with the support contact is missing")
+				}
+
+				hasUnsubscribe := strings.Contains(rendered.Text, "https://example.com/settings/notifications")
+				if hasUnsubscribe != spec.Unsubscribe {
+					t.Errorf("unsubscribe link present = %v, want %v", hasUnsubscribe, spec.Unsubscribe)
+				}
+			})
+		}
+	}
+}
+
+func TestPreviewMatchesSampleRender(t *testing.T) {
+	r := newTestRegistry(t, true)
+
+	for _, info := range r.Types() {
+		preview, err := r.Preview(info.Type, DefaultLanguage)
+		if err != nil {
+			t.Errorf("Preview(%s): %v", info.Type, err)
+			continue
+		}
+		rendered, err := r.Render(info.Type, DefaultLanguage, catalog[info.Type].Sample, "")
+		if err != nil {
+			t.Errorf("Render(%s): %v", info.Type, err)
+			continue
+		}
+		if *preview != *rendered {
+			t.Errorf("Preview(%s) differs from rendering the sample payload", info.Type)
+		}
+	}
+}
+
+func TestRenderChecksVariables(t *testing.T) {
+	sample := catalog[TypePasswordReset].Sample
+	missing := map[string]interface{}{"Name": sample["Name"]}
+	unexpected := map[string]interface{}{"Name": sample["Name"], "ResetToken": sample["ResetToken"], "Extra": "x"}
+
+	strict := newTestRegistry(t, true)
+	if _, err := strict.Render(TypePasswordReset, "en", missing, ""); err == nil {
+		t.Error("strict Render with a missing variable succeeded")
+	}
+	if _, err := strict.Render(TypePasswordReset, "en", unexpected, ""); err == nil {
+		t.Error("strict Render with an unexpected variable succeeded")
+	}
+	if _, err := strict.Render("no_such_email", "en", nil, ""); err == nil {
+		t.Error("Render of an unknown email type succeeded")
+	}
+
+	lenient := newTestRegistry(t, false)
+	rendered, err := lenient.Render(TypePasswordReset, "en", missing, "")
+	if err != nil {
+		t.Fatalf("lenient Render with a missing variable: %v", err)
+	}
+	if strings.Contains(rendered.Text, "<no value>") {
+		t.Errorf("missing variable rendered as <no value>:\n%s", rendered.Text)
+	}
+}
+
+func TestRenderLanguageFallback(t *testing.T) {
+	r := newTestRegistry(t, true)
+	sample := catalog[TypePasswordReset].Sample
+
+	english, err := r.Render(TypePasswordReset, "en", sample, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spanish, err := r.Render(TypePasswordReset, "es", sample, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spanish.Subject == english.Subject {
+		t.Errorf("Spanish subject = %q, want a translation of %q", spanish.Subject, english.Subject)
+	}
+
+	for _, language := range []string{"es", "fr", ""} {
+		digest, err := r.Render(TypeDigest, language, catalog[TypeDigest].Sample, "")
+		if err != nil {
+			t.Fatalf("Render(digest, %q): %v", language, err)
+		}
+		englishDigest, _ := r.Render(TypeDigest, DefaultLanguage, catalog[TypeDigest].Sample, "")
+		if *digest != *englishDigest {
+			t.Errorf("digest in %q didn't fall back to English", language)
+		}
+	}
+}
+
+func TestRenderUsesRecipientUnsubscribeURL(t *testing.T) {
+	r := newTestRegistry(t, true)
+
+	rendered, err := r.Render(TypeDigest, "en", catalog[TypeDigest].Sample, "https://example.com/unsubscribe/abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(rendered.Text, "https://example.com/unsubscribe/abc") || !strings.Contains(rendered.HTML, "https://example.com/unsubscribe/abc") {
+		t.Error("recipient's unsubscribe link is missing")
+	}
+
+	transactional, err := r.Render(TypePasswordReset, "en", catalog[TypePasswordReset].Sample, "https://example.com/unsubscribe/abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(transactional.Text, "unsubscribe/abc") {
+		t.Error("transactional email has an unsubscribe link")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -15,23 +16,29 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"social-media-api/internal/emails"
 	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
 	"social-media-api/internal/services"
 	"social-media-api/internal/utils"
 )
 
 type AdminHandler struct {
-	adminService *services.AdminService
-	authService  *services.AuthService
-	db           *mongo.Database
-	upgrader     websocket.Upgrader
+	adminService  *services.AdminService
+	authService   *services.AuthService
+	emailService  *services.EmailService
+	reportService *services.ReportService
+	db            *mongo.Database
+	upgrader      websocket.Upgrader
 }
 
-func NewAdminHandler(adminService *services.AdminService, authService *services.AuthService, db *mongo.Database) *AdminHandler {
+func NewAdminHandler(adminService *services.AdminService, authService *services.AuthService, emailService *services.EmailService, reportService *services.ReportService, db *mongo.Database) *AdminHandler {
 	return &AdminHandler{
-		adminService: adminService,
-		authService:  authService,
-		db:           db,
+		adminService:  adminService,
+		authService:   authService,
+		emailService:  emailService,
+		reportService: reportService,
+		db:            db,
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				return true
@@ -44,7 +51,7 @@ func NewAdminHandler(adminService *services.AdminService, authService *services.
 func (h *AdminHandler) GetDashboard(c *gin.Context) {
 	stats, err := h.adminService.GetDashboardStats(c.Request.Context())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get dashboard statistics", err)
+		h.queryErrorResponse(c, "dashboard", "Failed to get dashboard statistics", err)
 		return
 	}
 	utils.OkResponse(c, "Dashboard statistics retrieved successfully", stats)
@@ -52,12 +59,23 @@ func (h *AdminHandler) GetDashboard(c *gin.Context) {
 
 // User Management
 func (h *AdminHandler) GetAllUsers(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	page, limit := params.Page, params.Limit
 
 	filter := services.UserFilter{
 		Search: c.Query("search"),
 		Role:   c.Query("role"),
+		SortBy: c.Query("sort_by"),
+	}
+
+	if minScore := c.Query("min_abuse_score"); minScore != "" {
+		if parsedScore, err := strconv.ParseFloat(minScore, 64); err == nil {
+			filter.MinAbuseScore = &parsedScore
+		}
 	}
 
 	if c.Query("is_verified") != "" {
@@ -89,7 +107,7 @@ func (h *AdminHandler) GetAllUsers(c *gin.Context) {
 
 	users, pagination, err := h.adminService.GetAllUsers(c.Request.Context(), filter, page, limit)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get users", err)
+		h.queryErrorResponse(c, "users.list", "Failed to get users", err)
 		return
 	}
 
@@ -104,13 +122,17 @@ func (h *AdminHandler) SearchUsers(c *gin.Context) {
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	page, limit := params.Page, params.Limit
 
 	filter := services.UserFilter{Search: query}
 	users, pagination, err := h.adminService.GetAllUsers(c.Request.Context(), filter, page, limit)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to search users", err)
+		h.queryErrorResponse(c, "users.search", "Failed to search users", err)
 		return
 	}
 
@@ -141,6 +163,7 @@ func (h *AdminHandler) GetUserStats(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 
 	// Get user basic info
 	var user models.User
@@ -151,58 +174,49 @@ func (h *AdminHandler) GetUserStats(c *gin.Context) {
 	}
 
 	// Get posts count
-	postsCount, _ := h.db.Collection("posts").CountDocuments(ctx, bson.M{
-		"user_id":    objID,
-		"deleted_at": bson.M{"$exists": false},
-	})
+	postsCount, _ := h.db.Collection("posts").CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"user_id": objID,
+	}), guard.CountOptions())
 
 	// Get comments count
-	commentsCount, _ := h.db.Collection("comments").CountDocuments(ctx, bson.M{
-		"user_id":    objID,
-		"deleted_at": bson.M{"$exists": false},
-	})
+	commentsCount, _ := h.db.Collection("comments").CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"user_id": objID,
+	}), guard.CountOptions())
 
 	// Get likes received
-	likesReceived, _ := h.db.Collection("likes").CountDocuments(ctx, bson.M{
+	likesReceived, _ := h.db.Collection("likes").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"target_user_id": objID,
-		"deleted_at":     bson.M{"$exists": false},
-	})
+	}), guard.CountOptions())
 
 	// Get followers count
-	followersCount, _ := h.db.Collection("follows").CountDocuments(ctx, bson.M{
+	followersCount, _ := h.db.Collection("follows").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"following_id": objID,
-		"deleted_at":   bson.M{"$exists": false},
-	})
+	}), guard.CountOptions())
 
 	// Get following count
-	followingCount, _ := h.db.Collection("follows").CountDocuments(ctx, bson.M{
+	followingCount, _ := h.db.Collection("follows").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"follower_id": objID,
-		"deleted_at":  bson.M{"$exists": false},
-	})
+	}), guard.CountOptions())
 
 	// Get stories count
-	storiesCount, _ := h.db.Collection("stories").CountDocuments(ctx, bson.M{
-		"user_id":    objID,
-		"deleted_at": bson.M{"$exists": false},
-	})
+	storiesCount, _ := h.db.Collection("stories").CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"user_id": objID,
+	}), guard.CountOptions())
 
 	// Get messages sent
-	messagesSent, _ := h.db.Collection("messages").CountDocuments(ctx, bson.M{
-		"sender_id":  objID,
-		"deleted_at": bson.M{"$exists": false},
-	})
+	messagesSent, _ := h.db.Collection("messages").CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"sender_id": objID,
+	}), guard.CountOptions())
 
 	// Get reports made
-	reportsMade, _ := h.db.Collection("reports").CountDocuments(ctx, bson.M{
+	reportsMade, _ := h.db.Collection("reports").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"reporter_id": objID,
-		"deleted_at":  bson.M{"$exists": false},
-	})
+	}), guard.CountOptions())
 
 	// Get reports against
-	reportsAgainst, _ := h.db.Collection("reports").CountDocuments(ctx, bson.M{
+	reportsAgainst, _ := h.db.Collection("reports").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"target_user_id": objID,
-		"deleted_at":     bson.M{"$exists": false},
-	})
+	}), guard.CountOptions())
 
 	stats := gin.H{
 		"user_id":         userID,
@@ -269,6 +283,35 @@ func (h *AdminHandler) VerifyUser(c *gin.Context) {
 	})
 }
 
+func (h *AdminHandler) SetOnboardingSuggested(c *gin.Context) {
+	userID := c.Param("id")
+
+	var req struct {
+		Suggested bool `json:"suggested"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	err := h.adminService.SetOnboardingSuggested(c.Request.Context(), userID, req.Suggested)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "User not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to update onboarding suggestion", err)
+		return
+	}
+
+	h.logAdminActivity(c, "user_onboarding_suggested", "Updated onboarding suggestion for user ID: "+userID)
+	utils.OkResponse(c, "Onboarding suggestion updated successfully", gin.H{
+		"user_id":                 userID,
+		"is_onboarding_suggested": req.Suggested,
+	})
+}
+
 func (h *AdminHandler) DeleteUser(c *gin.Context) {
 	userID := c.Param("id")
 
@@ -369,8 +412,12 @@ func (h *AdminHandler) ExportUsers(c *gin.Context) {
 
 // Post Management
 func (h *AdminHandler) GetAllPosts(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	page, limit := params.Page, params.Limit
 
 	filter := services.PostFilter{
 		UserID:     c.Query("user_id"),
@@ -403,7 +450,7 @@ func (h *AdminHandler) GetAllPosts(c *gin.Context) {
 
 	posts, pagination, err := h.adminService.GetAllPosts(c.Request.Context(), filter, page, limit)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get posts", err)
+		h.queryErrorResponse(c, "posts.list", "Failed to get posts", err)
 		return
 	}
 
@@ -418,13 +465,17 @@ func (h *AdminHandler) SearchPosts(c *gin.Context) {
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	page, limit := params.Page, params.Limit
 
 	filter := services.PostFilter{Search: query}
 	posts, pagination, err := h.adminService.GetAllPosts(c.Request.Context(), filter, page, limit)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to search posts", err)
+		h.queryErrorResponse(c, "posts.search", "Failed to search posts", err)
 		return
 	}
 
@@ -441,6 +492,7 @@ func (h *AdminHandler) GetPostStats(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 
 	// Get post basic info
 	var post models.Post
@@ -451,30 +503,26 @@ func (h *AdminHandler) GetPostStats(c *gin.Context) {
 	}
 
 	// Get likes count
-	likesCount, _ := h.db.Collection("likes").CountDocuments(ctx, bson.M{
+	likesCount, _ := h.db.Collection("likes").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"target_id":   objID,
 		"target_type": "post",
-		"deleted_at":  bson.M{"$exists": false},
-	})
+	}), guard.CountOptions())
 
 	// Get comments count
-	commentsCount, _ := h.db.Collection("comments").CountDocuments(ctx, bson.M{
-		"post_id":    objID,
-		"deleted_at": bson.M{"$exists": false},
-	})
+	commentsCount, _ := h.db.Collection("comments").CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"post_id": objID,
+	}), guard.CountOptions())
 
 	// Get shares count (if you have shares collection)
-	sharesCount, _ := h.db.Collection("shares").CountDocuments(ctx, bson.M{
-		"post_id":    objID,
-		"deleted_at": bson.M{"$exists": false},
-	})
+	sharesCount, _ := h.db.Collection("shares").CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"post_id": objID,
+	}), guard.CountOptions())
 
 	// Get reports count
-	reportsCount, _ := h.db.Collection("reports").CountDocuments(ctx, bson.M{
+	reportsCount, _ := h.db.Collection("reports").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"target_id":   objID,
 		"target_type": "post",
-		"deleted_at":  bson.M{"$exists": false},
-	})
+	}), guard.CountOptions())
 
 	stats := gin.H{
 		"post_id":        postID,
@@ -505,7 +553,7 @@ func (h *AdminHandler) HidePost(c *gin.Context) {
 		return
 	}
 
-	err := h.adminService.HidePost(c.Request.Context(), postID)
+	err := h.adminService.HidePost(c.Request.Context(), postID, actingAdminID(c))
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to hide post", err)
 		return
@@ -568,7 +616,7 @@ func (h *AdminHandler) BulkPostAction(c *gin.Context) {
 		var err error
 		switch req.Action {
 		case "hide":
-			err = h.adminService.HidePost(c.Request.Context(), postID)
+			err = h.adminService.HidePost(c.Request.Context(), postID, actingAdminID(c))
 		case "delete":
 			err = h.adminService.DeletePost(c.Request.Context(), postID)
 		default:
@@ -618,16 +666,22 @@ func (h *AdminHandler) ExportPosts(c *gin.Context) {
 
 // Fixed GetAllComments with proper ID transformation
 func (h *AdminHandler) GetAllComments(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	page, limit := params.Page, params.Limit
+	guard := h.adminService.QueryGuard()
+	if limit > guard.MaxResults() {
+		limit = guard.MaxResults()
+	}
 	skip := (page - 1) * limit
 
 	ctx := c.Request.Context()
 
 	// Build match filter
-	matchFilter := bson.M{
-		"deleted_at": bson.M{"$exists": false},
-	}
+	matchFilter := repository.NotDeleted()
 
 	// Add optional filters
 	if postID := c.Query("post_id"); postID != "" {
@@ -706,22 +760,24 @@ func (h *AdminHandler) GetAllComments(c *gin.Context) {
 		},
 		{
 			"$project": bson.M{
-				"_id":           0,
-				"id":            1,
-				"post_id":       bson.M{"$toString": "$post_id"},
-				"user_id":       bson.M{"$toString": "$user_id"},
-				"parent_id":     bson.M{"$toString": "$parent_id"},
-				"content":       1,
-				"media_urls":    1,
-				"likes_count":   1,
-				"replies_count": 1,
-				"is_hidden":     1,
-				"is_reported":   1,
-				"depth":         1,
-				"created_at":    1,
-				"updated_at":    1,
-				"user":          1,
-				"post":          1,
+				"_id":                   0,
+				"id":                    1,
+				"post_id":               bson.M{"$toString": "$post_id"},
+				"user_id":               bson.M{"$toString": "$user_id"},
+				"parent_id":             bson.M{"$toString": "$parent_id"},
+				"posted_by_delegate":    bson.M{"$toString": "$posted_by_delegate"},
+				"moderated_by_delegate": bson.M{"$toString": "$moderated_by_delegate"},
+				"content":               1,
+				"media_urls":            1,
+				"likes_count":           1,
+				"replies_count":         1,
+				"is_hidden":             1,
+				"is_reported":           1,
+				"depth":                 1,
+				"created_at":            1,
+				"updated_at":            1,
+				"user":                  1,
+				"post":                  1,
 			},
 		},
 		{
@@ -735,16 +791,16 @@ func (h *AdminHandler) GetAllComments(c *gin.Context) {
 		},
 	}
 
-	cursor, err := h.db.Collection("comments").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("comments").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get comments", err)
+		h.queryErrorResponse(c, "comments.list", "Failed to get comments", err)
 		return
 	}
 	defer cursor.Close(ctx)
 
 	var comments []bson.M
 	if err := cursor.All(ctx, &comments); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode comments", err)
+		h.queryErrorResponse(c, "comments.list", "Failed to decode comments", err)
 		return
 	}
 
@@ -754,9 +810,9 @@ func (h *AdminHandler) GetAllComments(c *gin.Context) {
 		{"$count": "total"},
 	}
 
-	countCursor, err := h.db.Collection("comments").Aggregate(ctx, countPipeline)
+	countCursor, err := h.db.Collection("comments").Aggregate(ctx, countPipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get comments count", err)
+		h.queryErrorResponse(c, "comments.list", "Failed to get comments count", err)
 		return
 	}
 	defer countCursor.Close(ctx)
@@ -792,12 +848,10 @@ func (h *AdminHandler) GetComment(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"_id":        objID,
-				"deleted_at": bson.M{"$exists": false},
-			},
+			"$match": repository.ByID(objID),
 		},
 		{
 			"$lookup": bson.M{
@@ -847,29 +901,31 @@ func (h *AdminHandler) GetComment(c *gin.Context) {
 		},
 		{
 			"$project": bson.M{
-				"_id":           0,
-				"id":            1,
-				"post_id":       bson.M{"$toString": "$post_id"},
-				"user_id":       bson.M{"$toString": "$user_id"},
-				"parent_id":     bson.M{"$toString": "$parent_id"},
-				"content":       1,
-				"media_urls":    1,
-				"likes_count":   1,
-				"replies_count": 1,
-				"is_hidden":     1,
-				"is_reported":   1,
-				"depth":         1,
-				"created_at":    1,
-				"updated_at":    1,
-				"user":          1,
-				"post":          1,
+				"_id":                   0,
+				"id":                    1,
+				"post_id":               bson.M{"$toString": "$post_id"},
+				"user_id":               bson.M{"$toString": "$user_id"},
+				"parent_id":             bson.M{"$toString": "$parent_id"},
+				"posted_by_delegate":    bson.M{"$toString": "$posted_by_delegate"},
+				"moderated_by_delegate": bson.M{"$toString": "$moderated_by_delegate"},
+				"content":               1,
+				"media_urls":            1,
+				"likes_count":           1,
+				"replies_count":         1,
+				"is_hidden":             1,
+				"is_reported":           1,
+				"depth":                 1,
+				"created_at":            1,
+				"updated_at":            1,
+				"user":                  1,
+				"post":                  1,
 			},
 		},
 	}
 
-	cursor, err := h.db.Collection("comments").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("comments").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get comment", err)
+		h.queryErrorResponse(c, "comments.get", "Failed to get comment", err)
 		return
 	}
 	defer cursor.Close(ctx)
@@ -887,16 +943,22 @@ func (h *AdminHandler) GetComment(c *gin.Context) {
 
 // Add missing GetAllMessages function
 func (h *AdminHandler) GetAllMessages(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	page, limit := params.Page, params.Limit
+	guard := h.adminService.QueryGuard()
+	if limit > guard.MaxResults() {
+		limit = guard.MaxResults()
+	}
 	skip := (page - 1) * limit
 
 	ctx := c.Request.Context()
 
 	// Build match filter
-	matchFilter := bson.M{
-		"deleted_at": bson.M{"$exists": false},
-	}
+	matchFilter := repository.NotDeleted()
 
 	// Add optional filters
 	if conversationID := c.Query("conversation_id"); conversationID != "" {
@@ -975,25 +1037,26 @@ func (h *AdminHandler) GetAllMessages(c *gin.Context) {
 		},
 		{
 			"$project": bson.M{
-				"_id":             0,
-				"id":              1,
-				"conversation_id": bson.M{"$toString": "$conversation_id"},
-				"sender_id":       bson.M{"$toString": "$sender_id"},
-				"recipient_id":    bson.M{"$toString": "$recipient_id"},
-				"content":         1,
-				"content_type":    1,
-				"media_url":       1,
-				"file_name":       1,
-				"file_size":       1,
-				"is_read":         1,
-				"read_at":         1,
-				"is_edited":       1,
-				"edited_at":       1,
-				"reply_to_id":     bson.M{"$toString": "$reply_to_id"},
-				"created_at":      1,
-				"updated_at":      1,
-				"sender":          1,
-				"conversation":    1,
+				"_id":              0,
+				"id":               1,
+				"conversation_id":  bson.M{"$toString": "$conversation_id"},
+				"sender_id":        bson.M{"$toString": "$sender_id"},
+				"recipient_id":     bson.M{"$toString": "$recipient_id"},
+				"content":          1,
+				"content_type":     1,
+				"media_url":        1,
+				"file_name":        1,
+				"file_size":        1,
+				"is_read":          1,
+				"read_at":          1,
+				"is_edited":        1,
+				"edited_at":        1,
+				"reply_to_id":      bson.M{"$toString": "$reply_to_id"},
+				"sent_by_delegate": bson.M{"$toString": "$sent_by_delegate"},
+				"created_at":       1,
+				"updated_at":       1,
+				"sender":           1,
+				"conversation":     1,
 			},
 		},
 		{
@@ -1007,16 +1070,16 @@ func (h *AdminHandler) GetAllMessages(c *gin.Context) {
 		},
 	}
 
-	cursor, err := h.db.Collection("messages").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("messages").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get messages", err)
+		h.queryErrorResponse(c, "messages.list", "Failed to get messages", err)
 		return
 	}
 	defer cursor.Close(ctx)
 
 	var messages []bson.M
 	if err := cursor.All(ctx, &messages); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode messages", err)
+		h.queryErrorResponse(c, "messages.list", "Failed to decode messages", err)
 		return
 	}
 
@@ -1026,9 +1089,9 @@ func (h *AdminHandler) GetAllMessages(c *gin.Context) {
 		{"$count": "total"},
 	}
 
-	countCursor, err := h.db.Collection("messages").Aggregate(ctx, countPipeline)
+	countCursor, err := h.db.Collection("messages").Aggregate(ctx, countPipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get messages count", err)
+		h.queryErrorResponse(c, "messages.list", "Failed to get messages count", err)
 		return
 	}
 	defer countCursor.Close(ctx)
@@ -1064,12 +1127,10 @@ func (h *AdminHandler) GetMessage(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"_id":        objID,
-				"deleted_at": bson.M{"$exists": false},
-			},
+			"$match": repository.ByID(objID),
 		},
 		{
 			"$lookup": bson.M{
@@ -1119,32 +1180,33 @@ func (h *AdminHandler) GetMessage(c *gin.Context) {
 		},
 		{
 			"$project": bson.M{
-				"_id":             0,
-				"id":              1,
-				"conversation_id": bson.M{"$toString": "$conversation_id"},
-				"sender_id":       bson.M{"$toString": "$sender_id"},
-				"recipient_id":    bson.M{"$toString": "$recipient_id"},
-				"content":         1,
-				"content_type":    1,
-				"media_url":       1,
-				"file_name":       1,
-				"file_size":       1,
-				"is_read":         1,
-				"read_at":         1,
-				"is_edited":       1,
-				"edited_at":       1,
-				"reply_to_id":     bson.M{"$toString": "$reply_to_id"},
-				"created_at":      1,
-				"updated_at":      1,
-				"sender":          1,
-				"conversation":    1,
+				"_id":              0,
+				"id":               1,
+				"conversation_id":  bson.M{"$toString": "$conversation_id"},
+				"sender_id":        bson.M{"$toString": "$sender_id"},
+				"recipient_id":     bson.M{"$toString": "$recipient_id"},
+				"content":          1,
+				"content_type":     1,
+				"media_url":        1,
+				"file_name":        1,
+				"file_size":        1,
+				"is_read":          1,
+				"read_at":          1,
+				"is_edited":        1,
+				"edited_at":        1,
+				"reply_to_id":      bson.M{"$toString": "$reply_to_id"},
+				"sent_by_delegate": bson.M{"$toString": "$sent_by_delegate"},
+				"created_at":       1,
+				"updated_at":       1,
+				"sender":           1,
+				"conversation":     1,
 			},
 		},
 	}
 
-	cursor, err := h.db.Collection("messages").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("messages").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get message", err)
+		h.queryErrorResponse(c, "messages.get", "Failed to get message", err)
 		return
 	}
 	defer cursor.Close(ctx)
@@ -1162,16 +1224,22 @@ func (h *AdminHandler) GetMessage(c *gin.Context) {
 
 // Add missing GetAllConversations function
 func (h *AdminHandler) GetAllConversations(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	page, limit := params.Page, params.Limit
+	guard := h.adminService.QueryGuard()
+	if limit > guard.MaxResults() {
+		limit = guard.MaxResults()
+	}
 	skip := (page - 1) * limit
 
 	ctx := c.Request.Context()
 
 	// Build match filter
-	matchFilter := bson.M{
-		"deleted_at": bson.M{"$exists": false},
-	}
+	matchFilter := repository.NotDeleted()
 
 	// Add optional filters
 	if convType := c.Query("type"); convType != "" && convType != "all" {
@@ -1272,16 +1340,16 @@ func (h *AdminHandler) GetAllConversations(c *gin.Context) {
 		},
 	}
 
-	cursor, err := h.db.Collection("conversations").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("conversations").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get conversations", err)
+		h.queryErrorResponse(c, "conversations.list", "Failed to get conversations", err)
 		return
 	}
 	defer cursor.Close(ctx)
 
 	var conversations []bson.M
 	if err := cursor.All(ctx, &conversations); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode conversations", err)
+		h.queryErrorResponse(c, "conversations.list", "Failed to decode conversations", err)
 		return
 	}
 
@@ -1291,9 +1359,9 @@ func (h *AdminHandler) GetAllConversations(c *gin.Context) {
 		{"$count": "total"},
 	}
 
-	countCursor, err := h.db.Collection("conversations").Aggregate(ctx, countPipeline)
+	countCursor, err := h.db.Collection("conversations").Aggregate(ctx, countPipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get conversations count", err)
+		h.queryErrorResponse(c, "conversations.list", "Failed to get conversations count", err)
 		return
 	}
 	defer countCursor.Close(ctx)
@@ -1368,12 +1436,10 @@ func (h *AdminHandler) GetPost(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"_id":        objID,
-				"deleted_at": bson.M{"$exists": false},
-			},
+			"$match": repository.ByID(objID),
 		},
 		{
 			"$lookup": bson.M{
@@ -1404,39 +1470,41 @@ func (h *AdminHandler) GetPost(c *gin.Context) {
 		},
 		{
 			"$project": bson.M{
-				"_id":              0,
-				"id":               1,
-				"user_id":          bson.M{"$toString": "$user_id"},
-				"content":          1,
-				"type":             1,
-				"visibility":       1,
-				"media_urls":       1,
-				"hashtags":         1,
-				"mentions":         1,
-				"location":         1,
-				"likes_count":      1,
-				"comments_count":   1,
-				"shares_count":     1,
-				"views_count":      1,
-				"is_reported":      1,
-				"is_hidden":        1,
-				"is_pinned":        1,
-				"is_promoted":      1,
-				"original_post_id": bson.M{"$toString": "$original_post_id"},
-				"group_id":         bson.M{"$toString": "$group_id"},
-				"event_id":         bson.M{"$toString": "$event_id"},
-				"scheduled_at":     1,
-				"expires_at":       1,
-				"created_at":       1,
-				"updated_at":       1,
-				"user":             1,
+				"_id":                0,
+				"id":                 1,
+				"user_id":            bson.M{"$toString": "$user_id"},
+				"content":            1,
+				"type":               1,
+				"visibility":         1,
+				"media_urls":         1,
+				"hashtags":           1,
+				"mentions":           1,
+				"location":           1,
+				"likes_count":        1,
+				"comments_count":     1,
+				"shares_count":       1,
+				"views_count":        1,
+				"is_reported":        1,
+				"is_hidden":          1,
+				"is_pinned":          1,
+				"is_promoted":        1,
+				"original_post_id":   bson.M{"$toString": "$original_post_id"},
+				"posted_by_delegate": bson.M{"$toString": "$posted_by_delegate"},
+				"edited_by":          bson.M{"$toString": "$edited_by"},
+				"group_id":           bson.M{"$toString": "$group_id"},
+				"event_id":           bson.M{"$toString": "$event_id"},
+				"scheduled_at":       1,
+				"expires_at":         1,
+				"created_at":         1,
+				"updated_at":         1,
+				"user":               1,
 			},
 		},
 	}
 
-	cursor, err := h.db.Collection("posts").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("posts").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get post", err)
+		h.queryErrorResponse(c, "posts.get", "Failed to get post", err)
 		return
 	}
 	defer cursor.Close(ctx)
@@ -1551,9 +1619,8 @@ func (h *AdminHandler) UpdateComment(c *gin.Context) {
 	}
 
 	// Get admin user for audit logging
-	adminUser, exists := c.Get("user")
-	if !exists {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "Admin authentication required", nil)
+	adminID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -1568,7 +1635,7 @@ func (h *AdminHandler) UpdateComment(c *gin.Context) {
 		"$set": bson.M{
 			"content":    input.Content,
 			"updated_at": time.Now(),
-			"edited_by":  adminUser.(models.User).ID,
+			"edited_by":  adminID,
 		},
 	}
 
@@ -1589,9 +1656,8 @@ func (h *AdminHandler) ShowComment(c *gin.Context) {
 	id := c.Param("id")
 
 	// Get admin user for audit logging
-	adminUser, exists := c.Get("user")
-	if !exists {
-		utils.ErrorResponse(c, http.StatusUnauthorized, "Admin authentication required", nil)
+	adminID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -1606,8 +1672,9 @@ func (h *AdminHandler) ShowComment(c *gin.Context) {
 		"$set": bson.M{
 			"is_hidden":    false,
 			"updated_at":   time.Now(),
-			"moderated_by": adminUser.(models.User).ID,
+			"moderated_by": adminID,
 		},
+		"$unset": bson.M{"hidden_by": ""},
 	}
 
 	_, err = h.db.Collection("comments").UpdateOne(c, bson.M{"_id": objID}, update)
@@ -1642,6 +1709,7 @@ func (h *AdminHandler) HideComment(c *gin.Context) {
 	update := bson.M{
 		"$set": bson.M{
 			"is_hidden":  true,
+			"hidden_by":  actingAdminID(c),
 			"updated_at": time.Now(),
 		},
 	}
@@ -1734,6 +1802,7 @@ func (h *AdminHandler) BulkCommentAction(c *gin.Context) {
 			update = bson.M{
 				"$set": bson.M{
 					"is_hidden":  true,
+					"hidden_by":  actingAdminID(c),
 					"updated_at": time.Now(),
 				},
 			}
@@ -1772,12 +1841,16 @@ func (h *AdminHandler) BulkCommentAction(c *gin.Context) {
 
 // Group Management
 func (h *AdminHandler) GetAllGroups(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	page, limit := params.Page, params.Limit
 
 	groups, pagination, err := h.adminService.GetAllGroups(c.Request.Context(), page, limit)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get groups", err)
+		h.queryErrorResponse(c, "groups.list", "Failed to get groups", err)
 		return
 	}
 
@@ -1795,10 +1868,7 @@ func (h *AdminHandler) GetGroup(c *gin.Context) {
 
 	ctx := c.Request.Context()
 	var group models.Group
-	err = h.db.Collection("groups").FindOne(ctx, bson.M{
-		"_id":        objID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&group)
+	err = h.db.Collection("groups").FindOne(ctx, repository.ByID(objID)).Decode(&group)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			utils.NotFoundResponse(c, "Group not found")
@@ -1819,17 +1889,24 @@ func (h *AdminHandler) GetGroupMembers(c *gin.Context) {
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	page, limit := params.Page, params.Limit
+	guard := h.adminService.QueryGuard()
+	if limit > guard.MaxResults() {
+		limit = guard.MaxResults()
+	}
 	skip := (page - 1) * limit
 
 	ctx := c.Request.Context()
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"group_id":   objID,
-				"deleted_at": bson.M{"$exists": false},
-			},
+			"$match": repository.NotDeleted(bson.M{
+				"group_id": objID,
+			}),
 		},
 		{
 			"$lookup": bson.M{
@@ -1847,23 +1924,22 @@ func (h *AdminHandler) GetGroupMembers(c *gin.Context) {
 		},
 	}
 
-	cursor, err := h.db.Collection("group_members").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("group_members").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get group members", err)
+		h.queryErrorResponse(c, "groups.members", "Failed to get group members", err)
 		return
 	}
 	defer cursor.Close(ctx)
 
 	var members []bson.M
 	if err := cursor.All(ctx, &members); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode group members", err)
+		h.queryErrorResponse(c, "groups.members", "Failed to decode group members", err)
 		return
 	}
 
-	total, _ := h.db.Collection("group_members").CountDocuments(ctx, bson.M{
-		"group_id":   objID,
-		"deleted_at": bson.M{"$exists": false},
-	})
+	total, _ := h.db.Collection("group_members").CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"group_id": objID,
+	}), guard.CountOptions())
 
 	pagination := &utils.PaginationMeta{
 		CurrentPage: page,
@@ -2038,8 +2114,12 @@ func (h *AdminHandler) BulkGroupAction(c *gin.Context) {
 
 // Event Management
 func (h *AdminHandler) GetAllEvents(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	page, limit := params.Page, params.Limit
 
 	events, pagination, err := h.adminService.GetAllEvents(c.Request.Context(), page, limit)
 	if err != nil {
@@ -2061,10 +2141,7 @@ func (h *AdminHandler) GetEvent(c *gin.Context) {
 
 	ctx := c.Request.Context()
 	var event models.Event
-	err = h.db.Collection("events").FindOne(ctx, bson.M{
-		"_id":        objID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&event)
+	err = h.db.Collection("events").FindOne(ctx, repository.ByID(objID)).Decode(&event)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			utils.NotFoundResponse(c, "Event not found")
@@ -2085,18 +2162,25 @@ func (h *AdminHandler) GetEventAttendees(c *gin.Context) {
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	page, limit := params.Page, params.Limit
+	guard := h.adminService.QueryGuard()
+	if limit > guard.MaxResults() {
+		limit = guard.MaxResults()
+	}
 	skip := (page - 1) * limit
 
 	ctx := c.Request.Context()
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"event_id":   objID,
-				"status":     "attending",
-				"deleted_at": bson.M{"$exists": false},
-			},
+			"$match": repository.NotDeleted(bson.M{
+				"event_id": objID,
+				"status":   "attending",
+			}),
 		},
 		{
 			"$lookup": bson.M{
@@ -2114,24 +2198,23 @@ func (h *AdminHandler) GetEventAttendees(c *gin.Context) {
 		},
 	}
 
-	cursor, err := h.db.Collection("event_attendees").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("event_attendees").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get event attendees", err)
+		h.queryErrorResponse(c, "events.attendees", "Failed to get event attendees", err)
 		return
 	}
 	defer cursor.Close(ctx)
 
 	var attendees []bson.M
 	if err := cursor.All(ctx, &attendees); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode event attendees", err)
+		h.queryErrorResponse(c, "events.attendees", "Failed to decode event attendees", err)
 		return
 	}
 
-	total, _ := h.db.Collection("event_attendees").CountDocuments(ctx, bson.M{
-		"event_id":   objID,
-		"status":     "attending",
-		"deleted_at": bson.M{"$exists": false},
-	})
+	total, _ := h.db.Collection("event_attendees").CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"event_id": objID,
+		"status":   "attending",
+	}), guard.CountOptions())
 
 	pagination := &utils.PaginationMeta{
 		CurrentPage: page,
@@ -2306,8 +2389,12 @@ func (h *AdminHandler) BulkEventAction(c *gin.Context) {
 
 // Story Management
 func (h *AdminHandler) GetAllStories(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	page, limit := params.Page, params.Limit
 
 	stories, pagination, err := h.adminService.GetAllStories(c.Request.Context(), page, limit)
 	if err != nil {
@@ -2329,10 +2416,7 @@ func (h *AdminHandler) GetStory(c *gin.Context) {
 
 	ctx := c.Request.Context()
 	var story models.Story
-	err = h.db.Collection("stories").FindOne(ctx, bson.M{
-		"_id":        objID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&story)
+	err = h.db.Collection("stories").FindOne(ctx, repository.ByID(objID)).Decode(&story)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			utils.NotFoundResponse(c, "Story not found")
@@ -2503,8 +2587,16 @@ func (h *AdminHandler) GetConversationMessages(c *gin.Context) {
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	params, err := utils.ParsePagination(c, 50, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	page, limit := params.Page, params.Limit
+	guard := h.adminService.QueryGuard()
+	if limit > guard.MaxResults() {
+		limit = guard.MaxResults()
+	}
 	skip := (page - 1) * limit
 
 	ctx := c.Request.Context()
@@ -2512,10 +2604,9 @@ func (h *AdminHandler) GetConversationMessages(c *gin.Context) {
 	// Build aggregation pipeline for messages
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"conversation_id": objID,
-				"deleted_at":      bson.M{"$exists": false},
-			},
+			}),
 		},
 		{
 			"$lookup": bson.M{
@@ -2571,24 +2662,23 @@ func (h *AdminHandler) GetConversationMessages(c *gin.Context) {
 		},
 	}
 
-	cursor, err := h.db.Collection("messages").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("messages").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get conversation messages", err)
+		h.queryErrorResponse(c, "conversations.messages", "Failed to get conversation messages", err)
 		return
 	}
 	defer cursor.Close(ctx)
 
 	var messages []bson.M
 	if err := cursor.All(ctx, &messages); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode messages", err)
+		h.queryErrorResponse(c, "conversations.messages", "Failed to decode messages", err)
 		return
 	}
 
 	// Get total count
-	total, _ := h.db.Collection("messages").CountDocuments(ctx, bson.M{
+	total, _ := h.db.Collection("messages").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"conversation_id": objID,
-		"deleted_at":      bson.M{"$exists": false},
-	})
+	}), guard.CountOptions())
 
 	pagination := &utils.PaginationMeta{
 		CurrentPage: page,
@@ -2613,13 +2703,11 @@ func (h *AdminHandler) GetConversationAnalytics(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 
 	// Get conversation details
 	var conversation bson.M
-	err = h.db.Collection("conversations").FindOne(ctx, bson.M{
-		"_id":        objID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&conversation)
+	err = h.db.Collection("conversations").FindOne(ctx, repository.ByID(objID)).Decode(&conversation)
 	if err != nil {
 		utils.NotFoundResponse(c, "Conversation not found")
 		return
@@ -2628,10 +2716,9 @@ func (h *AdminHandler) GetConversationAnalytics(c *gin.Context) {
 	// Get message statistics
 	messageStatsPipeline := []bson.M{
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"conversation_id": objID,
-				"deleted_at":      bson.M{"$exists": false},
-			},
+			}),
 		},
 		{
 			"$group": bson.M{
@@ -2645,24 +2732,19 @@ func (h *AdminHandler) GetConversationAnalytics(c *gin.Context) {
 		},
 	}
 
-	cursor, err := h.db.Collection("messages").Aggregate(ctx, messageStatsPipeline)
+	messageStats, _, err := guard.AggregateAll(ctx, h.db.Collection("messages"), "analytics.conversation", messageStatsPipeline)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get message statistics", err)
+		h.queryErrorResponse(c, "analytics.conversation", "Failed to get message statistics", err)
 		return
 	}
-	defer cursor.Close(ctx)
-
-	var messageStats []bson.M
-	cursor.All(ctx, &messageStats)
 
 	// Get activity by day (last 30 days)
 	activityPipeline := []bson.M{
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"conversation_id": objID,
 				"created_at":      bson.M{"$gte": time.Now().AddDate(0, 0, -30)},
-				"deleted_at":      bson.M{"$exists": false},
-			},
+			}),
 		},
 		{
 			"$group": bson.M{
@@ -2680,23 +2762,18 @@ func (h *AdminHandler) GetConversationAnalytics(c *gin.Context) {
 		},
 	}
 
-	activityCursor, err := h.db.Collection("messages").Aggregate(ctx, activityPipeline)
+	activityStats, activityTruncated, err := guard.AggregateAll(ctx, h.db.Collection("messages"), "analytics.conversation", activityPipeline)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get activity statistics", err)
+		h.queryErrorResponse(c, "analytics.conversation", "Failed to get activity statistics", err)
 		return
 	}
-	defer activityCursor.Close(ctx)
-
-	var activityStats []bson.M
-	activityCursor.All(ctx, &activityStats)
 
 	// Get participant activity
 	participantActivityPipeline := []bson.M{
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"conversation_id": objID,
-				"deleted_at":      bson.M{"$exists": false},
-			},
+			}),
 		},
 		{
 			"$group": bson.M{
@@ -2723,15 +2800,11 @@ func (h *AdminHandler) GetConversationAnalytics(c *gin.Context) {
 		},
 	}
 
-	participantCursor, err := h.db.Collection("messages").Aggregate(ctx, participantActivityPipeline)
+	participantActivity, participantTruncated, err := guard.AggregateAll(ctx, h.db.Collection("messages"), "analytics.conversation", participantActivityPipeline)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get participant activity", err)
+		h.queryErrorResponse(c, "analytics.conversation", "Failed to get participant activity", err)
 		return
 	}
-	defer participantCursor.Close(ctx)
-
-	var participantActivity []bson.M
-	participantCursor.All(ctx, &participantActivity)
 
 	analytics := gin.H{
 		"conversation_id":      conversationID,
@@ -2740,6 +2813,8 @@ func (h *AdminHandler) GetConversationAnalytics(c *gin.Context) {
 		"message_statistics":   messageStats,
 		"activity_by_day":      activityStats,
 		"participant_activity": participantActivity,
+		"truncated":            activityTruncated || participantTruncated,
+		"max_results":          guard.MaxResults(),
 		"generated_at":         time.Now(),
 	}
 
@@ -2820,6 +2895,7 @@ func (h *AdminHandler) GetConversationReports(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 
 	// Get reports for messages in this conversation
 	pipeline := []bson.M{
@@ -2832,11 +2908,10 @@ func (h *AdminHandler) GetConversationReports(c *gin.Context) {
 			},
 		},
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"message.conversation_id": objID,
 				"target_type":             "message",
-				"deleted_at":              bson.M{"$exists": false},
-			},
+			}),
 		},
 		{
 			"$lookup": bson.M{
@@ -2864,16 +2939,16 @@ func (h *AdminHandler) GetConversationReports(c *gin.Context) {
 		},
 	}
 
-	cursor, err := h.db.Collection("reports").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("reports").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get conversation reports", err)
+		h.queryErrorResponse(c, "conversations.reports", "Failed to get conversation reports", err)
 		return
 	}
 	defer cursor.Close(ctx)
 
 	var reports []bson.M
 	if err := cursor.All(ctx, &reports); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode reports", err)
+		h.queryErrorResponse(c, "conversations.reports", "Failed to decode reports", err)
 		return
 	}
 
@@ -2985,10 +3060,7 @@ func (h *AdminHandler) GetConversation(c *gin.Context) {
 
 	ctx := c.Request.Context()
 	var conversation bson.M
-	err = h.db.Collection("conversations").FindOne(ctx, bson.M{
-		"_id":        objID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&conversation)
+	err = h.db.Collection("conversations").FindOne(ctx, repository.ByID(objID)).Decode(&conversation)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			utils.NotFoundResponse(c, "Conversation not found")
@@ -3003,8 +3075,12 @@ func (h *AdminHandler) GetConversation(c *gin.Context) {
 
 // Report Management
 func (h *AdminHandler) GetAllReports(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	page, limit := params.Page, params.Limit
 
 	filter := services.ReportFilter{
 		Status:     models.ReportStatus(c.Query("status")),
@@ -3027,7 +3103,7 @@ func (h *AdminHandler) GetAllReports(c *gin.Context) {
 
 	reports, pagination, err := h.adminService.GetAllReports(c.Request.Context(), filter, page, limit)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get reports", err)
+		h.queryErrorResponse(c, "reports.list", "Failed to get reports", err)
 		return
 	}
 
@@ -3044,12 +3120,10 @@ func (h *AdminHandler) GetReport(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"_id":        objID,
-				"deleted_at": bson.M{"$exists": false},
-			},
+			"$match": repository.ByID(objID),
 		},
 		{
 			"$lookup": bson.M{
@@ -3069,9 +3143,9 @@ func (h *AdminHandler) GetReport(c *gin.Context) {
 		},
 	}
 
-	cursor, err := h.db.Collection("reports").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("reports").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get report", err)
+		h.queryErrorResponse(c, "reports.get", "Failed to get report", err)
 		return
 	}
 	defer cursor.Close(ctx)
@@ -3101,15 +3175,8 @@ func (h *AdminHandler) UpdateReportStatus(c *gin.Context) {
 		return
 	}
 
-	adminIDValue, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "Admin not authenticated")
-		return
-	}
-
-	adminID, ok := adminIDValue.(primitive.ObjectID)
+	adminID, ok := utils.CurrentUserID(c)
 	if !ok {
-		utils.InternalServerErrorResponse(c, "Invalid admin ID", nil)
 		return
 	}
 
@@ -3181,51 +3248,58 @@ func (h *AdminHandler) ResolveReport(c *gin.Context) {
 		return
 	}
 
-	var req struct {
-		Resolution string `json:"resolution" binding:"required"`
-		Note       string `json:"note"`
-	}
-
+	var req models.ReportResolveRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.ValidationErrorResponse(c, err)
 		return
 	}
 
-	adminIDValue, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "Admin not authenticated")
+	if req.Resolution == "" {
+		utils.BadRequestResponse(c, "Resolution is required", nil)
 		return
 	}
 
-	adminID, ok := adminIDValue.(primitive.ObjectID)
+	adminID, ok := utils.CurrentUserID(c)
 	if !ok {
-		utils.InternalServerErrorResponse(c, "Invalid admin ID", nil)
 		return
 	}
 
-	ctx := c.Request.Context()
-	update := bson.M{
-		"$set": bson.M{
-			"status":          models.ReportResolved,
-			"resolution":      req.Resolution,
-			"resolution_note": req.Note,
-			"resolved_by":     adminID,
-			"resolved_at":     time.Now(),
-			"updated_at":      time.Now(),
-		},
+	if req.ResolutionAction == "" {
+		req.ResolutionAction = models.ResolutionActionNone
 	}
 
-	_, err = h.db.Collection("reports").UpdateOne(ctx, bson.M{"_id": objID}, update)
+	err = h.reportService.ResolveReport(objID, adminID, req.Resolution, req.ResolutionAction, req.Note)
 	if err != nil {
+		if strings.Contains(err.Error(), "target not found") || strings.Contains(err.Error(), "target owner not found") {
+			utils.BadRequestResponse(c, "Reported target no longer exists", err)
+			return
+		}
+		if strings.Contains(err.Error(), "not valid for") {
+			utils.BadRequestResponse(c, err.Error(), nil)
+			return
+		}
+		if strings.Contains(err.Error(), "already resolved") {
+			utils.ConflictResponse(c, "Report has already been resolved", err)
+			return
+		}
+		if err == mongo.ErrNoDocuments {
+			utils.NotFoundResponse(c, "Report not found")
+			return
+		}
+		if strings.Contains(err.Error(), "access denied") {
+			utils.ForbiddenResponse(c, "Access denied")
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to resolve report", err)
 		return
 	}
 
-	h.logAdminActivity(c, "report_resolution", "Resolved report ID: "+reportID)
+	h.logAdminActivity(c, "report_resolution", "Resolved report ID: "+reportID+" with action "+string(req.ResolutionAction))
 	utils.OkResponse(c, "Report resolved successfully", gin.H{
-		"report_id":  reportID,
-		"resolution": req.Resolution,
-		"note":       req.Note,
+		"report_id":         reportID,
+		"resolution":        req.Resolution,
+		"resolution_action": req.ResolutionAction,
+		"note":              req.Note,
 	})
 }
 
@@ -3246,15 +3320,8 @@ func (h *AdminHandler) RejectReport(c *gin.Context) {
 		return
 	}
 
-	adminIDValue, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "Admin not authenticated")
-		return
-	}
-
-	adminID, ok := adminIDValue.(primitive.ObjectID)
+	adminID, ok := utils.CurrentUserID(c)
 	if !ok {
-		utils.InternalServerErrorResponse(c, "Invalid admin ID", nil)
 		return
 	}
 
@@ -3300,15 +3367,8 @@ func (h *AdminHandler) BulkReportAction(c *gin.Context) {
 		return
 	}
 
-	adminIDValue, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "Admin not authenticated")
-		return
-	}
-
-	adminID, ok := adminIDValue.(primitive.ObjectID)
+	adminID, ok := utils.CurrentUserID(c)
 	if !ok {
-		utils.InternalServerErrorResponse(c, "Invalid admin ID", nil)
 		return
 	}
 
@@ -3378,36 +3438,30 @@ func (h *AdminHandler) BulkReportAction(c *gin.Context) {
 
 func (h *AdminHandler) GetReportStats(c *gin.Context) {
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 
 	// Get total reports
-	totalReports, _ := h.db.Collection("reports").CountDocuments(ctx, bson.M{
-		"deleted_at": bson.M{"$exists": false},
-	})
+	totalReports, _ := h.db.Collection("reports").CountDocuments(ctx, repository.NotDeleted(), guard.CountOptions())
 
 	// Get pending reports
-	pendingReports, _ := h.db.Collection("reports").CountDocuments(ctx, bson.M{
-		"status":     models.ReportPending,
-		"deleted_at": bson.M{"$exists": false},
-	})
+	pendingReports, _ := h.db.Collection("reports").CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"status": models.ReportPending,
+	}), guard.CountOptions())
 
 	// Get resolved reports
-	resolvedReports, _ := h.db.Collection("reports").CountDocuments(ctx, bson.M{
-		"status":     models.ReportResolved,
-		"deleted_at": bson.M{"$exists": false},
-	})
+	resolvedReports, _ := h.db.Collection("reports").CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"status": models.ReportResolved,
+	}), guard.CountOptions())
 
 	// Get rejected reports
-	rejectedReports, _ := h.db.Collection("reports").CountDocuments(ctx, bson.M{
-		"status":     models.ReportRejected,
-		"deleted_at": bson.M{"$exists": false},
-	})
+	rejectedReports, _ := h.db.Collection("reports").CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"status": models.ReportRejected,
+	}), guard.CountOptions())
 
 	// Get reports by reason
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"deleted_at": bson.M{"$exists": false},
-			},
+			"$match": repository.NotDeleted(),
 		},
 		{
 			"$group": bson.M{
@@ -3417,16 +3471,23 @@ func (h *AdminHandler) GetReportStats(c *gin.Context) {
 		},
 	}
 
-	cursor, err := h.db.Collection("reports").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("reports").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get report statistics", err)
+		h.queryErrorResponse(c, "reports.stats", "Failed to get report statistics", err)
 		return
 	}
 	defer cursor.Close(ctx)
 
 	var reportsByReason []bson.M
 	if err := cursor.All(ctx, &reportsByReason); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode report statistics", err)
+		h.queryErrorResponse(c, "reports.stats", "Failed to decode report statistics", err)
+		return
+	}
+
+	// Comments held by the link spam filter and how they were resolved
+	commentHolds, err := h.adminService.GetCommentHoldStats(ctx)
+	if err != nil {
+		h.queryErrorResponse(c, "reports.stats", "Failed to get comment hold statistics", err)
 		return
 	}
 
@@ -3436,6 +3497,7 @@ func (h *AdminHandler) GetReportStats(c *gin.Context) {
 		"resolved_reports":  resolvedReports,
 		"rejected_reports":  rejectedReports,
 		"reports_by_reason": reportsByReason,
+		"comment_holds":     commentHolds,
 		"resolution_rate":   float64(resolvedReports) / float64(totalReports) * 100,
 		"rejection_rate":    float64(rejectedReports) / float64(totalReports) * 100,
 	}
@@ -3445,14 +3507,14 @@ func (h *AdminHandler) GetReportStats(c *gin.Context) {
 
 func (h *AdminHandler) GetReportSummary(c *gin.Context) {
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 
 	// Get reports by status over time
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"created_at": bson.M{"$gte": time.Now().AddDate(0, 0, -30)},
-				"deleted_at": bson.M{"$exists": false},
-			},
+			}),
 		},
 		{
 			"$group": bson.M{
@@ -3468,16 +3530,16 @@ func (h *AdminHandler) GetReportSummary(c *gin.Context) {
 		},
 	}
 
-	cursor, err := h.db.Collection("reports").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("reports").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get report summary", err)
+		h.queryErrorResponse(c, "reports.summary", "Failed to get report summary", err)
 		return
 	}
 	defer cursor.Close(ctx)
 
 	var reportTrends []bson.M
 	if err := cursor.All(ctx, &reportTrends); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode report summary", err)
+		h.queryErrorResponse(c, "reports.summary", "Failed to decode report summary", err)
 		return
 	}
 
@@ -3491,16 +3553,22 @@ func (h *AdminHandler) GetReportSummary(c *gin.Context) {
 
 // Follow/Relationship Management
 func (h *AdminHandler) GetAllFollows(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	page, limit := params.Page, params.Limit
+	guard := h.adminService.QueryGuard()
+	if limit > guard.MaxResults() {
+		limit = guard.MaxResults()
+	}
 	skip := (page - 1) * limit
 
 	ctx := c.Request.Context()
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"deleted_at": bson.M{"$exists": false},
-			},
+			"$match": repository.NotDeleted(),
 		},
 		{
 			"$lookup": bson.M{
@@ -3529,22 +3597,20 @@ func (h *AdminHandler) GetAllFollows(c *gin.Context) {
 		},
 	}
 
-	cursor, err := h.db.Collection("follows").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("follows").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get follows", err)
+		h.queryErrorResponse(c, "follows.list", "Failed to get follows", err)
 		return
 	}
 	defer cursor.Close(ctx)
 
 	var follows []bson.M
 	if err := cursor.All(ctx, &follows); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode follows", err)
+		h.queryErrorResponse(c, "follows.list", "Failed to decode follows", err)
 		return
 	}
 
-	total, _ := h.db.Collection("follows").CountDocuments(ctx, bson.M{
-		"deleted_at": bson.M{"$exists": false},
-	})
+	total, _ := h.db.Collection("follows").CountDocuments(ctx, repository.NotDeleted(), guard.CountOptions())
 
 	pagination := &utils.PaginationMeta{
 		CurrentPage: page,
@@ -3568,12 +3634,10 @@ func (h *AdminHandler) GetFollow(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"_id":        objID,
-				"deleted_at": bson.M{"$exists": false},
-			},
+			"$match": repository.ByID(objID),
 		},
 		{
 			"$lookup": bson.M{
@@ -3593,9 +3657,9 @@ func (h *AdminHandler) GetFollow(c *gin.Context) {
 		},
 	}
 
-	cursor, err := h.db.Collection("follows").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("follows").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get follow", err)
+		h.queryErrorResponse(c, "follows.get", "Failed to get follow", err)
 		return
 	}
 	defer cursor.Close(ctx)
@@ -3663,26 +3727,24 @@ func (h *AdminHandler) GetRelationships(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 
 	// Get followers
-	followersCount, _ := h.db.Collection("follows").CountDocuments(ctx, bson.M{
+	followersCount, _ := h.db.Collection("follows").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"following_id": objID,
-		"deleted_at":   bson.M{"$exists": false},
-	})
+	}), guard.CountOptions())
 
 	// Get following
-	followingCount, _ := h.db.Collection("follows").CountDocuments(ctx, bson.M{
+	followingCount, _ := h.db.Collection("follows").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"follower_id": objID,
-		"deleted_at":  bson.M{"$exists": false},
-	})
+	}), guard.CountOptions())
 
 	// Get mutual follows (users who follow each other)
 	mutualPipeline := []bson.M{
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"follower_id": objID,
-				"deleted_at":  bson.M{"$exists": false},
-			},
+			}),
 		},
 		{
 			"$lookup": bson.M{
@@ -3690,15 +3752,14 @@ func (h *AdminHandler) GetRelationships(c *gin.Context) {
 				"let":  bson.M{"following_id": "$following_id"},
 				"pipeline": []bson.M{
 					{
-						"$match": bson.M{
+						"$match": repository.NotDeleted(bson.M{
 							"$expr": bson.M{
 								"$and": []bson.M{
 									{"$eq": []interface{}{"$follower_id", "$$following_id"}},
 									{"$eq": []interface{}{"$following_id", objID}},
 								},
 							},
-							"deleted_at": bson.M{"$exists": false},
-						},
+						}),
 					},
 				},
 				"as": "mutual",
@@ -3714,9 +3775,9 @@ func (h *AdminHandler) GetRelationships(c *gin.Context) {
 		},
 	}
 
-	mutualCursor, err := h.db.Collection("follows").Aggregate(ctx, mutualPipeline)
+	mutualCursor, err := h.db.Collection("follows").Aggregate(ctx, mutualPipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get mutual relationships", err)
+		h.queryErrorResponse(c, "follows.relationships", "Failed to get mutual relationships", err)
 		return
 	}
 	defer mutualCursor.Close(ctx)
@@ -3807,16 +3868,22 @@ func (h *AdminHandler) BulkFollowAction(c *gin.Context) {
 
 // Like/Reaction Management
 func (h *AdminHandler) GetAllLikes(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	page, limit := params.Page, params.Limit
+	guard := h.adminService.QueryGuard()
+	if limit > guard.MaxResults() {
+		limit = guard.MaxResults()
+	}
 	skip := (page - 1) * limit
 
 	ctx := c.Request.Context()
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"deleted_at": bson.M{"$exists": false},
-			},
+			"$match": repository.NotDeleted(),
 		},
 		{
 			"$lookup": bson.M{
@@ -3837,22 +3904,20 @@ func (h *AdminHandler) GetAllLikes(c *gin.Context) {
 		},
 	}
 
-	cursor, err := h.db.Collection("likes").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("likes").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get likes", err)
+		h.queryErrorResponse(c, "likes.list", "Failed to get likes", err)
 		return
 	}
 	defer cursor.Close(ctx)
 
 	var likes []bson.M
 	if err := cursor.All(ctx, &likes); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode likes", err)
+		h.queryErrorResponse(c, "likes.list", "Failed to decode likes", err)
 		return
 	}
 
-	total, _ := h.db.Collection("likes").CountDocuments(ctx, bson.M{
-		"deleted_at": bson.M{"$exists": false},
-	})
+	total, _ := h.db.Collection("likes").CountDocuments(ctx, repository.NotDeleted(), guard.CountOptions())
 
 	pagination := &utils.PaginationMeta{
 		CurrentPage: page,
@@ -3869,18 +3934,15 @@ func (h *AdminHandler) GetAllLikes(c *gin.Context) {
 
 func (h *AdminHandler) GetLikeStats(c *gin.Context) {
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 
 	// Get total likes
-	totalLikes, _ := h.db.Collection("likes").CountDocuments(ctx, bson.M{
-		"deleted_at": bson.M{"$exists": false},
-	})
+	totalLikes, _ := h.db.Collection("likes").CountDocuments(ctx, repository.NotDeleted(), guard.CountOptions())
 
 	// Get likes by type
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"deleted_at": bson.M{"$exists": false},
-			},
+			"$match": repository.NotDeleted(),
 		},
 		{
 			"$group": bson.M{
@@ -3890,26 +3952,43 @@ func (h *AdminHandler) GetLikeStats(c *gin.Context) {
 		},
 	}
 
-	cursor, err := h.db.Collection("likes").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("likes").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get like statistics", err)
+		h.queryErrorResponse(c, "likes.stats", "Failed to get like statistics", err)
 		return
 	}
 	defer cursor.Close(ctx)
 
 	var likesByType []bson.M
 	if err := cursor.All(ctx, &likesByType); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode like statistics", err)
+		h.queryErrorResponse(c, "likes.stats", "Failed to decode like statistics", err)
+		return
+	}
+
+	// Get likes by reaction; grouping on the stored value picks up new and retired reactions
+	reactionCursor, err := h.db.Collection("likes").Aggregate(ctx, []bson.M{
+		{"$match": repository.NotDeleted()},
+		{"$group": bson.M{"_id": "$reaction_type", "count": bson.M{"$sum": 1}}},
+		{"$sort": bson.M{"count": -1}},
+	}, guard.AggregateOptions())
+	if err != nil {
+		h.queryErrorResponse(c, "likes.stats", "Failed to get reaction statistics", err)
+		return
+	}
+	defer reactionCursor.Close(ctx)
+
+	var likesByReaction []bson.M
+	if err := reactionCursor.All(ctx, &likesByReaction); err != nil {
+		h.queryErrorResponse(c, "likes.stats", "Failed to decode reaction statistics", err)
 		return
 	}
 
 	// Get likes over time (last 30 days)
 	timePipeline := []bson.M{
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"created_at": bson.M{"$gte": time.Now().AddDate(0, 0, -30)},
-				"deleted_at": bson.M{"$exists": false},
-			},
+			}),
 		},
 		{
 			"$group": bson.M{
@@ -3927,23 +4006,24 @@ func (h *AdminHandler) GetLikeStats(c *gin.Context) {
 		},
 	}
 
-	timeCursor, err := h.db.Collection("likes").Aggregate(ctx, timePipeline)
+	timeCursor, err := h.db.Collection("likes").Aggregate(ctx, timePipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get like trends", err)
+		h.queryErrorResponse(c, "likes.stats", "Failed to get like trends", err)
 		return
 	}
 	defer timeCursor.Close(ctx)
 
 	var likesOverTime []bson.M
 	if err := timeCursor.All(ctx, &likesOverTime); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode like trends", err)
+		h.queryErrorResponse(c, "likes.stats", "Failed to decode like trends", err)
 		return
 	}
 
 	stats := gin.H{
-		"total_likes":     totalLikes,
-		"likes_by_type":   likesByType,
-		"likes_over_time": likesOverTime,
+		"total_likes":       totalLikes,
+		"likes_by_type":     likesByType,
+		"likes_by_reaction": likesByReaction,
+		"likes_over_time":   likesOverTime,
 	}
 
 	utils.OkResponse(c, "Like statistics retrieved successfully", stats)
@@ -4055,8 +4135,12 @@ func (h *AdminHandler) BulkLikeAction(c *gin.Context) {
 
 // Hashtag Management
 func (h *AdminHandler) GetAllHashtags(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	page, limit := params.Page, params.Limit
 
 	hashtags, pagination, err := h.adminService.GetAllHashtags(c.Request.Context(), page, limit)
 	if err != nil {
@@ -4078,10 +4162,7 @@ func (h *AdminHandler) GetHashtag(c *gin.Context) {
 
 	ctx := c.Request.Context()
 	var hashtag models.Hashtag
-	err = h.db.Collection("hashtags").FindOne(ctx, bson.M{
-		"_id":        objID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&hashtag)
+	err = h.db.Collection("hashtags").FindOne(ctx, repository.ByID(objID)).Decode(&hashtag)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			utils.NotFoundResponse(c, "Hashtag not found")
@@ -4095,24 +4176,28 @@ func (h *AdminHandler) GetHashtag(c *gin.Context) {
 }
 
 func (h *AdminHandler) GetTrendingHashtags(c *gin.Context) {
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	limit, err := utils.ParseLimit(c, 10, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 	opts := options.Find().SetLimit(int64(limit)).SetSort(bson.M{"total_usage": -1})
 
-	cursor, err := h.db.Collection("hashtags").Find(ctx, bson.M{
+	cursor, err := h.db.Collection("hashtags").Find(ctx, repository.NotDeleted(bson.M{
 		"is_blocked": false,
-		"deleted_at": bson.M{"$exists": false},
-	}, opts)
+	}), guard.FindOptions(opts))
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get trending hashtags", err)
+		h.queryErrorResponse(c, "hashtags.trending", "Failed to get trending hashtags", err)
 		return
 	}
 	defer cursor.Close(ctx)
 
 	var hashtags []models.Hashtag
 	if err := cursor.All(ctx, &hashtags); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode trending hashtags", err)
+		h.queryErrorResponse(c, "hashtags.trending", "Failed to decode trending hashtags", err)
 		return
 	}
 
@@ -4318,16 +4403,22 @@ func (h *AdminHandler) BulkHashtagAction(c *gin.Context) {
 
 // Mention Management
 func (h *AdminHandler) GetAllMentions(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	page, limit := params.Page, params.Limit
+	guard := h.adminService.QueryGuard()
+	if limit > guard.MaxResults() {
+		limit = guard.MaxResults()
+	}
 	skip := (page - 1) * limit
 
 	ctx := c.Request.Context()
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"deleted_at": bson.M{"$exists": false},
-			},
+			"$match": repository.NotDeleted(),
 		},
 		{
 			"$lookup": bson.M{
@@ -4356,22 +4447,20 @@ func (h *AdminHandler) GetAllMentions(c *gin.Context) {
 		},
 	}
 
-	cursor, err := h.db.Collection("mentions").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("mentions").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get mentions", err)
+		h.queryErrorResponse(c, "mentions.list", "Failed to get mentions", err)
 		return
 	}
 	defer cursor.Close(ctx)
 
 	var mentions []bson.M
 	if err := cursor.All(ctx, &mentions); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode mentions", err)
+		h.queryErrorResponse(c, "mentions.list", "Failed to decode mentions", err)
 		return
 	}
 
-	total, _ := h.db.Collection("mentions").CountDocuments(ctx, bson.M{
-		"deleted_at": bson.M{"$exists": false},
-	})
+	total, _ := h.db.Collection("mentions").CountDocuments(ctx, repository.NotDeleted(), guard.CountOptions())
 
 	pagination := &utils.PaginationMeta{
 		CurrentPage: page,
@@ -4395,12 +4484,10 @@ func (h *AdminHandler) GetMention(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"_id":        objID,
-				"deleted_at": bson.M{"$exists": false},
-			},
+			"$match": repository.ByID(objID),
 		},
 		{
 			"$lookup": bson.M{
@@ -4420,9 +4507,9 @@ func (h *AdminHandler) GetMention(c *gin.Context) {
 		},
 	}
 
-	cursor, err := h.db.Collection("mentions").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("mentions").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get mention", err)
+		h.queryErrorResponse(c, "mentions.get", "Failed to get mention", err)
 		return
 	}
 	defer cursor.Close(ctx)
@@ -4544,8 +4631,12 @@ func (h *AdminHandler) BulkMentionAction(c *gin.Context) {
 
 // Media Management
 func (h *AdminHandler) GetAllMedia(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	page, limit := params.Page, params.Limit
 
 	media, pagination, err := h.adminService.GetAllMedia(c.Request.Context(), page, limit)
 	if err != nil {
@@ -4567,10 +4658,7 @@ func (h *AdminHandler) GetMedia(c *gin.Context) {
 
 	ctx := c.Request.Context()
 	var media models.Media
-	err = h.db.Collection("media").FindOne(ctx, bson.M{
-		"_id":        objID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&media)
+	err = h.db.Collection("media").FindOne(ctx, repository.ByID(objID)).Decode(&media)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			utils.NotFoundResponse(c, "Media not found")
@@ -4585,18 +4673,15 @@ func (h *AdminHandler) GetMedia(c *gin.Context) {
 
 func (h *AdminHandler) GetMediaStats(c *gin.Context) {
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 
 	// Get total media count
-	totalMedia, _ := h.db.Collection("media").CountDocuments(ctx, bson.M{
-		"deleted_at": bson.M{"$exists": false},
-	})
+	totalMedia, _ := h.db.Collection("media").CountDocuments(ctx, repository.NotDeleted(), guard.CountOptions())
 
 	// Get media by type
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"deleted_at": bson.M{"$exists": false},
-			},
+			"$match": repository.NotDeleted(),
 		},
 		{
 			"$group": bson.M{
@@ -4607,25 +4692,23 @@ func (h *AdminHandler) GetMediaStats(c *gin.Context) {
 		},
 	}
 
-	cursor, err := h.db.Collection("media").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("media").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get media statistics", err)
+		h.queryErrorResponse(c, "media.stats", "Failed to get media statistics", err)
 		return
 	}
 	defer cursor.Close(ctx)
 
 	var mediaByType []bson.M
 	if err := cursor.All(ctx, &mediaByType); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode media statistics", err)
+		h.queryErrorResponse(c, "media.stats", "Failed to decode media statistics", err)
 		return
 	}
 
 	// Get total storage used
 	storagePipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"deleted_at": bson.M{"$exists": false},
-			},
+			"$match": repository.NotDeleted(),
 		},
 		{
 			"$group": bson.M{
@@ -4635,9 +4718,9 @@ func (h *AdminHandler) GetMediaStats(c *gin.Context) {
 		},
 	}
 
-	storageCursor, err := h.db.Collection("media").Aggregate(ctx, storagePipeline)
+	storageCursor, err := h.db.Collection("media").Aggregate(ctx, storagePipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get storage statistics", err)
+		h.queryErrorResponse(c, "media.stats", "Failed to get storage statistics", err)
 		return
 	}
 	defer storageCursor.Close(ctx)
@@ -4845,13 +4928,12 @@ func (h *AdminHandler) BulkMediaAction(c *gin.Context) {
 
 func (h *AdminHandler) GetStorageStats(c *gin.Context) {
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 
 	// Get storage statistics by media type
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"deleted_at": bson.M{"$exists": false},
-			},
+			"$match": repository.NotDeleted(),
 		},
 		{
 			"$group": bson.M{
@@ -4863,25 +4945,23 @@ func (h *AdminHandler) GetStorageStats(c *gin.Context) {
 		},
 	}
 
-	cursor, err := h.db.Collection("media").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("media").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get storage statistics", err)
+		h.queryErrorResponse(c, "media.storage", "Failed to get storage statistics", err)
 		return
 	}
 	defer cursor.Close(ctx)
 
 	var storageByType []bson.M
 	if err := cursor.All(ctx, &storageByType); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode storage statistics", err)
+		h.queryErrorResponse(c, "media.storage", "Failed to decode storage statistics", err)
 		return
 	}
 
 	// Get total storage
 	totalPipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"deleted_at": bson.M{"$exists": false},
-			},
+			"$match": repository.NotDeleted(),
 		},
 		{
 			"$group": bson.M{
@@ -4892,9 +4972,9 @@ func (h *AdminHandler) GetStorageStats(c *gin.Context) {
 		},
 	}
 
-	totalCursor, err := h.db.Collection("media").Aggregate(ctx, totalPipeline)
+	totalCursor, err := h.db.Collection("media").Aggregate(ctx, totalPipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get total storage", err)
+		h.queryErrorResponse(c, "media.storage", "Failed to get total storage", err)
 		return
 	}
 	defer totalCursor.Close(ctx)
@@ -4936,10 +5016,9 @@ func (h *AdminHandler) CleanupStorage(c *gin.Context) {
 	ctx := c.Request.Context()
 	cutoffDate := time.Now().AddDate(0, 0, -req.OlderThan)
 
-	filter := bson.M{
+	filter := repository.NotDeleted(bson.M{
 		"created_at": bson.M{"$lt": cutoffDate},
-		"deleted_at": bson.M{"$exists": false},
-	}
+	})
 
 	if req.MediaType != "" {
 		filter["media_type"] = req.MediaType
@@ -4978,16 +5057,22 @@ func (h *AdminHandler) CleanupStorage(c *gin.Context) {
 
 // Notification Management
 func (h *AdminHandler) GetAllNotifications(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	page, limit := params.Page, params.Limit
+	guard := h.adminService.QueryGuard()
+	if limit > guard.MaxResults() {
+		limit = guard.MaxResults()
+	}
 	skip := (page - 1) * limit
 
 	ctx := c.Request.Context()
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"deleted_at": bson.M{"$exists": false},
-			},
+			"$match": repository.NotDeleted(),
 		},
 		{
 			"$lookup": bson.M{
@@ -5008,22 +5093,20 @@ func (h *AdminHandler) GetAllNotifications(c *gin.Context) {
 		},
 	}
 
-	cursor, err := h.db.Collection("notifications").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("notifications").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get notifications", err)
+		h.queryErrorResponse(c, "notifications.list", "Failed to get notifications", err)
 		return
 	}
 	defer cursor.Close(ctx)
 
 	var notifications []bson.M
 	if err := cursor.All(ctx, &notifications); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode notifications", err)
+		h.queryErrorResponse(c, "notifications.list", "Failed to decode notifications", err)
 		return
 	}
 
-	total, _ := h.db.Collection("notifications").CountDocuments(ctx, bson.M{
-		"deleted_at": bson.M{"$exists": false},
-	})
+	total, _ := h.db.Collection("notifications").CountDocuments(ctx, repository.NotDeleted(), guard.CountOptions())
 
 	pagination := &utils.PaginationMeta{
 		CurrentPage: page,
@@ -5047,12 +5130,10 @@ func (h *AdminHandler) GetNotification(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"_id":        objID,
-				"deleted_at": bson.M{"$exists": false},
-			},
+			"$match": repository.ByID(objID),
 		},
 		{
 			"$lookup": bson.M{
@@ -5064,9 +5145,9 @@ func (h *AdminHandler) GetNotification(c *gin.Context) {
 		},
 	}
 
-	cursor, err := h.db.Collection("notifications").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("notifications").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get notification", err)
+		h.queryErrorResponse(c, "notifications.get", "Failed to get notification", err)
 		return
 	}
 	defer cursor.Close(ctx)
@@ -5162,10 +5243,9 @@ func (h *AdminHandler) BroadcastNotification(c *gin.Context) {
 	ctx := c.Request.Context()
 
 	// Get all active users (or filtered users)
-	userFilter := bson.M{
-		"is_active":  true,
-		"deleted_at": bson.M{"$exists": false},
-	}
+	userFilter := repository.NotDeleted(bson.M{
+		"is_active": true,
+	})
 
 	// Apply additional filters if provided
 	if req.Filter != nil {
@@ -5222,26 +5302,22 @@ func (h *AdminHandler) BroadcastNotification(c *gin.Context) {
 
 func (h *AdminHandler) GetNotificationStats(c *gin.Context) {
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 
 	// Get total notifications
-	totalNotifications, _ := h.db.Collection("notifications").CountDocuments(ctx, bson.M{
-		"deleted_at": bson.M{"$exists": false},
-	})
+	totalNotifications, _ := h.db.Collection("notifications").CountDocuments(ctx, repository.NotDeleted(), guard.CountOptions())
 
 	// Get read vs unread
-	readNotifications, _ := h.db.Collection("notifications").CountDocuments(ctx, bson.M{
-		"is_read":    true,
-		"deleted_at": bson.M{"$exists": false},
-	})
+	readNotifications, _ := h.db.Collection("notifications").CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"is_read": true,
+	}), guard.CountOptions())
 
 	unreadNotifications := totalNotifications - readNotifications
 
 	// Get notifications by type
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"deleted_at": bson.M{"$exists": false},
-			},
+			"$match": repository.NotDeleted(),
 		},
 		{
 			"$group": bson.M{
@@ -5251,16 +5327,16 @@ func (h *AdminHandler) GetNotificationStats(c *gin.Context) {
 		},
 	}
 
-	cursor, err := h.db.Collection("notifications").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("notifications").Aggregate(ctx, pipeline, guard.AggregateOptions())
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get notification statistics", err)
+		h.queryErrorResponse(c, "notifications.stats", "Failed to get notification statistics", err)
 		return
 	}
 	defer cursor.Close(ctx)
 
 	var notificationsByType []bson.M
 	if err := cursor.All(ctx, &notificationsByType); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode notification statistics", err)
+		h.queryErrorResponse(c, "notifications.stats", "Failed to decode notification statistics", err)
 		return
 	}
 
@@ -5417,28 +5493,19 @@ func (h *AdminHandler) GetContentAnalytics(c *gin.Context) {
 func (h *AdminHandler) GetEngagementAnalytics(c *gin.Context) {
 	period := c.DefaultQuery("period", "30d")
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 
-	var days int
-	switch period {
-	case "7d":
-		days = 7
-	case "30d":
-		days = 30
-	case "90d":
-		days = 90
-	default:
-		days = 30
+	startDate, ok := h.analyticsStartDate(c, period)
+	if !ok {
+		return
 	}
 
-	startDate := time.Now().AddDate(0, 0, -days)
-
 	// Get engagement metrics
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"created_at": bson.M{"$gte": startDate},
-				"deleted_at": bson.M{"$exists": false},
-			},
+			}),
 		},
 		{
 			"$group": bson.M{
@@ -5454,26 +5521,18 @@ func (h *AdminHandler) GetEngagementAnalytics(c *gin.Context) {
 		},
 	}
 
-	cursor, err := h.db.Collection("likes").Aggregate(ctx, pipeline)
+	engagementData, engagementTruncated, err := guard.AggregateAll(ctx, h.db.Collection("likes"), "analytics.engagement", pipeline)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get engagement analytics", err)
-		return
-	}
-	defer cursor.Close(ctx)
-
-	var engagementData []bson.M
-	if err := cursor.All(ctx, &engagementData); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode engagement analytics", err)
+		h.queryErrorResponse(c, "analytics.engagement", "Failed to get engagement analytics", err)
 		return
 	}
 
 	// Get comment engagement
 	commentPipeline := []bson.M{
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"created_at": bson.M{"$gte": startDate},
-				"deleted_at": bson.M{"$exists": false},
-			},
+			}),
 		},
 		{
 			"$group": bson.M{
@@ -5488,16 +5547,9 @@ func (h *AdminHandler) GetEngagementAnalytics(c *gin.Context) {
 		},
 	}
 
-	commentCursor, err := h.db.Collection("comments").Aggregate(ctx, commentPipeline)
+	commentData, commentTruncated, err := guard.AggregateAll(ctx, h.db.Collection("comments"), "analytics.engagement", commentPipeline)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get comment analytics", err)
-		return
-	}
-	defer commentCursor.Close(ctx)
-
-	var commentData []bson.M
-	if err := commentCursor.All(ctx, &commentData); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode comment analytics", err)
+		h.queryErrorResponse(c, "analytics.engagement", "Failed to get comment analytics", err)
 		return
 	}
 
@@ -5505,6 +5557,8 @@ func (h *AdminHandler) GetEngagementAnalytics(c *gin.Context) {
 		"period":          period,
 		"engagement_data": engagementData,
 		"comment_data":    commentData,
+		"truncated":       engagementTruncated || commentTruncated,
+		"max_results":     guard.MaxResults(),
 	}
 
 	utils.OkResponse(c, "Engagement analytics retrieved successfully", analytics)
@@ -5513,30 +5567,19 @@ func (h *AdminHandler) GetEngagementAnalytics(c *gin.Context) {
 func (h *AdminHandler) GetGrowthAnalytics(c *gin.Context) {
 	period := c.DefaultQuery("period", "30d")
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 
-	var days int
-	switch period {
-	case "7d":
-		days = 7
-	case "30d":
-		days = 30
-	case "90d":
-		days = 90
-	case "365d":
-		days = 365
-	default:
-		days = 30
+	startDate, ok := h.analyticsStartDate(c, period)
+	if !ok {
+		return
 	}
 
-	startDate := time.Now().AddDate(0, 0, -days)
-
 	// User growth
 	userGrowthPipeline := []bson.M{
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"created_at": bson.M{"$gte": startDate},
-				"deleted_at": bson.M{"$exists": false},
-			},
+			}),
 		},
 		{
 			"$group": bson.M{
@@ -5551,26 +5594,18 @@ func (h *AdminHandler) GetGrowthAnalytics(c *gin.Context) {
 		},
 	}
 
-	userCursor, err := h.db.Collection("users").Aggregate(ctx, userGrowthPipeline)
+	userGrowthData, userTruncated, err := guard.AggregateAll(ctx, h.db.Collection("users"), "analytics.growth", userGrowthPipeline)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get user growth analytics", err)
-		return
-	}
-	defer userCursor.Close(ctx)
-
-	var userGrowthData []bson.M
-	if err := userCursor.All(ctx, &userGrowthData); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode user growth analytics", err)
+		h.queryErrorResponse(c, "analytics.growth", "Failed to get user growth analytics", err)
 		return
 	}
 
 	// Content growth
 	contentGrowthPipeline := []bson.M{
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"created_at": bson.M{"$gte": startDate},
-				"deleted_at": bson.M{"$exists": false},
-			},
+			}),
 		},
 		{
 			"$group": bson.M{
@@ -5585,16 +5620,9 @@ func (h *AdminHandler) GetGrowthAnalytics(c *gin.Context) {
 		},
 	}
 
-	contentCursor, err := h.db.Collection("posts").Aggregate(ctx, contentGrowthPipeline)
+	contentGrowthData, contentTruncated, err := guard.AggregateAll(ctx, h.db.Collection("posts"), "analytics.growth", contentGrowthPipeline)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get content growth analytics", err)
-		return
-	}
-	defer contentCursor.Close(ctx)
-
-	var contentGrowthData []bson.M
-	if err := contentCursor.All(ctx, &contentGrowthData); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode content growth analytics", err)
+		h.queryErrorResponse(c, "analytics.growth", "Failed to get content growth analytics", err)
 		return
 	}
 
@@ -5602,6 +5630,8 @@ func (h *AdminHandler) GetGrowthAnalytics(c *gin.Context) {
 		"period":              period,
 		"user_growth_data":    userGrowthData,
 		"content_growth_data": contentGrowthData,
+		"truncated":           userTruncated || contentTruncated,
+		"max_results":         guard.MaxResults(),
 	}
 
 	utils.OkResponse(c, "Growth analytics retrieved successfully", analytics)
@@ -5609,14 +5639,14 @@ func (h *AdminHandler) GetGrowthAnalytics(c *gin.Context) {
 
 func (h *AdminHandler) GetDemographicAnalytics(c *gin.Context) {
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 
 	// Users by age group (if age is stored)
 	agePipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"deleted_at": bson.M{"$exists": false},
-				"age":        bson.M{"$exists": true, "$ne": nil},
-			},
+			"$match": repository.NotDeleted(bson.M{
+				"age": bson.M{"$exists": true, "$ne": nil},
+			}),
 		},
 		{
 			"$addFields": bson.M{
@@ -5643,26 +5673,18 @@ func (h *AdminHandler) GetDemographicAnalytics(c *gin.Context) {
 		},
 	}
 
-	ageCursor, err := h.db.Collection("users").Aggregate(ctx, agePipeline)
+	ageGroups, ageTruncated, err := guard.AggregateAll(ctx, h.db.Collection("users"), "analytics.demographics", agePipeline)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get age demographics", err)
-		return
-	}
-	defer ageCursor.Close(ctx)
-
-	var ageGroups []bson.M
-	if err := ageCursor.All(ctx, &ageGroups); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode age demographics", err)
+		h.queryErrorResponse(c, "analytics.demographics", "Failed to get age demographics", err)
 		return
 	}
 
 	// Users by gender (if gender is stored)
 	genderPipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"deleted_at": bson.M{"$exists": false},
-				"gender":     bson.M{"$exists": true, "$ne": ""},
-			},
+			"$match": repository.NotDeleted(bson.M{
+				"gender": bson.M{"$exists": true, "$ne": ""},
+			}),
 		},
 		{
 			"$group": bson.M{
@@ -5672,26 +5694,18 @@ func (h *AdminHandler) GetDemographicAnalytics(c *gin.Context) {
 		},
 	}
 
-	genderCursor, err := h.db.Collection("users").Aggregate(ctx, genderPipeline)
+	genderGroups, genderTruncated, err := guard.AggregateAll(ctx, h.db.Collection("users"), "analytics.demographics", genderPipeline)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get gender demographics", err)
-		return
-	}
-	defer genderCursor.Close(ctx)
-
-	var genderGroups []bson.M
-	if err := genderCursor.All(ctx, &genderGroups); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode gender demographics", err)
+		h.queryErrorResponse(c, "analytics.demographics", "Failed to get gender demographics", err)
 		return
 	}
 
 	// Users by country/location (if location is stored)
 	locationPipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"deleted_at": bson.M{"$exists": false},
-				"country":    bson.M{"$exists": true, "$ne": ""},
-			},
+			"$match": repository.NotDeleted(bson.M{
+				"country": bson.M{"$exists": true, "$ne": ""},
+			}),
 		},
 		{
 			"$group": bson.M{
@@ -5707,16 +5721,9 @@ func (h *AdminHandler) GetDemographicAnalytics(c *gin.Context) {
 		},
 	}
 
-	locationCursor, err := h.db.Collection("users").Aggregate(ctx, locationPipeline)
+	locationGroups, locationTruncated, err := guard.AggregateAll(ctx, h.db.Collection("users"), "analytics.demographics", locationPipeline)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to get location demographics", err)
-		return
-	}
-	defer locationCursor.Close(ctx)
-
-	var locationGroups []bson.M
-	if err := locationCursor.All(ctx, &locationGroups); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to decode location demographics", err)
+		h.queryErrorResponse(c, "analytics.demographics", "Failed to get location demographics", err)
 		return
 	}
 
@@ -5724,6 +5731,8 @@ func (h *AdminHandler) GetDemographicAnalytics(c *gin.Context) {
 		"age_groups":      ageGroups,
 		"gender_groups":   genderGroups,
 		"location_groups": locationGroups,
+		"truncated":       ageTruncated || genderTruncated || locationTruncated,
+		"max_results":     guard.MaxResults(),
 	}
 
 	utils.OkResponse(c, "Demographic analytics retrieved successfully", analytics)
@@ -5768,6 +5777,10 @@ func (h *AdminHandler) GetCustomReport(c *gin.Context) {
 		return
 	}
 
+	if !h.checkAnalyticsRange(c, startDate, endDate) {
+		return
+	}
+
 	ctx := c.Request.Context()
 	var report gin.H
 
@@ -5784,7 +5797,7 @@ func (h *AdminHandler) GetCustomReport(c *gin.Context) {
 	}
 
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to generate custom report", err)
+		h.queryErrorResponse(c, "analytics.custom_report", "Failed to generate custom report", err)
 		return
 	}
 
@@ -5794,45 +5807,40 @@ func (h *AdminHandler) GetCustomReport(c *gin.Context) {
 
 func (h *AdminHandler) GetRealtimeAnalytics(c *gin.Context) {
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 
 	// Get real-time metrics for the last hour
 	lastHour := time.Now().Add(-1 * time.Hour)
 
 	// Active users in last hour
-	activeUsers, _ := h.db.Collection("users").CountDocuments(ctx, bson.M{
+	activeUsers, _ := h.db.Collection("users").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"last_active_at": bson.M{"$gte": lastHour},
-		"deleted_at":     bson.M{"$exists": false},
-	})
+	}), guard.CountOptions())
 
 	// New posts in last hour
-	newPosts, _ := h.db.Collection("posts").CountDocuments(ctx, bson.M{
+	newPosts, _ := h.db.Collection("posts").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"created_at": bson.M{"$gte": lastHour},
-		"deleted_at": bson.M{"$exists": false},
-	})
+	}), guard.CountOptions())
 
 	// New comments in last hour
-	newComments, _ := h.db.Collection("comments").CountDocuments(ctx, bson.M{
+	newComments, _ := h.db.Collection("comments").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"created_at": bson.M{"$gte": lastHour},
-		"deleted_at": bson.M{"$exists": false},
-	})
+	}), guard.CountOptions())
 
 	// New likes in last hour
-	newLikes, _ := h.db.Collection("likes").CountDocuments(ctx, bson.M{
+	newLikes, _ := h.db.Collection("likes").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"created_at": bson.M{"$gte": lastHour},
-		"deleted_at": bson.M{"$exists": false},
-	})
+	}), guard.CountOptions())
 
 	// New users in last hour
-	newUsers, _ := h.db.Collection("users").CountDocuments(ctx, bson.M{
+	newUsers, _ := h.db.Collection("users").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"created_at": bson.M{"$gte": lastHour},
-		"deleted_at": bson.M{"$exists": false},
-	})
+	}), guard.CountOptions())
 
 	// New reports in last hour
-	newReports, _ := h.db.Collection("reports").CountDocuments(ctx, bson.M{
+	newReports, _ := h.db.Collection("reports").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"created_at": bson.M{"$gte": lastHour},
-		"deleted_at": bson.M{"$exists": false},
-	})
+	}), guard.CountOptions())
 
 	analytics := gin.H{
 		"timestamp":    time.Now(),
@@ -5850,31 +5858,24 @@ func (h *AdminHandler) GetRealtimeAnalytics(c *gin.Context) {
 
 func (h *AdminHandler) GetLiveStats(c *gin.Context) {
 	ctx := c.Request.Context()
+	guard := h.adminService.QueryGuard()
 
 	// Get current statistics
-	totalUsers, _ := h.db.Collection("users").CountDocuments(ctx, bson.M{
-		"deleted_at": bson.M{"$exists": false},
-	})
+	totalUsers, _ := h.db.Collection("users").CountDocuments(ctx, repository.NotDeleted(), guard.CountOptions())
 
-	totalPosts, _ := h.db.Collection("posts").CountDocuments(ctx, bson.M{
-		"deleted_at": bson.M{"$exists": false},
-	})
+	totalPosts, _ := h.db.Collection("posts").CountDocuments(ctx, repository.NotDeleted(), guard.CountOptions())
 
-	totalComments, _ := h.db.Collection("comments").CountDocuments(ctx, bson.M{
-		"deleted_at": bson.M{"$exists": false},
-	})
+	totalComments, _ := h.db.Collection("comments").CountDocuments(ctx, repository.NotDeleted(), guard.CountOptions())
 
-	pendingReports, _ := h.db.Collection("reports").CountDocuments(ctx, bson.M{
-		"status":     models.ReportPending,
-		"deleted_at": bson.M{"$exists": false},
-	})
+	pendingReports, _ := h.db.Collection("reports").CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"status": models.ReportPending,
+	}), guard.CountOptions())
 
 	// Current online users (active in last 5 minutes)
 	fiveMinutesAgo := time.Now().Add(-5 * time.Minute)
-	onlineUsers, _ := h.db.Collection("users").CountDocuments(ctx, bson.M{
+	onlineUsers, _ := h.db.Collection("users").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"last_active_at": bson.M{"$gte": fiveMinutesAgo},
-		"deleted_at":     bson.M{"$exists": false},
-	})
+	}), guard.CountOptions())
 
 	stats := gin.H{
 		"timestamp":       time.Now(),
@@ -5939,8 +5940,12 @@ func (h *AdminHandler) GetSystemInfo(c *gin.Context) {
 }
 
 func (h *AdminHandler) GetSystemLogs(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	params, err := utils.ParsePagination(c, 50, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	page, limit := params.Page, params.Limit
 	level := c.DefaultQuery("level", "")
 	c.Query("start_date")
 	c.Query("end_date")
@@ -6009,6 +6014,8 @@ func (h *AdminHandler) GetPerformanceMetrics(c *gin.Context) {
 			"query_time_avg":   45.2,
 			"connections_used": 25,
 			"connections_max":  100,
+			"query_guard":      services.AdminQueryGuardStats(),
+			"read_routing":     repository.ReadRoutingStats(),
 		},
 		"memory_usage": gin.H{
 			"used_mb":       512,
@@ -6224,6 +6231,37 @@ func (h *AdminHandler) OptimizeDatabase(c *gin.Context) {
 	})
 }
 
+// Email Templates
+
+// GetEmailTemplates lists the transactional email types with their variables and languages
+func (h *AdminHandler) GetEmailTemplates(c *gin.Context) {
+	utils.OkResponse(c, "Email templates retrieved successfully", h.emailService.GetTemplateTypes())
+}
+
+// PreviewEmailTemplate renders an email template with sample data.
+// Pass ?format=html to get the HTML body on its own for viewing in a browser.
+func (h *AdminHandler) PreviewEmailTemplate(c *gin.Context) {
+	emailType := c.Param("type")
+	language := c.DefaultQuery("language", emails.DefaultLanguage)
+
+	rendered, err := h.emailService.PreviewTemplate(emailType, language)
+	if err != nil {
+		if strings.Contains(err.Error(), "unknown email type") {
+			utils.NotFoundResponse(c, "Email template not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to render email template", err)
+		return
+	}
+
+	if c.Query("format") == "html" {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(rendered.HTML))
+		return
+	}
+
+	utils.OkResponse(c, "Email template preview rendered successfully", rendered)
+}
+
 // Configuration Management
 func (h *AdminHandler) GetConfiguration(c *gin.Context) {
 	// This would read from actual configuration
@@ -6268,8 +6306,12 @@ func (h *AdminHandler) UpdateConfiguration(c *gin.Context) {
 }
 
 func (h *AdminHandler) GetConfigurationHistory(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	page, limit := params.Page, params.Limit
 
 	// This would get configuration change history
 	history := []gin.H{
@@ -6426,42 +6468,58 @@ func (h *AdminHandler) createPaginationLinks(c *gin.Context, pagination *utils.P
 		}
 	}
 
-	baseURL := c.Request.URL.Path
-	query := c.Request.URL.Query()
+	// Links keep every filter, search and sort parameter so paging doesn't reset them
+	return utils.CreatePaginationLinks(c, *pagination)
+}
 
-	links := &utils.PaginationLinks{
-		Self: baseURL + "?" + query.Encode(),
+// queryErrorResponse answers a failed admin query, reporting a query that hit
+// its time limit as a 504 instead of a generic server error
+func (h *AdminHandler) queryErrorResponse(c *gin.Context, endpoint, message string, err error) {
+	err = h.adminService.QueryGuard().CheckError(endpoint, err)
+	if strings.Contains(err.Error(), "query timed out") {
+		utils.QueryTimeoutResponse(c, err)
+		return
 	}
+	utils.InternalServerErrorResponse(c, message, err)
+}
 
-	if pagination.HasNext {
-		query.Set("page", strconv.Itoa(pagination.CurrentPage+1))
-		links.Next = baseURL + "?" + query.Encode()
+// analyticsStartDate resolves the "period" query parameter (e.g. "30d") to the
+// start of the analytics window. Unknown periods fall back to 30 days; periods
+// wider than the configured maximum are rejected with a pointer to the exports.
+func (h *AdminHandler) analyticsStartDate(c *gin.Context, period string) (time.Time, bool) {
+	days, err := strconv.Atoi(strings.TrimSuffix(period, "d"))
+	if err != nil || days < 1 {
+		days = 30
 	}
 
-	if pagination.HasPrevious {
-		query.Set("page", strconv.Itoa(pagination.CurrentPage-1))
-		links.Previous = baseURL + "?" + query.Encode()
+	now := time.Now()
+	startDate := now.AddDate(0, 0, -days)
+	if !h.checkAnalyticsRange(c, startDate, now) {
+		return time.Time{}, false
 	}
+	return startDate, true
+}
 
-	// Add first and last page links
-	if pagination.TotalPages > 0 {
-		query.Set("page", "1")
-		links.First = baseURL + "?" + query.Encode()
-
-		query.Set("page", strconv.Itoa(pagination.TotalPages))
-		links.Last = baseURL + "?" + query.Encode()
+// checkAnalyticsRange rejects date ranges the analytics endpoints won't scan
+func (h *AdminHandler) checkAnalyticsRange(c *gin.Context, startDate, endDate time.Time) bool {
+	if err := h.adminService.QueryGuard().CheckRange(startDate, endDate); err != nil {
+		utils.ErrorResponseWithDetails(c, http.StatusBadRequest, err.Error(), utils.ErrorCodeBadRequest, gin.H{
+			"max_range_days": h.adminService.QueryGuard().MaxRangeDays(),
+			"exports":        []string{"/api/v1/admin/users/export", "/api/v1/admin/posts/export"},
+		})
+		return false
 	}
+	return true
+}
 
-	return links
+// actingAdminID returns the ID of the admin making the request
+func actingAdminID(c *gin.Context) primitive.ObjectID {
+	adminID, _ := utils.LookupUserID(c)
+	return adminID
 }
 
 func (h *AdminHandler) logAdminActivity(c *gin.Context, activityType, description string) {
-	adminIDValue, exists := c.Get("user_id")
-	if !exists {
-		return
-	}
-
-	adminID, ok := adminIDValue.(primitive.ObjectID)
+	adminID, ok := utils.LookupUserID(c)
 	if !ok {
 		return
 	}
@@ -6470,7 +6528,7 @@ func (h *AdminHandler) logAdminActivity(c *gin.Context, activityType, descriptio
 		"admin_id":    adminID,
 		"type":        activityType,
 		"description": description,
-		"ip_address":  c.ClientIP(),
+		"ip_address":  utils.ClientIP(c),
 		"user_agent":  c.GetHeader("User-Agent"),
 		"timestamp":   time.Now(),
 		"created_at":  time.Now(),
@@ -6486,10 +6544,9 @@ func (h *AdminHandler) generateUserActivityReport(ctx context.Context, startDate
 	// Generate user activity report
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"last_active_at": bson.M{"$gte": startDate, "$lte": endDate},
-				"deleted_at":     bson.M{"$exists": false},
-			},
+			}),
 		},
 		{
 			"$group": bson.M{
@@ -6504,21 +6561,16 @@ func (h *AdminHandler) generateUserActivityReport(ctx context.Context, startDate
 		},
 	}
 
-	cursor, err := h.db.Collection("users").Aggregate(ctx, pipeline)
+	activityData, truncated, err := h.adminService.QueryGuard().AggregateAll(ctx, h.db.Collection("users"), "analytics.custom_report", pipeline)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
-
-	var activityData []bson.M
-	if err := cursor.All(ctx, &activityData); err != nil {
-		return nil, err
-	}
 
 	return gin.H{
 		"report_type":   "user_activity",
 		"period":        fmt.Sprintf("%s to %s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")),
 		"activity_data": activityData,
+		"truncated":     truncated,
 		"generated_at":  time.Now(),
 	}, nil
 }
@@ -6527,10 +6579,9 @@ func (h *AdminHandler) generateContentPerformanceReport(ctx context.Context, sta
 	// Generate content performance report
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"created_at": bson.M{"$gte": startDate, "$lte": endDate},
-				"deleted_at": bson.M{"$exists": false},
-			},
+			}),
 		},
 		{
 			"$lookup": bson.M{
@@ -6563,7 +6614,7 @@ func (h *AdminHandler) generateContentPerformanceReport(ctx context.Context, sta
 		},
 	}
 
-	cursor, err := h.db.Collection("posts").Aggregate(ctx, pipeline)
+	cursor, err := h.db.Collection("posts").Aggregate(ctx, pipeline, h.adminService.QueryGuard().AggregateOptions())
 	if err != nil {
 		return nil, err
 	}
@@ -6584,20 +6635,18 @@ func (h *AdminHandler) generateContentPerformanceReport(ctx context.Context, sta
 
 func (h *AdminHandler) generateEngagementSummaryReport(ctx context.Context, startDate, endDate time.Time, filters gin.H) (gin.H, error) {
 	// Generate engagement summary report
-	likesCount, _ := h.db.Collection("likes").CountDocuments(ctx, bson.M{
+	countOpts := h.adminService.QueryGuard().CountOptions()
+	likesCount, _ := h.db.Collection("likes").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"created_at": bson.M{"$gte": startDate, "$lte": endDate},
-		"deleted_at": bson.M{"$exists": false},
-	})
+	}), countOpts)
 
-	commentsCount, _ := h.db.Collection("comments").CountDocuments(ctx, bson.M{
+	commentsCount, _ := h.db.Collection("comments").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"created_at": bson.M{"$gte": startDate, "$lte": endDate},
-		"deleted_at": bson.M{"$exists": false},
-	})
+	}), countOpts)
 
-	followsCount, _ := h.db.Collection("follows").CountDocuments(ctx, bson.M{
+	followsCount, _ := h.db.Collection("follows").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"created_at": bson.M{"$gte": startDate, "$lte": endDate},
-		"deleted_at": bson.M{"$exists": false},
-	})
+	}), countOpts)
 
 	return gin.H{
 		"report_type":      "engagement_summary",
@@ -6665,12 +6714,11 @@ func (h *AdminHandler) AdminLogin(c *gin.Context) {
 
 	// Find admin user by email
 	var user models.User
-	err := h.db.Collection("users").FindOne(ctx, bson.M{
-		"email":      req.Email,
-		"role":       bson.M{"$in": []string{"admin", "super_admin"}}, // Only admin/super_admin can login
-		"is_active":  true,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&user)
+	err := h.db.Collection("users").FindOne(ctx, repository.NotDeleted(bson.M{
+		"email":     req.Email,
+		"role":      bson.M{"$in": []string{"admin", "super_admin"}}, // Only admin/super_admin can login
+		"is_active": true,
+	})).Decode(&user)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -6695,7 +6743,7 @@ func (h *AdminHandler) AdminLogin(c *gin.Context) {
 
 	// Generate real JWT tokens using AuthService
 	sessionID := primitive.NewObjectID().Hex()
-	accessToken, refreshToken, err := h.authService.GenerateTokens(&user, sessionID, c.GetHeader("User-Agent"), c.ClientIP())
+	accessToken, refreshToken, err := h.authService.GenerateTokens(&user, sessionID, c.GetHeader("User-Agent"), utils.ClientIP(c))
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to generate tokens", err)
 		return
@@ -6706,7 +6754,7 @@ func (h *AdminHandler) AdminLogin(c *gin.Context) {
 		"user_id":          user.ID,
 		"session_id":       sessionID,
 		"device_info":      c.GetHeader("User-Agent"),
-		"ip_address":       c.ClientIP(),
+		"ip_address":       utils.ClientIP(c),
 		"is_active":        true,
 		"last_activity_at": time.Now(),
 		"expires_at":       time.Now().Add(24 * time.Hour),
@@ -6894,13 +6942,12 @@ func (h *AdminHandler) CreateUser(c *gin.Context) {
 	ctx := c.Request.Context()
 
 	// Check if username or email already exists
-	existingCount, err := h.db.Collection("users").CountDocuments(ctx, bson.M{
+	existingCount, err := h.db.Collection("users").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"$or": []bson.M{
 			{"username": req.Username},
 			{"email": req.Email},
 		},
-		"deleted_at": bson.M{"$exists": false},
-	})
+	}))
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to check existing user", err)
 		return
@@ -6999,11 +7046,10 @@ func (h *AdminHandler) UpdateUser(c *gin.Context) {
 
 	if req.Username != nil {
 		// Check if username is already taken by another user
-		existingCount, err := h.db.Collection("users").CountDocuments(ctx, bson.M{
-			"username":   *req.Username,
-			"_id":        bson.M{"$ne": objID},
-			"deleted_at": bson.M{"$exists": false},
-		})
+		existingCount, err := h.db.Collection("users").CountDocuments(ctx, repository.NotDeleted(bson.M{
+			"username": *req.Username,
+			"_id":      bson.M{"$ne": objID},
+		}))
 		if err != nil {
 			utils.InternalServerErrorResponse(c, "Failed to check username availability", err)
 			return
@@ -7017,11 +7063,10 @@ func (h *AdminHandler) UpdateUser(c *gin.Context) {
 
 	if req.Email != nil {
 		// Check if email is already taken by another user
-		existingCount, err := h.db.Collection("users").CountDocuments(ctx, bson.M{
-			"email":      *req.Email,
-			"_id":        bson.M{"$ne": objID},
-			"deleted_at": bson.M{"$exists": false},
-		})
+		existingCount, err := h.db.Collection("users").CountDocuments(ctx, repository.NotDeleted(bson.M{
+			"email": *req.Email,
+			"_id":   bson.M{"$ne": objID},
+		}))
 		if err != nil {
 			utils.InternalServerErrorResponse(c, "Failed to check email availability", err)
 			return
@@ -7056,7 +7101,7 @@ func (h *AdminHandler) UpdateUser(c *gin.Context) {
 	// Update user
 	result, err := h.db.Collection("users").UpdateOne(
 		ctx,
-		bson.M{"_id": objID, "deleted_at": bson.M{"$exists": false}},
+		repository.ByID(objID),
 		bson.M{"$set": updateDoc},
 	)
 	if err != nil {
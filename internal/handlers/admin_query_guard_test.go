@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func newTestAdminHandler(h *testutil.Harness, policy services.AdminQueryPolicy) *AdminHandler {
+	return NewAdminHandler(services.NewAdminService(h.DB, policy), nil, nil, nil, h.DB)
+}
+
+// TestAnalyticsQueryTimeoutAnswers504 runs an analytics pipeline whose time
+// runs out and checks the request ends promptly with a structured 504
+// rather than hanging or answering a generic 500
+func TestAnalyticsQueryTimeoutAnswers504(t *testing.T) {
+	h := testutil.NewHarness(t)
+	admin := h.CreateUser(testutil.WithRole(models.RoleAdmin))
+	handler := newTestAdminHandler(h, services.AdminQueryPolicy{MaxTime: time.Second, MaxResults: 100, MaxRangeDays: 365})
+
+	c, recorder := h.AuthenticatedContext(admin, http.MethodGet, "/api/v1/admin/analytics/engagement?period=30d", nil)
+	ctx, cancel := context.WithDeadline(c.Request.Context(), time.Now().Add(-time.Second))
+	defer cancel()
+	c.Request = c.Request.WithContext(ctx)
+
+	before := services.AdminQueryGuardStats()["timeout:analytics.engagement"]
+	started := time.Now()
+	handler.GetEngagementAnalytics(c)
+
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("timed-out query took %s to answer", elapsed)
+	}
+	if recorder.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want 504: %s", recorder.Code, recorder.Body.String())
+	}
+
+	var response utils.Response
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("response isn't JSON: %v", err)
+	}
+	if response.Success || response.ErrorCode != utils.ErrorCodeQueryTimeout || response.Message == "" {
+		t.Errorf("response = success %v, error_code %q, message %q, want a QUERY_TIMEOUT error", response.Success, response.ErrorCode, response.Message)
+	}
+
+	if got := services.AdminQueryGuardStats()["timeout:analytics.engagement"]; got != before+1 {
+		t.Errorf("timeout metric = %d, want %d", got, before+1)
+	}
+}
+
+// TestAnalyticsTruncationIsReported checks an analytics response capped by
+// MaxResults says so
+func TestAnalyticsTruncationIsReported(t *testing.T) {
+	h := testutil.NewHarness(t)
+	admin := h.CreateUser(testutil.WithRole(models.RoleAdmin))
+
+	// Likes of two target types make two engagement groups
+	for _, targetType := range []string{"post", "comment"} {
+		if _, err := h.DB.Collection("likes").InsertOne(h.Context(), bson.M{"target_type": targetType, "created_at": time.Now()}); err != nil {
+			t.Fatalf("seeding likes: %v", err)
+		}
+	}
+
+	tests := []struct {
+		maxResults int
+		truncated  bool
+		groups     int
+	}{
+		{1, true, 1},
+		{2, false, 2},
+	}
+
+	for _, tt := range tests {
+		handler := newTestAdminHandler(h, services.AdminQueryPolicy{MaxTime: 5 * time.Second, MaxResults: tt.maxResults, MaxRangeDays: 365})
+		c, recorder := h.AuthenticatedContext(admin, http.MethodGet, "/api/v1/admin/analytics/engagement", nil)
+		handler.GetEngagementAnalytics(c)
+
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("MaxResults %d: status = %d: %s", tt.maxResults, recorder.Code, recorder.Body.String())
+		}
+
+		var response struct {
+			Data struct {
+				EngagementData []bson.M `json:"engagement_data"`
+				Truncated      bool     `json:"truncated"`
+				MaxResults     int      `json:"max_results"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatalf("response isn't JSON: %v", err)
+		}
+		if response.Data.Truncated != tt.truncated || len(response.Data.EngagementData) != tt.groups || response.Data.MaxResults != tt.maxResults {
+			t.Errorf("MaxResults %d: truncated %v with %d groups and max_results %d, want truncated %v with %d groups",
+				tt.maxResults, response.Data.Truncated, len(response.Data.EngagementData), response.Data.MaxResults, tt.truncated, tt.groups)
+		}
+	}
+}
+
+// TestAdminListsAndStatsAnswer504 checks the admin lists and stats run
+// under the query guard, answering a query out of time with a 504 and
+// counting it against the endpoint
+func TestAdminListsAndStatsAnswer504(t *testing.T) {
+	h := testutil.NewHarness(t)
+	admin := h.CreateUser(testutil.WithRole(models.RoleAdmin))
+	handler := newTestAdminHandler(h, services.AdminQueryPolicy{MaxTime: time.Second, MaxResults: 100, MaxRangeDays: 365})
+
+	tests := []struct {
+		endpoint string
+		serve    func(*gin.Context)
+	}{
+		{"messages.list", handler.GetAllMessages},
+		{"follows.list", handler.GetAllFollows},
+		{"notifications.list", handler.GetAllNotifications},
+		{"likes.stats", handler.GetLikeStats},
+		{"media.stats", handler.GetMediaStats},
+		{"reports.stats", handler.GetReportStats},
+		{"reports.summary", handler.GetReportSummary},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			c, recorder := h.AuthenticatedContext(admin, http.MethodGet, "/", nil)
+			ctx, cancel := context.WithDeadline(c.Request.Context(), time.Now().Add(-time.Second))
+			defer cancel()
+			c.Request = c.Request.WithContext(ctx)
+
+			before := services.AdminQueryGuardStats()["timeout:"+tt.endpoint]
+			tt.serve(c)
+
+			if recorder.Code != http.StatusGatewayTimeout {
+				t.Errorf("status = %d, want 504: %s", recorder.Code, recorder.Body.String())
+			}
+			if got := services.AdminQueryGuardStats()["timeout:"+tt.endpoint]; got != before+1 {
+				t.Errorf("timeout metric = %d, want %d", got, before+1)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+// internal/handlers/analytics_export.go
+package handlers
+
+import (
+	"strings"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type AnalyticsExportHandler struct {
+	exportService *services.AnalyticsExportService
+	validator     *validator.Validate
+}
+
+func NewAnalyticsExportHandler(exportService *services.AnalyticsExportService) *AnalyticsExportHandler {
+	return &AnalyticsExportHandler{
+		exportService: exportService,
+		validator:     validator.New(),
+	}
+}
+
+// StartExport queues a behavior analytics export that runs in the background
+func (h *AnalyticsExportHandler) StartExport(c *gin.Context) {
+	adminID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.StartAnalyticsExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	job, err := h.exportService.StartJob(adminID, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid export") {
+			utils.BadRequestResponse(c, err.Error(), err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to start export", err)
+		return
+	}
+
+	utils.AcceptedResponse(c, "Export started", job)
+}
+
+// GetExports lists behavior analytics exports, newest first
+func (h *AnalyticsExportHandler) GetExports(c *gin.Context) {
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	jobs, total, err := h.exportService.ListJobs(params.Limit, params.Offset)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get exports", err)
+		return
+	}
+
+	paginationMeta := utils.CreatePaginationMeta(params, total)
+	utils.PaginatedSuccessResponse(c, "Exports retrieved successfully", jobs, paginationMeta, nil)
+}
+
+// GetExport returns an export's progress and, once completed, its download links
+func (h *AnalyticsExportHandler) GetExport(c *gin.Context) {
+	job, err := h.exportService.GetJob(c.Param("id"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Export not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get export", err)
+		return
+	}
+
+	utils.OkResponse(c, "Export retrieved successfully", job)
+}
+
+// DownloadExportFile serves one file of a completed export
+func (h *AnalyticsExportHandler) DownloadExportFile(c *gin.Context) {
+	name := c.Param("name")
+	path, err := h.exportService.JobFilePath(c.Param("id"), name)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "expired") {
+			utils.NotFoundResponse(c, "Export file not found")
+			return
+		}
+		if strings.Contains(err.Error(), "not completed") {
+			utils.ConflictResponse(c, "Export is not completed yet", err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get export file", err)
+		return
+	}
+
+	c.FileAttachment(path, name)
+}
@@ -2,6 +2,8 @@
 package handlers
 
 import (
+	"log"
+	"net/http"
 	"strings"
 
 	"social-media-api/internal/models"
@@ -14,16 +16,20 @@ import (
 )
 
 type AuthHandler struct {
-	authService *services.AuthService
-	userService *services.UserService
-	validator   *validator.Validate
+	authService   *services.AuthService
+	userService   *services.UserService
+	followService *services.FollowService
+	pushService   *services.PushService
+	validator     *validator.Validate
 }
 
-func NewAuthHandler(authService *services.AuthService, userService *services.UserService) *AuthHandler {
+func NewAuthHandler(authService *services.AuthService, userService *services.UserService, followService *services.FollowService, pushService *services.PushService) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
-		userService: userService,
-		validator:   validator.New(),
+		authService:   authService,
+		userService:   userService,
+		followService: followService,
+		pushService:   pushService,
+		validator:     validator.New(),
 	}
 }
 
@@ -53,10 +59,15 @@ func (h *AuthHandler) Register(c *gin.Context) {
 			utils.ConflictResponse(c, err.Error(), err)
 			return
 		}
+		if strings.Contains(err.Error(), "invalid interest") {
+			utils.BadRequestResponse(c, err.Error(), err)
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to register user", err)
 		return
 	}
 
+	h.addOnboardingSuggestions(response)
 	utils.CreatedResponse(c, "User registered successfully", response)
 }
 
@@ -90,16 +101,34 @@ func (h *AuthHandler) Login(c *gin.Context) {
 			utils.ForbiddenResponse(c, "Account is suspended")
 			return
 		}
+		if strings.Contains(err.Error(), "deactivated") {
+			utils.ErrorResponseWithCode(c, http.StatusForbidden, "Account is deactivated. Log in again with reactivate set to true to reactivate it now", utils.ErrorCodeAccountDeactivated, nil)
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Login failed", err)
 		return
 	}
 
-	utils.LoginSuccessResponse(c, response.User, gin.H{
+	tokens := gin.H{
 		"access_token":  response.AccessToken,
 		"refresh_token": response.RefreshToken,
 		"expires_in":    response.ExpiresIn,
 		"token_type":    response.TokenType,
-	})
+	}
+
+	// New accounts start with an empty feed; give them accounts to follow
+	if response.FirstLogin {
+		h.addOnboardingSuggestions(response)
+		utils.SuccessResponse(c, http.StatusOK, "Login successful", gin.H{
+			"user":                   response.User,
+			"tokens":                 tokens,
+			"first_login":            true,
+			"onboarding_suggestions": response.OnboardingSuggestions,
+		})
+		return
+	}
+
+	utils.LoginSuccessResponse(c, response.User, tokens)
 }
 
 // RefreshToken handles token refresh
@@ -126,7 +155,8 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	utils.OkResponse(c, "Tokens refreshed successfully", response)
 }
 
-// Logout handles user logout
+// Logout handles user logout. scope=current (the default) signs out only the
+// current account; scope=all-on-device signs out every account on the device.
 func (h *AuthHandler) Logout(c *gin.Context) {
 	// Get session ID from context (set by auth middleware)
 	sessionID, exists := c.Get("session_id")
@@ -135,8 +165,21 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		return
 	}
 
-	err := h.authService.Logout(sessionID.(string))
+	var err error
+	switch c.DefaultQuery("scope", models.LogoutScopeCurrent) {
+	case models.LogoutScopeCurrent:
+		err = h.authService.Logout(sessionID.(string))
+	case models.LogoutScopeAllOnDevice:
+		err = h.authService.LogoutDevice(sessionID.(string))
+	default:
+		utils.BadRequestResponse(c, "Invalid scope, must be current or all-on-device", nil)
+		return
+	}
 	if err != nil {
+		if strings.Contains(err.Error(), "invalid session") {
+			utils.UnauthorizedResponse(c, "No active session")
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to logout", err)
 		return
 	}
@@ -144,15 +187,176 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	utils.LogoutSuccessResponse(c)
 }
 
+// AddAccount signs an additional account in on the current device without
+// signing out the accounts already there
+func (h *AuthHandler) AddAccount(c *gin.Context) {
+	sessionID, exists := c.Get("session_id")
+	if !exists {
+		utils.UnauthorizedResponse(c, "No active session")
+		return
+	}
+
+	var req models.AddAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	response, err := h.authService.AddAccount(sessionID.(string), req)
+	if err != nil {
+		h.deviceAccountErrorResponse(c, "Failed to add account", err)
+		return
+	}
+
+	utils.OkResponse(c, "Account added successfully", response)
+}
+
+// GetDeviceAccounts lists the accounts signed in on the current device
+func (h *AuthHandler) GetDeviceAccounts(c *gin.Context) {
+	sessionID, exists := c.Get("session_id")
+	if !exists {
+		utils.UnauthorizedResponse(c, "No active session")
+		return
+	}
+
+	accounts, err := h.authService.GetDeviceAccounts(sessionID.(string))
+	if err != nil {
+		h.deviceAccountErrorResponse(c, "Failed to get accounts", err)
+		return
+	}
+
+	utils.OkResponse(c, "Accounts retrieved successfully", accounts)
+}
+
+// SwitchAccount exchanges the current tokens for those of another account on
+// the device. The password is only needed when that account has no session
+// on the device anymore.
+func (h *AuthHandler) SwitchAccount(c *gin.Context) {
+	sessionID, exists := c.Get("session_id")
+	if !exists {
+		utils.UnauthorizedResponse(c, "No active session")
+		return
+	}
+
+	targetUserID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID", err)
+		return
+	}
+
+	var req models.SwitchAccountRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BadRequestResponse(c, "Invalid request format", err)
+			return
+		}
+	}
+
+	response, err := h.authService.SwitchAccount(sessionID.(string), targetUserID, req.Password)
+	if err != nil {
+		h.deviceAccountErrorResponse(c, "Failed to switch account", err)
+		return
+	}
+
+	utils.OkResponse(c, "Account switched successfully", response)
+}
+
+func (h *AuthHandler) deviceAccountErrorResponse(c *gin.Context, message string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "credentials required"):
+		utils.ErrorResponseWithCode(c, http.StatusUnauthorized, "This account is no longer signed in on this device. Enter its password to switch", utils.ErrorCodeCredentialsRequired, nil)
+	case strings.Contains(err.Error(), "invalid credentials"):
+		utils.UnauthorizedResponse(c, "Invalid email/username or password")
+	case strings.Contains(err.Error(), "invalid session"):
+		utils.UnauthorizedResponse(c, "No active session")
+	case strings.Contains(err.Error(), "suspended"):
+		utils.ForbiddenResponse(c, "Account is suspended")
+	case strings.Contains(err.Error(), "deactivated"):
+		utils.ErrorResponseWithCode(c, http.StatusForbidden, "Account is deactivated. Log in to it directly to reactivate it", utils.ErrorCodeAccountDeactivated, nil)
+	case strings.Contains(err.Error(), "not found"):
+		utils.NotFoundResponse(c, "Account not found")
+	case strings.Contains(err.Error(), "already signed in"), strings.Contains(err.Error(), "limit reached"):
+		utils.ConflictResponse(c, err.Error(), err)
+	case strings.Contains(err.Error(), "invalid"):
+		utils.BadRequestResponse(c, err.Error(), err)
+	default:
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+}
+
+// RegisterPushToken registers the device's push token for the current
+// account. Every account on a device registers the same token separately.
+func (h *AuthHandler) RegisterPushToken(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	sessionID, _ := c.Get("session_id")
+
+	var req models.RegisterPushTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	sid, _ := sessionID.(string)
+	err := h.pushService.RegisterPushToken(userID, sid, req.Token, req.Platform, c.GetHeader("User-Agent"))
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid session") {
+			utils.UnauthorizedResponse(c, "No active session")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to register push token", err)
+		return
+	}
+
+	utils.OkResponse(c, "Push token registered successfully", nil)
+}
+
+// RemovePushToken stops pushes to the device for the current account
+func (h *AuthHandler) RemovePushToken(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.RemovePushTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	if err := h.pushService.RemoveUserPushToken(userID, req.Token); err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to remove push token", err)
+		return
+	}
+
+	utils.OkResponse(c, "Push token removed successfully", nil)
+}
+
 // LogoutAll handles logout from all devices
 func (h *AuthHandler) LogoutAll(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	err := h.authService.LogoutAll(userID.(primitive.ObjectID))
+	err := h.authService.LogoutAll(userID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to logout from all devices", err)
 		return
@@ -238,26 +442,24 @@ func (h *AuthHandler) VerifyEmail(c *gin.Context) {
 
 // GetProfile returns current user profile
 func (h *AuthHandler) GetProfile(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	user, err := h.userService.GetUserByID(userID.(primitive.ObjectID))
+	user, err := h.userService.GetUserByID(userID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get user profile", err)
 		return
 	}
 
-	utils.OkResponse(c, "Profile retrieved successfully", user.ToUserResponse())
+	utils.OkResponse(c, "Profile retrieved successfully", user.ToOwnUserResponse())
 }
 
 // UpdateProfile updates user profile
 func (h *AuthHandler) UpdateProfile(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -267,8 +469,11 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.UpdateUser(userID.(primitive.ObjectID), req)
+	user, err := h.userService.UpdateUser(userID, req)
 	if err != nil {
+		if respondLimitExceeded(c, err) {
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to update profile", err)
 		return
 	}
@@ -278,9 +483,8 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 
 // ChangePassword handles password change
 func (h *AuthHandler) ChangePassword(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -295,7 +499,7 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	err := h.userService.ChangePassword(userID.(primitive.ObjectID), req)
+	err := h.userService.ChangePassword(userID, req)
 	if err != nil {
 		if strings.Contains(err.Error(), "incorrect") {
 			utils.BadRequestResponse(c, err.Error(), err)
@@ -314,13 +518,12 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 
 // GetSessions returns user's active sessions
 func (h *AuthHandler) GetSessions(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	sessions, err := h.authService.GetUserSessions(userID.(primitive.ObjectID))
+	sessions, err := h.authService.GetUserSessions(userID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get sessions", err)
 		return
@@ -345,3 +548,19 @@ func (h *AuthHandler) RevokeSession(c *gin.Context) {
 
 	utils.OkResponse(c, "Session revoked successfully", nil)
 }
+
+// addOnboardingSuggestions attaches follow suggestions to a first session.
+// Suggestions are best effort and never fail the login.
+func (h *AuthHandler) addOnboardingSuggestions(response *services.LoginResponse) {
+	userID, err := primitive.ObjectIDFromHex(response.User.ID)
+	if err != nil {
+		return
+	}
+
+	suggestions, err := h.followService.GetOnboardingSuggestions(userID)
+	if err != nil {
+		log.Printf("Failed to get onboarding suggestions for user %s: %v", response.User.ID, err)
+		return
+	}
+	response.OnboardingSuggestions = suggestions
+}
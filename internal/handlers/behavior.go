@@ -3,7 +3,7 @@ package handlers
 
 import (
 	"fmt"
-	"strconv"
+	"strings"
 	"time"
 
 	"social-media-api/internal/models"
@@ -32,9 +32,8 @@ func NewUserBehaviorHandler(behaviorService *services.UserBehaviorService, analy
 
 // TrackPageView tracks when user visits a page
 func (h *UserBehaviorHandler) TrackPageView(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -57,7 +56,7 @@ func (h *UserBehaviorHandler) TrackPageView(c *gin.Context) {
 		Referrer:  req.Referrer,
 	}
 
-	err := h.behaviorService.RecordPageVisit(userID.(primitive.ObjectID), req.SessionID, pageVisit)
+	err := h.behaviorService.RecordPageVisit(userID, req.SessionID, pageVisit)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to track page view", err)
 		return
@@ -72,9 +71,8 @@ func (h *UserBehaviorHandler) TrackPageView(c *gin.Context) {
 
 // TrackUserAction tracks user interactions (clicks, scrolls, etc.)
 func (h *UserBehaviorHandler) TrackUserAction(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -97,7 +95,7 @@ func (h *UserBehaviorHandler) TrackUserAction(c *gin.Context) {
 		Metadata:  req.Metadata,
 	}
 
-	err := h.behaviorService.RecordUserAction(userID.(primitive.ObjectID), req.SessionID, action)
+	err := h.behaviorService.RecordUserAction(userID, req.SessionID, action)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to track action", err)
 		return
@@ -112,9 +110,8 @@ func (h *UserBehaviorHandler) TrackUserAction(c *gin.Context) {
 
 // TrackContentEngagement tracks detailed content interaction
 func (h *UserBehaviorHandler) TrackContentEngagement(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -140,7 +137,7 @@ func (h *UserBehaviorHandler) TrackContentEngagement(c *gin.Context) {
 	}
 
 	engagement := models.ContentEngagement{
-		UserID:       userID.(primitive.ObjectID),
+		UserID:       userID,
 		ContentID:    contentID,
 		ContentType:  req.ContentType,
 		ViewTime:     time.Now(),
@@ -171,11 +168,52 @@ func (h *UserBehaviorHandler) TrackContentEngagement(c *gin.Context) {
 	})
 }
 
+// RecordImpressions records a batch of posts the client reports as having
+// been on screen, with how long each was visible and whether it was engaged
+func (h *UserBehaviorHandler) RecordImpressions(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.RecordImpressionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	if len(req.Impressions) > models.MaxImpressionsPerBatch {
+		utils.BadRequestResponse(c, fmt.Sprintf("Cannot record more than %d impressions at once", models.MaxImpressionsPerBatch), nil)
+		return
+	}
+
+	if req.SessionID == "" {
+		if sessionID, exists := c.Get("session_id"); exists {
+			req.SessionID, _ = sessionID.(string)
+		}
+	}
+
+	recorded, err := h.behaviorService.RecordImpressions(userID, req.SessionID, req.Impressions)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to record impressions", err)
+		return
+	}
+
+	utils.OkResponse(c, "Impressions recorded successfully", gin.H{
+		"received": len(req.Impressions),
+		"recorded": recorded,
+	})
+}
+
 // StartSession starts a new user session
 func (h *UserBehaviorHandler) StartSession(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -190,14 +228,14 @@ func (h *UserBehaviorHandler) StartSession(c *gin.Context) {
 		return
 	}
 
-	ipAddress := c.ClientIP()
+	ipAddress := utils.ClientIP(c)
 	userAgent := req.UserAgent
 	if userAgent == "" {
 		userAgent = c.GetHeader("User-Agent")
 	}
 
 	err := h.behaviorService.StartSession(
-		userID.(primitive.ObjectID),
+		userID,
 		req.SessionID,
 		req.DeviceInfo,
 		ipAddress,
@@ -218,9 +256,8 @@ func (h *UserBehaviorHandler) StartSession(c *gin.Context) {
 
 // EndSession ends a user session
 func (h *UserBehaviorHandler) EndSession(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -242,22 +279,21 @@ func (h *UserBehaviorHandler) EndSession(c *gin.Context) {
 	utils.OkResponse(c, "Session ended successfully", gin.H{
 		"session_id": req.SessionID,
 		"ended_at":   time.Now(),
-		"user_id":    userID.(primitive.ObjectID).Hex(),
+		"user_id":    userID.Hex(),
 	})
 }
 
 // GetUserBehaviorAnalytics returns comprehensive user behavior analytics
 func (h *UserBehaviorHandler) GetUserBehaviorAnalytics(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	timeRange := c.DefaultQuery("time_range", "week") // day, week, month
 
 	analytics, err := h.behaviorService.GetUserBehaviorAnalytics(
-		userID.(primitive.ObjectID),
+		userID,
 		timeRange,
 	)
 	if err != nil {
@@ -266,29 +302,141 @@ func (h *UserBehaviorHandler) GetUserBehaviorAnalytics(c *gin.Context) {
 	}
 
 	utils.OkResponse(c, "Analytics retrieved successfully", gin.H{
-		"user_id":      userID.(primitive.ObjectID).Hex(),
+		"user_id":      userID.Hex(),
 		"time_range":   timeRange,
 		"analytics":    analytics,
 		"generated_at": time.Now(),
 	})
 }
 
+// GetMyInsights returns the current user's activity summary
+func (h *UserBehaviorHandler) GetMyInsights(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	insights, err := h.analyticsService.GetUserInsights(userID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get activity insights", err)
+		return
+	}
+
+	utils.OkResponse(c, "Activity insights retrieved successfully", insights)
+}
+
+// GetAudienceActivity returns when the current user's followers are most active
+func (h *UserBehaviorHandler) GetAudienceActivity(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	activity, err := h.analyticsService.GetAudienceActivity(userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "User not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get audience activity", err)
+		return
+	}
+
+	utils.OkResponse(c, "Audience activity retrieved successfully", activity)
+}
+
+// GetMyCreatorSummary returns the engagement the current user's posts
+// received over the period
+func (h *UserBehaviorHandler) GetMyCreatorSummary(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	h.respondCreatorSummary(c, userID)
+}
+
+// GetCreatorSummary returns the creator summary of any user (admin only)
+func (h *UserBehaviorHandler) GetCreatorSummary(c *gin.Context) {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID format", err)
+		return
+	}
+
+	h.respondCreatorSummary(c, userID)
+}
+
+func (h *UserBehaviorHandler) respondCreatorSummary(c *gin.Context, userID primitive.ObjectID) {
+	period := c.DefaultQuery("period", "month") // day, week, month, year
+
+	summary, err := h.analyticsService.GetCreatorSummary(userID, period)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid period") {
+			utils.BadRequestResponse(c, err.Error(), nil)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get creator summary", err)
+		return
+	}
+
+	utils.OkResponse(c, "Creator summary retrieved successfully", summary)
+}
+
+// GetUserInsights returns the activity summary of any user (admin only)
+func (h *UserBehaviorHandler) GetUserInsights(c *gin.Context) {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID format", err)
+		return
+	}
+
+	insights, err := h.analyticsService.GetUserInsights(userID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get activity insights", err)
+		return
+	}
+
+	utils.OkResponse(c, "Activity insights retrieved successfully", insights)
+}
+
+// GetTranslationUsage returns platform-wide translation usage per language pair
+func (h *UserBehaviorHandler) GetTranslationUsage(c *gin.Context) {
+	timeRange := c.DefaultQuery("time_range", "month") // day, week, month, year
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	usage, err := h.analyticsService.GetTranslationUsage(timeRange, params.Limit)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get translation usage", err)
+		return
+	}
+
+	utils.OkResponse(c, "Translation usage retrieved successfully", gin.H{
+		"time_range":     timeRange,
+		"language_pairs": usage,
+		"generated_at":   time.Now(),
+	})
+}
+
 // GetUserContentPreferences returns user's content preferences
 func (h *UserBehaviorHandler) GetUserContentPreferences(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	preferences, err := h.behaviorService.GetUserContentPreferences(userID.(primitive.ObjectID))
+	preferences, err := h.behaviorService.GetUserContentPreferences(userID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get content preferences", err)
 		return
 	}
 
 	utils.OkResponse(c, "Content preferences retrieved successfully", gin.H{
-		"user_id":      userID.(primitive.ObjectID).Hex(),
+		"user_id":      userID.Hex(),
 		"preferences":  preferences,
 		"retrieved_at": time.Now(),
 	})
@@ -296,20 +444,18 @@ func (h *UserBehaviorHandler) GetUserContentPreferences(c *gin.Context) {
 
 // GetSimilarUsers returns users with similar behavior patterns
 func (h *UserBehaviorHandler) GetSimilarUsers(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	limit := 10
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 50 {
-			limit = l
-		}
+	limit, err := utils.ParseLimit(c, 10, 50)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
 	}
 
-	similarUsers, err := h.behaviorService.GetSimilarUsers(userID.(primitive.ObjectID), limit)
+	similarUsers, err := h.behaviorService.GetSimilarUsers(userID, limit)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get similar users", err)
 		return
@@ -322,7 +468,7 @@ func (h *UserBehaviorHandler) GetSimilarUsers(c *gin.Context) {
 	}
 
 	utils.OkResponse(c, "Similar users retrieved successfully", gin.H{
-		"user_id":       userID.(primitive.ObjectID).Hex(),
+		"user_id":       userID.Hex(),
 		"similar_users": userIDs,
 		"count":         len(userIDs),
 		"limit":         limit,
@@ -331,9 +477,8 @@ func (h *UserBehaviorHandler) GetSimilarUsers(c *gin.Context) {
 
 // TrackRecommendation tracks recommendation performance
 func (h *UserBehaviorHandler) TrackRecommendation(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -358,7 +503,7 @@ func (h *UserBehaviorHandler) TrackRecommendation(c *gin.Context) {
 	}
 
 	event := models.RecommendationEvent{
-		UserID:             userID.(primitive.ObjectID),
+		UserID:             userID,
 		RecommendationType: req.RecommendationType,
 		ItemID:             itemID,
 		Algorithm:          req.Algorithm,
@@ -367,7 +512,7 @@ func (h *UserBehaviorHandler) TrackRecommendation(c *gin.Context) {
 		Presented:          time.Now(),
 	}
 
-	err = h.behaviorService.TrackRecommendation(userID.(primitive.ObjectID), event, req.Action)
+	err = h.behaviorService.TrackRecommendation(userID, event, req.Action)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to track recommendation", err)
 		return
@@ -383,9 +528,8 @@ func (h *UserBehaviorHandler) TrackRecommendation(c *gin.Context) {
 
 // TrackExperiment tracks A/B testing experiments
 func (h *UserBehaviorHandler) TrackExperiment(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -402,7 +546,7 @@ func (h *UserBehaviorHandler) TrackExperiment(c *gin.Context) {
 	}
 
 	err := h.behaviorService.TrackExperiment(
-		userID.(primitive.ObjectID),
+		userID,
 		req.ExperimentID,
 		req.VariantID,
 		req.Event,
@@ -424,9 +568,8 @@ func (h *UserBehaviorHandler) TrackExperiment(c *gin.Context) {
 
 // GetInterestScore gets user's interest score for specific content
 func (h *UserBehaviorHandler) GetInterestScore(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -437,14 +580,14 @@ func (h *UserBehaviorHandler) GetInterestScore(c *gin.Context) {
 		return
 	}
 
-	score, err := h.behaviorService.GetUserInterestScore(userID.(primitive.ObjectID), contentID)
+	score, err := h.behaviorService.GetUserInterestScore(userID, contentID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get interest score", err)
 		return
 	}
 
 	utils.OkResponse(c, "Interest score retrieved successfully", gin.H{
-		"user_id":       userID.(primitive.ObjectID).Hex(),
+		"user_id":       userID.Hex(),
 		"content_id":    contentIDStr,
 		"score":         score,
 		"calculated_at": time.Now(),
@@ -453,9 +596,8 @@ func (h *UserBehaviorHandler) GetInterestScore(c *gin.Context) {
 
 // RecordInteraction records generic user interaction for behavior learning
 func (h *UserBehaviorHandler) RecordInteraction(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -479,7 +621,7 @@ func (h *UserBehaviorHandler) RecordInteraction(c *gin.Context) {
 	}
 
 	err = h.behaviorService.RecordInteraction(
-		userID.(primitive.ObjectID),
+		userID,
 		contentID,
 		req.ContentType,
 		req.InteractionType,
@@ -502,23 +644,22 @@ func (h *UserBehaviorHandler) RecordInteraction(c *gin.Context) {
 
 // GetBehaviorInsights provides actionable insights based on user behavior
 func (h *UserBehaviorHandler) GetBehaviorInsights(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	timeRange := c.DefaultQuery("time_range", "week")
 
 	// Get comprehensive analytics
-	analytics, err := h.behaviorService.GetUserBehaviorAnalytics(userID.(primitive.ObjectID), timeRange)
+	analytics, err := h.behaviorService.GetUserBehaviorAnalytics(userID, timeRange)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get behavior insights", err)
 		return
 	}
 
 	// Get content preferences
-	preferences, err := h.behaviorService.GetUserContentPreferences(userID.(primitive.ObjectID))
+	preferences, err := h.behaviorService.GetUserContentPreferences(userID)
 	if err != nil {
 		preferences = make(map[string]float64) // Fallback to empty preferences
 	}
@@ -527,7 +668,7 @@ func (h *UserBehaviorHandler) GetBehaviorInsights(c *gin.Context) {
 	insights := h.generateBehaviorInsights(analytics, preferences)
 
 	utils.OkResponse(c, "Behavior insights retrieved successfully", gin.H{
-		"user_id":      userID.(primitive.ObjectID).Hex(),
+		"user_id":      userID.Hex(),
 		"time_range":   timeRange,
 		"insights":     insights,
 		"analytics":    analytics,
@@ -0,0 +1,181 @@
+// internal/handlers/boosted_post.go
+package handlers
+
+import (
+	"strings"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type BoostedPostHandler struct {
+	boostedPostService *services.BoostedPostService
+	validator          *validator.Validate
+}
+
+func NewBoostedPostHandler(boostedPostService *services.BoostedPostService) *BoostedPostHandler {
+	return &BoostedPostHandler{
+		boostedPostService: boostedPostService,
+		validator:          validator.New(),
+	}
+}
+
+// Admin handlers
+
+// GetBoostedPosts lists boost campaigns, optionally filtered by status
+func (h *BoostedPostHandler) GetBoostedPosts(c *gin.Context) {
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	status := c.Query("status")
+	if status != "" && status != string(models.BoostStatusActive) && status != string(models.BoostStatusPaused) {
+		utils.BadRequestResponse(c, "Invalid status filter", nil)
+		return
+	}
+
+	boosts, total, err := h.boostedPostService.GetBoostedPosts(status, params.Limit, params.Offset)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get boosted posts", err)
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, "Boosted posts retrieved successfully", boosts, utils.CreatePaginationMeta(params, total), nil)
+}
+
+// CreateBoostedPost starts a boost campaign for a post
+func (h *BoostedPostHandler) CreateBoostedPost(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateBoostedPostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	boost, err := h.boostedPostService.CreateBoostedPost(userID, req)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not eligible"):
+			utils.NotFoundResponse(c, "Post not found or not eligible for boosting")
+		case strings.Contains(err.Error(), "invalid"):
+			utils.BadRequestResponse(c, err.Error(), nil)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to create boosted post", err)
+		}
+		return
+	}
+
+	utils.CreatedResponse(c, "Boosted post created successfully", boost)
+}
+
+// GetBoostedPost returns one boost campaign
+func (h *BoostedPostHandler) GetBoostedPost(c *gin.Context) {
+	boostID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid boosted post ID format", err)
+		return
+	}
+
+	boost, err := h.boostedPostService.GetBoostedPost(boostID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Boosted post not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get boosted post", err)
+		return
+	}
+
+	utils.OkResponse(c, "Boosted post retrieved successfully", boost)
+}
+
+// UpdateBoostedPost pauses or resumes a campaign or changes its schedule and limits
+func (h *BoostedPostHandler) UpdateBoostedPost(c *gin.Context) {
+	boostID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid boosted post ID format", err)
+		return
+	}
+
+	var req models.UpdateBoostedPostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	boost, err := h.boostedPostService.UpdateBoostedPost(boostID, req)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			utils.NotFoundResponse(c, "Boosted post not found")
+		case strings.Contains(err.Error(), "invalid"):
+			utils.BadRequestResponse(c, err.Error(), nil)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to update boosted post", err)
+		}
+		return
+	}
+
+	utils.OkResponse(c, "Boosted post updated successfully", boost)
+}
+
+// DeleteBoostedPost ends a boost campaign
+func (h *BoostedPostHandler) DeleteBoostedPost(c *gin.Context) {
+	boostID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid boosted post ID format", err)
+		return
+	}
+
+	if err := h.boostedPostService.DeleteBoostedPost(boostID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Boosted post not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to delete boosted post", err)
+		return
+	}
+
+	utils.OkResponse(c, "Boosted post deleted successfully", nil)
+}
+
+// GetBoostPerformance reports a campaign's impressions, unique reach, taps and resulting follows
+func (h *BoostedPostHandler) GetBoostPerformance(c *gin.Context) {
+	boostID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid boosted post ID format", err)
+		return
+	}
+
+	performance, err := h.boostedPostService.GetBoostPerformance(boostID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Boosted post not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get boosted post performance", err)
+		return
+	}
+
+	utils.OkResponse(c, "Boosted post performance retrieved successfully", performance)
+}
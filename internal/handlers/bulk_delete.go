@@ -0,0 +1,62 @@
+// internal/handlers/bulk_delete.go
+package handlers
+
+import (
+	"strings"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// bindBulkDeleteRequest reads a bulk delete request, answering 400 when it
+// isn't valid
+func bindBulkDeleteRequest(c *gin.Context, v *validator.Validate) (models.BulkDeleteRequest, bool) {
+	var req models.BulkDeleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return req, false
+	}
+	if err := v.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return req, false
+	}
+	return req, true
+}
+
+// bulkDeleteJobID parses the job ID of a bulk delete status request
+func bulkDeleteJobID(c *gin.Context) (primitive.ObjectID, bool) {
+	jobID, err := primitive.ObjectIDFromHex(c.Param("jobId"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid job ID format", err)
+		return primitive.NilObjectID, false
+	}
+	return jobID, true
+}
+
+// bulkDeleteResponse answers a started bulk deletion: 200 when it finished
+// within the request, 202 when it runs in the background
+func bulkDeleteResponse(c *gin.Context, job *models.BulkDeleteJob) {
+	if job.Status == models.BulkDeleteCompleted || job.Status == models.BulkDeleteFailed {
+		utils.OkResponse(c, "Bulk delete finished", job)
+		return
+	}
+	utils.AcceptedResponse(c, "Bulk delete started", job)
+}
+
+// bulkDeleteErrorResponse maps bulk delete errors to responses
+func bulkDeleteErrorResponse(c *gin.Context, message string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "confirmation count"):
+		utils.ConflictResponse(c, err.Error(), err)
+	case strings.Contains(err.Error(), "not found"):
+		utils.NotFoundResponse(c, "Bulk delete job not found")
+	case strings.Contains(err.Error(), "invalid filter"), strings.Contains(err.Error(), "match the filter"):
+		utils.BadRequestResponse(c, err.Error(), err)
+	default:
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+}
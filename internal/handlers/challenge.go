@@ -0,0 +1,64 @@
+// internal/handlers/challenge.go
+package handlers
+
+import (
+	"strings"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type ChallengeHandler struct {
+	challengeService *services.ChallengeService
+	validator        *validator.Validate
+}
+
+func NewChallengeHandler(challengeService *services.ChallengeService) *ChallengeHandler {
+	return &ChallengeHandler{
+		challengeService: challengeService,
+		validator:        validator.New(),
+	}
+}
+
+// GetChallengeStatus returns each route group's challenge mode, current risk and counters
+func (h *ChallengeHandler) GetChallengeStatus(c *gin.Context) {
+	utils.OkResponse(c, "Challenge status retrieved successfully", gin.H{
+		"enabled": h.challengeService.Enabled(),
+		"groups":  h.challengeService.GetStatus(),
+	})
+}
+
+// UpdateChallengeGroup forces a route group's challenge on or off, or back to auto
+func (h *ChallengeHandler) UpdateChallengeGroup(c *gin.Context) {
+	adminID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateChallengeGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	settings, err := h.challengeService.UpdateGroup(adminID, c.Param("group"), req)
+	if err != nil {
+		if strings.Contains(err.Error(), "unknown challenge group") {
+			utils.NotFoundResponse(c, "Challenge group not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to update challenge settings", err)
+		return
+	}
+
+	utils.OkResponse(c, "Challenge settings updated successfully", settings)
+}
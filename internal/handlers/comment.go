@@ -2,6 +2,9 @@
 package handlers
 
 import (
+	"errors"
+	"net/http"
+	"strconv"
 	"strings"
 
 	"social-media-api/internal/models"
@@ -27,9 +30,8 @@ func NewCommentHandler(commentService *services.CommentService) *CommentHandler
 
 // CreateComment creates a new comment
 func (h *CommentHandler) CreateComment(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -38,6 +40,7 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 		utils.BadRequestResponse(c, "Invalid request format", err)
 		return
 	}
+	req.DelegateID = actingDelegate(c)
 
 	if err := h.validator.Struct(req); err != nil {
 		utils.ValidationErrorResponse(c, err)
@@ -55,8 +58,17 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 		return
 	}
 
-	comment, err := h.commentService.CreateComment(userID.(primitive.ObjectID), req)
+	comment, err := h.commentService.CreateComment(userID, req)
 	if err != nil {
+		if respondBoundExceeded(c, err) {
+			return
+		}
+		if respondBlockedLink(c, err) {
+			return
+		}
+		if respondCommentFlood(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "Target post not found")
 			return
@@ -65,6 +77,22 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 			utils.BadRequestResponse(c, "Comments are disabled for this post", err)
 			return
 		}
+		if strings.Contains(err.Error(), "comments limited") {
+			utils.ForbiddenResponse(c, "You can't comment on this post: "+err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "restricted") {
+			utils.ForbiddenResponse(c, "Your account is restricted from commenting")
+			return
+		}
+		if strings.Contains(err.Error(), "quick reply limit") {
+			utils.TooManyRequestsResponse(c, "You have reached the quick reply limit for this post")
+			return
+		}
+		if strings.Contains(err.Error(), "quick repl") {
+			utils.BadRequestResponse(c, err.Error(), err)
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to create comment", err)
 		return
 	}
@@ -82,11 +110,7 @@ func (h *CommentHandler) GetComment(c *gin.Context) {
 	}
 
 	// Get current user ID if authenticated
-	var currentUserID *primitive.ObjectID
-	if userID, exists := c.Get("user_id"); exists {
-		uid := userID.(primitive.ObjectID)
-		currentUserID = &uid
-	}
+	currentUserID := utils.OptionalUserID(c)
 
 	comment, err := h.commentService.GetCommentByID(commentID, currentUserID)
 	if err != nil {
@@ -112,7 +136,11 @@ func (h *CommentHandler) GetPostComments(c *gin.Context) {
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// Get sort parameter
 	sortBy := c.DefaultQuery("sort", "newest")
@@ -121,11 +149,7 @@ func (h *CommentHandler) GetPostComments(c *gin.Context) {
 	}
 
 	// Get current user ID if authenticated
-	var currentUserID *primitive.ObjectID
-	if userID, exists := c.Get("user_id"); exists {
-		uid := userID.(primitive.ObjectID)
-		currentUserID = &uid
-	}
+	currentUserID := utils.OptionalUserID(c)
 
 	comments, err := h.commentService.GetPostComments(postID, currentUserID, sortBy, params.Limit, params.Offset)
 	if err != nil {
@@ -149,6 +173,42 @@ func (h *CommentHandler) GetPostComments(c *gin.Context) {
 	utils.PaginatedSuccessResponse(c, "Comments retrieved successfully", commentResponses, paginationMeta, nil)
 }
 
+// GetQuickReplies retrieves the emoji-only quick replies on a post
+func (h *CommentHandler) GetQuickReplies(c *gin.Context) {
+	postID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid post ID format", err)
+		return
+	}
+
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	currentUserID := utils.OptionalUserID(c)
+
+	replies, err := h.commentService.GetQuickReplies(postID, currentUserID, params.Limit, params.Offset)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Post not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get quick replies", err)
+		return
+	}
+
+	replyResponses := make([]models.CommentResponse, len(replies))
+	for i := range replies {
+		replyResponses[i] = replies[i].ToCommentResponse()
+	}
+
+	paginationMeta := utils.CreatePaginationMeta(params, int64(len(replyResponses)))
+
+	utils.PaginatedSuccessResponse(c, "Quick replies retrieved successfully", replyResponses, paginationMeta, nil)
+}
+
 // GetCommentReplies retrieves replies to a specific comment
 func (h *CommentHandler) GetCommentReplies(c *gin.Context) {
 	commentIDStr := c.Param("id")
@@ -159,13 +219,19 @@ func (h *CommentHandler) GetCommentReplies(c *gin.Context) {
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// Get current user ID if authenticated
-	var currentUserID *primitive.ObjectID
-	if userID, exists := c.Get("user_id"); exists {
-		uid := userID.(primitive.ObjectID)
-		currentUserID = &uid
+	currentUserID := utils.OptionalUserID(c)
+
+	// Loading more after a reply preview pages by the comment's replies_cursor
+	if _, ok := c.GetQuery("cursor"); ok {
+		h.getMoreReplies(c, commentID, currentUserID)
+		return
 	}
 
 	replies, err := h.commentService.GetCommentReplies(commentID, currentUserID, params.Limit, params.Offset)
@@ -190,11 +256,45 @@ func (h *CommentHandler) GetCommentReplies(c *gin.Context) {
 	utils.PaginatedSuccessResponse(c, "Comment replies retrieved successfully", replyResponses, paginationMeta, nil)
 }
 
+// getMoreReplies serves a cursor page of a comment's replies
+func (h *CommentHandler) getMoreReplies(c *gin.Context, commentID primitive.ObjectID, currentUserID *primitive.ObjectID) {
+	params, err := utils.ParseCursorPagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	replies, nextCursor, err := h.commentService.GetReplies(commentID, currentUserID, params.Cursor, params.Limit)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid cursor") {
+			utils.BadRequestResponse(c, "Invalid cursor", err)
+			return
+		}
+		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "no documents") || strings.Contains(err.Error(), "not accessible") {
+			utils.NotFoundResponse(c, "Comment not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get comment replies", err)
+		return
+	}
+
+	replyResponses := make([]models.CommentResponse, 0, len(replies))
+	for _, reply := range replies {
+		replyResponses = append(replyResponses, reply.ToCommentResponse())
+	}
+
+	utils.OkResponse(c, "Comment replies retrieved successfully", utils.CreateCursorPaginatedResult(replyResponses, utils.CursorPaginationMeta{
+		HasNext:     nextCursor != "",
+		HasPrevious: params.Cursor != "",
+		NextCursor:  nextCursor,
+		Count:       len(replyResponses),
+	}))
+}
+
 // UpdateComment updates an existing comment
 func (h *CommentHandler) UpdateComment(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -223,12 +323,22 @@ func (h *CommentHandler) UpdateComment(c *gin.Context) {
 		}
 	}
 
-	comment, err := h.commentService.UpdateComment(commentID, userID.(primitive.ObjectID), req)
+	comment, err := h.commentService.UpdateComment(commentID, userID, req)
 	if err != nil {
+		if respondBoundExceeded(c, err) {
+			return
+		}
+		if respondBlockedLink(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Comment not found or access denied")
 			return
 		}
+		if strings.Contains(err.Error(), "quick replies") {
+			utils.BadRequestResponse(c, err.Error(), err)
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to update comment", err)
 		return
 	}
@@ -238,9 +348,8 @@ func (h *CommentHandler) UpdateComment(c *gin.Context) {
 
 // DeleteComment deletes a comment
 func (h *CommentHandler) DeleteComment(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -251,7 +360,7 @@ func (h *CommentHandler) DeleteComment(c *gin.Context) {
 		return
 	}
 
-	err = h.commentService.DeleteComment(commentID, userID.(primitive.ObjectID))
+	err = h.commentService.DeleteComment(commentID, userID, actingDelegate(c))
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Comment not found or access denied")
@@ -264,11 +373,77 @@ func (h *CommentHandler) DeleteComment(c *gin.Context) {
 	utils.OkResponse(c, "Comment deleted successfully", nil)
 }
 
+// PreviewBulkDeleteComments counts the current user's comments a bulk
+// delete filter matches; the count confirms the deletion
+func (h *CommentHandler) PreviewBulkDeleteComments(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var filter models.BulkDeleteFilter
+	if err := c.ShouldBindJSON(&filter); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	preview, err := h.commentService.PreviewBulkDeleteMyComments(userID, filter)
+	if err != nil {
+		bulkDeleteErrorResponse(c, "Failed to preview bulk delete", err)
+		return
+	}
+
+	utils.OkResponse(c, "Bulk delete preview", preview)
+}
+
+// BulkDeleteComments deletes the current user's comments matching a filter, once
+// confirmed with the count from the preview
+func (h *CommentHandler) BulkDeleteComments(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	req, ok := bindBulkDeleteRequest(c, h.validator)
+	if !ok {
+		return
+	}
+
+	job, err := h.commentService.BulkDeleteMyComments(userID, req)
+	if err != nil {
+		bulkDeleteErrorResponse(c, "Failed to delete comments", err)
+		return
+	}
+
+	bulkDeleteResponse(c, job)
+}
+
+// GetBulkDeleteCommentsJob reports the progress of one of the current user's
+// bulk comment deletions
+func (h *CommentHandler) GetBulkDeleteCommentsJob(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	jobID, ok := bulkDeleteJobID(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.commentService.GetBulkDeleteJob(userID, jobID)
+	if err != nil {
+		bulkDeleteErrorResponse(c, "Failed to get bulk delete job", err)
+		return
+	}
+
+	utils.OkResponse(c, "Bulk delete job retrieved", job)
+}
+
 // LikeComment adds or removes a like from a comment
 func (h *CommentHandler) LikeComment(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -288,12 +463,16 @@ func (h *CommentHandler) LikeComment(c *gin.Context) {
 		req.ReactionType = models.ReactionLike
 	}
 
-	err = h.commentService.LikeComment(commentID, userID.(primitive.ObjectID), req.ReactionType)
+	err = h.commentService.LikeComment(commentID, userID, req.ReactionType)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "Comment not found")
 			return
 		}
+		if strings.Contains(err.Error(), "invalid reaction") {
+			utils.BadRequestResponse(c, "Invalid reaction type", err)
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to like comment", err)
 		return
 	}
@@ -306,9 +485,8 @@ func (h *CommentHandler) LikeComment(c *gin.Context) {
 
 // UnlikeComment removes a like from a comment
 func (h *CommentHandler) UnlikeComment(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -319,7 +497,7 @@ func (h *CommentHandler) UnlikeComment(c *gin.Context) {
 		return
 	}
 
-	err = h.commentService.UnlikeComment(commentID, userID.(primitive.ObjectID))
+	err = h.commentService.UnlikeComment(commentID, userID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to unlike comment", err)
 		return
@@ -340,7 +518,11 @@ func (h *CommentHandler) GetCommentLikes(c *gin.Context) {
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	likes, err := h.commentService.GetCommentLikes(commentID, params.Limit, params.Offset)
 	if err != nil {
@@ -356,9 +538,8 @@ func (h *CommentHandler) GetCommentLikes(c *gin.Context) {
 
 // ReportComment reports a comment
 func (h *CommentHandler) ReportComment(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -379,7 +560,7 @@ func (h *CommentHandler) ReportComment(c *gin.Context) {
 		return
 	}
 
-	err = h.commentService.ReportComment(commentID, userID.(primitive.ObjectID), req.Reason, req.Description)
+	err = h.commentService.ReportComment(commentID, userID, req.Reason, req.Description)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "Comment not found")
@@ -397,9 +578,8 @@ func (h *CommentHandler) ReportComment(c *gin.Context) {
 
 // PinComment pins a comment (post author only)
 func (h *CommentHandler) PinComment(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -410,7 +590,7 @@ func (h *CommentHandler) PinComment(c *gin.Context) {
 		return
 	}
 
-	err = h.commentService.PinComment(commentID, userID.(primitive.ObjectID))
+	err = h.commentService.PinComment(commentID, userID, actingDelegate(c))
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "Comment not found")
@@ -431,9 +611,8 @@ func (h *CommentHandler) PinComment(c *gin.Context) {
 
 // UnpinComment unpins a comment (post author only)
 func (h *CommentHandler) UnpinComment(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -444,7 +623,7 @@ func (h *CommentHandler) UnpinComment(c *gin.Context) {
 		return
 	}
 
-	err = h.commentService.UnpinComment(commentID, userID.(primitive.ObjectID))
+	err = h.commentService.UnpinComment(commentID, userID, actingDelegate(c))
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "Comment not found")
@@ -473,14 +652,14 @@ func (h *CommentHandler) GetUserComments(c *gin.Context) {
 	}
 
 	// Get current user ID if authenticated
-	var currentUserID *primitive.ObjectID
-	if uid, exists := c.Get("user_id"); exists {
-		id := uid.(primitive.ObjectID)
-		currentUserID = &id
-	}
+	currentUserID := utils.OptionalUserID(c)
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	comments, err := h.commentService.GetUserComments(userID, currentUserID, params.Limit, params.Offset)
 	if err != nil {
@@ -510,11 +689,7 @@ func (h *CommentHandler) GetCommentThread(c *gin.Context) {
 	}
 
 	// Get current user ID if authenticated
-	var currentUserID *primitive.ObjectID
-	if userID, exists := c.Get("user_id"); exists {
-		uid := userID.(primitive.ObjectID)
-		currentUserID = &uid
-	}
+	currentUserID := utils.OptionalUserID(c)
 
 	thread, err := h.commentService.GetCommentThread(commentID, currentUserID)
 	if err != nil {
@@ -539,6 +714,124 @@ func (h *CommentHandler) GetCommentThread(c *gin.Context) {
 	})
 }
 
+// GetHeldComments lists comments waiting in the spam hold (moderators only)
+func (h *CommentHandler) GetHeldComments(c *gin.Context) {
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	comments, total, err := h.commentService.GetHeldComments(params.Limit, params.Offset)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get held comments", err)
+		return
+	}
+
+	commentResponses := make([]models.CommentResponse, 0, len(comments))
+	for _, comment := range comments {
+		response := comment.ToCommentResponse()
+		response.Author = comment.Author
+		commentResponses = append(commentResponses, response)
+	}
+
+	pagination := utils.CreatePaginationMeta(params, total)
+	utils.PaginatedSuccessResponse(c, "Held comments retrieved successfully", commentResponses, pagination, utils.CreatePaginationLinks(c, pagination))
+}
+
+// ApproveHeldComment releases a held comment to everyone (moderators only)
+func (h *CommentHandler) ApproveHeldComment(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	commentID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid comment ID format", err)
+		return
+	}
+
+	comment, err := h.commentService.ApproveHeldComment(commentID, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "not held") {
+			utils.NotFoundResponse(c, "Held comment not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to approve comment", err)
+		return
+	}
+
+	utils.OkResponse(c, "Comment approved successfully", comment.ToCommentResponse())
+}
+
+// RejectHeldComment removes a held comment and strikes its author (moderators only)
+func (h *CommentHandler) RejectHeldComment(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	commentID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid comment ID format", err)
+		return
+	}
+
+	var req models.RejectHeldCommentRequest
+
+	// Body is optional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BadRequestResponse(c, "Invalid request format", err)
+			return
+		}
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	err = h.commentService.RejectHeldComment(commentID, userID, req.Reason)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "not held") {
+			utils.NotFoundResponse(c, "Held comment not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to reject comment", err)
+		return
+	}
+
+	utils.OkResponse(c, "Comment rejected successfully", gin.H{
+		"rejected": true,
+	})
+}
+
+// GetCommentHoldStats returns spam hold volumes (moderators only)
+func (h *CommentHandler) GetCommentHoldStats(c *gin.Context) {
+	stats, err := h.commentService.GetCommentHoldStats()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get comment hold statistics", err)
+		return
+	}
+
+	utils.OkResponse(c, "Comment hold statistics retrieved successfully", stats)
+}
+
+// respondCommentFlood answers a comment flood error with 429 and a
+// Retry-After header, reporting false for any other error
+func respondCommentFlood(c *gin.Context, err error) bool {
+	var floodErr *models.CommentFloodError
+	if !errors.As(err, &floodErr) {
+		return false
+	}
+
+	c.Header("Retry-After", strconv.Itoa(floodErr.RetryAfterSeconds))
+	utils.ErrorResponseWithDetails(c, http.StatusTooManyRequests, floodErr.Error(), utils.ErrorCodeRateLimited, floodErr)
+	return true
+}
+
 // Helper methods for validation
 
 func (h *CommentHandler) isValidSortBy(sortBy string) bool {
@@ -0,0 +1,67 @@
+// internal/handlers/content_calendar.go
+package handlers
+
+import (
+	"strings"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ContentCalendarHandler struct {
+	calendarService *services.ContentCalendarService
+}
+
+func NewContentCalendarHandler(calendarService *services.ContentCalendarService) *ContentCalendarHandler {
+	return &ContentCalendarHandler{
+		calendarService: calendarService,
+	}
+}
+
+// GetContentCalendar returns the caller's published, scheduled and pending
+// posts grouped by day. Pass day and page to page through a crowded day.
+func (h *ContentCalendarHandler) GetContentCalendar(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	query := models.ContentCalendarQuery{
+		From: c.Query("from"),
+		To:   c.Query("to"),
+		Day:  c.Query("day"),
+	}
+	var err error
+	if query.Page, err = utils.ParseIntQuery(c, "page", 1, 1); err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	if query.PerDay, err = utils.ParseIntQuery(c, "per_day", models.ContentCalendarDefaultPerDay, 1); err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+	for _, include := range strings.Split(c.Query("include"), ",") {
+		if strings.TrimSpace(include) == "highlights" {
+			query.IncludeHighlights = true
+		}
+	}
+
+	calendar, err := h.calendarService.GetContentCalendar(userID, query)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			utils.BadRequestResponse(c, err.Error(), err)
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "User not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to retrieve content calendar", err)
+		return
+	}
+
+	utils.OkResponse(c, "Content calendar retrieved successfully", calendar)
+}
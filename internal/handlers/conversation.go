@@ -2,6 +2,7 @@
 package handlers
 
 import (
+	"net/http"
 	"strings"
 	"time"
 
@@ -36,17 +37,24 @@ func (h *ConversationHandler) CreateConversation(c *gin.Context) {
 	}
 
 	// Get user ID from context
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userObjectID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	userObjectID := userID.(primitive.ObjectID)
-
 	// Create conversation
 	conversation, err := h.conversationService.CreateConversation(userObjectID, req)
 	if err != nil {
+		if respondBoundExceeded(c, err) {
+			return
+		}
+		if respondAccountTooNew(c, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "yourself") {
+			utils.BadRequestResponse(c, "Cannot start a direct conversation with yourself", nil)
+			return
+		}
 		utils.BadRequestResponse(c, "Failed to create conversation", err)
 		return
 	}
@@ -59,23 +67,30 @@ func (h *ConversationHandler) CreateConversation(c *gin.Context) {
 // GetUserConversations retrieves conversations for the authenticated user
 func (h *ConversationHandler) GetUserConversations(c *gin.Context) {
 	// Get user ID from context
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userObjectID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	userObjectID := userID.(primitive.ObjectID)
-
 	// Get pagination parameters
-	paginationParams := utils.GetPaginationParams(c)
+	paginationParams, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	sortBy := c.DefaultQuery("sort", models.InboxSortRecent)
+	if sortBy != models.InboxSortRecent && sortBy != models.InboxSortMostActive {
+		utils.BadRequestResponse(c, "Invalid sort, must be recent or most_active", nil)
+		return
+	}
 
 	// Try to use the method with total count if available
 	// Otherwise fall back to the basic method
-	conversations, total, err := h.conversationService.GetUserConversationsWithTotal(userObjectID, paginationParams.Limit, paginationParams.Offset)
+	conversations, total, err := h.conversationService.GetUserConversationsWithTotal(userObjectID, sortBy, paginationParams.Limit, paginationParams.Offset)
 	if err != nil {
 		// Fallback to basic method
-		conversationsBasic, errBasic := h.conversationService.GetUserConversations(userObjectID, paginationParams.Limit, paginationParams.Offset)
+		conversationsBasic, errBasic := h.conversationService.GetUserConversations(userObjectID, sortBy, paginationParams.Limit, paginationParams.Offset)
 		if errBasic != nil {
 			utils.InternalServerErrorResponse(c, "Failed to get conversations", errBasic)
 			return
@@ -100,16 +115,12 @@ func (h *ConversationHandler) GetConversation(c *gin.Context) {
 	}
 
 	// Get user ID from context
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userObjectID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	userObjectID := userID.(primitive.ObjectID)
-
-	// Get conversation - service returns *models.ConversationResponse, error
-	conversation, err := h.conversationService.GetConversationByID(conversationID, userObjectID)
+	conversation, err := h.conversationService.GetParticipantConversation(conversationID, userObjectID)
 	if err != nil {
 		if err.Error() == "conversation not found or access denied" {
 			utils.NotFoundResponse(c, "Conversation not found")
@@ -119,7 +130,11 @@ func (h *ConversationHandler) GetConversation(c *gin.Context) {
 		return
 	}
 
-	utils.OkResponse(c, "Conversation retrieved successfully", conversation)
+	if utils.CheckETag(c, h.conversationService.ConversationETag(conversation, userObjectID)) {
+		return
+	}
+
+	utils.OkResponse(c, "Conversation retrieved successfully", h.conversationService.BuildConversationResponse(conversation, userObjectID))
 }
 
 // UpdateConversation updates conversation details
@@ -139,14 +154,11 @@ func (h *ConversationHandler) UpdateConversation(c *gin.Context) {
 	}
 
 	// Get user ID from context
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userObjectID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	userObjectID := userID.(primitive.ObjectID)
-
 	// Update conversation - service returns *models.ConversationResponse, error
 	conversation, err := h.conversationService.UpdateConversation(conversationID, userObjectID, req)
 	if err != nil {
@@ -182,17 +194,17 @@ func (h *ConversationHandler) AddParticipants(c *gin.Context) {
 	}
 
 	// Get user ID from context
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userObjectID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	userObjectID := userID.(primitive.ObjectID)
-
 	// Add participants - service expects models.AddParticipantsRequest
 	err = h.conversationService.AddParticipants(conversationID, userObjectID, req)
 	if err != nil {
+		if respondBoundExceeded(c, err) {
+			return
+		}
 		if err.Error() == "insufficient permissions to add members" {
 			utils.ForbiddenResponse(c, "Insufficient permissions to add members")
 			return
@@ -227,14 +239,11 @@ func (h *ConversationHandler) RemoveParticipant(c *gin.Context) {
 	}
 
 	// Get user ID from context
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userObjectID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	userObjectID := userID.(primitive.ObjectID)
-
 	// Remove participant
 	err = h.conversationService.RemoveParticipant(conversationID, userObjectID, participantID)
 	if err != nil {
@@ -264,14 +273,11 @@ func (h *ConversationHandler) LeaveConversation(c *gin.Context) {
 	}
 
 	// Get user ID from context
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userObjectID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	userObjectID := userID.(primitive.ObjectID)
-
 	// Leave conversation
 	err = h.conversationService.LeaveConversation(conversationID, userObjectID)
 	if err != nil {
@@ -301,16 +307,23 @@ func (h *ConversationHandler) GetConversationMessages(c *gin.Context) {
 	}
 
 	// Get user ID from context
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userObjectID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	userObjectID := userID.(primitive.ObjectID)
+	// Jumping to a message, or paging on from a jump, uses message windows
+	if c.Query("around") != "" || c.Query("older_than") != "" || c.Query("newer_than") != "" {
+		h.getMessageWindow(c, conversationID, userObjectID)
+		return
+	}
 
 	// Get pagination parameters
-	paginationParams := utils.GetPaginationParams(c)
+	paginationParams, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// Get messages - service returns []models.Message, error (not total count)
 	messages, err := h.messageService.GetConversationMessages(conversationID, userObjectID, paginationParams.Limit, paginationParams.Offset)
@@ -335,6 +348,70 @@ func (h *ConversationHandler) GetConversationMessages(c *gin.Context) {
 	utils.PaginatedSuccessResponse(c, "Messages retrieved successfully", responses, pagination, nil)
 }
 
+// getMessageWindow serves the messages around ?around=<message id> (with
+// ?before= and ?after= counts), or the page after a window cursor passed as
+// ?older_than= or ?newer_than= (with ?limit=)
+func (h *ConversationHandler) getMessageWindow(c *gin.Context, conversationID, userID primitive.ObjectID) {
+	var (
+		window   *models.MessageWindow
+		targetID *primitive.ObjectID
+		err      error
+	)
+
+	if around := c.Query("around"); around != "" {
+		messageID, parseErr := primitive.ObjectIDFromHex(around)
+		if parseErr != nil {
+			utils.BadRequestResponse(c, "Invalid message ID", parseErr)
+			return
+		}
+		before, parseErr := utils.ParseIntQuery(c, "before", utils.DefaultPageSize, 0)
+		if parseErr != nil {
+			utils.BadRequestResponse(c, "Invalid window size", parseErr)
+			return
+		}
+		after, parseErr := utils.ParseIntQuery(c, "after", utils.DefaultPageSize, 0)
+		if parseErr != nil {
+			utils.BadRequestResponse(c, "Invalid window size", parseErr)
+			return
+		}
+
+		targetID = &messageID
+		window, err = h.messageService.GetMessagesAround(conversationID, userID, messageID, before, after)
+	} else {
+		older := c.Query("older_than") != ""
+		cursor := c.Query("newer_than")
+		if older {
+			cursor = c.Query("older_than")
+		}
+		cursorID, parseErr := primitive.ObjectIDFromHex(cursor)
+		if parseErr != nil {
+			utils.BadRequestResponse(c, "Invalid cursor", parseErr)
+			return
+		}
+		limit, limitErr := utils.ParseLimit(c, utils.DefaultPageSize, utils.MaxPageSize)
+		if limitErr != nil {
+			utils.BadRequestResponse(c, "Invalid pagination parameters", limitErr)
+			return
+		}
+
+		window, err = h.messageService.GetMessagesFromCursor(conversationID, userID, cursorID, older, limit)
+	}
+
+	if err != nil {
+		switch {
+		case err.Error() == "access denied: user not in conversation":
+			utils.ForbiddenResponse(c, "Access denied")
+		case strings.Contains(err.Error(), "not found in conversation"):
+			utils.NotFoundResponse(c, "Message not found in this conversation")
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to get messages", err)
+		}
+		return
+	}
+
+	utils.OkResponse(c, "Messages retrieved successfully", window.ToMessageWindowResponse(targetID))
+}
+
 // SendMessage sends a message in a conversation
 func (h *ConversationHandler) SendMessage(c *gin.Context) {
 	// Get conversation ID from URL parameter
@@ -353,19 +430,23 @@ func (h *ConversationHandler) SendMessage(c *gin.Context) {
 
 	// Set conversation ID from URL parameter
 	req.ConversationID = conversationIDStr
+	req.DelegateID = actingDelegate(c)
 
 	// Get user ID from context
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userObjectID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	userObjectID := userID.(primitive.ObjectID)
-
 	// Send message - service returns *models.Message, error
 	message, err := h.messageService.SendMessage(userObjectID, conversationID, req)
 	if err != nil {
+		if respondBoundExceeded(c, err) {
+			return
+		}
+		if respondLimitExceeded(c, err) || respondAccountTooNew(c, err) {
+			return
+		}
 		if err.Error() == "access denied: user not in conversation" {
 			utils.ForbiddenResponse(c, "Access denied")
 			return
@@ -407,16 +488,13 @@ func (h *ConversationHandler) MarkAsRead(c *gin.Context) {
 	}
 
 	// Get user ID from context
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userObjectID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	userObjectID := userID.(primitive.ObjectID)
-
 	// Mark messages as read
-	err = h.messageService.MarkMessagesAsRead(conversationID, userObjectID, lastMessageID)
+	_, err = h.messageService.MarkMessagesAsRead(conversationID, userObjectID, lastMessageID)
 	if err != nil {
 		if err.Error() == "access denied: user not in conversation" {
 			utils.ForbiddenResponse(c, "Access denied")
@@ -440,14 +518,11 @@ func (h *ConversationHandler) GetConversationStats(c *gin.Context) {
 	}
 
 	// Get user ID from context
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userObjectID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	userObjectID := userID.(primitive.ObjectID)
-
 	// Get conversation stats
 	stats, err := h.conversationService.GetConversationStats(conversationID, userObjectID)
 	if err != nil {
@@ -462,6 +537,32 @@ func (h *ConversationHandler) GetConversationStats(c *gin.Context) {
 	utils.OkResponse(c, "Conversation stats retrieved successfully", stats)
 }
 
+// GetParticipants lists a conversation's members with their online state and last seen
+func (h *ConversationHandler) GetParticipants(c *gin.Context) {
+	conversationID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid conversation ID", err)
+		return
+	}
+
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	participants, err := h.conversationService.GetParticipantPresence(conversationID, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Conversation not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get conversation participants", err)
+		return
+	}
+
+	utils.OkResponse(c, "Conversation participants retrieved successfully", participants)
+}
+
 // SearchConversations searches user's conversations
 func (h *ConversationHandler) SearchConversations(c *gin.Context) {
 	query := c.Query("q")
@@ -471,22 +572,23 @@ func (h *ConversationHandler) SearchConversations(c *gin.Context) {
 	}
 
 	// Get user ID from context
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userObjectID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	userObjectID := userID.(primitive.ObjectID)
-
 	// Get pagination parameters
-	paginationParams := utils.GetPaginationParams(c)
+	paginationParams, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// Try to use the search method if available
 	conversations, total, err := h.conversationService.SearchUserConversations(userObjectID, query, paginationParams.Limit, paginationParams.Offset)
 	if err != nil {
 		// Fallback to basic search with client-side filtering
-		allConversations, errBasic := h.conversationService.GetUserConversations(userObjectID, paginationParams.Limit, paginationParams.Offset)
+		allConversations, errBasic := h.conversationService.GetUserConversations(userObjectID, models.InboxSortRecent, paginationParams.Limit, paginationParams.Offset)
 		if errBasic != nil {
 			utils.InternalServerErrorResponse(c, "Failed to search conversations", errBasic)
 			return
@@ -532,14 +634,11 @@ func (h *ConversationHandler) MuteConversation(c *gin.Context) {
 	}
 
 	// Get user ID from context
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userObjectID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	userObjectID := userID.(primitive.ObjectID)
-
 	// Mute/unmute conversation
 	err = h.conversationService.MuteConversation(conversationID, userObjectID, req.Muted, req.MuteUntil)
 	if err != nil {
@@ -580,17 +679,17 @@ func (h *ConversationHandler) UpdateParticipantRole(c *gin.Context) {
 	}
 
 	// Get user ID from context
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userObjectID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	userObjectID := userID.(primitive.ObjectID)
-
 	// Update participant role
 	err = h.conversationService.UpdateParticipantRole(conversationID, userObjectID, participantID, req)
 	if err != nil {
+		if respondBoundExceeded(c, err) {
+			return
+		}
 		if err.Error() == "admin privileges required" {
 			utils.ForbiddenResponse(c, "Admin privileges required")
 			return
@@ -609,14 +708,11 @@ func (h *ConversationHandler) UpdateParticipantRole(c *gin.Context) {
 // GetUnreadCounts returns unread message counts for all conversations
 func (h *ConversationHandler) GetUnreadCounts(c *gin.Context) {
 	// Get user ID from context
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userObjectID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	userObjectID := userID.(primitive.ObjectID)
-
 	// Get unread counts
 	counts, err := h.conversationService.GetUnreadCounts(userObjectID)
 	if err != nil {
@@ -627,6 +723,141 @@ func (h *ConversationHandler) GetUnreadCounts(c *gin.Context) {
 	utils.OkResponse(c, "Unread counts retrieved successfully", counts)
 }
 
+// GetMessageRequests retrieves conversations waiting in the user's message requests
+func (h *ConversationHandler) GetMessageRequests(c *gin.Context) {
+	// Get user ID from context
+	userObjectID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	// Get pagination parameters
+	paginationParams, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	requests, total, err := h.conversationService.GetMessageRequests(userObjectID, paginationParams.Limit, paginationParams.Offset)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get message requests", err)
+		return
+	}
+
+	pagination := utils.CreatePaginationMeta(paginationParams, total)
+	utils.PaginatedSuccessResponse(c, "Message requests retrieved successfully", requests, pagination, nil)
+}
+
+// AcceptMessageRequest moves a message request to the main inbox
+func (h *ConversationHandler) AcceptMessageRequest(c *gin.Context) {
+	// Get conversation ID from URL parameter
+	conversationID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid conversation ID", err)
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	err = h.conversationService.AcceptMessageRequest(conversationID, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Message request not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to accept message request", err)
+		return
+	}
+
+	utils.OkResponse(c, "Message request accepted successfully", nil)
+}
+
+// DeclineMessageRequest deletes a message request, optionally blocking the sender
+func (h *ConversationHandler) DeclineMessageRequest(c *gin.Context) {
+	// Get conversation ID from URL parameter
+	conversationID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid conversation ID", err)
+		return
+	}
+
+	var req struct {
+		Block bool `json:"block"`
+	}
+
+	// Body is optional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ValidationErrorResponse(c, err)
+			return
+		}
+	}
+
+	// Get user ID from context
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	err = h.conversationService.DeclineMessageRequest(conversationID, userID, req.Block)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Message request not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to decline message request", err)
+		return
+	}
+
+	utils.OkResponse(c, "Message request declined successfully", nil)
+}
+
+// ExportConversation downloads a transcript of the conversation
+func (h *ConversationHandler) ExportConversation(c *gin.Context) {
+	// Get conversation ID from URL parameter
+	conversationID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid conversation ID", err)
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", models.ConversationExportJSON))
+	if format == "text" {
+		format = models.ConversationExportText
+	}
+
+	data, export, err := h.conversationService.ExportConversation(userID, conversationID, format)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "unsupported"):
+			utils.BadRequestResponse(c, "Unsupported export format, use json or txt", nil)
+		case strings.Contains(err.Error(), "not found"):
+			utils.NotFoundResponse(c, "Conversation not found")
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to export conversation", err)
+		}
+		return
+	}
+
+	contentType := "application/json"
+	if export.Format == models.ConversationExportText {
+		contentType = "text/plain; charset=utf-8"
+	}
+
+	c.Header("Content-Disposition", "attachment; filename="+export.Filename)
+	c.Data(http.StatusOK, contentType, data)
+}
+
 // ArchiveConversation archives/unarchives a conversation
 func (h *ConversationHandler) ArchiveConversation(c *gin.Context) {
 	// Get conversation ID from URL parameter
@@ -647,14 +878,11 @@ func (h *ConversationHandler) ArchiveConversation(c *gin.Context) {
 	}
 
 	// Get user ID from context
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userObjectID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	userObjectID := userID.(primitive.ObjectID)
-
 	// Archive/unarchive conversation
 	err = h.conversationService.ArchiveConversation(conversationID, userObjectID, req.Archived)
 	if err != nil {
@@ -670,6 +898,59 @@ func (h *ConversationHandler) ArchiveConversation(c *gin.Context) {
 	utils.OkResponse(c, message, nil)
 }
 
+// PinConversation pins/unpins a conversation in the user's inbox
+func (h *ConversationHandler) PinConversation(c *gin.Context) {
+	// Get conversation ID from URL parameter
+	conversationID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid conversation ID", err)
+		return
+	}
+
+	var req struct {
+		Pinned bool `json:"pinned"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	if req.Pinned {
+		err = h.conversationService.PinConversation(conversationID, userID)
+	} else {
+		err = h.conversationService.UnpinConversation(conversationID, userID)
+	}
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Conversation not found")
+			return
+		}
+		if strings.Contains(err.Error(), "cannot pin more") {
+			utils.BadRequestResponse(c, err.Error(), err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to update pin status", err)
+		return
+	}
+
+	message := "Conversation pinned successfully"
+	if !req.Pinned {
+		message = "Conversation unpinned successfully"
+	}
+
+	utils.OkResponse(c, message, gin.H{
+		"conversation_id": conversationID.Hex(),
+		"pinned":          req.Pinned,
+	})
+}
+
 // Helper method to notify conversation participants
 func (h *ConversationHandler) notifyConversationParticipants(conversationID, senderID primitive.ObjectID, notificationType string) {
 	// Get conversation to find participants
@@ -680,11 +961,21 @@ func (h *ConversationHandler) notifyConversationParticipants(conversationID, sen
 
 	// Send notifications to all participants except sender
 	for _, participant := range conversation.ParticipantInfo {
-		if participant.UserID != senderID {
-			switch notificationType {
-			case "message":
-				h.notificationService.NotifyMessage(senderID, participant.UserID, conversationID)
+		if participant.UserID == senderID {
+			continue
+		}
+
+		// A message request notifies its recipient once rather than per message
+		if participant.RequestStatus == models.ConversationRequestPending {
+			if notificationType == "message" && h.conversationService.ClaimRequestNotification(conversationID, participant.UserID) {
+				h.notificationService.NotifyMessageRequest(senderID, participant.UserID, conversationID)
 			}
+			continue
+		}
+
+		switch notificationType {
+		case "message":
+			h.notificationService.NotifyMessage(senderID, participant.UserID, conversationID)
 		}
 	}
 }
@@ -0,0 +1,264 @@
+// internal/handlers/delegation.go
+package handlers
+
+import (
+	"strings"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type DelegationHandler struct {
+	delegationService *services.DelegationService
+	validator         *validator.Validate
+}
+
+func NewDelegationHandler(delegationService *services.DelegationService) *DelegationHandler {
+	return &DelegationHandler{
+		delegationService: delegationService,
+		validator:         validator.New(),
+	}
+}
+
+// InviteDelegate invites a user to help manage the caller's account
+func (h *DelegationHandler) InviteDelegate(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.InviteDelegateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	delegateID, err := primitive.ObjectIDFromHex(req.UserID)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID", err)
+		return
+	}
+
+	delegation, err := h.delegationService.InviteDelegate(userID, delegateID, req.Role)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "yourself"):
+			utils.BadRequestResponse(c, err.Error(), nil)
+		case strings.Contains(err.Error(), "not found"):
+			utils.NotFoundResponse(c, "User not found")
+		case strings.Contains(err.Error(), "already"):
+			utils.ConflictResponse(c, err.Error(), nil)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to invite delegate", err)
+		}
+		return
+	}
+
+	utils.CreatedResponse(c, "Delegate invited successfully", delegation.ToAccountDelegationResponse())
+}
+
+// GetDelegates lists the caller's delegates and pending invitations
+func (h *DelegationHandler) GetDelegates(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	delegations, err := h.delegationService.GetDelegates(userID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get delegates", err)
+		return
+	}
+
+	utils.OkResponse(c, "Delegates retrieved successfully", toDelegationResponses(delegations))
+}
+
+// UpdateDelegateRole changes what one of the caller's delegates may do
+func (h *DelegationHandler) UpdateDelegateRole(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	delegationID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid delegation ID", err)
+		return
+	}
+
+	var req models.UpdateDelegateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	delegation, err := h.delegationService.UpdateDelegateRole(userID, delegationID, req.Role)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Delegate not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to update delegate role", err)
+		return
+	}
+
+	utils.OkResponse(c, "Delegate role updated successfully", delegation.ToAccountDelegationResponse())
+}
+
+// RevokeDelegate removes a delegate from the caller's account, or lets a
+// delegate give up access to an account they manage
+func (h *DelegationHandler) RevokeDelegate(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	delegationID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid delegation ID", err)
+		return
+	}
+
+	if err := h.delegationService.RevokeDelegate(delegationID, userID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Delegate not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to revoke delegate", err)
+		return
+	}
+
+	utils.OkResponse(c, "Delegate revoked successfully", nil)
+}
+
+// GetManagedAccounts lists the accounts the caller can act for and their
+// unanswered invitations
+func (h *DelegationHandler) GetManagedAccounts(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	delegations, err := h.delegationService.GetManagedAccounts(userID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get managed accounts", err)
+		return
+	}
+
+	utils.OkResponse(c, "Managed accounts retrieved successfully", toDelegationResponses(delegations))
+}
+
+// AcceptInvitation accepts an invitation to manage an account
+func (h *DelegationHandler) AcceptInvitation(c *gin.Context) {
+	h.respondToInvitation(c, true)
+}
+
+// DeclineInvitation declines an invitation to manage an account
+func (h *DelegationHandler) DeclineInvitation(c *gin.Context) {
+	h.respondToInvitation(c, false)
+}
+
+// SwitchAccount issues a delegated token for an account the caller manages
+func (h *DelegationHandler) SwitchAccount(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	// Delegated tokens can't be used to switch again
+	if _, delegated := c.Get("actor_id"); delegated {
+		utils.ForbiddenResponse(c, "Switch back to your own account first")
+		return
+	}
+
+	accountID, err := primitive.ObjectIDFromHex(c.Param("accountId"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid account ID", err)
+		return
+	}
+
+	sessionID, _ := c.Get("session_id")
+	sessionIDStr, _ := sessionID.(string)
+
+	response, err := h.delegationService.SwitchAccount(accountID, userID, sessionIDStr)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "no delegated access"):
+			utils.ForbiddenResponse(c, "You don't manage this account")
+		case strings.Contains(err.Error(), "not found"):
+			utils.NotFoundResponse(c, "Account not found")
+		case strings.Contains(err.Error(), "suspended"):
+			utils.ForbiddenResponse(c, "Account is suspended or inactive")
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to switch account", err)
+		}
+		return
+	}
+
+	utils.OkResponse(c, "Switched account successfully", response)
+}
+
+// Helper methods
+
+func (h *DelegationHandler) respondToInvitation(c *gin.Context, accept bool) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	delegationID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid invitation ID", err)
+		return
+	}
+
+	delegation, err := h.delegationService.RespondToInvitation(delegationID, userID, accept)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Invitation not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to respond to invitation", err)
+		return
+	}
+
+	message := "Invitation declined"
+	if accept {
+		message = "Invitation accepted"
+	}
+	utils.OkResponse(c, message, delegation.ToAccountDelegationResponse())
+}
+
+func toDelegationResponses(delegations []models.AccountDelegation) []models.AccountDelegationResponse {
+	responses := make([]models.AccountDelegationResponse, len(delegations))
+	for i := range delegations {
+		responses[i] = delegations[i].ToAccountDelegationResponse()
+	}
+	return responses
+}
+
+// actingDelegate returns the delegate making the request on the account's
+// behalf, or nil when the account owner is acting themselves
+func actingDelegate(c *gin.Context) *primitive.ObjectID {
+	actorID, exists := c.Get("actor_id")
+	if !exists {
+		return nil
+	}
+	id := actorID.(primitive.ObjectID)
+	return &id
+}
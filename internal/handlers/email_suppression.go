@@ -0,0 +1,138 @@
+// internal/handlers/email_suppression.go
+package handlers
+
+import (
+	"strings"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type EmailSuppressionHandler struct {
+	emailService *services.EmailService
+	validator    *validator.Validate
+}
+
+func NewEmailSuppressionHandler(emailService *services.EmailService) *EmailSuppressionHandler {
+	return &EmailSuppressionHandler{
+		emailService: emailService,
+		validator:    validator.New(),
+	}
+}
+
+// GetUnsubscribeStatus checks the token of an unsubscribe link, for the web
+// app's confirmation page. It doesn't unsubscribe, so link scanners opening
+// it have no effect.
+func (h *EmailSuppressionHandler) GetUnsubscribeStatus(c *gin.Context) {
+	email, unsubscribed, err := h.emailService.GetUnsubscribeStatus(c.Query("token"))
+	if err != nil {
+		h.suppressionErrorResponse(c, "Failed to check unsubscribe link", err)
+		return
+	}
+
+	utils.OkResponse(c, "Unsubscribe link is valid", gin.H{
+		"email":        email,
+		"unsubscribed": unsubscribed,
+	})
+}
+
+// Unsubscribe stops notification, digest and announcement emails to the
+// address of an unsubscribe token. It also answers one-click List-Unsubscribe
+// requests from mail clients, which post to the URL with the token in it.
+func (h *EmailSuppressionHandler) Unsubscribe(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		token = c.PostForm("token")
+	}
+
+	email, err := h.emailService.Unsubscribe(token)
+	if err != nil {
+		h.suppressionErrorResponse(c, "Failed to unsubscribe", err)
+		return
+	}
+
+	utils.OkResponse(c, "Unsubscribed successfully", gin.H{
+		"email":        email,
+		"unsubscribed": true,
+	})
+}
+
+// GetSuppressions lists the email suppression list, optionally for one
+// address (?email=) or reason (?reason=)
+func (h *EmailSuppressionHandler) GetSuppressions(c *gin.Context) {
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	reason := models.EmailSuppressionReason(c.Query("reason"))
+	entries, total, err := h.emailService.ListSuppressions(c.Query("email"), reason, params.Limit, params.Offset)
+	if err != nil {
+		h.suppressionErrorResponse(c, "Failed to get email suppressions", err)
+		return
+	}
+
+	paginationMeta := utils.CreatePaginationMeta(params, total)
+	utils.PaginatedSuccessResponse(c, "Email suppressions retrieved successfully", entries, paginationMeta, nil)
+}
+
+// AddSuppression puts an address on the suppression list, for bounces and
+// complaints reported by the mail provider
+func (h *EmailSuppressionHandler) AddSuppression(c *gin.Context) {
+	adminID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateEmailSuppressionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	entry, err := h.emailService.SuppressEmail(req.Email, req.Reason, models.SuppressionSourceAdmin, strings.TrimSpace(req.Detail), &adminID)
+	if err != nil {
+		h.suppressionErrorResponse(c, "Failed to add email suppression", err)
+		return
+	}
+
+	utils.CreatedResponse(c, "Email address suppressed", entry)
+}
+
+// RemoveSuppression takes an entry off the suppression list
+func (h *EmailSuppressionHandler) RemoveSuppression(c *gin.Context) {
+	suppressionID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid suppression ID", err)
+		return
+	}
+
+	if err := h.emailService.RemoveSuppression(suppressionID); err != nil {
+		h.suppressionErrorResponse(c, "Failed to remove email suppression", err)
+		return
+	}
+
+	utils.OkResponse(c, "Email suppression removed", nil)
+}
+
+func (h *EmailSuppressionHandler) suppressionErrorResponse(c *gin.Context, message string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		utils.NotFoundResponse(c, "Email suppression not found")
+	case strings.Contains(err.Error(), "invalid"):
+		utils.BadRequestResponse(c, err.Error(), err)
+	default:
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+}
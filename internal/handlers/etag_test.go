@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// conditionalGet calls a detail handler as viewer with an optional
+// If-None-Match and returns the recorded response
+func conditionalGet(h *testutil.Harness, viewer *models.User, serve gin.HandlerFunc, id, ifNoneMatch string) *httptest.ResponseRecorder {
+	c, recorder := h.AuthenticatedContext(viewer, http.MethodGet, "/"+id, nil)
+	c.Params = gin.Params{{Key: "id", Value: id}}
+	if ifNoneMatch != "" {
+		c.Request.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	serve(c)
+	return recorder
+}
+
+// assertRevalidation checks that a repeated fetch gets 304 with no body,
+// that update changes the ETag so the next fetch returns the object again,
+// and that another viewer gets a different ETag
+func assertRevalidation(t *testing.T, h *testutil.Harness, serve gin.HandlerFunc, id string, viewer, other *models.User, update func()) {
+	t.Helper()
+
+	first := conditionalGet(h, viewer, serve, id, "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("first fetch = %d with ETag %q, want 200 with an ETag: %s", first.Code, etag, first.Body.String())
+	}
+
+	repeat := conditionalGet(h, viewer, serve, id, etag)
+	if repeat.Code != http.StatusNotModified {
+		t.Fatalf("fetch with the current ETag = %d, want 304", repeat.Code)
+	}
+	if repeat.Body.Len() != 0 {
+		t.Errorf("304 carries a body: %s", repeat.Body.String())
+	}
+
+	if otherETag := conditionalGet(h, other, serve, id, "").Header().Get("ETag"); otherETag == etag {
+		t.Error("another viewer got the same ETag for a personalized response")
+	}
+	if stale := conditionalGet(h, other, serve, id, etag); stale.Code != http.StatusOK {
+		t.Errorf("another viewer sending this viewer's ETag = %d, want 200", stale.Code)
+	}
+
+	update()
+
+	revalidated := conditionalGet(h, viewer, serve, id, etag)
+	if revalidated.Code != http.StatusOK {
+		t.Fatalf("fetch with the old ETag after an update = %d, want 200", revalidated.Code)
+	}
+	newETag := revalidated.Header().Get("ETag")
+	if newETag == "" || newETag == etag {
+		t.Fatalf("ETag after an update = %q, want a new tag", newETag)
+	}
+	if again := conditionalGet(h, viewer, serve, id, newETag); again.Code != http.StatusNotModified {
+		t.Errorf("fetch with the new ETag = %d, want 304", again.Code)
+	}
+}
+
+func TestPostDetailConditionalGet(t *testing.T) {
+	h := testutil.NewHarness(t)
+
+	limits := services.NewLimitsService(h.DB, time.Minute)
+	linkBlocklist := services.NewLinkBlocklistService(h.DB, services.LinkBlocklistPolicy{CacheTTL: time.Minute})
+	posts := services.NewPostService(h.DB, services.DuplicateContentPolicy{}, limits, models.NewHashtagCategorizer(nil), linkBlocklist)
+	handler := NewPostHandler(posts)
+
+	author := h.CreateUser()
+	viewer := h.CreateUser()
+	post := h.CreatePost(author)
+
+	assertRevalidation(t, h, handler.GetPost, post.ID.Hex(), viewer, author, func() {
+		content := "Edited after the first fetch"
+		if _, err := posts.UpdatePost(post.ID, author.ID, models.UpdatePostRequest{Content: &content}); err != nil {
+			t.Fatalf("UpdatePost: %v", err)
+		}
+	})
+
+	// The viewer's own reaction revalidates even when no counter moves, as
+	// does an edit to the author the response embeds
+	stale := func(what string, change func()) {
+		t.Helper()
+		etag := conditionalGet(h, viewer, handler.GetPost, post.ID.Hex(), "").Header().Get("ETag")
+		change()
+		if recorder := conditionalGet(h, viewer, handler.GetPost, post.ID.Hex(), etag); recorder.Code != http.StatusOK {
+			t.Errorf("fetch with the ETag from before %s = %d, want 200", what, recorder.Code)
+		}
+	}
+	stale("the viewer reacted", func() {
+		if _, err := h.DB.Collection("likes").InsertOne(h.Context(), bson.M{
+			"user_id":       viewer.ID,
+			"target_id":     post.ID,
+			"target_type":   "post",
+			"reaction_type": models.ReactionLove,
+		}); err != nil {
+			t.Fatalf("inserting reaction: %v", err)
+		}
+	})
+	stale("the author's profile changed", func() {
+		bio := "Updated after the first fetch"
+		if _, err := services.NewUserService(h.DB, services.AbuseScorePolicy{}, limits).UpdateUser(author.ID, models.UpdateProfileRequest{Bio: &bio}); err != nil {
+			t.Fatalf("UpdateUser: %v", err)
+		}
+	})
+}
+
+func TestUserProfileConditionalGet(t *testing.T) {
+	h := testutil.NewHarness(t)
+
+	limits := services.NewLimitsService(h.DB, time.Minute)
+	users := services.NewUserService(h.DB, services.AbuseScorePolicy{}, limits)
+	handler := NewUserHandler(users, services.NewSuggestionService(h.DB))
+
+	owner := h.CreateUser()
+	viewer := h.CreateUser()
+	other := h.CreateUser()
+
+	assertRevalidation(t, h, handler.GetUserProfile, owner.ID.Hex(), viewer, other, func() {
+		bio := "Updated after the first fetch"
+		if _, err := users.UpdateUser(owner.ID, models.UpdateProfileRequest{Bio: &bio}); err != nil {
+			t.Fatalf("UpdateUser: %v", err)
+		}
+	})
+
+	// A new follower changes the counters, which revalidates too
+	etag := conditionalGet(h, viewer, handler.GetUserProfile, owner.ID.Hex(), "").Header().Get("ETag")
+	if _, err := services.NewFollowService(h.DB, services.NewNotificationService(nil, nil)).FollowUser(other.ID, owner.ID); err != nil {
+		t.Fatalf("FollowUser: %v", err)
+	}
+	if recorder := conditionalGet(h, viewer, handler.GetUserProfile, owner.ID.Hex(), etag); recorder.Code != http.StatusOK {
+		t.Errorf("fetch with the ETag from before a new follower = %d, want 200", recorder.Code)
+	}
+}
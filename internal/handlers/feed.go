@@ -32,29 +32,22 @@ func NewFeedHandler(feedService *services.FeedService, behaviorService *services
 
 // GetPersonalizedFeed with behavior option
 func (h *FeedHandler) GetPersonalizedFeed(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
-	// Get algorithm parameter
-	algorithm := c.DefaultQuery("algorithm", "standard") // behavior or standard
 	refresh := c.Query("refresh") == "true"
+	languages := utils.GetLanguageFilter(c)
 
-	var feedItems []services.FeedItem
-	var err error
-
-	if algorithm == "behavior" && h.behaviorService != nil {
-		// Use behavior-driven algorithm
-		feedItems, err = h.getBehaviorEnhancedFeed(userID.(primitive.ObjectID), "home", params.Limit, params.Offset, refresh)
-	} else {
-		// Use standard algorithm
-		feedItems, err = h.feedService.GetUserFeed(userID.(primitive.ObjectID), "home", params.Limit, params.Offset, refresh)
-	}
+	feedItems, algorithm, source, err := h.getFeed(c, userID, "home", params.Limit, params.Offset, refresh, languages)
 
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get personalized feed", err)
@@ -64,13 +57,17 @@ func (h *FeedHandler) GetPersonalizedFeed(c *gin.Context) {
 	totalCount := int64(len(feedItems))
 	paginationMeta := utils.CreatePaginationMeta(params, totalCount)
 
+	// Injected slots are added per page, so they never count towards pagination
+	feedItems = h.feedService.InjectSlots(userID, feedItems, params.Offset, languages)
+
 	// Add algorithm context to response
 	response := gin.H{
 		"feed_type": "personalized",
 		"items":     feedItems,
 		"meta": gin.H{
 			"algorithm":        algorithm,
-			"behavior_enabled": algorithm == "behavior",
+			"algorithm_source": source,
+			"behavior_enabled": algorithm == models.FeedAlgorithmBehavior,
 			"total_items":      totalCount,
 		},
 	}
@@ -80,27 +77,22 @@ func (h *FeedHandler) GetPersonalizedFeed(c *gin.Context) {
 
 // GetFollowingFeed with behavior enhancements
 func (h *FeedHandler) GetFollowingFeed(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
-	// Get algorithm parameter
-	algorithm := c.DefaultQuery("algorithm", "standard")
 	refresh := c.Query("refresh") == "true"
+	languages := utils.GetLanguageFilter(c)
 
-	var feedItems []services.FeedItem
-	var err error
-
-	if algorithm == "behavior" && h.behaviorService != nil {
-		feedItems, err = h.getBehaviorEnhancedFeed(userID.(primitive.ObjectID), "following", params.Limit, params.Offset, refresh)
-	} else {
-		feedItems, err = h.feedService.GetUserFeed(userID.(primitive.ObjectID), "following", params.Limit, params.Offset, refresh)
-	}
+	feedItems, algorithm, source, err := h.getFeed(c, userID, "following", params.Limit, params.Offset, refresh, languages)
 
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get following feed", err)
@@ -115,7 +107,8 @@ func (h *FeedHandler) GetFollowingFeed(c *gin.Context) {
 		"items":     feedItems,
 		"meta": gin.H{
 			"algorithm":        algorithm,
-			"behavior_enabled": algorithm == "behavior",
+			"algorithm_source": source,
+			"behavior_enabled": algorithm == models.FeedAlgorithmBehavior,
 			"total_items":      totalCount,
 		},
 	}
@@ -126,26 +119,19 @@ func (h *FeedHandler) GetFollowingFeed(c *gin.Context) {
 // GetTrendingFeed with behavior personalization
 func (h *FeedHandler) GetTrendingFeed(c *gin.Context) {
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
-	// Get algorithm parameter
-	algorithm := c.DefaultQuery("algorithm", "standard")
 	refresh := c.Query("refresh") == "true"
+	languages := utils.GetLanguageFilter(c)
 
 	// Get current user ID if authenticated
-	var userID primitive.ObjectID
-	if uid, exists := c.Get("user_id"); exists {
-		userID = uid.(primitive.ObjectID)
-	}
+	userID, _ := utils.LookupUserID(c)
 
-	var feedItems []services.FeedItem
-	var err error
-
-	if algorithm == "behavior" && h.behaviorService != nil && !userID.IsZero() {
-		feedItems, err = h.getBehaviorEnhancedFeed(userID, "trending", params.Limit, params.Offset, refresh)
-	} else {
-		feedItems, err = h.feedService.GetUserFeed(userID, "trending", params.Limit, params.Offset, refresh)
-	}
+	feedItems, algorithm, source, err := h.getFeed(c, userID, "trending", params.Limit, params.Offset, refresh, languages)
 
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get trending feed", err)
@@ -160,7 +146,8 @@ func (h *FeedHandler) GetTrendingFeed(c *gin.Context) {
 		"items":     feedItems,
 		"meta": gin.H{
 			"algorithm":        algorithm,
-			"behavior_enabled": algorithm == "behavior" && !userID.IsZero(),
+			"algorithm_source": source,
+			"behavior_enabled": algorithm == models.FeedAlgorithmBehavior,
 			"total_items":      totalCount,
 		},
 	}
@@ -168,29 +155,45 @@ func (h *FeedHandler) GetTrendingFeed(c *gin.Context) {
 	utils.PaginatedSuccessResponse(c, "Trending feed retrieved successfully", response, paginationMeta, nil)
 }
 
+// GetNetworkTrendingFeed returns the posts trending among the accounts the
+// user follows, each labelled with who in the network engaged
+func (h *FeedHandler) GetNetworkTrendingFeed(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	feedItems, err := h.feedService.GetNetworkTrending(userID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get network trending feed", err)
+		return
+	}
+
+	utils.OkResponse(c, "Network trending feed retrieved successfully", gin.H{
+		"feed_type": "network_trending",
+		"items":     feedItems,
+		"meta": gin.H{
+			"total_items": len(feedItems),
+		},
+	})
+}
+
 // GetDiscoverFeed with intelligent discovery
 func (h *FeedHandler) GetDiscoverFeed(c *gin.Context) {
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
-	// Get algorithm parameter
-	algorithm := c.DefaultQuery("algorithm", "standard")
 	refresh := c.Query("refresh") == "true"
+	languages := utils.GetLanguageFilter(c)
 
 	// Get current user ID if authenticated
-	var userID primitive.ObjectID
-	if uid, exists := c.Get("user_id"); exists {
-		userID = uid.(primitive.ObjectID)
-	}
+	userID, _ := utils.LookupUserID(c)
 
-	var feedItems []services.FeedItem
-	var err error
-
-	if algorithm == "behavior" && h.behaviorService != nil && !userID.IsZero() {
-		feedItems, err = h.getBehaviorEnhancedFeed(userID, "discover", params.Limit, params.Offset, refresh)
-	} else {
-		feedItems, err = h.feedService.GetUserFeed(userID, "discover", params.Limit, params.Offset, refresh)
-	}
+	feedItems, algorithm, source, err := h.getFeed(c, userID, "discover", params.Limit, params.Offset, refresh, languages)
 
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get discover feed", err)
@@ -205,7 +208,8 @@ func (h *FeedHandler) GetDiscoverFeed(c *gin.Context) {
 		"items":     feedItems,
 		"meta": gin.H{
 			"algorithm":        algorithm,
-			"behavior_enabled": algorithm == "behavior" && !userID.IsZero(),
+			"algorithm_source": source,
+			"behavior_enabled": algorithm == models.FeedAlgorithmBehavior,
 			"total_items":      totalCount,
 		},
 	}
@@ -215,9 +219,8 @@ func (h *FeedHandler) GetDiscoverFeed(c *gin.Context) {
 
 // RecordInteraction with enhanced tracking
 func (h *FeedHandler) RecordInteraction(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -256,7 +259,7 @@ func (h *FeedHandler) RecordInteraction(c *gin.Context) {
 
 	// Record in feed service
 	err = h.feedService.RecordInteraction(
-		userID.(primitive.ObjectID),
+		userID,
 		postID,
 		req.InteractionType,
 		req.Source,
@@ -270,7 +273,7 @@ func (h *FeedHandler) RecordInteraction(c *gin.Context) {
 	// Record enhanced behavior data if behavior service is available
 	if h.behaviorService != nil {
 		engagement := models.ContentEngagement{
-			UserID:       userID.(primitive.ObjectID),
+			UserID:       userID,
 			ContentID:    postID,
 			ContentType:  "post",
 			ViewTime:     time.Now(),
@@ -302,9 +305,8 @@ func (h *FeedHandler) RecordInteraction(c *gin.Context) {
 
 // GetFeedAnalytics with behavior insights
 func (h *FeedHandler) GetFeedAnalytics(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -315,25 +317,25 @@ func (h *FeedHandler) GetFeedAnalytics(c *gin.Context) {
 	}
 
 	analytics := gin.H{
-		"user_id":    userID.(primitive.ObjectID).Hex(),
+		"user_id":    userID.Hex(),
 		"time_range": timeRange,
 	}
 
 	// Get behavior analytics if available
 	if h.behaviorService != nil {
-		behaviorAnalytics, err := h.behaviorService.GetUserBehaviorAnalytics(userID.(primitive.ObjectID), timeRange)
+		behaviorAnalytics, err := h.behaviorService.GetUserBehaviorAnalytics(userID, timeRange)
 		if err == nil {
 			analytics["behavior_insights"] = behaviorAnalytics
 		}
 
 		// Get content preferences
-		preferences, err := h.behaviorService.GetUserContentPreferences(userID.(primitive.ObjectID))
+		preferences, err := h.behaviorService.GetUserContentPreferences(userID)
 		if err == nil {
 			analytics["content_preferences"] = preferences
 		}
 
 		// Get similar users
-		similarUsers, err := h.behaviorService.GetSimilarUsers(userID.(primitive.ObjectID), 5)
+		similarUsers, err := h.behaviorService.GetSimilarUsers(userID, 5)
 		if err == nil {
 			var userIDs []string
 			for _, id := range similarUsers {
@@ -371,9 +373,8 @@ func (h *FeedHandler) GetFeedAnalytics(c *gin.Context) {
 
 // RefreshFeed forces refresh of user's feed
 func (h *FeedHandler) RefreshFeed(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -392,7 +393,7 @@ func (h *FeedHandler) RefreshFeed(c *gin.Context) {
 		return
 	}
 
-	err := h.feedService.RefreshUserFeed(userID.(primitive.ObjectID), req.FeedType)
+	err := h.feedService.RefreshUserFeed(userID, req.FeedType)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to refresh feed", err)
 		return
@@ -406,9 +407,8 @@ func (h *FeedHandler) RefreshFeed(c *gin.Context) {
 
 // HidePost hides a post from user's feed
 func (h *FeedHandler) HidePost(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -441,7 +441,7 @@ func (h *FeedHandler) HidePost(c *gin.Context) {
 		}
 
 		go h.behaviorService.RecordInteraction(
-			userID.(primitive.ObjectID),
+			userID,
 			postObjectID,
 			"post",
 			"hide",
@@ -459,9 +459,8 @@ func (h *FeedHandler) HidePost(c *gin.Context) {
 
 // ReportFeedIssue reports issues with feed algorithm
 func (h *FeedHandler) ReportFeedIssue(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -491,7 +490,7 @@ func (h *FeedHandler) ReportFeedIssue(c *gin.Context) {
 
 	// Record the feedback
 	feedback := gin.H{
-		"user_id":     userID.(primitive.ObjectID).Hex(),
+		"user_id":     userID.Hex(),
 		"issue_type":  req.IssueType,
 		"description": req.Description,
 		"feed_type":   req.FeedType,
@@ -516,17 +515,19 @@ func (h *FeedHandler) ReportFeedIssue(c *gin.Context) {
 
 // GetFeedPreferences gets user's feed preferences
 func (h *FeedHandler) GetFeedPreferences(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
+	algorithm, source := h.feedService.ResolveFeedAlgorithm(userID, "")
+
 	// Default preferences - in real app, these would be fetched from database
 	preferences := gin.H{
-		"user_id": userID.(primitive.ObjectID).Hex(),
+		"user_id": userID.Hex(),
 		"feed_preferences": gin.H{
-			"algorithm_type":        "personalized", // chronological, personalized, hybrid
+			"algorithm_type":        algorithm,
+			"algorithm_source":      source,
 			"show_liked_posts":      true,
 			"show_shared_posts":     true,
 			"show_reposted_content": true,
@@ -561,9 +562,8 @@ func (h *FeedHandler) GetFeedPreferences(c *gin.Context) {
 
 // UpdateFeedPreferences updates user's feed preferences
 func (h *FeedHandler) UpdateFeedPreferences(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -594,7 +594,7 @@ func (h *FeedHandler) UpdateFeedPreferences(c *gin.Context) {
 	// For now, we'll just return the updated preferences
 
 	updatedPreferences := gin.H{
-		"user_id": userID.(primitive.ObjectID).Hex(),
+		"user_id": userID.Hex(),
 		"updates": gin.H{},
 	}
 
@@ -625,25 +625,55 @@ func (h *FeedHandler) UpdateFeedPreferences(c *gin.Context) {
 	utils.OkResponse(c, "Feed preferences updated successfully", updatedPreferences)
 }
 
+// getFeed serves a feed with the algorithm resolved for the user and request,
+// and logs which algorithm served it. The behavior algorithm needs a signed
+// in user and the behavior service, and falls back to standard without them.
+func (h *FeedHandler) getFeed(c *gin.Context, userID primitive.ObjectID, feedType string, limit, skip int, refresh bool, languages []string) ([]services.FeedItem, string, string, error) {
+	// Behavior routes fix the algorithm in the context
+	requested := c.Query("algorithm")
+	if requested == "" {
+		requested = c.GetString("algorithm")
+	}
+
+	algorithm, source := h.feedService.ResolveFeedAlgorithm(userID, requested)
+	if algorithm == models.FeedAlgorithmBehavior && (h.behaviorService == nil || userID.IsZero()) {
+		algorithm = models.FeedAlgorithmStandard
+	}
+
+	var feedItems []services.FeedItem
+	var err error
+	if algorithm == models.FeedAlgorithmBehavior {
+		feedItems, err = h.getBehaviorEnhancedFeed(userID, feedType, limit, skip, refresh, languages)
+	} else {
+		feedItems, err = h.feedService.GetFeedWithAlgorithm(userID, feedType, algorithm, limit, skip, refresh, languages)
+	}
+	if err != nil {
+		return nil, algorithm, source, err
+	}
+
+	h.feedService.RecordFeedServed(userID, feedType, algorithm, source, len(feedItems))
+	return feedItems, algorithm, source, nil
+}
+
 // Get behavior-enhanced feed
-func (h *FeedHandler) getBehaviorEnhancedFeed(userID primitive.ObjectID, feedType string, limit, skip int, refresh bool) ([]services.FeedItem, error) {
+func (h *FeedHandler) getBehaviorEnhancedFeed(userID primitive.ObjectID, feedType string, limit, skip int, refresh bool, languages []string) ([]services.FeedItem, error) {
 	if h.behaviorService == nil {
 		// Fallback to standard feed if behavior service not available
-		return h.feedService.GetUserFeed(userID, feedType, limit, skip, refresh)
+		return h.feedService.GetUserFeed(userID, feedType, limit, skip, refresh, languages)
 	}
 
 	// Get user preferences
 	userPrefs, err := h.behaviorService.GetUserContentPreferences(userID)
 	if err != nil {
 		// Fallback to standard feed if can't get preferences
-		return h.feedService.GetUserFeed(userID, feedType, limit, skip, refresh)
+		return h.feedService.GetUserFeed(userID, feedType, limit, skip, refresh, languages)
 	}
 
 	// Get similar users for collaborative filtering
 	similarUsers, _ := h.behaviorService.GetSimilarUsers(userID, 10)
 
 	// Get standard feed first
-	standardFeed, err := h.feedService.GetUserFeed(userID, feedType, limit*2, skip, refresh) // Get more items for better selection
+	standardFeed, err := h.feedService.GetUserFeed(userID, feedType, limit*2, skip, refresh, languages) // Get more items for better selection
 	if err != nil {
 		return nil, err
 	}
@@ -746,9 +776,8 @@ func (h *FeedHandler) applyBehaviorDiversity(feedItems []services.FeedItem, user
 
 // Get user behavior insights
 func (h *FeedHandler) GetBehaviorInsights(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -760,14 +789,14 @@ func (h *FeedHandler) GetBehaviorInsights(c *gin.Context) {
 	timeRange := c.DefaultQuery("time_range", "week")
 
 	// Get comprehensive analytics
-	analytics, err := h.behaviorService.GetUserBehaviorAnalytics(userID.(primitive.ObjectID), timeRange)
+	analytics, err := h.behaviorService.GetUserBehaviorAnalytics(userID, timeRange)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get behavior insights", err)
 		return
 	}
 
 	// Get content preferences
-	preferences, err := h.behaviorService.GetUserContentPreferences(userID.(primitive.ObjectID))
+	preferences, err := h.behaviorService.GetUserContentPreferences(userID)
 	if err != nil {
 		preferences = make(map[string]float64) // Fallback to empty preferences
 	}
@@ -776,7 +805,7 @@ func (h *FeedHandler) GetBehaviorInsights(c *gin.Context) {
 	insights := h.generateBehaviorInsights(analytics, preferences)
 
 	utils.OkResponse(c, "Behavior insights retrieved successfully", gin.H{
-		"user_id":      userID.(primitive.ObjectID).Hex(),
+		"user_id":      userID.Hex(),
 		"time_range":   timeRange,
 		"insights":     insights,
 		"analytics":    analytics,
@@ -860,13 +889,7 @@ func (h *FeedHandler) isValidInteractionSource(source string) bool {
 }
 
 func (h *FeedHandler) isValidAlgorithmType(algorithmType string) bool {
-	validTypes := []string{"chronological", "personalized", "trending", "behavior", "hybrid"}
-	for _, t := range validTypes {
-		if algorithmType == t {
-			return true
-		}
-	}
-	return false
+	return models.IsValidFeedAlgorithm(algorithmType)
 }
 
 func (h *FeedHandler) isValidContentType(contentType string) bool {
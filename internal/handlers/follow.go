@@ -35,17 +35,21 @@ func (h *FollowHandler) GetFollowers(c *gin.Context) {
 	}
 
 	// Get current user ID if authenticated
-	var currentUserID *primitive.ObjectID
-	if uid, exists := c.Get("user_id"); exists {
-		id := uid.(primitive.ObjectID)
-		currentUserID = &id
-	}
+	currentUserID := utils.OptionalUserID(c)
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	followers, err := h.followService.GetFollowers(userID, currentUserID, params.Limit, params.Offset)
 	if err != nil {
+		if strings.Contains(err.Error(), "social graph hidden") {
+			h.hiddenGraphResponse(c, userID)
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to get followers", err)
 		return
 	}
@@ -66,17 +70,21 @@ func (h *FollowHandler) GetFollowing(c *gin.Context) {
 	}
 
 	// Get current user ID if authenticated
-	var currentUserID *primitive.ObjectID
-	if uid, exists := c.Get("user_id"); exists {
-		id := uid.(primitive.ObjectID)
-		currentUserID = &id
-	}
+	currentUserID := utils.OptionalUserID(c)
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	following, err := h.followService.GetFollowing(userID, currentUserID, params.Limit, params.Offset)
 	if err != nil {
+		if strings.Contains(err.Error(), "social graph hidden") {
+			h.hiddenGraphResponse(c, userID)
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to get following", err)
 		return
 	}
@@ -107,9 +115,8 @@ func (h *FollowHandler) GetFollowStats(c *gin.Context) {
 
 // GetMutualFollows retrieves mutual follows between current user and another user
 func (h *FollowHandler) GetMutualFollows(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -121,10 +128,18 @@ func (h *FollowHandler) GetMutualFollows(c *gin.Context) {
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
-	mutualFollows, err := h.followService.GetMutualFollows(userID.(primitive.ObjectID), targetUserID, params.Limit, params.Offset)
+	mutualFollows, err := h.followService.GetMutualFollows(userID, targetUserID, params.Limit, params.Offset)
 	if err != nil {
+		if strings.Contains(err.Error(), "social graph hidden") {
+			h.hiddenGraphResponse(c, targetUserID)
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to get mutual follows", err)
 		return
 	}
@@ -137,9 +152,8 @@ func (h *FollowHandler) GetMutualFollows(c *gin.Context) {
 
 // CheckFollowStatus checks if current user follows another user
 func (h *FollowHandler) CheckFollowStatus(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -150,7 +164,7 @@ func (h *FollowHandler) CheckFollowStatus(c *gin.Context) {
 		return
 	}
 
-	status, err := h.followService.GetFollowStatus(userID.(primitive.ObjectID), targetUserID)
+	status, err := h.followService.GetFollowStatus(userID, targetUserID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to check follow status", err)
 		return
@@ -166,9 +180,8 @@ func (h *FollowHandler) CheckFollowStatus(c *gin.Context) {
 
 // FollowUser follows another user
 func (h *FollowHandler) FollowUser(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -180,12 +193,12 @@ func (h *FollowHandler) FollowUser(c *gin.Context) {
 	}
 
 	// Check if user is trying to follow themselves
-	if userID.(primitive.ObjectID) == followeeID {
+	if userID == followeeID {
 		utils.BadRequestResponse(c, "Cannot follow yourself", nil)
 		return
 	}
 
-	follow, err := h.followService.FollowUser(userID.(primitive.ObjectID), followeeID)
+	follow, err := h.followService.FollowUser(userID, followeeID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "User not found")
@@ -209,9 +222,8 @@ func (h *FollowHandler) FollowUser(c *gin.Context) {
 
 // UnfollowUser unfollows another user
 func (h *FollowHandler) UnfollowUser(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -222,8 +234,12 @@ func (h *FollowHandler) UnfollowUser(c *gin.Context) {
 		return
 	}
 
-	err = h.followService.UnfollowUser(userID.(primitive.ObjectID), followeeID)
+	err = h.followService.UnfollowUser(userID, followeeID)
 	if err != nil {
+		if strings.Contains(err.Error(), "yourself") {
+			utils.BadRequestResponse(c, "Cannot unfollow yourself", nil)
+			return
+		}
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "Follow relationship not found")
 			return
@@ -240,9 +256,8 @@ func (h *FollowHandler) UnfollowUser(c *gin.Context) {
 
 // RemoveFollower removes a follower
 func (h *FollowHandler) RemoveFollower(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -253,7 +268,7 @@ func (h *FollowHandler) RemoveFollower(c *gin.Context) {
 		return
 	}
 
-	err = h.followService.RemoveFollower(userID.(primitive.ObjectID), followerID)
+	err = h.followService.RemoveFollower(userID, followerID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "Follower relationship not found")
@@ -271,14 +286,17 @@ func (h *FollowHandler) RemoveFollower(c *gin.Context) {
 
 // GetFollowRequests retrieves pending follow requests
 func (h *FollowHandler) GetFollowRequests(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// Get type parameter (received or sent)
 	requestType := c.DefaultQuery("type", "received")
@@ -287,12 +305,11 @@ func (h *FollowHandler) GetFollowRequests(c *gin.Context) {
 	}
 
 	var requests []models.FollowResponse
-	var err error
 
 	if requestType == "received" {
-		requests, err = h.followService.GetPendingFollowRequests(userID.(primitive.ObjectID), params.Limit, params.Offset)
+		requests, err = h.followService.GetPendingFollowRequests(userID, params.Limit, params.Offset)
 	} else {
-		requests, err = h.followService.GetSentFollowRequests(userID.(primitive.ObjectID), params.Limit, params.Offset)
+		requests, err = h.followService.GetSentFollowRequests(userID, params.Limit, params.Offset)
 	}
 
 	if err != nil {
@@ -308,9 +325,8 @@ func (h *FollowHandler) GetFollowRequests(c *gin.Context) {
 
 // AcceptFollowRequest accepts a follow request
 func (h *FollowHandler) AcceptFollowRequest(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -321,7 +337,7 @@ func (h *FollowHandler) AcceptFollowRequest(c *gin.Context) {
 		return
 	}
 
-	err = h.followService.AcceptFollowRequest(followID, userID.(primitive.ObjectID))
+	err = h.followService.AcceptFollowRequest(followID, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "Follow request not found")
@@ -343,9 +359,8 @@ func (h *FollowHandler) AcceptFollowRequest(c *gin.Context) {
 
 // RejectFollowRequest rejects a follow request
 func (h *FollowHandler) RejectFollowRequest(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -356,7 +371,7 @@ func (h *FollowHandler) RejectFollowRequest(c *gin.Context) {
 		return
 	}
 
-	err = h.followService.RejectFollowRequest(followID, userID.(primitive.ObjectID))
+	err = h.followService.RejectFollowRequest(followID, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "Follow request not found")
@@ -378,9 +393,8 @@ func (h *FollowHandler) RejectFollowRequest(c *gin.Context) {
 
 // CancelFollowRequest cancels a sent follow request
 func (h *FollowHandler) CancelFollowRequest(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -391,7 +405,7 @@ func (h *FollowHandler) CancelFollowRequest(c *gin.Context) {
 		return
 	}
 
-	err = h.followService.CancelFollowRequest(followID, userID.(primitive.ObjectID))
+	err = h.followService.CancelFollowRequest(followID, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "Follow request not found")
@@ -413,21 +427,19 @@ func (h *FollowHandler) CancelFollowRequest(c *gin.Context) {
 
 // GetSuggestedUsers retrieves suggested users to follow
 func (h *FollowHandler) GetSuggestedUsers(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Get limit parameter
-	limit := 10
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if l, err := utils.StringToInt(limitStr); err == nil && l > 0 && l <= 50 {
-			limit = l
-		}
+	limit, err := utils.ParseLimit(c, 10, 50)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
 	}
 
-	suggestions, err := h.followService.GetSuggestedUsers(userID.(primitive.ObjectID), limit)
+	suggestions, err := h.followService.GetSuggestedUsers(userID, limit)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get suggested users", err)
 		return
@@ -439,52 +451,94 @@ func (h *FollowHandler) GetSuggestedUsers(c *gin.Context) {
 	})
 }
 
-// BulkFollowUsers follows multiple users at once
-func (h *FollowHandler) BulkFollowUsers(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+// GetOnboardingSuggestions retrieves curated and popular accounts for a new user to follow
+func (h *FollowHandler) GetOnboardingSuggestions(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	var req struct {
-		UserIDs []string `json:"user_ids" binding:"required"`
+	suggestions, err := h.followService.GetOnboardingSuggestions(userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "User not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get onboarding suggestions", err)
+		return
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequestResponse(c, "Invalid request format", err)
+	utils.OkResponse(c, "Onboarding suggestions retrieved successfully", gin.H{
+		"suggestions": suggestions,
+		"count":       len(suggestions),
+	})
+}
+
+// FollowMany follows several users at once, reporting each target's outcome
+func (h *FollowHandler) FollowMany(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	if len(req.UserIDs) == 0 {
-		utils.BadRequestResponse(c, "At least one user ID is required", nil)
+	var req models.FollowManyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
 		return
 	}
 
-	if len(req.UserIDs) > 50 {
-		utils.BadRequestResponse(c, "Cannot follow more than 50 users at once", nil)
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
 		return
 	}
 
-	results, err := h.followService.BulkFollowUsers(userID.(primitive.ObjectID), req.UserIDs)
+	results, err := h.followService.FollowMany(userID, req.UserIDs)
 	if err != nil {
+		if strings.Contains(err.Error(), "cannot follow more than") {
+			utils.BadRequestResponse(c, err.Error(), err)
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "User not found")
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to follow users", err)
 		return
 	}
 
-	utils.OkResponse(c, "Bulk follow completed", results)
+	followed, requested, failed := 0, 0, 0
+	for _, result := range results {
+		switch {
+		case result.Error != "":
+			failed++
+		case result.Status == models.FollowStatusPending:
+			requested++
+		default:
+			followed++
+		}
+	}
+
+	utils.OkResponse(c, "Follow many completed", gin.H{
+		"results":   results,
+		"followed":  followed,
+		"requested": requested,
+		"failed":    failed,
+	})
 }
 
 // GetFollowActivity retrieves recent follow activity
 func (h *FollowHandler) GetFollowActivity(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// Get activity type parameter
 	activityType := c.DefaultQuery("type", "all")
@@ -500,7 +554,7 @@ func (h *FollowHandler) GetFollowActivity(c *gin.Context) {
 		activityType = "all"
 	}
 
-	activity, err := h.followService.GetFollowActivity(userID.(primitive.ObjectID), activityType, params.Limit, params.Offset)
+	activity, err := h.followService.GetFollowActivity(userID, activityType, params.Limit, params.Offset)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get follow activity", err)
 		return
@@ -511,3 +565,16 @@ func (h *FollowHandler) GetFollowActivity(c *gin.Context) {
 
 	utils.PaginatedSuccessResponse(c, "Follow activity retrieved successfully", activity, paginationMeta, nil)
 }
+
+// hiddenGraphResponse answers a list request for a user who hides their social
+// graph with their follower and following counts only
+func (h *FollowHandler) hiddenGraphResponse(c *gin.Context, userID primitive.ObjectID) {
+	stats, err := h.followService.GetFollowStats(userID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get follow statistics", err)
+		return
+	}
+	stats.SocialGraphHidden = true
+
+	utils.OkResponse(c, "This user's connections are hidden; only counts are shown", stats)
+}
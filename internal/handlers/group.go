@@ -14,22 +14,23 @@ import (
 )
 
 type GroupHandler struct {
-	groupService *services.GroupService
-	validator    *validator.Validate
+	groupService   *services.GroupService
+	libraryService *services.GroupLibraryService
+	validator      *validator.Validate
 }
 
-func NewGroupHandler(groupService *services.GroupService) *GroupHandler {
+func NewGroupHandler(groupService *services.GroupService, libraryService *services.GroupLibraryService) *GroupHandler {
 	return &GroupHandler{
-		groupService: groupService,
-		validator:    validator.New(),
+		groupService:   groupService,
+		libraryService: libraryService,
+		validator:      validator.New(),
 	}
 }
 
 // CreateGroup creates a new group
 func (h *GroupHandler) CreateGroup(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -56,7 +57,7 @@ func (h *GroupHandler) CreateGroup(c *gin.Context) {
 		return
 	}
 
-	group, err := h.groupService.CreateGroup(userID.(primitive.ObjectID), req)
+	group, err := h.groupService.CreateGroup(userID, req)
 	if err != nil {
 		if strings.Contains(err.Error(), "already exists") {
 			utils.ConflictResponse(c, err.Error(), err)
@@ -81,10 +82,7 @@ func (h *GroupHandler) GetGroup(c *gin.Context) {
 		return
 	}
 
-	var currentUserID primitive.ObjectID
-	if userID, exists := c.Get("user_id"); exists {
-		currentUserID = userID.(primitive.ObjectID)
-	}
+	currentUserID, _ := utils.LookupUserID(c)
 
 	var group *models.Group
 	var err error
@@ -123,6 +121,14 @@ func (h *GroupHandler) GetGroup(c *gin.Context) {
 		groupResponse.CanPost = group.CanPostInGroup(role, memberStatus)
 		groupResponse.CanInvite = group.CanInviteToGroup(role)
 		groupResponse.CanModerate = group.CanModerateGroup(role)
+		if memberStatus == "member" {
+			if member, err := h.groupService.GetGroupMember(group.ID, currentUserID); err == nil {
+				groupResponse.NotificationLevel = member.EffectiveNotificationLevel()
+			}
+		}
+		if group.CanManageLibrary(role) {
+			groupResponse.LibraryStorage = h.libraryService.GetStorage(group)
+		}
 	}
 
 	utils.OkResponse(c, "Group retrieved successfully", groupResponse)
@@ -130,9 +136,8 @@ func (h *GroupHandler) GetGroup(c *gin.Context) {
 
 // UpdateGroup updates a group
 func (h *GroupHandler) UpdateGroup(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -165,7 +170,7 @@ func (h *GroupHandler) UpdateGroup(c *gin.Context) {
 		return
 	}
 
-	group, err := h.groupService.UpdateGroup(groupID, userID.(primitive.ObjectID), req)
+	group, err := h.groupService.UpdateGroup(groupID, userID, req)
 	if err != nil {
 		if strings.Contains(err.Error(), "access denied") || strings.Contains(err.Error(), "privileges required") {
 			utils.ForbiddenResponse(c, err.Error())
@@ -183,14 +188,16 @@ func (h *GroupHandler) UpdateGroup(c *gin.Context) {
 		return
 	}
 
-	utils.OkResponse(c, "Group updated successfully", group.ToGroupResponse())
+	groupResponse := group.ToGroupResponse()
+	groupResponse.LibraryStorage = h.libraryService.GetStorage(group)
+
+	utils.OkResponse(c, "Group updated successfully", groupResponse)
 }
 
 // DeleteGroup soft deletes a group
 func (h *GroupHandler) DeleteGroup(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -200,7 +207,7 @@ func (h *GroupHandler) DeleteGroup(c *gin.Context) {
 		return
 	}
 
-	err = h.groupService.DeleteGroup(groupID, userID.(primitive.ObjectID))
+	err = h.groupService.DeleteGroup(groupID, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "access denied") || strings.Contains(err.Error(), "privileges required") {
 			utils.ForbiddenResponse(c, err.Error())
@@ -215,9 +222,8 @@ func (h *GroupHandler) DeleteGroup(c *gin.Context) {
 
 // JoinGroup allows a user to join a group
 func (h *GroupHandler) JoinGroup(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -238,7 +244,7 @@ func (h *GroupHandler) JoinGroup(c *gin.Context) {
 		return
 	}
 
-	err = h.groupService.JoinGroup(groupID, userID.(primitive.ObjectID), req)
+	err = h.groupService.JoinGroup(groupID, userID, req)
 	if err != nil {
 		if strings.Contains(err.Error(), "cannot join") || strings.Contains(err.Error(), "already") {
 			utils.BadRequestResponse(c, err.Error(), err)
@@ -260,9 +266,8 @@ func (h *GroupHandler) JoinGroup(c *gin.Context) {
 
 // LeaveGroup allows a user to leave a group
 func (h *GroupHandler) LeaveGroup(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -272,7 +277,7 @@ func (h *GroupHandler) LeaveGroup(c *gin.Context) {
 		return
 	}
 
-	err = h.groupService.LeaveGroup(groupID, userID.(primitive.ObjectID))
+	err = h.groupService.LeaveGroup(groupID, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not a member") {
 			utils.BadRequestResponse(c, err.Error(), err)
@@ -289,11 +294,50 @@ func (h *GroupHandler) LeaveGroup(c *gin.Context) {
 	utils.OkResponse(c, "Left group successfully", nil)
 }
 
+// UpdateNotificationSettings sets which of the group's notifications reach the current member
+func (h *GroupHandler) UpdateNotificationSettings(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid group ID", err)
+		return
+	}
+
+	var req models.UpdateGroupNotificationSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	member, err := h.groupService.UpdateNotificationSettings(groupID, userID, req.Level)
+	if err != nil {
+		if strings.Contains(err.Error(), "not a member") {
+			utils.ForbiddenResponse(c, err.Error())
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to update notification settings", err)
+		return
+	}
+
+	utils.OkResponse(c, "Notification settings updated successfully", gin.H{
+		"group_id": groupID.Hex(),
+		"level":    member.EffectiveNotificationLevel(),
+	})
+}
+
 // InviteToGroup invites users to a group
 func (h *GroupHandler) InviteToGroup(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -320,7 +364,7 @@ func (h *GroupHandler) InviteToGroup(c *gin.Context) {
 		return
 	}
 
-	err = h.groupService.InviteToGroup(groupID, userID.(primitive.ObjectID), req)
+	err = h.groupService.InviteToGroup(groupID, userID, req)
 	if err != nil {
 		if strings.Contains(err.Error(), "access denied") || strings.Contains(err.Error(), "insufficient permissions") {
 			utils.ForbiddenResponse(c, err.Error())
@@ -337,9 +381,8 @@ func (h *GroupHandler) InviteToGroup(c *gin.Context) {
 
 // AcceptGroupInvite accepts a group invitation
 func (h *GroupHandler) AcceptGroupInvite(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -349,7 +392,7 @@ func (h *GroupHandler) AcceptGroupInvite(c *gin.Context) {
 		return
 	}
 
-	err = h.groupService.AcceptGroupInvite(inviteID, userID.(primitive.ObjectID))
+	err = h.groupService.AcceptGroupInvite(inviteID, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "expired") {
 			utils.BadRequestResponse(c, err.Error(), err)
@@ -364,9 +407,8 @@ func (h *GroupHandler) AcceptGroupInvite(c *gin.Context) {
 
 // RejectGroupInvite rejects a group invitation
 func (h *GroupHandler) RejectGroupInvite(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -376,7 +418,7 @@ func (h *GroupHandler) RejectGroupInvite(c *gin.Context) {
 		return
 	}
 
-	err = h.groupService.RejectGroupInvite(inviteID, userID.(primitive.ObjectID))
+	err = h.groupService.RejectGroupInvite(inviteID, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.BadRequestResponse(c, err.Error(), err)
@@ -389,6 +431,164 @@ func (h *GroupHandler) RejectGroupInvite(c *gin.Context) {
 	utils.OkResponse(c, "Invitation rejected successfully", nil)
 }
 
+// CreateInviteLink creates a shareable invite link for a group
+func (h *GroupHandler) CreateInviteLink(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid group ID", err)
+		return
+	}
+
+	var req models.CreateGroupInviteLinkRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BadRequestResponse(c, "Invalid request format", err)
+			return
+		}
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	link, err := h.groupService.CreateInviteLink(groupID, userID, req.MaxUses, req.ExpiresAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "access denied") || strings.Contains(err.Error(), "permissions") {
+			utils.ForbiddenResponse(c, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "expiry") || strings.Contains(err.Error(), "negative") {
+			utils.BadRequestResponse(c, err.Error(), err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to create invite link", err)
+		return
+	}
+
+	utils.CreatedResponse(c, "Invite link created successfully", link)
+}
+
+// GetInviteLinks lists a group's invite links (admin/moderator only)
+func (h *GroupHandler) GetInviteLinks(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid group ID", err)
+		return
+	}
+
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	links, err := h.groupService.GetInviteLinks(groupID, userID, params.Limit, params.Offset)
+	if err != nil {
+		if strings.Contains(err.Error(), "access denied") || strings.Contains(err.Error(), "privileges required") {
+			utils.ForbiddenResponse(c, err.Error())
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get invite links", err)
+		return
+	}
+
+	paginationMeta := utils.CreatePaginationMeta(params, int64(len(links)))
+
+	utils.PaginatedSuccessResponse(c, "Invite links retrieved successfully", links, paginationMeta, nil)
+}
+
+// RevokeInviteLink stops an invite link from being used
+func (h *GroupHandler) RevokeInviteLink(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid group ID", err)
+		return
+	}
+
+	linkID, err := primitive.ObjectIDFromHex(c.Param("link_id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid invite link ID", err)
+		return
+	}
+
+	err = h.groupService.RevokeInviteLink(groupID, linkID, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "access denied") || strings.Contains(err.Error(), "privileges required") {
+			utils.ForbiddenResponse(c, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "already revoked") {
+			utils.ConflictResponse(c, err.Error(), err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to revoke invite link", err)
+		return
+	}
+
+	utils.OkResponse(c, "Invite link revoked successfully", nil)
+}
+
+// JoinViaInvite joins the group behind an invite link token
+func (h *GroupHandler) JoinViaInvite(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	member, err := h.groupService.JoinViaInvite(c.Param("token"), userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "already") {
+			utils.ConflictResponse(c, err.Error(), err)
+			return
+		}
+		if strings.Contains(err.Error(), "revoked") || strings.Contains(err.Error(), "expired") ||
+			strings.Contains(err.Error(), "usage limit") || strings.Contains(err.Error(), "cannot join") {
+			utils.BadRequestResponse(c, err.Error(), err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to join group", err)
+		return
+	}
+
+	message := "Joined group successfully"
+	if member.Status == "pending" {
+		message = "Join request sent successfully"
+	}
+
+	utils.OkResponse(c, message, gin.H{
+		"group_id": member.GroupID.Hex(),
+		"status":   member.Status,
+	})
+}
+
 // GetGroupMembers retrieves group members
 func (h *GroupHandler) GetGroupMembers(c *gin.Context) {
 	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
@@ -397,13 +597,14 @@ func (h *GroupHandler) GetGroupMembers(c *gin.Context) {
 		return
 	}
 
-	var currentUserID primitive.ObjectID
-	if userID, exists := c.Get("user_id"); exists {
-		currentUserID = userID.(primitive.ObjectID)
-	}
+	currentUserID, _ := utils.LookupUserID(c)
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	members, err := h.groupService.GetGroupMembers(groupID, currentUserID, params.Limit, params.Offset)
 	if err != nil {
@@ -421,18 +622,159 @@ func (h *GroupHandler) GetGroupMembers(c *gin.Context) {
 	utils.PaginatedSuccessResponse(c, "Group members retrieved successfully", members, paginationMeta, nil)
 }
 
+// GetGroupPosts retrieves the group's published posts
+func (h *GroupHandler) GetGroupPosts(c *gin.Context) {
+	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid group ID", err)
+		return
+	}
+
+	currentUserID, _ := utils.LookupUserID(c)
+
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	posts, err := h.groupService.GetGroupPosts(groupID, currentUserID, params.Limit, params.Offset)
+	if err != nil {
+		if strings.Contains(err.Error(), "access denied") {
+			utils.ForbiddenResponse(c, err.Error())
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get group posts", err)
+		return
+	}
+
+	paginationMeta := utils.CreatePaginationMeta(params, int64(len(posts)))
+
+	utils.PaginatedSuccessResponse(c, "Group posts retrieved successfully", posts, paginationMeta, nil)
+}
+
+// GetPendingPosts retrieves posts waiting for approval (admin/moderator only)
+func (h *GroupHandler) GetPendingPosts(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid group ID", err)
+		return
+	}
+
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	posts, err := h.groupService.GetPendingPosts(groupID, userID, params.Limit, params.Offset)
+	if err != nil {
+		if strings.Contains(err.Error(), "access denied") || strings.Contains(err.Error(), "privileges required") {
+			utils.ForbiddenResponse(c, err.Error())
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get pending posts", err)
+		return
+	}
+
+	paginationMeta := utils.CreatePaginationMeta(params, int64(len(posts)))
+
+	utils.PaginatedSuccessResponse(c, "Pending posts retrieved successfully", posts, paginationMeta, nil)
+}
+
+// ApprovePost publishes a pending group post (admin/moderator only)
+func (h *GroupHandler) ApprovePost(c *gin.Context) {
+	h.reviewPost(c, true)
+}
+
+// RejectPost declines a pending group post (admin/moderator only)
+func (h *GroupHandler) RejectPost(c *gin.Context) {
+	h.reviewPost(c, false)
+}
+
+// reviewPost applies a moderator's decision on a pending group post
+func (h *GroupHandler) reviewPost(c *gin.Context, approve bool) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	groupID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid group ID", err)
+		return
+	}
+
+	postID, err := primitive.ObjectIDFromHex(c.Param("post_id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid post ID", err)
+		return
+	}
+
+	var req models.ReviewGroupPostRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BadRequestResponse(c, "Invalid request format", err)
+			return
+		}
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	message := "Post approved successfully"
+	if approve {
+		err = h.groupService.ApprovePost(groupID, postID, userID)
+	} else {
+		message = "Post rejected successfully"
+		err = h.groupService.RejectPost(groupID, postID, userID, req.Reason)
+	}
+
+	if err != nil {
+		if strings.Contains(err.Error(), "access denied") || strings.Contains(err.Error(), "privileges required") {
+			utils.ForbiddenResponse(c, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "not pending") {
+			utils.ConflictResponse(c, err.Error(), err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to review post", err)
+		return
+	}
+
+	utils.OkResponse(c, message, gin.H{
+		"post_id":  postID.Hex(),
+		"approved": approve,
+	})
+}
+
 // GetUserGroups retrieves groups that the user is a member of
 func (h *GroupHandler) GetUserGroups(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
-	groups, err := h.groupService.GetUserGroups(userID.(primitive.ObjectID), params.Limit, params.Offset)
+	groups, err := h.groupService.GetUserGroups(userID, params.Limit, params.Offset)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get user groups", err)
 		return
@@ -462,14 +804,14 @@ func (h *GroupHandler) SearchGroups(c *gin.Context) {
 		return
 	}
 
-	var currentUserID *primitive.ObjectID
-	if userID, exists := c.Get("user_id"); exists {
-		id := userID.(primitive.ObjectID)
-		currentUserID = &id
-	}
+	currentUserID := utils.OptionalUserID(c)
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	groups, err := h.groupService.SearchGroups(query, currentUserID, params.Limit, params.Offset)
 	if err != nil {
@@ -485,9 +827,8 @@ func (h *GroupHandler) SearchGroups(c *gin.Context) {
 
 // UpdateMemberRole updates a member's role in the group
 func (h *GroupHandler) UpdateMemberRole(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -514,7 +855,7 @@ func (h *GroupHandler) UpdateMemberRole(c *gin.Context) {
 		return
 	}
 
-	err = h.groupService.UpdateMemberRole(groupID, userID.(primitive.ObjectID), memberID, req)
+	err = h.groupService.UpdateMemberRole(groupID, userID, memberID, req)
 	if err != nil {
 		if strings.Contains(err.Error(), "access denied") || strings.Contains(err.Error(), "insufficient permissions") {
 			utils.ForbiddenResponse(c, err.Error())
@@ -536,9 +877,8 @@ func (h *GroupHandler) UpdateMemberRole(c *gin.Context) {
 
 // RemoveGroupMember removes a member from the group
 func (h *GroupHandler) RemoveGroupMember(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -554,7 +894,7 @@ func (h *GroupHandler) RemoveGroupMember(c *gin.Context) {
 		return
 	}
 
-	err = h.groupService.RemoveGroupMember(groupID, userID.(primitive.ObjectID), memberID)
+	err = h.groupService.RemoveGroupMember(groupID, userID, memberID)
 	if err != nil {
 		if strings.Contains(err.Error(), "access denied") || strings.Contains(err.Error(), "insufficient permissions") {
 			utils.ForbiddenResponse(c, err.Error())
@@ -573,9 +913,8 @@ func (h *GroupHandler) RemoveGroupMember(c *gin.Context) {
 
 // GetGroupStats retrieves group statistics (admin only)
 func (h *GroupHandler) GetGroupStats(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -585,7 +924,7 @@ func (h *GroupHandler) GetGroupStats(c *gin.Context) {
 		return
 	}
 
-	stats, err := h.groupService.GetGroupStats(groupID, userID.(primitive.ObjectID))
+	stats, err := h.groupService.GetGroupStats(groupID, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "access denied") || strings.Contains(err.Error(), "privileges required") {
 			utils.ForbiddenResponse(c, err.Error())
@@ -601,7 +940,11 @@ func (h *GroupHandler) GetGroupStats(c *gin.Context) {
 // GetPublicGroups retrieves public groups for discovery
 func (h *GroupHandler) GetPublicGroups(c *gin.Context) {
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	groups, err := h.groupService.GetPublicGroups(params.Limit, params.Offset)
 	if err != nil {
@@ -618,7 +961,11 @@ func (h *GroupHandler) GetPublicGroups(c *gin.Context) {
 // GetTrendingGroups retrieves trending groups
 func (h *GroupHandler) GetTrendingGroups(c *gin.Context) {
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// Get time range parameter
 	timeRange := c.DefaultQuery("time_range", "day") // day, week, month
@@ -645,14 +992,17 @@ func (h *GroupHandler) GetGroupCategories(c *gin.Context) {
 
 // GetUserGroupInvites retrieves group invitations for the current user
 func (h *GroupHandler) GetUserGroupInvites(c *gin.Context) {
-	_, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	_, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// This would require implementing in the service
 	// For now, return empty array
@@ -663,9 +1013,8 @@ func (h *GroupHandler) GetUserGroupInvites(c *gin.Context) {
 
 // BulkRemoveMembers removes multiple members from a group
 func (h *GroupHandler) BulkRemoveMembers(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -701,7 +1050,7 @@ func (h *GroupHandler) BulkRemoveMembers(c *gin.Context) {
 			continue // Skip invalid IDs
 		}
 
-		err = h.groupService.RemoveGroupMember(groupID, userID.(primitive.ObjectID), memberID)
+		err = h.groupService.RemoveGroupMember(groupID, userID, memberID)
 		if err == nil {
 			successCount++
 		}
@@ -735,12 +1084,3 @@ func (h *GroupHandler) validateMemberID(c *gin.Context) (primitive.ObjectID, err
 	}
 	return memberID, nil
 }
-
-// getCurrentUserID gets the current user ID from context
-func (h *GroupHandler) getCurrentUserID(c *gin.Context) (primitive.ObjectID, bool) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		return primitive.NilObjectID, false
-	}
-	return userID.(primitive.ObjectID), true
-}
@@ -0,0 +1,266 @@
+// internal/handlers/group_library.go
+package handlers
+
+import (
+	"strings"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UploadLibraryFile adds a file to a group's resource library
+func (h *GroupHandler) UploadLibraryFile(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	groupID, err := h.validateGroupID(c)
+	if err != nil {
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		utils.BadRequestResponse(c, "No file provided", err)
+		return
+	}
+	defer file.Close()
+
+	req := models.UploadGroupFileRequest{
+		Folder:      c.PostForm("folder"),
+		Description: c.PostForm("description"),
+		Tags:        parseLibraryTags(c.PostForm("tags")),
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	groupFile, err := h.libraryService.UploadFile(groupID, userID, file, header, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Group not found")
+			return
+		}
+		if strings.Contains(err.Error(), "access denied") || strings.Contains(err.Error(), "permission required") {
+			utils.ForbiddenResponse(c, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "quota exceeded") || strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "size exceeds") || strings.Contains(err.Error(), "unsupported") {
+			utils.BadRequestResponse(c, err.Error(), err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to upload file", err)
+		return
+	}
+
+	utils.CreatedResponse(c, "File uploaded successfully", groupFile.ToGroupFileResponse())
+}
+
+// GetLibraryFiles lists the files in a group's resource library
+func (h *GroupHandler) GetLibraryFiles(c *gin.Context) {
+	groupID, err := h.validateGroupID(c)
+	if err != nil {
+		return
+	}
+
+	viewerID := utils.OptionalUserID(c)
+
+	// An explicit folder parameter, even an empty one, lists a single folder
+	var folder *string
+	if value, ok := c.GetQuery("folder"); ok {
+		folder = &value
+	}
+
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	files, total, err := h.libraryService.GetFiles(groupID, viewerID, folder, c.Query("tag"), c.DefaultQuery("sort", "newest"), params.Limit, params.Offset)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Group not found")
+			return
+		}
+		if strings.Contains(err.Error(), "access denied") {
+			utils.ForbiddenResponse(c, "Access denied")
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			utils.BadRequestResponse(c, err.Error(), err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to retrieve library files", err)
+		return
+	}
+
+	paginationMeta := utils.CreatePaginationMeta(params, total)
+	links := utils.CreatePaginationLinks(c, paginationMeta)
+
+	utils.PaginatedSuccessResponse(c, "Library files retrieved successfully", files, paginationMeta, links)
+}
+
+// GetLibraryFolders lists the folders of a group's resource library
+func (h *GroupHandler) GetLibraryFolders(c *gin.Context) {
+	groupID, err := h.validateGroupID(c)
+	if err != nil {
+		return
+	}
+
+	viewerID := utils.OptionalUserID(c)
+
+	folders, err := h.libraryService.GetFolders(groupID, viewerID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Group not found")
+			return
+		}
+		if strings.Contains(err.Error(), "access denied") {
+			utils.ForbiddenResponse(c, "Access denied")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to retrieve library folders", err)
+		return
+	}
+
+	utils.OkResponse(c, "Library folders retrieved successfully", folders)
+}
+
+// DownloadLibraryFile serves a file from a group's resource library
+func (h *GroupHandler) DownloadLibraryFile(c *gin.Context) {
+	groupID, err := h.validateGroupID(c)
+	if err != nil {
+		return
+	}
+
+	fileID, err := primitive.ObjectIDFromHex(c.Param("file_id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid file ID", err)
+		return
+	}
+
+	viewerID := utils.OptionalUserID(c)
+
+	groupFile, filePath, err := h.libraryService.OpenFile(groupID, fileID, viewerID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
+			utils.NotFoundResponse(c, "File not found or access denied")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get file", err)
+		return
+	}
+
+	// Set headers for download
+	c.Header("Content-Description", "File Transfer")
+	c.Header("Content-Transfer-Encoding", "binary")
+	c.Header("Content-Disposition", "attachment; filename="+groupFile.Name)
+	c.Header("Content-Type", groupFile.MimeType)
+
+	// Serve file
+	c.File(filePath)
+}
+
+// UpdateLibraryFile edits a library file's details or moves it to another folder
+func (h *GroupHandler) UpdateLibraryFile(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	groupID, err := h.validateGroupID(c)
+	if err != nil {
+		return
+	}
+
+	fileID, err := primitive.ObjectIDFromHex(c.Param("file_id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid file ID", err)
+		return
+	}
+
+	var req models.UpdateGroupFileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	groupFile, err := h.libraryService.UpdateFile(groupID, fileID, userID, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "access denied") || strings.Contains(err.Error(), "privileges required") {
+			utils.ForbiddenResponse(c, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			utils.BadRequestResponse(c, err.Error(), err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to update file", err)
+		return
+	}
+
+	utils.OkResponse(c, "File updated successfully", groupFile)
+}
+
+// DeleteLibraryFile removes a file from a group's resource library
+func (h *GroupHandler) DeleteLibraryFile(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	groupID, err := h.validateGroupID(c)
+	if err != nil {
+		return
+	}
+
+	fileID, err := primitive.ObjectIDFromHex(c.Param("file_id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid file ID", err)
+		return
+	}
+
+	err = h.libraryService.DeleteFile(groupID, fileID, userID, utils.ClientIP(c), c.GetHeader("User-Agent"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "access denied") {
+			utils.ForbiddenResponse(c, err.Error())
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to delete file", err)
+		return
+	}
+
+	utils.OkResponse(c, "File deleted successfully", nil)
+}
+
+// parseLibraryTags splits a comma-separated tags form field
+func parseLibraryTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
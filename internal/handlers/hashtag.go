@@ -0,0 +1,159 @@
+// internal/handlers/hashtag.go
+package handlers
+
+import (
+	"strings"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type HashtagHandler struct {
+	hashtagService *services.HashtagService
+	validator      *validator.Validate
+}
+
+func NewHashtagHandler(hashtagService *services.HashtagService) *HashtagHandler {
+	return &HashtagHandler{
+		hashtagService: hashtagService,
+		validator:      validator.New(),
+	}
+}
+
+// GetHashtag returns a hashtag page's details and related hashtags
+func (h *HashtagHandler) GetHashtag(c *gin.Context) {
+	role, _ := utils.LookupRole(c)
+
+	page, err := h.hashtagService.GetHashtagPage(c.Param("tag"), utils.OptionalUserID(c), role.IsAdmin())
+	if err != nil {
+		h.hashtagErrorResponse(c, "Failed to get hashtag", err)
+		return
+	}
+
+	utils.OkResponse(c, "Hashtag retrieved successfully", page)
+}
+
+// GetHashtagPosts returns a hashtag's posts for the "top" tab (page
+// pagination) or the "recent" tab (cursor pagination)
+func (h *HashtagHandler) GetHashtagPosts(c *gin.Context) {
+	viewerID := utils.OptionalUserID(c)
+	role, _ := utils.LookupRole(c)
+	isAdmin := role.IsAdmin()
+	tag := c.Param("tag")
+
+	switch c.DefaultQuery("tab", models.HashtagTabTop) {
+	case models.HashtagTabTop:
+		params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+			return
+		}
+
+		posts, total, err := h.hashtagService.GetTopPosts(tag, viewerID, isAdmin, params.Limit, params.Offset)
+		if err != nil {
+			h.hashtagErrorResponse(c, "Failed to get hashtag posts", err)
+			return
+		}
+
+		paginationMeta := utils.CreatePaginationMeta(params, total)
+		utils.PaginatedSuccessResponse(c, "Hashtag posts retrieved successfully", posts, paginationMeta, nil)
+
+	case models.HashtagTabRecent:
+		params, err := utils.ParseCursorPagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+			return
+		}
+
+		posts, nextCursor, err := h.hashtagService.GetRecentPosts(tag, viewerID, isAdmin, params.Cursor, params.Limit)
+		if err != nil {
+			h.hashtagErrorResponse(c, "Failed to get hashtag posts", err)
+			return
+		}
+
+		utils.OkResponse(c, "Hashtag posts retrieved successfully", utils.CreateCursorPaginatedResult(posts, utils.CursorPaginationMeta{
+			HasNext:     nextCursor != "",
+			HasPrevious: params.Cursor != "",
+			NextCursor:  nextCursor,
+			Count:       len(posts),
+		}))
+
+	default:
+		utils.BadRequestResponse(c, "Invalid tab, must be top or recent", nil)
+	}
+}
+
+// SetHashtagCategory files a hashtag under a category chosen by an admin;
+// automatic recategorization leaves it there
+func (h *HashtagHandler) SetHashtagCategory(c *gin.Context) {
+	hashtagID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid hashtag ID", err)
+		return
+	}
+
+	var req models.UpdateHashtagCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	hashtag, err := h.hashtagService.SetCategory(hashtagID, req.Category)
+	if err != nil {
+		h.hashtagErrorResponse(c, "Failed to update hashtag category", err)
+		return
+	}
+
+	utils.OkResponse(c, "Hashtag category updated successfully", hashtag.ToHashtagResponse())
+}
+
+// ResetHashtagCategory drops an admin's category override and categorizes
+// the hashtag automatically again
+func (h *HashtagHandler) ResetHashtagCategory(c *gin.Context) {
+	hashtagID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid hashtag ID", err)
+		return
+	}
+
+	hashtag, err := h.hashtagService.ResetCategory(hashtagID)
+	if err != nil {
+		h.hashtagErrorResponse(c, "Failed to reset hashtag category", err)
+		return
+	}
+
+	utils.OkResponse(c, "Hashtag category reset successfully", hashtag.ToHashtagResponse())
+}
+
+// RecategorizeHashtags categorizes every hashtag without an admin override
+// again, picking up changes to the category keywords
+func (h *HashtagHandler) RecategorizeHashtags(c *gin.Context) {
+	result, err := h.hashtagService.RecategorizeHashtags()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to recategorize hashtags", err)
+		return
+	}
+
+	utils.OkResponse(c, "Hashtags recategorized successfully", result)
+}
+
+func (h *HashtagHandler) hashtagErrorResponse(c *gin.Context, message string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		utils.NotFoundResponse(c, "Hashtag not found")
+	case strings.Contains(err.Error(), "invalid"):
+		utils.BadRequestResponse(c, err.Error(), err)
+	default:
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+}
@@ -0,0 +1,90 @@
+// internal/handlers/impersonation.go
+package handlers
+
+import (
+	"strings"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ImpersonationHandler struct {
+	impersonationService *services.ImpersonationService
+	validator            *validator.Validate
+}
+
+func NewImpersonationHandler(impersonationService *services.ImpersonationService) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		impersonationService: impersonationService,
+		validator:            validator.New(),
+	}
+}
+
+// StartImpersonation issues a super admin a token to act as a user
+func (h *ImpersonationHandler) StartImpersonation(c *gin.Context) {
+	adminID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID", err)
+		return
+	}
+
+	var req models.StartImpersonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	response, err := h.impersonationService.StartImpersonation(adminID, userID, req, utils.ClientIP(c), c.GetHeader("User-Agent"))
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "yourself"):
+			utils.BadRequestResponse(c, err.Error(), nil)
+		case strings.Contains(err.Error(), "not found"):
+			utils.NotFoundResponse(c, "User not found")
+		case strings.Contains(err.Error(), "suspended"), strings.Contains(err.Error(), "staff"):
+			utils.ForbiddenResponse(c, err.Error())
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to start impersonation", err)
+		}
+		return
+	}
+
+	utils.CreatedResponse(c, "Impersonation started", response)
+}
+
+// EndImpersonation ends the impersonation session the request is made under
+func (h *ImpersonationHandler) EndImpersonation(c *gin.Context) {
+	sessionID, exists := c.Get("impersonation_id")
+	if !exists {
+		utils.BadRequestResponse(c, "Not an impersonation session", nil)
+		return
+	}
+	adminID, _ := c.Get("impersonated_by")
+
+	err := h.impersonationService.EndImpersonation(sessionID.(primitive.ObjectID), adminID.(primitive.ObjectID), utils.ClientIP(c), c.GetHeader("User-Agent"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Impersonation session not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to end impersonation", err)
+		return
+	}
+
+	utils.OkResponse(c, "Impersonation ended", nil)
+}
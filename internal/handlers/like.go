@@ -28,9 +28,8 @@ func NewLikeHandler(likeService *services.LikeService) *LikeHandler {
 
 // CreateLike handles adding a like/reaction to content
 func (h *LikeHandler) CreateLike(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -58,7 +57,7 @@ func (h *LikeHandler) CreateLike(c *gin.Context) {
 		return
 	}
 
-	like, err := h.likeService.CreateLike(userID.(primitive.ObjectID), req)
+	like, err := h.likeService.CreateLike(userID, req)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "not accessible") {
 			utils.NotFoundResponse(c, "Target content not found or not accessible")
@@ -84,9 +83,8 @@ func (h *LikeHandler) CreateLike(c *gin.Context) {
 
 // UpdateLike handles updating the reaction type of an existing like
 func (h *LikeHandler) UpdateLike(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -114,12 +112,16 @@ func (h *LikeHandler) UpdateLike(c *gin.Context) {
 		return
 	}
 
-	like, err := h.likeService.UpdateLike(likeID, userID.(primitive.ObjectID), req)
+	like, err := h.likeService.UpdateLike(likeID, userID, req)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Like not found or access denied")
 			return
 		}
+		if strings.Contains(err.Error(), "invalid reaction") {
+			utils.BadRequestResponse(c, "Invalid reaction type", err)
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to update reaction", err)
 		return
 	}
@@ -132,11 +134,48 @@ func (h *LikeHandler) UpdateLike(c *gin.Context) {
 	})
 }
 
+// SyncReactions handles a batch of reaction changes queued offline
+func (h *LikeHandler) SyncReactions(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ReactionSyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	for _, item := range req.Items {
+		if item.ReactionType != "" && !models.IsValidReactionType(item.ReactionType) {
+			utils.BadRequestResponse(c, "Invalid reaction type", nil)
+			return
+		}
+	}
+
+	response, err := h.likeService.SyncReactions(userID, req.Items)
+	if err != nil {
+		if strings.Contains(err.Error(), "too many items") {
+			utils.BadRequestResponse(c, err.Error(), err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to sync reactions", err)
+		return
+	}
+
+	utils.OkResponse(c, "Reactions synced successfully", response)
+}
+
 // DeleteLike handles removing a like/reaction
 func (h *LikeHandler) DeleteLike(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -154,7 +193,7 @@ func (h *LikeHandler) DeleteLike(c *gin.Context) {
 		return
 	}
 
-	err = h.likeService.DeleteLike(targetID, userID.(primitive.ObjectID), targetType)
+	err = h.likeService.DeleteLike(targetID, userID, targetType)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "Like not found")
@@ -188,7 +227,11 @@ func (h *LikeHandler) GetLikes(c *gin.Context) {
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// Optional reaction type filter
 	var reactionType *models.ReactionType
@@ -238,11 +281,7 @@ func (h *LikeHandler) GetReactionSummary(c *gin.Context) {
 	}
 
 	// Get current user ID if authenticated
-	var currentUserID *primitive.ObjectID
-	if userID, exists := c.Get("user_id"); exists {
-		uid := userID.(primitive.ObjectID)
-		currentUserID = &uid
-	}
+	currentUserID := utils.OptionalUserID(c)
 
 	summary, err := h.likeService.GetReactionSummary(targetID, targetType, currentUserID)
 	if err != nil {
@@ -292,7 +331,11 @@ func (h *LikeHandler) GetUserLikes(c *gin.Context) {
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// Optional target type filter
 	var targetType *string
@@ -326,9 +369,8 @@ func (h *LikeHandler) GetUserLikes(c *gin.Context) {
 
 // CheckUserReaction checks if current user has reacted to content
 func (h *LikeHandler) CheckUserReaction(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -346,7 +388,7 @@ func (h *LikeHandler) CheckUserReaction(c *gin.Context) {
 		return
 	}
 
-	reactionType, err := h.likeService.CheckUserReaction(targetID, userID.(primitive.ObjectID), targetType)
+	reactionType, err := h.likeService.CheckUserReaction(targetID, userID, targetType)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to check user reaction", err)
 		return
@@ -395,11 +437,10 @@ func (h *LikeHandler) GetTrendingReactions(c *gin.Context) {
 	}
 
 	// Limit parameter
-	limit := 10
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if l, err := utils.StringToInt(limitStr); err == nil && l > 0 && l <= 50 {
-			limit = l
-		}
+	limit, err := utils.ParseLimit(c, 10, 50)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
 	}
 
 	reactions, err := h.likeService.GetTrendingReactions(targetType, timeRange, limit)
@@ -442,14 +483,17 @@ func (h *LikeHandler) GetReactionTypes(c *gin.Context) {
 
 // GetMyReactions gets current user's recent reactions
 func (h *LikeHandler) GetMyReactions(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// Optional target type filter
 	var targetType *string
@@ -460,7 +504,7 @@ func (h *LikeHandler) GetMyReactions(c *gin.Context) {
 		}
 	}
 
-	likes, err := h.likeService.GetUserLikes(userID.(primitive.ObjectID), targetType, params.Limit, params.Offset)
+	likes, err := h.likeService.GetUserLikes(userID, targetType, params.Limit, params.Offset)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get your reactions", err)
 		return
@@ -482,9 +526,8 @@ func (h *LikeHandler) GetMyReactions(c *gin.Context) {
 
 // BulkReaction handles bulk reaction operations (admin feature)
 func (h *LikeHandler) BulkReaction(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -535,9 +578,9 @@ func (h *LikeHandler) BulkReaction(c *gin.Context) {
 				TargetType:   req.TargetType,
 				ReactionType: req.ReactionType,
 			}
-			_, err = h.likeService.CreateLike(userID.(primitive.ObjectID), createReq)
+			_, err = h.likeService.CreateLike(userID, createReq)
 		} else {
-			err = h.likeService.DeleteLike(targetID, userID.(primitive.ObjectID), req.TargetType)
+			err = h.likeService.DeleteLike(targetID, userID, req.TargetType)
 		}
 
 		if err != nil {
@@ -0,0 +1,122 @@
+// internal/handlers/limits.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type LimitsHandler struct {
+	limitsService *services.LimitsService
+	validator     *validator.Validate
+}
+
+func NewLimitsHandler(limitsService *services.LimitsService) *LimitsHandler {
+	return &LimitsHandler{
+		limitsService: limitsService,
+		validator:     validator.New(),
+	}
+}
+
+// GetMyLimits returns the caller's tier limits and how much of each daily quota they have used
+func (h *LimitsHandler) GetMyLimits(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	limits, err := h.limitsService.GetUserLimits(userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "User not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get limits", err)
+		return
+	}
+
+	utils.OkResponse(c, "Limits retrieved successfully", limits)
+}
+
+// Admin handlers
+
+// GetTierLimits lists the limits of every tier
+func (h *LimitsHandler) GetTierLimits(c *gin.Context) {
+	limits, err := h.limitsService.ListTierLimits()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get tier limits", err)
+		return
+	}
+
+	utils.OkResponse(c, "Tier limits retrieved successfully", limits)
+}
+
+// UpdateTierLimits changes a tier's limits
+func (h *LimitsHandler) UpdateTierLimits(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	tier := models.LimitTier(c.Param("tier"))
+	if !models.IsValidLimitTier(tier) {
+		utils.BadRequestResponse(c, "Invalid limit tier", nil)
+		return
+	}
+
+	var req models.UpdateTierLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	limits, err := h.limitsService.UpdateTierLimits(tier, req, userID, utils.ClientIP(c), c.GetHeader("User-Agent"))
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to update tier limits", err)
+		return
+	}
+
+	utils.OkResponse(c, "Tier limits updated successfully", limits)
+}
+
+// respondLimitExceeded sends the structured response for a tier limit error,
+// reporting whether err was one. Daily quotas answer 429 since they clear as
+// the window rolls; size ceilings answer 400.
+func respondLimitExceeded(c *gin.Context, err error) bool {
+	var limitErr *models.LimitExceededError
+	if !errors.As(err, &limitErr) {
+		return false
+	}
+
+	status := http.StatusBadRequest
+	if limitErr.IsDaily() {
+		status = http.StatusTooManyRequests
+	}
+	utils.ErrorResponseWithDetails(c, status, limitErr.Error(), utils.ErrorCodeLimitExceeded, limitErr)
+	return true
+}
+
+// respondBoundExceeded sends the 400 for an ID list longer than the document
+// can hold, reporting whether err was one
+func respondBoundExceeded(c *gin.Context, err error) bool {
+	var boundErr *models.BoundExceededError
+	if !errors.As(err, &boundErr) {
+		return false
+	}
+
+	utils.ErrorResponseWithDetails(c, http.StatusBadRequest, boundErr.Error(), utils.ErrorCodeLimitExceeded, boundErr)
+	return true
+}
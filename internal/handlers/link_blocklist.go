@@ -0,0 +1,158 @@
+// internal/handlers/link_blocklist.go
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type LinkBlocklistHandler struct {
+	linkBlocklistService *services.LinkBlocklistService
+	validator            *validator.Validate
+}
+
+func NewLinkBlocklistHandler(linkBlocklistService *services.LinkBlocklistService) *LinkBlocklistHandler {
+	return &LinkBlocklistHandler{
+		linkBlocklistService: linkBlocklistService,
+		validator:            validator.New(),
+	}
+}
+
+// GetBlockedDomains lists the link blocklist
+func (h *LinkBlocklistHandler) GetBlockedDomains(c *gin.Context) {
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	domains, total, err := h.linkBlocklistService.ListBlockedDomains(params.Limit, params.Offset)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get blocked domains", err)
+		return
+	}
+
+	paginationMeta := utils.CreatePaginationMeta(params, total)
+	utils.PaginatedSuccessResponse(c, "Blocked domains retrieved successfully", domains, paginationMeta, nil)
+}
+
+// AddBlockedDomain puts a domain on the link blocklist
+func (h *LinkBlocklistHandler) AddBlockedDomain(c *gin.Context) {
+	adminID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateBlockedDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	domain, err := h.linkBlocklistService.AddBlockedDomain(adminID, req)
+	if err != nil {
+		h.blocklistErrorResponse(c, "Failed to add blocked domain", err)
+		return
+	}
+
+	utils.CreatedResponse(c, "Domain added to the blocklist", domain)
+}
+
+// UpdateBlockedDomain changes whether a blocklisted domain rejects or hides content
+func (h *LinkBlocklistHandler) UpdateBlockedDomain(c *gin.Context) {
+	domainID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid blocked domain ID", err)
+		return
+	}
+
+	var req models.UpdateBlockedDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	domain, err := h.linkBlocklistService.UpdateBlockedDomain(domainID, req)
+	if err != nil {
+		h.blocklistErrorResponse(c, "Failed to update blocked domain", err)
+		return
+	}
+
+	utils.OkResponse(c, "Blocked domain updated successfully", domain)
+}
+
+// RemoveBlockedDomain takes a domain off the link blocklist
+func (h *LinkBlocklistHandler) RemoveBlockedDomain(c *gin.Context) {
+	domainID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid blocked domain ID", err)
+		return
+	}
+
+	if err := h.linkBlocklistService.RemoveBlockedDomain(domainID); err != nil {
+		h.blocklistErrorResponse(c, "Failed to remove blocked domain", err)
+		return
+	}
+
+	utils.OkResponse(c, "Domain removed from the blocklist", nil)
+}
+
+// GetBlockLog lists content the blocklist refused or hid, optionally for one
+// domain (?domain=)
+func (h *LinkBlocklistHandler) GetBlockLog(c *gin.Context) {
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	entries, total, err := h.linkBlocklistService.GetBlockLog(c.Query("domain"), params.Limit, params.Offset)
+	if err != nil {
+		h.blocklistErrorResponse(c, "Failed to get link block log", err)
+		return
+	}
+
+	paginationMeta := utils.CreatePaginationMeta(params, total)
+	utils.PaginatedSuccessResponse(c, "Link block log retrieved successfully", entries, paginationMeta, nil)
+}
+
+// respondBlockedLink sends the response for content refused by the link
+// blocklist, reporting whether err was that refusal
+func respondBlockedLink(c *gin.Context, err error) bool {
+	if !strings.Contains(err.Error(), "blocked link") {
+		return false
+	}
+	utils.ErrorResponseWithCode(c, http.StatusUnprocessableEntity, "Content contains a link to a blocked domain", utils.ErrorCodeBlockedLink, nil)
+	return true
+}
+
+func (h *LinkBlocklistHandler) blocklistErrorResponse(c *gin.Context, message string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		utils.NotFoundResponse(c, "Blocked domain not found")
+	case strings.Contains(err.Error(), "already blocklisted"):
+		utils.ConflictResponse(c, "Domain is already on the blocklist", err)
+	case strings.Contains(err.Error(), "invalid"):
+		utils.BadRequestResponse(c, err.Error(), err)
+	default:
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+}
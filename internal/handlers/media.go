@@ -2,6 +2,7 @@
 package handlers
 
 import (
+	"net/http"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -29,9 +30,8 @@ func NewMediaHandler(mediaService *services.MediaService) *MediaHandler {
 
 // UploadMedia handles file upload
 func (h *MediaHandler) UploadMedia(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -74,9 +74,10 @@ func (h *MediaHandler) UploadMedia(c *gin.Context) {
 		}
 	}
 
-	result, err := h.mediaService.UploadMedia(userID.(primitive.ObjectID), file, header, req)
+	result, err := h.mediaService.UploadMedia(userID, file, header, req)
 	if err != nil {
-		if strings.Contains(err.Error(), "size exceeds") || strings.Contains(err.Error(), "unsupported") {
+		if strings.Contains(err.Error(), "size exceeds") || strings.Contains(err.Error(), "unsupported") ||
+			strings.Contains(err.Error(), "alt text") {
 			utils.BadRequestResponse(c, err.Error(), err)
 			return
 		}
@@ -101,11 +102,7 @@ func (h *MediaHandler) GetMedia(c *gin.Context) {
 	}
 
 	// Get current user ID if authenticated
-	var currentUserID *primitive.ObjectID
-	if userID, exists := c.Get("user_id"); exists {
-		uid := userID.(primitive.ObjectID)
-		currentUserID = &uid
-	}
+	currentUserID := utils.OptionalUserID(c)
 
 	media, err := h.mediaService.GetMediaByID(mediaID, currentUserID)
 	if err != nil {
@@ -130,14 +127,14 @@ func (h *MediaHandler) GetUserMedia(c *gin.Context) {
 	}
 
 	// Get current user ID if authenticated
-	var currentUserID *primitive.ObjectID
-	if uid, exists := c.Get("user_id"); exists {
-		id := uid.(primitive.ObjectID)
-		currentUserID = &id
-	}
+	currentUserID := utils.OptionalUserID(c)
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// Get media type filter
 	mediaType := c.Query("type")
@@ -162,9 +159,8 @@ func (h *MediaHandler) GetUserMedia(c *gin.Context) {
 
 // UpdateMedia updates media information
 func (h *MediaHandler) UpdateMedia(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -181,24 +177,22 @@ func (h *MediaHandler) UpdateMedia(c *gin.Context) {
 		return
 	}
 
-	// Validate alt text length if provided
-	if req.AltText != nil && len(*req.AltText) > utils.MaxAltTextLength {
-		utils.BadRequestResponse(c, "Alt text exceeds maximum length", nil)
-		return
-	}
-
 	// Validate description length if provided
 	if req.Description != nil && len(*req.Description) > utils.MaxMediaDescriptionLength {
 		utils.BadRequestResponse(c, "Description exceeds maximum length", nil)
 		return
 	}
 
-	media, err := h.mediaService.UpdateMedia(mediaID, userID.(primitive.ObjectID), req)
+	media, err := h.mediaService.UpdateMedia(mediaID, userID, req)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Media not found or access denied")
 			return
 		}
+		if strings.Contains(err.Error(), "alt text") {
+			utils.BadRequestResponse(c, err.Error(), nil)
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to update media", err)
 		return
 	}
@@ -206,11 +200,54 @@ func (h *MediaHandler) UpdateMedia(c *gin.Context) {
 	utils.OkResponse(c, "Media updated successfully", media.ToMediaResponse())
 }
 
+// GetMediaMissingAltText lists the current user's images that have no alt text
+func (h *MediaHandler) GetMediaMissingAltText(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	media, total, err := h.mediaService.GetMediaMissingAltText(userID, params.Limit, params.Offset)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get media missing alt text", err)
+		return
+	}
+
+	mediaResponses := make([]models.MediaResponse, len(media))
+	for i, m := range media {
+		mediaResponses[i] = m.ToMediaResponse()
+	}
+
+	utils.PaginatedSuccessResponse(c, "Media missing alt text retrieved successfully", mediaResponses, utils.CreatePaginationMeta(params, total), nil)
+}
+
+// GetDuplicateMedia lists the user's images that are exact copies of each
+// other or look alike
+func (h *MediaHandler) GetDuplicateMedia(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	groups, err := h.mediaService.FindDuplicateMedia(userID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to find duplicate media", err)
+		return
+	}
+
+	utils.OkResponse(c, "Duplicate media retrieved successfully", groups)
+}
+
 // DeleteMedia deletes media
 func (h *MediaHandler) DeleteMedia(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -221,7 +258,7 @@ func (h *MediaHandler) DeleteMedia(c *gin.Context) {
 		return
 	}
 
-	err = h.mediaService.DeleteMedia(mediaID, userID.(primitive.ObjectID))
+	err = h.mediaService.DeleteMedia(mediaID, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Media not found or access denied")
@@ -248,14 +285,14 @@ func (h *MediaHandler) SearchMedia(c *gin.Context) {
 	}
 
 	// Get current user ID if authenticated
-	var currentUserID *primitive.ObjectID
-	if userID, exists := c.Get("user_id"); exists {
-		uid := userID.(primitive.ObjectID)
-		currentUserID = &uid
-	}
+	currentUserID := utils.OptionalUserID(c)
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// Get media type filter
 	mediaType := c.Query("type")
@@ -290,10 +327,7 @@ func (h *MediaHandler) GetMediaStats(c *gin.Context) {
 
 	// If no user_id provided, use current user's ID if authenticated
 	if userID == nil {
-		if uid, exists := c.Get("user_id"); exists {
-			id := uid.(primitive.ObjectID)
-			userID = &id
-		}
+		userID = utils.OptionalUserID(c)
 	}
 
 	stats, err := h.mediaService.GetMediaStats(userID)
@@ -305,6 +339,17 @@ func (h *MediaHandler) GetMediaStats(c *gin.Context) {
 	utils.OkResponse(c, "Media statistics retrieved successfully", stats)
 }
 
+// GetStorageTierStats returns storage used per tier and the estimated cost saving (admin only)
+func (h *MediaHandler) GetStorageTierStats(c *gin.Context) {
+	stats, err := h.mediaService.GetStorageTierStats()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get storage tier statistics", err)
+		return
+	}
+
+	utils.OkResponse(c, "Storage tier statistics retrieved successfully", stats)
+}
+
 // DownloadMedia handles media download
 func (h *MediaHandler) DownloadMedia(c *gin.Context) {
 	mediaIDStr := c.Param("id")
@@ -315,11 +360,7 @@ func (h *MediaHandler) DownloadMedia(c *gin.Context) {
 	}
 
 	// Get current user ID if authenticated
-	var currentUserID *primitive.ObjectID
-	if userID, exists := c.Get("user_id"); exists {
-		uid := userID.(primitive.ObjectID)
-		currentUserID = &uid
-	}
+	currentUserID := utils.OptionalUserID(c)
 
 	media, err := h.mediaService.GetMediaByID(mediaID, currentUserID)
 	if err != nil {
@@ -333,6 +374,7 @@ func (h *MediaHandler) DownloadMedia(c *gin.Context) {
 
 	// Increment download count
 	go h.mediaService.IncrementDownloadCount(mediaID)
+	h.mediaService.RecordAccess(mediaID)
 
 	// Set headers for download
 	c.Header("Content-Description", "File Transfer")
@@ -340,8 +382,20 @@ func (h *MediaHandler) DownloadMedia(c *gin.Context) {
 	c.Header("Content-Disposition", "attachment; filename="+media.OriginalName)
 	c.Header("Content-Type", media.MimeType)
 
-	// Serve file
-	c.File(media.FilePath)
+	// Serve hot files from disk; cold ones stream from the cold tier
+	if path, ok := h.mediaService.HotOriginalPath(media); ok {
+		c.File(path)
+		return
+	}
+
+	reader, err := h.mediaService.OpenOriginal(media)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to read media file", err)
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, media.FileSize, media.MimeType, reader, nil)
 }
 
 // GetMediaVariant retrieves a specific variant of media (thumbnail, etc.)
@@ -359,11 +413,7 @@ func (h *MediaHandler) GetMediaVariant(c *gin.Context) {
 	}
 
 	// Get current user ID if authenticated
-	var currentUserID *primitive.ObjectID
-	if userID, exists := c.Get("user_id"); exists {
-		uid := userID.(primitive.ObjectID)
-		currentUserID = &uid
-	}
+	currentUserID := utils.OptionalUserID(c)
 
 	media, err := h.mediaService.GetMediaByID(mediaID, currentUserID)
 	if err != nil {
@@ -375,20 +425,26 @@ func (h *MediaHandler) GetMediaVariant(c *gin.Context) {
 		return
 	}
 
-	// Get variant URL
-	url := h.mediaService.GetMediaURL(media, variant)
+	// Pick the best format the client accepts, falling back to the original
+	url, format := media.URL, media.FileExtension
+	if chosen := h.mediaService.NegotiateVariant(media, variant, c.GetHeader("Accept")); chosen != nil {
+		url, format = chosen.URL, chosen.Format
+	} else {
+		h.mediaService.RecordAccess(mediaID)
+	}
+	c.Header("Vary", "Accept")
 
 	utils.OkResponse(c, "Media variant URL retrieved successfully", gin.H{
 		"url":     url,
 		"variant": variant,
+		"format":  format,
 	})
 }
 
 // BulkUploadMedia handles multiple file uploads
 func (h *MediaHandler) BulkUploadMedia(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -440,7 +496,7 @@ func (h *MediaHandler) BulkUploadMedia(c *gin.Context) {
 			IsPublic: isPublic,
 		}
 
-		result, err := h.mediaService.UploadMedia(userID.(primitive.ObjectID), file, header, req)
+		result, err := h.mediaService.UploadMedia(userID, file, header, req)
 		file.Close()
 
 		if err != nil {
@@ -483,11 +539,7 @@ func (h *MediaHandler) GetMediaMetadata(c *gin.Context) {
 	}
 
 	// Get current user ID if authenticated
-	var currentUserID *primitive.ObjectID
-	if userID, exists := c.Get("user_id"); exists {
-		uid := userID.(primitive.ObjectID)
-		currentUserID = &uid
-	}
+	currentUserID := utils.OptionalUserID(c)
 
 	media, err := h.mediaService.GetMediaByID(mediaID, currentUserID)
 	if err != nil {
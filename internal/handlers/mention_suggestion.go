@@ -0,0 +1,62 @@
+// internal/handlers/mention_suggestion.go
+package handlers
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"social-media-api/internal/services"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxMentionQueryLength matches the longest allowed username
+const maxMentionQueryLength = 50
+
+type MentionSuggestionHandler struct {
+	mentionSuggestionService *services.MentionSuggestionService
+}
+
+func NewMentionSuggestionHandler(mentionSuggestionService *services.MentionSuggestionService) *MentionSuggestionHandler {
+	return &MentionSuggestionHandler{
+		mentionSuggestionService: mentionSuggestionService,
+	}
+}
+
+// GetMentionSuggestions suggests users to @-mention in a comment on the post
+// (?q=), favoring the post's author and commenters and the caller's follows
+func (h *MentionSuggestionHandler) GetMentionSuggestions(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	postID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid post ID", err)
+		return
+	}
+
+	query := strings.TrimPrefix(strings.TrimSpace(c.Query("q")), "@")
+	if length := utf8.RuneCountInString(query); length < 1 || length > maxMentionQueryLength {
+		utils.BadRequestResponse(c, "Query must be between 1 and 50 characters", nil)
+		return
+	}
+
+	suggestions, err := h.mentionSuggestionService.SuggestMentions(postID, userID, query)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			utils.NotFoundResponse(c, "Post not found")
+		case strings.Contains(err.Error(), "invalid"):
+			utils.BadRequestResponse(c, err.Error(), err)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to get mention suggestions", err)
+		}
+		return
+	}
+
+	utils.OkResponse(c, "Mention suggestions retrieved successfully", suggestions)
+}
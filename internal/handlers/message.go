@@ -2,6 +2,9 @@
 package handlers
 
 import (
+	"errors"
+	"log"
+	"net/http"
 	"strings"
 	"time"
 
@@ -33,9 +36,8 @@ func NewMessageHandler(messageService *services.MessageService, conversationServ
 
 // CreateConversation creates a new conversation
 func (h *MessageHandler) CreateConversation(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -57,7 +59,7 @@ func (h *MessageHandler) CreateConversation(c *gin.Context) {
 	}
 
 	// Add current user to participants if not already included
-	currentUserIDStr := userID.(primitive.ObjectID).Hex()
+	currentUserIDStr := userID.Hex()
 	found := false
 	for _, participantID := range req.ParticipantIDs {
 		if participantID == currentUserIDStr {
@@ -69,8 +71,14 @@ func (h *MessageHandler) CreateConversation(c *gin.Context) {
 		req.ParticipantIDs = append(req.ParticipantIDs, currentUserIDStr)
 	}
 
-	conversation, err := h.conversationService.CreateConversation(userID.(primitive.ObjectID), req)
+	conversation, err := h.conversationService.CreateConversation(userID, req)
 	if err != nil {
+		if respondBoundExceeded(c, err) {
+			return
+		}
+		if respondAccountTooNew(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "already exists") {
 			utils.ConflictResponse(c, "Conversation already exists", err)
 			return
@@ -84,16 +92,25 @@ func (h *MessageHandler) CreateConversation(c *gin.Context) {
 
 // GetConversations retrieves user's conversations
 func (h *MessageHandler) GetConversations(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
-	conversations, err := h.conversationService.GetUserConversations(userID.(primitive.ObjectID), params.Limit, params.Offset)
+	sortBy := c.DefaultQuery("sort", models.InboxSortRecent)
+	if sortBy != models.InboxSortRecent && sortBy != models.InboxSortMostActive {
+		utils.BadRequestResponse(c, "Invalid sort, must be recent or most_active", nil)
+		return
+	}
+
+	conversations, err := h.conversationService.GetUserConversations(userID, sortBy, params.Limit, params.Offset)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get conversations", err)
 		return
@@ -108,9 +125,8 @@ func (h *MessageHandler) GetConversations(c *gin.Context) {
 
 // GetConversation retrieves a specific conversation
 func (h *MessageHandler) GetConversation(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -121,7 +137,7 @@ func (h *MessageHandler) GetConversation(c *gin.Context) {
 		return
 	}
 
-	conversation, err := h.conversationService.GetConversationByID(conversationID, userID.(primitive.ObjectID))
+	conversation, err := h.conversationService.GetConversationByID(conversationID, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Conversation not found or access denied")
@@ -137,9 +153,8 @@ func (h *MessageHandler) GetConversation(c *gin.Context) {
 
 // SendMessage sends a message in a conversation
 func (h *MessageHandler) SendMessage(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -175,9 +190,22 @@ func (h *MessageHandler) SendMessage(c *gin.Context) {
 
 	// Set conversation ID from URL parameter
 	req.ConversationID = conversationIDStr
+	req.DelegateID = actingDelegate(c)
 
-	message, err := h.messageService.SendMessage(userID.(primitive.ObjectID), conversationID, req)
+	message, err := h.messageService.SendMessage(userID, conversationID, req)
 	if err != nil {
+		if respondBoundExceeded(c, err) {
+			return
+		}
+		if respondBlockedLink(c, err) {
+			return
+		}
+		if respondMediaRejected(c, err) {
+			return
+		}
+		if respondLimitExceeded(c, err) || respondAccountTooNew(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Conversation not found or access denied")
 			return
@@ -194,9 +222,8 @@ func (h *MessageHandler) SendMessage(c *gin.Context) {
 
 // GetMessages retrieves messages from a conversation
 func (h *MessageHandler) GetMessages(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -208,9 +235,13 @@ func (h *MessageHandler) GetMessages(c *gin.Context) {
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
-	messages, err := h.messageService.GetConversationMessages(conversationID, userID.(primitive.ObjectID), params.Limit, params.Offset)
+	messages, err := h.messageService.GetConversationMessages(conversationID, userID, params.Limit, params.Offset)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Conversation not found or access denied")
@@ -234,9 +265,8 @@ func (h *MessageHandler) GetMessages(c *gin.Context) {
 
 // UpdateMessage updates a message
 func (h *MessageHandler) UpdateMessage(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -259,8 +289,20 @@ func (h *MessageHandler) UpdateMessage(c *gin.Context) {
 		return
 	}
 
-	message, err := h.messageService.UpdateMessage(messageID, userID.(primitive.ObjectID), req)
+	message, err := h.messageService.UpdateMessage(messageID, userID, req)
 	if err != nil {
+		if respondBoundExceeded(c, err) {
+			return
+		}
+		if respondBlockedLink(c, err) {
+			return
+		}
+		if respondMediaRejected(c, err) {
+			return
+		}
+		if respondLimitExceeded(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Message not found or access denied")
 			return
@@ -281,9 +323,8 @@ func (h *MessageHandler) UpdateMessage(c *gin.Context) {
 
 // DeleteMessage deletes a message
 func (h *MessageHandler) DeleteMessage(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -294,7 +335,7 @@ func (h *MessageHandler) DeleteMessage(c *gin.Context) {
 		return
 	}
 
-	message, err := h.messageService.DeleteMessage(messageID, userID.(primitive.ObjectID))
+	message, err := h.messageService.DeleteMessage(messageID, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Message not found or access denied")
@@ -312,9 +353,8 @@ func (h *MessageHandler) DeleteMessage(c *gin.Context) {
 
 // MarkMessagesAsRead marks messages as read
 func (h *MessageHandler) MarkMessagesAsRead(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -340,7 +380,7 @@ func (h *MessageHandler) MarkMessagesAsRead(c *gin.Context) {
 		return
 	}
 
-	err = h.messageService.MarkMessagesAsRead(conversationID, userID.(primitive.ObjectID), lastMessageID)
+	recorded, err := h.messageService.MarkMessagesAsRead(conversationID, userID, lastMessageID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Conversation not found or access denied")
@@ -350,8 +390,11 @@ func (h *MessageHandler) MarkMessagesAsRead(c *gin.Context) {
 		return
 	}
 
-	// Broadcast read receipt via WebSocket
-	go h.broadcastReadReceipt(conversationID, userID.(primitive.ObjectID), lastMessageID)
+	// Broadcast read receipt via WebSocket, unless it's withheld from the
+	// senders of a message request
+	if recorded {
+		go h.broadcastReadReceipt(conversationID, userID, lastMessageID)
+	}
 
 	utils.OkResponse(c, "Messages marked as read successfully", gin.H{
 		"conversation_id": conversationIDStr,
@@ -361,9 +404,8 @@ func (h *MessageHandler) MarkMessagesAsRead(c *gin.Context) {
 
 // ReactToMessage adds or removes a reaction to a message
 func (h *MessageHandler) ReactToMessage(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -385,18 +427,22 @@ func (h *MessageHandler) ReactToMessage(c *gin.Context) {
 		return
 	}
 
-	err = h.messageService.ReactToMessage(messageID, userID.(primitive.ObjectID), req.ReactionType, req.Action)
+	err = h.messageService.ReactToMessage(messageID, userID, req.ReactionType, req.Action)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Message not found or access denied")
 			return
 		}
+		if strings.Contains(err.Error(), "invalid reaction") {
+			utils.BadRequestResponse(c, "Invalid reaction type", err)
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to react to message", err)
 		return
 	}
 
 	// Broadcast reaction via WebSocket
-	go h.broadcastReaction(messageID, userID.(primitive.ObjectID), req.ReactionType, req.Action)
+	go h.broadcastReaction(messageID, userID, req.ReactionType, req.Action)
 
 	var message string
 	if req.Action == "add" {
@@ -412,11 +458,77 @@ func (h *MessageHandler) ReactToMessage(c *gin.Context) {
 	})
 }
 
+// ForwardMessage forwards a message into one or more conversations
+func (h *MessageHandler) ForwardMessage(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	messageIDStr := c.Param("id")
+	messageID, err := primitive.ObjectIDFromHex(messageIDStr)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid message ID format", err)
+		return
+	}
+
+	var req models.ForwardMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	var targetIDs []primitive.ObjectID
+	for _, idStr := range req.TargetConversationIDs {
+		targetID, err := primitive.ObjectIDFromHex(idStr)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid conversation ID format", err)
+			return
+		}
+		targetIDs = append(targetIDs, targetID)
+	}
+
+	messages, err := h.messageService.ForwardMessage(userID, messageID, targetIDs, actingDelegate(c))
+	if err != nil {
+		if respondBlockedLink(c, err) {
+			return
+		}
+		if respondMediaRejected(c, err) {
+			return
+		}
+		if respondLimitExceeded(c, err) || respondAccountTooNew(c, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
+			utils.NotFoundResponse(c, "Message or conversation not found or access denied")
+			return
+		}
+		if strings.Contains(err.Error(), "cannot") || strings.Contains(err.Error(), "required") || strings.Contains(err.Error(), "expired") {
+			utils.BadRequestResponse(c, err.Error(), err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to forward message", err)
+		return
+	}
+
+	var responses []models.MessageResponse
+	for i := range messages {
+		go h.broadcastMessage(&messages[i])
+		responses = append(responses, messages[i].ToMessageResponse())
+	}
+
+	utils.CreatedResponse(c, "Message forwarded successfully", responses)
+}
+
 // SearchMessages searches messages in conversations
 func (h *MessageHandler) SearchMessages(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -432,7 +544,11 @@ func (h *MessageHandler) SearchMessages(c *gin.Context) {
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// Get conversation ID filter if provided
 	var conversationID *primitive.ObjectID
@@ -442,7 +558,7 @@ func (h *MessageHandler) SearchMessages(c *gin.Context) {
 		}
 	}
 
-	messages, err := h.messageService.SearchMessages(userID.(primitive.ObjectID), query, conversationID, params.Limit, params.Offset)
+	messages, err := h.messageService.SearchMessages(userID, query, conversationID, params.Limit, params.Offset)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to search messages", err)
 		return
@@ -468,9 +584,8 @@ func (h *MessageHandler) SearchMessages(c *gin.Context) {
 
 // LeaveConversation allows user to leave a group conversation
 func (h *MessageHandler) LeaveConversation(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -481,7 +596,7 @@ func (h *MessageHandler) LeaveConversation(c *gin.Context) {
 		return
 	}
 
-	err = h.conversationService.LeaveConversation(conversationID, userID.(primitive.ObjectID))
+	err = h.conversationService.LeaveConversation(conversationID, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Conversation not found or access denied")
@@ -500,9 +615,8 @@ func (h *MessageHandler) LeaveConversation(c *gin.Context) {
 
 // AddParticipants adds participants to a group conversation
 func (h *MessageHandler) AddParticipants(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -532,8 +646,11 @@ func (h *MessageHandler) AddParticipants(c *gin.Context) {
 		ParticipantIDs: req.ParticipantIDs,
 	}
 
-	err = h.conversationService.AddParticipants(conversationID, userID.(primitive.ObjectID), addParticipantsReq)
+	err = h.conversationService.AddParticipants(conversationID, userID, addParticipantsReq)
 	if err != nil {
+		if respondBoundExceeded(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Conversation not found or access denied")
 			return
@@ -549,9 +666,8 @@ func (h *MessageHandler) AddParticipants(c *gin.Context) {
 
 // RemoveParticipant removes a participant from a group conversation
 func (h *MessageHandler) RemoveParticipant(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -569,7 +685,7 @@ func (h *MessageHandler) RemoveParticipant(c *gin.Context) {
 		return
 	}
 
-	err = h.conversationService.RemoveParticipant(conversationID, userID.(primitive.ObjectID), participantID)
+	err = h.conversationService.RemoveParticipant(conversationID, userID, participantID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Conversation not found or access denied")
@@ -584,9 +700,8 @@ func (h *MessageHandler) RemoveParticipant(c *gin.Context) {
 
 // UpdateConversation updates conversation details
 func (h *MessageHandler) UpdateConversation(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -603,7 +718,7 @@ func (h *MessageHandler) UpdateConversation(c *gin.Context) {
 		return
 	}
 
-	conversation, err := h.conversationService.UpdateConversation(conversationID, userID.(primitive.ObjectID), req)
+	conversation, err := h.conversationService.UpdateConversation(conversationID, userID, req)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Conversation not found or access denied")
@@ -619,9 +734,8 @@ func (h *MessageHandler) UpdateConversation(c *gin.Context) {
 
 // GetMessageStats gets message statistics
 func (h *MessageHandler) GetMessageStats(c *gin.Context) {
-	_, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	_, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -650,6 +764,24 @@ func (h *MessageHandler) GetMessageStats(c *gin.Context) {
 	utils.OkResponse(c, "Message statistics retrieved successfully", stats)
 }
 
+// ConnectWebSocket upgrades the request to the chat WebSocket for the current user
+func (h *MessageHandler) ConnectWebSocket(c *gin.Context) {
+	if h.hub == nil {
+		utils.ErrorResponse(c, http.StatusServiceUnavailable, "Real-time messaging is not available", nil)
+		return
+	}
+
+	currentUser, ok := utils.CurrentUser(c)
+	if !ok {
+		return
+	}
+
+	// The upgrader writes its own error response when the handshake fails
+	if err := websocket.ServeWS(h.hub, c.Writer, c.Request, currentUser.ID, currentUser.Username, utils.ClientIP(c), actingDelegate(c)); err != nil {
+		log.Printf("WebSocket upgrade failed for user %s: %v", currentUser.ID.Hex(), err)
+	}
+}
+
 // WebSocket broadcasting helper methods
 
 func (h *MessageHandler) broadcastMessage(message *models.Message) {
@@ -657,6 +789,12 @@ func (h *MessageHandler) broadcastMessage(message *models.Message) {
 		return
 	}
 
+	conversation, err := h.conversationService.GetParticipantConversation(message.ConversationID, message.SenderID)
+	if err != nil {
+		log.Printf("Failed to load conversation %s for broadcast: %v", message.ConversationID.Hex(), err)
+		return
+	}
+
 	wsMessage := websocket.WebSocketMessage{
 		Type:   "message",
 		Action: "new",
@@ -670,6 +808,8 @@ func (h *MessageHandler) broadcastMessage(message *models.Message) {
 			"status":          message.Status,
 			"sent_at":         message.SentAt,
 			"created_at":      message.CreatedAt,
+			"is_forwarded":    message.IsForwarded,
+			"forwarded_from":  message.ForwardedFrom,
 			"reply_to_message_id": func() string {
 				if message.ReplyToMessageID != nil {
 					return message.ReplyToMessageID.Hex()
@@ -679,9 +819,8 @@ func (h *MessageHandler) broadcastMessage(message *models.Message) {
 		},
 	}
 
-	// Broadcast to conversation channel
-	channel := "conversation:" + message.ConversationID.Hex()
-	h.hub.BroadcastToChannel(channel, wsMessage, message.SenderID)
+	// Recipients who haven't accepted a message request get it without its media
+	h.hub.BroadcastNewMessage(conversation, message.SenderID, wsMessage)
 }
 
 func (h *MessageHandler) broadcastMessageUpdate(message *models.Message) {
@@ -773,3 +912,25 @@ func (h *MessageHandler) broadcastReaction(messageID, userID primitive.ObjectID,
 	channel := "conversation:" + message.ConversationID.Hex()
 	h.hub.BroadcastToChannel(channel, wsMessage, primitive.NilObjectID)
 }
+
+// respondAccountTooNew answers an AccountTooNewError with the requirement the
+// account hasn't met, reporting whether it did
+func respondAccountTooNew(c *gin.Context, err error) bool {
+	var maturityErr *models.AccountTooNewError
+	if !errors.As(err, &maturityErr) {
+		return false
+	}
+	utils.ErrorResponseWithDetails(c, http.StatusForbidden, maturityErr.Error(), utils.ErrorCodeAccountTooNew, maturityErr)
+	return true
+}
+
+// respondMediaRejected answers a MediaRejectedError with the rejected
+// attachment and reason, reporting whether it did
+func respondMediaRejected(c *gin.Context, err error) bool {
+	var mediaErr *models.MediaRejectedError
+	if !errors.As(err, &mediaErr) {
+		return false
+	}
+	utils.ErrorResponseWithDetails(c, http.StatusUnprocessableEntity, mediaErr.Error(), utils.ErrorCodeMediaRejected, mediaErr)
+	return true
+}
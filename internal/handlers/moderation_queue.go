@@ -0,0 +1,126 @@
+// internal/handlers/moderation_queue.go
+package handlers
+
+import (
+	"strings"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ModerationQueueHandler struct {
+	queueService *services.ModerationQueueService
+	validator    *validator.Validate
+}
+
+func NewModerationQueueHandler(queueService *services.ModerationQueueService) *ModerationQueueHandler {
+	return &ModerationQueueHandler{
+		queueService: queueService,
+		validator:    validator.New(),
+	}
+}
+
+// GetQueue returns pending reports, flagged media, auto-hidden content and
+// held comments as one prioritized queue
+func (h *ModerationQueueHandler) GetQueue(c *gin.Context) {
+	moderatorID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	filter := models.ModerationQueueFilter{
+		QueueItemType: models.ModerationQueueItemType(c.Query("type")),
+		Unclaimed:     c.Query("unclaimed") == "true",
+	}
+	if filter.QueueItemType != "" && !filter.QueueItemType.IsValid() {
+		utils.BadRequestResponse(c, "Invalid queue item type", nil)
+		return
+	}
+
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	items, total, err := h.queueService.GetQueue(moderatorID, filter, params.Limit, params.Offset)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get moderation queue", err)
+		return
+	}
+
+	paginationMeta := utils.CreatePaginationMeta(params, total)
+	utils.PaginatedSuccessResponse(c, "Moderation queue retrieved successfully", items, paginationMeta, nil)
+}
+
+// ClaimItem assigns a queue item to the current moderator
+func (h *ModerationQueueHandler) ClaimItem(c *gin.Context) {
+	moderatorID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	itemType := models.ModerationQueueItemType(c.Param("type"))
+	if !itemType.IsValid() {
+		utils.BadRequestResponse(c, "Invalid queue item type", nil)
+		return
+	}
+
+	itemID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid item ID", err)
+		return
+	}
+
+	claim, err := h.queueService.ClaimItem(moderatorID, itemType, itemID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Queue item not found")
+			return
+		}
+		if strings.Contains(err.Error(), "already claimed") {
+			utils.ConflictResponse(c, "Item is already claimed by another moderator", err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to claim item", err)
+		return
+	}
+
+	utils.OkResponse(c, "Item claimed successfully", claim)
+}
+
+// ReleaseItem gives up the current moderator's claim on a queue item
+func (h *ModerationQueueHandler) ReleaseItem(c *gin.Context) {
+	moderatorID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	itemType := models.ModerationQueueItemType(c.Param("type"))
+	if !itemType.IsValid() {
+		utils.BadRequestResponse(c, "Invalid queue item type", nil)
+		return
+	}
+
+	itemID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid item ID", err)
+		return
+	}
+
+	if err := h.queueService.ReleaseItem(moderatorID, itemType, itemID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Claim not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to release item", err)
+		return
+	}
+
+	utils.OkResponse(c, "Item released successfully", nil)
+}
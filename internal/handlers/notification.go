@@ -28,20 +28,35 @@ func NewNotificationHandler(notificationService *services.NotificationService) *
 
 // GetNotifications retrieves user's notifications
 func (h *NotificationHandler) GetNotifications(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// Get unread only parameter
 	unreadOnly := c.Query("unread_only") == "true"
 
+	// Let clients revalidate the page cheaply before it is loaded and populated
+	lastModified, total, err := h.notificationService.GetNotificationsVersion(userID, unreadOnly)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get notifications", err)
+		return
+	}
+
+	etag := utils.WeakETag(userID.Hex(), unreadOnly, params.Limit, params.Offset, total, lastModified)
+	if utils.CheckETag(c, etag) || utils.CheckModifiedSince(c, lastModified) {
+		return
+	}
+
 	notifications, err := h.notificationService.GetUserNotifications(
-		userID.(primitive.ObjectID),
+		userID,
 		params.Limit,
 		params.Offset,
 		unreadOnly,
@@ -59,13 +74,12 @@ func (h *NotificationHandler) GetNotifications(c *gin.Context) {
 
 // GetNotificationStats retrieves notification statistics
 func (h *NotificationHandler) GetNotificationStats(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	stats, err := h.notificationService.GetNotificationStats(userID.(primitive.ObjectID))
+	stats, err := h.notificationService.GetNotificationStats(userID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get notification statistics", err)
 		return
@@ -76,9 +90,8 @@ func (h *NotificationHandler) GetNotificationStats(c *gin.Context) {
 
 // MarkAsRead marks notifications as read
 func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -96,7 +109,7 @@ func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
 		return
 	}
 
-	err := h.notificationService.MarkAsRead(userID.(primitive.ObjectID), req.NotificationIDs)
+	err := h.notificationService.MarkAsRead(userID, req.NotificationIDs)
 	if err != nil {
 		if strings.Contains(err.Error(), "no valid") {
 			utils.BadRequestResponse(c, err.Error(), err)
@@ -113,13 +126,12 @@ func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
 
 // MarkAllAsRead marks all notifications as read
 func (h *NotificationHandler) MarkAllAsRead(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	err := h.notificationService.MarkAllAsRead(userID.(primitive.ObjectID))
+	err := h.notificationService.MarkAllAsRead(userID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to mark all notifications as read", err)
 		return
@@ -130,9 +142,8 @@ func (h *NotificationHandler) MarkAllAsRead(c *gin.Context) {
 
 // DeleteNotifications deletes notifications
 func (h *NotificationHandler) DeleteNotifications(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -150,7 +161,7 @@ func (h *NotificationHandler) DeleteNotifications(c *gin.Context) {
 		return
 	}
 
-	err := h.notificationService.DeleteNotifications(userID.(primitive.ObjectID), req.NotificationIDs)
+	err := h.notificationService.DeleteNotifications(userID, req.NotificationIDs)
 	if err != nil {
 		if strings.Contains(err.Error(), "no valid") {
 			utils.BadRequestResponse(c, err.Error(), err)
@@ -167,9 +178,8 @@ func (h *NotificationHandler) DeleteNotifications(c *gin.Context) {
 
 // CreateNotification creates a new notification (admin/system use)
 func (h *NotificationHandler) CreateNotification(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -186,7 +196,7 @@ func (h *NotificationHandler) CreateNotification(c *gin.Context) {
 
 	// Set actor ID to current user if not provided
 	if req.ActorID == "" {
-		req.ActorID = userID.(primitive.ObjectID).Hex()
+		req.ActorID = userID.Hex()
 	}
 
 	notification, err := h.notificationService.CreateNotification(req)
@@ -204,9 +214,8 @@ func (h *NotificationHandler) CreateNotification(c *gin.Context) {
 
 // CreateBulkNotifications creates notifications for multiple users
 func (h *NotificationHandler) CreateBulkNotifications(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -223,7 +232,7 @@ func (h *NotificationHandler) CreateBulkNotifications(c *gin.Context) {
 
 	// Set actor ID to current user if not provided
 	if req.ActorID == "" {
-		req.ActorID = userID.(primitive.ObjectID).Hex()
+		req.ActorID = userID.Hex()
 	}
 
 	// Validate recipient count
@@ -255,13 +264,12 @@ func (h *NotificationHandler) CreateBulkNotifications(c *gin.Context) {
 
 // GetNotificationPreferences retrieves user's notification preferences
 func (h *NotificationHandler) GetNotificationPreferences(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	preferences, err := h.notificationService.GetUserPreferences(userID.(primitive.ObjectID))
+	preferences, err := h.notificationService.GetUserPreferences(userID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get notification preferences", err)
 		return
@@ -272,9 +280,8 @@ func (h *NotificationHandler) GetNotificationPreferences(c *gin.Context) {
 
 // UpdateNotificationPreferences updates user's notification preferences
 func (h *NotificationHandler) UpdateNotificationPreferences(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -284,7 +291,7 @@ func (h *NotificationHandler) UpdateNotificationPreferences(c *gin.Context) {
 		return
 	}
 
-	err := h.notificationService.UpdateUserPreferences(userID.(primitive.ObjectID), preferences)
+	err := h.notificationService.UpdateUserPreferences(userID, preferences)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to update notification preferences", err)
 		return
@@ -295,9 +302,8 @@ func (h *NotificationHandler) UpdateNotificationPreferences(c *gin.Context) {
 
 // NotifyLike creates a like notification
 func (h *NotificationHandler) NotifyLike(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -323,7 +329,7 @@ func (h *NotificationHandler) NotifyLike(c *gin.Context) {
 		return
 	}
 
-	err = h.notificationService.NotifyLike(userID.(primitive.ObjectID), recipientID, postID)
+	err = h.notificationService.NotifyLike(userID, recipientID, postID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to create like notification", err)
 		return
@@ -334,9 +340,8 @@ func (h *NotificationHandler) NotifyLike(c *gin.Context) {
 
 // NotifyComment creates a comment notification
 func (h *NotificationHandler) NotifyComment(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -369,7 +374,7 @@ func (h *NotificationHandler) NotifyComment(c *gin.Context) {
 		return
 	}
 
-	err = h.notificationService.NotifyComment(userID.(primitive.ObjectID), recipientID, postID, commentID)
+	err = h.notificationService.NotifyComment(userID, recipientID, postID, commentID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to create comment notification", err)
 		return
@@ -380,9 +385,8 @@ func (h *NotificationHandler) NotifyComment(c *gin.Context) {
 
 // NotifyFollow creates a follow notification
 func (h *NotificationHandler) NotifyFollow(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -401,7 +405,7 @@ func (h *NotificationHandler) NotifyFollow(c *gin.Context) {
 		return
 	}
 
-	err = h.notificationService.NotifyFollow(userID.(primitive.ObjectID), recipientID)
+	err = h.notificationService.NotifyFollow(userID, recipientID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to create follow notification", err)
 		return
@@ -412,9 +416,8 @@ func (h *NotificationHandler) NotifyFollow(c *gin.Context) {
 
 // NotifyMention creates a mention notification
 func (h *NotificationHandler) NotifyMention(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -447,7 +450,7 @@ func (h *NotificationHandler) NotifyMention(c *gin.Context) {
 		return
 	}
 
-	err = h.notificationService.NotifyMention(userID.(primitive.ObjectID), recipientID, contentID, req.ContentType)
+	err = h.notificationService.NotifyMention(userID, recipientID, contentID, req.ContentType)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to create mention notification", err)
 		return
@@ -458,9 +461,8 @@ func (h *NotificationHandler) NotifyMention(c *gin.Context) {
 
 // NotifyMessage creates a message notification
 func (h *NotificationHandler) NotifyMessage(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -486,7 +488,7 @@ func (h *NotificationHandler) NotifyMessage(c *gin.Context) {
 		return
 	}
 
-	err = h.notificationService.NotifyMessage(userID.(primitive.ObjectID), recipientID, conversationID)
+	err = h.notificationService.NotifyMessage(userID, recipientID, conversationID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to create message notification", err)
 		return
@@ -497,9 +499,8 @@ func (h *NotificationHandler) NotifyMessage(c *gin.Context) {
 
 // TestNotification sends a test notification (development/admin use)
 func (h *NotificationHandler) TestNotification(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -515,8 +516,8 @@ func (h *NotificationHandler) TestNotification(c *gin.Context) {
 	}
 
 	notificationReq := models.CreateNotificationRequest{
-		RecipientID: userID.(primitive.ObjectID).Hex(),
-		ActorID:     userID.(primitive.ObjectID).Hex(),
+		RecipientID: userID.Hex(),
+		ActorID:     userID.Hex(),
 		Type:        models.NotificationType(req.Type),
 		Title:       req.Title,
 		Message:     req.Message,
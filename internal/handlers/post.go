@@ -27,9 +27,8 @@ func NewPostHandler(postService *services.PostService) *PostHandler {
 
 // CreatePost handles post creation
 func (h *PostHandler) CreatePost(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -38,25 +37,69 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 		utils.BadRequestResponse(c, "Invalid request format", err)
 		return
 	}
+	req.DelegateID = actingDelegate(c)
 
 	if err := h.validator.Struct(req); err != nil {
 		utils.ValidationErrorResponse(c, err)
 		return
 	}
 
-	// Validate content length
-	if len(req.Content) > utils.MaxPostContentLength {
-		utils.BadRequestResponse(c, "Post content exceeds maximum length", nil)
+	post, err := h.postService.CreatePost(userID, req)
+	if err != nil {
+		if respondBoundExceeded(c, err) {
+			return
+		}
+		if respondBlockedLink(c, err) {
+			return
+		}
+		if respondLimitExceeded(c, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "duplicate content") {
+			utils.ConflictResponse(c, err.Error(), err)
+			return
+		}
+		if strings.Contains(err.Error(), "group not found") {
+			utils.NotFoundResponse(c, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "in this group") {
+			utils.ForbiddenResponse(c, err.Error())
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to create post", err)
 		return
 	}
 
-	post, err := h.postService.CreatePost(userID.(primitive.ObjectID), req)
+	utils.CreatedResponse(c, "Post created successfully", post.ToPostResponse())
+}
+
+// PreviewPost renders a draft the way it will publish, resolving mentions,
+// hashtags and link previews, without saving anything
+func (h *PostHandler) PreviewPost(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.PostPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	preview, err := h.postService.PreviewPost(userID, req)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to create post", err)
+		utils.InternalServerErrorResponse(c, "Failed to preview post", err)
 		return
 	}
 
-	utils.CreatedResponse(c, "Post created successfully", post.ToPostResponse())
+	utils.OkResponse(c, "Post preview", preview)
 }
 
 // GetPost retrieves a single post by ID
@@ -69,13 +112,9 @@ func (h *PostHandler) GetPost(c *gin.Context) {
 	}
 
 	// Get current user ID if authenticated
-	var currentUserID *primitive.ObjectID
-	if userID, exists := c.Get("user_id"); exists {
-		uid := userID.(primitive.ObjectID)
-		currentUserID = &uid
-	}
+	currentUserID := utils.OptionalUserID(c)
 
-	post, err := h.postService.GetPostByID(postID, currentUserID)
+	post, err := h.postService.GetViewablePost(postID, currentUserID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Post not found")
@@ -85,6 +124,15 @@ func (h *PostHandler) GetPost(c *gin.Context) {
 		return
 	}
 
+	if utils.CheckETag(c, h.postService.PostETag(post, currentUserID)) {
+		return
+	}
+
+	if err := h.postService.PreparePostDetail(post, currentUserID); err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get post", err)
+		return
+	}
+
 	utils.OkResponse(c, "Post retrieved successfully", post.ToPostResponse())
 }
 
@@ -98,14 +146,14 @@ func (h *PostHandler) GetUserPosts(c *gin.Context) {
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// Get current user ID if authenticated
-	var currentUserID *primitive.ObjectID
-	if uid, exists := c.Get("user_id"); exists {
-		id := uid.(primitive.ObjectID)
-		currentUserID = &id
-	}
+	currentUserID := utils.OptionalUserID(c)
 
 	posts, err := h.postService.GetUserPosts(userID, currentUserID, params.Limit, params.Offset)
 	if err != nil {
@@ -128,16 +176,19 @@ func (h *PostHandler) GetUserPosts(c *gin.Context) {
 
 // GetFeed retrieves user's personalized feed
 func (h *PostHandler) GetFeed(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
-	posts, err := h.postService.GetFeedPosts(userID.(primitive.ObjectID), params.Limit, params.Offset)
+	posts, err := h.postService.GetFeedPosts(userID, params.Limit, params.Offset)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get feed", err)
 		return
@@ -151,9 +202,8 @@ func (h *PostHandler) GetFeed(c *gin.Context) {
 
 // UpdatePost handles post updates
 func (h *PostHandler) UpdatePost(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -169,19 +219,34 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 		utils.BadRequestResponse(c, "Invalid request format", err)
 		return
 	}
+	req.DelegateID = actingDelegate(c)
 
-	// Validate content length if provided
-	if req.Content != nil && len(*req.Content) > utils.MaxPostContentLength {
-		utils.BadRequestResponse(c, "Post content exceeds maximum length", nil)
-		return
+	if req.ContentWarning != nil {
+		if err := h.validator.Var(*req.ContentWarning, "max=200"); err != nil {
+			utils.BadRequestResponse(c, "Content warning must be at most 200 characters", nil)
+			return
+		}
 	}
 
-	post, err := h.postService.UpdatePost(postID, userID.(primitive.ObjectID), req)
+	post, err := h.postService.UpdatePost(postID, userID, req)
 	if err != nil {
+		if respondBoundExceeded(c, err) {
+			return
+		}
+		if respondBlockedLink(c, err) {
+			return
+		}
+		if respondLimitExceeded(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Post not found or access denied")
 			return
 		}
+		if strings.Contains(err.Error(), "marked sensitive by a moderator") {
+			utils.ForbiddenResponse(c, "Only a moderator can clear this post's sensitive flag")
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to update post", err)
 		return
 	}
@@ -189,11 +254,56 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 	utils.OkResponse(c, "Post updated successfully", post.ToPostResponse())
 }
 
+// MarkPostSensitive marks a post sensitive or clears the flag. Moderators can
+// do this for any post, authors for their own.
+func (h *PostHandler) MarkPostSensitive(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	role, ok := utils.CurrentRole(c)
+	if !ok {
+		return
+	}
+
+	postID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid post ID format", err)
+		return
+	}
+
+	var req models.MarkPostSensitiveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	post, err := h.postService.SetPostSensitive(postID, userID, role.IsModerator(), req)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
+			utils.NotFoundResponse(c, "Post not found or access denied")
+			return
+		}
+		if strings.Contains(err.Error(), "marked sensitive by a moderator") {
+			utils.ForbiddenResponse(c, "Only a moderator can clear this post's sensitive flag")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to update post", err)
+		return
+	}
+
+	utils.OkResponse(c, "Post sensitivity updated successfully", post.ToPostResponse())
+}
+
 // DeletePost handles post deletion
 func (h *PostHandler) DeletePost(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -204,7 +314,7 @@ func (h *PostHandler) DeletePost(c *gin.Context) {
 		return
 	}
 
-	err = h.postService.DeletePost(postID, userID.(primitive.ObjectID))
+	err = h.postService.DeletePost(postID, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Post not found or access denied")
@@ -217,11 +327,77 @@ func (h *PostHandler) DeletePost(c *gin.Context) {
 	utils.OkResponse(c, "Post deleted successfully", nil)
 }
 
+// PreviewBulkDeletePosts counts the current user's posts a bulk delete filter
+// matches; the count confirms the deletion
+func (h *PostHandler) PreviewBulkDeletePosts(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var filter models.BulkDeleteFilter
+	if err := c.ShouldBindJSON(&filter); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	preview, err := h.postService.PreviewBulkDeleteMyPosts(userID, filter)
+	if err != nil {
+		bulkDeleteErrorResponse(c, "Failed to preview bulk delete", err)
+		return
+	}
+
+	utils.OkResponse(c, "Bulk delete preview", preview)
+}
+
+// BulkDeletePosts deletes the current user's posts matching a filter, once
+// confirmed with the count from the preview
+func (h *PostHandler) BulkDeletePosts(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	req, ok := bindBulkDeleteRequest(c, h.validator)
+	if !ok {
+		return
+	}
+
+	job, err := h.postService.BulkDeleteMyPosts(userID, req)
+	if err != nil {
+		bulkDeleteErrorResponse(c, "Failed to delete posts", err)
+		return
+	}
+
+	bulkDeleteResponse(c, job)
+}
+
+// GetBulkDeletePostsJob reports the progress of one of the current user's
+// bulk post deletions
+func (h *PostHandler) GetBulkDeletePostsJob(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	jobID, ok := bulkDeleteJobID(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.postService.GetBulkDeleteJob(userID, jobID)
+	if err != nil {
+		bulkDeleteErrorResponse(c, "Failed to get bulk delete job", err)
+		return
+	}
+
+	utils.OkResponse(c, "Bulk delete job retrieved", job)
+}
+
 // LikePost handles post likes
 func (h *PostHandler) LikePost(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -241,12 +417,16 @@ func (h *PostHandler) LikePost(c *gin.Context) {
 		req.ReactionType = models.ReactionLike
 	}
 
-	err = h.postService.LikePost(postID, userID.(primitive.ObjectID), req.ReactionType)
+	err = h.postService.LikePost(postID, userID, req.ReactionType)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "Post not found")
 			return
 		}
+		if strings.Contains(err.Error(), "invalid reaction") {
+			utils.BadRequestResponse(c, "Invalid reaction type", err)
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to like post", err)
 		return
 	}
@@ -259,9 +439,8 @@ func (h *PostHandler) LikePost(c *gin.Context) {
 
 // UnlikePost handles post unlikes
 func (h *PostHandler) UnlikePost(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -272,7 +451,7 @@ func (h *PostHandler) UnlikePost(c *gin.Context) {
 		return
 	}
 
-	err = h.postService.UnlikePost(postID, userID.(primitive.ObjectID))
+	err = h.postService.UnlikePost(postID, userID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to unlike post", err)
 		return
@@ -293,7 +472,11 @@ func (h *PostHandler) GetPostLikes(c *gin.Context) {
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	likes, err := h.postService.GetPostLikes(postID, params.Limit, params.Offset)
 	if err != nil {
@@ -307,11 +490,46 @@ func (h *PostHandler) GetPostLikes(c *gin.Context) {
 	utils.PaginatedSuccessResponse(c, "Post likes retrieved successfully", likes, paginationMeta, nil)
 }
 
+// GetPostEdits handles getting a post's edit history
+func (h *PostHandler) GetPostEdits(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	postIDStr := c.Param("id")
+	postID, err := primitive.ObjectIDFromHex(postIDStr)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid post ID format", err)
+		return
+	}
+
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	edits, err := h.postService.GetPostEdits(postID, userID, params.Limit, params.Offset)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
+			utils.NotFoundResponse(c, "Post not found or access denied")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get post edits", err)
+		return
+	}
+
+	totalCount := int64(len(edits))
+	paginationMeta := utils.CreatePaginationMeta(params, totalCount)
+
+	utils.PaginatedSuccessResponse(c, "Post edits retrieved successfully", edits, paginationMeta, nil)
+}
+
 // ReportPost handles post reporting
 func (h *PostHandler) ReportPost(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -332,7 +550,7 @@ func (h *PostHandler) ReportPost(c *gin.Context) {
 		return
 	}
 
-	err = h.postService.ReportPost(postID, userID.(primitive.ObjectID), req.Reason, req.Description)
+	err = h.postService.ReportPost(postID, userID, req.Reason, req.Description)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "Post not found")
@@ -379,14 +597,14 @@ func (h *PostHandler) SearchPosts(c *gin.Context) {
 	}
 
 	// Get current user ID if authenticated
-	var currentUserID *primitive.ObjectID
-	if userID, exists := c.Get("user_id"); exists {
-		uid := userID.(primitive.ObjectID)
-		currentUserID = &uid
-	}
+	currentUserID := utils.OptionalUserID(c)
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	posts, err := h.postService.SearchPosts(query, currentUserID, params.Limit, params.Offset)
 	if err != nil {
@@ -409,7 +627,11 @@ func (h *PostHandler) SearchPosts(c *gin.Context) {
 // GetTrendingPosts retrieves trending posts
 func (h *PostHandler) GetTrendingPosts(c *gin.Context) {
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// Get time range parameter
 	timeRange := c.DefaultQuery("time_range", "day")
@@ -437,9 +659,8 @@ func (h *PostHandler) GetTrendingPosts(c *gin.Context) {
 
 // PinPost pins a post to user's profile
 func (h *PostHandler) PinPost(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -456,7 +677,7 @@ func (h *PostHandler) PinPost(c *gin.Context) {
 		IsPinned: &isPinned,
 	}
 
-	_, err = h.postService.UpdatePost(postID, userID.(primitive.ObjectID), req)
+	_, err = h.postService.UpdatePost(postID, userID, req)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Post not found or access denied")
@@ -473,9 +694,8 @@ func (h *PostHandler) PinPost(c *gin.Context) {
 
 // UnpinPost unpins a post from user's profile
 func (h *PostHandler) UnpinPost(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -492,7 +712,7 @@ func (h *PostHandler) UnpinPost(c *gin.Context) {
 		IsPinned: &isPinned,
 	}
 
-	_, err = h.postService.UpdatePost(postID, userID.(primitive.ObjectID), req)
+	_, err = h.postService.UpdatePost(postID, userID, req)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Post not found or access denied")
@@ -506,3 +726,161 @@ func (h *PostHandler) UnpinPost(c *gin.Context) {
 		"pinned": false,
 	})
 }
+
+// TagUsers tags users on the media of the caller's post
+func (h *PostHandler) TagUsers(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	postID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid post ID format", err)
+		return
+	}
+
+	var req models.TagUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	tags, err := h.postService.TagUsers(postID, userID, req)
+	if err != nil {
+		h.photoTagErrorResponse(c, "Failed to tag users", err)
+		return
+	}
+
+	utils.OkResponse(c, "Users tagged successfully", gin.H{
+		"photo_tags": tags,
+	})
+}
+
+// RemoveTag removes a user's tag from a post, either by the post's author or
+// by the tagged user themselves
+func (h *PostHandler) RemoveTag(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	postID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid post ID format", err)
+		return
+	}
+
+	taggedUserID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID format", err)
+		return
+	}
+
+	if err := h.postService.RemoveTag(postID, userID, taggedUserID); err != nil {
+		h.photoTagErrorResponse(c, "Failed to remove tag", err)
+		return
+	}
+
+	utils.OkResponse(c, "Tag removed successfully", nil)
+}
+
+// RespondToTag accepts the post onto the caller's tagged tab or hides it
+// from there
+func (h *PostHandler) RespondToTag(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	postID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid post ID format", err)
+		return
+	}
+
+	var req models.RespondToPhotoTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	if err := h.postService.RespondToTag(postID, userID, req.Status); err != nil {
+		h.photoTagErrorResponse(c, "Failed to update tag", err)
+		return
+	}
+
+	utils.OkResponse(c, "Tag updated successfully", gin.H{
+		"status": req.Status,
+	})
+}
+
+// GetTaggedPosts returns the posts on a user's tagged tab. The user can list
+// tags awaiting their review with ?status=pending, or hidden ones with
+// ?status=hidden.
+func (h *PostHandler) GetTaggedPosts(c *gin.Context) {
+	userID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID format", err)
+		return
+	}
+
+	status := models.PhotoTagStatus(c.Query("status"))
+	switch status {
+	case "", models.PhotoTagAccepted, models.PhotoTagPending, models.PhotoTagHidden:
+	default:
+		utils.BadRequestResponse(c, "Invalid status, must be accepted, pending or hidden", nil)
+		return
+	}
+
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	currentUserID := utils.OptionalUserID(c)
+
+	posts, total, err := h.postService.GetTaggedPosts(userID, currentUserID, status, params.Limit, params.Offset)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get tagged posts", err)
+		return
+	}
+
+	postResponses := make([]models.PostResponse, 0, len(posts))
+	for _, post := range posts {
+		postResponses = append(postResponses, post.ToPostResponse())
+	}
+
+	paginationMeta := utils.CreatePaginationMeta(params, total)
+	utils.PaginatedSuccessResponse(c, "Tagged posts retrieved successfully", postResponses, paginationMeta, nil)
+}
+
+func (h *PostHandler) photoTagErrorResponse(c *gin.Context, message string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "tag not found"):
+		utils.NotFoundResponse(c, "Tag not found")
+	case strings.Contains(err.Error(), "user not found"):
+		utils.NotFoundResponse(c, "User not found")
+	case strings.Contains(err.Error(), "not found"):
+		utils.NotFoundResponse(c, "Post not found")
+	case strings.Contains(err.Error(), "access denied"):
+		utils.ForbiddenResponse(c, "You can't change tags on this post")
+	case strings.Contains(err.Error(), "does not allow tagging"):
+		utils.ForbiddenResponse(c, "User does not allow tagging")
+	case strings.Contains(err.Error(), "invalid"):
+		utils.BadRequestResponse(c, err.Error(), err)
+	default:
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+)
+
+// A client that only knows allow_messages still closes its inbox by turning
+// it off; who_can_message is derived from it when left out
+func TestPrivacySettingsDeriveWhoCanMessage(t *testing.T) {
+	h := testutil.NewHarness(t)
+	users := NewUserHandler(services.NewUserService(h.DB, services.AbuseScorePolicy{}, services.NewLimitsService(h.DB, time.Minute)), services.NewSuggestionService(h.DB))
+
+	tests := []struct {
+		name string
+		body string
+		want models.MessagePermission
+	}{
+		{"messages off", `{"allow_messages":false}`, models.MessagePermissionNoOne},
+		{"messages on", `{"allow_messages":true}`, models.MessagePermissionEveryone},
+		{"explicit permission", `{"allow_messages":false,"who_can_message":"mutual"}`, models.MessagePermissionMutual},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := h.CreateUser()
+			c, rec := h.AuthenticatedContext(user, http.MethodPut, "/", strings.NewReader(tt.body))
+			users.UpdatePrivacySettings(c)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+			}
+
+			if got := h.ReloadUser(user.ID).PrivacySettings.WhoCanMessage; got != tt.want {
+				t.Errorf("who_can_message = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
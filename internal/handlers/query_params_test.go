@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Malformed numeric query parameters are answered with 400 before any
+// service is called, so the handlers here have none
+func TestMalformedNumericQueryParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := primitive.NewObjectID()
+	messageID := primitive.NewObjectID().Hex()
+
+	calendar := NewContentCalendarHandler(nil)
+	conversations := &ConversationHandler{}
+
+	tests := []struct {
+		name   string
+		target string
+		serve  func(c *gin.Context)
+	}{
+		{"calendar page", "/calendar?page=abc", calendar.GetContentCalendar},
+		{"calendar page zero", "/calendar?page=0", calendar.GetContentCalendar},
+		{"calendar per_day", "/calendar?per_day=many", calendar.GetContentCalendar},
+		{"window before", "/messages?around=" + messageID + "&before=ten", func(c *gin.Context) {
+			conversations.getMessageWindow(c, primitive.NewObjectID(), userID)
+		}},
+		{"window after", "/messages?around=" + messageID + "&after=-2", func(c *gin.Context) {
+			conversations.getMessageWindow(c, primitive.NewObjectID(), userID)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = httptest.NewRequest(http.MethodGet, tt.target, nil)
+			c.Set(utils.ContextUserID, userID)
+
+			tt.serve(c)
+
+			if recorder.Code != http.StatusBadRequest {
+				t.Errorf("GET %s = %d, want %d", tt.target, recorder.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// internal/handlers/reaction_type.go
+package handlers
+
+import (
+	"strings"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReactionTypeHandler struct {
+	reactionTypeService *services.ReactionTypeService
+	validator           *validator.Validate
+}
+
+func NewReactionTypeHandler(reactionTypeService *services.ReactionTypeService) *ReactionTypeHandler {
+	return &ReactionTypeHandler{
+		reactionTypeService: reactionTypeService,
+		validator:           validator.New(),
+	}
+}
+
+// GetAvailableReactions returns the reactions clients render in the picker
+func (h *ReactionTypeHandler) GetAvailableReactions(c *gin.Context) {
+	reactions, err := h.reactionTypeService.GetAvailableReactions()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get available reactions", err)
+		return
+	}
+
+	utils.OkResponse(c, "Available reactions retrieved successfully", gin.H{
+		"reactions": reactions,
+		"total":     len(reactions),
+	})
+}
+
+// Admin handlers
+
+// GetReactionTypes lists configured reactions, optionally including retired ones
+func (h *ReactionTypeHandler) GetReactionTypes(c *gin.Context) {
+	includeRetired := c.Query("include_retired") == "true"
+
+	reactions, err := h.reactionTypeService.GetReactionTypes(includeRetired)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get reaction types", err)
+		return
+	}
+
+	utils.OkResponse(c, "Reaction types retrieved successfully", reactions)
+}
+
+// CreateReactionType adds a reaction to the picker
+func (h *ReactionTypeHandler) CreateReactionType(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateReactionTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	reaction, err := h.reactionTypeService.CreateReactionType(userID, req)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "already exists"):
+			utils.ConflictResponse(c, "Reaction key already exists", err)
+		case strings.Contains(err.Error(), "invalid"):
+			utils.BadRequestResponse(c, err.Error(), nil)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to create reaction type", err)
+		}
+		return
+	}
+
+	utils.CreatedResponse(c, "Reaction type created successfully", reaction)
+}
+
+// UpdateReactionType updates a reaction's display settings and schedule
+func (h *ReactionTypeHandler) UpdateReactionType(c *gin.Context) {
+	reactionID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid reaction ID format", err)
+		return
+	}
+
+	var req models.UpdateReactionTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	reaction, err := h.reactionTypeService.UpdateReactionType(reactionID, req)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			utils.NotFoundResponse(c, "Reaction type not found")
+		case strings.Contains(err.Error(), "invalid"):
+			utils.BadRequestResponse(c, err.Error(), nil)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to update reaction type", err)
+		}
+		return
+	}
+
+	utils.OkResponse(c, "Reaction type updated successfully", reaction)
+}
+
+// RetireReactionType removes a reaction from the picker
+func (h *ReactionTypeHandler) RetireReactionType(c *gin.Context) {
+	reactionID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid reaction ID format", err)
+		return
+	}
+
+	if err := h.reactionTypeService.RetireReactionType(reactionID); err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			utils.NotFoundResponse(c, "Reaction type not found or already retired")
+		case strings.Contains(err.Error(), "cannot be retired"):
+			utils.BadRequestResponse(c, err.Error(), nil)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to retire reaction type", err)
+		}
+		return
+	}
+
+	utils.OkResponse(c, "Reaction type retired successfully", nil)
+}
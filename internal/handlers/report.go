@@ -27,9 +27,8 @@ func NewReportHandler(reportService *services.ReportService) *ReportHandler {
 
 // CreateReport creates a new report
 func (h *ReportHandler) CreateReport(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -68,12 +67,16 @@ func (h *ReportHandler) CreateReport(c *gin.Context) {
 		return
 	}
 
-	report, err := h.reportService.CreateReport(userID.(primitive.ObjectID), req)
+	report, err := h.reportService.CreateReport(userID, req)
 	if err != nil {
 		if strings.Contains(err.Error(), "already reported") {
 			utils.ConflictResponse(c, "You have already reported this content", err)
 			return
 		}
+		if strings.Contains(err.Error(), "your own") {
+			utils.BadRequestResponse(c, "Cannot report your own content", nil)
+			return
+		}
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "Target content not found")
 			return
@@ -91,20 +94,23 @@ func (h *ReportHandler) CreateReport(c *gin.Context) {
 
 // GetReports retrieves reports with filtering and pagination
 func (h *ReportHandler) GetReports(c *gin.Context) {
-	_, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	_, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Check if user is moderator/admin
-	if !h.isModerator(c) {
+	if role, _ := utils.LookupRole(c); !role.IsModerator() {
 		utils.ForbiddenResponse(c, "Insufficient permissions")
 		return
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// Build filter from query parameters
 	filter := models.ReportFilter{
@@ -174,9 +180,8 @@ func (h *ReportHandler) GetReports(c *gin.Context) {
 
 // GetReport retrieves a specific report by ID
 func (h *ReportHandler) GetReport(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -187,7 +192,7 @@ func (h *ReportHandler) GetReport(c *gin.Context) {
 		return
 	}
 
-	report, err := h.reportService.GetReportByID(reportID, userID.(primitive.ObjectID))
+	report, err := h.reportService.GetReportByID(reportID, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "Report not found")
@@ -206,14 +211,13 @@ func (h *ReportHandler) GetReport(c *gin.Context) {
 
 // UpdateReport updates a report (moderator only)
 func (h *ReportHandler) UpdateReport(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Check if user is moderator/admin
-	if !h.isModerator(c) {
+	if role, _ := utils.LookupRole(c); !role.IsModerator() {
 		utils.ForbiddenResponse(c, "Insufficient permissions")
 		return
 	}
@@ -255,7 +259,7 @@ func (h *ReportHandler) UpdateReport(c *gin.Context) {
 		return
 	}
 
-	report, err := h.reportService.UpdateReport(reportID, userID.(primitive.ObjectID), req)
+	report, err := h.reportService.UpdateReport(reportID, userID, req)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "Report not found")
@@ -274,14 +278,13 @@ func (h *ReportHandler) UpdateReport(c *gin.Context) {
 
 // AssignReport assigns a report to a moderator
 func (h *ReportHandler) AssignReport(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Check if user is moderator/admin
-	if !h.isModerator(c) {
+	if role, _ := utils.LookupRole(c); !role.IsModerator() {
 		utils.ForbiddenResponse(c, "Insufficient permissions")
 		return
 	}
@@ -305,7 +308,7 @@ func (h *ReportHandler) AssignReport(c *gin.Context) {
 		return
 	}
 
-	err = h.reportService.AssignReport(reportID, userID.(primitive.ObjectID), assignedTo)
+	err = h.reportService.AssignReport(reportID, userID, assignedTo)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "Report or assignee not found")
@@ -327,14 +330,13 @@ func (h *ReportHandler) AssignReport(c *gin.Context) {
 
 // ResolveReport resolves a report
 func (h *ReportHandler) ResolveReport(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Check if user is moderator/admin
-	if !h.isModerator(c) {
+	if role, _ := utils.LookupRole(c); !role.IsModerator() {
 		utils.ForbiddenResponse(c, "Insufficient permissions")
 		return
 	}
@@ -364,8 +366,25 @@ func (h *ReportHandler) ResolveReport(c *gin.Context) {
 		return
 	}
 
-	err = h.reportService.ResolveReport(reportID, userID.(primitive.ObjectID), req.Resolution, req.Note)
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	err = h.reportService.ResolveReport(reportID, userID, req.Resolution, req.ResolutionAction, req.Note)
 	if err != nil {
+		if strings.Contains(err.Error(), "target not found") || strings.Contains(err.Error(), "target owner not found") {
+			utils.BadRequestResponse(c, "Reported target no longer exists", err)
+			return
+		}
+		if strings.Contains(err.Error(), "not valid for") {
+			utils.BadRequestResponse(c, err.Error(), nil)
+			return
+		}
+		if strings.Contains(err.Error(), "already resolved") {
+			utils.ConflictResponse(c, "Report has already been resolved", err)
+			return
+		}
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "Report not found")
 			return
@@ -379,22 +398,22 @@ func (h *ReportHandler) ResolveReport(c *gin.Context) {
 	}
 
 	utils.OkResponse(c, "Report resolved successfully", gin.H{
-		"report_id":  reportIDStr,
-		"status":     "resolved",
-		"resolution": req.Resolution,
+		"report_id":         reportIDStr,
+		"status":            "resolved",
+		"resolution":        req.Resolution,
+		"resolution_action": req.ResolutionAction,
 	})
 }
 
 // RejectReport rejects a report
 func (h *ReportHandler) RejectReport(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Check if user is moderator/admin
-	if !h.isModerator(c) {
+	if role, _ := utils.LookupRole(c); !role.IsModerator() {
 		utils.ForbiddenResponse(c, "Insufficient permissions")
 		return
 	}
@@ -423,7 +442,7 @@ func (h *ReportHandler) RejectReport(c *gin.Context) {
 		return
 	}
 
-	err = h.reportService.RejectReport(reportID, userID.(primitive.ObjectID), req.Note)
+	err = h.reportService.RejectReport(reportID, userID, req.Note)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "Report not found")
@@ -446,14 +465,13 @@ func (h *ReportHandler) RejectReport(c *gin.Context) {
 
 // GetReportStats retrieves report statistics
 func (h *ReportHandler) GetReportStats(c *gin.Context) {
-	_, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	_, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Check if user is moderator/admin
-	if !h.isModerator(c) {
+	if role, _ := utils.LookupRole(c); !role.IsModerator() {
 		utils.ForbiddenResponse(c, "Insufficient permissions")
 		return
 	}
@@ -469,14 +487,13 @@ func (h *ReportHandler) GetReportStats(c *gin.Context) {
 
 // GetReportSummary retrieves report summary by reason
 func (h *ReportHandler) GetReportSummary(c *gin.Context) {
-	_, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	_, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Check if user is moderator/admin
-	if !h.isModerator(c) {
+	if role, _ := utils.LookupRole(c); !role.IsModerator() {
 		utils.ForbiddenResponse(c, "Insufficient permissions")
 		return
 	}
@@ -492,9 +509,8 @@ func (h *ReportHandler) GetReportSummary(c *gin.Context) {
 
 // GetUserReports retrieves reports made by a specific user
 func (h *ReportHandler) GetUserReports(c *gin.Context) {
-	currentUserID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	currentUserID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -506,13 +522,17 @@ func (h *ReportHandler) GetUserReports(c *gin.Context) {
 	}
 
 	// Users can only see their own reports unless they're moderators
-	if userID != currentUserID.(primitive.ObjectID) && !h.isModerator(c) {
+	if role, _ := utils.LookupRole(c); userID != currentUserID && !role.IsModerator() {
 		utils.ForbiddenResponse(c, "Access denied")
 		return
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	reports, err := h.reportService.GetUserReports(userID, params.Limit, params.Offset)
 	if err != nil {
@@ -534,14 +554,13 @@ func (h *ReportHandler) GetUserReports(c *gin.Context) {
 
 // GetReportsByTarget retrieves reports for a specific target
 func (h *ReportHandler) GetReportsByTarget(c *gin.Context) {
-	_, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	_, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Check if user is moderator/admin
-	if !h.isModerator(c) {
+	if role, _ := utils.LookupRole(c); !role.IsModerator() {
 		utils.ForbiddenResponse(c, "Insufficient permissions")
 		return
 	}
@@ -563,7 +582,11 @@ func (h *ReportHandler) GetReportsByTarget(c *gin.Context) {
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	reports, err := h.reportService.GetReportsByTarget(targetType, targetID, params.Limit, params.Offset)
 	if err != nil {
@@ -591,14 +614,13 @@ func (h *ReportHandler) GetReportsByTarget(c *gin.Context) {
 
 // BulkUpdateReports updates multiple reports at once
 func (h *ReportHandler) BulkUpdateReports(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Check if user is moderator/admin
-	if !h.isModerator(c) {
+	if role, _ := utils.LookupRole(c); !role.IsModerator() {
 		utils.ForbiddenResponse(c, "Insufficient permissions")
 		return
 	}
@@ -636,7 +658,7 @@ func (h *ReportHandler) BulkUpdateReports(c *gin.Context) {
 			continue
 		}
 
-		_, err = h.reportService.UpdateReport(reportID, userID.(primitive.ObjectID), req.Data)
+		_, err = h.reportService.UpdateReport(reportID, userID, req.Data)
 		if err != nil {
 			failedReports = append(failedReports, reportIDStr+": "+err.Error())
 		} else {
@@ -664,16 +686,19 @@ func (h *ReportHandler) BulkUpdateReports(c *gin.Context) {
 
 // GetMyReports retrieves reports made by the current user
 func (h *ReportHandler) GetMyReports(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
-	reports, err := h.reportService.GetUserReports(userID.(primitive.ObjectID), params.Limit, params.Offset)
+	reports, err := h.reportService.GetUserReports(userID, params.Limit, params.Offset)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get your reports", err)
 		return
@@ -693,24 +718,27 @@ func (h *ReportHandler) GetMyReports(c *gin.Context) {
 
 // GetPendingReports retrieves pending reports assigned to current user
 func (h *ReportHandler) GetPendingReports(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Check if user is moderator/admin
-	if !h.isModerator(c) {
+	if role, _ := utils.LookupRole(c); !role.IsModerator() {
 		utils.ForbiddenResponse(c, "Insufficient permissions")
 		return
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// Build filter for pending reports assigned to current user
 	filter := models.ReportFilter{
-		AssignedTo: userID.(primitive.ObjectID).Hex(),
+		AssignedTo: userID.Hex(),
 		Status:     "pending",
 		SortBy:     "priority",
 	}
@@ -734,20 +762,6 @@ func (h *ReportHandler) GetPendingReports(c *gin.Context) {
 
 // Helper methods
 
-func (h *ReportHandler) isModerator(c *gin.Context) bool {
-	userRole, exists := c.Get("user_role")
-	if !exists {
-		return false
-	}
-
-	role, ok := userRole.(models.UserRole)
-	if !ok {
-		return false
-	}
-
-	return role == models.RoleModerator || role == models.RoleAdmin || role == models.RoleSuperAdmin
-}
-
 func (h *ReportHandler) isValidTargetType(targetType string, validTypes []string) bool {
 	for _, validType := range validTypes {
 		if targetType == validType {
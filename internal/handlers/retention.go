@@ -0,0 +1,70 @@
+// internal/handlers/retention.go
+package handlers
+
+import (
+	"strings"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type RetentionHandler struct {
+	retentionService *services.RetentionService
+	validator        *validator.Validate
+}
+
+func NewRetentionHandler(retentionService *services.RetentionService) *RetentionHandler {
+	return &RetentionHandler{
+		retentionService: retentionService,
+		validator:        validator.New(),
+	}
+}
+
+// GetRetention returns every data category with its retention period, legal hold and last run
+func (h *RetentionHandler) GetRetention(c *gin.Context) {
+	overview, err := h.retentionService.GetOverview()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get retention settings", err)
+		return
+	}
+
+	utils.OkResponse(c, "Retention settings retrieved successfully", overview)
+}
+
+// UpdateRetentionCategory changes a category's retention period or legal hold
+func (h *RetentionHandler) UpdateRetentionCategory(c *gin.Context) {
+	adminID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateRetentionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	status, err := h.retentionService.UpdateCategory(adminID, c.Param("category"), req)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "unknown retention category"):
+			utils.NotFoundResponse(c, "Retention category not found")
+		case strings.Contains(err.Error(), "invalid retention period"):
+			utils.BadRequestResponse(c, err.Error(), err)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to update retention settings", err)
+		}
+		return
+	}
+
+	utils.OkResponse(c, "Retention settings updated successfully", status)
+}
@@ -0,0 +1,194 @@
+// internal/handlers/saved_search.go
+package handlers
+
+import (
+	"strings"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SavedSearchHandler struct {
+	savedSearchService *services.SavedSearchService
+	validator          *validator.Validate
+}
+
+func NewSavedSearchHandler(savedSearchService *services.SavedSearchService) *SavedSearchHandler {
+	return &SavedSearchHandler{
+		savedSearchService: savedSearchService,
+		validator:          validator.New(),
+	}
+}
+
+// CreateSavedSearch saves a post search, with alerts for new matches unless
+// they are turned off
+func (h *SavedSearchHandler) CreateSavedSearch(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateSavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	search, err := h.savedSearchService.CreateSavedSearch(userID, req)
+	if err != nil {
+		h.savedSearchErrorResponse(c, "Failed to save search", err)
+		return
+	}
+
+	utils.CreatedResponse(c, "Search saved successfully", search)
+}
+
+// GetSavedSearches lists the current user's saved searches
+func (h *SavedSearchHandler) GetSavedSearches(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	searches, total, err := h.savedSearchService.GetSavedSearches(userID, params.Limit, params.Offset)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get saved searches", err)
+		return
+	}
+
+	paginationMeta := utils.CreatePaginationMeta(params, total)
+	utils.PaginatedSuccessResponse(c, "Saved searches retrieved successfully", searches, paginationMeta, nil)
+}
+
+// GetSavedSearch returns one of the current user's saved searches
+func (h *SavedSearchHandler) GetSavedSearch(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	searchID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid saved search ID", err)
+		return
+	}
+
+	search, err := h.savedSearchService.GetSavedSearch(searchID, userID)
+	if err != nil {
+		h.savedSearchErrorResponse(c, "Failed to get saved search", err)
+		return
+	}
+
+	utils.OkResponse(c, "Saved search retrieved successfully", search)
+}
+
+// UpdateSavedSearch changes one of the current user's saved searches
+func (h *SavedSearchHandler) UpdateSavedSearch(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	searchID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid saved search ID", err)
+		return
+	}
+
+	var req models.UpdateSavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	search, err := h.savedSearchService.UpdateSavedSearch(searchID, userID, req)
+	if err != nil {
+		h.savedSearchErrorResponse(c, "Failed to update saved search", err)
+		return
+	}
+
+	utils.OkResponse(c, "Saved search updated successfully", search)
+}
+
+// DeleteSavedSearch removes one of the current user's saved searches
+func (h *SavedSearchHandler) DeleteSavedSearch(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	searchID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid saved search ID", err)
+		return
+	}
+
+	if err := h.savedSearchService.DeleteSavedSearch(searchID, userID); err != nil {
+		h.savedSearchErrorResponse(c, "Failed to delete saved search", err)
+		return
+	}
+
+	utils.OkResponse(c, "Saved search deleted successfully", nil)
+}
+
+// RunSavedSearch runs one of the current user's saved searches now
+func (h *SavedSearchHandler) RunSavedSearch(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	searchID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid saved search ID", err)
+		return
+	}
+
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	response, err := h.savedSearchService.RunSavedSearch(searchID, userID, params.Limit, params.Offset)
+	if err != nil {
+		h.savedSearchErrorResponse(c, "Saved search failed", err)
+		return
+	}
+
+	utils.OkResponse(c, "Search completed successfully", response)
+}
+
+func (h *SavedSearchHandler) savedSearchErrorResponse(c *gin.Context, message string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		utils.NotFoundResponse(c, "Saved search not found")
+	case strings.Contains(err.Error(), "limit reached"):
+		utils.ConflictResponse(c, err.Error(), err)
+	case strings.Contains(err.Error(), "invalid"):
+		utils.BadRequestResponse(c, err.Error(), err)
+	default:
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+}
@@ -2,7 +2,6 @@
 package handlers
 
 import (
-	"strconv"
 	"strings"
 
 	"social-media-api/internal/services"
@@ -39,14 +38,14 @@ func (h *SearchHandler) Search(c *gin.Context) {
 	}
 
 	// Get current user ID if authenticated
-	var userID *primitive.ObjectID
-	if uid, exists := c.Get("user_id"); exists {
-		id := uid.(primitive.ObjectID)
-		userID = &id
-	}
+	userID := utils.OptionalUserID(c)
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// Build search filters
 	filters := services.SearchFilters{
@@ -56,6 +55,7 @@ func (h *SearchHandler) Search(c *gin.Context) {
 		Location:    c.Query("location"),
 		Language:    c.Query("language"),
 		ContentType: c.Query("content_type"),
+		Languages:   utils.GetLanguageFilter(c),
 	}
 
 	// Validate filters
@@ -92,14 +92,14 @@ func (h *SearchHandler) SearchPosts(c *gin.Context) {
 	}
 
 	// Get current user ID if authenticated
-	var userID *primitive.ObjectID
-	if uid, exists := c.Get("user_id"); exists {
-		id := uid.(primitive.ObjectID)
-		userID = &id
-	}
+	userID := utils.OptionalUserID(c)
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// Build filters for posts only
 	filters := services.SearchFilters{
@@ -109,6 +109,7 @@ func (h *SearchHandler) SearchPosts(c *gin.Context) {
 		Location:    c.Query("location"),
 		Language:    c.Query("language"),
 		ContentType: c.Query("content_type"),
+		Languages:   utils.GetLanguageFilter(c),
 	}
 
 	response, err := h.searchService.Search(query, userID, filters, params.Limit, params.Offset)
@@ -129,14 +130,14 @@ func (h *SearchHandler) SearchUsers(c *gin.Context) {
 	}
 
 	// Get current user ID if authenticated
-	var userID *primitive.ObjectID
-	if uid, exists := c.Get("user_id"); exists {
-		id := uid.(primitive.ObjectID)
-		userID = &id
-	}
+	userID := utils.OptionalUserID(c)
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// Build filters for users only
 	filters := services.SearchFilters{
@@ -162,7 +163,11 @@ func (h *SearchHandler) SearchHashtags(c *gin.Context) {
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// Build filters for hashtags only
 	filters := services.SearchFilters{
@@ -182,11 +187,10 @@ func (h *SearchHandler) SearchHashtags(c *gin.Context) {
 // GetTrendingHashtags retrieves trending hashtags
 func (h *SearchHandler) GetTrendingHashtags(c *gin.Context) {
 	// Get limit parameter
-	limit := 20
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
-		}
+	limit, err := utils.ParseLimit(c, 20, 100)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
 	}
 
 	// Get time range parameter
@@ -195,7 +199,10 @@ func (h *SearchHandler) GetTrendingHashtags(c *gin.Context) {
 		timeRange = "day"
 	}
 
-	hashtags, err := h.searchService.GetTrendingHashtags(limit, timeRange)
+	// Optional category narrows trending to one topic
+	category := strings.ToLower(c.Query("category"))
+
+	hashtags, err := h.searchService.GetTrendingHashtags(limit, timeRange, category)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get trending hashtags", err)
 		return
@@ -203,6 +210,7 @@ func (h *SearchHandler) GetTrendingHashtags(c *gin.Context) {
 
 	utils.OkResponse(c, "Trending hashtags retrieved successfully", gin.H{
 		"hashtags":   hashtags,
+		"category":   category,
 		"time_range": timeRange,
 		"count":      len(hashtags),
 	})
@@ -222,18 +230,13 @@ func (h *SearchHandler) GetSearchSuggestions(c *gin.Context) {
 	}
 
 	// Get current user ID if authenticated
-	var userID *primitive.ObjectID
-	if uid, exists := c.Get("user_id"); exists {
-		id := uid.(primitive.ObjectID)
-		userID = &id
-	}
+	userID := utils.OptionalUserID(c)
 
 	// Get limit parameter
-	limit := 10
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 20 {
-			limit = l
-		}
+	limit, err := utils.ParseLimit(c, 10, 20)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
 	}
 
 	// For now, we'll use a basic search to get suggestions
@@ -265,9 +268,8 @@ func (h *SearchHandler) GetSearchSuggestions(c *gin.Context) {
 // UpdateHashtagInfo updates hashtag information (internal use)
 func (h *SearchHandler) UpdateHashtagInfo(c *gin.Context) {
 	// This would typically be called internally, but can be exposed for admin use
-	_, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	_, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -303,14 +305,15 @@ func (h *SearchHandler) UpdateHashtagInfo(c *gin.Context) {
 
 // GetSearchHistory retrieves user's search history
 func (h *SearchHandler) GetSearchHistory(c *gin.Context) {
-	_, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	_, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	// Get pagination parameters
-	utils.GetPaginationParams(c)
+	if _, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize); err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	// This would be implemented in the search service
 	// For now, return a placeholder response
@@ -322,9 +325,8 @@ func (h *SearchHandler) GetSearchHistory(c *gin.Context) {
 
 // ClearSearchHistory clears user's search history
 func (h *SearchHandler) ClearSearchHistory(c *gin.Context) {
-	_, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	_, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -336,11 +338,10 @@ func (h *SearchHandler) ClearSearchHistory(c *gin.Context) {
 // GetPopularSearches retrieves popular/trending search queries
 func (h *SearchHandler) GetPopularSearches(c *gin.Context) {
 	// Get limit parameter
-	limit := 10
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 50 {
-			limit = l
-		}
+	limit, err := utils.ParseLimit(c, 10, 50)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
 	}
 
 	// Get time range parameter
@@ -351,7 +352,7 @@ func (h *SearchHandler) GetPopularSearches(c *gin.Context) {
 
 	// This would be implemented in the search service
 	// For now, return trending hashtags as popular searches
-	hashtags, err := h.searchService.GetTrendingHashtags(limit, timeRange)
+	hashtags, err := h.searchService.GetTrendingHashtags(limit, timeRange, "")
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get popular searches", err)
 		return
@@ -372,9 +373,8 @@ func (h *SearchHandler) GetPopularSearches(c *gin.Context) {
 
 // IndexContent manually triggers content indexing (admin only)
 func (h *SearchHandler) IndexContent(c *gin.Context) {
-	_, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	_, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
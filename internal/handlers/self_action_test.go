@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Following, unfollowing, blocking or reporting yourself, and a direct
+// conversation with only yourself, are answered with 400 and a message
+// naming the problem
+func TestSelfActionsAnswerBadRequest(t *testing.T) {
+	h := testutil.NewHarness(t)
+
+	notifications := services.NewNotificationService(nil, nil)
+	limits := services.NewLimitsService(h.DB, time.Minute)
+	follows := NewFollowHandler(services.NewFollowService(h.DB, notifications))
+	users := NewUserHandler(services.NewUserService(h.DB, services.AbuseScorePolicy{}, limits), services.NewSuggestionService(h.DB))
+	reports := NewReportHandler(services.NewReportService(notifications, nil))
+	conversations := NewConversationHandler(services.NewConversationService(10, services.ConversationActivityPolicy{}, models.AccountMaturityPolicy{}, nil), nil, notifications)
+
+	user := h.CreateUser()
+	post := h.CreatePost(user)
+
+	tests := []struct {
+		name    string
+		method  string
+		body    string
+		serve   gin.HandlerFunc
+		message string
+	}{
+		{"follow", http.MethodPost, "", follows.FollowUser, "Cannot follow yourself"},
+		{"unfollow", http.MethodDelete, "", follows.UnfollowUser, "Cannot unfollow yourself"},
+		{"block", http.MethodPost, "", users.BlockUser, "Cannot block yourself"},
+		{"report profile", http.MethodPost, `{"target_type":"user","target_id":"` + user.ID.Hex() + `","reason":"spam"}`, reports.CreateReport, "Cannot report your own content"},
+		{"report post", http.MethodPost, `{"target_type":"post","target_id":"` + post.ID.Hex() + `","reason":"spam"}`, reports.CreateReport, "Cannot report your own content"},
+		{"direct conversation", http.MethodPost, `{"type":"direct","participant_ids":["` + user.ID.Hex() + `"]}`, conversations.CreateConversation, "Cannot start a direct conversation with yourself"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			c, rec := h.AuthenticatedContext(user, tt.method, "/", body)
+			c.Params = gin.Params{{Key: "id", Value: user.ID.Hex()}}
+
+			tt.serve(c)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusBadRequest, rec.Body.String())
+			}
+			var response utils.Response
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if response.Message != tt.message {
+				t.Errorf("message = %q, want %q", response.Message, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// internal/handlers/setup_checklist.go
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type SetupChecklistHandler struct {
+	setupChecklistService *services.SetupChecklistService
+	validator             *validator.Validate
+}
+
+func NewSetupChecklistHandler(setupChecklistService *services.SetupChecklistService) *SetupChecklistHandler {
+	return &SetupChecklistHandler{
+		setupChecklistService: setupChecklistService,
+		validator:             validator.New(),
+	}
+}
+
+// GetSetupChecklist returns the current user's profile setup checklist
+func (h *SetupChecklistHandler) GetSetupChecklist(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	checklist, err := h.setupChecklistService.GetChecklist(userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "User not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get setup checklist", err)
+		return
+	}
+
+	// Progress changes slowly; let clients reuse the checklist for a few minutes
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", int(services.SetupChecklistCacheMaxAge.Seconds())))
+
+	utils.OkResponse(c, "Setup checklist retrieved successfully", checklist)
+}
+
+// GetSetupChecklistConfig returns the checklist configuration for admins
+func (h *SetupChecklistHandler) GetSetupChecklistConfig(c *gin.Context) {
+	utils.OkResponse(c, "Setup checklist configuration retrieved successfully", h.setupChecklistService.GetConfig())
+}
+
+// UpdateSetupChecklistConfig replaces the checklist configuration
+func (h *SetupChecklistHandler) UpdateSetupChecklistConfig(c *gin.Context) {
+	adminID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateSetupChecklistConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	config, err := h.setupChecklistService.UpdateConfig(adminID, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			utils.BadRequestResponse(c, err.Error(), nil)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to update setup checklist configuration", err)
+		return
+	}
+
+	utils.OkResponse(c, "Setup checklist configuration updated successfully", config)
+}
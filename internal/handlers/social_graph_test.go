@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// TestHiddenSocialGraphListEndpoints calls every follow list endpoint for a
+// user who hides their social graph and checks others get the counts only,
+// with no member of the graph anywhere in the response
+func TestHiddenSocialGraphListEndpoints(t *testing.T) {
+	h := testutil.NewHarness(t)
+	handler := NewFollowHandler(services.NewFollowService(h.DB, services.NewNotificationService(nil, nil)))
+
+	owner := h.CreateUser()
+	follower := h.CreateUser()
+	followee := h.CreateUser()
+	viewer := h.CreateUser()
+	h.CreateFollow(follower, owner, models.FollowStatusAccepted)
+	h.CreateFollow(owner, followee, models.FollowStatusAccepted)
+	h.CreateFollow(viewer, followee, models.FollowStatusAccepted)
+
+	if _, err := h.DB.Collection("users").UpdateOne(h.Context(),
+		bson.M{"_id": owner.ID},
+		bson.M{"$set": bson.M{"privacy_settings.hide_social_graph": true}},
+	); err != nil {
+		t.Fatalf("hiding the social graph: %v", err)
+	}
+
+	endpoints := []struct {
+		name      string
+		serve     gin.HandlerFunc
+		anonymous bool // Reachable without signing in
+	}{
+		{"followers", handler.GetFollowers, true},
+		{"following", handler.GetFollowing, true},
+		{"mutual follows", handler.GetMutualFollows, false},
+	}
+
+	call := func(user *models.User, serve gin.HandlerFunc) *httptest.ResponseRecorder {
+		var c *gin.Context
+		var recorder *httptest.ResponseRecorder
+		if user == nil {
+			c, recorder = h.AnonymousContext(http.MethodGet, "/"+owner.ID.Hex(), nil)
+		} else {
+			c, recorder = h.AuthenticatedContext(user, http.MethodGet, "/"+owner.ID.Hex(), nil)
+		}
+		c.Params = gin.Params{{Key: "id", Value: owner.ID.Hex()}}
+		serve(c)
+		return recorder
+	}
+
+	for _, endpoint := range endpoints {
+		viewers := map[string]*models.User{"another user": viewer, "a follower": follower}
+		if endpoint.anonymous {
+			viewers["an anonymous viewer"] = nil
+		}
+
+		for name, user := range viewers {
+			recorder := call(user, endpoint.serve)
+			if recorder.Code != http.StatusOK {
+				t.Errorf("%s for %s = %d, want 200: %s", endpoint.name, name, recorder.Code, recorder.Body.String())
+				continue
+			}
+
+			var response struct {
+				Data models.FollowStatsResponse `json:"data"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatalf("%s for %s: response isn't JSON: %v", endpoint.name, name, err)
+			}
+			if !response.Data.SocialGraphHidden || response.Data.FollowersCount != 1 || response.Data.FollowingCount != 1 {
+				t.Errorf("%s for %s = %+v, want the counts flagged social_graph_hidden", endpoint.name, name, response.Data)
+			}
+
+			body := recorder.Body.String()
+			for _, member := range []*models.User{follower, followee} {
+				if member != user && (strings.Contains(body, member.ID.Hex()) || strings.Contains(body, member.Username)) {
+					t.Errorf("%s for %s leaks %s", endpoint.name, name, member.Username)
+				}
+			}
+		}
+	}
+
+	// The owner still sees their own lists
+	lists := []struct {
+		serve  gin.HandlerFunc
+		member *models.User
+	}{
+		{handler.GetFollowers, follower},
+		{handler.GetFollowing, followee},
+	}
+	for _, list := range lists {
+		recorder := call(owner, list.serve)
+		if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), list.member.ID.Hex()) {
+			t.Errorf("owner's own list = %d without %s: %s", recorder.Code, list.member.Username, recorder.Body.String())
+		}
+		if strings.Contains(recorder.Body.String(), "social_graph_hidden") {
+			t.Error("owner's own list is flagged social_graph_hidden")
+		}
+	}
+}
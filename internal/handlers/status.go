@@ -0,0 +1,206 @@
+// internal/handlers/status.go
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type StatusHandler struct {
+	statusService *services.StatusService
+	validator     *validator.Validate
+}
+
+func NewStatusHandler(statusService *services.StatusService) *StatusHandler {
+	return &StatusHandler{
+		statusService: statusService,
+		validator:     validator.New(),
+	}
+}
+
+// GetStatusPage returns the public status page. It never fails: when history
+// can't be loaded the last snapshot is returned marked stale.
+func (h *StatusHandler) GetStatusPage(c *gin.Context) {
+	page := h.statusService.GetStatusPage()
+
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(h.statusService.CacheTTL().Seconds())))
+	utils.OkResponse(c, "Status retrieved successfully", page)
+}
+
+// Admin handlers
+
+// GetIncidents lists incidents, optionally filtered by status
+func (h *StatusHandler) GetIncidents(c *gin.Context) {
+	status := models.IncidentStatus(c.Query("status"))
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	incidents, err := h.statusService.GetIncidents(status, params.Limit, params.Offset)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get incidents", err)
+		return
+	}
+
+	paginationMeta := utils.CreatePaginationMeta(params, int64(len(incidents)))
+	utils.PaginatedSuccessResponse(c, "Incidents retrieved successfully", incidents, paginationMeta, nil)
+}
+
+// GetIncident returns one incident with its full timeline
+func (h *StatusHandler) GetIncident(c *gin.Context) {
+	incidentID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid incident ID", err)
+		return
+	}
+
+	incident, err := h.statusService.GetIncident(incidentID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Incident not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get incident", err)
+		return
+	}
+
+	utils.OkResponse(c, "Incident retrieved successfully", incident)
+}
+
+// CreateIncident opens an incident on the status page
+func (h *StatusHandler) CreateIncident(c *gin.Context) {
+	adminID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	incident, err := h.statusService.CreateIncident(adminID, req)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to create incident", err)
+		return
+	}
+
+	utils.CreatedResponse(c, "Incident created successfully", incident)
+}
+
+// UpdateIncident corrects an incident's title, severity or affected components
+func (h *StatusHandler) UpdateIncident(c *gin.Context) {
+	incidentID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid incident ID", err)
+		return
+	}
+
+	var req models.UpdateIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	incident, err := h.statusService.UpdateIncident(incidentID, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Incident not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to update incident", err)
+		return
+	}
+
+	utils.OkResponse(c, "Incident updated successfully", incident)
+}
+
+// AddIncidentUpdate posts to an incident's timeline
+func (h *StatusHandler) AddIncidentUpdate(c *gin.Context) {
+	var req models.AddIncidentUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	h.updateIncident(c, func(incidentID, adminID primitive.ObjectID) (*models.Incident, error) {
+		return h.statusService.AddIncidentUpdate(incidentID, adminID, req)
+	})
+}
+
+// ResolveIncident resolves an incident with a final update
+func (h *StatusHandler) ResolveIncident(c *gin.Context) {
+	var req models.ResolveIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	h.updateIncident(c, func(incidentID, adminID primitive.ObjectID) (*models.Incident, error) {
+		return h.statusService.ResolveIncident(incidentID, adminID, req)
+	})
+}
+
+// Helper methods
+
+// updateIncident runs a timeline update and maps its errors to responses
+func (h *StatusHandler) updateIncident(c *gin.Context, update func(incidentID, adminID primitive.ObjectID) (*models.Incident, error)) {
+	adminID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	incidentID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid incident ID", err)
+		return
+	}
+
+	incident, err := update(incidentID, adminID)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			utils.NotFoundResponse(c, "Incident not found")
+		case strings.Contains(err.Error(), "already resolved"):
+			utils.ConflictResponse(c, err.Error(), nil)
+		case strings.Contains(err.Error(), "limited to"):
+			utils.BadRequestResponse(c, err.Error(), nil)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to update incident", err)
+		}
+		return
+	}
+
+	utils.OkResponse(c, "Incident updated successfully", incident)
+}
@@ -28,9 +28,8 @@ func NewStoryHandler(storyService *services.StoryService) *StoryHandler {
 
 // CreateStory creates a new story
 func (h *StoryHandler) CreateStory(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -39,6 +38,7 @@ func (h *StoryHandler) CreateStory(c *gin.Context) {
 		utils.BadRequestResponse(c, "Invalid request format", err)
 		return
 	}
+	req.DelegateID = actingDelegate(c)
 
 	if err := h.validator.Struct(req); err != nil {
 		utils.ValidationErrorResponse(c, err)
@@ -51,8 +51,18 @@ func (h *StoryHandler) CreateStory(c *gin.Context) {
 		return
 	}
 
-	story, err := h.storyService.CreateStory(userID.(primitive.ObjectID), req)
+	story, err := h.storyService.CreateStory(userID, req)
 	if err != nil {
+		if respondBoundExceeded(c, err) {
+			return
+		}
+		if respondLimitExceeded(c, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			utils.BadRequestResponse(c, err.Error(), nil)
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to create story", err)
 		return
 	}
@@ -70,11 +80,7 @@ func (h *StoryHandler) GetStory(c *gin.Context) {
 	}
 
 	// Get current user ID if authenticated
-	var currentUserID *primitive.ObjectID
-	if userID, exists := c.Get("user_id"); exists {
-		uid := userID.(primitive.ObjectID)
-		currentUserID = &uid
-	}
+	currentUserID := utils.OptionalUserID(c)
 
 	story, err := h.storyService.GetStoryByID(storyID, currentUserID)
 	if err != nil {
@@ -99,11 +105,7 @@ func (h *StoryHandler) GetUserStories(c *gin.Context) {
 	}
 
 	// Get current user ID if authenticated
-	var currentUserID *primitive.ObjectID
-	if uid, exists := c.Get("user_id"); exists {
-		id := uid.(primitive.ObjectID)
-		currentUserID = &id
-	}
+	currentUserID := utils.OptionalUserID(c)
 
 	stories, err := h.storyService.GetUserStories(userID, currentUserID)
 	if err != nil {
@@ -126,13 +128,12 @@ func (h *StoryHandler) GetUserStories(c *gin.Context) {
 
 // GetFollowingStories retrieves stories from users that current user follows
 func (h *StoryHandler) GetFollowingStories(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	stories, err := h.storyService.GetFollowingStories(userID.(primitive.ObjectID))
+	stories, err := h.storyService.GetFollowingStories(userID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get following stories", err)
 		return
@@ -172,9 +173,8 @@ func (h *StoryHandler) GetFollowingStories(c *gin.Context) {
 
 // UpdateStory updates an existing story (limited fields)
 func (h *StoryHandler) UpdateStory(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -192,7 +192,7 @@ func (h *StoryHandler) UpdateStory(c *gin.Context) {
 		return
 	}
 
-	story, err := h.storyService.UpdateStory(storyID, userID.(primitive.ObjectID), req)
+	story, err := h.storyService.UpdateStory(storyID, userID, req)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Story not found or access denied")
@@ -207,9 +207,8 @@ func (h *StoryHandler) UpdateStory(c *gin.Context) {
 
 // DeleteStory deletes a story
 func (h *StoryHandler) DeleteStory(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -220,7 +219,7 @@ func (h *StoryHandler) DeleteStory(c *gin.Context) {
 		return
 	}
 
-	err = h.storyService.DeleteStory(storyID, userID.(primitive.ObjectID))
+	err = h.storyService.DeleteStory(storyID, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Story not found or access denied")
@@ -235,9 +234,8 @@ func (h *StoryHandler) DeleteStory(c *gin.Context) {
 
 // ViewStory marks a story as viewed by the current user
 func (h *StoryHandler) ViewStory(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -248,7 +246,7 @@ func (h *StoryHandler) ViewStory(c *gin.Context) {
 		return
 	}
 
-	err = h.storyService.ViewStory(storyID, userID.(primitive.ObjectID))
+	err = h.storyService.ViewStory(storyID, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "Story not found")
@@ -264,11 +262,40 @@ func (h *StoryHandler) ViewStory(c *gin.Context) {
 	})
 }
 
+// ReportScreenshot records that the viewer took a screenshot of a story
+func (h *StoryHandler) ReportScreenshot(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	storyIDStr := c.Param("id")
+	storyID, err := primitive.ObjectIDFromHex(storyIDStr)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid story ID format", err)
+		return
+	}
+
+	err = h.storyService.ReportScreenshot(storyID, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Story not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to record screenshot", err)
+		return
+	}
+
+	utils.OkResponse(c, "Screenshot recorded", gin.H{
+		"story_id":   storyIDStr,
+		"screenshot": true,
+	})
+}
+
 // GetStoryViews retrieves viewers of a story
 func (h *StoryHandler) GetStoryViews(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -280,9 +307,13 @@ func (h *StoryHandler) GetStoryViews(c *gin.Context) {
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
-	views, err := h.storyService.GetStoryViews(storyID, userID.(primitive.ObjectID), params.Limit, params.Offset)
+	views, err := h.storyService.GetStoryViews(storyID, userID, params.Limit, params.Offset)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Story not found or access denied")
@@ -300,9 +331,8 @@ func (h *StoryHandler) GetStoryViews(c *gin.Context) {
 
 // ReactToStory adds a reaction to a story
 func (h *StoryHandler) ReactToStory(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -322,7 +352,7 @@ func (h *StoryHandler) ReactToStory(c *gin.Context) {
 		return
 	}
 
-	err = h.storyService.ReactToStory(storyID, userID.(primitive.ObjectID), req.ReactionType)
+	err = h.storyService.ReactToStory(storyID, userID, req.ReactionType)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "Story not found")
@@ -332,6 +362,10 @@ func (h *StoryHandler) ReactToStory(c *gin.Context) {
 			utils.BadRequestResponse(c, err.Error(), nil)
 			return
 		}
+		if strings.Contains(err.Error(), "invalid reaction") {
+			utils.BadRequestResponse(c, "Invalid reaction type", err)
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to react to story", err)
 		return
 	}
@@ -344,9 +378,8 @@ func (h *StoryHandler) ReactToStory(c *gin.Context) {
 
 // UnreactToStory removes a reaction from a story
 func (h *StoryHandler) UnreactToStory(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -357,7 +390,7 @@ func (h *StoryHandler) UnreactToStory(c *gin.Context) {
 		return
 	}
 
-	err = h.storyService.UnreactToStory(storyID, userID.(primitive.ObjectID))
+	err = h.storyService.UnreactToStory(storyID, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "Story not found")
@@ -375,9 +408,8 @@ func (h *StoryHandler) UnreactToStory(c *gin.Context) {
 
 // GetStoryReactions retrieves reactions to a story
 func (h *StoryHandler) GetStoryReactions(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -389,9 +421,13 @@ func (h *StoryHandler) GetStoryReactions(c *gin.Context) {
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
-	reactions, err := h.storyService.GetStoryReactions(storyID, userID.(primitive.ObjectID), params.Limit, params.Offset)
+	reactions, err := h.storyService.GetStoryReactions(storyID, userID, params.Limit, params.Offset)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Story not found or access denied")
@@ -409,9 +445,8 @@ func (h *StoryHandler) GetStoryReactions(c *gin.Context) {
 
 // GetStoryStats retrieves story statistics
 func (h *StoryHandler) GetStoryStats(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -422,7 +457,7 @@ func (h *StoryHandler) GetStoryStats(c *gin.Context) {
 		return
 	}
 
-	stats, err := h.storyService.GetStoryStats(storyID, userID.(primitive.ObjectID))
+	stats, err := h.storyService.GetStoryStats(storyID, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Story not found or access denied")
@@ -435,17 +470,132 @@ func (h *StoryHandler) GetStoryStats(c *gin.Context) {
 	utils.OkResponse(c, "Story statistics retrieved successfully", stats)
 }
 
+// RespondToSticker records the caller's response to a poll, question, quiz or slider sticker
+func (h *StoryHandler) RespondToSticker(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	storyID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid story ID format", err)
+		return
+	}
+
+	stickerID, err := primitive.ObjectIDFromHex(c.Param("stickerId"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid sticker ID format", err)
+		return
+	}
+
+	var req models.RespondToStickerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	response, err := h.storyService.RespondToSticker(storyID, userID, stickerID, req)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied"):
+			utils.NotFoundResponse(c, "Story or sticker not found")
+		case strings.Contains(err.Error(), "already answered"):
+			utils.ConflictResponse(c, "You have already responded to this sticker", err)
+		case strings.Contains(err.Error(), "expired"),
+			strings.Contains(err.Error(), "invalid response"),
+			strings.Contains(err.Error(), "your own story"):
+			utils.BadRequestResponse(c, err.Error(), nil)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to respond to sticker", err)
+		}
+		return
+	}
+
+	utils.CreatedResponse(c, "Sticker response recorded successfully", response)
+}
+
+// GetStickerInsights returns poll percentages, quiz correct rates and slider
+// averages for the author's story
+func (h *StoryHandler) GetStickerInsights(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	storyID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid story ID format", err)
+		return
+	}
+
+	insights, err := h.storyService.GetStickerInsights(storyID, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
+			utils.NotFoundResponse(c, "Story not found or access denied")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get sticker insights", err)
+		return
+	}
+
+	utils.OkResponse(c, "Sticker insights retrieved successfully", insights)
+}
+
+// GetStickerResponses lists the individual responses to one of the author's stickers
+func (h *StoryHandler) GetStickerResponses(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	storyID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid story ID format", err)
+		return
+	}
+
+	stickerID, err := primitive.ObjectIDFromHex(c.Param("stickerId"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid sticker ID format", err)
+		return
+	}
+
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	responses, total, err := h.storyService.GetStickerResponses(storyID, userID, stickerID, params.Limit, params.Offset)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
+			utils.NotFoundResponse(c, "Story or sticker not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get sticker responses", err)
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, "Sticker responses retrieved successfully", responses, utils.CreatePaginationMeta(params, total), nil)
+}
+
 // GetActiveStories retrieves currently active stories from all users
 func (h *StoryHandler) GetActiveStories(c *gin.Context) {
 	// Get current user ID if authenticated
-	var currentUserID *primitive.ObjectID
-	if userID, exists := c.Get("user_id"); exists {
-		uid := userID.(primitive.ObjectID)
-		currentUserID = &uid
-	}
+	currentUserID := utils.OptionalUserID(c)
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	stories, err := h.storyService.GetActiveStories(currentUserID, params.Limit, params.Offset)
 	if err != nil {
@@ -487,9 +637,8 @@ func (h *StoryHandler) GetActiveStories(c *gin.Context) {
 
 // ArchiveStory archives a story
 func (h *StoryHandler) ArchiveStory(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -500,7 +649,7 @@ func (h *StoryHandler) ArchiveStory(c *gin.Context) {
 		return
 	}
 
-	err = h.storyService.ArchiveStory(storyID, userID.(primitive.ObjectID))
+	err = h.storyService.ArchiveStory(storyID, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "Story not found or access denied")
@@ -518,16 +667,19 @@ func (h *StoryHandler) ArchiveStory(c *gin.Context) {
 
 // GetArchivedStories retrieves user's archived stories
 func (h *StoryHandler) GetArchivedStories(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
-	stories, err := h.storyService.GetArchivedStories(userID.(primitive.ObjectID), params.Limit, params.Offset)
+	stories, err := h.storyService.GetArchivedStories(userID, params.Limit, params.Offset)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get archived stories", err)
 		return
@@ -549,9 +701,8 @@ func (h *StoryHandler) GetArchivedStories(c *gin.Context) {
 
 // CreateStoryHighlight creates a new story highlight
 func (h *StoryHandler) CreateStoryHighlight(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -566,8 +717,11 @@ func (h *StoryHandler) CreateStoryHighlight(c *gin.Context) {
 		return
 	}
 
-	highlight, err := h.storyService.CreateStoryHighlight(userID.(primitive.ObjectID), req)
+	highlight, err := h.storyService.CreateStoryHighlight(userID, req)
 	if err != nil {
+		if respondBoundExceeded(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied") {
 			utils.BadRequestResponse(c, err.Error(), nil)
 			return
@@ -603,9 +757,8 @@ func (h *StoryHandler) GetUserStoryHighlights(c *gin.Context) {
 
 // UpdateStoryHighlight updates an existing story highlight
 func (h *StoryHandler) UpdateStoryHighlight(c *gin.Context) {
-	_, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	_, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -628,9 +781,8 @@ func (h *StoryHandler) UpdateStoryHighlight(c *gin.Context) {
 
 // DeleteStoryHighlight deletes a story highlight
 func (h *StoryHandler) DeleteStoryHighlight(c *gin.Context) {
-	_, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	_, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
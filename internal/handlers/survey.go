@@ -0,0 +1,228 @@
+// internal/handlers/survey.go
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SurveyHandler struct {
+	surveyService *services.SurveyService
+	validator     *validator.Validate
+}
+
+func NewSurveyHandler(surveyService *services.SurveyService) *SurveyHandler {
+	return &SurveyHandler{
+		surveyService: surveyService,
+		validator:     validator.New(),
+	}
+}
+
+// GetPendingSurveys retrieves surveys waiting for the current user
+func (h *SurveyHandler) GetPendingSurveys(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	surveys, err := h.surveyService.GetPendingSurveys(userID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get pending surveys", err)
+		return
+	}
+
+	utils.OkResponse(c, "Pending surveys retrieved successfully", surveys)
+}
+
+// SubmitResponse records the current user's answers to a survey
+func (h *SurveyHandler) SubmitResponse(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	surveyID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid survey ID format", err)
+		return
+	}
+
+	var req models.SubmitSurveyResponseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	err = h.surveyService.SubmitResponse(surveyID, userID, req.Answers)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			utils.NotFoundResponse(c, "Survey not found")
+		case strings.Contains(err.Error(), "already answered"):
+			utils.ConflictResponse(c, "You have already answered this survey", err)
+		case strings.Contains(err.Error(), "closed"):
+			utils.BadRequestResponse(c, "Survey is closed", err)
+		case strings.Contains(err.Error(), "answer") || strings.Contains(err.Error(), "question"):
+			utils.BadRequestResponse(c, err.Error(), nil)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to submit survey response", err)
+		}
+		return
+	}
+
+	utils.CreatedResponse(c, "Survey response submitted successfully", nil)
+}
+
+// Admin handlers
+
+// CreateSurvey creates a new survey
+func (h *SurveyHandler) CreateSurvey(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateSurveyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	survey, err := h.surveyService.CreateSurvey(userID, req)
+	if err != nil {
+		if respondBoundExceeded(c, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "options") ||
+			strings.Contains(err.Error(), "questions") || strings.Contains(err.Error(), "end time") {
+			utils.BadRequestResponse(c, err.Error(), nil)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to create survey", err)
+		return
+	}
+
+	utils.CreatedResponse(c, "Survey created successfully", survey)
+}
+
+// GetSurveys retrieves surveys with pagination
+func (h *SurveyHandler) GetSurveys(c *gin.Context) {
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	surveys, total, err := h.surveyService.GetSurveys(c.Query("status"), params.Limit, params.Offset)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get surveys", err)
+		return
+	}
+
+	pagination := utils.CreatePaginationMeta(params, total)
+	utils.PaginatedSuccessResponse(c, "Surveys retrieved successfully", surveys, pagination, utils.CreatePaginationLinks(c, pagination))
+}
+
+// GetSurvey retrieves a single survey
+func (h *SurveyHandler) GetSurvey(c *gin.Context) {
+	surveyID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid survey ID format", err)
+		return
+	}
+
+	survey, err := h.surveyService.GetSurveyByID(surveyID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Survey not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get survey", err)
+		return
+	}
+
+	utils.OkResponse(c, "Survey retrieved successfully", survey)
+}
+
+// CloseSurvey closes a survey early
+func (h *SurveyHandler) CloseSurvey(c *gin.Context) {
+	surveyID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid survey ID format", err)
+		return
+	}
+
+	if err := h.surveyService.CloseSurvey(surveyID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Survey not found or already closed")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to close survey", err)
+		return
+	}
+
+	utils.OkResponse(c, "Survey closed successfully", nil)
+}
+
+// GetSurveyResults retrieves aggregated survey results
+func (h *SurveyHandler) GetSurveyResults(c *gin.Context) {
+	surveyID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid survey ID format", err)
+		return
+	}
+
+	results, err := h.surveyService.GetSurveyResults(surveyID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Survey not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get survey results", err)
+		return
+	}
+
+	utils.OkResponse(c, "Survey results retrieved successfully", results)
+}
+
+// ExportFreeTextResponses downloads free-text answers as CSV
+func (h *SurveyHandler) ExportFreeTextResponses(c *gin.Context) {
+	surveyID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid survey ID format", err)
+		return
+	}
+
+	data, err := h.surveyService.ExportFreeTextResponses(surveyID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "Survey not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to export survey responses", err)
+		return
+	}
+
+	filename := "survey_" + surveyID.Hex() + "_responses_" + time.Now().Format("20060102_150405") + ".csv"
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+}
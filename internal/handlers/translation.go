@@ -0,0 +1,79 @@
+// internal/handlers/translation.go
+package handlers
+
+import (
+	"strings"
+
+	"social-media-api/internal/services"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type TranslationHandler struct {
+	translationService *services.TranslationService
+}
+
+func NewTranslationHandler(translationService *services.TranslationService) *TranslationHandler {
+	return &TranslationHandler{
+		translationService: translationService,
+	}
+}
+
+// TranslatePost translates a post into the current user's language
+func (h *TranslationHandler) TranslatePost(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	postID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid post ID format", err)
+		return
+	}
+
+	result, err := h.translationService.TranslatePost(postID, userID)
+	if err != nil {
+		h.handleTranslationError(c, "Post", err)
+		return
+	}
+
+	utils.OkResponse(c, "Post translated successfully", result)
+}
+
+// TranslateComment translates a comment into the current user's language
+func (h *TranslationHandler) TranslateComment(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	commentID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid comment ID format", err)
+		return
+	}
+
+	result, err := h.translationService.TranslateComment(commentID, userID)
+	if err != nil {
+		h.handleTranslationError(c, "Comment", err)
+		return
+	}
+
+	utils.OkResponse(c, "Comment translated successfully", result)
+}
+
+func (h *TranslationHandler) handleTranslationError(c *gin.Context, contentName string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied"):
+		utils.NotFoundResponse(c, contentName+" not found")
+	case strings.Contains(err.Error(), "too long") || strings.Contains(err.Error(), "nothing to translate"):
+		utils.BadRequestResponse(c, err.Error(), nil)
+	case strings.Contains(err.Error(), "translation"):
+		utils.ServiceUnavailableResponse(c, "Translation service unavailable")
+	default:
+		utils.InternalServerErrorResponse(c, "Failed to translate "+strings.ToLower(contentName), err)
+	}
+}
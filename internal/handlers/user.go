@@ -2,6 +2,9 @@
 package handlers
 
 import (
+	"errors"
+	"net/http"
+	"strconv"
 	"strings"
 
 	"social-media-api/internal/models"
@@ -14,14 +17,16 @@ import (
 )
 
 type UserHandler struct {
-	userService *services.UserService
-	validator   *validator.Validate
+	userService       *services.UserService
+	suggestionService *services.SuggestionService
+	validator         *validator.Validate
 }
 
-func NewUserHandler(userService *services.UserService) *UserHandler {
+func NewUserHandler(userService *services.UserService, suggestionService *services.SuggestionService) *UserHandler {
 	return &UserHandler{
-		userService: userService,
-		validator:   validator.New(),
+		userService:       userService,
+		suggestionService: suggestionService,
+		validator:         validator.New(),
 	}
 }
 
@@ -35,12 +40,9 @@ func (h *UserHandler) GetUserProfile(c *gin.Context) {
 	}
 
 	// Get current user ID if authenticated
-	var currentUserID primitive.ObjectID
-	if uid, exists := c.Get("user_id"); exists {
-		currentUserID = uid.(primitive.ObjectID)
-	}
+	currentUserID, _ := utils.LookupUserID(c)
 
-	profile, err := h.userService.GetUserProfile(userID, currentUserID)
+	user, err := h.userService.GetUserByID(userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundResponse(c, "User not found")
@@ -50,7 +52,11 @@ func (h *UserHandler) GetUserProfile(c *gin.Context) {
 		return
 	}
 
-	utils.OkResponse(c, "User profile retrieved successfully", profile)
+	if utils.CheckETag(c, h.userService.ProfileETag(user, currentUserID)) {
+		return
+	}
+
+	utils.OkResponse(c, "User profile retrieved successfully", h.userService.BuildUserProfile(user, currentUserID))
 }
 
 // GetUserByUsername retrieves user profile by username
@@ -72,10 +78,7 @@ func (h *UserHandler) GetUserByUsername(c *gin.Context) {
 	}
 
 	// Get current user ID if authenticated for context
-	var currentUserID primitive.ObjectID
-	if uid, exists := c.Get("user_id"); exists {
-		currentUserID = uid.(primitive.ObjectID)
-	}
+	currentUserID, _ := utils.LookupUserID(c)
 
 	// Get full profile with context
 	profile, err := h.userService.GetUserProfile(user.ID, currentUserID)
@@ -89,9 +92,8 @@ func (h *UserHandler) GetUserByUsername(c *gin.Context) {
 
 // UpdateProfile updates user profile
 func (h *UserHandler) UpdateProfile(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -101,32 +103,227 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	// Validate bio length if provided
-	if req.Bio != nil && len(*req.Bio) > utils.MaxBioLength {
-		utils.BadRequestResponse(c, "Bio exceeds maximum length", nil)
-		return
-	}
-
 	// Validate display name length if provided
 	if req.DisplayName != nil && len(*req.DisplayName) > utils.MaxDisplayNameLength {
 		utils.BadRequestResponse(c, "Display name exceeds maximum length", nil)
 		return
 	}
 
-	user, err := h.userService.UpdateUser(userID.(primitive.ObjectID), req)
+	// Validate preferred content languages if provided
+	if req.PreferredLanguages != nil {
+		if err := h.validator.Var(req.PreferredLanguages, "max=10,dive,min=2,max=3,alpha"); err != nil {
+			utils.BadRequestResponse(c, "Preferred languages must be at most 10 ISO 639 language codes", nil)
+			return
+		}
+	}
+
+	if req.FeedAlgorithm != nil && *req.FeedAlgorithm != "default" && !models.IsValidFeedAlgorithm(*req.FeedAlgorithm) {
+		utils.BadRequestResponse(c, "Feed algorithm must be default, chronological, standard, behavior or hybrid", nil)
+		return
+	}
+
+	user, err := h.userService.UpdateUser(userID, req)
 	if err != nil {
+		if respondLimitExceeded(c, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "under-age") {
+			utils.ForbiddenResponse(c, "Sensitive content is not available for this account")
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to update profile", err)
 		return
 	}
 
-	utils.ProfileUpdateSuccessResponse(c, user.ToUserResponse())
+	utils.ProfileUpdateSuccessResponse(c, user.ToOwnUserResponse())
+}
+
+// UpdateAutoDeletePolicy sets how many days the user's posts are kept before
+// they are deleted automatically
+func (h *UserHandler) UpdateAutoDeletePolicy(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateAutoDeleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	user, err := h.userService.UpdateAutoDeletePolicy(userID, req)
+	if err != nil {
+		var confirmErr *models.AutoDeleteConfirmationError
+		if errors.As(err, &confirmErr) {
+			utils.ErrorResponseWithDetails(c, http.StatusConflict, "Existing posts would be deleted; send the change again with confirm set", utils.ErrorCodeConfirmationRequired, confirmErr)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to update auto-delete setting", err)
+		return
+	}
+
+	message := "Posts will be deleted automatically after " + strconv.Itoa(req.AfterDays) + " days"
+	if req.AfterDays == 0 {
+		message = "Automatic post deletion turned off"
+	}
+	utils.OkResponse(c, message, user.ToOwnUserResponse())
+}
+
+// GetAvailableInterests lists the hashtag categories users can pick as interests
+func (h *UserHandler) GetAvailableInterests(c *gin.Context) {
+	utils.OkResponse(c, "Available interests retrieved successfully", gin.H{
+		"interests":     models.GetHashtagCategories(),
+		"max_interests": models.MaxUserInterests,
+	})
+}
+
+// GetInterests returns the current user's interests
+func (h *UserHandler) GetInterests(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.GetUserByID(userID)
+	if err != nil {
+		utils.NotFoundResponse(c, "User not found")
+		return
+	}
+
+	interests := user.Interests
+	if interests == nil {
+		interests = []string{}
+	}
+	utils.OkResponse(c, "Interests retrieved successfully", gin.H{"interests": interests})
+}
+
+// SetInterests replaces the current user's interests, seeding feed
+// personalization before they have engagement history
+func (h *UserHandler) SetInterests(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.SetInterestsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	user, err := h.userService.SetInterests(userID, req.Interests)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid interest") {
+			utils.BadRequestResponse(c, err.Error(), err)
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "User not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to update interests", err)
+		return
+	}
+
+	utils.OkResponse(c, "Interests updated successfully", gin.H{"interests": user.Interests})
+}
+
+// GetFeaturedComments returns the comments a user features on their profile
+func (h *UserHandler) GetFeaturedComments(c *gin.Context) {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID format", err)
+		return
+	}
+
+	comments, err := h.userService.GetFeaturedComments(userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "User not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get featured comments", err)
+		return
+	}
+
+	utils.OkResponse(c, "Featured comments retrieved successfully", gin.H{
+		"comments":     comments,
+		"max_featured": models.MaxFeaturedComments,
+	})
+}
+
+// FeatureComment features a comment on one of the current user's posts on their profile
+func (h *UserHandler) FeatureComment(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	commentID, err := primitive.ObjectIDFromHex(c.Param("commentId"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid comment ID format", err)
+		return
+	}
+
+	if err := h.userService.FeatureComment(userID, commentID); err != nil {
+		switch {
+		case strings.Contains(err.Error(), "access denied"):
+			utils.ForbiddenResponse(c, "You can only feature comments on your own posts")
+		case strings.Contains(err.Error(), "not found"):
+			utils.NotFoundResponse(c, "Comment not found")
+		case strings.Contains(err.Error(), "already featured"):
+			utils.ConflictResponse(c, "Comment is already featured", err)
+		case strings.Contains(err.Error(), "limit reached"):
+			utils.BadRequestResponse(c, err.Error(), err)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to feature comment", err)
+		}
+		return
+	}
+
+	utils.OkResponse(c, "Comment featured successfully", nil)
+}
+
+// UnfeatureComment removes a comment from the current user's featured comments
+func (h *UserHandler) UnfeatureComment(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	commentID, err := primitive.ObjectIDFromHex(c.Param("commentId"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid comment ID format", err)
+		return
+	}
+
+	if err := h.userService.UnfeatureComment(userID, commentID); err != nil {
+		if strings.Contains(err.Error(), "not featured") {
+			utils.NotFoundResponse(c, "Comment is not featured")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to unfeature comment", err)
+		return
+	}
+
+	utils.OkResponse(c, "Comment unfeatured successfully", nil)
 }
 
 // UpdatePrivacySettings updates user privacy settings
 func (h *UserHandler) UpdatePrivacySettings(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -136,7 +333,27 @@ func (h *UserHandler) UpdatePrivacySettings(c *gin.Context) {
 		return
 	}
 
-	err := h.userService.UpdateUserPrivacySettings(userID.(primitive.ObjectID), req)
+	if req.WhoCanMessage == "" {
+		// Clients that only send allow_messages keep meaning what it says
+		req.WhoCanMessage = req.MessagePermission()
+	}
+	if !models.IsValidMessagePermission(req.WhoCanMessage) {
+		utils.BadRequestResponse(c, "Invalid who_can_message value", nil)
+		return
+	}
+
+	if req.DefaultPostVisibility == "" {
+		req.DefaultPostVisibility = models.PrivacyPublic
+	}
+	if req.DefaultStoryVisibility == "" {
+		req.DefaultStoryVisibility = models.PrivacyPublic
+	}
+	if !models.IsValidPrivacyLevel(req.DefaultPostVisibility) || !models.IsValidPrivacyLevel(req.DefaultStoryVisibility) {
+		utils.BadRequestResponse(c, "Invalid default visibility value", nil)
+		return
+	}
+
+	err := h.userService.UpdateUserPrivacySettings(userID, req)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to update privacy settings", err)
 		return
@@ -147,9 +364,8 @@ func (h *UserHandler) UpdatePrivacySettings(c *gin.Context) {
 
 // UpdateNotificationSettings updates user notification settings
 func (h *UserHandler) UpdateNotificationSettings(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -159,7 +375,7 @@ func (h *UserHandler) UpdateNotificationSettings(c *gin.Context) {
 		return
 	}
 
-	err := h.userService.UpdateNotificationSettings(userID.(primitive.ObjectID), req)
+	err := h.userService.UpdateNotificationSettings(userID, req)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to update notification settings", err)
 		return
@@ -182,7 +398,11 @@ func (h *UserHandler) SearchUsers(c *gin.Context) {
 	}
 
 	// Get pagination parameters
-	params := utils.GetPaginationParams(c)
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
 
 	users, err := h.userService.SearchUsers(query, params.Limit, params.Offset)
 	if err != nil {
@@ -226,39 +446,60 @@ func (h *UserHandler) GetUserStats(c *gin.Context) {
 
 // GetSuggestedUsers gets suggested users for current user
 func (h *UserHandler) GetSuggestedUsers(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	limit := 10
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if l, err := utils.StringToInt(limitStr); err == nil && l > 0 && l <= 50 {
-			limit = l
-		}
+	limit, err := utils.ParseLimit(c, 10, 50)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
 	}
 
-	users, err := h.userService.GetSuggestedUsers(userID.(primitive.ObjectID), limit)
+	suggestions, err := h.suggestionService.GetSuggestions(userID, limit)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get suggested users", err)
 		return
 	}
 
-	// Convert to response format
-	var userResponses []models.UserResponse
-	for _, user := range users {
-		userResponses = append(userResponses, user.ToUserResponse())
+	utils.OkResponse(c, "Suggested users retrieved successfully", suggestions)
+}
+
+// DismissSuggestion hides a suggested user from future suggestions
+func (h *UserHandler) DismissSuggestion(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
 	}
 
-	utils.OkResponse(c, "Suggested users retrieved successfully", userResponses)
+	dismissedUserID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID format", err)
+		return
+	}
+
+	if err := h.suggestionService.DismissSuggestion(userID, dismissedUserID); err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			utils.NotFoundResponse(c, "User not found")
+		case strings.Contains(err.Error(), "cannot dismiss"):
+			utils.BadRequestResponse(c, err.Error(), nil)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to dismiss suggestion", err)
+		}
+		return
+	}
+
+	utils.OkResponse(c, "Suggestion dismissed successfully", gin.H{
+		"dismissed_user_id": dismissedUserID.Hex(),
+	})
 }
 
 // BlockUser blocks a user
 func (h *UserHandler) BlockUser(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -270,12 +511,12 @@ func (h *UserHandler) BlockUser(c *gin.Context) {
 	}
 
 	// Check if user is trying to block themselves
-	if userID.(primitive.ObjectID) == blockedUserID {
+	if userID == blockedUserID {
 		utils.BadRequestResponse(c, "Cannot block yourself", nil)
 		return
 	}
 
-	err = h.userService.BlockUser(userID.(primitive.ObjectID), blockedUserID)
+	err = h.userService.BlockUser(userID, blockedUserID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to block user", err)
 		return
@@ -289,9 +530,8 @@ func (h *UserHandler) BlockUser(c *gin.Context) {
 
 // UnblockUser unblocks a user
 func (h *UserHandler) UnblockUser(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -302,7 +542,7 @@ func (h *UserHandler) UnblockUser(c *gin.Context) {
 		return
 	}
 
-	err = h.userService.UnblockUser(userID.(primitive.ObjectID), blockedUserID)
+	err = h.userService.UnblockUser(userID, blockedUserID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to unblock user", err)
 		return
@@ -316,32 +556,181 @@ func (h *UserHandler) UnblockUser(c *gin.Context) {
 
 // GetBlockedUsers retrieves list of blocked users
 func (h *UserHandler) GetBlockedUsers(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	users, err := h.userService.GetBlockedUsers(userID.(primitive.ObjectID))
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	users, total, err := h.userService.GetBlockedUsers(userID, params.Limit, params.Offset)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get blocked users", err)
 		return
 	}
 
 	// Convert to response format
-	var userResponses []models.UserResponse
+	userResponses := []models.UserResponse{}
 	for _, user := range users {
 		userResponses = append(userResponses, user.ToUserResponse())
 	}
 
-	utils.OkResponse(c, "Blocked users retrieved successfully", userResponses)
+	pagination := utils.CreatePaginationMeta(params, total)
+	utils.PaginatedSuccessResponse(c, "Blocked users retrieved successfully", userResponses, pagination, utils.CreatePaginationLinks(c, pagination))
+}
+
+// MuteUser mutes a user for an optional duration, forever by default
+func (h *UserHandler) MuteUser(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	mutedUserIDStr := c.Param("id")
+	mutedUserID, err := primitive.ObjectIDFromHex(mutedUserIDStr)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID format", err)
+		return
+	}
+
+	if userID == mutedUserID {
+		utils.BadRequestResponse(c, "Cannot mute yourself", nil)
+		return
+	}
+
+	var req models.MuteUserRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BadRequestResponse(c, "Invalid request format", err)
+			return
+		}
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	length, err := models.ParseMuteDuration(req.Duration)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid mute duration", err)
+		return
+	}
+
+	mute, err := h.userService.MuteUser(userID, mutedUserID, length)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundResponse(c, "User not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to mute user", err)
+		return
+	}
+
+	utils.OkResponse(c, "User muted successfully", gin.H{
+		"muted_user_id": mutedUserIDStr,
+		"muted":         true,
+		"expires_at":    mute.ExpiresAt,
+	})
+}
+
+// UnmuteUser unmutes a user
+func (h *UserHandler) UnmuteUser(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	mutedUserIDStr := c.Param("id")
+	mutedUserID, err := primitive.ObjectIDFromHex(mutedUserIDStr)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID format", err)
+		return
+	}
+
+	err = h.userService.UnmuteUser(userID, mutedUserID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to unmute user", err)
+		return
+	}
+
+	utils.OkResponse(c, "User unmuted successfully", gin.H{
+		"muted_user_id": mutedUserIDStr,
+		"muted":         false,
+	})
+}
+
+// GetMutedUsers retrieves the user's active mutes with their remaining time
+func (h *UserHandler) GetMutedUsers(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	mutes, total, err := h.userService.GetMutedUsers(userID, params.Limit, params.Offset)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get muted users", err)
+		return
+	}
+
+	pagination := utils.CreatePaginationMeta(params, total)
+	utils.PaginatedSuccessResponse(c, "Muted users retrieved successfully", mutes, pagination, utils.CreatePaginationLinks(c, pagination))
+}
+
+// GetActivityLog retrieves a user's own activity timeline. Only the user and
+// admins may read it since it includes likes and follows.
+func (h *UserHandler) GetActivityLog(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	if idStr := c.Param("id"); idStr != "me" {
+		targetID, err := primitive.ObjectIDFromHex(idStr)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid user ID format", err)
+			return
+		}
+		role, ok := utils.CurrentRole(c)
+		if !ok {
+			return
+		}
+		if targetID != userID && !role.IsAdmin() {
+			utils.ForbiddenResponse(c, "You can only view your own activity log")
+			return
+		}
+		userID = targetID
+	}
+
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	entries, total, err := h.userService.GetActivityLog(userID, params.Page, params.Limit)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get activity log", err)
+		return
+	}
+
+	pagination := utils.CreatePaginationMeta(params, total)
+	utils.PaginatedSuccessResponse(c, "Activity log retrieved successfully", entries, pagination, utils.CreatePaginationLinks(c, pagination))
 }
 
 // UpdateUserActivity updates user's activity status
 func (h *UserHandler) UpdateUserActivity(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
@@ -360,7 +749,7 @@ func (h *UserHandler) UpdateUserActivity(c *gin.Context) {
 		return
 	}
 
-	err := h.userService.UpdateUserActivity(userID.(primitive.ObjectID), req.Status)
+	err := h.userService.UpdateUserActivity(userID, req.Status)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to update user activity", err)
 		return
@@ -374,13 +763,12 @@ func (h *UserHandler) UpdateUserActivity(c *gin.Context) {
 // VerifyUser verifies a user account (admin only)
 func (h *UserHandler) VerifyUser(c *gin.Context) {
 	// Check if current user is admin
-	currentUserID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	currentUserID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	currentUser, err := h.userService.GetUserByID(currentUserID.(primitive.ObjectID))
+	currentUser, err := h.userService.GetUserByID(currentUserID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get current user", err)
 		return
@@ -418,13 +806,12 @@ func (h *UserHandler) VerifyUser(c *gin.Context) {
 // SuspendUser suspends a user account (admin only)
 func (h *UserHandler) SuspendUser(c *gin.Context) {
 	// Check if current user is admin
-	currentUserID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	currentUserID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	currentUser, err := h.userService.GetUserByID(currentUserID.(primitive.ObjectID))
+	currentUser, err := h.userService.GetUserByID(currentUserID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get current user", err)
 		return
@@ -472,13 +859,12 @@ func (h *UserHandler) SuspendUser(c *gin.Context) {
 // UnsuspendUser unsuspends a user account (admin only)
 func (h *UserHandler) UnsuspendUser(c *gin.Context) {
 	// Check if current user is admin
-	currentUserID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	currentUserID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	currentUser, err := h.userService.GetUserByID(currentUserID.(primitive.ObjectID))
+	currentUser, err := h.userService.GetUserByID(currentUserID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get current user", err)
 		return
@@ -515,24 +901,24 @@ func (h *UserHandler) UnsuspendUser(c *gin.Context) {
 
 // DeactivateAccount allows user to deactivate their own account
 func (h *UserHandler) DeactivateAccount(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		utils.UnauthorizedResponse(c, "User not authenticated")
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
 		return
 	}
 
-	var req struct {
-		Password string `json:"password" binding:"required"`
-		Reason   string `json:"reason"`
-	}
-
+	var req models.DeactivateAccountRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.BadRequestResponse(c, "Invalid request format", err)
 		return
 	}
 
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
 	// Verify password before deactivation
-	user, err := h.userService.GetUserByID(userID.(primitive.ObjectID))
+	user, err := h.userService.GetUserByID(userID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get user", err)
 		return
@@ -543,7 +929,28 @@ func (h *UserHandler) DeactivateAccount(c *gin.Context) {
 		return
 	}
 
-	err = h.userService.SoftDeleteUser(userID.(primitive.ObjectID))
+	// Temporary deactivation keeps the account for the user to come back to
+	if req.Temporary || req.ReactivateAt != nil {
+		err = h.userService.DeactivateTemporarily(userID, req.ReactivateAt)
+		if err != nil {
+			if strings.Contains(err.Error(), "invalid") {
+				utils.BadRequestResponse(c, err.Error(), nil)
+				return
+			}
+			utils.InternalServerErrorResponse(c, "Failed to deactivate account", err)
+			return
+		}
+
+		utils.OkResponse(c, "Account deactivated successfully", gin.H{
+			"deactivated":   true,
+			"temporary":     true,
+			"reactivate_at": req.ReactivateAt,
+			"reason":        req.Reason,
+		})
+		return
+	}
+
+	err = h.userService.SoftDeleteUser(userID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to deactivate account", err)
 		return
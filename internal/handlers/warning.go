@@ -0,0 +1,137 @@
+// internal/handlers/warning.go
+package handlers
+
+import (
+	"strings"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type WarningHandler struct {
+	warningService *services.WarningService
+	validator      *validator.Validate
+}
+
+func NewWarningHandler(warningService *services.WarningService) *WarningHandler {
+	return &WarningHandler{
+		warningService: warningService,
+		validator:      validator.New(),
+	}
+}
+
+// GetMyWarnings returns the caller's warnings and whether each still counts as a strike
+func (h *WarningHandler) GetMyWarnings(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	warnings, err := h.warningService.GetUserWarnings(userID, false, params.Limit, params.Offset)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get warnings", err)
+		return
+	}
+
+	responses := make([]models.UserWarningResponse, len(warnings))
+	for i := range warnings {
+		responses[i] = warnings[i].ToUserWarningResponse()
+	}
+
+	paginationMeta := utils.CreatePaginationMeta(params, int64(len(responses)))
+	utils.PaginatedSuccessResponse(c, "Warnings retrieved successfully", responses, paginationMeta, nil)
+}
+
+// Moderator handlers
+
+// WarnUser issues a warning to a user, adding a strike
+func (h *WarningHandler) WarnUser(c *gin.Context) {
+	moderatorID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID", err)
+		return
+	}
+
+	var req models.IssueWarningRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request format", err)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	var reportID *primitive.ObjectID
+	if req.ReportID != "" {
+		id, err := primitive.ObjectIDFromHex(req.ReportID)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid report ID", err)
+			return
+		}
+		reportID = &id
+	}
+
+	warning, err := h.warningService.IssueWarning(userID, moderatorID, req.Reason, reportID)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "yourself"):
+			utils.BadRequestResponse(c, err.Error(), nil)
+		case strings.Contains(err.Error(), "report not found"):
+			utils.NotFoundResponse(c, "Report not found")
+		case strings.Contains(err.Error(), "not found"):
+			utils.NotFoundResponse(c, "User not found")
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to warn user", err)
+		}
+		return
+	}
+
+	utils.CreatedResponse(c, "User warned successfully", warning.ToAdminWarningResponse())
+}
+
+// GetUserWarnings returns a user's warning history for the admin user view
+func (h *WarningHandler) GetUserWarnings(c *gin.Context) {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID", err)
+		return
+	}
+
+	params, err := utils.ParsePagination(c, utils.DefaultPageSize, utils.MaxPageSize)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid pagination parameters", err)
+		return
+	}
+
+	warnings, err := h.warningService.GetUserWarnings(userID, true, params.Limit, params.Offset)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get warnings", err)
+		return
+	}
+
+	responses := make([]models.UserWarningResponse, len(warnings))
+	for i := range warnings {
+		responses[i] = warnings[i].ToAdminWarningResponse()
+	}
+
+	paginationMeta := utils.CreatePaginationMeta(params, int64(len(responses)))
+	utils.PaginatedSuccessResponse(c, "Warnings retrieved successfully", responses, paginationMeta, nil)
+}
@@ -0,0 +1,39 @@
+// middleware/account_maturity.go
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAccountMaturity holds back accounts that don't meet the policy yet,
+// answering with the requirement they're missing. It must run after
+// RequireAuth so the user is in the context.
+func RequireAccountMaturity(policy models.AccountMaturityPolicy) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		if !policy.Enabled() {
+			c.Next()
+			return
+		}
+
+		user, ok := utils.LookupUser(c)
+		if !ok {
+			utils.ErrorResponseWithCode(c, http.StatusForbidden, "User information not found", utils.ErrorCodeAuthUserNotFound, nil)
+			c.Abort()
+			return
+		}
+
+		if err := policy.Check(user, time.Now()); err != nil {
+			utils.ErrorResponseWithDetails(c, http.StatusForbidden, err.Error(), utils.ErrorCodeAccountTooNew, err)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	})
+}
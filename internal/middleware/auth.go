@@ -15,6 +15,8 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 
 	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+	"social-media-api/internal/services"
 	"social-media-api/internal/utils"
 )
 
@@ -29,46 +31,72 @@ type JWTClaims struct {
 	IPAddress  string          `json:"ip_address,omitempty"`
 	IssuedAt   int64           `json:"iat"`
 	ExpiresAt  int64           `json:"exp"`
-	TokenType  string          `json:"token_type"` // "access" or "refresh"
+	AuthTime   int64           `json:"auth_time,omitempty"` // When the session signed in
+	TokenType  string          `json:"token_type"`          // "access" or "refresh"
+
+	// Set on delegated tokens: UserID is the managed account and ActorID the
+	// delegate acting for it
+	ActorID      string `json:"actor_id,omitempty"`
+	DelegationID string `json:"delegation_id,omitempty"`
+
+	// Set on impersonation tokens: UserID is the user and ImpersonatedBy the
+	// super admin signed in as them
+	ImpersonatedBy  string `json:"impersonated_by,omitempty"`
+	ImpersonationID string `json:"impersonation_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
 // AuthMiddleware handles JWT authentication
 type AuthMiddleware struct {
-	db            *mongo.Database
-	jwtSecret     []byte
-	refreshSecret []byte
+	db             *mongo.Database
+	jwtSecret      []byte
+	refreshSecret  []byte
+	delegations    *services.DelegationService    // nil rejects delegated tokens
+	impersonations *services.ImpersonationService // nil rejects impersonation tokens
+	sessions       models.SessionPolicies
 }
 
+// RefreshedTokenHeader carries the fresh access token issued to a session
+// whose token is within its sliding window
+const RefreshedTokenHeader = "X-Refreshed-Token"
+
 // NewAuthMiddleware creates a new auth middleware instance
-func NewAuthMiddleware(db *mongo.Database, jwtSecret, refreshSecret string) *AuthMiddleware {
+func NewAuthMiddleware(db *mongo.Database, jwtSecret, refreshSecret string, delegations *services.DelegationService, impersonations *services.ImpersonationService) *AuthMiddleware {
 	return &AuthMiddleware{
-		db:            db,
-		jwtSecret:     []byte(jwtSecret),
-		refreshSecret: []byte(refreshSecret),
+		db:             db,
+		jwtSecret:      []byte(jwtSecret),
+		refreshSecret:  []byte(refreshSecret),
+		delegations:    delegations,
+		impersonations: impersonations,
 	}
 }
 
+// SetSessionPolicies sets how long the sessions of each role last. Without
+// it, tokens are neither slid nor held to an absolute limit.
+func (am *AuthMiddleware) SetSessionPolicies(policies models.SessionPolicies) {
+	am.sessions = policies
+}
+
 // RequireAuth middleware that requires valid JWT token
 func (am *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
 		token := am.extractToken(c)
 		if token == "" {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Authentication required", nil)
+			utils.ErrorResponseWithCode(c, http.StatusUnauthorized, "Authentication required", utils.ErrorCodeAuthRequired, nil)
 			c.Abort()
 			return
 		}
 
 		claims, err := am.validateToken(token, am.jwtSecret)
 		if err != nil {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired token", nil)
+			utils.ErrorResponseWithCode(c, http.StatusUnauthorized, "Invalid or expired token", utils.ErrorCodeAuthInvalidToken, nil)
 			c.Abort()
 			return
 		}
 
 		// Check if token type is access token
 		if claims.TokenType != "access" {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid token type", nil)
+			utils.ErrorResponseWithCode(c, http.StatusUnauthorized, "Invalid token type", utils.ErrorCodeAuthInvalidToken, nil)
 			c.Abort()
 			return
 		}
@@ -76,28 +104,51 @@ func (am *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 		// Get user from database to ensure account is still active
 		user, err := am.getUserFromDB(claims.UserID)
 		if err != nil {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "User not found", nil)
+			utils.ErrorResponseWithCode(c, http.StatusUnauthorized, "User not found", utils.ErrorCodeAuthUserNotFound, nil)
 			c.Abort()
 			return
 		}
 
 		// Check if user account is active
 		if !user.IsActive || user.IsSuspended {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Account suspended or inactive", nil)
+			utils.ErrorResponseWithCode(c, http.StatusUnauthorized, "Account suspended or inactive", utils.ErrorCodeAccountSuspended, nil)
+			c.Abort()
+			return
+		}
+
+		// Delegates act as the account, limited to what their role allows
+		if claims.DelegationID != "" && !am.authorizeDelegate(c, claims, user) {
+			return
+		}
+
+		// Delegated and impersonation tokens have lifetimes of their own
+		if claims.DelegationID == "" && claims.ImpersonationID == "" && !am.applySessionPolicy(c, claims, user) {
+			utils.ErrorResponseWithCode(c, http.StatusUnauthorized, "Session expired, please sign in again", utils.ErrorCodeAuthInvalidToken, nil)
 			c.Abort()
 			return
 		}
 
-		// Update user's last active time
-		go am.updateUserActivity(user.ID, c.ClientIP(), c.GetHeader("User-Agent"))
+		// Support admins act as the user, kept away from the account's
+		// credentials and with every request audited
+		var impersonation *models.ImpersonationSession
+		if claims.ImpersonationID != "" {
+			if impersonation = am.authorizeImpersonation(c, claims, user); impersonation == nil {
+				return
+			}
+		} else {
+			// Update user's last active time
+			go am.updateUserActivity(user.ID, utils.ClientIP(c), c.GetHeader("User-Agent"))
+		}
 
 		// Set user info in context
-		c.Set("user_id", user.ID)
-		c.Set("user", user)
-		c.Set("user_role", user.Role)
-		c.Set("session_id", claims.SessionID)
+		utils.SetCurrentUser(c, user)
+		c.Set(utils.ContextSessionID, claims.SessionID)
 
 		c.Next()
+
+		if impersonation != nil {
+			am.recordImpersonatedRequest(c, impersonation)
+		}
 	})
 }
 
@@ -137,16 +188,47 @@ func (am *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 			return
 		}
 
-		// Update user's last active time
-		go am.updateUserActivity(user.ID, c.ClientIP(), c.GetHeader("User-Agent"))
+		// A session past its absolute limit is treated as no token
+		if claims.DelegationID == "" && claims.ImpersonationID == "" && !am.applySessionPolicy(c, claims, user) {
+			c.Next()
+			return
+		}
+
+		// Delegates only ever read through optional auth; a stale delegated
+		// token is treated as no token
+		if claims.DelegationID != "" {
+			actor, delegation, err := am.resolveDelegate(c.Request.Context(), claims, user)
+			if err != nil || !delegation.Role.Allows(delegateOperation(c.Request.Method, c.FullPath())) {
+				c.Next()
+				return
+			}
+			setDelegateContext(c, actor, delegation)
+		}
+
+		// A stale or blocked impersonation token is treated as no token too
+		var impersonation *models.ImpersonationSession
+		if claims.ImpersonationID != "" {
+			session, err := am.resolveImpersonation(c.Request.Context(), claims, user)
+			if err != nil || impersonationBlocked(c.FullPath()) {
+				c.Next()
+				return
+			}
+			impersonation = session
+			setImpersonationContext(c, session)
+		} else {
+			// Update user's last active time
+			go am.updateUserActivity(user.ID, utils.ClientIP(c), c.GetHeader("User-Agent"))
+		}
 
 		// Set user info in context
-		c.Set("user_id", user.ID)
-		c.Set("user", user)
-		c.Set("user_role", user.Role)
-		c.Set("session_id", claims.SessionID)
+		utils.SetCurrentUser(c, user)
+		c.Set(utils.ContextSessionID, claims.SessionID)
 
 		c.Next()
+
+		if impersonation != nil {
+			am.recordImpersonatedRequest(c, impersonation)
+		}
 	})
 }
 
@@ -159,15 +241,13 @@ func (am *AuthMiddleware) RequireRole(roles ...models.UserRole) gin.HandlerFunc
 			return
 		}
 
-		userRole, exists := c.Get("user_role")
-		if !exists {
-			utils.ErrorResponse(c, http.StatusForbidden, "Role information not found", nil)
+		role, ok := utils.LookupRole(c)
+		if !ok {
+			utils.ErrorResponseWithCode(c, http.StatusForbidden, "Role information not found", utils.ErrorCodeInsufficientPermissions, nil)
 			c.Abort()
 			return
 		}
 
-		role := userRole.(models.UserRole)
-
 		// Check if user has required role
 		for _, requiredRole := range roles {
 			if role == requiredRole {
@@ -176,7 +256,7 @@ func (am *AuthMiddleware) RequireRole(roles ...models.UserRole) gin.HandlerFunc
 			}
 		}
 
-		utils.ErrorResponse(c, http.StatusForbidden, "Insufficient permissions", nil)
+		utils.ErrorResponseWithCode(c, http.StatusForbidden, "Insufficient permissions", utils.ErrorCodeInsufficientPermissions, nil)
 		c.Abort()
 	})
 }
@@ -190,16 +270,15 @@ func (am *AuthMiddleware) RequireVerified() gin.HandlerFunc {
 			return
 		}
 
-		user, exists := c.Get("user")
-		if !exists {
-			utils.ErrorResponse(c, http.StatusForbidden, "User information not found", nil)
+		u, ok := utils.LookupUser(c)
+		if !ok {
+			utils.ErrorResponseWithCode(c, http.StatusForbidden, "User information not found", utils.ErrorCodeAuthUserNotFound, nil)
 			c.Abort()
 			return
 		}
 
-		u := user.(*models.User)
 		if !u.IsVerified {
-			utils.ErrorResponse(c, http.StatusForbidden, "Email verification required", nil)
+			utils.ErrorResponseWithCode(c, http.StatusForbidden, "Email verification required", utils.ErrorCodeEmailNotVerified, nil)
 			c.Abort()
 			return
 		}
@@ -217,16 +296,15 @@ func (am *AuthMiddleware) RequireEmailVerified() gin.HandlerFunc {
 			return
 		}
 
-		user, exists := c.Get("user")
-		if !exists {
-			utils.ErrorResponse(c, http.StatusForbidden, "User information not found", nil)
+		u, ok := utils.LookupUser(c)
+		if !ok {
+			utils.ErrorResponseWithCode(c, http.StatusForbidden, "User information not found", utils.ErrorCodeAuthUserNotFound, nil)
 			c.Abort()
 			return
 		}
 
-		u := user.(*models.User)
 		if !u.EmailVerified {
-			utils.ErrorResponse(c, http.StatusForbidden, "Email verification required", nil)
+			utils.ErrorResponseWithCode(c, http.StatusForbidden, "Email verification required", utils.ErrorCodeEmailNotVerified, nil)
 			c.Abort()
 			return
 		}
@@ -240,21 +318,21 @@ func (am *AuthMiddleware) RefreshToken() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
 		refreshToken := am.extractToken(c)
 		if refreshToken == "" {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Refresh token required", nil)
+			utils.ErrorResponseWithCode(c, http.StatusUnauthorized, "Refresh token required", utils.ErrorCodeAuthRequired, nil)
 			c.Abort()
 			return
 		}
 
 		claims, err := am.validateToken(refreshToken, am.refreshSecret)
 		if err != nil {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired refresh token", nil)
+			utils.ErrorResponseWithCode(c, http.StatusUnauthorized, "Invalid or expired refresh token", utils.ErrorCodeAuthInvalidToken, nil)
 			c.Abort()
 			return
 		}
 
 		// Check if token type is refresh token
 		if claims.TokenType != "refresh" {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid token type", nil)
+			utils.ErrorResponseWithCode(c, http.StatusUnauthorized, "Invalid token type", utils.ErrorCodeAuthInvalidToken, nil)
 			c.Abort()
 			return
 		}
@@ -262,23 +340,21 @@ func (am *AuthMiddleware) RefreshToken() gin.HandlerFunc {
 		// Get user from database
 		user, err := am.getUserFromDB(claims.UserID)
 		if err != nil {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "User not found", nil)
+			utils.ErrorResponseWithCode(c, http.StatusUnauthorized, "User not found", utils.ErrorCodeAuthUserNotFound, nil)
 			c.Abort()
 			return
 		}
 
 		// Check if user account is active
 		if !user.IsActive || user.IsSuspended {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Account suspended or inactive", nil)
+			utils.ErrorResponseWithCode(c, http.StatusUnauthorized, "Account suspended or inactive", utils.ErrorCodeAccountSuspended, nil)
 			c.Abort()
 			return
 		}
 
 		// Set user info in context for token generation
-		c.Set("user_id", user.ID)
-		c.Set("user", user)
-		c.Set("user_role", user.Role)
-		c.Set("session_id", claims.SessionID)
+		utils.SetCurrentUser(c, user)
+		c.Set(utils.ContextSessionID, claims.SessionID)
 
 		c.Next()
 	})
@@ -287,15 +363,14 @@ func (am *AuthMiddleware) RefreshToken() gin.HandlerFunc {
 // BlockSuspended middleware that blocks suspended users
 func (am *AuthMiddleware) BlockSuspended() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
-		user, exists := c.Get("user")
-		if !exists {
+		u, ok := utils.LookupUser(c)
+		if !ok {
 			c.Next()
 			return
 		}
 
-		u := user.(*models.User)
 		if u.IsSuspended {
-			utils.ErrorResponse(c, http.StatusForbidden, "Account is suspended", nil)
+			utils.ErrorResponseWithCode(c, http.StatusForbidden, "Account is suspended", utils.ErrorCodeAccountSuspended, nil)
 			c.Abort()
 			return
 		}
@@ -307,13 +382,12 @@ func (am *AuthMiddleware) BlockSuspended() gin.HandlerFunc {
 // RateLimitByUser middleware that applies rate limiting per user
 func (am *AuthMiddleware) RateLimitByUser(maxRequests int, window time.Duration) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
-		userID, exists := c.Get("user_id")
-		if !exists {
+		userObjID, ok := utils.LookupUserID(c)
+		if !ok {
 			c.Next()
 			return
 		}
 
-		userObjID := userID.(primitive.ObjectID)
 		fmt.Sprintf("rate_limit_user_%s", userObjID.Hex())
 
 		// Implement rate limiting logic here (Redis recommended)
@@ -385,10 +459,7 @@ func (am *AuthMiddleware) getUserFromDB(userID string) (*models.User, error) {
 	}
 
 	var user models.User
-	err = am.db.Collection("users").FindOne(context.Background(), bson.M{
-		"_id":        objID,
-		"deleted_at": nil,
-	}).Decode(&user)
+	err = am.db.Collection("users").FindOne(context.Background(), repository.ByID(objID)).Decode(&user)
 
 	if err != nil {
 		return nil, err
@@ -477,6 +548,43 @@ func (am *AuthMiddleware) GenerateTokens(user *models.User, sessionID, deviceInf
 	return accessTokenString, refreshTokenString, nil
 }
 
+// applySessionPolicy holds the session to its role's absolute limit,
+// returning false once it is over. Within the sliding window it sends a
+// fresh access token in the X-Refreshed-Token header.
+func (am *AuthMiddleware) applySessionPolicy(c *gin.Context, claims *JWTClaims, user *models.User) bool {
+	now := time.Now()
+	policy := am.sessions.For(user.Role)
+
+	// Tokens from before sign-in times were recorded count from issue
+	authTime := time.Unix(claims.AuthTime, 0)
+	if claims.AuthTime == 0 {
+		authTime = time.Unix(claims.IssuedAt, 0)
+	}
+
+	if policy.SessionExpired(authTime, now) {
+		return false
+	}
+
+	if !policy.ShouldSlide(authTime, time.Unix(claims.ExpiresAt, 0), now) {
+		return true
+	}
+
+	refreshed := *claims
+	refreshed.Role = user.Role
+	refreshed.AuthTime = authTime.Unix()
+	refreshed.IssuedAt = now.Unix()
+	refreshed.ExpiresAt = policy.AccessTokenExpiry(authTime, now).Unix()
+	refreshed.RegisteredClaims = jwt.RegisteredClaims{}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &refreshed).SignedString(am.jwtSecret)
+	if err != nil {
+		// The current token is still good; the next request tries again
+		return true
+	}
+	c.Header(RefreshedTokenHeader, token)
+	return true
+}
+
 // ValidateTokenString validates a token string and returns claims
 func (am *AuthMiddleware) ValidateTokenString(tokenString string) (*JWTClaims, error) {
 	return am.validateToken(tokenString, am.jwtSecret)
@@ -484,45 +592,33 @@ func (am *AuthMiddleware) ValidateTokenString(tokenString string) (*JWTClaims, e
 
 // GetCurrentUser gets current user from context
 func GetCurrentUser(c *gin.Context) (*models.User, bool) {
-	user, exists := c.Get("user")
-	if !exists {
-		return nil, false
-	}
-	return user.(*models.User), true
+	return utils.LookupUser(c)
 }
 
 // GetCurrentUserID gets current user ID from context
 func GetCurrentUserID(c *gin.Context) (primitive.ObjectID, bool) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		return primitive.NilObjectID, false
-	}
-	return userID.(primitive.ObjectID), true
+	return utils.LookupUserID(c)
 }
 
 // IsAuthenticated checks if request is authenticated
 func IsAuthenticated(c *gin.Context) bool {
-	_, exists := c.Get("user_id")
-	return exists
+	_, ok := utils.LookupUserID(c)
+	return ok
 }
 
 // HasRole checks if current user has specific role
 func HasRole(c *gin.Context, role models.UserRole) bool {
-	userRole, exists := c.Get("user_role")
-	if !exists {
-		return false
-	}
-	return userRole.(models.UserRole) == role
+	currentRole, ok := utils.LookupRole(c)
+	return ok && currentRole == role
 }
 
 // HasAnyRole checks if current user has any of the specified roles
 func HasAnyRole(c *gin.Context, roles ...models.UserRole) bool {
-	userRole, exists := c.Get("user_role")
-	if !exists {
+	currentRole, ok := utils.LookupRole(c)
+	if !ok {
 		return false
 	}
 
-	currentRole := userRole.(models.UserRole)
 	for _, role := range roles {
 		if currentRole == role {
 			return true
@@ -10,6 +10,7 @@ import (
 
 	"social-media-api/internal/models"
 	"social-media-api/internal/services"
+	"social-media-api/internal/utils"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -35,15 +36,15 @@ func (m *BehaviorTrackingMiddleware) AutoTrackBehavior() gin.HandlerFunc {
 		c.Set("session_id", sessionID)
 
 		// Get user info if authenticated
-		userID, exists := c.Get("user_id")
+		userID, exists := utils.LookupUserID(c)
 		if exists {
 			// Start session tracking if new session
 			if m.isNewSession(c, sessionID) {
-				go m.startSessionTracking(userID.(primitive.ObjectID), sessionID, c)
+				go m.startSessionTracking(userID, sessionID, c)
 			}
 
 			// Track page visit
-			go m.trackPageVisit(userID.(primitive.ObjectID), sessionID, c)
+			go m.trackPageVisit(userID, sessionID, c)
 		}
 
 		// Continue to next handler
@@ -52,7 +53,15 @@ func (m *BehaviorTrackingMiddleware) AutoTrackBehavior() gin.HandlerFunc {
 		// Track response and duration
 		if exists {
 			duration := time.Since(startTime)
-			go m.trackRequestCompletion(userID.(primitive.ObjectID), sessionID, c, duration)
+			go m.trackRequestCompletion(userID, sessionID, c, duration)
+		}
+
+		// Opening a post from a sponsored feed slot (?boost_id=) is a tap on
+		// its campaign. Auth runs after this middleware, so check the user now.
+		if boostID := c.Query("boost_id"); boostID != "" && c.Request.Method == "GET" && c.Writer.Status() < 300 {
+			if tapUserID, ok := utils.LookupUserID(c); ok {
+				go m.trackBoostTap(tapUserID, c.Request.URL.Path, boostID)
+			}
 		}
 	})
 }
@@ -64,9 +73,9 @@ func (m *BehaviorTrackingMiddleware) TrackContentInteraction() gin.HandlerFunc {
 
 		// Track after successful request
 		if c.Writer.Status() >= 200 && c.Writer.Status() < 300 {
-			userID, exists := c.Get("user_id")
+			userID, exists := utils.LookupUserID(c)
 			if exists {
-				go m.trackContentInteraction(userID.(primitive.ObjectID), c)
+				go m.trackContentInteraction(userID, c)
 			}
 		}
 	})
@@ -80,10 +89,10 @@ func (m *BehaviorTrackingMiddleware) TrackAPIUsage() gin.HandlerFunc {
 		c.Next()
 
 		// Track API usage
-		userID, exists := c.Get("user_id")
+		userID, exists := utils.LookupUserID(c)
 		if exists {
 			duration := time.Since(startTime)
-			go m.trackAPIUsage(userID.(primitive.ObjectID), c, duration)
+			go m.trackAPIUsage(userID, c, duration)
 		}
 	})
 }
@@ -119,7 +128,7 @@ func (m *BehaviorTrackingMiddleware) startSessionTracking(userID primitive.Objec
 		deviceInfo = "Unknown"
 	}
 
-	ipAddress := c.ClientIP()
+	ipAddress := utils.ClientIP(c)
 	userAgent := c.GetHeader("User-Agent")
 
 	m.behaviorService.StartSession(userID, sessionID, deviceInfo, ipAddress, userAgent)
@@ -214,6 +223,23 @@ func (m *BehaviorTrackingMiddleware) trackPostInteraction(userID primitive.Objec
 	m.behaviorService.AutoTrackPostInteraction(userID, postID, interactionType, source)
 }
 
+func (m *BehaviorTrackingMiddleware) trackBoostTap(userID primitive.ObjectID, path, boostHex string) {
+	boostID, err := primitive.ObjectIDFromHex(boostHex)
+	if err != nil {
+		return
+	}
+
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		if part == "posts" && i+1 < len(parts) {
+			if postID, err := primitive.ObjectIDFromHex(parts[i+1]); err == nil {
+				m.behaviorService.RecordBoostTap(userID, postID, boostID)
+			}
+			return
+		}
+	}
+}
+
 func (m *BehaviorTrackingMiddleware) trackStoryInteraction(userID primitive.ObjectID, path, method string, c *gin.Context) {
 	// Extract story ID from path
 	parts := strings.Split(path, "/")
@@ -387,11 +413,11 @@ func (m *BehaviorTrackingMiddleware) TrackRecommendations() gin.HandlerFunc {
 		c.Next()
 
 		// Track recommendation events
-		userID, exists := c.Get("user_id")
+		userID, exists := utils.LookupUserID(c)
 		if exists && c.Writer.Status() >= 200 && c.Writer.Status() < 300 {
 			// Check if this is a recommended content view
 			if recommendationData := c.GetHeader("X-Recommendation-Data"); recommendationData != "" {
-				go m.trackRecommendationEvent(userID.(primitive.ObjectID), recommendationData, c)
+				go m.trackRecommendationEvent(userID, recommendationData, c)
 			}
 		}
 	})
@@ -424,9 +450,9 @@ func (m *BehaviorTrackingMiddleware) TrackConversions() gin.HandlerFunc {
 		c.Next()
 
 		if c.Writer.Status() >= 200 && c.Writer.Status() < 300 {
-			userID, exists := c.Get("user_id")
+			userID, exists := utils.LookupUserID(c)
 			if exists {
-				go m.trackConversionEvents(userID.(primitive.ObjectID), c)
+				go m.trackConversionEvents(userID, c)
 			}
 		}
 	})
@@ -482,9 +508,9 @@ func (m *BehaviorTrackingMiddleware) TrackErrors() gin.HandlerFunc {
 
 		// Track errors
 		if c.Writer.Status() >= 400 {
-			userID, exists := c.Get("user_id")
+			userID, exists := utils.LookupUserID(c)
 			if exists {
-				go m.trackErrorEvent(userID.(primitive.ObjectID), c)
+				go m.trackErrorEvent(userID, c)
 			}
 		}
 	})
@@ -529,7 +555,7 @@ func (m *BehaviorTrackingMiddleware) SessionCleanup() gin.HandlerFunc {
 // BehaviorBasedCaching provides behavior-based cache strategies
 func (m *BehaviorTrackingMiddleware) BehaviorBasedCaching() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
-		userID, exists := c.Get("user_id")
+		userID, exists := utils.LookupUserID(c)
 		if !exists {
 			c.Next()
 			return
@@ -553,7 +579,7 @@ func (m *BehaviorTrackingMiddleware) BehaviorBasedCaching() gin.HandlerFunc {
 			metadata := map[string]interface{}{
 				"cache_strategy": "behavior_based",
 				"path":           path,
-				"user_id":        userID.(primitive.ObjectID).Hex(),
+				"user_id":        userID.Hex(),
 			}
 
 			sessionID, _ := c.Get("session_id")
@@ -564,7 +590,7 @@ func (m *BehaviorTrackingMiddleware) BehaviorBasedCaching() gin.HandlerFunc {
 					Timestamp: time.Now(),
 					Metadata:  metadata,
 				}
-				m.behaviorService.RecordUserAction(userID.(primitive.ObjectID), sessionID.(string), action)
+				m.behaviorService.RecordUserAction(userID, sessionID.(string), action)
 			}
 		}()
 
@@ -0,0 +1,82 @@
+// middleware/challenge.go
+package middleware
+
+import (
+	"net/http"
+
+	"social-media-api/internal/services"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Headers carrying a solved proof-of-work challenge
+const (
+	HeaderPoWChallenge = "X-PoW-Challenge"
+	HeaderPoWSolution  = "X-PoW-Solution"
+)
+
+// ChallengeMiddleware demands proof-of-work from untrusted clients on route
+// groups the abuse heuristics or admins flag as under attack
+type ChallengeMiddleware struct {
+	challenges *services.ChallengeService
+}
+
+// NewChallengeMiddleware creates a new challenge middleware instance
+func NewChallengeMiddleware(challenges *services.ChallengeService) *ChallengeMiddleware {
+	return &ChallengeMiddleware{challenges: challenges}
+}
+
+// Require challenges requests to the route group while its risk is elevated.
+// It must run after any auth middleware so trusted users can be recognized.
+func (cm *ChallengeMiddleware) Require(group string) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		if cm.challenges == nil || !cm.challenges.Enabled() {
+			c.Next()
+			return
+		}
+
+		if u, ok := utils.LookupUser(c); ok && cm.challenges.IsTrusted(u) {
+			cm.challenges.RecordBypass(group)
+			c.Next()
+			return
+		}
+
+		ip := utils.ClientIP(c)
+		cm.challenges.ObserveRequest(group, ip)
+
+		if risk := cm.challenges.RiskLevel(group, ip); risk > 0 {
+			challenge := c.GetHeader(HeaderPoWChallenge)
+			solution := c.GetHeader(HeaderPoWSolution)
+			if challenge == "" || solution == "" {
+				cm.demand(c, group, risk, "Proof of work required")
+				return
+			}
+			if err := cm.challenges.Verify(group, challenge, solution, risk); err != nil {
+				cm.demand(c, group, risk, "Proof of work rejected: "+err.Error())
+				return
+			}
+		}
+
+		c.Next()
+
+		// Rejected credentials feed the failed login heuristic
+		if c.Writer.Status() == http.StatusUnauthorized {
+			cm.challenges.ObserveFailure(group)
+		}
+	})
+}
+
+// demand aborts with a 428 carrying a fresh challenge
+func (cm *ChallengeMiddleware) demand(c *gin.Context, group string, risk int, message string) {
+	challenge, err := cm.challenges.Issue(group, risk)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to issue challenge", err)
+		c.Abort()
+		return
+	}
+
+	c.Header(HeaderPoWChallenge, challenge.Challenge)
+	utils.ErrorResponseWithDetails(c, http.StatusPreconditionRequired, message, utils.ErrorCodeChallengeRequired, challenge)
+	c.Abort()
+}
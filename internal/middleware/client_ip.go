@@ -0,0 +1,18 @@
+// middleware/client_ip.go
+package middleware
+
+import (
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RealClientIP resolves the client IP of each request once, honouring
+// forwarding headers only from trusted proxies, and stores it for
+// utils.ClientIP. It should run before anything that logs or rate limits by IP.
+func RealClientIP(resolver *utils.ClientIPResolver) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		c.Set(utils.ContextClientIP, resolver.Resolve(c.Request))
+		c.Next()
+	})
+}
@@ -0,0 +1,177 @@
+// middleware/delegation.go
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/utils"
+)
+
+// Routes a delegate can never use, whatever their role: credentials, account
+// deletion, managing delegates and staff tools
+var ownerOnlyRoutes = []string{
+	"/api/v1/auth/change-password",
+	"/api/v1/auth/switch-account",
+	"/api/v1/auth/sessions",
+	"/api/v1/auth/logout-all",
+	"/api/v1/users/deactivate",
+	"/api/v1/delegates",
+	"/api/v1/admin",
+	"/api/v1/moderation",
+	"/api/v1/search/saved",
+	"/api/v1/search/history",
+	"/api/v1/reactions/my-reactions",
+}
+
+// Areas a delegate may read as the account: its content and the feeds,
+// search and profiles around it. Reads outside them, such as notifications,
+// follow requests, saved searches or the account's own profile and devices,
+// stay with the owner.
+var delegateReadPrefixes = []string{
+	"/api/v1/posts",
+	"/api/v1/stories",
+	"/api/v1/story-highlights",
+	"/api/v1/media",
+	"/api/v1/feeds",
+	"/api/v1/hashtags",
+	"/api/v1/groups",
+	"/api/v1/search",
+	"/api/v1/reactions",
+}
+
+// Profile routes a delegate may read. Matched exactly, as the same prefixes
+// also hold private lists such as the activity log.
+var delegateReadRoutes = map[string]bool{
+	"/api/v1/users/search":                true,
+	"/api/v1/users/:id":                   true,
+	"/api/v1/users/username/:username":    true,
+	"/api/v1/users/:id/featured-comments": true,
+	"/api/v1/users/:id/followers":         true,
+	"/api/v1/users/:id/following":         true,
+	"/api/v1/users/:id/follow-stats":      true,
+}
+
+// delegateOperation classifies a request made with a delegated token. Reads
+// and writes that don't belong to a delegable area are treated as owner
+// operations.
+func delegateOperation(method, path string) models.DelegateOperation {
+	for _, prefix := range ownerOnlyRoutes {
+		if strings.HasPrefix(path, prefix) {
+			return models.DelegateOpOwner
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(path, "/api/v1/messaging"):
+		return models.DelegateOpMessages
+	case strings.Contains(path, "/insights"), strings.HasSuffix(path, "/stats"), strings.HasPrefix(path, "/api/v1/behavior"):
+		return models.DelegateOpInsights
+	case strings.Contains(path, "/comments"):
+		return models.DelegateOpComments
+	}
+
+	if method == http.MethodGet || method == http.MethodHead {
+		if delegateReadRoutes[path] {
+			return models.DelegateOpRead
+		}
+		for _, prefix := range delegateReadPrefixes {
+			if strings.HasPrefix(path, prefix) {
+				return models.DelegateOpRead
+			}
+		}
+		return models.DelegateOpOwner
+	}
+
+	switch {
+	case strings.HasPrefix(path, "/api/v1/posts"),
+		strings.HasPrefix(path, "/api/v1/stories"),
+		strings.HasPrefix(path, "/api/v1/story-highlights"),
+		strings.HasPrefix(path, "/api/v1/media"):
+		return models.DelegateOpContent
+	}
+
+	return models.DelegateOpOwner
+}
+
+// authorizeDelegate checks a delegated token against its delegation and the
+// delegate's role, and records who is really acting. It reports false, having
+// written the error response, when the request must stop.
+func (am *AuthMiddleware) authorizeDelegate(c *gin.Context, claims *JWTClaims, account *models.User) bool {
+	actor, delegation, err := am.resolveDelegate(c.Request.Context(), claims, account)
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusUnauthorized, "Delegated access is no longer valid", utils.ErrorCodeAuthInvalidToken, nil)
+		c.Abort()
+		return false
+	}
+
+	op := delegateOperation(c.Request.Method, c.FullPath())
+	if !delegation.Role.Allows(op) {
+		utils.ErrorResponseWithCode(c, http.StatusForbidden, "Your delegate role does not allow this action", utils.ErrorCodeInsufficientPermissions, nil)
+		c.Abort()
+		return false
+	}
+
+	setDelegateContext(c, actor, delegation)
+	return true
+}
+
+// resolveDelegate loads the person behind a delegated token and the
+// delegation that lets them act for the account
+func (am *AuthMiddleware) resolveDelegate(ctx context.Context, claims *JWTClaims, account *models.User) (*models.User, *models.AccountDelegation, error) {
+	if am.delegations == nil {
+		return nil, nil, errors.New("delegated access is not enabled")
+	}
+
+	delegationID, err := primitive.ObjectIDFromHex(claims.DelegationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	actor, err := am.getUserFromDB(claims.ActorID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !actor.IsActive || actor.IsSuspended {
+		return nil, nil, errors.New("delegate account suspended or inactive")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	delegation, err := am.delegations.CheckDelegation(ctx, delegationID, account.ID, actor.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return actor, delegation, nil
+}
+
+func setDelegateContext(c *gin.Context, actor *models.User, delegation *models.AccountDelegation) {
+	c.Set("actor_id", actor.ID)
+	c.Set("actor", actor)
+	c.Set("delegation_id", delegation.ID)
+	c.Set("delegate_role", delegation.Role)
+}
+
+// GetActorID returns the person making the request: the delegate when one is
+// acting for the account, otherwise the authenticated user
+func GetActorID(c *gin.Context) (primitive.ObjectID, bool) {
+	if actorID, exists := c.Get("actor_id"); exists {
+		return actorID.(primitive.ObjectID), true
+	}
+	return GetCurrentUserID(c)
+}
+
+// IsDelegated checks if the request is made by a delegate on the account's behalf
+func IsDelegated(c *gin.Context) bool {
+	_, exists := c.Get("actor_id")
+	return exists
+}
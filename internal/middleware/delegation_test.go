@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"social-media-api/internal/models"
+)
+
+func TestDelegateRolesReachOnlyTheirAreas(t *testing.T) {
+	roles := []models.DelegateRole{models.DelegateRoleEditor, models.DelegateRoleAnalyst, models.DelegateRoleModerator}
+
+	tests := []struct {
+		method  string
+		path    string
+		allowed []models.DelegateRole
+	}{
+		// The account's content, feeds and public profiles
+		{http.MethodGet, "/api/v1/posts/:id", []models.DelegateRole{models.DelegateRoleEditor, models.DelegateRoleModerator}},
+		{http.MethodGet, "/api/v1/feeds/following", []models.DelegateRole{models.DelegateRoleEditor, models.DelegateRoleModerator}},
+		{http.MethodGet, "/api/v1/users/:id", []models.DelegateRole{models.DelegateRoleEditor, models.DelegateRoleModerator}},
+		{http.MethodPost, "/api/v1/posts/", []models.DelegateRole{models.DelegateRoleEditor}},
+		{http.MethodDelete, "/api/v1/comments/:id", []models.DelegateRole{models.DelegateRoleEditor, models.DelegateRoleModerator}},
+		{http.MethodGet, "/api/v1/messaging/conversations/", []models.DelegateRole{models.DelegateRoleModerator}},
+		{http.MethodGet, "/api/v1/users/me/insights/audience", []models.DelegateRole{models.DelegateRoleAnalyst}},
+		{http.MethodGet, "/api/v1/posts/:id/stats", []models.DelegateRole{models.DelegateRoleAnalyst}},
+
+		// Private reads stay with the owner
+		{http.MethodGet, "/api/v1/notifications/", nil},
+		{http.MethodGet, "/api/v1/follow-requests", nil},
+		{http.MethodGet, "/api/v1/search/saved", nil},
+		{http.MethodGet, "/api/v1/search/history", nil},
+		{http.MethodGet, "/api/v1/reactions/my-reactions", nil},
+		{http.MethodGet, "/api/v1/auth/accounts", nil},
+		{http.MethodGet, "/api/v1/auth/profile", nil},
+		{http.MethodGet, "/api/v1/users/:id/activity", nil},
+		{http.MethodGet, "/api/v1/users/blocked", nil},
+		{http.MethodGet, "/api/v1/delegates/", nil},
+		{http.MethodPost, "/api/v1/auth/change-password", nil},
+	}
+
+	for _, tt := range tests {
+		op := delegateOperation(tt.method, tt.path)
+		for _, role := range roles {
+			want := false
+			for _, allowed := range tt.allowed {
+				want = want || allowed == role
+			}
+			if got := role.Allows(op); got != want {
+				t.Errorf("%s %s (%s) for %s: allowed = %v, want %v", tt.method, tt.path, op, role, got, want)
+			}
+		}
+	}
+}
@@ -9,21 +9,23 @@ import (
 	"strings"
 	"time"
 
+	"social-media-api/internal/utils"
+
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // ErrorResponse represents a structured error response
 type ErrorResponse struct {
-	Success   bool        `json:"success"`
-	Message   string      `json:"message"`
-	Error     string      `json:"error,omitempty"`
-	Code      string      `json:"code,omitempty"`
-	Details   interface{} `json:"details,omitempty"`
-	Timestamp time.Time   `json:"timestamp"`
-	Path      string      `json:"path"`
-	Method    string      `json:"method"`
-	RequestID string      `json:"request_id,omitempty"`
+	Success   bool            `json:"success"`
+	Message   string          `json:"message"`
+	Error     string          `json:"error,omitempty"`
+	ErrorCode utils.ErrorCode `json:"error_code,omitempty"`
+	Details   interface{}     `json:"details,omitempty"`
+	Timestamp time.Time       `json:"timestamp"`
+	Path      string          `json:"path"`
+	Method    string          `json:"method"`
+	RequestID string          `json:"request_id,omitempty"`
 }
 
 // GlobalErrorHandler handles all unhandled errors and panics
@@ -39,7 +41,7 @@ func GlobalErrorHandler() gin.HandlerFunc {
 					Success:   false,
 					Message:   "Internal server error",
 					Error:     "An unexpected error occurred",
-					Code:      "INTERNAL_ERROR",
+					ErrorCode: utils.ErrorCodeInternal,
 					Timestamp: time.Now(),
 					Path:      c.Request.URL.Path,
 					Method:    c.Request.Method,
@@ -93,7 +95,7 @@ func NotFoundHandler() gin.HandlerFunc {
 			Success:   false,
 			Message:   "Route not found",
 			Error:     fmt.Sprintf("The requested endpoint %s %s was not found", c.Request.Method, c.Request.URL.Path),
-			Code:      "ROUTE_NOT_FOUND",
+			ErrorCode: utils.ErrorCodeRouteNotFound,
 			Timestamp: time.Now(),
 			Path:      c.Request.URL.Path,
 			Method:    c.Request.Method,
@@ -111,7 +113,7 @@ func MethodNotAllowedHandler() gin.HandlerFunc {
 			Success:   false,
 			Message:   "Method not allowed",
 			Error:     fmt.Sprintf("The %s method is not allowed for this endpoint", c.Request.Method),
-			Code:      "METHOD_NOT_ALLOWED",
+			ErrorCode: utils.ErrorCodeMethodNotAllowed,
 			Timestamp: time.Now(),
 			Path:      c.Request.URL.Path,
 			Method:    c.Request.Method,
@@ -131,35 +133,35 @@ func handleGinErrors(c *gin.Context) {
 
 	var statusCode int
 	var message string
-	var errorCode string
+	var errorCode utils.ErrorCode
 
 	// Determine error type and appropriate response
 	switch ginError.Type {
 	case gin.ErrorTypeBind:
 		statusCode = http.StatusBadRequest
 		message = "Invalid request data"
-		errorCode = "INVALID_REQUEST"
+		errorCode = utils.ErrorCodeBadRequest
 	case gin.ErrorTypePublic:
 		statusCode = http.StatusBadRequest
 		message = ginError.Error()
-		errorCode = "BAD_REQUEST"
+		errorCode = utils.ErrorCodeBadRequest
 	case gin.ErrorTypePrivate:
 		statusCode = http.StatusInternalServerError
 		message = "Internal server error"
-		errorCode = "INTERNAL_ERROR"
+		errorCode = utils.ErrorCodeInternal
 		// Log private errors
 		log.Printf("Private error: %v", ginError.Error())
 	default:
 		statusCode = http.StatusInternalServerError
 		message = "Internal server error"
-		errorCode = "INTERNAL_ERROR"
+		errorCode = utils.ErrorCodeInternal
 	}
 
 	errorResponse := ErrorResponse{
 		Success:   false,
 		Message:   message,
 		Error:     ginError.Error(),
-		Code:      errorCode,
+		ErrorCode: errorCode,
 		Timestamp: time.Now(),
 		Path:      c.Request.URL.Path,
 		Method:    c.Request.Method,
@@ -173,13 +175,13 @@ func handleGinErrors(c *gin.Context) {
 func handleDatabaseError(c *gin.Context, err error) {
 	var statusCode int
 	var message string
-	var errorCode string
+	var errorCode utils.ErrorCode
 
 	switch {
 	case mongo.IsDuplicateKeyError(err):
 		statusCode = http.StatusConflict
 		message = "Resource already exists"
-		errorCode = "DUPLICATE_RESOURCE"
+		errorCode = utils.ErrorCodeConflict
 
 		// Extract field name from duplicate key error
 		if strings.Contains(err.Error(), "username") {
@@ -191,22 +193,22 @@ func handleDatabaseError(c *gin.Context, err error) {
 	case err == mongo.ErrNoDocuments:
 		statusCode = http.StatusNotFound
 		message = "Resource not found"
-		errorCode = "RESOURCE_NOT_FOUND"
+		errorCode = utils.ErrorCodeNotFound
 
 	case mongo.IsTimeout(err):
 		statusCode = http.StatusRequestTimeout
 		message = "Database operation timed out"
-		errorCode = "DATABASE_TIMEOUT"
+		errorCode = utils.ErrorCodeRequestTimeout
 
 	case mongo.IsNetworkError(err):
 		statusCode = http.StatusServiceUnavailable
 		message = "Database connection error"
-		errorCode = "DATABASE_CONNECTION_ERROR"
+		errorCode = utils.ErrorCodeServiceUnavailable
 
 	default:
 		statusCode = http.StatusInternalServerError
 		message = "Database error"
-		errorCode = "DATABASE_ERROR"
+		errorCode = utils.ErrorCodeInternal
 		// Log unexpected database errors
 		log.Printf("Unexpected database error: %v", err)
 	}
@@ -215,7 +217,7 @@ func handleDatabaseError(c *gin.Context, err error) {
 		Success:   false,
 		Message:   message,
 		Error:     err.Error(),
-		Code:      errorCode,
+		ErrorCode: errorCode,
 		Timestamp: time.Now(),
 		Path:      c.Request.URL.Path,
 		Method:    c.Request.Method,
@@ -231,7 +233,7 @@ func handleValidationError(c *gin.Context, err error) {
 		Success:   false,
 		Message:   "Validation failed",
 		Error:     err.Error(),
-		Code:      "VALIDATION_ERROR",
+		ErrorCode: utils.ErrorCodeValidationFailed,
 		Timestamp: time.Now(),
 		Path:      c.Request.URL.Path,
 		Method:    c.Request.Method,
@@ -252,11 +254,11 @@ func SetValidationError(c *gin.Context, err error) {
 }
 
 // CustomError creates a custom error response
-func CustomError(c *gin.Context, statusCode int, message, errorCode string, details interface{}) {
+func CustomError(c *gin.Context, statusCode int, message string, errorCode utils.ErrorCode, details interface{}) {
 	errorResponse := ErrorResponse{
 		Success:   false,
 		Message:   message,
-		Code:      errorCode,
+		ErrorCode: errorCode,
 		Details:   details,
 		Timestamp: time.Now(),
 		Path:      c.Request.URL.Path,
@@ -288,8 +290,8 @@ func LogError(c *gin.Context, err error, level string) {
 	requestID := getRequestID(c)
 	userID := ""
 
-	if user, exists := c.Get("user_id"); exists {
-		userID = user.(string)
+	if id, ok := utils.LookupUserID(c); ok {
+		userID = id.Hex()
 	}
 
 	log.Printf("[%s] Error: %v | Path: %s | Method: %s | UserID: %s | RequestID: %s",
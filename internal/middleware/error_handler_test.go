@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestErrorResponsesUseErrorCodeKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name  string
+		route func(router *gin.Engine)
+		want  utils.ErrorCode
+	}{
+		{"route not found", func(router *gin.Engine) {
+			router.NoRoute(NotFoundHandler())
+		}, utils.ErrorCodeRouteNotFound},
+		{"method not allowed", func(router *gin.Engine) {
+			router.GET("/test", MethodNotAllowedHandler())
+		}, utils.ErrorCodeMethodNotAllowed},
+		{"custom error", func(router *gin.Engine) {
+			router.GET("/test", func(c *gin.Context) {
+				CustomError(c, http.StatusBadRequest, "Bad input", utils.ErrorCodeBadRequest, nil)
+			})
+		}, utils.ErrorCodeBadRequest},
+		{"recovered panic", func(router *gin.Engine) {
+			router.Use(GlobalErrorHandler())
+			router.GET("/test", func(c *gin.Context) { panic("boom") })
+		}, utils.ErrorCodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			tt.route(router)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+			var body map[string]interface{}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if body["error_code"] != string(tt.want) {
+				t.Errorf("error_code = %v, want %s in %s", body["error_code"], tt.want, recorder.Body.String())
+			}
+			if _, ok := body["code"]; ok {
+				t.Errorf("response still carries code: %s", recorder.Body.String())
+			}
+		})
+	}
+}
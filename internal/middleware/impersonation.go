@@ -0,0 +1,125 @@
+// middleware/impersonation.go
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/utils"
+)
+
+// Routes an impersonation token can never use: credentials and sessions,
+// push tokens that would route the user's notifications elsewhere,
+// destructive account settings, bulk content deletion, delegates and staff
+// tools
+var impersonationBlockedRoutes = []string{
+	"/api/v1/auth/change-password",
+	"/api/v1/auth/sessions",
+	"/api/v1/auth/logout",
+	"/api/v1/auth/add-account",
+	"/api/v1/auth/switch",
+	"/api/v1/auth/push-token",
+	"/api/v1/users/deactivate",
+	"/api/v1/users/me/auto-delete",
+	"/api/v1/posts/bulk-delete",
+	"/api/v1/comments/bulk-delete",
+	"/api/v1/delegates",
+	"/api/v1/admin",
+	"/api/v1/moderation",
+}
+
+// impersonationBlocked checks if a route is off limits while impersonating
+func impersonationBlocked(path string) bool {
+	for _, prefix := range impersonationBlockedRoutes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// authorizeImpersonation checks an impersonation token against its session
+// and the route. It returns nil, having written the error response, when
+// the request must stop.
+func (am *AuthMiddleware) authorizeImpersonation(c *gin.Context, claims *JWTClaims, user *models.User) *models.ImpersonationSession {
+	session, err := am.resolveImpersonation(c.Request.Context(), claims, user)
+	if err != nil {
+		utils.ErrorResponseWithCode(c, http.StatusUnauthorized, "Impersonation session is no longer valid", utils.ErrorCodeAuthInvalidToken, nil)
+		c.Abort()
+		return nil
+	}
+
+	if impersonationBlocked(c.FullPath()) {
+		utils.ErrorResponseWithCode(c, http.StatusForbidden, "This action is not available while impersonating a user", utils.ErrorCodeInsufficientPermissions, nil)
+		c.Abort()
+		am.recordImpersonatedRequest(c, session)
+		return nil
+	}
+
+	setImpersonationContext(c, session)
+	return session
+}
+
+// resolveImpersonation loads the session behind an impersonation token and
+// confirms the admin who opened it is still an active super admin
+func (am *AuthMiddleware) resolveImpersonation(ctx context.Context, claims *JWTClaims, user *models.User) (*models.ImpersonationSession, error) {
+	if am.impersonations == nil {
+		return nil, errors.New("impersonation is not enabled")
+	}
+
+	sessionID, err := primitive.ObjectIDFromHex(claims.ImpersonationID)
+	if err != nil {
+		return nil, err
+	}
+
+	admin, err := am.getUserFromDB(claims.ImpersonatedBy)
+	if err != nil {
+		return nil, err
+	}
+	if !admin.IsActive || admin.IsSuspended || admin.Role != models.RoleSuperAdmin {
+		return nil, errors.New("impersonating admin no longer allowed")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return am.impersonations.CheckImpersonation(ctx, sessionID, user.ID, admin.ID)
+}
+
+// recordImpersonatedRequest audits a request made under an impersonation
+// session once its outcome is known
+func (am *AuthMiddleware) recordImpersonatedRequest(c *gin.Context, session *models.ImpersonationSession) {
+	path := c.FullPath()
+	if path == "" {
+		path = c.Request.URL.Path
+	}
+	am.impersonations.RecordRequest(session, c.Request.Method, path, c.Writer.Status(), utils.ClientIP(c), c.GetHeader("User-Agent"))
+}
+
+func setImpersonationContext(c *gin.Context, session *models.ImpersonationSession) {
+	c.Set("impersonated_by", session.AdminID)
+	c.Set("impersonation_id", session.ID)
+}
+
+// GetImpersonatorID returns the admin signed in as the user when the request
+// is made under an impersonation session
+func GetImpersonatorID(c *gin.Context) (primitive.ObjectID, bool) {
+	adminID, exists := c.Get("impersonated_by")
+	if !exists {
+		return primitive.NilObjectID, false
+	}
+	return adminID.(primitive.ObjectID), true
+}
+
+// IsImpersonated checks if the request is made by an admin impersonating the user
+func IsImpersonated(c *gin.Context) bool {
+	_, exists := c.Get("impersonated_by")
+	return exists
+}
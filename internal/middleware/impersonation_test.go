@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestImpersonationBlocked(t *testing.T) {
+	tests := []struct {
+		path    string
+		blocked bool
+	}{
+		{"/api/v1/posts/bulk-delete", true},
+		{"/api/v1/posts/bulk-delete/preview", true},
+		{"/api/v1/posts/bulk-delete/:jobId", true},
+		{"/api/v1/comments/bulk-delete", true},
+		{"/api/v1/comments/bulk-delete/preview", true},
+		{"/api/v1/auth/change-password", true},
+		{"/api/v1/admin/users", true},
+		{"/api/v1/posts/:id", false},
+		{"/api/v1/comments/:id", false},
+		{"/api/v1/posts/feed", false},
+	}
+
+	for _, tt := range tests {
+		if got := impersonationBlocked(tt.path); got != tt.blocked {
+			t.Errorf("impersonationBlocked(%q) = %v, want %v", tt.path, got, tt.blocked)
+		}
+	}
+}
+
+func TestImpersonatedBulkDeleteIsForbidden(t *testing.T) {
+	h := testutil.NewHarness(t)
+
+	admin := h.CreateUser(testutil.WithRole(models.RoleSuperAdmin))
+	user := h.CreateUser()
+
+	impersonations := services.NewImpersonationService(h.DB, nil, testutil.TestJWTSecret, services.ImpersonationPolicy{
+		TokenTTL:   time.Hour,
+		NotifyUser: models.ImpersonationNotifyNever,
+	})
+	session, err := impersonations.StartImpersonation(admin.ID, user.ID, models.StartImpersonationRequest{Reason: "Reproduce a reported bug"}, "127.0.0.1", "test")
+	if err != nil {
+		t.Fatalf("StartImpersonation: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	authMiddleware := NewAuthMiddleware(h.DB, testutil.TestJWTSecret, testutil.TestRefreshSecret, nil, impersonations)
+	router := gin.New()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.POST("/api/v1/posts/bulk-delete", authMiddleware.RequireAuth(), ok)
+	router.POST("/api/v1/comments/bulk-delete", authMiddleware.RequireAuth(), ok)
+	router.GET("/api/v1/posts/feed", authMiddleware.RequireAuth(), ok)
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodPost, "/api/v1/posts/bulk-delete", http.StatusForbidden},
+		{http.MethodPost, "/api/v1/comments/bulk-delete", http.StatusForbidden},
+		{http.MethodGet, "/api/v1/posts/feed", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set("Authorization", "Bearer "+session.AccessToken)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		if recorder.Code != tt.want {
+			t.Errorf("%s %s with an impersonation token = %d, want %d", tt.method, tt.path, recorder.Code, tt.want)
+		}
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"os"
 	"time"
 
+	"social-media-api/internal/utils"
+
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -61,11 +63,8 @@ func (w *CustomLogWriter) WriteHeader(statusCode int) {
 
 // getUserID safely extracts user ID from context
 func getUserID(c *gin.Context) interface{} {
-	if userID, exists := c.Get("user_id"); exists {
-		if objID, ok := userID.(primitive.ObjectID); ok {
-			return objID.Hex()
-		}
-		return userID
+	if userID, ok := utils.LookupUserID(c); ok {
+		return userID.Hex()
 	}
 	return "anonymous"
 }
@@ -105,12 +104,8 @@ func Logger() gin.HandlerFunc {
 
 		// Get user ID if authenticated
 		var userID string
-		if user, exists := c.Get("user_id"); exists {
-			if objectID, ok := user.(primitive.ObjectID); ok {
-				userID = objectID.Hex()
-			} else if strID, ok := user.(string); ok {
-				userID = strID
-			}
+		if id, ok := utils.LookupUserID(c); ok {
+			userID = id.Hex()
 		}
 
 		// Create log entry
@@ -121,7 +116,7 @@ func Logger() gin.HandlerFunc {
 			Path:         c.Request.URL.Path,
 			StatusCode:   customWriter.statusCode,
 			ResponseTime: responseTime,
-			ClientIP:     c.ClientIP(),
+			ClientIP:     utils.ClientIP(c),
 			UserAgent:    c.Request.UserAgent(),
 			UserID:       userID,
 			RequestID:    requestID,
@@ -212,7 +207,7 @@ func PerformanceLogger() gin.HandlerFunc {
 				c.Request.URL.Path,
 				duration,
 				getUserID(c),
-				c.ClientIP(),
+				utils.ClientIP(c),
 			)
 		}
 
@@ -257,7 +252,7 @@ func logRequestDetails(c *gin.Context) {
 	log.Printf("REQUEST: %s %s | IP: %s | UserAgent: %s | ContentLength: %d",
 		c.Request.Method,
 		c.Request.URL.Path,
-		c.ClientIP(),
+		utils.ClientIP(c),
 		c.Request.UserAgent(),
 		c.Request.ContentLength,
 	)
@@ -294,7 +289,7 @@ func logSecurityEvent(c *gin.Context, event string) {
 		event,
 		c.Request.Method,
 		c.Request.URL.Path,
-		c.ClientIP(),
+		utils.ClientIP(c),
 		getUserID(c),
 		c.Request.UserAgent(),
 	)
@@ -322,7 +317,7 @@ func SetAuthEvent(c *gin.Context, eventType string) {
 
 	// You can also log it immediately if preferred
 	userAgent := c.Request.UserAgent()
-	clientIP := c.ClientIP()
+	clientIP := utils.ClientIP(c)
 
 	// Log the authentication event
 	fmt.Printf("AUTH_EVENT: %s | IP: %s | UserAgent: %s | Time: %v\n",
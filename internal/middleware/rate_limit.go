@@ -2,8 +2,12 @@
 package middleware
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,7 +15,6 @@ import (
 	"social-media-api/internal/utils"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // RateLimiter represents a rate limiter
@@ -40,6 +43,9 @@ type RateLimitConfig struct {
 	Headers bool // whether to add rate limit headers
 	Skip    func(*gin.Context) bool
 	OnLimit func(*gin.Context) // callback when rate limit is exceeded
+
+	Limiter *RateLimiter           // shared limiter so several routes draw from one budget
+	Cost    func(*gin.Context) int // requests this request counts as, 1 when unset
 }
 
 // NewRateLimiter creates a new rate limiter
@@ -64,7 +70,10 @@ func NewRateLimiter(rate int, window time.Duration, cleanupInterval ...time.Dura
 }
 
 func RateLimit(config RateLimitConfig) gin.HandlerFunc {
-	limiter := NewRateLimiter(config.Rate, config.Window) // Now works with optional parameter
+	limiter := config.Limiter
+	if limiter == nil {
+		limiter = NewRateLimiter(config.Rate, config.Window) // Now works with optional parameter
+	}
 
 	return gin.HandlerFunc(func(c *gin.Context) {
 		// Skip rate limiting if configured
@@ -79,11 +88,15 @@ func RateLimit(config RateLimitConfig) gin.HandlerFunc {
 			key = config.KeyFunc(c)
 		}
 		if key == "" {
-			key = c.ClientIP()
+			key = utils.ClientIP(c)
 		}
 
 		// Check rate limit
-		allowed, remaining, resetTime := limiter.isAllowed(key)
+		cost := 1
+		if config.Cost != nil {
+			cost = config.Cost(c)
+		}
+		allowed, remaining, resetTime := limiter.allowN(key, cost)
 
 		// Add headers if configured
 		if config.Headers {
@@ -119,7 +132,7 @@ func IPRateLimit(rate int, window time.Duration) gin.HandlerFunc {
 		Rate:   rate,
 		Window: window,
 		KeyFunc: func(c *gin.Context) string {
-			return c.ClientIP()
+			return utils.ClientIP(c)
 		},
 		Headers: true,
 		Message: "Too many requests from this IP address",
@@ -132,19 +145,17 @@ func UserRateLimit(rate int, window time.Duration) gin.HandlerFunc {
 		Rate:   rate,
 		Window: window,
 		KeyFunc: func(c *gin.Context) string {
-			if userID, exists := c.Get("user_id"); exists {
-				if objID, ok := userID.(primitive.ObjectID); ok {
-					return objID.Hex()
-				}
+			if userID, ok := utils.LookupUserID(c); ok {
+				return userID.Hex()
 			}
-			return c.ClientIP() // fallback to IP
+			return utils.ClientIP(c) // fallback to IP
 		},
 		Headers: true,
 		Message: "Too many requests from this user",
 		Skip: func(c *gin.Context) bool {
 			// Skip for unauthenticated users (they'll be limited by IP)
-			_, exists := c.Get("user_id")
-			return !exists
+			_, ok := utils.LookupUserID(c)
+			return !ok
 		},
 	})
 }
@@ -176,7 +187,7 @@ func LoginRateLimit() gin.HandlerFunc {
 		Rate:   5,                // 5 attempts
 		Window: time.Minute * 15, // per 15 minutes
 		KeyFunc: func(c *gin.Context) string {
-			return "login_" + c.ClientIP()
+			return "login_" + utils.ClientIP(c)
 		},
 		Headers: true,
 		Message: "Too many login attempts",
@@ -186,12 +197,34 @@ func LoginRateLimit() gin.HandlerFunc {
 		},
 	})
 }
+
+// AccountSwitchRateLimit limits account switches that carry a password like
+// login attempts. Switching to an account still signed in on the device
+// sends no body and is not limited.
+func AccountSwitchRateLimit() gin.HandlerFunc {
+	return RateLimit(RateLimitConfig{
+		Rate:   5,                // 5 attempts
+		Window: time.Minute * 15, // per 15 minutes
+		KeyFunc: func(c *gin.Context) string {
+			return "account_switch_" + utils.ClientIP(c)
+		},
+		Skip: func(c *gin.Context) bool {
+			return c.Request.ContentLength <= 0
+		},
+		Headers: true,
+		Message: "Too many login attempts",
+		OnLimit: func(c *gin.Context) {
+			SetAuthEvent(c, "LOGIN_RATE_LIMIT_EXCEEDED")
+		},
+	})
+}
+
 func DevLoginRateLimit() gin.HandlerFunc {
 	return RateLimit(RateLimitConfig{
 		Rate:   5000,            // 5 attempts
 		Window: time.Minute * 1, // per 15 minutes
 		KeyFunc: func(c *gin.Context) string {
-			return "login_" + c.ClientIP()
+			return "login_" + utils.ClientIP(c)
 		},
 		Headers: true,
 		Message: "Too many login attempts",
@@ -208,7 +241,7 @@ func RegisterRateLimit() gin.HandlerFunc {
 		Rate:   3,             // 3 registrations
 		Window: time.Hour * 1, // per hour
 		KeyFunc: func(c *gin.Context) string {
-			return "register_" + c.ClientIP()
+			return "register_" + utils.ClientIP(c)
 		},
 		Headers: true,
 		Message: "Too many registration attempts",
@@ -221,22 +254,17 @@ func PostRateLimit() gin.HandlerFunc {
 		Rate:   10,              // 10 posts
 		Window: time.Minute * 5, // per 5 minutes
 		KeyFunc: func(c *gin.Context) string {
-			if userID, exists := c.Get("user_id"); exists {
-				if objID, ok := userID.(primitive.ObjectID); ok {
-					return "post_" + objID.Hex()
-				}
+			if userID, ok := utils.LookupUserID(c); ok {
+				return "post_" + userID.Hex()
 			}
-			return "post_" + c.ClientIP()
+			return "post_" + utils.ClientIP(c)
 		},
 		Headers: true,
 		Message: "Too many posts created",
 		Skip: func(c *gin.Context) bool {
 			// Skip for moderators and admins
-			if userRole, exists := c.Get("user_role"); exists {
-				role := userRole.(models.UserRole)
-				return role == models.RoleModerator || role == models.RoleAdmin || role == models.RoleSuperAdmin
-			}
-			return false
+			role, ok := utils.LookupRole(c)
+			return ok && role.IsModerator()
 		},
 	})
 }
@@ -247,12 +275,10 @@ func CommentRateLimit() gin.HandlerFunc {
 		Rate:   20,              // 20 comments
 		Window: time.Minute * 5, // per 5 minutes
 		KeyFunc: func(c *gin.Context) string {
-			if userID, exists := c.Get("user_id"); exists {
-				if objID, ok := userID.(primitive.ObjectID); ok {
-					return "comment_" + objID.Hex()
-				}
+			if userID, ok := utils.LookupUserID(c); ok {
+				return "comment_" + userID.Hex()
 			}
-			return "comment_" + c.ClientIP()
+			return "comment_" + utils.ClientIP(c)
 		},
 		Headers: true,
 		Message: "Too many comments posted",
@@ -265,34 +291,77 @@ func MessageRateLimit() gin.HandlerFunc {
 		Rate:   50,              // 50 messages
 		Window: time.Minute * 5, // per 5 minutes
 		KeyFunc: func(c *gin.Context) string {
-			if userID, exists := c.Get("user_id"); exists {
-				if objID, ok := userID.(primitive.ObjectID); ok {
-					return "message_" + objID.Hex()
-				}
+			if userID, ok := utils.LookupUserID(c); ok {
+				return "message_" + userID.Hex()
 			}
-			return "message_" + c.ClientIP()
+			return "message_" + utils.ClientIP(c)
 		},
 		Headers: true,
 		Message: "Too many messages sent",
 	})
 }
 
+// Follow actions allowed per window, shared by single and batch follows
+const (
+	followRate   = 30               // 30 follow actions
+	followWindow = time.Minute * 10 // per 10 minutes
+)
+
+var followLimiter = NewRateLimiter(followRate, followWindow)
+
 // FollowRateLimit creates a rate limiter for follow actions
 func FollowRateLimit() gin.HandlerFunc {
-	return RateLimit(RateLimitConfig{
-		Rate:   30,               // 30 follow actions
-		Window: time.Minute * 10, // per 10 minutes
+	return RateLimit(followRateLimitConfig(nil))
+}
+
+// FollowManyRateLimit charges a follow-many request one follow action per
+// distinct target against the same budget as single follows. Batches larger
+// than the remaining budget are rejected whole.
+func FollowManyRateLimit() gin.HandlerFunc {
+	return RateLimit(followRateLimitConfig(followManyCost))
+}
+
+func followRateLimitConfig(cost func(*gin.Context) int) RateLimitConfig {
+	return RateLimitConfig{
+		Rate:    followRate,
+		Window:  followWindow,
+		Limiter: followLimiter,
+		Cost:    cost,
 		KeyFunc: func(c *gin.Context) string {
-			if userID, exists := c.Get("user_id"); exists {
-				if objID, ok := userID.(primitive.ObjectID); ok {
-					return "follow_" + objID.Hex()
-				}
+			if userID, ok := utils.LookupUserID(c); ok {
+				return "follow_" + userID.Hex()
 			}
-			return "follow_" + c.ClientIP()
+			return "follow_" + utils.ClientIP(c)
 		},
 		Headers: true,
 		Message: "Too many follow/unfollow actions",
-	})
+	}
+}
+
+// followManyCost counts the distinct targets of a follow-many request,
+// leaving the body in place for the handler
+func followManyCost(c *gin.Context) int {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 64<<10))
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+	if err != nil {
+		return 1
+	}
+
+	var req models.FollowManyRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return 1
+	}
+
+	targets := make(map[string]bool, len(req.UserIDs))
+	for _, userID := range req.UserIDs {
+		if userID = strings.TrimSpace(userID); userID != "" {
+			targets[userID] = true
+		}
+	}
+	if len(targets) == 0 {
+		return 1
+	}
+	return len(targets)
 }
 
 // LikeRateLimit creates a rate limiter for like actions
@@ -301,30 +370,108 @@ func LikeRateLimit() gin.HandlerFunc {
 		Rate:   100,             // 100 likes
 		Window: time.Minute * 5, // per 5 minutes
 		KeyFunc: func(c *gin.Context) string {
-			if userID, exists := c.Get("user_id"); exists {
-				if objID, ok := userID.(primitive.ObjectID); ok {
-					return "like_" + objID.Hex()
-				}
+			if userID, ok := utils.LookupUserID(c); ok {
+				return "like_" + userID.Hex()
 			}
-			return "like_" + c.ClientIP()
+			return "like_" + utils.ClientIP(c)
 		},
 		Headers: true,
 		Message: "Too many like actions",
 	})
 }
 
+// ImpressionRateLimit creates a rate limiter for client impression batches
+func ImpressionRateLimit() gin.HandlerFunc {
+	return RateLimit(RateLimitConfig{
+		Rate:   60,          // 60 batches
+		Window: time.Minute, // per minute
+		KeyFunc: func(c *gin.Context) string {
+			if userID, ok := utils.LookupUserID(c); ok {
+				return "impression_" + userID.Hex()
+			}
+			return "impression_" + utils.ClientIP(c)
+		},
+		Headers: true,
+		Message: "Too many impression batches",
+	})
+}
+
+// TranslationRateLimit creates a rate limiter for content translation
+func TranslationRateLimit() gin.HandlerFunc {
+	return RateLimit(RateLimitConfig{
+		Rate:   30,          // 30 translations
+		Window: time.Minute, // per minute
+		KeyFunc: func(c *gin.Context) string {
+			if userID, ok := utils.LookupUserID(c); ok {
+				return "translate_" + userID.Hex()
+			}
+			return "translate_" + utils.ClientIP(c)
+		},
+		Headers: true,
+		Message: "Too many translation requests",
+	})
+}
+
+// MentionSuggestionRateLimit creates a rate limiter for mention
+// autocomplete, which clients call on every keystroke
+func MentionSuggestionRateLimit() gin.HandlerFunc {
+	return RateLimit(RateLimitConfig{
+		Rate:   120,         // 120 lookups
+		Window: time.Minute, // per minute
+		KeyFunc: func(c *gin.Context) string {
+			if userID, ok := utils.LookupUserID(c); ok {
+				return "mention_suggest_" + userID.Hex()
+			}
+			return "mention_suggest_" + utils.ClientIP(c)
+		},
+		Headers: true,
+		Message: "Too many mention suggestion requests",
+	})
+}
+
+// PostPreviewRateLimit creates a rate limiter for draft post previews,
+// which clients request as the author types
+func PostPreviewRateLimit() gin.HandlerFunc {
+	return RateLimit(RateLimitConfig{
+		Rate:   60,          // 60 previews
+		Window: time.Minute, // per minute
+		KeyFunc: func(c *gin.Context) string {
+			if userID, ok := utils.LookupUserID(c); ok {
+				return "post_preview_" + userID.Hex()
+			}
+			return "post_preview_" + utils.ClientIP(c)
+		},
+		Headers: true,
+		Message: "Too many post preview requests",
+	})
+}
+
+// ConversationExportRateLimit creates a rate limiter for conversation exports
+func ConversationExportRateLimit() gin.HandlerFunc {
+	return RateLimit(RateLimitConfig{
+		Rate:   5,         // 5 exports
+		Window: time.Hour, // per hour
+		KeyFunc: func(c *gin.Context) string {
+			if userID, ok := utils.LookupUserID(c); ok {
+				return "conversation_export_" + userID.Hex()
+			}
+			return "conversation_export_" + utils.ClientIP(c)
+		},
+		Headers: true,
+		Message: "Too many conversation exports",
+	})
+}
+
 // AdminRateLimit creates a less restrictive rate limiter for admins
 func AdminRateLimit() gin.HandlerFunc {
 	return RateLimit(RateLimitConfig{
 		Rate:   1000,            // 1000 requests
 		Window: time.Minute * 5, // per 5 minutes
 		KeyFunc: func(c *gin.Context) string {
-			if userID, exists := c.Get("user_id"); exists {
-				if objID, ok := userID.(primitive.ObjectID); ok {
-					return "admin_" + objID.Hex()
-				}
+			if userID, ok := utils.LookupUserID(c); ok {
+				return "admin_" + userID.Hex()
 			}
-			return "admin_" + c.ClientIP()
+			return "admin_" + utils.ClientIP(c)
 		},
 		Headers: true,
 		Message: "Admin rate limit exceeded",
@@ -334,6 +481,13 @@ func AdminRateLimit() gin.HandlerFunc {
 // Methods for RateLimiter
 
 func (rl *RateLimiter) isAllowed(key string) (bool, int, time.Time) {
+	return rl.allowN(key, 1)
+}
+
+// allowN records n requests at once, or none when they don't all fit in the
+// window. Only a client already at the limit gets blocked, so a smaller batch
+// can still go through after an oversized one is rejected.
+func (rl *RateLimiter) allowN(key string, n int) (bool, int, time.Time) {
 	rl.mutex.Lock()
 	defer rl.mutex.Unlock()
 
@@ -374,8 +528,15 @@ func (rl *RateLimiter) isAllowed(key string) (bool, int, time.Time) {
 		return false, 0, now.Add(rl.window)
 	}
 
-	// Add current request
-	client.requests = append(client.requests, now)
+	// The whole batch has to fit in what's left of the window
+	if len(client.requests)+n > rl.rate {
+		return false, rl.rate - len(client.requests), client.requests[0].Add(rl.window)
+	}
+
+	// Add current requests
+	for i := 0; i < n; i++ {
+		client.requests = append(client.requests, now)
+	}
 	client.blocked = false
 
 	remaining := rl.rate - len(client.requests)
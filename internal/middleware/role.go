@@ -13,16 +13,15 @@ import (
 // RequireAdmin checks if user has admin or super admin role
 func RequireAdmin() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
-		userRole, exists := c.Get("user_role")
-		if !exists {
+		role, ok := utils.LookupRole(c)
+		if !ok {
 			utils.ErrorResponse(c, http.StatusUnauthorized, "Authentication required", nil)
 			c.Abort()
 			return
 		}
 
-		role := userRole.(models.UserRole)
-		if role != models.RoleAdmin && role != models.RoleSuperAdmin {
-			utils.ErrorResponse(c, http.StatusForbidden, "Admin access required", nil)
+		if !role.IsAdmin() {
+			utils.ErrorResponseWithCode(c, http.StatusForbidden, "Admin access required", utils.ErrorCodeInsufficientPermissions, nil)
 			c.Abort()
 			return
 		}
@@ -34,16 +33,15 @@ func RequireAdmin() gin.HandlerFunc {
 // RequireSuperAdmin checks if user has super admin role
 func RequireSuperAdmin() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
-		userRole, exists := c.Get("user_role")
-		if !exists {
+		role, ok := utils.LookupRole(c)
+		if !ok {
 			utils.ErrorResponse(c, http.StatusUnauthorized, "Authentication required", nil)
 			c.Abort()
 			return
 		}
 
-		role := userRole.(models.UserRole)
 		if role != models.RoleSuperAdmin {
-			utils.ErrorResponse(c, http.StatusForbidden, "Super admin access required", nil)
+			utils.ErrorResponseWithCode(c, http.StatusForbidden, "Super admin access required", utils.ErrorCodeInsufficientPermissions, nil)
 			c.Abort()
 			return
 		}
@@ -55,16 +53,15 @@ func RequireSuperAdmin() gin.HandlerFunc {
 // RequireModerator checks if user has moderator role or higher
 func RequireModerator() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
-		userRole, exists := c.Get("user_role")
-		if !exists {
+		role, ok := utils.LookupRole(c)
+		if !ok {
 			utils.ErrorResponse(c, http.StatusUnauthorized, "Authentication required", nil)
 			c.Abort()
 			return
 		}
 
-		role := userRole.(models.UserRole)
-		if role != models.RoleModerator && role != models.RoleAdmin && role != models.RoleSuperAdmin {
-			utils.ErrorResponse(c, http.StatusForbidden, "Moderator access required", nil)
+		if !role.IsModerator() {
+			utils.ErrorResponseWithCode(c, http.StatusForbidden, "Moderator access required", utils.ErrorCodeInsufficientPermissions, nil)
 			c.Abort()
 			return
 		}
@@ -81,15 +78,13 @@ func RequireStaff() gin.HandlerFunc {
 // RequireRole checks if user has any of the specified roles
 func RequireRole(roles ...models.UserRole) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
-		userRole, exists := c.Get("user_role")
-		if !exists {
+		currentRole, ok := utils.LookupRole(c)
+		if !ok {
 			utils.ErrorResponse(c, http.StatusUnauthorized, "Authentication required", nil)
 			c.Abort()
 			return
 		}
 
-		currentRole := userRole.(models.UserRole)
-
 		// Check if user has any of the required roles
 		for _, role := range roles {
 			if currentRole == role {
@@ -98,7 +93,7 @@ func RequireRole(roles ...models.UserRole) gin.HandlerFunc {
 			}
 		}
 
-		utils.ErrorResponse(c, http.StatusForbidden, "Insufficient permissions", nil)
+		utils.ErrorResponseWithCode(c, http.StatusForbidden, "Insufficient permissions", utils.ErrorCodeInsufficientPermissions, nil)
 		c.Abort()
 	})
 }
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequireRoleMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		middleware gin.HandlerFunc
+		role       interface{}
+		want       int
+	}{
+		{"admin allowed", RequireAdmin(), models.RoleAdmin, http.StatusOK},
+		{"super admin is an admin", RequireAdmin(), models.RoleSuperAdmin, http.StatusOK},
+		{"moderator is not an admin", RequireAdmin(), models.RoleModerator, http.StatusForbidden},
+		{"moderator allowed", RequireModerator(), models.RoleModerator, http.StatusOK},
+		{"user is not a moderator", RequireModerator(), models.RoleUser, http.StatusForbidden},
+		{"admin is not a super admin", RequireSuperAdmin(), models.RoleAdmin, http.StatusForbidden},
+		{"listed role allowed", RequireRole(models.RoleModerator), models.RoleModerator, http.StatusOK},
+		{"no role", RequireAdmin(), nil, http.StatusUnauthorized},
+		{"role of the wrong type", RequireAdmin(), "admin", http.StatusUnauthorized},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", func(c *gin.Context) {
+				if tt.role != nil {
+					c.Set(utils.ContextUserRole, tt.role)
+				}
+				c.Next()
+			}, tt.middleware, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 
+	"social-media-api/internal/models"
 	"social-media-api/internal/utils"
 
 	"github.com/gin-gonic/gin"
@@ -113,7 +114,7 @@ func ValidateJSON(model interface{}) gin.HandlerFunc {
 
 		// Bind JSON to model
 		if err := c.ShouldBindJSON(newModel); err != nil {
-			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid JSON format", nil)
+			utils.ErrorResponseWithCode(c, http.StatusBadRequest, "Invalid JSON format", utils.ErrorCodeInvalidJSON, nil)
 			c.Abort()
 			return
 		}
@@ -389,15 +390,7 @@ func validateUserRole(fl validator.FieldLevel) bool {
 }
 
 func validateReactionType(fl validator.FieldLevel) bool {
-	reaction := fl.Field().String()
-	validReactions := []string{"like", "love", "haha", "wow", "sad", "angry", "support"}
-
-	for _, validReaction := range validReactions {
-		if reaction == validReaction {
-			return true
-		}
-	}
-	return false
+	return models.IsValidReactionType(models.ReactionType(fl.Field().String()))
 }
 
 func validateContentType(fl validator.FieldLevel) bool {
@@ -518,7 +511,7 @@ func getValidationMessage(fe validator.FieldError) string {
 	case "user_role":
 		return fmt.Sprintf("%s must be one of: user, moderator, admin, super_admin", fe.Field())
 	case "reaction_type":
-		return fmt.Sprintf("%s must be one of: like, love, haha, wow, sad, angry, support", fe.Field())
+		return fmt.Sprintf("%s must be a valid reaction type", fe.Field())
 	case "content_type":
 		return fmt.Sprintf("%s must be one of: text, image, video, audio, file, link, gif, poll", fe.Field())
 	case "notification_type":
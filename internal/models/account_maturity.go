@@ -0,0 +1,133 @@
+// models/account_maturity.go
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Actions a new account may have to wait for
+const (
+	MaturityActionMessageStrangers = "message_strangers" // Direct messages to users who don't follow the sender
+	MaturityActionReport           = "report"
+	MaturityActionCreateGroup      = "create_group"
+)
+
+// AccountMaturityPolicy is what an account needs before it can take an
+// action: a minimum age, a verified email, or both. A zero MinAccountAge and
+// no email requirement leave the action open to everyone.
+type AccountMaturityPolicy struct {
+	Action               string
+	MinAccountAge        time.Duration
+	RequireVerifiedEmail bool
+	ExemptVerified       bool // Accounts with the verified badge skip the policy
+	ExemptPremium        bool // Accounts with an active premium subscription skip the policy
+}
+
+// AccountMaturityPolicies holds the policy of each gated action
+type AccountMaturityPolicies struct {
+	MessageStrangers AccountMaturityPolicy
+	Report           AccountMaturityPolicy
+	CreateGroup      AccountMaturityPolicy
+}
+
+// Enabled checks if the policy requires anything
+func (p AccountMaturityPolicy) Enabled() bool {
+	return p.MinAccountAge > 0 || p.RequireVerifiedEmail
+}
+
+// Check returns an AccountTooNewError if the user doesn't meet the policy.
+// Staff are never held back.
+func (p AccountMaturityPolicy) Check(u *User, now time.Time) error {
+	if !p.Enabled() {
+		return nil
+	}
+	switch u.Role {
+	case RoleModerator, RoleAdmin, RoleSuperAdmin:
+		return nil
+	}
+	if p.ExemptVerified && u.IsVerified {
+		return nil
+	}
+	if p.ExemptPremium && u.IsPremium && (u.PremiumExpiry == nil || now.Before(*u.PremiumExpiry)) {
+		return nil
+	}
+
+	availableAt := u.CreatedAt.Add(p.MinAccountAge)
+	oldEnough := !now.Before(availableAt)
+	emailOK := !p.RequireVerifiedEmail || u.EmailVerified
+	if oldEnough && emailOK {
+		return nil
+	}
+
+	err := &AccountTooNewError{
+		Action:               p.Action,
+		RequireVerifiedEmail: p.RequireVerifiedEmail,
+		EmailVerified:        u.EmailVerified,
+		minAccountAge:        p.MinAccountAge,
+	}
+	if p.MinAccountAge > 0 {
+		err.MinAccountAgeHours = p.MinAccountAge.Hours()
+		if !oldEnough {
+			err.AvailableAt = &availableAt
+		}
+	}
+	return err
+}
+
+// AccountTooNewError names the action and the requirement the account hasn't met
+type AccountTooNewError struct {
+	Action               string     `json:"action"`
+	MinAccountAgeHours   float64    `json:"min_account_age_hours,omitempty"`
+	RequireVerifiedEmail bool       `json:"require_verified_email"`
+	EmailVerified        bool       `json:"email_verified"`
+	AvailableAt          *time.Time `json:"available_at,omitempty"` // When the account is old enough
+
+	minAccountAge time.Duration
+}
+
+func (e *AccountTooNewError) Error() string {
+	var requirements []string
+	if e.minAccountAge > 0 {
+		requirements = append(requirements, "be at least "+formatDuration(e.minAccountAge)+" old")
+	}
+	if e.RequireVerifiedEmail {
+		requirements = append(requirements, "have a verified email")
+	}
+	return fmt.Sprintf("your account is too new to %s: it must %s", maturityActionPhrase(e.Action), strings.Join(requirements, " and "))
+}
+
+// maturityActionPhrase describes the action for error messages
+func maturityActionPhrase(action string) string {
+	switch action {
+	case MaturityActionMessageStrangers:
+		return "message people who don't follow you"
+	case MaturityActionReport:
+		return "report content"
+	case MaturityActionCreateGroup:
+		return "create groups"
+	}
+	return "do this"
+}
+
+// formatDuration renders a duration in whole days, hours or minutes where it can
+func formatDuration(d time.Duration) string {
+	day := 24 * time.Hour
+	switch {
+	case d%day == 0:
+		return pluralize(int(d/day), "day")
+	case d%time.Hour == 0:
+		return pluralize(int(d/time.Hour), "hour")
+	case d%time.Minute == 0:
+		return pluralize(int(d/time.Minute), "minute")
+	}
+	return d.String()
+}
+
+func pluralize(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,30 @@
+// models/activity.go
+package models
+
+import (
+	"time"
+)
+
+// ActivityType discriminates the entries of a user's activity log
+type ActivityType string
+
+const (
+	ActivityPost      ActivityType = "post"
+	ActivityComment   ActivityType = "comment"
+	ActivityLike      ActivityType = "like"
+	ActivityFollow    ActivityType = "follow"
+	ActivityGroupJoin ActivityType = "group_join"
+)
+
+// ActivityLogEntry is one of the user's own actions in their activity log.
+// Entries are built on the fly from the collections that record each action.
+type ActivityLogEntry struct {
+	Type         ActivityType `json:"type"`
+	ID           string       `json:"id"`                      // ID of the post, comment, like, follow or membership
+	TargetID     string       `json:"target_id,omitempty"`     // What the action was taken on
+	TargetType   string       `json:"target_type,omitempty"`   // post, comment, story, user or group
+	Preview      string       `json:"preview,omitempty"`       // Start of the post or comment text
+	ReactionType ReactionType `json:"reaction_type,omitempty"` // Set for likes
+	Status       string       `json:"status,omitempty"`        // Set for follows and group joins
+	CreatedAt    time.Time    `json:"created_at"`
+}
@@ -370,7 +370,8 @@ type AuditLog struct {
 
 	Action     string                 `json:"action" bson:"action"`
 	ActorID    primitive.ObjectID     `json:"actor_id" bson:"actor_id"`
-	ActorType  string                 `json:"actor_type" bson:"actor_type"` // user, admin, system
+	ActorType  string                 `json:"actor_type" bson:"actor_type"`                     // user, admin, system, cli
+	ActorName  string                 `json:"actor_name,omitempty" bson:"actor_name,omitempty"` // e.g. "cli:<hostname>" when there is no actor ID
 	TargetType string                 `json:"target_type" bson:"target_type"`
 	TargetID   primitive.ObjectID     `json:"target_id" bson:"target_id"`
 	Changes    map[string]interface{} `json:"changes,omitempty" bson:"changes,omitempty"`
@@ -380,6 +381,9 @@ type AuditLog struct {
 	IPAddress  string                 `json:"ip_address" bson:"ip_address"`
 	UserAgent  string                 `json:"user_agent" bson:"user_agent"`
 	SessionID  string                 `json:"session_id,omitempty" bson:"session_id,omitempty"`
+
+	// The admin behind the action when it was made while impersonating the actor
+	ImpersonatedBy *primitive.ObjectID `json:"impersonated_by,omitempty" bson:"impersonated_by,omitempty"`
 }
 
 // ==================== EXPORT MODELS ====================
@@ -397,6 +401,7 @@ type ExportJob struct {
 	Progress     float64                `json:"progress" bson:"progress"`
 	FileURL      string                 `json:"file_url,omitempty" bson:"file_url,omitempty"`
 	FileSize     int64                  `json:"file_size,omitempty" bson:"file_size,omitempty"`
+	Files        []ExportFile           `json:"files,omitempty" bson:"files,omitempty"`
 	RecordCount  int64                  `json:"record_count" bson:"record_count"`
 	ErrorMessage string                 `json:"error_message,omitempty" bson:"error_message,omitempty"`
 	StartedAt    *time.Time             `json:"started_at,omitempty" bson:"started_at,omitempty"`
@@ -404,6 +409,13 @@ type ExportJob struct {
 	ExpiresAt    time.Time              `json:"expires_at" bson:"expires_at"`
 }
 
+// ExportFile is one downloadable file of an export job
+type ExportFile struct {
+	Name string `json:"name" bson:"name"`
+	URL  string `json:"url" bson:"url"`
+	Size int64  `json:"size" bson:"size"`
+}
+
 // ==================== ALERT MODELS ====================
 
 // SystemAlert represents system alerts
@@ -0,0 +1,96 @@
+// models/analytics_export.go
+package models
+
+import "time"
+
+// AnalyticsExportSchemaVersion is bumped whenever an export's columns change
+const AnalyticsExportSchemaVersion = 1
+
+// Analytics export formats
+const (
+	AnalyticsExportCSV    = "csv"
+	AnalyticsExportNDJSON = "ndjson" // One JSON object per line
+)
+
+// AnalyticsExportManifestFile is written next to the exported files
+const AnalyticsExportManifestFile = "manifest.json"
+
+// AnalyticsExportDataType is the ExportJob data type of behavior analytics exports
+const AnalyticsExportDataType = "behavior_analytics"
+
+// AnalyticsExportColumns is the documented, stable schema of each exported
+// collection, in column order. Times are RFC 3339 in UTC, durations are
+// milliseconds, and list columns are joined with ";". With anonymize on,
+// user_id and session_id are replaced by keyed hashes that are consistent
+// within one export, so the files can still be joined on them. IP addresses
+// and user agents are never exported.
+var AnalyticsExportColumns = map[string][]string{
+	"user_sessions": {
+		"id", "user_id", "session_id", "start_time", "end_time", "duration_ms",
+		"device_info", "pages_visited", "actions", "created_at",
+	},
+	"content_engagements": {
+		"id", "user_id", "content_id", "content_type", "view_time", "view_duration_ms",
+		"scroll_depth", "interaction_types", "source", "created_at",
+	},
+	"user_journeys": {
+		"id", "user_id", "session_id", "goal", "completed", "duration_ms",
+		"touchpoints", "touchpoint_pages", "created_at",
+	},
+	"recommendation_events": {
+		"id", "user_id", "recommendation_type", "item_id", "algorithm", "score",
+		"position", "presented_at", "clicked_at", "converted_at", "feedback", "created_at",
+	},
+}
+
+// AnalyticsExportCollections lists the exported collections in export order
+var AnalyticsExportCollections = []string{"user_sessions", "content_engagements", "user_journeys", "recommendation_events"}
+
+// AnalyticsExportOptions selects what an analytics export contains. From is
+// inclusive and To exclusive, both matched against created_at.
+type AnalyticsExportOptions struct {
+	From      time.Time
+	To        time.Time
+	Format    string
+	Anonymize bool
+	OutputDir string
+	Restart   bool // Discard the progress of an interrupted export in OutputDir
+}
+
+// AnalyticsExportManifest describes an analytics export. It doubles as the
+// progress record that lets an interrupted export resume per collection.
+type AnalyticsExportManifest struct {
+	SchemaVersion int                         `json:"schema_version"`
+	Format        string                      `json:"format"`
+	From          time.Time                   `json:"from"`
+	To            time.Time                   `json:"to"`
+	Anonymized    bool                        `json:"anonymized"`
+	StartedAt     time.Time                   `json:"started_at"`
+	CompletedAt   *time.Time                  `json:"completed_at,omitempty"`
+	Files         []AnalyticsExportFileStatus `json:"files"`
+}
+
+// AnalyticsExportFileStatus is one collection's file in an export
+type AnalyticsExportFileStatus struct {
+	Collection string   `json:"collection"`
+	File       string   `json:"file"`
+	Columns    []string `json:"columns"`
+	Rows       int64    `json:"rows"`
+	Excluded   int64    `json:"excluded"` // Rows of users who opted out of analytics
+	Completed  bool     `json:"completed"`
+	LastID     string   `json:"last_id,omitempty"` // Resume point while incomplete
+	Bytes      int64    `json:"bytes"`             // File size at the resume point
+}
+
+// StartAnalyticsExportRequest represents the admin request to export behavior analytics
+type StartAnalyticsExportRequest struct {
+	From      time.Time `json:"from" validate:"required"`
+	To        time.Time `json:"to" validate:"required,gtfield=From"`
+	Format    string    `json:"format" validate:"omitempty,oneof=csv ndjson"`
+	Anonymize bool      `json:"anonymize"`
+}
+
+// IsValidAnalyticsExportFormat checks if the export format is supported
+func IsValidAnalyticsExportFormat(format string) bool {
+	return format == AnalyticsExportCSV || format == AnalyticsExportNDJSON
+}
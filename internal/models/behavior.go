@@ -63,6 +63,28 @@ type Interaction struct {
 	Value     string    `bson:"value,omitempty" json:"value,omitempty"` // for reactions
 }
 
+// Impression limits for client-reported post impressions
+const (
+	MaxImpressionsPerBatch  = 100
+	MaxImpressionDurationMs = 10 * 60 * 1000 // anything longer is a backgrounded tab
+	SeenImpressionMinMs     = 1000           // visible for at least a second counts as seen
+)
+
+// PostImpression is a single post the client reports as having been on screen
+type PostImpression struct {
+	PostID          string `json:"post_id" validate:"required,len=24,hexadecimal"`
+	VisibleDuration int64  `json:"visible_duration" validate:"gte=0"` // milliseconds
+	Engaged         bool   `json:"engaged"`
+	Source          string `json:"source,omitempty" validate:"omitempty,max=50"`               // feed, profile, search
+	BoostID         string `json:"boost_id,omitempty" validate:"omitempty,len=24,hexadecimal"` // Set for sponsored feed slots
+}
+
+// RecordImpressionsRequest is a batch of client-reported post impressions
+type RecordImpressionsRequest struct {
+	SessionID   string           `json:"session_id,omitempty"`
+	Impressions []PostImpression `json:"impressions" validate:"required,min=1,dive"`
+}
+
 // UserJourney tracks user journey through the application
 type UserJourney struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
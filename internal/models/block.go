@@ -0,0 +1,17 @@
+// models/block.go
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BlockedUser represents one user blocking another, stored in the blocked_users
+// collection instead of an ever-growing array on the user document
+type BlockedUser struct {
+	BaseModel `bson:",inline"`
+
+	BlockerID primitive.ObjectID `json:"blocker_id" bson:"blocker_id"`
+	BlockedID primitive.ObjectID `json:"blocked_id" bson:"blocked_id"`
+	Reason    string             `json:"reason,omitempty" bson:"reason,omitempty"`
+	IsActive  bool               `json:"is_active" bson:"is_active"`
+}
@@ -0,0 +1,124 @@
+// models/boosted_post.go
+package models
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BoostAudience selects who a boosted post is delivered to
+type BoostAudience string
+
+const (
+	BoostAudienceFollowersOfFollowers BoostAudience = "followers_of_followers" // Users following one of the author's followers
+	BoostAudienceInterests            BoostAudience = "interests"              // Users whose interests match the campaign hashtags
+	BoostAudienceRegion               BoostAudience = "region"                 // Users whose country is one of the campaign regions
+)
+
+// BoostStatus is the delivery state of a boost campaign
+type BoostStatus string
+
+const (
+	BoostStatusActive BoostStatus = "active"
+	BoostStatusPaused BoostStatus = "paused"
+)
+
+// BoostPauseBudgetExhausted is recorded when delivery stops because the
+// impression budget ran out
+const BoostPauseBudgetExhausted = "budget_exhausted"
+
+// BoostedPost is a campaign promoting a post into the home feed of users who
+// don't follow its author yet, stored in the boosted_posts collection.
+// Impressions and taps are running totals; per-user delivery lives in
+// boost_deliveries.
+type BoostedPost struct {
+	BaseModel `bson:",inline"`
+
+	PostID    primitive.ObjectID `json:"post_id" bson:"post_id"`
+	AuthorID  primitive.ObjectID `json:"author_id" bson:"author_id"`
+	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`
+
+	// Targeting
+	Audience BoostAudience `json:"audience" bson:"audience"`
+	Hashtags []string      `json:"hashtags,omitempty" bson:"hashtags,omitempty"` // Interest audience; defaults to the post's hashtags
+	Regions  []string      `json:"regions,omitempty" bson:"regions,omitempty"`   // Region audience, ISO 3166-1 alpha-2
+
+	// Schedule and budget
+	StartsAt          time.Time `json:"starts_at" bson:"starts_at"`
+	EndsAt            time.Time `json:"ends_at" bson:"ends_at"`
+	ImpressionBudget  int64     `json:"impression_budget" bson:"impression_budget"`
+	DailyFrequencyCap int       `json:"daily_frequency_cap" bson:"daily_frequency_cap"` // Deliveries per user per day
+
+	Status      BoostStatus `json:"status" bson:"status"`
+	PauseReason string      `json:"pause_reason,omitempty" bson:"pause_reason,omitempty"`
+
+	Impressions int64 `json:"impressions" bson:"impressions"`
+	Taps        int64 `json:"taps" bson:"taps"`
+}
+
+// BoostDelivery tracks one user's exposure to a campaign on one day, for
+// frequency capping and reach
+type BoostDelivery struct {
+	ID               primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	BoostID          primitive.ObjectID `json:"boost_id" bson:"boost_id"`
+	UserID           primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Day              string             `json:"day" bson:"day"` // UTC date, 2006-01-02
+	Served           int                `json:"served" bson:"served"`
+	Impressions      int64              `json:"impressions" bson:"impressions"`
+	Taps             int64              `json:"taps" bson:"taps"`
+	FirstServedAt    time.Time          `json:"first_served_at" bson:"first_served_at"`
+	LastImpressionAt *time.Time         `json:"last_impression_at,omitempty" bson:"last_impression_at,omitempty"`
+}
+
+// CreateBoostedPostRequest represents the request to start a boost campaign
+type CreateBoostedPostRequest struct {
+	PostID            string        `json:"post_id" validate:"required,len=24,hexadecimal"`
+	Audience          BoostAudience `json:"audience" validate:"required,oneof=followers_of_followers interests region"`
+	Hashtags          []string      `json:"hashtags,omitempty" validate:"omitempty,max=20,dive,min=1,max=100"`
+	Regions           []string      `json:"regions,omitempty" validate:"omitempty,max=50,dive,len=2"`
+	StartsAt          *time.Time    `json:"starts_at,omitempty"`
+	EndsAt            time.Time     `json:"ends_at" validate:"required"`
+	ImpressionBudget  int64         `json:"impression_budget" validate:"required,min=1"`
+	DailyFrequencyCap int           `json:"daily_frequency_cap,omitempty" validate:"omitempty,min=1,max=20"`
+}
+
+// UpdateBoostedPostRequest represents the request to change a boost campaign
+type UpdateBoostedPostRequest struct {
+	Status            *BoostStatus `json:"status,omitempty" validate:"omitempty,oneof=active paused"`
+	EndsAt            *time.Time   `json:"ends_at,omitempty"`
+	ImpressionBudget  *int64       `json:"impression_budget,omitempty" validate:"omitempty,min=1"`
+	DailyFrequencyCap *int         `json:"daily_frequency_cap,omitempty" validate:"omitempty,min=1,max=20"`
+}
+
+// BoostPerformance summarizes a campaign's delivery
+type BoostPerformance struct {
+	BoostID          string      `json:"boost_id"`
+	Status           BoostStatus `json:"status"`
+	PauseReason      string      `json:"pause_reason,omitempty"`
+	Impressions      int64       `json:"impressions"`
+	ImpressionBudget int64       `json:"impression_budget"`
+	UniqueReach      int64       `json:"unique_reach"`
+	Taps             int64       `json:"taps"`
+	TapRate          float64     `json:"tap_rate"` // Taps per impression
+	ResultingFollows int64       `json:"resulting_follows"`
+}
+
+// IsDelivering checks if the campaign may be shown at the given time
+func (b *BoostedPost) IsDelivering(now time.Time) bool {
+	return b.Status == BoostStatusActive && b.DeletedAt == nil &&
+		!now.Before(b.StartsAt) && now.Before(b.EndsAt) &&
+		b.Impressions < b.ImpressionBudget
+}
+
+// TargetsRegion checks if a user in the given country is in the campaign's regions
+func (b *BoostedPost) TargetsRegion(country string) bool {
+	country = strings.ToUpper(country)
+	for _, region := range b.Regions {
+		if country != "" && region == country {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+// models/bounds.go
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Arrays of IDs stored on a document grow with activity and eventually hit the
+// 16MB document cap. Every []primitive.ObjectID persisted on a model must
+// declare its maximum length with a `bound:"N"` tag; anything that can grow
+// without limit belongs in its own collection instead (see BlockedUser).
+// Services refuse writes past the bound with CheckBounds.
+
+var objectIDSliceType = reflect.TypeOf([]primitive.ObjectID{})
+
+// PersistedModels returns the documents stored in MongoDB that are checked for unbounded arrays
+func PersistedModels() []interface{} {
+	return []interface{}{
+		User{}, Post{}, Comment{}, Conversation{}, Message{}, Story{}, StoryHighlight{},
+		Event{}, Media{}, Report{}, Group{}, Notification{}, Survey{}, BlockedUser{},
+		UserSuggestions{}, GroupFile{}, Incident{},
+	}
+}
+
+// CheckSliceBounds reports ID slice fields that are missing a positive `bound` tag
+func CheckSliceBounds(docs ...interface{}) []string {
+	var issues []string
+	for _, doc := range docs {
+		t := reflect.TypeOf(doc)
+		issues = append(issues, checkStructBounds(t, t.Name(), map[reflect.Type]bool{})...)
+	}
+	return issues
+}
+
+func checkStructBounds(t reflect.Type, path string, seen map[reflect.Type]bool) []string {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return nil
+	}
+	seen[t] = true
+
+	var issues []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || strings.HasPrefix(field.Tag.Get("bson"), "-") {
+			continue
+		}
+
+		fieldPath := path + "." + field.Name
+		if field.Type == objectIDSliceType {
+			if bound, err := strconv.Atoi(field.Tag.Get("bound")); err != nil || bound <= 0 {
+				issues = append(issues, fmt.Sprintf("%s has no bound tag", fieldPath))
+			}
+			continue
+		}
+
+		issues = append(issues, checkStructBounds(field.Type, fieldPath, seen)...)
+	}
+	return issues
+}
+
+// BoundExceededError reports an ID array written past its `bound` tag
+type BoundExceededError struct {
+	Field string `json:"field"`
+	Bound int    `json:"bound"`
+}
+
+func (e *BoundExceededError) Error() string {
+	return fmt.Sprintf("too many %s: at most %d allowed", strings.ReplaceAll(e.Field, "_", " "), e.Bound)
+}
+
+// CheckBounds returns a *BoundExceededError for the first slice in doc longer
+// than its `bound` tag. Services call it before writing a document or
+// a $set of its arrays, passing a model with just those fields filled in.
+func CheckBounds(doc interface{}) error {
+	return checkValueBounds(reflect.ValueOf(doc))
+}
+
+func checkValueBounds(v reflect.Value) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		bsonTag := field.Tag.Get("bson")
+		if !field.IsExported() || strings.HasPrefix(bsonTag, "-") {
+			continue
+		}
+
+		if bound, err := strconv.Atoi(field.Tag.Get("bound")); err == nil && field.Type.Kind() == reflect.Slice {
+			if v.Field(i).Len() > bound {
+				name := strings.Split(bsonTag, ",")[0]
+				if name == "" {
+					name = strings.ToLower(field.Name)
+				}
+				return &BoundExceededError{Field: name, Bound: bound}
+			}
+			continue
+		}
+
+		if field.Type.Kind() == reflect.Struct || field.Type.Kind() == reflect.Ptr {
+			if err := checkValueBounds(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
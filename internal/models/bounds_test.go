@@ -0,0 +1,145 @@
+package models
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPersistedModelsDeclareSliceBounds(t *testing.T) {
+	for _, issue := range CheckSliceBounds(PersistedModels()...) {
+		t.Error(issue)
+	}
+}
+
+// TestModelSourcesDeclareSliceBounds reads the model sources rather than the
+// PersistedModels list, so a new document type can't skip the check by not
+// being registered. Every []primitive.ObjectID field stored in MongoDB, and
+// any other persisted slice already tagged, needs a positive bound.
+func TestModelSourcesDeclareSliceBounds(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	checked := 0
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		parsed, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", file, err)
+		}
+
+		ast.Inspect(parsed, func(node ast.Node) bool {
+			spec, ok := node.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			structType, ok := spec.Type.(*ast.StructType)
+			if !ok {
+				return true
+			}
+
+			for _, field := range structType.Fields.List {
+				array, ok := field.Type.(*ast.ArrayType)
+				if !ok || array.Len != nil || field.Tag == nil || len(field.Names) == 0 {
+					continue
+				}
+				tagValue, err := strconv.Unquote(field.Tag.Value)
+				if err != nil {
+					t.Fatalf("%s: malformed tag on %s.%s", fset.Position(field.Pos()), spec.Name.Name, field.Names[0].Name)
+				}
+				tag := reflect.StructTag(tagValue)
+
+				bsonName, persisted := tag.Lookup("bson")
+				if !persisted || strings.HasPrefix(bsonName, "-") {
+					continue
+				}
+				bound, tagged := tag.Lookup("bound")
+				if !tagged && !isObjectIDExpr(array.Elt) {
+					continue
+				}
+
+				checked++
+				if n, err := strconv.Atoi(bound); err != nil || n <= 0 {
+					t.Errorf("%s: %s.%s is a persisted ID slice without a positive bound tag; give it `bound:\"N\"` or move it to its own collection",
+						fset.Position(field.Pos()), spec.Name.Name, field.Names[0].Name)
+				}
+			}
+			return true
+		})
+	}
+
+	if checked == 0 {
+		t.Fatal("no bounded slice fields found; is the test running in the models directory?")
+	}
+}
+
+func TestCheckSliceBoundsReportsMissingTags(t *testing.T) {
+	type nested struct {
+		Unbounded []primitive.ObjectID `bson:"unbounded"`
+	}
+	type document struct {
+		Bounded   []primitive.ObjectID `bson:"bounded" bound:"10"`
+		Zero      []primitive.ObjectID `bson:"zero" bound:"0"`
+		Ignored   []primitive.ObjectID `bson:"-"`
+		Nested    nested               `bson:"nested"`
+		NestedPtr *nested              `bson:"nested_ptr"`
+	}
+
+	issues := CheckSliceBounds(document{})
+	want := []string{"document.Zero has no bound tag", "document.Nested.Unbounded has no bound tag"}
+	if !reflect.DeepEqual(issues, want) {
+		t.Errorf("issues = %q, want %q", issues, want)
+	}
+}
+
+func TestCheckBoundsRefusesOverlongSlices(t *testing.T) {
+	ids := func(n int) []primitive.ObjectID {
+		return make([]primitive.ObjectID, n)
+	}
+
+	if err := CheckBounds(&Post{Mentions: ids(50)}); err != nil {
+		t.Errorf("50 mentions: %v, want them allowed", err)
+	}
+
+	tests := []struct {
+		name string
+		doc  interface{}
+		want BoundExceededError
+	}{
+		{"post mentions", &Post{Mentions: ids(51)}, BoundExceededError{Field: "mentions", Bound: 50}},
+		{"conversation participants", Conversation{Participants: ids(501)}, BoundExceededError{Field: "participants", Bound: 500}},
+		{"nested survey audience", &Survey{Audience: SurveyAudience{UserIDs: ids(10001)}}, BoundExceededError{Field: "user_ids", Bound: 10000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var boundErr *BoundExceededError
+			if err := CheckBounds(tt.doc); !errors.As(err, &boundErr) || *boundErr != tt.want {
+				t.Errorf("CheckBounds = %v, want %+v", err, tt.want)
+			}
+		})
+	}
+}
+
+// isObjectIDExpr reports whether expr is primitive.ObjectID
+func isObjectIDExpr(expr ast.Expr) bool {
+	selector, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := selector.X.(*ast.Ident)
+	return ok && pkg.Name == "primitive" && selector.Sel.Name == "ObjectID"
+}
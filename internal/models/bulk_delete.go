@@ -0,0 +1,95 @@
+// models/bulk_delete.go
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Content a user can bulk delete
+const (
+	BulkDeletePosts    = "posts"
+	BulkDeleteComments = "comments"
+)
+
+// Bulk delete job statuses
+const (
+	BulkDeleteQueued     = "queued"
+	BulkDeleteProcessing = "processing"
+	BulkDeleteCompleted  = "completed"
+	BulkDeleteFailed     = "failed"
+)
+
+// BulkDeleteFilter selects a user's own posts or comments: the listed IDs,
+// everything created between From (inclusive) and To (exclusive), or the
+// listed IDs within that range. Either bound of the range may be left open.
+type BulkDeleteFilter struct {
+	IDs  []string   `json:"ids,omitempty" bson:"ids,omitempty"`
+	From *time.Time `json:"from,omitempty" bson:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty" bson:"to,omitempty"`
+}
+
+// Validate checks that the filter selects something and lists at most maxIDs IDs
+func (f BulkDeleteFilter) Validate(maxIDs int) error {
+	if len(f.IDs) == 0 && f.From == nil && f.To == nil {
+		return errors.New("invalid filter: give ids or a date range")
+	}
+	if maxIDs > 0 && len(f.IDs) > maxIDs {
+		return fmt.Errorf("invalid filter: at most %d ids per request", maxIDs)
+	}
+	for _, id := range f.IDs {
+		if !primitive.IsValidObjectID(id) {
+			return fmt.Errorf("invalid filter: %q is not a valid id", id)
+		}
+	}
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return errors.New("invalid filter: from must be before to")
+	}
+	return nil
+}
+
+// ObjectIDs returns the filter's IDs, which Validate has checked
+func (f BulkDeleteFilter) ObjectIDs() []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, 0, len(f.IDs))
+	for _, id := range f.IDs {
+		if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+			ids = append(ids, oid)
+		}
+	}
+	return ids
+}
+
+// BulkDeleteRequest deletes the content matching the filter. ConfirmCount
+// must equal the number of items the filter matches, as reported by the
+// preview, so a mistaken filter can't delete more than the user was shown.
+type BulkDeleteRequest struct {
+	BulkDeleteFilter
+	ConfirmCount int64 `json:"confirm_count" validate:"required,min=1"`
+}
+
+// BulkDeletePreview reports what a bulk delete filter matches
+type BulkDeletePreview struct {
+	Matched  int64 `json:"matched"`   // Pass this back as confirm_count to delete
+	MaxItems int64 `json:"max_items"` // Most items one request may delete
+	Async    bool  `json:"async"`     // Whether the deletion will run as a background job
+}
+
+// BulkDeleteJob records a bulk deletion. Small deletions complete within the
+// request; larger ones run in the background and are polled by ID.
+type BulkDeleteJob struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID       primitive.ObjectID `json:"user_id" bson:"user_id"`
+	ContentType  string             `json:"content_type" bson:"content_type"` // posts or comments
+	Filter       BulkDeleteFilter   `json:"filter" bson:"filter"`
+	Status       string             `json:"status" bson:"status"` // queued, processing, completed, failed
+	Matched      int64              `json:"matched" bson:"matched"`
+	Deleted      int64              `json:"deleted" bson:"deleted"`
+	ErrorMessage string             `json:"error_message,omitempty" bson:"error_message,omitempty"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+	StartedAt    *time.Time         `json:"started_at,omitempty" bson:"started_at,omitempty"`
+	CompletedAt  *time.Time         `json:"completed_at,omitempty" bson:"completed_at,omitempty"`
+	ExpiresAt    time.Time          `json:"expires_at" bson:"expires_at"` // The record is removed after this
+}
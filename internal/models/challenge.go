@@ -0,0 +1,73 @@
+// models/challenge.go
+package models
+
+import "time"
+
+// Route groups that can demand a proof-of-work challenge
+const (
+	ChallengeGroupRegister = "register"
+	ChallengeGroupLogin    = "login"
+	ChallengeGroupPosts    = "posts"
+	ChallengeGroupComments = "comments"
+)
+
+// ChallengeGroups lists the route groups in the order admin views show them
+var ChallengeGroups = []string{ChallengeGroupRegister, ChallengeGroupLogin, ChallengeGroupPosts, ChallengeGroupComments}
+
+// ChallengeSettingsKey is the app_settings document holding the per-group challenge overrides
+const ChallengeSettingsKey = "pow_challenge"
+
+// MaxChallengeRisk is the highest risk level; level 0 means no challenge
+const MaxChallengeRisk = 3
+
+// ChallengeMode decides when a route group demands proof-of-work
+type ChallengeMode string
+
+const (
+	ChallengeModeAuto ChallengeMode = "auto" // Challenge while the abuse heuristics flag elevated risk
+	ChallengeModeOn   ChallengeMode = "on"   // Always challenge untrusted clients
+	ChallengeModeOff  ChallengeMode = "off"  // Never challenge
+)
+
+// ChallengeGroupSettings is the admin override for one route group
+type ChallengeGroupSettings struct {
+	Mode      ChallengeMode `json:"mode" bson:"mode"`
+	MinRisk   int           `json:"min_risk" bson:"min_risk"` // Risk floor, raising the difficulty during an attack
+	UpdatedAt time.Time     `json:"updated_at" bson:"updated_at"`
+	UpdatedBy string        `json:"updated_by,omitempty" bson:"updated_by,omitempty"`
+}
+
+// ChallengeSettings is stored in the app_settings collection under
+// ChallengeSettingsKey. Groups without an entry run in auto mode.
+type ChallengeSettings struct {
+	Groups map[string]ChallengeGroupSettings `json:"groups" bson:"groups"`
+}
+
+// UpdateChallengeGroupRequest represents the admin request to force a route group's challenge on or off
+type UpdateChallengeGroupRequest struct {
+	Mode    ChallengeMode `json:"mode" validate:"required,oneof=auto on off"`
+	MinRisk int           `json:"min_risk" validate:"min=0,max=3"`
+}
+
+// ProofOfWorkChallenge is sent with a 428 response. The client finds a
+// solution such that SHA-256(challenge + ":" + solution) starts with
+// Difficulty zero bits, then retries with the challenge and solution headers.
+type ProofOfWorkChallenge struct {
+	Challenge  string    `json:"challenge"`
+	Difficulty int       `json:"difficulty"`
+	Algorithm  string    `json:"algorithm"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// ChallengeGroupStatus is a route group's challenge state and counters for admins
+type ChallengeGroupStatus struct {
+	Group      string        `json:"group"`
+	Mode       ChallengeMode `json:"mode"`
+	MinRisk    int           `json:"min_risk"`
+	Risk       int           `json:"risk"`       // Current group-wide risk on this instance
+	Difficulty int           `json:"difficulty"` // Leading zero bits at the current risk, 0 when not challenging
+	Issued     int64         `json:"issued"`
+	Solved     int64         `json:"solved"`
+	Failed     int64         `json:"failed"`
+	Bypassed   int64         `json:"bypassed"` // Trusted users let through without a challenge
+}
@@ -15,10 +15,15 @@ type Comment struct {
 	UserID primitive.ObjectID `json:"user_id" bson:"user_id" validate:"required"`
 	Author UserResponse       `json:"author,omitempty" bson:"-"` // Populated when querying
 
+	// The person behind it when a delegate posted for the author; admin only
+	PostedByDelegate *primitive.ObjectID `json:"-" bson:"posted_by_delegate,omitempty"`
+
 	// Content
 	Content     string      `json:"content" bson:"content" validate:"required,max=2000"`
 	ContentType ContentType `json:"content_type" bson:"content_type"`
 	Media       []MediaInfo `json:"media,omitempty" bson:"media,omitempty"`
+	Kind        CommentKind `json:"kind,omitempty" bson:"kind,omitempty"` // Standard when empty
+	ContentHash string      `json:"-" bson:"content_hash,omitempty"`       // Hash of the normalized text, for repeated comment detection
 
 	// Comment Hierarchy
 	PostID          primitive.ObjectID  `json:"post_id" bson:"post_id" validate:"required"`
@@ -27,12 +32,19 @@ type Comment struct {
 	Level           int                 `json:"level" bson:"level"`             // 0 for top-level, 1 for replies, 2 for nested replies
 	ThreadPath      string              `json:"thread_path" bson:"thread_path"` // For efficient nested comments querying
 
+	// Reply preview, populated when listing a post's comments; RepliesCursor
+	// loads the rest
+	Replies       []Comment `json:"-" bson:"-"`
+	RepliesCursor string    `json:"-" bson:"-"`
+
 	// Engagement Statistics
 	LikesCount   int64 `json:"likes_count" bson:"likes_count"`
 	RepliesCount int64 `json:"replies_count" bson:"replies_count"`
+	// Per-reaction counters keyed by reaction type
+	ReactionCounts map[ReactionType]int64 `json:"reaction_counts,omitempty" bson:"reaction_counts,omitempty"`
 
 	// Social Features
-	Mentions     []primitive.ObjectID `json:"mentions,omitempty" bson:"mentions,omitempty"`
+	Mentions     []primitive.ObjectID `json:"mentions,omitempty" bson:"mentions,omitempty" bound:"50"`
 	MentionUsers []UserResponse       `json:"mention_users,omitempty" bson:"-"` // Populated when querying
 
 	// Comment Status
@@ -46,6 +58,18 @@ type Comment struct {
 	ReportsCount int64 `json:"reports_count" bson:"reports_count"`
 	IsHidden     bool  `json:"is_hidden" bson:"is_hidden"`
 	IsApproved   bool  `json:"is_approved" bson:"is_approved"`
+	// HiddenBy is the moderator who hid the comment; hidden comments without
+	// one were hidden automatically and wait in the moderation queue
+	HiddenBy *primitive.ObjectID `json:"-" bson:"hidden_by,omitempty"`
+	// The delegate behind the last delete, pin or unpin made for the post
+	// author; admin only
+	ModeratedByDelegate *primitive.ObjectID `json:"-" bson:"moderated_by_delegate,omitempty"`
+
+	// Spam Hold (link comments from new accounts wait for moderator review)
+	HoldStatus     CommentHoldStatus   `json:"hold_status,omitempty" bson:"hold_status,omitempty"`
+	HeldAt         *time.Time          `json:"held_at,omitempty" bson:"held_at,omitempty"`
+	HoldReviewedBy *primitive.ObjectID `json:"-" bson:"hold_reviewed_by,omitempty"` // Unset when released automatically
+	HoldReviewedAt *time.Time          `json:"-" bson:"hold_reviewed_at,omitempty"`
 
 	// Additional Metadata
 	Source    string `json:"source,omitempty" bson:"source,omitempty"` // web, mobile, api
@@ -71,6 +95,26 @@ type Comment struct {
 	AwardsCount int64          `json:"awards_count" bson:"awards_count"`
 }
 
+// CommentKind separates thread comments from emoji-only quick replies
+type CommentKind string
+
+const (
+	CommentKindStandard CommentKind = "standard"
+	CommentKindQuick    CommentKind = "quick" // Emoji-only, kept out of the thread and summarized on the post
+)
+
+// MaxQuickReplyEmoji is the most emoji a quick reply may contain
+const MaxQuickReplyEmoji = 5
+
+// CommentHoldStatus tracks a comment through the spam hold
+type CommentHoldStatus string
+
+const (
+	CommentHoldHeld     CommentHoldStatus = "held"
+	CommentHoldApproved CommentHoldStatus = "approved"
+	CommentHoldRejected CommentHoldStatus = "rejected"
+)
+
 // CommentAward represents awards given to comments
 type CommentAward struct {
 	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
@@ -83,44 +127,48 @@ type CommentAward struct {
 
 // CommentResponse represents the comment data returned in API responses
 type CommentResponse struct {
-	ID              string         `json:"id"`
-	UserID          string         `json:"user_id"`
-	Author          UserResponse   `json:"author"`
-	Content         string         `json:"content"`
-	ContentType     ContentType    `json:"content_type"`
-	Media           []MediaInfo    `json:"media,omitempty"`
-	PostID          string         `json:"post_id"`
-	ParentCommentID string         `json:"parent_comment_id,omitempty"`
-	RootCommentID   string         `json:"root_comment_id,omitempty"`
-	Level           int            `json:"level"`
-	LikesCount      int64          `json:"likes_count"`
-	RepliesCount    int64          `json:"replies_count"`
-	Mentions        []string       `json:"mentions,omitempty"`
-	MentionUsers    []UserResponse `json:"mention_users,omitempty"`
-	IsEdited        bool           `json:"is_edited"`
-	EditedAt        *time.Time     `json:"edited_at,omitempty"`
-	IsPinned        bool           `json:"is_pinned"`
-	IsHighlighted   bool           `json:"is_highlighted"`
-	UpvotesCount    int64          `json:"upvotes_count"`
-	DownvotesCount  int64          `json:"downvotes_count"`
-	VoteScore       int64          `json:"vote_score"`
-	QualityScore    float64        `json:"quality_score"`
-	IsVerifiedReply bool           `json:"is_verified_reply"`
-	IsAuthorReply   bool           `json:"is_author_reply"`
-	Awards          []CommentAward `json:"awards,omitempty"`
-	AwardsCount     int64          `json:"awards_count"`
-	CreatedAt       time.Time      `json:"created_at"`
-	UpdatedAt       time.Time      `json:"updated_at"`
+	ID              string                 `json:"id"`
+	UserID          string                 `json:"user_id"`
+	Author          UserResponse           `json:"author"`
+	Content         string                 `json:"content"`
+	ContentType     ContentType            `json:"content_type"`
+	Media           []MediaInfo            `json:"media,omitempty"`
+	Kind            CommentKind            `json:"kind"`
+	PostID          string                 `json:"post_id"`
+	ParentCommentID string                 `json:"parent_comment_id,omitempty"`
+	RootCommentID   string                 `json:"root_comment_id,omitempty"`
+	Level           int                    `json:"level"`
+	LikesCount      int64                  `json:"likes_count"`
+	ReactionCounts  map[ReactionType]int64 `json:"reaction_counts,omitempty"`
+	RepliesCount    int64                  `json:"replies_count"`
+	Mentions        []string               `json:"mentions,omitempty"`
+	MentionUsers    []UserResponse         `json:"mention_users,omitempty"`
+	IsEdited        bool                   `json:"is_edited"`
+	EditedAt        *time.Time             `json:"edited_at,omitempty"`
+	IsPinned        bool                   `json:"is_pinned"`
+	IsHighlighted   bool                   `json:"is_highlighted"`
+	IsHeld          bool                   `json:"is_held,omitempty"` // Only ever returned to the author
+	UpvotesCount    int64                  `json:"upvotes_count"`
+	DownvotesCount  int64                  `json:"downvotes_count"`
+	VoteScore       int64                  `json:"vote_score"`
+	QualityScore    float64                `json:"quality_score"`
+	IsVerifiedReply bool                   `json:"is_verified_reply"`
+	IsAuthorReply   bool                   `json:"is_author_reply"`
+	Awards          []CommentAward         `json:"awards,omitempty"`
+	AwardsCount     int64                  `json:"awards_count"`
+	CreatedAt       time.Time              `json:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at"`
 
 	// User-specific context
-	IsLiked      bool              `json:"is_liked,omitempty"`
-	UserReaction ReactionType      `json:"user_reaction,omitempty"`
-	UserVote     string            `json:"user_vote,omitempty"` // upvote, downvote, none
-	Replies      []CommentResponse `json:"replies,omitempty"`   // Nested replies
-	TimeAgo      string            `json:"time_ago,omitempty"`
-	CanEdit      bool              `json:"can_edit,omitempty"`
-	CanDelete    bool              `json:"can_delete,omitempty"`
-	CanReply     bool              `json:"can_reply,omitempty"`
+	IsLiked       bool              `json:"is_liked,omitempty"`
+	UserReaction  ReactionType      `json:"user_reaction,omitempty"`
+	UserVote      string            `json:"user_vote,omitempty"`      // upvote, downvote, none
+	Replies       []CommentResponse `json:"replies,omitempty"`        // Nested replies
+	RepliesCursor string            `json:"replies_cursor,omitempty"` // Loads the replies after those listed
+	TimeAgo       string            `json:"time_ago,omitempty"`
+	CanEdit       bool              `json:"can_edit,omitempty"`
+	CanDelete     bool              `json:"can_delete,omitempty"`
+	CanReply      bool              `json:"can_reply,omitempty"`
 }
 
 // CreateCommentRequest represents the request to create a new comment
@@ -131,6 +179,9 @@ type CreateCommentRequest struct {
 	ContentType     ContentType `json:"content_type" validate:"required,oneof=text image gif"`
 	Media           []MediaInfo `json:"media,omitempty"`
 	Mentions        []string    `json:"mentions,omitempty"` // User IDs as strings
+	Kind            CommentKind `json:"kind,omitempty" validate:"omitempty,oneof=standard quick"`
+
+	DelegateID *primitive.ObjectID `json:"-"` // Set when a delegate comments for the author
 }
 
 // UpdateCommentRequest represents the request to update a comment
@@ -170,6 +221,32 @@ type CommentTreeResponse struct {
 	LoadMoreURL     string                `json:"load_more_url,omitempty"`
 }
 
+// QuickReactionSummary aggregates a post's quick replies
+type QuickReactionSummary struct {
+	Total      int64                 `json:"total"`
+	Counts     map[string]int64      `json:"counts"` // Emoji to the number of quick replies using it
+	TopSenders []QuickReactionSender `json:"top_senders,omitempty"`
+}
+
+// QuickReactionSender is a user who sent quick replies to a post
+type QuickReactionSender struct {
+	User  UserResponse `json:"user"`
+	Count int64        `json:"count"`
+}
+
+// RejectHeldCommentRequest represents a moderator rejecting a held comment
+type RejectHeldCommentRequest struct {
+	Reason string `json:"reason,omitempty" validate:"max=500"`
+}
+
+// CommentHoldStats summarizes spam hold volumes for moderation stats
+type CommentHoldStats struct {
+	Held         int64 `json:"held"`
+	Approved     int64 `json:"approved"`
+	AutoReleased int64 `json:"auto_released"` // Approved without a moderator once the author became trusted
+	Rejected     int64 `json:"rejected"`
+}
+
 // CommentModerationRequest represents comment moderation actions
 type CommentModerationRequest struct {
 	Action string `json:"action" validate:"required,oneof=approve hide pin unpin highlight unhighlight"`
@@ -246,14 +323,17 @@ func (c *Comment) ToCommentResponse() CommentResponse {
 		Content:         c.Content,
 		ContentType:     c.ContentType,
 		Media:           c.Media,
+		Kind:            c.EffectiveKind(),
 		PostID:          c.PostID.Hex(),
 		Level:           c.Level,
 		LikesCount:      c.LikesCount,
+		ReactionCounts:  c.ReactionCounts,
 		RepliesCount:    c.RepliesCount,
 		IsEdited:        c.IsEdited,
 		EditedAt:        c.EditedAt,
 		IsPinned:        c.IsPinned,
 		IsHighlighted:   c.IsHighlighted,
+		IsHeld:          c.IsHeld(),
 		UpvotesCount:    c.UpvotesCount,
 		DownvotesCount:  c.DownvotesCount,
 		VoteScore:       c.VoteScore,
@@ -282,6 +362,11 @@ func (c *Comment) ToCommentResponse() CommentResponse {
 		}
 	}
 
+	for i := range c.Replies {
+		response.Replies = append(response.Replies, c.Replies[i].ToCommentResponse())
+	}
+	response.RepliesCursor = c.RepliesCursor
+
 	return response
 }
 
@@ -556,6 +641,42 @@ func (c *Comment) CanViewComment() bool {
 	return !c.IsDeleted() && !c.IsHidden && c.IsApproved
 }
 
+// EffectiveKind returns the comment kind. Comments created before quick
+// replies existed carry no kind.
+func (c *Comment) EffectiveKind() CommentKind {
+	if c.Kind == "" {
+		return CommentKindStandard
+	}
+	return c.Kind
+}
+
+// IsQuickReply checks if the comment is an emoji-only quick reply
+func (c *Comment) IsQuickReply() bool {
+	return c.Kind == CommentKindQuick
+}
+
+// IsHeld checks if the comment is waiting in the spam hold
+func (c *Comment) IsHeld() bool {
+	return c.HoldStatus == CommentHoldHeld
+}
+
+// CanBeViewedBy checks if a viewer can see this comment; held comments are
+// shown to their author only, so the hold isn't apparent to them
+func (c *Comment) CanBeViewedBy(viewerID *primitive.ObjectID) bool {
+	if c.CanViewComment() {
+		return true
+	}
+	return c.IsHeld() && viewerID != nil && *viewerID == c.UserID && !c.IsDeleted() && !c.IsHidden
+}
+
+// Hold places the comment in the spam hold until a moderator reviews it
+func (c *Comment) Hold() {
+	now := time.Now()
+	c.IsApproved = false
+	c.HoldStatus = CommentHoldHeld
+	c.HeldAt = &now
+}
+
 // GetEngagementRate calculates the engagement rate of the comment
 func (c *Comment) GetEngagementRate() float64 {
 	totalEngagements := c.LikesCount + c.RepliesCount + c.UpvotesCount + c.DownvotesCount
@@ -572,11 +693,6 @@ func (c *Comment) GetEngagementRate() float64 {
 	return float64(totalEngagements) / hoursSinceCreation
 }
 
-// GetMentionedUsernames extracts mentioned usernames from content
-func (c *Comment) GetMentionedUsernames() []string {
-	return ExtractMentionsFromText(c.Content)
-}
-
 // BuildCommentTree builds a tree structure from a flat list of comments
 func BuildCommentTree(comments []Comment, maxDepth int) []CommentTreeResponse {
 	commentMap := make(map[string]CommentTreeResponse)
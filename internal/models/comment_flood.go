@@ -0,0 +1,48 @@
+// models/comment_flood.go
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Comment flood limits a user can run into
+const (
+	CommentFloodGlobal    = "global"    // Comments on any post within the window
+	CommentFloodPost      = "post"      // Comments on a single post within the window
+	CommentFloodDuplicate = "duplicate" // The same comment text repeated within the window
+)
+
+// CommentFloodError reports which comment limit was hit and how long the user
+// has to wait before commenting again
+type CommentFloodError struct {
+	Scope             string `json:"scope"`
+	Limit             int    `json:"limit,omitempty"`
+	WindowSeconds     int    `json:"window_seconds"`
+	RetryAfterSeconds int    `json:"retry_after_seconds"`
+
+	window time.Duration
+}
+
+// NewCommentFloodError builds the error for a limit of the given scope,
+// rounding the wait up to whole seconds
+func NewCommentFloodError(scope string, limit int, window, retryAfter time.Duration) *CommentFloodError {
+	return &CommentFloodError{
+		Scope:             scope,
+		Limit:             limit,
+		WindowSeconds:     int(window / time.Second),
+		RetryAfterSeconds: int(math.Max(1, math.Ceil(retryAfter.Seconds()))),
+		window:            window,
+	}
+}
+
+func (e *CommentFloodError) Error() string {
+	switch e.Scope {
+	case CommentFloodDuplicate:
+		return "duplicate comment: you already posted this comment recently"
+	case CommentFloodPost:
+		return fmt.Sprintf("comment rate limit exceeded: at most %d comments on one post every %s", e.Limit, formatDuration(e.window))
+	}
+	return fmt.Sprintf("comment rate limit exceeded: at most %d comments every %s", e.Limit, formatDuration(e.window))
+}
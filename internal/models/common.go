@@ -80,19 +80,27 @@ const (
 type NotificationType string
 
 const (
-	NotificationLike          NotificationType = "like"
-	NotificationLove          NotificationType = "love"
-	NotificationComment       NotificationType = "comment"
-	NotificationFollow        NotificationType = "follow"
-	NotificationMessage       NotificationType = "message"
-	NotificationMention       NotificationType = "mention"
-	NotificationGroupInvite   NotificationType = "group_invite"
-	NotificationEventInvite   NotificationType = "event_invite"
-	NotificationFriendRequest NotificationType = "friend_request"
-	NotificationPostShare     NotificationType = "post_share"
-	NotificationStoryView     NotificationType = "story_view"
-	NotificationGroupPost     NotificationType = "group_post"
-	NotificationEventReminder NotificationType = "event_reminder"
+	NotificationLike            NotificationType = "like"
+	NotificationLove            NotificationType = "love"
+	NotificationComment         NotificationType = "comment"
+	NotificationQuickReply      NotificationType = "quick_reply"
+	NotificationFollow          NotificationType = "follow"
+	NotificationMessage         NotificationType = "message"
+	NotificationMention         NotificationType = "mention"
+	NotificationGroupInvite     NotificationType = "group_invite"
+	NotificationEventInvite     NotificationType = "event_invite"
+	NotificationFriendRequest   NotificationType = "friend_request"
+	NotificationPostShare       NotificationType = "post_share"
+	NotificationStoryView       NotificationType = "story_view"
+	NotificationGroupPost       NotificationType = "group_post"
+	NotificationEventReminder   NotificationType = "event_reminder"
+	NotificationSurvey          NotificationType = "survey"
+	NotificationDelegate        NotificationType = "delegate_invite"
+	NotificationPhotoTag        NotificationType = "photo_tag"
+	NotificationStoryScreenshot NotificationType = "story_screenshot"
+	NotificationMediaRejected   NotificationType = "media_rejected"
+	NotificationSavedSearch     NotificationType = "saved_search_alert"
+	NotificationSupportAccess   NotificationType = "support_access"
 )
 
 // User role enum
@@ -105,6 +113,16 @@ const (
 	RoleSuperAdmin UserRole = "super_admin"
 )
 
+// IsAdmin reports whether the role is admin or super admin
+func (r UserRole) IsAdmin() bool {
+	return r == RoleAdmin || r == RoleSuperAdmin
+}
+
+// IsModerator reports whether the role is moderator or higher
+func (r UserRole) IsModerator() bool {
+	return r == RoleModerator || r.IsAdmin()
+}
+
 // Report status enum
 type ReportStatus string
 
@@ -189,12 +207,13 @@ type Location struct {
 type MediaInfo struct {
 	URL       string `json:"url" bson:"url"`
 	Type      string `json:"type" bson:"type"` // image, video, audio
+	MimeType  string `json:"mime_type,omitempty" bson:"mime_type,omitempty"`
 	Size      int64  `json:"size" bson:"size"`
 	Width     int    `json:"width,omitempty" bson:"width,omitempty"`
 	Height    int    `json:"height,omitempty" bson:"height,omitempty"`
 	Duration  int    `json:"duration,omitempty" bson:"duration,omitempty"` // for videos/audio in seconds
 	Thumbnail string `json:"thumbnail,omitempty" bson:"thumbnail,omitempty"`
-	AltText   string `json:"alt_text,omitempty" bson:"alt_text,omitempty"`
+	AltText   string `json:"alt_text" bson:"alt_text,omitempty"`
 }
 
 // PaginationInfo for API responses
@@ -231,17 +250,66 @@ type Stats struct {
 
 // PrivacySettings struct for user privacy configuration
 type PrivacySettings struct {
-	ProfileVisibility   PrivacyLevel `json:"profile_visibility" bson:"profile_visibility"`
-	PostsVisibility     PrivacyLevel `json:"posts_visibility" bson:"posts_visibility"`
-	FollowersVisibility PrivacyLevel `json:"followers_visibility" bson:"followers_visibility"`
-	FollowingVisibility PrivacyLevel `json:"following_visibility" bson:"following_visibility"`
-	EmailVisibility     PrivacyLevel `json:"email_visibility" bson:"email_visibility"`
-	PhoneVisibility     PrivacyLevel `json:"phone_visibility" bson:"phone_visibility"`
-	AllowMessages       bool         `json:"allow_messages" bson:"allow_messages"`
-	AllowTagging        bool         `json:"allow_tagging" bson:"allow_tagging"`
-	AllowFollowRequests bool         `json:"allow_follow_requests" bson:"allow_follow_requests"`
-	ShowOnlineStatus    bool         `json:"show_online_status" bson:"show_online_status"`
-	AllowStoryViews     bool         `json:"allow_story_views" bson:"allow_story_views"`
+	ProfileVisibility   PrivacyLevel      `json:"profile_visibility" bson:"profile_visibility"`
+	PostsVisibility     PrivacyLevel      `json:"posts_visibility" bson:"posts_visibility"`
+	FollowersVisibility PrivacyLevel      `json:"followers_visibility" bson:"followers_visibility"`
+	FollowingVisibility PrivacyLevel      `json:"following_visibility" bson:"following_visibility"`
+	EmailVisibility     PrivacyLevel      `json:"email_visibility" bson:"email_visibility"`
+	PhoneVisibility     PrivacyLevel      `json:"phone_visibility" bson:"phone_visibility"`
+	AllowMessages       bool              `json:"allow_messages" bson:"allow_messages"`
+	WhoCanMessage       MessagePermission `json:"who_can_message" bson:"who_can_message"`
+	AllowTagging        bool              `json:"allow_tagging" bson:"allow_tagging"`
+	ReviewTags          bool              `json:"review_tags" bson:"review_tags"` // Photo tags wait for approval before the post shows on the tagged tab
+	AllowFollowRequests bool              `json:"allow_follow_requests" bson:"allow_follow_requests"`
+	ShowOnlineStatus    bool              `json:"show_online_status" bson:"show_online_status"`
+	AllowStoryViews     bool              `json:"allow_story_views" bson:"allow_story_views"`
+	AnalyticsOptOut     bool              `json:"analytics_opt_out" bson:"analytics_opt_out"` // Leave the user out of creators' audience insights
+	HideSocialGraph     bool              `json:"hide_social_graph" bson:"hide_social_graph"` // Others see follower/following counts only, and the user is left out of mutual connections
+
+	// Visibility applied when a create request doesn't set one
+	DefaultPostVisibility  PrivacyLevel `json:"default_post_visibility" bson:"default_post_visibility,omitempty"`
+	DefaultStoryVisibility PrivacyLevel `json:"default_story_visibility" bson:"default_story_visibility,omitempty"`
+}
+
+// IsValidPrivacyLevel checks if the privacy level is supported
+func IsValidPrivacyLevel(level PrivacyLevel) bool {
+	switch level {
+	case PrivacyPublic, PrivacyFriends, PrivacyPrivate:
+		return true
+	}
+	return false
+}
+
+// MessagePermission controls who can send a user messages straight to their inbox
+type MessagePermission string
+
+const (
+	MessagePermissionEveryone  MessagePermission = "everyone"
+	MessagePermissionFollowing MessagePermission = "following" // People the user follows
+	MessagePermissionFollowers MessagePermission = "followers" // People who follow the user
+	MessagePermissionMutual    MessagePermission = "mutual"    // People who follow the user and are followed back
+	MessagePermissionNoOne     MessagePermission = "no_one"
+)
+
+// IsValidMessagePermission checks if the message permission is supported
+func IsValidMessagePermission(permission MessagePermission) bool {
+	switch permission {
+	case MessagePermissionEveryone, MessagePermissionFollowing, MessagePermissionFollowers, MessagePermissionMutual, MessagePermissionNoOne:
+		return true
+	}
+	return false
+}
+
+// MessagePermission returns who can message the user. Settings saved before
+// who_can_message existed fall back to allow_messages.
+func (p PrivacySettings) MessagePermission() MessagePermission {
+	if p.WhoCanMessage != "" {
+		return p.WhoCanMessage
+	}
+	if !p.AllowMessages {
+		return MessagePermissionNoOne
+	}
+	return MessagePermissionEveryone
 }
 
 // NotificationSettings struct for user notification preferences
@@ -270,10 +338,14 @@ func DefaultPrivacySettings() PrivacySettings {
 		EmailVisibility:     PrivacyPrivate,
 		PhoneVisibility:     PrivacyPrivate,
 		AllowMessages:       true,
+		WhoCanMessage:       MessagePermissionEveryone,
 		AllowTagging:        true,
 		AllowFollowRequests: true,
 		ShowOnlineStatus:    true,
 		AllowStoryViews:     true,
+
+		DefaultPostVisibility:  PrivacyPublic,
+		DefaultStoryVisibility: PrivacyPublic,
 	}
 }
 
@@ -0,0 +1,74 @@
+// models/content_calendar.go
+package models
+
+import "time"
+
+// ContentCalendarState is where an item sits in the creator's publishing pipeline
+type ContentCalendarState string
+
+const (
+	CalendarStatePublished       ContentCalendarState = "published"
+	CalendarStateScheduled       ContentCalendarState = "scheduled"
+	CalendarStatePendingApproval ContentCalendarState = "pending_approval" // Group post waiting for a moderator
+	CalendarStateHighlight       ContentCalendarState = "highlight"        // Story highlight, only with include=highlights
+)
+
+// Content calendar limits
+const (
+	ContentCalendarMaxDays       = 92
+	ContentCalendarDefaultPerDay = 20
+	ContentCalendarMaxPerDay     = 50
+)
+
+// ContentCalendarQuery selects the range of a content calendar request.
+// From and To are inclusive days in the user's timezone. Setting Day narrows
+// the range to that day and pages through its items.
+type ContentCalendarQuery struct {
+	From              string
+	To                string
+	Day               string
+	Page              int
+	PerDay            int
+	IncludeHighlights bool
+}
+
+// ContentCalendarEngagement holds the basic numbers of a published post
+type ContentCalendarEngagement struct {
+	Likes    int64 `json:"likes"`
+	Comments int64 `json:"comments"`
+	Shares   int64 `json:"shares"`
+	Views    int64 `json:"views"`
+}
+
+// ContentCalendarItem is a post or story highlight placed on the calendar
+type ContentCalendarItem struct {
+	ID           string                     `json:"id"`
+	Kind         string                     `json:"kind"` // post, highlight
+	State        ContentCalendarState       `json:"state"`
+	Date         time.Time                  `json:"date"` // The time that places the item on its day
+	ScheduledFor *time.Time                 `json:"scheduled_for,omitempty"`
+	PublishedAt  *time.Time                 `json:"published_at,omitempty"`
+	Preview      string                     `json:"preview"`
+	Thumbnail    string                     `json:"thumbnail,omitempty"`
+	GroupID      string                     `json:"group_id,omitempty"`
+	Engagement   *ContentCalendarEngagement `json:"engagement,omitempty"`
+}
+
+// ContentCalendarDay groups the items of one day in the user's timezone
+type ContentCalendarDay struct {
+	Date    string                `json:"date"` // YYYY-MM-DD
+	Items   []ContentCalendarItem `json:"items"`
+	Total   int64                 `json:"total"`
+	Page    int                   `json:"page"`
+	HasMore bool                  `json:"has_more"`
+}
+
+// ContentCalendarResponse is the creator's content calendar over a range.
+// Days without items are left out.
+type ContentCalendarResponse struct {
+	From     string                         `json:"from"`
+	To       string                         `json:"to"`
+	Timezone string                         `json:"timezone"`
+	Summary  map[ContentCalendarState]int64 `json:"summary"`
+	Days     []ContentCalendarDay           `json:"days"`
+}
@@ -0,0 +1,51 @@
+// models/content_fingerprint.go
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ContentFingerprint records that a user published content with a given hash,
+// stored in the content_fingerprints collection. Fingerprints expire with the
+// duplicate detection window and are used to spot repeated and coordinated posts.
+type ContentFingerprint struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Hash      string             `json:"hash" bson:"hash"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	PostID    primitive.ObjectID `json:"post_id" bson:"post_id"`
+	Reported  bool               `json:"reported" bson:"reported"` // Filed for review as coordinated spam
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	ExpiresAt time.Time          `json:"expires_at" bson:"expires_at"`
+}
+
+// NormalizeContent reduces post text to the form compared for duplicates:
+// lowercased letters and digits separated by single spaces, so changes in
+// case, punctuation, emoji or spacing don't make a repost look new
+func NormalizeContent(content string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(content) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteRune(r)
+			space = false
+		} else {
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// ContentHash returns the fingerprint hash of normalized content
+func ContentHash(normalized string) string {
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -18,9 +18,9 @@ type Conversation struct {
 	AvatarURL   string `json:"avatar_url,omitempty" bson:"avatar_url,omitempty"`
 
 	// Participants
-	Participants    []primitive.ObjectID      `json:"participants" bson:"participants" validate:"required,min=2"`
+	Participants    []primitive.ObjectID      `json:"participants" bson:"participants" validate:"required,min=2" bound:"500"`
 	ParticipantInfo []ConversationParticipant `json:"participant_info,omitempty" bson:"participant_info,omitempty"`
-	AdminIDs        []primitive.ObjectID      `json:"admin_ids,omitempty" bson:"admin_ids,omitempty"` // For group conversations
+	AdminIDs        []primitive.ObjectID      `json:"admin_ids,omitempty" bson:"admin_ids,omitempty" bound:"500"` // For group conversations
 	CreatedBy       primitive.ObjectID        `json:"created_by" bson:"created_by"`
 
 	// Last message info (for conversation list)
@@ -48,7 +48,7 @@ type Conversation struct {
 
 	// Features
 	HasPinnedMessages bool                 `json:"has_pinned_messages" bson:"has_pinned_messages"`
-	PinnedMessages    []primitive.ObjectID `json:"pinned_messages,omitempty" bson:"pinned_messages,omitempty"`
+	PinnedMessages    []primitive.ObjectID `json:"pinned_messages,omitempty" bson:"pinned_messages,omitempty" bound:"50"`
 
 	// Encryption (for future implementation)
 	IsEncrypted   bool   `json:"is_encrypted" bson:"is_encrypted"`
@@ -106,8 +106,50 @@ type ConversationParticipant struct {
 	InvitedBy  *primitive.ObjectID `json:"invited_by,omitempty" bson:"invited_by,omitempty"`
 	InvitedAt  *time.Time          `json:"invited_at,omitempty" bson:"invited_at,omitempty"`
 	JoinMethod string              `json:"join_method,omitempty" bson:"join_method,omitempty"` // invited, joined, added
+
+	// Message request state, never exposed so senders can't tell they landed in requests
+	RequestStatus     string     `json:"-" bson:"request_status,omitempty"`
+	RequestNotifiedAt *time.Time `json:"-" bson:"request_notified_at,omitempty"` // The recipient is notified of a request once
+
+	// Inbox pin, a personal preference only shown to the participant through
+	// ConversationResponse.IsPinned
+	IsPinned bool       `json:"-" bson:"is_pinned,omitempty"`
+	PinnedAt *time.Time `json:"-" bson:"pinned_at,omitempty"`
+}
+
+// Message request states for conversation participants
+const (
+	ConversationRequestPending = "pending"
+)
+
+// Presence states reported for conversation participants
+const (
+	PresenceOnline  = "online"
+	PresenceAway    = "away"
+	PresenceOffline = "offline"
+)
+
+// ParticipantPresence is a conversation member with their current presence.
+// Status and LastSeenAt are left out for members who hide their online status.
+type ParticipantPresence struct {
+	User       UserResponse `json:"user"`
+	Role       string       `json:"role"`
+	Status     string       `json:"status,omitempty"` // online, away, offline
+	LastSeenAt *time.Time   `json:"last_seen_at,omitempty"`
 }
 
+// MaxPinnedMessages caps the pinned message IDs kept on a conversation document
+const MaxPinnedMessages = 50
+
+// MaxPinnedConversations caps how many conversations a user can pin in their inbox
+const MaxPinnedConversations = 5
+
+// Inbox sort orders; pinned conversations come first in either
+const (
+	InboxSortRecent     = "recent"      // Last activity
+	InboxSortMostActive = "most_active" // Messages within the activity window
+)
+
 // ConversationResponse represents the conversation data returned in API responses
 type ConversationResponse struct {
 	ID                 string                    `json:"id"`
@@ -150,6 +192,9 @@ type ConversationResponse struct {
 	CanSendMessages   bool           `json:"can_send_messages,omitempty"`
 	CanAddMembers     bool           `json:"can_add_members,omitempty"`
 	TypingUsers       []UserResponse `json:"typing_users,omitempty"`
+	IsRequest         bool           `json:"is_request,omitempty"` // In the current user's message requests
+	IsPinned          bool           `json:"is_pinned,omitempty"`
+	PinnedAt          *time.Time     `json:"pinned_at,omitempty"`
 }
 
 // CreateConversationRequest represents the request to create a conversation
@@ -157,7 +202,7 @@ type CreateConversationRequest struct {
 	Type              string   `json:"type" validate:"required,oneof=direct group"`
 	Title             string   `json:"title,omitempty" validate:"max=100"`
 	Description       string   `json:"description,omitempty" validate:"max=500"`
-	ParticipantIDs    []string `json:"participant_ids" validate:"required,min=1,max=50"`
+	ParticipantIDs    []string `json:"participant_ids" validate:"required,min=1,max=500"`
 	IsPrivate         bool     `json:"is_private"`
 	AllowInvites      bool     `json:"allow_invites"`
 	AllowMediaSharing bool     `json:"allow_media_sharing"`
@@ -409,6 +454,46 @@ func (c *Conversation) IsParticipant(userID primitive.ObjectID) bool {
 	return false
 }
 
+// IsPendingRequest checks if the conversation is in the user's message requests
+func (c *Conversation) IsPendingRequest(userID primitive.ObjectID) bool {
+	for _, info := range c.ParticipantInfo {
+		if info.UserID == userID {
+			return info.RequestStatus == ConversationRequestPending
+		}
+	}
+	return false
+}
+
+// SetRequestPending moves the conversation into the user's message requests
+func (c *Conversation) SetRequestPending(userID primitive.ObjectID) {
+	for i, info := range c.ParticipantInfo {
+		if info.UserID == userID {
+			c.ParticipantInfo[i].RequestStatus = ConversationRequestPending
+			break
+		}
+	}
+}
+
+// GetRequestSender returns who put the conversation in the user's message requests
+func (c *Conversation) GetRequestSender(userID primitive.ObjectID) primitive.ObjectID {
+	for _, info := range c.ParticipantInfo {
+		if info.UserID == userID && info.InvitedBy != nil {
+			return *info.InvitedBy
+		}
+	}
+	return c.CreatedBy
+}
+
+// GetPinnedAt returns when the user pinned the conversation, or nil if they haven't
+func (c *Conversation) GetPinnedAt(userID primitive.ObjectID) *time.Time {
+	for _, info := range c.ParticipantInfo {
+		if info.UserID == userID && info.IsPinned {
+			return info.PinnedAt
+		}
+	}
+	return nil
+}
+
 // IsAdmin checks if a user is an admin of the conversation
 func (c *Conversation) IsAdmin(userID primitive.ObjectID) bool {
 	for _, adminID := range c.AdminIDs {
@@ -496,18 +581,23 @@ func (c *Conversation) GetTypingUsers() []primitive.ObjectID {
 	return typingUsers
 }
 
-// PinMessage pins a message in the conversation
-func (c *Conversation) PinMessage(messageID primitive.ObjectID) {
+// PinMessage pins a message in the conversation, returning false once MaxPinnedMessages is reached
+func (c *Conversation) PinMessage(messageID primitive.ObjectID) bool {
 	// Check if already pinned
 	for _, pinnedID := range c.PinnedMessages {
 		if pinnedID == messageID {
-			return
+			return true
 		}
 	}
 
+	if len(c.PinnedMessages) >= MaxPinnedMessages {
+		return false
+	}
+
 	c.PinnedMessages = append(c.PinnedMessages, messageID)
 	c.HasPinnedMessages = true
 	c.BeforeUpdate()
+	return true
 }
 
 // UnpinMessage unpins a message from the conversation
@@ -0,0 +1,53 @@
+// models/conversation_export.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Conversation export formats
+const (
+	ConversationExportJSON = "json"
+	ConversationExportText = "txt"
+)
+
+// ConversationExport records a transcript downloaded by a participant
+type ConversationExport struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID         primitive.ObjectID `json:"user_id" bson:"user_id"`
+	ConversationID primitive.ObjectID `json:"conversation_id" bson:"conversation_id"`
+	Format         string             `json:"format" bson:"format"`
+	MessageCount   int                `json:"message_count" bson:"message_count"`
+	Filename       string             `json:"filename" bson:"filename"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// ConversationTranscript is the JSON export of a conversation
+type ConversationTranscript struct {
+	ConversationID string              `json:"conversation_id"`
+	Type           string              `json:"type"`
+	Title          string              `json:"title,omitempty"`
+	ExportedBy     string              `json:"exported_by"`
+	ExportedAt     time.Time           `json:"exported_at"`
+	Messages       []TranscriptMessage `json:"messages"`
+}
+
+// TranscriptMessage is a single message in a conversation transcript
+type TranscriptMessage struct {
+	ID          string      `json:"id"`
+	SenderID    string      `json:"sender_id"`
+	SenderName  string      `json:"sender_name"`
+	Content     string      `json:"content"`
+	ContentType ContentType `json:"content_type"`
+	Media       []MediaInfo `json:"media,omitempty"`
+	MediaHidden bool        `json:"media_hidden,omitempty"` // Media withheld until the exporter accepts the message request
+	IsEdited    bool        `json:"is_edited"`
+	SentAt      time.Time   `json:"sent_at"`
+}
+
+// IsValidConversationExportFormat checks if the export format is supported
+func IsValidConversationExportFormat(format string) bool {
+	return format == ConversationExportJSON || format == ConversationExportText
+}
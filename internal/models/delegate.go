@@ -0,0 +1,129 @@
+// models/delegate.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DelegateRole decides what a delegate may do while switched into an account
+type DelegateRole string
+
+const (
+	DelegateRoleEditor    DelegateRole = "editor"    // Posts, stories and replies to comments
+	DelegateRoleAnalyst   DelegateRole = "analyst"   // Read-only insights
+	DelegateRoleModerator DelegateRole = "moderator" // Comments and direct messages
+)
+
+// DelegationStatus tracks a delegate invitation through to revocation
+type DelegationStatus string
+
+const (
+	DelegationPending  DelegationStatus = "pending"
+	DelegationActive   DelegationStatus = "active"
+	DelegationDeclined DelegationStatus = "declined"
+	DelegationRevoked  DelegationStatus = "revoked"
+)
+
+// DelegateOperation is the kind of request a delegated token makes
+type DelegateOperation string
+
+const (
+	DelegateOpRead     DelegateOperation = "read"     // Viewing the account's content, feeds and profiles
+	DelegateOpContent  DelegateOperation = "content"  // Creating and editing posts and stories
+	DelegateOpComments DelegateOperation = "comments" // Responding to and managing comments
+	DelegateOpMessages DelegateOperation = "messages" // Reading and answering direct messages
+	DelegateOpInsights DelegateOperation = "insights" // Analytics and stats
+	DelegateOpOwner    DelegateOperation = "owner"    // Password, deletion, delegates and anything unlisted
+)
+
+// Allows checks if the role may perform an operation. Owner operations are
+// never delegated, whatever the role.
+func (r DelegateRole) Allows(op DelegateOperation) bool {
+	switch op {
+	case DelegateOpRead:
+		// Analysts see the account through its insights only
+		return r == DelegateRoleEditor || r == DelegateRoleModerator
+	case DelegateOpContent:
+		return r == DelegateRoleEditor
+	case DelegateOpComments:
+		return r == DelegateRoleEditor || r == DelegateRoleModerator
+	case DelegateOpMessages:
+		return r == DelegateRoleModerator
+	case DelegateOpInsights:
+		return r == DelegateRoleAnalyst
+	}
+	return false
+}
+
+// AccountDelegation grants a user access to act on behalf of another account
+type AccountDelegation struct {
+	BaseModel `bson:",inline"`
+
+	AccountID  primitive.ObjectID `json:"account_id" bson:"account_id"`   // The managed account
+	DelegateID primitive.ObjectID `json:"delegate_id" bson:"delegate_id"` // The person acting for it
+	Role       DelegateRole       `json:"role" bson:"role"`
+	Status     DelegationStatus   `json:"status" bson:"status"`
+
+	AcceptedAt *time.Time `json:"accepted_at,omitempty" bson:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+
+	Account  UserResponse `json:"account,omitempty" bson:"-"`  // Populated when querying
+	Delegate UserResponse `json:"delegate,omitempty" bson:"-"` // Populated when querying
+}
+
+// AccountDelegationResponse represents a delegation in API responses
+type AccountDelegationResponse struct {
+	ID         string           `json:"id"`
+	AccountID  string           `json:"account_id"`
+	Account    *UserResponse    `json:"account,omitempty"`
+	DelegateID string           `json:"delegate_id"`
+	Delegate   *UserResponse    `json:"delegate,omitempty"`
+	Role       DelegateRole     `json:"role"`
+	Status     DelegationStatus `json:"status"`
+	AcceptedAt *time.Time       `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time       `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+// InviteDelegateRequest represents the request to invite a delegate
+type InviteDelegateRequest struct {
+	UserID string       `json:"user_id" validate:"required"`
+	Role   DelegateRole `json:"role" validate:"required,oneof=editor analyst moderator"`
+}
+
+// UpdateDelegateRoleRequest represents the request to change a delegate's role
+type UpdateDelegateRoleRequest struct {
+	Role DelegateRole `json:"role" validate:"required,oneof=editor analyst moderator"`
+}
+
+// ToAccountDelegationResponse converts AccountDelegation to AccountDelegationResponse
+func (d *AccountDelegation) ToAccountDelegationResponse() AccountDelegationResponse {
+	response := AccountDelegationResponse{
+		ID:         d.ID.Hex(),
+		AccountID:  d.AccountID.Hex(),
+		DelegateID: d.DelegateID.Hex(),
+		Role:       d.Role,
+		Status:     d.Status,
+		AcceptedAt: d.AcceptedAt,
+		RevokedAt:  d.RevokedAt,
+		CreatedAt:  d.CreatedAt,
+	}
+
+	if d.Account.ID != "" {
+		account := d.Account
+		response.Account = &account
+	}
+	if d.Delegate.ID != "" {
+		delegate := d.Delegate
+		response.Delegate = &delegate
+	}
+
+	return response
+}
+
+// IsActive checks if the delegate can currently switch into the account
+func (d *AccountDelegation) IsActive() bool {
+	return d.Status == DelegationActive
+}
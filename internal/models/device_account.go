@@ -0,0 +1,56 @@
+// models/device_account.go
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxAccountsPerDeviceGroup caps how many accounts can be signed in side by
+// side on one device
+const MaxAccountsPerDeviceGroup = 5
+
+// UnreadBadgeCap is where the account switcher's unread counts stop counting;
+// clients show it as "99+"
+const UnreadBadgeCap = 100
+
+// Logout scopes
+const (
+	LogoutScopeCurrent     = "current"       // Only the account making the request
+	LogoutScopeAllOnDevice = "all-on-device" // Every account signed in on the device
+)
+
+// AddAccountRequest signs an additional account in on the current device
+type AddAccountRequest struct {
+	EmailOrUsername string `json:"email_or_username" validate:"required"`
+	Password        string `json:"password" validate:"required"`
+}
+
+// SwitchAccountRequest carries the password of the account being switched
+// to. It is only needed when the account has no valid session on the device.
+type SwitchAccountRequest struct {
+	Password string `json:"password,omitempty"`
+}
+
+// RegisterPushTokenRequest registers the device's push token for the current account
+type RegisterPushTokenRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Platform string `json:"platform" validate:"required,oneof=ios android web"`
+}
+
+// RemovePushTokenRequest removes the device's push token for the current account
+type RemovePushTokenRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// DeviceAccount is one account signed in on the device, as shown in the
+// account switcher
+type DeviceAccount struct {
+	UserID              primitive.ObjectID `json:"user_id"`
+	Username            string             `json:"username"`
+	DisplayName         string             `json:"display_name,omitempty"`
+	ProfilePic          string             `json:"profile_pic,omitempty"`
+	IsVerified          bool               `json:"is_verified"`
+	IsCurrent           bool               `json:"is_current"`
+	UnreadNotifications int64              `json:"unread_notifications"` // Capped at UnreadBadgeCap
+	UnreadConversations int64              `json:"unread_conversations"` // Capped at UnreadBadgeCap
+}
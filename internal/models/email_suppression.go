@@ -0,0 +1,64 @@
+// models/email_suppression.go
+package models
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EmailSuppressionReason is why no more email goes to an address
+type EmailSuppressionReason string
+
+const (
+	SuppressionHardBounce  EmailSuppressionReason = "hard_bounce" // The mail server rejected the address
+	SuppressionComplaint   EmailSuppressionReason = "complaint"   // The recipient marked an email as spam
+	SuppressionUnsubscribe EmailSuppressionReason = "unsubscribe" // The recipient used an unsubscribe link
+)
+
+// IsValid checks if the reason is one the suppression list knows about
+func (r EmailSuppressionReason) IsValid() bool {
+	return r == SuppressionHardBounce || r == SuppressionComplaint || r == SuppressionUnsubscribe
+}
+
+// BlocksTransactional reports whether the reason also stops account emails
+// such as password resets and verification. An address that bounces can't
+// receive anything, while complaints and unsubscribes only opt out of
+// notifications, digests and announcements.
+func (r EmailSuppressionReason) BlocksTransactional() bool {
+	return r == SuppressionHardBounce
+}
+
+// Sources of suppression list entries
+const (
+	SuppressionSourceSMTP        = "smtp"        // Recorded from a permanent SMTP rejection
+	SuppressionSourceUnsubscribe = "unsubscribe" // The signed link in an email footer or List-Unsubscribe header
+	SuppressionSourceAdmin       = "admin"
+)
+
+// EmailSuppression is one entry of the suppression list. An address can have
+// one entry per reason.
+type EmailSuppression struct {
+	ID        primitive.ObjectID     `json:"id" bson:"_id,omitempty"`
+	Email     string                 `json:"email" bson:"email"` // Lowercased, see NormalizeEmailAddress
+	Reason    EmailSuppressionReason `json:"reason" bson:"reason"`
+	Source    string                 `json:"source" bson:"source"`
+	Detail    string                 `json:"detail,omitempty" bson:"detail,omitempty"` // SMTP response or admin note
+	AddedBy   *primitive.ObjectID    `json:"added_by,omitempty" bson:"added_by,omitempty"`
+	CreatedAt time.Time              `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at" bson:"updated_at"`
+}
+
+// CreateEmailSuppressionRequest adds an address to the suppression list
+type CreateEmailSuppressionRequest struct {
+	Email  string                 `json:"email" validate:"required,email,max=254"`
+	Reason EmailSuppressionReason `json:"reason" validate:"required,oneof=hard_bounce complaint unsubscribe"`
+	Detail string                 `json:"detail,omitempty" validate:"max=500"`
+}
+
+// NormalizeEmailAddress trims and lowercases an address so suppressions
+// match however it was typed
+func NormalizeEmailAddress(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -43,8 +43,8 @@ type Event struct {
 	Creator    UserResponse         `json:"creator,omitempty" bson:"-"` // Populated when querying
 	GroupID    *primitive.ObjectID  `json:"group_id,omitempty" bson:"group_id,omitempty"`
 	Group      *GroupResponse       `json:"group,omitempty" bson:"-"` // Populated when querying
-	Organizers []primitive.ObjectID `json:"organizers,omitempty" bson:"organizers,omitempty"`
-	CoHosts    []primitive.ObjectID `json:"co_hosts,omitempty" bson:"co_hosts,omitempty"`
+	Organizers []primitive.ObjectID `json:"organizers,omitempty" bson:"organizers,omitempty" bound:"20"`
+	CoHosts    []primitive.ObjectID `json:"co_hosts,omitempty" bson:"co_hosts,omitempty" bound:"20"`
 
 	// Event Settings
 	Status            EventStatus  `json:"status" bson:"status"`
@@ -0,0 +1,44 @@
+// models/feed_algorithm.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Feed algorithms
+const (
+	FeedAlgorithmChronological = "chronological" // Newest first, no ranking
+	FeedAlgorithmStandard      = "standard"      // Ranked by engagement, recency and affinity
+	FeedAlgorithmBehavior      = "behavior"      // Standard ranking adjusted by the user's tracked behavior
+	FeedAlgorithmHybrid        = "hybrid"        // Ranked items alternating with the newest ones
+)
+
+// Where the algorithm serving a feed request came from
+const (
+	FeedAlgorithmSourceRequest = "request" // The algorithm query parameter or a route that fixes it
+	FeedAlgorithmSourceSetting = "setting" // The user's feed algorithm setting
+	FeedAlgorithmSourceDefault = "default" // The configured default
+)
+
+// IsValidFeedAlgorithm checks if an algorithm can serve feeds
+func IsValidFeedAlgorithm(algorithm string) bool {
+	switch algorithm {
+	case FeedAlgorithmChronological, FeedAlgorithmStandard, FeedAlgorithmBehavior, FeedAlgorithmHybrid:
+		return true
+	}
+	return false
+}
+
+// FeedAlgorithmLog records which algorithm served one feed request, for
+// comparing algorithms
+type FeedAlgorithmLog struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `json:"user_id,omitempty" bson:"user_id,omitempty"` // Unset for anonymous requests
+	FeedType  string             `json:"feed_type" bson:"feed_type"`
+	Algorithm string             `json:"algorithm" bson:"algorithm"`
+	Source    string             `json:"source" bson:"source"`
+	Items     int                `json:"items" bson:"items"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
@@ -77,6 +77,22 @@ type CreateFollowRequest struct {
 	Categories           []string `json:"categories,omitempty"`
 }
 
+// MaxFollowManyTargets caps how many accounts one follow-many call can follow
+const MaxFollowManyTargets = 50
+
+// FollowManyRequest represents the request to follow several users at once
+type FollowManyRequest struct {
+	UserIDs []string `json:"user_ids" validate:"required,min=1,max=50"`
+}
+
+// FollowManyResult is the outcome of following one target of a follow-many call
+type FollowManyResult struct {
+	UserID  string       `json:"user_id"`
+	Status  FollowStatus `json:"status,omitempty"` // pending for private accounts
+	Created bool         `json:"created"`          // false when already following or requested
+	Error   string       `json:"error,omitempty"`
+}
+
 // UpdateFollowRequest represents the request to update follow settings
 type UpdateFollowRequest struct {
 	NotificationsEnabled *bool    `json:"notifications_enabled,omitempty"`
@@ -90,6 +106,9 @@ type FollowStatsResponse struct {
 	FollowersCount int64  `json:"followers_count"`
 	FollowingCount int64  `json:"following_count"`
 	MutualFollows  int64  `json:"mutual_follows,omitempty"`
+
+	// Set when the user hides their follower and following lists from the viewer
+	SocialGraphHidden bool `json:"social_graph_hidden,omitempty"`
 }
 
 // FollowSuggestionResponse represents a follow suggestion
@@ -2,6 +2,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -42,6 +43,7 @@ type Group struct {
 	// Group Rules and Settings
 	Rules                  []GroupRule `json:"rules,omitempty" bson:"rules,omitempty"`
 	PostApprovalRequired   bool        `json:"post_approval_required" bson:"post_approval_required"`
+	WhoCanPost             WhoCanPost  `json:"who_can_post,omitempty" bson:"who_can_post,omitempty"` // Everyone when empty
 	MemberApprovalRequired bool        `json:"member_approval_required" bson:"member_approval_required"`
 	AllowMemberInvites     bool        `json:"allow_member_invites" bson:"allow_member_invites"`
 	AllowExternalSharing   bool        `json:"allow_external_sharing" bson:"allow_external_sharing"`
@@ -67,10 +69,24 @@ type Group struct {
 	IsPremium       bool     `json:"is_premium" bson:"is_premium"`
 	PremiumFeatures []string `json:"premium_features,omitempty" bson:"premium_features,omitempty"`
 
+	// Resource Library
+	LibraryUploadRole  GroupRole `json:"library_upload_role,omitempty" bson:"library_upload_role,omitempty"` // Lowest role allowed to upload, members when empty
+	LibraryQuotaBytes  int64     `json:"library_quota_bytes,omitempty" bson:"library_quota_bytes,omitempty"` // Admin-set limit, capped by the configured quota
+	LibraryStorageUsed int64     `json:"library_storage_used" bson:"library_storage_used"`
+	LibraryFilesCount  int64     `json:"library_files_count" bson:"library_files_count"`
+
 	// Custom Fields
 	CustomFields map[string]interface{} `json:"custom_fields,omitempty" bson:"custom_fields,omitempty"`
 }
 
+// WhoCanPost decides which members may post in a group
+type WhoCanPost string
+
+const (
+	WhoCanPostEveryone WhoCanPost = "everyone" // Every active member
+	WhoCanPostAdmins   WhoCanPost = "admins"   // The owner, admins and moderators
+)
+
 // GroupRule represents a rule for the group
 type GroupRule struct {
 	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
@@ -108,9 +124,10 @@ type GroupMember struct {
 	LastActiveAt  *time.Time `json:"last_active_at,omitempty" bson:"last_active_at,omitempty"`
 
 	// Member settings
-	NotificationsEnabled bool       `json:"notifications_enabled" bson:"notifications_enabled"`
-	IsMuted              bool       `json:"is_muted" bson:"is_muted"`
-	MutedUntil           *time.Time `json:"muted_until,omitempty" bson:"muted_until,omitempty"`
+	NotificationsEnabled bool                   `json:"notifications_enabled" bson:"notifications_enabled"`
+	IsMuted              bool                   `json:"is_muted" bson:"is_muted"`
+	MutedUntil           *time.Time             `json:"muted_until,omitempty" bson:"muted_until,omitempty"`
+	NotificationLevel    GroupNotificationLevel `json:"notification_level,omitempty" bson:"notification_level,omitempty"` // Set on joining; see EffectiveNotificationLevel
 
 	// Custom member data
 	Nickname     string                 `json:"nickname,omitempty" bson:"nickname,omitempty"`
@@ -118,6 +135,16 @@ type GroupMember struct {
 	CustomFields map[string]interface{} `json:"custom_fields,omitempty" bson:"custom_fields,omitempty"`
 }
 
+// GroupNotificationLevel decides which of a group's notifications reach a member
+type GroupNotificationLevel string
+
+const (
+	GroupNotifyAll        GroupNotificationLevel = "all"        // Every post, comment and event
+	GroupNotifyHighlights GroupNotificationLevel = "highlights" // Posts and events from admins and moderators, replies to the member and mentions
+	GroupNotifyMentions   GroupNotificationLevel = "mentions"   // Only mentions of the member
+	GroupNotifyMuted      GroupNotificationLevel = "muted"      // Nothing, including push and email already queued
+)
+
 // GroupInvite represents an invitation to join a group
 type GroupInvite struct {
 	BaseModel `bson:",inline"`
@@ -141,6 +168,24 @@ type GroupInvite struct {
 	Invitee UserResponse  `json:"invitee,omitempty" bson:"-"`
 }
 
+// GroupInviteLink represents a shareable token link that lets anyone holding it join a group
+type GroupInviteLink struct {
+	BaseModel `bson:",inline"`
+
+	GroupID   primitive.ObjectID `json:"group_id" bson:"group_id"`
+	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`
+	Token     string             `json:"token" bson:"token"`
+
+	// Limits
+	MaxUses   int64      `json:"max_uses" bson:"max_uses"` // 0 means unlimited
+	UsesCount int64      `json:"uses_count" bson:"uses_count"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" bson:"expires_at,omitempty"` // Never expires when nil
+
+	// Revocation
+	RevokedAt *time.Time          `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+	RevokedBy *primitive.ObjectID `json:"revoked_by,omitempty" bson:"revoked_by,omitempty"`
+}
+
 // Response Models
 
 // GroupResponse represents the group data returned in API responses
@@ -166,6 +211,7 @@ type GroupResponse struct {
 	EventsCount            int64        `json:"events_count"`
 	Rules                  []GroupRule  `json:"rules,omitempty"`
 	PostApprovalRequired   bool         `json:"post_approval_required"`
+	WhoCanPost             WhoCanPost   `json:"who_can_post"`
 	MemberApprovalRequired bool         `json:"member_approval_required"`
 	AllowMemberInvites     bool         `json:"allow_member_invites"`
 	AllowExternalSharing   bool         `json:"allow_external_sharing"`
@@ -183,6 +229,11 @@ type GroupResponse struct {
 	CreatedAt              time.Time    `json:"created_at"`
 	UpdatedAt              time.Time    `json:"updated_at"`
 
+	// Resource library
+	LibraryFilesCount int64                `json:"library_files_count"`
+	LibraryUploadRole GroupRole            `json:"library_upload_role"`
+	LibraryStorage    *GroupLibraryStorage `json:"library_storage,omitempty"`
+
 	// User-specific context
 	UserRole    GroupRole  `json:"user_role,omitempty"`
 	UserStatus  string     `json:"user_status,omitempty"` // member, pending, invited, not_member
@@ -193,6 +244,8 @@ type GroupResponse struct {
 	CanInvite   bool       `json:"can_invite,omitempty"`
 	CanModerate bool       `json:"can_moderate,omitempty"`
 	JoinedAt    *time.Time `json:"joined_at,omitempty"`
+
+	NotificationLevel GroupNotificationLevel `json:"notification_level,omitempty"` // The caller's setting when they are a member
 }
 
 // GroupMemberResponse represents group member data
@@ -231,6 +284,21 @@ type GroupInviteResponse struct {
 	TimeAgo   string        `json:"time_ago,omitempty"`
 }
 
+// GroupInviteLinkResponse represents an invite link returned in API responses
+type GroupInviteLinkResponse struct {
+	ID        string     `json:"id"`
+	GroupID   string     `json:"group_id"`
+	CreatedBy string     `json:"created_by"`
+	Token     string     `json:"token"`
+	URL       string     `json:"url"`
+	MaxUses   int64      `json:"max_uses"`
+	UsesCount int64      `json:"uses_count"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	IsActive  bool       `json:"is_active"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
 // Request Models
 
 // CreateGroupRequest represents the request to create a group
@@ -244,6 +312,7 @@ type CreateGroupRequest struct {
 	Website                string       `json:"website,omitempty" validate:"omitempty,url"`
 	Rules                  []GroupRule  `json:"rules,omitempty"`
 	PostApprovalRequired   bool         `json:"post_approval_required"`
+	WhoCanPost             WhoCanPost   `json:"who_can_post,omitempty" validate:"omitempty,oneof=everyone admins"`
 	MemberApprovalRequired bool         `json:"member_approval_required"`
 	AllowMemberInvites     bool         `json:"allow_member_invites"`
 	AllowExternalSharing   bool         `json:"allow_external_sharing"`
@@ -264,12 +333,15 @@ type UpdateGroupRequest struct {
 	Color                  *string       `json:"color,omitempty"`
 	Rules                  []GroupRule   `json:"rules,omitempty"`
 	PostApprovalRequired   *bool         `json:"post_approval_required,omitempty"`
+	WhoCanPost             *WhoCanPost   `json:"who_can_post,omitempty" validate:"omitempty,oneof=everyone admins"`
 	MemberApprovalRequired *bool         `json:"member_approval_required,omitempty"`
 	AllowMemberInvites     *bool         `json:"allow_member_invites,omitempty"`
 	AllowExternalSharing   *bool         `json:"allow_external_sharing,omitempty"`
 	AllowPolls             *bool         `json:"allow_polls,omitempty"`
 	AllowEvents            *bool         `json:"allow_events,omitempty"`
 	AllowDiscussions       *bool         `json:"allow_discussions,omitempty"`
+	LibraryUploadRole      *GroupRole    `json:"library_upload_role,omitempty" validate:"omitempty,oneof=member moderator admin"`
+	LibraryQuotaBytes      *int64        `json:"library_quota_bytes,omitempty" validate:"omitempty,min=0"` // 0 restores the configured quota
 }
 
 // JoinGroupRequest represents the request to join a group
@@ -283,6 +355,22 @@ type InviteToGroupRequest struct {
 	Message string   `json:"message,omitempty" validate:"max=500"`
 }
 
+// CreateGroupInviteLinkRequest represents the request to create a group invite link
+type CreateGroupInviteLinkRequest struct {
+	MaxUses   int64      `json:"max_uses,omitempty" validate:"min=0,max=10000"` // 0 means unlimited
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// UpdateGroupNotificationSettingsRequest represents a member's change to their group notification level
+type UpdateGroupNotificationSettingsRequest struct {
+	Level GroupNotificationLevel `json:"level" validate:"required,oneof=all highlights mentions muted"`
+}
+
+// ReviewGroupPostRequest represents a moderator's decision on a pending group post
+type ReviewGroupPostRequest struct {
+	Reason string `json:"reason,omitempty" validate:"max=500"`
+}
+
 // UpdateMemberRoleRequest represents the request to update a member's role
 type UpdateMemberRoleRequest struct {
 	Role GroupRole `json:"role" validate:"required,oneof=member moderator admin"`
@@ -357,6 +445,7 @@ func (g *Group) ToGroupResponse() GroupResponse {
 		EventsCount:            g.EventsCount,
 		Rules:                  g.Rules,
 		PostApprovalRequired:   g.PostApprovalRequired,
+		WhoCanPost:             g.EffectiveWhoCanPost(),
 		MemberApprovalRequired: g.MemberApprovalRequired,
 		AllowMemberInvites:     g.AllowMemberInvites,
 		AllowExternalSharing:   g.AllowExternalSharing,
@@ -373,6 +462,53 @@ func (g *Group) ToGroupResponse() GroupResponse {
 		IsPremium:              g.IsPremium,
 		CreatedAt:              g.CreatedAt,
 		UpdatedAt:              g.UpdatedAt,
+		LibraryFilesCount:      g.LibraryFilesCount,
+		LibraryUploadRole:      g.EffectiveLibraryUploadRole(),
+	}
+}
+
+// EffectiveWhoCanPost returns which members may post in the group
+func (g *Group) EffectiveWhoCanPost() WhoCanPost {
+	if g.WhoCanPost == "" {
+		return WhoCanPostEveryone
+	}
+	return g.WhoCanPost
+}
+
+// EffectiveLibraryUploadRole returns the lowest role allowed to upload to the library
+func (g *Group) EffectiveLibraryUploadRole() GroupRole {
+	if g.LibraryUploadRole == "" {
+		return GroupRoleMember
+	}
+	return g.LibraryUploadRole
+}
+
+// CanUploadToLibrary checks if a member with the given role may upload library files
+func (g *Group) CanUploadToLibrary(userRole GroupRole, memberStatus string) bool {
+	if memberStatus != "active" {
+		return false
+	}
+	return groupRoleRank(userRole) >= groupRoleRank(g.EffectiveLibraryUploadRole())
+}
+
+// CanManageLibrary checks if a user can move or delete any library file
+func (g *Group) CanManageLibrary(userRole GroupRole) bool {
+	return userRole == GroupRoleAdmin || userRole == GroupRoleOwner
+}
+
+// groupRoleRank orders group roles from least to most privileged
+func groupRoleRank(role GroupRole) int {
+	switch role {
+	case GroupRoleOwner:
+		return 4
+	case GroupRoleAdmin:
+		return 3
+	case GroupRoleModerator:
+		return 2
+	case GroupRoleMember:
+		return 1
+	default:
+		return 0
 	}
 }
 
@@ -466,14 +602,19 @@ func (g *Group) CanPostInGroup(userRole GroupRole, memberStatus string) bool {
 		return false
 	}
 
-	// Check if group allows discussions
-	if !g.AllowDiscussions {
-		return userRole == GroupRoleAdmin || userRole == GroupRoleModerator
+	// Check if group allows discussions and member posts
+	if !g.AllowDiscussions || g.EffectiveWhoCanPost() == WhoCanPostAdmins {
+		return g.CanModerateGroup(userRole)
 	}
 
 	return true
 }
 
+// RequiresPostApproval checks if a post by a member with this role waits for moderator review
+func (g *Group) RequiresPostApproval(userRole GroupRole) bool {
+	return g.PostApprovalRequired && !g.CanModerateGroup(userRole)
+}
+
 // CanInviteToGroup checks if a user can invite others to this group
 func (g *Group) CanInviteToGroup(userRole GroupRole) bool {
 	if !g.AllowMemberInvites {
@@ -609,6 +750,40 @@ func (gi *GroupInvite) IsExpired() bool {
 	return time.Now().After(gi.ExpiresAt)
 }
 
+// Methods for GroupInviteLink model
+
+// ToGroupInviteLinkResponse converts GroupInviteLink to GroupInviteLinkResponse
+func (gl *GroupInviteLink) ToGroupInviteLinkResponse(baseURL string) GroupInviteLinkResponse {
+	return GroupInviteLinkResponse{
+		ID:        gl.ID.Hex(),
+		GroupID:   gl.GroupID.Hex(),
+		CreatedBy: gl.CreatedBy.Hex(),
+		Token:     gl.Token,
+		URL:       strings.TrimRight(baseURL, "/") + "/groups/join/" + gl.Token,
+		MaxUses:   gl.MaxUses,
+		UsesCount: gl.UsesCount,
+		ExpiresAt: gl.ExpiresAt,
+		RevokedAt: gl.RevokedAt,
+		IsActive:  gl.IsUsable(),
+		CreatedAt: gl.CreatedAt,
+	}
+}
+
+// IsExpired checks if the invite link has passed its expiry time
+func (gl *GroupInviteLink) IsExpired() bool {
+	return gl.ExpiresAt != nil && time.Now().After(*gl.ExpiresAt)
+}
+
+// IsExhausted checks if the invite link has reached its usage limit
+func (gl *GroupInviteLink) IsExhausted() bool {
+	return gl.MaxUses > 0 && gl.UsesCount >= gl.MaxUses
+}
+
+// IsUsable checks if the invite link can still be used to join
+func (gl *GroupInviteLink) IsUsable() bool {
+	return gl.RevokedAt == nil && !gl.IsExpired() && !gl.IsExhausted()
+}
+
 // Utility functions
 
 // GetGroupCategories returns available group categories
@@ -647,3 +822,32 @@ func IsValidGroupCategory(category string) bool {
 	}
 	return false
 }
+
+// EffectiveNotificationLevel returns the member's notification level. Members
+// who joined before levels existed keep their old mute setting.
+func (gm *GroupMember) EffectiveNotificationLevel() GroupNotificationLevel {
+	if gm.NotificationLevel != "" {
+		return gm.NotificationLevel
+	}
+	if gm.IsMuted || !gm.NotificationsEnabled {
+		return GroupNotifyMuted
+	}
+	return GroupNotifyAll
+}
+
+// Allows checks if a group notification reaches a member at this level.
+// Comment and quick reply notifications only go to the author of the post or
+// comment replied to, so they count as replies to the member.
+func (l GroupNotificationLevel) Allows(notificationType NotificationType, fromStaff bool) bool {
+	switch l {
+	case GroupNotifyMuted:
+		return false
+	case GroupNotifyMentions:
+		return notificationType == NotificationMention
+	case GroupNotifyHighlights:
+		return notificationType == NotificationMention || notificationType == NotificationComment ||
+			notificationType == NotificationQuickReply || fromStaff
+	default:
+		return true
+	}
+}
@@ -0,0 +1,128 @@
+// models/group_library.go
+package models
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxLibraryFolderDepth limits how deeply library folders can nest
+const MaxLibraryFolderDepth = 5
+
+// GroupFile is an entry in a group's resource library, stored in the
+// group_files collection. The file itself is a media record uploaded through
+// MediaService; the entry adds the folder, description and tags members
+// browse by.
+type GroupFile struct {
+	BaseModel `bson:",inline"`
+
+	GroupID    primitive.ObjectID `json:"group_id" bson:"group_id"`
+	MediaID    primitive.ObjectID `json:"media_id" bson:"media_id"`
+	UploadedBy primitive.ObjectID `json:"uploaded_by" bson:"uploaded_by"`
+	Uploader   UserResponse       `json:"uploader,omitempty" bson:"-"` // Populated when querying
+
+	// File details, copied from the media record
+	Name          string `json:"name" bson:"name"`
+	FileSize      int64  `json:"file_size" bson:"file_size"`
+	MimeType      string `json:"mime_type" bson:"mime_type"`
+	FileExtension string `json:"file_extension" bson:"file_extension"`
+
+	// Library organization
+	Folder      string   `json:"folder" bson:"folder"` // Slash-separated path, empty for the library root
+	Description string   `json:"description,omitempty" bson:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty" bson:"tags,omitempty"`
+
+	DownloadCount int64 `json:"download_count" bson:"download_count"`
+}
+
+// GroupFileResponse represents a library file returned in API responses
+type GroupFileResponse struct {
+	ID            string       `json:"id"`
+	GroupID       string       `json:"group_id"`
+	UploadedBy    string       `json:"uploaded_by"`
+	Uploader      UserResponse `json:"uploader,omitempty"`
+	Name          string       `json:"name"`
+	FileSize      int64        `json:"file_size"`
+	MimeType      string       `json:"mime_type"`
+	FileExtension string       `json:"file_extension"`
+	Folder        string       `json:"folder"`
+	Description   string       `json:"description,omitempty"`
+	Tags          []string     `json:"tags,omitempty"`
+	DownloadCount int64        `json:"download_count"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+// GroupLibraryFolder summarizes one folder of a group's library
+type GroupLibraryFolder struct {
+	Path       string `json:"path" bson:"_id"`
+	FilesCount int64  `json:"files_count" bson:"files_count"`
+	TotalSize  int64  `json:"total_size" bson:"total_size"`
+}
+
+// GroupLibraryStorage reports how much of its quota a group's library uses
+type GroupLibraryStorage struct {
+	UsedBytes  int64 `json:"used_bytes"`
+	QuotaBytes int64 `json:"quota_bytes"`
+	FilesCount int64 `json:"files_count"`
+}
+
+// UploadGroupFileRequest carries the form fields sent with a library upload
+type UploadGroupFileRequest struct {
+	Folder      string   `json:"folder" validate:"max=200"`
+	Description string   `json:"description" validate:"max=1000"`
+	Tags        []string `json:"tags" validate:"max=20,dive,max=50"`
+}
+
+// UpdateGroupFileRequest represents the request to edit or move a library file
+type UpdateGroupFileRequest struct {
+	Folder      *string  `json:"folder,omitempty" validate:"omitempty,max=200"`
+	Description *string  `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Tags        []string `json:"tags,omitempty" validate:"omitempty,max=20,dive,max=50"`
+}
+
+// ToGroupFileResponse converts GroupFile to GroupFileResponse
+func (f *GroupFile) ToGroupFileResponse() GroupFileResponse {
+	return GroupFileResponse{
+		ID:            f.ID.Hex(),
+		GroupID:       f.GroupID.Hex(),
+		UploadedBy:    f.UploadedBy.Hex(),
+		Uploader:      f.Uploader,
+		Name:          f.Name,
+		FileSize:      f.FileSize,
+		MimeType:      f.MimeType,
+		FileExtension: f.FileExtension,
+		Folder:        f.Folder,
+		Description:   f.Description,
+		Tags:          f.Tags,
+		DownloadCount: f.DownloadCount,
+		CreatedAt:     f.CreatedAt,
+		UpdatedAt:     f.UpdatedAt,
+	}
+}
+
+// NormalizeLibraryFolder cleans a folder path into the stored form, e.g.
+// "/Notes//week 1/" becomes "Notes/week 1". The root folder is "".
+func NormalizeLibraryFolder(folder string) (string, error) {
+	folder = strings.TrimSpace(folder)
+	if folder == "" || folder == "/" {
+		return "", nil
+	}
+
+	cleaned := strings.Trim(path.Clean("/"+folder), "/")
+	segments := strings.Split(cleaned, "/")
+	if len(segments) > MaxLibraryFolderDepth {
+		return "", errors.New("invalid folder: nested too deeply")
+	}
+	for _, segment := range segments {
+		if segment == "" || segment == "." || segment == ".." {
+			return "", errors.New("invalid folder name")
+		}
+	}
+
+	return cleaned, nil
+}
@@ -41,9 +41,10 @@ type Hashtag struct {
 	AgeDistribution    map[string]int64 `json:"age_distribution,omitempty" bson:"age_distribution,omitempty"`
 	GenderDistribution map[string]int64 `json:"gender_distribution,omitempty" bson:"gender_distribution,omitempty"`
 
-	// Related Hashtags
+	// Related Hashtags, recomputed daily from co-occurrence in recent posts
 	RelatedTags        []string              `json:"related_tags,omitempty" bson:"related_tags,omitempty"`
 	FrequentlyUsedWith []HashtagCooccurrence `json:"frequently_used_with,omitempty" bson:"frequently_used_with,omitempty"`
+	RelatedComputedAt  *time.Time            `json:"-" bson:"related_computed_at,omitempty"`
 
 	// Content Classification
 	Category    string   `json:"category,omitempty" bson:"category,omitempty"` // entertainment, sports, news, etc.
@@ -51,6 +52,9 @@ type Hashtag struct {
 	ContentType []string `json:"content_type,omitempty" bson:"content_type,omitempty"` // text, image, video
 	Sentiment   string   `json:"sentiment,omitempty" bson:"sentiment,omitempty"`       // positive, negative, neutral
 
+	// Set when an admin picks the category; automatic categorization leaves it alone
+	CategoryLocked bool `json:"category_locked,omitempty" bson:"category_locked,omitempty"`
+
 	// Moderation
 	IsBlocked     bool                `json:"is_blocked" bson:"is_blocked"`
 	IsSensitive   bool                `json:"is_sensitive" bson:"is_sensitive"`
@@ -149,6 +153,29 @@ type HashtagTrendingResponse struct {
 	RankChange      string  `json:"rank_change"` // up, down, new, same
 }
 
+// HashtagPageResponse is the hashtag detail page. Blocked hashtags are only
+// ever returned to admins, with IsBlocked set so clients can show a banner.
+type HashtagPageResponse struct {
+	HashtagResponse
+	IsFollowing     bool                  `json:"is_following"`
+	IsBlocked       bool                  `json:"is_blocked,omitempty"`
+	BlockedReason   string                `json:"blocked_reason,omitempty"`
+	RelatedHashtags []HashtagCooccurrence `json:"related_hashtags"`
+}
+
+// Hashtag page post tabs
+const (
+	HashtagTabTop    = "top"    // Ranked by engagement, posts of the last 7 days
+	HashtagTabRecent = "recent" // Newest first, cursor paginated
+)
+
+// HashtagPostResponse is a post on a hashtag page. SensitiveHidden asks the
+// client to cover the post with its content warning until tapped.
+type HashtagPostResponse struct {
+	PostResponse
+	SensitiveHidden bool `json:"sensitive_hidden,omitempty"`
+}
+
 // CreateHashtagRequest represents request to create/track a hashtag
 type CreateHashtagRequest struct {
 	Tag         string `json:"tag" validate:"required,min=1,max=100"`
@@ -348,27 +375,6 @@ func (m *Mention) Unblock() {
 }
 
 
-// ExtractHashtagsFromText extracts hashtags from text content
-func ExtractHashtagsFromText(text string) []string {
-	// Simple hashtag extraction - in practice would use regex
-	var hashtags []string
-	words := strings.Fields(text)
-
-	for _, word := range words {
-		if strings.HasPrefix(word, "#") && len(word) > 1 {
-			// Clean the hashtag
-			tag := strings.TrimPrefix(word, "#")
-			// Remove trailing punctuation
-			tag = strings.TrimRight(tag, ".,!?;:")
-			if len(tag) > 0 {
-				hashtags = append(hashtags, tag)
-			}
-		}
-	}
-
-	return hashtags
-}
-
 
 // IsValidHashtag checks if a hashtag string is valid
 func IsValidHashtag(tag string) bool {
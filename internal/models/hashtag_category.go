@@ -0,0 +1,102 @@
+// models/hashtag_category.go
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// HashtagCategoryFallback is the category of hashtags no keyword matches
+const HashtagCategoryFallback = "general"
+
+// minHashtagAffixKeyword is the shortest keyword matched as a prefix or
+// suffix; shorter ones ("ai", "tv") would match far too many tags
+const minHashtagAffixKeyword = 3
+
+// HashtagCategorizer maps hashtags to categories by keyword
+type HashtagCategorizer struct {
+	exact    map[string]string
+	keywords []hashtagKeyword // Longest first
+}
+
+type hashtagKeyword struct {
+	keyword  string
+	category string
+}
+
+// NewHashtagCategorizer builds a categorizer from a category -> keywords map.
+// A keyword listed under several categories goes to the first in name order.
+func NewHashtagCategorizer(categoryKeywords map[string][]string) *HashtagCategorizer {
+	categories := make([]string, 0, len(categoryKeywords))
+	for category := range categoryKeywords {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	hc := &HashtagCategorizer{exact: make(map[string]string)}
+	for _, category := range categories {
+		for _, keyword := range categoryKeywords[category] {
+			keyword = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(keyword), "#"))
+			if keyword == "" {
+				continue
+			}
+			if _, exists := hc.exact[keyword]; exists {
+				continue
+			}
+			hc.exact[keyword] = category
+			if len(keyword) >= minHashtagAffixKeyword {
+				hc.keywords = append(hc.keywords, hashtagKeyword{keyword: keyword, category: category})
+			}
+		}
+	}
+
+	sort.Slice(hc.keywords, func(i, j int) bool {
+		if len(hc.keywords[i].keyword) != len(hc.keywords[j].keyword) {
+			return len(hc.keywords[i].keyword) > len(hc.keywords[j].keyword)
+		}
+		return hc.keywords[i].keyword < hc.keywords[j].keyword
+	})
+
+	return hc
+}
+
+// Categorize returns the category of the keyword the tag equals, else of the
+// longest keyword it starts or ends with, else HashtagCategoryFallback
+func (hc *HashtagCategorizer) Categorize(tag string) string {
+	tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+
+	if category, ok := hc.exact[tag]; ok {
+		return category
+	}
+	for _, kw := range hc.keywords {
+		if strings.HasPrefix(tag, kw.keyword) || strings.HasSuffix(tag, kw.keyword) {
+			return kw.category
+		}
+	}
+	return HashtagCategoryFallback
+}
+
+// IsKnownCategory checks if a category is one of the built-in hashtag
+// categories or has configured keywords
+func (hc *HashtagCategorizer) IsKnownCategory(category string) bool {
+	if IsValidHashtagCategory(category) {
+		return true
+	}
+	for _, known := range hc.exact {
+		if known == category {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateHashtagCategoryRequest overrides a hashtag's category
+type UpdateHashtagCategoryRequest struct {
+	Category string `json:"category" validate:"required"`
+}
+
+// RecategorizeHashtagsResponse reports a recategorization run
+type RecategorizeHashtagsResponse struct {
+	Scanned int64 `json:"scanned"`
+	Changed int64 `json:"changed"`
+}
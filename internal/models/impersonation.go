@@ -0,0 +1,50 @@
+// models/impersonation.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// How users are told support signed in as them
+const (
+	ImpersonationNotifyAlways   = "always"
+	ImpersonationNotifyNever    = "never"
+	ImpersonationNotifyOptional = "optional" // The admin decides per session
+)
+
+// ImpersonationSession is a super admin signed in as a user to reproduce a
+// support issue. Its token is valid until ExpiresAt or until it is ended.
+type ImpersonationSession struct {
+	BaseModel `bson:",inline"`
+
+	AdminID      primitive.ObjectID `json:"admin_id" bson:"admin_id"`
+	UserID       primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Reason       string             `json:"reason" bson:"reason"`
+	UserNotified bool               `json:"user_notified" bson:"user_notified"`
+	ExpiresAt    time.Time          `json:"expires_at" bson:"expires_at"`
+	EndedAt      *time.Time         `json:"ended_at,omitempty" bson:"ended_at,omitempty"`
+	IPAddress    string             `json:"-" bson:"ip_address,omitempty"`
+}
+
+// IsActive checks if the session's token may still be used
+func (s *ImpersonationSession) IsActive() bool {
+	return s.EndedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+// StartImpersonationRequest opens an impersonation session. NotifyUser is
+// only consulted when the notification policy leaves it to the admin.
+type StartImpersonationRequest struct {
+	Reason     string `json:"reason" validate:"required,min=5,max=500"`
+	NotifyUser bool   `json:"notify_user,omitempty"`
+}
+
+// ImpersonationResponse carries the token an admin uses to act as the user
+type ImpersonationResponse struct {
+	Session     ImpersonationSession `json:"session"`
+	Account     UserResponse         `json:"account"`
+	AccessToken string               `json:"access_token"`
+	ExpiresIn   int64                `json:"expires_in"`
+	TokenType   string               `json:"token_type"`
+}
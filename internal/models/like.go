@@ -3,6 +3,7 @@ package models
 
 import (
 	"fmt"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -50,6 +51,63 @@ type UpdateLikeRequest struct {
 	ReactionType ReactionType `json:"reaction_type" validate:"required"`
 }
 
+// Reaction sync actions
+const (
+	ReactionActionAdd    = "add"
+	ReactionActionRemove = "remove"
+)
+
+// MaxReactionSyncItems caps the queued reaction changes one sync carries
+const MaxReactionSyncItems = 100
+
+// ReactionSyncItem is a reaction change a client queued while offline.
+// ClientTimestamp is when the user made the change on the device.
+type ReactionSyncItem struct {
+	TargetID        string       `json:"target_id" validate:"required"`
+	TargetType      string       `json:"target_type" validate:"required,oneof=post comment story message"`
+	ReactionType    ReactionType `json:"reaction_type,omitempty"` // Required to add
+	Action          string       `json:"action" validate:"required,oneof=add remove"`
+	ClientTimestamp time.Time    `json:"client_timestamp" validate:"required"`
+}
+
+// ReactionSyncRequest carries a client's queued reaction changes, oldest first
+type ReactionSyncRequest struct {
+	Items []ReactionSyncItem `json:"items" validate:"required,min=1,max=100,dive"`
+}
+
+// Outcomes of a synced reaction change
+const (
+	ReactionSyncApplied    = "applied"
+	ReactionSyncUnchanged  = "unchanged"  // The reaction was already in the requested state
+	ReactionSyncSuperseded = "superseded" // A later change to the same target, in the batch or on the server, wins
+	ReactionSyncFailed     = "failed"
+)
+
+// ReactionSyncResult is the outcome of one item of a sync, by its position
+// in the request
+type ReactionSyncResult struct {
+	Index      int    `json:"index"`
+	TargetID   string `json:"target_id"`
+	TargetType string `json:"target_type"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ReactionState is the user's reaction on a target after a sync. A nil
+// ReactionType means the user has no reaction on it.
+type ReactionState struct {
+	TargetID     string        `json:"target_id"`
+	TargetType   string        `json:"target_type"`
+	ReactionType *ReactionType `json:"reaction_type"`
+}
+
+// ReactionSyncResponse reports each item's outcome and the final reaction
+// on every target in the sync
+type ReactionSyncResponse struct {
+	Results []ReactionSyncResult `json:"results"`
+	States  []ReactionState      `json:"states"`
+}
+
 // ReactionSummary represents aggregated reaction counts for a target
 type ReactionSummary struct {
 	TargetID     string                 `json:"target_id"`
@@ -60,16 +118,8 @@ type ReactionSummary struct {
 	UserReaction ReactionType           `json:"user_reaction,omitempty"` // Current user's reaction
 }
 
-// ReactionStats represents detailed reaction statistics
-type ReactionStats struct {
-	Like    ReactionCount `json:"like"`
-	Love    ReactionCount `json:"love"`
-	Haha    ReactionCount `json:"haha"`
-	Wow     ReactionCount `json:"wow"`
-	Sad     ReactionCount `json:"sad"`
-	Angry   ReactionCount `json:"angry"`
-	Support ReactionCount `json:"support"`
-}
+// ReactionStats represents detailed reaction statistics keyed by reaction type
+type ReactionStats map[ReactionType]ReactionCount
 
 // ReactionCount represents count and sample users for a reaction type
 type ReactionCount struct {
@@ -127,30 +177,29 @@ func (l *Like) CanDeleteReaction(currentUserID primitive.ObjectID, userRole User
 
 // GetReactionEmoji returns the emoji representation of the reaction
 func (l *Like) GetReactionEmoji() string {
-	switch l.ReactionType {
-	case ReactionLike:
-		return "👍"
-	case ReactionLove:
-		return "❤️"
-	case ReactionHaha:
-		return "😂"
-	case ReactionWow:
-		return "😮"
-	case ReactionSad:
-		return "😢"
-	case ReactionAngry:
-		return "😠"
-	case ReactionSupport:
-		return "🤗"
-	default:
-		return "👍"
-	}
+	return GetReactionEmoji(l.ReactionType)
 }
 
 // Utility functions for reaction handling
 
 // GetReactionEmoji returns emoji for a reaction type
 func GetReactionEmoji(reactionType ReactionType) string {
+	if definition, exists := GetReactionDefinition(reactionType); exists && definition.Emoji != "" {
+		return definition.Emoji
+	}
+	return builtInReactionEmoji(reactionType)
+}
+
+// GetReactionName returns human-readable name for a reaction type
+func GetReactionName(reactionType ReactionType) string {
+	if definition, exists := GetReactionDefinition(reactionType); exists && definition.Name != "" {
+		return definition.Name
+	}
+	return builtInReactionName(reactionType)
+}
+
+// builtInReactionEmoji returns the emoji for one of the built-in reactions
+func builtInReactionEmoji(reactionType ReactionType) string {
 	switch reactionType {
 	case ReactionLike:
 		return "👍"
@@ -171,8 +220,8 @@ func GetReactionEmoji(reactionType ReactionType) string {
 	}
 }
 
-// GetReactionName returns human-readable name for a reaction type
-func GetReactionName(reactionType ReactionType) string {
+// builtInReactionName returns the name of one of the built-in reactions
+func builtInReactionName(reactionType ReactionType) string {
 	switch reactionType {
 	case ReactionLike:
 		return "Like"
@@ -193,37 +242,21 @@ func GetReactionName(reactionType ReactionType) string {
 	}
 }
 
-// IsValidReactionType checks if a reaction type is valid
+// IsValidReactionType checks if a reaction type is known, including retired reactions
 func IsValidReactionType(reactionType ReactionType) bool {
-	validTypes := []ReactionType{
-		ReactionLike,
-		ReactionLove,
-		ReactionHaha,
-		ReactionWow,
-		ReactionSad,
-		ReactionAngry,
-		ReactionSupport,
-	}
-
-	for _, validType := range validTypes {
-		if reactionType == validType {
-			return true
-		}
-	}
-	return false
+	_, exists := GetReactionDefinition(reactionType)
+	return exists
 }
 
-// GetAllReactionTypes returns all available reaction types
+// GetAllReactionTypes returns the reaction types currently available in the picker
 func GetAllReactionTypes() []ReactionType {
-	return []ReactionType{
-		ReactionLike,
-		ReactionLove,
-		ReactionHaha,
-		ReactionWow,
-		ReactionSad,
-		ReactionAngry,
-		ReactionSupport,
+	available := GetAvailableReactions(time.Now())
+
+	reactionTypes := make([]ReactionType, len(available))
+	for i, definition := range available {
+		reactionTypes[i] = definition.Key
 	}
+	return reactionTypes
 }
 
 // CreateReactionSummary creates a reaction summary from aggregated data
@@ -245,44 +278,20 @@ func CreateReactionSummary(targetID, targetType string, reactions map[ReactionTy
 
 // CreateReactionStats creates detailed reaction statistics
 func CreateReactionStats(reactions map[ReactionType]ReactionCount) ReactionStats {
-	// Initialize with zero counts
-	stats := ReactionStats{
-		Like:    ReactionCount{Count: 0, SampleUsers: []UserResponse{}},
-		Love:    ReactionCount{Count: 0, SampleUsers: []UserResponse{}},
-		Haha:    ReactionCount{Count: 0, SampleUsers: []UserResponse{}},
-		Wow:     ReactionCount{Count: 0, SampleUsers: []UserResponse{}},
-		Sad:     ReactionCount{Count: 0, SampleUsers: []UserResponse{}},
-		Angry:   ReactionCount{Count: 0, SampleUsers: []UserResponse{}},
-		Support: ReactionCount{Count: 0, SampleUsers: []UserResponse{}},
+	// Initialize available reactions with zero counts
+	stats := make(ReactionStats, len(reactions))
+	for _, reactionType := range GetAllReactionTypes() {
+		stats[reactionType] = ReactionCount{Count: 0, SampleUsers: []UserResponse{}}
 	}
 
-	// Fill in actual data
-	if count, exists := reactions[ReactionLike]; exists {
-		stats.Like = count
-	}
-	if count, exists := reactions[ReactionLove]; exists {
-		stats.Love = count
-	}
-	if count, exists := reactions[ReactionHaha]; exists {
-		stats.Haha = count
-	}
-	if count, exists := reactions[ReactionWow]; exists {
-		stats.Wow = count
-	}
-	if count, exists := reactions[ReactionSad]; exists {
-		stats.Sad = count
-	}
-	if count, exists := reactions[ReactionAngry]; exists {
-		stats.Angry = count
-	}
-	if count, exists := reactions[ReactionSupport]; exists {
-		stats.Support = count
+	// Fill in actual data, including reactions that have since been retired
+	for reactionType, count := range reactions {
+		stats[reactionType] = count
 	}
 
 	return stats
 }
 
-
 // ReactionInfo represents reaction information with count and metadata
 type ReactionInfo struct {
 	Type  ReactionType `json:"type"`
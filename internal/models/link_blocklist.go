@@ -0,0 +1,136 @@
+// models/link_blocklist.go
+package models
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LinkBlockAction is what happens to content linking to a blocklisted domain
+type LinkBlockAction string
+
+const (
+	LinkBlockReject LinkBlockAction = "reject" // The content is refused
+	LinkBlockHide   LinkBlockAction = "hide"   // The content is saved hidden and reported for review
+)
+
+// IsValid checks if the action is one the blocklist knows about
+func (a LinkBlockAction) IsValid() bool {
+	return a == LinkBlockReject || a == LinkBlockHide
+}
+
+// LinkBlockReportCategory is the report category filed for content hidden by
+// the link blocklist
+const LinkBlockReportCategory = "blocked_link"
+
+// Content types screened by the link blocklist
+const (
+	LinkBlockContentPost    = "post"
+	LinkBlockContentComment = "comment"
+	LinkBlockContentMessage = "message"
+)
+
+// BlockedDomain is one entry of the link blocklist. Domain is either a host
+// ("example.com"), which matches only that host, or a wildcard
+// ("*.example.com"), which matches the domain and every subdomain of it.
+type BlockedDomain struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	Domain    string              `json:"domain" bson:"domain"`
+	Action    LinkBlockAction     `json:"action" bson:"action"`
+	Reason    string              `json:"reason,omitempty" bson:"reason,omitempty"`
+	Source    string              `json:"source" bson:"source"` // admin or config
+	AddedBy   *primitive.ObjectID `json:"added_by,omitempty" bson:"added_by,omitempty"`
+	CreatedAt time.Time           `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at" bson:"updated_at"`
+}
+
+// IsWildcard reports whether the entry covers subdomains
+func (d *BlockedDomain) IsWildcard() bool {
+	return strings.HasPrefix(d.Domain, "*.")
+}
+
+// Matches reports whether host, as returned by NormalizeLinkHost, is covered
+// by the entry
+func (d *BlockedDomain) Matches(host string) bool {
+	if d.IsWildcard() {
+		base := d.Domain[2:]
+		return host == base || strings.HasSuffix(host, "."+base)
+	}
+	return host == d.Domain
+}
+
+// NormalizeLinkHost lowercases a host and drops the port, the trailing dot
+// and a leading "www.", so "WWW.Example.com.:443" and "example.com" compare
+// equal
+func NormalizeLinkHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, found := strings.Cut(host, ":"); found {
+		host = h
+	}
+	host = strings.TrimSuffix(host, ".")
+	return strings.TrimPrefix(host, "www.")
+}
+
+// NormalizeBlockedDomain turns what an admin typed ("https://www.Example.com/path",
+// "*.example.com") into the form stored on the blocklist
+func NormalizeBlockedDomain(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	wildcard := strings.HasPrefix(input, "*.")
+	input = strings.TrimPrefix(input, "*.")
+
+	if !strings.Contains(input, "://") {
+		input = "http://" + input
+	}
+	parsed, err := url.Parse(input)
+	if err != nil {
+		return "", errors.New("invalid domain")
+	}
+
+	host := NormalizeLinkHost(parsed.Host)
+	if !strings.Contains(host, ".") || strings.ContainsAny(host, "*/ ") {
+		return "", errors.New("invalid domain")
+	}
+
+	if wildcard {
+		return "*." + host, nil
+	}
+	return host, nil
+}
+
+// CreateBlockedDomainRequest adds a domain to the link blocklist
+type CreateBlockedDomainRequest struct {
+	Domain string          `json:"domain" validate:"required,max=253"`
+	Action LinkBlockAction `json:"action" validate:"omitempty,oneof=reject hide"`
+	Reason string          `json:"reason,omitempty" validate:"max=500"`
+}
+
+// UpdateBlockedDomainRequest changes how a blocklisted domain is handled
+type UpdateBlockedDomainRequest struct {
+	Action *LinkBlockAction `json:"action,omitempty" validate:"omitempty,oneof=reject hide"`
+	Reason *string          `json:"reason,omitempty" validate:"omitempty,max=500"`
+}
+
+// LinkBlockMatch is a link found in content that the blocklist covers
+type LinkBlockMatch struct {
+	URL    string          `json:"url"`    // The link after normalization
+	Host   string          `json:"host"`   // Host the rule matched, after resolving shorteners
+	Domain string          `json:"domain"` // The blocklist entry
+	Action LinkBlockAction `json:"action"`
+}
+
+// LinkBlockLog records content the blocklist acted on, for review
+type LinkBlockLog struct {
+	ID          primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	UserID      primitive.ObjectID  `json:"user_id" bson:"user_id"`
+	ContentType string              `json:"content_type" bson:"content_type"`                 // post, comment or message
+	ContentID   *primitive.ObjectID `json:"content_id,omitempty" bson:"content_id,omitempty"` // Only set for hidden content
+	URL         string              `json:"url" bson:"url"`
+	Host        string              `json:"host" bson:"host"`
+	Domain      string              `json:"domain" bson:"domain"`
+	Action      LinkBlockAction     `json:"action" bson:"action"`
+	CreatedAt   time.Time           `json:"created_at" bson:"created_at"`
+}
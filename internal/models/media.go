@@ -27,6 +27,11 @@ type Media struct {
 	Height   int    `json:"height,omitempty" bson:"height,omitempty"`
 	Duration int    `json:"duration,omitempty" bson:"duration,omitempty"` // in seconds
 
+	// Size of the upload when the stored original was downscaled to the
+	// maximum resolution
+	OriginalWidth  int `json:"original_width,omitempty" bson:"original_width,omitempty"`
+	OriginalHeight int `json:"original_height,omitempty" bson:"original_height,omitempty"`
+
 	// URLs
 	URL string `json:"url" bson:"url" validate:"required"`
 
@@ -39,7 +44,7 @@ type Media struct {
 	UploadedBy   primitive.ObjectID   `json:"uploaded_by" bson:"uploaded_by" validate:"required"`
 	IsPublic     bool                 `json:"is_public" bson:"is_public"`
 	AccessPolicy string               `json:"access_policy" bson:"access_policy"` // public, private, restricted
-	AllowedUsers []primitive.ObjectID `json:"allowed_users,omitempty" bson:"allowed_users,omitempty"`
+	AllowedUsers []primitive.ObjectID `json:"allowed_users,omitempty" bson:"allowed_users,omitempty" bound:"1000"`
 
 	// Usage tracking
 	ViewCount     int64 `json:"view_count" bson:"view_count"`
@@ -51,7 +56,7 @@ type Media struct {
 
 	// Processing status
 	IsProcessed      bool       `json:"is_processed" bson:"is_processed"`
-	ProcessingStatus string     `json:"processing_status" bson:"processing_status"` // pending, processing, completed, failed
+	ProcessingStatus string     `json:"processing_status" bson:"processing_status"` // pending, processing, completed, failed, quarantined
 	ProcessedAt      *time.Time `json:"processed_at,omitempty" bson:"processed_at,omitempty"`
 
 	// Storage information
@@ -59,14 +64,36 @@ type Media struct {
 	StorageKey      string `json:"storage_key" bson:"storage_key"`
 	StorageBucket   string `json:"storage_bucket,omitempty" bson:"storage_bucket,omitempty"`
 
+	// Storage tiering; only the original moves, thumbnails and variants stay hot
+	StorageTier     string     `json:"storage_tier,omitempty" bson:"storage_tier,omitempty"` // hot, cold
+	LastAccessedAt  *time.Time `json:"-" bson:"last_accessed_at,omitempty"`                  // Updated in batches from the serving path
+	TieredAt        *time.Time `json:"-" bson:"tiered_at,omitempty"`                         // When the original last changed tier
+	ColdRequestsDay string     `json:"-" bson:"cold_requests_day,omitempty"`                 // Day cold_requests counts, YYYY-MM-DD
+	ColdRequests    int        `json:"-" bson:"cold_requests,omitempty"`                     // Requests for the cold original that day
+
 	// Thumbnails and variants
 	Thumbnails []MediaVariant `json:"thumbnails,omitempty" bson:"thumbnails,omitempty"`
 	Variants   []MediaVariant `json:"variants,omitempty" bson:"variants,omitempty"`
 
+	// Bytes the WebP/AVIF variants save over their JPEG fallbacks, plus what
+	// downscaling the original or replacing it with the large variant saved
+	StorageSavedBytes int64 `json:"-" bson:"storage_saved_bytes,omitempty"`
+	OriginalDiscarded bool  `json:"original_discarded,omitempty" bson:"original_discarded,omitempty"`
+
+	// Duplicate detection for images. An upload byte-identical to one the
+	// user already has shares its stored file and points at it with
+	// DuplicateOf; the perceptual hash only suggests similar images.
+	ContentHash    string              `json:"-" bson:"content_hash,omitempty"`    // SHA-256 of the file, hex
+	PerceptualHash string              `json:"-" bson:"perceptual_hash,omitempty"` // 64-bit dHash, hex
+	DuplicateOf    *primitive.ObjectID `json:"duplicate_of,omitempty" bson:"duplicate_of,omitempty"`
+
 	// Expiration
 	ExpiresAt *time.Time `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
 	IsExpired bool       `json:"is_expired" bson:"is_expired"`
 
+	// Set by the orphan cleanup job once the file of deleted media is removed
+	FileRemovedAt *time.Time `json:"-" bson:"file_removed_at,omitempty"`
+
 	// Metadata
 	Metadata map[string]interface{} `json:"metadata,omitempty" bson:"metadata,omitempty"`
 	Tags     []string               `json:"tags,omitempty" bson:"tags,omitempty"`
@@ -77,6 +104,41 @@ type Media struct {
 	ModerationNotes      string `json:"moderation_notes,omitempty" bson:"moderation_notes,omitempty"`
 }
 
+// Media storage tiers
+const (
+	MediaTierHot  = "hot"
+	MediaTierCold = "cold"
+)
+
+// MediaTierUsage is the stored size of the media originals in one tier
+type MediaTierUsage struct {
+	Tier                 string  `json:"tier" bson:"_id"`
+	Files                int64   `json:"files" bson:"files"`
+	Bytes                int64   `json:"bytes" bson:"bytes"`
+	EstimatedMonthlyCost float64 `json:"estimated_monthly_cost" bson:"-"`
+}
+
+// MediaTierStats summarizes media storage per tier and what tiering saves
+type MediaTierStats struct {
+	Tiers                []MediaTierUsage `json:"tiers"`
+	EstimatedMonthlyCost float64          `json:"estimated_monthly_cost"`
+	AllHotMonthlyCost    float64          `json:"all_hot_monthly_cost"`
+	EstimatedSavings     float64          `json:"estimated_savings"` // All-hot cost minus the tiered cost
+}
+
+// Duplicate media group matches
+const (
+	MediaMatchExact   = "exact"   // Byte-identical files
+	MediaMatchSimilar = "similar" // Images that look alike
+)
+
+// MediaDuplicateGroup is a set of a user's images that are copies of each
+// other or look alike
+type MediaDuplicateGroup struct {
+	Match string          `json:"match"` // exact or similar
+	Media []MediaResponse `json:"media"`
+}
+
 // MediaVariant represents different sizes/formats of media
 type MediaVariant struct {
 	Name      string    `json:"name" bson:"name"` // thumbnail, small, medium, large
@@ -101,9 +163,11 @@ type MediaResponse struct {
 	Category         string                 `json:"category,omitempty"`
 	Width            int                    `json:"width,omitempty"`
 	Height           int                    `json:"height,omitempty"`
+	OriginalWidth    int                    `json:"original_width,omitempty"`
+	OriginalHeight   int                    `json:"original_height,omitempty"`
 	Duration         int                    `json:"duration,omitempty"`
 	URL              string                 `json:"url"`
-	AltText          string                 `json:"alt_text,omitempty"`
+	AltText          string                 `json:"alt_text"`
 	Description      string                 `json:"description,omitempty"`
 	Caption          string                 `json:"caption,omitempty"`
 	UploadedBy       string                 `json:"uploaded_by"`
@@ -115,6 +179,8 @@ type MediaResponse struct {
 	IsProcessed      bool                   `json:"is_processed"`
 	ProcessingStatus string                 `json:"processing_status"`
 	StorageProvider  string                 `json:"storage_provider"`
+	StorageTier      string                 `json:"storage_tier"`
+	DuplicateOf      string                 `json:"duplicate_of,omitempty"`
 	Thumbnails       []MediaVariant         `json:"thumbnails,omitempty"`
 	Variants         []MediaVariant         `json:"variants,omitempty"`
 	ExpiresAt        *time.Time             `json:"expires_at,omitempty"`
@@ -189,6 +255,18 @@ func (m *Media) BeforeCreate() {
 	if m.StorageProvider == "" {
 		m.StorageProvider = "local"
 	}
+
+	// New uploads start hot and count as just accessed
+	m.StorageTier = MediaTierHot
+	m.LastAccessedAt = &m.CreatedAt
+}
+
+// EffectiveStorageTier returns the tier holding the original
+func (m *Media) EffectiveStorageTier() string {
+	if m.StorageTier == "" {
+		return MediaTierHot
+	}
+	return m.StorageTier
 }
 
 // ToMediaResponse converts Media model to MediaResponse
@@ -204,6 +282,8 @@ func (m *Media) ToMediaResponse() MediaResponse {
 		Category:         m.Category,
 		Width:            m.Width,
 		Height:           m.Height,
+		OriginalWidth:    m.OriginalWidth,
+		OriginalHeight:   m.OriginalHeight,
 		Duration:         m.Duration,
 		URL:              m.URL,
 		AltText:          m.AltText,
@@ -217,6 +297,7 @@ func (m *Media) ToMediaResponse() MediaResponse {
 		IsProcessed:      m.IsProcessed,
 		ProcessingStatus: m.ProcessingStatus,
 		StorageProvider:  m.StorageProvider,
+		StorageTier:      m.EffectiveStorageTier(),
 		Thumbnails:       m.Thumbnails,
 		Variants:         m.Variants,
 		ExpiresAt:        m.ExpiresAt,
@@ -230,6 +311,9 @@ func (m *Media) ToMediaResponse() MediaResponse {
 	if m.RelatedID != nil {
 		response.RelatedID = m.RelatedID.Hex()
 	}
+	if m.DuplicateOf != nil {
+		response.DuplicateOf = m.DuplicateOf.Hex()
+	}
 
 	return response
 }
@@ -325,6 +409,8 @@ func (m *Media) GetFileExtensionFromMime() string {
 		return "gif"
 	case "image/webp":
 		return "webp"
+	case "image/avif":
+		return "avif"
 	case "video/mp4":
 		return "mp4"
 	case "video/webm":
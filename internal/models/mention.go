@@ -337,26 +337,6 @@ func (m *Mention) GetReadStatus() map[string]interface{} {
 
 // Utility functions for mentions
 
-// ExtractMentionsFromText extracts user mentions from text content
-func ExtractMentionsFromText(text string) []string {
-	var mentions []string
-	words := strings.Fields(text)
-
-	for _, word := range words {
-		if strings.HasPrefix(word, "@") && len(word) > 1 {
-			// Clean the mention
-			mention := strings.TrimPrefix(word, "@")
-			// Remove trailing punctuation
-			mention = strings.TrimRight(mention, ".,!?;:")
-			if len(mention) > 0 {
-				mentions = append(mentions, mention)
-			}
-		}
-	}
-
-	return mentions
-}
-
 // ExtractMentionsWithPositions extracts mentions with their positions in text
 func ExtractMentionsWithPositions(text string) []MentionPosition {
 	var mentions []MentionPosition
@@ -0,0 +1,22 @@
+// models/mention_suggestion.go
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Reasons a user is suggested while composing a comment
+const (
+	MentionReasonAuthor   = "author"     // Wrote the post
+	MentionReasonInThread = "in thread"  // Commented on the post
+	MentionReasonFollow   = "you follow" // Followed by the commenter
+)
+
+// MentionSuggestion is a user offered for an @-mention in a comment. Reason
+// is empty for users suggested only because their username matched.
+type MentionSuggestion struct {
+	UserID      primitive.ObjectID `json:"user_id"`
+	Username    string             `json:"username"`
+	DisplayName string             `json:"display_name"`
+	ProfilePic  string             `json:"profile_pic"`
+	IsVerified  bool               `json:"is_verified"`
+	Reason      string             `json:"reason,omitempty"`
+}
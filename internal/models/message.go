@@ -2,6 +2,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -16,10 +17,17 @@ type Message struct {
 	SenderID       primitive.ObjectID `json:"sender_id" bson:"sender_id" validate:"required"`
 	Sender         UserResponse       `json:"sender,omitempty" bson:"-"` // Populated when querying
 
+	// The person behind it when a delegate sent it for the account; admin only
+	SentByDelegate *primitive.ObjectID `json:"-" bson:"sent_by_delegate,omitempty"`
+
 	// Content
 	Content     string      `json:"content" bson:"content" validate:"max=5000"`
 	ContentType ContentType `json:"content_type" bson:"content_type"`
 	Media       []MediaInfo `json:"media,omitempty" bson:"media,omitempty"`
+	MediaHidden bool        `json:"media_hidden,omitempty" bson:"-"` // Media withheld until the reader accepts the message request
+
+	// Participants @-mentioned in the content, so bounded like Conversation.Participants
+	Mentions []primitive.ObjectID `json:"mentions,omitempty" bson:"mentions,omitempty" bound:"500"`
 
 	// Message status
 	Status      MessageStatus `json:"status" bson:"status"`
@@ -32,6 +40,9 @@ type Message struct {
 	EditedAt      *time.Time          `json:"edited_at,omitempty" bson:"edited_at,omitempty"`
 	IsForwarded   bool                `json:"is_forwarded" bson:"is_forwarded"`
 	ForwardedFrom *primitive.ObjectID `json:"forwarded_from,omitempty" bson:"forwarded_from,omitempty"`
+	// Sender of the original message, kept so attribution survives its deletion
+	ForwardedFromSenderID *primitive.ObjectID `json:"forwarded_from_sender_id,omitempty" bson:"forwarded_from_sender_id,omitempty"`
+	ForwardedFromSender   *UserResponse       `json:"forwarded_from_sender,omitempty" bson:"-"` // Populated when querying
 
 	// Reply to another message
 	ReplyToMessageID *primitive.ObjectID `json:"reply_to_message_id,omitempty" bson:"reply_to_message_id,omitempty"`
@@ -63,6 +74,20 @@ type Message struct {
 	ThreadCount  int64               `json:"thread_count" bson:"thread_count"`
 }
 
+// MediaRejectedError names a message attachment refused by the media policy
+type MediaRejectedError struct {
+	Index    int    `json:"index"` // Position of the attachment, -1 when the attachments as a whole are over a limit
+	MimeType string `json:"mime_type,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+func (e *MediaRejectedError) Error() string {
+	if e.Index < 0 {
+		return "media rejected: " + e.Reason
+	}
+	return fmt.Sprintf("media rejected: attachment %d %s", e.Index, e.Reason)
+}
+
 // MessageReadReceipt tracks when a user read a message
 type MessageReadReceipt struct {
 	UserID   primitive.ObjectID `json:"user_id" bson:"user_id"`
@@ -79,6 +104,8 @@ type MessageResponse struct {
 	Content          string                 `json:"content"`
 	ContentType      ContentType            `json:"content_type"`
 	Media            []MediaInfo            `json:"media,omitempty"`
+	MediaHidden      bool                   `json:"media_hidden,omitempty"`
+	Mentions         []string               `json:"mentions,omitempty"`
 	Status           MessageStatus          `json:"status"`
 	SentAt           *time.Time             `json:"sent_at,omitempty"`
 	DeliveredAt      *time.Time             `json:"delivered_at,omitempty"`
@@ -86,6 +113,8 @@ type MessageResponse struct {
 	IsEdited         bool                   `json:"is_edited"`
 	EditedAt         *time.Time             `json:"edited_at,omitempty"`
 	IsForwarded      bool                   `json:"is_forwarded"`
+	ForwardedFrom    string                 `json:"forwarded_from,omitempty"`
+	ForwardedSender  *UserResponse          `json:"forwarded_from_sender,omitempty"`
 	ReplyToMessageID string                 `json:"reply_to_message_id,omitempty"`
 	ReplyToMessage   *MessageResponse       `json:"reply_to_message,omitempty"`
 	ReactionsCount   map[ReactionType]int64 `json:"reactions_count,omitempty"`
@@ -118,6 +147,13 @@ type CreateMessageRequest struct {
 	ReplyToMessageID string      `json:"reply_to_message_id,omitempty"`
 	Priority         string      `json:"priority,omitempty" validate:"omitempty,oneof=normal high urgent"`
 	ExpiresAt        *time.Time  `json:"expires_at,omitempty"`
+
+	DelegateID *primitive.ObjectID `json:"-"` // Set when a delegate sends for the account
+}
+
+// ForwardMessageRequest represents the request to forward a message to other conversations
+type ForwardMessageRequest struct {
+	TargetConversationIDs []string `json:"target_conversation_ids" validate:"required,min=1,max=10"`
 }
 
 // UpdateMessageRequest represents the request to update a message
@@ -155,6 +191,22 @@ type MessageStats struct {
 	MostActiveDay     string           `json:"most_active_day,omitempty"`
 }
 
+// MessageWindow is a run of consecutive messages in a conversation, oldest first
+type MessageWindow struct {
+	Messages []Message
+	HasOlder bool // More messages exist before the first one
+	HasNewer bool // More messages exist after the last one
+}
+
+// MessageWindowResponse represents a message window with cursors for loading
+// further in either direction. Cursors are message IDs.
+type MessageWindowResponse struct {
+	Messages    []MessageResponse `json:"messages"`
+	TargetID    string            `json:"target_id,omitempty"`
+	OlderCursor string            `json:"older_cursor,omitempty"` // Pass as older_than to load the messages before the window
+	NewerCursor string            `json:"newer_cursor,omitempty"` // Pass as newer_than to load the messages after the window
+}
+
 // BeforeCreate sets default values before creating message
 func (m *Message) BeforeCreate() {
 	m.BaseModel.BeforeCreate()
@@ -194,6 +246,7 @@ func (m *Message) ToMessageResponse() MessageResponse {
 		Content:        m.Content,
 		ContentType:    m.ContentType,
 		Media:          m.Media,
+		MediaHidden:    m.MediaHidden,
 		Status:         m.Status,
 		SentAt:         m.SentAt,
 		DeliveredAt:    m.DeliveredAt,
@@ -217,6 +270,15 @@ func (m *Message) ToMessageResponse() MessageResponse {
 		response.ReplyToMessageID = m.ReplyToMessageID.Hex()
 	}
 
+	for _, mentionID := range m.Mentions {
+		response.Mentions = append(response.Mentions, mentionID.Hex())
+	}
+
+	if m.ForwardedFrom != nil {
+		response.ForwardedFrom = m.ForwardedFrom.Hex()
+		response.ForwardedSender = m.ForwardedFromSender
+	}
+
 	if m.ThreadID != nil {
 		response.ThreadID = m.ThreadID.Hex()
 	}
@@ -224,6 +286,31 @@ func (m *Message) ToMessageResponse() MessageResponse {
 	return response
 }
 
+// ToMessageWindowResponse converts a message window to its response, with
+// cursors only in the directions that have more messages
+func (w *MessageWindow) ToMessageWindowResponse(targetID *primitive.ObjectID) MessageWindowResponse {
+	response := MessageWindowResponse{
+		Messages: make([]MessageResponse, 0, len(w.Messages)),
+	}
+	for i := range w.Messages {
+		response.Messages = append(response.Messages, w.Messages[i].ToMessageResponse())
+	}
+
+	if targetID != nil {
+		response.TargetID = targetID.Hex()
+	}
+	if len(w.Messages) > 0 {
+		if w.HasOlder {
+			response.OlderCursor = w.Messages[0].ID.Hex()
+		}
+		if w.HasNewer {
+			response.NewerCursor = w.Messages[len(w.Messages)-1].ID.Hex()
+		}
+	}
+
+	return response
+}
+
 // MarkAsDelivered marks the message as delivered
 func (m *Message) MarkAsDelivered() {
 	if m.Status == MessageSent {
@@ -366,13 +453,27 @@ func (m *Message) GetTotalReactions() int64 {
 	return total
 }
 
-// MarkAsForwarded marks the message as forwarded
-func (m *Message) MarkAsForwarded(originalMessageID primitive.ObjectID) {
+// MarkAsForwarded marks the message as a forward of original. Forwarding a
+// forward keeps pointing at the first message and its sender.
+func (m *Message) MarkAsForwarded(original *Message) {
 	m.IsForwarded = true
-	m.ForwardedFrom = &originalMessageID
+	if original.IsForwarded && original.ForwardedFrom != nil {
+		m.ForwardedFrom = original.ForwardedFrom
+		m.ForwardedFromSenderID = original.ForwardedFromSenderID
+	} else {
+		originalID := original.ID
+		senderID := original.SenderID
+		m.ForwardedFrom = &originalID
+		m.ForwardedFromSenderID = &senderID
+	}
 	m.BeforeUpdate()
 }
 
+// IsSystemMessage checks if the message was generated by the system
+func (m *Message) IsSystemMessage() bool {
+	return m.Source == "system"
+}
+
 // CreateThread creates a new thread from this message
 func (m *Message) CreateThread() {
 	if !m.IsThreadRoot {
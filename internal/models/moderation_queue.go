@@ -0,0 +1,69 @@
+// models/moderation_queue.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ModerationQueueItemType discriminates the sources merged into the moderation queue
+type ModerationQueueItemType string
+
+const (
+	QueueItemReport            ModerationQueueItemType = "report"             // Open user report
+	QueueItemFlaggedMedia      ModerationQueueItemType = "flagged_media"      // Media with moderation_status flagged
+	QueueItemHiddenPost        ModerationQueueItemType = "hidden_post"        // Post hidden without a moderator
+	QueueItemHiddenComment     ModerationQueueItemType = "hidden_comment"     // Comment hidden without a moderator
+	QueueItemRestrictedComment ModerationQueueItemType = "restricted_comment" // Comment waiting in the spam hold
+)
+
+// IsValid checks if the queue item type is one the queue knows about
+func (t ModerationQueueItemType) IsValid() bool {
+	switch t {
+	case QueueItemReport, QueueItemFlaggedMedia, QueueItemHiddenPost, QueueItemHiddenComment, QueueItemRestrictedComment:
+		return true
+	}
+	return false
+}
+
+// ModerationClaimTTL is how long a claim holds an item before another
+// moderator can take it over
+const ModerationClaimTTL = 30 * time.Minute
+
+// ModerationPriorities lists queue priorities from lowest to highest
+var ModerationPriorities = []string{"low", "medium", "high", "urgent"}
+
+// ModerationQueueItem is one entry of the unified moderation queue. ItemID is
+// the report, media, post or comment the item was built from; TargetID is
+// the content or user the moderator acts on, which differs from ItemID only
+// for reports.
+type ModerationQueueItem struct {
+	QueueItemType  ModerationQueueItemType `json:"queue_item_type" bson:"queue_item_type"`
+	ItemID         primitive.ObjectID      `json:"item_id" bson:"item_id"`
+	TargetType     string                  `json:"target_type" bson:"target_type"`
+	TargetID       primitive.ObjectID      `json:"target_id" bson:"target_id"`
+	Priority       string                  `json:"priority" bson:"priority"`
+	Reason         string                  `json:"reason,omitempty" bson:"reason,omitempty"`
+	Preview        string                  `json:"preview,omitempty" bson:"preview,omitempty"`
+	QueuedAt       time.Time               `json:"queued_at" bson:"queued_at"`
+	ClaimedBy      *primitive.ObjectID     `json:"claimed_by,omitempty" bson:"claimed_by,omitempty"`
+	ClaimExpiresAt *time.Time              `json:"claim_expires_at,omitempty" bson:"claim_expires_at,omitempty"`
+}
+
+// ModerationClaim marks a queue item as being worked on by one moderator.
+// Claims expire after ModerationClaimTTL so abandoned items return to the pool.
+type ModerationClaim struct {
+	ID            primitive.ObjectID      `json:"id" bson:"_id,omitempty"`
+	QueueItemType ModerationQueueItemType `json:"queue_item_type" bson:"queue_item_type"`
+	ItemID        primitive.ObjectID      `json:"item_id" bson:"item_id"`
+	ModeratorID   primitive.ObjectID      `json:"moderator_id" bson:"moderator_id"`
+	ClaimedAt     time.Time               `json:"claimed_at" bson:"claimed_at"`
+	ExpiresAt     time.Time               `json:"expires_at" bson:"expires_at"`
+}
+
+// ModerationQueueFilter narrows the moderation queue
+type ModerationQueueFilter struct {
+	QueueItemType ModerationQueueItemType
+	Unclaimed     bool // Leave out items another moderator holds a live claim on
+}
@@ -0,0 +1,80 @@
+// models/mute.go
+package models
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MutedUser represents one user muting another, stored in the user_mutes
+// collection. Muting hides the muted user's posts, stories and notifications
+// from the muter without the muted user being able to tell.
+type MutedUser struct {
+	BaseModel `bson:",inline"`
+
+	MuterID   primitive.ObjectID `json:"muter_id" bson:"muter_id"`
+	MutedID   primitive.ObjectID `json:"muted_id" bson:"muted_id"`
+	ExpiresAt *time.Time         `json:"expires_at,omitempty" bson:"expires_at,omitempty"` // Nil for mutes that never expire
+}
+
+// MuteUserRequest represents the request to mute a user
+type MuteUserRequest struct {
+	Duration string `json:"duration" validate:"omitempty,oneof=8h 24h 7d 30d forever"`
+}
+
+// MutedUserResponse represents a muted user in the muted users list
+type MutedUserResponse struct {
+	User             UserResponse `json:"user"`
+	MutedAt          time.Time    `json:"muted_at"`
+	ExpiresAt        *time.Time   `json:"expires_at,omitempty"`
+	RemainingSeconds *int64       `json:"remaining_seconds,omitempty"` // Nil for mutes that never expire
+}
+
+// muteDurations maps the accepted mute durations to their length; zero means forever
+var muteDurations = map[string]time.Duration{
+	"8h":      8 * time.Hour,
+	"24h":     24 * time.Hour,
+	"7d":      7 * 24 * time.Hour,
+	"30d":     30 * 24 * time.Hour,
+	"forever": 0,
+}
+
+// ParseMuteDuration returns the length of a mute duration, zero for forever.
+// An empty duration mutes forever.
+func ParseMuteDuration(duration string) (time.Duration, error) {
+	if duration == "" {
+		return 0, nil
+	}
+
+	length, ok := muteDurations[duration]
+	if !ok {
+		return 0, errors.New("invalid mute duration")
+	}
+	return length, nil
+}
+
+// IsActive checks if the mute is still in effect
+func (m *MutedUser) IsActive() bool {
+	return m.ExpiresAt == nil || time.Now().Before(*m.ExpiresAt)
+}
+
+// ToMutedUserResponse converts a mute and the muted user to the list response
+func (m *MutedUser) ToMutedUserResponse(user UserResponse) MutedUserResponse {
+	response := MutedUserResponse{
+		User:      user,
+		MutedAt:   m.UpdatedAt,
+		ExpiresAt: m.ExpiresAt,
+	}
+
+	if m.ExpiresAt != nil {
+		remaining := int64(time.Until(*m.ExpiresAt).Seconds())
+		if remaining < 0 {
+			remaining = 0
+		}
+		response.RemainingSeconds = &remaining
+	}
+
+	return response
+}
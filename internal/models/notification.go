@@ -30,6 +30,10 @@ type Notification struct {
 	TargetType string              `json:"target_type,omitempty" bson:"target_type,omitempty"` // post, comment, user, group, event
 	TargetURL  string              `json:"target_url,omitempty" bson:"target_url,omitempty"`
 
+	// Group the activity happened in; delivery follows the recipient's level for the group
+	GroupID        *primitive.ObjectID `json:"group_id,omitempty" bson:"group_id,omitempty"`
+	PushSuppressed bool                `json:"-" bson:"push_suppressed,omitempty"` // Set when the recipient mutes the group before push or email went out
+
 	// Additional context data
 	Metadata map[string]interface{} `json:"metadata,omitempty" bson:"metadata,omitempty"`
 
@@ -99,6 +103,8 @@ type CreateNotificationRequest struct {
 	TargetID     string                 `json:"target_id,omitempty"`
 	TargetType   string                 `json:"target_type,omitempty"`
 	TargetURL    string                 `json:"target_url,omitempty"`
+	GroupID      string                 `json:"group_id,omitempty"`
+	GroupKey     string                 `json:"group_key,omitempty"` // Bundles similar notifications; derived from type, actor and target when empty
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
 	Priority     string                 `json:"priority,omitempty" validate:"omitempty,oneof=high medium low"`
 	ScheduledAt  *time.Time             `json:"scheduled_at,omitempty"`
@@ -119,6 +125,7 @@ type BulkCreateNotificationRequest struct {
 	TargetID     string                 `json:"target_id,omitempty"`
 	TargetType   string                 `json:"target_type,omitempty"`
 	TargetURL    string                 `json:"target_url,omitempty"`
+	GroupID      string                 `json:"group_id,omitempty"`
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
 	Priority     string                 `json:"priority,omitempty" validate:"omitempty,oneof=high medium low"`
 	ScheduledAt  *time.Time             `json:"scheduled_at,omitempty"`
@@ -252,6 +259,8 @@ func (n *Notification) getDisplayProperties() (string, string) {
 		return "❤️", "#E41E3F"
 	case NotificationComment:
 		return "💬", "#42B883"
+	case NotificationQuickReply:
+		return "🔥", "#F43F5E"
 	case NotificationFollow:
 		return "👤", "#8B5CF6"
 	case NotificationMessage:
@@ -272,6 +281,18 @@ func (n *Notification) getDisplayProperties() (string, string) {
 		return "📝", "#F97316"
 	case NotificationEventReminder:
 		return "⏰", "#D97706"
+	case NotificationDelegate:
+		return "🔑", "#0EA5E9"
+	case NotificationPhotoTag:
+		return "🏷️", "#EC4899"
+	case NotificationStoryScreenshot:
+		return "📸", "#EC4899"
+	case NotificationMediaRejected:
+		return "🛡️", "#DC2626"
+	case NotificationSavedSearch:
+		return "🔍", "#3B82F6"
+	case NotificationSupportAccess:
+		return "🛟", "#6366F1"
 	default:
 		return "🔔", "#6B7280"
 	}
@@ -319,7 +340,7 @@ func (n *Notification) IsExpired() bool {
 
 // ShouldSendViaEmail checks if notification should be sent via email
 func (n *Notification) ShouldSendViaEmail(userPrefs NotificationPreferences) bool {
-	if !userPrefs.EmailEnabled {
+	if !userPrefs.EmailEnabled || n.PushSuppressed {
 		return false
 	}
 
@@ -328,13 +349,22 @@ func (n *Notification) ShouldSendViaEmail(userPrefs NotificationPreferences) boo
 
 // ShouldSendViaPush checks if notification should be sent via push
 func (n *Notification) ShouldSendViaPush(userPrefs NotificationPreferences) bool {
-	if !userPrefs.PushEnabled {
+	if !userPrefs.PushEnabled || n.PushSuppressed {
 		return false
 	}
 
 	return n.isTypeEnabled(userPrefs) && !n.isInQuietHours(userPrefs)
 }
 
+// ShouldIncludeInDigest checks if notification belongs in the user's digest email
+func (n *Notification) ShouldIncludeInDigest(userPrefs NotificationPreferences) bool {
+	if !userPrefs.EmailEnabled || n.PushSuppressed {
+		return false
+	}
+
+	return n.isTypeEnabled(userPrefs)
+}
+
 // ShouldSendViaSMS checks if notification should be sent via SMS
 func (n *Notification) ShouldSendViaSMS(userPrefs NotificationPreferences) bool {
 	if !userPrefs.SMSEnabled {
@@ -350,13 +380,13 @@ func (n *Notification) isTypeEnabled(prefs NotificationPreferences) bool {
 	switch n.Type {
 	case NotificationLike:
 		return prefs.LikeNotifications
-	case NotificationComment:
+	case NotificationComment, NotificationQuickReply:
 		return prefs.CommentNotifications
 	case NotificationFollow:
 		return prefs.FollowNotifications
 	case NotificationMessage:
 		return prefs.MessageNotifications
-	case NotificationMention:
+	case NotificationMention, NotificationPhotoTag:
 		return prefs.MentionNotifications
 	case NotificationGroupInvite, NotificationGroupPost:
 		return prefs.GroupNotifications
@@ -430,6 +460,8 @@ func getNotificationContent(notifType NotificationType, metadata map[string]inte
 		return "New Like", "Someone liked your post", "View Post"
 	case NotificationComment:
 		return "New Comment", "Someone commented on your post", "View Comment"
+	case NotificationQuickReply:
+		return "New Reactions", "People reacted to your post", "View Post"
 	case NotificationFollow:
 		return "New Follower", "Someone started following you", "View Profile"
 	case NotificationMessage:
@@ -450,6 +482,18 @@ func getNotificationContent(notifType NotificationType, metadata map[string]inte
 		return "New Group Post", "New post in your group", "View Post"
 	case NotificationEventReminder:
 		return "Event Reminder", "You have an upcoming event", "View Event"
+	case NotificationDelegate:
+		return "Account Access Invitation", "You were invited to help manage an account", "View Invitation"
+	case NotificationPhotoTag:
+		return "You were tagged", "Someone tagged you in a photo", "View Post"
+	case NotificationStoryScreenshot:
+		return "Story Screenshot", "Someone took a screenshot of your story", "View Story"
+	case NotificationMediaRejected:
+		return "Upload Blocked", "A file you uploaded was blocked", "View Media"
+	case NotificationSavedSearch:
+		return "New Search Results", "New posts match your saved search", "View Results"
+	case NotificationSupportAccess:
+		return "Support Access", "Our support team signed in to your account", "Review Activity"
 	default:
 		return "Notification", "You have a new notification", "View"
 	}
@@ -460,7 +504,7 @@ func getTargetInfo(notifType NotificationType, targetID primitive.ObjectID) (str
 	targetIDStr := targetID.Hex()
 
 	switch notifType {
-	case NotificationLike, NotificationComment, NotificationPostShare, NotificationMention:
+	case NotificationLike, NotificationComment, NotificationQuickReply, NotificationPostShare, NotificationMention, NotificationPhotoTag:
 		return "post", "/posts/" + targetIDStr
 	case NotificationFollow, NotificationFriendRequest:
 		return "user", "/users/" + targetIDStr
@@ -470,8 +514,14 @@ func getTargetInfo(notifType NotificationType, targetID primitive.ObjectID) (str
 		return "group", "/groups/" + targetIDStr
 	case NotificationEventInvite, NotificationEventReminder:
 		return "event", "/events/" + targetIDStr
-	case NotificationStoryView:
+	case NotificationStoryView, NotificationStoryScreenshot:
 		return "story", "/stories/" + targetIDStr
+	case NotificationDelegate:
+		return "delegation", "/settings/delegations/" + targetIDStr
+	case NotificationSavedSearch:
+		return "saved_search", "/search/saved/" + targetIDStr
+	case NotificationSupportAccess:
+		return "impersonation_session", "/settings/security"
 	default:
 		return "unknown", "/"
 	}
@@ -0,0 +1,51 @@
+// models/photo_tag.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxPhotoTagsPerPost caps how many tags a post's media can carry
+const MaxPhotoTagsPerPost = 20
+
+// PhotoTagStatus is whether a tag shows on the tagged user's "tagged" tab
+type PhotoTagStatus string
+
+const (
+	PhotoTagPending  PhotoTagStatus = "pending"  // Waiting for the tagged user, who reviews tags
+	PhotoTagAccepted PhotoTagStatus = "accepted" // Shown on the tagged tab
+	PhotoTagHidden   PhotoTagStatus = "hidden"   // Left on the post but kept off the tagged tab
+)
+
+// PhotoTag places a user on one of a post's media. X and Y are fractions of
+// the media's width and height, measured from the top left corner.
+type PhotoTag struct {
+	UserID     primitive.ObjectID `json:"user_id" bson:"user_id"`
+	MediaIndex int                `json:"media_index" bson:"media_index"`
+	X          float64            `json:"x" bson:"x"`
+	Y          float64            `json:"y" bson:"y"`
+	Status     PhotoTagStatus     `json:"status" bson:"status"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// PhotoTagInput is one tag in a TagUsersRequest
+type PhotoTagInput struct {
+	UserID     string  `json:"user_id" validate:"required"`
+	MediaIndex int     `json:"media_index" validate:"min=0"`
+	X          float64 `json:"x" validate:"min=0,max=1"`
+	Y          float64 `json:"y" validate:"min=0,max=1"`
+}
+
+// TagUsersRequest tags users on a post's media. Tagging a user again on the
+// same media moves their tag.
+type TagUsersRequest struct {
+	Tags []PhotoTagInput `json:"tags" validate:"required,min=1,max=20,dive"`
+}
+
+// RespondToPhotoTagRequest is the tagged user's choice about showing a post
+// on their tagged tab
+type RespondToPhotoTagRequest struct {
+	Status PhotoTagStatus `json:"status" validate:"required,oneof=accepted hidden"`
+}
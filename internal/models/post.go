@@ -7,6 +7,16 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// CommentPolicy controls who may comment on a post
+type CommentPolicy string
+
+const (
+	CommentPolicyEveryone      CommentPolicy = "everyone"
+	CommentPolicyFollowers     CommentPolicy = "followers"      // Followers of the author
+	CommentPolicyMentionedOnly CommentPolicy = "mentioned_only" // Users mentioned in the post
+	CommentPolicyOff           CommentPolicy = "off"
+)
+
 // Post represents a post in the social media platform
 type Post struct {
 	BaseModel `bson:",inline"`
@@ -15,6 +25,11 @@ type Post struct {
 	UserID primitive.ObjectID `json:"user_id" bson:"user_id" validate:"required"`
 	Author UserResponse       `json:"author,omitempty" bson:"-"` // Populated when querying
 
+	// Delegate attribution: the person behind a post made or last edited on
+	// the author's behalf. Only shown to admins.
+	PostedByDelegate *primitive.ObjectID `json:"-" bson:"posted_by_delegate,omitempty"`
+	EditedBy         *primitive.ObjectID `json:"-" bson:"edited_by,omitempty"`
+
 	// Content
 	Content     string      `json:"content" bson:"content" validate:"max=5000"`
 	ContentType ContentType `json:"content_type" bson:"content_type"`
@@ -27,25 +42,35 @@ type Post struct {
 	Location   *Location    `json:"location,omitempty" bson:"location,omitempty"`
 
 	// Engagement Statistics
-	LikesCount    int64 `json:"likes_count" bson:"likes_count"`
-	CommentsCount int64 `json:"comments_count" bson:"comments_count"`
-	SharesCount   int64 `json:"shares_count" bson:"shares_count"`
-	ViewsCount    int64 `json:"views_count" bson:"views_count"`
-	SavesCount    int64 `json:"saves_count" bson:"saves_count"`
+	LikesCount int64 `json:"likes_count" bson:"likes_count"`
+	// Per-reaction counters keyed by reaction type
+	ReactionCounts map[ReactionType]int64 `json:"reaction_counts,omitempty" bson:"reaction_counts,omitempty"`
+	CommentsCount  int64                  `json:"comments_count" bson:"comments_count"` // Standard comments only
+	SharesCount    int64                  `json:"shares_count" bson:"shares_count"`
+	ViewsCount     int64                  `json:"views_count" bson:"views_count"`
+	SavesCount     int64                  `json:"saves_count" bson:"saves_count"`
+
+	// Quick replies (emoji-only comments kept out of the thread)
+	QuickRepliesCount   int64                 `json:"quick_replies_count" bson:"quick_replies_count"`
+	QuickReactionCounts map[string]int64      `json:"quick_reaction_counts,omitempty" bson:"quick_reaction_counts,omitempty"`
+	QuickReplySenders   []QuickReactionSender `json:"-" bson:"-"` // Populated for post detail
 
 	// Social Features
 	Hashtags     []string             `json:"hashtags,omitempty" bson:"hashtags,omitempty"`
-	Mentions     []primitive.ObjectID `json:"mentions,omitempty" bson:"mentions,omitempty"`
+	Mentions     []primitive.ObjectID `json:"mentions,omitempty" bson:"mentions,omitempty" bound:"50"`
 	MentionUsers []UserResponse       `json:"mention_users,omitempty" bson:"-"` // Populated when querying
+	PhotoTags    []PhotoTag           `json:"photo_tags,omitempty" bson:"photo_tags,omitempty"`
 
 	// Post Options
 	IsEdited        bool       `json:"is_edited" bson:"is_edited"`
 	EditedAt        *time.Time `json:"edited_at,omitempty" bson:"edited_at,omitempty"`
 	CommentsEnabled bool       `json:"comments_enabled" bson:"comments_enabled"`
-	LikesEnabled    bool       `json:"likes_enabled" bson:"likes_enabled"`
-	SharesEnabled   bool       `json:"shares_enabled" bson:"shares_enabled"`
-	IsPinned        bool       `json:"is_pinned" bson:"is_pinned"`
-	IsPromoted      bool       `json:"is_promoted" bson:"is_promoted"`
+	// CommentPolicy narrows who may comment; comments_enabled mirrors it being anything but off
+	CommentPolicy CommentPolicy `json:"comment_policy,omitempty" bson:"comment_policy,omitempty"`
+	LikesEnabled  bool          `json:"likes_enabled" bson:"likes_enabled"`
+	SharesEnabled bool          `json:"shares_enabled" bson:"shares_enabled"`
+	IsPinned      bool          `json:"is_pinned" bson:"is_pinned"`
+	IsPromoted    bool          `json:"is_promoted" bson:"is_promoted"`
 
 	// Content Moderation
 	IsReported     bool   `json:"is_reported" bson:"is_reported"`
@@ -53,6 +78,16 @@ type Post struct {
 	IsHidden       bool   `json:"is_hidden" bson:"is_hidden"`
 	IsApproved     bool   `json:"is_approved" bson:"is_approved"`
 	ModerationNote string `json:"moderation_note,omitempty" bson:"moderation_note,omitempty"`
+	// HiddenBy is the moderator who hid the post; hidden posts without one
+	// were hidden automatically and wait in the moderation queue
+	HiddenBy *primitive.ObjectID `json:"-" bson:"hidden_by,omitempty"`
+
+	// Sensitive content is shown behind its warning until the viewer taps to
+	// reveal it, and never to under-age accounts. A flag set by a moderator
+	// can't be cleared by the author.
+	IsSensitive          bool   `json:"is_sensitive" bson:"is_sensitive,omitempty"`
+	ContentWarning       string `json:"content_warning,omitempty" bson:"content_warning,omitempty"`
+	SensitiveByModerator bool   `json:"-" bson:"sensitive_by_moderator,omitempty"`
 
 	// Sharing and Reposting
 	OriginalPostID *primitive.ObjectID `json:"original_post_id,omitempty" bson:"original_post_id,omitempty"`
@@ -64,6 +99,11 @@ type Post struct {
 	GroupID *primitive.ObjectID `json:"group_id,omitempty" bson:"group_id,omitempty"`
 	EventID *primitive.ObjectID `json:"event_id,omitempty" bson:"event_id,omitempty"`
 
+	// Group Post Review (set when the group requires post approval)
+	GroupReviewStatus GroupPostReviewStatus `json:"group_review_status,omitempty" bson:"group_review_status,omitempty"`
+	GroupReviewedBy   *primitive.ObjectID   `json:"group_reviewed_by,omitempty" bson:"group_reviewed_by,omitempty"`
+	GroupReviewedAt   *time.Time            `json:"group_reviewed_at,omitempty" bson:"group_reviewed_at,omitempty"`
+
 	// Scheduled Posts
 	IsScheduled  bool       `json:"is_scheduled" bson:"is_scheduled"`
 	ScheduledFor *time.Time `json:"scheduled_for,omitempty" bson:"scheduled_for,omitempty"`
@@ -96,45 +136,63 @@ type PollOption struct {
 	Percentage float64            `json:"percentage" bson:"percentage"`
 }
 
+// GroupPostReviewStatus tracks a group post through moderator approval
+type GroupPostReviewStatus string
+
+const (
+	GroupPostPending  GroupPostReviewStatus = "pending"  // Hidden from the group until reviewed
+	GroupPostApproved GroupPostReviewStatus = "approved" // Published to the group
+	GroupPostRejected GroupPostReviewStatus = "rejected" // Only visible to the author
+)
+
 // PostResponse represents the post data returned in API responses
 type PostResponse struct {
-	ID              string         `json:"id"`
-	UserID          string         `json:"user_id"`
-	Author          UserResponse   `json:"author"`
-	Content         string         `json:"content"`
-	ContentType     ContentType    `json:"content_type"`
-	Media           []MediaInfo    `json:"media,omitempty"`
-	Type            string         `json:"type"`
-	Visibility      PrivacyLevel   `json:"visibility"`
-	Language        string         `json:"language,omitempty"`
-	Location        *Location      `json:"location,omitempty"`
-	LikesCount      int64          `json:"likes_count"`
-	CommentsCount   int64          `json:"comments_count"`
-	SharesCount     int64          `json:"shares_count"`
-	ViewsCount      int64          `json:"views_count"`
-	SavesCount      int64          `json:"saves_count"`
-	Hashtags        []string       `json:"hashtags,omitempty"`
-	Mentions        []string       `json:"mentions,omitempty"` // User IDs as strings
-	MentionUsers    []UserResponse `json:"mention_users,omitempty"`
-	IsEdited        bool           `json:"is_edited"`
-	EditedAt        *time.Time     `json:"edited_at,omitempty"`
-	CommentsEnabled bool           `json:"comments_enabled"`
-	LikesEnabled    bool           `json:"likes_enabled"`
-	SharesEnabled   bool           `json:"shares_enabled"`
-	IsPinned        bool           `json:"is_pinned"`
-	IsRepost        bool           `json:"is_repost"`
-	RepostComment   string         `json:"repost_comment,omitempty"`
-	OriginalPost    *PostResponse  `json:"original_post,omitempty"`
-	GroupID         string         `json:"group_id,omitempty"`
-	EventID         string         `json:"event_id,omitempty"`
-	IsScheduled     bool           `json:"is_scheduled"`
-	ScheduledFor    *time.Time     `json:"scheduled_for,omitempty"`
-	PublishedAt     *time.Time     `json:"published_at,omitempty"`
-	PollOptions     []PollOption   `json:"poll_options,omitempty"`
-	PollExpiresAt   *time.Time     `json:"poll_expires_at,omitempty"`
-	TotalVotes      int64          `json:"total_votes,omitempty"`
-	CreatedAt       time.Time      `json:"created_at"`
-	UpdatedAt       time.Time      `json:"updated_at"`
+	ID               string                 `json:"id"`
+	UserID           string                 `json:"user_id"`
+	Author           UserResponse           `json:"author"`
+	Content          string                 `json:"content"`
+	ContentType      ContentType            `json:"content_type"`
+	Media            []MediaInfo            `json:"media,omitempty"`
+	Type             string                 `json:"type"`
+	Visibility       PrivacyLevel           `json:"visibility"`
+	Language         string                 `json:"language,omitempty"`
+	Location         *Location              `json:"location,omitempty"`
+	LikesCount       int64                  `json:"likes_count"`
+	ReactionCounts   map[ReactionType]int64 `json:"reaction_counts,omitempty"`
+	CommentsCount    int64                  `json:"comments_count"`
+	QuickReactions   *QuickReactionSummary  `json:"quick_reactions,omitempty"`
+	SharesCount      int64                  `json:"shares_count"`
+	ViewsCount       int64                  `json:"views_count"`
+	SavesCount       int64                  `json:"saves_count"`
+	Hashtags         []string               `json:"hashtags,omitempty"`
+	Mentions         []string               `json:"mentions,omitempty"` // User IDs as strings
+	MentionUsers     []UserResponse         `json:"mention_users,omitempty"`
+	PhotoTags        []PhotoTag             `json:"photo_tags,omitempty"`
+	IsEdited         bool                   `json:"is_edited"`
+	EditedAt         *time.Time             `json:"edited_at,omitempty"`
+	EditedBy         string                 `json:"edited_by,omitempty"`          // Admin views only
+	PostedByDelegate string                 `json:"posted_by_delegate,omitempty"` // Admin views only
+	CommentsEnabled  bool                   `json:"comments_enabled"`
+	CommentPolicy    CommentPolicy          `json:"comment_policy"`
+	LikesEnabled     bool                   `json:"likes_enabled"`
+	SharesEnabled    bool                   `json:"shares_enabled"`
+	IsPinned         bool                   `json:"is_pinned"`
+	IsRepost         bool                   `json:"is_repost"`
+	RepostComment    string                 `json:"repost_comment,omitempty"`
+	OriginalPost     *PostResponse          `json:"original_post,omitempty"`
+	GroupID          string                 `json:"group_id,omitempty"`
+	EventID          string                 `json:"event_id,omitempty"`
+	GroupReview      GroupPostReviewStatus  `json:"group_review_status,omitempty"`
+	IsSensitive      bool                   `json:"is_sensitive"`
+	ContentWarning   string                 `json:"content_warning,omitempty"`
+	IsScheduled      bool                   `json:"is_scheduled"`
+	ScheduledFor     *time.Time             `json:"scheduled_for,omitempty"`
+	PublishedAt      *time.Time             `json:"published_at,omitempty"`
+	PollOptions      []PollOption           `json:"poll_options,omitempty"`
+	PollExpiresAt    *time.Time             `json:"poll_expires_at,omitempty"`
+	TotalVotes       int64                  `json:"total_votes,omitempty"`
+	CreatedAt        time.Time              `json:"created_at"`
+	UpdatedAt        time.Time              `json:"updated_at"`
 
 	// User-specific context (set based on current user)
 	IsLiked       bool         `json:"is_liked,omitempty"`
@@ -145,16 +203,17 @@ type PostResponse struct {
 
 // CreatePostRequest represents the request to create a new post
 type CreatePostRequest struct {
-	Content         string                 `json:"content" validate:"max=5000"`
+	Content         string                 `json:"content"` // Length and media count are capped by the author's tier limits
 	ContentType     ContentType            `json:"content_type" validate:"required,oneof=text image video link gif poll"`
 	Media           []MediaInfo            `json:"media,omitempty"`
 	Type            string                 `json:"type" validate:"oneof=post story reel poll"`
-	Visibility      PrivacyLevel           `json:"visibility" validate:"required,oneof=public friends private"`
+	Visibility      PrivacyLevel           `json:"visibility,omitempty" validate:"omitempty,oneof=public friends private"` // Defaults to the author's default_post_visibility
 	Language        string                 `json:"language,omitempty"`
 	Location        *Location              `json:"location,omitempty"`
 	Hashtags        []string               `json:"hashtags,omitempty"`
 	Mentions        []string               `json:"mentions,omitempty"` // User IDs as strings
 	CommentsEnabled bool                   `json:"comments_enabled"`
+	CommentPolicy   CommentPolicy          `json:"comment_policy,omitempty" validate:"omitempty,oneof=everyone followers mentioned_only off"`
 	LikesEnabled    bool                   `json:"likes_enabled"`
 	SharesEnabled   bool                   `json:"shares_enabled"`
 	GroupID         string                 `json:"group_id,omitempty"`
@@ -164,6 +223,10 @@ type CreatePostRequest struct {
 	PollExpiresAt   *time.Time             `json:"poll_expires_at,omitempty"`
 	PollMultiple    bool                   `json:"poll_multiple,omitempty"`
 	CustomFields    map[string]interface{} `json:"custom_fields,omitempty"`
+	IsSensitive     bool                   `json:"is_sensitive"`
+	ContentWarning  string                 `json:"content_warning,omitempty" validate:"max=200"`
+
+	DelegateID *primitive.ObjectID `json:"-"` // Set when a delegate posts for the author
 }
 
 // CreatePollOption represents a poll option in create request
@@ -173,16 +236,40 @@ type CreatePollOption struct {
 
 // UpdatePostRequest represents the request to update a post
 type UpdatePostRequest struct {
-	Content         *string       `json:"content,omitempty" validate:"omitempty,max=5000"`
-	Visibility      *PrivacyLevel `json:"visibility,omitempty" validate:"omitempty,oneof=public friends private"`
-	Language        *string       `json:"language,omitempty"`
-	Location        *Location     `json:"location,omitempty"`
-	Hashtags        []string      `json:"hashtags,omitempty"`
-	Mentions        []string      `json:"mentions,omitempty"`
-	CommentsEnabled *bool         `json:"comments_enabled,omitempty"`
-	LikesEnabled    *bool         `json:"likes_enabled,omitempty"`
-	SharesEnabled   *bool         `json:"shares_enabled,omitempty"`
-	IsPinned        *bool         `json:"is_pinned,omitempty"`
+	Content         *string        `json:"content,omitempty"`
+	Visibility      *PrivacyLevel  `json:"visibility,omitempty" validate:"omitempty,oneof=public friends private"`
+	Language        *string        `json:"language,omitempty"`
+	Location        *Location      `json:"location,omitempty"`
+	Hashtags        []string       `json:"hashtags,omitempty"`
+	Mentions        []string       `json:"mentions,omitempty"`
+	CommentsEnabled *bool          `json:"comments_enabled,omitempty"`
+	CommentPolicy   *CommentPolicy `json:"comment_policy,omitempty" validate:"omitempty,oneof=everyone followers mentioned_only off"`
+	LikesEnabled    *bool          `json:"likes_enabled,omitempty"`
+	SharesEnabled   *bool          `json:"shares_enabled,omitempty"`
+	IsPinned        *bool          `json:"is_pinned,omitempty"`
+	IsSensitive     *bool          `json:"is_sensitive,omitempty"`
+	ContentWarning  *string        `json:"content_warning,omitempty" validate:"omitempty,max=200"`
+
+	DelegateID *primitive.ObjectID `json:"-"` // Set when a delegate edits for the author
+}
+
+// MarkPostSensitiveRequest represents the request to mark a post sensitive or
+// clear the flag, made by its author or a moderator
+type MarkPostSensitiveRequest struct {
+	IsSensitive    bool   `json:"is_sensitive"`
+	ContentWarning string `json:"content_warning,omitempty" validate:"max=200"`
+}
+
+// PostEdit records one edit of a post. Edits live in their own collection so
+// a frequently edited post doesn't grow without bound.
+type PostEdit struct {
+	BaseModel `bson:",inline"`
+
+	PostID          primitive.ObjectID  `json:"post_id" bson:"post_id"`
+	EditedBy        primitive.ObjectID  `json:"edited_by" bson:"edited_by"`                                   // The person who made the edit
+	OnBehalfOf      *primitive.ObjectID `json:"on_behalf_of,omitempty" bson:"on_behalf_of,omitempty"`         // The author, when a delegate edited
+	PreviousContent string              `json:"previous_content,omitempty" bson:"previous_content,omitempty"` // Content before the edit
+	Editor          UserResponse        `json:"editor,omitempty" bson:"-"`                                    // Populated when querying
 }
 
 // RepostRequest represents the request to repost/share a post
@@ -204,6 +291,9 @@ type PostStatsResponse struct {
 	EngagementRate  float64 `json:"engagement_rate"`
 	ReachCount      int64   `json:"reach_count"`
 	ImpressionCount int64   `json:"impression_count"`
+
+	// Reaction composition of the post's quick replies
+	QuickReactions *QuickReactionSummary `json:"quick_reactions,omitempty"`
 }
 
 // PostFeedResponse represents posts in feed with additional context
@@ -233,7 +323,10 @@ func (p *Post) BeforeCreate() {
 	p.ImpressionCount = 0
 
 	// Set default permissions
-	p.CommentsEnabled = true
+	if p.CommentPolicy == "" {
+		p.CommentPolicy = CommentPolicyEveryone
+	}
+	p.CommentsEnabled = p.CommentPolicy != CommentPolicyOff
 	p.LikesEnabled = true
 	p.SharesEnabled = true
 
@@ -275,6 +368,19 @@ func (p *Post) BeforeCreate() {
 	}
 }
 
+// ToAdminPostResponse converts Post model to PostResponse including the
+// delegate attribution that is hidden from other users
+func (p *Post) ToAdminPostResponse() PostResponse {
+	response := p.ToPostResponse()
+	if p.PostedByDelegate != nil {
+		response.PostedByDelegate = p.PostedByDelegate.Hex()
+	}
+	if p.EditedBy != nil {
+		response.EditedBy = p.EditedBy.Hex()
+	}
+	return response
+}
+
 // ToPostResponse converts Post model to PostResponse
 func (p *Post) ToPostResponse() PostResponse {
 	response := PostResponse{
@@ -288,25 +394,32 @@ func (p *Post) ToPostResponse() PostResponse {
 		Language:        p.Language,
 		Location:        p.Location,
 		LikesCount:      p.LikesCount,
+		ReactionCounts:  p.ReactionCounts,
 		CommentsCount:   p.CommentsCount,
 		SharesCount:     p.SharesCount,
 		ViewsCount:      p.ViewsCount,
 		SavesCount:      p.SavesCount,
 		Hashtags:        p.Hashtags,
+		PhotoTags:       p.PhotoTags,
 		IsEdited:        p.IsEdited,
 		EditedAt:        p.EditedAt,
 		CommentsEnabled: p.CommentsEnabled,
+		CommentPolicy:   p.EffectiveCommentPolicy(),
 		LikesEnabled:    p.LikesEnabled,
 		SharesEnabled:   p.SharesEnabled,
 		IsPinned:        p.IsPinned,
 		IsRepost:        p.IsRepost,
 		RepostComment:   p.RepostComment,
+		GroupReview:     p.GroupReviewStatus,
+		IsSensitive:     p.IsSensitive,
+		ContentWarning:  p.ContentWarning,
 		IsScheduled:     p.IsScheduled,
 		ScheduledFor:    p.ScheduledFor,
 		PublishedAt:     p.PublishedAt,
 		PollOptions:     p.PollOptions,
 		PollExpiresAt:   p.PollExpiresAt,
 		TotalVotes:      p.TotalVotes,
+		QuickReactions:  p.QuickReactionSummary(),
 		CreatedAt:       p.CreatedAt,
 		UpdatedAt:       p.UpdatedAt,
 	}
@@ -330,6 +443,28 @@ func (p *Post) ToPostResponse() PostResponse {
 	return response
 }
 
+// EffectiveCommentPolicy returns the comment policy in force. Posts created
+// before comment policies existed only carry comments_enabled.
+func (p *Post) EffectiveCommentPolicy() CommentPolicy {
+	if !p.CommentsEnabled {
+		return CommentPolicyOff
+	}
+	if p.CommentPolicy == "" {
+		return CommentPolicyEveryone
+	}
+	return p.CommentPolicy
+}
+
+// IsMentioned checks if the user is mentioned in the post
+func (p *Post) IsMentioned(userID primitive.ObjectID) bool {
+	for _, mention := range p.Mentions {
+		if mention == userID {
+			return true
+		}
+	}
+	return false
+}
+
 // ToPostStatsResponse converts Post model to PostStatsResponse
 func (p *Post) ToPostStatsResponse() PostStatsResponse {
 	return PostStatsResponse{
@@ -342,6 +477,28 @@ func (p *Post) ToPostStatsResponse() PostStatsResponse {
 		EngagementRate:  p.EngagementRate,
 		ReachCount:      p.ReachCount,
 		ImpressionCount: p.ImpressionCount,
+		QuickReactions:  p.QuickReactionSummary(),
+	}
+}
+
+// QuickReactionSummary returns the post's quick reply summary, or nil when it
+// has none. Top senders are only present once populated for post detail.
+func (p *Post) QuickReactionSummary() *QuickReactionSummary {
+	if p.QuickRepliesCount <= 0 {
+		return nil
+	}
+
+	counts := make(map[string]int64, len(p.QuickReactionCounts))
+	for emoji, count := range p.QuickReactionCounts {
+		if count > 0 {
+			counts[emoji] = count
+		}
+	}
+
+	return &QuickReactionSummary{
+		Total:      p.QuickRepliesCount,
+		Counts:     counts,
+		TopSenders: p.QuickReplySenders,
 	}
 }
 
@@ -0,0 +1,56 @@
+// models/post_preview.go
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// PostPreviewRequest is a draft to preview. Nothing is saved.
+type PostPreviewRequest struct {
+	Content string `json:"content" validate:"required,max=50000"`
+}
+
+// PostPreview is the render data of a draft post, parsed by the same rules
+// used when it is published. Mention and hashtag offsets are character
+// (rune) offsets into Content, End exclusive.
+type PostPreview struct {
+	Content   string           `json:"content"`
+	Length    int              `json:"length"`     // Characters, as counted against the post length limit
+	MaxLength int              `json:"max_length"` // The author's post length limit, 0 meaning unlimited
+	Mentions  []PreviewMention `json:"mentions"`
+	Hashtags  []PreviewHashtag `json:"hashtags"`
+	Links     []LinkPreview    `json:"links"`
+}
+
+// PreviewMention is an @-mention in a draft. Resolved is false when no
+// account the author can mention has the username, and the mention will
+// publish as plain text.
+type PreviewMention struct {
+	Username    string              `json:"username"`
+	Start       int                 `json:"start"`
+	End         int                 `json:"end"`
+	Resolved    bool                `json:"resolved"`
+	UserID      *primitive.ObjectID `json:"user_id,omitempty"`
+	DisplayName string              `json:"display_name,omitempty"`
+	ProfilePic  string              `json:"profile_pic,omitempty"`
+	IsVerified  bool                `json:"is_verified,omitempty"`
+}
+
+// PreviewHashtag is a hashtag in a draft
+type PreviewHashtag struct {
+	Tag   string `json:"tag"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// LinkPreview describes the page a link points to, read from its Open Graph
+// or Twitter card tags, falling back to the page title and description.
+// Fetched is false when the page wasn't fetched or had nothing to show; the
+// link is then rendered bare.
+type LinkPreview struct {
+	URL         string `json:"url"` // The link after normalization
+	Fetched     bool   `json:"fetched"`
+	Blocked     bool   `json:"blocked,omitempty"` // Links to a blocklisted domain, which publishing would refuse or hide
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+	SiteName    string `json:"site_name,omitempty"`
+}
@@ -0,0 +1,164 @@
+// models/reaction_type.go
+package models
+
+import (
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReactionKeyPattern restricts reaction keys so they are safe to use as
+// field names in per-type counters (reaction_counts.<key>)
+var ReactionKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,31}$`)
+
+// ReactionDefinition is a reaction available in the picker, stored in the
+// reaction_types collection. Retired reactions stay in the collection so
+// existing likes keep rendering.
+type ReactionDefinition struct {
+	BaseModel `bson:",inline"`
+
+	Key          ReactionType        `json:"key" bson:"key"`
+	Name         string              `json:"name" bson:"name"`
+	Emoji        string              `json:"emoji,omitempty" bson:"emoji,omitempty"`
+	AssetURL     string              `json:"asset_url,omitempty" bson:"asset_url,omitempty"`
+	DisplayOrder int                 `json:"display_order" bson:"display_order"`
+	IsBuiltIn    bool                `json:"is_built_in" bson:"is_built_in"`
+	IsRetired    bool                `json:"is_retired" bson:"is_retired"`
+	RetiredAt    *time.Time          `json:"retired_at,omitempty" bson:"retired_at,omitempty"`
+	StartsAt     *time.Time          `json:"starts_at,omitempty" bson:"starts_at,omitempty"`
+	EndsAt       *time.Time          `json:"ends_at,omitempty" bson:"ends_at,omitempty"`
+	CreatedBy    *primitive.ObjectID `json:"created_by,omitempty" bson:"created_by,omitempty"`
+}
+
+// CreateReactionTypeRequest represents the request to add a reaction
+type CreateReactionTypeRequest struct {
+	Key          string     `json:"key" validate:"required,min=2,max=32"`
+	Name         string     `json:"name" validate:"required,max=50"`
+	Emoji        string     `json:"emoji" validate:"omitempty,max=16"`
+	AssetURL     string     `json:"asset_url" validate:"omitempty,url"`
+	DisplayOrder int        `json:"display_order" validate:"min=0"`
+	StartsAt     *time.Time `json:"starts_at,omitempty"`
+	EndsAt       *time.Time `json:"ends_at,omitempty"`
+}
+
+// UpdateReactionTypeRequest represents the request to update a reaction
+type UpdateReactionTypeRequest struct {
+	Name         *string    `json:"name,omitempty" validate:"omitempty,max=50"`
+	Emoji        *string    `json:"emoji,omitempty" validate:"omitempty,max=16"`
+	AssetURL     *string    `json:"asset_url,omitempty" validate:"omitempty,url"`
+	DisplayOrder *int       `json:"display_order,omitempty" validate:"omitempty,min=0"`
+	StartsAt     *time.Time `json:"starts_at,omitempty"`
+	EndsAt       *time.Time `json:"ends_at,omitempty"`
+}
+
+// IsAvailable checks if the reaction can be used for new likes at the given time
+func (d *ReactionDefinition) IsAvailable(now time.Time) bool {
+	if d.IsRetired || d.DeletedAt != nil {
+		return false
+	}
+	if d.StartsAt != nil && now.Before(*d.StartsAt) {
+		return false
+	}
+	if d.EndsAt != nil && !now.Before(*d.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// DefaultReactionDefinitions returns the built-in reactions seeded into reaction_types
+func DefaultReactionDefinitions() []ReactionDefinition {
+	builtIns := []ReactionType{
+		ReactionLike,
+		ReactionLove,
+		ReactionHaha,
+		ReactionWow,
+		ReactionSad,
+		ReactionAngry,
+		ReactionSupport,
+	}
+
+	definitions := make([]ReactionDefinition, len(builtIns))
+	for i, reactionType := range builtIns {
+		definitions[i] = ReactionDefinition{
+			Key:          reactionType,
+			Name:         builtInReactionName(reactionType),
+			Emoji:        builtInReactionEmoji(reactionType),
+			DisplayOrder: i,
+			IsBuiltIn:    true,
+		}
+	}
+	return definitions
+}
+
+// reactionCatalog holds the reaction definitions loaded from the database.
+// It is empty until the first load, in which case the built-in set is used.
+var reactionCatalog struct {
+	sync.RWMutex
+	definitions map[ReactionType]ReactionDefinition
+	ordered     []ReactionDefinition
+}
+
+// SetReactionCatalog replaces the in-memory reaction definitions
+func SetReactionCatalog(definitions []ReactionDefinition) {
+	byKey := make(map[ReactionType]ReactionDefinition, len(definitions))
+	ordered := make([]ReactionDefinition, 0, len(definitions))
+	for _, definition := range definitions {
+		byKey[definition.Key] = definition
+		ordered = append(ordered, definition)
+	}
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].DisplayOrder < ordered[j].DisplayOrder
+	})
+
+	reactionCatalog.Lock()
+	reactionCatalog.definitions = byKey
+	reactionCatalog.ordered = ordered
+	reactionCatalog.Unlock()
+}
+
+// GetReactionDefinition returns the definition for a reaction, including retired ones
+func GetReactionDefinition(reactionType ReactionType) (ReactionDefinition, bool) {
+	reactionCatalog.RLock()
+	defer reactionCatalog.RUnlock()
+
+	if reactionCatalog.definitions == nil {
+		for _, definition := range DefaultReactionDefinitions() {
+			if definition.Key == reactionType {
+				return definition, true
+			}
+		}
+		return ReactionDefinition{}, false
+	}
+
+	definition, exists := reactionCatalog.definitions[reactionType]
+	return definition, exists
+}
+
+// GetAvailableReactions returns the reactions currently offered in the picker, in display order
+func GetAvailableReactions(now time.Time) []ReactionDefinition {
+	reactionCatalog.RLock()
+	ordered := reactionCatalog.ordered
+	reactionCatalog.RUnlock()
+
+	if ordered == nil {
+		return DefaultReactionDefinitions()
+	}
+
+	available := make([]ReactionDefinition, 0, len(ordered))
+	for i := range ordered {
+		if ordered[i].IsAvailable(now) {
+			available = append(available, ordered[i])
+		}
+	}
+	return available
+}
+
+// IsReactionAvailable checks if a reaction can be used for new likes
+func IsReactionAvailable(reactionType ReactionType, now time.Time) bool {
+	definition, exists := GetReactionDefinition(reactionType)
+	return exists && definition.IsAvailable(now)
+}
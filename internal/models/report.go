@@ -35,11 +35,12 @@ type Report struct {
 	AssignedModerator UserResponse        `json:"assigned_moderator,omitempty" bson:"-"` // Populated when querying
 
 	// Resolution
-	Resolution         string              `json:"resolution,omitempty" bson:"resolution,omitempty"`
-	ResolutionNote     string              `json:"resolution_note,omitempty" bson:"resolution_note,omitempty" validate:"max=2000"`
-	ResolvedAt         *time.Time          `json:"resolved_at,omitempty" bson:"resolved_at,omitempty"`
-	ResolvedBy         *primitive.ObjectID `json:"resolved_by,omitempty" bson:"resolved_by,omitempty"`
-	ResolvingModerator UserResponse        `json:"resolving_moderator,omitempty" bson:"-"` // Populated when querying
+	Resolution         string                 `json:"resolution,omitempty" bson:"resolution,omitempty"`
+	ResolutionAction   ReportResolutionAction `json:"resolution_action,omitempty" bson:"resolution_action,omitempty"`
+	ResolutionNote     string                 `json:"resolution_note,omitempty" bson:"resolution_note,omitempty" validate:"max=2000"`
+	ResolvedAt         *time.Time             `json:"resolved_at,omitempty" bson:"resolved_at,omitempty"`
+	ResolvedBy         *primitive.ObjectID    `json:"resolved_by,omitempty" bson:"resolved_by,omitempty"`
+	ResolvingModerator UserResponse           `json:"resolving_moderator,omitempty" bson:"-"` // Populated when querying
 
 	// Actions Taken
 	ActionsTaken   []ReportAction `json:"actions_taken,omitempty" bson:"actions_taken,omitempty"`
@@ -54,7 +55,7 @@ type Report struct {
 	FollowUpNote     string     `json:"follow_up_note,omitempty" bson:"follow_up_note,omitempty"`
 
 	// Internal tracking
-	SimilarReports []primitive.ObjectID `json:"similar_reports,omitempty" bson:"similar_reports,omitempty"`
+	SimilarReports []primitive.ObjectID `json:"similar_reports,omitempty" bson:"similar_reports,omitempty" bound:"100"`
 	ReportedBefore bool                 `json:"reported_before" bson:"reported_before"`
 	AutoDetected   bool                 `json:"auto_detected" bson:"auto_detected"`
 
@@ -70,6 +71,39 @@ type Report struct {
 	Metadata  map[string]interface{} `json:"metadata,omitempty" bson:"metadata,omitempty"`
 }
 
+// ReportResolutionAction is what resolving a report does to its target
+type ReportResolutionAction string
+
+const (
+	ResolutionActionHide        ReportResolutionAction = "hide"
+	ResolutionActionDelete      ReportResolutionAction = "delete"
+	ResolutionActionWarnUser    ReportResolutionAction = "warn_user"
+	ResolutionActionSuspendUser ReportResolutionAction = "suspend_user"
+	ResolutionActionNone        ReportResolutionAction = "no_action"
+)
+
+// AppliesTo checks if the action can be taken against a target type. Content
+// actions need hideable or deletable content; user actions need a target with
+// an owner to warn or suspend.
+func (a ReportResolutionAction) AppliesTo(targetType string) bool {
+	switch a {
+	case ResolutionActionNone:
+		return true
+	case ResolutionActionHide:
+		return targetType == "post" || targetType == "comment" || targetType == "story"
+	case ResolutionActionDelete:
+		return targetType == "post" || targetType == "comment" || targetType == "story" || targetType == "message"
+	case ResolutionActionWarnUser, ResolutionActionSuspendUser:
+		return targetType == "user" || targetType == "post" || targetType == "comment" || targetType == "story" || targetType == "message"
+	}
+	return false
+}
+
+// RemovesContent checks if the action takes the target content down
+func (a ReportResolutionAction) RemovesContent() bool {
+	return a == ResolutionActionHide || a == ResolutionActionDelete
+}
+
 // ReportAction represents an action taken in response to a report
 type ReportAction struct {
 	ID          primitive.ObjectID     `json:"id" bson:"_id,omitempty"`
@@ -82,37 +116,38 @@ type ReportAction struct {
 
 // ReportResponse represents report data returned in API responses
 type ReportResponse struct {
-	ID                 string         `json:"id"`
-	ReporterID         string         `json:"reporter_id"`
-	Reporter           UserResponse   `json:"reporter,omitempty"`
-	TargetType         string         `json:"target_type"`
-	TargetID           string         `json:"target_id"`
-	Reason             ReportReason   `json:"reason"`
-	Description        string         `json:"description,omitempty"`
-	Category           string         `json:"category,omitempty"`
-	Screenshots        []MediaInfo    `json:"screenshots,omitempty"`
-	Status             ReportStatus   `json:"status"`
-	Priority           string         `json:"priority"`
-	AssignedTo         string         `json:"assigned_to,omitempty"`
-	AssignedModerator  UserResponse   `json:"assigned_moderator,omitempty"`
-	Resolution         string         `json:"resolution,omitempty"`
-	ResolutionNote     string         `json:"resolution_note,omitempty"`
-	ResolvedAt         *time.Time     `json:"resolved_at,omitempty"`
-	ResolvedBy         string         `json:"resolved_by,omitempty"`
-	ResolvingModerator UserResponse   `json:"resolving_moderator,omitempty"`
-	ActionsTaken       []ReportAction `json:"actions_taken,omitempty"`
-	Warning            bool           `json:"warning"`
-	ContentRemoved     bool           `json:"content_removed"`
-	UserSuspended      bool           `json:"user_suspended"`
-	AccountBanned      bool           `json:"account_banned"`
-	RequiresFollowUp   bool           `json:"requires_follow_up"`
-	FollowUpDate       *time.Time     `json:"follow_up_date,omitempty"`
-	ReportedBefore     bool           `json:"reported_before"`
-	AutoDetected       bool           `json:"auto_detected"`
-	ReporterNotified   bool           `json:"reporter_notified"`
-	CreatedAt          time.Time      `json:"created_at"`
-	UpdatedAt          time.Time      `json:"updated_at"`
-	TimeAgo            string         `json:"time_ago,omitempty"`
+	ID                 string                 `json:"id"`
+	ReporterID         string                 `json:"reporter_id"`
+	Reporter           UserResponse           `json:"reporter,omitempty"`
+	TargetType         string                 `json:"target_type"`
+	TargetID           string                 `json:"target_id"`
+	Reason             ReportReason           `json:"reason"`
+	Description        string                 `json:"description,omitempty"`
+	Category           string                 `json:"category,omitempty"`
+	Screenshots        []MediaInfo            `json:"screenshots,omitempty"`
+	Status             ReportStatus           `json:"status"`
+	Priority           string                 `json:"priority"`
+	AssignedTo         string                 `json:"assigned_to,omitempty"`
+	AssignedModerator  UserResponse           `json:"assigned_moderator,omitempty"`
+	Resolution         string                 `json:"resolution,omitempty"`
+	ResolutionAction   ReportResolutionAction `json:"resolution_action,omitempty"`
+	ResolutionNote     string                 `json:"resolution_note,omitempty"`
+	ResolvedAt         *time.Time             `json:"resolved_at,omitempty"`
+	ResolvedBy         string                 `json:"resolved_by,omitempty"`
+	ResolvingModerator UserResponse           `json:"resolving_moderator,omitempty"`
+	ActionsTaken       []ReportAction         `json:"actions_taken,omitempty"`
+	Warning            bool                   `json:"warning"`
+	ContentRemoved     bool                   `json:"content_removed"`
+	UserSuspended      bool                   `json:"user_suspended"`
+	AccountBanned      bool                   `json:"account_banned"`
+	RequiresFollowUp   bool                   `json:"requires_follow_up"`
+	FollowUpDate       *time.Time             `json:"follow_up_date,omitempty"`
+	ReportedBefore     bool                   `json:"reported_before"`
+	AutoDetected       bool                   `json:"auto_detected"`
+	ReporterNotified   bool                   `json:"reporter_notified"`
+	CreatedAt          time.Time              `json:"created_at"`
+	UpdatedAt          time.Time              `json:"updated_at"`
+	TimeAgo            string                 `json:"time_ago,omitempty"`
 }
 
 // CreateReportRequest represents the request to create a report
@@ -148,6 +183,8 @@ type ReportStatsResponse struct {
 	HighPriorityReports   int64   `json:"high_priority_reports"`
 	AutoDetectedReports   int64   `json:"auto_detected_reports"`
 	AverageResolutionTime float64 `json:"average_resolution_time"` // in hours
+
+	CommentHolds CommentHoldStats `json:"comment_holds"`
 }
 
 // ReportSummaryResponse represents a summary of reports by type/reason
@@ -200,8 +237,9 @@ type ReportAssignRequest struct {
 
 // ReportResolveRequest represents request to resolve a report
 type ReportResolveRequest struct {
-	Resolution string `json:"resolution" validate:"required,max=500"`
-	Note       string `json:"note,omitempty" validate:"max=2000"`
+	Resolution       string                 `json:"resolution" validate:"required,max=500"`
+	ResolutionAction ReportResolutionAction `json:"resolution_action,omitempty" validate:"omitempty,oneof=hide delete warn_user suspend_user no_action"` // Defaults to no_action
+	Note             string                 `json:"note,omitempty" validate:"max=2000"`
 }
 
 // ReportRejectRequest represents request to reject a report
@@ -245,6 +283,7 @@ func (r *Report) ToReportResponse() ReportResponse {
 		Status:           r.Status,
 		Priority:         r.Priority,
 		Resolution:       r.Resolution,
+		ResolutionAction: r.ResolutionAction,
 		ResolutionNote:   r.ResolutionNote,
 		ResolvedAt:       r.ResolvedAt,
 		ActionsTaken:     r.ActionsTaken,
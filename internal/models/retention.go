@@ -0,0 +1,95 @@
+// models/retention.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RetentionSettingsKey is the app_settings document holding admin overrides
+// of retention periods and legal holds
+const RetentionSettingsKey = "data_retention"
+
+// Data categories with a retention period
+const (
+	RetentionBehaviorSessions     = "behavior_sessions"
+	RetentionUserJourneys         = "user_journeys"
+	RetentionEngagementEvents     = "engagement_events"
+	RetentionRecommendationEvents = "recommendation_events"
+	RetentionFeedCache            = "feed_cache"
+	RetentionNotifications        = "notifications"
+	RetentionAuditLogs            = "audit_logs"
+	RetentionResolvedReports      = "resolved_reports"
+	RetentionLoginHistory         = "login_history"
+	RetentionIdempotencyKeys      = "idempotency_keys"
+	RetentionStatusSamples        = "status_samples"
+	RetentionFeedAlgorithmLogs    = "feed_algorithm_logs"
+)
+
+// Outcomes of a retention run for one category
+const (
+	RetentionOutcomeCompleted = "completed"
+	RetentionOutcomeLegalHold = "legal_hold" // Nothing deleted; the category is on hold
+	RetentionOutcomeRefused   = "refused"    // The safety valve stopped the run
+	RetentionOutcomeFailed    = "failed"
+)
+
+// RetentionSetting is an admin's override for one category. A nil Days
+// keeps the configured default.
+type RetentionSetting struct {
+	Days       *int               `json:"days,omitempty" bson:"days,omitempty"`
+	LegalHold  bool               `json:"legal_hold" bson:"legal_hold"`
+	HoldReason string             `json:"hold_reason,omitempty" bson:"hold_reason,omitempty"`
+	UpdatedBy  primitive.ObjectID `json:"updated_by" bson:"updated_by"`
+	UpdatedAt  time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// RetentionSettings is stored in app_settings under RetentionSettingsKey,
+// keyed by category
+type RetentionSettings map[string]RetentionSetting
+
+// RetentionRun records the latest retention run of a category
+type RetentionRun struct {
+	Category     string    `json:"category" bson:"_id"`
+	LastRunAt    time.Time `json:"last_run_at" bson:"last_run_at"`
+	LastOutcome  string    `json:"last_outcome" bson:"last_outcome"`
+	LastDeleted  int64     `json:"last_deleted" bson:"last_deleted"`
+	LastError    string    `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	TotalDeleted int64     `json:"total_deleted" bson:"total_deleted"`
+}
+
+// RetentionCategoryStatus describes a category for the admin retention view
+type RetentionCategoryStatus struct {
+	Category    string   `json:"category"`
+	Description string   `json:"description"`
+	Collections []string `json:"collections"`
+	PeriodDays  float64  `json:"period_days"` // Period the next run applies
+	DefaultDays float64  `json:"default_days"`
+	MinDays     int      `json:"min_days"`
+	Overridden  bool     `json:"overridden"`
+	LegalHold   bool     `json:"legal_hold"`
+	HoldReason  string   `json:"hold_reason,omitempty"`
+
+	LastRunAt    *time.Time `json:"last_run_at,omitempty"`
+	LastOutcome  string     `json:"last_outcome,omitempty"`
+	LastDeleted  int64      `json:"last_deleted"`
+	LastError    string     `json:"last_error,omitempty"`
+	TotalDeleted int64      `json:"total_deleted"`
+}
+
+// RetentionOverview is the admin retention view
+type RetentionOverview struct {
+	Categories         []RetentionCategoryStatus `json:"categories"`
+	MaxDeleteFraction  float64                   `json:"max_delete_fraction"`
+	SafetyMinDocuments int64                     `json:"safety_min_documents"`
+}
+
+// UpdateRetentionRequest changes a category's period or legal hold. Setting
+// use_default drops the period override.
+type UpdateRetentionRequest struct {
+	Days       *int   `json:"days,omitempty" validate:"omitempty,min=0,max=3650"`
+	UseDefault bool   `json:"use_default,omitempty"`
+	LegalHold  *bool  `json:"legal_hold,omitempty"`
+	HoldReason string `json:"hold_reason,omitempty" validate:"max=500"`
+}
@@ -0,0 +1,52 @@
+// models/saved_search.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SavedSearchAlertMaxMatches caps the new posts counted for one alert
+const SavedSearchAlertMaxMatches = 100
+
+// SavedSearchFilters narrows the posts a saved search matches
+type SavedSearchFilters struct {
+	ContentType string `json:"content_type,omitempty" bson:"content_type,omitempty" validate:"omitempty,oneof=text image video"`
+	Language    string `json:"language,omitempty" bson:"language,omitempty" validate:"omitempty,max=10"`
+}
+
+// SavedSearch is a post search a user keeps to rerun. With alerts on, the
+// alert job reruns it and notifies the user of posts created since LastRunAt.
+type SavedSearch struct {
+	BaseModel `bson:",inline"`
+
+	UserID        primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Name          string             `json:"name" bson:"name"`
+	Query         string             `json:"query" bson:"query"`
+	Filters       SavedSearchFilters `json:"filters" bson:"filters"`
+	AlertsEnabled bool               `json:"alerts_enabled" bson:"alerts_enabled"`
+
+	LastRunAt       *time.Time `json:"last_run_at,omitempty" bson:"last_run_at,omitempty"`
+	LastMatchCount  int        `json:"last_match_count" bson:"last_match_count"`
+	LastAlertedAt   *time.Time `json:"last_alerted_at,omitempty" bson:"last_alerted_at,omitempty"`
+	TotalAlertCount int64      `json:"total_alert_count" bson:"total_alert_count"`
+}
+
+// CreateSavedSearchRequest saves a search. Alerts are on unless
+// alerts_enabled is false.
+type CreateSavedSearchRequest struct {
+	Name          string             `json:"name" validate:"required,min=1,max=100"`
+	Query         string             `json:"query" validate:"required,min=2,max=200"`
+	Filters       SavedSearchFilters `json:"filters"`
+	AlertsEnabled *bool              `json:"alerts_enabled,omitempty"`
+}
+
+// UpdateSavedSearchRequest changes a saved search. Changing the query or
+// filters restarts alerts from now, so old posts aren't reported as new.
+type UpdateSavedSearchRequest struct {
+	Name          *string             `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Query         *string             `json:"query,omitempty" validate:"omitempty,min=2,max=200"`
+	Filters       *SavedSearchFilters `json:"filters,omitempty"`
+	AlertsEnabled *bool               `json:"alerts_enabled,omitempty"`
+}
@@ -0,0 +1,67 @@
+// models/session_policy.go
+package models
+
+import "time"
+
+// SessionPolicy sets how long sessions of a role last. Access tokens live
+// AccessTokenDuration. With a SlidingWindow, a request made when the token
+// has less than the window left is answered with a fresh token, so active
+// sessions carry on. However active, no session outlives MaxSessionAge from
+// sign-in.
+type SessionPolicy struct {
+	AccessTokenDuration time.Duration
+	SlidingWindow       time.Duration // 0 turns sliding expiry off; needs MaxSessionAge
+	MaxSessionAge       time.Duration // 0 leaves sessions unbounded
+}
+
+// SessionPolicies holds the session policy of each role
+type SessionPolicies struct {
+	Default              SessionPolicy
+	Roles                map[UserRole]SessionPolicy // Roles without an entry use Default
+	RefreshTokenDuration time.Duration
+}
+
+// For returns the session policy of a role
+func (p SessionPolicies) For(role UserRole) SessionPolicy {
+	if policy, ok := p.Roles[role]; ok {
+		return policy
+	}
+	return p.Default
+}
+
+// SessionEnd returns when a session signed in at authTime must end, and
+// false when it has no absolute limit
+func (p SessionPolicy) SessionEnd(authTime time.Time) (time.Time, bool) {
+	if p.MaxSessionAge <= 0 {
+		return time.Time{}, false
+	}
+	return authTime.Add(p.MaxSessionAge), true
+}
+
+// SessionExpired checks if a session signed in at authTime is past its
+// absolute limit
+func (p SessionPolicy) SessionExpired(authTime, now time.Time) bool {
+	end, bounded := p.SessionEnd(authTime)
+	return bounded && !now.Before(end)
+}
+
+// AccessTokenExpiry returns when an access token issued now expires, capped
+// at the end of the session
+func (p SessionPolicy) AccessTokenExpiry(authTime, now time.Time) time.Time {
+	expiry := now.Add(p.AccessTokenDuration)
+	if end, bounded := p.SessionEnd(authTime); bounded && end.Before(expiry) {
+		return end
+	}
+	return expiry
+}
+
+// ShouldSlide checks if an access token expiring at expiresAt is due to be
+// replaced by a fresh one, which it only is when the fresh token would
+// outlast it. Sessions without a MaxSessionAge never slide, so sliding can't
+// keep a session alive forever.
+func (p SessionPolicy) ShouldSlide(authTime, expiresAt, now time.Time) bool {
+	if p.SlidingWindow <= 0 || p.MaxSessionAge <= 0 || expiresAt.Sub(now) > p.SlidingWindow {
+		return false
+	}
+	return p.AccessTokenExpiry(authTime, now).After(expiresAt)
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionPolicyShouldSlide(t *testing.T) {
+	now := time.Now()
+	sliding := SessionPolicy{AccessTokenDuration: time.Hour, SlidingWindow: 15 * time.Minute, MaxSessionAge: 2 * time.Hour}
+
+	tests := []struct {
+		name      string
+		policy    SessionPolicy
+		authTime  time.Time
+		expiresAt time.Time
+		want      bool
+	}{
+		{"inside the window", sliding, now.Add(-50 * time.Minute), now.Add(10 * time.Minute), true},
+		{"outside the window", sliding, now.Add(-5 * time.Minute), now.Add(55 * time.Minute), false},
+		{"fresh token capped at the session end", sliding, now.Add(-110 * time.Minute), now.Add(10 * time.Minute), false},
+		{"sliding disabled", SessionPolicy{AccessTokenDuration: time.Hour, MaxSessionAge: 2 * time.Hour}, now.Add(-50 * time.Minute), now.Add(10 * time.Minute), false},
+		{"no max session age", SessionPolicy{AccessTokenDuration: time.Hour, SlidingWindow: 15 * time.Minute}, now.Add(-50 * time.Minute), now.Add(10 * time.Minute), false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.policy.ShouldSlide(tt.authTime, tt.expiresAt, now); got != tt.want {
+			t.Errorf("%s: ShouldSlide() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSessionPolicyExpiry(t *testing.T) {
+	now := time.Now()
+	policy := SessionPolicy{AccessTokenDuration: time.Hour, MaxSessionAge: 2 * time.Hour}
+
+	if !policy.SessionExpired(now.Add(-2*time.Hour), now) {
+		t.Error("a session at its max age should be expired")
+	}
+	if policy.SessionExpired(now.Add(-time.Hour), now) {
+		t.Error("a session under its max age should not be expired")
+	}
+	if got, want := policy.AccessTokenExpiry(now.Add(-90*time.Minute), now), now.Add(30*time.Minute); !got.Equal(want) {
+		t.Errorf("AccessTokenExpiry() = %v, want it capped at the session end %v", got, want)
+	}
+	if (SessionPolicy{AccessTokenDuration: time.Hour}).SessionExpired(now.Add(-1000*time.Hour), now) {
+		t.Error("a session without a max age should never expire")
+	}
+}
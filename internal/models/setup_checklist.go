@@ -0,0 +1,122 @@
+// models/setup_checklist.go
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// SetupItemKey identifies a step of the guided profile setup checklist
+type SetupItemKey string
+
+const (
+	SetupItemAvatar        SetupItemKey = "avatar"
+	SetupItemBio           SetupItemKey = "bio"
+	SetupItemEmailVerified SetupItemKey = "email_verified"
+	SetupItemPhoneVerified SetupItemKey = "phone_verified"
+	SetupItemFirstPost     SetupItemKey = "first_post"
+	SetupItemFollowing     SetupItemKey = "follow_accounts"
+	SetupItemJoinGroup     SetupItemKey = "join_group"
+	SetupItemNotifications SetupItemKey = "notifications"
+)
+
+// SetupChecklistSettingsKey is the app_settings document holding the checklist configuration
+const SetupChecklistSettingsKey = "setup_checklist"
+
+// SetupChecklistItemConfig configures one checklist step. Steps are shown in
+// Order; those not counting toward the score are still shown but don't affect
+// the completeness percentage or the trial.
+type SetupChecklistItemConfig struct {
+	Key               SetupItemKey `json:"key" bson:"key" validate:"required"`
+	Title             string       `json:"title" bson:"title" validate:"required,max=100"`
+	Description       string       `json:"description,omitempty" bson:"description,omitempty" validate:"max=300"`
+	Reward            string       `json:"reward,omitempty" bson:"reward,omitempty" validate:"max=200"` // Copy shown for completing the step
+	Order             int          `json:"order" bson:"order"`
+	Enabled           bool         `json:"enabled" bson:"enabled"`
+	CountsTowardScore bool         `json:"counts_toward_score" bson:"counts_toward_score"`
+}
+
+// SetupChecklistConfig is the admin-editable checklist configuration, stored
+// in the app_settings collection under SetupChecklistSettingsKey
+type SetupChecklistConfig struct {
+	Items            []SetupChecklistItemConfig `json:"items" bson:"items"`
+	FollowTarget     int64                      `json:"follow_target" bson:"follow_target"`         // Accounts to follow for SetupItemFollowing
+	TrialDays        int                        `json:"trial_days" bson:"trial_days"`               // Premium trial for finishing the checklist; 0 disables it
+	CompletionReward string                     `json:"completion_reward,omitempty" bson:"completion_reward,omitempty"`
+	UpdatedAt        time.Time                  `json:"updated_at" bson:"updated_at"`
+	UpdatedBy        string                     `json:"updated_by,omitempty" bson:"updated_by,omitempty"`
+}
+
+// UpdateSetupChecklistConfigRequest represents the admin request to reconfigure the checklist
+type UpdateSetupChecklistConfigRequest struct {
+	Items            []SetupChecklistItemConfig `json:"items" validate:"required,min=1,dive"`
+	FollowTarget     int64                      `json:"follow_target" validate:"min=1,max=100"`
+	TrialDays        int                        `json:"trial_days" validate:"min=0,max=90"`
+	CompletionReward string                     `json:"completion_reward" validate:"max=200"`
+}
+
+// SetupChecklistItem is one checklist step as shown to the user
+type SetupChecklistItem struct {
+	Key               SetupItemKey `json:"key"`
+	Title             string       `json:"title"`
+	Description       string       `json:"description,omitempty"`
+	Reward            string       `json:"reward,omitempty"`
+	Status            string       `json:"status"` // done, pending
+	CountsTowardScore bool         `json:"counts_toward_score"`
+}
+
+// SetupChecklistResponse represents the user's setup checklist
+type SetupChecklistResponse struct {
+	Items            []SetupChecklistItem `json:"items"`
+	CompletedCount   int                  `json:"completed_count"`
+	ScoredCount      int                  `json:"scored_count"`
+	Completeness     int                  `json:"completeness"` // Percentage of scored steps done
+	IsComplete       bool                 `json:"is_complete"`
+	CompletionReward string               `json:"completion_reward,omitempty"`
+	TrialExpiresAt   *time.Time           `json:"trial_expires_at,omitempty"` // Set once the completion trial was granted
+}
+
+// setupItemKeys lists the checklist steps the server knows how to evaluate
+var setupItemKeys = map[SetupItemKey]bool{
+	SetupItemAvatar:        true,
+	SetupItemBio:           true,
+	SetupItemEmailVerified: true,
+	SetupItemPhoneVerified: true,
+	SetupItemFirstPost:     true,
+	SetupItemFollowing:     true,
+	SetupItemJoinGroup:     true,
+	SetupItemNotifications: true,
+}
+
+// DefaultSetupChecklistConfig returns the checklist used until an admin saves one
+func DefaultSetupChecklistConfig() SetupChecklistConfig {
+	return SetupChecklistConfig{
+		Items: []SetupChecklistItemConfig{
+			{Key: SetupItemAvatar, Title: "Add a profile photo", Order: 1, Enabled: true, CountsTowardScore: true},
+			{Key: SetupItemBio, Title: "Write a short bio", Order: 2, Enabled: true, CountsTowardScore: true},
+			{Key: SetupItemEmailVerified, Title: "Verify your email", Order: 3, Enabled: true, CountsTowardScore: true},
+			// There is no phone verification flow yet; a number on file completes the step
+			{Key: SetupItemPhoneVerified, Title: "Add your phone number", Order: 4, Enabled: true, CountsTowardScore: false},
+			{Key: SetupItemFirstPost, Title: "Share your first post", Order: 5, Enabled: true, CountsTowardScore: true},
+			{Key: SetupItemFollowing, Title: "Follow 5 accounts", Order: 6, Enabled: true, CountsTowardScore: true},
+			{Key: SetupItemJoinGroup, Title: "Join a group", Order: 7, Enabled: true, CountsTowardScore: true},
+			{Key: SetupItemNotifications, Title: "Turn on notifications", Order: 8, Enabled: true, CountsTowardScore: true},
+		},
+		FollowTarget: 5,
+	}
+}
+
+// Validate checks the request only names known steps, each at most once
+func (r *UpdateSetupChecklistConfigRequest) Validate() error {
+	seen := make(map[SetupItemKey]bool, len(r.Items))
+	for _, item := range r.Items {
+		if !setupItemKeys[item.Key] {
+			return fmt.Errorf("invalid checklist item: %s", item.Key)
+		}
+		if seen[item.Key] {
+			return fmt.Errorf("invalid checklist item: %s listed twice", item.Key)
+		}
+		seen[item.Key] = true
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+// models/status.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StatusHistoryDays is how far back the public status page reports uptime.
+// Status samples expire after the same period.
+const StatusHistoryDays = 90
+
+// MaxIncidentUpdates caps an incident's timeline
+const MaxIncidentUpdates = 100
+
+// ComponentStatus is the health of a status page component
+type ComponentStatus string
+
+const (
+	ComponentOperational ComponentStatus = "operational"
+	ComponentDegraded    ComponentStatus = "degraded"
+	ComponentOutage      ComponentStatus = "outage"
+)
+
+// Severity ranks statuses so the worst of several can be reported
+func (s ComponentStatus) Severity() int {
+	switch s {
+	case ComponentDegraded:
+		return 1
+	case ComponentOutage:
+		return 2
+	}
+	return 0
+}
+
+// Status page components. Names are public, so they describe what users
+// experience rather than the hosts behind it.
+const (
+	StatusComponentAPI      = "api"
+	StatusComponentDatabase = "database"
+	StatusComponentJobs     = "background_jobs"
+)
+
+// StatusComponents lists the components in the order the status page shows them
+var StatusComponents = []string{StatusComponentAPI, StatusComponentDatabase, StatusComponentJobs}
+
+// StatusSample is one periodic health observation of a component. Background
+// job samples are heartbeats written after each run.
+type StatusSample struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Component  string             `json:"component" bson:"component"`
+	Source     string             `json:"source,omitempty" bson:"source,omitempty"` // The job behind a heartbeat
+	Status     ComponentStatus    `json:"status" bson:"status"`
+	LatencyMs  int64              `json:"latency_ms" bson:"latency_ms"`
+	Interval   int64              `json:"interval,omitempty" bson:"interval,omitempty"` // Seconds until the next heartbeat is due
+	RecordedAt time.Time          `json:"recorded_at" bson:"recorded_at"`
+}
+
+// IncidentSeverity is how badly an incident affects users
+type IncidentSeverity string
+
+const (
+	IncidentMinor    IncidentSeverity = "minor"
+	IncidentMajor    IncidentSeverity = "major"
+	IncidentCritical IncidentSeverity = "critical"
+)
+
+// ComponentStatus is the status an incident of this severity gives the components it affects
+func (s IncidentSeverity) ComponentStatus() ComponentStatus {
+	if s == IncidentCritical {
+		return ComponentOutage
+	}
+	return ComponentDegraded
+}
+
+// IncidentStatus tracks an incident from discovery to resolution
+type IncidentStatus string
+
+const (
+	IncidentInvestigating IncidentStatus = "investigating"
+	IncidentIdentified    IncidentStatus = "identified"
+	IncidentMonitoring    IncidentStatus = "monitoring"
+	IncidentResolved      IncidentStatus = "resolved"
+)
+
+// IncidentUpdate is one entry in an incident's timeline
+type IncidentUpdate struct {
+	Status    IncidentStatus     `json:"status" bson:"status"`
+	Message   string             `json:"message" bson:"message"`
+	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// Incident is an admin annotation on the status page
+type Incident struct {
+	BaseModel `bson:",inline"`
+
+	Title              string             `json:"title" bson:"title"`
+	Severity           IncidentSeverity   `json:"severity" bson:"severity"`
+	Status             IncidentStatus     `json:"status" bson:"status"`
+	AffectedComponents []string           `json:"affected_components" bson:"affected_components"`
+	Updates            []IncidentUpdate   `json:"updates" bson:"updates"` // Oldest first, at most MaxIncidentUpdates
+	CreatedBy          primitive.ObjectID `json:"created_by" bson:"created_by"`
+	ResolvedAt         *time.Time         `json:"resolved_at,omitempty" bson:"resolved_at,omitempty"`
+}
+
+// IsResolved checks if the incident is over
+func (i *Incident) IsResolved() bool {
+	return i.Status == IncidentResolved
+}
+
+// CreateIncidentRequest represents the request to open an incident
+type CreateIncidentRequest struct {
+	Title              string           `json:"title" validate:"required,min=3,max=200"`
+	Severity           IncidentSeverity `json:"severity" validate:"required,oneof=minor major critical"`
+	Status             IncidentStatus   `json:"status,omitempty" validate:"omitempty,oneof=investigating identified monitoring"`
+	AffectedComponents []string         `json:"affected_components" validate:"required,min=1,dive,oneof=api database background_jobs"`
+	Message            string           `json:"message" validate:"required,max=2000"`
+	Notify             bool             `json:"notify"` // Post the change to the status webhook
+}
+
+// UpdateIncidentRequest represents the request to correct an incident's details
+type UpdateIncidentRequest struct {
+	Title              *string           `json:"title,omitempty" validate:"omitempty,min=3,max=200"`
+	Severity           *IncidentSeverity `json:"severity,omitempty" validate:"omitempty,oneof=minor major critical"`
+	AffectedComponents []string          `json:"affected_components,omitempty" validate:"omitempty,min=1,dive,oneof=api database background_jobs"`
+	Notify             bool              `json:"notify"`
+}
+
+// AddIncidentUpdateRequest represents the request to post to an incident's timeline
+type AddIncidentUpdateRequest struct {
+	Status  IncidentStatus `json:"status" validate:"required,oneof=investigating identified monitoring resolved"`
+	Message string         `json:"message" validate:"required,max=2000"`
+	Notify  bool           `json:"notify"`
+}
+
+// ResolveIncidentRequest represents the request to resolve an incident
+type ResolveIncidentRequest struct {
+	Message string `json:"message" validate:"required,max=2000"`
+	Notify  bool   `json:"notify"`
+}
+
+// Public status page responses
+
+// StatusPageResponse is the public status page
+type StatusPageResponse struct {
+	Status     ComponentStatus           `json:"status"` // The worst current component status
+	Components []ComponentStatusResponse `json:"components"`
+	Incidents  []PublicIncidentResponse  `json:"incidents"`
+	UpdatedAt  time.Time                 `json:"updated_at"`
+	Stale      bool                      `json:"stale,omitempty"` // History couldn't be refreshed; showing the last snapshot
+}
+
+// ComponentStatusResponse is one component's current status and uptime history
+type ComponentStatusResponse struct {
+	Name   string          `json:"name"`
+	Status ComponentStatus `json:"status"`
+	Uptime *float64        `json:"uptime,omitempty"` // Percentage over the history window, nil without samples
+	Daily  []DailyUptime   `json:"daily"`
+}
+
+// DailyUptime is a component's uptime percentage for one UTC day with samples
+type DailyUptime struct {
+	Date   string  `json:"date"` // YYYY-MM-DD
+	Uptime float64 `json:"uptime"`
+}
+
+// PublicIncidentResponse is an incident as shown on the status page
+type PublicIncidentResponse struct {
+	ID                 string                 `json:"id"`
+	Title              string                 `json:"title"`
+	Severity           IncidentSeverity       `json:"severity"`
+	Status             IncidentStatus         `json:"status"`
+	AffectedComponents []string               `json:"affected_components"`
+	Updates            []PublicIncidentUpdate `json:"updates"`
+	StartedAt          time.Time              `json:"started_at"`
+	ResolvedAt         *time.Time             `json:"resolved_at,omitempty"`
+}
+
+// PublicIncidentUpdate is a timeline entry without the admin who posted it
+type PublicIncidentUpdate struct {
+	Status    IncidentStatus `json:"status"`
+	Message   string         `json:"message"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// ToPublicIncidentResponse converts Incident to PublicIncidentResponse, newest update first
+func (i *Incident) ToPublicIncidentResponse() PublicIncidentResponse {
+	updates := make([]PublicIncidentUpdate, len(i.Updates))
+	for j, update := range i.Updates {
+		updates[len(i.Updates)-1-j] = PublicIncidentUpdate{
+			Status:    update.Status,
+			Message:   update.Message,
+			CreatedAt: update.CreatedAt,
+		}
+	}
+
+	return PublicIncidentResponse{
+		ID:                 i.ID.Hex(),
+		Title:              i.Title,
+		Severity:           i.Severity,
+		Status:             i.Status,
+		AffectedComponents: i.AffectedComponents,
+		Updates:            updates,
+		StartedAt:          i.CreatedAt,
+		ResolvedAt:         i.ResolvedAt,
+	}
+}
@@ -2,6 +2,9 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -15,6 +18,9 @@ type Story struct {
 	UserID primitive.ObjectID `json:"user_id" bson:"user_id" validate:"required"`
 	Author UserResponse       `json:"author,omitempty" bson:"-"` // Populated when querying
 
+	// The person behind it when a delegate posted for the author; admin only
+	PostedByDelegate *primitive.ObjectID `json:"-" bson:"posted_by_delegate,omitempty"`
+
 	// Content
 	Content     string      `json:"content,omitempty" bson:"content,omitempty" validate:"max=2000"`
 	ContentType ContentType `json:"content_type" bson:"content_type" validate:"required"`
@@ -27,8 +33,8 @@ type Story struct {
 
 	// Privacy and visibility
 	Visibility     PrivacyLevel         `json:"visibility" bson:"visibility"`
-	AllowedViewers []primitive.ObjectID `json:"allowed_viewers,omitempty" bson:"allowed_viewers,omitempty"` // For custom audience
-	BlockedViewers []primitive.ObjectID `json:"blocked_viewers,omitempty" bson:"blocked_viewers,omitempty"` // Users who can't see this story
+	AllowedViewers []primitive.ObjectID `json:"allowed_viewers,omitempty" bson:"allowed_viewers,omitempty" bound:"1000"` // For custom audience
+	BlockedViewers []primitive.ObjectID `json:"blocked_viewers,omitempty" bson:"blocked_viewers,omitempty" bound:"1000"` // Users who can't see this story
 
 	// Engagement Statistics
 	ViewsCount   int64 `json:"views_count" bson:"views_count"`
@@ -36,6 +42,9 @@ type Story struct {
 	RepliesCount int64 `json:"replies_count" bson:"replies_count"`
 	SharesCount  int64 `json:"shares_count" bson:"shares_count"`
 
+	// Viewers who reported taking a screenshot, shown to the author in the story stats
+	ScreenshotsCount int64 `json:"-" bson:"screenshots_count,omitempty"`
+
 	// Story interactions
 	AllowReplies    bool `json:"allow_replies" bson:"allow_replies"`
 	AllowReactions  bool `json:"allow_reactions" bson:"allow_reactions"`
@@ -72,7 +81,7 @@ type Story struct {
 // StorySticker represents a sticker placed on a story
 type StorySticker struct {
 	ID       primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Type     string             `json:"type" bson:"type"` // emoji, gif, poll, question, location, time
+	Type     string             `json:"type" bson:"type"` // emoji, gif, poll, question, quiz, slider, location, time
 	Content  string             `json:"content" bson:"content"`
 	X        float64            `json:"x" bson:"x"`               // Position X (0-1)
 	Y        float64            `json:"y" bson:"y"`               // Position Y (0-1)
@@ -81,13 +90,27 @@ type StorySticker struct {
 	Rotation float64            `json:"rotation" bson:"rotation"` // Rotation in degrees
 	Scale    float64            `json:"scale" bson:"scale"`       // Scale factor
 
-	// Interactive sticker data
-	PollOptions     []string             `json:"poll_options,omitempty" bson:"poll_options,omitempty"`
-	PollVotes       map[string]int64     `json:"poll_votes,omitempty" bson:"poll_votes,omitempty"`
-	QuestionText    string               `json:"question_text,omitempty" bson:"question_text,omitempty"`
-	QuestionReplies []StoryQuestionReply `json:"question_replies,omitempty" bson:"question_replies,omitempty"`
+	// Interactive sticker data. Responses are kept in story_sticker_responses.
+	PollOptions   []string `json:"poll_options,omitempty" bson:"poll_options,omitempty"`     // Choices for poll and quiz stickers
+	CorrectOption *int     `json:"correct_option,omitempty" bson:"correct_option,omitempty"` // Index of the quiz answer; hidden from viewers
+	QuestionText  string   `json:"question_text,omitempty" bson:"question_text,omitempty"`
+	SliderEmoji   string   `json:"slider_emoji,omitempty" bson:"slider_emoji,omitempty"`
 }
 
+// Interactive sticker types that collect responses
+const (
+	StickerTypePoll     = "poll"
+	StickerTypeQuestion = "question"
+	StickerTypeQuiz     = "quiz"
+	StickerTypeSlider   = "slider"
+)
+
+// Bounds on the choices of poll and quiz stickers
+const (
+	MinStickerOptions = 2
+	MaxStickerOptions = 4
+)
+
 // StoryMention represents a user mention in a story
 type StoryMention struct {
 	UserID   primitive.ObjectID `json:"user_id" bson:"user_id"`
@@ -118,13 +141,22 @@ type StoryMusic struct {
 	ExternalID string `json:"external_id" bson:"external_id"` // Spotify/Apple Music ID
 }
 
-// StoryQuestionReply represents a reply to a question sticker
-type StoryQuestionReply struct {
-	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
-	User      UserResponse       `json:"user,omitempty" bson:"-"`
-	Reply     string             `json:"reply" bson:"reply"`
-	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+// StoryStickerResponse records one viewer's response to an interactive sticker
+type StoryStickerResponse struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	StoryID     primitive.ObjectID `json:"story_id" bson:"story_id"`
+	StickerID   primitive.ObjectID `json:"sticker_id" bson:"sticker_id"`
+	StickerType string             `json:"sticker_type" bson:"sticker_type"`
+	UserID      primitive.ObjectID `json:"user_id" bson:"user_id"`
+	User        *UserResponse      `json:"user,omitempty" bson:"-"` // Populated when the author lists responses
+
+	OptionIndex *int     `json:"option_index,omitempty" bson:"option_index,omitempty"` // Poll and quiz
+	Text        string   `json:"text,omitempty" bson:"text,omitempty"`                 // Question
+	Value       *float64 `json:"value,omitempty" bson:"value,omitempty"`               // Slider, from 0 to 1
+	IsCorrect   *bool    `json:"is_correct,omitempty" bson:"is_correct,omitempty"`     // Quiz
+
+	CorrectOption *int      `json:"correct_option,omitempty" bson:"-"` // Revealed to the viewer once they have answered a quiz
+	CreatedAt     time.Time `json:"created_at" bson:"created_at"`
 }
 
 // StoryView represents a view of a story by a user
@@ -159,12 +191,32 @@ type StoryHighlight struct {
 	UserID       primitive.ObjectID   `json:"user_id" bson:"user_id" validate:"required"`
 	Title        string               `json:"title" bson:"title" validate:"required,max=50"`
 	CoverImage   string               `json:"cover_image" bson:"cover_image"`
-	StoryIDs     []primitive.ObjectID `json:"story_ids" bson:"story_ids"`
+	StoryIDs     []primitive.ObjectID `json:"story_ids" bson:"story_ids" bound:"100"`
 	StoriesCount int64                `json:"stories_count" bson:"stories_count"`
 	IsActive     bool                 `json:"is_active" bson:"is_active"`
 	Order        int                  `json:"order" bson:"order"` // Display order
 }
 
+// StickerInsights aggregates the responses to one interactive sticker for the story's author
+type StickerInsights struct {
+	StickerID     string                `json:"sticker_id"`
+	Type          string                `json:"type"`
+	QuestionText  string                `json:"question_text,omitempty"`
+	Responses     int64                 `json:"responses"`
+	Options       []StickerOptionResult `json:"options,omitempty"`        // Poll and quiz
+	CorrectOption *int                  `json:"correct_option,omitempty"` // Quiz
+	CorrectRate   float64               `json:"correct_rate"`             // Percentage of quiz responses that were correct
+	AverageValue  float64               `json:"average_value"`            // Slider
+}
+
+// StickerOptionResult is the share of responses that picked one poll or quiz option
+type StickerOptionResult struct {
+	Index      int     `json:"index"`
+	Text       string  `json:"text"`
+	Count      int64   `json:"count"`
+	Percentage float64 `json:"percentage"`
+}
+
 // Story Response Models
 
 // StoryResponse represents the story data returned in API responses
@@ -214,6 +266,7 @@ type StoryViewResponse struct {
 	User         UserResponse `json:"user"`
 	ViewDuration float64      `json:"view_duration"`
 	WatchedFully bool         `json:"watched_fully"`
+	Screenshot   bool         `json:"screenshot"`
 	CreatedAt    time.Time    `json:"created_at"`
 	TimeAgo      string       `json:"time_ago,omitempty"`
 }
@@ -239,8 +292,8 @@ type CreateStoryRequest struct {
 	Content         string         `json:"content,omitempty" validate:"max=2000"`
 	ContentType     ContentType    `json:"content_type" validate:"required,oneof=image video"`
 	Media           MediaInfo      `json:"media" validate:"required"`
-	Duration        int            `json:"duration,omitempty" validate:"min=1,max=30"`
-	Visibility      PrivacyLevel   `json:"visibility" validate:"required,oneof=public friends private"`
+	Duration        int            `json:"duration,omitempty" validate:"min=1"`                                    // Capped by the author's tier limits
+	Visibility      PrivacyLevel   `json:"visibility,omitempty" validate:"omitempty,oneof=public friends private"` // Defaults to the author's default_story_visibility
 	AllowedViewers  []string       `json:"allowed_viewers,omitempty"`
 	BlockedViewers  []string       `json:"blocked_viewers,omitempty"`
 	AllowReplies    bool           `json:"allow_replies"`
@@ -255,6 +308,16 @@ type CreateStoryRequest struct {
 	Hashtags        []StoryHashtag `json:"hashtags,omitempty"`
 	Location        *Location      `json:"location,omitempty"`
 	Music           *StoryMusic    `json:"music,omitempty"`
+
+	DelegateID *primitive.ObjectID `json:"-"` // Set when a delegate posts for the author
+}
+
+// RespondToStickerRequest represents a viewer's response to an interactive sticker.
+// Polls and quizzes take option_index, questions take text and sliders take value.
+type RespondToStickerRequest struct {
+	OptionIndex *int     `json:"option_index,omitempty" validate:"omitempty,min=0"`
+	Text        string   `json:"text,omitempty" validate:"max=500"`
+	Value       *float64 `json:"value,omitempty" validate:"omitempty,min=0,max=1"`
 }
 
 // CreateStoryHighlightRequest represents the request to create a story highlight
@@ -294,6 +357,10 @@ func (s *Story) BeforeCreate() {
 	s.IsReported = false
 	s.IsHidden = false
 
+	for i := range s.Stickers {
+		s.Stickers[i].ID = primitive.NewObjectID()
+	}
+
 	// Set default duration
 	if s.Duration == 0 {
 		if s.ContentType == ContentTypeImage {
@@ -341,7 +408,7 @@ func (s *Story) ToStoryResponse() StoryResponse {
 		BackgroundColor: s.BackgroundColor,
 		TextColor:       s.TextColor,
 		FontFamily:      s.FontFamily,
-		Stickers:        s.Stickers,
+		Stickers:        viewerStickers(s.Stickers),
 		Mentions:        s.Mentions,
 		Hashtags:        s.Hashtags,
 		Location:        s.Location,
@@ -507,6 +574,7 @@ func (sv *StoryView) ToStoryViewResponse() StoryViewResponse {
 		UserID:       sv.UserID.Hex(),
 		ViewDuration: sv.ViewDuration,
 		WatchedFully: sv.WatchedFully,
+		Screenshot:   sv.Screenshot,
 		CreatedAt:    sv.CreatedAt,
 	}
 }
@@ -597,3 +665,86 @@ func IsValidStoryContentType(contentType ContentType) bool {
 	}
 	return false
 }
+
+// FindSticker returns the story's sticker with the given ID
+func (s *Story) FindSticker(stickerID primitive.ObjectID) *StorySticker {
+	for i := range s.Stickers {
+		if s.Stickers[i].ID == stickerID {
+			return &s.Stickers[i]
+		}
+	}
+	return nil
+}
+
+// viewerStickers copies stickers with quiz answers removed
+func viewerStickers(stickers []StorySticker) []StorySticker {
+	if len(stickers) == 0 {
+		return nil
+	}
+	visible := make([]StorySticker, len(stickers))
+	copy(visible, stickers)
+	for i := range visible {
+		visible[i].CorrectOption = nil
+	}
+	return visible
+}
+
+// IsInteractive checks if the sticker collects responses
+func (st *StorySticker) IsInteractive() bool {
+	switch st.Type {
+	case StickerTypePoll, StickerTypeQuestion, StickerTypeQuiz, StickerTypeSlider:
+		return true
+	}
+	return false
+}
+
+// Validate checks that an interactive sticker is complete
+func (st *StorySticker) Validate() error {
+	switch st.Type {
+	case StickerTypePoll, StickerTypeQuiz:
+		if len(st.PollOptions) < MinStickerOptions || len(st.PollOptions) > MaxStickerOptions {
+			return fmt.Errorf("%s stickers need between %d and %d options", st.Type, MinStickerOptions, MaxStickerOptions)
+		}
+		for _, option := range st.PollOptions {
+			if strings.TrimSpace(option) == "" {
+				return errors.New("sticker options cannot be empty")
+			}
+		}
+		if st.Type == StickerTypeQuiz {
+			if st.CorrectOption == nil || *st.CorrectOption < 0 || *st.CorrectOption >= len(st.PollOptions) {
+				return errors.New("quiz stickers need a correct_option matching one of the options")
+			}
+		} else if st.CorrectOption != nil {
+			return errors.New("only quiz stickers have a correct_option")
+		}
+	case StickerTypeQuestion:
+		if strings.TrimSpace(st.QuestionText) == "" {
+			return errors.New("question stickers need question_text")
+		}
+	}
+	return nil
+}
+
+// ValidateResponse checks that a response fits the sticker's type
+func (st *StorySticker) ValidateResponse(req RespondToStickerRequest) error {
+	switch st.Type {
+	case StickerTypePoll, StickerTypeQuiz:
+		if req.OptionIndex == nil || req.Text != "" || req.Value != nil {
+			return fmt.Errorf("%s responses take only an option_index", st.Type)
+		}
+		if *req.OptionIndex >= len(st.PollOptions) {
+			return errors.New("option_index does not match an option")
+		}
+	case StickerTypeQuestion:
+		if strings.TrimSpace(req.Text) == "" || req.OptionIndex != nil || req.Value != nil {
+			return errors.New("question responses take only text")
+		}
+	case StickerTypeSlider:
+		if req.Value == nil || req.OptionIndex != nil || req.Text != "" {
+			return errors.New("slider responses take only a value")
+		}
+	default:
+		return errors.New("sticker does not accept responses")
+	}
+	return nil
+}
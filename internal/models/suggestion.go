@@ -0,0 +1,56 @@
+// models/suggestion.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxStoredSuggestions is the number of candidates kept per user by the batch job
+const MaxStoredSuggestions = 50
+
+// OnboardingSuggestionLimit is the number of accounts suggested to a new user
+const OnboardingSuggestionLimit = 20
+
+// Suggestion sources
+const (
+	SuggestionSourceMutual    = "mutual_connections"
+	SuggestionSourcePopular   = "popular"
+	SuggestionSourceCurated   = "curated"   // Picked by admins for onboarding
+	SuggestionSourceInterests = "interests" // Posts about the new user's selected interests
+)
+
+// UserSuggestions holds the precomputed follow suggestions for one user
+type UserSuggestions struct {
+	BaseModel `bson:",inline"`
+
+	UserID      primitive.ObjectID    `json:"user_id" bson:"user_id"`
+	Candidates  []SuggestionCandidate `json:"candidates" bson:"candidates" bound:"50"`
+	GeneratedAt time.Time             `json:"generated_at" bson:"generated_at"`
+}
+
+// SuggestionCandidate is one suggested account with the mutual connections behind it
+type SuggestionCandidate struct {
+	UserID      primitive.ObjectID   `json:"user_id" bson:"user_id"`
+	Score       float64              `json:"score" bson:"score"`
+	MutualCount int64                `json:"mutual_count" bson:"mutual_count"`
+	MutualIDs   []primitive.ObjectID `json:"mutual_ids,omitempty" bson:"mutual_ids,omitempty" bound:"3"` // Strongest mutual connections, for social proof
+}
+
+// SuggestionDismissal records that a user never wants to see an account suggested again
+type SuggestionDismissal struct {
+	BaseModel `bson:",inline"`
+
+	UserID          primitive.ObjectID `json:"user_id" bson:"user_id"`
+	DismissedUserID primitive.ObjectID `json:"dismissed_user_id" bson:"dismissed_user_id"`
+}
+
+// SuggestedUserResponse represents a follow suggestion returned in API responses
+type SuggestedUserResponse struct {
+	User            UserResponse `json:"user"`
+	Score           float64      `json:"score"`
+	Source          string       `json:"source"` // mutual_connections, popular, curated, interests
+	MutualCount     int64        `json:"mutual_count"`
+	MutualUsernames []string     `json:"mutual_usernames,omitempty"`
+}
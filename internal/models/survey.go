@@ -0,0 +1,337 @@
+// models/survey.go
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SurveyQuestionType represents the type of a survey question
+type SurveyQuestionType string
+
+const (
+	SurveyQuestionSingleChoice SurveyQuestionType = "single_choice"
+	SurveyQuestionMultiChoice  SurveyQuestionType = "multi_choice"
+	SurveyQuestionScale        SurveyQuestionType = "scale"
+	SurveyQuestionFreeText     SurveyQuestionType = "free_text"
+)
+
+// SurveyStatus represents the lifecycle state of a survey
+type SurveyStatus string
+
+const (
+	SurveyStatusActive SurveyStatus = "active"
+	SurveyStatusClosed SurveyStatus = "closed"
+)
+
+// Survey close reasons
+const (
+	SurveyCloseWindowEnded = "window_ended"
+	SurveyCloseCapReached  = "cap_reached"
+	SurveyCloseManual      = "manual"
+)
+
+// Survey limits
+const (
+	MaxSurveyQuestions    = 5
+	SurveyScaleMin        = 1
+	SurveyScaleMax        = 10
+	MaxSurveyFreeTextSize = 2000
+)
+
+// Survey represents an in-app survey broadcast by admins
+type Survey struct {
+	BaseModel `bson:",inline"`
+
+	Title       string           `json:"title" bson:"title"`
+	Description string           `json:"description,omitempty" bson:"description,omitempty"`
+	Questions   []SurveyQuestion `json:"questions" bson:"questions"`
+	Audience    SurveyAudience   `json:"audience" bson:"audience"`
+
+	// Active window and response cap
+	StartsAt       time.Time `json:"starts_at" bson:"starts_at"`
+	EndsAt         time.Time `json:"ends_at" bson:"ends_at"`
+	MaxResponses   int64     `json:"max_responses" bson:"max_responses"` // 0 means unlimited
+	ResponsesCount int64     `json:"responses_count" bson:"responses_count"`
+
+	// Status
+	Status      SurveyStatus `json:"status" bson:"status"`
+	ClosedAt    *time.Time   `json:"closed_at,omitempty" bson:"closed_at,omitempty"`
+	CloseReason string       `json:"close_reason,omitempty" bson:"close_reason,omitempty"`
+
+	NotifyUsers bool               `json:"notify_users" bson:"notify_users"`
+	CreatedBy   primitive.ObjectID `json:"created_by" bson:"created_by"`
+}
+
+// SurveyQuestion represents a single question in a survey
+type SurveyQuestion struct {
+	ID       string             `json:"id" bson:"id"`
+	Type     SurveyQuestionType `json:"type" bson:"type"`
+	Text     string             `json:"text" bson:"text"`
+	Options  []string           `json:"options,omitempty" bson:"options,omitempty"` // For choice questions
+	Required bool               `json:"required" bson:"required"`
+}
+
+// SurveyAudience represents the users a survey is targeted at; empty fields match everyone
+type SurveyAudience struct {
+	Roles        []UserRole           `json:"roles,omitempty" bson:"roles,omitempty"`
+	Countries    []string             `json:"countries,omitempty" bson:"countries,omitempty"`
+	IsPremium    *bool                `json:"is_premium,omitempty" bson:"is_premium,omitempty"`
+	SignedUpFrom *time.Time           `json:"signed_up_from,omitempty" bson:"signed_up_from,omitempty"`
+	SignedUpTo   *time.Time           `json:"signed_up_to,omitempty" bson:"signed_up_to,omitempty"`
+	UserIDs      []primitive.ObjectID `json:"user_ids,omitempty" bson:"user_ids,omitempty" bound:"10000"`
+}
+
+// SurveySubmission represents a user's response to a survey
+type SurveySubmission struct {
+	ID         primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	SurveyID   primitive.ObjectID  `json:"survey_id" bson:"survey_id"`
+	UserID     *primitive.ObjectID `json:"user_id,omitempty" bson:"user_id,omitempty"` // Removed when the user is deleted
+	Answers    []SurveyAnswer      `json:"answers" bson:"answers"`
+	Anonymized bool                `json:"anonymized" bson:"anonymized"`
+	CreatedAt  time.Time           `json:"created_at" bson:"created_at"`
+}
+
+// SurveyAnswer represents the answer to a single survey question
+type SurveyAnswer struct {
+	QuestionID string   `json:"question_id" bson:"question_id"`
+	Choices    []string `json:"choices,omitempty" bson:"choices,omitempty"`
+	Scale      *int     `json:"scale,omitempty" bson:"scale,omitempty"`
+	Text       string   `json:"text,omitempty" bson:"text,omitempty"`
+}
+
+// Request DTOs
+
+// CreateSurveyRequest represents the request to create a survey
+type CreateSurveyRequest struct {
+	Title        string                  `json:"title" validate:"required,min=3,max=200"`
+	Description  string                  `json:"description,omitempty" validate:"max=1000"`
+	Questions    []CreateQuestionRequest `json:"questions" validate:"required,min=1,max=5,dive"`
+	Audience     SurveyAudienceRequest   `json:"audience"`
+	StartsAt     *time.Time              `json:"starts_at,omitempty"`
+	EndsAt       time.Time               `json:"ends_at" validate:"required"`
+	MaxResponses int64                   `json:"max_responses" validate:"min=0"`
+	NotifyUsers  bool                    `json:"notify_users"`
+}
+
+// CreateQuestionRequest represents a question in a create survey request
+type CreateQuestionRequest struct {
+	Type     SurveyQuestionType `json:"type" validate:"required,oneof=single_choice multi_choice scale free_text"`
+	Text     string             `json:"text" validate:"required,max=500"`
+	Options  []string           `json:"options,omitempty" validate:"max=10,dive,required,max=100"`
+	Required bool               `json:"required"`
+}
+
+// SurveyAudienceRequest represents the audience filter in a create survey request
+type SurveyAudienceRequest struct {
+	Roles        []UserRole `json:"roles,omitempty"`
+	Countries    []string   `json:"countries,omitempty" validate:"dive,len=2"`
+	IsPremium    *bool      `json:"is_premium,omitempty"`
+	SignedUpFrom *time.Time `json:"signed_up_from,omitempty"`
+	SignedUpTo   *time.Time `json:"signed_up_to,omitempty"`
+	UserIDs      []string   `json:"user_ids,omitempty" validate:"max=10000"`
+}
+
+// SubmitSurveyResponseRequest represents a user's answers to a survey
+type SubmitSurveyResponseRequest struct {
+	Answers []SurveyAnswer `json:"answers" validate:"required,min=1,max=5"`
+}
+
+// Response DTOs
+
+// PendingSurveyResponse represents a survey shown to an eligible user
+type PendingSurveyResponse struct {
+	ID          string           `json:"id"`
+	Title       string           `json:"title"`
+	Description string           `json:"description,omitempty"`
+	Questions   []SurveyQuestion `json:"questions"`
+	EndsAt      time.Time        `json:"ends_at"`
+}
+
+// SurveyResults represents aggregated survey results for admins
+type SurveyResults struct {
+	SurveyID       primitive.ObjectID     `json:"survey_id" bson:"_id"`
+	Status         SurveyStatus           `json:"status" bson:"status"`
+	TotalResponses int64                  `json:"total_responses" bson:"total_responses"`
+	Questions      []SurveyQuestionResult `json:"questions" bson:"questions"`
+	GeneratedAt    time.Time              `json:"generated_at" bson:"generated_at"`
+	ExpiresAt      time.Time              `json:"-" bson:"expires_at"`
+}
+
+// SurveyQuestionResult represents the breakdown of answers to a single question
+type SurveyQuestionResult struct {
+	QuestionID   string             `json:"question_id" bson:"question_id"`
+	Type         SurveyQuestionType `json:"type" bson:"type"`
+	Text         string             `json:"text" bson:"text"`
+	Answered     int64              `json:"answered" bson:"answered"`
+	Distribution map[string]int64   `json:"distribution,omitempty" bson:"distribution,omitempty"` // Choice or scale value counts
+	AverageScore float64            `json:"average_score,omitempty" bson:"average_score,omitempty"`
+}
+
+// Survey methods
+
+// BeforeCreate sets defaults before creating a survey
+func (s *Survey) BeforeCreate() {
+	s.BaseModel.BeforeCreate()
+
+	if s.StartsAt.IsZero() {
+		s.StartsAt = s.CreatedAt
+	}
+	if s.Status == "" {
+		s.Status = SurveyStatusActive
+	}
+	for i := range s.Questions {
+		s.Questions[i].ID = fmt.Sprintf("q%d", i+1)
+	}
+}
+
+// IsOpen checks if the survey currently accepts responses
+func (s *Survey) IsOpen(now time.Time) bool {
+	if s.Status != SurveyStatusActive || now.Before(s.StartsAt) || !now.Before(s.EndsAt) {
+		return false
+	}
+	return s.MaxResponses == 0 || s.ResponsesCount < s.MaxResponses
+}
+
+// Matches checks if the user falls within the survey audience
+func (a *SurveyAudience) Matches(user *User) bool {
+	if len(a.UserIDs) > 0 && !containsObjectID(a.UserIDs, user.ID) {
+		return false
+	}
+	if len(a.Roles) > 0 {
+		matched := false
+		for _, role := range a.Roles {
+			if role == user.Role {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(a.Countries) > 0 {
+		matched := false
+		for _, country := range a.Countries {
+			if strings.EqualFold(country, user.Country) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if a.IsPremium != nil && *a.IsPremium != user.IsPremium {
+		return false
+	}
+	if a.SignedUpFrom != nil && user.CreatedAt.Before(*a.SignedUpFrom) {
+		return false
+	}
+	if a.SignedUpTo != nil && user.CreatedAt.After(*a.SignedUpTo) {
+		return false
+	}
+	return true
+}
+
+// ValidateAnswers checks the answers against the survey questions
+func (s *Survey) ValidateAnswers(answers []SurveyAnswer) error {
+	byQuestion := make(map[string]SurveyAnswer, len(answers))
+	for _, answer := range answers {
+		if _, exists := byQuestion[answer.QuestionID]; exists {
+			return fmt.Errorf("duplicate answer for question %s", answer.QuestionID)
+		}
+		byQuestion[answer.QuestionID] = answer
+	}
+
+	for _, question := range s.Questions {
+		answer, answered := byQuestion[question.ID]
+		if !answered {
+			if question.Required {
+				return fmt.Errorf("question %s requires an answer", question.ID)
+			}
+			continue
+		}
+		delete(byQuestion, question.ID)
+
+		if err := question.validateAnswer(answer); err != nil {
+			return fmt.Errorf("invalid answer for question %s: %w", question.ID, err)
+		}
+	}
+
+	for questionID := range byQuestion {
+		return fmt.Errorf("unknown question %s", questionID)
+	}
+
+	return nil
+}
+
+func (q *SurveyQuestion) validateAnswer(answer SurveyAnswer) error {
+	switch q.Type {
+	case SurveyQuestionSingleChoice, SurveyQuestionMultiChoice:
+		if len(answer.Choices) == 0 {
+			return errors.New("at least one choice is required")
+		}
+		if q.Type == SurveyQuestionSingleChoice && len(answer.Choices) > 1 {
+			return errors.New("only one choice is allowed")
+		}
+		seen := make(map[string]bool)
+		for _, choice := range answer.Choices {
+			if !containsString(q.Options, choice) {
+				return fmt.Errorf("%q is not a valid choice", choice)
+			}
+			if seen[choice] {
+				return fmt.Errorf("%q was chosen more than once", choice)
+			}
+			seen[choice] = true
+		}
+	case SurveyQuestionScale:
+		if answer.Scale == nil || *answer.Scale < SurveyScaleMin || *answer.Scale > SurveyScaleMax {
+			return fmt.Errorf("scale must be between %d and %d", SurveyScaleMin, SurveyScaleMax)
+		}
+	case SurveyQuestionFreeText:
+		text := strings.TrimSpace(answer.Text)
+		if text == "" {
+			return errors.New("text is required")
+		}
+		if len(text) > MaxSurveyFreeTextSize {
+			return fmt.Errorf("text must be at most %d characters", MaxSurveyFreeTextSize)
+		}
+	default:
+		return errors.New("unsupported question type")
+	}
+	return nil
+}
+
+// ToPendingSurveyResponse converts Survey to PendingSurveyResponse
+func (s *Survey) ToPendingSurveyResponse() PendingSurveyResponse {
+	return PendingSurveyResponse{
+		ID:          s.ID.Hex(),
+		Title:       s.Title,
+		Description: s.Description,
+		Questions:   s.Questions,
+		EndsAt:      s.EndsAt,
+	}
+}
+
+func containsObjectID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+// models/tier_limits.go
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LimitTier groups users that share the same content and usage ceilings
+type LimitTier string
+
+const (
+	LimitTierFree    LimitTier = "free"
+	LimitTierPremium LimitTier = "premium"
+	LimitTierStaff   LimitTier = "staff" // Moderators and admins
+)
+
+// LimitName identifies one ceiling in TierLimits
+type LimitName string
+
+const (
+	LimitPostLength          LimitName = "post_length"            // Characters per post
+	LimitMediaPerPost        LimitName = "media_per_post"         // Attachments per post
+	LimitStoryDuration       LimitName = "story_duration"         // Seconds per story
+	LimitBioLength           LimitName = "bio_length"             // Characters in the profile bio
+	LimitDailyPosts          LimitName = "daily_posts"            // Posts in any 24 hours
+	LimitDailyNonFollowerDMs LimitName = "daily_non_follower_dms" // Direct messages in any 24 hours to users who don't follow the sender
+	LimitDailyMessageMedia   LimitName = "daily_message_media"    // Attachments sent in messages in any 24 hours
+)
+
+// LimitWindow is the rolling window daily limits are counted over
+const LimitWindow = 24 * time.Hour
+
+// TierLimits holds a tier's ceilings, stored in the tier_limits collection.
+// A ceiling of 0 means unlimited.
+type TierLimits struct {
+	ID   primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	Tier LimitTier          `json:"tier" bson:"tier"`
+
+	PostLength          int `json:"post_length" bson:"post_length"`
+	MediaPerPost        int `json:"media_per_post" bson:"media_per_post"`
+	StoryDuration       int `json:"story_duration" bson:"story_duration"`
+	BioLength           int `json:"bio_length" bson:"bio_length"`
+	DailyPosts          int `json:"daily_posts" bson:"daily_posts"`
+	DailyNonFollowerDMs int `json:"daily_non_follower_dms" bson:"daily_non_follower_dms"`
+	DailyMessageMedia   int `json:"daily_message_media" bson:"daily_message_media"`
+
+	UpdatedAt *time.Time          `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
+	UpdatedBy *primitive.ObjectID `json:"updated_by,omitempty" bson:"updated_by,omitempty"`
+}
+
+// LimitUsage records one use of a daily limit in the limit_usage collection.
+// Entries expire through a TTL index once they fall out of the window.
+type LimitUsage struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id"`
+	Limit     LimitName          `bson:"limit"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+// UpdateTierLimitsRequest represents an admin change to a tier's ceilings
+type UpdateTierLimitsRequest struct {
+	PostLength          *int   `json:"post_length,omitempty" validate:"omitempty,min=0"`
+	MediaPerPost        *int   `json:"media_per_post,omitempty" validate:"omitempty,min=0"`
+	StoryDuration       *int   `json:"story_duration,omitempty" validate:"omitempty,min=0"`
+	BioLength           *int   `json:"bio_length,omitempty" validate:"omitempty,min=0"`
+	DailyPosts          *int   `json:"daily_posts,omitempty" validate:"omitempty,min=0"`
+	DailyNonFollowerDMs *int   `json:"daily_non_follower_dms,omitempty" validate:"omitempty,min=0"`
+	DailyMessageMedia   *int   `json:"daily_message_media,omitempty" validate:"omitempty,min=0"`
+	Reason              string `json:"reason,omitempty" validate:"max=500"`
+}
+
+// LimitUsageCounters reports how much of each daily limit the user has used
+// in the current rolling window
+type LimitUsageCounters struct {
+	PostsToday          int64 `json:"posts_today"`
+	NonFollowerDMsToday int64 `json:"non_follower_dms_today"`
+	MessageMediaToday   int64 `json:"message_media_today"`
+}
+
+// UserLimitsResponse is the caller's effective limits and current usage
+type UserLimitsResponse struct {
+	Tier   LimitTier          `json:"tier"`
+	Limits TierLimits         `json:"limits"`
+	Usage  LimitUsageCounters `json:"usage"`
+	Window string             `json:"window"` // Daily limits count the trailing 24 hours
+}
+
+// LimitExceededError names the limit that was hit and the tier's ceiling
+type LimitExceededError struct {
+	Limit   LimitName `json:"limit"`
+	Tier    LimitTier `json:"tier"`
+	Ceiling int       `json:"ceiling"`
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("limit exceeded: %s is limited to %d for the %s tier", e.Limit, e.Ceiling, e.Tier)
+}
+
+// IsDaily checks if the limit is a rolling daily quota rather than a size ceiling
+func (e *LimitExceededError) IsDaily() bool {
+	return e.Limit == LimitDailyPosts || e.Limit == LimitDailyNonFollowerDMs || e.Limit == LimitDailyMessageMedia
+}
+
+// IsValidLimitTier checks if the tier is supported
+func IsValidLimitTier(tier LimitTier) bool {
+	switch tier {
+	case LimitTierFree, LimitTierPremium, LimitTierStaff:
+		return true
+	}
+	return false
+}
+
+// DefaultTierLimits returns the ceilings used until an admin stores limits for the tier
+func DefaultTierLimits(tier LimitTier) TierLimits {
+	switch tier {
+	case LimitTierPremium:
+		return TierLimits{
+			Tier:                LimitTierPremium,
+			PostLength:          5000,
+			MediaPerPost:        10,
+			StoryDuration:       60,
+			BioLength:           1000,
+			DailyPosts:          500,
+			DailyNonFollowerDMs: 200,
+			DailyMessageMedia:   500,
+		}
+	case LimitTierStaff:
+		return TierLimits{Tier: LimitTierStaff}
+	default:
+		return TierLimits{
+			Tier:                LimitTierFree,
+			PostLength:          2000,
+			MediaPerPost:        4,
+			StoryDuration:       30,
+			BioLength:           500,
+			DailyPosts:          100,
+			DailyNonFollowerDMs: 50,
+			DailyMessageMedia:   100,
+		}
+	}
+}
+
+// LimitTierFor returns the tier a user's limits come from. Staff roles win
+// over an active premium subscription.
+func LimitTierFor(u *User, now time.Time) LimitTier {
+	switch u.Role {
+	case RoleModerator, RoleAdmin, RoleSuperAdmin:
+		return LimitTierStaff
+	}
+	if u.IsPremium && (u.PremiumExpiry == nil || now.Before(*u.PremiumExpiry)) {
+		return LimitTierPremium
+	}
+	return LimitTierFree
+}
+
+// Ceiling returns the tier's ceiling for a limit, 0 meaning unlimited
+func (l *TierLimits) Ceiling(name LimitName) int {
+	switch name {
+	case LimitPostLength:
+		return l.PostLength
+	case LimitMediaPerPost:
+		return l.MediaPerPost
+	case LimitStoryDuration:
+		return l.StoryDuration
+	case LimitBioLength:
+		return l.BioLength
+	case LimitDailyPosts:
+		return l.DailyPosts
+	case LimitDailyNonFollowerDMs:
+		return l.DailyNonFollowerDMs
+	case LimitDailyMessageMedia:
+		return l.DailyMessageMedia
+	}
+	return 0
+}
+
+// Check returns a LimitExceededError if value is over the tier's ceiling
+func (l *TierLimits) Check(name LimitName, value int) error {
+	ceiling := l.Ceiling(name)
+	if ceiling > 0 && value > ceiling {
+		return &LimitExceededError{Limit: name, Tier: l.Tier, Ceiling: ceiling}
+	}
+	return nil
+}
+
+// Apply copies the fields set in the request onto the limits
+func (l *TierLimits) Apply(req UpdateTierLimitsRequest) {
+	if req.PostLength != nil {
+		l.PostLength = *req.PostLength
+	}
+	if req.MediaPerPost != nil {
+		l.MediaPerPost = *req.MediaPerPost
+	}
+	if req.StoryDuration != nil {
+		l.StoryDuration = *req.StoryDuration
+	}
+	if req.BioLength != nil {
+		l.BioLength = *req.BioLength
+	}
+	if req.DailyPosts != nil {
+		l.DailyPosts = *req.DailyPosts
+	}
+	if req.DailyNonFollowerDMs != nil {
+		l.DailyNonFollowerDMs = *req.DailyNonFollowerDMs
+	}
+	if req.DailyMessageMedia != nil {
+		l.DailyMessageMedia = *req.DailyMessageMedia
+	}
+}
@@ -0,0 +1,77 @@
+// models/translation.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Translation represents a cached machine translation of a post or comment
+type Translation struct {
+	BaseModel `bson:",inline"`
+
+	// Translated content
+	ContentID   primitive.ObjectID `json:"content_id" bson:"content_id"`
+	ContentType string             `json:"content_type" bson:"content_type"` // post, comment
+	SourceHash  string             `json:"-" bson:"source_hash"`             // Hash of the original text
+
+	// Languages
+	SourceLanguage string `json:"source_language" bson:"source_language"`
+	TargetLanguage string `json:"target_language" bson:"target_language"`
+
+	// Result
+	TranslatedText string `json:"translated_text" bson:"translated_text"`
+	Provider       string `json:"provider" bson:"provider"`
+	Attribution    string `json:"attribution" bson:"attribution"`
+}
+
+// TranslationUsage records a single translation request for analytics
+type TranslationUsage struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID         primitive.ObjectID `json:"user_id" bson:"user_id"`
+	ContentID      primitive.ObjectID `json:"content_id" bson:"content_id"`
+	ContentType    string             `json:"content_type" bson:"content_type"`
+	SourceLanguage string             `json:"source_language" bson:"source_language"`
+	TargetLanguage string             `json:"target_language" bson:"target_language"`
+	Characters     int                `json:"characters" bson:"characters"`
+	Cached         bool               `json:"cached" bson:"cached"`
+	Provider       string             `json:"provider" bson:"provider"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// TranslationResponse represents a translation returned in API responses
+type TranslationResponse struct {
+	ContentID      string `json:"content_id"`
+	ContentType    string `json:"content_type"`
+	SourceLanguage string `json:"source_language"`
+	TargetLanguage string `json:"target_language"`
+	TranslatedText string `json:"translated_text"`
+	Provider       string `json:"provider"`
+	Attribution    string `json:"attribution"`
+	Cached         bool   `json:"cached"`
+}
+
+// ToTranslationResponse converts Translation to TranslationResponse
+func (t *Translation) ToTranslationResponse(cached bool) TranslationResponse {
+	return TranslationResponse{
+		ContentID:      t.ContentID.Hex(),
+		ContentType:    t.ContentType,
+		SourceLanguage: t.SourceLanguage,
+		TargetLanguage: t.TargetLanguage,
+		TranslatedText: t.TranslatedText,
+		Provider:       t.Provider,
+		Attribution:    t.Attribution,
+		Cached:         cached,
+	}
+}
+
+// TranslationUsageStats represents aggregate translation usage for a language pair
+type TranslationUsageStats struct {
+	SourceLanguage string `json:"source_language" bson:"source_language"`
+	TargetLanguage string `json:"target_language" bson:"target_language"`
+	Requests       int64  `json:"requests" bson:"requests"`
+	CacheHits      int64  `json:"cache_hits" bson:"cache_hits"`
+	Characters     int64  `json:"characters" bson:"characters"`
+	UniqueUsers    int64  `json:"unique_users" bson:"unique_users"`
+}
@@ -3,6 +3,8 @@
 package models
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -13,12 +15,14 @@ type User struct {
 	BaseModel `bson:",inline"`
 
 	// Basic Information
-	Username    string `json:"username" bson:"username" validate:"required,min=3,max=50"`
-	Email       string `json:"email" bson:"email" validate:"required,email"`
-	Password    string `json:"-" bson:"password" validate:"required,min=8"`
-	FirstName   string `json:"first_name" bson:"first_name" validate:"required,min=2,max=50"`
-	LastName    string `json:"last_name" bson:"last_name" validate:"required,min=2,max=50"`
-	DisplayName string `json:"display_name" bson:"display_name" validate:"max=100"`
+	Username string `json:"username" bson:"username" validate:"required,min=3,max=50"`
+	// Lowercased username for case-insensitive prefix lookups
+	UsernameLower string `json:"-" bson:"username_lower,omitempty"`
+	Email         string `json:"email" bson:"email" validate:"required,email"`
+	Password      string `json:"-" bson:"password" validate:"required,min=8"`
+	FirstName     string `json:"first_name" bson:"first_name" validate:"required,min=2,max=50"`
+	LastName      string `json:"last_name" bson:"last_name" validate:"required,min=2,max=50"`
+	DisplayName   string `json:"display_name" bson:"display_name" validate:"max=100"`
 
 	// Profile Information
 	Bio         string     `json:"bio" bson:"bio" validate:"max=500"`
@@ -26,6 +30,7 @@ type User struct {
 	CoverPic    string     `json:"cover_pic" bson:"cover_pic"`
 	Website     string     `json:"website,omitempty" bson:"website,omitempty" validate:"omitempty,url"`
 	Location    string     `json:"location,omitempty" bson:"location,omitempty" validate:"max=100"`
+	Country     string     `json:"country,omitempty" bson:"country,omitempty" validate:"omitempty,len=2"` // ISO 3166-1 alpha-2
 	DateOfBirth *time.Time `json:"date_of_birth,omitempty" bson:"date_of_birth,omitempty"`
 	Gender      string     `json:"gender,omitempty" bson:"gender,omitempty" validate:"omitempty,oneof=male female other prefer_not_to_say"`
 
@@ -40,6 +45,19 @@ type User struct {
 	IsSuspended bool     `json:"is_suspended" bson:"is_suspended"`
 	Role        UserRole `json:"role" bson:"role"`
 
+	// Admin-curated account suggested to new users during onboarding
+	IsOnboardingSuggested bool `json:"is_onboarding_suggested" bson:"is_onboarding_suggested,omitempty"`
+
+	// Active moderator warnings; reaching the configured thresholds suspends
+	// the account, until SuspendedUntil when the suspension is temporary
+	StrikeCount    int        `json:"strike_count" bson:"strike_count"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty" bson:"suspended_until,omitempty"`
+
+	// Temporary deactivation hides the account until the user logs in again
+	// or, when ReactivateAt is set, until the reactivation job restores it
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty" bson:"deactivated_at,omitempty"`
+	ReactivateAt  *time.Time `json:"reactivate_at,omitempty" bson:"reactivate_at,omitempty"`
+
 	// Social Statistics
 	FollowersCount int64 `json:"followers_count" bson:"followers_count"`
 	FollowingCount int64 `json:"following_count" bson:"following_count"`
@@ -67,23 +85,42 @@ type User struct {
 	EmailVerified       bool       `json:"email_verified" bson:"email_verified"`
 	EmailVerifiedAt     *time.Time `json:"email_verified_at,omitempty" bson:"email_verified_at,omitempty"`
 
-	// Blocked/Reported Users
-	BlockedUsers    []primitive.ObjectID `json:"-" bson:"blocked_users,omitempty"`
-	ReportedByCount int64                `json:"-" bson:"reported_by_count"`
+	// Reported Users (blocks live in the blocked_users collection)
+	ReportedByCount int64 `json:"-" bson:"reported_by_count"`
+	// Held comments rejected as spam; enough strikes restricts the account
+	CommentStrikes int64 `json:"-" bson:"comment_strikes,omitempty"`
+	// Moderator triage signal derived from reports and spam flags by the
+	// abuse score job; never set from user input
+	AbuseScore          float64    `json:"-" bson:"abuse_score,omitempty"`
+	AbuseScoreUpdatedAt *time.Time `json:"-" bson:"abuse_score_updated_at,omitempty"`
 
 	// Device and Session Info
 	LastDeviceInfo string               `json:"-" bson:"last_device_info,omitempty"`
 	FCMTokens      []string             `json:"-" bson:"fcm_tokens,omitempty"` // For push notifications
-	ActiveSessions []primitive.ObjectID `json:"-" bson:"active_sessions,omitempty"`
+	ActiveSessions []primitive.ObjectID `json:"-" bson:"active_sessions,omitempty" bound:"20"`
 
 	// Preferences
-	Language string `json:"language" bson:"language"`
-	Timezone string `json:"timezone" bson:"timezone"`
-	Theme    string `json:"theme" bson:"theme"` // light, dark, auto
+	Language           string   `json:"language" bson:"language"`
+	PreferredLanguages []string `json:"preferred_languages,omitempty" bson:"preferred_languages,omitempty"` // Content languages for feed and search
+	Interests          []string `json:"interests,omitempty" bson:"interests,omitempty"`                     // Hashtag categories the user picked
+	InterestHashtags   []string `json:"-" bson:"interest_hashtags,omitempty"`                               // Top hashtags of the picked categories, personalizing the feed before behavior data accumulates
+	Timezone           string   `json:"timezone" bson:"timezone"`
+	Theme              string   `json:"theme" bson:"theme"` // light, dark, auto
+	// Show posts marked sensitive without the tap-to-reveal warning. Ignored
+	// for under-age accounts, which never see sensitive posts.
+	ShowSensitiveContent bool `json:"show_sensitive_content" bson:"show_sensitive_content"`
+	// Posts older than this are deleted by the auto-delete job, except
+	// pinned ones. Nil keeps posts until the user deletes them.
+	AutoDeletePostsAfter *time.Duration `json:"-" bson:"auto_delete_posts_after,omitempty"`
+	// Algorithm serving the user's feeds; empty uses the configured default
+	FeedAlgorithm string `json:"-" bson:"feed_algorithm,omitempty"`
 
 	// Social Links
 	SocialLinks map[string]string `json:"social_links,omitempty" bson:"social_links,omitempty"`
 
+	// Comments on the user's own posts featured on their profile, newest first
+	FeaturedComments []primitive.ObjectID `json:"-" bson:"featured_comments,omitempty" bound:"10"`
+
 	// Account Metrics (for analytics)
 	TotalLikesReceived    int64 `json:"total_likes_received" bson:"total_likes_received"`
 	TotalCommentsReceived int64 `json:"total_comments_received" bson:"total_comments_received"`
@@ -94,6 +131,11 @@ type User struct {
 	IsPremium        bool       `json:"is_premium" bson:"is_premium"`
 	PremiumExpiry    *time.Time `json:"premium_expiry,omitempty" bson:"premium_expiry,omitempty"`
 	SubscriptionPlan string     `json:"subscription_plan,omitempty" bson:"subscription_plan,omitempty"`
+
+	// Guided setup checklist: steps already recorded as completed, and when
+	// finishing the checklist granted a premium trial
+	SetupCompletedItems []SetupItemKey `json:"-" bson:"setup_completed_items,omitempty"`
+	SetupTrialGrantedAt *time.Time     `json:"-" bson:"setup_trial_granted_at,omitempty"`
 }
 
 // UserResponse represents the user data returned in API responses
@@ -125,6 +167,12 @@ type UserResponse struct {
 	MutualFriends  int64             `json:"mutual_friends,omitempty"` // Set based on current user context
 	SocialLinks    map[string]string `json:"social_links,omitempty"`
 	IsPremium      bool              `json:"is_premium"`
+
+	PreferredLanguages   []string `json:"preferred_languages,omitempty"`          // Only set for the user's own profile
+	ShowSensitiveContent *bool    `json:"show_sensitive_content,omitempty"`       // Only set for the user's own profile
+	AutoDeletePostsDays  *int     `json:"auto_delete_posts_after_days,omitempty"` // Only set for the user's own profile; 0 keeps posts
+	FeedAlgorithm        *string  `json:"feed_algorithm,omitempty"`               // Only set for the user's own profile; "default" follows the configured default
+	AbuseScore           *float64 `json:"abuse_score,omitempty"`                  // Only set in admin user lists
 }
 
 // ProfileResponse represents detailed profile information
@@ -135,6 +183,11 @@ type ProfileResponse struct {
 	ProfileViews          int64          `json:"profile_views,omitempty"`
 	RecentPosts           []PostResponse `json:"recent_posts,omitempty"`
 	MutualConnections     []UserResponse `json:"mutual_connections,omitempty"`
+	SocialGraphHidden     bool           `json:"social_graph_hidden,omitempty"` // Follower and following lists show counts only
+
+	// Only set when the viewer has muted this user
+	IsMuted    bool       `json:"is_muted,omitempty"`
+	MutedUntil *time.Time `json:"muted_until,omitempty"`
 }
 
 // RegisterRequest represents the user registration request
@@ -149,6 +202,7 @@ type RegisterRequest struct {
 	DateOfBirth *time.Time `json:"date_of_birth,omitempty"`
 	Gender      string     `json:"gender,omitempty" validate:"omitempty,oneof=male female other prefer_not_to_say"`
 	Phone       string     `json:"phone,omitempty"`
+	Interests   []string   `json:"interests,omitempty" validate:"omitempty,max=10,dive,min=1,max=50"` // Hashtag categories
 }
 
 // LoginRequest represents the user login request
@@ -157,6 +211,16 @@ type LoginRequest struct {
 	Password        string `json:"password" validate:"required"`
 	RememberMe      bool   `json:"remember_me"`
 	DeviceInfo      string `json:"device_info,omitempty"`
+	Reactivate      bool   `json:"reactivate"` // Reactivate a temporarily deactivated account
+}
+
+// DeactivateAccountRequest represents the request to deactivate the current account.
+// Temporary deactivation keeps the account intact; ReactivateAt schedules its return.
+type DeactivateAccountRequest struct {
+	Password     string     `json:"password" validate:"required"`
+	Reason       string     `json:"reason" validate:"max=500"`
+	Temporary    bool       `json:"temporary"`
+	ReactivateAt *time.Time `json:"reactivate_at,omitempty"`
 }
 
 // UpdateProfileRequest represents profile update request
@@ -164,13 +228,63 @@ type UpdateProfileRequest struct {
 	FirstName   *string           `json:"first_name,omitempty" validate:"omitempty,min=2,max=50"`
 	LastName    *string           `json:"last_name,omitempty" validate:"omitempty,min=2,max=50"`
 	DisplayName *string           `json:"display_name,omitempty" validate:"omitempty,max=100"`
-	Bio         *string           `json:"bio,omitempty" validate:"omitempty,max=500"`
+	Bio         *string           `json:"bio,omitempty"` // Capped by the user's tier limits
 	Website     *string           `json:"website,omitempty" validate:"omitempty,url"`
 	Location    *string           `json:"location,omitempty" validate:"omitempty,max=100"`
+	Country     *string           `json:"country,omitempty" validate:"omitempty,len=2"`
 	DateOfBirth *time.Time        `json:"date_of_birth,omitempty"`
 	Gender      *string           `json:"gender,omitempty" validate:"omitempty,oneof=male female other prefer_not_to_say"`
 	Phone       *string           `json:"phone,omitempty"`
 	SocialLinks map[string]string `json:"social_links,omitempty"`
+
+	PreferredLanguages   []string `json:"preferred_languages,omitempty" validate:"omitempty,max=10,dive,min=2,max=3,alpha"`
+	ShowSensitiveContent *bool    `json:"show_sensitive_content,omitempty"` // Refused for under-age accounts
+	FeedAlgorithm        *string  `json:"feed_algorithm,omitempty" validate:"omitempty,oneof=default chronological standard behavior hybrid"`
+}
+
+// UpdateAutoDeleteRequest sets how many days the user's posts are kept. 0
+// turns auto-delete off. When existing posts are already past the period the
+// change must be sent again with confirm set, since they are deleted on the
+// next run.
+type UpdateAutoDeleteRequest struct {
+	AfterDays int  `json:"after_days" validate:"min=0,max=3650"`
+	Confirm   bool `json:"confirm,omitempty"`
+}
+
+// AutoDeleteConfirmationError reports the existing posts an auto-delete
+// setting would delete, which the user has to confirm
+type AutoDeleteConfirmationError struct {
+	AfterDays     int   `json:"after_days"`
+	AffectedPosts int64 `json:"affected_posts"`
+}
+
+func (e *AutoDeleteConfirmationError) Error() string {
+	return fmt.Sprintf("confirmation required: %d existing posts are older than %d days and would be deleted", e.AffectedPosts, e.AfterDays)
+}
+
+// SensitiveContentMinimumAge is the age below which accounts never see posts
+// marked sensitive
+const SensitiveContentMinimumAge = 18
+
+// IsUnderAge reports whether a user born on dateOfBirth is younger than
+// SensitiveContentMinimumAge. Accounts without a birth date aren't under age.
+func IsUnderAge(dateOfBirth *time.Time) bool {
+	if dateOfBirth == nil {
+		return false
+	}
+	return time.Now().Before(dateOfBirth.AddDate(SensitiveContentMinimumAge, 0, 0))
+}
+
+// MaxUserInterests caps how many hashtag categories a user can pick
+const MaxUserInterests = 10
+
+// MaxFeaturedComments caps how many comments a user can feature on their profile
+const MaxFeaturedComments = 10
+
+// SetInterestsRequest replaces the user's interests with hashtag categories
+// from GetHashtagCategories
+type SetInterestsRequest struct {
+	Interests []string `json:"interests" validate:"required,min=1,max=10,dive,min=1,max=50"`
 }
 
 // ChangePasswordRequest represents password change request
@@ -231,6 +345,7 @@ const (
 // BeforeCreate sets default values before creating user
 func (u *User) BeforeCreate() {
 	u.BaseModel.BeforeCreate()
+	u.UsernameLower = strings.ToLower(u.Username)
 	u.IsVerified = false
 	u.IsActive = true
 	u.IsPrivate = false
@@ -262,6 +377,20 @@ func (u *User) BeforeCreate() {
 	}
 }
 
+// HasAdFreeEntitlement checks if the user's current premium plan is one of
+// the given ad-free plans
+func (u *User) HasAdFreeEntitlement(adFreePlans []string, now time.Time) bool {
+	if !u.IsPremium || (u.PremiumExpiry != nil && !now.Before(*u.PremiumExpiry)) {
+		return false
+	}
+	for _, plan := range adFreePlans {
+		if u.SubscriptionPlan == plan {
+			return true
+		}
+	}
+	return false
+}
+
 // ToUserResponse converts User model to UserResponse
 func (u *User) ToUserResponse() UserResponse {
 	return UserResponse{
@@ -287,6 +416,25 @@ func (u *User) ToUserResponse() UserResponse {
 	}
 }
 
+// ToOwnUserResponse converts User model to UserResponse for the user
+// themselves, including their content settings
+func (u *User) ToOwnUserResponse() UserResponse {
+	response := u.ToUserResponse()
+	response.PreferredLanguages = u.PreferredLanguages
+	response.ShowSensitiveContent = &u.ShowSensitiveContent
+	days := 0
+	if u.AutoDeletePostsAfter != nil {
+		days = int(*u.AutoDeletePostsAfter / (24 * time.Hour))
+	}
+	response.AutoDeletePostsDays = &days
+	algorithm := u.FeedAlgorithm
+	if algorithm == "" {
+		algorithm = "default"
+	}
+	response.FeedAlgorithm = &algorithm
+	return response
+}
+
 // ToUserResponseWithContext converts User model to UserResponse with relationship context
 func (u *User) ToUserResponseWithContext(currentUserID primitive.ObjectID, isFollowing, isFollowedBy, isFriend, isBlocked bool, mutualFriends int64) UserResponse {
 	response := u.ToUserResponse()
@@ -342,35 +490,6 @@ func (u *User) ToUserSearchResponse() UserSearchResponse {
 	}
 }
 
-// IsBlocking checks if this user is blocking another user
-func (u *User) IsBlocking(userID primitive.ObjectID) bool {
-	for _, blockedID := range u.BlockedUsers {
-		if blockedID == userID {
-			return true
-		}
-	}
-	return false
-}
-
-// BlockUser adds a user to the blocked list
-func (u *User) BlockUser(userID primitive.ObjectID) {
-	if !u.IsBlocking(userID) {
-		u.BlockedUsers = append(u.BlockedUsers, userID)
-		u.BeforeUpdate()
-	}
-}
-
-// UnblockUser removes a user from the blocked list
-func (u *User) UnblockUser(userID primitive.ObjectID) {
-	for i, blockedID := range u.BlockedUsers {
-		if blockedID == userID {
-			u.BlockedUsers = append(u.BlockedUsers[:i], u.BlockedUsers[i+1:]...)
-			u.BeforeUpdate()
-			break
-		}
-	}
-}
-
 // UpdateOnlineStatus updates the user's online status and last active time
 func (u *User) UpdateOnlineStatus(status string) {
 	u.OnlineStatus = status
@@ -0,0 +1,102 @@
+// models/warning.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WarningEscalation is what a warning triggered once the user's strikes
+// reached a configured threshold
+type WarningEscalation string
+
+const (
+	EscalationNone             WarningEscalation = ""
+	EscalationTempSuspension   WarningEscalation = "temp_suspension"
+	EscalationPermanentSuspend WarningEscalation = "permanent_suspension"
+)
+
+// UserWarning is a strike issued to a user by a moderator. Each active warning
+// counts towards the user's strike_count until it expires.
+type UserWarning struct {
+	BaseModel `bson:",inline"`
+
+	UserID   primitive.ObjectID  `json:"user_id" bson:"user_id"`
+	Reason   string              `json:"reason" bson:"reason"`
+	IssuedBy primitive.ObjectID  `json:"issued_by" bson:"issued_by"`
+	ReportID *primitive.ObjectID `json:"report_id,omitempty" bson:"report_id,omitempty"` // The report that led to it, if any
+
+	StrikeNumber   int               `json:"strike_number" bson:"strike_number"` // Active strikes including this one when issued
+	Escalation     WarningEscalation `json:"escalation,omitempty" bson:"escalation,omitempty"`
+	SuspendedUntil *time.Time        `json:"suspended_until,omitempty" bson:"suspended_until,omitempty"`
+
+	ExpiresAt *time.Time `json:"expires_at,omitempty" bson:"expires_at,omitempty"` // Nil when strikes never expire
+	Expired   bool       `json:"expired" bson:"expired"`
+
+	Issuer UserResponse `json:"issuer,omitempty" bson:"-"` // Populated when querying
+}
+
+// UserWarningResponse represents a warning in API responses
+type UserWarningResponse struct {
+	ID             string            `json:"id"`
+	UserID         string            `json:"user_id"`
+	Reason         string            `json:"reason"`
+	ReportID       string            `json:"report_id,omitempty"`
+	StrikeNumber   int               `json:"strike_number"`
+	Escalation     WarningEscalation `json:"escalation,omitempty"`
+	SuspendedUntil *time.Time        `json:"suspended_until,omitempty"`
+	ExpiresAt      *time.Time        `json:"expires_at,omitempty"`
+	Active         bool              `json:"active"`
+	CreatedAt      time.Time         `json:"created_at"`
+
+	// Admin views only
+	IssuedBy string        `json:"issued_by,omitempty"`
+	Issuer   *UserResponse `json:"issuer,omitempty"`
+}
+
+// IssueWarningRequest represents the request to warn a user
+type IssueWarningRequest struct {
+	Reason   string `json:"reason" validate:"required,min=3,max=1000"`
+	ReportID string `json:"report_id,omitempty"`
+}
+
+// IsActive checks if the warning still counts as a strike
+func (w *UserWarning) IsActive() bool {
+	if w.Expired {
+		return false
+	}
+	return w.ExpiresAt == nil || time.Now().Before(*w.ExpiresAt)
+}
+
+// ToUserWarningResponse converts UserWarning to the response the warned user sees
+func (w *UserWarning) ToUserWarningResponse() UserWarningResponse {
+	response := UserWarningResponse{
+		ID:             w.ID.Hex(),
+		UserID:         w.UserID.Hex(),
+		Reason:         w.Reason,
+		StrikeNumber:   w.StrikeNumber,
+		Escalation:     w.Escalation,
+		SuspendedUntil: w.SuspendedUntil,
+		ExpiresAt:      w.ExpiresAt,
+		Active:         w.IsActive(),
+		CreatedAt:      w.CreatedAt,
+	}
+
+	if w.ReportID != nil {
+		response.ReportID = w.ReportID.Hex()
+	}
+
+	return response
+}
+
+// ToAdminWarningResponse converts UserWarning to a response naming the moderator who issued it
+func (w *UserWarning) ToAdminWarningResponse() UserWarningResponse {
+	response := w.ToUserWarningResponse()
+	response.IssuedBy = w.IssuedBy.Hex()
+	if w.Issuer.ID != "" {
+		issuer := w.Issuer
+		response.Issuer = &issuer
+	}
+	return response
+}
@@ -0,0 +1,141 @@
+// internal/repository/filters.go
+package repository
+
+import (
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DeletedAtField is set on soft-deleted documents. Every read excludes those
+// documents unless it opts in with IncludeDeleted.
+const DeletedAtField = "deleted_at"
+
+// notDeletedClause matches documents that haven't been soft-deleted
+func notDeletedClause() bson.M {
+	return bson.M{"$exists": false}
+}
+
+// NotDeleted merges the given filters and excludes soft-deleted documents.
+// Without filters it matches every live document.
+func NotDeleted(filters ...bson.M) bson.M {
+	filter := bson.M{}
+	for _, f := range filters {
+		for key, value := range f {
+			filter[key] = value
+		}
+	}
+	filter[DeletedAtField] = notDeletedClause()
+	return filter
+}
+
+// ByID matches the live document with the given ID
+func ByID(id primitive.ObjectID) bson.M {
+	return NotDeleted(bson.M{"_id": id})
+}
+
+// Query composes a read filter from a base filter and the soft-delete,
+// hidden and published rules shared by content collections. The soft-delete
+// filter is always applied unless IncludeDeleted is set.
+type Query struct {
+	filter         bson.M
+	clauses        []bson.M
+	includeDeleted bool
+}
+
+// Where starts a query from a base filter, which may be nil
+func Where(filter bson.M) *Query {
+	q := &Query{filter: bson.M{}}
+	for key, value := range filter {
+		q.filter[key] = value
+	}
+	return q
+}
+
+// WhereID starts a query for the document with the given ID
+func WhereID(id primitive.ObjectID) *Query {
+	return Where(bson.M{"_id": id})
+}
+
+// Published keeps published documents, leaving out scheduled posts
+func (q *Query) Published() *Query {
+	q.filter["is_published"] = true
+	return q
+}
+
+// NotHidden leaves out documents hidden by moderation
+func (q *Query) NotHidden() *Query {
+	q.filter["is_hidden"] = bson.M{"$ne": true}
+	return q
+}
+
+// VisibleTo leaves out documents hidden by moderation, except the viewer's
+// own. A nil viewer sees no hidden documents.
+func (q *Query) VisibleTo(viewerID *primitive.ObjectID) *Query {
+	if viewerID == nil {
+		return q.NotHidden()
+	}
+	q.clauses = append(q.clauses, bson.M{"$or": []bson.M{
+		{"is_hidden": bson.M{"$ne": true}},
+		{"user_id": *viewerID},
+	}})
+	return q
+}
+
+// IncludeDeleted makes the query match soft-deleted documents too, for the
+// admin and restore paths that need to see them
+func (q *Query) IncludeDeleted() *Query {
+	q.includeDeleted = true
+	return q
+}
+
+// Filter returns the composed filter
+func (q *Query) Filter() bson.M {
+	filter := bson.M{}
+	for key, value := range q.filter {
+		filter[key] = value
+	}
+	if !q.includeDeleted {
+		filter[DeletedAtField] = notDeletedClause()
+	}
+
+	if len(q.clauses) == 0 {
+		return filter
+	}
+
+	// Keep any $or in the base filter apart from the composed clauses, and
+	// any $and the base filter already has alongside them
+	for _, clause := range q.clauses {
+		AppendAnd(filter, clause)
+	}
+	return filter
+}
+
+// AppendAnd adds conditions to the filter's $and, keeping the conditions
+// already there. The $and becomes a bson.A.
+func AppendAnd(filter bson.M, conditions ...interface{}) bson.M {
+	filter["$and"] = append(andClauses(filter["$and"]), conditions...)
+	return filter
+}
+
+// andClauses returns the conditions of an existing $and, whatever slice type
+// holds them (bson.A, []bson.M, []bson.D, []interface{}...). A value that
+// isn't a slice is kept as a single condition, so the server rejects the
+// malformed $and rather than the conditions being dropped.
+func andClauses(existing interface{}) bson.A {
+	if existing == nil {
+		return bson.A{}
+	}
+
+	value := reflect.ValueOf(existing)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return bson.A{existing}
+	}
+
+	clauses := make(bson.A, 0, value.Len()+1)
+	for i := 0; i < value.Len(); i++ {
+		clauses = append(clauses, value.Index(i).Interface())
+	}
+	return clauses
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestQueryFilterKeepsExistingAnd(t *testing.T) {
+	viewerID := primitive.NewObjectID()
+	visibility := bson.M{"$or": []bson.M{
+		{"is_hidden": bson.M{"$ne": true}},
+		{"user_id": viewerID},
+	}}
+	existing := bson.M{"$or": []bson.M{{"visibility": "public"}, {"user_id": viewerID}}}
+
+	tests := []struct {
+		name string
+		and  interface{}
+		want bson.A
+	}{
+		{"no $and", nil, bson.A{visibility}},
+		{"[]bson.M", []bson.M{existing}, bson.A{existing, visibility}},
+		{"bson.A", bson.A{existing}, bson.A{existing, visibility}},
+		{"[]interface{}", []interface{}{existing}, bson.A{existing, visibility}},
+		{"[]bson.D", []bson.D{{{Key: "visibility", Value: "public"}}}, bson.A{bson.D{{Key: "visibility", Value: "public"}}, visibility}},
+		{"not a slice", existing, bson.A{existing, visibility}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := bson.M{"user_id": viewerID}
+			if tt.and != nil {
+				base["$and"] = tt.and
+			}
+
+			filter := Where(base).VisibleTo(&viewerID).Filter()
+
+			if !reflect.DeepEqual(filter["$and"], tt.want) {
+				t.Errorf("$and = %#v, want %#v", filter["$and"], tt.want)
+			}
+			if _, ok := filter[DeletedAtField]; !ok {
+				t.Error("the soft-delete filter is missing")
+			}
+		})
+	}
+}
+
+func TestQueryFilterLeavesBaseUntouched(t *testing.T) {
+	base := bson.M{"$and": []bson.M{{"a": 1}}}
+	viewerID := primitive.NewObjectID()
+
+	Where(base).VisibleTo(&viewerID).Filter()
+
+	if and := base["$and"].([]bson.M); len(and) != 1 {
+		t.Errorf("base $and was modified: %v", and)
+	}
+}
+
+func TestQueryFilter(t *testing.T) {
+	id := primitive.NewObjectID()
+
+	tests := []struct {
+		name  string
+		query *Query
+		want  bson.M
+	}{
+		{
+			name:  "live documents only by default",
+			query: WhereID(id),
+			want:  bson.M{"_id": id, DeletedAtField: bson.M{"$exists": false}},
+		},
+		{
+			name:  "include deleted",
+			query: WhereID(id).IncludeDeleted(),
+			want:  bson.M{"_id": id},
+		},
+		{
+			name:  "published and not hidden",
+			query: Where(nil).Published().NotHidden(),
+			want:  bson.M{"is_published": true, "is_hidden": bson.M{"$ne": true}, DeletedAtField: bson.M{"$exists": false}},
+		},
+		{
+			name:  "anonymous viewers see nothing hidden",
+			query: Where(nil).VisibleTo(nil),
+			want:  bson.M{"is_hidden": bson.M{"$ne": true}, DeletedAtField: bson.M{"$exists": false}},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.query.Filter(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Filter() = %#v, want %#v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAppendAnd(t *testing.T) {
+	filter := bson.M{"$and": []bson.M{{"a": 1}}}
+	AppendAnd(filter, bson.M{"b": 2}, bson.M{"c": 3})
+
+	want := bson.A{bson.M{"a": 1}, bson.M{"b": 2}, bson.M{"c": 3}}
+	if !reflect.DeepEqual(filter["$and"], want) {
+		t.Errorf("$and = %#v, want %#v", filter["$and"], want)
+	}
+}
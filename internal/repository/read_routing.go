@@ -0,0 +1,169 @@
+// internal/repository/read_routing.go
+package repository
+
+import (
+	"context"
+	"expvar"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// Read routing sends heavy reads (feeds, explore, search, analytics, admin
+// exports and content lists) to secondaries while everything else stays on
+// the primary. Services opt a read in by building its context with
+// HeavyRead and reading through ReadFrom. A user who wrote recently reads
+// their own heavy reads from the primary for ReadYourWritesWindow, so a post
+// or comment they just created shows up even while secondaries lag.
+//
+// The write marker is kept in memory, so the guarantee holds for reads
+// served by the instance that took the write.
+
+// Read preference names reported by ReadRoutingStats
+const (
+	ReadPrimary            = "primary"
+	ReadSecondaryPreferred = "secondary_preferred"
+	ReadYourWrites         = "read_your_writes" // Heavy read kept on the primary after the reader's write
+)
+
+// maxWriteMarkers is how many write markers are kept before expired ones
+// are swept
+const maxWriteMarkers = 10000
+
+// readRoutingCounts counts reads per preference
+var readRoutingCounts = expvar.NewMap("read_routing")
+
+// ReadRoutingPolicy configures read routing. MaxStaleness bounds how far
+// behind a secondary may be and still serve reads; MongoDB requires at
+// least 90 seconds.
+type ReadRoutingPolicy struct {
+	Enabled              bool
+	MaxStaleness         time.Duration
+	ReadYourWritesWindow time.Duration
+
+	// Secondary returns the handle secondary reads of coll go to. It
+	// defaults to coll with a secondaryPreferred read preference; tests
+	// point it at a lagging copy of the data.
+	Secondary func(coll *mongo.Collection) *mongo.Collection
+}
+
+type readContextKey struct{}
+
+// readContext is what HeavyRead stores in a context
+type readContext struct {
+	readerID *primitive.ObjectID
+}
+
+var (
+	routingMu sync.RWMutex
+	routing   ReadRoutingPolicy
+
+	writesMu   sync.Mutex
+	lastWrites = make(map[primitive.ObjectID]time.Time)
+)
+
+// ConfigureReadRouting sets the read routing policy; routing is off until
+// it is called with Enabled set
+func ConfigureReadRouting(policy ReadRoutingPolicy) {
+	if policy.Secondary == nil {
+		secondary := readpref.SecondaryPreferred(readpref.WithMaxStaleness(policy.MaxStaleness))
+		policy.Secondary = func(coll *mongo.Collection) *mongo.Collection {
+			clone, err := coll.Clone(options.Collection().SetReadPreference(secondary))
+			if err != nil {
+				return coll
+			}
+			return clone
+		}
+	}
+
+	routingMu.Lock()
+	routing = policy
+	routingMu.Unlock()
+}
+
+// HeavyRead marks ctx as a read that may be served by a secondary. readerID
+// is the signed-in user the read is for, nil for anonymous or system reads.
+func HeavyRead(ctx context.Context, readerID *primitive.ObjectID) context.Context {
+	return context.WithValue(ctx, readContextKey{}, readContext{readerID: readerID})
+}
+
+// RecordWrite marks that userID just wrote content, keeping their heavy
+// reads on the primary for the read-your-writes window
+func RecordWrite(userID primitive.ObjectID) {
+	routingMu.RLock()
+	enabled, window := routing.Enabled, routing.ReadYourWritesWindow
+	routingMu.RUnlock()
+	if !enabled {
+		return
+	}
+
+	writesMu.Lock()
+	defer writesMu.Unlock()
+
+	current := time.Now()
+	lastWrites[userID] = current
+
+	// Forget markers whose window has passed so the map stays small
+	if len(lastWrites) > maxWriteMarkers {
+		for id, at := range lastWrites {
+			if current.Sub(at) > window {
+				delete(lastWrites, id)
+			}
+		}
+	}
+}
+
+// ReadPreferenceFor returns the read preference a read with ctx is routed to
+func ReadPreferenceFor(ctx context.Context) string {
+	routingMu.RLock()
+	policy := routing
+	routingMu.RUnlock()
+
+	read, ok := ctx.Value(readContextKey{}).(readContext)
+	if !policy.Enabled || !ok {
+		return ReadPrimary
+	}
+	if read.readerID != nil && wroteRecently(*read.readerID, policy.ReadYourWritesWindow) {
+		return ReadYourWrites
+	}
+	return ReadSecondaryPreferred
+}
+
+// ReadFrom returns the handle a read with ctx should use: coll itself, or
+// coll routed to a secondary for heavy reads. Every call is counted per
+// read preference.
+func ReadFrom(ctx context.Context, coll *mongo.Collection) *mongo.Collection {
+	preference := ReadPreferenceFor(ctx)
+	readRoutingCounts.Add(preference, 1)
+
+	if preference != ReadSecondaryPreferred {
+		return coll
+	}
+
+	routingMu.RLock()
+	secondary := routing.Secondary
+	routingMu.RUnlock()
+	return secondary(coll)
+}
+
+// ReadRoutingStats returns the number of reads per read preference
+func ReadRoutingStats() map[string]int64 {
+	stats := make(map[string]int64)
+	readRoutingCounts.Do(func(kv expvar.KeyValue) {
+		if counter, ok := kv.Value.(*expvar.Int); ok {
+			stats[kv.Key] = counter.Value()
+		}
+	})
+	return stats
+}
+
+func wroteRecently(userID primitive.ObjectID, window time.Duration) bool {
+	writesMu.Lock()
+	at, ok := lastWrites[userID]
+	writesMu.Unlock()
+	return ok && time.Since(at) <= window
+}
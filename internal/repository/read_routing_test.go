@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const testReadYourWritesWindow = 100 * time.Millisecond
+
+// testCollections returns a primary handle and the distinct handle secondary
+// reads are routed to. The client never connects; routing only picks handles.
+func testCollections(t *testing.T) (primary, secondary *mongo.Collection) {
+	t.Helper()
+
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+
+	return client.Database("primary").Collection("posts"), client.Database("secondary").Collection("posts")
+}
+
+// routeReads turns routing on with secondary reads going to secondary and
+// turns it off again when the test finishes
+func routeReads(t *testing.T, secondary *mongo.Collection) {
+	t.Helper()
+
+	ConfigureReadRouting(ReadRoutingPolicy{
+		Enabled:              true,
+		MaxStaleness:         90 * time.Second,
+		ReadYourWritesWindow: testReadYourWritesWindow,
+		Secondary:            func(*mongo.Collection) *mongo.Collection { return secondary },
+	})
+	t.Cleanup(func() { ConfigureReadRouting(ReadRoutingPolicy{}) })
+}
+
+func TestReadPreferenceFor(t *testing.T) {
+	_, secondary := testCollections(t)
+	routeReads(t, secondary)
+
+	writer := primitive.NewObjectID()
+	reader := primitive.NewObjectID()
+	RecordWrite(writer)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"plain read", context.Background(), ReadPrimary},
+		{"anonymous heavy read", HeavyRead(context.Background(), nil), ReadSecondaryPreferred},
+		{"heavy read by another user", HeavyRead(context.Background(), &reader), ReadSecondaryPreferred},
+		{"heavy read by the writer", HeavyRead(context.Background(), &writer), ReadYourWrites},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ReadPreferenceFor(tt.ctx); got != tt.want {
+				t.Errorf("ReadPreferenceFor = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	time.Sleep(testReadYourWritesWindow + 50*time.Millisecond)
+	if got := ReadPreferenceFor(HeavyRead(context.Background(), &writer)); got != ReadSecondaryPreferred {
+		t.Errorf("writer's heavy read after the window = %q, want %q", got, ReadSecondaryPreferred)
+	}
+}
+
+func TestReadFromRoutesOnlyHeavyReads(t *testing.T) {
+	primary, secondary := testCollections(t)
+	routeReads(t, secondary)
+
+	writer := primitive.NewObjectID()
+	RecordWrite(writer)
+
+	before := ReadRoutingStats()
+
+	if got := ReadFrom(context.Background(), primary); got != primary {
+		t.Errorf("plain read went to %s, want the primary", got.Database().Name())
+	}
+	if got := ReadFrom(HeavyRead(context.Background(), nil), primary); got != secondary {
+		t.Errorf("heavy read went to %s, want the secondary", got.Database().Name())
+	}
+	if got := ReadFrom(HeavyRead(context.Background(), &writer), primary); got != primary {
+		t.Errorf("writer's heavy read went to %s, want the primary", got.Database().Name())
+	}
+
+	after := ReadRoutingStats()
+	for _, preference := range []string{ReadPrimary, ReadSecondaryPreferred, ReadYourWrites} {
+		if got := after[preference] - before[preference]; got != 1 {
+			t.Errorf("%s reads counted = %d, want 1", preference, got)
+		}
+	}
+}
+
+func TestReadRoutingDisabled(t *testing.T) {
+	primary, _ := testCollections(t)
+	ConfigureReadRouting(ReadRoutingPolicy{ReadYourWritesWindow: time.Minute})
+	t.Cleanup(func() { ConfigureReadRouting(ReadRoutingPolicy{}) })
+
+	writer := primitive.NewObjectID()
+	RecordWrite(writer)
+
+	ctx := HeavyRead(context.Background(), nil)
+	if got := ReadPreferenceFor(ctx); got != ReadPrimary {
+		t.Errorf("heavy read with routing off = %q, want %q", got, ReadPrimary)
+	}
+	if got := ReadFrom(ctx, primary); got != primary {
+		t.Error("heavy read with routing off left the primary")
+	}
+
+	// Writes aren't tracked while routing is off
+	writesMu.Lock()
+	_, tracked := lastWrites[writer]
+	writesMu.Unlock()
+	if tracked {
+		t.Error("write recorded while routing is off")
+	}
+}
+
+func TestDefaultSecondaryClonesCollection(t *testing.T) {
+	primary, _ := testCollections(t)
+	ConfigureReadRouting(ReadRoutingPolicy{Enabled: true, MaxStaleness: 90 * time.Second})
+	t.Cleanup(func() { ConfigureReadRouting(ReadRoutingPolicy{}) })
+
+	got := ReadFrom(HeavyRead(context.Background(), nil), primary)
+	if got == primary {
+		t.Error("heavy read used the primary handle, want a secondaryPreferred clone")
+	}
+	if got.Name() != primary.Name() || got.Database().Name() != primary.Database().Name() {
+		t.Errorf("heavy read went to %s.%s, want a clone of %s.%s", got.Database().Name(), got.Name(), primary.Database().Name(), primary.Name())
+	}
+}
@@ -0,0 +1,171 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"social-media-api/internal/handlers"
+	"social-media-api/internal/middleware"
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+	"social-media-api/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestAccountSwitcherRoutes drives the account switcher over HTTP through
+// the auth middleware: adding an account, listing and switching, a
+// suspension that signs out only the suspended account, and scoped logouts
+func TestAccountSwitcherRoutes(t *testing.T) {
+	h := testutil.NewHarness(t)
+
+	auth := h.NewAuthService()
+	limits := services.NewLimitsService(h.DB, time.Minute)
+	users := services.NewUserService(h.DB, services.AbuseScorePolicy{}, limits)
+	authHandler := handlers.NewAuthHandler(auth, users, services.NewFollowService(h.DB, services.NewNotificationService(nil, nil)), services.NewPushService("", "", "", "", "", nil))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	authMiddleware := middleware.NewAuthMiddleware(h.DB, testutil.TestJWTSecret, testutil.TestRefreshSecret, nil, nil)
+	SetupAuthRoutes(router, authHandler, authMiddleware, middleware.NewChallengeMiddleware(nil))
+
+	call := func(method, path, token string, body interface{}) (*httptest.ResponseRecorder, utils.Response) {
+		t.Helper()
+
+		var payload bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&payload).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &payload)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		var response utils.Response
+		json.Unmarshal(recorder.Body.Bytes(), &response)
+		return recorder, response
+	}
+
+	tokens := func(response utils.Response) string {
+		t.Helper()
+		data, _ := json.Marshal(response.Data)
+		var login services.LoginResponse
+		json.Unmarshal(data, &login)
+		if login.AccessToken == "" {
+			t.Fatalf("response carries no access token: %s", data)
+		}
+		return login.AccessToken
+	}
+
+	accounts := func(token string) []models.DeviceAccount {
+		t.Helper()
+		recorder, response := call(http.MethodGet, "/api/v1/auth/accounts", token, nil)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("GET /auth/accounts = %d: %s", recorder.Code, recorder.Body.String())
+		}
+		data, _ := json.Marshal(response.Data)
+		var list []models.DeviceAccount
+		json.Unmarshal(data, &list)
+		return list
+	}
+
+	personal := h.CreateUser()
+	brand := h.CreateUser()
+	personalToken := h.SignIn(personal).AccessToken
+
+	recorder, response := call(http.MethodPost, "/api/v1/auth/add-account", personalToken, models.AddAccountRequest{
+		EmailOrUsername: brand.Username,
+		Password:        testutil.DefaultPassword,
+	})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("POST /auth/add-account = %d: %s", recorder.Code, recorder.Body.String())
+	}
+	brandToken := tokens(response)
+
+	list := accounts(brandToken)
+	if len(list) != 2 || list[0].UserID != personal.ID || list[1].UserID != brand.ID || !list[1].IsCurrent || list[0].IsCurrent {
+		t.Errorf("accounts as brand = %+v, want personal then the current brand account", list)
+	}
+
+	recorder, _ = call(http.MethodPost, "/api/v1/auth/add-account", personalToken, models.AddAccountRequest{
+		EmailOrUsername: brand.Username,
+		Password:        testutil.DefaultPassword,
+	})
+	if recorder.Code != http.StatusConflict {
+		t.Errorf("adding the brand account again = %d, want 409", recorder.Code)
+	}
+
+	// A live session switches without a password
+	recorder, response = call(http.MethodPost, "/api/v1/auth/switch/"+personal.ID.Hex(), brandToken, nil)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("switching to personal = %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if list := accounts(tokens(response)); len(list) != 2 || !list[0].IsCurrent {
+		t.Errorf("accounts after switching = %+v, want personal current", list)
+	}
+
+	// Suspending the brand account signs out only the brand account
+	if err := users.SuspendUser(brand.ID, "test"); err != nil {
+		t.Fatalf("SuspendUser: %v", err)
+	}
+	if recorder, _ := call(http.MethodGet, "/api/v1/auth/accounts", brandToken, nil); recorder.Code != http.StatusUnauthorized {
+		t.Errorf("suspended account's token = %d, want 401", recorder.Code)
+	}
+	if list := accounts(personalToken); len(list) != 1 || list[0].UserID != personal.ID {
+		t.Errorf("accounts after the suspension = %+v, want only personal", list)
+	}
+
+	for _, password := range []string{"", testutil.DefaultPassword} {
+		recorder, _ = call(http.MethodPost, "/api/v1/auth/switch/"+brand.ID.Hex(), personalToken, models.SwitchAccountRequest{Password: password})
+		if recorder.Code != http.StatusForbidden {
+			t.Errorf("switching to the suspended account (password %q) = %d, want 403", password, recorder.Code)
+		}
+	}
+
+	// Scoped logouts
+	if recorder, _ := call(http.MethodPost, "/api/v1/auth/logout?scope=everywhere", personalToken, nil); recorder.Code != http.StatusBadRequest {
+		t.Errorf("logout with an unknown scope = %d, want 400", recorder.Code)
+	}
+
+	other := h.CreateUser()
+	recorder, response = call(http.MethodPost, "/api/v1/auth/add-account", personalToken, models.AddAccountRequest{
+		EmailOrUsername: other.Username,
+		Password:        testutil.DefaultPassword,
+	})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("adding another account = %d: %s", recorder.Code, recorder.Body.String())
+	}
+	otherToken := tokens(response)
+
+	if recorder, _ := call(http.MethodPost, "/api/v1/auth/logout?scope=current", otherToken, nil); recorder.Code != http.StatusOK {
+		t.Fatalf("logout scope=current = %d", recorder.Code)
+	}
+	if list := accounts(personalToken); len(list) != 1 {
+		t.Errorf("accounts after the current-scope logout = %+v, want personal only", list)
+	}
+
+	// Without a live session the switch demands the password
+	recorder, response = call(http.MethodPost, "/api/v1/auth/switch/"+other.ID.Hex(), personalToken, nil)
+	if recorder.Code != http.StatusUnauthorized || response.ErrorCode != utils.ErrorCodeCredentialsRequired {
+		t.Errorf("switching to the signed-out account = %d %q, want 401 %q", recorder.Code, response.ErrorCode, utils.ErrorCodeCredentialsRequired)
+	}
+	recorder, _ = call(http.MethodPost, "/api/v1/auth/switch/"+other.ID.Hex(), personalToken, models.SwitchAccountRequest{Password: testutil.DefaultPassword})
+	if recorder.Code != http.StatusOK {
+		t.Errorf("switching to the signed-out account with its password = %d, want 200", recorder.Code)
+	}
+
+	if recorder, _ := call(http.MethodPost, "/api/v1/auth/logout?scope=all-on-device", personalToken, nil); recorder.Code != http.StatusOK {
+		t.Fatalf("logout scope=all-on-device = %d", recorder.Code)
+	}
+	if recorder, _ := call(http.MethodGet, "/api/v1/auth/accounts", personalToken, nil); recorder.Code != http.StatusUnauthorized {
+		t.Errorf("token after logging out the device = %d, want 401", recorder.Code)
+	}
+}
@@ -18,22 +18,15 @@ import (
 // Simplified admin middleware with better error handling
 func requireAdminRole() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
-		userRole, exists := c.Get("user_role")
-		if !exists {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Authentication required", nil)
-			c.Abort()
-			return
-		}
-
-		role, ok := userRole.(models.UserRole)
+		role, ok := utils.LookupRole(c)
 		if !ok {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user role", nil)
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Authentication required", nil)
 			c.Abort()
 			return
 		}
 
-		if role != models.RoleAdmin && role != models.RoleSuperAdmin {
-			utils.ErrorResponse(c, http.StatusForbidden, "Admin access required", nil)
+		if !role.IsAdmin() {
+			utils.ErrorResponseWithCode(c, http.StatusForbidden, "Admin access required", utils.ErrorCodeInsufficientPermissions, nil)
 			c.Abort()
 			return
 		}
@@ -44,22 +37,15 @@ func requireAdminRole() gin.HandlerFunc {
 
 func requireSuperAdminRole() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
-		userRole, exists := c.Get("user_role")
-		if !exists {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Authentication required", nil)
-			c.Abort()
-			return
-		}
-
-		role, ok := userRole.(models.UserRole)
+		role, ok := utils.LookupRole(c)
 		if !ok {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid user role", nil)
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Authentication required", nil)
 			c.Abort()
 			return
 		}
 
 		if role != models.RoleSuperAdmin {
-			utils.ErrorResponse(c, http.StatusForbidden, "Super admin access required", nil)
+			utils.ErrorResponseWithCode(c, http.StatusForbidden, "Super admin access required", utils.ErrorCodeInsufficientPermissions, nil)
 			c.Abort()
 			return
 		}
@@ -91,6 +77,7 @@ func SetupAdminRoutes(router *gin.Engine, adminHandler *handlers.AdminHandler, a
 		users.GET("/:id/stats", middleware.ValidateObjectID("id"), adminHandler.GetUserStats)
 		users.PUT("/:id/status", middleware.ValidateObjectID("id"), adminHandler.UpdateUserStatus)
 		users.PUT("/:id/verify", middleware.ValidateObjectID("id"), adminHandler.VerifyUser)
+		users.PUT("/:id/onboarding-suggested", middleware.ValidateObjectID("id"), adminHandler.SetOnboardingSuggested)
 		users.DELETE("/:id", middleware.ValidateObjectID("id"), adminHandler.DeleteUser)
 		users.POST("/bulk/actions", adminHandler.BulkUserAction)
 		users.GET("/export", adminHandler.ExportUsers)
@@ -253,6 +240,13 @@ func SetupAdminRoutes(router *gin.Engine, adminHandler *handlers.AdminHandler, a
 		notifications.POST("/bulk/actions", adminHandler.BulkNotificationAction)
 	}
 
+	// Email Templates
+	emailTemplates := admin.Group("/email-templates")
+	{
+		emailTemplates.GET("", adminHandler.GetEmailTemplates)
+		emailTemplates.GET("/:type/preview", adminHandler.PreviewEmailTemplate)
+	}
+
 	// Analytics
 	analytics := admin.Group("/analytics")
 	{
@@ -335,7 +329,8 @@ func SetupPublicAdminRoutes(router *gin.Engine, adminHandler *handlers.AdminHand
 
 // WebSocket routes for real-time admin features
 func SetupAdminWebSocketRoutes(router *gin.Engine, adminHandler *handlers.AdminHandler, db *mongo.Database, jwtSecret, refreshSecret string) {
-	authMiddleware := middleware.NewAuthMiddleware(db, jwtSecret, refreshSecret)
+	// Delegated and impersonation tokens are never accepted on the admin socket
+	authMiddleware := middleware.NewAuthMiddleware(db, jwtSecret, refreshSecret, nil, nil)
 
 	ws := router.Group("/api/v1/admin/ws")
 	ws.Use(authMiddleware.RequireAuth())
@@ -0,0 +1,23 @@
+// internal/routes/analytics_export_routes.go
+package routes
+
+import (
+	"social-media-api/internal/handlers"
+	"social-media-api/internal/middleware"
+	"social-media-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAnalyticsExportRoutes sets up the admin routes for behavior analytics exports
+func SetupAnalyticsExportRoutes(router *gin.Engine, exportHandler *handlers.AnalyticsExportHandler, authMiddleware *middleware.AuthMiddleware) {
+	exports := router.Group("/api/v1/admin/analytics/exports")
+	exports.Use(authMiddleware.RequireAuth())
+	exports.Use(authMiddleware.RequireRole(models.RoleAdmin, models.RoleSuperAdmin))
+	{
+		exports.POST("", exportHandler.StartExport)
+		exports.GET("", exportHandler.GetExports)
+		exports.GET("/:id", exportHandler.GetExport)
+		exports.GET("/:id/files/:name", exportHandler.DownloadExportFile)
+	}
+}
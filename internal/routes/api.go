@@ -7,7 +7,9 @@ import (
 	"social-media-api/internal/config"
 	"social-media-api/internal/handlers"
 	"social-media-api/internal/middleware"
+	"social-media-api/internal/models"
 	"social-media-api/internal/services"
+	"social-media-api/internal/websocket"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -16,54 +18,100 @@ import (
 // APIRouter holds all route handlers and services
 type APIRouter struct {
 	// Handlers
-	AuthHandler         *handlers.AuthHandler
-	AdminHandler        *handlers.AdminHandler
-	UserHandler         *handlers.UserHandler
-	PostHandler         *handlers.PostHandler
-	CommentHandler      *handlers.CommentHandler
-	FollowHandler       *handlers.FollowHandler
-	MessageHandler      *handlers.MessageHandler
-	ConversationHandler *handlers.ConversationHandler
-	StoryHandler        *handlers.StoryHandler
-	GroupHandler        *handlers.GroupHandler
-	FeedHandler         *handlers.FeedHandler
-	SearchHandler       *handlers.SearchHandler
-	NotificationHandler *handlers.NotificationHandler
-	MediaHandler        *handlers.MediaHandler
-	LikeHandler         *handlers.LikeHandler
-	ReportHandler       *handlers.ReportHandler
-	BehaviorHandler     *handlers.UserBehaviorHandler
+	AuthHandler              *handlers.AuthHandler
+	AdminHandler             *handlers.AdminHandler
+	UserHandler              *handlers.UserHandler
+	PostHandler              *handlers.PostHandler
+	CommentHandler           *handlers.CommentHandler
+	FollowHandler            *handlers.FollowHandler
+	MessageHandler           *handlers.MessageHandler
+	ConversationHandler      *handlers.ConversationHandler
+	StoryHandler             *handlers.StoryHandler
+	GroupHandler             *handlers.GroupHandler
+	FeedHandler              *handlers.FeedHandler
+	SearchHandler            *handlers.SearchHandler
+	NotificationHandler      *handlers.NotificationHandler
+	MediaHandler             *handlers.MediaHandler
+	LikeHandler              *handlers.LikeHandler
+	ReportHandler            *handlers.ReportHandler
+	DelegationHandler        *handlers.DelegationHandler
+	WarningHandler           *handlers.WarningHandler
+	StatusHandler            *handlers.StatusHandler
+	ChallengeHandler         *handlers.ChallengeHandler
+	ContentCalendarHandler   *handlers.ContentCalendarHandler
+	AnalyticsExportHandler   *handlers.AnalyticsExportHandler
+	ModerationQueueHandler   *handlers.ModerationQueueHandler
+	HashtagHandler           *handlers.HashtagHandler
+	LinkBlocklistHandler     *handlers.LinkBlocklistHandler
+	EmailSuppressionHandler  *handlers.EmailSuppressionHandler
+	SavedSearchHandler       *handlers.SavedSearchHandler
+	ImpersonationHandler     *handlers.ImpersonationHandler
+	MentionSuggestionHandler *handlers.MentionSuggestionHandler
+	RetentionHandler         *handlers.RetentionHandler
+	BehaviorHandler          *handlers.UserBehaviorHandler
+	TranslationHandler       *handlers.TranslationHandler
+	SurveyHandler            *handlers.SurveyHandler
+	ReactionTypeHandler      *handlers.ReactionTypeHandler
+	SetupChecklistHandler    *handlers.SetupChecklistHandler
+	BoostedPostHandler       *handlers.BoostedPostHandler
+	LimitsHandler            *handlers.LimitsHandler
 	// Middleware
-	AuthMiddleware     *middleware.AuthMiddleware
-	BehaviorMiddleware *middleware.BehaviorTrackingMiddleware
-	DB                 *mongo.Database
-	JWTSecret          string
-	RefreshSecret      string
-	Services           *Services
+	AuthMiddleware      *middleware.AuthMiddleware
+	BehaviorMiddleware  *middleware.BehaviorTrackingMiddleware
+	ChallengeMiddleware *middleware.ChallengeMiddleware
+	AccountMaturity     models.AccountMaturityPolicies // Gates on new accounts, applied per route
+	DB                  *mongo.Database
+	JWTSecret           string
+	RefreshSecret       string
+	Services            *Services
 }
 
 // Services holds all service instances
 type Services struct {
-	AuthService         *services.AuthService
-	AdminService        *services.AdminService
-	UserService         *services.UserService
-	PostService         *services.PostService
-	CommentService      *services.CommentService
-	FollowService       *services.FollowService
-	MessageService      *services.MessageService
-	ConversationService *services.ConversationService
-	StoryService        *services.StoryService
-	GroupService        *services.GroupService
-	FeedService         *services.FeedService
-	SearchService       *services.SearchService
-	NotificationService *services.NotificationService
-	MediaService        *services.MediaService
-	LikeService         *services.LikeService
-	ReportService       *services.ReportService
-	EmailService        *services.EmailService
-	PushService         *services.PushService
-	BehaviorService     *services.UserBehaviorService // Added behavior service
-	AnalyticsService    *services.AnalyticsService
+	AuthService              *services.AuthService
+	AdminService             *services.AdminService
+	UserService              *services.UserService
+	PostService              *services.PostService
+	CommentService           *services.CommentService
+	FollowService            *services.FollowService
+	MessageService           *services.MessageService
+	ConversationService      *services.ConversationService
+	PresenceService          *services.PresenceService
+	ChatHub                  *websocket.Hub
+	StoryService             *services.StoryService
+	GroupService             *services.GroupService
+	GroupLibraryService      *services.GroupLibraryService
+	FeedService              *services.FeedService
+	BoostedPostService       *services.BoostedPostService
+	LimitsService            *services.LimitsService
+	SearchService            *services.SearchService
+	SavedSearchService       *services.SavedSearchService
+	ImpersonationService     *services.ImpersonationService
+	NotificationService      *services.NotificationService
+	MediaService             *services.MediaService
+	LikeService              *services.LikeService
+	ReportService            *services.ReportService
+	WarningService           *services.WarningService
+	StatusService            *services.StatusService
+	ChallengeService         *services.ChallengeService
+	ContentCalendarService   *services.ContentCalendarService
+	AnalyticsExportService   *services.AnalyticsExportService
+	ModerationQueueService   *services.ModerationQueueService
+	HashtagService           *services.HashtagService
+	LinkBlocklistService     *services.LinkBlocklistService
+	MentionSuggestionService *services.MentionSuggestionService
+	RetentionService         *services.RetentionService
+	DelegationService        *services.DelegationService
+	EmailService             *services.EmailService
+	DigestService            *services.DigestService
+	PushService              *services.PushService
+	BehaviorService          *services.UserBehaviorService // Added behavior service
+	AnalyticsService         *services.AnalyticsService
+	TranslationService       *services.TranslationService
+	SurveyService            *services.SurveyService
+	ReactionTypeService      *services.ReactionTypeService
+	SuggestionService        *services.SuggestionService
+	SetupChecklistService    *services.SetupChecklistService
 }
 
 // SetupRoutes initializes all routes for the API
@@ -86,17 +134,38 @@ func SetupRoutes(router *gin.Engine, apiRouter *APIRouter) {
 	router.GET("/api/v1", apiInfo)
 
 	// Setup all route groups
-	SetupAuthRoutes(router, apiRouter.AuthHandler, apiRouter.AuthMiddleware)
+	SetupAuthRoutes(router, apiRouter.AuthHandler, apiRouter.AuthMiddleware, apiRouter.ChallengeMiddleware)
 	SetupUserRoutes(router, apiRouter.UserHandler, apiRouter.AuthMiddleware)
-	SetupPostRoutes(router, apiRouter.PostHandler, apiRouter.AuthMiddleware)
-	SetupCommentRoutes(router, apiRouter.CommentHandler, apiRouter.AuthMiddleware)
+	SetupPostRoutes(router, apiRouter.PostHandler, apiRouter.AuthMiddleware, apiRouter.ChallengeMiddleware, apiRouter.AccountMaturity.Report)
+	SetupCommentRoutes(router, apiRouter.CommentHandler, apiRouter.AuthMiddleware, apiRouter.ChallengeMiddleware, apiRouter.AccountMaturity.Report)
+	SetupTranslationRoutes(router, apiRouter.TranslationHandler, apiRouter.AuthMiddleware)
 	SetupFollowRoutes(router, apiRouter.FollowHandler, apiRouter.AuthMiddleware)
 	SetupMessagingRoutes(router, apiRouter.MessageHandler, apiRouter.ConversationHandler, apiRouter.AuthMiddleware)
 	SetupStoryRoutes(router, apiRouter.StoryHandler, apiRouter.AuthMiddleware)
-	SetupGroupRoutes(router, apiRouter.GroupHandler, apiRouter.AuthMiddleware)
+	SetupGroupRoutes(router, apiRouter.GroupHandler, apiRouter.AuthMiddleware, apiRouter.AccountMaturity.CreateGroup)
 	SetupSocialRoutes(router, apiRouter.FeedHandler, apiRouter.SearchHandler, apiRouter.LikeHandler, apiRouter.AuthMiddleware)
 	SetupNotificationRoutes(router, apiRouter.NotificationHandler, apiRouter.AuthMiddleware)
 	SetupMediaRoutes(router, apiRouter.MediaHandler, apiRouter.AuthMiddleware)
+	SetupBehaviorRoutes(router, apiRouter.BehaviorHandler, *apiRouter.AuthMiddleware, apiRouter.BehaviorMiddleware)
+	SetupSurveyRoutes(router, apiRouter.SurveyHandler, apiRouter.AuthMiddleware)
+	SetupReactionTypeRoutes(router, apiRouter.ReactionTypeHandler, apiRouter.AuthMiddleware)
+	SetupSetupChecklistRoutes(router, apiRouter.SetupChecklistHandler, apiRouter.AuthMiddleware)
+	SetupBoostedPostRoutes(router, apiRouter.BoostedPostHandler, apiRouter.AuthMiddleware)
+	SetupLimitsRoutes(router, apiRouter.LimitsHandler, apiRouter.AuthMiddleware)
+	SetupDelegationRoutes(router, apiRouter.DelegationHandler, apiRouter.AuthMiddleware)
+	SetupWarningRoutes(router, apiRouter.WarningHandler, apiRouter.AuthMiddleware)
+	SetupStatusRoutes(router, apiRouter.StatusHandler, apiRouter.AuthMiddleware)
+	SetupChallengeRoutes(router, apiRouter.ChallengeHandler, apiRouter.AuthMiddleware)
+	SetupContentCalendarRoutes(router, apiRouter.ContentCalendarHandler, apiRouter.AuthMiddleware)
+	SetupAnalyticsExportRoutes(router, apiRouter.AnalyticsExportHandler, apiRouter.AuthMiddleware)
+	SetupModerationQueueRoutes(router, apiRouter.ModerationQueueHandler, apiRouter.AuthMiddleware)
+	SetupHashtagRoutes(router, apiRouter.HashtagHandler, apiRouter.AuthMiddleware)
+	SetupLinkBlocklistRoutes(router, apiRouter.LinkBlocklistHandler, apiRouter.AuthMiddleware)
+	SetupEmailSuppressionRoutes(router, apiRouter.EmailSuppressionHandler, apiRouter.AuthMiddleware)
+	SetupSavedSearchRoutes(router, apiRouter.SavedSearchHandler, apiRouter.AuthMiddleware)
+	SetupImpersonationRoutes(router, apiRouter.ImpersonationHandler, apiRouter.AuthMiddleware)
+	SetupMentionSuggestionRoutes(router, apiRouter.MentionSuggestionHandler, apiRouter.AuthMiddleware)
+	SetupRetentionRoutes(router, apiRouter.RetentionHandler, apiRouter.AuthMiddleware)
 	SetupPublicAdminRoutes(router, apiRouter.AdminHandler)
 	SetupAdminRoutes(router, apiRouter.AdminHandler, apiRouter.AuthMiddleware)
 	// SetupAdminWebSocketRoutes(router, apiRouter.AdminHandler, apiRouter.DB, apiRouter.JWTSecret, apiRouter.RefreshSecret)
@@ -144,6 +213,7 @@ func apiInfo(c *gin.Context) {
 			"media":         "/api/v1/media",
 			"reactions":     "/api/v1/reactions",
 			"reports":       "/api/v1/reports",
+			"behavior":      "/api/v1/behavior",
 			"admin":         "/api/v1/admin",
 		},
 		"features": []string{
@@ -163,29 +233,51 @@ func apiInfo(c *gin.Context) {
 }
 
 // NewAPIRouter creates a new API router with all dependencies
-func NewAPIRouter(services *Services, authMiddleware *middleware.AuthMiddleware, behaviorMiddleware *middleware.BehaviorTrackingMiddleware, db *mongo.Database, jwtSecret, refreshSecret string) *APIRouter {
+func NewAPIRouter(services *Services, authMiddleware *middleware.AuthMiddleware, behaviorMiddleware *middleware.BehaviorTrackingMiddleware, maturity models.AccountMaturityPolicies, db *mongo.Database, jwtSecret, refreshSecret string) *APIRouter {
 	return &APIRouter{
 		// Initialize handlers with their respective services
-		AuthHandler:         handlers.NewAuthHandler(services.AuthService, services.UserService),
-		UserHandler:         handlers.NewUserHandler(services.UserService),
-		PostHandler:         handlers.NewPostHandler(services.PostService),
-		CommentHandler:      handlers.NewCommentHandler(services.CommentService),
-		FollowHandler:       handlers.NewFollowHandler(services.FollowService),
-		MessageHandler:      handlers.NewMessageHandler(services.MessageService, services.ConversationService, nil), // WebSocket hub would be injected here
-		ConversationHandler: handlers.NewConversationHandler(services.ConversationService, services.MessageService, services.NotificationService),
-		StoryHandler:        handlers.NewStoryHandler(services.StoryService),
-		GroupHandler:        handlers.NewGroupHandler(services.GroupService),
-		FeedHandler:         handlers.NewFeedHandler(services.FeedService, services.BehaviorService),
-		SearchHandler:       handlers.NewSearchHandler(services.SearchService),
-		NotificationHandler: handlers.NewNotificationHandler(services.NotificationService),
-		MediaHandler:        handlers.NewMediaHandler(services.MediaService),
-		LikeHandler:         handlers.NewLikeHandler(services.LikeService),
-		ReportHandler:       handlers.NewReportHandler(services.ReportService),
-		BehaviorHandler:     handlers.NewUserBehaviorHandler(services.BehaviorService, services.AnalyticsService),
+		AuthHandler:              handlers.NewAuthHandler(services.AuthService, services.UserService, services.FollowService, services.PushService),
+		UserHandler:              handlers.NewUserHandler(services.UserService, services.SuggestionService),
+		PostHandler:              handlers.NewPostHandler(services.PostService),
+		CommentHandler:           handlers.NewCommentHandler(services.CommentService),
+		FollowHandler:            handlers.NewFollowHandler(services.FollowService),
+		MessageHandler:           handlers.NewMessageHandler(services.MessageService, services.ConversationService, services.ChatHub),
+		ConversationHandler:      handlers.NewConversationHandler(services.ConversationService, services.MessageService, services.NotificationService),
+		StoryHandler:             handlers.NewStoryHandler(services.StoryService),
+		GroupHandler:             handlers.NewGroupHandler(services.GroupService, services.GroupLibraryService),
+		FeedHandler:              handlers.NewFeedHandler(services.FeedService, services.BehaviorService),
+		SearchHandler:            handlers.NewSearchHandler(services.SearchService),
+		NotificationHandler:      handlers.NewNotificationHandler(services.NotificationService),
+		MediaHandler:             handlers.NewMediaHandler(services.MediaService),
+		LikeHandler:              handlers.NewLikeHandler(services.LikeService),
+		ReportHandler:            handlers.NewReportHandler(services.ReportService),
+		DelegationHandler:        handlers.NewDelegationHandler(services.DelegationService),
+		WarningHandler:           handlers.NewWarningHandler(services.WarningService),
+		StatusHandler:            handlers.NewStatusHandler(services.StatusService),
+		ChallengeHandler:         handlers.NewChallengeHandler(services.ChallengeService),
+		ContentCalendarHandler:   handlers.NewContentCalendarHandler(services.ContentCalendarService),
+		AnalyticsExportHandler:   handlers.NewAnalyticsExportHandler(services.AnalyticsExportService),
+		ModerationQueueHandler:   handlers.NewModerationQueueHandler(services.ModerationQueueService),
+		HashtagHandler:           handlers.NewHashtagHandler(services.HashtagService),
+		LinkBlocklistHandler:     handlers.NewLinkBlocklistHandler(services.LinkBlocklistService),
+		EmailSuppressionHandler:  handlers.NewEmailSuppressionHandler(services.EmailService),
+		SavedSearchHandler:       handlers.NewSavedSearchHandler(services.SavedSearchService),
+		ImpersonationHandler:     handlers.NewImpersonationHandler(services.ImpersonationService),
+		MentionSuggestionHandler: handlers.NewMentionSuggestionHandler(services.MentionSuggestionService),
+		RetentionHandler:         handlers.NewRetentionHandler(services.RetentionService),
+		BehaviorHandler:          handlers.NewUserBehaviorHandler(services.BehaviorService, services.AnalyticsService),
+		TranslationHandler:       handlers.NewTranslationHandler(services.TranslationService),
+		SurveyHandler:            handlers.NewSurveyHandler(services.SurveyService),
+		ReactionTypeHandler:      handlers.NewReactionTypeHandler(services.ReactionTypeService),
+		SetupChecklistHandler:    handlers.NewSetupChecklistHandler(services.SetupChecklistService),
+		BoostedPostHandler:       handlers.NewBoostedPostHandler(services.BoostedPostService),
+		LimitsHandler:            handlers.NewLimitsHandler(services.LimitsService),
 		// Middleware
-		AuthMiddleware:     authMiddleware,
-		BehaviorMiddleware: behaviorMiddleware,
-		AdminHandler:       handlers.NewAdminHandler(services.AdminService, services.AuthService, db),
-		Services:           services,
+		AuthMiddleware:      authMiddleware,
+		BehaviorMiddleware:  behaviorMiddleware,
+		ChallengeMiddleware: middleware.NewChallengeMiddleware(services.ChallengeService),
+		AccountMaturity:     maturity,
+		AdminHandler:        handlers.NewAdminHandler(services.AdminService, services.AuthService, services.EmailService, services.ReportService, db),
+		Services:            services,
 	}
 }
@@ -4,12 +4,13 @@ package routes
 import (
 	"social-media-api/internal/handlers"
 	"social-media-api/internal/middleware"
+	"social-media-api/internal/models"
 
 	"github.com/gin-gonic/gin"
 )
 
 // SetupAuthRoutes sets up authentication and user profile routes
-func SetupAuthRoutes(router *gin.Engine, authHandler *handlers.AuthHandler, authMiddleware *middleware.AuthMiddleware) {
+func SetupAuthRoutes(router *gin.Engine, authHandler *handlers.AuthHandler, authMiddleware *middleware.AuthMiddleware, challenges *middleware.ChallengeMiddleware) {
 	// Public auth routes (no authentication required)
 	auth := router.Group("/api/v1/auth")
 	{
@@ -17,8 +18,8 @@ func SetupAuthRoutes(router *gin.Engine, authHandler *handlers.AuthHandler, auth
 		auth.Use(middleware.LoginRateLimit())
 
 		// Authentication endpoints
-		auth.POST("/register", authHandler.Register)
-		auth.POST("/login", authHandler.Login)
+		auth.POST("/register", challenges.Require(models.ChallengeGroupRegister), authHandler.Register)
+		auth.POST("/login", challenges.Require(models.ChallengeGroupLogin), authHandler.Login)
 		auth.POST("/refresh", authHandler.RefreshToken)
 		auth.POST("/forgot-password", authHandler.ForgotPassword)
 		auth.POST("/reset-password", authHandler.ResetPassword)
@@ -39,5 +40,14 @@ func SetupAuthRoutes(router *gin.Engine, authHandler *handlers.AuthHandler, auth
 		authProtected.DELETE("/sessions/:sessionId", authHandler.RevokeSession)
 		authProtected.POST("/logout", authHandler.Logout)
 		authProtected.POST("/logout-all", authHandler.LogoutAll)
+
+		// Account switcher: several accounts signed in on one device
+		authProtected.POST("/add-account", middleware.LoginRateLimit(), authHandler.AddAccount)
+		authProtected.GET("/accounts", authHandler.GetDeviceAccounts)
+		authProtected.POST("/switch/:userId", middleware.AccountSwitchRateLimit(), authHandler.SwitchAccount)
+
+		// Push tokens are registered per account and device
+		authProtected.POST("/push-token", authHandler.RegisterPushToken)
+		authProtected.DELETE("/push-token", authHandler.RemovePushToken)
 	}
 }
@@ -34,10 +34,12 @@ func SetupBehaviorRoutes(router *gin.Engine, behaviorHandler *handlers.UserBehav
 		// Content Engagement
 		behaviorRoutes.POST("/content-engagement", behaviorHandler.TrackContentEngagement)
 		behaviorRoutes.GET("/content/:contentId/interest-score", behaviorHandler.GetInterestScore)
+		behaviorRoutes.POST("/impressions", middleware.ImpressionRateLimit(), behaviorHandler.RecordImpressions)
 
 		// Analytics and Insights
 		behaviorRoutes.GET("/analytics", behaviorHandler.GetUserBehaviorAnalytics)
 		behaviorRoutes.GET("/insights", behaviorHandler.GetBehaviorInsights)
+		behaviorRoutes.GET("/activity", behaviorHandler.GetMyInsights)
 		behaviorRoutes.GET("/preferences", behaviorHandler.GetUserContentPreferences)
 
 		// Social Intelligence
@@ -50,11 +52,23 @@ func SetupBehaviorRoutes(router *gin.Engine, behaviorHandler *handlers.UserBehav
 		behaviorRoutes.POST("/experiments", behaviorHandler.TrackExperiment)
 	}
 
+	// Creator insights
+	creatorInsights := router.Group("/api/v1/users/me/insights")
+	creatorInsights.Use(authMiddleware.RequireAuth())
+	{
+		creatorInsights.GET("/audience-activity", behaviorHandler.GetAudienceActivity)
+		creatorInsights.GET("/summary", behaviorHandler.GetMyCreatorSummary)
+	}
+
 	// Admin behavior routes (for platform analytics)
 	adminBehaviorRoutes := router.Group("/api/v1/admin/behavior")
 	adminBehaviorRoutes.Use(authMiddleware.RequireAuth())
 	adminBehaviorRoutes.Use(authMiddleware.RequireRole("admin"))
 	{
+		adminBehaviorRoutes.GET("/translation-usage", behaviorHandler.GetTranslationUsage)
+		adminBehaviorRoutes.GET("/users/:id/insights", behaviorHandler.GetUserInsights)
+		adminBehaviorRoutes.GET("/users/:id/creator-summary", behaviorHandler.GetCreatorSummary)
+
 		// Platform-wide behavior analytics would go here
 		// adminBehaviorRoutes.GET("/platform-analytics", behaviorHandler.GetPlatformBehaviorAnalytics)
 		// adminBehaviorRoutes.GET("/user-segments", behaviorHandler.GetUserSegments)
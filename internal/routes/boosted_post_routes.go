@@ -0,0 +1,25 @@
+// internal/routes/boosted_post_routes.go
+package routes
+
+import (
+	"social-media-api/internal/handlers"
+	"social-media-api/internal/middleware"
+	"social-media-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupBoostedPostRoutes sets up admin boost campaign routes
+func SetupBoostedPostRoutes(router *gin.Engine, boostedPostHandler *handlers.BoostedPostHandler, authMiddleware *middleware.AuthMiddleware) {
+	adminBoosts := router.Group("/api/v1/admin/boosted-posts")
+	adminBoosts.Use(authMiddleware.RequireAuth())
+	adminBoosts.Use(authMiddleware.RequireRole(models.RoleAdmin, models.RoleSuperAdmin))
+	{
+		adminBoosts.GET("", boostedPostHandler.GetBoostedPosts)
+		adminBoosts.POST("", boostedPostHandler.CreateBoostedPost)
+		adminBoosts.GET("/:id", boostedPostHandler.GetBoostedPost)
+		adminBoosts.PUT("/:id", boostedPostHandler.UpdateBoostedPost)
+		adminBoosts.DELETE("/:id", boostedPostHandler.DeleteBoostedPost)
+		adminBoosts.GET("/:id/performance", boostedPostHandler.GetBoostPerformance)
+	}
+}
@@ -0,0 +1,21 @@
+// internal/routes/challenge_routes.go
+package routes
+
+import (
+	"social-media-api/internal/handlers"
+	"social-media-api/internal/middleware"
+	"social-media-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupChallengeRoutes sets up the admin routes for proof-of-work challenges
+func SetupChallengeRoutes(router *gin.Engine, challengeHandler *handlers.ChallengeHandler, authMiddleware *middleware.AuthMiddleware) {
+	challenges := router.Group("/api/v1/admin/challenges")
+	challenges.Use(authMiddleware.RequireAuth())
+	challenges.Use(authMiddleware.RequireRole(models.RoleAdmin, models.RoleSuperAdmin))
+	{
+		challenges.GET("", challengeHandler.GetChallengeStatus)
+		challenges.PUT("/:group", challengeHandler.UpdateChallengeGroup)
+	}
+}
@@ -4,12 +4,13 @@ package routes
 import (
 	"social-media-api/internal/handlers"
 	"social-media-api/internal/middleware"
+	"social-media-api/internal/models"
 
 	"github.com/gin-gonic/gin"
 )
 
 // SetupCommentRoutes sets up comment-related routes
-func SetupCommentRoutes(router *gin.Engine, commentHandler *handlers.CommentHandler, authMiddleware *middleware.AuthMiddleware) {
+func SetupCommentRoutes(router *gin.Engine, commentHandler *handlers.CommentHandler, authMiddleware *middleware.AuthMiddleware, challenges *middleware.ChallengeMiddleware, reportMaturity models.AccountMaturityPolicy) {
 	// Public comment routes
 	comments := router.Group("/api/v1/comments")
 	{
@@ -25,14 +26,19 @@ func SetupCommentRoutes(router *gin.Engine, commentHandler *handlers.CommentHand
 	commentsProtected.Use(authMiddleware.RequireAuth())
 	{
 		// Comment creation and management
-		commentsProtected.POST("/", middleware.CommentRateLimit(), commentHandler.CreateComment)
+		commentsProtected.POST("/", challenges.Require(models.ChallengeGroupComments), commentHandler.CreateComment)
 		commentsProtected.PUT("/:id", commentHandler.UpdateComment)
 		commentsProtected.DELETE("/:id", commentHandler.DeleteComment)
 
+		// Bulk deleting one's own comments: preview the count, then confirm with it
+		commentsProtected.POST("/bulk-delete/preview", commentHandler.PreviewBulkDeleteComments)
+		commentsProtected.POST("/bulk-delete", commentHandler.BulkDeleteComments)
+		commentsProtected.GET("/bulk-delete/:jobId", commentHandler.GetBulkDeleteCommentsJob)
+
 		// Comment interactions
 		commentsProtected.POST("/:id/like", middleware.LikeRateLimit(), commentHandler.LikeComment)
 		commentsProtected.DELETE("/:id/like", commentHandler.UnlikeComment)
-		commentsProtected.POST("/:id/report", commentHandler.ReportComment)
+		commentsProtected.POST("/:id/report", middleware.RequireAccountMaturity(reportMaturity), commentHandler.ReportComment)
 
 		// Comment moderation (post author only)
 		commentsProtected.POST("/:id/pin", commentHandler.PinComment)
@@ -47,5 +53,17 @@ func SetupCommentRoutes(router *gin.Engine, commentHandler *handlers.CommentHand
 	{
 		// Public comment viewing
 		postComments.GET("/", authMiddleware.OptionalAuth(), commentHandler.GetPostComments)
+		postComments.GET("/quick", authMiddleware.OptionalAuth(), commentHandler.GetQuickReplies)
+	}
+
+	// Spam hold review queue (moderators)
+	heldComments := router.Group("/api/v1/moderation/comments")
+	heldComments.Use(authMiddleware.RequireAuth())
+	heldComments.Use(authMiddleware.RequireRole(models.RoleModerator, models.RoleAdmin, models.RoleSuperAdmin))
+	{
+		heldComments.GET("/held", commentHandler.GetHeldComments)
+		heldComments.GET("/held/stats", commentHandler.GetCommentHoldStats)
+		heldComments.POST("/:id/approve", middleware.ValidateObjectID("id"), commentHandler.ApproveHeldComment)
+		heldComments.POST("/:id/reject", middleware.ValidateObjectID("id"), commentHandler.RejectHeldComment)
 	}
 }
@@ -0,0 +1,18 @@
+// internal/routes/content_calendar_routes.go
+package routes
+
+import (
+	"social-media-api/internal/handlers"
+	"social-media-api/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupContentCalendarRoutes sets up the creator content calendar routes
+func SetupContentCalendarRoutes(router *gin.Engine, calendarHandler *handlers.ContentCalendarHandler, authMiddleware *middleware.AuthMiddleware) {
+	calendar := router.Group("/api/v1/users/me")
+	calendar.Use(authMiddleware.RequireAuth())
+	{
+		calendar.GET("/content-calendar", calendarHandler.GetContentCalendar)
+	}
+}
@@ -0,0 +1,29 @@
+// internal/routes/delegation_routes.go
+package routes
+
+import (
+	"social-media-api/internal/handlers"
+	"social-media-api/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupDelegationRoutes sets up delegate management and account switching routes
+func SetupDelegationRoutes(router *gin.Engine, delegationHandler *handlers.DelegationHandler, authMiddleware *middleware.AuthMiddleware) {
+	router.POST("/api/v1/auth/switch-account/:accountId", authMiddleware.RequireAuth(), delegationHandler.SwitchAccount)
+
+	delegates := router.Group("/api/v1/delegates")
+	delegates.Use(authMiddleware.RequireAuth())
+	{
+		// Account owner
+		delegates.POST("", delegationHandler.InviteDelegate)
+		delegates.GET("", delegationHandler.GetDelegates)
+		delegates.PUT("/:id/role", delegationHandler.UpdateDelegateRole)
+		delegates.DELETE("/:id", delegationHandler.RevokeDelegate)
+
+		// Delegate
+		delegates.GET("/managed", delegationHandler.GetManagedAccounts)
+		delegates.POST("/invitations/:id/accept", delegationHandler.AcceptInvitation)
+		delegates.POST("/invitations/:id/decline", delegationHandler.DeclineInvitation)
+	}
+}
@@ -0,0 +1,31 @@
+// internal/routes/email_suppression_routes.go
+package routes
+
+import (
+	"social-media-api/internal/handlers"
+	"social-media-api/internal/middleware"
+	"social-media-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupEmailSuppressionRoutes sets up the public unsubscribe links and the
+// admin routes managing the email suppression list
+func SetupEmailSuppressionRoutes(router *gin.Engine, emailSuppressionHandler *handlers.EmailSuppressionHandler, authMiddleware *middleware.AuthMiddleware) {
+	// Public; the signed token identifies the address
+	unsubscribe := router.Group("/api/v1/email/unsubscribe")
+	unsubscribe.Use(middleware.CORS())
+	{
+		unsubscribe.GET("", emailSuppressionHandler.GetUnsubscribeStatus)
+		unsubscribe.POST("", emailSuppressionHandler.Unsubscribe)
+	}
+
+	suppressions := router.Group("/api/v1/admin/email-suppressions")
+	suppressions.Use(authMiddleware.RequireAuth())
+	suppressions.Use(authMiddleware.RequireRole(models.RoleAdmin, models.RoleSuperAdmin))
+	{
+		suppressions.GET("", emailSuppressionHandler.GetSuppressions)
+		suppressions.POST("", emailSuppressionHandler.AddSuppression)
+		suppressions.DELETE("/:id", middleware.ValidateObjectID("id"), emailSuppressionHandler.RemoveSuppression)
+	}
+}
@@ -38,7 +38,9 @@ func SetupFollowRoutes(router *gin.Engine, followHandler *handlers.FollowHandler
 
 		// Follow discovery and suggestions
 		followsProtected.GET("/suggested-users", followHandler.GetSuggestedUsers)
-		followsProtected.POST("/bulk-follow", followHandler.BulkFollowUsers)
+		followsProtected.GET("/onboarding-suggestions", followHandler.GetOnboardingSuggestions)
+		followsProtected.POST("/follow-many", middleware.FollowManyRateLimit(), followHandler.FollowMany)
+		followsProtected.POST("/bulk-follow", middleware.FollowManyRateLimit(), followHandler.FollowMany)
 
 		// Follow activity
 		followsProtected.GET("/follow-activity", followHandler.GetFollowActivity)
@@ -4,12 +4,13 @@ package routes
 import (
 	"social-media-api/internal/handlers"
 	"social-media-api/internal/middleware"
+	"social-media-api/internal/models"
 
 	"github.com/gin-gonic/gin"
 )
 
 // SetupGroupRoutes sets up group-related routes
-func SetupGroupRoutes(router *gin.Engine, groupHandler *handlers.GroupHandler, authMiddleware *middleware.AuthMiddleware) {
+func SetupGroupRoutes(router *gin.Engine, groupHandler *handlers.GroupHandler, authMiddleware *middleware.AuthMiddleware, createMaturity models.AccountMaturityPolicy) {
 	// Public group routes
 	groups := router.Group("/api/v1/groups")
 	{
@@ -20,6 +21,12 @@ func SetupGroupRoutes(router *gin.Engine, groupHandler *handlers.GroupHandler, a
 		groups.GET("/categories", groupHandler.GetGroupCategories)
 		groups.GET("/:id", authMiddleware.OptionalAuth(), groupHandler.GetGroup)
 		groups.GET("/:id/members", authMiddleware.OptionalAuth(), groupHandler.GetGroupMembers)
+		groups.GET("/:id/posts", authMiddleware.OptionalAuth(), groupHandler.GetGroupPosts)
+
+		// Resource library (members only for private and secret groups)
+		groups.GET("/:id/library", authMiddleware.OptionalAuth(), groupHandler.GetLibraryFiles)
+		groups.GET("/:id/library/folders", authMiddleware.OptionalAuth(), groupHandler.GetLibraryFolders)
+		groups.GET("/:id/library/:file_id/download", authMiddleware.OptionalAuth(), groupHandler.DownloadLibraryFile)
 	}
 
 	// Protected group routes
@@ -27,7 +34,7 @@ func SetupGroupRoutes(router *gin.Engine, groupHandler *handlers.GroupHandler, a
 	groupsProtected.Use(authMiddleware.RequireAuth())
 	{
 		// Group creation and management
-		groupsProtected.POST("/", groupHandler.CreateGroup)
+		groupsProtected.POST("/", middleware.RequireAccountMaturity(createMaturity), groupHandler.CreateGroup)
 		groupsProtected.PUT("/:id", groupHandler.UpdateGroup)
 		groupsProtected.DELETE("/:id", groupHandler.DeleteGroup)
 
@@ -35,12 +42,28 @@ func SetupGroupRoutes(router *gin.Engine, groupHandler *handlers.GroupHandler, a
 		groupsProtected.POST("/:id/join", groupHandler.JoinGroup)
 		groupsProtected.POST("/:id/leave", groupHandler.LeaveGroup)
 		groupsProtected.POST("/:id/invite", groupHandler.InviteToGroup)
+		groupsProtected.PUT("/:id/notification-settings", groupHandler.UpdateNotificationSettings)
+
+		// Invite links
+		groupsProtected.POST("/:id/invite-links", groupHandler.CreateInviteLink)
+		groupsProtected.GET("/:id/invite-links", groupHandler.GetInviteLinks)
+		groupsProtected.DELETE("/:id/invite-links/:link_id", groupHandler.RevokeInviteLink)
 
 		// Member management (admin/moderator only)
 		groupsProtected.PUT("/:id/members/:member_id/role", groupHandler.UpdateMemberRole)
 		groupsProtected.DELETE("/:id/members/:member_id", groupHandler.RemoveGroupMember)
 		groupsProtected.POST("/:id/members/bulk-remove", groupHandler.BulkRemoveMembers)
 
+		// Post approval (admin/moderator only)
+		groupsProtected.GET("/:id/posts/pending", groupHandler.GetPendingPosts)
+		groupsProtected.POST("/:id/posts/:post_id/approve", groupHandler.ApprovePost)
+		groupsProtected.POST("/:id/posts/:post_id/reject", groupHandler.RejectPost)
+
+		// Resource library management
+		groupsProtected.POST("/:id/library", groupHandler.UploadLibraryFile)
+		groupsProtected.PUT("/:id/library/:file_id", groupHandler.UpdateLibraryFile)
+		groupsProtected.DELETE("/:id/library/:file_id", groupHandler.DeleteLibraryFile)
+
 		// Group statistics (admin/moderator only)
 		groupsProtected.GET("/:id/stats", groupHandler.GetGroupStats)
 
@@ -55,5 +78,6 @@ func SetupGroupRoutes(router *gin.Engine, groupHandler *handlers.GroupHandler, a
 	{
 		groupInvites.POST("/:invite_id/accept", groupHandler.AcceptGroupInvite)
 		groupInvites.POST("/:invite_id/reject", groupHandler.RejectGroupInvite)
+		groupInvites.POST("/links/:token/join", groupHandler.JoinViaInvite)
 	}
 }
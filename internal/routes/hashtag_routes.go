@@ -0,0 +1,31 @@
+// internal/routes/hashtag_routes.go
+package routes
+
+import (
+	"social-media-api/internal/handlers"
+	"social-media-api/internal/middleware"
+	"social-media-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupHashtagRoutes sets up the hashtag page routes, where signing in
+// personalizes the results, and the admin hashtag category routes
+func SetupHashtagRoutes(router *gin.Engine, hashtagHandler *handlers.HashtagHandler, authMiddleware *middleware.AuthMiddleware) {
+	hashtags := router.Group("/api/v1/hashtags")
+	hashtags.Use(authMiddleware.OptionalAuth())
+	{
+		hashtags.GET("/:tag", hashtagHandler.GetHashtag)
+		hashtags.GET("/:tag/posts", hashtagHandler.GetHashtagPosts)
+	}
+
+	// Category overrides and recategorization, next to the admin hashtag management routes
+	adminHashtags := router.Group("/api/v1/admin/hashtags")
+	adminHashtags.Use(authMiddleware.RequireAuth())
+	adminHashtags.Use(authMiddleware.RequireRole(models.RoleAdmin, models.RoleSuperAdmin))
+	{
+		adminHashtags.POST("/recategorize", hashtagHandler.RecategorizeHashtags)
+		adminHashtags.PUT("/:id/category", middleware.ValidateObjectID("id"), hashtagHandler.SetHashtagCategory)
+		adminHashtags.DELETE("/:id/category", middleware.ValidateObjectID("id"), hashtagHandler.ResetHashtagCategory)
+	}
+}
@@ -0,0 +1,22 @@
+// internal/routes/impersonation_routes.go
+package routes
+
+import (
+	"social-media-api/internal/handlers"
+	"social-media-api/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupImpersonationRoutes sets up support impersonation routes. Starting a
+// session takes a super admin; it is ended with the impersonation token.
+func SetupImpersonationRoutes(router *gin.Engine, impersonationHandler *handlers.ImpersonationHandler, authMiddleware *middleware.AuthMiddleware) {
+	router.POST("/api/v1/admin/users/:id/impersonate",
+		authMiddleware.RequireAuth(),
+		requireSuperAdminRole(),
+		middleware.ValidateObjectID("id"),
+		impersonationHandler.StartImpersonation,
+	)
+
+	router.POST("/api/v1/impersonation/end", authMiddleware.RequireAuth(), impersonationHandler.EndImpersonation)
+}
@@ -0,0 +1,23 @@
+// internal/routes/limits_routes.go
+package routes
+
+import (
+	"social-media-api/internal/handlers"
+	"social-media-api/internal/middleware"
+	"social-media-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupLimitsRoutes sets up the caller's limits and the admin tier limit routes
+func SetupLimitsRoutes(router *gin.Engine, limitsHandler *handlers.LimitsHandler, authMiddleware *middleware.AuthMiddleware) {
+	router.GET("/api/v1/users/me/limits", authMiddleware.RequireAuth(), limitsHandler.GetMyLimits)
+
+	adminLimits := router.Group("/api/v1/admin/limits")
+	adminLimits.Use(authMiddleware.RequireAuth())
+	adminLimits.Use(authMiddleware.RequireRole(models.RoleAdmin, models.RoleSuperAdmin))
+	{
+		adminLimits.GET("", limitsHandler.GetTierLimits)
+		adminLimits.PUT("/:tier", limitsHandler.UpdateTierLimits)
+	}
+}
@@ -0,0 +1,25 @@
+// internal/routes/link_blocklist_routes.go
+package routes
+
+import (
+	"social-media-api/internal/handlers"
+	"social-media-api/internal/middleware"
+	"social-media-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupLinkBlocklistRoutes sets up the admin routes managing the link
+// blocklist and reviewing what it blocked
+func SetupLinkBlocklistRoutes(router *gin.Engine, linkBlocklistHandler *handlers.LinkBlocklistHandler, authMiddleware *middleware.AuthMiddleware) {
+	blocklist := router.Group("/api/v1/admin/link-blocklist")
+	blocklist.Use(authMiddleware.RequireAuth())
+	blocklist.Use(authMiddleware.RequireRole(models.RoleAdmin, models.RoleSuperAdmin))
+	{
+		blocklist.GET("", linkBlocklistHandler.GetBlockedDomains)
+		blocklist.POST("", linkBlocklistHandler.AddBlockedDomain)
+		blocklist.GET("/log", linkBlocklistHandler.GetBlockLog)
+		blocklist.PUT("/:id", middleware.ValidateObjectID("id"), linkBlocklistHandler.UpdateBlockedDomain)
+		blocklist.DELETE("/:id", middleware.ValidateObjectID("id"), linkBlocklistHandler.RemoveBlockedDomain)
+	}
+}
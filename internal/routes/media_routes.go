@@ -4,6 +4,7 @@ package routes
 import (
 	"social-media-api/internal/handlers"
 	"social-media-api/internal/middleware"
+	"social-media-api/internal/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -34,5 +35,19 @@ func SetupMediaRoutes(router *gin.Engine, mediaHandler *handlers.MediaHandler, a
 
 		// Media statistics
 		mediaProtected.GET("/stats", mediaHandler.GetMediaStats)
+
+		// Accessibility
+		mediaProtected.GET("/missing-alt-text", mediaHandler.GetMediaMissingAltText)
+
+		// Duplicate and similar images
+		mediaProtected.GET("/duplicates", mediaHandler.GetDuplicateMedia)
+	}
+
+	// Storage tiering statistics (admin only)
+	adminMedia := router.Group("/api/v1/admin/media")
+	adminMedia.Use(authMiddleware.RequireAuth())
+	adminMedia.Use(authMiddleware.RequireRole(models.RoleAdmin, models.RoleSuperAdmin))
+	{
+		adminMedia.GET("/storage-tiers", mediaHandler.GetStorageTierStats)
 	}
 }
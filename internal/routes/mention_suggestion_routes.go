@@ -0,0 +1,18 @@
+// internal/routes/mention_suggestion_routes.go
+package routes
+
+import (
+	"social-media-api/internal/handlers"
+	"social-media-api/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupMentionSuggestionRoutes sets up @-mention autocomplete for comments
+func SetupMentionSuggestionRoutes(router *gin.Engine, mentionSuggestionHandler *handlers.MentionSuggestionHandler, authMiddleware *middleware.AuthMiddleware) {
+	posts := router.Group("/api/v1/posts")
+	posts.Use(authMiddleware.RequireAuth())
+	{
+		posts.GET("/:id/mention-suggestions", middleware.ValidateObjectID("id"), middleware.MentionSuggestionRateLimit(), mentionSuggestionHandler.GetMentionSuggestions)
+	}
+}
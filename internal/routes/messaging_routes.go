@@ -22,6 +22,12 @@ func SetupMessagingRoutes(router *gin.Engine, messageHandler *handlers.MessageHa
 			conversations.GET("/", conversationHandler.GetUserConversations)
 			conversations.GET("/search", conversationHandler.SearchConversations)
 			conversations.GET("/unread-counts", conversationHandler.GetUnreadCounts)
+
+			// Message requests
+			conversations.GET("/requests", conversationHandler.GetMessageRequests)
+			conversations.POST("/requests/:id/accept", conversationHandler.AcceptMessageRequest)
+			conversations.POST("/requests/:id/decline", conversationHandler.DeclineMessageRequest)
+
 			conversations.GET("/:id", conversationHandler.GetConversation)
 			conversations.PUT("/:id", conversationHandler.UpdateConversation)
 			conversations.DELETE("/:id/leave", conversationHandler.LeaveConversation)
@@ -30,6 +36,7 @@ func SetupMessagingRoutes(router *gin.Engine, messageHandler *handlers.MessageHa
 			conversations.GET("/:id/stats", conversationHandler.GetConversationStats)
 
 			// Participant management
+			conversations.GET("/:id/participants", conversationHandler.GetParticipants)
 			conversations.POST("/:id/participants", conversationHandler.AddParticipants)
 			conversations.DELETE("/:id/participants/:participantId", conversationHandler.RemoveParticipant)
 			conversations.PUT("/:id/participants/:participantId/role", conversationHandler.UpdateParticipantRole)
@@ -37,6 +44,8 @@ func SetupMessagingRoutes(router *gin.Engine, messageHandler *handlers.MessageHa
 			// Conversation settings
 			conversations.PUT("/:id/mute", conversationHandler.MuteConversation)
 			conversations.PUT("/:id/archive", conversationHandler.ArchiveConversation)
+			conversations.PUT("/:id/pin", conversationHandler.PinConversation)
+			conversations.GET("/:id/export", middleware.ConversationExportRateLimit(), conversationHandler.ExportConversation)
 
 			// Messages within conversations - RESTRUCTURED to avoid conflicts
 			conversations.GET("/:id/messages", conversationHandler.GetConversationMessages)
@@ -44,6 +53,9 @@ func SetupMessagingRoutes(router *gin.Engine, messageHandler *handlers.MessageHa
 			conversations.POST("/:id/mark-read", conversationHandler.MarkAsRead)
 		}
 
+		// Chat WebSocket for real-time messages, typing and presence events
+		messaging.GET("/ws", messageHandler.ConnectWebSocket)
+
 		// Individual message management - FIXED: Removed conflicting routes
 		messages := messaging.Group("/messages")
 		{
@@ -53,6 +65,9 @@ func SetupMessagingRoutes(router *gin.Engine, messageHandler *handlers.MessageHa
 			messages.DELETE("/:id", messageHandler.DeleteMessage)      // Delete single message
 			messages.POST("/:id/react", messageHandler.ReactToMessage) // React to single message
 
+			// Forward a message into other conversations the user is in
+			messages.POST("/:id/forward", middleware.MessageRateLimit(), messageHandler.ForwardMessage)
+
 			// Global message operations (not conversation-specific)
 			messages.GET("/search", messageHandler.SearchMessages) // Search across all messages
 			messages.GET("/stats", messageHandler.GetMessageStats) // User's message statistics
@@ -0,0 +1,22 @@
+// internal/routes/moderation_queue_routes.go
+package routes
+
+import (
+	"social-media-api/internal/handlers"
+	"social-media-api/internal/middleware"
+	"social-media-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupModerationQueueRoutes sets up the unified moderation queue, open to moderators as well as admins
+func SetupModerationQueueRoutes(router *gin.Engine, queueHandler *handlers.ModerationQueueHandler, authMiddleware *middleware.AuthMiddleware) {
+	queue := router.Group("/api/v1/admin/moderation/queue")
+	queue.Use(authMiddleware.RequireAuth())
+	queue.Use(authMiddleware.RequireRole(models.RoleModerator, models.RoleAdmin, models.RoleSuperAdmin))
+	{
+		queue.GET("", queueHandler.GetQueue)
+		queue.POST("/:type/:id/claim", middleware.ValidateObjectID("id"), queueHandler.ClaimItem)
+		queue.DELETE("/:type/:id/claim", middleware.ValidateObjectID("id"), queueHandler.ReleaseItem)
+	}
+}
@@ -4,12 +4,13 @@ package routes
 import (
 	"social-media-api/internal/handlers"
 	"social-media-api/internal/middleware"
+	"social-media-api/internal/models"
 
 	"github.com/gin-gonic/gin"
 )
 
 // SetupPostRoutes sets up post-related routes
-func SetupPostRoutes(router *gin.Engine, postHandler *handlers.PostHandler, authMiddleware *middleware.AuthMiddleware) {
+func SetupPostRoutes(router *gin.Engine, postHandler *handlers.PostHandler, authMiddleware *middleware.AuthMiddleware, challenges *middleware.ChallengeMiddleware, reportMaturity models.AccountMaturityPolicy) {
 	// Public post routes
 	posts := router.Group("/api/v1/posts")
 	{
@@ -26,14 +27,29 @@ func SetupPostRoutes(router *gin.Engine, postHandler *handlers.PostHandler, auth
 	postsProtected.Use(authMiddleware.RequireAuth())
 	{
 		// Post creation and management
-		postsProtected.POST("/", middleware.PostRateLimit(), postHandler.CreatePost)
+		postsProtected.POST("/", challenges.Require(models.ChallengeGroupPosts), middleware.PostRateLimit(), postHandler.CreatePost)
 		postsProtected.PUT("/:id", postHandler.UpdatePost)
 		postsProtected.DELETE("/:id", postHandler.DeletePost)
+		postsProtected.GET("/:id/edits", postHandler.GetPostEdits)
+
+		// Rendering a draft as it will publish, requested as the author types
+		postsProtected.POST("/preview", middleware.PostPreviewRateLimit(), postHandler.PreviewPost)
+
+		// Bulk deleting one's own posts: preview the count, then confirm with it
+		postsProtected.POST("/bulk-delete/preview", postHandler.PreviewBulkDeletePosts)
+		postsProtected.POST("/bulk-delete", postHandler.BulkDeletePosts)
+		postsProtected.GET("/bulk-delete/:jobId", postHandler.GetBulkDeletePostsJob)
+		postsProtected.PUT("/:id/sensitive", postHandler.MarkPostSensitive)
 
 		// Post interactions
 		postsProtected.POST("/:id/like", middleware.LikeRateLimit(), postHandler.LikePost)
 		postsProtected.DELETE("/:id/like", postHandler.UnlikePost)
-		postsProtected.POST("/:id/report", postHandler.ReportPost)
+		postsProtected.POST("/:id/report", middleware.RequireAccountMaturity(reportMaturity), postHandler.ReportPost)
+
+		// Photo tags
+		postsProtected.POST("/:id/tags", postHandler.TagUsers)
+		postsProtected.PUT("/:id/tags/me", postHandler.RespondToTag)
+		postsProtected.DELETE("/:id/tags/:userId", postHandler.RemoveTag)
 
 		// Post management
 		postsProtected.POST("/:id/pin", postHandler.PinPost)
@@ -42,5 +58,6 @@ func SetupPostRoutes(router *gin.Engine, postHandler *handlers.PostHandler, auth
 		// User-specific post endpoints
 		postsProtected.GET("/feed", postHandler.GetFeed)
 		postsProtected.GET("/user/:userId", postHandler.GetUserPosts)
+		postsProtected.GET("/user/:userId/tagged", postHandler.GetTaggedPosts)
 	}
 }
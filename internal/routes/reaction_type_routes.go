@@ -0,0 +1,25 @@
+// internal/routes/reaction_type_routes.go
+package routes
+
+import (
+	"social-media-api/internal/handlers"
+	"social-media-api/internal/middleware"
+	"social-media-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupReactionTypeRoutes sets up reaction picker and admin reaction pack routes
+func SetupReactionTypeRoutes(router *gin.Engine, reactionTypeHandler *handlers.ReactionTypeHandler, authMiddleware *middleware.AuthMiddleware) {
+	router.GET("/api/v1/reactions/available", reactionTypeHandler.GetAvailableReactions)
+
+	adminReactions := router.Group("/api/v1/admin/reactions")
+	adminReactions.Use(authMiddleware.RequireAuth())
+	adminReactions.Use(authMiddleware.RequireRole(models.RoleAdmin, models.RoleSuperAdmin))
+	{
+		adminReactions.GET("", reactionTypeHandler.GetReactionTypes)
+		adminReactions.POST("", reactionTypeHandler.CreateReactionType)
+		adminReactions.PUT("/:id", reactionTypeHandler.UpdateReactionType)
+		adminReactions.PUT("/:id/retire", reactionTypeHandler.RetireReactionType)
+	}
+}
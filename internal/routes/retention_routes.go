@@ -0,0 +1,21 @@
+// internal/routes/retention_routes.go
+package routes
+
+import (
+	"social-media-api/internal/handlers"
+	"social-media-api/internal/middleware"
+	"social-media-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupRetentionRoutes sets up the admin routes for data retention
+func SetupRetentionRoutes(router *gin.Engine, retentionHandler *handlers.RetentionHandler, authMiddleware *middleware.AuthMiddleware) {
+	retention := router.Group("/api/v1/admin/retention")
+	retention.Use(authMiddleware.RequireAuth())
+	retention.Use(authMiddleware.RequireRole(models.RoleAdmin, models.RoleSuperAdmin))
+	{
+		retention.GET("", retentionHandler.GetRetention)
+		retention.PUT("/:category", retentionHandler.UpdateRetentionCategory)
+	}
+}
@@ -0,0 +1,23 @@
+// internal/routes/saved_search_routes.go
+package routes
+
+import (
+	"social-media-api/internal/handlers"
+	"social-media-api/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupSavedSearchRoutes sets up the saved search routes
+func SetupSavedSearchRoutes(router *gin.Engine, savedSearchHandler *handlers.SavedSearchHandler, authMiddleware *middleware.AuthMiddleware) {
+	saved := router.Group("/api/v1/search/saved")
+	saved.Use(authMiddleware.RequireAuth())
+	{
+		saved.GET("", savedSearchHandler.GetSavedSearches)
+		saved.POST("", savedSearchHandler.CreateSavedSearch)
+		saved.GET("/:id", savedSearchHandler.GetSavedSearch)
+		saved.PUT("/:id", savedSearchHandler.UpdateSavedSearch)
+		saved.DELETE("/:id", savedSearchHandler.DeleteSavedSearch)
+		saved.GET("/:id/results", savedSearchHandler.RunSavedSearch)
+	}
+}
@@ -0,0 +1,23 @@
+// internal/routes/setup_checklist_routes.go
+package routes
+
+import (
+	"social-media-api/internal/handlers"
+	"social-media-api/internal/middleware"
+	"social-media-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupSetupChecklistRoutes sets up the profile setup checklist and its admin configuration routes
+func SetupSetupChecklistRoutes(router *gin.Engine, setupChecklistHandler *handlers.SetupChecklistHandler, authMiddleware *middleware.AuthMiddleware) {
+	router.GET("/api/v1/users/me/setup-checklist", authMiddleware.RequireAuth(), setupChecklistHandler.GetSetupChecklist)
+
+	adminChecklist := router.Group("/api/v1/admin/setup-checklist")
+	adminChecklist.Use(authMiddleware.RequireAuth())
+	adminChecklist.Use(authMiddleware.RequireRole(models.RoleAdmin, models.RoleSuperAdmin))
+	{
+		adminChecklist.GET("", setupChecklistHandler.GetSetupChecklistConfig)
+		adminChecklist.PUT("", setupChecklistHandler.UpdateSetupChecklistConfig)
+	}
+}
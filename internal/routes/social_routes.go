@@ -19,6 +19,7 @@ func SetupSocialRoutes(router *gin.Engine, feedHandler *handlers.FeedHandler, se
 		feeds.GET("/following", feedHandler.GetFollowingFeed)
 		feeds.GET("/trending", feedHandler.GetTrendingFeed)
 		feeds.GET("/discover", feedHandler.GetDiscoverFeed)
+		feeds.GET("/network-trending", feedHandler.GetNetworkTrendingFeed)
 
 		// Feed interactions
 		feeds.POST("/interactions", feedHandler.RecordInteraction)
@@ -81,6 +82,7 @@ func SetupSocialRoutes(router *gin.Engine, feedHandler *handlers.FeedHandler, se
 	{
 		// Reaction management
 		reactionsProtected.POST("/", middleware.LikeRateLimit(), likeHandler.CreateLike)
+		reactionsProtected.POST("/batch", middleware.LikeRateLimit(), likeHandler.SyncReactions)
 		reactionsProtected.PUT("/:id", likeHandler.UpdateLike)
 		reactionsProtected.DELETE("/:targetType/:targetId", likeHandler.DeleteLike)
 
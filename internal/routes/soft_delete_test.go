@@ -0,0 +1,66 @@
+package routes
+
+import (
+	"testing"
+	"time"
+
+	"social-media-api/internal/handlers"
+	"social-media-api/internal/middleware"
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestListEndpointsExcludeSoftDeleted registers the content and admin route
+// groups on the services the server runs and checks no list endpoint returns
+// a soft-deleted document
+func TestListEndpointsExcludeSoftDeleted(t *testing.T) {
+	h := testutil.NewHarness(t)
+
+	limits := services.NewLimitsService(h.DB, time.Minute)
+	linkBlocklist := services.NewLinkBlocklistService(h.DB, services.LinkBlocklistPolicy{CacheTTL: time.Minute})
+	notifications := services.NewNotificationService(nil, nil)
+	groups := services.NewGroupService(h.DB, notifications, 10, "http://localhost")
+	boosts := services.NewBoostedPostService(h.DB, services.BoostPolicy{})
+
+	postHandler := handlers.NewPostHandler(services.NewPostService(h.DB, services.DuplicateContentPolicy{}, limits, nil, linkBlocklist))
+	commentHandler := handlers.NewCommentHandler(services.NewCommentService(services.CommentHoldPolicy{}, services.CommentFloodPolicy{}, 0, 3, notifications, linkBlocklist))
+	userHandler := handlers.NewUserHandler(services.NewUserService(h.DB, services.AbuseScorePolicy{}, limits), services.NewSuggestionService(h.DB))
+	followHandler := handlers.NewFollowHandler(services.NewFollowService(h.DB, notifications))
+	storyHandler := handlers.NewStoryHandler(services.NewStoryService(limits))
+	groupHandler := handlers.NewGroupHandler(groups, services.NewGroupLibraryService(h.DB, groups, nil, services.GroupLibraryPolicy{}))
+	feedHandler := handlers.NewFeedHandler(services.NewFeedService(boosts, services.FeedInjectionPolicy{}, "chronological", services.NetworkTrendingPolicy{}), services.NewUserBehaviorService())
+	searchHandler := handlers.NewSearchHandler(services.NewSearchService())
+	likeHandler := handlers.NewLikeHandler(services.NewLikeService())
+	adminHandler := handlers.NewAdminHandler(services.NewAdminService(h.DB, services.AdminQueryPolicy{MaxTime: 10 * time.Second, MaxResults: 1000, MaxRangeDays: 365}),
+		nil, nil, services.NewReportService(notifications, nil), h.DB)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	authMiddleware := middleware.NewAuthMiddleware(h.DB, testutil.TestJWTSecret, testutil.TestRefreshSecret, nil, nil)
+	challenges := middleware.NewChallengeMiddleware(nil)
+
+	SetupUserRoutes(router, userHandler, authMiddleware)
+	SetupPostRoutes(router, postHandler, authMiddleware, challenges, models.AccountMaturityPolicy{})
+	SetupCommentRoutes(router, commentHandler, authMiddleware, challenges, models.AccountMaturityPolicy{})
+	SetupFollowRoutes(router, followHandler, authMiddleware)
+	SetupStoryRoutes(router, storyHandler, authMiddleware)
+	SetupGroupRoutes(router, groupHandler, authMiddleware, models.AccountMaturityPolicy{})
+	SetupSocialRoutes(router, feedHandler, searchHandler, likeHandler, authMiddleware)
+	SetupAdminRoutes(router, adminHandler, authMiddleware)
+
+	// An admin owner reaches the admin lists as well as their own content
+	owner := h.CreateUser(testutil.WithRole(models.RoleAdmin))
+	pairs := h.SeedSoftDeletedContent(owner)
+
+	h.AssertListEndpointsExcludeDeleted(router, owner, pairs, map[string]string{
+		"/api/v1/admin/events":                        "AdminService.GetAllEvents is not implemented yet",
+		"/api/v1/admin/stories":                       "AdminService.GetAllStories is not implemented yet",
+		"/api/v1/admin/hashtags":                      "AdminService.GetAllHashtags is not implemented yet",
+		"/api/v1/admin/media":                         "AdminService.GetAllMedia is not implemented yet",
+		"/api/v1/admin/email-templates":               "renders templates, reads no documents",
+		"/api/v1/admin/email-templates/:type/preview": "renders templates, reads no documents",
+	})
+}
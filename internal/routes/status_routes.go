@@ -0,0 +1,29 @@
+// internal/routes/status_routes.go
+package routes
+
+import (
+	"social-media-api/internal/handlers"
+	"social-media-api/internal/middleware"
+	"social-media-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupStatusRoutes sets up the public status page and the admin incident routes
+func SetupStatusRoutes(router *gin.Engine, statusHandler *handlers.StatusHandler, authMiddleware *middleware.AuthMiddleware) {
+	// Public, unauthenticated and cached
+	router.GET("/status", middleware.CORS(), statusHandler.GetStatusPage)
+	router.GET("/api/v1/status", middleware.CORS(), statusHandler.GetStatusPage)
+
+	incidents := router.Group("/api/v1/admin/incidents")
+	incidents.Use(authMiddleware.RequireAuth())
+	incidents.Use(authMiddleware.RequireRole(models.RoleAdmin, models.RoleSuperAdmin))
+	{
+		incidents.GET("", statusHandler.GetIncidents)
+		incidents.POST("", statusHandler.CreateIncident)
+		incidents.GET("/:id", middleware.ValidateObjectID("id"), statusHandler.GetIncident)
+		incidents.PUT("/:id", middleware.ValidateObjectID("id"), statusHandler.UpdateIncident)
+		incidents.POST("/:id/updates", middleware.ValidateObjectID("id"), statusHandler.AddIncidentUpdate)
+		incidents.POST("/:id/resolve", middleware.ValidateObjectID("id"), statusHandler.ResolveIncident)
+	}
+}
@@ -33,9 +33,15 @@ func SetupStoryRoutes(router *gin.Engine, storyHandler *handlers.StoryHandler, a
 
 		// Story interactions
 		storiesProtected.POST("/:id/view", storyHandler.ViewStory)
+		storiesProtected.POST("/:id/screenshot", storyHandler.ReportScreenshot)
 		storiesProtected.POST("/:id/react", storyHandler.ReactToStory)
 		storiesProtected.DELETE("/:id/react", storyHandler.UnreactToStory)
 
+		// Interactive stickers
+		storiesProtected.POST("/:id/stickers/:stickerId/respond", storyHandler.RespondToSticker)
+		storiesProtected.GET("/:id/stickers/insights", storyHandler.GetStickerInsights)
+		storiesProtected.GET("/:id/stickers/:stickerId/responses", storyHandler.GetStickerResponses)
+
 		// Story management
 		storiesProtected.POST("/:id/archive", storyHandler.ArchiveStory)
 		storiesProtected.GET("/archived", storyHandler.GetArchivedStories)
@@ -0,0 +1,32 @@
+// internal/routes/survey_routes.go
+package routes
+
+import (
+	"social-media-api/internal/handlers"
+	"social-media-api/internal/middleware"
+	"social-media-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupSurveyRoutes sets up in-app survey routes
+func SetupSurveyRoutes(router *gin.Engine, surveyHandler *handlers.SurveyHandler, authMiddleware *middleware.AuthMiddleware) {
+	surveys := router.Group("/api/v1/surveys")
+	surveys.Use(authMiddleware.RequireAuth())
+	{
+		surveys.GET("/pending", surveyHandler.GetPendingSurveys)
+		surveys.POST("/:id/responses", surveyHandler.SubmitResponse)
+	}
+
+	adminSurveys := router.Group("/api/v1/admin/surveys")
+	adminSurveys.Use(authMiddleware.RequireAuth())
+	adminSurveys.Use(authMiddleware.RequireRole(models.RoleAdmin, models.RoleSuperAdmin))
+	{
+		adminSurveys.POST("", surveyHandler.CreateSurvey)
+		adminSurveys.GET("", surveyHandler.GetSurveys)
+		adminSurveys.GET("/:id", surveyHandler.GetSurvey)
+		adminSurveys.PUT("/:id/close", surveyHandler.CloseSurvey)
+		adminSurveys.GET("/:id/results", surveyHandler.GetSurveyResults)
+		adminSurveys.GET("/:id/responses/export", surveyHandler.ExportFreeTextResponses)
+	}
+}
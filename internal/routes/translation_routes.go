@@ -0,0 +1,24 @@
+// internal/routes/translation_routes.go
+package routes
+
+import (
+	"social-media-api/internal/handlers"
+	"social-media-api/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupTranslationRoutes sets up post and comment translation routes
+func SetupTranslationRoutes(router *gin.Engine, translationHandler *handlers.TranslationHandler, authMiddleware *middleware.AuthMiddleware) {
+	posts := router.Group("/api/v1/posts")
+	posts.Use(authMiddleware.RequireAuth())
+	{
+		posts.POST("/:id/translate", middleware.TranslationRateLimit(), translationHandler.TranslatePost)
+	}
+
+	comments := router.Group("/api/v1/comments")
+	comments.Use(authMiddleware.RequireAuth())
+	{
+		comments.POST("/:id/translate", middleware.TranslationRateLimit(), translationHandler.TranslateComment)
+	}
+}
@@ -15,9 +15,11 @@ func SetupUserRoutes(router *gin.Engine, userHandler *handlers.UserHandler, auth
 	{
 		// User discovery (public)
 		users.GET("/search", userHandler.SearchUsers)
+		users.GET("/interests/available", userHandler.GetAvailableInterests)
 		users.GET("/:id", userHandler.GetUserProfile)
 		users.GET("/username/:username", userHandler.GetUserByUsername)
 		users.GET("/:id/stats", userHandler.GetUserStats)
+		users.GET("/:id/featured-comments", userHandler.GetFeaturedComments)
 	}
 
 	// Protected user routes
@@ -25,13 +27,24 @@ func SetupUserRoutes(router *gin.Engine, userHandler *handlers.UserHandler, auth
 	usersProtected.Use(authMiddleware.RequireAuth())
 	{
 		// User suggestions and discovery
+		usersProtected.GET("/suggested", userHandler.GetSuggestedUsers)
 		usersProtected.GET("/suggestions", userHandler.GetSuggestedUsers)
+		usersProtected.POST("/suggested/:id/dismiss", userHandler.DismissSuggestion)
 
 		// Profile management
 		usersProtected.PUT("/profile", userHandler.UpdateProfile)
 		usersProtected.PUT("/privacy-settings", userHandler.UpdatePrivacySettings)
 		usersProtected.PUT("/notification-settings", userHandler.UpdateNotificationSettings)
 		usersProtected.PUT("/activity-status", userHandler.UpdateUserActivity)
+		usersProtected.PUT("/me/auto-delete", userHandler.UpdateAutoDeletePolicy)
+
+		// Interests personalize the feed before behavior data accumulates
+		usersProtected.GET("/interests", userHandler.GetInterests)
+		usersProtected.POST("/interests", userHandler.SetInterests)
+
+		// Featured comments highlight comments on the user's own posts
+		usersProtected.POST("/me/featured-comments/:commentId", userHandler.FeatureComment)
+		usersProtected.DELETE("/me/featured-comments/:commentId", userHandler.UnfeatureComment)
 
 		// Account management
 		usersProtected.POST("/deactivate", userHandler.DeactivateAccount)
@@ -40,6 +53,14 @@ func SetupUserRoutes(router *gin.Engine, userHandler *handlers.UserHandler, auth
 		usersProtected.POST("/:id/block", userHandler.BlockUser)
 		usersProtected.DELETE("/:id/block", userHandler.UnblockUser)
 		usersProtected.GET("/blocked", userHandler.GetBlockedUsers)
+
+		// Muting hides a user's content for a while without them knowing
+		usersProtected.POST("/:id/mute", userHandler.MuteUser)
+		usersProtected.DELETE("/:id/mute", userHandler.UnmuteUser)
+		usersProtected.GET("/me/muted", userHandler.GetMutedUsers)
+
+		// Activity log is private to the user and admins
+		usersProtected.GET("/:id/activity", userHandler.GetActivityLog)
 	}
 
 	// Admin-only user routes
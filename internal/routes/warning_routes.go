@@ -0,0 +1,23 @@
+// internal/routes/warning_routes.go
+package routes
+
+import (
+	"social-media-api/internal/handlers"
+	"social-media-api/internal/middleware"
+	"social-media-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupWarningRoutes sets up the caller's warning history and the moderator warning routes
+func SetupWarningRoutes(router *gin.Engine, warningHandler *handlers.WarningHandler, authMiddleware *middleware.AuthMiddleware) {
+	router.GET("/api/v1/users/me/warnings", authMiddleware.RequireAuth(), warningHandler.GetMyWarnings)
+
+	adminWarnings := router.Group("/api/v1/admin/users")
+	adminWarnings.Use(authMiddleware.RequireAuth())
+	adminWarnings.Use(authMiddleware.RequireRole(models.RoleModerator, models.RoleAdmin, models.RoleSuperAdmin))
+	{
+		adminWarnings.POST("/:id/warn", middleware.ValidateObjectID("id"), warningHandler.WarnUser)
+		adminWarnings.GET("/:id/warnings", middleware.ValidateObjectID("id"), warningHandler.GetUserWarnings)
+	}
+}
@@ -0,0 +1,126 @@
+// internal/services/admin_query_guard.go
+package services
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// adminQueryEvents counts guardrail hits per endpoint, keyed "timeout:<endpoint>"
+// and "truncated:<endpoint>", so endpoints that need pre-aggregation stand out
+var adminQueryEvents = expvar.NewMap("admin_query_guard")
+
+// AdminQueryPolicy bounds the cost of admin and analytics queries
+type AdminQueryPolicy struct {
+	MaxTime      time.Duration // Server-side time limit (maxTimeMS) per query
+	MaxResults   int           // Documents one aggregation may return before it's truncated
+	MaxRangeDays int           // Widest date range analytics endpoints accept
+}
+
+// AdminQueryGuard applies AdminQueryPolicy to admin and analytics queries.
+// Queries run with a server-side time limit so an expensive pipeline fails
+// fast instead of scanning a whole collection, and timeouts and truncated
+// results are counted per endpoint.
+type AdminQueryGuard struct {
+	policy AdminQueryPolicy
+}
+
+func NewAdminQueryGuard(policy AdminQueryPolicy) *AdminQueryGuard {
+	return &AdminQueryGuard{policy: policy}
+}
+
+// MaxResults returns the most documents one query may return
+func (g *AdminQueryGuard) MaxResults() int {
+	return g.policy.MaxResults
+}
+
+// MaxRangeDays returns the widest date range analytics endpoints accept
+func (g *AdminQueryGuard) MaxRangeDays() int {
+	return g.policy.MaxRangeDays
+}
+
+// AggregateOptions returns aggregate options carrying the time limit
+func (g *AdminQueryGuard) AggregateOptions() *options.AggregateOptions {
+	return options.Aggregate().SetMaxTime(g.policy.MaxTime)
+}
+
+// FindOptions adds the time limit to find options
+func (g *AdminQueryGuard) FindOptions(opts *options.FindOptions) *options.FindOptions {
+	if opts == nil {
+		opts = options.Find()
+	}
+	return opts.SetMaxTime(g.policy.MaxTime)
+}
+
+// CountOptions returns count options carrying the time limit
+func (g *AdminQueryGuard) CountOptions() *options.CountOptions {
+	return options.Count().SetMaxTime(g.policy.MaxTime)
+}
+
+// AggregateAll runs a pipeline with the time limit and returns at most
+// MaxResults documents, reporting whether the result was cut short
+func (g *AdminQueryGuard) AggregateAll(ctx context.Context, collection *mongo.Collection, endpoint string, pipeline []bson.M) ([]bson.M, bool, error) {
+	// One extra document tells a full result apart from a truncated one
+	capped := append(append([]bson.M(nil), pipeline...), bson.M{"$limit": g.policy.MaxResults + 1})
+
+	cursor, err := collection.Aggregate(ctx, capped, g.AggregateOptions())
+	if err != nil {
+		return nil, false, g.CheckError(endpoint, err)
+	}
+	defer cursor.Close(ctx)
+
+	results := []bson.M{}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, false, g.CheckError(endpoint, err)
+	}
+
+	if len(results) > g.policy.MaxResults {
+		g.record("truncated", endpoint)
+		return results[:g.policy.MaxResults], true, nil
+	}
+	return results, false, nil
+}
+
+// CheckError replaces a time limit error with a "query timed out" error and
+// records it; other errors are returned unchanged
+func (g *AdminQueryGuard) CheckError(endpoint string, err error) error {
+	if err == nil || !mongo.IsTimeout(err) {
+		return err
+	}
+	g.record("timeout", endpoint)
+	return fmt.Errorf("query timed out after %s: narrow the filters or request an export", g.policy.MaxTime)
+}
+
+// CheckRange rejects analytics date ranges wider than the policy allows
+func (g *AdminQueryGuard) CheckRange(start, end time.Time) error {
+	if end.Before(start) {
+		return fmt.Errorf("invalid date range: end is before start")
+	}
+	if end.Sub(start) > time.Duration(g.policy.MaxRangeDays)*24*time.Hour {
+		return fmt.Errorf("date range too wide: analytics cover at most %d days, use the export endpoints for longer periods", g.policy.MaxRangeDays)
+	}
+	return nil
+}
+
+func (g *AdminQueryGuard) record(event, endpoint string) {
+	adminQueryEvents.Add(event+":"+endpoint, 1)
+	log.Printf("ADMIN QUERY GUARD: %s on %s", event, endpoint)
+}
+
+// AdminQueryGuardStats returns the guardrail hit counts per endpoint
+func AdminQueryGuardStats() map[string]int64 {
+	stats := make(map[string]int64)
+	adminQueryEvents.Do(func(kv expvar.KeyValue) {
+		if counter, ok := kv.Value.(*expvar.Int); ok {
+			stats[kv.Key] = counter.Value()
+		}
+	})
+	return stats
+}
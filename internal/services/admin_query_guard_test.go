@@ -0,0 +1,120 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestAdminQueryGuardCheckError(t *testing.T) {
+	guard := services.NewAdminQueryGuard(services.AdminQueryPolicy{MaxTime: 5 * time.Second})
+
+	tests := []struct {
+		name    string
+		err     error
+		timeout bool
+	}{
+		{"server time limit", mongo.CommandError{Code: 50, Name: "MaxTimeMSExpired", Message: "operation exceeded time limit"}, true},
+		{"client deadline", context.DeadlineExceeded, true},
+		{"wrapped client deadline", fmt.Errorf("aggregate: %w", context.DeadlineExceeded), true},
+		{"other command error", mongo.CommandError{Code: 2, Name: "BadValue", Message: "bad pipeline"}, false},
+		{"plain error", errors.New("connection refused"), false},
+	}
+
+	for _, tt := range tests {
+		before := services.AdminQueryGuardStats()["timeout:test."+tt.name]
+		err := guard.CheckError("test."+tt.name, tt.err)
+
+		if got := strings.Contains(err.Error(), "query timed out"); got != tt.timeout {
+			t.Errorf("%s: CheckError = %q, timed out %v, want %v", tt.name, err, got, tt.timeout)
+		}
+		if !tt.timeout && err.Error() != tt.err.Error() {
+			t.Errorf("%s: CheckError changed a non-timeout error to %q", tt.name, err)
+		}
+
+		want := before
+		if tt.timeout {
+			want++
+		}
+		if got := services.AdminQueryGuardStats()["timeout:test."+tt.name]; got != want {
+			t.Errorf("%s: timeout metric = %d, want %d", tt.name, got, want)
+		}
+	}
+
+	if err := guard.CheckError("test.nil", nil); err != nil {
+		t.Errorf("CheckError(nil) = %v, want nil", err)
+	}
+}
+
+func TestAdminQueryGuardTruncatesResults(t *testing.T) {
+	h := testutil.NewHarness(t)
+
+	collection := h.DB.Collection("admin_query_guard_test")
+	for i := 0; i < 5; i++ {
+		if _, err := collection.InsertOne(h.Context(), bson.M{"n": i}); err != nil {
+			t.Fatalf("seeding: %v", err)
+		}
+	}
+	pipeline := []bson.M{{"$sort": bson.M{"n": 1}}}
+
+	tests := []struct {
+		maxResults int
+		want       int
+		truncated  bool
+	}{
+		{3, 3, true},
+		{5, 5, false},
+		{10, 5, false},
+	}
+
+	for _, tt := range tests {
+		guard := services.NewAdminQueryGuard(services.AdminQueryPolicy{MaxTime: 5 * time.Second, MaxResults: tt.maxResults})
+		endpoint := fmt.Sprintf("test.truncate_%d", tt.maxResults)
+		before := services.AdminQueryGuardStats()["truncated:"+endpoint]
+
+		results, truncated, err := guard.AggregateAll(h.Context(), collection, endpoint, pipeline)
+		if err != nil {
+			t.Fatalf("MaxResults %d: AggregateAll: %v", tt.maxResults, err)
+		}
+		if len(results) != tt.want || truncated != tt.truncated {
+			t.Errorf("MaxResults %d: got %d results, truncated %v, want %d, truncated %v", tt.maxResults, len(results), truncated, tt.want, tt.truncated)
+		}
+		for i, result := range results {
+			if n, _ := result["n"].(int32); int(n) != i {
+				t.Errorf("MaxResults %d: result %d is n=%v, want the pipeline's order kept", tt.maxResults, i, result["n"])
+			}
+		}
+
+		want := before
+		if tt.truncated {
+			want++
+		}
+		if got := services.AdminQueryGuardStats()["truncated:"+endpoint]; got != want {
+			t.Errorf("MaxResults %d: truncated metric = %d, want %d", tt.maxResults, got, want)
+		}
+	}
+}
+
+func TestAdminQueryGuardCheckRange(t *testing.T) {
+	guard := services.NewAdminQueryGuard(services.AdminQueryPolicy{MaxRangeDays: 30})
+	now := time.Now()
+
+	if err := guard.CheckRange(now.AddDate(0, 0, -30), now); err != nil {
+		t.Errorf("30-day range: %v", err)
+	}
+	if err := guard.CheckRange(now.AddDate(0, 0, -31), now); err == nil {
+		t.Error("31-day range was accepted")
+	}
+	if err := guard.CheckRange(now, now.AddDate(0, 0, -1)); err == nil {
+		t.Error("range ending before it starts was accepted")
+	}
+}
@@ -3,7 +3,9 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -12,15 +14,22 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
 	"social-media-api/internal/utils"
 )
 
 type AdminService struct {
-	db *mongo.Database
+	db         *mongo.Database
+	queryGuard *AdminQueryGuard
 }
 
-func NewAdminService(db *mongo.Database) *AdminService {
-	return &AdminService{db: db}
+func NewAdminService(db *mongo.Database, queryPolicy AdminQueryPolicy) *AdminService {
+	return &AdminService{db: db, queryGuard: NewAdminQueryGuard(queryPolicy)}
+}
+
+// QueryGuard returns the limits applied to admin and analytics queries
+func (s *AdminService) QueryGuard() *AdminQueryGuard {
+	return s.queryGuard
 }
 
 // Dashboard Statistics
@@ -92,99 +101,94 @@ func (s *AdminService) GetDashboardStats(ctx context.Context) (*DashboardStats,
 
 	// Get basic counts
 	var err error
-	stats.TotalUsers, err = s.db.Collection("users").CountDocuments(ctx, bson.M{"deleted_at": bson.M{"$exists": false}})
+	stats.TotalUsers, err = s.db.Collection("users").CountDocuments(ctx, repository.NotDeleted(), s.queryGuard.CountOptions())
 	if err != nil {
 		return nil, err
 	}
 
-	stats.TotalPosts, err = s.db.Collection("posts").CountDocuments(ctx, bson.M{"deleted_at": bson.M{"$exists": false}})
+	stats.TotalPosts, err = s.db.Collection("posts").CountDocuments(ctx, repository.NotDeleted(), s.queryGuard.CountOptions())
 	if err != nil {
 		return nil, err
 	}
 
-	stats.TotalComments, err = s.db.Collection("comments").CountDocuments(ctx, bson.M{"deleted_at": bson.M{"$exists": false}})
+	stats.TotalComments, err = s.db.Collection("comments").CountDocuments(ctx, repository.NotDeleted(), s.queryGuard.CountOptions())
 	if err != nil {
 		return nil, err
 	}
 
-	stats.TotalGroups, err = s.db.Collection("groups").CountDocuments(ctx, bson.M{"deleted_at": bson.M{"$exists": false}})
+	stats.TotalGroups, err = s.db.Collection("groups").CountDocuments(ctx, repository.NotDeleted(), s.queryGuard.CountOptions())
 	if err != nil {
 		return nil, err
 	}
 
-	stats.TotalEvents, err = s.db.Collection("events").CountDocuments(ctx, bson.M{"deleted_at": bson.M{"$exists": false}})
+	stats.TotalEvents, err = s.db.Collection("events").CountDocuments(ctx, repository.NotDeleted(), s.queryGuard.CountOptions())
 	if err != nil {
 		return nil, err
 	}
 
-	stats.TotalStories, err = s.db.Collection("stories").CountDocuments(ctx, bson.M{"deleted_at": bson.M{"$exists": false}})
+	stats.TotalStories, err = s.db.Collection("stories").CountDocuments(ctx, repository.NotDeleted(), s.queryGuard.CountOptions())
 	if err != nil {
 		return nil, err
 	}
 
-	stats.TotalMessages, err = s.db.Collection("messages").CountDocuments(ctx, bson.M{"deleted_at": bson.M{"$exists": false}})
+	stats.TotalMessages, err = s.db.Collection("messages").CountDocuments(ctx, repository.NotDeleted(), s.queryGuard.CountOptions())
 	if err != nil {
 		return nil, err
 	}
 
-	stats.TotalReports, err = s.db.Collection("reports").CountDocuments(ctx, bson.M{"deleted_at": bson.M{"$exists": false}})
+	stats.TotalReports, err = s.db.Collection("reports").CountDocuments(ctx, repository.NotDeleted(), s.queryGuard.CountOptions())
 	if err != nil {
 		return nil, err
 	}
 
-	stats.TotalLikes, err = s.db.Collection("likes").CountDocuments(ctx, bson.M{"deleted_at": bson.M{"$exists": false}})
+	stats.TotalLikes, err = s.db.Collection("likes").CountDocuments(ctx, repository.NotDeleted(), s.queryGuard.CountOptions())
 	if err != nil {
 		return nil, err
 	}
 
-	stats.TotalFollows, err = s.db.Collection("follows").CountDocuments(ctx, bson.M{"deleted_at": bson.M{"$exists": false}})
+	stats.TotalFollows, err = s.db.Collection("follows").CountDocuments(ctx, repository.NotDeleted(), s.queryGuard.CountOptions())
 	if err != nil {
 		return nil, err
 	}
 
 	// Active users (logged in within last 24 hours)
 	yesterday := time.Now().Add(-24 * time.Hour)
-	stats.ActiveUsers, err = s.db.Collection("users").CountDocuments(ctx, bson.M{
+	stats.ActiveUsers, err = s.db.Collection("users").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"last_active_at": bson.M{"$gte": yesterday},
-		"deleted_at":     bson.M{"$exists": false},
-	})
+	}), s.queryGuard.CountOptions())
 	if err != nil {
 		return nil, err
 	}
 
 	// New users today
 	today := time.Now().Truncate(24 * time.Hour)
-	stats.NewUsersToday, err = s.db.Collection("users").CountDocuments(ctx, bson.M{
+	stats.NewUsersToday, err = s.db.Collection("users").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"created_at": bson.M{"$gte": today},
-		"deleted_at": bson.M{"$exists": false},
-	})
+	}), s.queryGuard.CountOptions())
 	if err != nil {
 		return nil, err
 	}
 
 	// New posts today
-	stats.NewPostsToday, err = s.db.Collection("posts").CountDocuments(ctx, bson.M{
+	stats.NewPostsToday, err = s.db.Collection("posts").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"created_at": bson.M{"$gte": today},
-		"deleted_at": bson.M{"$exists": false},
-	})
+	}), s.queryGuard.CountOptions())
 	if err != nil {
 		return nil, err
 	}
 
 	// Pending reports
-	stats.PendingReports, err = s.db.Collection("reports").CountDocuments(ctx, bson.M{
-		"status":     models.ReportPending,
-		"deleted_at": bson.M{"$exists": false},
-	})
+	stats.PendingReports, err = s.db.Collection("reports").CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"status": models.ReportPending,
+	}), s.queryGuard.CountOptions())
 	if err != nil {
 		return nil, err
 	}
 
 	// Suspended users
-	stats.SuspendedUsers, err = s.db.Collection("users").CountDocuments(ctx, bson.M{
+	stats.SuspendedUsers, err = s.db.Collection("users").CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"is_suspended": true,
-		"deleted_at":   bson.M{"$exists": false},
-	})
+	}), s.queryGuard.CountOptions())
 	if err != nil {
 		return nil, err
 	}
@@ -233,10 +237,9 @@ func (s *AdminService) GetDashboardStats(ctx context.Context) (*DashboardStats,
 func (s *AdminService) getUserGrowthChart(ctx context.Context) ([]ChartData, error) {
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"created_at": bson.M{"$gte": time.Now().AddDate(0, 0, -30)},
-				"deleted_at": bson.M{"$exists": false},
-			},
+			}),
 		},
 		{
 			"$group": bson.M{
@@ -254,7 +257,7 @@ func (s *AdminService) getUserGrowthChart(ctx context.Context) ([]ChartData, err
 		},
 	}
 
-	cursor, err := s.db.Collection("users").Aggregate(ctx, pipeline)
+	cursor, err := s.db.Collection("users").Aggregate(ctx, pipeline, s.queryGuard.AggregateOptions())
 	if err != nil {
 		return nil, err
 	}
@@ -281,10 +284,9 @@ func (s *AdminService) getUserGrowthChart(ctx context.Context) ([]ChartData, err
 func (s *AdminService) getPostGrowthChart(ctx context.Context) ([]ChartData, error) {
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"created_at": bson.M{"$gte": time.Now().AddDate(0, 0, -30)},
-				"deleted_at": bson.M{"$exists": false},
-			},
+			}),
 		},
 		{
 			"$group": bson.M{
@@ -302,7 +304,7 @@ func (s *AdminService) getPostGrowthChart(ctx context.Context) ([]ChartData, err
 		},
 	}
 
-	cursor, err := s.db.Collection("posts").Aggregate(ctx, pipeline)
+	cursor, err := s.db.Collection("posts").Aggregate(ctx, pipeline, s.queryGuard.AggregateOptions())
 	if err != nil {
 		return nil, err
 	}
@@ -329,9 +331,7 @@ func (s *AdminService) getPostGrowthChart(ctx context.Context) ([]ChartData, err
 func (s *AdminService) getTopHashtags(ctx context.Context) ([]HashtagStats, error) {
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"deleted_at": bson.M{"$exists": false},
-			},
+			"$match": repository.NotDeleted(),
 		},
 		{
 			"$sort": bson.M{"total_usage": -1},
@@ -341,7 +341,7 @@ func (s *AdminService) getTopHashtags(ctx context.Context) ([]HashtagStats, erro
 		},
 	}
 
-	cursor, err := s.db.Collection("hashtags").Aggregate(ctx, pipeline)
+	cursor, err := s.db.Collection("hashtags").Aggregate(ctx, pipeline, s.queryGuard.AggregateOptions())
 	if err != nil {
 		return nil, err
 	}
@@ -364,9 +364,7 @@ func (s *AdminService) getTopHashtags(ctx context.Context) ([]HashtagStats, erro
 
 func (s *AdminService) getTopUsers(ctx context.Context) ([]models.UserResponse, error) {
 	opts := options.Find().SetLimit(10).SetSort(bson.M{"followers_count": -1})
-	cursor, err := s.db.Collection("users").Find(ctx, bson.M{
-		"deleted_at": bson.M{"$exists": false},
-	}, opts)
+	cursor, err := s.db.Collection("users").Find(ctx, repository.NotDeleted(), s.queryGuard.FindOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -389,9 +387,7 @@ func (s *AdminService) getRecentActivities(ctx context.Context) ([]AdminActivity
 	// This would typically come from an admin_activities collection
 	// For now, we'll return recent reports as activities
 	opts := options.Find().SetLimit(10).SetSort(bson.M{"created_at": -1})
-	cursor, err := s.db.Collection("reports").Find(ctx, bson.M{
-		"deleted_at": bson.M{"$exists": false},
-	}, opts)
+	cursor, err := s.db.Collection("reports").Find(ctx, repository.NotDeleted(), s.queryGuard.FindOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -441,9 +437,7 @@ func (s *AdminService) getContentDistribution(ctx context.Context) (ContentDistr
 	// Posts by type
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"deleted_at": bson.M{"$exists": false},
-			},
+			"$match": repository.NotDeleted(),
 		},
 		{
 			"$group": bson.M{
@@ -453,7 +447,7 @@ func (s *AdminService) getContentDistribution(ctx context.Context) (ContentDistr
 		},
 	}
 
-	cursor, err := s.db.Collection("posts").Aggregate(ctx, pipeline)
+	cursor, err := s.db.Collection("posts").Aggregate(ctx, pipeline, s.queryGuard.AggregateOptions())
 	if err != nil {
 		return stats, err
 	}
@@ -473,10 +467,9 @@ func (s *AdminService) getContentDistribution(ctx context.Context) (ContentDistr
 	// Content by hour
 	hourPipeline := []bson.M{
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"created_at": bson.M{"$gte": time.Now().AddDate(0, 0, -7)},
-				"deleted_at": bson.M{"$exists": false},
-			},
+			}),
 		},
 		{
 			"$group": bson.M{
@@ -491,7 +484,7 @@ func (s *AdminService) getContentDistribution(ctx context.Context) (ContentDistr
 		},
 	}
 
-	cursor, err = s.db.Collection("posts").Aggregate(ctx, hourPipeline)
+	cursor, err = s.db.Collection("posts").Aggregate(ctx, hourPipeline, s.queryGuard.AggregateOptions())
 	if err != nil {
 		return stats, err
 	}
@@ -519,9 +512,13 @@ func (s *AdminService) GetAllUsers(ctx context.Context, filter UserFilter, page,
 	query := s.buildUserFilter(filter)
 
 	skip := (page - 1) * limit
-	opts := options.Find().SetSkip(int64(skip)).SetLimit(int64(limit)).SetSort(bson.M{"created_at": -1})
+	sort := bson.D{{Key: "created_at", Value: -1}}
+	if filter.SortBy == "abuse_score" {
+		sort = bson.D{{Key: "abuse_score", Value: -1}, {Key: "created_at", Value: -1}}
+	}
+	opts := options.Find().SetSkip(int64(skip)).SetLimit(int64(limit)).SetSort(sort)
 
-	cursor, err := s.db.Collection("users").Find(ctx, query, opts)
+	cursor, err := s.db.Collection("users").Find(ctx, query, s.queryGuard.FindOptions(opts))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -532,14 +529,17 @@ func (s *AdminService) GetAllUsers(ctx context.Context, filter UserFilter, page,
 		return nil, nil, err
 	}
 
-	total, err := s.db.Collection("users").CountDocuments(ctx, query)
+	total, err := s.db.Collection("users").CountDocuments(ctx, query, s.queryGuard.CountOptions())
 	if err != nil {
 		return nil, nil, err
 	}
 
 	var userResponses []models.UserResponse
 	for _, user := range users {
-		userResponses = append(userResponses, user.ToUserResponse())
+		response := user.ToUserResponse()
+		abuseScore := user.AbuseScore
+		response.AbuseScore = &abuseScore
+		userResponses = append(userResponses, response)
 	}
 
 	pagination := &utils.PaginationMeta{
@@ -555,17 +555,19 @@ func (s *AdminService) GetAllUsers(ctx context.Context, filter UserFilter, page,
 }
 
 type UserFilter struct {
-	IsVerified  *bool      `json:"is_verified"`
-	IsActive    *bool      `json:"is_active"`
-	IsSuspended *bool      `json:"is_suspended"`
-	Role        string     `json:"role"`
-	Search      string     `json:"search"`
-	DateFrom    *time.Time `json:"date_from"`
-	DateTo      *time.Time `json:"date_to"`
+	IsVerified    *bool      `json:"is_verified"`
+	IsActive      *bool      `json:"is_active"`
+	IsSuspended   *bool      `json:"is_suspended"`
+	Role          string     `json:"role"`
+	Search        string     `json:"search"`
+	DateFrom      *time.Time `json:"date_from"`
+	DateTo        *time.Time `json:"date_to"`
+	MinAbuseScore *float64   `json:"min_abuse_score"`
+	SortBy        string     `json:"sort_by"` // created_at (default) or abuse_score
 }
 
 func (s *AdminService) buildUserFilter(filter UserFilter) bson.M {
-	query := bson.M{"deleted_at": bson.M{"$exists": false}}
+	query := repository.NotDeleted()
 
 	if filter.IsVerified != nil {
 		query["is_verified"] = *filter.IsVerified
@@ -583,6 +585,10 @@ func (s *AdminService) buildUserFilter(filter UserFilter) bson.M {
 		query["role"] = filter.Role
 	}
 
+	if filter.MinAbuseScore != nil {
+		query["abuse_score"] = bson.M{"$gte": *filter.MinAbuseScore}
+	}
+
 	if filter.Search != "" {
 		query["$or"] = []bson.M{
 			{"username": bson.M{"$regex": filter.Search, "$options": "i"}},
@@ -613,10 +619,7 @@ func (s *AdminService) GetUserByID(ctx context.Context, userID string) (*models.
 	}
 
 	var user models.User
-	err = s.db.Collection("users").FindOne(ctx, bson.M{
-		"_id":        objID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&user)
+	err = s.db.Collection("users").FindOne(ctx, repository.ByID(objID)).Decode(&user)
 	if err != nil {
 		return nil, err
 	}
@@ -638,9 +641,19 @@ func (s *AdminService) UpdateUserStatus(ctx context.Context, userID string, isAc
 			"updated_at":   time.Now(),
 		},
 	}
+	// A moderator's decision replaces any strike suspension end date
+	if !isSuspended {
+		update["$unset"] = bson.M{"suspended_until": ""}
+	}
 
-	_, err = s.db.Collection("users").UpdateOne(ctx, bson.M{"_id": objID}, update)
-	return err
+	if _, err = s.db.Collection("users").UpdateOne(ctx, bson.M{"_id": objID}, update); err != nil {
+		return err
+	}
+
+	if isSuspended || !isActive {
+		return revokeAccountSessions(ctx, s.db, objID)
+	}
+	return nil
 }
 
 func (s *AdminService) VerifyUser(ctx context.Context, userID string) error {
@@ -660,6 +673,31 @@ func (s *AdminService) VerifyUser(ctx context.Context, userID string) error {
 	return err
 }
 
+// SetOnboardingSuggested adds or removes a user from the curated accounts
+// suggested to new users
+func (s *AdminService) SetOnboardingSuggested(ctx context.Context, userID string, suggested bool) error {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"is_onboarding_suggested": suggested,
+			"updated_at":              time.Now(),
+		},
+	}
+
+	result, err := s.db.Collection("users").UpdateOne(ctx, repository.ByID(objID), update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
 func (s *AdminService) DeleteUser(ctx context.Context, userID string) error {
 	objID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
@@ -674,6 +712,166 @@ func (s *AdminService) DeleteUser(ctx context.Context, userID string) error {
 	}
 
 	_, err = s.db.Collection("users").UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return err
+	}
+
+	return anonymizeSurveyResponses(ctx, s.db, objID)
+}
+
+// GetUserByEmail finds a non-deleted user by email address
+func (s *AdminService) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	var user models.User
+	err := s.db.Collection("users").FindOne(ctx, repository.NotDeleted(bson.M{
+		"email": strings.TrimSpace(email),
+	})).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (s *AdminService) UpdateUserRole(ctx context.Context, userID string, role models.UserRole) error {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	switch role {
+	case models.RoleUser, models.RoleModerator, models.RoleAdmin, models.RoleSuperAdmin:
+	default:
+		return fmt.Errorf("invalid role: %s", role)
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"role":       role,
+			"updated_at": time.Now(),
+		},
+	}
+
+	_, err = s.db.Collection("users").UpdateOne(ctx, bson.M{"_id": objID}, update)
+	return err
+}
+
+// ResetTwoFactor disables two-factor authentication and discards the secret and backup codes
+func (s *AdminService) ResetTwoFactor(ctx context.Context, userID string) error {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"two_factor_enabled": false,
+			"updated_at":         time.Now(),
+		},
+		"$unset": bson.M{
+			"two_factor_secret": "",
+			"backup_codes":      "",
+		},
+	}
+
+	_, err = s.db.Collection("users").UpdateOne(ctx, bson.M{"_id": objID}, update)
+	return err
+}
+
+// VerifyUserEmail marks the user's email as verified and discards any pending verification token
+func (s *AdminService) VerifyUserEmail(ctx context.Context, userID string) error {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"email_verified":    true,
+			"email_verified_at": now,
+			"updated_at":        now,
+		},
+		"$unset": bson.M{"email_verify_token": ""},
+	}
+
+	_, err = s.db.Collection("users").UpdateOne(ctx, bson.M{"_id": objID}, update)
+	return err
+}
+
+// RecountUserStats recomputes a user's denormalized follower, following and post
+// counts from the source collections and returns the new values
+func (s *AdminService) RecountUserStats(ctx context.Context, userID string) (map[string]int64, error) {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	followers, err := s.db.Collection("follows").CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"followee_id": objID,
+		"status":      models.FollowStatusAccepted,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	following, err := s.db.Collection("follows").CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"follower_id": objID,
+		"status":      models.FollowStatusAccepted,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := s.db.Collection("posts").CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"user_id": objID,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int64{
+		"followers_count": followers,
+		"following_count": following,
+		"posts_count":     posts,
+	}
+
+	update := bson.M{"$set": bson.M{"updated_at": time.Now()}}
+	for field, value := range counts {
+		update["$set"].(bson.M)[field] = value
+	}
+
+	result, err := s.db.Collection("users").UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, errors.New("user not found")
+	}
+
+	return counts, nil
+}
+
+// GetCommentHoldStats counts comments by spam hold outcome
+func (s *AdminService) GetCommentHoldStats(ctx context.Context) (*models.CommentHoldStats, error) {
+	return getCommentHoldStats(ctx, s.db)
+}
+
+// CreateAuditLog records an administrative action in the audit trail
+func (s *AdminService) CreateAuditLog(ctx context.Context, entry *models.AuditLog) error {
+	return createAuditLog(ctx, s.db, entry)
+}
+
+// createAuditLog inserts an entry into the audit_logs collection
+func createAuditLog(ctx context.Context, db *mongo.Database, entry *models.AuditLog) error {
+	entry.BeforeCreate()
+	if entry.ID.IsZero() {
+		entry.ID = primitive.NewObjectID()
+	}
+
+	_, err := db.Collection("audit_logs").InsertOne(ctx, entry)
 	return err
 }
 
@@ -684,7 +882,7 @@ func (s *AdminService) GetAllPosts(ctx context.Context, filter PostFilter, page,
 	skip := (page - 1) * limit
 	opts := options.Find().SetSkip(int64(skip)).SetLimit(int64(limit)).SetSort(bson.M{"created_at": -1})
 
-	cursor, err := s.db.Collection("posts").Find(ctx, query, opts)
+	cursor, err := s.db.Collection("posts").Find(ctx, query, s.queryGuard.FindOptions(opts))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -695,14 +893,14 @@ func (s *AdminService) GetAllPosts(ctx context.Context, filter PostFilter, page,
 		return nil, nil, err
 	}
 
-	total, err := s.db.Collection("posts").CountDocuments(ctx, query)
+	total, err := s.db.Collection("posts").CountDocuments(ctx, query, s.queryGuard.CountOptions())
 	if err != nil {
 		return nil, nil, err
 	}
 
 	var postResponses []models.PostResponse
 	for _, post := range posts {
-		postResponses = append(postResponses, post.ToPostResponse())
+		postResponses = append(postResponses, post.ToAdminPostResponse())
 	}
 
 	pagination := &utils.PaginationMeta{
@@ -729,7 +927,7 @@ type PostFilter struct {
 }
 
 func (s *AdminService) buildPostFilter(filter PostFilter) bson.M {
-	query := bson.M{"deleted_at": bson.M{"$exists": false}}
+	query := repository.NotDeleted()
 
 	if filter.UserID != "" {
 		if objID, err := primitive.ObjectIDFromHex(filter.UserID); err == nil {
@@ -771,7 +969,9 @@ func (s *AdminService) buildPostFilter(filter PostFilter) bson.M {
 	return query
 }
 
-func (s *AdminService) HidePost(ctx context.Context, postID string) error {
+// HidePost hides a post on behalf of a moderator, which also takes it out of
+// the moderation queue if it was hidden automatically
+func (s *AdminService) HidePost(ctx context.Context, postID string, adminID primitive.ObjectID) error {
 	objID, err := primitive.ObjectIDFromHex(postID)
 	if err != nil {
 		return err
@@ -780,6 +980,7 @@ func (s *AdminService) HidePost(ctx context.Context, postID string) error {
 	update := bson.M{
 		"$set": bson.M{
 			"is_hidden":  true,
+			"hidden_by":  adminID,
 			"updated_at": time.Now(),
 		},
 	}
@@ -788,20 +989,28 @@ func (s *AdminService) HidePost(ctx context.Context, postID string) error {
 	return err
 }
 
+// DeletePost soft-deletes a post with its comments, likes, mentions and
+// reports. Posts that are already deleted are left as they are.
 func (s *AdminService) DeletePost(ctx context.Context, postID string) error {
 	objID, err := primitive.ObjectIDFromHex(postID)
 	if err != nil {
 		return err
 	}
 
-	update := bson.M{
-		"$set": bson.M{
-			"deleted_at": time.Now(),
-			"updated_at": time.Now(),
-		},
+	var post models.Post
+	err = s.db.Collection("posts").FindOne(ctx, repository.ByID(objID)).Decode(&post)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
 	}
 
-	_, err = s.db.Collection("posts").UpdateOne(ctx, bson.M{"_id": objID}, update)
+	now := time.Now()
+	_, err = softDeletePost(ctx, s.db, &post, now, bson.M{
+		"deleted_at": now,
+		"updated_at": now,
+	})
 	return err
 }
 
@@ -812,7 +1021,7 @@ func (s *AdminService) GetAllReports(ctx context.Context, filter ReportFilter, p
 	skip := (page - 1) * limit
 	opts := options.Find().SetSkip(int64(skip)).SetLimit(int64(limit)).SetSort(bson.M{"created_at": -1})
 
-	cursor, err := s.db.Collection("reports").Find(ctx, query, opts)
+	cursor, err := s.db.Collection("reports").Find(ctx, query, s.queryGuard.FindOptions(opts))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -823,7 +1032,7 @@ func (s *AdminService) GetAllReports(ctx context.Context, filter ReportFilter, p
 		return nil, nil, err
 	}
 
-	total, err := s.db.Collection("reports").CountDocuments(ctx, query)
+	total, err := s.db.Collection("reports").CountDocuments(ctx, query, s.queryGuard.CountOptions())
 	if err != nil {
 		return nil, nil, err
 	}
@@ -855,7 +1064,7 @@ type ReportFilter struct {
 }
 
 func (s *AdminService) buildReportFilter(filter ReportFilter) bson.M {
-	query := bson.M{"deleted_at": bson.M{"$exists": false}}
+	query := repository.NotDeleted()
 
 	if filter.Status != "" {
 		query["status"] = filter.Status
@@ -916,9 +1125,7 @@ func (s *AdminService) GetAllGroups(ctx context.Context, page, limit int) ([]mod
 	skip := (page - 1) * limit
 	opts := options.Find().SetSkip(int64(skip)).SetLimit(int64(limit)).SetSort(bson.M{"created_at": -1})
 
-	cursor, err := s.db.Collection("groups").Find(ctx, bson.M{
-		"deleted_at": bson.M{"$exists": false},
-	}, opts)
+	cursor, err := s.db.Collection("groups").Find(ctx, repository.NotDeleted(), s.queryGuard.FindOptions(opts))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -929,9 +1136,7 @@ func (s *AdminService) GetAllGroups(ctx context.Context, page, limit int) ([]mod
 		return nil, nil, err
 	}
 
-	total, err := s.db.Collection("groups").CountDocuments(ctx, bson.M{
-		"deleted_at": bson.M{"$exists": false},
-	})
+	total, err := s.db.Collection("groups").CountDocuments(ctx, repository.NotDeleted(), s.queryGuard.CountOptions())
 	if err != nil {
 		return nil, nil, err
 	}
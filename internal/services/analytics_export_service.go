@@ -0,0 +1,838 @@
+// internal/services/analytics_export_service.go
+package services
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// Rows written between progress saves; an interrupted export redoes at most this many
+	analyticsExportCheckpoint = 10000
+
+	// Rows between progress log lines
+	analyticsExportLogEvery = 100000
+
+	// How far a document's created_at may trail its _id timestamp. The
+	// export walks the _id index, bounded by the date range widened by this.
+	analyticsExportIDSlack = 24 * time.Hour
+
+	// Holds the anonymization key of an unfinished export so a resumed run
+	// hashes IDs the same way. Removed once the export completes.
+	analyticsExportKeyFile = ".export_key"
+
+	// Analytics export job statuses
+	exportJobQueued     = "queued"
+	exportJobProcessing = "processing"
+	exportJobCompleted  = "completed"
+	exportJobFailed     = "failed"
+)
+
+// AnalyticsExportService writes behavior analytics collections to CSV or
+// newline-delimited JSON files for offline analysis. Exports stream through
+// cursors, skip users who opted out of analytics, and record their progress
+// in the manifest so an interrupted export resumes where it stopped.
+type AnalyticsExportService struct {
+	db   *mongo.Database
+	jobs *mongo.Collection
+	dir  string
+	ttl  time.Duration
+}
+
+// NewAnalyticsExportService creates an export service. Admin export jobs
+// write below dir and can be downloaded for ttl.
+func NewAnalyticsExportService(db *mongo.Database, dir string, ttl time.Duration) *AnalyticsExportService {
+	return &AnalyticsExportService{
+		db:   db,
+		jobs: db.Collection("export_jobs"),
+		dir:  dir,
+		ttl:  ttl,
+	}
+}
+
+// analyticsRow turns one decoded document into its user and column values
+type analyticsRow func(cursor *mongo.Cursor, anonymize func(string) string) (primitive.ObjectID, []interface{}, error)
+
+// Export writes each analytics collection in the range to opts.OutputDir and
+// returns the manifest. Canceling ctx stops the export after saving progress.
+func (s *AnalyticsExportService) Export(ctx context.Context, opts models.AnalyticsExportOptions) (*models.AnalyticsExportManifest, error) {
+	return s.export(ctx, opts, nil)
+}
+
+func (s *AnalyticsExportService) export(ctx context.Context, opts models.AnalyticsExportOptions, onProgress func(*models.AnalyticsExportManifest)) (*models.AnalyticsExportManifest, error) {
+	// Exports read from a secondary when read routing is enabled
+	ctx = repository.HeavyRead(ctx, nil)
+
+	if !models.IsValidAnalyticsExportFormat(opts.Format) {
+		return nil, fmt.Errorf("invalid export format: %s", opts.Format)
+	}
+	if !opts.From.Before(opts.To) {
+		return nil, errors.New("invalid export range: from must be before to")
+	}
+	if err := os.MkdirAll(opts.OutputDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	manifest, err := s.prepareManifest(opts)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.CompletedAt != nil {
+		return manifest, nil
+	}
+
+	anonymize := func(id string) string { return id }
+	if opts.Anonymize {
+		key, err := loadExportKey(opts.OutputDir)
+		if err != nil {
+			return nil, err
+		}
+		anonymize = func(id string) string {
+			if id == "" {
+				return ""
+			}
+			mac := hmac.New(sha256.New, key)
+			mac.Write([]byte(id))
+			return hex.EncodeToString(mac.Sum(nil)[:16])
+		}
+	}
+
+	optedOut, err := s.optedOutUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range manifest.Files {
+		file := &manifest.Files[i]
+		if file.Completed {
+			continue
+		}
+
+		log.Printf("Analytics export: writing %s", file.File)
+		err := s.exportCollection(ctx, opts, manifest, file, anonymize, optedOut, onProgress)
+		if err != nil {
+			return manifest, fmt.Errorf("failed to export %s: %w", file.Collection, err)
+		}
+		log.Printf("Analytics export: %s done (%d rows, %d excluded)", file.File, file.Rows, file.Excluded)
+	}
+
+	now := time.Now().UTC()
+	manifest.CompletedAt = &now
+	if err := saveAnalyticsManifest(opts.OutputDir, manifest); err != nil {
+		return manifest, err
+	}
+	os.Remove(filepath.Join(opts.OutputDir, analyticsExportKeyFile))
+
+	return manifest, nil
+}
+
+// prepareManifest resumes the unfinished export in the output directory, or
+// starts over when there is none or a restart was asked for
+func (s *AnalyticsExportService) prepareManifest(opts models.AnalyticsExportOptions) (*models.AnalyticsExportManifest, error) {
+	path := filepath.Join(opts.OutputDir, models.AnalyticsExportManifestFile)
+
+	if !opts.Restart {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			var existing models.AnalyticsExportManifest
+			if err := json.Unmarshal(data, &existing); err != nil {
+				return nil, fmt.Errorf("unreadable manifest in %s: %w", opts.OutputDir, err)
+			}
+			if existing.SchemaVersion != models.AnalyticsExportSchemaVersion ||
+				existing.Format != opts.Format ||
+				existing.Anonymized != opts.Anonymize ||
+				!existing.From.Equal(opts.From.UTC()) ||
+				!existing.To.Equal(opts.To.UTC()) {
+				return nil, fmt.Errorf("%s holds an export with different options; restart it or use another directory", opts.OutputDir)
+			}
+			log.Printf("Analytics export: resuming the export in %s", opts.OutputDir)
+			return &existing, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	manifest := &models.AnalyticsExportManifest{
+		SchemaVersion: models.AnalyticsExportSchemaVersion,
+		Format:        opts.Format,
+		From:          opts.From.UTC(),
+		To:            opts.To.UTC(),
+		Anonymized:    opts.Anonymize,
+		StartedAt:     time.Now().UTC(),
+	}
+	for _, collection := range models.AnalyticsExportCollections {
+		file := collection + "." + opts.Format
+		if err := os.Remove(filepath.Join(opts.OutputDir, file)); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		manifest.Files = append(manifest.Files, models.AnalyticsExportFileStatus{
+			Collection: collection,
+			File:       file,
+			Columns:    models.AnalyticsExportColumns[collection],
+		})
+	}
+	if err := os.Remove(filepath.Join(opts.OutputDir, analyticsExportKeyFile)); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return manifest, saveAnalyticsManifest(opts.OutputDir, manifest)
+}
+
+// exportCollection streams one collection to its file in _id order, saving
+// the resume point every analyticsExportCheckpoint rows
+func (s *AnalyticsExportService) exportCollection(ctx context.Context, opts models.AnalyticsExportOptions, manifest *models.AnalyticsExportManifest, status *models.AnalyticsExportFileStatus, anonymize func(string) string, optedOut map[primitive.ObjectID]bool, onProgress func(*models.AnalyticsExportManifest)) error {
+	path := filepath.Join(opts.OutputDir, status.File)
+	resuming := status.LastID != ""
+
+	// Drop anything written after the last saved resume point
+	if resuming {
+		if err := os.Truncate(path, status.Bytes); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if !resuming {
+		if err := file.Truncate(0); err != nil {
+			return err
+		}
+		status.Bytes = 0
+	}
+
+	counter := &countingWriter{w: file, n: status.Bytes}
+	buffered := bufio.NewWriterSize(counter, 256<<10)
+	writer := newAnalyticsWriter(opts.Format, buffered, status.Columns)
+	if !resuming {
+		if err := writer.header(); err != nil {
+			return err
+		}
+	}
+
+	lower := primitive.NewObjectIDFromTimestamp(opts.From.Add(-analyticsExportIDSlack))
+	if resuming {
+		lastID, err := primitive.ObjectIDFromHex(status.LastID)
+		if err != nil {
+			return fmt.Errorf("invalid resume point %q", status.LastID)
+		}
+		lower = lastID
+	}
+	idRange := bson.M{"$lt": primitive.NewObjectIDFromTimestamp(opts.To.Add(analyticsExportIDSlack))}
+	if resuming {
+		idRange["$gt"] = lower
+	} else {
+		idRange["$gte"] = lower
+	}
+
+	filter := bson.M{
+		"_id":        idRange,
+		"created_at": bson.M{"$gte": opts.From, "$lt": opts.To},
+	}
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetBatchSize(1000)
+
+	cursor, err := repository.ReadFrom(ctx, s.db.Collection(status.Collection)).Find(ctx, filter, findOpts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(context.Background())
+
+	build := analyticsRows[status.Collection]
+	checkpoint := func(lastID primitive.ObjectID) error {
+		if err := writer.flush(); err != nil {
+			return err
+		}
+		if err := buffered.Flush(); err != nil {
+			return err
+		}
+		status.LastID = lastID.Hex()
+		status.Bytes = counter.n
+		if onProgress != nil {
+			onProgress(manifest)
+		}
+		return saveAnalyticsManifest(opts.OutputDir, manifest)
+	}
+
+	var lastID primitive.ObjectID
+	sinceCheckpoint := 0
+	for cursor.Next(ctx) {
+		var id struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&id); err != nil {
+			return err
+		}
+		lastID = id.ID
+
+		userID, row, err := build(cursor, anonymize)
+		if err != nil {
+			return err
+		}
+		if optedOut[userID] {
+			status.Excluded++
+		} else {
+			if err := writer.write(row); err != nil {
+				return err
+			}
+			status.Rows++
+			if status.Rows%analyticsExportLogEvery == 0 {
+				log.Printf("Analytics export: %s %d rows", status.File, status.Rows)
+			}
+		}
+
+		sinceCheckpoint++
+		if sinceCheckpoint == analyticsExportCheckpoint {
+			if err := checkpoint(lastID); err != nil {
+				return err
+			}
+			sinceCheckpoint = 0
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		// Keep what was written up to the interruption for the next run
+		if !lastID.IsZero() {
+			if cpErr := checkpoint(lastID); cpErr != nil {
+				log.Printf("Analytics export: failed to save progress of %s: %v", status.File, cpErr)
+			}
+		}
+		return err
+	}
+
+	if err := writer.flush(); err != nil {
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+	status.Completed = true
+	status.LastID = ""
+	status.Bytes = counter.n
+	if onProgress != nil {
+		onProgress(manifest)
+	}
+	return saveAnalyticsManifest(opts.OutputDir, manifest)
+}
+
+// optedOutUsers returns the users who opted out of analytics
+func (s *AnalyticsExportService) optedOutUsers(ctx context.Context) (map[primitive.ObjectID]bool, error) {
+	ids, err := s.db.Collection("users").Distinct(ctx, "_id", bson.M{
+		"privacy_settings.analytics_opt_out": true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	optedOut := make(map[primitive.ObjectID]bool, len(ids))
+	for _, id := range ids {
+		if oid, ok := id.(primitive.ObjectID); ok {
+			optedOut[oid] = true
+		}
+	}
+	return optedOut, nil
+}
+
+// StartJob queues an analytics export for an admin and runs it in the background
+func (s *AnalyticsExportService) StartJob(adminID primitive.ObjectID, req models.StartAnalyticsExportRequest) (*models.ExportJob, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	format := req.Format
+	if format == "" {
+		format = models.AnalyticsExportCSV
+	}
+	if !models.IsValidAnalyticsExportFormat(format) {
+		return nil, fmt.Errorf("invalid export format: %s", format)
+	}
+	if !req.From.Before(req.To) {
+		return nil, errors.New("invalid export range: from must be before to")
+	}
+
+	s.purgeExpiredJobs(ctx)
+
+	job := &models.ExportJob{
+		RequestedBy: adminID,
+		DataType:    models.AnalyticsExportDataType,
+		Format:      format,
+		Parameters: map[string]interface{}{
+			"from":      req.From.UTC(),
+			"to":        req.To.UTC(),
+			"anonymize": req.Anonymize,
+		},
+		Status:    exportJobQueued,
+		ExpiresAt: time.Now().Add(s.ttl),
+	}
+	job.BeforeCreate()
+	job.ID = primitive.NewObjectID()
+	job.ExportID = job.ID.Hex()
+
+	if _, err := s.jobs.InsertOne(ctx, job); err != nil {
+		return nil, err
+	}
+
+	go s.runJob(*job)
+
+	return job, nil
+}
+
+// ResumeInterruptedJobs restarts the export jobs a previous process left
+// unfinished. Their files pick up from the last saved progress.
+func (s *AnalyticsExportService) ResumeInterruptedJobs() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := s.jobs.Find(ctx, bson.M{
+		"data_type":  models.AnalyticsExportDataType,
+		"status":     bson.M{"$in": []string{exportJobQueued, exportJobProcessing}},
+		"expires_at": bson.M{"$gt": time.Now()},
+	})
+	if err != nil {
+		log.Printf("Failed to load interrupted analytics exports: %v", err)
+		return
+	}
+
+	var jobs []models.ExportJob
+	if err := cursor.All(ctx, &jobs); err != nil {
+		log.Printf("Failed to load interrupted analytics exports: %v", err)
+		return
+	}
+
+	for _, job := range jobs {
+		log.Printf("Resuming analytics export %s", job.ExportID)
+		go s.runJob(job)
+	}
+}
+
+// runJob runs an export job to completion, recording progress on the job
+func (s *AnalyticsExportService) runJob(job models.ExportJob) {
+	from, _ := job.Parameters["from"].(primitive.DateTime)
+	to, _ := job.Parameters["to"].(primitive.DateTime)
+	anonymize, _ := job.Parameters["anonymize"].(bool)
+	opts := models.AnalyticsExportOptions{
+		From:      from.Time(),
+		To:        to.Time(),
+		Format:    job.Format,
+		Anonymize: anonymize,
+		OutputDir: s.jobDir(job.ExportID),
+	}
+	// Jobs started in this process still hold native values
+	if t, ok := job.Parameters["from"].(time.Time); ok {
+		opts.From = t
+	}
+	if t, ok := job.Parameters["to"].(time.Time); ok {
+		opts.To = t
+	}
+
+	now := time.Now()
+	s.updateJob(job.ID, bson.M{"status": exportJobProcessing, "started_at": now})
+
+	manifest, err := s.export(context.Background(), opts, func(manifest *models.AnalyticsExportManifest) {
+		done, rows := 0, int64(0)
+		for _, file := range manifest.Files {
+			if file.Completed {
+				done++
+			}
+			rows += file.Rows
+		}
+		s.updateJob(job.ID, bson.M{
+			"progress":     float64(done) / float64(len(manifest.Files)) * 100,
+			"record_count": rows,
+		})
+	})
+	if err != nil {
+		log.Printf("Analytics export %s failed: %v", job.ExportID, err)
+		s.updateJob(job.ID, bson.M{
+			"status":        exportJobFailed,
+			"error_message": err.Error(),
+			"completed_at":  time.Now(),
+		})
+		return
+	}
+
+	var files []models.ExportFile
+	var totalSize, rows int64
+	baseURL := "/api/v1/admin/analytics/exports/" + job.ExportID + "/files/"
+	names := []string{models.AnalyticsExportManifestFile}
+	for _, file := range manifest.Files {
+		names = append(names, file.File)
+		rows += file.Rows
+	}
+	for _, name := range names {
+		info, err := os.Stat(filepath.Join(opts.OutputDir, name))
+		if err != nil {
+			continue
+		}
+		files = append(files, models.ExportFile{Name: name, URL: baseURL + name, Size: info.Size()})
+		totalSize += info.Size()
+	}
+
+	s.updateJob(job.ID, bson.M{
+		"status":       exportJobCompleted,
+		"progress":     100.0,
+		"record_count": rows,
+		"files":        files,
+		"file_url":     baseURL + models.AnalyticsExportManifestFile,
+		"file_size":    totalSize,
+		"completed_at": time.Now(),
+	})
+}
+
+// GetJob retrieves an analytics export job
+func (s *AnalyticsExportService) GetJob(exportID string) (*models.ExportJob, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var job models.ExportJob
+	err := s.jobs.FindOne(ctx, bson.M{
+		"export_id": exportID,
+		"data_type": models.AnalyticsExportDataType,
+	}).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("export not found")
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ListJobs returns analytics export jobs, newest first
+func (s *AnalyticsExportService) ListJobs(limit, skip int) ([]models.ExportJob, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"data_type": models.AnalyticsExportDataType}
+	total, err := s.jobs.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	cursor, err := s.jobs.Find(ctx, filter, options.Find().
+		SetSort(bson.M{"created_at": -1}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit)))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	jobs := []models.ExportJob{}
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, 0, err
+	}
+	return jobs, total, nil
+}
+
+// JobFilePath returns where a completed job's file is stored, if it can
+// still be downloaded
+func (s *AnalyticsExportService) JobFilePath(exportID, name string) (string, error) {
+	job, err := s.GetJob(exportID)
+	if err != nil {
+		return "", err
+	}
+	if job.Status != exportJobCompleted {
+		return "", errors.New("export is not completed")
+	}
+	if time.Now().After(job.ExpiresAt) {
+		return "", errors.New("export has expired")
+	}
+
+	for _, file := range job.Files {
+		if file.Name == name {
+			return filepath.Join(s.jobDir(job.ExportID), file.Name), nil
+		}
+	}
+	return "", errors.New("export file not found")
+}
+
+// purgeExpiredJobs deletes expired jobs and their files
+func (s *AnalyticsExportService) purgeExpiredJobs(ctx context.Context) {
+	cursor, err := s.jobs.Find(ctx, bson.M{
+		"data_type":  models.AnalyticsExportDataType,
+		"expires_at": bson.M{"$lte": time.Now()},
+	}, options.Find().SetProjection(bson.M{"export_id": 1}))
+	if err != nil {
+		return
+	}
+
+	var expired []models.ExportJob
+	if err := cursor.All(ctx, &expired); err != nil {
+		return
+	}
+
+	for _, job := range expired {
+		if err := os.RemoveAll(s.jobDir(job.ExportID)); err != nil {
+			log.Printf("Failed to remove expired analytics export %s: %v", job.ExportID, err)
+			continue
+		}
+		s.jobs.DeleteOne(ctx, bson.M{"_id": job.ID})
+	}
+}
+
+func (s *AnalyticsExportService) updateJob(jobID primitive.ObjectID, set bson.M) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	set["updated_at"] = time.Now()
+	if _, err := s.jobs.UpdateOne(ctx, bson.M{"_id": jobID}, bson.M{"$set": set}); err != nil {
+		log.Printf("Failed to update export job %s: %v", jobID.Hex(), err)
+	}
+}
+
+// jobDir is where a job writes its files. Export IDs are generated object IDs,
+// so they can't escape the export directory.
+func (s *AnalyticsExportService) jobDir(exportID string) string {
+	return filepath.Join(s.dir, filepath.Base(exportID))
+}
+
+// saveAnalyticsManifest atomically replaces the manifest
+func saveAnalyticsManifest(dir string, manifest *models.AnalyticsExportManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, models.AnalyticsExportManifestFile)
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// loadExportKey reads the anonymization key of the export in dir, creating
+// one for a new export
+func loadExportKey(dir string) ([]byte, error) {
+	path := filepath.Join(dir, analyticsExportKeyFile)
+	if data, err := os.ReadFile(path); err == nil {
+		return hex.DecodeString(strings.TrimSpace(string(data)))
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(key)), 0o600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// countingWriter tracks the file size so a resume can cut off unsaved rows
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// analyticsWriter writes rows in one of the export formats
+type analyticsWriter struct {
+	format  string
+	columns []string
+	w       *bufio.Writer
+	csv     *csv.Writer
+}
+
+func newAnalyticsWriter(format string, w *bufio.Writer, columns []string) *analyticsWriter {
+	writer := &analyticsWriter{format: format, columns: columns, w: w}
+	if format == models.AnalyticsExportCSV {
+		writer.csv = csv.NewWriter(w)
+	}
+	return writer
+}
+
+// header writes the CSV header row; NDJSON rows name their own fields
+func (aw *analyticsWriter) header() error {
+	if aw.csv == nil {
+		return nil
+	}
+	return aw.csv.Write(aw.columns)
+}
+
+func (aw *analyticsWriter) write(values []interface{}) error {
+	if aw.csv != nil {
+		record := make([]string, len(values))
+		for i, value := range values {
+			record[i] = formatAnalyticsValue(value)
+		}
+		return aw.csv.Write(record)
+	}
+
+	// Keep the documented column order in every object
+	aw.w.WriteByte('{')
+	for i, column := range aw.columns {
+		if i > 0 {
+			aw.w.WriteByte(',')
+		}
+		key, _ := json.Marshal(column)
+		aw.w.Write(key)
+		aw.w.WriteByte(':')
+
+		value := values[i]
+		if t, ok := value.(time.Time); ok {
+			value = t.UTC().Format(time.RFC3339)
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		aw.w.Write(encoded)
+	}
+	aw.w.WriteByte('}')
+	return aw.w.WriteByte('\n')
+}
+
+func (aw *analyticsWriter) flush() error {
+	if aw.csv != nil {
+		aw.csv.Flush()
+		return aw.csv.Error()
+	}
+	return nil
+}
+
+// formatAnalyticsValue renders a column value as CSV text; nil is empty
+func formatAnalyticsValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// optionalTime is nil for unset times so they export as empty or null
+func optionalTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+// analyticsRows builds the rows of each exported collection, in the column
+// order of models.AnalyticsExportColumns
+var analyticsRows = map[string]analyticsRow{
+	"user_sessions": func(cursor *mongo.Cursor, anonymize func(string) string) (primitive.ObjectID, []interface{}, error) {
+		var session models.UserSession
+		if err := cursor.Decode(&session); err != nil {
+			return primitive.NilObjectID, nil, err
+		}
+		return session.UserID, []interface{}{
+			session.ID.Hex(),
+			anonymize(session.UserID.Hex()),
+			anonymize(session.SessionID),
+			session.StartTime,
+			optionalTime(session.EndTime),
+			session.Duration,
+			session.DeviceInfo,
+			len(session.PagesVisited),
+			len(session.Actions),
+			session.CreatedAt,
+		}, nil
+	},
+	"content_engagements": func(cursor *mongo.Cursor, anonymize func(string) string) (primitive.ObjectID, []interface{}, error) {
+		var engagement models.ContentEngagement
+		if err := cursor.Decode(&engagement); err != nil {
+			return primitive.NilObjectID, nil, err
+		}
+		types := make([]string, len(engagement.Interactions))
+		for i, interaction := range engagement.Interactions {
+			types[i] = interaction.Type
+		}
+		return engagement.UserID, []interface{}{
+			engagement.ID.Hex(),
+			anonymize(engagement.UserID.Hex()),
+			engagement.ContentID.Hex(),
+			engagement.ContentType,
+			engagement.ViewTime,
+			engagement.ViewDuration,
+			engagement.ScrollDepth,
+			strings.Join(types, ";"),
+			engagement.Source,
+			engagement.CreatedAt,
+		}, nil
+	},
+	"user_journeys": func(cursor *mongo.Cursor, anonymize func(string) string) (primitive.ObjectID, []interface{}, error) {
+		var journey models.UserJourney
+		if err := cursor.Decode(&journey); err != nil {
+			return primitive.NilObjectID, nil, err
+		}
+		pages := make([]string, len(journey.Touchpoints))
+		for i, touchpoint := range journey.Touchpoints {
+			pages[i] = touchpoint.Page
+		}
+		return journey.UserID, []interface{}{
+			journey.ID.Hex(),
+			anonymize(journey.UserID.Hex()),
+			anonymize(journey.SessionID),
+			journey.Goal,
+			journey.Completed,
+			journey.Duration,
+			len(journey.Touchpoints),
+			strings.Join(pages, ";"),
+			journey.CreatedAt,
+		}, nil
+	},
+	"recommendation_events": func(cursor *mongo.Cursor, anonymize func(string) string) (primitive.ObjectID, []interface{}, error) {
+		var event models.RecommendationEvent
+		if err := cursor.Decode(&event); err != nil {
+			return primitive.NilObjectID, nil, err
+		}
+		return event.UserID, []interface{}{
+			event.ID.Hex(),
+			anonymize(event.UserID.Hex()),
+			event.RecommendationType,
+			event.ItemID.Hex(),
+			event.Algorithm,
+			event.Score,
+			event.Position,
+			event.Presented,
+			optionalTime(event.Clicked),
+			optionalTime(event.Converted),
+			event.Feedback,
+			event.CreatedAt,
+		}, nil
+	},
+}
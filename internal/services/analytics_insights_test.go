@@ -0,0 +1,65 @@
+package services_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestUserInsightsTopCategoriesUseHashtagCategories(t *testing.T) {
+	h := testutil.NewHarness(t)
+	analytics := services.NewAnalyticsService(services.AudienceActivityPolicy{})
+	viewer := h.CreateUser()
+	author := h.CreateUser()
+
+	for tag, category := range map[string]string{"football": "sports", "soccer": "sports", "election": "news"} {
+		if _, err := h.DB.Collection("hashtags").InsertOne(h.Context(), bson.M{"tag": tag, "normalized_tag": tag, "category": category}); err != nil {
+			t.Fatalf("inserting hashtag: %v", err)
+		}
+	}
+
+	tagged := func(contentType models.ContentType, tags ...string) *models.Post {
+		return h.CreatePost(author, func(post *models.Post) {
+			post.ContentType = contentType
+			post.Hashtags = tags
+		})
+	}
+	bothSports := tagged(models.ContentTypeImage, "Football", "soccer") // Counted once for sports
+	news := tagged(models.ContentTypeText, "election")
+	uncategorized := tagged(models.ContentTypeText, "somethingnew")
+	untagged := tagged(models.ContentTypeVideo)
+
+	for _, post := range []*models.Post{bothSports, bothSports, news, uncategorized, untagged} {
+		engagement := models.ContentEngagement{
+			UserID:      viewer.ID,
+			ContentID:   post.ID,
+			ContentType: "post",
+			ViewTime:    time.Now().Add(-time.Hour),
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+		if _, err := h.DB.Collection("content_engagements").InsertOne(h.Context(), engagement); err != nil {
+			t.Fatalf("inserting engagement: %v", err)
+		}
+	}
+
+	insights, err := analytics.GetUserInsights(viewer.ID)
+	if err != nil {
+		t.Fatalf("GetUserInsights: %v", err)
+	}
+
+	want := []services.InsightBucket{
+		{Name: "sports", Count: 2},
+		{Name: models.HashtagCategoryFallback, Count: 1},
+		{Name: "news", Count: 1},
+	}
+	if !reflect.DeepEqual(insights.TopCategories, want) {
+		t.Errorf("top categories = %+v, want %+v", insights.TopCategories, want)
+	}
+}
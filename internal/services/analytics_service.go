@@ -3,10 +3,16 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
 	"time"
 
 	"social-media-api/internal/config"
 	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -15,10 +21,21 @@ import (
 )
 
 type AnalyticsService struct {
-	eventsCollection *mongo.Collection
-	userCollection   *mongo.Collection
-	postCollection   *mongo.Collection
-	db               *mongo.Database
+	eventsCollection   *mongo.Collection
+	userCollection     *mongo.Collection
+	postCollection     *mongo.Collection
+	audienceCollection *mongo.Collection
+	db                 *mongo.Database
+	audiencePolicy     AudienceActivityPolicy
+	stopAudienceJob    context.CancelFunc
+}
+
+// AudienceActivityPolicy decides which creators get audience activity
+// insights and caps the cost of computing them
+type AudienceActivityPolicy struct {
+	MinFollowers   int64
+	IncludePremium bool // Premium accounts qualify regardless of follower count
+	SampleSize     int  // Larger audiences are sampled down to this many followers
 }
 
 type AnalyticsEvent struct {
@@ -136,12 +153,122 @@ type GrowthData struct {
 	Engagement int64     `json:"engagement"`
 }
 
-func NewAnalyticsService() *AnalyticsService {
+// UserInsights summarizes a user's own activity for the "your activity" screen
+type UserInsights struct {
+	UserID             primitive.ObjectID `json:"user_id" bson:"user_id"`
+	TopCategories      []InsightBucket    `json:"top_categories" bson:"top_categories"` // Categories of the hashtags on posts engaged with
+	TopHashtags        []InsightBucket    `json:"top_hashtags" bson:"top_hashtags"`
+	MostActiveHours    []HourlyActivity   `json:"most_active_hours" bson:"most_active_hours"`
+	Timezone           string             `json:"timezone" bson:"timezone"`
+	AvgSessionLength   float64            `json:"avg_session_length" bson:"avg_session_length"` // in seconds
+	TotalSessions      int64              `json:"total_sessions" bson:"total_sessions"`
+	EngagementTrend    []DailyEngagement  `json:"engagement_trend" bson:"engagement_trend"`
+	TrendChangePercent float64            `json:"trend_change_percent" bson:"trend_change_percent"` // last 7 days vs the 7 before
+	PeriodDays         int                `json:"period_days" bson:"period_days"`
+	GeneratedAt        time.Time          `json:"generated_at" bson:"generated_at"`
+	ExpiresAt          time.Time          `json:"-" bson:"expires_at"`
+
+	// Stored separately by the nightly audience job and attached on every read
+	AudienceActivity *AudienceActivity `json:"audience_activity,omitempty" bson:"-"`
+}
+
+// CreatorSummary totals the engagement a creator's posts received over a
+// period, for the creator dashboard. Engagement by the creator themselves
+// isn't counted.
+type CreatorSummary struct {
+	UserID       primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Period       string             `json:"period" bson:"period"`
+	Since        time.Time          `json:"since" bson:"since"`
+	Likes        int64              `json:"likes" bson:"likes"`
+	Comments     int64              `json:"comments" bson:"comments"`
+	Shares       int64              `json:"shares" bson:"shares"` // Reposts
+	Views        int64              `json:"views" bson:"views"`
+	NewFollowers int64              `json:"new_followers" bson:"new_followers"`
+	TopPosts     []CreatorTopPost   `json:"top_posts" bson:"top_posts"`
+	GeneratedAt  time.Time          `json:"generated_at" bson:"generated_at"`
+	ExpiresAt    time.Time          `json:"-" bson:"expires_at"`
+}
+
+// CreatorTopPost is one of the creator's most engaged posts over the period
+type CreatorTopPost struct {
+	PostID     primitive.ObjectID `json:"post_id" bson:"post_id"`
+	Content    string             `json:"content" bson:"content"` // Truncated preview
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+	Likes      int64              `json:"likes" bson:"likes"`
+	Comments   int64              `json:"comments" bson:"comments"`
+	Shares     int64              `json:"shares" bson:"shares"`
+	Views      int64              `json:"views" bson:"views"`
+	Engagement int64              `json:"engagement" bson:"engagement"` // Likes, comments and shares
+}
+
+// Audience activity states
+const (
+	AudienceActivityReady         = "ready"
+	AudienceActivityNotEnoughData = "not_enough_data"
+	AudienceActivityPending       = "pending" // Eligible, but the nightly job hasn't summarized the audience yet
+)
+
+// AudienceActivity summarizes when a creator's followers are active. The
+// nightly job stores one per eligible creator in audience_activity.
+type AudienceActivity struct {
+	UserID           primitive.ObjectID `json:"-" bson:"user_id"`
+	Status           string             `json:"status" bson:"status"`
+	Reason           string             `json:"reason,omitempty" bson:"reason,omitempty"`
+	Timezone         string             `json:"timezone,omitempty" bson:"timezone"`
+	Heatmap          [][]float64        `json:"heatmap,omitempty" bson:"heatmap,omitempty"` // [weekday][hour], Sunday first; 1 is the busiest hour
+	SuggestedWindow  *PostingWindow     `json:"suggested_window,omitempty" bson:"suggested_window,omitempty"`
+	ActiveFollowers  int64              `json:"active_followers" bson:"active_followers"`
+	SampledFollowers int64              `json:"sampled_followers" bson:"sampled_followers"`
+	Source           string             `json:"source,omitempty" bson:"source,omitempty"` // sessions, or engagements when sessions are sparse
+	PeriodDays       int                `json:"period_days,omitempty" bson:"period_days"`
+	GeneratedAt      *time.Time         `json:"generated_at,omitempty" bson:"generated_at"`
+}
+
+// PostingWindow is the run of hours when a creator's followers are most active
+type PostingWindow struct {
+	Weekday   string `json:"weekday" bson:"weekday"`
+	StartHour int    `json:"start_hour" bson:"start_hour"`
+	EndHour   int    `json:"end_hour" bson:"end_hour"` // Exclusive; below StartHour when the window runs past midnight
+}
+
+type InsightBucket struct {
+	Name  string `json:"name" bson:"_id"`
+	Count int64  `json:"count" bson:"count"`
+}
+
+type HourlyActivity struct {
+	Hour  int   `json:"hour" bson:"_id"`
+	Count int64 `json:"count" bson:"count"`
+}
+
+type DailyEngagement struct {
+	Date         string `json:"date" bson:"_id"`
+	Views        int64  `json:"views" bson:"views"`
+	Interactions int64  `json:"interactions" bson:"interactions"`
+}
+
+const (
+	userInsightsPeriodDays = 30
+	userInsightsCacheTTL   = 1 * time.Hour
+
+	creatorSummaryCacheTTL = 15 * time.Minute
+	creatorSummaryTopPosts = 5
+
+	audiencePeriodDays         = 28 // Four of each weekday
+	audienceMinActiveFollowers = 20
+	audienceMinActivity        = 100 // Follower-hours of activity needed for a meaningful heatmap
+	audienceWindowHours        = 3
+	AudienceRefreshInterval    = 24 * time.Hour
+)
+
+func NewAnalyticsService(audiencePolicy AudienceActivityPolicy) *AnalyticsService {
 	return &AnalyticsService{
-		eventsCollection: config.DB.Collection("analytics_events"),
-		userCollection:   config.DB.Collection("users"),
-		postCollection:   config.DB.Collection("posts"),
-		db:               config.DB,
+		eventsCollection:   config.DB.Collection("analytics_events"),
+		userCollection:     config.DB.Collection("users"),
+		postCollection:     config.DB.Collection("posts"),
+		audienceCollection: config.DB.Collection("audience_activity"),
+		db:                 config.DB,
+		audiencePolicy:     audiencePolicy,
 	}
 }
 
@@ -222,6 +349,7 @@ func (as *AnalyticsService) TrackPostView(userID *primitive.ObjectID, postID pri
 func (as *AnalyticsService) GetUserAnalytics(userID primitive.ObjectID, timeRange string) (*UserAnalytics, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
+	ctx = repository.HeavyRead(ctx, nil)
 
 	// Calculate time filter
 	timeFilter := as.getTimeFilter(timeRange)
@@ -283,7 +411,7 @@ func (as *AnalyticsService) GetUserAnalytics(userID primitive.ObjectID, timeRang
 		},
 	}
 
-	cursor, err := as.eventsCollection.Aggregate(ctx, pipeline)
+	cursor, err := repository.ReadFrom(ctx, as.eventsCollection).Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -307,6 +435,7 @@ func (as *AnalyticsService) GetUserAnalytics(userID primitive.ObjectID, timeRang
 func (as *AnalyticsService) GetPostAnalytics(postID primitive.ObjectID, timeRange string) (*PostAnalytics, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
+	ctx = repository.HeavyRead(ctx, nil)
 
 	timeFilter := as.getTimeFilter(timeRange)
 
@@ -375,7 +504,7 @@ func (as *AnalyticsService) GetPostAnalytics(postID primitive.ObjectID, timeRang
 		},
 	}
 
-	cursor, err := as.eventsCollection.Aggregate(ctx, pipeline)
+	cursor, err := repository.ReadFrom(ctx, as.eventsCollection).Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -408,6 +537,7 @@ func (as *AnalyticsService) GetPostAnalytics(postID primitive.ObjectID, timeRang
 func (as *AnalyticsService) GetPlatformStats(timeRange string) (*PlatformStats, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
+	ctx = repository.HeavyRead(ctx, nil)
 
 	timeFilter := as.getTimeFilter(timeRange)
 
@@ -459,6 +589,7 @@ func (as *AnalyticsService) GetPlatformStats(timeRange string) (*PlatformStats,
 func (as *AnalyticsService) GetTopContent(contentType, timeRange string, limit int) ([]ContentPerformance, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
+	ctx = repository.HeavyRead(ctx, nil)
 
 	timeFilter := as.getTimeFilter(timeRange)
 
@@ -525,7 +656,7 @@ func (as *AnalyticsService) GetTopContent(contentType, timeRange string, limit i
 		},
 	}
 
-	cursor, err := as.eventsCollection.Aggregate(ctx, pipeline)
+	cursor, err := repository.ReadFrom(ctx, as.eventsCollection).Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -570,6 +701,7 @@ func (as *AnalyticsService) GetTopContent(contentType, timeRange string, limit i
 func (as *AnalyticsService) GetUserEngagement(userID primitive.ObjectID, timeRange string) (map[string]interface{}, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
+	ctx = repository.HeavyRead(ctx, nil)
 
 	timeFilter := as.getTimeFilter(timeRange)
 
@@ -622,7 +754,7 @@ func (as *AnalyticsService) GetUserEngagement(userID primitive.ObjectID, timeRan
 		},
 	}
 
-	cursor, err := as.eventsCollection.Aggregate(ctx, pipeline)
+	cursor, err := repository.ReadFrom(ctx, as.eventsCollection).Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -642,8 +774,1003 @@ func (as *AnalyticsService) GetUserEngagement(userID primitive.ObjectID, timeRan
 	return result, nil
 }
 
+// GetUserInsights returns a summary of the user's own behavior, cached per user
+func (as *AnalyticsService) GetUserInsights(userID primitive.ObjectID) (*UserInsights, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ctx = repository.HeavyRead(ctx, nil)
+
+	cacheCollection := as.db.Collection("user_insights_cache")
+
+	var cached UserInsights
+	err := cacheCollection.FindOne(ctx, bson.M{
+		"user_id":    userID,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&cached)
+	if err == nil {
+		as.attachAudienceActivity(&cached)
+		return &cached, nil
+	}
+
+	since := time.Now().AddDate(0, 0, -userInsightsPeriodDays)
+	timezone := as.getUserTimezone(ctx, userID)
+
+	insights := &UserInsights{
+		UserID:      userID,
+		Timezone:    timezone,
+		PeriodDays:  userInsightsPeriodDays,
+		GeneratedAt: time.Now(),
+		ExpiresAt:   time.Now().Add(userInsightsCacheTTL),
+	}
+
+	if insights.TopCategories, err = as.getTopEngagedCategories(ctx, userID, since); err != nil {
+		return nil, err
+	}
+	if insights.TopHashtags, err = as.getTopEngagedHashtags(ctx, userID, since); err != nil {
+		return nil, err
+	}
+	if insights.MostActiveHours, err = as.getMostActiveHours(ctx, userID, since, timezone); err != nil {
+		return nil, err
+	}
+	if err := as.getSessionLengthStats(ctx, userID, since, insights); err != nil {
+		return nil, err
+	}
+	if insights.EngagementTrend, err = as.getEngagementTrend(ctx, userID, since, timezone); err != nil {
+		return nil, err
+	}
+	insights.TrendChangePercent = calculateTrendChange(insights.EngagementTrend)
+
+	opts := options.Replace().SetUpsert(true)
+	cacheCollection.ReplaceOne(ctx, bson.M{"user_id": userID}, insights, opts)
+
+	as.attachAudienceActivity(insights)
+	return insights, nil
+}
+
+// GetCreatorSummary returns the likes, comments, shares, views and new
+// followers a creator's posts brought in over the period (day, week, month or
+// year) and their top posts, cached per user and period
+func (as *AnalyticsService) GetCreatorSummary(userID primitive.ObjectID, period string) (*CreatorSummary, error) {
+	switch period {
+	case "day", "week", "month", "year":
+	default:
+		return nil, errors.New("invalid period: must be day, week, month or year")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ctx = repository.HeavyRead(ctx, nil)
+
+	cacheCollection := as.db.Collection("creator_summary_cache")
+
+	var cached CreatorSummary
+	err := cacheCollection.FindOne(ctx, bson.M{
+		"user_id":    userID,
+		"period":     period,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&cached)
+	if err == nil {
+		return &cached, nil
+	}
+
+	now := time.Now()
+	since := as.getTimeFilter(period)
+	summary := &CreatorSummary{
+		UserID:      userID,
+		Period:      period,
+		Since:       since,
+		TopPosts:    []CreatorTopPost{},
+		GeneratedAt: now,
+		ExpiresAt:   now.Add(creatorSummaryCacheTTL),
+	}
+
+	summary.NewFollowers, err = repository.ReadFrom(ctx, as.db.Collection("follows")).CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"followee_id": userID,
+		"status":      models.FollowStatusAccepted,
+		"created_at":  bson.M{"$gte": since},
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	postIDs, err := as.getCreatorPostIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(postIDs) > 0 {
+		likes, err := as.countByPost(ctx, "likes", "target_id", repository.NotDeleted(bson.M{
+			"target_type": "post",
+			"target_id":   bson.M{"$in": postIDs},
+			"user_id":     bson.M{"$ne": userID},
+			"created_at":  bson.M{"$gte": since},
+		}))
+		if err != nil {
+			return nil, err
+		}
+		comments, err := as.countByPost(ctx, "comments", "post_id", repository.NotDeleted(bson.M{
+			"post_id":    bson.M{"$in": postIDs},
+			"user_id":    bson.M{"$ne": userID},
+			"created_at": bson.M{"$gte": since},
+		}))
+		if err != nil {
+			return nil, err
+		}
+		shares, err := as.countByPost(ctx, "posts", "original_post_id", repository.NotDeleted(bson.M{
+			"original_post_id": bson.M{"$in": postIDs},
+			"user_id":          bson.M{"$ne": userID},
+			"created_at":       bson.M{"$gte": since},
+		}))
+		if err != nil {
+			return nil, err
+		}
+		views, err := as.countByPost(ctx, "content_engagements", "content_id", bson.M{
+			"content_type": "post",
+			"content_id":   bson.M{"$in": postIDs},
+			"user_id":      bson.M{"$ne": userID},
+			"view_time":    bson.M{"$gte": since},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		engaged := map[primitive.ObjectID]*CreatorTopPost{}
+		for _, counts := range []struct {
+			byPost map[primitive.ObjectID]int64
+			total  *int64
+			field  func(*CreatorTopPost) *int64
+		}{
+			{likes, &summary.Likes, func(p *CreatorTopPost) *int64 { return &p.Likes }},
+			{comments, &summary.Comments, func(p *CreatorTopPost) *int64 { return &p.Comments }},
+			{shares, &summary.Shares, func(p *CreatorTopPost) *int64 { return &p.Shares }},
+			{views, &summary.Views, func(p *CreatorTopPost) *int64 { return &p.Views }},
+		} {
+			for postID, count := range counts.byPost {
+				*counts.total += count
+				post, ok := engaged[postID]
+				if !ok {
+					post = &CreatorTopPost{PostID: postID}
+					engaged[postID] = post
+				}
+				*counts.field(post) = count
+			}
+		}
+
+		if summary.TopPosts, err = as.getCreatorTopPosts(ctx, engaged); err != nil {
+			return nil, err
+		}
+	}
+
+	opts := options.Replace().SetUpsert(true)
+	cacheCollection.ReplaceOne(ctx, bson.M{"user_id": userID, "period": period}, summary, opts)
+
+	return summary, nil
+}
+
+// GetAudienceActivity returns the creator's follower activity heatmap and
+// suggested posting window, as last computed by the nightly job
+func (as *AnalyticsService) GetAudienceActivity(userID primitive.ObjectID) (*AudienceActivity, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = repository.HeavyRead(ctx, nil)
+
+	var user models.User
+	err := as.userCollection.FindOne(ctx, bson.M{"_id": userID},
+		options.FindOne().SetProjection(bson.M{"followers_count": 1, "is_premium": 1})).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, err
+	}
+
+	if !as.audiencePolicy.isEligible(&user) {
+		return &AudienceActivity{
+			Status: AudienceActivityNotEnoughData,
+			Reason: fmt.Sprintf("Audience activity is available once you have %d followers", as.audiencePolicy.MinFollowers),
+		}, nil
+	}
+
+	var activity AudienceActivity
+	err = as.audienceCollection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&activity)
+	if err == mongo.ErrNoDocuments {
+		return &AudienceActivity{
+			Status: AudienceActivityPending,
+			Reason: "Your audience activity is being prepared and will be ready within a day",
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &activity, nil
+}
+
+// RefreshAudienceActivity recomputes the audience activity summary of every eligible creator
+func (as *AnalyticsService) RefreshAudienceActivity(ctx context.Context) (int, error) {
+	ctx = repository.HeavyRead(ctx, nil)
+	eligible := []bson.M{{"followers_count": bson.M{"$gte": as.audiencePolicy.MinFollowers}}}
+	if as.audiencePolicy.IncludePremium {
+		eligible = append(eligible, bson.M{"is_premium": true})
+	}
+
+	filter := repository.NotDeleted(bson.M{
+		"is_active": true,
+		"$or":       eligible,
+	})
+	opts := options.Find().SetProjection(bson.M{"_id": 1, "timezone": 1})
+
+	cursor, err := repository.ReadFrom(ctx, as.userCollection).Find(ctx, filter, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	refreshed := 0
+	for cursor.Next(ctx) {
+		var creator models.User
+		if err := cursor.Decode(&creator); err != nil {
+			continue
+		}
+
+		if err := as.refreshCreatorAudience(ctx, creator.ID, validTimezone(creator.Timezone)); err != nil {
+			if ctx.Err() != nil {
+				return refreshed, ctx.Err()
+			}
+			log.Printf("Failed to refresh audience activity for user %s: %v", creator.ID.Hex(), err)
+			continue
+		}
+		refreshed++
+	}
+
+	return refreshed, cursor.Err()
+}
+
+// StartAudienceJob runs RefreshAudienceActivity every interval until StopAudienceJob is called
+func (as *AnalyticsService) StartAudienceJob(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	as.stopAudienceJob = cancel
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				start := time.Now()
+				refreshed, err := as.RefreshAudienceActivity(ctx)
+				if ctx.Err() == nil {
+					recordJobHeartbeat(as.db, "audience_activity", interval, err)
+				}
+				if err != nil && ctx.Err() == nil {
+					log.Printf("Audience activity refresh failed after %d creators: %v", refreshed, err)
+					continue
+				}
+				log.Printf("Refreshed audience activity for %d creators in %s", refreshed, time.Since(start).Round(time.Second))
+			}
+		}
+	}()
+}
+
+// StopAudienceJob stops the periodic audience activity refresh
+func (as *AnalyticsService) StopAudienceJob() {
+	if as.stopAudienceJob != nil {
+		as.stopAudienceJob()
+	}
+}
+
+// GetTranslationUsage aggregates translation requests per language pair
+func (as *AnalyticsService) GetTranslationUsage(timeRange string, limit int) ([]models.TranslationUsageStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	ctx = repository.HeavyRead(ctx, nil)
+
+	timeFilter := as.getTimeFilter(timeRange)
+
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"created_at": bson.M{"$gte": timeFilter},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id": bson.M{
+					"source": "$source_language",
+					"target": "$target_language",
+				},
+				"requests":   bson.M{"$sum": 1},
+				"cache_hits": bson.M{"$sum": bson.M{"$cond": []interface{}{"$cached", 1, 0}}},
+				"characters": bson.M{"$sum": "$characters"},
+				"users":      bson.M{"$addToSet": "$user_id"},
+			},
+		},
+		{
+			"$project": bson.M{
+				"_id":             0,
+				"source_language": "$_id.source",
+				"target_language": "$_id.target",
+				"requests":        1,
+				"cache_hits":      1,
+				"characters":      1,
+				"unique_users":    bson.M{"$size": "$users"},
+			},
+		},
+		{
+			"$sort": bson.M{"requests": -1},
+		},
+		{
+			"$limit": limit,
+		},
+	}
+
+	cursor, err := repository.ReadFrom(ctx, as.db.Collection("translation_usage")).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var usage []models.TranslationUsageStats
+	if err := cursor.All(ctx, &usage); err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
 // Helper methods
 
+func (as *AnalyticsService) getUserTimezone(ctx context.Context, userID primitive.ObjectID) string {
+	var user models.User
+	err := as.userCollection.FindOne(ctx, bson.M{"_id": userID},
+		options.FindOne().SetProjection(bson.M{"timezone": 1})).Decode(&user)
+	if err != nil {
+		return "UTC"
+	}
+	return validTimezone(user.Timezone)
+}
+
+// validTimezone returns the timezone, or UTC when it is empty or unknown.
+// MongoDB rejects unknown zones, so only pass through ones Go recognises.
+func validTimezone(timezone string) string {
+	if timezone == "" {
+		return "UTC"
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return "UTC"
+	}
+	return timezone
+}
+
+// attachAudienceActivity adds the creator's audience summary to their insights
+func (as *AnalyticsService) attachAudienceActivity(insights *UserInsights) {
+	activity, err := as.GetAudienceActivity(insights.UserID)
+	if err != nil {
+		log.Printf("Failed to get audience activity for user %s: %v", insights.UserID.Hex(), err)
+		return
+	}
+	insights.AudienceActivity = activity
+}
+
+// isEligible checks if a creator's audience is large enough, or the account
+// premium, for audience activity insights
+func (p AudienceActivityPolicy) isEligible(user *models.User) bool {
+	return user.FollowersCount >= p.MinFollowers || (p.IncludePremium && user.IsPremium)
+}
+
+// refreshCreatorAudience computes and stores one creator's audience activity summary
+func (as *AnalyticsService) refreshCreatorAudience(ctx context.Context, creatorID primitive.ObjectID, timezone string) error {
+	followerIDs, sampled, err := as.sampleAudience(ctx, creatorID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	since := now.AddDate(0, 0, -audiencePeriodDays)
+	activity := &AudienceActivity{
+		UserID:           creatorID,
+		Timezone:         timezone,
+		SampledFollowers: sampled,
+		PeriodDays:       audiencePeriodDays,
+		GeneratedAt:      &now,
+	}
+
+	var grid [7][24]float64
+	if len(followerIDs) > 0 {
+		// Sessions show when followers open the app; post views stand in
+		// for clients that don't report sessions
+		activity.Source = "sessions"
+		grid, activity.ActiveFollowers, err = as.aggregateAudienceGrid(ctx, "user_sessions", "start_time", followerIDs, since, timezone)
+		if err != nil {
+			return err
+		}
+
+		if gridTotal(grid) < audienceMinActivity {
+			activity.Source = "engagements"
+			grid, activity.ActiveFollowers, err = as.aggregateAudienceGrid(ctx, "content_engagements", "view_time", followerIDs, since, timezone)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if activity.ActiveFollowers < audienceMinActiveFollowers || gridTotal(grid) < audienceMinActivity {
+		activity.Status = AudienceActivityNotEnoughData
+		activity.Reason = "Not enough recent follower activity to find your best time to post yet"
+		activity.Source = ""
+	} else {
+		activity.Status = AudienceActivityReady
+		activity.Heatmap = normalizeAudienceGrid(grid)
+		activity.SuggestedWindow = bestPostingWindow(grid)
+	}
+
+	_, err = as.audienceCollection.ReplaceOne(ctx, bson.M{"user_id": creatorID}, activity, options.Replace().SetUpsert(true))
+	return err
+}
+
+// sampleAudience returns the creator's followers who haven't opted out of
+// analytics, sampled down to the policy's sample size, and the number sampled
+func (as *AnalyticsService) sampleAudience(ctx context.Context, creatorID primitive.ObjectID) ([]primitive.ObjectID, int64, error) {
+	follows := as.db.Collection("follows")
+	match := bson.M{"followee_id": creatorID, "status": models.FollowStatusAccepted}
+
+	total, err := follows.CountDocuments(ctx, match)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pipeline := []bson.M{{"$match": match}}
+	if total > int64(as.audiencePolicy.SampleSize) {
+		pipeline = append(pipeline, bson.M{"$sample": bson.M{"size": as.audiencePolicy.SampleSize}})
+	}
+	pipeline = append(pipeline, bson.M{"$project": bson.M{"_id": 0, "follower_id": 1}})
+
+	cursor, err := repository.ReadFrom(ctx, follows).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var sample []struct {
+		FollowerID primitive.ObjectID `bson:"follower_id"`
+	}
+	if err := cursor.All(ctx, &sample); err != nil {
+		return nil, 0, err
+	}
+	if len(sample) == 0 {
+		return nil, 0, nil
+	}
+
+	sampledIDs := make([]primitive.ObjectID, len(sample))
+	for i, follow := range sample {
+		sampledIDs[i] = follow.FollowerID
+	}
+
+	// Followers who opted out of analytics are left out of the aggregate
+	optOutIDs, err := as.userCollection.Distinct(ctx, "_id", bson.M{
+		"_id":                                bson.M{"$in": sampledIDs},
+		"privacy_settings.analytics_opt_out": true,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	optedOut := make(map[primitive.ObjectID]bool, len(optOutIDs))
+	for _, id := range optOutIDs {
+		if oid, ok := id.(primitive.ObjectID); ok {
+			optedOut[oid] = true
+		}
+	}
+
+	followerIDs := make([]primitive.ObjectID, 0, len(sampledIDs))
+	for _, id := range sampledIDs {
+		if !optedOut[id] {
+			followerIDs = append(followerIDs, id)
+		}
+	}
+
+	return followerIDs, int64(len(sample)), nil
+}
+
+// aggregateAudienceGrid counts, for each weekday and hour in the timezone, how
+// many of the followers were active then, so one very active follower counts
+// once per hour rather than per event. It also returns how many followers
+// were active at all.
+func (as *AnalyticsService) aggregateAudienceGrid(ctx context.Context, collection, timeField string, followerIDs []primitive.ObjectID, since time.Time, timezone string) ([7][24]float64, int64, error) {
+	var grid [7][24]float64
+
+	date := bson.M{"date": "$" + timeField, "timezone": timezone}
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"user_id": bson.M{"$in": followerIDs},
+				timeField: bson.M{"$gte": since},
+			},
+		},
+		{
+			"$facet": bson.M{
+				"cells": []bson.M{
+					{
+						"$group": bson.M{
+							"_id": bson.M{
+								"user": "$user_id",
+								"day":  bson.M{"$dayOfWeek": date},
+								"hour": bson.M{"$hour": date},
+							},
+						},
+					},
+					{
+						"$group": bson.M{
+							"_id":       bson.M{"day": "$_id.day", "hour": "$_id.hour"},
+							"followers": bson.M{"$sum": 1},
+						},
+					},
+				},
+				"active": []bson.M{
+					{"$group": bson.M{"_id": "$user_id"}},
+					{"$count": "count"},
+				},
+			},
+		},
+	}
+
+	cursor, err := repository.ReadFrom(ctx, as.db.Collection(collection)).Aggregate(ctx, pipeline)
+	if err != nil {
+		return grid, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Cells []struct {
+			ID struct {
+				Day  int `bson:"day"`
+				Hour int `bson:"hour"`
+			} `bson:"_id"`
+			Followers int64 `bson:"followers"`
+		} `bson:"cells"`
+		Active []struct {
+			Count int64 `bson:"count"`
+		} `bson:"active"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return grid, 0, err
+	}
+	if len(results) == 0 {
+		return grid, 0, nil
+	}
+
+	for _, cell := range results[0].Cells {
+		// $dayOfWeek counts from 1 for Sunday
+		day := cell.ID.Day - 1
+		if day < 0 || day > 6 || cell.ID.Hour < 0 || cell.ID.Hour > 23 {
+			continue
+		}
+		grid[day][cell.ID.Hour] = float64(cell.Followers)
+	}
+
+	var active int64
+	if len(results[0].Active) > 0 {
+		active = results[0].Active[0].Count
+	}
+
+	return grid, active, nil
+}
+
+func gridTotal(grid [7][24]float64) float64 {
+	var total float64
+	for _, day := range grid {
+		for _, count := range day {
+			total += count
+		}
+	}
+	return total
+}
+
+// normalizeAudienceGrid scales the grid so the busiest hour is 1
+func normalizeAudienceGrid(grid [7][24]float64) [][]float64 {
+	var peak float64
+	for _, day := range grid {
+		for _, count := range day {
+			peak = math.Max(peak, count)
+		}
+	}
+
+	heatmap := make([][]float64, 7)
+	for day := range grid {
+		heatmap[day] = make([]float64, 24)
+		if peak == 0 {
+			continue
+		}
+		for hour, count := range grid[day] {
+			heatmap[day][hour] = math.Round(count/peak*100) / 100
+		}
+	}
+	return heatmap
+}
+
+// bestPostingWindow finds the run of audienceWindowHours hours with the most
+// follower activity, treating the week as a loop so windows can cross midnight
+func bestPostingWindow(grid [7][24]float64) *PostingWindow {
+	const weekHours = 7 * 24
+	at := func(i int) float64 {
+		i %= weekHours
+		return grid[i/24][i%24]
+	}
+
+	bestStart := 0
+	bestTotal := -1.0
+	for start := 0; start < weekHours; start++ {
+		var total float64
+		for offset := 0; offset < audienceWindowHours; offset++ {
+			total += at(start + offset)
+		}
+		if total > bestTotal {
+			bestStart, bestTotal = start, total
+		}
+	}
+
+	return &PostingWindow{
+		Weekday:   time.Weekday(bestStart / 24).String(),
+		StartHour: bestStart % 24,
+		EndHour:   (bestStart + audienceWindowHours) % 24,
+	}
+}
+
+// getCreatorPostIDs returns the IDs of the creator's live posts, reposts aside
+func (as *AnalyticsService) getCreatorPostIDs(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	cursor, err := repository.ReadFrom(ctx, as.postCollection).Find(ctx, repository.NotDeleted(bson.M{
+		"user_id":   userID,
+		"is_repost": bson.M{"$ne": true},
+	}), options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var posts []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+	return ids, nil
+}
+
+// countByPost counts the matching documents of a collection per post, the
+// post being named by postField
+func (as *AnalyticsService) countByPost(ctx context.Context, collection, postField string, match bson.M) (map[primitive.ObjectID]int64, error) {
+	cursor, err := repository.ReadFrom(ctx, as.db.Collection(collection)).Aggregate(ctx, []bson.M{
+		{"$match": match},
+		{"$group": bson.M{"_id": "$" + postField, "count": bson.M{"$sum": 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		PostID primitive.ObjectID `bson:"_id"`
+		Count  int64              `bson:"count"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[primitive.ObjectID]int64, len(results))
+	for _, result := range results {
+		counts[result.PostID] = result.Count
+	}
+	return counts, nil
+}
+
+// getCreatorTopPosts ranks the engaged posts by likes, comments and shares,
+// views breaking ties, and fills in a preview of the top ones
+func (as *AnalyticsService) getCreatorTopPosts(ctx context.Context, engaged map[primitive.ObjectID]*CreatorTopPost) ([]CreatorTopPost, error) {
+	ranked := make([]*CreatorTopPost, 0, len(engaged))
+	for _, post := range engaged {
+		post.Engagement = post.Likes + post.Comments + post.Shares
+		ranked = append(ranked, post)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Engagement != ranked[j].Engagement {
+			return ranked[i].Engagement > ranked[j].Engagement
+		}
+		return ranked[i].Views > ranked[j].Views
+	})
+	if len(ranked) > creatorSummaryTopPosts {
+		ranked = ranked[:creatorSummaryTopPosts]
+	}
+
+	ids := make([]primitive.ObjectID, len(ranked))
+	for i, post := range ranked {
+		ids[i] = post.PostID
+	}
+
+	cursor, err := repository.ReadFrom(ctx, as.postCollection).Find(ctx, bson.M{"_id": bson.M{"$in": ids}},
+		options.Find().SetProjection(bson.M{"content": 1, "created_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var posts []models.Post
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+	byID := make(map[primitive.ObjectID]models.Post, len(posts))
+	for _, post := range posts {
+		byID[post.ID] = post
+	}
+
+	topPosts := make([]CreatorTopPost, 0, len(ranked))
+	for _, post := range ranked {
+		if p, ok := byID[post.PostID]; ok {
+			post.Content = activityPreview(p.Content)
+			post.CreatedAt = p.CreatedAt
+		}
+		topPosts = append(topPosts, *post)
+	}
+	return topPosts, nil
+}
+
+// engagedPostsPipeline joins the user's content engagements with the posts they engaged with
+func engagedPostsPipeline(userID primitive.ObjectID, since time.Time) []bson.M {
+	return []bson.M{
+		{
+			"$match": bson.M{
+				"user_id":      userID,
+				"content_type": "post",
+				"view_time":    bson.M{"$gte": since},
+			},
+		},
+		{
+			"$lookup": bson.M{
+				"from":         "posts",
+				"localField":   "content_id",
+				"foreignField": "_id",
+				"as":           "post",
+			},
+		},
+		{
+			"$unwind": "$post",
+		},
+	}
+}
+
+// getTopEngagedCategories counts the user's engagements per topic category.
+// A post's categories are those of its hashtags; an engagement counts once
+// for each distinct category, and posts without hashtags aren't counted.
+func (as *AnalyticsService) getTopEngagedCategories(ctx context.Context, userID primitive.ObjectID, since time.Time) ([]InsightBucket, error) {
+	pipeline := append(engagedPostsPipeline(userID, since),
+		bson.M{"$match": bson.M{"post.hashtags.0": bson.M{"$exists": true}}},
+		bson.M{
+			"$project": bson.M{
+				"tags": bson.M{"$map": bson.M{
+					"input": "$post.hashtags",
+					"as":    "tag",
+					"in":    bson.M{"$toLower": "$$tag"},
+				}},
+			},
+		},
+		bson.M{
+			"$lookup": bson.M{
+				"from":         "hashtags",
+				"localField":   "tags",
+				"foreignField": "normalized_tag",
+				"as":           "hashtag",
+			},
+		},
+		bson.M{
+			// Hashtags not categorized yet fall back like the categorizer does
+			"$project": bson.M{
+				"categories": bson.M{"$setUnion": []interface{}{"$hashtag.category", []interface{}{}}},
+			},
+		},
+		bson.M{
+			"$project": bson.M{
+				"categories": bson.M{"$cond": []interface{}{
+					bson.M{"$gt": []interface{}{bson.M{"$size": "$categories"}, 0}},
+					"$categories",
+					[]string{models.HashtagCategoryFallback},
+				}},
+			},
+		},
+		bson.M{"$unwind": "$categories"},
+		bson.M{
+			"$group": bson.M{
+				"_id":   "$categories",
+				"count": bson.M{"$sum": 1},
+			},
+		},
+		bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		bson.M{"$limit": 5},
+	)
+
+	return as.aggregateInsightBuckets(ctx, pipeline)
+}
+
+func (as *AnalyticsService) getTopEngagedHashtags(ctx context.Context, userID primitive.ObjectID, since time.Time) ([]InsightBucket, error) {
+	pipeline := append(engagedPostsPipeline(userID, since),
+		bson.M{"$unwind": "$post.hashtags"},
+		bson.M{
+			"$group": bson.M{
+				"_id":   bson.M{"$toLower": "$post.hashtags"},
+				"count": bson.M{"$sum": 1},
+			},
+		},
+		bson.M{"$sort": bson.M{"count": -1}},
+		bson.M{"$limit": 10},
+	)
+
+	return as.aggregateInsightBuckets(ctx, pipeline)
+}
+
+func (as *AnalyticsService) aggregateInsightBuckets(ctx context.Context, pipeline []bson.M) ([]InsightBucket, error) {
+	cursor, err := repository.ReadFrom(ctx, as.db.Collection("content_engagements")).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	buckets := []InsightBucket{}
+	if err := cursor.All(ctx, &buckets); err != nil {
+		return nil, err
+	}
+
+	return buckets, nil
+}
+
+func (as *AnalyticsService) getMostActiveHours(ctx context.Context, userID primitive.ObjectID, since time.Time, timezone string) ([]HourlyActivity, error) {
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"user_id":   userID,
+				"view_time": bson.M{"$gte": since},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":   bson.M{"$hour": bson.M{"date": "$view_time", "timezone": timezone}},
+				"count": bson.M{"$sum": 1},
+			},
+		},
+		{
+			"$sort": bson.M{"count": -1},
+		},
+		{
+			"$limit": 3,
+		},
+	}
+
+	cursor, err := repository.ReadFrom(ctx, as.db.Collection("content_engagements")).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	hours := []HourlyActivity{}
+	if err := cursor.All(ctx, &hours); err != nil {
+		return nil, err
+	}
+
+	return hours, nil
+}
+
+func (as *AnalyticsService) getSessionLengthStats(ctx context.Context, userID primitive.ObjectID, since time.Time, insights *UserInsights) error {
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"user_id":    userID,
+				"start_time": bson.M{"$gte": since},
+				"end_time":   bson.M{"$exists": true},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":          nil,
+				"avg_duration": bson.M{"$avg": "$duration"},
+				"sessions":     bson.M{"$sum": 1},
+			},
+		},
+	}
+
+	cursor, err := repository.ReadFrom(ctx, as.db.Collection("user_sessions")).Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		AvgDuration float64 `bson:"avg_duration"`
+		Sessions    int64   `bson:"sessions"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return err
+	}
+
+	if len(results) > 0 {
+		// Session durations are stored in milliseconds
+		insights.AvgSessionLength = results[0].AvgDuration / 1000
+		insights.TotalSessions = results[0].Sessions
+	}
+
+	return nil
+}
+
+func (as *AnalyticsService) getEngagementTrend(ctx context.Context, userID primitive.ObjectID, since time.Time, timezone string) ([]DailyEngagement, error) {
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"user_id":   userID,
+				"view_time": bson.M{"$gte": since},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id": bson.M{
+					"$dateToString": bson.M{
+						"format":   "%Y-%m-%d",
+						"date":     "$view_time",
+						"timezone": timezone,
+					},
+				},
+				"views":        bson.M{"$sum": 1},
+				"interactions": bson.M{"$sum": bson.M{"$size": bson.M{"$ifNull": []interface{}{"$interactions", []interface{}{}}}}},
+			},
+		},
+		{
+			"$sort": bson.M{"_id": 1},
+		},
+	}
+
+	cursor, err := repository.ReadFrom(ctx, as.db.Collection("content_engagements")).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	trend := []DailyEngagement{}
+	if err := cursor.All(ctx, &trend); err != nil {
+		return nil, err
+	}
+
+	return trend, nil
+}
+
+// calculateTrendChange compares engagement over the last 7 days with the 7 days before
+func calculateTrendChange(trend []DailyEngagement) float64 {
+	recentStart := time.Now().AddDate(0, 0, -7).Format("2006-01-02")
+	previousStart := time.Now().AddDate(0, 0, -14).Format("2006-01-02")
+
+	var recent, previous int64
+	for _, day := range trend {
+		total := day.Views + day.Interactions
+		switch {
+		case day.Date > recentStart:
+			recent += total
+		case day.Date > previousStart:
+			previous += total
+		}
+	}
+
+	if previous == 0 {
+		if recent == 0 {
+			return 0
+		}
+		return 100
+	}
+
+	return float64(recent-previous) / float64(previous) * 100
+}
+
 func (as *AnalyticsService) getTimeFilter(timeRange string) time.Time {
 	now := time.Now()
 	switch timeRange {
@@ -664,9 +1791,7 @@ func (as *AnalyticsService) getTimeFilter(timeRange string) time.Time {
 
 func (as *AnalyticsService) getUserStats(ctx context.Context, timeFilter time.Time) (map[string]interface{}, error) {
 	// Get total users
-	totalUsers, err := as.userCollection.CountDocuments(ctx, bson.M{
-		"deleted_at": bson.M{"$exists": false},
-	})
+	totalUsers, err := as.userCollection.CountDocuments(ctx, repository.NotDeleted())
 	if err != nil {
 		return nil, err
 	}
@@ -689,7 +1814,7 @@ func (as *AnalyticsService) getUserStats(ctx context.Context, timeFilter time.Ti
 		},
 	}
 
-	cursor, err := as.eventsCollection.Aggregate(ctx, activeUsersPipeline)
+	cursor, err := repository.ReadFrom(ctx, as.eventsCollection).Aggregate(ctx, activeUsersPipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -706,10 +1831,9 @@ func (as *AnalyticsService) getUserStats(ctx context.Context, timeFilter time.Ti
 	}
 
 	// Get new users
-	newUsers, err := as.userCollection.CountDocuments(ctx, bson.M{
+	newUsers, err := as.userCollection.CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"created_at": bson.M{"$gte": timeFilter},
-		"deleted_at": bson.M{"$exists": false},
-	})
+	}))
 	if err != nil {
 		return nil, err
 	}
@@ -759,7 +1883,7 @@ func (as *AnalyticsService) getContentStats(ctx context.Context, timeFilter time
 		},
 	}
 
-	cursor, err := as.eventsCollection.Aggregate(ctx, pipeline)
+	cursor, err := repository.ReadFrom(ctx, as.eventsCollection).Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -797,7 +1921,7 @@ func (as *AnalyticsService) getPlatformDistribution(ctx context.Context, timeFil
 		},
 	}
 
-	cursor, err := as.eventsCollection.Aggregate(ctx, pipeline)
+	cursor, err := repository.ReadFrom(ctx, as.eventsCollection).Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, err
 	}
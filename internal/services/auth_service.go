@@ -11,12 +11,14 @@ import (
 
 	"social-media-api/internal/config"
 	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
 	"social-media-api/internal/utils"
 
 	"github.com/golang-jwt/jwt/v5"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type AuthService struct {
@@ -25,6 +27,7 @@ type AuthService struct {
 	db                *mongo.Database
 	jwtSecret         string
 	refreshSecret     string
+	sessions          models.SessionPolicies
 }
 
 type LoginResponse struct {
@@ -33,6 +36,9 @@ type LoginResponse struct {
 	RefreshToken string              `json:"refresh_token"`
 	ExpiresIn    int64               `json:"expires_in"`
 	TokenType    string              `json:"token_type"`
+	FirstLogin   bool                `json:"first_login,omitempty"` // The account had never logged in before
+
+	OnboardingSuggestions []models.SuggestedUserResponse `json:"onboarding_suggestions,omitempty"`
 }
 
 type RefreshTokenResponse struct {
@@ -52,6 +58,10 @@ type Session struct {
 	IsActive         bool               `json:"is_active" bson:"is_active"`
 	LastActivityAt   time.Time          `json:"last_activity_at" bson:"last_activity_at"`
 	ExpiresAt        time.Time          `json:"expires_at" bson:"expires_at"`
+
+	// Sessions of every account signed in on the same device share a group.
+	// A fresh login starts a group named after its own session ID.
+	DeviceGroupID string `json:"device_group_id,omitempty" bson:"device_group_id,omitempty"`
 }
 
 func NewAuthService(jwtSecret, refreshSecret string) *AuthService {
@@ -61,24 +71,55 @@ func NewAuthService(jwtSecret, refreshSecret string) *AuthService {
 		db:                config.DB,
 		jwtSecret:         jwtSecret,
 		refreshSecret:     refreshSecret,
+		sessions: models.SessionPolicies{
+			Default:              models.SessionPolicy{AccessTokenDuration: 24 * time.Hour},
+			RefreshTokenDuration: 30 * 24 * time.Hour,
+		},
 	}
 }
 
+// SetSessionPolicies sets how long the sessions of each role last
+func (as *AuthService) SetSessionPolicies(policies models.SessionPolicies) {
+	as.sessions = policies
+}
+
 // Login authenticates user and returns tokens
 func (as *AuthService) Login(req models.LoginRequest) (*LoginResponse, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	user, err := as.authenticate(ctx, req.EmailOrUsername, req.Password, req.Reactivate)
+	if err != nil {
+		return nil, err
+	}
+
+	// A fresh login starts a new device group
+	sessionID, err := as.createSession(ctx, user.ID, req.DeviceInfo, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return as.loginResponse(user, sessionID, req.DeviceInfo)
+}
+
+// authenticate checks an account's credentials. Temporarily deactivated
+// accounts are only let in when reactivate confirms the user wants them back.
+func (as *AuthService) authenticate(ctx context.Context, emailOrUsername, password string, reactivate bool) (*models.User, error) {
 	// Find user by email or username
 	var user models.User
-	filter := bson.M{
-		"$or": []bson.M{
-			{"email": req.EmailOrUsername},
-			{"username": req.EmailOrUsername},
+	// Temporarily deactivated accounts can log in to reactivate
+	filter := repository.NotDeleted(bson.M{
+		"$and": []bson.M{
+			{"$or": []bson.M{
+				{"email": emailOrUsername},
+				{"username": emailOrUsername},
+			}},
+			{"$or": []bson.M{
+				{"is_active": true},
+				{"deactivated_at": bson.M{"$exists": true}},
+			}},
 		},
-		"is_active":  true,
-		"deleted_at": bson.M{"$exists": false},
-	}
+	})
 
 	err := as.userCollection.FindOne(ctx, filter).Decode(&user)
 	if err != nil {
@@ -89,7 +130,7 @@ func (as *AuthService) Login(req models.LoginRequest) (*LoginResponse, error) {
 	}
 
 	// Check password
-	if !utils.CheckPasswordHash(req.Password, user.Password) {
+	if !utils.CheckPasswordHash(password, user.Password) {
 		return nil, errors.New("invalid credentials")
 	}
 
@@ -98,39 +139,66 @@ func (as *AuthService) Login(req models.LoginRequest) (*LoginResponse, error) {
 		return nil, errors.New("account is suspended")
 	}
 
-	// Create session
+	// A deactivated account is only restored when the user confirms it
+	if user.DeactivatedAt != nil {
+		if !reactivate {
+			return nil, errors.New("account is deactivated")
+		}
+		if _, err := reactivateUser(ctx, as.userCollection, user.ID); err != nil {
+			return nil, err
+		}
+		user.IsActive = true
+		user.DeactivatedAt = nil
+		user.ReactivateAt = nil
+	}
+
+	return &user, nil
+}
+
+// createSession stores a new 30-day session for the user in the device
+// group. An empty group starts a new one named after the session.
+func (as *AuthService) createSession(ctx context.Context, userID primitive.ObjectID, deviceInfo, deviceGroupID string) (string, error) {
 	sessionID := primitive.NewObjectID().Hex()
+	if deviceGroupID == "" {
+		deviceGroupID = sessionID
+	}
+
 	session := &Session{
-		UserID:         user.ID,
+		UserID:         userID,
 		SessionID:      sessionID,
-		DeviceInfo:     req.DeviceInfo,
+		DeviceInfo:     deviceInfo,
 		IPAddress:      "", // This would be set by the handler
 		IsActive:       true,
 		LastActivityAt: time.Now(),
 		ExpiresAt:      time.Now().Add(30 * 24 * time.Hour), // 30 days
+		DeviceGroupID:  deviceGroupID,
 	}
 	session.BeforeCreate()
 
-	_, err = as.sessionCollection.InsertOne(ctx, session)
-	if err != nil {
-		return nil, err
+	if _, err := as.sessionCollection.InsertOne(ctx, session); err != nil {
+		return "", err
 	}
+	return sessionID, nil
+}
 
+// loginResponse issues tokens for a session of the user and records the login
+func (as *AuthService) loginResponse(user *models.User, sessionID, deviceInfo string) (*LoginResponse, error) {
 	// Generate tokens
-	accessToken, refreshToken, err := as.GenerateTokens(&user, sessionID, req.DeviceInfo, "")
+	accessToken, refreshToken, expiresIn, err := as.issueTokens(user, sessionID, deviceInfo, "", time.Now())
 	if err != nil {
 		return nil, err
 	}
 
 	// Update user's last login
-	as.UpdateUserLogin(user.ID, req.DeviceInfo)
+	as.UpdateUserLogin(user.ID, deviceInfo)
 
 	return &LoginResponse{
 		User:         user.ToUserResponse(),
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
-		ExpiresIn:    24 * 60 * 60, // 24 hours in seconds
+		ExpiresIn:    expiresIn,
 		TokenType:    "Bearer",
+		FirstLogin:   user.LastLoginAt == nil,
 	}, nil
 }
 
@@ -148,6 +216,11 @@ func (as *AuthService) Register(req models.RegisterRequest) (*LoginResponse, err
 		return nil, errors.New("username or email already exists")
 	}
 
+	interests, err := normalizeInterests(req.Interests)
+	if err != nil {
+		return nil, err
+	}
+
 	// Hash password
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
@@ -166,8 +239,11 @@ func (as *AuthService) Register(req models.RegisterRequest) (*LoginResponse, err
 		DateOfBirth: req.DateOfBirth,
 		Gender:      req.Gender,
 		Phone:       req.Phone,
+		Interests:   interests,
 	}
 
+	user.InterestHashtags = seedInterestHashtags(ctx, as.db, interests)
+
 	user.BeforeCreate()
 
 	result, err := as.userCollection.InsertOne(ctx, user)
@@ -178,23 +254,13 @@ func (as *AuthService) Register(req models.RegisterRequest) (*LoginResponse, err
 	user.ID = result.InsertedID.(primitive.ObjectID)
 
 	// Create session
-	sessionID := primitive.NewObjectID().Hex()
-	session := &Session{
-		UserID:         user.ID,
-		SessionID:      sessionID,
-		IsActive:       true,
-		LastActivityAt: time.Now(),
-		ExpiresAt:      time.Now().Add(30 * 24 * time.Hour),
-	}
-	session.BeforeCreate()
-
-	_, err = as.sessionCollection.InsertOne(ctx, session)
+	sessionID, err := as.createSession(ctx, user.ID, "", "")
 	if err != nil {
 		return nil, err
 	}
 
 	// Generate tokens
-	accessToken, refreshToken, err := as.GenerateTokens(user, sessionID, "", "")
+	accessToken, refreshToken, expiresIn, err := as.issueTokens(user, sessionID, "", "", time.Now())
 	if err != nil {
 		return nil, err
 	}
@@ -203,8 +269,9 @@ func (as *AuthService) Register(req models.RegisterRequest) (*LoginResponse, err
 		User:         user.ToUserResponse(),
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
-		ExpiresIn:    24 * 60 * 60,
+		ExpiresIn:    expiresIn,
 		TokenType:    "Bearer",
+		FirstLogin:   true,
 	}, nil
 }
 
@@ -253,8 +320,18 @@ func (as *AuthService) RefreshTokens(refreshToken string) (*RefreshTokenResponse
 		return nil, errors.New("invalid session")
 	}
 
+	// Refreshing never stretches a session past its absolute limit. Tokens
+	// from before sign-in times were recorded count from the session's start.
+	authTime := session.CreatedAt
+	if signedIn, ok := claims["auth_time"].(float64); ok {
+		authTime = time.Unix(int64(signedIn), 0)
+	}
+	if as.sessions.For(user.Role).SessionExpired(authTime, time.Now()) {
+		return nil, errors.New("session expired, sign in again")
+	}
+
 	// Generate new tokens
-	newAccessToken, newRefreshToken, err := as.GenerateTokens(user, sessionID, deviceInfo, ipAddress)
+	newAccessToken, newRefreshToken, expiresIn, err := as.issueTokens(user, sessionID, deviceInfo, ipAddress, authTime)
 	if err != nil {
 		return nil, err
 	}
@@ -265,12 +342,14 @@ func (as *AuthService) RefreshTokens(refreshToken string) (*RefreshTokenResponse
 	return &RefreshTokenResponse{
 		AccessToken:  newAccessToken,
 		RefreshToken: newRefreshToken,
-		ExpiresIn:    24 * 60 * 60,
+		ExpiresIn:    expiresIn,
 		TokenType:    "Bearer",
 	}, nil
 }
 
-// Logout invalidates user session
+// Logout invalidates user session. Other accounts signed in on the same
+// device keep their sessions; the account's push tokens on the device are
+// switched off so its notifications stop arriving there.
 func (as *AuthService) Logout(sessionID string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -282,8 +361,46 @@ func (as *AuthService) Logout(sessionID string) error {
 		},
 	}
 
-	_, err := as.sessionCollection.UpdateOne(ctx, bson.M{"session_id": sessionID}, update)
-	return err
+	var session Session
+	err := as.sessionCollection.FindOneAndUpdate(ctx, bson.M{"session_id": sessionID}, update).Decode(&session)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		return err
+	}
+
+	if session.DeviceGroupID == "" {
+		return nil
+	}
+	return deactivatePushTokens(ctx, as.db, bson.M{
+		"user_id":         session.UserID,
+		"device_group_id": session.DeviceGroupID,
+	})
+}
+
+// LogoutDevice invalidates the sessions of every account signed in on the
+// device of the given session
+func (as *AuthService) LogoutDevice(sessionID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	current, err := as.deviceGroupSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	_, err = as.sessionCollection.UpdateMany(ctx, bson.M{"device_group_id": current.DeviceGroupID}, bson.M{
+		"$set": bson.M{
+			"is_active":  false,
+			"updated_at": time.Now(),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return deactivatePushTokens(ctx, as.db, bson.M{"device_group_id": current.DeviceGroupID})
 }
 
 // LogoutAll invalidates all user sessions
@@ -291,15 +408,24 @@ func (as *AuthService) LogoutAll(userID primitive.ObjectID) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	update := bson.M{
+	return revokeAccountSessions(ctx, as.db, userID)
+}
+
+// revokeAccountSessions signs one account out everywhere and switches off its
+// push tokens. It goes by user ID only, so other accounts sharing a device
+// with the account stay signed in; suspensions use it for the same reason.
+func revokeAccountSessions(ctx context.Context, db *mongo.Database, userID primitive.ObjectID) error {
+	_, err := db.Collection("sessions").UpdateMany(ctx, bson.M{"user_id": userID, "is_active": true}, bson.M{
 		"$set": bson.M{
 			"is_active":  false,
 			"updated_at": time.Now(),
 		},
+	})
+	if err != nil {
+		return err
 	}
 
-	_, err := as.sessionCollection.UpdateMany(ctx, bson.M{"user_id": userID}, update)
-	return err
+	return deactivatePushTokens(ctx, db, bson.M{"user_id": userID})
 }
 
 // ForgotPassword initiates password reset process
@@ -309,11 +435,10 @@ func (as *AuthService) ForgotPassword(req models.ForgotPasswordRequest) error {
 
 	// Find user by email
 	var user models.User
-	err := as.userCollection.FindOne(ctx, bson.M{
-		"email":      req.Email,
-		"is_active":  true,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&user)
+	err := as.userCollection.FindOne(ctx, repository.NotDeleted(bson.M{
+		"email":     req.Email,
+		"is_active": true,
+	})).Decode(&user)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -363,14 +488,13 @@ func (as *AuthService) ResetPassword(req models.ResetPasswordRequest) error {
 
 	// Find user by reset token
 	var user models.User
-	err := as.userCollection.FindOne(ctx, bson.M{
+	err := as.userCollection.FindOne(ctx, repository.NotDeleted(bson.M{
 		"password_reset_token": req.Token,
 		"password_reset_expiry": bson.M{
 			"$gt": time.Now(),
 		},
-		"is_active":  true,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&user)
+		"is_active": true,
+	})).Decode(&user)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -415,11 +539,10 @@ func (as *AuthService) VerifyEmail(token string) error {
 
 	// Find user by email verification token
 	var user models.User
-	err := as.userCollection.FindOne(ctx, bson.M{
+	err := as.userCollection.FindOne(ctx, repository.NotDeleted(bson.M{
 		"email_verify_token": token,
 		"is_active":          true,
-		"deleted_at":         bson.M{"$exists": false},
-	}).Decode(&user)
+	})).Decode(&user)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -445,9 +568,23 @@ func (as *AuthService) VerifyEmail(token string) error {
 	return err
 }
 
-// GenerateTokens generates access and refresh tokens
+// GenerateTokens generates access and refresh tokens for a new sign-in
 func (as *AuthService) GenerateTokens(user *models.User, sessionID, deviceInfo, ipAddress string) (string, string, error) {
+	accessToken, refreshToken, _, err := as.issueTokens(user, sessionID, deviceInfo, ipAddress, time.Now())
+	return accessToken, refreshToken, err
+}
+
+// issueTokens generates access and refresh tokens for a session signed in at
+// authTime, with lifetimes from the user's role capped at the session's
+// absolute limit. It also returns the access token's lifetime in seconds.
+func (as *AuthService) issueTokens(user *models.User, sessionID, deviceInfo, ipAddress string, authTime time.Time) (string, string, int64, error) {
 	now := time.Now()
+	policy := as.sessions.For(user.Role)
+	accessExpiry := policy.AccessTokenExpiry(authTime, now)
+	refreshExpiry := now.Add(as.sessions.RefreshTokenDuration)
+	if end, bounded := policy.SessionEnd(authTime); bounded && end.Before(refreshExpiry) {
+		refreshExpiry = end
+	}
 
 	// Access token claims
 	accessClaims := jwt.MapClaims{
@@ -459,15 +596,16 @@ func (as *AuthService) GenerateTokens(user *models.User, sessionID, deviceInfo,
 		"device_info": deviceInfo,
 		"ip_address":  ipAddress,
 		"token_type":  "access",
+		"auth_time":   authTime.Unix(),
 		"iat":         now.Unix(),
-		"exp":         now.Add(24 * time.Hour).Unix(), // 24 hours
+		"exp":         accessExpiry.Unix(),
 	}
 
 	// Create access token
 	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
 	accessTokenString, err := accessToken.SignedString([]byte(as.jwtSecret))
 	if err != nil {
-		return "", "", err
+		return "", "", 0, err
 	}
 
 	// Refresh token claims
@@ -480,18 +618,19 @@ func (as *AuthService) GenerateTokens(user *models.User, sessionID, deviceInfo,
 		"device_info": deviceInfo,
 		"ip_address":  ipAddress,
 		"token_type":  "refresh",
+		"auth_time":   authTime.Unix(),
 		"iat":         now.Unix(),
-		"exp":         now.Add(30 * 24 * time.Hour).Unix(), // 30 days
+		"exp":         refreshExpiry.Unix(),
 	}
 
 	// Create refresh token
 	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
 	refreshTokenString, err := refreshToken.SignedString([]byte(as.refreshSecret))
 	if err != nil {
-		return "", "", err
+		return "", "", 0, err
 	}
 
-	return accessTokenString, refreshTokenString, nil
+	return accessTokenString, refreshTokenString, int64(accessExpiry.Sub(now).Seconds()), nil
 }
 
 // ValidateAccessToken validates access token
@@ -546,11 +685,10 @@ func (as *AuthService) GetUserByID(userID primitive.ObjectID) (*models.User, err
 	defer cancel()
 
 	var user models.User
-	err := as.userCollection.FindOne(ctx, bson.M{
-		"_id":        userID,
-		"is_active":  true,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&user)
+	err := as.userCollection.FindOne(ctx, repository.NotDeleted(bson.M{
+		"_id":       userID,
+		"is_active": true,
+	})).Decode(&user)
 
 	if err != nil {
 		return nil, err
@@ -649,13 +787,12 @@ func (as *AuthService) CheckUserExists(username, email string) (bool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	filter := bson.M{
+	filter := repository.NotDeleted(bson.M{
 		"$or": []bson.M{
 			{"username": username},
 			{"email": email},
 		},
-		"deleted_at": bson.M{"$exists": false},
-	}
+	})
 
 	count, err := as.userCollection.CountDocuments(ctx, filter)
 	if err != nil {
@@ -680,3 +817,255 @@ func (as *AuthService) CleanupExpiredSessions() error {
 	_, err := as.sessionCollection.DeleteMany(ctx, filter)
 	return err
 }
+
+// deviceGroupSession returns the active session behind a request along with
+// its device group. Sessions created before device groups existed are
+// adopted into a group named after themselves.
+func (as *AuthService) deviceGroupSession(ctx context.Context, sessionID string) (*Session, error) {
+	var session Session
+	err := as.sessionCollection.FindOne(ctx, bson.M{
+		"session_id": sessionID,
+		"is_active":  true,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&session)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("invalid session")
+		}
+		return nil, err
+	}
+
+	if session.DeviceGroupID == "" {
+		session.DeviceGroupID = session.SessionID
+		_, err = as.sessionCollection.UpdateOne(ctx, bson.M{"_id": session.ID}, bson.M{
+			"$set": bson.M{"device_group_id": session.DeviceGroupID},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &session, nil
+}
+
+// deviceGroupFilter matches the live sessions of a device group
+func deviceGroupFilter(deviceGroupID string) bson.M {
+	return bson.M{
+		"device_group_id": deviceGroupID,
+		"is_active":       true,
+		"expires_at":      bson.M{"$gt": time.Now()},
+	}
+}
+
+// deviceGroupAccounts returns the accounts with a live session in the device
+// group, in the order they were signed in
+func (as *AuthService) deviceGroupAccounts(ctx context.Context, deviceGroupID string) ([]primitive.ObjectID, error) {
+	cursor, err := as.sessionCollection.Find(ctx, deviceGroupFilter(deviceGroupID),
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}).SetProjection(bson.M{"user_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var sessions []Session
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[primitive.ObjectID]bool)
+	var userIDs []primitive.ObjectID
+	for _, session := range sessions {
+		if !seen[session.UserID] {
+			seen[session.UserID] = true
+			userIDs = append(userIDs, session.UserID)
+		}
+	}
+	return userIDs, nil
+}
+
+// joinDeviceGroup signs the user into the device group with a new session,
+// unless the group already holds the maximum number of accounts
+func (as *AuthService) joinDeviceGroup(ctx context.Context, user *models.User, current *Session) (*LoginResponse, error) {
+	accounts, err := as.deviceGroupAccounts(ctx, current.DeviceGroupID)
+	if err != nil {
+		return nil, err
+	}
+	if len(accounts) >= models.MaxAccountsPerDeviceGroup {
+		return nil, fmt.Errorf("device account limit reached, at most %d accounts can be signed in on one device", models.MaxAccountsPerDeviceGroup)
+	}
+
+	sessionID, err := as.createSession(ctx, user.ID, current.DeviceInfo, current.DeviceGroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return as.loginResponse(user, sessionID, current.DeviceInfo)
+}
+
+// AddAccount signs an additional account in on the device of the given
+// session. The accounts already signed in there keep their sessions.
+func (as *AuthService) AddAccount(sessionID string, req models.AddAccountRequest) (*LoginResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	current, err := as.deviceGroupSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := as.authenticate(ctx, req.EmailOrUsername, req.Password, false)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := deviceGroupFilter(current.DeviceGroupID)
+	filter["user_id"] = user.ID
+	signedIn, err := as.sessionCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if signedIn > 0 {
+		return nil, errors.New("account is already signed in on this device")
+	}
+
+	return as.joinDeviceGroup(ctx, user, current)
+}
+
+// GetDeviceAccounts lists the accounts signed in on the device of the given
+// session, with unread badge counts for the switcher. Suspended and
+// deactivated accounts are left out since they can't be switched to.
+func (as *AuthService) GetDeviceAccounts(sessionID string) ([]models.DeviceAccount, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	current, err := as.deviceGroupSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs, err := as.deviceGroupAccounts(ctx, current.DeviceGroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := as.userCollection.Find(ctx, repository.NotDeleted(bson.M{
+		"_id":          bson.M{"$in": userIDs},
+		"is_active":    true,
+		"is_suspended": bson.M{"$ne": true},
+	}), options.Find().SetProjection(bson.M{
+		"username":     1,
+		"display_name": 1,
+		"profile_pic":  1,
+		"is_verified":  1,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	usersByID := make(map[primitive.ObjectID]models.User, len(users))
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+
+	// Badges only need to know whether to show "99+", so counting stops there
+	countOpts := options.Count().SetLimit(models.UnreadBadgeCap)
+
+	accounts := []models.DeviceAccount{}
+	for _, userID := range userIDs {
+		user, ok := usersByID[userID]
+		if !ok {
+			continue
+		}
+
+		account := models.DeviceAccount{
+			UserID:      user.ID,
+			Username:    user.Username,
+			DisplayName: user.DisplayName,
+			ProfilePic:  user.ProfilePic,
+			IsVerified:  user.IsVerified,
+			IsCurrent:   user.ID == current.UserID,
+		}
+
+		account.UnreadNotifications, err = as.db.Collection("notifications").CountDocuments(ctx, bson.M{
+			"recipient_id": user.ID,
+			"is_read":      false,
+		}, countOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		account.UnreadConversations, err = as.db.Collection("conversations").CountDocuments(ctx, repository.NotDeleted(bson.M{
+			"participant_info": bson.M{"$elemMatch": bson.M{
+				"user_id":      user.ID,
+				"left_at":      bson.M{"$exists": false},
+				"unread_count": bson.M{"$gt": 0},
+			}},
+		}), countOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		accounts = append(accounts, account)
+	}
+
+	return accounts, nil
+}
+
+// SwitchAccount issues tokens for another account on the device of the given
+// session. An account with a live session on the device switches without a
+// password; otherwise the password signs it in to the device again.
+func (as *AuthService) SwitchAccount(sessionID string, targetUserID primitive.ObjectID, password string) (*LoginResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	current, err := as.deviceGroupSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if current.UserID == targetUserID {
+		return nil, errors.New("invalid switch, account is already the current account")
+	}
+
+	var user models.User
+	err = as.userCollection.FindOne(ctx, repository.NotDeleted(bson.M{
+		"_id":       targetUserID,
+		"is_active": true,
+	})).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("account not found")
+		}
+		return nil, err
+	}
+	if user.IsSuspended {
+		return nil, errors.New("account is suspended")
+	}
+
+	filter := deviceGroupFilter(current.DeviceGroupID)
+	filter["user_id"] = targetUserID
+
+	var target Session
+	err = as.sessionCollection.FindOne(ctx, filter, options.FindOne().SetSort(bson.D{{Key: "last_activity_at", Value: -1}})).Decode(&target)
+	if err == nil {
+		as.UpdateSessionActivity(target.SessionID)
+		return as.loginResponse(&user, target.SessionID, target.DeviceInfo)
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	// No live session on the device: the account has to sign in again
+	if password == "" {
+		return nil, errors.New("credentials required, account has no session on this device")
+	}
+	if !utils.CheckPasswordHash(password, user.Password) {
+		return nil, errors.New("invalid credentials")
+	}
+
+	return as.joinDeviceGroup(ctx, &user, current)
+}
@@ -0,0 +1,443 @@
+// internal/services/boosted_post_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// boostCandidateLimit bounds how many live campaigns are considered for one feed page
+const boostCandidateLimit = 20
+
+// BoostPolicy holds the delivery defaults for boosted posts
+type BoostPolicy struct {
+	DefaultDailyFrequencyCap int
+	AdFreePlans              []string // Subscription plans excluded from delivery
+}
+
+type BoostedPostService struct {
+	collection *mongo.Collection
+	deliveries *mongo.Collection
+	db         *mongo.Database
+	policy     BoostPolicy
+}
+
+func NewBoostedPostService(db *mongo.Database, policy BoostPolicy) *BoostedPostService {
+	return &BoostedPostService{
+		collection: db.Collection("boosted_posts"),
+		deliveries: db.Collection("boost_deliveries"),
+		db:         db,
+		policy:     policy,
+	}
+}
+
+// boostablePostFilter matches a post that may be shown to people outside the
+// author's audience: published, public, and not hidden or under review
+func boostablePostFilter(postID primitive.ObjectID) bson.M {
+	return repository.NotDeleted(bson.M{
+		"_id":          postID,
+		"is_published": true,
+		"visibility":   models.PrivacyPublic,
+		"is_hidden":    bson.M{"$ne": true},
+		"is_reported":  bson.M{"$ne": true},
+	})
+}
+
+// CreateBoostedPost starts a boost campaign for a post
+func (bs *BoostedPostService) CreateBoostedPost(adminID primitive.ObjectID, req models.CreateBoostedPostRequest) (*models.BoostedPost, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	postID, err := primitive.ObjectIDFromHex(req.PostID)
+	if err != nil {
+		return nil, errors.New("invalid post ID")
+	}
+
+	var post models.Post
+	if err := bs.db.Collection("posts").FindOne(ctx, boostablePostFilter(postID)).Decode(&post); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("post not found or not eligible for boosting")
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	boost := &models.BoostedPost{
+		PostID:            post.ID,
+		AuthorID:          post.UserID,
+		CreatedBy:         adminID,
+		Audience:          req.Audience,
+		Hashtags:          normalizeBoostHashtags(req.Hashtags),
+		StartsAt:          now,
+		EndsAt:            req.EndsAt,
+		ImpressionBudget:  req.ImpressionBudget,
+		DailyFrequencyCap: req.DailyFrequencyCap,
+		Status:            models.BoostStatusActive,
+	}
+	if req.StartsAt != nil {
+		boost.StartsAt = *req.StartsAt
+	}
+	if boost.DailyFrequencyCap == 0 {
+		boost.DailyFrequencyCap = bs.policy.DefaultDailyFrequencyCap
+	}
+	for _, region := range req.Regions {
+		boost.Regions = append(boost.Regions, strings.ToUpper(region))
+	}
+
+	switch boost.Audience {
+	case models.BoostAudienceInterests:
+		if len(boost.Hashtags) == 0 {
+			boost.Hashtags = normalizeBoostHashtags(post.Hashtags)
+		}
+		if len(boost.Hashtags) == 0 {
+			return nil, errors.New("invalid targeting: the interests audience needs hashtags")
+		}
+	case models.BoostAudienceRegion:
+		if len(boost.Regions) == 0 {
+			return nil, errors.New("invalid targeting: the region audience needs regions")
+		}
+	}
+
+	if !boost.EndsAt.After(boost.StartsAt) || !boost.EndsAt.After(now) {
+		return nil, errors.New("invalid schedule: end time must be in the future and after the start time")
+	}
+
+	boost.BeforeCreate()
+	result, err := bs.collection.InsertOne(ctx, boost)
+	if err != nil {
+		return nil, err
+	}
+	boost.ID = result.InsertedID.(primitive.ObjectID)
+
+	return boost, nil
+}
+
+// GetBoostedPosts lists boost campaigns, newest first, optionally by status
+func (bs *BoostedPostService) GetBoostedPosts(status string, limit, skip int) ([]models.BoostedPost, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := repository.NotDeleted()
+	if status != "" {
+		filter["status"] = status
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"created_at": -1}).
+		SetLimit(int64(limit)).
+		SetSkip(int64(skip))
+
+	cursor, err := bs.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	boosts := []models.BoostedPost{}
+	if err := cursor.All(ctx, &boosts); err != nil {
+		return nil, 0, err
+	}
+
+	total, err := bs.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return boosts, total, nil
+}
+
+// GetBoostedPost returns one boost campaign
+func (bs *BoostedPostService) GetBoostedPost(boostID primitive.ObjectID) (*models.BoostedPost, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return bs.findBoost(ctx, boostID)
+}
+
+func (bs *BoostedPostService) findBoost(ctx context.Context, boostID primitive.ObjectID) (*models.BoostedPost, error) {
+	var boost models.BoostedPost
+	if err := bs.collection.FindOne(ctx, repository.ByID(boostID)).Decode(&boost); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("boosted post not found")
+		}
+		return nil, err
+	}
+	return &boost, nil
+}
+
+// UpdateBoostedPost pauses or resumes a campaign or changes its end time,
+// budget or frequency cap. A campaign paused for an exhausted budget only
+// resumes once the budget is raised.
+func (bs *BoostedPostService) UpdateBoostedPost(boostID primitive.ObjectID, req models.UpdateBoostedPostRequest) (*models.BoostedPost, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	boost, err := bs.findBoost(ctx, boostID)
+	if err != nil {
+		return nil, err
+	}
+
+	set := bson.M{"updated_at": time.Now()}
+	unset := bson.M{}
+	if req.EndsAt != nil {
+		boost.EndsAt = *req.EndsAt
+		set["ends_at"] = boost.EndsAt
+	}
+	if req.ImpressionBudget != nil {
+		boost.ImpressionBudget = *req.ImpressionBudget
+		set["impression_budget"] = boost.ImpressionBudget
+	}
+	if req.DailyFrequencyCap != nil {
+		boost.DailyFrequencyCap = *req.DailyFrequencyCap
+		set["daily_frequency_cap"] = boost.DailyFrequencyCap
+	}
+	if req.Status != nil {
+		boost.Status = *req.Status
+		set["status"] = boost.Status
+		boost.PauseReason = ""
+		unset["pause_reason"] = ""
+	}
+
+	if !boost.EndsAt.After(boost.StartsAt) {
+		return nil, errors.New("invalid schedule: end time must be after the start time")
+	}
+	if boost.Status == models.BoostStatusActive && boost.Impressions >= boost.ImpressionBudget {
+		return nil, errors.New("invalid budget: raise the impression budget before resuming")
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	if _, err := bs.collection.UpdateOne(ctx, bson.M{"_id": boostID}, update); err != nil {
+		return nil, err
+	}
+
+	return boost, nil
+}
+
+// DeleteBoostedPost ends a campaign and removes it from the admin list.
+// Delivery records are kept.
+func (bs *BoostedPostService) DeleteBoostedPost(boostID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	result, err := bs.collection.UpdateOne(ctx, repository.ByID(boostID), bson.M{
+		"$set": bson.M{"status": models.BoostStatusPaused, "deleted_at": now, "updated_at": now},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("boosted post not found")
+	}
+	return nil
+}
+
+// GetBoostPerformance reports a campaign's impressions, unique reach, taps,
+// and follows of the author by users after they were first served the post
+func (bs *BoostedPostService) GetBoostPerformance(boostID primitive.ObjectID) (*models.BoostPerformance, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	boost, err := bs.findBoost(ctx, boostID)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := bs.deliveries.Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"boost_id": boostID, "impressions": bson.M{"$gt": 0}}},
+		{"$group": bson.M{
+			"_id":             "$user_id",
+			"first_served_at": bson.M{"$min": "$first_served_at"},
+		}},
+		{"$lookup": bson.M{
+			"from": "follows",
+			"let":  bson.M{"user_id": "$_id", "since": "$first_served_at"},
+			"pipeline": []bson.M{
+				{"$match": bson.M{
+					"followee_id": boost.AuthorID,
+					"$expr": bson.M{"$and": []bson.M{
+						{"$eq": []interface{}{"$follower_id", "$$user_id"}},
+						{"$gte": []interface{}{"$created_at", "$$since"}},
+					}},
+				}},
+				{"$limit": 1},
+			},
+			"as": "follows",
+		}},
+		{"$group": bson.M{
+			"_id":   nil,
+			"reach": bson.M{"$sum": 1},
+			"follows": bson.M{"$sum": bson.M{
+				"$cond": []interface{}{bson.M{"$gt": []interface{}{bson.M{"$size": "$follows"}, 0}}, 1, 0},
+			}},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var totals struct {
+		Reach   int64 `bson:"reach"`
+		Follows int64 `bson:"follows"`
+	}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&totals); err != nil {
+			return nil, err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	performance := &models.BoostPerformance{
+		BoostID:          boost.ID.Hex(),
+		Status:           boost.Status,
+		PauseReason:      boost.PauseReason,
+		Impressions:      boost.Impressions,
+		ImpressionBudget: boost.ImpressionBudget,
+		UniqueReach:      totals.Reach,
+		Taps:             boost.Taps,
+		ResultingFollows: totals.Follows,
+	}
+	if boost.Impressions > 0 {
+		performance.TapRate = float64(boost.Taps) / float64(boost.Impressions)
+	}
+
+	return performance, nil
+}
+
+// liveBoosts returns campaigns that may deliver now, least delivered first
+// so budgets are spread across campaigns
+func (bs *BoostedPostService) liveBoosts(ctx context.Context, now time.Time) ([]models.BoostedPost, error) {
+	cursor, err := bs.collection.Find(ctx, repository.NotDeleted(bson.M{
+		"status":    models.BoostStatusActive,
+		"starts_at": bson.M{"$lte": now},
+		"ends_at":   bson.M{"$gt": now},
+		"$expr":     bson.M{"$lt": []interface{}{"$impressions", "$impression_budget"}},
+	}), options.Find().SetSort(bson.M{"impressions": 1}).SetLimit(boostCandidateLimit))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var boosts []models.BoostedPost
+	if err := cursor.All(ctx, &boosts); err != nil {
+		return nil, err
+	}
+	return boosts, nil
+}
+
+// reserveDelivery counts serving boost to userID today. It reports false
+// when the user already reached the campaign's daily frequency cap.
+func (bs *BoostedPostService) reserveDelivery(ctx context.Context, boost *models.BoostedPost, userID primitive.ObjectID, now time.Time) (bool, error) {
+	// The unique index on (boost_id, user_id, day) turns the upsert into a
+	// duplicate key error once today's delivery is at the cap
+	_, err := bs.deliveries.UpdateOne(ctx, bson.M{
+		"boost_id": boost.ID,
+		"user_id":  userID,
+		"day":      boostDay(now),
+		"served":   bson.M{"$lt": boost.DailyFrequencyCap},
+	}, bson.M{
+		"$inc":         bson.M{"served": 1},
+		"$setOnInsert": bson.M{"first_served_at": now, "impressions": 0, "taps": 0},
+	}, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// recordBoostImpression counts an impression of a served boosted post. Only
+// impressions of posts actually served to the user count, at most once per
+// serve, and the campaign pauses once its impression budget is spent.
+func recordBoostImpression(ctx context.Context, db *mongo.Database, boostID, postID, userID primitive.ObjectID) error {
+	now := time.Now()
+	err := db.Collection("boost_deliveries").FindOneAndUpdate(ctx, bson.M{
+		"boost_id": boostID,
+		"user_id":  userID,
+		"$expr":    bson.M{"$lt": []interface{}{"$impressions", "$served"}},
+	}, bson.M{
+		"$inc": bson.M{"impressions": 1},
+		"$set": bson.M{"last_impression_at": now},
+	}, options.FindOneAndUpdate().SetSort(bson.M{"day": -1})).Err()
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	boosts := db.Collection("boosted_posts")
+	if _, err := boosts.UpdateOne(ctx, bson.M{"_id": boostID, "post_id": postID}, bson.M{
+		"$inc": bson.M{"impressions": 1},
+	}); err != nil {
+		return err
+	}
+
+	_, err = boosts.UpdateOne(ctx, bson.M{
+		"_id":    boostID,
+		"status": models.BoostStatusActive,
+		"$expr":  bson.M{"$gte": []interface{}{"$impressions", "$impression_budget"}},
+	}, bson.M{
+		"$set": bson.M{
+			"status":       models.BoostStatusPaused,
+			"pause_reason": models.BoostPauseBudgetExhausted,
+			"updated_at":   now,
+		},
+	})
+	return err
+}
+
+// recordBoostTap counts a user opening a boosted post they were served, at
+// most once per serve
+func recordBoostTap(ctx context.Context, db *mongo.Database, boostID, postID, userID primitive.ObjectID) error {
+	err := db.Collection("boost_deliveries").FindOneAndUpdate(ctx, bson.M{
+		"boost_id": boostID,
+		"user_id":  userID,
+		"$expr":    bson.M{"$lt": []interface{}{"$taps", "$served"}},
+	}, bson.M{
+		"$inc": bson.M{"taps": 1},
+	}, options.FindOneAndUpdate().SetSort(bson.M{"day": -1})).Err()
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection("boosted_posts").UpdateOne(ctx, bson.M{"_id": boostID, "post_id": postID}, bson.M{
+		"$inc": bson.M{"taps": 1},
+	})
+	return err
+}
+
+// boostDay is the UTC day frequency caps are counted in
+func boostDay(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// normalizeBoostHashtags lowercases hashtags and strips the leading #
+func normalizeBoostHashtags(hashtags []string) []string {
+	var normalized []string
+	for _, tag := range hashtags {
+		tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+		if tag != "" {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
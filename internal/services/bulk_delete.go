@@ -0,0 +1,230 @@
+// internal/services/bulk_delete.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// bulkDeleteBatch is how many items a bulk deletion loads at a time
+const bulkDeleteBatch = 100
+
+// BulkDeletePolicy caps how much of their own content users can delete in
+// one request
+type BulkDeletePolicy struct {
+	MaxIDs    int           // IDs one request may list
+	MaxItems  int64         // Items one request may delete; 0 means no cap
+	SyncLimit int64         // Deletions up to this size finish within the request, larger ones run as a background job
+	JobTTL    time.Duration // How long job records are kept for status checks
+}
+
+// bulkDeleter runs the bulk deletions of one content type, recording each as
+// a job. The content's service supplies how a batch of items is deleted.
+type bulkDeleter struct {
+	contentType string
+	content     *mongo.Collection
+	jobs        *mongo.Collection
+	policy      BulkDeletePolicy
+
+	// deleteBatch soft-deletes up to limit items matching the filter and
+	// returns how many it deleted; 0 means nothing is left
+	deleteBatch func(ctx context.Context, filter bson.M, limit int64) (int64, error)
+}
+
+func newBulkDeleter(db *mongo.Database, contentType string, content *mongo.Collection) *bulkDeleter {
+	return &bulkDeleter{
+		contentType: contentType,
+		content:     content,
+		jobs:        db.Collection("bulk_delete_jobs"),
+	}
+}
+
+// query builds the filter matching a user's live content selected by filter
+func (bd *bulkDeleter) query(userID primitive.ObjectID, filter models.BulkDeleteFilter) bson.M {
+	query := bson.M{"user_id": userID}
+	if len(filter.IDs) > 0 {
+		query["_id"] = bson.M{"$in": filter.ObjectIDs()}
+	}
+	if filter.From != nil || filter.To != nil {
+		createdAt := bson.M{}
+		if filter.From != nil {
+			createdAt["$gte"] = *filter.From
+		}
+		if filter.To != nil {
+			createdAt["$lt"] = *filter.To
+		}
+		query["created_at"] = createdAt
+	}
+	return repository.NotDeleted(query)
+}
+
+// preview counts what the filter matches
+func (bd *bulkDeleter) preview(userID primitive.ObjectID, filter models.BulkDeleteFilter) (*models.BulkDeletePreview, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := filter.Validate(bd.policy.MaxIDs); err != nil {
+		return nil, err
+	}
+
+	matched, err := bd.content.CountDocuments(ctx, bd.query(userID, filter))
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.BulkDeletePreview{
+		Matched:  matched,
+		MaxItems: bd.policy.MaxItems,
+		Async:    matched > bd.policy.SyncLimit,
+	}, nil
+}
+
+// start checks the confirmation against what the filter matches now and
+// deletes it, within the request when it is small and as a background job
+// otherwise
+func (bd *bulkDeleter) start(userID primitive.ObjectID, req models.BulkDeleteRequest) (*models.BulkDeleteJob, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := req.Validate(bd.policy.MaxIDs); err != nil {
+		return nil, err
+	}
+
+	query := bd.query(userID, req.BulkDeleteFilter)
+	matched, err := bd.content.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if matched == 0 {
+		return nil, fmt.Errorf("no %s match the filter", bd.contentType)
+	}
+	if bd.policy.MaxItems > 0 && matched > bd.policy.MaxItems {
+		return nil, fmt.Errorf("too many %s match the filter: %d, at most %d can be deleted per request", bd.contentType, matched, bd.policy.MaxItems)
+	}
+	if req.ConfirmCount != matched {
+		return nil, fmt.Errorf("confirmation count does not match: %d %s match the filter", matched, bd.contentType)
+	}
+
+	now := time.Now()
+	job := &models.BulkDeleteJob{
+		ID:          primitive.NewObjectID(),
+		UserID:      userID,
+		ContentType: bd.contentType,
+		Filter:      req.BulkDeleteFilter,
+		Status:      models.BulkDeleteQueued,
+		Matched:     matched,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(bd.policy.JobTTL),
+	}
+	if _, err := bd.jobs.InsertOne(ctx, job); err != nil {
+		return nil, err
+	}
+
+	if matched > bd.policy.SyncLimit {
+		go bd.run(*job)
+		return job, nil
+	}
+
+	bd.run(*job)
+	return bd.job(userID, job.ID)
+}
+
+// resume restarts the jobs a previous process left unfinished. Deleted items
+// no longer match the filter, so a job picks up where it stopped.
+func (bd *bulkDeleter) resume() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := bd.jobs.Find(ctx, bson.M{
+		"content_type": bd.contentType,
+		"status":       bson.M{"$in": []string{models.BulkDeleteQueued, models.BulkDeleteProcessing}},
+	})
+	if err != nil {
+		log.Printf("Failed to load interrupted bulk %s deletions: %v", bd.contentType, err)
+		return
+	}
+
+	var jobs []models.BulkDeleteJob
+	if err := cursor.All(ctx, &jobs); err != nil {
+		log.Printf("Failed to load interrupted bulk %s deletions: %v", bd.contentType, err)
+		return
+	}
+
+	for _, job := range jobs {
+		log.Printf("Resuming bulk %s deletion %s", bd.contentType, job.ID.Hex())
+		go bd.run(job)
+	}
+}
+
+// run deletes a job's content batch by batch, recording progress on the job
+func (bd *bulkDeleter) run(job models.BulkDeleteJob) {
+	ctx := context.Background()
+	query := bd.query(job.UserID, job.Filter)
+
+	bd.updateJob(job.ID, bson.M{"status": models.BulkDeleteProcessing, "started_at": time.Now()})
+
+	deleted := job.Deleted
+	for deleted < job.Matched {
+		n, err := bd.deleteBatch(ctx, query, min(bulkDeleteBatch, job.Matched-deleted))
+		if err != nil {
+			log.Printf("Bulk %s deletion %s failed: %v", bd.contentType, job.ID.Hex(), err)
+			bd.updateJob(job.ID, bson.M{
+				"status":        models.BulkDeleteFailed,
+				"deleted":       deleted,
+				"error_message": err.Error(),
+				"completed_at":  time.Now(),
+			})
+			return
+		}
+		if n == 0 {
+			break
+		}
+		deleted += n
+		bd.updateJob(job.ID, bson.M{"deleted": deleted})
+	}
+
+	bd.updateJob(job.ID, bson.M{
+		"status":       models.BulkDeleteCompleted,
+		"deleted":      deleted,
+		"completed_at": time.Now(),
+	})
+}
+
+// job retrieves one of a user's bulk deletion jobs
+func (bd *bulkDeleter) job(userID, jobID primitive.ObjectID) (*models.BulkDeleteJob, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var job models.BulkDeleteJob
+	err := bd.jobs.FindOne(ctx, bson.M{
+		"_id":          jobID,
+		"user_id":      userID,
+		"content_type": bd.contentType,
+	}).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("bulk delete job not found")
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (bd *bulkDeleter) updateJob(jobID primitive.ObjectID, set bson.M) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := bd.jobs.UpdateOne(ctx, bson.M{"_id": jobID}, bson.M{"$set": set}); err != nil {
+		log.Printf("Failed to update bulk delete job %s: %v", jobID.Hex(), err)
+	}
+}
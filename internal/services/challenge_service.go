@@ -0,0 +1,482 @@
+// internal/services/challenge_service.go
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"math/bits"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"social-media-api/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// challengeEvents counts challenges per route group, keyed
+// "issued:<group>", "solved:<group>", "failed:<group>" and "bypassed:<group>"
+var challengeEvents = expvar.NewMap("pow_challenges")
+
+// ChallengePolicy configures the adaptive proof-of-work challenge
+type ChallengePolicy struct {
+	Enabled              bool // Feature flag; when off no route group is ever challenged
+	Secret               string
+	TTL                  time.Duration
+	BaseDifficulty       int
+	MaxDifficulty        int
+	TrustedAccountAge    time.Duration
+	SignupThreshold      int64 // Signups per signupWindow
+	FailedLoginThreshold int64 // Failed logins per minute
+	PerIPThreshold       int64 // Requests per minute from one IP to one route group
+	SettingsTTL          time.Duration
+}
+
+const (
+	// Signup velocity is measured over this window and recounted at most
+	// once per signupRecount
+	signupWindow  = 10 * time.Minute
+	signupRecount = 30 * time.Second
+
+	// Each risk level above 1 adds this many leading zero bits
+	challengeDifficultyStep = 2
+)
+
+// challengeWindow counts one route group's traffic for the current minute
+type challengeWindow struct {
+	start    time.Time
+	failures int64
+	perIP    map[string]int64
+}
+
+// ChallengeService decides when route groups demand proof-of-work and
+// issues and verifies the challenges.
+//
+// Challenges are verified statelessly: the token carries its group,
+// difficulty and expiry and is signed with the policy secret. Only solved
+// tokens are remembered, until they expire, so each can be used once. Rates
+// are counted per instance, so thresholds apply to each instance separately.
+type ChallengeService struct {
+	userCollection     *mongo.Collection
+	settingsCollection *mongo.Collection
+	policy             ChallengePolicy
+
+	mu         sync.Mutex
+	settings   models.ChallengeSettings
+	settingsAt time.Time
+	windows    map[string]*challengeWindow
+	solved     map[string]time.Time // Solved challenge tokens until they expire
+	prunedAt   time.Time
+
+	signupMu  sync.Mutex
+	signups   int64
+	signupsAt time.Time
+}
+
+func NewChallengeService(db *mongo.Database, policy ChallengePolicy) *ChallengeService {
+	return &ChallengeService{
+		userCollection:     db.Collection("users"),
+		settingsCollection: db.Collection("app_settings"),
+		policy:             policy,
+		windows:            make(map[string]*challengeWindow),
+		solved:             make(map[string]time.Time),
+	}
+}
+
+// Enabled reports whether challenges are switched on by the feature flag
+func (cs *ChallengeService) Enabled() bool {
+	return cs.policy.Enabled
+}
+
+// IsTrusted reports whether a user skips challenges entirely: staff,
+// verified accounts and accounts older than the trusted age
+func (cs *ChallengeService) IsTrusted(user *models.User) bool {
+	if user == nil {
+		return false
+	}
+	if user.Role == models.RoleModerator || user.Role == models.RoleAdmin || user.Role == models.RoleSuperAdmin {
+		return true
+	}
+	return user.IsVerified || time.Since(user.CreatedAt) >= cs.policy.TrustedAccountAge
+}
+
+// RiskLevel returns the risk for a request from ip to the route group, from
+// 0 (no challenge) to MaxChallengeRisk. Admin overrides win over the
+// heuristics: off disables the challenge, on forces at least level 1, and the
+// group's minimum risk is a floor in on and auto modes.
+func (cs *ChallengeService) RiskLevel(group, ip string) int {
+	settings := cs.groupSettings(group)
+
+	switch settings.Mode {
+	case models.ChallengeModeOff:
+		return 0
+	case models.ChallengeModeOn:
+		return maxInt(settings.MinRisk, 1)
+	}
+
+	risk := maxInt(settings.MinRisk, cs.groupRisk(group))
+
+	cs.mu.Lock()
+	if window := cs.currentWindow(group); window != nil {
+		risk = maxInt(risk, riskFromRate(window.perIP[ip], cs.policy.PerIPThreshold))
+	}
+	cs.mu.Unlock()
+
+	return risk
+}
+
+// Difficulty returns the leading zero bits required at a risk level
+func (cs *ChallengeService) Difficulty(risk int) int {
+	if risk <= 0 {
+		return 0
+	}
+	difficulty := cs.policy.BaseDifficulty + (risk-1)*challengeDifficultyStep
+	if difficulty > cs.policy.MaxDifficulty {
+		return cs.policy.MaxDifficulty
+	}
+	return difficulty
+}
+
+// ObserveRequest counts a request from ip to the route group
+func (cs *ChallengeService) ObserveRequest(group, ip string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	window := cs.currentWindow(group)
+	if window == nil {
+		window = &challengeWindow{start: time.Now().Truncate(time.Minute), perIP: make(map[string]int64)}
+		cs.windows[group] = window
+	}
+	window.perIP[ip]++
+}
+
+// ObserveFailure counts a failed request, such as a rejected login, to the route group
+func (cs *ChallengeService) ObserveFailure(group string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if window := cs.currentWindow(group); window != nil {
+		window.failures++
+	}
+}
+
+// RecordBypass counts a trusted user let through without a challenge
+func (cs *ChallengeService) RecordBypass(group string) {
+	challengeEvents.Add("bypassed:"+group, 1)
+}
+
+// Issue creates a challenge for the route group at the risk level
+func (cs *ChallengeService) Issue(group string, risk int) (*models.ProofOfWorkChallenge, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	difficulty := cs.Difficulty(risk)
+	expiresAt := time.Now().Add(cs.policy.TTL)
+	payload := fmt.Sprintf("%s.%d.%d.%s", group, difficulty, expiresAt.Unix(), hex.EncodeToString(nonce))
+
+	challengeEvents.Add("issued:"+group, 1)
+
+	return &models.ProofOfWorkChallenge{
+		Challenge:  payload + "." + cs.sign(payload),
+		Difficulty: difficulty,
+		Algorithm:  "sha256",
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// Verify checks a solved challenge for the route group. The challenge must be
+// signed by this service, unexpired, at least as hard as the current risk
+// requires, and not used before.
+func (cs *ChallengeService) Verify(group, challenge, solution string, risk int) error {
+	err := cs.verify(group, challenge, solution, risk)
+	if err != nil {
+		challengeEvents.Add("failed:"+group, 1)
+		return err
+	}
+	challengeEvents.Add("solved:"+group, 1)
+	return nil
+}
+
+// GetStatus returns each route group's settings, current risk and counters
+func (cs *ChallengeService) GetStatus() []models.ChallengeGroupStatus {
+	statuses := make([]models.ChallengeGroupStatus, 0, len(models.ChallengeGroups))
+	for _, group := range models.ChallengeGroups {
+		settings := cs.groupSettings(group)
+
+		risk := 0
+		if cs.policy.Enabled {
+			risk = cs.RiskLevel(group, "")
+		}
+
+		statuses = append(statuses, models.ChallengeGroupStatus{
+			Group:      group,
+			Mode:       settings.Mode,
+			MinRisk:    settings.MinRisk,
+			Risk:       risk,
+			Difficulty: cs.Difficulty(risk),
+			Issued:     challengeEventCount("issued:" + group),
+			Solved:     challengeEventCount("solved:" + group),
+			Failed:     challengeEventCount("failed:" + group),
+			Bypassed:   challengeEventCount("bypassed:" + group),
+		})
+	}
+	return statuses
+}
+
+// UpdateGroup forces a route group's challenge on or off, or back to auto.
+// The change applies on this instance at once and on others within SettingsTTL.
+func (cs *ChallengeService) UpdateGroup(adminID primitive.ObjectID, group string, req models.UpdateChallengeGroupRequest) (*models.ChallengeGroupSettings, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if !isChallengeGroup(group) {
+		return nil, errors.New("unknown challenge group")
+	}
+
+	settings := models.ChallengeGroupSettings{
+		Mode:      req.Mode,
+		MinRisk:   req.MinRisk,
+		UpdatedAt: time.Now(),
+		UpdatedBy: adminID.Hex(),
+	}
+
+	_, err := cs.settingsCollection.UpdateOne(ctx,
+		bson.M{"_id": models.ChallengeSettingsKey},
+		bson.M{"$set": bson.M{"value.groups." + group: settings, "updated_at": settings.UpdatedAt}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save challenge settings: %w", err)
+	}
+
+	cs.mu.Lock()
+	if cs.settings.Groups == nil {
+		cs.settings.Groups = make(map[string]models.ChallengeGroupSettings)
+	}
+	cs.settings.Groups[group] = settings
+	cs.mu.Unlock()
+
+	log.Printf("Proof-of-work challenge for %s set to %s (min risk %d) by %s", group, req.Mode, req.MinRisk, adminID.Hex())
+	return &settings, nil
+}
+
+// Helper methods
+
+func (cs *ChallengeService) verify(group, challenge, solution string, risk int) error {
+	parts := strings.Split(challenge, ".")
+	if len(parts) != 5 {
+		return errors.New("malformed challenge")
+	}
+
+	payload := strings.Join(parts[:4], ".")
+	if !hmac.Equal([]byte(parts[4]), []byte(cs.sign(payload))) {
+		return errors.New("invalid challenge signature")
+	}
+	if parts[0] != group {
+		return errors.New("challenge issued for another route")
+	}
+
+	difficulty, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return errors.New("malformed challenge")
+	}
+	expiresAt, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return errors.New("malformed challenge")
+	}
+
+	now := time.Now()
+	if now.Unix() > expiresAt {
+		return errors.New("challenge expired")
+	}
+	if difficulty < cs.Difficulty(risk) {
+		return errors.New("challenge difficulty too low")
+	}
+
+	sum := sha256.Sum256([]byte(challenge + ":" + solution))
+	if leadingZeroBits(sum[:]) < difficulty {
+		return errors.New("incorrect solution")
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if now.Sub(cs.prunedAt) > time.Minute {
+		for token, expiry := range cs.solved {
+			if now.After(expiry) {
+				delete(cs.solved, token)
+			}
+		}
+		cs.prunedAt = now
+	}
+	if _, used := cs.solved[challenge]; used {
+		return errors.New("challenge already used")
+	}
+	cs.solved[challenge] = time.Unix(expiresAt, 0)
+
+	return nil
+}
+
+func (cs *ChallengeService) sign(payload string) string {
+	mac := hmac.New(sha256.New, []byte(cs.policy.Secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// currentWindow returns the group's counts for the current minute, or nil
+// when nothing was counted yet. Callers hold cs.mu.
+func (cs *ChallengeService) currentWindow(group string) *challengeWindow {
+	window := cs.windows[group]
+	if window == nil || time.Since(window.start) >= time.Minute {
+		delete(cs.windows, group)
+		return nil
+	}
+	return window
+}
+
+// groupRisk is the group-wide risk from the abuse heuristics: signup
+// velocity for registration and the failed login rate for login
+func (cs *ChallengeService) groupRisk(group string) int {
+	switch group {
+	case models.ChallengeGroupRegister:
+		return riskFromRate(cs.recentSignups(), cs.policy.SignupThreshold)
+	case models.ChallengeGroupLogin:
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+		if window := cs.currentWindow(group); window != nil {
+			return riskFromRate(window.failures, cs.policy.FailedLoginThreshold)
+		}
+	}
+	return 0
+}
+
+// recentSignups counts accounts created within signupWindow. The count is
+// kept for signupRecount so registration bursts don't each query it.
+func (cs *ChallengeService) recentSignups() int64 {
+	cs.signupMu.Lock()
+	defer cs.signupMu.Unlock()
+
+	if time.Since(cs.signupsAt) < signupRecount {
+		return cs.signups
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	count, err := cs.userCollection.CountDocuments(ctx, bson.M{
+		"created_at": bson.M{"$gte": time.Now().Add(-signupWindow)},
+	})
+	if err != nil {
+		log.Printf("Failed to count recent signups for challenge risk: %v", err)
+	} else {
+		cs.signups = count
+	}
+	cs.signupsAt = time.Now()
+
+	return cs.signups
+}
+
+// groupSettings returns the admin override for the group, re-reading the
+// settings store at most once per SettingsTTL. Groups without an override
+// run in auto mode.
+func (cs *ChallengeService) groupSettings(group string) models.ChallengeGroupSettings {
+	cs.mu.Lock()
+	stale := time.Since(cs.settingsAt) >= cs.policy.SettingsTTL
+	if stale {
+		// Claim the refresh so concurrent requests keep using the cached copy
+		cs.settingsAt = time.Now()
+	}
+	cs.mu.Unlock()
+
+	if stale {
+		cs.loadSettings()
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	settings, ok := cs.settings.Groups[group]
+	if !ok || settings.Mode == "" {
+		settings.Mode = models.ChallengeModeAuto
+	}
+	return settings
+}
+
+func (cs *ChallengeService) loadSettings() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	var stored struct {
+		Value models.ChallengeSettings `bson:"value"`
+	}
+	err := cs.settingsCollection.FindOne(ctx, bson.M{"_id": models.ChallengeSettingsKey}).Decode(&stored)
+	if err != nil && err != mongo.ErrNoDocuments {
+		// Keep the last known overrides
+		log.Printf("Failed to load challenge settings: %v", err)
+		return
+	}
+
+	cs.mu.Lock()
+	cs.settings = stored.Value
+	cs.mu.Unlock()
+}
+
+func isChallengeGroup(group string) bool {
+	for _, known := range models.ChallengeGroups {
+		if group == known {
+			return true
+		}
+	}
+	return false
+}
+
+// riskFromRate maps a rate to a risk level: level 1 at the threshold and one
+// more for each doubling, up to MaxChallengeRisk
+func riskFromRate(rate, threshold int64) int {
+	if threshold <= 0 || rate < threshold {
+		return 0
+	}
+	risk := 1
+	for limit := threshold * 2; rate >= limit && risk < models.MaxChallengeRisk; limit *= 2 {
+		risk++
+	}
+	return risk
+}
+
+func leadingZeroBits(hash []byte) int {
+	zeros := 0
+	for _, b := range hash {
+		if b != 0 {
+			return zeros + bits.LeadingZeros8(b)
+		}
+		zeros += 8
+	}
+	return zeros
+}
+
+func challengeEventCount(key string) int64 {
+	if counter, ok := challengeEvents.Get(key).(*expvar.Int); ok {
+		return counter.Value()
+	}
+	return 0
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -4,10 +4,14 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
 	"time"
 
 	"social-media-api/internal/config"
 	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+	"social-media-api/internal/utils"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -15,22 +19,70 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// quickReplyTopSenders is how many top senders a post's quick reaction summary lists
+const quickReplyTopSenders = 5
+
 type CommentService struct {
-	collection     *mongo.Collection
-	postCollection *mongo.Collection
-	userCollection *mongo.Collection
-	likeCollection *mongo.Collection
-	db             *mongo.Database
+	collection          *mongo.Collection
+	postCollection      *mongo.Collection
+	userCollection      *mongo.Collection
+	likeCollection      *mongo.Collection
+	db                  *mongo.Database
+	holdPolicy          CommentHoldPolicy
+	quickReplyLimit     int64 // Quick replies one user may leave on a post; 0 disables the cap
+	replyPreview        int   // Replies listed under each top-level comment of a post
+	notificationService *NotificationService
+	linkBlocklist       *LinkBlocklistService
+	floodPolicy         CommentFloodPolicy
+	bulkDeletes         *bulkDeleter
 }
 
-func NewCommentService() *CommentService {
-	return &CommentService{
-		collection:     config.DB.Collection("comments"),
-		postCollection: config.DB.Collection("posts"),
-		userCollection: config.DB.Collection("users"),
-		likeCollection: config.DB.Collection("likes"),
-		db:             config.DB,
-	}
+// CommentHoldPolicy decides which comments are held for spam review. Comments
+// with links are held unless the author's account is at least MinAccountAge
+// old and has MinApprovedComments approved comments.
+type CommentHoldPolicy struct {
+	MinAccountAge       time.Duration
+	MinApprovedComments int64
+	StrikeLimit         int64 // Rejected holds before the account is restricted; 0 disables
+}
+
+// CommentFloodPolicy caps how fast one user may comment: at most Limit
+// comments per Window across all posts and PerPostLimit on a single post,
+// with the Verified limits for accounts with the verified badge. The same
+// text can't be repeated within DuplicateWindow unless it is shorter than
+// DuplicateMinLength. 0 disables a limit.
+type CommentFloodPolicy struct {
+	Window               time.Duration
+	Limit                int
+	PerPostLimit         int
+	VerifiedLimit        int
+	VerifiedPerPostLimit int
+	DuplicateWindow      time.Duration
+	DuplicateMinLength   int
+}
+
+func NewCommentService(holdPolicy CommentHoldPolicy, floodPolicy CommentFloodPolicy, quickReplyLimit int64, replyPreview int, notificationService *NotificationService, linkBlocklist *LinkBlocklistService) *CommentService {
+	cs := &CommentService{
+		collection:          config.DB.Collection("comments"),
+		postCollection:      config.DB.Collection("posts"),
+		userCollection:      config.DB.Collection("users"),
+		likeCollection:      config.DB.Collection("likes"),
+		db:                  config.DB,
+		holdPolicy:          holdPolicy,
+		quickReplyLimit:     quickReplyLimit,
+		replyPreview:        replyPreview,
+		notificationService: notificationService,
+		linkBlocklist:       linkBlocklist,
+		floodPolicy:         floodPolicy,
+	}
+	cs.bulkDeletes = newBulkDeleter(config.DB, models.BulkDeleteComments, cs.collection)
+	cs.bulkDeletes.deleteBatch = cs.deleteCommentBatch
+	return cs
+}
+
+// SetBulkDeletePolicy sets the caps on users bulk deleting their comments
+func (cs *CommentService) SetBulkDeletePolicy(policy BulkDeletePolicy) {
+	cs.bulkDeletes.policy = policy
 }
 
 // CreateComment creates a new comment
@@ -46,12 +98,11 @@ func (cs *CommentService) CreateComment(userID primitive.ObjectID, req models.Cr
 
 	// Check if post exists and comments are enabled
 	var post models.Post
-	err = cs.postCollection.FindOne(ctx, bson.M{
+	err = cs.postCollection.FindOne(ctx, repository.NotDeleted(bson.M{
 		"_id":              postID,
 		"is_published":     true,
 		"comments_enabled": true,
-		"deleted_at":       bson.M{"$exists": false},
-	}).Decode(&post)
+	})).Decode(&post)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -60,6 +111,29 @@ func (cs *CommentService) CreateComment(userID primitive.ObjectID, req models.Cr
 		return nil, err
 	}
 
+	if err := cs.checkCommentPolicy(ctx, &post, userID); err != nil {
+		return nil, err
+	}
+
+	var author models.User
+	if err := cs.userCollection.FindOne(ctx, bson.M{"_id": userID}).Decode(&author); err != nil {
+		return nil, err
+	}
+	if author.Status == models.UserStatusRestricted {
+		return nil, errors.New("account is restricted from commenting")
+	}
+
+	isQuick := req.Kind == models.CommentKindQuick
+	contentHash, err := cs.checkCommentFlood(ctx, &author, postID, req.Content, isQuick)
+	if err != nil {
+		return nil, err
+	}
+	if isQuick {
+		if err := cs.checkQuickReply(ctx, postID, userID, req); err != nil {
+			return nil, err
+		}
+	}
+
 	// Convert parent comment ID if provided
 	var parentCommentID *primitive.ObjectID
 	if req.ParentCommentID != "" {
@@ -85,8 +159,13 @@ func (cs *CommentService) CreateComment(userID primitive.ObjectID, req models.Cr
 		ContentType:     req.ContentType,
 		Media:           req.Media,
 		Mentions:        mentions,
+		ContentHash:     contentHash,
 		IsApproved:      true, // Auto-approve by default
 	}
+	comment.PostedByDelegate = req.DelegateID
+	if isQuick {
+		comment.Kind = models.CommentKindQuick
+	}
 
 	// Set thread information
 	if parentCommentID != nil {
@@ -94,6 +173,9 @@ func (cs *CommentService) CreateComment(userID primitive.ObjectID, req models.Cr
 		if err != nil {
 			return nil, errors.New("parent comment not found")
 		}
+		if parentComment.IsQuickReply() {
+			return nil, errors.New("cannot reply to a quick reply")
+		}
 		comment.SetThreadInfo(parentComment)
 	} else {
 		comment.SetThreadInfo(nil)
@@ -103,12 +185,29 @@ func (cs *CommentService) CreateComment(userID primitive.ObjectID, req models.Cr
 
 	// Extract mentions from content if not provided
 	if len(comment.Mentions) == 0 {
-		mentionedUsernames := comment.GetMentionedUsernames()
+		mentionedUsernames := utils.ParseEntities(comment.Content).Usernames()
 		if len(mentionedUsernames) > 0 {
 			userIDs, _ := cs.getUserIDsByUsernames(mentionedUsernames)
 			comment.Mentions = userIDs
 		}
 	}
+	if err := models.CheckBounds(comment); err != nil {
+		return nil, err
+	}
+
+	// Links to blocklisted domains are refused, or the comment is hidden for review
+	blockedLink, err := cs.linkBlocklist.Screen(ctx, userID, models.LinkBlockContentComment, comment.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Links from accounts that haven't earned trust yet wait for review
+	trusted := cs.isTrustedCommenter(ctx, &author)
+	if blockedLink != nil {
+		comment.IsHidden = true
+	} else if !trusted && utils.ContainsLink(comment.Content) {
+		comment.Hold()
+	}
 
 	result, err := cs.collection.InsertOne(ctx, comment)
 	if err != nil {
@@ -116,25 +215,30 @@ func (cs *CommentService) CreateComment(userID primitive.ObjectID, req models.Cr
 	}
 
 	comment.ID = result.InsertedID.(primitive.ObjectID)
+	repository.RecordWrite(userID)
+
+	if blockedLink != nil {
+		// Hidden comments are never counted, and nobody is notified about them
+		cs.linkBlocklist.ReportHidden(ctx, userID, models.LinkBlockContentComment, comment.ID, blockedLink)
+	} else if comment.IsHeld() {
+		// Counts and notifications wait until the comment is approved
+		cs.queueHeldComment(ctx, comment)
+	} else {
+		cs.updateCommentCounts(ctx, comment, 1)
 
-	// Update post comments count
-	cs.postCollection.UpdateOne(ctx, bson.M{"_id": postID}, bson.M{
-		"$inc": bson.M{"comments_count": 1},
-	})
+		// Create mention notifications
+		if len(comment.Mentions) > 0 {
+			go cs.createMentionNotifications(userID, comment.ID, comment.Mentions)
+		}
 
-	// Update parent comment replies count if this is a reply
-	if parentCommentID != nil {
-		cs.collection.UpdateOne(ctx, bson.M{"_id": parentCommentID}, bson.M{
-			"$inc": bson.M{"replies_count": 1},
-		})
+		if comment.IsQuickReply() && cs.notificationService != nil {
+			go cs.notificationService.NotifyQuickReply(userID, post.UserID, postID, comment.Content)
+		}
 	}
 
-	// Update user's comments count
-	go cs.updateUserCommentsCount(userID, true)
-
-	// Create mention notifications
-	if len(comment.Mentions) > 0 {
-		go cs.createMentionNotifications(userID, comment.ID, comment.Mentions)
+	// Earlier comments held before the author became trusted are released now
+	if trusted {
+		go cs.releaseTrustedHeldComments(userID)
 	}
 
 	// Populate author information
@@ -143,23 +247,160 @@ func (cs *CommentService) CreateComment(userID primitive.ObjectID, req models.Cr
 	return comment, nil
 }
 
+// checkCommentPolicy enforces the post's comment policy. The post author can
+// always comment on their own post.
+func (cs *CommentService) checkCommentPolicy(ctx context.Context, post *models.Post, userID primitive.ObjectID) error {
+	if post.UserID == userID {
+		return nil
+	}
+
+	switch post.EffectiveCommentPolicy() {
+	case models.CommentPolicyOff:
+		return errors.New("comments disabled for this post")
+	case models.CommentPolicyFollowers:
+		count, err := cs.db.Collection("follows").CountDocuments(ctx, repository.NotDeleted(bson.M{
+			"follower_id": userID,
+			"followee_id": post.UserID,
+			"status":      models.FollowStatusAccepted,
+		}))
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return errors.New("comments limited to followers of the author")
+		}
+	case models.CommentPolicyMentionedOnly:
+		if !post.IsMentioned(userID) {
+			return errors.New("comments limited to users mentioned in the post")
+		}
+	}
+	return nil
+}
+
+// checkQuickReply validates a quick reply: emoji-only content on the post
+// itself, within the per-user cap for the post
+func (cs *CommentService) checkQuickReply(ctx context.Context, postID, userID primitive.ObjectID, req models.CreateCommentRequest) error {
+	if req.ParentCommentID != "" {
+		return errors.New("quick replies cannot be replies to comments")
+	}
+	if len(req.Media) > 0 {
+		return errors.New("quick replies cannot include media")
+	}
+	if !utils.IsEmojiOnly(req.Content, models.MaxQuickReplyEmoji) {
+		return fmt.Errorf("quick replies must contain only emoji (up to %d)", models.MaxQuickReplyEmoji)
+	}
+
+	if cs.quickReplyLimit <= 0 {
+		return nil
+	}
+
+	sent, err := cs.collection.CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"post_id": postID,
+		"user_id": userID,
+		"kind":    models.CommentKindQuick,
+	}), options.Count().SetLimit(cs.quickReplyLimit))
+	if err != nil {
+		return err
+	}
+	if sent >= cs.quickReplyLimit {
+		return errors.New("quick reply limit reached for this post")
+	}
+
+	return nil
+}
+
+// checkCommentFlood returns a CommentFloodError if the author is commenting
+// faster than the flood policy allows, or repeating a recent comment. It
+// returns the content hash to store for later duplicate checks, empty when the
+// comment is too short to compare. Deleted comments still count, so deleting
+// and reposting doesn't get around the limits.
+func (cs *CommentService) checkCommentFlood(ctx context.Context, author *models.User, postID primitive.ObjectID, content string, isQuick bool) (string, error) {
+	policy := cs.floodPolicy
+	now := time.Now()
+
+	limit, perPostLimit := policy.Limit, policy.PerPostLimit
+	if author.IsVerified {
+		limit, perPostLimit = policy.VerifiedLimit, policy.VerifiedPerPostLimit
+	}
+
+	if policy.Window > 0 {
+		filter := bson.M{"user_id": author.ID}
+		if err := cs.checkCommentRate(ctx, filter, models.CommentFloodGlobal, limit, now); err != nil {
+			return "", err
+		}
+		filter = bson.M{"user_id": author.ID, "post_id": postID}
+		if err := cs.checkCommentRate(ctx, filter, models.CommentFloodPost, perPostLimit, now); err != nil {
+			return "", err
+		}
+	}
+
+	// Quick replies are capped per post on their own and are meant to repeat
+	normalized := models.NormalizeContent(content)
+	if isQuick || len([]rune(normalized)) < policy.DuplicateMinLength {
+		return "", nil
+	}
+	hash := models.ContentHash(normalized)
+	if policy.DuplicateWindow <= 0 {
+		return hash, nil
+	}
+
+	var previous models.Comment
+	err := cs.collection.FindOne(ctx, bson.M{
+		"user_id":      author.ID,
+		"content_hash": hash,
+		"created_at":   bson.M{"$gte": now.Add(-policy.DuplicateWindow)},
+	}, options.FindOne().SetSort(bson.M{"created_at": -1}).SetProjection(bson.M{"created_at": 1})).Decode(&previous)
+	if err == nil {
+		retryAfter := previous.CreatedAt.Add(policy.DuplicateWindow).Sub(now)
+		return "", models.NewCommentFloodError(models.CommentFloodDuplicate, 0, policy.DuplicateWindow, retryAfter)
+	}
+	if err != mongo.ErrNoDocuments {
+		return "", err
+	}
+	return hash, nil
+}
+
+// checkCommentRate returns a CommentFloodError if the comments matching the
+// filter already reach the limit within the policy window. The wait lasts
+// until the oldest of those comments leaves the window.
+func (cs *CommentService) checkCommentRate(ctx context.Context, filter bson.M, scope string, limit int, now time.Time) error {
+	if limit <= 0 {
+		return nil
+	}
+	window := cs.floodPolicy.Window
+	filter["created_at"] = bson.M{"$gte": now.Add(-window)}
+
+	// The limit-th most recent comment in the window is the one that has to
+	// age out before another is allowed
+	var oldest models.Comment
+	err := cs.collection.FindOne(ctx, filter, options.FindOne().
+		SetSort(bson.M{"created_at": -1}).
+		SetSkip(int64(limit-1)).
+		SetProjection(bson.M{"created_at": 1})).Decode(&oldest)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return models.NewCommentFloodError(scope, limit, window, oldest.CreatedAt.Add(window).Sub(now))
+}
+
 // GetCommentByID retrieves a comment by ID
 func (cs *CommentService) GetCommentByID(commentID primitive.ObjectID, currentUserID *primitive.ObjectID) (*models.Comment, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	var comment models.Comment
-	err := cs.collection.FindOne(ctx, bson.M{
-		"_id":        commentID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&comment)
+	err := cs.collection.FindOne(ctx, repository.ByID(commentID)).Decode(&comment)
 
 	if err != nil {
 		return nil, err
 	}
 
 	// Check if user can view this comment
-	if !comment.CanViewComment() {
+	if !comment.CanBeViewedBy(currentUserID) {
 		return nil, errors.New("comment not accessible")
 	}
 
@@ -178,22 +419,18 @@ func (cs *CommentService) GetPostComments(postID primitive.ObjectID, currentUser
 
 	// Check if post exists
 	var post models.Post
-	err := cs.postCollection.FindOne(ctx, bson.M{
-		"_id":        postID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&post)
+	err := cs.postCollection.FindOne(ctx, repository.ByID(postID)).Decode(&post)
 
 	if err != nil {
 		return nil, err
 	}
 
-	filter := bson.M{
-		"post_id":     postID,
-		"level":       0, // Only top-level comments
-		"deleted_at":  bson.M{"$exists": false},
-		"is_hidden":   false,
-		"is_approved": true,
-	}
+	filter := visibleCommentsFilter(repository.NotDeleted(bson.M{
+		"post_id":   postID,
+		"level":     0, // Only top-level comments
+		"kind":      bson.M{"$ne": models.CommentKindQuick},
+		"is_hidden": false,
+	}), currentUserID)
 
 	// Set sort order
 	var sortOption bson.M
@@ -213,7 +450,10 @@ func (cs *CommentService) GetPostComments(postID primitive.ObjectID, currentUser
 		SetSkip(int64(skip)).
 		SetSort(sortOption)
 
-	cursor, err := cs.collection.Find(ctx, filter, opts)
+	// Comment lists may be served by a secondary; the commenter's own
+	// recent comments are read from the primary
+	ctx = repository.HeavyRead(ctx, currentUserID)
+	cursor, err := repository.ReadFrom(ctx, cs.collection).Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -229,9 +469,55 @@ func (cs *CommentService) GetPostComments(postID primitive.ObjectID, currentUser
 		cs.populateCommentAuthor(&comments[i])
 	}
 
+	if err := cs.attachReplyPreviews(ctx, comments, currentUserID); err != nil {
+		return nil, err
+	}
+
 	return comments, nil
 }
 
+// GetQuickReplies retrieves the emoji-only quick replies on a post, newest first
+func (cs *CommentService) GetQuickReplies(postID primitive.ObjectID, currentUserID *primitive.ObjectID, limit, skip int) ([]models.Comment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	count, err := cs.postCollection.CountDocuments(ctx, repository.ByID(postID))
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, errors.New("post not found")
+	}
+
+	filter := visibleCommentsFilter(repository.NotDeleted(bson.M{
+		"post_id":   postID,
+		"kind":      models.CommentKindQuick,
+		"is_hidden": false,
+	}), currentUserID)
+
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSkip(int64(skip)).
+		SetSort(bson.M{"created_at": -1})
+
+	cursor, err := cs.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var replies []models.Comment
+	if err := cursor.All(ctx, &replies); err != nil {
+		return nil, err
+	}
+
+	for i := range replies {
+		cs.populateCommentAuthor(&replies[i])
+	}
+
+	return replies, nil
+}
+
 // GetCommentReplies retrieves replies to a specific comment
 func (cs *CommentService) GetCommentReplies(commentID primitive.ObjectID, currentUserID *primitive.ObjectID, limit, skip int) ([]models.Comment, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -243,12 +529,10 @@ func (cs *CommentService) GetCommentReplies(commentID primitive.ObjectID, curren
 		return nil, err
 	}
 
-	filter := bson.M{
+	filter := visibleCommentsFilter(repository.NotDeleted(bson.M{
 		"parent_comment_id": commentID,
-		"deleted_at":        bson.M{"$exists": false},
 		"is_hidden":         false,
-		"is_approved":       true,
-	}
+	}), currentUserID)
 
 	opts := options.Find().
 		SetLimit(int64(limit)).
@@ -271,9 +555,38 @@ func (cs *CommentService) GetCommentReplies(commentID primitive.ObjectID, curren
 		cs.populateCommentAuthor(&replies[i])
 	}
 
+	if err := cs.countVisibleReplies(ctx, replies, currentUserID); err != nil {
+		return nil, err
+	}
+
 	return replies, nil
 }
 
+// GetReplies loads more replies to a comment, oldest first, after the reply
+// preview listed with the post's comments. The cursor is the comment's
+// replies_cursor or the next cursor of the previous page; the returned
+// cursor is empty when there are no more replies.
+func (cs *CommentService) GetReplies(commentID primitive.ObjectID, currentUserID *primitive.ObjectID, cursor string, limit int) ([]models.Comment, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Check if parent comment exists
+	if _, err := cs.GetCommentByID(commentID, currentUserID); err != nil {
+		return nil, "", err
+	}
+
+	replies, nextCursor, err := cs.findReplies(ctx, commentID, currentUserID, cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := cs.countVisibleReplies(ctx, replies, currentUserID); err != nil {
+		return nil, "", err
+	}
+
+	return replies, nextCursor, nil
+}
+
 // UpdateComment updates an existing comment
 func (cs *CommentService) UpdateComment(commentID, userID primitive.ObjectID, req models.UpdateCommentRequest) (*models.Comment, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -289,13 +602,18 @@ func (cs *CommentService) UpdateComment(commentID, userID primitive.ObjectID, re
 		return nil, errors.New("access denied")
 	}
 
+	// Quick replies are deleted and resent rather than edited
+	if comment.IsQuickReply() {
+		return nil, errors.New("quick replies cannot be edited")
+	}
+
 	update := bson.M{"$set": bson.M{"updated_at": time.Now()}}
 
 	// Update fields if provided
 	if req.Content != nil {
 		update["$set"].(bson.M)["content"] = *req.Content
 		// Re-extract mentions if content changed
-		mentionedUsernames := models.ExtractMentionsFromText(*req.Content)
+		mentionedUsernames := utils.ParseEntities(*req.Content).Usernames()
 		if len(mentionedUsernames) > 0 {
 			userIDs, _ := cs.getUserIDsByUsernames(mentionedUsernames)
 			update["$set"].(bson.M)["mentions"] = userIDs
@@ -313,21 +631,84 @@ func (cs *CommentService) UpdateComment(commentID, userID primitive.ObjectID, re
 		}
 		update["$set"].(bson.M)["mentions"] = mentions
 	}
+	if mentions, ok := update["$set"].(bson.M)["mentions"].([]primitive.ObjectID); ok {
+		if err := models.CheckBounds(models.Comment{Mentions: mentions}); err != nil {
+			return nil, err
+		}
+	}
 
 	// Mark as edited
 	update["$set"].(bson.M)["is_edited"] = true
 	update["$set"].(bson.M)["edited_at"] = time.Now()
 
+	// Editing in a blocklisted link is screened like posting one
+	var blockedLink *models.LinkBlockMatch
+	if req.Content != nil {
+		blockedLink, err = cs.linkBlocklist.Screen(ctx, userID, models.LinkBlockContentComment, *req.Content)
+		if err != nil {
+			return nil, err
+		}
+	}
+	hiddenByEdit := blockedLink != nil && !comment.IsHidden
+	if blockedLink != nil {
+		update["$set"].(bson.M)["is_hidden"] = true
+	}
+
+	// Editing links into an approved comment goes through the same hold as posting them
+	heldByEdit := false
+	if blockedLink == nil && req.Content != nil && !comment.IsHeld() && utils.ContainsLink(*req.Content) {
+		var author models.User
+		if err := cs.userCollection.FindOne(ctx, bson.M{"_id": userID}).Decode(&author); err != nil {
+			return nil, err
+		}
+		if !cs.isTrustedCommenter(ctx, &author) {
+			comment.Hold()
+			update["$set"].(bson.M)["is_approved"] = false
+			update["$set"].(bson.M)["hold_status"] = comment.HoldStatus
+			update["$set"].(bson.M)["held_at"] = comment.HeldAt
+			heldByEdit = true
+		}
+	}
+
 	_, err = cs.collection.UpdateOne(ctx, bson.M{"_id": commentID}, update)
 	if err != nil {
 		return nil, err
 	}
+	repository.RecordWrite(userID)
+
+	if heldByEdit {
+		cs.updateCommentCounts(ctx, comment, -1)
+		cs.queueHeldComment(ctx, comment)
+	}
+	if blockedLink != nil {
+		// Held and unapproved comments were never counted
+		if hiddenByEdit && comment.IsApproved && !comment.IsHeld() {
+			cs.updateCommentCounts(ctx, comment, -1)
+		}
+		cs.linkBlocklist.ReportHidden(ctx, userID, models.LinkBlockContentComment, commentID, blockedLink)
+	}
+
+	// Cached translations no longer match the edited content
+	if req.Content != nil {
+		invalidateTranslations(ctx, cs.db, "comment", commentID)
+	}
+
+	// Hidden comments aren't viewable, even by their author, so the edited
+	// comment is read back directly
+	if blockedLink != nil {
+		var hidden models.Comment
+		if err := cs.collection.FindOne(ctx, bson.M{"_id": commentID}).Decode(&hidden); err != nil {
+			return nil, err
+		}
+		cs.populateCommentAuthor(&hidden)
+		return &hidden, nil
+	}
 
 	return cs.GetCommentByID(commentID, &userID)
 }
 
 // DeleteComment soft deletes a comment
-func (cs *CommentService) DeleteComment(commentID, userID primitive.ObjectID) error {
+func (cs *CommentService) DeleteComment(commentID, userID primitive.ObjectID, delegateID *primitive.ObjectID) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -340,10 +721,7 @@ func (cs *CommentService) DeleteComment(commentID, userID primitive.ObjectID) er
 
 	// Check if comment exists
 	var comment models.Comment
-	err = cs.collection.FindOne(ctx, bson.M{
-		"_id":        commentID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&comment)
+	err = cs.collection.FindOne(ctx, repository.ByID(commentID)).Decode(&comment)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -364,12 +742,12 @@ func (cs *CommentService) DeleteComment(commentID, userID primitive.ObjectID) er
 	// Soft delete the comment
 	now := time.Now()
 	update := bson.M{
-		"$set": bson.M{
+		"$set": moderationSet(bson.M{
 			"deleted_at":  now,
 			"updated_at":  now,
 			"is_hidden":   true,
 			"is_approved": false,
-		},
+		}, delegateID),
 	}
 
 	_, err = cs.collection.UpdateOne(ctx, bson.M{"_id": commentID}, update)
@@ -377,37 +755,106 @@ func (cs *CommentService) DeleteComment(commentID, userID primitive.ObjectID) er
 		return err
 	}
 
-	// Update post comments count
-	cs.postCollection.UpdateOne(ctx, bson.M{"_id": comment.PostID}, bson.M{
-		"$inc": bson.M{"comments_count": -1},
-	})
-
-	// Update parent comment replies count if this is a reply
-	if comment.ParentCommentID != nil {
-		cs.collection.UpdateOne(ctx, bson.M{"_id": comment.ParentCommentID}, bson.M{
-			"$inc": bson.M{"replies_count": -1},
-		})
+	// Held comments were never counted; they just leave the review queue
+	if comment.IsHeld() {
+		cs.closeHoldReports(ctx, commentID, models.ReportResolved, "comment_deleted", "", nil)
+		return nil
 	}
 
-	// Update user's comments count
-	go cs.updateUserCommentsCount(comment.UserID, false)
+	cs.updateCommentCounts(ctx, &comment, -1)
 
 	return nil
 }
 
+// PreviewBulkDeleteMyComments counts the user's comments a bulk delete
+// filter matches. The count is what BulkDeleteMyComments must be given to
+// confirm.
+func (cs *CommentService) PreviewBulkDeleteMyComments(userID primitive.ObjectID, filter models.BulkDeleteFilter) (*models.BulkDeletePreview, error) {
+	return cs.bulkDeletes.preview(userID, filter)
+}
+
+// BulkDeleteMyComments soft deletes the user's comments matching the filter.
+// The returned job is already completed for small deletions; larger ones run
+// in the background.
+func (cs *CommentService) BulkDeleteMyComments(userID primitive.ObjectID, req models.BulkDeleteRequest) (*models.BulkDeleteJob, error) {
+	return cs.bulkDeletes.start(userID, req)
+}
+
+// GetBulkDeleteJob retrieves one of the user's bulk comment deletions
+func (cs *CommentService) GetBulkDeleteJob(userID, jobID primitive.ObjectID) (*models.BulkDeleteJob, error) {
+	return cs.bulkDeletes.job(userID, jobID)
+}
+
+// ResumeBulkDeleteJobs restarts the bulk comment deletions a previous
+// process left unfinished
+func (cs *CommentService) ResumeBulkDeleteJobs() {
+	cs.bulkDeletes.resume()
+}
+
+// deleteCommentBatch soft deletes up to limit comments matching the filter
+// the way DeleteComment does, taking them off their author's comment count
+// in one update
+func (cs *CommentService) deleteCommentBatch(ctx context.Context, filter bson.M, limit int64) (int64, error) {
+	cursor, err := cs.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(limit))
+	if err != nil {
+		return 0, err
+	}
+	var comments []models.Comment
+	if err := cursor.All(ctx, &comments); err != nil {
+		return 0, err
+	}
+
+	countedByUser := make(map[primitive.ObjectID]int64)
+	var deleted int64
+	var deleteErr error
+	for i := range comments {
+		comment := &comments[i]
+		now := time.Now()
+		if _, deleteErr = cs.collection.UpdateOne(ctx, bson.M{"_id": comment.ID}, bson.M{"$set": bson.M{
+			"deleted_at":  now,
+			"updated_at":  now,
+			"is_hidden":   true,
+			"is_approved": false,
+		}}); deleteErr != nil {
+			break
+		}
+		deleted++
+
+		// Held comments were never counted; they just leave the review queue
+		if comment.IsHeld() {
+			cs.closeHoldReports(ctx, comment.ID, models.ReportResolved, "comment_deleted", "", nil)
+			continue
+		}
+		cs.updateCommentThreadCounts(ctx, comment, -1)
+		countedByUser[comment.UserID]++
+	}
+
+	for userID, count := range countedByUser {
+		cs.userCollection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+			"$inc": bson.M{"comments_count": -count},
+			"$set": bson.M{"updated_at": time.Now()},
+		})
+	}
+
+	return deleted, deleteErr
+}
+
 // LikeComment adds or updates a like on a comment
 func (cs *CommentService) LikeComment(commentID, userID primitive.ObjectID, reactionType models.ReactionType) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	if err := ensureReactionAvailable(ctx, cs.db, reactionType); err != nil {
+		return err
+	}
+
 	// Check if comment exists
 	var comment models.Comment
-	err := cs.collection.FindOne(ctx, bson.M{
+	err := cs.collection.FindOne(ctx, repository.NotDeleted(bson.M{
 		"_id":         commentID,
-		"deleted_at":  bson.M{"$exists": false},
 		"is_hidden":   false,
 		"is_approved": true,
-	}).Decode(&comment)
+	})).Decode(&comment)
 
 	if err != nil {
 		return err
@@ -430,6 +877,9 @@ func (cs *CommentService) LikeComment(commentID, userID primitive.ObjectID, reac
 			},
 		}
 		_, err = cs.likeCollection.UpdateOne(ctx, bson.M{"_id": existingLike.ID}, update)
+		if err == nil {
+			adjustReactionCounts(ctx, cs.collection, commentID, existingLike.ReactionType, reactionType)
+		}
 	} else if err == mongo.ErrNoDocuments {
 		// Create new like
 		like := &models.Like{
@@ -445,9 +895,9 @@ func (cs *CommentService) LikeComment(commentID, userID primitive.ObjectID, reac
 			return err
 		}
 
-		// Increment comment like count
+		// Increment comment like and reaction counts
 		cs.collection.UpdateOne(ctx, bson.M{"_id": commentID}, bson.M{
-			"$inc": bson.M{"likes_count": 1},
+			"$inc": bson.M{"likes_count": 1, "reaction_counts." + string(like.ReactionType): 1},
 		})
 
 		// Update comment quality score
@@ -463,20 +913,21 @@ func (cs *CommentService) UnlikeComment(commentID, userID primitive.ObjectID) er
 	defer cancel()
 
 	// Find and delete the like
-	result, err := cs.likeCollection.DeleteOne(ctx, bson.M{
+	var like models.Like
+	err := cs.likeCollection.FindOneAndDelete(ctx, bson.M{
 		"user_id":     userID,
 		"target_id":   commentID,
 		"target_type": "comment",
-	})
+	}).Decode(&like)
 
-	if err != nil {
+	if err != nil && err != mongo.ErrNoDocuments {
 		return err
 	}
 
-	if result.DeletedCount > 0 {
-		// Decrement comment like count
+	if err == nil {
+		// Decrement comment like and reaction counts
 		cs.collection.UpdateOne(ctx, bson.M{"_id": commentID}, bson.M{
-			"$inc": bson.M{"likes_count": -1},
+			"$inc": bson.M{"likes_count": -1, "reaction_counts." + string(like.ReactionType): -1},
 		})
 
 		// Update comment quality score
@@ -541,10 +992,7 @@ func (cs *CommentService) ReportComment(commentID, reporterID primitive.ObjectID
 
 	// Check if comment exists
 	var comment models.Comment
-	err := cs.collection.FindOne(ctx, bson.M{
-		"_id":        commentID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&comment)
+	err := cs.collection.FindOne(ctx, repository.ByID(commentID)).Decode(&comment)
 
 	if err != nil {
 		return err
@@ -576,16 +1024,13 @@ func (cs *CommentService) ReportComment(commentID, reporterID primitive.ObjectID
 }
 
 // PinComment pins a comment (post author only)
-func (cs *CommentService) PinComment(commentID, userID primitive.ObjectID) error {
+func (cs *CommentService) PinComment(commentID, userID primitive.ObjectID, delegateID *primitive.ObjectID) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	// Get comment and check if user is post author
 	var comment models.Comment
-	err := cs.collection.FindOne(ctx, bson.M{
-		"_id":        commentID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&comment)
+	err := cs.collection.FindOne(ctx, repository.ByID(commentID)).Decode(&comment)
 
 	if err != nil {
 		return err
@@ -604,26 +1049,23 @@ func (cs *CommentService) PinComment(commentID, userID primitive.ObjectID) error
 
 	// Pin the comment
 	_, err = cs.collection.UpdateOne(ctx, bson.M{"_id": commentID}, bson.M{
-		"$set": bson.M{
+		"$set": moderationSet(bson.M{
 			"is_pinned":  true,
 			"updated_at": time.Now(),
-		},
+		}, delegateID),
 	})
 
 	return err
 }
 
 // UnpinComment unpins a comment (post author only)
-func (cs *CommentService) UnpinComment(commentID, userID primitive.ObjectID) error {
+func (cs *CommentService) UnpinComment(commentID, userID primitive.ObjectID, delegateID *primitive.ObjectID) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	// Get comment and check if user is post author
 	var comment models.Comment
-	err := cs.collection.FindOne(ctx, bson.M{
-		"_id":        commentID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&comment)
+	err := cs.collection.FindOne(ctx, repository.ByID(commentID)).Decode(&comment)
 
 	if err != nil {
 		return err
@@ -642,26 +1084,33 @@ func (cs *CommentService) UnpinComment(commentID, userID primitive.ObjectID) err
 
 	// Unpin the comment
 	_, err = cs.collection.UpdateOne(ctx, bson.M{"_id": commentID}, bson.M{
-		"$set": bson.M{
+		"$set": moderationSet(bson.M{
 			"is_pinned":  false,
 			"updated_at": time.Now(),
-		},
+		}, delegateID),
 	})
 
 	return err
 }
 
+// moderationSet adds the delegate behind a moderation action, if any, to its
+// $set
+func moderationSet(set bson.M, delegateID *primitive.ObjectID) bson.M {
+	if delegateID != nil {
+		set["moderated_by_delegate"] = *delegateID
+	}
+	return set
+}
+
 // GetUserComments retrieves comments made by a specific user
 func (cs *CommentService) GetUserComments(userID primitive.ObjectID, currentUserID *primitive.ObjectID, limit, skip int) ([]models.Comment, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	filter := bson.M{
-		"user_id":     userID,
-		"deleted_at":  bson.M{"$exists": false},
-		"is_hidden":   false,
-		"is_approved": true,
-	}
+	filter := visibleCommentsFilter(repository.NotDeleted(bson.M{
+		"user_id":   userID,
+		"is_hidden": false,
+	}), currentUserID)
 
 	// If not viewing own comments, only show public comments
 	if currentUserID == nil || *currentUserID != userID {
@@ -710,31 +1159,28 @@ func (cs *CommentService) GetCommentThread(commentID primitive.ObjectID, current
 	}
 
 	// Get all comments in the same thread
-	filter := bson.M{
+	filter := repository.NotDeleted(bson.M{
 		"$or": []bson.M{
 			{"_id": commentID},
 			{"root_comment_id": rootComment.ID},
 		},
-		"deleted_at":  bson.M{"$exists": false},
-		"is_hidden":   false,
-		"is_approved": true,
-	}
+		"is_hidden": false,
+	})
 
 	// If this is already a reply, get the root and all its replies
 	if rootComment.RootCommentID != nil {
-		filter = bson.M{
+		filter = repository.NotDeleted(bson.M{
 			"$or": []bson.M{
 				{"_id": *rootComment.RootCommentID},
 				{"root_comment_id": *rootComment.RootCommentID},
 			},
-			"deleted_at":  bson.M{"$exists": false},
-			"is_hidden":   false,
-			"is_approved": true,
-		}
+			"is_hidden": false,
+		})
 	}
+	filter = visibleCommentsFilter(filter, currentUserID)
 
 	opts := options.Find().
-		SetSort(bson.M{"level": 1, "created_at": 1})
+		SetSort(bson.D{{Key: "level", Value: 1}, {Key: "created_at", Value: 1}})
 
 	cursor, err := cs.collection.Find(ctx, filter, opts)
 	if err != nil {
@@ -755,6 +1201,109 @@ func (cs *CommentService) GetCommentThread(commentID primitive.ObjectID, current
 	return comments, nil
 }
 
+// GetHeldComments retrieves comments waiting in the spam hold, oldest first
+func (cs *CommentService) GetHeldComments(limit, skip int) ([]models.Comment, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := repository.NotDeleted(bson.M{
+		"hold_status": models.CommentHoldHeld,
+	})
+
+	total, err := cs.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSkip(int64(skip)).
+		SetSort(bson.M{"held_at": 1})
+
+	cursor, err := cs.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var comments []models.Comment
+	if err := cursor.All(ctx, &comments); err != nil {
+		return nil, 0, err
+	}
+
+	for i := range comments {
+		cs.populateCommentAuthor(&comments[i])
+	}
+
+	return comments, total, nil
+}
+
+// ApproveHeldComment releases a held comment as if it had been posted
+// normally at its original time
+func (cs *CommentService) ApproveHeldComment(commentID, moderatorID primitive.ObjectID) (*models.Comment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	comment, err := cs.getHeldComment(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cs.releaseHeldComment(ctx, comment, &moderatorID); err != nil {
+		return nil, err
+	}
+
+	// The approval may take the author past the trust threshold
+	go cs.releaseTrustedHeldComments(comment.UserID)
+
+	return cs.GetCommentByID(commentID, nil)
+}
+
+// RejectHeldComment removes a held comment and gives its author a strike;
+// reaching the strike limit restricts the account from commenting
+func (cs *CommentService) RejectHeldComment(commentID, moderatorID primitive.ObjectID, reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	comment, err := cs.getHeldComment(ctx, commentID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	result, err := cs.collection.UpdateOne(ctx, bson.M{
+		"_id":         commentID,
+		"hold_status": models.CommentHoldHeld,
+	}, bson.M{
+		"$set": bson.M{
+			"hold_status":      models.CommentHoldRejected,
+			"hold_reviewed_by": moderatorID,
+			"hold_reviewed_at": now,
+			"is_hidden":        true,
+			"deleted_at":       now,
+			"updated_at":       now,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 {
+		return errors.New("comment is not held")
+	}
+
+	cs.closeHoldReports(ctx, commentID, models.ReportResolved, "comment_removed", reason, &moderatorID)
+
+	return cs.addCommentStrike(ctx, comment.UserID)
+}
+
+// GetCommentHoldStats counts comments by spam hold outcome
+func (cs *CommentService) GetCommentHoldStats() (*models.CommentHoldStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return getCommentHoldStats(ctx, cs.db)
+}
+
 // Helper methods
 
 func (cs *CommentService) populateCommentAuthor(comment *models.Comment) error {
@@ -771,6 +1320,464 @@ func (cs *CommentService) populateCommentAuthor(comment *models.Comment) error {
 	return nil
 }
 
+// visibleCommentsFilter limits a comment query to what the viewer may see:
+// approved comments, plus the viewer's own held comments
+func visibleCommentsFilter(filter bson.M, viewerID *primitive.ObjectID) bson.M {
+	if viewerID == nil {
+		filter["is_approved"] = true
+		return filter
+	}
+
+	filter["$and"] = []bson.M{{
+		"$or": []bson.M{
+			{"is_approved": true},
+			{"user_id": *viewerID, "hold_status": models.CommentHoldHeld},
+		},
+	}}
+	return filter
+}
+
+// visibleRepliesFilter matches the replies to the given comments that the
+// viewer may see, leaving out deleted and hidden ones
+func visibleRepliesFilter(parentIDs []primitive.ObjectID, viewerID *primitive.ObjectID) bson.M {
+	return visibleCommentsFilter(repository.NotDeleted(bson.M{
+		"parent_comment_id": bson.M{"$in": parentIDs},
+		"is_hidden":         false,
+	}), viewerID)
+}
+
+// attachReplyPreviews sets each comment's reply count and its first replies,
+// with a cursor to load the rest when there are more
+func (cs *CommentService) attachReplyPreviews(ctx context.Context, comments []models.Comment, viewerID *primitive.ObjectID) error {
+	if err := cs.countVisibleReplies(ctx, comments, viewerID); err != nil {
+		return err
+	}
+	if cs.replyPreview <= 0 {
+		return nil
+	}
+
+	var previewed []models.Comment
+	for i := range comments {
+		if comments[i].RepliesCount == 0 {
+			continue
+		}
+
+		replies, nextCursor, err := cs.findReplies(ctx, comments[i].ID, viewerID, "", cs.replyPreview)
+		if err != nil {
+			return err
+		}
+		comments[i].Replies = replies
+		comments[i].RepliesCursor = nextCursor
+		previewed = append(previewed, replies...)
+	}
+
+	// The previewed replies carry counts of their own nested replies
+	if err := cs.countVisibleReplies(ctx, previewed, viewerID); err != nil {
+		return err
+	}
+	offset := 0
+	for i := range comments {
+		for j := range comments[i].Replies {
+			comments[i].Replies[j].RepliesCount = previewed[offset].RepliesCount
+			offset++
+		}
+	}
+
+	return nil
+}
+
+// countVisibleReplies sets each comment's RepliesCount to the replies the
+// viewer can see. The stored replies_count isn't used because comments
+// hidden by moderators stay in it.
+func (cs *CommentService) countVisibleReplies(ctx context.Context, comments []models.Comment, viewerID *primitive.ObjectID) error {
+	if len(comments) == 0 {
+		return nil
+	}
+
+	ids := make([]primitive.ObjectID, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.ID
+	}
+
+	cursor, err := cs.collection.Aggregate(ctx, []bson.M{
+		{"$match": visibleRepliesFilter(ids, viewerID)},
+		{"$group": bson.M{"_id": "$parent_comment_id", "count": bson.M{"$sum": 1}}},
+	})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var counts []struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Count int64              `bson:"count"`
+	}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return err
+	}
+
+	byParent := make(map[primitive.ObjectID]int64, len(counts))
+	for _, count := range counts {
+		byParent[count.ID] = count.Count
+	}
+	for i := range comments {
+		comments[i].RepliesCount = byParent[comments[i].ID]
+	}
+	return nil
+}
+
+// findReplies returns a page of replies to a comment, oldest first, starting
+// after cursor, and the cursor of the next page if there is one
+func (cs *CommentService) findReplies(ctx context.Context, parentID primitive.ObjectID, viewerID *primitive.ObjectID, cursor string, limit int) ([]models.Comment, string, error) {
+	filter := visibleRepliesFilter([]primitive.ObjectID{parentID}, viewerID)
+	if cursor != "" {
+		createdAt, id, err := decodeTimeCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		and, _ := filter["$and"].([]bson.M)
+		filter["$and"] = append(and, bson.M{"$or": []bson.M{
+			{"created_at": bson.M{"$gt": createdAt}},
+			{"created_at": createdAt, "_id": bson.M{"$gt": id}},
+		}})
+	}
+
+	// One extra reply tells whether there is a next page
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit + 1))
+
+	results, err := cs.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	defer results.Close(ctx)
+
+	var replies []models.Comment
+	if err := results.All(ctx, &replies); err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(replies) > limit {
+		replies = replies[:limit]
+		last := replies[len(replies)-1]
+		nextCursor = encodeTimeCursor(last.CreatedAt, last.ID)
+	}
+
+	for i := range replies {
+		cs.populateCommentAuthor(&replies[i])
+	}
+
+	return replies, nextCursor, nil
+}
+
+// isTrustedCommenter checks if a user's links skip the spam hold
+func (cs *CommentService) isTrustedCommenter(ctx context.Context, user *models.User) bool {
+	if user.IsVerified || user.Role == models.RoleModerator || user.Role == models.RoleAdmin || user.Role == models.RoleSuperAdmin {
+		return true
+	}
+
+	// Accounts that have had spam rejected stay under review
+	if user.CommentStrikes > 0 {
+		return false
+	}
+
+	if time.Since(user.CreatedAt) < cs.holdPolicy.MinAccountAge {
+		return false
+	}
+
+	if cs.holdPolicy.MinApprovedComments <= 0 {
+		return true
+	}
+
+	approved, err := cs.collection.CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"user_id":     user.ID,
+		"is_approved": true,
+	}), options.Count().SetLimit(cs.holdPolicy.MinApprovedComments))
+
+	return err == nil && approved >= cs.holdPolicy.MinApprovedComments
+}
+
+// updateCommentCounts applies a comment appearing (delta 1) or disappearing
+// (delta -1) to the post, parent comment and author counters. Quick replies
+// count towards the post's quick reaction summary instead of comments_count.
+func (cs *CommentService) updateCommentCounts(ctx context.Context, comment *models.Comment, delta int) {
+	cs.updateCommentThreadCounts(ctx, comment, delta)
+	go cs.updateUserCommentsCount(comment.UserID, delta > 0)
+}
+
+// updateCommentThreadCounts adjusts the counts a comment adds to its post
+// and parent comment
+func (cs *CommentService) updateCommentThreadCounts(ctx context.Context, comment *models.Comment, delta int) {
+	if comment.IsQuickReply() {
+		cs.postCollection.UpdateOne(ctx, bson.M{"_id": comment.PostID}, bson.M{
+			"$inc": quickReplyCountsUpdate(comment.Content, delta),
+		})
+	} else {
+		cs.postCollection.UpdateOne(ctx, bson.M{"_id": comment.PostID}, bson.M{
+			"$inc": bson.M{"comments_count": delta},
+		})
+	}
+
+	if comment.ParentCommentID != nil {
+		cs.collection.UpdateOne(ctx, bson.M{"_id": comment.ParentCommentID}, bson.M{
+			"$inc": bson.M{"replies_count": delta},
+		})
+	}
+}
+
+// quickReplyCountsUpdate builds the $inc for a quick reply's emoji. Each
+// distinct emoji counts once per reply, so "❤️❤️❤️" is one heart.
+func quickReplyCountsUpdate(content string, delta int) bson.M {
+	inc := bson.M{"quick_replies_count": delta}
+
+	emoji, _ := utils.SplitEmoji(content)
+	for _, e := range emoji {
+		inc["quick_reaction_counts."+e] = delta
+	}
+
+	return inc
+}
+
+// getQuickReplySenders returns the users who sent the most quick replies to a post
+func getQuickReplySenders(ctx context.Context, db *mongo.Database, postID primitive.ObjectID, limit int) ([]models.QuickReactionSender, error) {
+	pipeline := []bson.M{
+		{"$match": repository.NotDeleted(bson.M{
+			"post_id":     postID,
+			"kind":        models.CommentKindQuick,
+			"is_approved": true,
+		})},
+		{"$group": bson.M{"_id": "$user_id", "count": bson.M{"$sum": 1}, "last": bson.M{"$max": "$created_at"}}},
+		{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "last", Value: -1}}},
+		{"$limit": limit},
+	}
+
+	cursor, err := db.Collection("comments").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		UserID primitive.ObjectID `bson:"_id"`
+		Count  int64              `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	senders := make([]models.QuickReactionSender, 0, len(rows))
+	for _, row := range rows {
+		var user models.User
+		if err := db.Collection("users").FindOne(ctx, bson.M{"_id": row.UserID}).Decode(&user); err != nil {
+			continue
+		}
+		senders = append(senders, models.QuickReactionSender{
+			User:  user.ToUserResponse(),
+			Count: row.Count,
+		})
+	}
+
+	return senders, nil
+}
+
+// queueHeldComment files a held comment in the moderation review queue
+func (cs *CommentService) queueHeldComment(ctx context.Context, comment *models.Comment) {
+	report := &models.Report{
+		TargetType:  "comment",
+		TargetID:    comment.ID,
+		Reason:      models.ReportSpam,
+		Description: "Comment with links from a new account held for review",
+		Category:    "comment_hold",
+		Source:      "auto",
+	}
+	report.BeforeCreate()
+	report.AutoDetected = true
+
+	if _, err := cs.db.Collection("reports").InsertOne(ctx, report); err != nil {
+		log.Printf("Failed to queue held comment %s for review: %v", comment.ID.Hex(), err)
+	}
+}
+
+// closeHoldReports resolves the review queue entries for a held comment;
+// reviewerID is nil when the comment was released automatically
+func (cs *CommentService) closeHoldReports(ctx context.Context, commentID primitive.ObjectID, status models.ReportStatus, resolution, note string, reviewerID *primitive.ObjectID) {
+	now := time.Now()
+	set := bson.M{
+		"status":          status,
+		"resolution":      resolution,
+		"resolution_note": note,
+		"content_removed": resolution == "comment_removed",
+		"resolved_at":     now,
+		"updated_at":      now,
+	}
+	if reviewerID != nil {
+		set["resolved_by"] = *reviewerID
+	}
+
+	cs.db.Collection("reports").UpdateMany(ctx, bson.M{
+		"target_type":   "comment",
+		"target_id":     commentID,
+		"auto_detected": true,
+		"status":        bson.M{"$in": []models.ReportStatus{models.ReportPending, models.ReportReviewing}},
+	}, bson.M{"$set": set})
+}
+
+func (cs *CommentService) getHeldComment(ctx context.Context, commentID primitive.ObjectID) (*models.Comment, error) {
+	var comment models.Comment
+	err := cs.collection.FindOne(ctx, repository.NotDeleted(bson.M{
+		"_id":         commentID,
+		"hold_status": models.CommentHoldHeld,
+	})).Decode(&comment)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("held comment not found")
+		}
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+// releaseHeldComment approves a held comment and applies the counts and
+// notifications that were deferred while it was held
+func (cs *CommentService) releaseHeldComment(ctx context.Context, comment *models.Comment, reviewerID *primitive.ObjectID) error {
+	now := time.Now()
+	set := bson.M{
+		"is_approved":      true,
+		"hold_status":      models.CommentHoldApproved,
+		"hold_reviewed_at": now,
+		"updated_at":       now,
+	}
+	if reviewerID != nil {
+		set["hold_reviewed_by"] = *reviewerID
+	}
+
+	result, err := cs.collection.UpdateOne(ctx, repository.NotDeleted(bson.M{
+		"_id":         comment.ID,
+		"hold_status": models.CommentHoldHeld,
+	}), bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 {
+		return errors.New("comment is not held")
+	}
+
+	cs.updateCommentCounts(ctx, comment, 1)
+	cs.closeHoldReports(ctx, comment.ID, models.ReportRejected, "comment_approved", "", reviewerID)
+
+	if cs.notificationService != nil {
+		var post models.Post
+		if err := cs.postCollection.FindOne(ctx, bson.M{"_id": comment.PostID}).Decode(&post); err == nil {
+			go cs.notificationService.NotifyComment(comment.UserID, post.UserID, comment.PostID, comment.ID)
+		}
+	}
+
+	if len(comment.Mentions) > 0 {
+		go cs.createMentionNotifications(comment.UserID, comment.ID, comment.Mentions)
+	}
+
+	return nil
+}
+
+// releaseTrustedHeldComments releases a user's held comments once they have
+// crossed the trust threshold
+func (cs *CommentService) releaseTrustedHeldComments(userID primitive.ObjectID) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	filter := repository.NotDeleted(bson.M{
+		"user_id":     userID,
+		"hold_status": models.CommentHoldHeld,
+	})
+
+	if count, err := cs.collection.CountDocuments(ctx, filter, options.Count().SetLimit(1)); err != nil || count == 0 {
+		return
+	}
+
+	var author models.User
+	if err := cs.userCollection.FindOne(ctx, bson.M{"_id": userID}).Decode(&author); err != nil {
+		return
+	}
+	if !cs.isTrustedCommenter(ctx, &author) {
+		return
+	}
+
+	cursor, err := cs.collection.Find(ctx, filter)
+	if err != nil {
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var held []models.Comment
+	if err := cursor.All(ctx, &held); err != nil {
+		return
+	}
+
+	for i := range held {
+		if err := cs.releaseHeldComment(ctx, &held[i], nil); err != nil {
+			log.Printf("Failed to release held comment %s: %v", held[i].ID.Hex(), err)
+		}
+	}
+}
+
+// addCommentStrike records a rejected hold against a user and restricts the
+// account once the strike limit is reached
+func (cs *CommentService) addCommentStrike(ctx context.Context, userID primitive.ObjectID) error {
+	var user models.User
+	err := cs.userCollection.FindOneAndUpdate(ctx, bson.M{"_id": userID}, bson.M{
+		"$inc": bson.M{"comment_strikes": 1},
+		"$set": bson.M{"updated_at": time.Now()},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&user)
+	if err != nil {
+		return err
+	}
+
+	if cs.holdPolicy.StrikeLimit <= 0 || user.CommentStrikes < cs.holdPolicy.StrikeLimit || user.Status == models.UserStatusRestricted {
+		return nil
+	}
+
+	_, err = cs.userCollection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+		"$set": bson.M{
+			"status":     models.UserStatusRestricted,
+			"updated_at": time.Now(),
+		},
+	})
+	if err == nil {
+		log.Printf("Restricted user %s after %d rejected comments", userID.Hex(), user.CommentStrikes)
+	}
+	return err
+}
+
+// getCommentHoldStats counts comments by spam hold outcome
+func getCommentHoldStats(ctx context.Context, db *mongo.Database) (*models.CommentHoldStats, error) {
+	comments := db.Collection("comments")
+	stats := &models.CommentHoldStats{}
+
+	counts := []struct {
+		target *int64
+		filter bson.M
+	}{
+		{&stats.Held, repository.NotDeleted(bson.M{"hold_status": models.CommentHoldHeld})},
+		{&stats.Approved, bson.M{"hold_status": models.CommentHoldApproved}},
+		{&stats.AutoReleased, bson.M{"hold_status": models.CommentHoldApproved, "hold_reviewed_by": bson.M{"$exists": false}}},
+		{&stats.Rejected, bson.M{"hold_status": models.CommentHoldRejected}},
+	}
+
+	for _, count := range counts {
+		n, err := comments.CountDocuments(ctx, count.filter)
+		if err != nil {
+			return nil, err
+		}
+		*count.target = n
+	}
+
+	return stats, nil
+}
+
 func (cs *CommentService) updateUserCommentsCount(userID primitive.ObjectID, increment bool) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -842,10 +1849,7 @@ func (cs *CommentService) VoteComment(commentID, userID primitive.ObjectID, vote
 
 	// Check if comment exists
 	var comment models.Comment
-	err := cs.collection.FindOne(ctx, bson.M{
-		"_id":        commentID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&comment)
+	err := cs.collection.FindOne(ctx, repository.ByID(commentID)).Decode(&comment)
 
 	if err != nil {
 		return err
@@ -0,0 +1,382 @@
+// internal/services/content_calendar_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// contentCalendarMaxItems caps the items loaded per state. Day totals stay
+// exact beyond it, so crowded days report has_more and are paged by day.
+const contentCalendarMaxItems = 2000
+
+const calendarDayLayout = "2006-01-02"
+
+// ContentCalendarService builds a creator's planning view over their
+// published, scheduled and pending posts
+type ContentCalendarService struct {
+	db             *mongo.Database
+	userCollection *mongo.Collection
+}
+
+func NewContentCalendarService(db *mongo.Database) *ContentCalendarService {
+	return &ContentCalendarService{
+		db:             db,
+		userCollection: db.Collection("users"),
+	}
+}
+
+// calendarSource describes one state's indexed query on the calendar
+type calendarSource struct {
+	collection string
+	filter     bson.M
+	dateField  string
+	projection bson.M
+	build      func(cursor *mongo.Cursor) (models.ContentCalendarItem, error)
+}
+
+// GetContentCalendar returns the user's items in the requested range grouped
+// by day in their stored timezone, with per-state totals for the range
+func (s *ContentCalendarService) GetContentCalendar(userID primitive.ObjectID, query models.ContentCalendarQuery) (*models.ContentCalendarResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	var user models.User
+	err := s.userCollection.FindOne(ctx, bson.M{"_id": userID},
+		options.FindOne().SetProjection(bson.M{"timezone": 1})).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	loc := time.UTC
+	if user.Timezone != "" {
+		if userLoc, err := time.LoadLocation(user.Timezone); err == nil {
+			loc = userLoc
+		}
+	}
+
+	from, to, err := calendarRange(query, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	perDay := query.PerDay
+	if perDay < 1 || perDay > models.ContentCalendarMaxPerDay {
+		perDay = models.ContentCalendarDefaultPerDay
+	}
+	page := query.Page
+	if page < 1 || query.Day == "" {
+		page = 1
+	}
+
+	start := from
+	end := to.AddDate(0, 0, 1)
+	summary := map[models.ContentCalendarState]int64{
+		models.CalendarStatePublished:       0,
+		models.CalendarStateScheduled:       0,
+		models.CalendarStatePendingApproval: 0,
+	}
+	if query.IncludeHighlights {
+		summary[models.CalendarStateHighlight] = 0
+	}
+
+	dayTotals := map[string]int64{}
+	itemsByDay := map[string][]models.ContentCalendarItem{}
+	for state, source := range calendarSources(userID, query.IncludeHighlights) {
+		if err := s.countByDay(ctx, source, start, end, loc, dayTotals, func(count int64) {
+			summary[state] += count
+		}); err != nil {
+			return nil, err
+		}
+
+		items, err := s.collectItems(ctx, source, start, end)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			day := item.Date.In(loc).Format(calendarDayLayout)
+			itemsByDay[day] = append(itemsByDay[day], item)
+		}
+	}
+
+	days := make([]models.ContentCalendarDay, 0, len(dayTotals))
+	offset := (page - 1) * perDay
+	for day, total := range dayTotals {
+		items := itemsByDay[day]
+		sort.SliceStable(items, func(i, j int) bool {
+			if items[i].Date.Equal(items[j].Date) {
+				return items[i].ID < items[j].ID
+			}
+			return items[i].Date.Before(items[j].Date)
+		})
+
+		pageItems := []models.ContentCalendarItem{}
+		if offset < len(items) {
+			last := offset + perDay
+			if last > len(items) {
+				last = len(items)
+			}
+			pageItems = items[offset:last]
+		}
+
+		days = append(days, models.ContentCalendarDay{
+			Date:    day,
+			Items:   pageItems,
+			Total:   total,
+			Page:    page,
+			HasMore: int64(offset+perDay) < total,
+		})
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+
+	return &models.ContentCalendarResponse{
+		From:     from.Format(calendarDayLayout),
+		To:       to.Format(calendarDayLayout),
+		Timezone: loc.String(),
+		Summary:  summary,
+		Days:     days,
+	}, nil
+}
+
+// countByDay adds a source's item counts per local day to totals
+func (s *ContentCalendarService) countByDay(ctx context.Context, source calendarSource, start, end time.Time, loc *time.Location, totals map[string]int64, add func(int64)) error {
+	match := bson.M{source.dateField: bson.M{"$gte": start, "$lt": end}}
+	for key, value := range source.filter {
+		match[key] = value
+	}
+
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$group": bson.M{
+			"_id": bson.M{"$dateToString": bson.M{
+				"format":   "%Y-%m-%d",
+				"date":     "$" + source.dateField,
+				"timezone": loc.String(),
+			}},
+			"count": bson.M{"$sum": 1},
+		}},
+	}
+
+	cursor, err := s.db.Collection(source.collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+
+	var counts []struct {
+		Day   string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return err
+	}
+
+	for _, count := range counts {
+		totals[count.Day] += count.Count
+		add(count.Count)
+	}
+	return nil
+}
+
+// collectItems loads a source's items in the range, oldest first
+func (s *ContentCalendarService) collectItems(ctx context.Context, source calendarSource, start, end time.Time) ([]models.ContentCalendarItem, error) {
+	filter := bson.M{source.dateField: bson.M{"$gte": start, "$lt": end}}
+	for key, value := range source.filter {
+		filter[key] = value
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: source.dateField, Value: 1}}).
+		SetLimit(contentCalendarMaxItems).
+		SetProjection(source.projection)
+
+	cursor, err := s.db.Collection(source.collection).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var items []models.ContentCalendarItem
+	for cursor.Next(ctx) {
+		item, err := source.build(cursor)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, cursor.Err()
+}
+
+// calendarRange resolves the inclusive day range of a query in loc. Without
+// dates it covers the current month.
+func calendarRange(query models.ContentCalendarQuery, loc *time.Location) (time.Time, time.Time, error) {
+	if query.Day != "" {
+		day, err := time.ParseInLocation(calendarDayLayout, query.Day, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid day: expected YYYY-MM-DD")
+		}
+		return day, day, nil
+	}
+
+	now := time.Now().In(loc)
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	if query.From != "" {
+		parsed, err := time.ParseInLocation(calendarDayLayout, query.From, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid from date: expected YYYY-MM-DD")
+		}
+		from = parsed
+	}
+
+	to := time.Date(from.Year(), from.Month()+1, 0, 0, 0, 0, 0, loc)
+	if query.To != "" {
+		parsed, err := time.ParseInLocation(calendarDayLayout, query.To, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid to date: expected YYYY-MM-DD")
+		}
+		to = parsed
+	}
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, errors.New("invalid range: to is before from")
+	}
+	if !to.Before(from.AddDate(0, 0, models.ContentCalendarMaxDays)) {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid range: at most %d days can be requested", models.ContentCalendarMaxDays)
+	}
+	return from, to, nil
+}
+
+// calendarSources lists the indexed queries that make up a content calendar
+func calendarSources(userID primitive.ObjectID, includeHighlights bool) map[models.ContentCalendarState]calendarSource {
+	postProjection := bson.M{
+		"content":             1,
+		"media":               bson.M{"$slice": 1},
+		"group_id":            1,
+		"scheduled_for":       1,
+		"published_at":        1,
+		"created_at":          1,
+		"likes_count":         1,
+		"comments_count":      1,
+		"shares_count":        1,
+		"views_count":         1,
+		"group_review_status": 1,
+	}
+
+	buildPost := func(state models.ContentCalendarState, date func(post *models.Post) time.Time) func(cursor *mongo.Cursor) (models.ContentCalendarItem, error) {
+		return func(cursor *mongo.Cursor) (models.ContentCalendarItem, error) {
+			var post models.Post
+			if err := cursor.Decode(&post); err != nil {
+				return models.ContentCalendarItem{}, err
+			}
+
+			item := models.ContentCalendarItem{
+				ID:           post.ID.Hex(),
+				Kind:         "post",
+				State:        state,
+				Date:         date(&post),
+				ScheduledFor: post.ScheduledFor,
+				PublishedAt:  post.PublishedAt,
+				Preview:      activityPreview(post.Content),
+			}
+			if len(post.Media) > 0 {
+				item.Thumbnail = post.Media[0].Thumbnail
+				if item.Thumbnail == "" && post.Media[0].Type == "image" {
+					item.Thumbnail = post.Media[0].URL
+				}
+			}
+			if post.GroupID != nil {
+				item.GroupID = post.GroupID.Hex()
+			}
+			if state == models.CalendarStatePublished {
+				item.Engagement = &models.ContentCalendarEngagement{
+					Likes:    post.LikesCount,
+					Comments: post.CommentsCount,
+					Shares:   post.SharesCount,
+					Views:    post.ViewsCount,
+				}
+			}
+			return item, nil
+		}
+	}
+
+	sources := map[models.ContentCalendarState]calendarSource{
+		models.CalendarStatePublished: {
+			collection: "posts",
+			filter: repository.NotDeleted(bson.M{
+				"user_id":      userID,
+				"is_published": true,
+			}),
+			dateField:  "published_at",
+			projection: postProjection,
+			build: buildPost(models.CalendarStatePublished, func(post *models.Post) time.Time {
+				return *post.PublishedAt
+			}),
+		},
+		models.CalendarStateScheduled: {
+			collection: "posts",
+			filter: repository.NotDeleted(bson.M{
+				"user_id":             userID,
+				"is_scheduled":        true,
+				"is_published":        false,
+				"group_review_status": bson.M{"$ne": models.GroupPostPending},
+			}),
+			dateField:  "scheduled_for",
+			projection: postProjection,
+			build: buildPost(models.CalendarStateScheduled, func(post *models.Post) time.Time {
+				return *post.ScheduledFor
+			}),
+		},
+		models.CalendarStatePendingApproval: {
+			collection: "posts",
+			filter: repository.NotDeleted(bson.M{
+				"user_id":             userID,
+				"group_review_status": models.GroupPostPending,
+			}),
+			dateField:  "created_at",
+			projection: postProjection,
+			build: buildPost(models.CalendarStatePendingApproval, func(post *models.Post) time.Time {
+				return post.CreatedAt
+			}),
+		},
+	}
+
+	if includeHighlights {
+		sources[models.CalendarStateHighlight] = calendarSource{
+			collection: "story_highlights",
+			filter: repository.NotDeleted(bson.M{
+				"user_id": userID,
+			}),
+			dateField:  "created_at",
+			projection: bson.M{"title": 1, "cover_image": 1, "created_at": 1},
+			build: func(cursor *mongo.Cursor) (models.ContentCalendarItem, error) {
+				var highlight models.StoryHighlight
+				if err := cursor.Decode(&highlight); err != nil {
+					return models.ContentCalendarItem{}, err
+				}
+				return models.ContentCalendarItem{
+					ID:        highlight.ID.Hex(),
+					Kind:      "highlight",
+					State:     models.CalendarStateHighlight,
+					Date:      highlight.CreatedAt,
+					Preview:   highlight.Title,
+					Thumbnail: highlight.CoverImage,
+				}, nil
+			},
+		}
+	}
+
+	return sources
+}
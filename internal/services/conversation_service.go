@@ -3,11 +3,18 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"social-media-api/internal/config"
 	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+	"social-media-api/internal/utils"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -15,19 +22,47 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ConversationActivityPolicy decides how the most_active inbox sort measures
+// activity: messages sent within Window, with each conversation's count
+// cached for CacheTTL
+type ConversationActivityPolicy struct {
+	Window   time.Duration
+	CacheTTL time.Duration
+}
+
 type ConversationService struct {
 	conversationCollection *mongo.Collection
 	messageCollection      *mongo.Collection
 	userCollection         *mongo.Collection
 	db                     *mongo.Database
+	maxParticipants        int
+	presence               *PresenceService
+
+	strangerMaturity models.AccountMaturityPolicy // Held back from starting DMs with users who don't follow them
+
+	activityPolicy ConversationActivityPolicy
+	activityMu     sync.Mutex
+	activity       map[primitive.ObjectID]conversationActivity // Conversation ID → cached message count
 }
 
-func NewConversationService() *ConversationService {
+// conversationActivity is a conversation's cached message count over the
+// activity window
+type conversationActivity struct {
+	messages  int64
+	expiresAt time.Time
+}
+
+func NewConversationService(maxParticipants int, activity ConversationActivityPolicy, strangerMaturity models.AccountMaturityPolicy, presence *PresenceService) *ConversationService {
 	return &ConversationService{
 		conversationCollection: config.DB.Collection("conversations"),
 		messageCollection:      config.DB.Collection("messages"),
 		userCollection:         config.DB.Collection("users"),
 		db:                     config.DB,
+		maxParticipants:        maxParticipants,
+		presence:               presence,
+		strangerMaturity:       strangerMaturity,
+		activityPolicy:         activity,
+		activity:               make(map[primitive.ObjectID]conversationActivity),
 	}
 }
 
@@ -36,26 +71,40 @@ func (cs *ConversationService) CreateConversation(creatorID primitive.ObjectID,
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Convert participant IDs
-	var participants []primitive.ObjectID
+	if req.Type != "direct" && req.Type != "group" {
+		return nil, errors.New("invalid conversation type: must be direct or group")
+	}
+
+	// Convert participant IDs, ignoring duplicates
+	participants := []primitive.ObjectID{creatorID}
+	seen := map[primitive.ObjectID]bool{creatorID: true}
 	for _, participantIDStr := range req.ParticipantIDs {
 		participantID, err := primitive.ObjectIDFromHex(participantIDStr)
 		if err != nil {
 			return nil, errors.New("invalid participant ID: " + participantIDStr)
 		}
-		participants = append(participants, participantID)
+		if !seen[participantID] {
+			seen[participantID] = true
+			participants = append(participants, participantID)
+		}
 	}
 
-	// Ensure creator is in participants
-	found := false
-	for _, participantID := range participants {
-		if participantID == creatorID {
-			found = true
-			break
-		}
+	if req.Type == "direct" && len(participants) == 1 {
+		return nil, errors.New("cannot start a direct conversation with yourself")
+	}
+	if req.Type == "direct" && len(participants) != 2 {
+		return nil, errors.New("direct conversations must have exactly 2 participants")
+	}
+	if len(participants) < 2 {
+		return nil, errors.New("conversation requires at least one other participant")
+	}
+	if len(participants) > cs.maxParticipants {
+		return nil, fmt.Errorf("conversation cannot have more than %d participants", cs.maxParticipants)
 	}
-	if !found {
-		participants = append(participants, creatorID)
+
+	maxParticipants := req.MaxParticipants
+	if req.Type == "group" && (maxParticipants <= 0 || maxParticipants > int64(cs.maxParticipants)) {
+		maxParticipants = int64(cs.maxParticipants)
 	}
 
 	// For direct conversations (2 participants), check if conversation already exists
@@ -70,6 +119,26 @@ func (cs *ConversationService) CreateConversation(creatorID primitive.ObjectID,
 		return nil, err
 	}
 
+	for _, participantID := range participants {
+		if participantID != creatorID && isUserBlocked(ctx, cs.db, participantID, creatorID) {
+			return nil, errors.New("cannot send messages to this user")
+		}
+	}
+
+	blocked, err := hasBlockBetween(ctx, cs.db, participants, participants)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, errors.New("one or more participants have blocked each other")
+	}
+
+	if req.Type == "direct" && !cs.isFollowing(ctx, participants[1], creatorID) {
+		if err := checkAccountMaturity(ctx, cs.userCollection, cs.strangerMaturity, creatorID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Create conversation using model
 	conversation := &models.Conversation{
 		Type:              req.Type,
@@ -81,14 +150,24 @@ func (cs *ConversationService) CreateConversation(creatorID primitive.ObjectID,
 		IsPrivate:         req.IsPrivate,
 		AllowInvites:      req.AllowInvites,
 		AllowMediaSharing: req.AllowMediaSharing,
-		MaxParticipants:   req.MaxParticipants,
+		MaxParticipants:   maxParticipants,
 		Category:          req.Category,
 		Tags:              req.Tags,
 	}
+	if err := models.CheckBounds(conversation); err != nil {
+		return nil, err
+	}
 
 	// Use model's BeforeCreate method to set defaults
 	conversation.BeforeCreate()
 
+	// Participants who don't accept messages from the creator get it as a message request
+	for _, participantID := range participants {
+		if participantID != creatorID && !cs.canMessage(ctx, creatorID, participantID) {
+			conversation.SetRequestPending(participantID)
+		}
+	}
+
 	// Insert conversation
 	result, err := cs.conversationCollection.InsertOne(ctx, conversation)
 	if err != nil {
@@ -96,6 +175,7 @@ func (cs *ConversationService) CreateConversation(creatorID primitive.ObjectID,
 	}
 
 	conversation.ID = result.InsertedID.(primitive.ObjectID)
+	cs.presence.InvalidateConversation(conversation.ID, participants...)
 
 	// Populate participant information
 	cs.populateConversationUsers(ctx, conversation)
@@ -109,31 +189,14 @@ func (cs *ConversationService) CreateConversation(creatorID primitive.ObjectID,
 }
 
 // GetUserConversations retrieves conversations for a user
-func (cs *ConversationService) GetUserConversations(userID primitive.ObjectID, limit, skip int) ([]models.ConversationResponse, error) {
+func (cs *ConversationService) GetUserConversations(userID primitive.ObjectID, sortBy string, limit, skip int) ([]models.ConversationResponse, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	filter := bson.M{
-		"participants": userID,
-		"is_active":    true,
-		"deleted_at":   bson.M{"$exists": false},
-	}
-
-	opts := options.Find().
-		SetLimit(int64(limit)).
-		SetSkip(int64(skip)).
-		SetSort(bson.M{"last_activity_at": -1})
-
-	cursor, err := cs.conversationCollection.Find(ctx, filter, opts)
+	conversations, err := cs.findInboxConversations(ctx, userID, sortBy, limit, skip)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
-
-	var conversations []models.Conversation
-	if err := cursor.All(ctx, &conversations); err != nil {
-		return nil, err
-	}
 
 	// Convert to response format
 	var responses []models.ConversationResponse
@@ -150,6 +213,8 @@ func (cs *ConversationService) GetUserConversations(userID primitive.ObjectID, l
 		response.UserRole = conv.GetParticipantRole(userID)
 		response.CanSendMessages = conv.CanSendMessages(userID)
 		response.CanAddMembers = conv.CanAddMembers(userID)
+		response.PinnedAt = conv.GetPinnedAt(userID)
+		response.IsPinned = response.PinnedAt != nil
 
 		// Get typing users
 		response.TypingUsers = cs.getTypingUsers(ctx, conv.ID, userID)
@@ -162,16 +227,26 @@ func (cs *ConversationService) GetUserConversations(userID primitive.ObjectID, l
 
 // GetConversationByID retrieves a specific conversation
 func (cs *ConversationService) GetConversationByID(conversationID, userID primitive.ObjectID) (*models.ConversationResponse, error) {
+	conversation, err := cs.GetParticipantConversation(conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return cs.BuildConversationResponse(conversation, userID), nil
+}
+
+// GetParticipantConversation fetches an active conversation the user takes
+// part in, without populating participants
+func (cs *ConversationService) GetParticipantConversation(conversationID, userID primitive.ObjectID) (*models.Conversation, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	var conversation models.Conversation
-	err := cs.conversationCollection.FindOne(ctx, bson.M{
+	err := cs.conversationCollection.FindOne(ctx, repository.NotDeleted(bson.M{
 		"_id":          conversationID,
 		"participants": userID,
 		"is_active":    true,
-		"deleted_at":   bson.M{"$exists": false},
-	}).Decode(&conversation)
+	})).Decode(&conversation)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -180,8 +255,81 @@ func (cs *ConversationService) GetConversationByID(conversationID, userID primit
 		return nil, err
 	}
 
+	return &conversation, nil
+}
+
+// GetParticipantPresence lists the conversation's members with their presence.
+// Members still deciding on a message request are left out, and a viewer in
+// that state can't see the list.
+func (cs *ConversationService) GetParticipantPresence(conversationID, userID primitive.ObjectID) ([]models.ParticipantPresence, error) {
+	conversation, err := cs.GetParticipantConversation(conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.IsPendingRequest(userID) {
+		return nil, errors.New("conversation not found or access denied")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	roles := make(map[primitive.ObjectID]string, len(conversation.Participants))
+	var memberIDs []primitive.ObjectID
+	for _, participantID := range conversation.Participants {
+		if conversation.IsPendingRequest(participantID) {
+			continue
+		}
+		memberIDs = append(memberIDs, participantID)
+		roles[participantID] = "member"
+	}
+	for _, info := range conversation.ParticipantInfo {
+		if _, ok := roles[info.UserID]; ok && info.Role != "" {
+			roles[info.UserID] = info.Role
+		}
+	}
+
+	cursor, err := cs.userCollection.Find(ctx, repository.NotDeleted(bson.M{
+		"_id": bson.M{"$in": memberIDs},
+	}), options.Find().SetProjection(bson.M{
+		"password":       0,
+		"refresh_tokens": 0,
+		"reset_tokens":   0,
+	}).SetSort(bson.M{"username": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	return cs.presence.ResolveParticipants(users, roles, userID), nil
+}
+
+// ConversationETag returns the entity tag for a conversation as seen by the
+// user. The pin and unread count are folded in because neither touches
+// updated_at.
+func (cs *ConversationService) ConversationETag(conversation *models.Conversation, userID primitive.ObjectID) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return utils.WeakETag(
+		conversation.ID.Hex(), conversation.UpdatedAt, userID.Hex(),
+		conversation.MessagesCount, conversation.LastMessageAt, conversation.LastActivityAt,
+		conversation.GetPinnedAt(userID), cs.getUnreadCount(ctx, conversation.ID, userID),
+	)
+}
+
+// BuildConversationResponse populates participants and the user-specific
+// context for a conversation fetched with GetParticipantConversation
+func (cs *ConversationService) BuildConversationResponse(conversation *models.Conversation, userID primitive.ObjectID) *models.ConversationResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
 	// Populate participant information
-	cs.populateConversationUsers(ctx, &conversation)
+	cs.populateConversationUsers(ctx, conversation)
 
 	// Convert to response
 	response := conversation.ToConversationResponse()
@@ -193,8 +341,11 @@ func (cs *ConversationService) GetConversationByID(conversationID, userID primit
 	response.CanSendMessages = conversation.CanSendMessages(userID)
 	response.CanAddMembers = conversation.CanAddMembers(userID)
 	response.TypingUsers = cs.getTypingUsers(ctx, conversation.ID, userID)
+	response.IsRequest = conversation.IsPendingRequest(userID)
+	response.PinnedAt = conversation.GetPinnedAt(userID)
+	response.IsPinned = response.PinnedAt != nil
 
-	return &response, nil
+	return &response
 }
 
 // UpdateConversation updates conversation details
@@ -204,11 +355,10 @@ func (cs *ConversationService) UpdateConversation(conversationID, userID primiti
 
 	// Get existing conversation
 	var conversation models.Conversation
-	err := cs.conversationCollection.FindOne(ctx, bson.M{
+	err := cs.conversationCollection.FindOne(ctx, repository.NotDeleted(bson.M{
 		"_id":          conversationID,
 		"participants": userID,
-		"deleted_at":   bson.M{"$exists": false},
-	}).Decode(&conversation)
+	})).Decode(&conversation)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -254,6 +404,9 @@ func (cs *ConversationService) UpdateConversation(conversationID, userID primiti
 	}
 
 	if req.MaxParticipants != nil {
+		if *req.MaxParticipants < int64(len(conversation.Participants)) || *req.MaxParticipants > int64(cs.maxParticipants) {
+			return nil, fmt.Errorf("max participants must be between %d and %d", len(conversation.Participants), cs.maxParticipants)
+		}
 		update["$set"].(bson.M)["max_participants"] = *req.MaxParticipants
 	}
 
@@ -285,11 +438,10 @@ func (cs *ConversationService) AddParticipants(conversationID, userID primitive.
 
 	// Get conversation
 	var conversation models.Conversation
-	err := cs.conversationCollection.FindOne(ctx, bson.M{
+	err := cs.conversationCollection.FindOne(ctx, repository.NotDeleted(bson.M{
 		"_id":          conversationID,
 		"participants": userID,
-		"deleted_at":   bson.M{"$exists": false},
-	}).Decode(&conversation)
+	})).Decode(&conversation)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -303,8 +455,13 @@ func (cs *ConversationService) AddParticipants(conversationID, userID primitive.
 		return errors.New("insufficient permissions to add members")
 	}
 
+	if conversation.Type == "direct" {
+		return errors.New("cannot add participants to a direct conversation")
+	}
+
 	// Convert and validate participant IDs
 	var newParticipants []primitive.ObjectID
+	seen := make(map[primitive.ObjectID]bool)
 	for _, participantIDStr := range req.ParticipantIDs {
 		participantID, err := primitive.ObjectIDFromHex(participantIDStr)
 		if err != nil {
@@ -312,7 +469,8 @@ func (cs *ConversationService) AddParticipants(conversationID, userID primitive.
 		}
 
 		// Check if already a participant
-		if !conversation.IsParticipant(participantID) {
+		if !conversation.IsParticipant(participantID) && !seen[participantID] {
+			seen[participantID] = true
 			newParticipants = append(newParticipants, participantID)
 		}
 	}
@@ -322,8 +480,12 @@ func (cs *ConversationService) AddParticipants(conversationID, userID primitive.
 	}
 
 	// Check max participants limit
-	if conversation.MaxParticipants > 0 && int64(len(conversation.Participants)+len(newParticipants)) > conversation.MaxParticipants {
-		return errors.New("would exceed maximum participants limit")
+	limit := int64(cs.maxParticipants)
+	if conversation.MaxParticipants > 0 && conversation.MaxParticipants < limit {
+		limit = conversation.MaxParticipants
+	}
+	if int64(len(conversation.Participants)+len(newParticipants)) > limit {
+		return fmt.Errorf("would exceed maximum participants limit: conversation cannot have more than %d participants", limit)
 	}
 
 	// Validate participants exist
@@ -331,9 +493,25 @@ func (cs *ConversationService) AddParticipants(conversationID, userID primitive.
 		return err
 	}
 
-	// Add participants using model method
+	// New members can't have blocked, or be blocked by, anyone already in the conversation or each other
+	blocked, err := hasBlockBetween(ctx, cs.db, newParticipants, append(newParticipants, conversation.Participants...))
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return errors.New("one or more participants have blocked each other")
+	}
+
+	// Add participants using model method; users who don't accept messages
+	// from the inviter have to accept the group as a message request
 	for _, participantID := range newParticipants {
 		conversation.AddParticipant(participantID, &userID)
+		if !cs.canMessage(ctx, userID, participantID) {
+			conversation.SetRequestPending(participantID)
+		}
+	}
+	if err := models.CheckBounds(conversation); err != nil {
+		return err
 	}
 
 	// Update in database
@@ -347,8 +525,12 @@ func (cs *ConversationService) AddParticipants(conversationID, userID primitive.
 		},
 	}
 
-	_, err = cs.conversationCollection.UpdateOne(ctx, bson.M{"_id": conversationID}, update)
-	return err
+	if _, err = cs.conversationCollection.UpdateOne(ctx, bson.M{"_id": conversationID}, update); err != nil {
+		return err
+	}
+
+	cs.presence.InvalidateConversation(conversationID, newParticipants...)
+	return nil
 }
 
 // RemoveParticipant removes a participant from a conversation
@@ -358,11 +540,10 @@ func (cs *ConversationService) RemoveParticipant(conversationID, userID, partici
 
 	// Get conversation
 	var conversation models.Conversation
-	err := cs.conversationCollection.FindOne(ctx, bson.M{
+	err := cs.conversationCollection.FindOne(ctx, repository.NotDeleted(bson.M{
 		"_id":          conversationID,
 		"participants": userID,
-		"deleted_at":   bson.M{"$exists": false},
-	}).Decode(&conversation)
+	})).Decode(&conversation)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -396,8 +577,12 @@ func (cs *ConversationService) RemoveParticipant(conversationID, userID, partici
 		},
 	}
 
-	_, err = cs.conversationCollection.UpdateOne(ctx, bson.M{"_id": conversationID}, update)
-	return err
+	if _, err = cs.conversationCollection.UpdateOne(ctx, bson.M{"_id": conversationID}, update); err != nil {
+		return err
+	}
+
+	cs.presence.InvalidateConversation(conversationID, participantID)
+	return nil
 }
 
 // LeaveConversation allows a user to leave a conversation
@@ -407,11 +592,10 @@ func (cs *ConversationService) LeaveConversation(conversationID, userID primitiv
 
 	// Get conversation
 	var conversation models.Conversation
-	err := cs.conversationCollection.FindOne(ctx, bson.M{
+	err := cs.conversationCollection.FindOne(ctx, repository.NotDeleted(bson.M{
 		"_id":          conversationID,
 		"participants": userID,
-		"deleted_at":   bson.M{"$exists": false},
-	}).Decode(&conversation)
+	})).Decode(&conversation)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -440,11 +624,10 @@ func (cs *ConversationService) UpdateParticipantRole(conversationID, adminID, pa
 
 	// Get conversation
 	var conversation models.Conversation
-	err := cs.conversationCollection.FindOne(ctx, bson.M{
+	err := cs.conversationCollection.FindOne(ctx, repository.NotDeleted(bson.M{
 		"_id":          conversationID,
 		"participants": adminID,
-		"deleted_at":   bson.M{"$exists": false},
-	}).Decode(&conversation)
+	})).Decode(&conversation)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -506,6 +689,10 @@ func (cs *ConversationService) UpdateParticipantRole(conversationID, adminID, pa
 		}
 	}
 
+	if err := models.CheckBounds(conversation); err != nil {
+		return err
+	}
+
 	// Update in database
 	update := bson.M{
 		"$set": bson.M{
@@ -525,11 +712,10 @@ func (cs *ConversationService) ArchiveConversation(conversationID, userID primit
 	defer cancel()
 
 	// Verify user is participant
-	count, err := cs.conversationCollection.CountDocuments(ctx, bson.M{
+	count, err := cs.conversationCollection.CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"_id":          conversationID,
 		"participants": userID,
-		"deleted_at":   bson.M{"$exists": false},
-	})
+	}))
 
 	if err != nil || count == 0 {
 		return errors.New("conversation not found or access denied")
@@ -548,17 +734,698 @@ func (cs *ConversationService) ArchiveConversation(conversationID, userID primit
 	return err
 }
 
+// PinConversation pins a conversation to the top of the user's inbox. Pins
+// are per participant and don't affect anyone else's inbox.
+func (cs *ConversationService) PinConversation(conversationID, userID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := inboxFilter(userID)
+	filter["_id"] = conversationID
+
+	var conversation models.Conversation
+	if err := cs.conversationCollection.FindOne(ctx, filter).Decode(&conversation); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.New("conversation not found or access denied")
+		}
+		return err
+	}
+
+	if conversation.GetPinnedAt(userID) != nil {
+		return nil
+	}
+
+	pinnedFilter := inboxFilter(userID)
+	pinnedFilter["participant_info"] = bson.M{"$elemMatch": bson.M{"user_id": userID, "is_pinned": true}}
+	pinned, err := cs.conversationCollection.CountDocuments(ctx, pinnedFilter)
+	if err != nil {
+		return err
+	}
+	if pinned >= models.MaxPinnedConversations {
+		return fmt.Errorf("cannot pin more than %d conversations", models.MaxPinnedConversations)
+	}
+
+	return cs.setPinned(ctx, conversationID, userID, true)
+}
+
+// UnpinConversation removes a conversation from the pinned section of the user's inbox
+func (cs *ConversationService) UnpinConversation(conversationID, userID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	count, err := cs.conversationCollection.CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"_id":          conversationID,
+		"participants": userID,
+	}))
+	if err != nil || count == 0 {
+		return errors.New("conversation not found or access denied")
+	}
+
+	return cs.setPinned(ctx, conversationID, userID, false)
+}
+
+// GetMessageRequests retrieves conversations waiting in the user's message requests
+func (cs *ConversationService) GetMessageRequests(userID primitive.ObjectID, limit, skip int) ([]models.ConversationResponse, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	filter := requestsFilter(userID)
+
+	totalCount, err := cs.conversationCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSkip(int64(skip)).
+		SetSort(bson.M{"last_activity_at": -1})
+
+	cursor, err := cs.conversationCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var conversations []models.Conversation
+	if err := cursor.All(ctx, &conversations); err != nil {
+		return nil, 0, err
+	}
+
+	responses := []models.ConversationResponse{}
+	for _, conv := range conversations {
+		cs.populateConversationUsers(ctx, &conv)
+
+		response := conv.ToConversationResponse()
+		response.UnreadCount = cs.getUnreadCount(ctx, conv.ID, userID)
+		response.UserRole = conv.GetParticipantRole(userID)
+		response.CanSendMessages = conv.CanSendMessages(userID)
+		response.IsRequest = true
+
+		responses = append(responses, response)
+	}
+
+	return responses, totalCount, nil
+}
+
+// AcceptMessageRequest moves a conversation from message requests to the main inbox
+func (cs *ConversationService) AcceptMessageRequest(conversationID, userID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := requestsFilter(userID)
+	filter["_id"] = conversationID
+
+	update := bson.M{
+		"$unset": bson.M{"participant_info.$[p].request_status": ""},
+		"$set":   bson.M{"updated_at": time.Now()},
+	}
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"p.user_id": userID}},
+	})
+
+	result, err := cs.conversationCollection.UpdateOne(ctx, filter, update, opts)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("message request not found")
+	}
+
+	cs.presence.InvalidateConversation(conversationID, userID)
+	return nil
+}
+
+// ClaimRequestNotification reports whether the user should be notified of
+// the message request, which is true only the first time it is called for
+// a pending request so further messages don't ping them again
+func (cs *ConversationService) ClaimRequestNotification(conversationID, userID primitive.ObjectID) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := cs.conversationCollection.UpdateOne(ctx, bson.M{
+		"_id": conversationID,
+		"participant_info": bson.M{"$elemMatch": bson.M{
+			"user_id":             userID,
+			"request_status":      models.ConversationRequestPending,
+			"request_notified_at": bson.M{"$exists": false},
+		}},
+	}, bson.M{
+		"$set": bson.M{"participant_info.$.request_notified_at": time.Now()},
+	})
+	return err == nil && result.ModifiedCount > 0
+}
+
+// DeclineMessageRequest deletes a message request, optionally blocking the sender
+func (cs *ConversationService) DeclineMessageRequest(conversationID, userID primitive.ObjectID, blockSender bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := requestsFilter(userID)
+	filter["_id"] = conversationID
+
+	var conversation models.Conversation
+	if err := cs.conversationCollection.FindOne(ctx, filter).Decode(&conversation); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.New("message request not found")
+		}
+		return err
+	}
+
+	senderID := conversation.GetRequestSender(userID)
+	now := time.Now()
+
+	if conversation.Type == "direct" {
+		// Declining a direct request deletes the conversation and its messages
+		_, err := cs.conversationCollection.UpdateOne(ctx, bson.M{"_id": conversationID}, bson.M{
+			"$set": bson.M{
+				"is_active":  false,
+				"deleted_at": now,
+				"updated_at": now,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		cs.messageCollection.UpdateMany(ctx, repository.NotDeleted(bson.M{
+			"conversation_id": conversationID,
+		}), bson.M{"$set": bson.M{"deleted_at": now, "updated_at": now}})
+	} else {
+		// Declining a group request only removes the user from the group
+		conversation.RemoveParticipant(userID)
+
+		_, err := cs.conversationCollection.UpdateOne(ctx, bson.M{"_id": conversationID}, bson.M{
+			"$set": bson.M{
+				"participants":         conversation.Participants,
+				"participant_info":     conversation.ParticipantInfo,
+				"active_members_count": conversation.ActiveMembersCount,
+				"updated_at":           now,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	cs.presence.InvalidateConversation(conversationID, userID)
+
+	if blockSender && senderID != userID {
+		return blockUser(ctx, cs.db, userID, senderID)
+	}
+
+	return nil
+}
+
+// ExportConversation builds a downloadable transcript of the messages a participant can see
+func (cs *ConversationService) ExportConversation(userID, conversationID primitive.ObjectID, format string) ([]byte, *models.ConversationExport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if !models.IsValidConversationExportFormat(format) {
+		return nil, nil, errors.New("unsupported export format")
+	}
+
+	var conversation models.Conversation
+	err := cs.conversationCollection.FindOne(ctx, repository.NotDeleted(bson.M{
+		"_id":          conversationID,
+		"participants": userID,
+	})).Decode(&conversation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil, errors.New("conversation not found or access denied")
+		}
+		return nil, nil, err
+	}
+
+	filter := repository.NotDeleted(bson.M{
+		"conversation_id": conversationID,
+	})
+
+	// Participants only get the history from when they joined
+	for _, info := range conversation.ParticipantInfo {
+		if info.UserID == userID && !info.JoinedAt.IsZero() {
+			filter["created_at"] = bson.M{"$gte": info.JoinedAt}
+			break
+		}
+	}
+
+	cursor, err := cs.messageCollection.Find(ctx, filter, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var messages []models.Message
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	senderNames := cs.getSenderNames(ctx, messages)
+	isRequest := conversation.IsPendingRequest(userID)
+
+	transcript := models.ConversationTranscript{
+		ConversationID: conversationID.Hex(),
+		Type:           conversation.Type,
+		Title:          conversation.Title,
+		ExportedBy:     userID.Hex(),
+		ExportedAt:     now,
+		Messages:       make([]models.TranscriptMessage, 0, len(messages)),
+	}
+	for _, message := range messages {
+		entry := models.TranscriptMessage{
+			ID:          message.ID.Hex(),
+			SenderID:    message.SenderID.Hex(),
+			SenderName:  senderNames[message.SenderID],
+			Content:     message.Content,
+			ContentType: message.ContentType,
+			Media:       message.Media,
+			IsEdited:    message.IsEdited,
+			SentAt:      message.CreatedAt,
+		}
+
+		// Media others sent in a message request stays hidden until it's accepted
+		if isRequest && message.SenderID != userID && len(entry.Media) > 0 {
+			entry.Media = nil
+			entry.MediaHidden = true
+		}
+
+		transcript.Messages = append(transcript.Messages, entry)
+	}
+
+	var data []byte
+	if format == models.ConversationExportJSON {
+		data, err = json.MarshalIndent(transcript, "", "  ")
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		data = []byte(formatTranscriptText(transcript))
+	}
+
+	export := &models.ConversationExport{
+		UserID:         userID,
+		ConversationID: conversationID,
+		Format:         format,
+		MessageCount:   len(transcript.Messages),
+		Filename:       fmt.Sprintf("conversation_%s_%s.%s", conversationID.Hex(), now.Format("20060102_150405"), format),
+		CreatedAt:      now,
+	}
+
+	result, err := cs.db.Collection("conversation_exports").InsertOne(ctx, export)
+	if err != nil {
+		return nil, nil, err
+	}
+	export.ID = result.InsertedID.(primitive.ObjectID)
+
+	return data, export, nil
+}
+
 // Helper methods
 
+// getSenderNames resolves display names for the senders of the given messages
+func (cs *ConversationService) getSenderNames(ctx context.Context, messages []models.Message) map[primitive.ObjectID]string {
+	names := make(map[primitive.ObjectID]string)
+
+	var senderIDs []primitive.ObjectID
+	for _, message := range messages {
+		if _, seen := names[message.SenderID]; !seen {
+			names[message.SenderID] = "Unknown user"
+			senderIDs = append(senderIDs, message.SenderID)
+		}
+	}
+	if len(senderIDs) == 0 {
+		return names
+	}
+
+	cursor, err := cs.userCollection.Find(ctx, bson.M{"_id": bson.M{"$in": senderIDs}},
+		options.Find().SetProjection(bson.M{"username": 1, "display_name": 1, "first_name": 1, "last_name": 1}))
+	if err != nil {
+		return names
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return names
+	}
+
+	for _, user := range users {
+		name := user.DisplayName
+		if name == "" {
+			name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+		}
+		if name == "" {
+			name = user.Username
+		}
+		names[user.ID] = name
+	}
+
+	return names
+}
+
+// formatTranscriptText renders a transcript as plain text
+func formatTranscriptText(transcript models.ConversationTranscript) string {
+	var b strings.Builder
+
+	title := transcript.Title
+	if title == "" {
+		title = "Direct conversation"
+	}
+	fmt.Fprintf(&b, "%s\n", title)
+	fmt.Fprintf(&b, "Exported at %s\n\n", transcript.ExportedAt.UTC().Format(time.RFC3339))
+
+	for _, message := range transcript.Messages {
+		content := message.Content
+		if content == "" && len(message.Media) > 0 {
+			content = fmt.Sprintf("[%d attachment(s)]", len(message.Media))
+		}
+		if content == "" && message.MediaHidden {
+			content = "[attachments hidden until the request is accepted]"
+		}
+		if message.IsEdited {
+			content += " (edited)"
+		}
+		fmt.Fprintf(&b, "[%s] %s: %s\n", message.SentAt.UTC().Format("2006-01-02 15:04:05"), message.SenderName, content)
+	}
+
+	return b.String()
+}
+
+// findInboxConversations returns a page of the user's inbox: pinned
+// conversations first, most recently pinned on top, then the rest by the
+// sort order - last activity, or message volume for most_active
+func (cs *ConversationService) findInboxConversations(ctx context.Context, userID primitive.ObjectID, sortBy string, limit, skip int) ([]models.Conversation, error) {
+	if sortBy == models.InboxSortMostActive {
+		return cs.findMostActiveConversations(ctx, userID, limit, skip)
+	}
+
+	pipeline := []bson.M{
+		{"$match": inboxFilter(userID)},
+		{"$addFields": bson.M{"user_pinned_at": userPinnedAtField(userID)}},
+		// Unpinned conversations have no pin time and sort after pinned ones
+		{"$sort": bson.D{{Key: "user_pinned_at", Value: -1}, {Key: "last_activity_at", Value: -1}}},
+		{"$skip": int64(skip)},
+		{"$limit": int64(limit)},
+		{"$project": bson.M{"user_pinned_at": 0}},
+	}
+
+	cursor, err := cs.conversationCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var conversations []models.Conversation
+	if err := cursor.All(ctx, &conversations); err != nil {
+		return nil, err
+	}
+
+	return conversations, nil
+}
+
+// findMostActiveConversations ranks the user's inbox by messages sent within
+// the activity window. The ranking needs every inbox conversation's count, so
+// only IDs and sort keys are loaded before the page is fetched in full.
+func (cs *ConversationService) findMostActiveConversations(ctx context.Context, userID primitive.ObjectID, limit, skip int) ([]models.Conversation, error) {
+	cursor, err := cs.conversationCollection.Aggregate(ctx, []bson.M{
+		{"$match": inboxFilter(userID)},
+		{"$project": bson.M{
+			"user_pinned_at":   userPinnedAtField(userID),
+			"last_activity_at": 1,
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []struct {
+		ID             primitive.ObjectID `bson:"_id"`
+		PinnedAt       *time.Time         `bson:"user_pinned_at"`
+		LastActivityAt *time.Time         `bson:"last_activity_at"`
+	}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+	}
+	scores, err := cs.activityScores(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if (a.PinnedAt != nil) != (b.PinnedAt != nil) {
+			return a.PinnedAt != nil
+		}
+		if a.PinnedAt != nil {
+			return a.PinnedAt.After(*b.PinnedAt)
+		}
+		if scores[a.ID] != scores[b.ID] {
+			return scores[a.ID] > scores[b.ID]
+		}
+		if a.LastActivityAt == nil || b.LastActivityAt == nil {
+			return a.LastActivityAt != nil
+		}
+		return a.LastActivityAt.After(*b.LastActivityAt)
+	})
+
+	if skip >= len(entries) {
+		return nil, nil
+	}
+	end := skip + limit
+	if end > len(entries) {
+		end = len(entries)
+	}
+	page := make([]primitive.ObjectID, 0, end-skip)
+	for _, entry := range entries[skip:end] {
+		page = append(page, entry.ID)
+	}
+
+	cursor, err = cs.conversationCollection.Find(ctx, bson.M{"_id": bson.M{"$in": page}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var found []models.Conversation
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[primitive.ObjectID]models.Conversation, len(found))
+	for _, conv := range found {
+		byID[conv.ID] = conv
+	}
+	conversations := make([]models.Conversation, 0, len(page))
+	for _, id := range page {
+		if conv, ok := byID[id]; ok {
+			conversations = append(conversations, conv)
+		}
+	}
+
+	return conversations, nil
+}
+
+// activityScores returns how many messages each conversation received within
+// the activity window. Counts are cached per conversation, since members of a
+// group share them, and only conversations without a fresh count are counted.
+func (cs *ConversationService) activityScores(ctx context.Context, conversationIDs []primitive.ObjectID) (map[primitive.ObjectID]int64, error) {
+	now := time.Now()
+	scores := make(map[primitive.ObjectID]int64, len(conversationIDs))
+	var stale []primitive.ObjectID
+
+	cs.activityMu.Lock()
+	for _, id := range conversationIDs {
+		if cached, ok := cs.activity[id]; ok && now.Before(cached.expiresAt) {
+			scores[id] = cached.messages
+		} else {
+			stale = append(stale, id)
+		}
+	}
+	cs.activityMu.Unlock()
+
+	if len(stale) == 0 {
+		return scores, nil
+	}
+
+	cursor, err := cs.messageCollection.Aggregate(ctx, []bson.M{
+		{"$match": repository.NotDeleted(bson.M{
+			"conversation_id": bson.M{"$in": stale},
+			"created_at":      bson.M{"$gte": now.Add(-cs.activityPolicy.Window)},
+		})},
+		{"$group": bson.M{"_id": "$conversation_id", "count": bson.M{"$sum": 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var counts []struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Count int64              `bson:"count"`
+	}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+	for _, count := range counts {
+		scores[count.ID] = count.Count
+	}
+
+	expiresAt := now.Add(cs.activityPolicy.CacheTTL)
+	cs.activityMu.Lock()
+	for id, cached := range cs.activity {
+		if now.After(cached.expiresAt) {
+			delete(cs.activity, id)
+		}
+	}
+	for _, id := range stale {
+		cs.activity[id] = conversationActivity{messages: scores[id], expiresAt: expiresAt}
+	}
+	cs.activityMu.Unlock()
+
+	return scores, nil
+}
+
+// userPinnedAtField is an aggregation expression for when the user pinned
+// the conversation, missing when they haven't
+func userPinnedAtField(userID primitive.ObjectID) bson.M {
+	return bson.M{"$arrayElemAt": []interface{}{
+		bson.M{"$map": bson.M{
+			"input": bson.M{"$filter": bson.M{
+				"input": "$participant_info",
+				"as":    "p",
+				"cond": bson.M{"$and": []bson.M{
+					{"$eq": []interface{}{"$$p.user_id", userID}},
+					{"$eq": []interface{}{"$$p.is_pinned", true}},
+				}},
+			}},
+			"as": "p",
+			"in": "$$p.pinned_at",
+		}},
+		0,
+	}}
+}
+
+// setPinned sets or clears the user's pin on a conversation
+func (cs *ConversationService) setPinned(ctx context.Context, conversationID, userID primitive.ObjectID, pinned bool) error {
+	update := bson.M{
+		"$set": bson.M{
+			"participant_info.$[p].is_pinned": true,
+			"participant_info.$[p].pinned_at": time.Now(),
+		},
+	}
+	if !pinned {
+		update = bson.M{
+			"$unset": bson.M{
+				"participant_info.$[p].is_pinned": "",
+				"participant_info.$[p].pinned_at": "",
+			},
+		}
+	}
+
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"p.user_id": userID}},
+	})
+
+	_, err := cs.conversationCollection.UpdateOne(ctx, bson.M{"_id": conversationID}, update, opts)
+	return err
+}
+
+// inboxFilter matches the user's conversations outside of message requests
+func inboxFilter(userID primitive.ObjectID) bson.M {
+	return repository.NotDeleted(bson.M{
+		"participants": userID,
+		"is_active":    true,
+		"participant_info": bson.M{"$not": bson.M{"$elemMatch": bson.M{
+			"user_id":        userID,
+			"request_status": models.ConversationRequestPending,
+		}}},
+	})
+}
+
+// requestsFilter matches conversations waiting in the user's message requests
+func requestsFilter(userID primitive.ObjectID) bson.M {
+	return repository.NotDeleted(bson.M{
+		"is_active": true,
+		"participant_info": bson.M{"$elemMatch": bson.M{
+			"user_id":        userID,
+			"request_status": models.ConversationRequestPending,
+		}},
+	})
+}
+
+// canMessage checks whether the recipient's privacy settings let the sender message them directly
+func (cs *ConversationService) canMessage(ctx context.Context, senderID, recipientID primitive.ObjectID) bool {
+	var recipient models.User
+	err := cs.userCollection.FindOne(ctx, bson.M{"_id": recipientID},
+		options.FindOne().SetProjection(bson.M{"privacy_settings": 1})).Decode(&recipient)
+	if err != nil {
+		return false
+	}
+
+	switch recipient.PrivacySettings.MessagePermission() {
+	case models.MessagePermissionEveryone:
+		return true
+	case models.MessagePermissionFollowing:
+		return cs.isFollowing(ctx, recipientID, senderID)
+	case models.MessagePermissionFollowers:
+		return cs.isFollowing(ctx, senderID, recipientID)
+	case models.MessagePermissionMutual:
+		return cs.isFollowing(ctx, senderID, recipientID) && cs.isFollowing(ctx, recipientID, senderID)
+	default:
+		return false
+	}
+}
+
+// isFollowing checks for an accepted follow from follower to followee
+func (cs *ConversationService) isFollowing(ctx context.Context, followerID, followeeID primitive.ObjectID) bool {
+	count, err := cs.db.Collection("follows").CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"follower_id": followerID,
+		"followee_id": followeeID,
+		"status":      models.FollowStatusAccepted,
+	}))
+	return err == nil && count > 0
+}
+
+// getRequestCounts returns the number of message requests and their unread messages
+func (cs *ConversationService) getRequestCounts(ctx context.Context, userID primitive.ObjectID) (int64, int64, error) {
+	cursor, err := cs.conversationCollection.Find(ctx, requestsFilter(userID),
+		options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var requests []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &requests); err != nil {
+		return 0, 0, err
+	}
+
+	unread := int64(0)
+	for _, request := range requests {
+		unread += cs.getUnreadCount(ctx, request.ID, userID)
+	}
+
+	return int64(len(requests)), unread, nil
+}
+
 // findDirectConversation finds existing direct conversation between two users
 func (cs *ConversationService) findDirectConversation(ctx context.Context, user1ID, user2ID primitive.ObjectID) (*models.Conversation, error) {
 	var conversation models.Conversation
-	err := cs.conversationCollection.FindOne(ctx, bson.M{
+	err := cs.conversationCollection.FindOne(ctx, repository.NotDeleted(bson.M{
 		"type":         "direct",
 		"participants": bson.M{"$all": []primitive.ObjectID{user1ID, user2ID}, "$size": 2},
 		"is_active":    true,
-		"deleted_at":   bson.M{"$exists": false},
-	}).Decode(&conversation)
+	})).Decode(&conversation)
 
 	if err != nil {
 		return nil, err
@@ -570,11 +1437,10 @@ func (cs *ConversationService) findDirectConversation(ctx context.Context, user1
 
 // validateParticipants validates that all participant IDs exist and are active users
 func (cs *ConversationService) validateParticipants(ctx context.Context, participantIDs []primitive.ObjectID) error {
-	count, err := cs.userCollection.CountDocuments(ctx, bson.M{
-		"_id":        bson.M{"$in": participantIDs},
-		"is_active":  true,
-		"deleted_at": bson.M{"$exists": false},
-	})
+	count, err := cs.userCollection.CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"_id":       bson.M{"$in": participantIDs},
+		"is_active": true,
+	}))
 
 	if err != nil {
 		return err
@@ -621,12 +1487,11 @@ func (cs *ConversationService) populateConversationUsers(ctx context.Context, co
 
 // getUnreadCount gets unread message count for user in conversation
 func (cs *ConversationService) getUnreadCount(ctx context.Context, conversationID, userID primitive.ObjectID) int64 {
-	count, err := cs.messageCollection.CountDocuments(ctx, bson.M{
+	count, err := cs.messageCollection.CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"conversation_id": conversationID,
 		"sender_id":       bson.M{"$ne": userID},
 		"read_by.user_id": bson.M{"$ne": userID},
-		"deleted_at":      bson.M{"$exists": false},
-	})
+	}))
 
 	if err != nil {
 		return 0
@@ -692,29 +1557,26 @@ func (cs *ConversationService) GetConversationStats(conversationID, userID primi
 
 	// Verify user access
 	var conversation models.Conversation
-	err := cs.conversationCollection.FindOne(ctx, bson.M{
+	err := cs.conversationCollection.FindOne(ctx, repository.NotDeleted(bson.M{
 		"_id":          conversationID,
 		"participants": userID,
-		"deleted_at":   bson.M{"$exists": false},
-	}).Decode(&conversation)
+	})).Decode(&conversation)
 
 	if err != nil {
 		return nil, errors.New("conversation not found or access denied")
 	}
 
 	// Count messages
-	messageCount, _ := cs.messageCollection.CountDocuments(ctx, bson.M{
+	messageCount, _ := cs.messageCollection.CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"conversation_id": conversationID,
-		"deleted_at":      bson.M{"$exists": false},
-	})
+	}))
 
 	// Count unread messages for user
-	unreadCount, _ := cs.messageCollection.CountDocuments(ctx, bson.M{
+	unreadCount, _ := cs.messageCollection.CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"conversation_id": conversationID,
 		"sender_id":       bson.M{"$ne": userID},
 		"read_by.user_id": bson.M{"$ne": userID},
-		"deleted_at":      bson.M{"$exists": false},
-	})
+	}))
 
 	return &models.ConversationStatsResponse{
 		ConversationID:      conversationID.Hex(),
@@ -734,11 +1596,10 @@ func (cs *ConversationService) MuteConversation(conversationID, userID primitive
 	defer cancel()
 
 	// Verify user is participant
-	count, err := cs.conversationCollection.CountDocuments(ctx, bson.M{
+	count, err := cs.conversationCollection.CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"_id":          conversationID,
 		"participants": userID,
-		"deleted_at":   bson.M{"$exists": false},
-	})
+	}))
 
 	if err != nil || count == 0 {
 		return errors.New("conversation not found or access denied")
@@ -775,7 +1636,7 @@ func (cs *ConversationService) GetUnreadCounts(userID primitive.ObjectID) (map[s
 	defer cancel()
 
 	// Get user's conversations
-	conversations, err := cs.GetUserConversations(userID, 100, 0) // Get first 100 conversations
+	conversations, err := cs.GetUserConversations(userID, models.InboxSortRecent, 100, 0) // Get first 100 conversations
 	if err != nil {
 		return nil, err
 	}
@@ -802,9 +1663,17 @@ func (cs *ConversationService) GetUnreadCounts(userID primitive.ObjectID) (map[s
 		}
 	}
 
+	// Message requests are counted separately so they don't drive the inbox badge
+	requestsCount, requestsUnread, err := cs.getRequestCounts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	return map[string]interface{}{
-		"total_unread":  totalUnread,
-		"conversations": conversationCounts,
+		"total_unread":    totalUnread,
+		"conversations":   conversationCounts,
+		"requests_count":  requestsCount,
+		"requests_unread": requestsUnread,
 	}, nil
 }
 
@@ -814,14 +1683,10 @@ func (cs *ConversationService) SearchUserConversations(userID primitive.ObjectID
 	defer cancel()
 
 	// Build search filter
-	filter := bson.M{
-		"participants": userID,
-		"is_active":    true,
-		"deleted_at":   bson.M{"$exists": false},
-		"$or": []bson.M{
-			{"title": bson.M{"$regex": query, "$options": "i"}},
-			{"description": bson.M{"$regex": query, "$options": "i"}},
-		},
+	filter := inboxFilter(userID)
+	filter["$or"] = []bson.M{
+		{"title": bson.M{"$regex": query, "$options": "i"}},
+		{"description": bson.M{"$regex": query, "$options": "i"}},
 	}
 
 	// Get total count for pagination
@@ -862,6 +1727,8 @@ func (cs *ConversationService) SearchUserConversations(userID primitive.ObjectID
 		response.UserRole = conv.GetParticipantRole(userID)
 		response.CanSendMessages = conv.CanSendMessages(userID)
 		response.CanAddMembers = conv.CanAddMembers(userID)
+		response.PinnedAt = conv.GetPinnedAt(userID)
+		response.IsPinned = response.PinnedAt != nil
 
 		responses = append(responses, response)
 	}
@@ -870,15 +1737,11 @@ func (cs *ConversationService) SearchUserConversations(userID primitive.ObjectID
 }
 
 // GetUserConversationsWithTotal returns conversations with total count for proper pagination
-func (cs *ConversationService) GetUserConversationsWithTotal(userID primitive.ObjectID, limit, skip int) ([]models.ConversationResponse, int64, error) {
+func (cs *ConversationService) GetUserConversationsWithTotal(userID primitive.ObjectID, sortBy string, limit, skip int) ([]models.ConversationResponse, int64, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	filter := bson.M{
-		"participants": userID,
-		"is_active":    true,
-		"deleted_at":   bson.M{"$exists": false},
-	}
+	filter := inboxFilter(userID)
 
 	// Get total count
 	totalCount, err := cs.conversationCollection.CountDocuments(ctx, filter)
@@ -887,21 +1750,10 @@ func (cs *ConversationService) GetUserConversationsWithTotal(userID primitive.Ob
 	}
 
 	// Get conversations
-	opts := options.Find().
-		SetLimit(int64(limit)).
-		SetSkip(int64(skip)).
-		SetSort(bson.M{"last_activity_at": -1})
-
-	cursor, err := cs.conversationCollection.Find(ctx, filter, opts)
+	conversations, err := cs.findInboxConversations(ctx, userID, sortBy, limit, skip)
 	if err != nil {
 		return nil, 0, err
 	}
-	defer cursor.Close(ctx)
-
-	var conversations []models.Conversation
-	if err := cursor.All(ctx, &conversations); err != nil {
-		return nil, 0, err
-	}
 
 	// Convert to response format
 	var responses []models.ConversationResponse
@@ -918,6 +1770,8 @@ func (cs *ConversationService) GetUserConversationsWithTotal(userID primitive.Ob
 		response.UserRole = conv.GetParticipantRole(userID)
 		response.CanSendMessages = conv.CanSendMessages(userID)
 		response.CanAddMembers = conv.CanAddMembers(userID)
+		response.PinnedAt = conv.GetPinnedAt(userID)
+		response.IsPinned = response.PinnedAt != nil
 
 		// Get typing users
 		response.TypingUsers = cs.getTypingUsers(ctx, conv.ID, userID)
@@ -0,0 +1,41 @@
+package services_test
+
+import (
+	"testing"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestCreateConversationRejectsSelfOnlyDirect(t *testing.T) {
+	h := testutil.NewHarness(t)
+	conversations := services.NewConversationService(10, services.ConversationActivityPolicy{}, models.AccountMaturityPolicy{}, nil)
+	user := h.CreateUser()
+
+	tests := []struct {
+		name         string
+		participants []string
+	}{
+		{"only self", []string{user.ID.Hex()}},
+		{"self twice", []string{user.ID.Hex(), user.ID.Hex()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := conversations.CreateConversation(user.ID, models.CreateConversationRequest{
+				Type:           "direct",
+				ParticipantIDs: tt.participants,
+			})
+			if err == nil || err.Error() != "cannot start a direct conversation with yourself" {
+				t.Errorf("CreateConversation error = %v, want \"cannot start a direct conversation with yourself\"", err)
+			}
+		})
+	}
+
+	if got := h.Count("conversations", bson.M{}); got != 0 {
+		t.Errorf("conversations = %d, want 0", got)
+	}
+}
@@ -0,0 +1,68 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Messages a delegate sends or forwards, and comment moderation they do for
+// the account, record who really acted
+func TestDelegateActionsAreAttributed(t *testing.T) {
+	h := testutil.NewHarness(t)
+	messages := newTestMessageService(h)
+	comments := services.NewCommentService(services.CommentHoldPolicy{}, services.CommentFloodPolicy{}, 0, 3,
+		services.NewNotificationService(nil, nil), services.NewLinkBlocklistService(h.DB, services.LinkBlocklistPolicy{CacheTTL: time.Minute}))
+	account := h.CreateUser()
+	delegate := h.CreateUser()
+	friend := h.CreateUser()
+
+	conversation := h.CreateConversation(account, []*models.User{friend})
+	sent, err := messages.SendMessage(account.ID, conversation.ID, models.CreateMessageRequest{
+		Content:     "replying for the account",
+		ContentType: models.ContentTypeText,
+		DelegateID:  &delegate.ID,
+	})
+	if err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	target := h.CreateConversation(account, []*models.User{h.CreateUser()})
+	forwarded, err := messages.ForwardMessage(account.ID, sent.ID, []primitive.ObjectID{target.ID}, &delegate.ID)
+	if err != nil {
+		t.Fatalf("ForwardMessage: %v", err)
+	}
+	own, err := messages.SendMessage(account.ID, conversation.ID, textMessage("the owner themselves"))
+	if err != nil {
+		t.Fatalf("SendMessage by the owner: %v", err)
+	}
+
+	for _, id := range []primitive.ObjectID{sent.ID, forwarded[0].ID} {
+		if got := h.Count("messages", bson.M{"_id": id, "sent_by_delegate": delegate.ID}); got != 1 {
+			t.Errorf("message %s isn't attributed to the delegate", id.Hex())
+		}
+	}
+	if got := h.Count("messages", bson.M{"_id": own.ID, "sent_by_delegate": bson.M{"$exists": true}}); got != 0 {
+		t.Error("the owner's own message is attributed to a delegate")
+	}
+
+	post := h.CreatePost(account)
+	pinned := h.CreateComment(friend, post, "pin me")
+	removed := h.CreateComment(friend, post, "remove me")
+	if err := comments.PinComment(pinned.ID, account.ID, &delegate.ID); err != nil {
+		t.Fatalf("PinComment: %v", err)
+	}
+	if err := comments.DeleteComment(removed.ID, account.ID, &delegate.ID); err != nil {
+		t.Fatalf("DeleteComment: %v", err)
+	}
+	for _, id := range []primitive.ObjectID{pinned.ID, removed.ID} {
+		if got := h.Count("comments", bson.M{"_id": id, "moderated_by_delegate": delegate.ID}); got != 1 {
+			t.Errorf("moderation of comment %s isn't attributed to the delegate", id.Hex())
+		}
+	}
+}
@@ -0,0 +1,375 @@
+// internal/services/delegation_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DelegationService manages delegated access to accounts. Delegates switch
+// into an account with a short-lived token that names both of them; every
+// request with such a token is checked against the delegation here.
+//
+// Delegation lookups are cached for cacheTTL. Revoking a delegation records
+// it in a revocation cache that is checked before anything else, so tokens
+// die immediately on this instance and within cacheTTL everywhere else.
+type DelegationService struct {
+	collection     *mongo.Collection
+	userCollection *mongo.Collection
+	db             *mongo.Database
+
+	notificationService *NotificationService
+	jwtSecret           []byte
+	cacheTTL            time.Duration
+	tokenTTL            time.Duration
+
+	mu      sync.RWMutex
+	cache   map[primitive.ObjectID]cachedDelegation
+	revoked map[primitive.ObjectID]time.Time // Delegation ID to when its last token expires
+}
+
+type cachedDelegation struct {
+	delegation models.AccountDelegation
+	loadedAt   time.Time
+}
+
+// SwitchAccountResponse carries the token a delegate uses to act for an account
+type SwitchAccountResponse struct {
+	Account     models.UserResponse `json:"account"`
+	Role        models.DelegateRole `json:"role"`
+	AccessToken string              `json:"access_token"`
+	ExpiresIn   int64               `json:"expires_in"`
+	TokenType   string              `json:"token_type"`
+}
+
+func NewDelegationService(db *mongo.Database, notificationService *NotificationService, jwtSecret string, tokenTTL, cacheTTL time.Duration) *DelegationService {
+	return &DelegationService{
+		collection:          db.Collection("account_delegations"),
+		userCollection:      db.Collection("users"),
+		db:                  db,
+		notificationService: notificationService,
+		jwtSecret:           []byte(jwtSecret),
+		cacheTTL:            cacheTTL,
+		tokenTTL:            tokenTTL,
+		cache:               make(map[primitive.ObjectID]cachedDelegation),
+		revoked:             make(map[primitive.ObjectID]time.Time),
+	}
+}
+
+// InviteDelegate invites a user to act on behalf of an account. The
+// delegation stays pending until the invited user accepts it.
+func (ds *DelegationService) InviteDelegate(accountID, delegateID primitive.ObjectID, role models.DelegateRole) (*models.AccountDelegation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if accountID == delegateID {
+		return nil, errors.New("cannot delegate to yourself")
+	}
+
+	var delegate models.User
+	err := ds.userCollection.FindOne(ctx, repository.NotDeleted(bson.M{
+		"_id":       delegateID,
+		"is_active": true,
+	})).Decode(&delegate)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	delegation := &models.AccountDelegation{
+		AccountID:  accountID,
+		DelegateID: delegateID,
+		Role:       role,
+		Status:     models.DelegationPending,
+	}
+	delegation.BeforeCreate()
+
+	// The partial unique index allows one open delegation per pair
+	result, err := ds.collection.InsertOne(ctx, delegation)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, errors.New("user is already a delegate or has a pending invitation")
+		}
+		return nil, err
+	}
+	delegation.ID = result.InsertedID.(primitive.ObjectID)
+	delegation.Delegate = delegate.ToUserResponse()
+
+	if ds.notificationService != nil {
+		go ds.notificationService.NotifyDelegateInvite(accountID, delegateID, delegation.ID, role)
+	}
+
+	return delegation, nil
+}
+
+// RespondToInvitation accepts or declines a pending invitation
+func (ds *DelegationService) RespondToInvitation(delegationID, delegateID primitive.ObjectID, accept bool) (*models.AccountDelegation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	set := bson.M{"status": models.DelegationDeclined, "updated_at": now}
+	if accept {
+		set = bson.M{"status": models.DelegationActive, "accepted_at": now, "updated_at": now}
+	}
+
+	var delegation models.AccountDelegation
+	err := ds.collection.FindOneAndUpdate(ctx, bson.M{
+		"_id":         delegationID,
+		"delegate_id": delegateID,
+		"status":      models.DelegationPending,
+	}, bson.M{"$set": set}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&delegation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("invitation not found")
+		}
+		return nil, err
+	}
+
+	ds.populateDelegation(ctx, &delegation)
+	return &delegation, nil
+}
+
+// GetDelegates lists the open delegations on an account
+func (ds *DelegationService) GetDelegates(accountID primitive.ObjectID) ([]models.AccountDelegation, error) {
+	return ds.findDelegations(bson.M{
+		"account_id": accountID,
+		"status":     bson.M{"$in": []models.DelegationStatus{models.DelegationPending, models.DelegationActive}},
+	})
+}
+
+// GetManagedAccounts lists the accounts a user can act for, along with
+// invitations they haven't answered yet
+func (ds *DelegationService) GetManagedAccounts(delegateID primitive.ObjectID) ([]models.AccountDelegation, error) {
+	return ds.findDelegations(bson.M{
+		"delegate_id": delegateID,
+		"status":      bson.M{"$in": []models.DelegationStatus{models.DelegationPending, models.DelegationActive}},
+	})
+}
+
+// UpdateDelegateRole changes what a delegate may do. Tokens already issued
+// pick up the new role on their next request.
+func (ds *DelegationService) UpdateDelegateRole(accountID, delegationID primitive.ObjectID, role models.DelegateRole) (*models.AccountDelegation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var delegation models.AccountDelegation
+	err := ds.collection.FindOneAndUpdate(ctx, bson.M{
+		"_id":        delegationID,
+		"account_id": accountID,
+		"status":     bson.M{"$in": []models.DelegationStatus{models.DelegationPending, models.DelegationActive}},
+	}, bson.M{
+		"$set": bson.M{"role": role, "updated_at": time.Now()},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&delegation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("delegate not found")
+		}
+		return nil, err
+	}
+
+	ds.mu.Lock()
+	delete(ds.cache, delegationID)
+	ds.mu.Unlock()
+
+	ds.populateDelegation(ctx, &delegation)
+	return &delegation, nil
+}
+
+// RevokeDelegate ends a delegation. The account owner can revoke any of
+// their delegates and a delegate can give up their own access. Tokens issued
+// for the delegation stop working at once.
+func (ds *DelegationService) RevokeDelegate(delegationID, userID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	result, err := ds.collection.UpdateOne(ctx, bson.M{
+		"_id":    delegationID,
+		"$or":    []bson.M{{"account_id": userID}, {"delegate_id": userID}},
+		"status": bson.M{"$in": []models.DelegationStatus{models.DelegationPending, models.DelegationActive}},
+	}, bson.M{
+		"$set": bson.M{
+			"status":     models.DelegationRevoked,
+			"revoked_at": now,
+			"updated_at": now,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("delegate not found")
+	}
+
+	ds.mu.Lock()
+	delete(ds.cache, delegationID)
+	ds.revoked[delegationID] = now.Add(ds.tokenTTL)
+	ds.mu.Unlock()
+
+	return nil
+}
+
+// GetActiveDelegation returns the delegation that lets a user switch into an account
+func (ds *DelegationService) GetActiveDelegation(accountID, delegateID primitive.ObjectID) (*models.AccountDelegation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var delegation models.AccountDelegation
+	err := ds.collection.FindOne(ctx, bson.M{
+		"account_id":  accountID,
+		"delegate_id": delegateID,
+		"status":      models.DelegationActive,
+	}).Decode(&delegation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("no delegated access to this account")
+		}
+		return nil, err
+	}
+
+	return &delegation, nil
+}
+
+// SwitchAccount issues a delegate an access token for an account they manage.
+// The token carries both identities: user_id is the account, actor_id the
+// delegate. It can't be refreshed; the delegate switches again when it expires.
+func (ds *DelegationService) SwitchAccount(accountID, delegateID primitive.ObjectID, sessionID string) (*SwitchAccountResponse, error) {
+	delegation, err := ds.GetActiveDelegation(accountID, delegateID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var account models.User
+	err = ds.userCollection.FindOne(ctx, repository.ByID(accountID)).Decode(&account)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("account not found")
+		}
+		return nil, err
+	}
+	if !account.IsActive || account.IsSuspended {
+		return nil, errors.New("account suspended or inactive")
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"user_id":       account.ID.Hex(),
+		"username":      account.Username,
+		"email":         account.Email,
+		"role":          account.Role,
+		"session_id":    sessionID,
+		"actor_id":      delegateID.Hex(),
+		"delegation_id": delegation.ID.Hex(),
+		"token_type":    "access",
+		"iat":           now.Unix(),
+		"exp":           now.Add(ds.tokenTTL).Unix(),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(ds.jwtSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SwitchAccountResponse{
+		Account:     account.ToUserResponse(),
+		Role:        delegation.Role,
+		AccessToken: token,
+		ExpiresIn:   int64(ds.tokenTTL.Seconds()),
+		TokenType:   "Bearer",
+	}, nil
+}
+
+// CheckDelegation confirms a delegated token is still backed by an active
+// delegation between the same account and delegate, and returns its role
+func (ds *DelegationService) CheckDelegation(ctx context.Context, delegationID, accountID, delegateID primitive.ObjectID) (*models.AccountDelegation, error) {
+	ds.mu.RLock()
+	revokedUntil, isRevoked := ds.revoked[delegationID]
+	cached, ok := ds.cache[delegationID]
+	ds.mu.RUnlock()
+
+	if isRevoked && time.Now().Before(revokedUntil) {
+		return nil, errors.New("delegation revoked")
+	}
+
+	delegation := cached.delegation
+	if !ok || time.Since(cached.loadedAt) >= ds.cacheTTL {
+		if err := ds.collection.FindOne(ctx, bson.M{"_id": delegationID}).Decode(&delegation); err != nil {
+			return nil, err
+		}
+
+		ds.mu.Lock()
+		ds.cache[delegationID] = cachedDelegation{delegation: delegation, loadedAt: time.Now()}
+		ds.pruneRevocations()
+		ds.mu.Unlock()
+	}
+
+	if !delegation.IsActive() || delegation.AccountID != accountID || delegation.DelegateID != delegateID {
+		return nil, errors.New("delegation revoked")
+	}
+
+	return &delegation, nil
+}
+
+// Helper methods
+
+func (ds *DelegationService) findDelegations(filter bson.M) ([]models.AccountDelegation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := ds.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var delegations []models.AccountDelegation
+	if err := cursor.All(ctx, &delegations); err != nil {
+		return nil, err
+	}
+
+	for i := range delegations {
+		ds.populateDelegation(ctx, &delegations[i])
+	}
+
+	return delegations, nil
+}
+
+func (ds *DelegationService) populateDelegation(ctx context.Context, delegation *models.AccountDelegation) {
+	var account models.User
+	if err := ds.userCollection.FindOne(ctx, bson.M{"_id": delegation.AccountID}).Decode(&account); err == nil {
+		delegation.Account = account.ToUserResponse()
+	}
+
+	var delegate models.User
+	if err := ds.userCollection.FindOne(ctx, bson.M{"_id": delegation.DelegateID}).Decode(&delegate); err == nil {
+		delegation.Delegate = delegate.ToUserResponse()
+	}
+}
+
+// pruneRevocations drops revocations whose tokens have all expired; callers
+// hold ds.mu
+func (ds *DelegationService) pruneRevocations() {
+	now := time.Now()
+	for id, until := range ds.revoked {
+		if now.After(until) {
+			delete(ds.revoked, id)
+		}
+	}
+}
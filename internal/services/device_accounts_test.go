@@ -0,0 +1,123 @@
+package services_test
+
+import (
+	"strings"
+	"testing"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// TestDeviceAccountFlows walks the account switcher end to end: adding
+// accounts to a device, listing them, switching with and without a live
+// session, the per-device account limit, scoped logouts, per-account push
+// tokens and account-only revocation.
+func TestDeviceAccountFlows(t *testing.T) {
+	h := testutil.NewHarness(t)
+	auth := h.NewAuthService()
+	push := services.NewPushService("", "", "", "", "", nil)
+
+	personal := h.CreateUser()
+	brand := h.CreateUser()
+
+	personalSession := h.SessionID(h.SignIn(personal))
+	brandSession := h.SessionID(h.AddAccount(personalSession, brand))
+
+	if _, err := auth.AddAccount(personalSession, models.AddAccountRequest{EmailOrUsername: brand.Username, Password: testutil.DefaultPassword}); err == nil || !strings.Contains(err.Error(), "already signed in") {
+		t.Errorf("adding an account twice: got %v, want already signed in", err)
+	}
+
+	accounts, err := auth.GetDeviceAccounts(brandSession)
+	if err != nil {
+		t.Fatalf("failed to list device accounts: %v", err)
+	}
+	if len(accounts) != 2 || accounts[0].UserID != personal.ID || !accounts[1].IsCurrent {
+		t.Errorf("device accounts: got %+v, want personal then current brand", accounts)
+	}
+
+	// A live session switches without a password and reuses that session
+	switched, err := auth.SwitchAccount(brandSession, personal.ID, "")
+	if err != nil {
+		t.Fatalf("failed to switch to personal account: %v", err)
+	}
+	if got := h.SessionID(switched); got != personalSession {
+		t.Errorf("switch issued tokens for session %s, want %s", got, personalSession)
+	}
+
+	// Push tokens are kept per account on the shared device
+	const deviceToken = "test-device-token"
+	for _, session := range []struct {
+		user *models.User
+		id   string
+	}{{personal, personalSession}, {brand, brandSession}} {
+		if err := push.RegisterPushToken(session.user.ID, session.id, deviceToken, "ios", "test"); err != nil {
+			t.Fatalf("failed to register push token: %v", err)
+		}
+	}
+	if count := h.Count("push_tokens", bson.M{"token": deviceToken, "is_active": true}); count != 2 {
+		t.Errorf("active push token entries: got %d, want one per account", count)
+	}
+
+	// Logging the brand account out leaves the personal account signed in
+	if err := auth.Logout(brandSession); err != nil {
+		t.Fatalf("failed to log out brand account: %v", err)
+	}
+	if _, err := auth.GetSession(personalSession); err != nil {
+		t.Errorf("personal session ended with the brand logout: %v", err)
+	}
+	if count := h.Count("push_tokens", bson.M{"token": deviceToken, "user_id": personal.ID, "is_active": true}); count != 1 {
+		t.Errorf("personal push token was switched off by the brand logout")
+	}
+	if count := h.Count("push_tokens", bson.M{"token": deviceToken, "user_id": brand.ID, "is_active": true}); count != 0 {
+		t.Errorf("brand push token still active after its logout")
+	}
+
+	// Without a live session the switch demands the password
+	if _, err := auth.SwitchAccount(personalSession, brand.ID, ""); err == nil || !strings.Contains(err.Error(), "credentials required") {
+		t.Errorf("switch without session: got %v, want credentials required", err)
+	}
+	if _, err := auth.SwitchAccount(personalSession, brand.ID, "wrong password"); err == nil || !strings.Contains(err.Error(), "invalid credentials") {
+		t.Errorf("switch with wrong password: got %v, want invalid credentials", err)
+	}
+	if _, err := auth.SwitchAccount(personalSession, brand.ID, testutil.DefaultPassword); err != nil {
+		t.Errorf("switch with password: %v", err)
+	}
+
+	// Fill the device up to the limit
+	var extra []*models.User
+	for i := 2; i < models.MaxAccountsPerDeviceGroup; i++ {
+		user := h.CreateUser()
+		h.AddAccount(personalSession, user)
+		extra = append(extra, user)
+	}
+	overflow := h.CreateUser()
+	if _, err := auth.AddAccount(personalSession, models.AddAccountRequest{EmailOrUsername: overflow.Username, Password: testutil.DefaultPassword}); err == nil || !strings.Contains(err.Error(), "limit reached") {
+		t.Errorf("adding account %d: got %v, want limit reached", models.MaxAccountsPerDeviceGroup+1, err)
+	}
+
+	// Revoking one account signs out only that account on the device
+	if err := auth.LogoutAll(extra[0].ID); err != nil {
+		t.Fatalf("failed to revoke account: %v", err)
+	}
+	accounts, err = auth.GetDeviceAccounts(personalSession)
+	if err != nil {
+		t.Fatalf("failed to list device accounts: %v", err)
+	}
+	if len(accounts) != models.MaxAccountsPerDeviceGroup-1 {
+		t.Errorf("device accounts after revoking one: got %d, want %d", len(accounts), models.MaxAccountsPerDeviceGroup-1)
+	}
+
+	// Logging out everything on the device ends every session in the group
+	if err := auth.LogoutDevice(personalSession); err != nil {
+		t.Fatalf("failed to log out device: %v", err)
+	}
+	if _, err := auth.GetDeviceAccounts(personalSession); err == nil {
+		t.Errorf("device accounts still listed after logging out the device")
+	}
+	if count := h.Count("push_tokens", bson.M{"token": deviceToken, "is_active": true}); count != 0 {
+		t.Errorf("push tokens still active after logging out the device: %d", count)
+	}
+}
@@ -0,0 +1,486 @@
+// internal/services/digest_service.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"social-media-api/internal/emails"
+	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DigestCheckInterval is how often the digest job looks for users due a digest
+const DigestCheckInterval = time.Hour
+
+const (
+	// digestFrequency is the notification preference that opts a user into
+	// the activity digest
+	digestFrequency = "weekly"
+
+	digestUserBatch     = 200
+	digestFollowerNames = 2
+)
+
+// DigestPolicy decides who receives the activity digest email and how often
+type DigestPolicy struct {
+	Cadence       time.Duration // Time between two digests to the same user
+	InactiveAfter time.Duration // Users who logged in more recently than this get none
+	TopPosts      int           // Posts from the user's network listed in each digest
+}
+
+// DigestRunReport summarizes one run of the digest job
+type DigestRunReport struct {
+	UsersChecked int
+	Sent         int
+	Failed       int
+}
+
+// DigestService emails inactive users a summary of what they missed
+type DigestService struct {
+	db            *mongo.Database
+	emailService  *EmailService
+	policy        DigestPolicy
+	users         *mongo.Collection
+	preferences   *mongo.Collection
+	sends         *mongo.Collection
+	follows       *mongo.Collection
+	posts         *mongo.Collection
+	notifications *mongo.Collection
+	stopDigest    context.CancelFunc
+}
+
+func NewDigestService(db *mongo.Database, emailService *EmailService, policy DigestPolicy) *DigestService {
+	return &DigestService{
+		db:            db,
+		emailService:  emailService,
+		policy:        policy,
+		users:         db.Collection("users"),
+		preferences:   db.Collection("notification_preferences"),
+		sends:         db.Collection("digest_sends"),
+		follows:       db.Collection("follows"),
+		posts:         db.Collection("posts"),
+		notifications: db.Collection("notifications"),
+	}
+}
+
+// SendDueDigests emails a digest to every user who opted into it, hasn't
+// logged in within the inactivity threshold and had no digest within the
+// cadence. Users with nothing to report are skipped until the next run.
+func (ds *DigestService) SendDueDigests(ctx context.Context) (DigestRunReport, error) {
+	var report DigestRunReport
+
+	cursor, err := ds.preferences.Find(ctx, bson.M{
+		"digest_frequency": digestFrequency,
+		"email_enabled":    true,
+	}, options.Find().SetProjection(bson.M{"user_id": 1}))
+	if err != nil {
+		return report, err
+	}
+	defer cursor.Close(ctx)
+
+	batch := make([]primitive.ObjectID, 0, digestUserBatch)
+	for cursor.Next(ctx) {
+		var prefs struct {
+			UserID primitive.ObjectID `bson:"user_id"`
+		}
+		if err := cursor.Decode(&prefs); err != nil {
+			return report, err
+		}
+		batch = append(batch, prefs.UserID)
+		if len(batch) < digestUserBatch {
+			continue
+		}
+		if err := ds.sendBatch(ctx, batch, &report); err != nil {
+			return report, err
+		}
+		batch = batch[:0]
+	}
+	if err := cursor.Err(); err != nil {
+		return report, err
+	}
+
+	if len(batch) > 0 {
+		if err := ds.sendBatch(ctx, batch, &report); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// StartDigestJob runs SendDueDigests every interval until StopDigestJob is called
+func (ds *DigestService) StartDigestJob(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ds.stopDigest = cancel
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				report, err := ds.SendDueDigests(ctx)
+				if ctx.Err() != nil {
+					return
+				}
+				recordJobHeartbeat(ds.db, "activity_digest", interval, err)
+				if err != nil {
+					log.Printf("Activity digest run failed after %d users: %v", report.UsersChecked, err)
+				}
+				if report.Sent > 0 || report.Failed > 0 {
+					log.Printf("Sent %d activity digests (%d failed)", report.Sent, report.Failed)
+				}
+			}
+		}
+	}()
+}
+
+// StopDigestJob stops the periodic activity digest
+func (ds *DigestService) StopDigestJob() {
+	if ds.stopDigest != nil {
+		ds.stopDigest()
+	}
+}
+
+// Helper methods
+
+// sendBatch sends the digests due to a batch of opted-in users
+func (ds *DigestService) sendBatch(ctx context.Context, userIDs []primitive.ObjectID, report *DigestRunReport) error {
+	now := time.Now()
+	since := now.Add(-ds.policy.Cadence)
+
+	recent, err := ds.sends.Distinct(ctx, "user_id", bson.M{
+		"user_id":      bson.M{"$in": userIDs},
+		"last_sent_at": bson.M{"$gte": since},
+	})
+	if err != nil {
+		return err
+	}
+	due := make([]primitive.ObjectID, 0, len(userIDs))
+	sent := make(map[primitive.ObjectID]bool, len(recent))
+	for _, id := range recent {
+		if oid, ok := id.(primitive.ObjectID); ok {
+			sent[oid] = true
+		}
+	}
+	for _, id := range userIDs {
+		if !sent[id] {
+			due = append(due, id)
+		}
+	}
+	if len(due) == 0 {
+		return nil
+	}
+
+	// Email notifications can also be switched off in the account settings
+	cursor, err := ds.users.Find(ctx, repository.NotDeleted(bson.M{
+		"_id":          bson.M{"$in": due},
+		"is_active":    true,
+		"is_suspended": bson.M{"$ne": true},
+		"notification_settings.email_notifications": true,
+		"$or": []bson.M{
+			{"last_login_at": bson.M{"$lt": now.Add(-ds.policy.InactiveAfter)}},
+			{"last_login_at": bson.M{"$exists": false}},
+		},
+	}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return err
+	}
+
+	for i := range users {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		report.UsersChecked++
+
+		user := &users[i]
+		items, err := ds.compileDigest(ctx, user, since)
+		if err != nil {
+			log.Printf("Failed to compile activity digest for user %s: %v", user.ID.Hex(), err)
+			report.Failed++
+			continue
+		}
+		if len(items) == 0 {
+			continue
+		}
+
+		claimed, previous, err := ds.claimDigest(ctx, user.ID, now)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := ds.emailService.SendDigestEmail(user, items, digestPeriod(ds.policy.Cadence)); err != nil {
+			log.Printf("Failed to send activity digest to user %s: %v", user.ID.Hex(), err)
+			report.Failed++
+			// A suppressed address won't take the next attempt either
+			if !strings.Contains(err.Error(), "suppressed") {
+				ds.releaseDigest(ctx, user.ID, previous)
+			}
+			continue
+		}
+		report.Sent++
+	}
+
+	return nil
+}
+
+// compileDigest lists the user's new followers, the top posts from the
+// people they follow and their unread notifications since the given time
+func (ds *DigestService) compileDigest(ctx context.Context, user *models.User, since time.Time) ([]emails.DigestItem, error) {
+	var items []emails.DigestItem
+
+	followers, err := ds.newFollowersItem(ctx, user, since)
+	if err != nil {
+		return nil, err
+	}
+	if followers != nil {
+		items = append(items, *followers)
+	}
+
+	posts, err := ds.topNetworkPostItems(ctx, user, since)
+	if err != nil {
+		return nil, err
+	}
+	items = append(items, posts...)
+
+	unread, err := ds.notifications.CountDocuments(ctx, bson.M{
+		"recipient_id": user.ID,
+		"is_read":      false,
+		"$or": []bson.M{
+			{"expires_at": bson.M{"$exists": false}},
+			{"expires_at": bson.M{"$gt": time.Now()}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if unread > 0 {
+		items = append(items, emails.DigestItem{
+			Title:   pluralCount(unread, "unread notification"),
+			Summary: "Catch up on what you missed",
+			URL:     "/notifications",
+		})
+	}
+
+	return items, nil
+}
+
+// newFollowersItem summarizes the accepted follows since the given time
+func (ds *DigestService) newFollowersItem(ctx context.Context, user *models.User, since time.Time) (*emails.DigestItem, error) {
+	filter := bson.M{
+		"followee_id": user.ID,
+		"status":      models.FollowStatusAccepted,
+		"created_at":  bson.M{"$gte": since},
+	}
+	count, err := ds.follows.CountDocuments(ctx, filter)
+	if err != nil || count == 0 {
+		return nil, err
+	}
+
+	cursor, err := ds.follows.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(digestFollowerNames))
+	if err != nil {
+		return nil, err
+	}
+	var follows []models.Follow
+	if err := cursor.All(ctx, &follows); err != nil {
+		return nil, err
+	}
+
+	followerIDs := make([]primitive.ObjectID, 0, len(follows))
+	for _, follow := range follows {
+		followerIDs = append(followerIDs, follow.FollowerID)
+	}
+	usernames, err := ds.usernames(ctx, followerIDs)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(followerIDs))
+	for _, id := range followerIDs {
+		if name, ok := usernames[id]; ok {
+			names = append(names, name)
+		}
+	}
+
+	var summary string
+	others := count - int64(len(names))
+	switch {
+	case len(names) == 0:
+		summary = "See who started following you"
+	case others == 1:
+		summary = fmt.Sprintf("%s and 1 other followed you", strings.Join(names, ", "))
+	case others > 1:
+		summary = fmt.Sprintf("%s and %d others followed you", strings.Join(names, ", "), others)
+	default:
+		summary = fmt.Sprintf("%s followed you", strings.Join(names, " and "))
+	}
+
+	return &emails.DigestItem{
+		Title:   pluralCount(count, "new follower"),
+		Summary: summary,
+		URL:     "/users/" + user.ID.Hex() + "/followers",
+	}, nil
+}
+
+// topNetworkPostItems lists the most liked posts the user's follows
+// published since the given time
+func (ds *DigestService) topNetworkPostItems(ctx context.Context, user *models.User, since time.Time) ([]emails.DigestItem, error) {
+	if ds.policy.TopPosts <= 0 {
+		return nil, nil
+	}
+
+	followees, err := ds.follows.Distinct(ctx, "followee_id", bson.M{
+		"follower_id": user.ID,
+		"status":      models.FollowStatusAccepted,
+	})
+	if err != nil || len(followees) == 0 {
+		return nil, err
+	}
+
+	filter := repository.Where(bson.M{
+		"user_id":    bson.M{"$in": followees},
+		"created_at": bson.M{"$gte": since},
+		"visibility": bson.M{"$in": []models.PrivacyLevel{models.PrivacyPublic, models.PrivacyFriends}},
+	}).Published().NotHidden().Filter()
+
+	cursor, err := repository.ReadFrom(ctx, ds.posts).Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "likes_count", Value: -1}, {Key: "comments_count", Value: -1}}).
+		SetLimit(int64(ds.policy.TopPosts)))
+	if err != nil {
+		return nil, err
+	}
+	var posts []models.Post
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+
+	authorIDs := make([]primitive.ObjectID, 0, len(posts))
+	for _, post := range posts {
+		authorIDs = append(authorIDs, post.UserID)
+	}
+	usernames, err := ds.usernames(ctx, authorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]emails.DigestItem, 0, len(posts))
+	for _, post := range posts {
+		title := "A post from your network"
+		if name, ok := usernames[post.UserID]; ok {
+			title = name + " posted"
+		}
+		items = append(items, emails.DigestItem{
+			Title:   title,
+			Summary: activityPreview(post.Content),
+			URL:     "/posts/" + post.ID.Hex(),
+		})
+	}
+	return items, nil
+}
+
+// usernames maps user IDs to usernames
+func (ds *DigestService) usernames(ctx context.Context, userIDs []primitive.ObjectID) (map[primitive.ObjectID]string, error) {
+	names := make(map[primitive.ObjectID]string, len(userIDs))
+	if len(userIDs) == 0 {
+		return names, nil
+	}
+
+	cursor, err := ds.users.Find(ctx, bson.M{"_id": bson.M{"$in": userIDs}},
+		options.Find().SetProjection(bson.M{"username": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var users []struct {
+		ID       primitive.ObjectID `bson:"_id"`
+		Username string             `bson:"username"`
+	}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		names[user.ID] = user.Username
+	}
+	return names, nil
+}
+
+// claimDigest records a digest as sent unless another run sent one within
+// the cadence, so instances running the job don't both send. It returns the
+// previous send time for releaseDigest.
+func (ds *DigestService) claimDigest(ctx context.Context, userID primitive.ObjectID, now time.Time) (bool, *time.Time, error) {
+	var previous struct {
+		LastSentAt *time.Time `bson:"last_sent_at"`
+	}
+	err := ds.sends.FindOneAndUpdate(ctx,
+		bson.M{"user_id": userID, "last_sent_at": bson.M{"$lt": now.Add(-ds.policy.Cadence)}},
+		bson.M{
+			"$set": bson.M{"last_sent_at": now},
+			"$inc": bson.M{"sent_count": 1},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before),
+	).Decode(&previous)
+	switch {
+	case err == mongo.ErrNoDocuments:
+		return true, nil, nil // First digest for the user
+	case mongo.IsDuplicateKeyError(err):
+		return false, nil, nil // Sent within the cadence
+	case err != nil:
+		return false, nil, err
+	}
+	return true, previous.LastSentAt, nil
+}
+
+// releaseDigest undoes a claim whose email wasn't sent, so the next run tries again
+func (ds *DigestService) releaseDigest(ctx context.Context, userID primitive.ObjectID, previous *time.Time) {
+	var err error
+	if previous == nil {
+		_, err = ds.sends.DeleteOne(ctx, bson.M{"user_id": userID})
+	} else {
+		_, err = ds.sends.UpdateOne(ctx, bson.M{"user_id": userID}, bson.M{
+			"$set": bson.M{"last_sent_at": *previous},
+			"$inc": bson.M{"sent_count": -1},
+		})
+	}
+	if err != nil {
+		log.Printf("Failed to release activity digest claim for user %s: %v", userID.Hex(), err)
+	}
+}
+
+// digestPeriod names the cadence for the email's heading
+func digestPeriod(cadence time.Duration) string {
+	switch cadence {
+	case 24 * time.Hour:
+		return "daily"
+	case 7 * 24 * time.Hour:
+		return "weekly"
+	}
+	return ""
+}
+
+// pluralCount renders a count with its noun, pluralized with an s
+func pluralCount(n int64, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -3,14 +3,26 @@ package services
 
 import (
 	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"html/template"
 	"log"
 	"net/smtp"
+	"net/textproto"
 	"strings"
 	"time"
 
+	"social-media-api/internal/emails"
 	"social-media-api/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type EmailService struct {
@@ -20,7 +32,21 @@ type EmailService struct {
 	SMTPPassword string
 	FromEmail    string
 	FromName     string
-	Templates    map[string]*template.Template
+	templates    *emails.Registry
+	policy       EmailPolicy
+	suppressions *mongo.Collection
+	sends        *mongo.Collection
+}
+
+// EmailPolicy limits how much email goes out and signs the unsubscribe links
+// of emails recipients can opt out of
+type EmailPolicy struct {
+	RateWindow        time.Duration
+	PerRecipientLimit int64 // Emails one address can receive per window; 0 disables
+	GlobalLimit       int64 // Emails sent in total per window; 0 disables
+	UnsubscribeSecret string
+	AppURL            string // The web app's /unsubscribe page confirms footer links
+	APIURL            string // One-click List-Unsubscribe requests go straight to the API
 }
 
 type EmailData struct {
@@ -29,6 +55,10 @@ type EmailData struct {
 	Body        string
 	HTMLBody    string
 	Attachments []EmailAttachment
+
+	// ListUnsubscribe is a one-click unsubscribe URL (RFC 8058) for mail
+	// clients to offer next to the sender
+	ListUnsubscribe string
 }
 
 type EmailAttachment struct {
@@ -37,20 +67,19 @@ type EmailAttachment struct {
 	MimeType string
 }
 
-func NewEmailService(smtpHost, smtpPort, smtpUsername, smtpPassword, fromEmail, fromName string) *EmailService {
-	es := &EmailService{
+func NewEmailService(db *mongo.Database, smtpHost, smtpPort, smtpUsername, smtpPassword, fromEmail, fromName string, templates *emails.Registry, policy EmailPolicy) *EmailService {
+	return &EmailService{
 		SMTPHost:     smtpHost,
 		SMTPPort:     smtpPort,
 		SMTPUsername: smtpUsername,
 		SMTPPassword: smtpPassword,
 		FromEmail:    fromEmail,
 		FromName:     fromName,
-		Templates:    make(map[string]*template.Template),
+		templates:    templates,
+		policy:       policy,
+		suppressions: db.Collection("email_suppressions"),
+		sends:        db.Collection("email_sends"),
 	}
-
-	// Load email templates
-	es.loadTemplates()
-	return es
 }
 
 // SendEmail sends a basic email
@@ -80,284 +109,262 @@ func (es *EmailService) SendEmail(data EmailData) error {
 
 // SendWelcomeEmail sends welcome email to new users
 func (es *EmailService) SendWelcomeEmail(user *models.User, verificationToken string) error {
-	data := map[string]interface{}{
-		"User":              user,
+	return es.sendTemplate(user.Email, emails.TypeWelcome, user.Language, map[string]interface{}{
+		"Name":              emailName(user),
 		"VerificationToken": verificationToken,
-		"AppName":           "Social Media App",
-		"SupportEmail":      "support@example.com",
-		"Year":              time.Now().Year(),
-	}
-
-	htmlBody, err := es.renderTemplate("welcome", data)
-	if err != nil {
-		return err
-	}
-
-	emailData := EmailData{
-		To:       []string{user.Email},
-		Subject:  "Welcome to Social Media App!",
-		HTMLBody: htmlBody,
-		Body:     es.generatePlainTextVersion(htmlBody),
-	}
-
-	return es.SendEmail(emailData)
+	})
 }
 
 // SendEmailVerification sends email verification
 func (es *EmailService) SendEmailVerification(user *models.User, verificationToken string) error {
-	data := map[string]interface{}{
-		"User":              user,
+	return es.sendTemplate(user.Email, emails.TypeEmailVerification, user.Language, map[string]interface{}{
+		"Name":              emailName(user),
 		"VerificationToken": verificationToken,
-		"AppName":           "Social Media App",
-		"Year":              time.Now().Year(),
-	}
-
-	htmlBody, err := es.renderTemplate("email_verification", data)
-	if err != nil {
-		return err
-	}
-
-	emailData := EmailData{
-		To:       []string{user.Email},
-		Subject:  "Verify Your Email Address",
-		HTMLBody: htmlBody,
-		Body:     es.generatePlainTextVersion(htmlBody),
-	}
-
-	return es.SendEmail(emailData)
+	})
 }
 
 // SendPasswordResetEmail sends password reset email
 func (es *EmailService) SendPasswordResetEmail(user *models.User, resetToken string) error {
-	data := map[string]interface{}{
-		"User":       user,
+	return es.sendTemplate(user.Email, emails.TypePasswordReset, user.Language, map[string]interface{}{
+		"Name":       emailName(user),
 		"ResetToken": resetToken,
-		"AppName":    "Social Media App",
-		"Year":       time.Now().Year(),
-	}
-
-	htmlBody, err := es.renderTemplate("password_reset", data)
-	if err != nil {
-		return err
-	}
-
-	emailData := EmailData{
-		To:       []string{user.Email},
-		Subject:  "Reset Your Password",
-		HTMLBody: htmlBody,
-		Body:     es.generatePlainTextVersion(htmlBody),
-	}
-
-	return es.SendEmail(emailData)
+	})
 }
 
 // SendPasswordChangeConfirmation sends password change confirmation
 func (es *EmailService) SendPasswordChangeConfirmation(user *models.User) error {
-	data := map[string]interface{}{
-		"User":    user,
-		"AppName": "Social Media App",
-		"Year":    time.Now().Year(),
-	}
-
-	htmlBody, err := es.renderTemplate("password_changed", data)
-	if err != nil {
-		return err
-	}
+	return es.sendTemplate(user.Email, emails.TypePasswordChanged, user.Language, map[string]interface{}{
+		"Name": emailName(user),
+	})
+}
 
-	emailData := EmailData{
-		To:       []string{user.Email},
-		Subject:  "Your Password Has Been Changed",
-		HTMLBody: htmlBody,
-		Body:     es.generatePlainTextVersion(htmlBody),
-	}
+// SendNotificationEmail sends an email copy of a notification to its recipient
+func (es *EmailService) SendNotificationEmail(recipient *models.User, notification *models.Notification) error {
+	return es.sendTemplate(recipient.Email, emails.TypeNotification, recipient.Language, map[string]interface{}{
+		"Title":      notification.Title,
+		"Message":    notification.Message,
+		"ActionText": notification.ActionText,
+		"TargetURL":  notification.TargetURL,
+	})
+}
 
-	return es.SendEmail(emailData)
+// SendDigestEmail sends daily/weekly digest emails
+func (es *EmailService) SendDigestEmail(user *models.User, items []emails.DigestItem, period string) error {
+	return es.sendTemplate(user.Email, emails.TypeDigest, user.Language, map[string]interface{}{
+		"Name":   emailName(user),
+		"Period": period,
+		"Items":  items,
+	})
 }
 
-// SendNotificationEmail sends notification emails
-func (es *EmailService) SendNotificationEmail(notification *models.Notification) error {
-	// Get recipient user info (this would be passed or fetched)
-	recipientEmail := "user@example.com" // This should be fetched from user service
+// SendExportReadyEmail tells a user their data export can be downloaded
+func (es *EmailService) SendExportReadyEmail(user *models.User, downloadURL string, expiresAt time.Time) error {
+	return es.sendTemplate(user.Email, emails.TypeExportReady, user.Language, map[string]interface{}{
+		"Name":        emailName(user),
+		"DownloadURL": downloadURL,
+		"ExpiresAt":   formatEmailTime(expiresAt),
+	})
+}
 
-	data := map[string]interface{}{
-		"Notification": notification,
-		"AppName":      "Social Media App",
-		"Year":         time.Now().Year(),
-	}
+// SendAccountSuspensionEmail sends account suspension notification
+func (es *EmailService) SendAccountSuspensionEmail(user *models.User, reason string) error {
+	return es.sendTemplate(user.Email, emails.TypeAccountSuspended, user.Language, map[string]interface{}{
+		"Name":   emailName(user),
+		"Reason": reason,
+	})
+}
 
-	htmlBody, err := es.renderTemplate("notification", data)
-	if err != nil {
-		return err
-	}
+// SendAccountReactivatedEmail welcomes back a user whose account reactivated on schedule
+func (es *EmailService) SendAccountReactivatedEmail(user *models.User) error {
+	return es.sendTemplate(user.Email, emails.TypeAccountReactivated, user.Language, map[string]interface{}{
+		"Name": emailName(user),
+	})
+}
 
-	emailData := EmailData{
-		To:       []string{recipientEmail},
-		Subject:  notification.Title,
-		HTMLBody: htmlBody,
-		Body:     es.generatePlainTextVersion(htmlBody),
-	}
+// SendBroadcastEmail sends an admin announcement to a user
+func (es *EmailService) SendBroadcastEmail(user *models.User, title, message string) error {
+	return es.sendTemplate(user.Email, emails.TypeBroadcast, user.Language, map[string]interface{}{
+		"Name":    emailName(user),
+		"Title":   title,
+		"Message": message,
+	})
+}
 
-	return es.SendEmail(emailData)
+// SendGroupInviteEmail sends group invitation email
+func (es *EmailService) SendGroupInviteEmail(invitee *models.User, groupName, groupURL string, inviter *models.User) error {
+	return es.sendTemplate(invitee.Email, emails.TypeGroupInvite, invitee.Language, map[string]interface{}{
+		"Name":        emailName(invitee),
+		"InviterName": emailName(inviter),
+		"GroupName":   groupName,
+		"GroupURL":    groupURL,
+	})
 }
 
-// SendDigestEmail sends daily/weekly digest emails
-func (es *EmailService) SendDigestEmail(user *models.User, digestData interface{}, digestType string) error {
-	var subject string
-	switch digestType {
-	case "daily":
-		subject = "Your Daily Digest"
-	case "weekly":
-		subject = "Your Weekly Digest"
-	default:
-		subject = "Your Digest"
-	}
-
-	data := map[string]interface{}{
-		"User":       user,
-		"DigestData": digestData,
-		"DigestType": digestType,
-		"AppName":    "Social Media App",
-		"Year":       time.Now().Year(),
-	}
-
-	htmlBody, err := es.renderTemplate("digest", data)
-	if err != nil {
-		return err
-	}
+// SendEventInviteEmail sends event invitation email
+func (es *EmailService) SendEventInviteEmail(invitee *models.User, eventTitle, eventURL string, inviter *models.User) error {
+	return es.sendTemplate(invitee.Email, emails.TypeEventInvite, invitee.Language, map[string]interface{}{
+		"Name":        emailName(invitee),
+		"InviterName": emailName(inviter),
+		"EventTitle":  eventTitle,
+		"EventURL":    eventURL,
+	})
+}
 
-	emailData := EmailData{
-		To:       []string{user.Email},
-		Subject:  subject,
-		HTMLBody: htmlBody,
-		Body:     es.generatePlainTextVersion(htmlBody),
-	}
+// SendEventReminderEmail sends event reminder email
+func (es *EmailService) SendEventReminderEmail(user *models.User, eventTitle, eventURL string, startsAt time.Time) error {
+	return es.sendTemplate(user.Email, emails.TypeEventReminder, user.Language, map[string]interface{}{
+		"Name":       emailName(user),
+		"EventTitle": eventTitle,
+		"StartsAt":   formatEmailTime(startsAt),
+		"EventURL":   eventURL,
+	})
+}
 
-	return es.SendEmail(emailData)
+// SendSecurityAlertEmail sends security alert emails
+func (es *EmailService) SendSecurityAlertEmail(user *models.User, alertType, details string) error {
+	return es.sendTemplate(user.Email, emails.TypeSecurityAlert, user.Language, map[string]interface{}{
+		"Name":      emailName(user),
+		"AlertType": alertType,
+		"Details":   details,
+	})
 }
 
-// SendAccountSuspensionEmail sends account suspension notification
-func (es *EmailService) SendAccountSuspensionEmail(user *models.User, reason string) error {
-	data := map[string]interface{}{
-		"User":         user,
-		"Reason":       reason,
-		"AppName":      "Social Media App",
-		"SupportEmail": "support@example.com",
-		"Year":         time.Now().Year(),
+// SuppressEmail puts an address on the suppression list for a reason.
+// Suppressing it again for the same reason updates the entry.
+func (es *EmailService) SuppressEmail(email string, reason models.EmailSuppressionReason, source, detail string, addedBy *primitive.ObjectID) (*models.EmailSuppression, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	email = models.NormalizeEmailAddress(email)
+	if email == "" {
+		return nil, errors.New("invalid email address")
+	}
+	if !reason.IsValid() {
+		return nil, errors.New("invalid suppression reason")
 	}
 
-	htmlBody, err := es.renderTemplate("account_suspended", data)
-	if err != nil {
-		return err
+	now := time.Now()
+	set := bson.M{"source": source, "updated_at": now}
+	if detail != "" {
+		set["detail"] = detail
+	}
+	if addedBy != nil {
+		set["added_by"] = *addedBy
+	}
+	update := bson.M{
+		"$set":         set,
+		"$setOnInsert": bson.M{"created_at": now},
 	}
 
-	emailData := EmailData{
-		To:       []string{user.Email},
-		Subject:  "Your Account Has Been Suspended",
-		HTMLBody: htmlBody,
-		Body:     es.generatePlainTextVersion(htmlBody),
+	var entry models.EmailSuppression
+	err := es.suppressions.FindOneAndUpdate(ctx, bson.M{"email": email, "reason": reason}, update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&entry)
+	if err != nil {
+		return nil, err
 	}
 
-	return es.SendEmail(emailData)
+	log.Printf("Email address %s suppressed (%s, %s)", email, reason, source)
+	return &entry, nil
 }
 
-// SendGroupInviteEmail sends group invitation email
-func (es *EmailService) SendGroupInviteEmail(invitee *models.User, group interface{}, inviter *models.User) error {
-	data := map[string]interface{}{
-		"Invitee": invitee,
-		"Group":   group,
-		"Inviter": inviter,
-		"AppName": "Social Media App",
-		"Year":    time.Now().Year(),
-	}
+// RemoveSuppression takes an entry off the suppression list, so the address
+// receives the emails it blocked again
+func (es *EmailService) RemoveSuppression(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-	htmlBody, err := es.renderTemplate("group_invite", data)
+	result, err := es.suppressions.DeleteOne(ctx, bson.M{"_id": id})
 	if err != nil {
 		return err
 	}
-
-	emailData := EmailData{
-		To:       []string{invitee.Email},
-		Subject:  "You've been invited to join a group",
-		HTMLBody: htmlBody,
-		Body:     es.generatePlainTextVersion(htmlBody),
+	if result.DeletedCount == 0 {
+		return errors.New("suppression not found")
 	}
-
-	return es.SendEmail(emailData)
+	return nil
 }
 
-// SendEventInviteEmail sends event invitation email
-func (es *EmailService) SendEventInviteEmail(invitee *models.User, event interface{}, inviter *models.User) error {
-	data := map[string]interface{}{
-		"Invitee": invitee,
-		"Event":   event,
-		"Inviter": inviter,
-		"AppName": "Social Media App",
-		"Year":    time.Now().Year(),
-	}
+// ListSuppressions returns the suppression list, most recent first,
+// optionally for one address or reason
+func (es *EmailService) ListSuppressions(email string, reason models.EmailSuppressionReason, limit, skip int) ([]models.EmailSuppression, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	htmlBody, err := es.renderTemplate("event_invite", data)
-	if err != nil {
-		return err
+	filter := bson.M{}
+	if email != "" {
+		filter["email"] = models.NormalizeEmailAddress(email)
 	}
-
-	emailData := EmailData{
-		To:       []string{invitee.Email},
-		Subject:  "You've been invited to an event",
-		HTMLBody: htmlBody,
-		Body:     es.generatePlainTextVersion(htmlBody),
+	if reason != "" {
+		if !reason.IsValid() {
+			return nil, 0, errors.New("invalid suppression reason")
+		}
+		filter["reason"] = reason
 	}
 
-	return es.SendEmail(emailData)
-}
-
-// SendEventReminderEmail sends event reminder email
-func (es *EmailService) SendEventReminderEmail(user *models.User, event interface{}) error {
-	data := map[string]interface{}{
-		"User":    user,
-		"Event":   event,
-		"AppName": "Social Media App",
-		"Year":    time.Now().Year(),
+	total, err := es.suppressions.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	htmlBody, err := es.renderTemplate("event_reminder", data)
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit)).
+		SetSkip(int64(skip))
+
+	cursor, err := es.suppressions.Find(ctx, filter, opts)
 	if err != nil {
-		return err
+		return nil, 0, err
 	}
+	defer cursor.Close(ctx)
 
-	emailData := EmailData{
-		To:       []string{user.Email},
-		Subject:  "Event Reminder",
-		HTMLBody: htmlBody,
-		Body:     es.generatePlainTextVersion(htmlBody),
+	entries := []models.EmailSuppression{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, 0, err
 	}
 
-	return es.SendEmail(emailData)
+	return entries, total, nil
 }
 
-// SendSecurityAlertEmail sends security alert emails
-func (es *EmailService) SendSecurityAlertEmail(user *models.User, alertType, details string) error {
-	data := map[string]interface{}{
-		"User":         user,
-		"AlertType":    alertType,
-		"Details":      details,
-		"AppName":      "Social Media App",
-		"SupportEmail": "support@example.com",
-		"Year":         time.Now().Year(),
+// GetUnsubscribeStatus checks an unsubscribe token and reports the address
+// it was issued for and whether that address already unsubscribed
+func (es *EmailService) GetUnsubscribeStatus(token string) (string, bool, error) {
+	email, err := es.verifyUnsubscribeToken(token)
+	if err != nil {
+		return "", false, err
 	}
 
-	htmlBody, err := es.renderTemplate("security_alert", data)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	count, err := es.suppressions.CountDocuments(ctx, bson.M{"email": email, "reason": models.SuppressionUnsubscribe})
 	if err != nil {
-		return err
+		return "", false, err
+	}
+	return email, count > 0, nil
+}
+
+// Unsubscribe suppresses the address an unsubscribe token was issued for.
+// It stops notifications, digests and announcements; account emails such as
+// password resets are still sent.
+func (es *EmailService) Unsubscribe(token string) (string, error) {
+	email, err := es.verifyUnsubscribeToken(token)
+	if err != nil {
+		return "", err
 	}
 
-	emailData := EmailData{
-		To:       []string{user.Email},
-		Subject:  "Security Alert - " + alertType,
-		HTMLBody: htmlBody,
-		Body:     es.generatePlainTextVersion(htmlBody),
+	if _, err := es.SuppressEmail(email, models.SuppressionUnsubscribe, models.SuppressionSourceUnsubscribe, "", nil); err != nil {
+		return "", err
 	}
+	return email, nil
+}
+
+// PreviewTemplate renders an email type with its sample data, without sending it
+func (es *EmailService) PreviewTemplate(emailType, language string) (*emails.Rendered, error) {
+	return es.templates.Preview(emailType, language)
+}
 
-	return es.SendEmail(emailData)
+// GetTemplateTypes lists the email types with their variables and languages
+func (es *EmailService) GetTemplateTypes() []emails.TypeInfo {
+	return es.templates.Types()
 }
 
 // Helper methods
@@ -369,6 +376,10 @@ func (es *EmailService) buildMessage(data EmailData) string {
 	msg.WriteString(fmt.Sprintf("From: %s <%s>\r\n", es.FromName, es.FromEmail))
 	msg.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(data.To, ",")))
 	msg.WriteString(fmt.Sprintf("Subject: %s\r\n", data.Subject))
+	if data.ListUnsubscribe != "" {
+		msg.WriteString(fmt.Sprintf("List-Unsubscribe: <%s>\r\n", data.ListUnsubscribe))
+		msg.WriteString("List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n")
+	}
 	msg.WriteString("MIME-Version: 1.0\r\n")
 
 	if data.HTMLBody != "" {
@@ -402,19 +413,169 @@ func (es *EmailService) buildMessage(data EmailData) string {
 	return msg.String()
 }
 
-func (es *EmailService) renderTemplate(templateName string, data interface{}) (string, error) {
-	tmpl, exists := es.Templates[templateName]
-	if !exists {
-		return "", fmt.Errorf("template %s not found", templateName)
+// sendTemplate renders an email template in the recipient's language and
+// sends it, unless the address is suppressed or a rate limit is reached.
+// Emails recipients can opt out of carry their own unsubscribe link.
+func (es *EmailService) sendTemplate(to, emailType, language string, vars map[string]interface{}) error {
+	transactional := !es.templates.Unsubscribable(emailType)
+	if err := es.checkSendAllowed(to, transactional); err != nil {
+		log.Printf("Not sending %s email to %s: %v", emailType, to, err)
+		return err
+	}
+
+	var unsubscribeURL, oneClickURL string
+	if !transactional {
+		token := es.unsubscribeToken(to)
+		unsubscribeURL = strings.TrimRight(es.policy.AppURL, "/") + "/unsubscribe?token=" + token
+		oneClickURL = strings.TrimRight(es.policy.APIURL, "/") + "/api/v1/email/unsubscribe?token=" + token
 	}
 
-	var buf bytes.Buffer
-	err := tmpl.Execute(&buf, data)
+	rendered, err := es.templates.Render(emailType, language, vars, unsubscribeURL)
 	if err != nil {
-		return "", err
+		log.Printf("Failed to render %s email: %v", emailType, err)
+		return err
+	}
+
+	err = es.SendEmail(EmailData{
+		To:              []string{to},
+		Subject:         rendered.Subject,
+		HTMLBody:        rendered.HTML,
+		Body:            rendered.Text,
+		ListUnsubscribe: oneClickURL,
+	})
+	if err != nil {
+		es.recordBounce(to, err)
+		return err
 	}
 
-	return buf.String(), nil
+	es.recordSend(to, emailType)
+	return nil
+}
+
+// checkSendAllowed refuses addresses on the suppression list and sends over
+// the rate limits. Transactional emails only stop for hard bounces.
+func (es *EmailService) checkSendAllowed(to string, transactional bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	email := models.NormalizeEmailAddress(to)
+	filter := bson.M{"email": email}
+	if transactional {
+		filter["reason"] = models.SuppressionHardBounce
+	}
+
+	var entry models.EmailSuppression
+	err := es.suppressions.FindOne(ctx, filter).Decode(&entry)
+	if err == nil {
+		return fmt.Errorf("email address is suppressed (%s)", entry.Reason)
+	}
+	if err != mongo.ErrNoDocuments {
+		return err
+	}
+
+	if es.policy.RateWindow <= 0 {
+		return nil
+	}
+	since := bson.M{"$gte": time.Now().Add(-es.policy.RateWindow)}
+
+	if es.policy.PerRecipientLimit > 0 {
+		count, err := es.sends.CountDocuments(ctx, bson.M{"email": email, "created_at": since},
+			options.Count().SetLimit(es.policy.PerRecipientLimit))
+		if err != nil {
+			return err
+		}
+		if count >= es.policy.PerRecipientLimit {
+			return fmt.Errorf("email rate limit exceeded: at most %d emails to one address every %s", es.policy.PerRecipientLimit, es.policy.RateWindow)
+		}
+	}
+
+	if es.policy.GlobalLimit > 0 {
+		count, err := es.sends.CountDocuments(ctx, bson.M{"created_at": since},
+			options.Count().SetLimit(es.policy.GlobalLimit))
+		if err != nil {
+			return err
+		}
+		if count >= es.policy.GlobalLimit {
+			return fmt.Errorf("email rate limit exceeded: at most %d emails every %s", es.policy.GlobalLimit, es.policy.RateWindow)
+		}
+	}
+
+	return nil
+}
+
+// recordSend logs a sent email for the rate limits. The log expires with the window.
+func (es *EmailService) recordSend(to, emailType string) {
+	if es.policy.RateWindow <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	_, err := es.sends.InsertOne(ctx, bson.M{
+		"email":      models.NormalizeEmailAddress(to),
+		"type":       emailType,
+		"created_at": now,
+		"expires_at": now.Add(es.policy.RateWindow),
+	})
+	if err != nil {
+		log.Printf("Failed to log %s email to %s: %v", emailType, to, err)
+	}
+}
+
+// recordBounce suppresses the address when the mail server rejected it
+// permanently: unknown mailbox, user not local or a malformed address
+func (es *EmailService) recordBounce(to string, err error) {
+	var smtpErr *textproto.Error
+	if !errors.As(err, &smtpErr) {
+		return
+	}
+	switch smtpErr.Code {
+	case 550, 551, 553:
+		if _, err := es.SuppressEmail(to, models.SuppressionHardBounce, models.SuppressionSourceSMTP, smtpErr.Error(), nil); err != nil {
+			log.Printf("Failed to suppress bounced address %s: %v", to, err)
+		}
+	}
+}
+
+// unsubscribeToken signs an address so unsubscribe links work without
+// logging in. The address travels in the token, base64url encoded.
+func (es *EmailService) unsubscribeToken(email string) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(models.NormalizeEmailAddress(email)))
+	return payload + "." + es.signUnsubscribe(payload)
+}
+
+func (es *EmailService) verifyUnsubscribeToken(token string) (string, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(es.signUnsubscribe(payload))) {
+		return "", errors.New("invalid unsubscribe token")
+	}
+
+	email, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil || len(email) == 0 {
+		return "", errors.New("invalid unsubscribe token")
+	}
+	return string(email), nil
+}
+
+func (es *EmailService) signUnsubscribe(payload string) string {
+	mac := hmac.New(sha256.New, []byte(es.policy.UnsubscribeSecret))
+	mac.Write([]byte("unsubscribe:" + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// emailName returns the name to greet a user by
+func emailName(user *models.User) string {
+	if user.FirstName != "" {
+		return user.FirstName
+	}
+	return user.Username
+}
+
+// formatEmailTime formats a time for display in an email
+func formatEmailTime(t time.Time) string {
+	return t.UTC().Format("January 2, 2006 15:04 UTC")
 }
 
 func (es *EmailService) generatePlainTextVersion(htmlBody string) string {
@@ -449,136 +610,3 @@ func (es *EmailService) generatePlainTextVersion(htmlBody string) string {
 
 	return strings.Join(cleanLines, "\n")
 }
-
-func (es *EmailService) loadTemplates() {
-	// Load email templates
-	// In production, these would be loaded from files or embedded resources
-
-	// Welcome email template
-	welcomeTemplate := `
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <title>Welcome to {{.AppName}}</title>
-</head>
-<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
-    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-        <h1 style="color: #4CAF50;">Welcome to {{.AppName}}!</h1>
-        <p>Hi {{.User.FirstName}},</p>
-        <p>Welcome to {{.AppName}}! We're excited to have you join our community.</p>
-        <p>To get started, please verify your email address by clicking the button below:</p>
-        <div style="text-align: center; margin: 30px 0;">
-            <a href="https://yourapp.com/verify-email?token={{.VerificationToken}}" 
-               style="background-color: #4CAF50; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; display: inline-block;">
-                Verify Email
-            </a>
-        </div>
-        <p>If the button doesn't work, you can copy and paste this link into your browser:</p>
-        <p style="word-break: break-all;">https://yourapp.com/verify-email?token={{.VerificationToken}}</p>
-        <p>If you have any questions, feel free to contact us at {{.SupportEmail}}.</p>
-        <p>Best regards,<br>The {{.AppName}} Team</p>
-        <hr>
-        <p style="font-size: 12px; color: #666;">© {{.Year}} {{.AppName}}. All rights reserved.</p>
-    </div>
-</body>
-</html>`
-
-	// Email verification template
-	emailVerificationTemplate := `
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <title>Verify Your Email</title>
-</head>
-<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
-    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-        <h1 style="color: #2196F3;">Verify Your Email Address</h1>
-        <p>Hi {{.User.FirstName}},</p>
-        <p>Please verify your email address by clicking the button below:</p>
-        <div style="text-align: center; margin: 30px 0;">
-            <a href="https://yourapp.com/verify-email?token={{.VerificationToken}}" 
-               style="background-color: #2196F3; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; display: inline-block;">
-                Verify Email
-            </a>
-        </div>
-        <p>This link will expire in 24 hours for security reasons.</p>
-        <p>If you didn't request this verification, please ignore this email.</p>
-        <p>Best regards,<br>The {{.AppName}} Team</p>
-        <hr>
-        <p style="font-size: 12px; color: #666;">© {{.Year}} {{.AppName}}. All rights reserved.</p>
-    </div>
-</body>
-</html>`
-
-	// Password reset template
-	passwordResetTemplate := `
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <title>Reset Your Password</title>
-</head>
-<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
-    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-        <h1 style="color: #FF9800;">Reset Your Password</h1>
-        <p>Hi {{.User.FirstName}},</p>
-        <p>We received a request to reset your password. Click the button below to create a new password:</p>
-        <div style="text-align: center; margin: 30px 0;">
-            <a href="https://yourapp.com/reset-password?token={{.ResetToken}}" 
-               style="background-color: #FF9800; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; display: inline-block;">
-                Reset Password
-            </a>
-        </div>
-        <p>This link will expire in 1 hour for security reasons.</p>
-        <p>If you didn't request a password reset, please ignore this email or contact us if you have concerns.</p>
-        <p>Best regards,<br>The {{.AppName}} Team</p>
-        <hr>
-        <p style="font-size: 12px; color: #666;">© {{.Year}} {{.AppName}}. All rights reserved.</p>
-    </div>
-</body>
-</html>`
-
-	// Notification template
-	notificationTemplate := `
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <title>{{.Notification.Title}}</title>
-</head>
-<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
-    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
-        <h1 style="color: #9C27B0;">{{.Notification.Title}}</h1>
-        <p>{{.Notification.Message}}</p>
-        {{if .Notification.ActionText}}
-        <div style="text-align: center; margin: 30px 0;">
-            <a href="https://yourapp.com{{.Notification.TargetURL}}" 
-               style="background-color: #9C27B0; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; display: inline-block;">
-                {{.Notification.ActionText}}
-            </a>
-        </div>
-        {{end}}
-        <p>Best regards,<br>The {{.AppName}} Team</p>
-        <hr>
-        <p style="font-size: 12px; color: #666;">© {{.Year}} {{.AppName}}. All rights reserved.</p>
-    </div>
-</body>
-</html>`
-
-	// Parse and store templates
-	es.Templates["welcome"] = template.Must(template.New("welcome").Parse(welcomeTemplate))
-	es.Templates["email_verification"] = template.Must(template.New("email_verification").Parse(emailVerificationTemplate))
-	es.Templates["password_reset"] = template.Must(template.New("password_reset").Parse(passwordResetTemplate))
-	es.Templates["notification"] = template.Must(template.New("notification").Parse(notificationTemplate))
-
-	// Add more templates as needed
-	es.Templates["password_changed"] = es.Templates["notification"]  // Reuse notification template
-	es.Templates["account_suspended"] = es.Templates["notification"] // Reuse notification template
-	es.Templates["group_invite"] = es.Templates["notification"]      // Reuse notification template
-	es.Templates["event_invite"] = es.Templates["notification"]      // Reuse notification template
-	es.Templates["event_reminder"] = es.Templates["notification"]    // Reuse notification template
-	es.Templates["security_alert"] = es.Templates["notification"]    // Reuse notification template
-	es.Templates["digest"] = es.Templates["notification"]            // Reuse notification template
-}
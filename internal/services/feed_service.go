@@ -4,12 +4,17 @@ package services
 import (
 	"context"
 	"fmt"
+	"log"
 	"math"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"social-media-api/internal/config"
 	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+	"social-media-api/internal/translation"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -23,7 +28,60 @@ type FeedService struct {
 	followCollection      *mongo.Collection
 	interactionCollection *mongo.Collection
 	feedCacheCollection   *mongo.Collection
+	engagementCollection  *mongo.Collection
+	injectionCollection   *mongo.Collection
 	db                    *mongo.Database
+	boosts                *BoostedPostService // nil disables sponsored slots
+	injection             FeedInjectionPolicy
+	defaultAlgorithm      string // Serves users without a feed algorithm setting
+	networkTrending       NetworkTrendingPolicy
+
+	networkMu    sync.Mutex
+	networkCache map[primitive.ObjectID]networkTrendingEntry // User ID → cached network trending section
+}
+
+// Where a feed item came from. Injected items are the recommended and
+// sponsored ones placed between the organic items.
+const (
+	FeedSourceOrganic     = "organic"
+	FeedSourceRecommended = "recommended"
+	FeedSourceSponsored   = "sponsored"
+)
+
+// recommendationCandidateLimit caps the posts loaded to fill the recommended
+// slots of one feed page
+const recommendationCandidateLimit = 50
+
+const (
+	networkTrendingCandidates = 500 // Most engaged posts of each engagement kind considered for the network section
+	networkEngagedByPreview   = 3   // Followed accounts named on each network trending item
+)
+
+// NetworkTrendingPolicy controls the "trending in your network" section:
+// posts that at least MinEngagers accounts the user follows liked, commented
+// on or reposted within Window. Limit 0 disables the section.
+type NetworkTrendingPolicy struct {
+	Window      time.Duration
+	MinEngagers int
+	Limit       int
+	CacheTTL    time.Duration // How long each user's section is cached
+}
+
+// NetworkTrendingContext says why a post trends in the user's network
+type NetworkTrendingContext struct {
+	Engagers  int                   `json:"engagers"` // Followed accounts that engaged with the post
+	Likes     int64                 `json:"likes"`
+	Comments  int64                 `json:"comments"`
+	Reposts   int64                 `json:"reposts"`
+	EngagedBy []models.UserResponse `json:"engaged_by"` // The followed accounts that engaged most
+	Label     string                `json:"label"`
+}
+
+// FeedInjectionPolicy controls injecting non-followed posts into home feeds
+type FeedInjectionPolicy struct {
+	Interval      int           // Every Interval-th item is injected; 0 disables injection
+	Slots         []string      // FeedSourceRecommended and FeedSourceSponsored, in the order slots take turns
+	SessionWindow time.Duration // An injected post isn't injected again for the user within this window
 }
 
 type FeedItem struct {
@@ -33,6 +91,17 @@ type FeedItem struct {
 	TimeAgo       string         `json:"time_ago" bson:"time_ago"`
 	IsPromoted    bool           `json:"is_promoted" bson:"is_promoted"`
 	PromotionInfo *PromotionInfo `json:"promotion_info,omitempty" bson:"promotion_info,omitempty"`
+	// Sponsored marks a boosted slot. Clients label it as sponsored and send
+	// BoostID with its impressions and when opening the post (?boost_id=).
+	Sponsored bool   `json:"sponsored" bson:"-"`
+	BoostID   string `json:"boost_id,omitempty" bson:"-"`
+	// SensitiveHidden asks clients to cover the post with its content warning
+	// until the user taps to reveal it
+	SensitiveHidden bool `json:"sensitive_hidden,omitempty" bson:"-"`
+	// Source is FeedSourceOrganic, FeedSourceRecommended or FeedSourceSponsored
+	Source string `json:"source" bson:"-"`
+	// Network explains items of the network trending section
+	Network *NetworkTrendingContext `json:"network,omitempty" bson:"-"`
 }
 
 type PromotionInfo struct {
@@ -72,34 +141,117 @@ type FeedAlgorithmWeights struct {
 	DiversityWeight    float64 `json:"diversity_weight"`
 }
 
-func NewFeedService() *FeedService {
+func NewFeedService(boosts *BoostedPostService, injection FeedInjectionPolicy, defaultAlgorithm string, networkTrending NetworkTrendingPolicy) *FeedService {
+	if !models.IsValidFeedAlgorithm(defaultAlgorithm) {
+		defaultAlgorithm = models.FeedAlgorithmStandard
+	}
+
 	return &FeedService{
 		postCollection:        config.DB.Collection("posts"),
 		userCollection:        config.DB.Collection("users"),
 		followCollection:      config.DB.Collection("follows"),
 		interactionCollection: config.DB.Collection("user_interactions"),
 		feedCacheCollection:   config.DB.Collection("feed_cache"),
+		engagementCollection:  config.DB.Collection("content_engagements"),
+		injectionCollection:   config.DB.Collection("feed_injections"),
 		db:                    config.DB,
+		boosts:                boosts,
+		injection:             injection,
+		defaultAlgorithm:      defaultAlgorithm,
+		networkTrending:       networkTrending,
+		networkCache:          make(map[primitive.ObjectID]networkTrendingEntry),
+	}
+}
+
+// ResolveFeedAlgorithm picks the algorithm serving a user's feed request and
+// where it came from: the requested algorithm when valid, else the user's
+// setting, else the configured default. Anonymous requests skip the setting.
+func (fs *FeedService) ResolveFeedAlgorithm(userID primitive.ObjectID, requested string) (string, string) {
+	if models.IsValidFeedAlgorithm(requested) {
+		return requested, models.FeedAlgorithmSourceRequest
+	}
+
+	if !userID.IsZero() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		var user struct {
+			FeedAlgorithm string `bson:"feed_algorithm"`
+		}
+		opts := options.FindOne().SetProjection(bson.M{"feed_algorithm": 1})
+		if err := fs.userCollection.FindOne(ctx, bson.M{"_id": userID}, opts).Decode(&user); err == nil && models.IsValidFeedAlgorithm(user.FeedAlgorithm) {
+			return user.FeedAlgorithm, models.FeedAlgorithmSourceSetting
+		}
 	}
+
+	return fs.defaultAlgorithm, models.FeedAlgorithmSourceDefault
+}
+
+// RecordFeedServed logs which algorithm served a feed request so algorithms
+// can be compared. The write happens in the background.
+func (fs *FeedService) RecordFeedServed(userID primitive.ObjectID, feedType, algorithm, source string, items int) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_, err := fs.db.Collection("feed_algorithm_logs").InsertOne(ctx, models.FeedAlgorithmLog{
+			UserID:    userID,
+			FeedType:  feedType,
+			Algorithm: algorithm,
+			Source:    source,
+			Items:     items,
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			log.Printf("Failed to log feed algorithm for %s feed: %v", feedType, err)
+		}
+	}()
+}
+
+// GetUserFeed generates and returns personalized feed for a user. A nil
+// languages slice falls back to the user's preferred languages; an empty one
+// disables language filtering.
+func (fs *FeedService) GetUserFeed(userID primitive.ObjectID, feedType string, limit, skip int, refresh bool, languages []string) ([]FeedItem, error) {
+	return fs.GetFeedWithAlgorithm(userID, feedType, models.FeedAlgorithmStandard, limit, skip, refresh, languages)
 }
 
-// GetUserFeed generates and returns personalized feed for a user
-func (fs *FeedService) GetUserFeed(userID primitive.ObjectID, feedType string, limit, skip int, refresh bool) ([]FeedItem, error) {
+// GetFeedWithAlgorithm is GetUserFeed ordered by the given algorithm. The
+// behavior algorithm builds on the standard feed, so it is served as
+// standard here. Each algorithm's feed is cached separately.
+func (fs *FeedService) GetFeedWithAlgorithm(userID primitive.ObjectID, feedType, algorithm string, limit, skip int, refresh bool, languages []string) ([]FeedItem, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// Feeds may be served by a secondary
+	ctx = repository.HeavyRead(ctx, &userID)
+
+	if languages == nil {
+		languages = getPreferredLanguages(ctx, fs.userCollection, userID)
+	}
+	muted := getMutedUserIDs(ctx, fs.db, userID)
+	sensitivity := getSensitiveContentSetting(ctx, fs.userCollection, userID)
+
+	// Standard feeds keep the plain feed type as their cache key
+	cacheKey := feedType
+	if algorithm == models.FeedAlgorithmChronological || algorithm == models.FeedAlgorithmHybrid {
+		cacheKey = feedType + ":" + algorithm
+	}
+
 	// Check cache first if not forcing refresh
 	if !refresh {
-		cachedFeed, err := fs.getCachedFeed(ctx, userID, feedType)
+		cachedFeed, err := fs.getCachedFeed(ctx, userID, cacheKey)
 		if err == nil && cachedFeed != nil && !fs.isCacheExpired(cachedFeed) {
+			posts := filterFeedByLanguage(cachedFeed.Posts, languages)
+			posts = filterFeedByMutes(posts, muted)
+			posts = filterFeedBySensitivity(posts, sensitivity)
 			start := skip
 			end := skip + limit
-			if end > len(cachedFeed.Posts) {
-				end = len(cachedFeed.Posts)
+			if end > len(posts) {
+				end = len(posts)
 			}
 
-			if start < len(cachedFeed.Posts) {
-				return cachedFeed.Posts[start:end], nil
+			if start < len(posts) {
+				return posts[start:end], nil
 			}
 		}
 	}
@@ -125,11 +277,28 @@ func (fs *FeedService) GetUserFeed(userID primitive.ObjectID, feedType string, l
 		return nil, err
 	}
 
-	// Apply diversity and ranking
-	rankedFeed := fs.applyFinalRanking(feedItems, userID)
+	var rankedFeed []FeedItem
+	if algorithm == models.FeedAlgorithmChronological {
+		rankedFeed = orderFeedByRecency(feedItems)
+	} else {
+		// Push posts the user has already scrolled past further down
+		feedItems = fs.applySeenPenalty(ctx, userID, feedItems)
+
+		// Apply diversity and ranking
+		rankedFeed = fs.applyFinalRanking(feedItems, userID)
+		if algorithm == models.FeedAlgorithmHybrid {
+			rankedFeed = blendFeedWithLatest(rankedFeed)
+		}
+	}
 
 	// Cache the feed
-	go fs.cacheFeed(userID, feedType, rankedFeed)
+	go fs.cacheFeed(userID, cacheKey, rankedFeed)
+
+	// Cached feeds stay unfiltered so changing languages, mutes or the
+	// sensitive content preference doesn't need a refresh
+	rankedFeed = filterFeedByLanguage(rankedFeed, languages)
+	rankedFeed = filterFeedByMutes(rankedFeed, muted)
+	rankedFeed = filterFeedBySensitivity(rankedFeed, sensitivity)
 
 	// Return requested page
 	start := skip
@@ -145,6 +314,347 @@ func (fs *FeedService) GetUserFeed(userID primitive.ObjectID, feedType string, l
 	return []FeedItem{}, nil
 }
 
+// InjectSlots places non-followed posts on a home feed page: after every
+// Interval-1 organic items comes one injected item, with the slot kinds
+// taking turns in the order the policy lists them. offset is the number of
+// organic items on earlier pages, so slots keep their places while paging.
+// A slot that can't be filled is left out rather than filled with organic
+// items, which keeps the organic ranking between slots unchanged. A post
+// injected for the user isn't injected again within the session window.
+// Injection problems never fail the feed.
+func (fs *FeedService) InjectSlots(userID primitive.ObjectID, items []FeedItem, offset int, languages []string) []FeedItem {
+	for i := range items {
+		if items[i].Source == "" {
+			items[i].Source = FeedSourceOrganic
+		}
+	}
+
+	policy := fs.injection
+	if policy.Interval < 2 || len(policy.Slots) == 0 || len(items) == 0 {
+		return items
+	}
+	organicPerSlot := policy.Interval - 1
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	exclude := fs.recentlyInjected(ctx, userID)
+	for _, item := range items {
+		exclude[item.Post.ID] = true
+	}
+
+	picker := &injectionPicker{fs: fs, userID: userID, languages: languages, exclude: exclude}
+	page := make([]FeedItem, 0, len(items)+len(items)/organicPerSlot)
+	slot := offset / organicPerSlot // Slots already shown on earlier pages
+	for i, item := range items {
+		page = append(page, item)
+		if (offset+i+1)%organicPerSlot != 0 {
+			continue
+		}
+
+		source := policy.Slots[slot%len(policy.Slots)]
+		slot++
+		injected, err := picker.pick(ctx, source)
+		if err != nil {
+			log.Printf("Failed to pick %s feed slot for user %s: %v", source, userID.Hex(), err)
+			continue
+		}
+		if injected == nil {
+			continue
+		}
+
+		exclude[injected.Post.ID] = true
+		fs.recordInjection(ctx, userID, *injected)
+		page = append(page, *injected)
+	}
+
+	return page
+}
+
+// injectionPicker fills the injected slots of one feed page. Recommendation
+// candidates are loaded once, on the first recommended slot.
+type injectionPicker struct {
+	fs         *FeedService
+	userID     primitive.ObjectID
+	languages  []string
+	exclude    map[primitive.ObjectID]bool
+	candidates []FeedItem
+	loaded     bool
+}
+
+func (p *injectionPicker) pick(ctx context.Context, source string) (*FeedItem, error) {
+	switch source {
+	case FeedSourceSponsored:
+		if p.fs.boosts == nil {
+			return nil, nil
+		}
+		return p.fs.pickBoostedItem(ctx, p.userID, p.exclude, p.languages)
+	case FeedSourceRecommended:
+		if !p.loaded {
+			candidates, err := p.fs.recommendationCandidates(ctx, p.userID, p.languages)
+			if err != nil {
+				return nil, err
+			}
+			p.candidates = candidates
+			p.loaded = true
+		}
+		for len(p.candidates) > 0 {
+			item := p.candidates[0]
+			p.candidates = p.candidates[1:]
+			if !p.exclude[item.Post.ID] {
+				return &item, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// recommendationCandidates returns recent public posts for recommended
+// slots, best first. Authors the user follows, has muted or has a block with
+// in either direction are left out, as are sensitive posts unless the user
+// sees them uncovered.
+func (fs *FeedService) recommendationCandidates(ctx context.Context, userID primitive.ObjectID, languages []string) ([]FeedItem, error) {
+	excluded, err := getSuggestionExclusions(ctx, fs.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	for mutedID := range getMutedUserIDs(ctx, fs.db, userID) {
+		excluded[mutedID] = true
+	}
+	authors := make([]primitive.ObjectID, 0, len(excluded))
+	for id := range excluded {
+		authors = append(authors, id)
+	}
+
+	if languages == nil {
+		languages = getPreferredLanguages(ctx, fs.userCollection, userID)
+	}
+	sensitivity := getSensitiveContentSetting(ctx, fs.userCollection, userID)
+
+	filter := repository.Where(bson.M{
+		"user_id":     bson.M{"$nin": authors},
+		"visibility":  models.PrivacyPublic,
+		"is_reported": bson.M{"$ne": true},
+		"created_at":  bson.M{"$gte": time.Now().Add(-2 * 24 * time.Hour)},
+	}).Published().NotHidden().Filter()
+	if sensitivity != sensitiveContentShown {
+		filter["is_sensitive"] = bson.M{"$ne": true}
+	}
+
+	opts := options.Find().
+		SetLimit(recommendationCandidateLimit).
+		SetSort(bson.D{{Key: "engagement_rate", Value: -1}, {Key: "created_at", Value: -1}})
+	cursor, err := repository.ReadFrom(ctx, fs.postCollection).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var posts []models.Post
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+
+	items := make([]FeedItem, 0, len(posts))
+	for _, post := range posts {
+		if len(languages) > 0 && post.Language != "" && !containsLanguage(languages, post.Language) {
+			continue
+		}
+		var author models.User
+		if err := fs.userCollection.FindOne(ctx, repository.NotDeleted(bson.M{
+			"_id":          post.UserID,
+			"is_active":    true,
+			"is_suspended": bson.M{"$ne": true},
+		})).Decode(&author); err != nil {
+			continue
+		}
+		post.Author = author.ToUserResponse()
+		items = append(items, FeedItem{
+			Post:    post,
+			Score:   fs.calculateEngagementScore(post),
+			Reason:  "recommended",
+			TimeAgo: fs.calculateTimeAgo(post.CreatedAt),
+			Source:  FeedSourceRecommended,
+		})
+	}
+
+	return items, nil
+}
+
+// recentlyInjected returns the posts injected into the user's feed within
+// the session window
+func (fs *FeedService) recentlyInjected(ctx context.Context, userID primitive.ObjectID) map[primitive.ObjectID]bool {
+	injected := make(map[primitive.ObjectID]bool)
+
+	postIDs, err := fs.injectionCollection.Distinct(ctx, "post_id", bson.M{
+		"user_id":    userID,
+		"expires_at": bson.M{"$gt": time.Now()},
+	})
+	if err != nil {
+		return injected
+	}
+	for _, id := range postIDs {
+		if oid, ok := id.(primitive.ObjectID); ok {
+			injected[oid] = true
+		}
+	}
+	return injected
+}
+
+// recordInjection remembers an injected post for the session window;
+// expired records are removed by the TTL index
+func (fs *FeedService) recordInjection(ctx context.Context, userID primitive.ObjectID, item FeedItem) {
+	now := time.Now()
+	_, err := fs.injectionCollection.InsertOne(ctx, bson.M{
+		"user_id":    userID,
+		"post_id":    item.Post.ID,
+		"source":     item.Source,
+		"created_at": now,
+		"expires_at": now.Add(fs.injection.SessionWindow),
+	})
+	if err != nil {
+		log.Printf("Failed to record feed injection for user %s: %v", userID.Hex(), err)
+	}
+}
+
+// pickBoostedItem finds the first live campaign the user qualifies for whose
+// post isn't excluded, and records serving it
+func (fs *FeedService) pickBoostedItem(ctx context.Context, userID primitive.ObjectID, exclude map[primitive.ObjectID]bool, languages []string) (*FeedItem, error) {
+	var viewer models.User
+	if err := fs.userCollection.FindOne(ctx, bson.M{"_id": userID}).Decode(&viewer); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if viewer.HasAdFreeEntitlement(fs.boosts.policy.AdFreePlans, now) {
+		return nil, nil
+	}
+
+	boosts, err := fs.boosts.liveBoosts(ctx, now)
+	if err != nil || len(boosts) == 0 {
+		return nil, err
+	}
+
+	// Any follow, pending or accepted, means the author is already known
+	cursor, err := fs.followCollection.Find(ctx, repository.NotDeleted(bson.M{
+		"follower_id": userID,
+	}), options.Find().SetProjection(bson.M{"followee_id": 1, "status": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var follows []models.Follow
+	if err := cursor.All(ctx, &follows); err != nil {
+		return nil, err
+	}
+	followed := make(map[primitive.ObjectID]bool, len(follows))
+	var accepted []primitive.ObjectID
+	for _, follow := range follows {
+		followed[follow.FolloweeID] = true
+		if follow.Status == models.FollowStatusAccepted {
+			accepted = append(accepted, follow.FolloweeID)
+		}
+	}
+
+	if languages == nil {
+		languages = getPreferredLanguages(ctx, fs.userCollection, userID)
+	}
+	muted := getMutedUserIDs(ctx, fs.db, userID)
+
+	var interests map[string]bool
+	for i := range boosts {
+		boost := &boosts[i]
+		if boost.AuthorID == userID || followed[boost.AuthorID] || muted[boost.AuthorID] || exclude[boost.PostID] {
+			continue
+		}
+		if isUserBlocked(ctx, fs.db, userID, boost.AuthorID) || isUserBlocked(ctx, fs.db, boost.AuthorID, userID) {
+			continue
+		}
+
+		switch boost.Audience {
+		case models.BoostAudienceFollowersOfFollowers:
+			if len(accepted) == 0 {
+				continue
+			}
+			count, err := fs.followCollection.CountDocuments(ctx, repository.NotDeleted(bson.M{
+				"follower_id": bson.M{"$in": accepted},
+				"followee_id": boost.AuthorID,
+				"status":      models.FollowStatusAccepted,
+			}), options.Count().SetLimit(1))
+			if err != nil || count == 0 {
+				continue
+			}
+		case models.BoostAudienceInterests:
+			if interests == nil {
+				userInterests, _ := fs.getUserInterests(ctx, userID)
+				interests = make(map[string]bool, len(userInterests))
+				for _, tag := range normalizeBoostHashtags(userInterests) {
+					interests[tag] = true
+				}
+			}
+			matched := false
+			for _, tag := range boost.Hashtags {
+				if interests[tag] {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+		case models.BoostAudienceRegion:
+			if !boost.TargetsRegion(viewer.Country) {
+				continue
+			}
+		default:
+			continue
+		}
+
+		// The post and its author must still pass the usual visibility rules
+		var post models.Post
+		if err := fs.postCollection.FindOne(ctx, boostablePostFilter(boost.PostID)).Decode(&post); err != nil {
+			continue
+		}
+		if len(languages) > 0 && post.Language != "" && !containsLanguage(languages, post.Language) {
+			continue
+		}
+		// Sensitive boosts only go to users who see sensitive posts uncovered
+		if post.IsSensitive && (!viewer.ShowSensitiveContent || models.IsUnderAge(viewer.DateOfBirth)) {
+			continue
+		}
+		var author models.User
+		if err := fs.userCollection.FindOne(ctx, repository.NotDeleted(bson.M{
+			"_id":          boost.AuthorID,
+			"is_active":    true,
+			"is_suspended": bson.M{"$ne": true},
+		})).Decode(&author); err != nil {
+			continue
+		}
+
+		reserved, err := fs.boosts.reserveDelivery(ctx, boost, userID, now)
+		if err != nil {
+			return nil, err
+		}
+		if !reserved {
+			continue
+		}
+
+		post.Author = author.ToUserResponse()
+		return &FeedItem{
+			Post:       post,
+			Reason:     "sponsored",
+			TimeAgo:    fs.calculateTimeAgo(post.CreatedAt),
+			IsPromoted: true,
+			PromotionInfo: &PromotionInfo{
+				Type:       "boosted",
+				Advertiser: author.Username,
+				ExpiresAt:  boost.EndsAt,
+			},
+			Sponsored: true,
+			BoostID:   boost.ID.Hex(),
+			Source:    FeedSourceSponsored,
+		}, nil
+	}
+
+	return nil, nil
+}
+
 // generatePersonalizedFeed creates a personalized feed using ML-like algorithm
 func (fs *FeedService) generatePersonalizedFeed(ctx context.Context, userID primitive.ObjectID, limit int) ([]FeedItem, error) {
 	weights := FeedAlgorithmWeights{
@@ -172,10 +682,8 @@ func (fs *FeedService) generatePersonalizedFeed(ctx context.Context, userID prim
 	pipeline := []bson.M{
 		// Match eligible posts
 		{
-			"$match": bson.M{
-				"is_published": true,
-				"deleted_at":   bson.M{"$exists": false},
-				"created_at":   bson.M{"$gte": time.Now().Add(-7 * 24 * time.Hour)}, // Last 7 days
+			"$match": repository.Where(bson.M{
+				"created_at": bson.M{"$gte": time.Now().Add(-7 * 24 * time.Hour)}, // Last 7 days
 				"$or": []bson.M{
 					{"visibility": "public"},
 					{
@@ -186,7 +694,7 @@ func (fs *FeedService) generatePersonalizedFeed(ctx context.Context, userID prim
 					},
 					{"user_id": userID}, // User's own posts
 				},
-			},
+			}).Published().VisibleTo(&userID).Filter(),
 		},
 		// Lookup author information
 		{
@@ -279,7 +787,7 @@ func (fs *FeedService) generatePersonalizedFeed(ctx context.Context, userID prim
 		},
 	}
 
-	cursor, err := fs.postCollection.Aggregate(ctx, pipeline)
+	cursor, err := repository.ReadFrom(ctx, fs.postCollection).Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -329,18 +837,16 @@ func (fs *FeedService) generateFollowingFeed(ctx context.Context, userID primiti
 		return []FeedItem{}, nil
 	}
 
-	filter := bson.M{
-		"user_id":      bson.M{"$in": append(following, userID)}, // Include user's own posts
-		"is_published": true,
-		"deleted_at":   bson.M{"$exists": false},
-		"created_at":   bson.M{"$gte": time.Now().Add(-3 * 24 * time.Hour)}, // Last 3 days
-	}
+	filter := repository.Where(bson.M{
+		"user_id":    bson.M{"$in": append(following, userID)},            // Include user's own posts
+		"created_at": bson.M{"$gte": time.Now().Add(-3 * 24 * time.Hour)}, // Last 3 days
+	}).Published().VisibleTo(&userID).Filter()
 
 	opts := options.Find().
 		SetLimit(int64(limit)).
 		SetSort(bson.M{"created_at": -1})
 
-	cursor, err := fs.postCollection.Find(ctx, filter, opts)
+	cursor, err := repository.ReadFrom(ctx, fs.postCollection).Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -377,12 +883,10 @@ func (fs *FeedService) generateTrendingFeed(ctx context.Context, userID primitiv
 
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"is_published": true,
-				"visibility":   "public",
-				"deleted_at":   bson.M{"$exists": false},
-				"created_at":   bson.M{"$gte": timeThreshold},
-			},
+			"$match": repository.Where(bson.M{
+				"visibility": "public",
+				"created_at": bson.M{"$gte": timeThreshold},
+			}).Published().NotHidden().Filter(),
 		},
 		{
 			"$addFields": bson.M{
@@ -423,7 +927,7 @@ func (fs *FeedService) generateTrendingFeed(ctx context.Context, userID primitiv
 		},
 	}
 
-	cursor, err := fs.postCollection.Aggregate(ctx, pipeline)
+	cursor, err := repository.ReadFrom(ctx, fs.postCollection).Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -462,13 +966,11 @@ func (fs *FeedService) generateDiscoverFeed(ctx context.Context, userID primitiv
 	following, _ := fs.getUserFollowing(ctx, userID)
 	userInterests, _ := fs.getUserInterests(ctx, userID)
 
-	filter := bson.M{
-		"user_id":      bson.M{"$nin": append(following, userID)}, // Exclude following and self
-		"is_published": true,
-		"visibility":   "public",
-		"deleted_at":   bson.M{"$exists": false},
-		"created_at":   bson.M{"$gte": time.Now().Add(-2 * 24 * time.Hour)}, // Last 2 days
-	}
+	filter := repository.Where(bson.M{
+		"user_id":    bson.M{"$nin": append(following, userID)}, // Exclude following and self
+		"visibility": "public",
+		"created_at": bson.M{"$gte": time.Now().Add(-2 * 24 * time.Hour)}, // Last 2 days
+	}).Published().NotHidden().Filter()
 
 	// Add hashtag filter based on user interests
 	if len(userInterests) > 0 {
@@ -480,9 +982,9 @@ func (fs *FeedService) generateDiscoverFeed(ctx context.Context, userID primitiv
 
 	opts := options.Find().
 		SetLimit(int64(limit * 2)). // Get more for better selection
-		SetSort(bson.M{"engagement_rate": -1, "created_at": -1})
+		SetSort(bson.D{{Key: "engagement_rate", Value: -1}, {Key: "created_at", Value: -1}})
 
-	cursor, err := fs.postCollection.Find(ctx, filter, opts)
+	cursor, err := repository.ReadFrom(ctx, fs.postCollection).Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -522,6 +1024,342 @@ func (fs *FeedService) generateDiscoverFeed(ctx context.Context, userID primitiv
 	return feedItems, nil
 }
 
+// GetNetworkTrending returns the "trending in your network" section: posts
+// the accounts the user follows engaged with most over the policy window,
+// each labelled with who in the network engaged. The section is cached per
+// user for the policy's CacheTTL, since the follow graph changes slowly;
+// languages, mutes and the sensitive content preference apply on every read.
+func (fs *FeedService) GetNetworkTrending(userID primitive.ObjectID) ([]FeedItem, error) {
+	policy := fs.networkTrending
+	if policy.Limit <= 0 {
+		return []FeedItem{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	// The section may be served by a secondary
+	ctx = repository.HeavyRead(ctx, &userID)
+
+	now := time.Now()
+	fs.networkMu.Lock()
+	cached, ok := fs.networkCache[userID]
+	fs.networkMu.Unlock()
+
+	items := cached.items
+	if !ok || !now.Before(cached.expiresAt) {
+		var err error
+		if items, err = fs.generateNetworkTrending(ctx, userID); err != nil {
+			return nil, err
+		}
+
+		fs.networkMu.Lock()
+		for id, entry := range fs.networkCache {
+			if now.After(entry.expiresAt) {
+				delete(fs.networkCache, id)
+			}
+		}
+		fs.networkCache[userID] = networkTrendingEntry{items: items, expiresAt: now.Add(policy.CacheTTL)}
+		fs.networkMu.Unlock()
+	}
+
+	items = filterFeedByLanguage(items, getPreferredLanguages(ctx, fs.userCollection, userID))
+	items = filterFeedByMutes(items, getMutedUserIDs(ctx, fs.db, userID))
+	items = filterFeedBySensitivity(items, getSensitiveContentSetting(ctx, fs.userCollection, userID))
+	return items, nil
+}
+
+// networkTrendingEntry is a user's cached network trending section
+type networkTrendingEntry struct {
+	items     []FeedItem
+	expiresAt time.Time
+}
+
+// networkPost tallies the engagement a post got from the user's network
+type networkPost struct {
+	likes, comments, reposts int64
+	engagers                 map[primitive.ObjectID]int64 // Followed account → its engagements with the post
+}
+
+// generateNetworkTrending ranks the posts at least MinEngagers followed
+// accounts liked, commented on or reposted within the window, weighing them
+// like the trending feed does. The user's own posts, posts they have already
+// seen or liked, and authors blocked either way are left out.
+func (fs *FeedService) generateNetworkTrending(ctx context.Context, userID primitive.ObjectID) ([]FeedItem, error) {
+	policy := fs.networkTrending
+
+	following, err := fs.getUserFollowing(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(following) == 0 {
+		return []FeedItem{}, nil
+	}
+
+	since := time.Now().Add(-policy.Window)
+	likes, err := fs.networkEngagement(ctx, "likes", "target_id", repository.NotDeleted(bson.M{
+		"target_type": "post",
+		"user_id":     bson.M{"$in": following},
+		"created_at":  bson.M{"$gte": since},
+	}))
+	if err != nil {
+		return nil, err
+	}
+	comments, err := fs.networkEngagement(ctx, "comments", "post_id", repository.NotDeleted(bson.M{
+		"user_id":    bson.M{"$in": following},
+		"created_at": bson.M{"$gte": since},
+	}))
+	if err != nil {
+		return nil, err
+	}
+	reposts, err := fs.networkEngagement(ctx, "posts", "original_post_id", repository.NotDeleted(bson.M{
+		"original_post_id": bson.M{"$exists": true},
+		"user_id":          bson.M{"$in": following},
+		"created_at":       bson.M{"$gte": since},
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	engaged := make(map[primitive.ObjectID]*networkPost)
+	for _, kind := range []struct {
+		counts []networkEngagementCount
+		total  func(*networkPost) *int64
+	}{
+		{likes, func(p *networkPost) *int64 { return &p.likes }},
+		{comments, func(p *networkPost) *int64 { return &p.comments }},
+		{reposts, func(p *networkPost) *int64 { return &p.reposts }},
+	} {
+		for _, count := range kind.counts {
+			post, ok := engaged[count.PostID]
+			if !ok {
+				post = &networkPost{engagers: make(map[primitive.ObjectID]int64)}
+				engaged[count.PostID] = post
+			}
+			*kind.total(post) += count.Count
+			for _, user := range count.Users {
+				post.engagers[user.UserID] += user.Count
+			}
+		}
+	}
+
+	var candidateIDs []primitive.ObjectID
+	for postID, post := range engaged {
+		if len(post.engagers) >= policy.MinEngagers {
+			candidateIDs = append(candidateIDs, postID)
+		}
+	}
+	if len(candidateIDs) == 0 {
+		return []FeedItem{}, nil
+	}
+
+	excluded, err := fs.excludedNetworkAuthors(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	seen := fs.getSeenPostIDs(ctx, userID)
+	liked, _ := fs.db.Collection("likes").Distinct(ctx, "target_id", repository.NotDeleted(bson.M{
+		"user_id":     userID,
+		"target_type": "post",
+		"target_id":   bson.M{"$in": candidateIDs},
+	}))
+	for _, id := range liked {
+		if oid, ok := id.(primitive.ObjectID); ok {
+			seen[oid] = true
+		}
+	}
+
+	// Friends-only posts count when the user follows their author
+	filter := repository.Where(bson.M{
+		"_id":     bson.M{"$in": candidateIDs},
+		"user_id": bson.M{"$nin": excluded},
+		"$or": []bson.M{
+			{"visibility": models.PrivacyPublic},
+			{"visibility": models.PrivacyFriends, "user_id": bson.M{"$in": following}},
+		},
+	}).Published().NotHidden().Filter()
+
+	cursor, err := repository.ReadFrom(ctx, fs.postCollection).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var posts []models.Post
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+
+	var feedItems []FeedItem
+	for _, post := range posts {
+		if seen[post.ID] {
+			continue
+		}
+		tally := engaged[post.ID]
+		feedItems = append(feedItems, FeedItem{
+			Post:    post,
+			Score:   float64(tally.likes + tally.comments*2 + tally.reposts*3),
+			Reason:  "network_trending",
+			TimeAgo: fs.calculateTimeAgo(post.CreatedAt),
+			Source:  FeedSourceOrganic,
+		})
+	}
+
+	sort.Slice(feedItems, func(i, j int) bool {
+		if feedItems[i].Score != feedItems[j].Score {
+			return feedItems[i].Score > feedItems[j].Score
+		}
+		return feedItems[i].Post.CreatedAt.After(feedItems[j].Post.CreatedAt)
+	})
+	if len(feedItems) > policy.Limit {
+		feedItems = feedItems[:policy.Limit]
+	}
+
+	for i := range feedItems {
+		fs.populatePostAuthor(ctx, &feedItems[i].Post)
+	}
+	fs.labelNetworkTrending(ctx, feedItems, engaged)
+
+	return feedItems, nil
+}
+
+// networkEngagementCount is one post's engagement of one kind from the
+// user's network, with each followed account's share
+type networkEngagementCount struct {
+	PostID primitive.ObjectID `bson:"_id"`
+	Count  int64              `bson:"count"`
+	Users  []struct {
+		UserID primitive.ObjectID `bson:"user_id"`
+		Count  int64              `bson:"count"`
+	} `bson:"users"`
+}
+
+// networkEngagement counts the documents matching the filter per post and per
+// engaging account, for the most engaged networkTrendingCandidates posts
+func (fs *FeedService) networkEngagement(ctx context.Context, collection, postField string, match bson.M) ([]networkEngagementCount, error) {
+	cursor, err := repository.ReadFrom(ctx, fs.db.Collection(collection)).Aggregate(ctx, []bson.M{
+		{"$match": match},
+		{"$group": bson.M{
+			"_id":   bson.M{"post": "$" + postField, "user": "$user_id"},
+			"count": bson.M{"$sum": 1},
+		}},
+		{"$group": bson.M{
+			"_id":   "$_id.post",
+			"count": bson.M{"$sum": "$count"},
+			"users": bson.M{"$push": bson.M{"user_id": "$_id.user", "count": "$count"}},
+		}},
+		{"$sort": bson.M{"count": -1}},
+		{"$limit": networkTrendingCandidates},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var counts []networkEngagementCount
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// excludedNetworkAuthors returns the user and anyone blocked by or blocking
+// them, whose posts never trend in the user's network
+func (fs *FeedService) excludedNetworkAuthors(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	excluded := []primitive.ObjectID{userID}
+
+	blocked, err := blockedEitherWay(ctx, fs.db, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for id := range blocked {
+		excluded = append(excluded, id)
+	}
+	return excluded, nil
+}
+
+// labelNetworkTrending explains each item's place in the section: how much
+// of its engagement came from the network, and the followed accounts that
+// engaged most
+func (fs *FeedService) labelNetworkTrending(ctx context.Context, items []FeedItem, engaged map[primitive.ObjectID]*networkPost) {
+	previews := make(map[primitive.ObjectID][]primitive.ObjectID, len(items))
+	var userIDs []primitive.ObjectID
+	for _, item := range items {
+		tally := engaged[item.Post.ID]
+		ids := make([]primitive.ObjectID, 0, len(tally.engagers))
+		for id := range tally.engagers {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			if tally.engagers[ids[i]] != tally.engagers[ids[j]] {
+				return tally.engagers[ids[i]] > tally.engagers[ids[j]]
+			}
+			return ids[i].Hex() < ids[j].Hex()
+		})
+		if len(ids) > networkEngagedByPreview {
+			ids = ids[:networkEngagedByPreview]
+		}
+		previews[item.Post.ID] = ids
+		userIDs = append(userIDs, ids...)
+	}
+
+	users := make(map[primitive.ObjectID]models.UserResponse)
+	if len(userIDs) > 0 {
+		cursor, err := fs.userCollection.Find(ctx, bson.M{"_id": bson.M{"$in": userIDs}})
+		if err == nil {
+			var found []models.User
+			if cursor.All(ctx, &found) == nil {
+				for _, user := range found {
+					users[user.ID] = user.ToUserResponse()
+				}
+			}
+		}
+	}
+
+	for i := range items {
+		tally := engaged[items[i].Post.ID]
+		network := &NetworkTrendingContext{
+			Engagers:  len(tally.engagers),
+			Likes:     tally.likes,
+			Comments:  tally.comments,
+			Reposts:   tally.reposts,
+			EngagedBy: []models.UserResponse{},
+		}
+		for _, id := range previews[items[i].Post.ID] {
+			if user, ok := users[id]; ok {
+				network.EngagedBy = append(network.EngagedBy, user)
+			}
+		}
+		network.Label = networkTrendingLabel(network.EngagedBy, network.Engagers)
+		items[i].Network = network
+	}
+}
+
+// networkTrendingLabel names who in the network engaged with a post, such as
+// "Popular with alice, bob and 3 others you follow"
+func networkTrendingLabel(engagedBy []models.UserResponse, engagers int) string {
+	names := make([]string, 0, 2)
+	for _, user := range engagedBy {
+		if len(names) == 2 {
+			break
+		}
+		names = append(names, user.Username)
+	}
+
+	others := engagers - len(names)
+	switch {
+	case len(names) == 0:
+		return fmt.Sprintf("Popular with %d people you follow", engagers)
+	case others == 1:
+		return fmt.Sprintf("Popular with %s and 1 other you follow", strings.Join(names, ", "))
+	case others > 1:
+		return fmt.Sprintf("Popular with %s and %d others you follow", strings.Join(names, ", "), others)
+	case len(names) == 2:
+		return fmt.Sprintf("Popular with %s and %s, who you follow", names[0], names[1])
+	}
+	return fmt.Sprintf("Popular with %s, who you follow", names[0])
+}
+
 // RecordInteraction records user interaction with content
 func (fs *FeedService) RecordInteraction(userID, postID primitive.ObjectID, interactionType, source string, timeSpent int64) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -586,6 +1424,23 @@ func (fs *FeedService) RefreshUserFeed(userID primitive.ObjectID, feedType strin
 	return err
 }
 
+// ClearFeedCache deletes cached feeds for one user, or for everyone when userID is nil
+func (fs *FeedService) ClearFeedCache(userID *primitive.ObjectID) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if userID != nil {
+		filter["user_id"] = *userID
+	}
+
+	result, err := fs.feedCacheCollection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
 // Helper methods
 
 func (fs *FeedService) getUserFollowing(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error) {
@@ -653,7 +1508,7 @@ func (fs *FeedService) getUserInterests(ctx context.Context, userID primitive.Ob
 		},
 	}
 
-	cursor, err := fs.interactionCollection.Aggregate(ctx, pipeline)
+	cursor, err := repository.ReadFrom(ctx, fs.interactionCollection).Aggregate(ctx, pipeline)
 	if err != nil {
 		return []string{}, nil // Return empty if error
 	}
@@ -668,8 +1523,29 @@ func (fs *FeedService) getUserInterests(ctx context.Context, userID primitive.Ob
 	}
 
 	var interests []string
+	seen := make(map[string]bool, len(results))
 	for _, result := range results {
 		interests = append(interests, result.Hashtag)
+		seen[result.Hashtag] = true
+	}
+
+	// Fill up with the hashtags seeded from the user's picked interests
+	// until enough behavior data accumulates
+	if len(interests) < 10 {
+		var user models.User
+		err := fs.userCollection.FindOne(ctx, bson.M{"_id": userID},
+			options.FindOne().SetProjection(bson.M{"interest_hashtags": 1})).Decode(&user)
+		if err == nil {
+			for _, tag := range user.InterestHashtags {
+				if len(interests) == 10 {
+					break
+				}
+				if !seen[tag] {
+					seen[tag] = true
+					interests = append(interests, tag)
+				}
+			}
+		}
 	}
 
 	return interests, nil
@@ -765,6 +1641,53 @@ func (fs *FeedService) applyInterestFiltering(feedItems []FeedItem, userInterest
 	return feedItems
 }
 
+// getSeenPostIDs returns posts the client reported as on screen for at least
+// SeenImpressionMinMs in the last three days without the user engaging
+func (fs *FeedService) getSeenPostIDs(ctx context.Context, userID primitive.ObjectID) map[primitive.ObjectID]bool {
+	seen := make(map[primitive.ObjectID]bool)
+
+	cursor, err := fs.engagementCollection.Find(ctx, bson.M{
+		"user_id":            userID,
+		"content_type":       "post",
+		"context.impression": true,
+		"context.engaged":    false,
+		"view_duration":      bson.M{"$gte": models.SeenImpressionMinMs},
+		"view_time":          bson.M{"$gte": time.Now().Add(-3 * 24 * time.Hour)},
+	}, options.Find().SetProjection(bson.M{"content_id": 1}).SetLimit(1000))
+	if err != nil {
+		return seen
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		ContentID primitive.ObjectID `bson:"content_id"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return seen
+	}
+
+	for _, result := range results {
+		seen[result.ContentID] = true
+	}
+
+	return seen
+}
+
+func (fs *FeedService) applySeenPenalty(ctx context.Context, userID primitive.ObjectID, feedItems []FeedItem) []FeedItem {
+	seen := fs.getSeenPostIDs(ctx, userID)
+	if len(seen) == 0 {
+		return feedItems
+	}
+
+	for i := range feedItems {
+		if seen[feedItems[i].Post.ID] {
+			feedItems[i].Score *= 0.3 // Seen but ignored: keep it, just lower
+		}
+	}
+
+	return feedItems
+}
+
 func (fs *FeedService) applyFinalRanking(feedItems []FeedItem, userID primitive.ObjectID) []FeedItem {
 	// Apply diversity: avoid too many posts from same author
 	authorPostCount := make(map[primitive.ObjectID]int)
@@ -788,6 +1711,33 @@ func (fs *FeedService) applyFinalRanking(feedItems []FeedItem, userID primitive.
 	return finalFeed
 }
 
+// orderFeedByRecency orders feed items newest first, for the chronological
+// algorithm
+func orderFeedByRecency(feedItems []FeedItem) []FeedItem {
+	sort.SliceStable(feedItems, func(i, j int) bool {
+		return feedItems[i].Post.CreatedAt.After(feedItems[j].Post.CreatedAt)
+	})
+	return feedItems
+}
+
+// blendFeedWithLatest alternates ranked items with the newest items not yet
+// placed, for the hybrid algorithm
+func blendFeedWithLatest(rankedFeed []FeedItem) []FeedItem {
+	latest := orderFeedByRecency(append([]FeedItem(nil), rankedFeed...))
+
+	blended := make([]FeedItem, 0, len(rankedFeed))
+	placed := make(map[primitive.ObjectID]bool, len(rankedFeed))
+	for i := range rankedFeed {
+		for _, item := range []FeedItem{rankedFeed[i], latest[i]} {
+			if !placed[item.Post.ID] {
+				placed[item.Post.ID] = true
+				blended = append(blended, item)
+			}
+		}
+	}
+	return blended
+}
+
 func (fs *FeedService) getCachedFeed(ctx context.Context, userID primitive.ObjectID, feedType string) (*FeedCache, error) {
 	var cache FeedCache
 	err := fs.feedCacheCollection.FindOne(ctx, bson.M{
@@ -853,3 +1803,125 @@ func (fs *FeedService) CleanupOldCaches() error {
 	fmt.Printf("Cleaned up %d expired feed caches\n", result.DeletedCount)
 	return nil
 }
+
+// filterFeedByLanguage keeps feed items in one of the given languages. Posts
+// whose language couldn't be detected are always kept.
+func filterFeedByLanguage(items []FeedItem, languages []string) []FeedItem {
+	if len(languages) == 0 {
+		return items
+	}
+
+	filtered := make([]FeedItem, 0, len(items))
+	for _, item := range items {
+		if item.Post.Language == "" || containsLanguage(languages, item.Post.Language) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// filterFeedByMutes drops feed items authored by muted users
+func filterFeedByMutes(items []FeedItem, muted map[primitive.ObjectID]bool) []FeedItem {
+	if len(muted) == 0 {
+		return items
+	}
+
+	filtered := make([]FeedItem, 0, len(items))
+	for _, item := range items {
+		if !muted[item.Post.UserID] {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// sensitiveContentSetting is how a user's feed treats posts marked sensitive
+type sensitiveContentSetting int
+
+const (
+	sensitiveContentHidden   sensitiveContentSetting = iota // Covered by the content warning
+	sensitiveContentShown                                   // Opted in to see them uncovered
+	sensitiveContentExcluded                                // Under-age account; left out entirely
+)
+
+// filterFeedBySensitivity drops or covers sensitive posts per the user's setting
+func filterFeedBySensitivity(items []FeedItem, setting sensitiveContentSetting) []FeedItem {
+	if setting == sensitiveContentShown {
+		return items
+	}
+
+	filtered := make([]FeedItem, 0, len(items))
+	for _, item := range items {
+		if item.Post.IsSensitive {
+			if setting == sensitiveContentExcluded {
+				continue
+			}
+			item.SensitiveHidden = true
+		}
+		filtered = append(filtered, item)
+	}
+	return filtered
+}
+
+// getSensitiveContentSetting returns how sensitive posts are shown to a user
+func getSensitiveContentSetting(ctx context.Context, userCollection *mongo.Collection, userID primitive.ObjectID) sensitiveContentSetting {
+	var user struct {
+		DateOfBirth          *time.Time `bson:"date_of_birth"`
+		ShowSensitiveContent bool       `bson:"show_sensitive_content"`
+	}
+	opts := options.FindOne().SetProjection(bson.M{"date_of_birth": 1, "show_sensitive_content": 1})
+	if err := userCollection.FindOne(ctx, bson.M{"_id": userID}, opts).Decode(&user); err != nil {
+		return sensitiveContentHidden
+	}
+	if models.IsUnderAge(user.DateOfBirth) {
+		return sensitiveContentExcluded
+	}
+	if user.ShowSensitiveContent {
+		return sensitiveContentShown
+	}
+	return sensitiveContentHidden
+}
+
+// getPreferredLanguages returns the content languages a user has opted into
+func getPreferredLanguages(ctx context.Context, userCollection *mongo.Collection, userID primitive.ObjectID) []string {
+	var user struct {
+		PreferredLanguages []string `bson:"preferred_languages"`
+	}
+	opts := options.FindOne().SetProjection(bson.M{"preferred_languages": 1})
+	if err := userCollection.FindOne(ctx, bson.M{"_id": userID}, opts).Decode(&user); err != nil {
+		return nil
+	}
+	return user.PreferredLanguages
+}
+
+// languageMatchFilter builds a posts query matching the given languages,
+// including posts with no detected language
+func languageMatchFilter(languages []string) bson.M {
+	values := make([]interface{}, 0, len(languages)+2)
+	for _, lang := range languages {
+		values = append(values, lang)
+	}
+	values = append(values, "", nil)
+	return bson.M{"$in": values}
+}
+
+// normalizeLanguages reduces language tags to unique base codes
+func normalizeLanguages(languages []string) []string {
+	normalized := []string{}
+	for _, lang := range languages {
+		lang = translation.NormalizeLanguage(lang)
+		if lang != "" && !containsLanguage(normalized, lang) {
+			normalized = append(normalized, lang)
+		}
+	}
+	return normalized
+}
+
+func containsLanguage(languages []string, lang string) bool {
+	for _, l := range languages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
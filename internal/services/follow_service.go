@@ -4,10 +4,14 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"strings"
 	"time"
 
 	"social-media-api/internal/config"
 	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -19,18 +23,35 @@ type FollowService struct {
 	followCollection *mongo.Collection
 	userCollection   *mongo.Collection
 	db               *mongo.Database
+	notifications    *NotificationService
 }
 
-func NewFollowService() *FollowService {
+const (
+	// Posts an account needs before it's suggested to new users as popular
+	onboardingMinPosts = 5
+
+	// How far back posts count toward interest-based onboarding suggestions
+	onboardingInterestWindow = 30 * 24 * time.Hour
+)
+
+func NewFollowService(db *mongo.Database, notifications *NotificationService) *FollowService {
 	return &FollowService{
-		followCollection: config.DB.Collection("follows"),
-		userCollection:   config.DB.Collection("users"),
-		db:               config.DB,
+		followCollection: db.Collection("follows"),
+		userCollection:   db.Collection("users"),
+		db:               db,
+		notifications:    notifications,
 	}
 }
 
-// FollowUser creates a follow relationship
+// FollowUser creates a follow relationship. It is idempotent: following a user
+// that is already followed (or already has a pending request) returns the
+// existing relationship without touching the counters. The follow document and
+// both users' counters are written in a single transaction where available.
 func (fs *FollowService) FollowUser(followerID, followeeID primitive.ObjectID) (*models.Follow, error) {
+	if followerID == followeeID {
+		return nil, errors.New("cannot follow yourself")
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -39,71 +60,169 @@ func (fs *FollowService) FollowUser(followerID, followeeID primitive.ObjectID) (
 		return nil, errors.New("user not found")
 	}
 
-	// Check if already following
-	var existingFollow models.Follow
-	err := fs.followCollection.FindOne(ctx, bson.M{
-		"follower_id": followerID,
-		"followee_id": followeeID,
-		"deleted_at":  bson.M{"$exists": false},
-	}).Decode(&existingFollow)
-
-	if err == nil {
-		if existingFollow.Status == models.FollowStatusAccepted {
-			return nil, errors.New("already following this user")
-		} else if existingFollow.Status == models.FollowStatusPending {
-			return nil, errors.New("follow request already pending")
-		}
-	}
-
 	// Get followee's privacy settings to determine if approval is needed
 	var followee models.User
-	err = fs.userCollection.FindOne(ctx, bson.M{"_id": followeeID}).Decode(&followee)
+	err := fs.userCollection.FindOne(ctx, bson.M{"_id": followeeID}).Decode(&followee)
 	if err != nil {
 		return nil, errors.New("user not found")
 	}
 
-	// Create follow relationship
-	follow := &models.Follow{
-		FollowerID: followerID,
-		FolloweeID: followeeID,
-		Status:     models.FollowStatusPending,
+	follow, _, err := fs.follow(ctx, followerID, &followee)
+	return follow, err
+}
+
+// FollowMany follows up to MaxFollowManyTargets accounts in one call, as new
+// users do after picking onboarding suggestions. Targets are deduped, private
+// accounts get a pending request, and each target reports its own outcome so
+// one failure doesn't abort the rest. The new followers and requests are
+// notified in one batch per kind.
+func (fs *FollowService) FollowMany(followerID primitive.ObjectID, targetIDs []string) ([]models.FollowManyResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	targetIDs = dedupeFollowTargets(targetIDs)
+	if len(targetIDs) > models.MaxFollowManyTargets {
+		return nil, fmt.Errorf("cannot follow more than %d users at once", models.MaxFollowManyTargets)
+	}
+
+	if !fs.userExists(ctx, followerID) {
+		return nil, errors.New("user not found")
+	}
+
+	results := make([]models.FollowManyResult, len(targetIDs))
+	var ids []primitive.ObjectID
+	for i, targetID := range targetIDs {
+		results[i].UserID = targetID
+		id, err := primitive.ObjectIDFromHex(targetID)
+		switch {
+		case err != nil:
+			results[i].Error = "invalid user ID format"
+		case id == followerID:
+			results[i].Error = "cannot follow yourself"
+		default:
+			ids = append(ids, id)
+		}
+	}
+
+	// Load every target at once instead of one lookup per follow
+	followees := make(map[primitive.ObjectID]*models.User, len(ids))
+	if len(ids) > 0 {
+		cursor, err := fs.userCollection.Find(ctx, repository.NotDeleted(bson.M{
+			"_id":       bson.M{"$in": ids},
+			"is_active": true,
+		}), options.Find().SetProjection(bson.M{"is_private": 1}))
+		if err != nil {
+			return nil, err
+		}
+		var users []models.User
+		if err := cursor.All(ctx, &users); err != nil {
+			return nil, err
+		}
+		for i := range users {
+			followees[users[i].ID] = &users[i]
+		}
+	}
+
+	var followed, requested []primitive.ObjectID
+	for i := range results {
+		if results[i].Error != "" {
+			continue
+		}
+		id, _ := primitive.ObjectIDFromHex(results[i].UserID)
+
+		followee, ok := followees[id]
+		if !ok {
+			results[i].Error = "user not found"
+			continue
+		}
+		blocked, err := hasBlockBetween(ctx, fs.db, []primitive.ObjectID{followerID}, []primitive.ObjectID{id})
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		if blocked {
+			results[i].Error = "cannot follow this user"
+			continue
+		}
+
+		follow, created, err := fs.follow(ctx, followerID, followee)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+
+		results[i].Status = follow.Status
+		results[i].Created = created
+		if created {
+			if follow.Status == models.FollowStatusAccepted {
+				followed = append(followed, id)
+			} else {
+				requested = append(requested, id)
+			}
+		}
+	}
+
+	if fs.notifications != nil {
+		if err := fs.notifications.NotifyFollowMany(followerID, followed, requested); err != nil {
+			log.Printf("Failed to send follow notifications for %s: %v", followerID.Hex(), err)
+		}
 	}
 
-	// Auto-approve if user has public profile
+	return results, nil
+}
+
+// follow writes the follow to an already loaded followee, pending when their
+// account is private, and reports whether it created the relationship
+func (fs *FollowService) follow(ctx context.Context, followerID primitive.ObjectID, followee *models.User) (*models.Follow, bool, error) {
+	followeeID := followee.ID
+
+	status := models.FollowStatusPending
 	if !followee.IsPrivate {
-		follow.Status = models.FollowStatusAccepted
-		now := time.Now()
-		follow.AcceptedAt = &now
+		// Auto-approve if user has public profile
+		status = models.FollowStatusAccepted
 	}
 
-	follow.BeforeCreate()
+	var follow *models.Follow
+	var created bool
 
-	result, err := fs.followCollection.InsertOne(ctx, follow)
+	transactional, err := config.RunInTransaction(ctx, fs.db, func(ctx context.Context) error {
+		var err error
+		follow, created, err = fs.upsertFollow(ctx, followerID, followeeID, status)
+		if err != nil || !created || follow.Status != models.FollowStatusAccepted {
+			return err
+		}
+		return fs.applyFollowCounts(ctx, followerID, followeeID, 1)
+	})
 	if err != nil {
-		return nil, err
+		if !transactional && created {
+			// Compensate: the follow was written but the counters were not
+			fs.softDeleteFollow(context.Background(), follow.ID)
+		}
+		return nil, false, err
 	}
 
-	follow.ID = result.InsertedID.(primitive.ObjectID)
-
-	// Update user follow counts if accepted
-	if follow.Status == models.FollowStatusAccepted {
-		go fs.updateFollowCounts(followerID, followeeID, true)
+	if created {
+		markSuggestionFollowed(ctx, fs.db, followerID, followeeID)
 	}
 
-	return follow, nil
+	return follow, created, nil
 }
 
-// UnfollowUser removes a follow relationship
+// UnfollowUser removes a follow relationship and reverses the counter updates
+// made by FollowUser
 func (fs *FollowService) UnfollowUser(followerID, followeeID primitive.ObjectID) error {
+	if followerID == followeeID {
+		return errors.New("cannot unfollow yourself")
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Find and soft delete the follow relationship
-	filter := bson.M{
+	// Find the follow relationship
+	filter := repository.NotDeleted(bson.M{
 		"follower_id": followerID,
 		"followee_id": followeeID,
-		"deleted_at":  bson.M{"$exists": false},
-	}
+	})
 
 	var follow models.Follow
 	err := fs.followCollection.FindOne(ctx, filter).Decode(&follow)
@@ -114,40 +233,25 @@ func (fs *FollowService) UnfollowUser(followerID, followeeID primitive.ObjectID)
 		return err
 	}
 
-	// Soft delete
-	now := time.Now()
-	update := bson.M{
-		"$set": bson.M{
-			"deleted_at": now,
-			"updated_at": now,
-		},
-	}
-
-	_, err = fs.followCollection.UpdateOne(ctx, bson.M{"_id": follow.ID}, update)
-	if err != nil {
-		return err
-	}
-
-	// Update follow counts if it was accepted
-	if follow.Status == models.FollowStatusAccepted {
-		go fs.updateFollowCounts(followerID, followeeID, false)
-	}
-
-	return nil
+	return fs.endFollow(ctx, &follow)
 }
 
-// GetFollowers retrieves a user's followers
+// GetFollowers retrieves a user's followers. Users who hide their social graph
+// only list followers to themselves.
 func (fs *FollowService) GetFollowers(userID primitive.ObjectID, currentUserID *primitive.ObjectID, limit, skip int) ([]models.FollowResponse, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
+	if isSocialGraphHidden(ctx, fs.db, userID, currentUserID) {
+		return nil, errors.New("social graph hidden")
+	}
+
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"followee_id": userID,
 				"status":      models.FollowStatusAccepted,
-				"deleted_at":  bson.M{"$exists": false},
-			},
+			}),
 		},
 		{
 			"$lookup": bson.M{
@@ -209,18 +313,22 @@ func (fs *FollowService) GetFollowers(userID primitive.ObjectID, currentUserID *
 	return followers, nil
 }
 
-// GetFollowing retrieves users that a user is following
+// GetFollowing retrieves users that a user is following. Users who hide their
+// social graph only list followees to themselves.
 func (fs *FollowService) GetFollowing(userID primitive.ObjectID, currentUserID *primitive.ObjectID, limit, skip int) ([]models.FollowResponse, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
+	if isSocialGraphHidden(ctx, fs.db, userID, currentUserID) {
+		return nil, errors.New("social graph hidden")
+	}
+
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"follower_id": userID,
 				"status":      models.FollowStatusAccepted,
-				"deleted_at":  bson.M{"$exists": false},
-			},
+			}),
 		},
 		{
 			"$lookup": bson.M{
@@ -289,11 +397,10 @@ func (fs *FollowService) GetPendingFollowRequests(userID primitive.ObjectID, lim
 
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"followee_id": userID,
 				"status":      models.FollowStatusPending,
-				"deleted_at":  bson.M{"$exists": false},
-			},
+			}),
 		},
 		{
 			"$lookup": bson.M{
@@ -355,11 +462,10 @@ func (fs *FollowService) GetSentFollowRequests(userID primitive.ObjectID, limit,
 
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"follower_id": userID,
 				"status":      models.FollowStatusPending,
-				"deleted_at":  bson.M{"$exists": false},
-			},
+			}),
 		},
 		{
 			"$lookup": bson.M{
@@ -421,12 +527,11 @@ func (fs *FollowService) AcceptFollowRequest(followID, userID primitive.ObjectID
 
 	// Find the follow request
 	var follow models.Follow
-	err := fs.followCollection.FindOne(ctx, bson.M{
+	err := fs.followCollection.FindOne(ctx, repository.NotDeleted(bson.M{
 		"_id":         followID,
 		"followee_id": userID,
 		"status":      models.FollowStatusPending,
-		"deleted_at":  bson.M{"$exists": false},
-	}).Decode(&follow)
+	})).Decode(&follow)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -435,7 +540,7 @@ func (fs *FollowService) AcceptFollowRequest(followID, userID primitive.ObjectID
 		return err
 	}
 
-	// Accept the request
+	// Accept the request and bump counters together
 	now := time.Now()
 	update := bson.M{
 		"$set": bson.M{
@@ -445,15 +550,28 @@ func (fs *FollowService) AcceptFollowRequest(followID, userID primitive.ObjectID
 		},
 	}
 
-	_, err = fs.followCollection.UpdateOne(ctx, bson.M{"_id": followID}, update)
-	if err != nil {
-		return err
+	var accepted bool
+	transactional, err := config.RunInTransaction(ctx, fs.db, func(ctx context.Context) error {
+		// Guard on status so a concurrent accept can't double count
+		result, err := fs.followCollection.UpdateOne(ctx, bson.M{
+			"_id":    followID,
+			"status": models.FollowStatusPending,
+		}, update)
+		if err != nil || result.ModifiedCount == 0 {
+			return err
+		}
+		accepted = true
+		return fs.applyFollowCounts(ctx, follow.FollowerID, follow.FolloweeID, 1)
+	})
+	if err != nil && !transactional && accepted {
+		// Compensate: revert the request back to pending
+		fs.followCollection.UpdateOne(context.Background(), bson.M{"_id": followID}, bson.M{
+			"$set":   bson.M{"status": models.FollowStatusPending, "updated_at": time.Now()},
+			"$unset": bson.M{"accepted_at": ""},
+		})
 	}
 
-	// Update follow counts
-	go fs.updateFollowCounts(follow.FollowerID, follow.FolloweeID, true)
-
-	return nil
+	return err
 }
 
 // RejectFollowRequest rejects a follow request
@@ -463,12 +581,11 @@ func (fs *FollowService) RejectFollowRequest(followID, userID primitive.ObjectID
 
 	// Find the follow request
 	var follow models.Follow
-	err := fs.followCollection.FindOne(ctx, bson.M{
+	err := fs.followCollection.FindOne(ctx, repository.NotDeleted(bson.M{
 		"_id":         followID,
 		"followee_id": userID,
 		"status":      models.FollowStatusPending,
-		"deleted_at":  bson.M{"$exists": false},
-	}).Decode(&follow)
+	})).Decode(&follow)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -497,12 +614,11 @@ func (fs *FollowService) CancelFollowRequest(followID, userID primitive.ObjectID
 
 	// Find the follow request
 	var follow models.Follow
-	err := fs.followCollection.FindOne(ctx, bson.M{
+	err := fs.followCollection.FindOne(ctx, repository.NotDeleted(bson.M{
 		"_id":         followID,
 		"follower_id": userID,
 		"status":      models.FollowStatusPending,
-		"deleted_at":  bson.M{"$exists": false},
-	}).Decode(&follow)
+	})).Decode(&follow)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -530,12 +646,11 @@ func (fs *FollowService) RemoveFollower(userID, followerID primitive.ObjectID) e
 	defer cancel()
 
 	// Find the follow relationship
-	filter := bson.M{
+	filter := repository.NotDeleted(bson.M{
 		"follower_id": followerID,
 		"followee_id": userID,
 		"status":      models.FollowStatusAccepted,
-		"deleted_at":  bson.M{"$exists": false},
-	}
+	})
 
 	var follow models.Follow
 	err := fs.followCollection.FindOne(ctx, filter).Decode(&follow)
@@ -546,24 +661,7 @@ func (fs *FollowService) RemoveFollower(userID, followerID primitive.ObjectID) e
 		return err
 	}
 
-	// Soft delete
-	now := time.Now()
-	update := bson.M{
-		"$set": bson.M{
-			"deleted_at": now,
-			"updated_at": now,
-		},
-	}
-
-	_, err = fs.followCollection.UpdateOne(ctx, bson.M{"_id": follow.ID}, update)
-	if err != nil {
-		return err
-	}
-
-	// Update follow counts
-	go fs.updateFollowCounts(followerID, userID, false)
-
-	return nil
+	return fs.endFollow(ctx, &follow)
 }
 
 // GetFollowStats retrieves follow statistics for a user
@@ -572,21 +670,19 @@ func (fs *FollowService) GetFollowStats(userID primitive.ObjectID) (*models.Foll
 	defer cancel()
 
 	// Get followers count
-	followersCount, err := fs.followCollection.CountDocuments(ctx, bson.M{
+	followersCount, err := fs.followCollection.CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"followee_id": userID,
 		"status":      models.FollowStatusAccepted,
-		"deleted_at":  bson.M{"$exists": false},
-	})
+	}))
 	if err != nil {
 		return nil, err
 	}
 
 	// Get following count
-	followingCount, err := fs.followCollection.CountDocuments(ctx, bson.M{
+	followingCount, err := fs.followCollection.CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"follower_id": userID,
 		"status":      models.FollowStatusAccepted,
-		"deleted_at":  bson.M{"$exists": false},
-	})
+	}))
 	if err != nil {
 		return nil, err
 	}
@@ -604,11 +700,10 @@ func (fs *FollowService) GetFollowStatus(followerID, followeeID primitive.Object
 	defer cancel()
 
 	var follow models.Follow
-	err := fs.followCollection.FindOne(ctx, bson.M{
+	err := fs.followCollection.FindOne(ctx, repository.NotDeleted(bson.M{
 		"follower_id": followerID,
 		"followee_id": followeeID,
-		"deleted_at":  bson.M{"$exists": false},
-	}).Decode(&follow)
+	})).Decode(&follow)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -620,20 +715,25 @@ func (fs *FollowService) GetFollowStatus(followerID, followeeID primitive.Object
 	return string(follow.Status), nil
 }
 
-// GetMutualFollows retrieves mutual follows between two users
+// GetMutualFollows retrieves the accounts both users follow, as seen by
+// userID1. Nothing is listed when userID2 hides their social graph, and
+// accounts that hide theirs are left out.
 func (fs *FollowService) GetMutualFollows(userID1, userID2 primitive.ObjectID, limit, skip int) ([]models.UserResponse, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
 
+	if isSocialGraphHidden(ctx, fs.db, userID2, &userID1) {
+		return nil, errors.New("social graph hidden")
+	}
+
 	// Find users that both users follow
 	pipeline := []bson.M{
 		// Get user1's following
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"follower_id": userID1,
 				"status":      models.FollowStatusAccepted,
-				"deleted_at":  bson.M{"$exists": false},
-			},
+			}),
 		},
 		// Look for user2 also following the same users
 		{
@@ -675,6 +775,11 @@ func (fs *FollowService) GetMutualFollows(userID1, userID2 primitive.ObjectID, l
 		{
 			"$unwind": "$user",
 		},
+		{
+			"$match": bson.M{
+				"user.privacy_settings.hide_social_graph": bson.M{"$ne": true},
+			},
+		},
 		{
 			"$skip": skip,
 		},
@@ -715,10 +820,23 @@ func (fs *FollowService) GetSuggestedUsers(userID primitive.ObjectID, limit int)
 	pipeline := []bson.M{
 		// Get user's followers
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"followee_id": userID,
 				"status":      models.FollowStatusAccepted,
-				"deleted_at":  bson.M{"$exists": false},
+			}),
+		},
+		// Followers who hide their social graph don't contribute
+		{
+			"$lookup": bson.M{
+				"from":         "users",
+				"localField":   "follower_id",
+				"foreignField": "_id",
+				"as":           "intermediary",
+			},
+		},
+		{
+			"$match": bson.M{
+				"intermediary.privacy_settings.hide_social_graph": bson.M{"$ne": true},
 			},
 		},
 		// Get who these followers are following
@@ -805,35 +923,71 @@ func (fs *FollowService) GetSuggestedUsers(userID primitive.ObjectID, limit int)
 	return suggestions, nil
 }
 
-// BulkFollowUsers follows multiple users at once
-func (fs *FollowService) BulkFollowUsers(followerID primitive.ObjectID, userIDStrs []string) (map[string]interface{}, error) {
-	results := map[string]interface{}{
-		"success": []string{},
-		"failed":  []map[string]string{},
+// GetOnboardingSuggestions returns accounts for a new user to follow: admin
+// curated accounts first, then active posters in the user's selected interests,
+// then popular verified and established creators. Unlike GetSuggestedUsers it
+// doesn't need an existing social graph.
+func (fs *FollowService) GetOnboardingSuggestions(userID primitive.ObjectID) ([]models.SuggestedUserResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	var user models.User
+	err := fs.userCollection.FindOne(ctx, bson.M{"_id": userID},
+		options.FindOne().SetProjection(bson.M{"interest_hashtags": 1})).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	excluded, err := getSuggestionExclusions(ctx, fs.db, userID)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, userIDStr := range userIDStrs {
-		userID, err := primitive.ObjectIDFromHex(userIDStr)
-		if err != nil {
-			results["failed"] = append(results["failed"].([]map[string]string), map[string]string{
-				"user_id": userIDStr,
-				"error":   "invalid user ID format",
+	limit := models.OnboardingSuggestionLimit
+	suggestions := []models.SuggestedUserResponse{}
+	add := func(users []models.User, source string) {
+		for _, candidate := range users {
+			if len(suggestions) == limit || excluded[candidate.ID] {
+				continue
+			}
+			excluded[candidate.ID] = true
+			suggestions = append(suggestions, models.SuggestedUserResponse{
+				User:   candidate.ToUserResponse(),
+				Source: source,
 			})
-			continue
 		}
+	}
 
-		_, err = fs.FollowUser(followerID, userID)
+	curated, err := fs.findOnboardingCandidates(ctx, bson.M{"is_onboarding_suggested": true}, excluded, limit)
+	if err != nil {
+		return nil, err
+	}
+	add(curated, models.SuggestionSourceCurated)
+
+	if len(suggestions) < limit && len(user.InterestHashtags) > 0 {
+		posters, err := fs.findInterestPosters(ctx, user.InterestHashtags, excluded, limit-len(suggestions))
 		if err != nil {
-			results["failed"] = append(results["failed"].([]map[string]string), map[string]string{
-				"user_id": userIDStr,
-				"error":   err.Error(),
-			})
-		} else {
-			results["success"] = append(results["success"].([]string), userIDStr)
+			return nil, err
 		}
+		add(posters, models.SuggestionSourceInterests)
 	}
 
-	return results, nil
+	if len(suggestions) < limit {
+		// Established creators without active strikes
+		popular, err := fs.findOnboardingCandidates(ctx, bson.M{
+			"posts_count":  bson.M{"$gte": onboardingMinPosts},
+			"strike_count": bson.M{"$not": bson.M{"$gt": 0}},
+		}, excluded, limit-len(suggestions))
+		if err != nil {
+			return nil, err
+		}
+		add(popular, models.SuggestionSourcePopular)
+	}
+
+	return suggestions, nil
 }
 
 // GetFollowActivity retrieves recent follow activity
@@ -841,9 +995,7 @@ func (fs *FollowService) GetFollowActivity(userID primitive.ObjectID, activityTy
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	filter := bson.M{
-		"deleted_at": bson.M{"$exists": false},
-	}
+	filter := repository.NotDeleted()
 
 	switch activityType {
 	case "new_followers":
@@ -913,45 +1065,317 @@ func (fs *FollowService) GetFollowActivity(userID primitive.ObjectID, activityTy
 // Helper methods
 
 // userExists checks if a user exists
+// findOnboardingCandidates finds suggestable accounts matching filter,
+// verified and most followed first
+func (fs *FollowService) findOnboardingCandidates(ctx context.Context, filter bson.M, excluded map[primitive.ObjectID]bool, limit int) ([]models.User, error) {
+	excludedIDs := make([]primitive.ObjectID, 0, len(excluded))
+	for id := range excluded {
+		excludedIDs = append(excludedIDs, id)
+	}
+
+	if ids, ok := filter["_id"].(bson.M); ok {
+		ids["$nin"] = excludedIDs
+	} else {
+		filter["_id"] = bson.M{"$nin": excludedIDs}
+	}
+	filter["is_active"] = true
+	filter["is_private"] = false
+	filter["is_suspended"] = bson.M{"$ne": true}
+	filter = repository.NotDeleted(filter)
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "is_verified", Value: -1}, {Key: "followers_count", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := fs.userCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// findInterestPosters finds suggestable accounts that recently posted public
+// posts tagged with any of the interests, most such posts first
+func (fs *FollowService) findInterestPosters(ctx context.Context, interests []string, excluded map[primitive.ObjectID]bool, limit int) ([]models.User, error) {
+	pipeline := []bson.M{
+		{"$match": repository.NotDeleted(bson.M{
+			"hashtags":     bson.M{"$in": interests},
+			"visibility":   models.PrivacyPublic,
+			"is_published": true,
+			"created_at":   bson.M{"$gte": time.Now().Add(-onboardingInterestWindow)},
+		})},
+		{"$group": bson.M{"_id": "$user_id", "posts": bson.M{"$sum": 1}}},
+		{"$sort": bson.M{"posts": -1}},
+		// Leave room for posters that turn out to be excluded or unsuggestable
+		{"$limit": limit * 3},
+	}
+
+	cursor, err := fs.db.Collection("posts").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var posters []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &posters); err != nil {
+		return nil, err
+	}
+
+	var ids []primitive.ObjectID
+	for _, poster := range posters {
+		if !excluded[poster.ID] {
+			ids = append(ids, poster.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	users, err := fs.findOnboardingCandidates(ctx, bson.M{"_id": bson.M{"$in": ids}}, excluded, len(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the posting activity order rather than popularity
+	byID := make(map[primitive.ObjectID]models.User, len(users))
+	for _, candidate := range users {
+		byID[candidate.ID] = candidate
+	}
+	ordered := make([]models.User, 0, limit)
+	for _, id := range ids {
+		if candidate, ok := byID[id]; ok && len(ordered) < limit {
+			ordered = append(ordered, candidate)
+		}
+	}
+	return ordered, nil
+}
+
 func (fs *FollowService) userExists(ctx context.Context, userID primitive.ObjectID) bool {
-	count, err := fs.userCollection.CountDocuments(ctx, bson.M{
-		"_id":        userID,
-		"is_active":  true,
-		"deleted_at": bson.M{"$exists": false},
-	})
+	count, err := fs.userCollection.CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"_id":       userID,
+		"is_active": true,
+	}))
 	return err == nil && count > 0
 }
 
 // isFollowing checks if user1 is following user2
 func (fs *FollowService) isFollowing(ctx context.Context, followerID, followeeID primitive.ObjectID) bool {
-	count, err := fs.followCollection.CountDocuments(ctx, bson.M{
+	count, err := fs.followCollection.CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"follower_id": followerID,
 		"followee_id": followeeID,
 		"status":      models.FollowStatusAccepted,
-		"deleted_at":  bson.M{"$exists": false},
-	})
+	}))
 	return err == nil && count > 0
 }
 
-// updateFollowCounts updates follower/following counts for users
-func (fs *FollowService) updateFollowCounts(followerID, followeeID primitive.ObjectID, increment bool) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// isSocialGraphHidden checks if the user hides their follower and following
+// lists from the viewer. Users always see their own lists; lookup failures
+// count as hidden.
+func isSocialGraphHidden(ctx context.Context, db *mongo.Database, userID primitive.ObjectID, viewerID *primitive.ObjectID) bool {
+	if viewerID != nil && *viewerID == userID {
+		return false
+	}
+
+	count, err := db.Collection("users").CountDocuments(ctx, bson.M{
+		"_id":                                userID,
+		"privacy_settings.hide_social_graph": true,
+	})
+	return err != nil || count > 0
+}
 
-	value := 1
-	if !increment {
-		value = -1
+// getHiddenGraphUserIDs returns which of the given users hide their social graph
+func getHiddenGraphUserIDs(ctx context.Context, db *mongo.Database, userIDs []primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+	hidden := make(map[primitive.ObjectID]bool)
+	if len(userIDs) == 0 {
+		return hidden, nil
 	}
 
-	// Update follower's following count
-	fs.userCollection.UpdateOne(ctx, bson.M{"_id": followerID}, bson.M{
-		"$inc": bson.M{"following_count": value},
-		"$set": bson.M{"updated_at": time.Now()},
+	ids, err := db.Collection("users").Distinct(ctx, "_id", bson.M{
+		"_id":                                bson.M{"$in": userIDs},
+		"privacy_settings.hide_social_graph": true,
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	// Update followee's followers count
-	fs.userCollection.UpdateOne(ctx, bson.M{"_id": followeeID}, bson.M{
-		"$inc": bson.M{"followers_count": value},
-		"$set": bson.M{"updated_at": time.Now()},
+	for _, id := range ids {
+		if oid, ok := id.(primitive.ObjectID); ok {
+			hidden[oid] = true
+		}
+	}
+	return hidden, nil
+}
+
+// upsertFollow writes the follow document for a follower/followee pair. The
+// unique index on the pair means a previously unfollowed relationship is revived
+// rather than re-inserted. created is false when an active relationship already
+// existed, in which case that relationship is returned unchanged.
+func (fs *FollowService) upsertFollow(ctx context.Context, followerID, followeeID primitive.ObjectID, status models.FollowStatus) (*models.Follow, bool, error) {
+	pair := bson.M{"follower_id": followerID, "followee_id": followeeID}
+
+	var existing models.Follow
+	err := fs.followCollection.FindOne(ctx, pair).Decode(&existing)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, false, err
+	}
+
+	follow := &models.Follow{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+		Status:     status,
+	}
+	follow.BeforeCreate()
+
+	if err == nil {
+		if !existing.IsDeleted() {
+			return &existing, false, nil
+		}
+
+		// Revive the soft-deleted relationship in place
+		follow.ID = existing.ID
+		result, err := fs.followCollection.UpdateOne(ctx, bson.M{
+			"_id":        existing.ID,
+			"deleted_at": bson.M{"$exists": true},
+		}, bson.M{
+			"$set": bson.M{
+				"status":                follow.Status,
+				"requested_at":          follow.RequestedAt,
+				"accepted_at":           follow.AcceptedAt,
+				"notifications_enabled": follow.NotificationsEnabled,
+				"show_in_feed":          follow.ShowInFeed,
+				"interaction_score":     follow.InteractionScore,
+				"created_at":            follow.CreatedAt,
+				"updated_at":            follow.UpdatedAt,
+			},
+			"$unset": bson.M{"deleted_at": ""},
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		if result.ModifiedCount == 0 {
+			// Revived concurrently by another request
+			return fs.findFollowPair(ctx, pair)
+		}
+		return follow, true, nil
+	}
+
+	result, err := fs.followCollection.InsertOne(ctx, follow)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			// Inserted concurrently by another request
+			return fs.findFollowPair(ctx, pair)
+		}
+		return nil, false, err
+	}
+
+	follow.ID = result.InsertedID.(primitive.ObjectID)
+	return follow, true, nil
+}
+
+// findFollowPair loads the relationship for a follower/followee pair
+func (fs *FollowService) findFollowPair(ctx context.Context, pair bson.M) (*models.Follow, bool, error) {
+	var follow models.Follow
+	if err := fs.followCollection.FindOne(ctx, pair).Decode(&follow); err != nil {
+		return nil, false, err
+	}
+	return &follow, false, nil
+}
+
+// endFollow soft deletes an active follow and, if it had been accepted,
+// decrements both users' counters in the same transaction
+func (fs *FollowService) endFollow(ctx context.Context, follow *models.Follow) error {
+	var ended bool
+	transactional, err := config.RunInTransaction(ctx, fs.db, func(ctx context.Context) error {
+		// Guard on deleted_at so concurrent unfollows only decrement once
+		now := time.Now()
+		result, err := fs.followCollection.UpdateOne(ctx, repository.ByID(follow.ID), bson.M{
+			"$set": bson.M{
+				"deleted_at": now,
+				"updated_at": now,
+			},
+		})
+		if err != nil || result.ModifiedCount == 0 {
+			return err
+		}
+		ended = true
+
+		if follow.Status != models.FollowStatusAccepted {
+			return nil
+		}
+		return fs.applyFollowCounts(ctx, follow.FollowerID, follow.FolloweeID, -1)
+	})
+	if err != nil && !transactional && ended {
+		// Compensate: restore the relationship since the counters were not updated
+		fs.followCollection.UpdateOne(context.Background(), bson.M{"_id": follow.ID}, bson.M{
+			"$unset": bson.M{"deleted_at": ""},
+			"$set":   bson.M{"updated_at": time.Now()},
+		})
+	}
+
+	return err
+}
+
+// softDeleteFollow marks a follow as deleted without touching counters
+func (fs *FollowService) softDeleteFollow(ctx context.Context, followID primitive.ObjectID) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	fs.followCollection.UpdateOne(ctx, bson.M{"_id": followID}, bson.M{
+		"$set": bson.M{
+			"deleted_at": now,
+			"updated_at": now,
+		},
 	})
 }
+
+// applyFollowCounts adjusts the follower's following count and the followee's
+// followers count by delta. If the second update fails outside a transaction,
+// the first is reverted so the two counters never drift apart.
+func (fs *FollowService) applyFollowCounts(ctx context.Context, followerID, followeeID primitive.ObjectID, delta int) error {
+	now := time.Now()
+
+	// Update follower's following count
+	if _, err := fs.userCollection.UpdateOne(ctx, bson.M{"_id": followerID}, bson.M{
+		"$inc": bson.M{"following_count": delta},
+		"$set": bson.M{"updated_at": now},
+	}); err != nil {
+		return err
+	}
+
+	// Update followee's followers count
+	if _, err := fs.userCollection.UpdateOne(ctx, bson.M{"_id": followeeID}, bson.M{
+		"$inc": bson.M{"followers_count": delta},
+		"$set": bson.M{"updated_at": now},
+	}); err != nil {
+		if _, inTxn := ctx.(mongo.SessionContext); !inTxn {
+			fs.userCollection.UpdateOne(context.Background(), bson.M{"_id": followerID}, bson.M{
+				"$inc": bson.M{"following_count": -delta},
+			})
+		}
+		return err
+	}
+
+	return nil
+}
+
+// dedupeFollowTargets drops repeated and blank target IDs, keeping the first occurrence
+func dedupeFollowTargets(targetIDs []string) []string {
+	seen := make(map[string]bool, len(targetIDs))
+	unique := make([]string, 0, len(targetIDs))
+	for _, targetID := range targetIDs {
+		targetID = strings.TrimSpace(targetID)
+		if targetID == "" || seen[targetID] {
+			continue
+		}
+		seen[targetID] = true
+		unique = append(unique, targetID)
+	}
+	return unique
+}
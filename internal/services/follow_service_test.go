@@ -0,0 +1,139 @@
+package services_test
+
+import (
+	"testing"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func newTestFollowService(h *testutil.Harness) *services.FollowService {
+	return services.NewFollowService(h.DB, services.NewNotificationService(nil, nil))
+}
+
+func TestFollowUserUpdatesCounters(t *testing.T) {
+	h := testutil.NewHarness(t)
+	follows := newTestFollowService(h)
+
+	follower := h.CreateUser()
+	followee := h.CreateUser()
+
+	follow, err := follows.FollowUser(follower.ID, followee.ID)
+	if err != nil {
+		t.Fatalf("FollowUser: %v", err)
+	}
+	if follow.Status != models.FollowStatusAccepted {
+		t.Errorf("status = %q, want %q", follow.Status, models.FollowStatusAccepted)
+	}
+
+	if got := h.ReloadUser(follower.ID).FollowingCount; got != 1 {
+		t.Errorf("follower following_count = %d, want 1", got)
+	}
+	if got := h.ReloadUser(followee.ID).FollowersCount; got != 1 {
+		t.Errorf("followee followers_count = %d, want 1", got)
+	}
+
+	again, err := follows.FollowUser(follower.ID, followee.ID)
+	if err != nil {
+		t.Fatalf("repeated FollowUser: %v", err)
+	}
+	if again.ID != follow.ID {
+		t.Errorf("repeated follow = %s, want the existing follow %s", again.ID.Hex(), follow.ID.Hex())
+	}
+	if got := h.ReloadUser(followee.ID).FollowersCount; got != 1 {
+		t.Errorf("followers_count after a repeated follow = %d, want 1", got)
+	}
+}
+
+func TestUnfollowUserUpdatesCounters(t *testing.T) {
+	h := testutil.NewHarness(t)
+	follows := newTestFollowService(h)
+
+	follower := h.CreateUser()
+	followee := h.CreateUser()
+
+	if _, err := follows.FollowUser(follower.ID, followee.ID); err != nil {
+		t.Fatalf("FollowUser: %v", err)
+	}
+	if err := follows.UnfollowUser(follower.ID, followee.ID); err != nil {
+		t.Fatalf("UnfollowUser: %v", err)
+	}
+
+	if got := h.ReloadUser(follower.ID).FollowingCount; got != 0 {
+		t.Errorf("follower following_count = %d, want 0", got)
+	}
+	if got := h.ReloadUser(followee.ID).FollowersCount; got != 0 {
+		t.Errorf("followee followers_count = %d, want 0", got)
+	}
+
+	status, err := follows.GetFollowStatus(follower.ID, followee.ID)
+	if err != nil {
+		t.Fatalf("GetFollowStatus: %v", err)
+	}
+	if status == string(models.FollowStatusAccepted) {
+		t.Errorf("follow status after unfollowing = %q", status)
+	}
+
+	if err := follows.UnfollowUser(follower.ID, followee.ID); err == nil {
+		t.Error("unfollowing twice succeeded")
+	}
+}
+
+func TestFollowPrivateAccountCountsOnAccept(t *testing.T) {
+	h := testutil.NewHarness(t)
+	follows := newTestFollowService(h)
+
+	follower := h.CreateUser()
+	followee := h.CreateUser(testutil.WithPrivateAccount())
+
+	follow, err := follows.FollowUser(follower.ID, followee.ID)
+	if err != nil {
+		t.Fatalf("FollowUser: %v", err)
+	}
+	if follow.Status != models.FollowStatusPending {
+		t.Fatalf("status = %q, want %q", follow.Status, models.FollowStatusPending)
+	}
+	if got := h.ReloadUser(followee.ID).FollowersCount; got != 0 {
+		t.Errorf("followers_count of a pending request = %d, want 0", got)
+	}
+
+	if err := follows.AcceptFollowRequest(follow.ID, followee.ID); err != nil {
+		t.Fatalf("AcceptFollowRequest: %v", err)
+	}
+	if got := h.ReloadUser(followee.ID).FollowersCount; got != 1 {
+		t.Errorf("followers_count after accepting = %d, want 1", got)
+	}
+	if got := h.ReloadUser(follower.ID).FollowingCount; got != 1 {
+		t.Errorf("following_count after accepting = %d, want 1", got)
+	}
+
+	if err := follows.AcceptFollowRequest(follow.ID, followee.ID); err == nil {
+		t.Error("accepting twice succeeded")
+	}
+	if got := h.ReloadUser(followee.ID).FollowersCount; got != 1 {
+		t.Errorf("followers_count after accepting twice = %d, want 1", got)
+	}
+}
+
+func TestFollowServiceRejectsSelfFollow(t *testing.T) {
+	h := testutil.NewHarness(t)
+	follows := newTestFollowService(h)
+	user := h.CreateUser()
+
+	if _, err := follows.FollowUser(user.ID, user.ID); err == nil || err.Error() != "cannot follow yourself" {
+		t.Errorf("FollowUser(self) error = %v, want \"cannot follow yourself\"", err)
+	}
+	if err := follows.UnfollowUser(user.ID, user.ID); err == nil || err.Error() != "cannot unfollow yourself" {
+		t.Errorf("UnfollowUser(self) error = %v, want \"cannot unfollow yourself\"", err)
+	}
+
+	if got := h.Count("follows", bson.M{}); got != 0 {
+		t.Errorf("follows = %d, want 0", got)
+	}
+	if stored := h.ReloadUser(user.ID); stored.FollowersCount != 0 || stored.FollowingCount != 0 {
+		t.Errorf("counters = %d/%d after a self-follow, want 0/0", stored.FollowersCount, stored.FollowingCount)
+	}
+}
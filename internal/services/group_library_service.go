@@ -0,0 +1,566 @@
+// internal/services/group_library_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"path/filepath"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+	"social-media-api/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GroupLibraryPolicy sets the storage ceilings for group resource libraries
+type GroupLibraryPolicy struct {
+	QuotaBytes        int64
+	PremiumQuotaBytes int64
+}
+
+// GroupLibraryService manages the shared file area of each group. Files are
+// stored through MediaService; group_files entries add folders, tags and
+// download counts, and the group document tracks storage used.
+type GroupLibraryService struct {
+	db           *mongo.Database
+	filesColl    *mongo.Collection
+	groupsColl   *mongo.Collection
+	usersColl    *mongo.Collection
+	groupService *GroupService
+	mediaService *MediaService
+	policy       GroupLibraryPolicy
+}
+
+func NewGroupLibraryService(db *mongo.Database, groupService *GroupService, mediaService *MediaService, policy GroupLibraryPolicy) *GroupLibraryService {
+	return &GroupLibraryService{
+		db:           db,
+		filesColl:    db.Collection("group_files"),
+		groupsColl:   db.Collection("groups"),
+		usersColl:    db.Collection("users"),
+		groupService: groupService,
+		mediaService: mediaService,
+		policy:       policy,
+	}
+}
+
+// librarySortOptions maps the accepted sort parameter to a sort document
+var librarySortOptions = map[string]bson.D{
+	"newest":    {{Key: "created_at", Value: -1}},
+	"oldest":    {{Key: "created_at", Value: 1}},
+	"name":      {{Key: "name", Value: 1}, {Key: "created_at", Value: -1}},
+	"size":      {{Key: "file_size", Value: -1}, {Key: "created_at", Value: -1}},
+	"downloads": {{Key: "download_count", Value: -1}, {Key: "created_at", Value: -1}},
+}
+
+// QuotaFor returns the storage limit that applies to a group's library. Admins
+// may set a lower limit; premium groups get the higher configured ceiling.
+func (ls *GroupLibraryService) QuotaFor(group *models.Group) int64 {
+	ceiling := ls.policy.QuotaBytes
+	if group.IsPremium {
+		ceiling = ls.policy.PremiumQuotaBytes
+	}
+
+	if group.LibraryQuotaBytes > 0 && group.LibraryQuotaBytes < ceiling {
+		return group.LibraryQuotaBytes
+	}
+	return ceiling
+}
+
+// GetStorage reports a group's library usage against its quota
+func (ls *GroupLibraryService) GetStorage(group *models.Group) *models.GroupLibraryStorage {
+	return &models.GroupLibraryStorage{
+		UsedBytes:  group.LibraryStorageUsed,
+		QuotaBytes: ls.QuotaFor(group),
+		FilesCount: group.LibraryFilesCount,
+	}
+}
+
+// UploadFile stores a file in the group's library for a member allowed to upload
+func (ls *GroupLibraryService) UploadFile(groupID, userID primitive.ObjectID, file multipart.File, header *multipart.FileHeader, req models.UploadGroupFileRequest) (*models.GroupFile, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	group, err := ls.getGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := ls.groupService.GetGroupMember(groupID, userID)
+	if err != nil {
+		return nil, errors.New("access denied")
+	}
+	if !group.CanUploadToLibrary(member.Role, member.Status) {
+		return nil, errors.New("upload permission required")
+	}
+
+	folder, err := models.NormalizeLibraryFolder(req.Folder)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType := utils.InferMediaTypeFromExtension(filepath.Ext(header.Filename))
+	if !utils.IsValidMediaType(mediaType) {
+		return nil, errors.New("unsupported file type")
+	}
+
+	// Reserve the space first so concurrent uploads cannot overshoot the quota
+	reserved := header.Size
+	if err := ls.reserveStorage(ctx, group, reserved); err != nil {
+		return nil, err
+	}
+
+	result, err := ls.mediaService.UploadMedia(userID, file, header, models.CreateMediaRequest{
+		Type:        mediaType,
+		Category:    "group_library",
+		Description: req.Description,
+		RelatedTo:   "group",
+		RelatedID:   groupID.Hex(),
+		IsPublic:    false,
+		Tags:        req.Tags,
+	})
+	if err != nil {
+		ls.adjustStorage(ctx, groupID, -reserved, 0)
+		return nil, err
+	}
+	media := result.Media
+
+	groupFile := &models.GroupFile{
+		GroupID:       groupID,
+		MediaID:       media.ID,
+		UploadedBy:    userID,
+		Name:          media.OriginalName,
+		FileSize:      media.FileSize,
+		MimeType:      media.MimeType,
+		FileExtension: media.FileExtension,
+		Folder:        folder,
+		Description:   req.Description,
+		Tags:          req.Tags,
+	}
+	groupFile.BeforeCreate()
+
+	insertResult, err := ls.filesColl.InsertOne(ctx, groupFile)
+	if err != nil {
+		releaseMedia(ctx, ls.db, []primitive.ObjectID{media.ID})
+		ls.adjustStorage(ctx, groupID, -reserved, 0)
+		return nil, fmt.Errorf("failed to add file to library: %w", err)
+	}
+	groupFile.ID = insertResult.InsertedID.(primitive.ObjectID)
+
+	// Settle the reservation against the size actually written
+	ls.adjustStorage(ctx, groupID, media.FileSize-reserved, 1)
+
+	return groupFile, nil
+}
+
+// GetFiles lists library files visible to the viewer, optionally limited to one folder or tag
+func (ls *GroupLibraryService) GetFiles(groupID primitive.ObjectID, viewerID *primitive.ObjectID, folder *string, tag, sortBy string, limit, skip int) ([]models.GroupFileResponse, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := ls.getViewableGroup(ctx, groupID, viewerID); err != nil {
+		return nil, 0, err
+	}
+
+	filter := repository.NotDeleted(bson.M{
+		"group_id": groupID,
+	})
+	if folder != nil {
+		normalized, err := models.NormalizeLibraryFolder(*folder)
+		if err != nil {
+			return nil, 0, err
+		}
+		filter["folder"] = normalized
+	}
+	if tag != "" {
+		filter["tags"] = tag
+	}
+
+	sortDoc, ok := librarySortOptions[sortBy]
+	if !ok {
+		sortDoc = librarySortOptions["newest"]
+	}
+
+	total, err := ls.filesColl.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(sortDoc).
+		SetLimit(int64(limit)).
+		SetSkip(int64(skip))
+
+	cursor, err := ls.filesColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var files []models.GroupFile
+	if err := cursor.All(ctx, &files); err != nil {
+		return nil, 0, err
+	}
+
+	return ls.toFileResponses(ctx, files), total, nil
+}
+
+// GetFolders lists the folders of a group's library with their file counts and sizes
+func (ls *GroupLibraryService) GetFolders(groupID primitive.ObjectID, viewerID *primitive.ObjectID) ([]models.GroupLibraryFolder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := ls.getViewableGroup(ctx, groupID, viewerID); err != nil {
+		return nil, err
+	}
+
+	pipeline := []bson.M{
+		{"$match": repository.NotDeleted(bson.M{
+			"group_id": groupID,
+		})},
+		{"$group": bson.M{
+			"_id":         "$folder",
+			"files_count": bson.M{"$sum": 1},
+			"total_size":  bson.M{"$sum": "$file_size"},
+		}},
+		{"$sort": bson.M{"_id": 1}},
+	}
+
+	cursor, err := ls.filesColl.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	folders := []models.GroupLibraryFolder{}
+	if err := cursor.All(ctx, &folders); err != nil {
+		return nil, err
+	}
+
+	return folders, nil
+}
+
+// OpenFile returns a library file and the path of its stored copy for a viewer
+// allowed to download it, and counts the download
+func (ls *GroupLibraryService) OpenFile(groupID, fileID primitive.ObjectID, viewerID *primitive.ObjectID) (*models.GroupFile, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Private and secret groups only serve files to members
+	if _, err := ls.getViewableGroup(ctx, groupID, viewerID); err != nil {
+		return nil, "", err
+	}
+
+	groupFile, err := ls.getFile(ctx, groupID, fileID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var media models.Media
+	err = ls.db.Collection("media").FindOne(ctx, repository.ByID(groupFile.MediaID)).Decode(&media)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, "", errors.New("file not found")
+		}
+		return nil, "", err
+	}
+
+	go ls.recordDownload(groupFile.ID, media.ID)
+
+	return groupFile, media.FilePath, nil
+}
+
+// UpdateFile edits a library file's description and tags, or moves it to
+// another folder. Uploaders may edit their own files; only group admins move files.
+func (ls *GroupLibraryService) UpdateFile(groupID, fileID, userID primitive.ObjectID, req models.UpdateGroupFileRequest) (*models.GroupFileResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	group, err := ls.getGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	groupFile, err := ls.getFile(ctx, groupID, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, role := ls.groupService.GetMemberStatus(groupID, userID)
+	canManage := group.CanManageLibrary(role)
+	if !canManage && groupFile.UploadedBy != userID {
+		return nil, errors.New("access denied")
+	}
+
+	update := bson.M{"updated_at": time.Now()}
+
+	if req.Folder != nil {
+		if !canManage {
+			return nil, errors.New("admin privileges required")
+		}
+		folder, err := models.NormalizeLibraryFolder(*req.Folder)
+		if err != nil {
+			return nil, err
+		}
+		update["folder"] = folder
+	}
+
+	if req.Description != nil {
+		update["description"] = *req.Description
+	}
+
+	if req.Tags != nil {
+		update["tags"] = req.Tags
+	}
+
+	err = ls.filesColl.FindOneAndUpdate(ctx,
+		repository.ByID(fileID),
+		bson.M{"$set": update},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(groupFile)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("file not found")
+		}
+		return nil, fmt.Errorf("failed to update file: %w", err)
+	}
+
+	responses := ls.toFileResponses(ctx, []models.GroupFile{*groupFile})
+	return &responses[0], nil
+}
+
+// DeleteFile removes a file from the library and records the deletion in the
+// audit trail. Uploaders may delete their own files; group admins may delete any.
+func (ls *GroupLibraryService) DeleteFile(groupID, fileID, userID primitive.ObjectID, ipAddress, userAgent string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	group, err := ls.getGroup(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
+	groupFile, err := ls.getFile(ctx, groupID, fileID)
+	if err != nil {
+		return err
+	}
+
+	_, role := ls.groupService.GetMemberStatus(groupID, userID)
+	if !group.CanManageLibrary(role) && groupFile.UploadedBy != userID {
+		return errors.New("access denied")
+	}
+
+	now := time.Now()
+	result, err := ls.filesColl.UpdateOne(ctx, repository.ByID(fileID), bson.M{
+		"$set": bson.M{"deleted_at": now, "updated_at": now},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	if result.ModifiedCount == 0 {
+		return errors.New("file not found")
+	}
+
+	ls.adjustStorage(ctx, groupID, -groupFile.FileSize, -1)
+
+	if err := releaseMedia(ctx, ls.db, []primitive.ObjectID{groupFile.MediaID}); err != nil {
+		return fmt.Errorf("failed to release file storage: %w", err)
+	}
+
+	return createAuditLog(ctx, ls.db, &models.AuditLog{
+		Action:     "group_file_deleted",
+		ActorID:    userID,
+		ActorType:  "user",
+		TargetType: "group_file",
+		TargetID:   fileID,
+		OldValues: map[string]interface{}{
+			"group_id":    groupID.Hex(),
+			"name":        groupFile.Name,
+			"folder":      groupFile.Folder,
+			"file_size":   groupFile.FileSize,
+			"uploaded_by": groupFile.UploadedBy.Hex(),
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	})
+}
+
+// softDeleteGroupLibrary soft deletes every file in a deleted group's library
+// and hands their storage to the orphan cleanup job
+func softDeleteGroupLibrary(ctx context.Context, db *mongo.Database, groupID primitive.ObjectID) error {
+	files := db.Collection("group_files")
+	filter := repository.NotDeleted(bson.M{
+		"group_id": groupID,
+	})
+
+	cursor, err := files.Find(ctx, filter, options.Find().SetProjection(bson.M{"media_id": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []models.GroupFile
+	if err := cursor.All(ctx, &entries); err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	mediaIDs := make([]primitive.ObjectID, 0, len(entries))
+	for _, entry := range entries {
+		mediaIDs = append(mediaIDs, entry.MediaID)
+	}
+
+	now := time.Now()
+	if _, err := files.UpdateMany(ctx, filter, bson.M{
+		"$set": bson.M{"deleted_at": now, "updated_at": now},
+	}); err != nil {
+		return err
+	}
+
+	return releaseMedia(ctx, db, mediaIDs)
+}
+
+// Helper methods
+
+// getGroup fetches an active, undeleted group
+func (ls *GroupLibraryService) getGroup(ctx context.Context, groupID primitive.ObjectID) (*models.Group, error) {
+	var group models.Group
+	err := ls.groupsColl.FindOne(ctx, repository.NotDeleted(bson.M{
+		"_id":       groupID,
+		"is_active": true,
+	})).Decode(&group)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("group not found")
+		}
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+
+	if group.IsSuspended {
+		return nil, errors.New("access denied")
+	}
+	return &group, nil
+}
+
+// getViewableGroup fetches a group whose library the viewer may browse.
+// Public libraries are open to everyone; private and secret ones to members only.
+func (ls *GroupLibraryService) getViewableGroup(ctx context.Context, groupID primitive.ObjectID, viewerID *primitive.ObjectID) (*models.Group, error) {
+	group, err := ls.getGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	if group.Privacy == models.GroupPublic {
+		return group, nil
+	}
+
+	if viewerID == nil {
+		return nil, errors.New("access denied")
+	}
+	member, err := ls.groupService.GetGroupMember(groupID, *viewerID)
+	if err != nil || member.Status != "active" {
+		return nil, errors.New("access denied")
+	}
+
+	return group, nil
+}
+
+// getFile fetches an undeleted library file belonging to the group
+func (ls *GroupLibraryService) getFile(ctx context.Context, groupID, fileID primitive.ObjectID) (*models.GroupFile, error) {
+	var groupFile models.GroupFile
+	err := ls.filesColl.FindOne(ctx, repository.NotDeleted(bson.M{
+		"_id":      fileID,
+		"group_id": groupID,
+	})).Decode(&groupFile)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("file not found")
+		}
+		return nil, err
+	}
+	return &groupFile, nil
+}
+
+// reserveStorage adds size to the group's used storage unless that would exceed its quota
+func (ls *GroupLibraryService) reserveStorage(ctx context.Context, group *models.Group, size int64) error {
+	remaining := ls.QuotaFor(group) - size
+	if remaining < 0 {
+		return errors.New("library storage quota exceeded")
+	}
+
+	result, err := ls.groupsColl.UpdateOne(ctx, bson.M{
+		"_id":                  group.ID,
+		"library_storage_used": bson.M{"$not": bson.M{"$gt": remaining}},
+	}, bson.M{
+		"$inc": bson.M{"library_storage_used": size},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reserve library storage: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("library storage quota exceeded")
+	}
+	return nil
+}
+
+// adjustStorage changes the group's library usage counters
+func (ls *GroupLibraryService) adjustStorage(ctx context.Context, groupID primitive.ObjectID, sizeDelta, filesDelta int64) {
+	if sizeDelta == 0 && filesDelta == 0 {
+		return
+	}
+
+	ls.groupsColl.UpdateOne(ctx, bson.M{"_id": groupID}, bson.M{
+		"$inc": bson.M{
+			"library_storage_used": sizeDelta,
+			"library_files_count":  filesDelta,
+		},
+	})
+}
+
+// recordDownload counts a download on the library entry and its media record
+func (ls *GroupLibraryService) recordDownload(fileID, mediaID primitive.ObjectID) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ls.filesColl.UpdateOne(ctx, bson.M{"_id": fileID}, bson.M{
+		"$inc": bson.M{"download_count": 1},
+	})
+	ls.mediaService.IncrementDownloadCount(mediaID)
+}
+
+// toFileResponses converts library files and populates their uploaders
+func (ls *GroupLibraryService) toFileResponses(ctx context.Context, files []models.GroupFile) []models.GroupFileResponse {
+	responses := make([]models.GroupFileResponse, 0, len(files))
+	if len(files) == 0 {
+		return responses
+	}
+
+	uploaderIDs := make([]primitive.ObjectID, 0, len(files))
+	for _, f := range files {
+		uploaderIDs = append(uploaderIDs, f.UploadedBy)
+	}
+
+	uploaders := make(map[primitive.ObjectID]models.UserResponse)
+	if cursor, err := ls.usersColl.Find(ctx, bson.M{"_id": bson.M{"$in": uploaderIDs}}); err == nil {
+		var users []models.User
+		if cursor.All(ctx, &users) == nil {
+			for _, user := range users {
+				uploaders[user.ID] = user.ToUserResponse()
+			}
+		}
+	}
+
+	for _, f := range files {
+		f.Uploader = uploaders[f.UploadedBy]
+		responses = append(responses, f.ToGroupFileResponse())
+	}
+	return responses
+}
@@ -3,11 +3,14 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"time"
 
 	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
 	"social-media-api/internal/utils"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -21,20 +24,28 @@ type GroupService struct {
 	groupsColl          *mongo.Collection
 	membersColl         *mongo.Collection
 	invitesColl         *mongo.Collection
+	inviteLinksColl     *mongo.Collection
 	usersColl           *mongo.Collection
 	postsColl           *mongo.Collection
+	notificationsColl   *mongo.Collection
 	notificationService *NotificationService
+	highlightsThreshold int64  // Member count from which new members default to highlights
+	inviteLinkBaseURL   string // Frontend URL invite link tokens are appended to
 }
 
-func NewGroupService(db *mongo.Database, notificationService *NotificationService) *GroupService {
+func NewGroupService(db *mongo.Database, notificationService *NotificationService, highlightsThreshold int64, inviteLinkBaseURL string) *GroupService {
 	return &GroupService{
 		db:                  db,
 		groupsColl:          db.Collection("groups"),
 		membersColl:         db.Collection("group_members"),
 		invitesColl:         db.Collection("group_invites"),
+		inviteLinksColl:     db.Collection("group_invite_links"),
 		usersColl:           db.Collection("users"),
 		postsColl:           db.Collection("posts"),
+		notificationsColl:   db.Collection("notifications"),
 		notificationService: notificationService,
+		highlightsThreshold: highlightsThreshold,
+		inviteLinkBaseURL:   inviteLinkBaseURL,
 	}
 }
 
@@ -49,10 +60,9 @@ func (s *GroupService) CreateGroup(creatorID primitive.ObjectID, req models.Crea
 	}
 
 	// Check if group name already exists (case-insensitive)
-	existingCount, err := s.groupsColl.CountDocuments(ctx, bson.M{
-		"name":       bson.M{"$regex": "^" + req.Name + "$", "$options": "i"},
-		"deleted_at": bson.M{"$exists": false},
-	})
+	existingCount, err := s.groupsColl.CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"name": bson.M{"$regex": "^" + req.Name + "$", "$options": "i"},
+	}))
 	if err != nil {
 		return nil, fmt.Errorf("failed to check group name uniqueness: %w", err)
 	}
@@ -72,6 +82,7 @@ func (s *GroupService) CreateGroup(creatorID primitive.ObjectID, req models.Crea
 		Rules:                  req.Rules,
 		CreatedBy:              creatorID,
 		PostApprovalRequired:   req.PostApprovalRequired,
+		WhoCanPost:             req.WhoCanPost,
 		MemberApprovalRequired: req.MemberApprovalRequired,
 		AllowMemberInvites:     req.AllowMemberInvites,
 		AllowExternalSharing:   req.AllowExternalSharing,
@@ -96,6 +107,8 @@ func (s *GroupService) CreateGroup(creatorID primitive.ObjectID, req models.Crea
 		UserID:  creatorID,
 		Role:    models.GroupRoleOwner,
 		Status:  "active",
+
+		NotificationLevel: models.GroupNotifyAll,
 	}
 	member.BeforeCreate()
 
@@ -116,10 +129,7 @@ func (s *GroupService) GetGroupByID(groupID, currentUserID primitive.ObjectID) (
 
 	// Get group
 	var group models.Group
-	err := s.groupsColl.FindOne(ctx, bson.M{
-		"_id":        groupID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&group)
+	err := s.groupsColl.FindOne(ctx, repository.ByID(groupID)).Decode(&group)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.New("group not found")
@@ -149,10 +159,9 @@ func (s *GroupService) GetGroupBySlug(slug string, currentUserID primitive.Objec
 	defer cancel()
 
 	var group models.Group
-	err := s.groupsColl.FindOne(ctx, bson.M{
-		"slug":       slug,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&group)
+	err := s.groupsColl.FindOne(ctx, repository.NotDeleted(bson.M{
+		"slug": slug,
+	})).Decode(&group)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.New("group not found")
@@ -189,11 +198,10 @@ func (s *GroupService) UpdateGroup(groupID, userID primitive.ObjectID, req model
 
 	if req.Name != nil {
 		// Check name uniqueness
-		existingCount, err := s.groupsColl.CountDocuments(ctx, bson.M{
-			"name":       bson.M{"$regex": "^" + *req.Name + "$", "$options": "i"},
-			"_id":        bson.M{"$ne": groupID},
-			"deleted_at": bson.M{"$exists": false},
-		})
+		existingCount, err := s.groupsColl.CountDocuments(ctx, repository.NotDeleted(bson.M{
+			"name": bson.M{"$regex": "^" + *req.Name + "$", "$options": "i"},
+			"_id":  bson.M{"$ne": groupID},
+		}))
 		if err != nil {
 			return nil, fmt.Errorf("failed to check name uniqueness: %w", err)
 		}
@@ -243,6 +251,10 @@ func (s *GroupService) UpdateGroup(groupID, userID primitive.ObjectID, req model
 		update["$set"].(bson.M)["post_approval_required"] = *req.PostApprovalRequired
 	}
 
+	if req.WhoCanPost != nil {
+		update["$set"].(bson.M)["who_can_post"] = *req.WhoCanPost
+	}
+
 	if req.MemberApprovalRequired != nil {
 		update["$set"].(bson.M)["member_approval_required"] = *req.MemberApprovalRequired
 	}
@@ -267,6 +279,14 @@ func (s *GroupService) UpdateGroup(groupID, userID primitive.ObjectID, req model
 		update["$set"].(bson.M)["allow_discussions"] = *req.AllowDiscussions
 	}
 
+	if req.LibraryUploadRole != nil {
+		update["$set"].(bson.M)["library_upload_role"] = *req.LibraryUploadRole
+	}
+
+	if req.LibraryQuotaBytes != nil {
+		update["$set"].(bson.M)["library_quota_bytes"] = *req.LibraryQuotaBytes
+	}
+
 	// Update group
 	_, err = s.groupsColl.UpdateOne(ctx, bson.M{"_id": groupID}, update)
 	if err != nil {
@@ -303,6 +323,11 @@ func (s *GroupService) DeleteGroup(groupID, userID primitive.ObjectID) error {
 		return fmt.Errorf("failed to delete group: %w", err)
 	}
 
+	// Release the group's library files
+	if err := softDeleteGroupLibrary(ctx, s.db, groupID); err != nil {
+		return fmt.Errorf("failed to delete group library: %w", err)
+	}
+
 	return nil
 }
 
@@ -339,6 +364,8 @@ func (s *GroupService) JoinGroup(groupID, userID primitive.ObjectID, req models.
 		GroupID: groupID,
 		UserID:  userID,
 		Role:    models.GroupRoleMember,
+
+		NotificationLevel: s.defaultNotificationLevel(&group),
 	}
 
 	if group.MemberApprovalRequired && group.Privacy != models.GroupPublic {
@@ -512,11 +539,19 @@ func (s *GroupService) AcceptGroupInvite(inviteID, userID primitive.ObjectID) er
 	}
 
 	// Add user as member
+	var group models.Group
+	if err := s.groupsColl.FindOne(ctx, bson.M{"_id": invite.GroupID},
+		options.FindOne().SetProjection(bson.M{"members_count": 1})).Decode(&group); err != nil {
+		return errors.New("group not found")
+	}
+
 	member := models.GroupMember{
 		GroupID: invite.GroupID,
 		UserID:  userID,
 		Role:    models.GroupRoleMember,
 		Status:  "active",
+
+		NotificationLevel: s.defaultNotificationLevel(&group),
 	}
 	member.BeforeCreate()
 
@@ -566,6 +601,225 @@ func (s *GroupService) RejectGroupInvite(inviteID, userID primitive.ObjectID) er
 	return nil
 }
 
+// CreateInviteLink creates a shareable invite link for a group. A maxUses of
+// 0 allows unlimited joins and a nil expiresAt never expires.
+func (s *GroupService) CreateInviteLink(groupID, actorID primitive.ObjectID, maxUses int64, expiresAt *time.Time) (*models.GroupInviteLinkResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	group, err := s.GetGroupByID(groupID, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.GetGroupMember(groupID, actorID)
+	if err != nil || member.Status != "active" {
+		return nil, errors.New("access denied")
+	}
+
+	if !group.CanInviteToGroup(member.Role) {
+		return nil, errors.New("insufficient permissions to invite")
+	}
+
+	if maxUses < 0 {
+		return nil, errors.New("max uses cannot be negative")
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, errors.New("expiry must be in the future")
+	}
+
+	token, err := generateInviteToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invite token: %w", err)
+	}
+
+	link := models.GroupInviteLink{
+		GroupID:   groupID,
+		CreatedBy: actorID,
+		Token:     token,
+		MaxUses:   maxUses,
+		ExpiresAt: expiresAt,
+	}
+	link.BeforeCreate()
+
+	result, err := s.inviteLinksColl.InsertOne(ctx, link)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invite link: %w", err)
+	}
+	link.ID = result.InsertedID.(primitive.ObjectID)
+
+	response := link.ToGroupInviteLinkResponse(s.inviteLinkBaseURL)
+	return &response, nil
+}
+
+// GetInviteLinks lists a group's invite links, newest first (admin/moderator only)
+func (s *GroupService) GetInviteLinks(groupID, actorID primitive.ObjectID, limit, offset int) ([]models.GroupInviteLinkResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := s.requireModerator(groupID, actorID); err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"created_at": -1}).
+		SetLimit(int64(limit)).
+		SetSkip(int64(offset))
+
+	cursor, err := s.inviteLinksColl.Find(ctx, bson.M{"group_id": groupID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invite links: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var links []models.GroupInviteLink
+	if err := cursor.All(ctx, &links); err != nil {
+		return nil, fmt.Errorf("failed to decode invite links: %w", err)
+	}
+
+	responses := make([]models.GroupInviteLinkResponse, len(links))
+	for i := range links {
+		responses[i] = links[i].ToGroupInviteLinkResponse(s.inviteLinkBaseURL)
+	}
+
+	return responses, nil
+}
+
+// RevokeInviteLink stops an invite link from being used. Group moderators can
+// revoke any link; other members only the links they created.
+func (s *GroupService) RevokeInviteLink(groupID, linkID, actorID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var link models.GroupInviteLink
+	err := s.inviteLinksColl.FindOne(ctx, bson.M{"_id": linkID, "group_id": groupID}).Decode(&link)
+	if err != nil {
+		return errors.New("invite link not found")
+	}
+
+	if link.CreatedBy != actorID {
+		if err := s.requireModerator(groupID, actorID); err != nil {
+			return err
+		}
+	}
+
+	if link.RevokedAt != nil {
+		return errors.New("invite link already revoked")
+	}
+
+	now := time.Now()
+	_, err = s.inviteLinksColl.UpdateOne(ctx, bson.M{"_id": linkID, "revoked_at": bson.M{"$exists": false}}, bson.M{
+		"$set": bson.M{
+			"revoked_at": now,
+			"revoked_by": actorID,
+			"updated_at": now,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke invite link: %w", err)
+	}
+
+	return nil
+}
+
+// JoinViaInvite adds the user to the group behind an invite link. The link
+// bypasses secret-group discovery, but groups that require member approval
+// still receive a pending join request.
+func (s *GroupService) JoinViaInvite(token string, userID primitive.ObjectID) (*models.GroupMember, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var link models.GroupInviteLink
+	if err := s.inviteLinksColl.FindOne(ctx, bson.M{"token": token}).Decode(&link); err != nil {
+		return nil, errors.New("invite link not found")
+	}
+
+	switch {
+	case link.RevokedAt != nil:
+		return nil, errors.New("invite link has been revoked")
+	case link.IsExpired():
+		return nil, errors.New("invite link has expired")
+	case link.IsExhausted():
+		return nil, errors.New("invite link has reached its usage limit")
+	}
+
+	var group models.Group
+	err := s.groupsColl.FindOne(ctx, repository.ByID(link.GroupID)).Decode(&group)
+	if err != nil {
+		return nil, errors.New("group not found")
+	}
+
+	if !group.IsActive || group.IsSuspended {
+		return nil, errors.New("cannot join this group")
+	}
+
+	memberStatus, _ := s.GetMemberStatus(link.GroupID, userID)
+	switch memberStatus {
+	case "active":
+		return nil, errors.New("already a member of this group")
+	case "pending":
+		return nil, errors.New("join request already pending")
+	case "banned":
+		return nil, errors.New("cannot join this group")
+	}
+
+	// Claim a use atomically so concurrent joins cannot exceed the limit
+	claimFilter := bson.M{"_id": link.ID, "revoked_at": bson.M{"$exists": false}}
+	if link.MaxUses > 0 {
+		claimFilter["uses_count"] = bson.M{"$lt": link.MaxUses}
+	}
+	claim, err := s.inviteLinksColl.UpdateOne(ctx, claimFilter, bson.M{
+		"$inc": bson.M{"uses_count": 1},
+		"$set": bson.M{"updated_at": time.Now()},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to use invite link: %w", err)
+	}
+	if claim.ModifiedCount == 0 {
+		return nil, errors.New("invite link has reached its usage limit")
+	}
+
+	member := models.GroupMember{
+		GroupID:   link.GroupID,
+		UserID:    userID,
+		Role:      models.GroupRoleMember,
+		InvitedBy: &link.CreatedBy,
+
+		NotificationLevel: s.defaultNotificationLevel(&group),
+	}
+	member.BeforeCreate()
+
+	if group.MemberApprovalRequired && group.Privacy != models.GroupPublic {
+		member.Status = "pending"
+	}
+
+	if _, err := s.membersColl.InsertOne(ctx, member); err != nil {
+		// Give the use back so a failed join does not count against the limit
+		s.inviteLinksColl.UpdateOne(ctx, bson.M{"_id": link.ID}, bson.M{"$inc": bson.M{"uses_count": -1}})
+		return nil, fmt.Errorf("failed to join group: %w", err)
+	}
+
+	if member.Status == "active" {
+		s.groupsColl.UpdateOne(ctx, bson.M{"_id": link.GroupID}, bson.M{
+			"$inc": bson.M{"members_count": 1},
+			"$set": bson.M{"updated_at": time.Now()},
+		})
+	} else {
+		go s.notifyGroupAdmins(link.GroupID, userID, "join_request")
+	}
+
+	return &member, nil
+}
+
+// generateInviteToken returns a random URL-safe invite link token
+func generateInviteToken() (string, error) {
+	bytes := make([]byte, 24)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
 // GetGroupMembers retrieves group members
 func (s *GroupService) GetGroupMembers(groupID, currentUserID primitive.ObjectID, limit, offset int) ([]models.GroupMemberResponse, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -688,15 +942,14 @@ func (s *GroupService) SearchGroups(query string, currentUserID *primitive.Objec
 	defer cancel()
 
 	// Build search filter
-	searchFilter := bson.M{
+	searchFilter := repository.NotDeleted(bson.M{
 		"$or": []bson.M{
 			{"name": bson.M{"$regex": query, "$options": "i"}},
 			{"description": bson.M{"$regex": query, "$options": "i"}},
 			{"tags": bson.M{"$in": []string{query}}},
 		},
-		"deleted_at": bson.M{"$exists": false},
-		"is_active":  true,
-	}
+		"is_active": true,
+	})
 
 	// Only show public groups to non-members
 	if currentUserID == nil {
@@ -868,16 +1121,348 @@ func (s *GroupService) GetGroupStats(groupID primitive.ObjectID, userID primitiv
 	stats["new_members_30d"] = newMembers
 
 	// Posts in last 30 days
-	newPosts, _ := s.postsColl.CountDocuments(ctx, bson.M{
+	newPosts, _ := s.postsColl.CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"group_id":   groupID,
 		"created_at": bson.M{"$gte": thirtyDaysAgo},
-		"deleted_at": bson.M{"$exists": false},
-	})
+	}))
 	stats["new_posts_30d"] = newPosts
 
+	notificationLevels, err := s.countNotificationLevels(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count notification levels: %w", err)
+	}
+	stats["notification_levels"] = notificationLevels
+
 	return stats, nil
 }
 
+// countNotificationLevels counts active members at each notification level.
+// Members who joined before levels existed are counted at their effective level.
+func (s *GroupService) countNotificationLevels(ctx context.Context, groupID primitive.ObjectID) (map[models.GroupNotificationLevel]int64, error) {
+	cursor, err := s.membersColl.Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"group_id": groupID, "status": "active"}},
+		{"$group": bson.M{
+			"_id": bson.M{"$ifNull": []interface{}{
+				"$notification_level",
+				bson.M{"$cond": []interface{}{
+					bson.M{"$or": []interface{}{"$is_muted", bson.M{"$eq": []interface{}{"$notifications_enabled", false}}}},
+					models.GroupNotifyMuted,
+					models.GroupNotifyAll,
+				}},
+			}},
+			"count": bson.M{"$sum": 1},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Level models.GroupNotificationLevel `bson:"_id"`
+		Count int64                         `bson:"count"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	counts := map[models.GroupNotificationLevel]int64{
+		models.GroupNotifyAll:        0,
+		models.GroupNotifyHighlights: 0,
+		models.GroupNotifyMentions:   0,
+		models.GroupNotifyMuted:      0,
+	}
+	for _, result := range results {
+		counts[result.Level] = result.Count
+	}
+	return counts, nil
+}
+
+// UpdateNotificationSettings sets the member's notification level for the
+// group. Muting also stops push and email for the member's group
+// notifications that are stored but not yet delivered.
+func (s *GroupService) UpdateNotificationSettings(groupID, userID primitive.ObjectID, level models.GroupNotificationLevel) (*models.GroupMember, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var member models.GroupMember
+	err := s.membersColl.FindOneAndUpdate(ctx, bson.M{
+		"group_id": groupID,
+		"user_id":  userID,
+		"status":   "active",
+	}, bson.M{
+		"$set": bson.M{
+			"notification_level": level,
+			"updated_at":         time.Now(),
+		},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&member)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("not a member of this group")
+		}
+		return nil, fmt.Errorf("failed to update notification settings: %w", err)
+	}
+
+	if level == models.GroupNotifyMuted {
+		_, err = s.notificationsColl.UpdateMany(ctx, bson.M{
+			"recipient_id": userID,
+			"group_id":     groupID,
+			"is_delivered": false,
+		}, bson.M{
+			"$set": bson.M{"push_suppressed": true},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to suppress pending notifications: %w", err)
+		}
+	}
+
+	return &member, nil
+}
+
+// defaultNotificationLevel returns the level a new member of the group starts at
+func (s *GroupService) defaultNotificationLevel(group *models.Group) models.GroupNotificationLevel {
+	if group.MembersCount >= s.highlightsThreshold {
+		return models.GroupNotifyHighlights
+	}
+	return models.GroupNotifyAll
+}
+
+// GetGroupPosts retrieves the group's published posts. Posts waiting for
+// approval or rejected by moderators are left out.
+func (s *GroupService) GetGroupPosts(groupID, currentUserID primitive.ObjectID, limit, offset int) ([]models.PostResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Check if user can view posts
+	memberStatus, _ := s.GetMemberStatus(groupID, currentUserID)
+	if memberStatus != "member" {
+		var group models.Group
+		err := s.groupsColl.FindOne(ctx, bson.M{"_id": groupID}).Decode(&group)
+		if err != nil || group.Privacy != models.GroupPublic {
+			return nil, errors.New("access denied")
+		}
+	}
+
+	return s.findGroupPosts(ctx, repository.NotDeleted(bson.M{
+		"group_id":     groupID,
+		"is_published": true,
+		"is_approved":  true,
+		"is_hidden":    false,
+	}), bson.M{"published_at": -1}, limit, offset)
+}
+
+// GetPendingPosts retrieves the group's posts waiting for moderator approval, oldest first
+func (s *GroupService) GetPendingPosts(groupID, moderatorID primitive.ObjectID, limit, offset int) ([]models.PostResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := s.requireModerator(groupID, moderatorID); err != nil {
+		return nil, err
+	}
+
+	return s.findGroupPosts(ctx, repository.NotDeleted(bson.M{
+		"group_id":            groupID,
+		"group_review_status": models.GroupPostPending,
+	}), bson.M{"created_at": 1}, limit, offset)
+}
+
+// ApprovePost publishes a pending group post and notifies its author
+func (s *GroupService) ApprovePost(groupID, postID, moderatorID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := s.requireModerator(groupID, moderatorID); err != nil {
+		return err
+	}
+
+	post, err := s.getPendingPost(ctx, groupID, postID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	set := bson.M{
+		"group_review_status": models.GroupPostApproved,
+		"group_reviewed_by":   moderatorID,
+		"group_reviewed_at":   now,
+		"is_approved":         true,
+		"updated_at":          now,
+	}
+
+	// Scheduled posts keep waiting for their time
+	published := post.ScheduledFor == nil || !post.ScheduledFor.After(now)
+	if published {
+		set["is_published"] = true
+		set["published_at"] = now
+	}
+
+	result, err := s.postsColl.UpdateOne(ctx, bson.M{
+		"_id":                 postID,
+		"group_review_status": models.GroupPostPending,
+	}, bson.M{"$set": set})
+	if err != nil {
+		return fmt.Errorf("failed to approve post: %w", err)
+	}
+	if result.ModifiedCount == 0 {
+		return errors.New("post is not pending approval")
+	}
+
+	if published {
+		s.usersColl.UpdateOne(ctx, bson.M{"_id": post.UserID}, bson.M{
+			"$inc": bson.M{"posts_count": 1},
+			"$set": bson.M{"updated_at": now},
+		})
+	}
+
+	if s.notificationService != nil {
+		go s.notificationService.NotifyGroupPostReviewed(moderatorID, post.UserID, groupID, postID, true, "")
+	}
+
+	return nil
+}
+
+// RejectPost keeps a pending group post out of the group and notifies its author
+func (s *GroupService) RejectPost(groupID, postID, moderatorID primitive.ObjectID, reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := s.requireModerator(groupID, moderatorID); err != nil {
+		return err
+	}
+
+	post, err := s.getPendingPost(ctx, groupID, postID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	set := bson.M{
+		"group_review_status": models.GroupPostRejected,
+		"group_reviewed_by":   moderatorID,
+		"group_reviewed_at":   now,
+		"updated_at":          now,
+	}
+	if reason != "" {
+		set["moderation_note"] = reason
+	}
+
+	result, err := s.postsColl.UpdateOne(ctx, bson.M{
+		"_id":                 postID,
+		"group_review_status": models.GroupPostPending,
+	}, bson.M{"$set": set})
+	if err != nil {
+		return fmt.Errorf("failed to reject post: %w", err)
+	}
+	if result.ModifiedCount == 0 {
+		return errors.New("post is not pending approval")
+	}
+
+	if s.notificationService != nil {
+		go s.notificationService.NotifyGroupPostReviewed(moderatorID, post.UserID, groupID, postID, false, reason)
+	}
+
+	return nil
+}
+
+// getPendingPost retrieves a group post waiting for approval
+func (s *GroupService) getPendingPost(ctx context.Context, groupID, postID primitive.ObjectID) (*models.Post, error) {
+	var post models.Post
+	err := s.postsColl.FindOne(ctx, repository.NotDeleted(bson.M{
+		"_id":                 postID,
+		"group_id":            groupID,
+		"group_review_status": models.GroupPostPending,
+	})).Decode(&post)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("pending post not found")
+		}
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+	return &post, nil
+}
+
+// findGroupPosts retrieves group posts with their authors
+func (s *GroupService) findGroupPosts(ctx context.Context, filter, sort bson.M, limit, offset int) ([]models.PostResponse, error) {
+	pipeline := []bson.M{
+		{"$match": filter},
+		{"$sort": sort},
+		{"$skip": offset},
+		{"$limit": limit},
+		{"$lookup": bson.M{
+			"from":         "users",
+			"localField":   "user_id",
+			"foreignField": "_id",
+			"as":           "author",
+		}},
+		{"$unwind": "$author"},
+	}
+
+	cursor, err := s.postsColl.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group posts: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	posts := []models.PostResponse{}
+	for cursor.Next(ctx) {
+		var result struct {
+			models.Post `bson:",inline"`
+			Author      models.User `bson:"author"`
+		}
+
+		if err := cursor.Decode(&result); err != nil {
+			continue
+		}
+
+		postResponse := result.Post.ToPostResponse()
+		postResponse.Author = result.Author.ToUserResponse()
+		posts = append(posts, postResponse)
+	}
+
+	return posts, nil
+}
+
+// requireModerator checks the user is the group's owner, an admin or a moderator
+func (s *GroupService) requireModerator(groupID, userID primitive.ObjectID) error {
+	member, err := s.GetGroupMember(groupID, userID)
+	if err != nil || member.Status != "active" {
+		return errors.New("access denied")
+	}
+
+	switch member.Role {
+	case models.GroupRoleOwner, models.GroupRoleAdmin, models.GroupRoleModerator:
+		return nil
+	default:
+		return errors.New("moderator privileges required")
+	}
+}
+
+// checkGroupPostPermission checks the user may post in the group and reports
+// whether the post has to wait for moderator approval
+func checkGroupPostPermission(ctx context.Context, db *mongo.Database, groupID, userID primitive.ObjectID) (bool, error) {
+	var group models.Group
+	err := db.Collection("groups").FindOne(ctx, repository.ByID(groupID)).Decode(&group)
+	if err != nil {
+		return false, errors.New("group not found")
+	}
+
+	var member models.GroupMember
+	err = db.Collection("group_members").FindOne(ctx, bson.M{
+		"group_id": groupID,
+		"user_id":  userID,
+		"status":   "active",
+	}).Decode(&member)
+	if err != nil {
+		return false, errors.New("you must be a member to post in this group")
+	}
+
+	if !group.CanPostInGroup(member.Role, "member") {
+		return false, errors.New("only admins and moderators can post in this group")
+	}
+
+	return group.RequiresPostApproval(member.Role), nil
+}
+
 // Helper methods
 
 // GetGroupMember retrieves a group member
@@ -998,11 +1583,10 @@ func (s *GroupService) GetPublicGroups(limit, offset int) ([]models.GroupRespons
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cursor, err := s.groupsColl.Find(ctx, bson.M{
-		"privacy":    models.GroupPublic,
-		"is_active":  true,
-		"deleted_at": bson.M{"$exists": false},
-	}, &options.FindOptions{
+	cursor, err := s.groupsColl.Find(ctx, repository.NotDeleted(bson.M{
+		"privacy":   models.GroupPublic,
+		"is_active": true,
+	}), &options.FindOptions{
 		Sort:  bson.D{{Key: "members_count", Value: -1}},
 		Skip:  func() *int64 { skip := int64(offset); return &skip }(),
 		Limit: func() *int64 { limit := int64(limit); return &limit }(),
@@ -1043,12 +1627,11 @@ func (s *GroupService) GetTrendingGroups(limit, offset int, timeRange string) ([
 
 	// For now, sort by activity score and member count
 	// In a full implementation, you'd calculate trending based on recent activity
-	cursor, err := s.groupsColl.Find(ctx, bson.M{
+	cursor, err := s.groupsColl.Find(ctx, repository.NotDeleted(bson.M{
 		"privacy":          models.GroupPublic,
 		"is_active":        true,
-		"deleted_at":       bson.M{"$exists": false},
 		"last_activity_at": bson.M{"$gte": since},
-	}, &options.FindOptions{
+	}), &options.FindOptions{
 		Sort: bson.D{
 			{Key: "activity_score", Value: -1},
 			{Key: "members_count", Value: -1},
@@ -0,0 +1,654 @@
+// internal/services/hashtag_service.go
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// RelatedHashtagsRefreshInterval is how often related hashtags are recomputed
+	RelatedHashtagsRefreshInterval = 24 * time.Hour
+
+	// Co-occurrence is counted over at most relatedHashtagsSampleSize of a
+	// tag's newest posts from the last relatedHashtagsWindow, so a viral tag
+	// costs the same as any other
+	relatedHashtagsSampleSize = 500
+	relatedHashtagsWindow     = 30 * 24 * time.Hour
+	relatedHashtagsLimit      = 10
+
+	// hashtagTopWindow is how far back the top tab looks
+	hashtagTopWindow = 7 * 24 * time.Hour
+)
+
+type HashtagService struct {
+	db                *mongo.Database
+	hashtagCollection *mongo.Collection
+	postCollection    *mongo.Collection
+	userCollection    *mongo.Collection
+	categorizer       *models.HashtagCategorizer
+	stopRelatedJob    context.CancelFunc
+}
+
+func NewHashtagService(db *mongo.Database, categorizer *models.HashtagCategorizer) *HashtagService {
+	return &HashtagService{
+		db:                db,
+		hashtagCollection: db.Collection("hashtags"),
+		postCollection:    db.Collection("posts"),
+		userCollection:    db.Collection("users"),
+		categorizer:       categorizer,
+	}
+}
+
+// normalizeHashtag returns the normalized form hashtags are stored under
+func normalizeHashtag(tag string) string {
+	hashtag := models.Hashtag{Tag: strings.TrimSpace(tag)}
+	hashtag.NormalizeTag()
+	return hashtag.NormalizedTag
+}
+
+// getHashtag loads a hashtag by any casing of its tag. Blocked hashtags are
+// reported as not found unless includeBlocked is set.
+func (hs *HashtagService) getHashtag(ctx context.Context, tag string, includeBlocked bool) (*models.Hashtag, error) {
+	normalized := normalizeHashtag(tag)
+	if !models.IsValidHashtag(normalized) {
+		return nil, errors.New("invalid hashtag")
+	}
+
+	var hashtag models.Hashtag
+	err := hs.hashtagCollection.FindOne(ctx, repository.NotDeleted(bson.M{"normalized_tag": normalized})).Decode(&hashtag)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("hashtag not found")
+		}
+		return nil, err
+	}
+
+	if hashtag.IsBlocked && !includeBlocked {
+		return nil, errors.New("hashtag not found")
+	}
+
+	return &hashtag, nil
+}
+
+// postTagVariants lists the spellings a hashtag is stored under on posts,
+// which keep the casing their author typed
+func postTagVariants(hashtag *models.Hashtag) []string {
+	variants := []string{}
+	seen := map[string]bool{}
+	for _, tag := range []string{hashtag.NormalizedTag, hashtag.Tag, hashtag.DisplayTag} {
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			variants = append(variants, tag)
+		}
+	}
+	return variants
+}
+
+// GetHashtagPage returns a hashtag's details with its related hashtags.
+// Blocked hashtags are only returned to admins.
+func (hs *HashtagService) GetHashtagPage(tag string, viewerID *primitive.ObjectID, isAdmin bool) (*models.HashtagPageResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	hashtag, err := hs.getHashtag(ctx, tag, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.HashtagPageResponse{
+		HashtagResponse: hashtag.ToHashtagResponse(),
+		IsBlocked:       hashtag.IsBlocked,
+		BlockedReason:   hashtag.BlockedReason,
+		RelatedHashtags: hs.visibleRelatedHashtags(ctx, hashtag.FrequentlyUsedWith),
+	}
+
+	// Following a hashtag is picking it as an interest
+	if viewerID != nil {
+		count, err := hs.userCollection.CountDocuments(ctx, bson.M{
+			"_id":               *viewerID,
+			"interest_hashtags": hashtag.NormalizedTag,
+		})
+		page.IsFollowing = err == nil && count > 0
+	}
+
+	return page, nil
+}
+
+// visibleRelatedHashtags drops related hashtags that were blocked since the
+// last refresh
+func (hs *HashtagService) visibleRelatedHashtags(ctx context.Context, related []models.HashtagCooccurrence) []models.HashtagCooccurrence {
+	visible := []models.HashtagCooccurrence{}
+	if len(related) == 0 {
+		return visible
+	}
+
+	tags := make([]string, 0, len(related))
+	for _, r := range related {
+		tags = append(tags, r.Tag)
+	}
+
+	blocked := map[string]bool{}
+	values, err := hs.hashtagCollection.Distinct(ctx, "normalized_tag", bson.M{
+		"normalized_tag": bson.M{"$in": tags},
+		"is_blocked":     true,
+	})
+	if err == nil {
+		for _, value := range values {
+			if tag, ok := value.(string); ok {
+				blocked[tag] = true
+			}
+		}
+	}
+
+	for _, r := range related {
+		if !blocked[r.Tag] {
+			visible = append(visible, r)
+		}
+	}
+	return visible
+}
+
+// hashtagPostsFilter selects the posts of a hashtag the viewer may see:
+// published and not hidden, public or their own or shared with them as a
+// follower, not by anyone they muted or are blocked with, and without
+// sensitive posts for under-age accounts
+func (hs *HashtagService) hashtagPostsFilter(ctx context.Context, hashtag *models.Hashtag, viewerID *primitive.ObjectID) (bson.M, sensitiveContentSetting, error) {
+	filter := bson.M{"hashtags": bson.M{"$in": postTagVariants(hashtag)}}
+	visibility := []bson.M{{"visibility": models.PrivacyPublic}}
+	sensitivity := sensitiveContentHidden
+
+	if viewerID != nil {
+		following, err := hs.db.Collection("follows").Distinct(ctx, "followee_id", repository.NotDeleted(bson.M{
+			"follower_id": *viewerID,
+			"status":      models.FollowStatusAccepted,
+		}))
+		if err != nil {
+			return nil, sensitivity, err
+		}
+		visibility = append(visibility,
+			bson.M{"user_id": *viewerID},
+			bson.M{"visibility": models.PrivacyFriends, "user_id": bson.M{"$in": following}},
+		)
+
+		excluded, err := hs.excludedAuthors(ctx, *viewerID)
+		if err != nil {
+			return nil, sensitivity, err
+		}
+		if len(excluded) > 0 {
+			filter["user_id"] = bson.M{"$nin": excluded}
+		}
+
+		sensitivity = getSensitiveContentSetting(ctx, hs.userCollection, *viewerID)
+	}
+
+	if sensitivity == sensitiveContentExcluded {
+		filter["is_sensitive"] = bson.M{"$ne": true}
+	}
+	filter["$and"] = []bson.M{{"$or": visibility}}
+
+	return repository.Where(filter).Published().VisibleTo(viewerID).Filter(), sensitivity, nil
+}
+
+// excludedAuthors returns the users whose posts the viewer never sees on a
+// hashtag page: muted users and anyone blocked in either direction
+func (hs *HashtagService) excludedAuthors(ctx context.Context, viewerID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	excluded := []primitive.ObjectID{}
+	for id := range getMutedUserIDs(ctx, hs.db, viewerID) {
+		excluded = append(excluded, id)
+	}
+
+	blocked, err := blockedEitherWay(ctx, hs.db, viewerID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for id := range blocked {
+		excluded = append(excluded, id)
+	}
+	return excluded, nil
+}
+
+// GetTopPosts returns a hashtag's posts of the last 7 days, ranked by
+// likes, comments and shares the same way the home feed weighs them
+func (hs *HashtagService) GetTopPosts(tag string, viewerID *primitive.ObjectID, isAdmin bool, limit, skip int) ([]models.HashtagPostResponse, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	hashtag, err := hs.getHashtag(ctx, tag, isAdmin)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	filter, sensitivity, err := hs.hashtagPostsFilter(ctx, hashtag, viewerID)
+	if err != nil {
+		return nil, 0, err
+	}
+	filter["created_at"] = bson.M{"$gte": time.Now().Add(-hashtagTopWindow)}
+
+	total, err := hs.postCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pipeline := []bson.M{
+		{"$match": filter},
+		{
+			"$addFields": bson.M{
+				"engagement_score": bson.M{
+					"$add": []interface{}{
+						"$likes_count",
+						bson.M{"$multiply": []interface{}{"$comments_count", 2}},
+						bson.M{"$multiply": []interface{}{"$shares_count", 3}},
+					},
+				},
+			},
+		},
+		{"$sort": bson.D{{Key: "engagement_score", Value: -1}, {Key: "_id", Value: -1}}},
+		{"$skip": skip},
+		{"$limit": limit},
+	}
+
+	cursor, err := hs.postCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var posts []models.Post
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, 0, err
+	}
+
+	return hs.toHashtagPosts(ctx, posts, sensitivity), total, nil
+}
+
+// GetRecentPosts returns a hashtag's posts newest first. The cursor is the
+// next_cursor of the previous page; it is empty when there are no more posts.
+func (hs *HashtagService) GetRecentPosts(tag string, viewerID *primitive.ObjectID, isAdmin bool, cursor string, limit int) ([]models.HashtagPostResponse, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	hashtag, err := hs.getHashtag(ctx, tag, isAdmin)
+	if err != nil {
+		return nil, "", err
+	}
+
+	filter, sensitivity, err := hs.hashtagPostsFilter(ctx, hashtag, viewerID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if cursor != "" {
+		createdAt, id, err := decodeTimeCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		repository.AppendAnd(filter, bson.M{"$or": []bson.M{
+			{"created_at": bson.M{"$lt": createdAt}},
+			{"created_at": createdAt, "_id": bson.M{"$lt": id}},
+		}})
+	}
+
+	// One extra post tells whether there is a next page
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit + 1))
+
+	results, err := hs.postCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	defer results.Close(ctx)
+
+	var posts []models.Post
+	if err := results.All(ctx, &posts); err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(posts) > limit {
+		posts = posts[:limit]
+		last := posts[len(posts)-1]
+		nextCursor = encodeTimeCursor(last.CreatedAt, last.ID)
+	}
+
+	return hs.toHashtagPosts(ctx, posts, sensitivity), nextCursor, nil
+}
+
+// encodeTimeCursor encodes the position after a document in a list ordered
+// by created_at and then _id, such as a hashtag's recent tab
+func encodeTimeCursor(createdAt time.Time, id primitive.ObjectID) string {
+	raw := fmt.Sprintf("%d_%s", createdAt.UnixMilli(), id.Hex())
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeTimeCursor reverses encodeTimeCursor
+func decodeTimeCursor(cursor string) (time.Time, primitive.ObjectID, error) {
+	invalid := errors.New("invalid cursor")
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, primitive.NilObjectID, invalid
+	}
+
+	parts := strings.SplitN(string(raw), "_", 2)
+	if len(parts) != 2 {
+		return time.Time{}, primitive.NilObjectID, invalid
+	}
+
+	millis, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, primitive.NilObjectID, invalid
+	}
+	id, err := primitive.ObjectIDFromHex(parts[1])
+	if err != nil {
+		return time.Time{}, primitive.NilObjectID, invalid
+	}
+
+	return time.UnixMilli(millis), id, nil
+}
+
+// toHashtagPosts converts posts for a hashtag page, loading their authors and
+// covering sensitive posts unless the viewer opted in to see them
+func (hs *HashtagService) toHashtagPosts(ctx context.Context, posts []models.Post, sensitivity sensitiveContentSetting) []models.HashtagPostResponse {
+	authorIDs := make([]primitive.ObjectID, 0, len(posts))
+	for _, post := range posts {
+		authorIDs = append(authorIDs, post.UserID)
+	}
+
+	authors := make(map[primitive.ObjectID]models.UserResponse)
+	if len(authorIDs) > 0 {
+		cursor, err := hs.userCollection.Find(ctx, bson.M{"_id": bson.M{"$in": authorIDs}})
+		if err == nil {
+			var users []models.User
+			if cursor.All(ctx, &users) == nil {
+				for i := range users {
+					authors[users[i].ID] = users[i].ToUserResponse()
+				}
+			}
+		}
+	}
+
+	responses := make([]models.HashtagPostResponse, 0, len(posts))
+	for i := range posts {
+		posts[i].Author = authors[posts[i].UserID]
+		responses = append(responses, models.HashtagPostResponse{
+			PostResponse:    posts[i].ToPostResponse(),
+			SensitiveHidden: posts[i].IsSensitive && sensitivity != sensitiveContentShown,
+		})
+	}
+	return responses
+}
+
+// Categorize returns the category a hashtag is filed under automatically
+func (hs *HashtagService) Categorize(tag string) string {
+	return hs.categorizer.Categorize(tag)
+}
+
+// SetCategory files a hashtag under a category chosen by an admin. The
+// choice sticks: recategorization skips the hashtag until ResetCategory.
+func (hs *HashtagService) SetCategory(hashtagID primitive.ObjectID, category string) (*models.Hashtag, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	category = strings.ToLower(strings.TrimSpace(category))
+	if !hs.categorizer.IsKnownCategory(category) {
+		return nil, errors.New("invalid category")
+	}
+
+	return hs.updateCategory(ctx, hashtagID, bson.M{
+		"$set": bson.M{
+			"category":        category,
+			"category_locked": true,
+			"updated_at":      time.Now(),
+		},
+	})
+}
+
+// ResetCategory drops an admin's category override and files the hashtag
+// automatically again
+func (hs *HashtagService) ResetCategory(hashtagID primitive.ObjectID) (*models.Hashtag, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var hashtag models.Hashtag
+	err := hs.hashtagCollection.FindOne(ctx, repository.ByID(hashtagID)).Decode(&hashtag)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("hashtag not found")
+		}
+		return nil, err
+	}
+
+	return hs.updateCategory(ctx, hashtagID, bson.M{
+		"$set": bson.M{
+			"category":   hs.Categorize(hashtag.NormalizedTag),
+			"updated_at": time.Now(),
+		},
+		"$unset": bson.M{"category_locked": ""},
+	})
+}
+
+func (hs *HashtagService) updateCategory(ctx context.Context, hashtagID primitive.ObjectID, update bson.M) (*models.Hashtag, error) {
+	var hashtag models.Hashtag
+	err := hs.hashtagCollection.FindOneAndUpdate(ctx, repository.ByID(hashtagID), update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&hashtag)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("hashtag not found")
+		}
+		return nil, err
+	}
+	return &hashtag, nil
+}
+
+// RecategorizeHashtags files every hashtag without an admin override again,
+// e.g. after the category keywords changed
+func (hs *HashtagService) RecategorizeHashtags() (*models.RecategorizeHashtagsResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	cursor, err := hs.hashtagCollection.Find(ctx, repository.NotDeleted(bson.M{
+		"category_locked": bson.M{"$ne": true},
+	}), options.Find().SetProjection(bson.M{"normalized_tag": 1, "category": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	result := &models.RecategorizeHashtagsResponse{}
+	var writes []mongo.WriteModel
+	flush := func() error {
+		if len(writes) == 0 {
+			return nil
+		}
+		res, err := hs.hashtagCollection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			return err
+		}
+		result.Changed += res.ModifiedCount
+		writes = writes[:0]
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var hashtag models.Hashtag
+		if err := cursor.Decode(&hashtag); err != nil {
+			return nil, err
+		}
+		result.Scanned++
+
+		category := hs.Categorize(hashtag.NormalizedTag)
+		if category == hashtag.Category {
+			continue
+		}
+
+		// The lock check guards against an override made during the run
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": hashtag.ID, "category_locked": bson.M{"$ne": true}}).
+			SetUpdate(bson.M{"$set": bson.M{"category": category, "updated_at": time.Now()}}))
+		if len(writes) >= 500 {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RefreshRelatedHashtags recomputes every unblocked hashtag's related
+// hashtags from the hashtags its recent posts are tagged with
+func (hs *HashtagService) RefreshRelatedHashtags(ctx context.Context) (int, error) {
+	opts := options.Find().SetProjection(bson.M{"normalized_tag": 1, "tag": 1, "display_tag": 1})
+	cursor, err := hs.hashtagCollection.Find(ctx, repository.NotDeleted(bson.M{
+		"is_blocked":  bson.M{"$ne": true},
+		"posts_count": bson.M{"$gt": 0},
+	}), opts)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	refreshed := 0
+	for cursor.Next(ctx) {
+		var hashtag models.Hashtag
+		if err := cursor.Decode(&hashtag); err != nil {
+			continue
+		}
+
+		if err := hs.refreshRelatedHashtags(ctx, &hashtag); err != nil {
+			if ctx.Err() != nil {
+				return refreshed, ctx.Err()
+			}
+			log.Printf("Failed to refresh related hashtags for #%s: %v", hashtag.NormalizedTag, err)
+			continue
+		}
+		refreshed++
+	}
+
+	return refreshed, cursor.Err()
+}
+
+// refreshRelatedHashtags counts which hashtags appear alongside one hashtag
+// in a bounded sample of its newest public posts
+func (hs *HashtagService) refreshRelatedHashtags(ctx context.Context, hashtag *models.Hashtag) error {
+	match := repository.Where(bson.M{
+		"hashtags":   bson.M{"$in": postTagVariants(hashtag)},
+		"visibility": models.PrivacyPublic,
+		"created_at": bson.M{"$gte": time.Now().Add(-relatedHashtagsWindow)},
+	}).Published().NotHidden().Filter()
+
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$sort": bson.M{"created_at": -1}},
+		{"$limit": relatedHashtagsSampleSize},
+		{"$project": bson.M{"hashtags": 1}},
+		{"$unwind": "$hashtags"},
+		{"$group": bson.M{
+			"_id":   bson.M{"$toLower": "$hashtags"},
+			"posts": bson.M{"$addToSet": "$_id"},
+		}},
+		{"$match": bson.M{"_id": bson.M{"$ne": hashtag.NormalizedTag}}},
+		{"$project": bson.M{"count": bson.M{"$size": "$posts"}}},
+		{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		{"$limit": relatedHashtagsLimit},
+	}
+
+	// The sample size is needed for the score, so count it separately
+	sampled, err := hs.postCollection.CountDocuments(ctx, match, options.Count().SetLimit(relatedHashtagsSampleSize))
+	if err != nil {
+		return err
+	}
+
+	cursor, err := hs.postCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		Tag   string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return err
+	}
+
+	related := make([]models.HashtagCooccurrence, 0, len(rows))
+	tags := make([]string, 0, len(rows))
+	for _, row := range rows {
+		related = append(related, models.HashtagCooccurrence{
+			Tag:   row.Tag,
+			Count: row.Count,
+			Score: float64(row.Count) / float64(sampled),
+		})
+		tags = append(tags, row.Tag)
+	}
+
+	now := time.Now()
+	_, err = hs.hashtagCollection.UpdateOne(ctx, bson.M{"_id": hashtag.ID}, bson.M{
+		"$set": bson.M{
+			"frequently_used_with": related,
+			"related_tags":         tags,
+			"related_computed_at":  now,
+		},
+	})
+	return err
+}
+
+// StartRelatedJob runs RefreshRelatedHashtags every interval until StopRelatedJob is called
+func (hs *HashtagService) StartRelatedJob(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	hs.stopRelatedJob = cancel
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				start := time.Now()
+				refreshed, err := hs.RefreshRelatedHashtags(ctx)
+				if ctx.Err() == nil {
+					recordJobHeartbeat(hs.db, "related_hashtags", interval, err)
+				}
+				if err != nil && ctx.Err() == nil {
+					log.Printf("Related hashtags refresh failed after %d hashtags: %v", refreshed, err)
+					continue
+				}
+				log.Printf("Refreshed related hashtags for %d hashtags in %s", refreshed, time.Since(start).Round(time.Second))
+			}
+		}
+	}()
+}
+
+// StopRelatedJob stops the periodic related hashtags refresh
+func (hs *HashtagService) StopRelatedJob() {
+	if hs.stopRelatedJob != nil {
+		hs.stopRelatedJob()
+	}
+}
@@ -0,0 +1,116 @@
+// internal/services/image_encoder.go
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Media output formats, named by their file extension
+const (
+	ImageFormatJPEG = "jpg"
+	ImageFormatWebP = "webp"
+	ImageFormatAVIF = "avif"
+)
+
+// ImageEncoder writes an image to a file in one output format at the given
+// quality (1-100)
+type ImageEncoder interface {
+	Format() string
+	MimeType() string
+	Encode(ctx context.Context, img image.Image, quality int, dst string) error
+}
+
+// JPEGEncoder encodes with the standard library; every client can display
+// its output, so it is always produced as the fallback
+type JPEGEncoder struct{}
+
+func (JPEGEncoder) Format() string   { return ImageFormatJPEG }
+func (JPEGEncoder) MimeType() string { return "image/jpeg" }
+
+// Encode writes the image as a baseline JPEG
+func (JPEGEncoder) Encode(ctx context.Context, img image.Image, quality int, dst string) error {
+	file, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return jpeg.Encode(file, img, &jpeg.Options{Quality: quality})
+}
+
+// CommandEncoder encodes by running an external tool on a PNG copy of the
+// image, for formats the standard library can't write
+type CommandEncoder struct {
+	format   string
+	mimeType string
+	binary   string
+	args     func(quality int, src, dst string) []string
+}
+
+// NewWebPEncoder encodes with libwebp's cwebp
+func NewWebPEncoder(binary string) *CommandEncoder {
+	return &CommandEncoder{
+		format:   ImageFormatWebP,
+		mimeType: "image/webp",
+		binary:   binary,
+		args: func(quality int, src, dst string) []string {
+			return []string{"-quiet", "-q", strconv.Itoa(quality), src, "-o", dst}
+		},
+	}
+}
+
+// NewAVIFEncoder encodes with libavif's avifenc
+func NewAVIFEncoder(binary string) *CommandEncoder {
+	return &CommandEncoder{
+		format:   ImageFormatAVIF,
+		mimeType: "image/avif",
+		binary:   binary,
+		args: func(quality int, src, dst string) []string {
+			return []string{"-q", strconv.Itoa(quality), src, dst}
+		},
+	}
+}
+
+func (ce *CommandEncoder) Format() string   { return ce.format }
+func (ce *CommandEncoder) MimeType() string { return ce.mimeType }
+
+// Available checks if the encoder's tool can be found
+func (ce *CommandEncoder) Available() bool {
+	_, err := exec.LookPath(ce.binary)
+	return err == nil
+}
+
+// Encode writes the image to a temporary PNG and converts it with the tool
+func (ce *CommandEncoder) Encode(ctx context.Context, img image.Image, quality int, dst string) error {
+	src, err := os.CreateTemp("", "media-*.png")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(src.Name())
+
+	if err := png.Encode(src, img); err != nil {
+		src.Close()
+		return err
+	}
+	if err := src.Close(); err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, ce.binary, ce.args(quality, src.Name(), dst)...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		os.Remove(dst)
+		return fmt.Errorf("%s failed: %v: %s", ce.binary, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
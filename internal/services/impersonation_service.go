@@ -0,0 +1,228 @@
+// internal/services/impersonation_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ImpersonationPolicy controls support sessions in which a super admin acts
+// as a user
+type ImpersonationPolicy struct {
+	TokenTTL   time.Duration // How long an impersonation token lasts; it can't be refreshed
+	NotifyUser string        // models.ImpersonationNotifyAlways, Never or Optional
+}
+
+// ImpersonationService lets super admins sign in as a user to reproduce a
+// reported issue. The token names both the user and the admin, and every
+// request made with it is checked against its session here, so ending the
+// session stops the token immediately. Each request is written to the audit
+// log with impersonated_by set to the admin.
+type ImpersonationService struct {
+	collection     *mongo.Collection
+	userCollection *mongo.Collection
+	db             *mongo.Database
+
+	notificationService *NotificationService
+	jwtSecret           []byte
+	policy              ImpersonationPolicy
+}
+
+func NewImpersonationService(db *mongo.Database, notificationService *NotificationService, jwtSecret string, policy ImpersonationPolicy) *ImpersonationService {
+	return &ImpersonationService{
+		collection:          db.Collection("impersonation_sessions"),
+		userCollection:      db.Collection("users"),
+		db:                  db,
+		notificationService: notificationService,
+		jwtSecret:           []byte(jwtSecret),
+		policy:              policy,
+	}
+}
+
+// StartImpersonation opens a session for an admin to act as a user and
+// issues its access token. The token carries both identities: user_id is
+// the user, impersonated_by the admin. Staff accounts can't be impersonated.
+func (is *ImpersonationService) StartImpersonation(adminID, userID primitive.ObjectID, req models.StartImpersonationRequest, ipAddress, userAgent string) (*models.ImpersonationResponse, error) {
+	if adminID == userID {
+		return nil, errors.New("cannot impersonate yourself")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var user models.User
+	err := is.userCollection.FindOne(ctx, repository.ByID(userID)).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	if !user.IsActive || user.IsSuspended {
+		return nil, errors.New("account suspended or inactive")
+	}
+	if user.Role == models.RoleModerator || user.Role == models.RoleAdmin || user.Role == models.RoleSuperAdmin {
+		return nil, errors.New("staff accounts cannot be impersonated")
+	}
+
+	notify := is.policy.NotifyUser == models.ImpersonationNotifyAlways ||
+		(is.policy.NotifyUser == models.ImpersonationNotifyOptional && req.NotifyUser)
+
+	now := time.Now()
+	session := &models.ImpersonationSession{
+		AdminID:      adminID,
+		UserID:       userID,
+		Reason:       req.Reason,
+		UserNotified: notify,
+		ExpiresAt:    now.Add(is.policy.TokenTTL),
+		IPAddress:    ipAddress,
+	}
+	session.BeforeCreate()
+
+	result, err := is.collection.InsertOne(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	session.ID = result.InsertedID.(primitive.ObjectID)
+
+	claims := jwt.MapClaims{
+		"user_id":          user.ID.Hex(),
+		"username":         user.Username,
+		"email":            user.Email,
+		"role":             user.Role,
+		"session_id":       session.ID.Hex(),
+		"impersonated_by":  adminID.Hex(),
+		"impersonation_id": session.ID.Hex(),
+		"token_type":       "access",
+		"iat":              now.Unix(),
+		"exp":              session.ExpiresAt.Unix(),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(is.jwtSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	createAuditLog(ctx, is.db, &models.AuditLog{
+		Action:     "impersonation_started",
+		ActorID:    adminID,
+		ActorType:  "admin",
+		TargetType: "user",
+		TargetID:   userID,
+		NewValues: map[string]interface{}{
+			"expires_at":    session.ExpiresAt,
+			"user_notified": notify,
+		},
+		Reason:    req.Reason,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		SessionID: session.ID.Hex(),
+	})
+
+	if notify {
+		if err := is.notificationService.NotifySupportAccess(userID, session.ID); err != nil {
+			log.Printf("Failed to notify user %s of impersonation session %s: %v", userID.Hex(), session.ID.Hex(), err)
+		}
+	}
+
+	log.Printf("Admin %s started impersonating user %s (session %s)", adminID.Hex(), userID.Hex(), session.ID.Hex())
+
+	return &models.ImpersonationResponse{
+		Session:     *session,
+		Account:     user.ToUserResponse(),
+		AccessToken: token,
+		ExpiresIn:   int64(is.policy.TokenTTL.Seconds()),
+		TokenType:   "Bearer",
+	}, nil
+}
+
+// EndImpersonation ends a session early; its token stops working at once
+func (is *ImpersonationService) EndImpersonation(sessionID, adminID primitive.ObjectID, ipAddress, userAgent string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	var session models.ImpersonationSession
+	err := is.collection.FindOneAndUpdate(ctx, bson.M{
+		"_id":      sessionID,
+		"admin_id": adminID,
+		"ended_at": bson.M{"$exists": false},
+	}, bson.M{
+		"$set": bson.M{
+			"ended_at":   now,
+			"updated_at": now,
+		},
+	}).Decode(&session)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.New("impersonation session not found")
+		}
+		return err
+	}
+
+	createAuditLog(ctx, is.db, &models.AuditLog{
+		Action:     "impersonation_ended",
+		ActorID:    adminID,
+		ActorType:  "admin",
+		TargetType: "user",
+		TargetID:   session.UserID,
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		SessionID:  sessionID.Hex(),
+	})
+
+	return nil
+}
+
+// CheckImpersonation confirms an impersonation token is still backed by an
+// open session between the same admin and user
+func (is *ImpersonationService) CheckImpersonation(ctx context.Context, sessionID, userID, adminID primitive.ObjectID) (*models.ImpersonationSession, error) {
+	var session models.ImpersonationSession
+	if err := is.collection.FindOne(ctx, bson.M{"_id": sessionID}).Decode(&session); err != nil {
+		return nil, err
+	}
+
+	if !session.IsActive() || session.UserID != userID || session.AdminID != adminID {
+		return nil, errors.New("impersonation session ended")
+	}
+
+	return &session, nil
+}
+
+// RecordRequest writes a request made under an impersonation session to the
+// audit log, as the user's action tagged with the admin behind it
+func (is *ImpersonationService) RecordRequest(session *models.ImpersonationSession, method, path string, status int, ipAddress, userAgent string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	adminID := session.AdminID
+	err := createAuditLog(ctx, is.db, &models.AuditLog{
+		Action:     "impersonated_request",
+		ActorID:    session.UserID,
+		ActorType:  "user",
+		TargetType: "user",
+		TargetID:   session.UserID,
+		Changes: map[string]interface{}{
+			"method": method,
+			"path":   path,
+			"status": status,
+		},
+		IPAddress:      ipAddress,
+		UserAgent:      userAgent,
+		SessionID:      session.ID.Hex(),
+		ImpersonatedBy: &adminID,
+	})
+	if err != nil {
+		log.Printf("Failed to audit impersonated request %s %s (session %s): %v", method, path, session.ID.Hex(), err)
+	}
+}
@@ -4,10 +4,12 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"social-media-api/internal/config"
 	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -47,6 +49,11 @@ func (ls *LikeService) CreateLike(userID primitive.ObjectID, req models.CreateLi
 		return nil, errors.New("invalid target ID")
 	}
 
+	// Only reactions in the active set can be used for new reactions
+	if err := ensureReactionAvailable(ctx, ls.db, req.ReactionType); err != nil {
+		return nil, err
+	}
+
 	// Validate target exists and user can interact with it
 	if err := ls.validateTarget(targetID, req.TargetType, userID); err != nil {
 		return nil, err
@@ -73,6 +80,10 @@ func (ls *LikeService) CreateLike(userID primitive.ObjectID, req models.CreateLi
 			return nil, err
 		}
 
+		if collection := ls.reactionCountCollection(req.TargetType); collection != nil {
+			adjustReactionCounts(ctx, collection, targetID, existingLike.ReactionType, req.ReactionType)
+		}
+
 		existingLike.ReactionType = req.ReactionType
 		existingLike.UpdatedAt = time.Now()
 
@@ -102,7 +113,7 @@ func (ls *LikeService) CreateLike(userID primitive.ObjectID, req models.CreateLi
 	like.ID = result.InsertedID.(primitive.ObjectID)
 
 	// Update target engagement counts
-	go ls.updateTargetCounts(targetID, req.TargetType, true)
+	go ls.updateTargetCounts(targetID, req.TargetType, like.ReactionType, true)
 
 	// Update user engagement stats
 	go ls.updateUserEngagementStats(userID, req.TargetType, true)
@@ -135,6 +146,10 @@ func (ls *LikeService) UpdateLike(likeID, userID primitive.ObjectID, req models.
 		return nil, err
 	}
 
+	if err := ensureReactionAvailable(ctx, ls.db, req.ReactionType); err != nil {
+		return nil, err
+	}
+
 	// Update reaction type
 	update := bson.M{
 		"$set": bson.M{
@@ -148,6 +163,10 @@ func (ls *LikeService) UpdateLike(likeID, userID primitive.ObjectID, req models.
 		return nil, err
 	}
 
+	if collection := ls.reactionCountCollection(like.TargetType); collection != nil {
+		adjustReactionCounts(ctx, collection, like.TargetID, like.ReactionType, req.ReactionType)
+	}
+
 	like.ReactionType = req.ReactionType
 	like.UpdatedAt = time.Now()
 
@@ -163,22 +182,22 @@ func (ls *LikeService) DeleteLike(targetID, userID primitive.ObjectID, targetTyp
 	defer cancel()
 
 	// Find and delete the like
-	result, err := ls.collection.DeleteOne(ctx, bson.M{
+	var like models.Like
+	err := ls.collection.FindOneAndDelete(ctx, bson.M{
 		"user_id":     userID,
 		"target_id":   targetID,
 		"target_type": targetType,
-	})
+	}).Decode(&like)
 
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.New("like not found")
+		}
 		return err
 	}
 
-	if result.DeletedCount == 0 {
-		return errors.New("like not found")
-	}
-
 	// Update target engagement counts
-	go ls.updateTargetCounts(targetID, targetType, false)
+	go ls.updateTargetCounts(targetID, targetType, like.ReactionType, false)
 
 	// Update user engagement stats
 	go ls.updateUserEngagementStats(userID, targetType, false)
@@ -386,6 +405,9 @@ func (ls *LikeService) GetDetailedReactionStats(targetID primitive.ObjectID, tar
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// Refresh names and emoji for reactions added since the last load
+	loadReactionCatalog(ctx, ls.db)
+
 	pipeline := []bson.M{
 		{
 			"$match": bson.M{
@@ -492,6 +514,9 @@ func (ls *LikeService) GetTrendingReactions(targetType *string, timeRange time.D
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
+	// Refresh names and emoji for reactions added since the last load
+	loadReactionCatalog(ctx, ls.db)
+
 	matchFilter := bson.M{
 		"created_at": bson.M{
 			"$gte": time.Now().Add(-timeRange),
@@ -549,6 +574,126 @@ func (ls *LikeService) GetTrendingReactions(targetType *string, timeRange time.D
 	return reactions, nil
 }
 
+// SyncReactions applies reaction changes a client queued while offline.
+// Changes to the same target are resolved by client timestamp: only the
+// latest is applied (the later item in the request on a tie) and the rest
+// are superseded. The latest is also superseded when the user's reaction on
+// the server changed after it, such as from another device; a removal on
+// another device leaves nothing to compare with, so it is not detected.
+// Resending a sync leaves the reactions as they are.
+func (ls *LikeService) SyncReactions(userID primitive.ObjectID, items []models.ReactionSyncItem) (*models.ReactionSyncResponse, error) {
+	if len(items) > models.MaxReactionSyncItems {
+		return nil, fmt.Errorf("too many items: at most %d reactions can be synced at once", models.MaxReactionSyncItems)
+	}
+
+	type target struct {
+		id         string
+		targetType string
+	}
+
+	results := make([]models.ReactionSyncResult, len(items))
+	latest := make(map[target]int)
+	var targets []target
+	now := time.Now()
+	for i, item := range items {
+		results[i] = models.ReactionSyncResult{
+			Index:      i,
+			TargetID:   item.TargetID,
+			TargetType: item.TargetType,
+		}
+		// A device clock running ahead can't claim a change from the future
+		if item.ClientTimestamp.After(now) {
+			items[i].ClientTimestamp = now
+		}
+
+		key := target{id: item.TargetID, targetType: item.TargetType}
+		previous, seen := latest[key]
+		if !seen {
+			targets = append(targets, key)
+			latest[key] = i
+			continue
+		}
+		if items[i].ClientTimestamp.Before(items[previous].ClientTimestamp) {
+			results[i].Status = models.ReactionSyncSuperseded
+			continue
+		}
+		results[previous].Status = models.ReactionSyncSuperseded
+		latest[key] = i
+	}
+
+	response := &models.ReactionSyncResponse{Results: results}
+	for _, key := range targets {
+		i := latest[key]
+		status, err := ls.applySyncItem(userID, items[i])
+		if err != nil {
+			results[i].Status = models.ReactionSyncFailed
+			results[i].Error = err.Error()
+		} else {
+			results[i].Status = status
+		}
+
+		state := models.ReactionState{TargetID: key.id, TargetType: key.targetType}
+		if targetID, err := primitive.ObjectIDFromHex(key.id); err == nil {
+			state.ReactionType, _ = ls.CheckUserReaction(targetID, userID, key.targetType)
+		}
+		response.States = append(response.States, state)
+	}
+
+	return response, nil
+}
+
+// applySyncItem applies the winning change for one target of a sync
+func (ls *LikeService) applySyncItem(userID primitive.ObjectID, item models.ReactionSyncItem) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	targetID, err := primitive.ObjectIDFromHex(item.TargetID)
+	if err != nil {
+		return "", errors.New("invalid target ID")
+	}
+	if item.Action == models.ReactionActionAdd && item.ReactionType == "" {
+		return "", errors.New("reaction_type is required to add a reaction")
+	}
+
+	var existing models.Like
+	err = ls.collection.FindOne(ctx, bson.M{
+		"user_id":     userID,
+		"target_id":   targetID,
+		"target_type": item.TargetType,
+	}).Decode(&existing)
+	found := err == nil
+	if err != nil && err != mongo.ErrNoDocuments {
+		return "", err
+	}
+
+	switch {
+	case item.Action == models.ReactionActionAdd && found && existing.ReactionType == item.ReactionType,
+		item.Action == models.ReactionActionRemove && !found:
+		return models.ReactionSyncUnchanged, nil
+	case found && existing.UpdatedAt.After(item.ClientTimestamp):
+		return models.ReactionSyncSuperseded, nil
+	}
+
+	if item.Action == models.ReactionActionRemove {
+		if err := ls.DeleteLike(targetID, userID, item.TargetType); err != nil {
+			if err.Error() == "like not found" {
+				return models.ReactionSyncUnchanged, nil
+			}
+			return "", err
+		}
+		return models.ReactionSyncApplied, nil
+	}
+
+	if _, err := ls.CreateLike(userID, models.CreateLikeRequest{
+		TargetID:     item.TargetID,
+		TargetType:   item.TargetType,
+		ReactionType: item.ReactionType,
+	}); err != nil {
+		return "", err
+	}
+	return models.ReactionSyncApplied, nil
+}
+
 // Helper methods
 
 // validateTarget validates that target exists and user can interact with it
@@ -562,34 +707,28 @@ func (ls *LikeService) validateTarget(targetID primitive.ObjectID, targetType st
 	switch targetType {
 	case "post":
 		collection = ls.postCollection
-		filter = bson.M{
+		filter = repository.NotDeleted(bson.M{
 			"_id":           targetID,
 			"is_published":  true,
 			"likes_enabled": true,
-			"deleted_at":    bson.M{"$exists": false},
-		}
+		})
 	case "comment":
 		collection = ls.commentCollection
-		filter = bson.M{
+		filter = repository.NotDeleted(bson.M{
 			"_id":         targetID,
 			"is_approved": true,
 			"is_hidden":   false,
-			"deleted_at":  bson.M{"$exists": false},
-		}
+		})
 	case "story":
 		collection = ls.storyCollection
-		filter = bson.M{
+		filter = repository.NotDeleted(bson.M{
 			"_id":             targetID,
 			"is_expired":      false,
 			"allow_reactions": true,
-			"deleted_at":      bson.M{"$exists": false},
-		}
+		})
 	case "message":
 		collection = ls.messageCollection
-		filter = bson.M{
-			"_id":        targetID,
-			"deleted_at": bson.M{"$exists": false},
-		}
+		filter = repository.ByID(targetID)
 	default:
 		return errors.New("invalid target type")
 	}
@@ -607,7 +746,7 @@ func (ls *LikeService) validateTarget(targetID primitive.ObjectID, targetType st
 }
 
 // updateTargetCounts updates engagement counts on the target
-func (ls *LikeService) updateTargetCounts(targetID primitive.ObjectID, targetType string, increment bool) {
+func (ls *LikeService) updateTargetCounts(targetID primitive.ObjectID, targetType string, reactionType models.ReactionType, increment bool) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -630,14 +769,31 @@ func (ls *LikeService) updateTargetCounts(targetID primitive.ObjectID, targetTyp
 		return
 	}
 
+	inc := bson.M{"likes_count": value}
+	if ls.reactionCountCollection(targetType) != nil && reactionType != "" {
+		inc["reaction_counts."+string(reactionType)] = value
+	}
+
 	update := bson.M{
-		"$inc": bson.M{"likes_count": value},
+		"$inc": inc,
 		"$set": bson.M{"updated_at": time.Now()},
 	}
 
 	collection.UpdateOne(ctx, bson.M{"_id": targetID}, update)
 }
 
+// reactionCountCollection returns the collection keeping per-reaction counters for a target type
+func (ls *LikeService) reactionCountCollection(targetType string) *mongo.Collection {
+	switch targetType {
+	case "post":
+		return ls.postCollection
+	case "comment":
+		return ls.commentCollection
+	default:
+		return nil
+	}
+}
+
 // updateUserEngagementStats updates user engagement statistics
 func (ls *LikeService) updateUserEngagementStats(userID primitive.ObjectID, targetType string, increment bool) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
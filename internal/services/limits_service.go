@@ -0,0 +1,276 @@
+// internal/services/limits_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"social-media-api/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LimitsService resolves the ceilings that apply to a user from their tier
+// and keeps the rolling daily usage counters. Tier limits are cached in
+// memory for cacheTTL, so an admin change reaches every instance within
+// that time without a restart.
+type LimitsService struct {
+	collection      *mongo.Collection
+	usageCollection *mongo.Collection
+	userCollection  *mongo.Collection
+	db              *mongo.Database
+	cacheTTL        time.Duration
+
+	mu    sync.RWMutex
+	cache map[models.LimitTier]cachedTierLimits
+}
+
+type cachedTierLimits struct {
+	limits   models.TierLimits
+	loadedAt time.Time
+}
+
+// EffectiveLimits are the ceilings that apply to one user
+type EffectiveLimits struct {
+	UserID primitive.ObjectID
+	models.TierLimits
+}
+
+func NewLimitsService(db *mongo.Database, cacheTTL time.Duration) *LimitsService {
+	return &LimitsService{
+		collection:      db.Collection("tier_limits"),
+		usageCollection: db.Collection("limit_usage"),
+		userCollection:  db.Collection("users"),
+		db:              db,
+		cacheTTL:        cacheTTL,
+		cache:           make(map[models.LimitTier]cachedTierLimits),
+	}
+}
+
+// GetTierLimits returns a tier's limits, falling back to the defaults when
+// none are stored
+func (ls *LimitsService) GetTierLimits(ctx context.Context, tier models.LimitTier) (models.TierLimits, error) {
+	ls.mu.RLock()
+	cached, ok := ls.cache[tier]
+	ls.mu.RUnlock()
+	if ok && time.Since(cached.loadedAt) < ls.cacheTTL {
+		return cached.limits, nil
+	}
+
+	limits := models.DefaultTierLimits(tier)
+	err := ls.collection.FindOne(ctx, bson.M{"tier": tier}).Decode(&limits)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return models.TierLimits{}, err
+	}
+
+	ls.mu.Lock()
+	ls.cache[tier] = cachedTierLimits{limits: limits, loadedAt: time.Now()}
+	ls.mu.Unlock()
+
+	return limits, nil
+}
+
+// ListTierLimits returns the limits of every tier
+func (ls *LimitsService) ListTierLimits() ([]models.TierLimits, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tiers := []models.LimitTier{models.LimitTierFree, models.LimitTierPremium, models.LimitTierStaff}
+	limits := make([]models.TierLimits, 0, len(tiers))
+	for _, tier := range tiers {
+		tierLimits, err := ls.GetTierLimits(ctx, tier)
+		if err != nil {
+			return nil, err
+		}
+		limits = append(limits, tierLimits)
+	}
+	return limits, nil
+}
+
+// UpdateTierLimits changes a tier's ceilings and records the change in the audit log
+func (ls *LimitsService) UpdateTierLimits(tier models.LimitTier, req models.UpdateTierLimitsRequest, adminID primitive.ObjectID, ipAddress, userAgent string) (*models.TierLimits, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if !models.IsValidLimitTier(tier) {
+		return nil, errors.New("invalid limit tier")
+	}
+
+	// Read through the cache so the audit entry records what was stored
+	ls.invalidate(tier)
+	current, err := ls.GetTierLimits(ctx, tier)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := current
+	updated.Apply(req)
+	now := time.Now()
+	updated.UpdatedAt = &now
+	updated.UpdatedBy = &adminID
+
+	var stored models.TierLimits
+	err = ls.collection.FindOneAndUpdate(ctx, bson.M{"tier": tier}, bson.M{
+		"$set": bson.M{
+			"post_length":            updated.PostLength,
+			"media_per_post":         updated.MediaPerPost,
+			"story_duration":         updated.StoryDuration,
+			"bio_length":             updated.BioLength,
+			"daily_posts":            updated.DailyPosts,
+			"daily_non_follower_dms": updated.DailyNonFollowerDMs,
+			"updated_at":             now,
+			"updated_by":             adminID,
+		},
+	}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&stored)
+	if err != nil {
+		return nil, err
+	}
+	ls.invalidate(tier)
+
+	err = createAuditLog(ctx, ls.db, &models.AuditLog{
+		Action:     "tier_limits_updated",
+		ActorID:    adminID,
+		ActorType:  "admin",
+		TargetType: "tier_limits",
+		TargetID:   stored.ID,
+		OldValues:  tierLimitsValues(current),
+		NewValues:  tierLimitsValues(stored),
+		Reason:     req.Reason,
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &stored, nil
+}
+
+// GetEffectiveLimits returns the limits that apply to the user
+func (ls *LimitsService) GetEffectiveLimits(ctx context.Context, userID primitive.ObjectID) (*EffectiveLimits, error) {
+	var user models.User
+	err := ls.userCollection.FindOne(ctx, bson.M{"_id": userID},
+		options.FindOne().SetProjection(bson.M{"role": 1, "is_premium": 1, "premium_expiry": 1})).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	limits, err := ls.GetTierLimits(ctx, models.LimitTierFor(&user, time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	return &EffectiveLimits{UserID: userID, TierLimits: limits}, nil
+}
+
+// GetUserLimits returns the caller's limits along with their usage in the current window
+func (ls *LimitsService) GetUserLimits(userID primitive.ObjectID) (*models.UserLimitsResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	limits, err := ls.GetEffectiveLimits(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := ls.usage(ctx, userID, models.LimitDailyPosts)
+	if err != nil {
+		return nil, err
+	}
+	dms, err := ls.usage(ctx, userID, models.LimitDailyNonFollowerDMs)
+	if err != nil {
+		return nil, err
+	}
+	media, err := ls.usage(ctx, userID, models.LimitDailyMessageMedia)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserLimitsResponse{
+		Tier:   limits.Tier,
+		Limits: limits.TierLimits,
+		Usage: models.LimitUsageCounters{
+			PostsToday:          posts,
+			NonFollowerDMsToday: dms,
+			MessageMediaToday:   media,
+		},
+		Window: models.LimitWindow.String(),
+	}, nil
+}
+
+// Consume counts one use of a daily limit, returning a LimitExceededError
+// when the rolling window is already full. The use is recorded before
+// counting so concurrent requests can't both slip under the ceiling; callers
+// Release it if the action then fails.
+func (ls *LimitsService) Consume(ctx context.Context, limits *EffectiveLimits, name models.LimitName) (primitive.ObjectID, error) {
+	ceiling := limits.Ceiling(name)
+	if ceiling == 0 {
+		return primitive.NilObjectID, nil
+	}
+
+	result, err := ls.usageCollection.InsertOne(ctx, models.LimitUsage{
+		UserID:    limits.UserID,
+		Limit:     name,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	usageID := result.InsertedID.(primitive.ObjectID)
+
+	used, err := ls.usage(ctx, limits.UserID, name)
+	if err != nil {
+		ls.Release(ctx, usageID)
+		return primitive.NilObjectID, err
+	}
+	if used > int64(ceiling) {
+		ls.Release(ctx, usageID)
+		return primitive.NilObjectID, &models.LimitExceededError{Limit: name, Tier: limits.Tier, Ceiling: ceiling}
+	}
+
+	return usageID, nil
+}
+
+// Release gives back a use recorded by Consume
+func (ls *LimitsService) Release(ctx context.Context, usageID primitive.ObjectID) {
+	if usageID.IsZero() {
+		return
+	}
+	ls.usageCollection.DeleteOne(ctx, bson.M{"_id": usageID})
+}
+
+// usage counts the user's uses of a daily limit in the trailing window
+func (ls *LimitsService) usage(ctx context.Context, userID primitive.ObjectID, name models.LimitName) (int64, error) {
+	return ls.usageCollection.CountDocuments(ctx, bson.M{
+		"user_id":    userID,
+		"limit":      name,
+		"created_at": bson.M{"$gt": time.Now().Add(-models.LimitWindow)},
+	})
+}
+
+func (ls *LimitsService) invalidate(tier models.LimitTier) {
+	ls.mu.Lock()
+	delete(ls.cache, tier)
+	ls.mu.Unlock()
+}
+
+// tierLimitsValues flattens limits for the audit log
+func tierLimitsValues(limits models.TierLimits) map[string]interface{} {
+	return map[string]interface{}{
+		"tier":                   limits.Tier,
+		"post_length":            limits.PostLength,
+		"media_per_post":         limits.MediaPerPost,
+		"story_duration":         limits.StoryDuration,
+		"bio_length":             limits.BioLength,
+		"daily_posts":            limits.DailyPosts,
+		"daily_non_follower_dms": limits.DailyNonFollowerDMs,
+		"daily_message_media":    limits.DailyMessageMedia,
+	}
+}
@@ -0,0 +1,484 @@
+// internal/services/link_blocklist_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxShortenerHops is how many redirects are followed when resolving a
+// shortened link
+const maxShortenerHops = 3
+
+// LinkBlocklistPolicy configures link screening. The blocklist is cached in
+// memory for CacheTTL. With ResolveShorteners set, links on the Shorteners
+// hosts are followed (without fetching the target) to screen the host they
+// redirect to.
+type LinkBlocklistPolicy struct {
+	CacheTTL          time.Duration
+	ResolveShorteners bool
+	Shorteners        []string
+	ResolveTimeout    time.Duration
+}
+
+// LinkBlocklistService screens posts, comments and messages for links to
+// blocklisted domains. Links to any other domain are left alone.
+type LinkBlocklistService struct {
+	collection    *mongo.Collection
+	logCollection *mongo.Collection
+	db            *mongo.Database
+	policy        LinkBlocklistPolicy
+	shorteners    map[string]bool
+	client        *http.Client
+
+	mu       sync.RWMutex
+	rules    []models.BlockedDomain
+	loadedAt time.Time
+}
+
+func NewLinkBlocklistService(db *mongo.Database, policy LinkBlocklistPolicy) *LinkBlocklistService {
+	shorteners := make(map[string]bool)
+	for _, host := range policy.Shorteners {
+		if host = models.NormalizeLinkHost(host); host != "" {
+			shorteners[host] = true
+		}
+	}
+
+	return &LinkBlocklistService{
+		collection:    db.Collection("blocked_domains"),
+		logCollection: db.Collection("link_blocks"),
+		db:            db,
+		policy:        policy,
+		shorteners:    shorteners,
+		client: &http.Client{
+			Timeout: policy.ResolveTimeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Screen checks content before it is saved. Content linking to a domain
+// whose entry rejects it is refused and the block logged. For a domain whose
+// entry hides content the match is returned, and the caller saves the
+// content hidden and calls ReportHidden. Messages can't be hidden, so they
+// are refused for either action. A nil service screens nothing.
+func (lbs *LinkBlocklistService) Screen(ctx context.Context, userID primitive.ObjectID, contentType, content string) (*models.LinkBlockMatch, error) {
+	if lbs == nil || content == "" {
+		return nil, nil
+	}
+
+	match, err := lbs.Check(ctx, content)
+	if err != nil {
+		// A blocklist that can't be loaded doesn't stop people posting
+		log.Printf("Failed to screen %s links: %v", contentType, err)
+		return nil, nil
+	}
+	if match == nil {
+		return nil, nil
+	}
+
+	if match.Action == models.LinkBlockReject || contentType == models.LinkBlockContentMessage {
+		lbs.logBlock(ctx, userID, contentType, nil, match)
+		return nil, errors.New("content contains a blocked link")
+	}
+
+	return match, nil
+}
+
+// ScreenMessage screens a message sent over the chat WebSocket
+func (lbs *LinkBlocklistService) ScreenMessage(ctx context.Context, senderID primitive.ObjectID, content string) error {
+	_, err := lbs.Screen(ctx, senderID, models.LinkBlockContentMessage, content)
+	return err
+}
+
+// ReportHidden logs content saved hidden because of match and files an
+// automatic report so it can be reviewed
+func (lbs *LinkBlocklistService) ReportHidden(ctx context.Context, userID primitive.ObjectID, contentType string, contentID primitive.ObjectID, match *models.LinkBlockMatch) {
+	lbs.logBlock(ctx, userID, contentType, &contentID, match)
+
+	report := &models.Report{
+		TargetType:  contentType,
+		TargetID:    contentID,
+		Reason:      models.ReportSpam,
+		Description: "Link to blocklisted domain " + match.Domain,
+		Category:    models.LinkBlockReportCategory,
+		Evidence: map[string]interface{}{
+			"url":    match.URL,
+			"host":   match.Host,
+			"domain": match.Domain,
+		},
+		Source: "auto",
+	}
+	report.BeforeCreate()
+	report.AutoDetected = true
+
+	if _, err := lbs.db.Collection("reports").InsertOne(ctx, report); err != nil {
+		log.Printf("Failed to report %s %s hidden for a blocked link: %v", contentType, contentID.Hex(), err)
+	}
+}
+
+// Check returns the blocklist match in text, if any. A rejecting entry wins
+// over a hiding one.
+func (lbs *LinkBlocklistService) Check(ctx context.Context, text string) (*models.LinkBlockMatch, error) {
+	links := utils.ExtractLinks(text)
+	if len(links) == 0 {
+		return nil, nil
+	}
+
+	rules, err := lbs.getRules(ctx)
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+
+	var hidden *models.LinkBlockMatch
+	for _, link := range links {
+		parsed, err := utils.NormalizeLink(link)
+		if err != nil {
+			continue
+		}
+
+		host := models.NormalizeLinkHost(parsed.Host)
+		rule := matchBlockedDomain(rules, host)
+		if rule == nil && lbs.policy.ResolveShorteners && lbs.shorteners[host] {
+			if target := lbs.resolveShortener(ctx, parsed); target != "" {
+				host = target
+				rule = matchBlockedDomain(rules, host)
+			}
+		}
+		if rule == nil {
+			continue
+		}
+
+		match := &models.LinkBlockMatch{
+			URL:    parsed.String(),
+			Host:   host,
+			Domain: rule.Domain,
+			Action: rule.Action,
+		}
+		if match.Action == models.LinkBlockReject {
+			return match, nil
+		}
+		if hidden == nil {
+			hidden = match
+		}
+	}
+
+	return hidden, nil
+}
+
+// matchBlockedDomain returns the entry covering host. An exact entry beats
+// a wildcard, and a longer wildcard beats a shorter one.
+func matchBlockedDomain(rules []models.BlockedDomain, host string) *models.BlockedDomain {
+	var best *models.BlockedDomain
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Matches(host) {
+			continue
+		}
+		if !rule.IsWildcard() {
+			return rule
+		}
+		if best == nil || len(rule.Domain) > len(best.Domain) {
+			best = rule
+		}
+	}
+	return best
+}
+
+// resolveShortener follows a shortened link's redirects without fetching
+// the target and returns the host it ends up on, or "" when it can't tell
+func (lbs *LinkBlocklistService) resolveShortener(ctx context.Context, link *url.URL) string {
+	current := link
+	for hop := 0; hop < maxShortenerHops; hop++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, current.String(), nil)
+		if err != nil {
+			return ""
+		}
+
+		resp, err := lbs.client.Do(req)
+		if err != nil {
+			return ""
+		}
+		resp.Body.Close()
+
+		location, err := resp.Location()
+		if err != nil {
+			return ""
+		}
+
+		host := models.NormalizeLinkHost(location.Host)
+		if !lbs.shorteners[host] {
+			return host
+		}
+		current = location
+	}
+	return ""
+}
+
+// getRules returns the blocklist, reloading it once the cache has expired
+func (lbs *LinkBlocklistService) getRules(ctx context.Context) ([]models.BlockedDomain, error) {
+	lbs.mu.RLock()
+	rules, loadedAt := lbs.rules, lbs.loadedAt
+	lbs.mu.RUnlock()
+	if !loadedAt.IsZero() && time.Since(loadedAt) < lbs.policy.CacheTTL {
+		return rules, nil
+	}
+
+	cursor, err := lbs.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	rules = []models.BlockedDomain{}
+	if err := cursor.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+
+	lbs.mu.Lock()
+	lbs.rules = rules
+	lbs.loadedAt = time.Now()
+	lbs.mu.Unlock()
+
+	return rules, nil
+}
+
+// invalidate drops the cached blocklist so an admin change applies on this
+// instance right away
+func (lbs *LinkBlocklistService) invalidate() {
+	lbs.mu.Lock()
+	lbs.loadedAt = time.Time{}
+	lbs.mu.Unlock()
+}
+
+// logBlock records content the blocklist acted on
+func (lbs *LinkBlocklistService) logBlock(ctx context.Context, userID primitive.ObjectID, contentType string, contentID *primitive.ObjectID, match *models.LinkBlockMatch) {
+	entry := models.LinkBlockLog{
+		UserID:      userID,
+		ContentType: contentType,
+		ContentID:   contentID,
+		URL:         match.URL,
+		Host:        match.Host,
+		Domain:      match.Domain,
+		Action:      match.Action,
+		CreatedAt:   time.Now(),
+	}
+	if contentType == models.LinkBlockContentMessage {
+		// Messages are always refused, whatever the entry says
+		entry.Action = models.LinkBlockReject
+	}
+
+	if _, err := lbs.logCollection.InsertOne(ctx, entry); err != nil {
+		log.Printf("Failed to log blocked link %s: %v", match.URL, err)
+	}
+}
+
+// SeedDomains adds configured domains to the blocklist as rejecting entries.
+// Entries already on the list, including ones an admin changed, are left
+// as they are.
+func (lbs *LinkBlocklistService) SeedDomains(domains []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	var writes []mongo.WriteModel
+	for _, input := range domains {
+		if strings.TrimSpace(input) == "" {
+			continue
+		}
+		domain, err := models.NormalizeBlockedDomain(input)
+		if err != nil {
+			log.Printf("Skipping invalid blocklist domain %q", input)
+			continue
+		}
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"domain": domain}).
+			SetUpdate(bson.M{"$setOnInsert": models.BlockedDomain{
+				Domain:    domain,
+				Action:    models.LinkBlockReject,
+				Source:    "config",
+				CreatedAt: now,
+				UpdatedAt: now,
+			}}).
+			SetUpsert(true))
+	}
+	if len(writes) == 0 {
+		return nil
+	}
+
+	if _, err := lbs.collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		return err
+	}
+
+	lbs.invalidate()
+	return nil
+}
+
+// ListBlockedDomains returns the blocklist, most recently added first
+func (lbs *LinkBlocklistService) ListBlockedDomains(limit, skip int) ([]models.BlockedDomain, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	total, err := lbs.collection.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit)).
+		SetSkip(int64(skip))
+
+	cursor, err := lbs.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	domains := []models.BlockedDomain{}
+	if err := cursor.All(ctx, &domains); err != nil {
+		return nil, 0, err
+	}
+
+	return domains, total, nil
+}
+
+// AddBlockedDomain puts a domain on the blocklist. Entries reject content
+// unless told to hide it.
+func (lbs *LinkBlocklistService) AddBlockedDomain(adminID primitive.ObjectID, req models.CreateBlockedDomainRequest) (*models.BlockedDomain, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	domain, err := models.NormalizeBlockedDomain(req.Domain)
+	if err != nil {
+		return nil, err
+	}
+
+	action := req.Action
+	if action == "" {
+		action = models.LinkBlockReject
+	}
+
+	now := time.Now()
+	entry := &models.BlockedDomain{
+		Domain:    domain,
+		Action:    action,
+		Reason:    strings.TrimSpace(req.Reason),
+		Source:    "admin",
+		AddedBy:   &adminID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	result, err := lbs.collection.InsertOne(ctx, entry)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, errors.New("domain is already blocklisted")
+		}
+		return nil, err
+	}
+	entry.ID = result.InsertedID.(primitive.ObjectID)
+
+	lbs.invalidate()
+	return entry, nil
+}
+
+// UpdateBlockedDomain changes a blocklist entry's action or reason
+func (lbs *LinkBlocklistService) UpdateBlockedDomain(id primitive.ObjectID, req models.UpdateBlockedDomainRequest) (*models.BlockedDomain, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	set := bson.M{"updated_at": time.Now()}
+	if req.Action != nil {
+		set["action"] = *req.Action
+	}
+	if req.Reason != nil {
+		set["reason"] = strings.TrimSpace(*req.Reason)
+	}
+
+	var entry models.BlockedDomain
+	err := lbs.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&entry)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("blocked domain not found")
+		}
+		return nil, err
+	}
+
+	lbs.invalidate()
+	return &entry, nil
+}
+
+// RemoveBlockedDomain takes a domain off the blocklist. Content already
+// hidden because of it stays hidden until a moderator reviews it.
+func (lbs *LinkBlocklistService) RemoveBlockedDomain(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := lbs.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return errors.New("blocked domain not found")
+	}
+
+	lbs.invalidate()
+	return nil
+}
+
+// GetBlockLog returns logged blocks, newest first, optionally only those of
+// one blocklist entry
+func (lbs *LinkBlocklistService) GetBlockLog(domain string, limit, skip int) ([]models.LinkBlockLog, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if domain != "" {
+		normalized, err := models.NormalizeBlockedDomain(domain)
+		if err != nil {
+			return nil, 0, err
+		}
+		filter["domain"] = normalized
+	}
+
+	total, err := lbs.logCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit)).
+		SetSkip(int64(skip))
+
+	cursor, err := lbs.logCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []models.LinkBlockLog{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
@@ -0,0 +1,305 @@
+// internal/services/link_preview_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"social-media-api/internal/models"
+)
+
+const (
+	linkPreviewMaxRedirects      = 5
+	linkPreviewCacheSize         = 10000
+	linkPreviewTitleLength       = 200
+	linkPreviewDescriptionLength = 300
+	linkPreviewUserAgent         = "SocialMediaAPI-LinkPreview/1.0"
+)
+
+var (
+	previewMetaTag   = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	previewAttribute = regexp.MustCompile(`(?is)([a-z:_-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	previewTitleTag  = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+	// Ranges that aren't reachable on the public internet besides the ones
+	// net.IP reports on (loopback, private, link-local, multicast)
+	nonPublicNetworks = mustParseNetworks(
+		"0.0.0.0/8",
+		"100.64.0.0/10", // carrier-grade NAT
+		"192.0.0.0/24",
+		"198.18.0.0/15", // benchmarking
+		"240.0.0.0/4",
+		"64:ff9b::/96", // NAT64, which can reach private IPv4 addresses
+	)
+)
+
+// LinkPreviewPolicy controls the link previews fetched for post drafts
+type LinkPreviewPolicy struct {
+	Enabled  bool
+	MaxLinks int           // Links fetched per draft
+	Timeout  time.Duration // Bounds fetching one page, redirects included
+	MaxBytes int64         // Page bytes read looking for preview tags
+	CacheTTL time.Duration
+}
+
+// LinkPreviewService fetches the title, description and image of linked
+// pages. Only public addresses are fetched, so a draft can't make the server
+// probe its own network.
+type LinkPreviewService struct {
+	policy LinkPreviewPolicy
+	client *http.Client
+
+	mu    sync.Mutex
+	cache map[string]cachedLinkPreview
+}
+
+type cachedLinkPreview struct {
+	preview   models.LinkPreview
+	fetchedAt time.Time
+}
+
+func NewLinkPreviewService(policy LinkPreviewPolicy) *LinkPreviewService {
+	// Addresses are checked as they are dialed, after DNS resolution and on
+	// every redirect, so a public name resolving to a private address is
+	// refused too
+	dialer := &net.Dialer{Timeout: policy.Timeout, Control: dialPublicOnly}
+
+	return &LinkPreviewService{
+		policy: policy,
+		client: &http.Client{
+			Timeout: policy.Timeout,
+			Transport: &http.Transport{
+				DialContext:           dialer.DialContext,
+				TLSHandshakeTimeout:   policy.Timeout,
+				ResponseHeaderTimeout: policy.Timeout,
+				MaxIdleConns:          20,
+				IdleConnTimeout:       90 * time.Second,
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= linkPreviewMaxRedirects {
+					return errors.New("too many redirects")
+				}
+				if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+					return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+				}
+				return nil
+			},
+		},
+		cache: make(map[string]cachedLinkPreview),
+	}
+}
+
+// Fetch fills in the previews of the first MaxLinks links that aren't
+// blocked, fetching them in parallel. Links that can't be fetched are left
+// with Fetched false. A nil or disabled service fetches nothing.
+func (lps *LinkPreviewService) Fetch(ctx context.Context, previews []models.LinkPreview) {
+	if lps == nil || !lps.policy.Enabled {
+		return
+	}
+
+	var wg sync.WaitGroup
+	fetched := 0
+	for i := range previews {
+		if previews[i].Blocked {
+			continue
+		}
+		if fetched >= lps.policy.MaxLinks {
+			break
+		}
+		fetched++
+
+		wg.Add(1)
+		go func(preview *models.LinkPreview) {
+			defer wg.Done()
+			*preview = lps.preview(ctx, preview.URL)
+		}(&previews[i])
+	}
+	wg.Wait()
+}
+
+// preview returns the cached preview of a link, fetching it when there is
+// none. Failures are cached as well, so a draft being typed doesn't refetch
+// a dead link on every keystroke.
+func (lps *LinkPreviewService) preview(ctx context.Context, link string) models.LinkPreview {
+	lps.mu.Lock()
+	cached, ok := lps.cache[link]
+	lps.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < lps.policy.CacheTTL {
+		return cached.preview
+	}
+
+	preview, err := lps.fetch(ctx, link)
+	if err != nil {
+		preview = models.LinkPreview{URL: link}
+	}
+	if ctx.Err() != nil {
+		// The draft's request ran out of time, which says nothing about the link
+		return preview
+	}
+
+	lps.mu.Lock()
+	if len(lps.cache) >= linkPreviewCacheSize {
+		for key, entry := range lps.cache {
+			if time.Since(entry.fetchedAt) >= lps.policy.CacheTTL {
+				delete(lps.cache, key)
+			}
+		}
+	}
+	if len(lps.cache) < linkPreviewCacheSize {
+		lps.cache[link] = cachedLinkPreview{preview: preview, fetchedAt: time.Now()}
+	}
+	lps.mu.Unlock()
+
+	return preview
+}
+
+// fetch reads the preview tags of an HTML page
+func (lps *LinkPreviewService) fetch(ctx context.Context, link string) (models.LinkPreview, error) {
+	preview := models.LinkPreview{URL: link}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return preview, err
+	}
+	req.Header.Set("User-Agent", linkPreviewUserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := lps.client.Do(req)
+	if err != nil {
+		return preview, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return preview, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	contentType := strings.ToLower(resp.Header.Get("Content-Type"))
+	if !strings.Contains(contentType, "text/html") && !strings.Contains(contentType, "application/xhtml") {
+		return preview, fmt.Errorf("unsupported content type %q", contentType)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, lps.policy.MaxBytes))
+	if err != nil {
+		return preview, err
+	}
+
+	page := string(body)
+	meta := make(map[string]string)
+	for _, tag := range previewMetaTag.FindAllString(page, -1) {
+		var key, content string
+		for _, attr := range previewAttribute.FindAllStringSubmatch(tag, -1) {
+			value := attr[2] + attr[3] + attr[4]
+			switch strings.ToLower(attr[1]) {
+			case "property", "name":
+				key = strings.ToLower(strings.TrimSpace(value))
+			case "content":
+				content = previewText(value)
+			}
+		}
+		if key != "" && content != "" && meta[key] == "" {
+			meta[key] = content
+		}
+	}
+
+	title := firstNonEmpty(meta["og:title"], meta["twitter:title"])
+	if title == "" {
+		if match := previewTitleTag.FindStringSubmatch(page); match != nil {
+			title = previewText(match[1])
+		}
+	}
+	preview.Title = truncateRunes(title, linkPreviewTitleLength)
+	preview.Description = truncateRunes(firstNonEmpty(meta["og:description"], meta["twitter:description"], meta["description"]), linkPreviewDescriptionLength)
+	preview.ImageURL = resolvePreviewImage(resp.Request.URL, firstNonEmpty(meta["og:image"], meta["og:image:url"], meta["twitter:image"], meta["twitter:image:src"]))
+	preview.SiteName = firstNonEmpty(meta["og:site_name"], resp.Request.URL.Hostname())
+	preview.Fetched = preview.Title != "" || preview.Description != "" || preview.ImageURL != ""
+
+	return preview, nil
+}
+
+// previewText unescapes tag text and collapses its whitespace
+func previewText(text string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+}
+
+// resolvePreviewImage resolves an image reference against the page it was
+// found on, keeping only http and https images
+func resolvePreviewImage(page *url.URL, image string) string {
+	if image == "" {
+		return ""
+	}
+	ref, err := url.Parse(image)
+	if err != nil {
+		return ""
+	}
+	resolved := page.ResolveReference(ref)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return ""
+	}
+	return resolved.String()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-3]) + "..."
+}
+
+// dialPublicOnly refuses connections to addresses that aren't public
+func dialPublicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("refusing to fetch link preview from non-public address %s", host)
+	}
+	return nil
+}
+
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func mustParseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
@@ -0,0 +1,246 @@
+package services_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const testMaxResolution = 800
+
+// gradientImage is a width x height image with varied pixels, so encoders
+// don't collapse it
+func gradientImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: uint8(x + y), A: 255})
+		}
+	}
+	return img
+}
+
+// uploadImage encodes img by the file name's extension, uploads it through a
+// MediaService with the given output policy and waits for processing to
+// complete. It returns the upload result, the stored media and the bytes
+// that were uploaded.
+func uploadImage(t *testing.T, h *testutil.Harness, output services.MediaOutputPolicy, fileName string, img image.Image) (*services.UploadResult, models.Media, []byte) {
+	t.Helper()
+
+	var encoded bytes.Buffer
+	var err error
+	if filepath.Ext(fileName) == ".png" {
+		err = png.Encode(&encoded, img)
+	} else {
+		err = jpeg.Encode(&encoded, img, &jpeg.Options{Quality: 90})
+	}
+	if err != nil {
+		t.Fatalf("encoding %s: %v", fileName, err)
+	}
+
+	source := filepath.Join(t.TempDir(), fileName)
+	if err := os.WriteFile(source, encoded.Bytes(), 0600); err != nil {
+		t.Fatalf("writing upload: %v", err)
+	}
+	file, err := os.Open(source)
+	if err != nil {
+		t.Fatalf("opening upload: %v", err)
+	}
+	defer file.Close()
+
+	media := services.NewMediaService(t.TempDir(), "http://localhost", false, nil, services.MediaTieringPolicy{}, output, nil, nil)
+	result, err := media.UploadMedia(h.CreateUser().ID, file, &multipart.FileHeader{Filename: fileName, Size: int64(encoded.Len())}, models.CreateMediaRequest{
+		Type:     "image",
+		IsPublic: true,
+	})
+	if err != nil {
+		t.Fatalf("UploadMedia: %v", err)
+	}
+
+	var stored models.Media
+	h.Eventually(10*time.Second, func() bool {
+		err := h.DB.Collection("media").FindOne(h.Context(), bson.M{"_id": result.Media.ID}).Decode(&stored)
+		return err == nil && stored.ProcessingStatus == "completed"
+	}, "upload %s never finished processing", result.Media.ID.Hex())
+	return result, stored, encoded.Bytes()
+}
+
+// storedSize decodes the dimensions of the file at path
+func storedSize(t *testing.T, path string) (int, int) {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("opening stored file: %v", err)
+	}
+	defer file.Close()
+
+	cfg, _, err := image.DecodeConfig(file)
+	if err != nil {
+		t.Fatalf("decoding stored file: %v", err)
+	}
+	return cfg.Width, cfg.Height
+}
+
+// assertAspect checks width x height keeps the aspect ratio of the original.
+// The shorter edge may be off by the rounding of the downscale and of the
+// variant resize made from it.
+func assertAspect(t *testing.T, what string, width, height, originalWidth, originalHeight int) {
+	t.Helper()
+
+	var diff float64
+	if originalWidth >= originalHeight {
+		diff = float64(height) - float64(width)*float64(originalHeight)/float64(originalWidth)
+	} else {
+		diff = float64(width) - float64(height)*float64(originalWidth)/float64(originalHeight)
+	}
+	if diff > 2 || diff < -2 {
+		t.Errorf("%s is %dx%d, which doesn't keep the %dx%d aspect ratio", what, width, height, originalWidth, originalHeight)
+	}
+}
+
+// largeVariant returns the JPEG large variant of stored media
+func largeVariant(t *testing.T, stored models.Media) models.MediaVariant {
+	t.Helper()
+
+	for _, variant := range stored.Variants {
+		if variant.Name == "large" && variant.Format == services.ImageFormatJPEG {
+			return variant
+		}
+	}
+	t.Fatalf("no large JPEG variant in %+v", stored.Variants)
+	return models.MediaVariant{}
+}
+
+func TestOversizedImagesAreDownscaled(t *testing.T) {
+	h := testutil.NewHarness(t)
+
+	tests := []struct {
+		name                  string
+		fileName              string
+		width, height         int
+		wantWidth, wantHeight int
+	}{
+		{"landscape PNG", "wide.png", 2400, 600, 800, 200},
+		{"portrait JPEG", "tall.jpg", 500, 1500, 266, 800},
+		{"odd ratio PNG", "odd.png", 2047, 1365, 800, 533},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, stored, _ := uploadImage(t, h, services.MediaOutputPolicy{
+				KeepOriginal:  true,
+				MaxResolution: testMaxResolution,
+			}, tt.fileName, gradientImage(tt.width, tt.height))
+
+			// The upload already reports the size the image is stored at
+			if result.Media.Width != tt.wantWidth || result.Media.Height != tt.wantHeight {
+				t.Errorf("upload reported %dx%d, want %dx%d", result.Media.Width, result.Media.Height, tt.wantWidth, tt.wantHeight)
+			}
+			if result.Media.OriginalWidth != tt.width || result.Media.OriginalHeight != tt.height {
+				t.Errorf("upload reported original %dx%d, want %dx%d", result.Media.OriginalWidth, result.Media.OriginalHeight, tt.width, tt.height)
+			}
+
+			if stored.Width != tt.wantWidth || stored.Height != tt.wantHeight {
+				t.Errorf("stored media is %dx%d, want %dx%d", stored.Width, stored.Height, tt.wantWidth, tt.wantHeight)
+			}
+			if width, height := storedSize(t, stored.FilePath); width != tt.wantWidth || height != tt.wantHeight {
+				t.Errorf("stored file is %dx%d, want %dx%d", width, height, tt.wantWidth, tt.wantHeight)
+			}
+			assertAspect(t, "stored image", stored.Width, stored.Height, tt.width, tt.height)
+			if stored.StorageSavedBytes <= 0 {
+				t.Error("downscaling saved no storage")
+			}
+
+			// Variants are made from the downscaled source: the large variant
+			// (up to 1600px) can be no bigger than it
+			if large := largeVariant(t, stored); large.Width != tt.wantWidth || large.Height != tt.wantHeight {
+				t.Errorf("large variant is %dx%d, want the downscaled %dx%d", large.Width, large.Height, tt.wantWidth, tt.wantHeight)
+			}
+			if len(stored.Thumbnails) == 0 {
+				t.Fatal("no thumbnails were generated")
+			}
+			for _, thumbnail := range stored.Thumbnails {
+				assertAspect(t, thumbnail.Name+" variant", thumbnail.Width, thumbnail.Height, tt.width, tt.height)
+			}
+		})
+	}
+}
+
+func TestImagesWithinMaxResolutionAreUnchanged(t *testing.T) {
+	h := testutil.NewHarness(t)
+
+	tests := []struct {
+		name          string
+		fileName      string
+		width, height int
+	}{
+		{"smaller PNG", "small.png", 600, 300},
+		{"JPEG at the limit", "square.jpg", testMaxResolution, testMaxResolution},
+		{"PNG with one edge at the limit", "edge.png", 400, testMaxResolution},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, stored, uploaded := uploadImage(t, h, services.MediaOutputPolicy{
+				KeepOriginal:  true,
+				MaxResolution: testMaxResolution,
+			}, tt.fileName, gradientImage(tt.width, tt.height))
+
+			if result.Media.Width != tt.width || result.Media.Height != tt.height || result.Media.OriginalWidth != 0 {
+				t.Errorf("upload reported %dx%d (original width %d), want %dx%d and no original size",
+					result.Media.Width, result.Media.Height, result.Media.OriginalWidth, tt.width, tt.height)
+			}
+			if stored.Width != tt.width || stored.Height != tt.height || stored.OriginalWidth != 0 || stored.OriginalHeight != 0 {
+				t.Errorf("stored media is %dx%d (original %dx%d), want %dx%d and no original size",
+					stored.Width, stored.Height, stored.OriginalWidth, stored.OriginalHeight, tt.width, tt.height)
+			}
+
+			onDisk, err := os.ReadFile(stored.FilePath)
+			if err != nil {
+				t.Fatalf("reading stored file: %v", err)
+			}
+			if !bytes.Equal(onDisk, uploaded) {
+				t.Error("stored file differs from the upload")
+			}
+		})
+	}
+}
+
+func TestKeepFullResolutionOnlyDownscalesVariants(t *testing.T) {
+	h := testutil.NewHarness(t)
+
+	result, stored, uploaded := uploadImage(t, h, services.MediaOutputPolicy{
+		KeepOriginal:       true,
+		MaxResolution:      testMaxResolution,
+		KeepFullResolution: true,
+	}, "wide.png", gradientImage(2400, 600))
+
+	if result.Media.Width != 2400 || result.Media.Height != 600 {
+		t.Errorf("upload reported %dx%d, want the full 2400x600", result.Media.Width, result.Media.Height)
+	}
+	onDisk, err := os.ReadFile(stored.FilePath)
+	if err != nil {
+		t.Fatalf("reading stored file: %v", err)
+	}
+	if !bytes.Equal(onDisk, uploaded) {
+		t.Error("full resolution original was rewritten")
+	}
+
+	if large := largeVariant(t, stored); large.Width != testMaxResolution || large.Height != 200 {
+		t.Errorf("large variant is %dx%d, want it made from the %dx200 downscaled source", large.Width, large.Height, testMaxResolution)
+	}
+}
@@ -3,17 +3,27 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"image"
+	"image/png"
 	"io"
+	"log"
 	"mime/multipart"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"social-media-api/internal/config"
 	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+	"social-media-api/internal/storage"
 	"social-media-api/internal/utils"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -22,23 +32,115 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+const (
+	// OrphanCleanupInterval is how often the orphan cleanup job removes files of deleted media
+	OrphanCleanupInterval = time.Hour
+	// orphanFileGrace keeps a deleted media file on disk this long before the job removes it
+	orphanFileGrace = 24 * time.Hour
+	// orphanCleanupBatch caps how many files one cleanup run removes
+	orphanCleanupBatch = 500
+
+	// MediaTieringInterval is how often the tiering job moves unread originals to the cold tier
+	MediaTieringInterval = 24 * time.Hour
+	// mediaAccessFlushInterval is how often recorded media accesses are written to the database
+	mediaAccessFlushInterval = time.Minute
+	// mediaTieringBatch caps how many originals one tiering run archives
+	mediaTieringBatch = 500
+
+	// mediaScanTimeout bounds one malware scan of an upload
+	mediaScanTimeout = 2 * time.Minute
+	// mediaQuarantineDir holds infected uploads under the upload path, out of
+	// reach of media URLs
+	mediaQuarantineDir = ".quarantine"
+
+	// similarImageDistance is the most bits two perceptual hashes may differ
+	// by for the images to be suggested as similar
+	similarImageDistance = 6
+	// duplicateScanLimit caps how many of a user's images are compared when
+	// looking for duplicates
+	duplicateScanLimit = 2000
+)
+
+// mediaImageVariants are the sizes produced for image uploads. The first
+// three are listed as thumbnails, large as a variant.
+var mediaImageVariants = []utils.ThumbnailSize{
+	{Width: 150, Height: 150, Name: "thumbnail"},
+	{Width: 300, Height: 300, Name: "small"},
+	{Width: 600, Height: 600, Name: "medium"},
+	{Width: 1600, Height: 1600, Name: mediaVariantLarge},
+}
+
+// mediaVariantLarge is the variant that replaces a discarded original
+const mediaVariantLarge = "large"
+
+// mediaDownscaleQuality is the JPEG quality of downscaled originals, kept
+// high since every variant is encoded again from them
+const mediaDownscaleQuality = 90
+
+// MediaOutputPolicy controls the formats and quality of image variants
+type MediaOutputPolicy struct {
+	Encoders      []ImageEncoder // JPEG fallback first, then the modern formats
+	Quality       map[string]int // Encoding quality per variant
+	KeepOriginal  bool           // When false, JPEG originals are replaced by the large variant
+	EncodeTimeout time.Duration
+
+	// Originals with a longer edge than MaxResolution are downscaled before
+	// the variants are made from them (0 disables). KeepFullResolution
+	// leaves the uploaded file as it is and only downscales the variant source.
+	MaxResolution      int
+	KeepFullResolution bool
+}
+
+// MediaTieringPolicy controls when media originals move between storage tiers
+type MediaTieringPolicy struct {
+	ColdAfter          time.Duration // Originals unread this long move to the cold tier
+	HotCategories      []string      // Categories that always stay hot
+	RestoreRequests    int           // Requests in one day that bring a cold original back
+	HotCostPerGBMonth  float64
+	ColdCostPerGBMonth float64
+}
+
 type MediaService struct {
-	collection     *mongo.Collection
-	userCollection *mongo.Collection
-	db             *mongo.Database
-	uploadPath     string
-	baseURL        string
-	maxFileSize    int64
-	allowedTypes   map[string][]string
+	collection        *mongo.Collection
+	userCollection    *mongo.Collection
+	db                *mongo.Database
+	uploadPath        string
+	baseURL           string
+	maxFileSize       int64
+	allowedTypes      map[string][]string
+	requireAltText    bool // Image uploads must carry alt text
+	stopOrphanCleanup context.CancelFunc
+
+	// Uploads are scanned for malware before processing completes
+	scanner             ScanService
+	notificationService *NotificationService
+
+	// Storage tiering; tiers is nil when no cold tier is configured
+	tiers       *storage.TieredStorage
+	tiering     MediaTieringPolicy
+	accessMu    sync.Mutex
+	accesses    map[primitive.ObjectID]int // Requests per original since the last flush
+	stopTiering context.CancelFunc
+
+	// Image variant formats and quality
+	output MediaOutputPolicy
 }
 
 type UploadResult struct {
-	Media    *models.Media `json:"media"`
-	URL      string        `json:"url"`
-	Filename string        `json:"filename"`
+	Media        *models.Media `json:"media"`
+	URL          string        `json:"url"`
+	Filename     string        `json:"filename"`
+	Deduplicated bool          `json:"deduplicated,omitempty"` // The file matched one the user already had and is shared with it
 }
 
-func NewMediaService(uploadPath, baseURL string) *MediaService {
+func NewMediaService(uploadPath, baseURL string, requireImageAltText bool, tiers *storage.TieredStorage, tiering MediaTieringPolicy, output MediaOutputPolicy, scanner ScanService, notificationService *NotificationService) *MediaService {
+	if scanner == nil {
+		scanner = NoopScanService{}
+	}
+	if len(output.Encoders) == 0 {
+		output.Encoders = []ImageEncoder{JPEGEncoder{}}
+	}
+
 	return &MediaService{
 		collection:     config.DB.Collection("media"),
 		userCollection: config.DB.Collection("users"),
@@ -46,12 +148,19 @@ func NewMediaService(uploadPath, baseURL string) *MediaService {
 		uploadPath:     uploadPath,
 		baseURL:        baseURL,
 		maxFileSize:    50 * 1024 * 1024, // 50MB default
+		requireAltText: requireImageAltText,
 		allowedTypes: map[string][]string{
 			"image":    {"jpg", "jpeg", "png", "gif", "webp", "bmp"},
 			"video":    {"mp4", "mov", "avi", "mkv", "webm"},
 			"audio":    {"mp3", "wav", "ogg", "aac", "flac"},
 			"document": {"pdf", "doc", "docx", "txt", "rtf"},
 		},
+		tiers:               tiers,
+		tiering:             tiering,
+		output:              output,
+		accesses:            make(map[primitive.ObjectID]int),
+		scanner:             scanner,
+		notificationService: notificationService,
 	}
 }
 
@@ -61,6 +170,10 @@ func (ms *MediaService) UploadMedia(userID primitive.ObjectID, file multipart.Fi
 	if err := ms.validateFile(header, req.Type); err != nil {
 		return nil, err
 	}
+	req.AltText = strings.TrimSpace(req.AltText)
+	if err := ms.validateAltText(req.Type, req.AltText); err != nil {
+		return nil, err
+	}
 
 	// Generate unique filename
 	ext := strings.ToLower(filepath.Ext(header.Filename))
@@ -82,33 +195,51 @@ func (ms *MediaService) UploadMedia(userID primitive.ObjectID, file multipart.Fi
 	}
 	defer destFile.Close()
 
-	size, err := io.Copy(destFile, file)
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(destFile, hasher), file)
 	if err != nil {
 		return nil, fmt.Errorf("failed to save file: %v", err)
 	}
 
+	// Convert related ID if provided
+	var relatedID *primitive.ObjectID
+	if req.RelatedID != "" {
+		if rID, err := primitive.ObjectIDFromHex(req.RelatedID); err == nil {
+			relatedID = &rID
+		}
+	}
+
+	// An image byte-identical to one the user already has shares its file
+	contentHash := ""
+	if req.Type == "image" {
+		contentHash = hex.EncodeToString(hasher.Sum(nil))
+		if existing := ms.findExactDuplicate(userID, contentHash); existing != nil {
+			destFile.Close()
+			os.Remove(filePath)
+			return ms.createDuplicateMedia(userID, existing, header.Filename, relatedID, req)
+		}
+	}
+
 	// Get file info
 	mimeType := utils.GetMimeType(ext)
 	width, height := 0, 0
 	duration := 0
 
-	// Extract metadata for images and videos
+	// Extract metadata for images and videos. Oversized images report the
+	// size they are stored at once processing downscales them.
+	originalWidth, originalHeight := 0, 0
 	if req.Type == "image" {
 		width, height = ms.getImageDimensions(filePath)
+		if w, h, ok := ms.downscaledSize(ext, width, height); ok {
+			originalWidth, originalHeight = width, height
+			width, height = w, h
+		}
 	} else if req.Type == "video" {
 		width, height, duration = ms.getVideoMetadata(filePath)
 	} else if req.Type == "audio" {
 		duration = ms.getAudioDuration(filePath)
 	}
 
-	// Convert related ID if provided
-	var relatedID *primitive.ObjectID
-	if req.RelatedID != "" {
-		if rID, err := primitive.ObjectIDFromHex(req.RelatedID); err == nil {
-			relatedID = &rID
-		}
-	}
-
 	// Create media record
 	media := &models.Media{
 		OriginalName:    header.Filename,
@@ -122,6 +253,8 @@ func (ms *MediaService) UploadMedia(userID primitive.ObjectID, file multipart.Fi
 		UploadedBy:      userID,
 		Width:           width,
 		Height:          height,
+		OriginalWidth:   originalWidth,
+		OriginalHeight:  originalHeight,
 		Duration:        duration,
 		URL:             fmt.Sprintf("%s/media/%s/%s/%s", ms.baseURL, req.Type, dateFolder, filename),
 		IsPublic:        req.IsPublic,
@@ -132,6 +265,7 @@ func (ms *MediaService) UploadMedia(userID primitive.ObjectID, file multipart.Fi
 		ExpiresAt:       req.ExpiresAt,
 		StorageProvider: "local",
 		StorageKey:      fmt.Sprintf("%s/%s/%s", req.Type, dateFolder, filename),
+		ContentHash:     contentHash,
 	}
 
 	media.BeforeCreate()
@@ -149,10 +283,7 @@ func (ms *MediaService) UploadMedia(userID primitive.ObjectID, file multipart.Fi
 
 	media.ID = result.InsertedID.(primitive.ObjectID)
 
-	// Generate thumbnails for images and videos
-	go ms.generateThumbnails(media)
-
-	// Process media asynchronously (resize, optimize, etc.)
+	// Scan and process media asynchronously (thumbnails, resize, optimize, etc.)
 	go ms.processMedia(media)
 
 	return &UploadResult{
@@ -162,21 +293,224 @@ func (ms *MediaService) UploadMedia(userID primitive.ObjectID, file multipart.Fi
 	}, nil
 }
 
+// findExactDuplicate finds a processed image of the user's with the same
+// content hash whose file can be shared
+func (ms *MediaService) findExactDuplicate(userID primitive.ObjectID, contentHash string) *models.Media {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var media models.Media
+	err := ms.collection.FindOne(ctx, repository.NotDeleted(bson.M{
+		"uploaded_by":       userID,
+		"content_hash":      contentHash,
+		"processing_status": "completed",
+		"is_expired":        bson.M{"$ne": true},
+	})).Decode(&media)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.Printf("Failed to look up duplicate media for user %s: %v", userID.Hex(), err)
+		}
+		return nil
+	}
+	return &media
+}
+
+// createDuplicateMedia records an upload that shares an existing media's
+// file and variants. The upload's own details (alt text, visibility, what it
+// is attached to) are kept; the file was already scanned and processed.
+func (ms *MediaService) createDuplicateMedia(userID primitive.ObjectID, existing *models.Media, originalName string, relatedID *primitive.ObjectID, req models.CreateMediaRequest) (*UploadResult, error) {
+	media := &models.Media{
+		OriginalName:      originalName,
+		FileName:          existing.FileName,
+		FilePath:          existing.FilePath,
+		FileSize:          existing.FileSize,
+		MimeType:          existing.MimeType,
+		FileExtension:     existing.FileExtension,
+		Type:              existing.Type,
+		Category:          req.Category,
+		UploadedBy:        userID,
+		Width:             existing.Width,
+		Height:            existing.Height,
+		OriginalWidth:     existing.OriginalWidth,
+		OriginalHeight:    existing.OriginalHeight,
+		URL:               existing.URL,
+		IsPublic:          req.IsPublic,
+		AltText:           req.AltText,
+		Description:       req.Description,
+		RelatedTo:         req.RelatedTo,
+		RelatedID:         relatedID,
+		ExpiresAt:         req.ExpiresAt,
+		StorageProvider:   existing.StorageProvider,
+		StorageKey:        existing.StorageKey,
+		Thumbnails:        existing.Thumbnails,
+		Variants:          existing.Variants,
+		OriginalDiscarded: existing.OriginalDiscarded,
+		ContentHash:       existing.ContentHash,
+		PerceptualHash:    existing.PerceptualHash,
+		DuplicateOf:       &existing.ID,
+	}
+
+	media.BeforeCreate()
+	media.IsProcessed = true
+	media.ProcessingStatus = "completed"
+	media.ProcessedAt = &media.CreatedAt
+	media.StorageTier = existing.EffectiveStorageTier()
+	media.IsModerationRequired = existing.IsModerationRequired
+	media.ModerationStatus = existing.ModerationStatus
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := ms.collection.InsertOne(ctx, media)
+	if err != nil {
+		return nil, err
+	}
+	media.ID = result.InsertedID.(primitive.ObjectID)
+
+	return &UploadResult{
+		Media:        media,
+		URL:          media.URL,
+		Filename:     media.FileName,
+		Deduplicated: true,
+	}, nil
+}
+
+// FindDuplicateMedia groups a user's images that are byte-identical copies
+// and, separately, images that look alike by perceptual hash. Only the most
+// recent duplicateScanLimit images are compared.
+func (ms *MediaService) FindDuplicateMedia(userID primitive.ObjectID) ([]models.MediaDuplicateGroup, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(duplicateScanLimit)
+
+	cursor, err := ms.collection.Find(ctx, repository.NotDeleted(bson.M{
+		"uploaded_by": userID,
+		"type":        "image",
+		"is_expired":  bson.M{"$ne": true},
+		"$or": []bson.M{
+			{"content_hash": bson.M{"$exists": true}},
+			{"perceptual_hash": bson.M{"$exists": true}},
+		},
+	}), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var images []models.Media
+	if err := cursor.All(ctx, &images); err != nil {
+		return nil, err
+	}
+
+	groups := []models.MediaDuplicateGroup{}
+
+	// Exact copies share a content hash
+	byContent := make(map[string][]int)
+	var contentOrder []string
+	for i, media := range images {
+		if media.ContentHash == "" {
+			continue
+		}
+		if _, seen := byContent[media.ContentHash]; !seen {
+			contentOrder = append(contentOrder, media.ContentHash)
+		}
+		byContent[media.ContentHash] = append(byContent[media.ContentHash], i)
+	}
+	for _, hash := range contentOrder {
+		if members := byContent[hash]; len(members) > 1 {
+			groups = append(groups, duplicateGroup(models.MediaMatchExact, images, members))
+		}
+	}
+
+	// Similar images are linked by perceptual hashes a few bits apart; a
+	// group needs at least two different files
+	hashes := make([]uint64, len(images))
+	hashed := make([]bool, len(images))
+	for i, media := range images {
+		if hash, err := strconv.ParseUint(media.PerceptualHash, 16, 64); err == nil {
+			hashes[i], hashed[i] = hash, true
+		}
+	}
+
+	parent := make([]int, len(images))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+
+	for i := range images {
+		if !hashed[i] {
+			continue
+		}
+		for j := i + 1; j < len(images); j++ {
+			if !hashed[j] || (images[i].ContentHash != "" && images[i].ContentHash == images[j].ContentHash) {
+				continue
+			}
+			if utils.HammingDistance(hashes[i], hashes[j]) <= similarImageDistance {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	similar := make(map[int][]int)
+	var similarOrder []int
+	for i := range images {
+		if !hashed[i] {
+			continue
+		}
+		root := find(i)
+		if _, seen := similar[root]; !seen {
+			similarOrder = append(similarOrder, root)
+		}
+		similar[root] = append(similar[root], i)
+	}
+	for _, root := range similarOrder {
+		if members := similar[root]; len(members) > 1 {
+			groups = append(groups, duplicateGroup(models.MediaMatchSimilar, images, members))
+		}
+	}
+
+	return groups, nil
+}
+
+func duplicateGroup(match string, images []models.Media, members []int) models.MediaDuplicateGroup {
+	group := models.MediaDuplicateGroup{
+		Match: match,
+		Media: make([]models.MediaResponse, len(members)),
+	}
+	for i, index := range members {
+		group.Media[i] = images[index].ToMediaResponse()
+	}
+	return group
+}
+
 // GetMediaByID retrieves media by ID
 func (ms *MediaService) GetMediaByID(mediaID primitive.ObjectID, currentUserID *primitive.ObjectID) (*models.Media, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	var media models.Media
-	err := ms.collection.FindOne(ctx, bson.M{
-		"_id":        mediaID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&media)
+	err := ms.collection.FindOne(ctx, repository.ByID(mediaID)).Decode(&media)
 
 	if err != nil {
 		return nil, err
 	}
 
+	// Rejected media, such as quarantined malware, is never served
+	if media.ModerationStatus == "rejected" {
+		return nil, errors.New("media not found")
+	}
+
 	// Check access permissions
 	if !ms.canAccessMedia(&media, currentUserID) {
 		return nil, errors.New("access denied")
@@ -195,10 +529,9 @@ func (ms *MediaService) GetUserMedia(userID primitive.ObjectID, currentUserID *p
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	filter := bson.M{
+	filter := repository.NotDeleted(bson.M{
 		"uploaded_by": userID,
-		"deleted_at":  bson.M{"$exists": false},
-	}
+	})
 
 	if mediaType != "" {
 		filter["type"] = mediaType
@@ -228,6 +561,45 @@ func (ms *MediaService) GetUserMedia(userID primitive.ObjectID, currentUserID *p
 	return media, nil
 }
 
+// GetMediaMissingAltText retrieves a user's images that have no alt text, oldest
+// first, along with how many there are in total
+func (ms *MediaService) GetMediaMissingAltText(userID primitive.ObjectID, limit, skip int) ([]models.Media, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := repository.NotDeleted(bson.M{
+		"uploaded_by": userID,
+		"type":        "image",
+		"$or": []bson.M{
+			{"alt_text": bson.M{"$exists": false}},
+			{"alt_text": ""},
+		},
+	})
+
+	total, err := ms.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSkip(int64(skip)).
+		SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := ms.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var media []models.Media
+	if err := cursor.All(ctx, &media); err != nil {
+		return nil, 0, err
+	}
+
+	return media, total, nil
+}
+
 // UpdateMedia updates media information
 func (ms *MediaService) UpdateMedia(mediaID, userID primitive.ObjectID, req models.UpdateMediaRequest) (*models.Media, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -235,11 +607,10 @@ func (ms *MediaService) UpdateMedia(mediaID, userID primitive.ObjectID, req mode
 
 	// Check if media exists and user owns it
 	var media models.Media
-	err := ms.collection.FindOne(ctx, bson.M{
+	err := ms.collection.FindOne(ctx, repository.NotDeleted(bson.M{
 		"_id":         mediaID,
 		"uploaded_by": userID,
-		"deleted_at":  bson.M{"$exists": false},
-	}).Decode(&media)
+	})).Decode(&media)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -251,8 +622,13 @@ func (ms *MediaService) UpdateMedia(mediaID, userID primitive.ObjectID, req mode
 	// Build update document
 	update := bson.M{"$set": bson.M{"updated_at": time.Now()}}
 
+	var altText string
 	if req.AltText != nil {
-		update["$set"].(bson.M)["alt_text"] = *req.AltText
+		altText = strings.TrimSpace(*req.AltText)
+		if err := ms.validateAltText(media.Type, altText); err != nil {
+			return nil, err
+		}
+		update["$set"].(bson.M)["alt_text"] = altText
 	}
 	if req.Description != nil {
 		update["$set"].(bson.M)["description"] = *req.Description
@@ -272,6 +648,13 @@ func (ms *MediaService) UpdateMedia(mediaID, userID primitive.ObjectID, req mode
 		return nil, err
 	}
 
+	// Keep the alt text shown on the user's posts in step with the media record
+	if req.AltText != nil {
+		if err := ms.syncPostAltText(ctx, userID, media.URL, altText); err != nil {
+			log.Printf("Failed to sync alt text to posts for media %s: %v", mediaID.Hex(), err)
+		}
+	}
+
 	return ms.GetMediaByID(mediaID, &userID)
 }
 
@@ -282,11 +665,10 @@ func (ms *MediaService) DeleteMedia(mediaID, userID primitive.ObjectID) error {
 
 	// Check if media exists and user owns it
 	var media models.Media
-	err := ms.collection.FindOne(ctx, bson.M{
+	err := ms.collection.FindOne(ctx, repository.NotDeleted(bson.M{
 		"_id":         mediaID,
 		"uploaded_by": userID,
-		"deleted_at":  bson.M{"$exists": false},
-	}).Decode(&media)
+	})).Decode(&media)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -310,7 +692,7 @@ func (ms *MediaService) DeleteMedia(mediaID, userID primitive.ObjectID) error {
 	}
 
 	// Schedule physical file deletion
-	go ms.scheduleFileDeletion(media.FilePath, 24*time.Hour)
+	go ms.scheduleFileDeletion(media, 24*time.Hour)
 
 	return nil
 }
@@ -329,7 +711,7 @@ func (ms *MediaService) SearchMedia(query string, mediaType string, currentUserI
 					{"alt_text": bson.M{"$regex": query, "$options": "i"}},
 				},
 			},
-			{"deleted_at": bson.M{"$exists": false}},
+			repository.NotDeleted(),
 			{"is_public": true}, // Only search public media
 		},
 	}
@@ -362,9 +744,7 @@ func (ms *MediaService) GetMediaStats(userID *primitive.ObjectID) (map[string]in
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	matchStage := bson.M{
-		"deleted_at": bson.M{"$exists": false},
-	}
+	matchStage := repository.NotDeleted()
 
 	if userID != nil {
 		matchStage["uploaded_by"] = *userID
@@ -382,6 +762,7 @@ func (ms *MediaService) GetMediaStats(userID *primitive.ObjectID) (map[string]in
 				"avg_size":        bson.M{"$avg": "$file_size"},
 				"total_views":     bson.M{"$sum": "$view_count"},
 				"total_downloads": bson.M{"$sum": "$download_count"},
+				"storage_saved":   bson.M{"$sum": "$storage_saved_bytes"},
 			},
 		},
 	}
@@ -399,6 +780,7 @@ func (ms *MediaService) GetMediaStats(userID *primitive.ObjectID) (map[string]in
 		AvgSize        float64 `bson:"avg_size"`
 		TotalViews     int64   `bson:"total_views"`
 		TotalDownloads int64   `bson:"total_downloads"`
+		StorageSaved   int64   `bson:"storage_saved"`
 	}
 
 	if err := cursor.All(ctx, &results); err != nil {
@@ -410,6 +792,7 @@ func (ms *MediaService) GetMediaStats(userID *primitive.ObjectID) (map[string]in
 	totalSize := int64(0)
 	totalViews := int64(0)
 	totalDownloads := int64(0)
+	totalSaved := int64(0)
 
 	for _, result := range results {
 		stats[result.ID] = map[string]interface{}{
@@ -418,11 +801,13 @@ func (ms *MediaService) GetMediaStats(userID *primitive.ObjectID) (map[string]in
 			"avg_size":        result.AvgSize,
 			"total_views":     result.TotalViews,
 			"total_downloads": result.TotalDownloads,
+			"storage_saved":   result.StorageSaved,
 		}
 		totalCount += result.Count
 		totalSize += result.TotalSize
 		totalViews += result.TotalViews
 		totalDownloads += result.TotalDownloads
+		totalSaved += result.StorageSaved
 	}
 
 	stats["total"] = map[string]interface{}{
@@ -430,6 +815,7 @@ func (ms *MediaService) GetMediaStats(userID *primitive.ObjectID) (map[string]in
 		"total_size":      totalSize,
 		"total_views":     totalViews,
 		"total_downloads": totalDownloads,
+		"storage_saved":   totalSaved,
 	}
 
 	return stats, nil
@@ -486,13 +872,120 @@ func (ms *MediaService) CleanupExpiredMedia() error {
 
 	// Schedule file deletion
 	for _, media := range expiredMedia {
-		go ms.scheduleFileDeletion(media.FilePath, 0)
+		go ms.scheduleFileDeletion(media, 0)
 	}
 
 	fmt.Printf("Marked %d media items as expired\n", result.ModifiedCount)
 	return nil
 }
 
+// CleanupOrphanedFiles removes the files behind media deleted more than
+// orphanFileGrace ago and records that they are gone
+func (ms *MediaService) CleanupOrphanedFiles(ctx context.Context) (int, error) {
+	opts := options.Find().
+		SetProjection(bson.M{"file_path": 1, "storage_key": 1, "storage_tier": 1}).
+		SetLimit(orphanCleanupBatch)
+
+	cursor, err := ms.collection.Find(ctx, bson.M{
+		"deleted_at":      bson.M{"$lt": time.Now().Add(-orphanFileGrace)},
+		"file_removed_at": bson.M{"$exists": false},
+	}, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var orphans []models.Media
+	if err := cursor.All(ctx, &orphans); err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, media := range orphans {
+		// A file still used by a duplicate upload is left to the last media
+		// using it; this media is done with it
+		shared, err := ms.fileShared(ctx, &media)
+		if err != nil {
+			log.Printf("Failed to check if file %s is shared: %v", media.StorageKey, err)
+			continue
+		}
+
+		if !shared {
+			if media.EffectiveStorageTier() == models.MediaTierCold && ms.tiers != nil {
+				if err := ms.tiers.Delete(storage.TierCold, media.StorageKey); err != nil && !storage.IsNotFoundError(err) {
+					log.Printf("Failed to remove orphaned cold file %s: %v", media.StorageKey, err)
+					continue
+				}
+			} else if err := os.Remove(media.FilePath); err != nil && !os.IsNotExist(err) {
+				log.Printf("Failed to remove orphaned file %s: %v", media.FilePath, err)
+				continue
+			}
+		}
+
+		if _, err := ms.collection.UpdateOne(ctx, bson.M{"_id": media.ID}, bson.M{
+			"$set": bson.M{"file_removed_at": time.Now()},
+		}); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// StartOrphanCleanup runs CleanupOrphanedFiles every interval until StopOrphanCleanup is called
+func (ms *MediaService) StartOrphanCleanup(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ms.stopOrphanCleanup = cancel
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				removed, err := ms.CleanupOrphanedFiles(ctx)
+				if ctx.Err() == nil {
+					recordJobHeartbeat(ms.db, "orphan_cleanup", interval, err)
+				}
+				if err != nil && ctx.Err() == nil {
+					log.Printf("Orphan cleanup failed after %d files: %v", removed, err)
+					continue
+				}
+				if removed > 0 {
+					log.Printf("Removed %d orphaned media files", removed)
+				}
+			}
+		}
+	}()
+}
+
+// StopOrphanCleanup stops the periodic orphan cleanup
+func (ms *MediaService) StopOrphanCleanup() {
+	if ms.stopOrphanCleanup != nil {
+		ms.stopOrphanCleanup()
+	}
+}
+
+// releaseMedia soft deletes media records whatever their owner, leaving their
+// files to the orphan cleanup job
+func releaseMedia(ctx context.Context, db *mongo.Database, mediaIDs []primitive.ObjectID) error {
+	if len(mediaIDs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	_, err := db.Collection("media").UpdateMany(ctx, repository.NotDeleted(bson.M{
+		"_id": bson.M{"$in": mediaIDs},
+	}), bson.M{
+		"$set": bson.M{"deleted_at": now, "updated_at": now},
+	})
+	return err
+}
+
 // Private helper methods
 
 func (ms *MediaService) validateFile(header *multipart.FileHeader, mediaType string) error {
@@ -517,6 +1010,31 @@ func (ms *MediaService) validateFile(header *multipart.FileHeader, mediaType str
 	return fmt.Errorf("unsupported file extension: %s", ext)
 }
 
+// validateAltText checks alt text length and, when the service requires it,
+// that images carry a description
+func (ms *MediaService) validateAltText(mediaType, altText string) error {
+	if utf8.RuneCountInString(altText) > utils.MaxAltTextLength {
+		return fmt.Errorf("alt text exceeds maximum length of %d characters", utils.MaxAltTextLength)
+	}
+	if ms.requireAltText && mediaType == "image" && altText == "" {
+		return errors.New("alt text is required for images")
+	}
+	return nil
+}
+
+// syncPostAltText copies a media record's alt text onto the matching media
+// entries of the owner's posts
+func (ms *MediaService) syncPostAltText(ctx context.Context, userID primitive.ObjectID, url, altText string) error {
+	_, err := ms.db.Collection("posts").UpdateMany(ctx,
+		bson.M{"user_id": userID, "media.url": url},
+		bson.M{"$set": bson.M{"media.$[item].alt_text": altText}},
+		options.Update().SetArrayFilters(options.ArrayFilters{
+			Filters: []interface{}{bson.M{"item.url": url}},
+		}),
+	)
+	return err
+}
+
 func (ms *MediaService) canAccessMedia(media *models.Media, userID *primitive.ObjectID) bool {
 	// Owner can always access
 	if userID != nil && media.UploadedBy == *userID {
@@ -552,35 +1070,250 @@ func (ms *MediaService) incrementViewCount(mediaID primitive.ObjectID) {
 	ms.collection.UpdateOne(ctx, bson.M{"_id": mediaID}, update)
 }
 
+// generateThumbnails hashes an uploaded image for similar image suggestions
+// and writes its variants
 func (ms *MediaService) generateThumbnails(media *models.Media) {
-	// Generate thumbnails for images and videos
-	// This would integrate with image processing libraries like imaging or ffmpeg for videos
-
-	if media.Type == "image" {
-		// Generate different sized thumbnails
-		thumbnails := []models.MediaVariant{
-			{Name: "thumbnail", Width: 150, Height: 150},
-			{Name: "small", Width: 300, Height: 300},
-			{Name: "medium", Width: 600, Height: 600},
-		}
-
-		for _, thumb := range thumbnails {
-			// Generate thumbnail and save
-			thumbPath := ms.generateImageThumbnail(media.FilePath, thumb.Width, thumb.Height)
-			if thumbPath != "" {
-				thumb.URL = fmt.Sprintf("%s/media/thumbnails/%s", ms.baseURL, filepath.Base(thumbPath))
-				thumb.FileSize = ms.getFileSize(thumbPath)
-				thumb.Format = media.FileExtension
-				media.Thumbnails = append(media.Thumbnails, thumb)
+	// Video thumbnails would need ffmpeg; only images are processed
+	if media.Type != "image" {
+		return
+	}
+
+	img, err := decodeImageFile(media.FilePath)
+	if err != nil {
+		log.Printf("Skipping variants for media %s: %v", media.ID.Hex(), err)
+		return
+	}
+
+	// GIFs are hashed by their first frame
+	media.PerceptualHash = fmt.Sprintf("%016x", utils.DifferenceHash(img))
+	update := bson.M{
+		"perceptual_hash": media.PerceptualHash,
+		"updated_at":      time.Now(),
+	}
+
+	// Animated GIFs get no variants, since they would be stills
+	if media.FileExtension != "gif" {
+		img = ms.downscaleOriginal(media, img, update)
+		ms.writeImageVariants(media, img, update)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ms.collection.UpdateOne(ctx, bson.M{"_id": media.ID}, bson.M{"$set": update})
+}
+
+// writeImageVariants writes the image variants in JPEG and each configured
+// modern format, records what the modern formats save over JPEG, and
+// replaces a JPEG original with the large variant when originals are not
+// kept. The changes to the media are added to update.
+func (ms *MediaService) writeImageVariants(media *models.Media, img image.Image, update bson.M) {
+	bounds := img.Bounds()
+	dir := filepath.Dir(media.FilePath)
+	baseName := strings.TrimSuffix(media.FileName, filepath.Ext(media.FileName))
+	baseURL := strings.TrimSuffix(media.URL, media.FileName)
+	discard := !ms.output.KeepOriginal && media.MimeType == "image/jpeg"
+
+	var thumbnails, variants []models.MediaVariant
+	saved := media.StorageSavedBytes // What downscaling the original saved
+	var largeJPEG string
+
+	for _, size := range mediaImageVariants {
+		width, height := utils.CalculateOptimalSize(bounds.Dx(), bounds.Dy(), size.Width, size.Height)
+		resized := utils.ResizeToFit(img, width, height)
+		quality := ms.output.Quality[size.Name]
+		if quality == 0 {
+			quality = 80
+		}
+
+		var jpegSize, smallest int64
+		for _, encoder := range ms.output.Encoders {
+			fileName := fmt.Sprintf("%s_%s.%s", baseName, size.Name, encoder.Format())
+			path := filepath.Join(dir, fileName)
+			if err := ms.encodeVariant(encoder, resized, quality, path); err != nil {
+				log.Printf("Failed to write %s %s variant of media %s: %v", encoder.Format(), size.Name, media.ID.Hex(), err)
+				continue
+			}
+
+			variant := models.MediaVariant{
+				Name:      size.Name,
+				URL:       baseURL + fileName,
+				Width:     resized.Bounds().Dx(),
+				Height:    resized.Bounds().Dy(),
+				FileSize:  ms.getFileSize(path),
+				Format:    encoder.Format(),
+				Quality:   quality,
+				CreatedAt: time.Now(),
+			}
+
+			if encoder.Format() == ImageFormatJPEG {
+				jpegSize = variant.FileSize
+				// The large JPEG becomes the original instead of being listed
+				if discard && size.Name == mediaVariantLarge {
+					largeJPEG = path
+					continue
+				}
+			} else if smallest == 0 || variant.FileSize < smallest {
+				smallest = variant.FileSize
 			}
+
+			if size.Name == mediaVariantLarge {
+				variants = append(variants, variant)
+			} else {
+				thumbnails = append(thumbnails, variant)
+			}
+		}
+
+		if jpegSize > 0 && smallest > 0 && smallest < jpegSize {
+			saved += jpegSize - smallest
 		}
+	}
+
+	update["thumbnails"] = thumbnails
+	update["variants"] = variants
+	update["storage_saved_bytes"] = saved
+
+	// The original is overwritten in place so its URL keeps working
+	if largeJPEG != "" {
+		if err := os.Rename(largeJPEG, media.FilePath); err != nil {
+			log.Printf("Failed to replace original of media %s: %v", media.ID.Hex(), err)
+			os.Remove(largeJPEG)
+		} else {
+			size := ms.getFileSize(media.FilePath)
+			if size < media.FileSize {
+				saved += media.FileSize - size
+			}
+			update["file_size"] = size
+			update["storage_saved_bytes"] = saved
+			update["original_discarded"] = true
+			media.FileSize = size
+			media.OriginalDiscarded = true
+		}
+	}
+
+	media.Thumbnails = thumbnails
+	media.Variants = variants
+	media.StorageSavedBytes = saved
+}
+
+// downscaleOriginal shrinks an image whose longest edge is over the maximum
+// resolution and returns it as the source for the variants. Unless full
+// resolution originals are kept, the JPEG or PNG original is overwritten in
+// place with the smaller image, so its URL keeps working. The changes to the
+// media are added to update.
+func (ms *MediaService) downscaleOriginal(media *models.Media, img image.Image, update bson.M) image.Image {
+	bounds := img.Bounds()
+	limit := ms.output.MaxResolution
+	if limit <= 0 || (bounds.Dx() <= limit && bounds.Dy() <= limit) {
+		return img
+	}
+
+	width, height := utils.CalculateOptimalSize(bounds.Dx(), bounds.Dy(), limit, limit)
+	resized := utils.ResizeToFit(img, width, height)
+	if _, _, ok := ms.downscaledSize("."+media.FileExtension, bounds.Dx(), bounds.Dy()); !ok {
+		return resized
+	}
+
+	tmpPath := media.FilePath + ".downscaled"
+	var err error
+	if media.FileExtension == "png" {
+		err = writePNG(resized, tmpPath)
+	} else {
+		err = ms.encodeVariant(JPEGEncoder{}, resized, mediaDownscaleQuality, tmpPath)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, media.FilePath)
+	}
+	if err != nil {
+		log.Printf("Failed to downscale original of media %s: %v", media.ID.Hex(), err)
+		os.Remove(tmpPath)
+		// The upload reported the downscaled size; the file keeps its own
+		update["width"], update["height"] = bounds.Dx(), bounds.Dy()
+		update["original_width"], update["original_height"] = 0, 0
+		media.Width, media.Height = bounds.Dx(), bounds.Dy()
+		media.OriginalWidth, media.OriginalHeight = 0, 0
+		return resized
+	}
+
+	size := ms.getFileSize(media.FilePath)
+	if size < media.FileSize {
+		media.StorageSavedBytes += media.FileSize - size
+	}
+	update["file_size"] = size
+	update["width"], update["height"] = resized.Bounds().Dx(), resized.Bounds().Dy()
+	update["original_width"], update["original_height"] = bounds.Dx(), bounds.Dy()
+	media.FileSize = size
+	media.Width, media.Height = resized.Bounds().Dx(), resized.Bounds().Dy()
+	media.OriginalWidth, media.OriginalHeight = bounds.Dx(), bounds.Dy()
+
+	return resized
+}
+
+// downscaledSize returns the size an image is stored at when its original is
+// downscaled on upload, and whether it is. Only JPEG and PNG originals are
+// rewritten; the formats that can't be re-encoded, animated GIFs and
+// originals kept at full resolution stay as uploaded.
+func (ms *MediaService) downscaledSize(ext string, width, height int) (int, int, bool) {
+	limit := ms.output.MaxResolution
+	if limit <= 0 || ms.output.KeepFullResolution || (width <= limit && height <= limit) {
+		return width, height, false
+	}
+	switch strings.ToLower(ext) {
+	case ".jpg", ".jpeg", ".png":
+	default:
+		return width, height, false
+	}
+
+	w, h := utils.CalculateOptimalSize(width, height, limit, limit)
+	return w, h, true
+}
+
+// writePNG writes an image as PNG, keeping its transparency
+func writePNG(img image.Image, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// encodeVariant writes one variant file, bounded by the encode timeout
+func (ms *MediaService) encodeVariant(encoder ImageEncoder, img image.Image, quality int, path string) error {
+	timeout := ms.output.EncodeTimeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return encoder.Encode(ctx, img, quality, path)
+}
 
-		// Update media record with thumbnails
-		ms.updateMediaThumbnails(media.ID, media.Thumbnails)
+// decodeImageFile decodes an image with the registered standard library decoders
+func decodeImageFile(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
 	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	return img, err
 }
 
 func (ms *MediaService) processMedia(media *models.Media) {
+	// Nothing else touches the file until it is scanned
+	if !ms.scanMedia(media) {
+		return
+	}
+
+	// Generate thumbnails for images and videos
+	ms.generateThumbnails(media)
+
 	// Process media (optimize, resize, etc.)
 	// This would integrate with image/video processing tools
 
@@ -600,10 +1333,96 @@ func (ms *MediaService) processMedia(media *models.Media) {
 	ms.collection.UpdateOne(ctx, bson.M{"_id": media.ID}, update)
 }
 
+// scanMedia scans an upload for malware, quarantining it when infected. It
+// reports whether processing should go on. Files that couldn't be scanned
+// stay available but are marked for moderation.
+func (ms *MediaService) scanMedia(media *models.Media) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), mediaScanTimeout)
+	defer cancel()
+
+	file, err := os.Open(media.FilePath)
+	if err != nil {
+		ms.markScanFailed(ctx, media, err)
+		return false
+	}
+	defer file.Close()
+
+	clean, detail, err := ms.scanner.Scan(ctx, file)
+	if err != nil {
+		ms.markScanFailed(ctx, media, err)
+		return false
+	}
+	if clean {
+		return true
+	}
+
+	file.Close()
+	ms.quarantineMedia(ctx, media, detail)
+	return false
+}
+
+func (ms *MediaService) markScanFailed(ctx context.Context, media *models.Media, err error) {
+	log.Printf("Failed to scan media %s for malware: %v", media.ID.Hex(), err)
+
+	ms.collection.UpdateOne(ctx, bson.M{"_id": media.ID}, bson.M{
+		"$set": bson.M{
+			"processing_status":      "failed",
+			"is_moderation_required": true,
+			"moderation_notes":       "Malware scan failed: " + err.Error(),
+			"updated_at":             time.Now(),
+		},
+	})
+}
+
+// quarantineMedia moves an infected file out of the served upload tree,
+// rejects the media so it is never served, and tells the uploader
+func (ms *MediaService) quarantineMedia(ctx context.Context, media *models.Media, detail string) {
+	log.Printf("Quarantining media %s uploaded by %s: %s", media.ID.Hex(), media.UploadedBy.Hex(), detail)
+
+	filePath := media.FilePath
+	quarantineDir := filepath.Join(ms.uploadPath, mediaQuarantineDir)
+	if err := os.MkdirAll(quarantineDir, 0700); err == nil {
+		quarantinePath := filepath.Join(quarantineDir, media.FileName)
+		if err := os.Rename(filePath, quarantinePath); err == nil {
+			filePath = quarantinePath
+		}
+	}
+	if filePath == media.FilePath {
+		// The file couldn't be moved aside, so it is removed rather than left in place
+		os.Remove(media.FilePath)
+	}
+
+	ms.collection.UpdateOne(ctx, bson.M{"_id": media.ID}, bson.M{
+		"$set": bson.M{
+			"file_path":              filePath,
+			"is_public":              false,
+			"processing_status":      "quarantined",
+			"is_moderation_required": true,
+			"moderation_status":      "rejected",
+			"moderation_notes":       "Malware detected: " + detail,
+			"updated_at":             time.Now(),
+		},
+	})
+
+	if ms.notificationService != nil {
+		ms.notificationService.NotifyMediaQuarantined(media.UploadedBy, media.ID, media.OriginalName)
+	}
+}
+
+// getImageDimensions reads an image's size from its header, without
+// decoding the pixels. Formats without a registered decoder report 0x0.
 func (ms *MediaService) getImageDimensions(filePath string) (int, int) {
-	// Use image processing library to get dimensions
-	// Placeholder implementation
-	return 800, 600
+	file, err := os.Open(filePath)
+	if err != nil {
+		return 0, 0
+	}
+	defer file.Close()
+
+	cfg, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return 0, 0
+	}
+	return cfg.Width, cfg.Height
 }
 
 func (ms *MediaService) getVideoMetadata(filePath string) (int, int, int) {
@@ -618,12 +1437,6 @@ func (ms *MediaService) getAudioDuration(filePath string) int {
 	return 180 // duration in seconds
 }
 
-func (ms *MediaService) generateImageThumbnail(originalPath string, width, height int) string {
-	// Generate thumbnail using image processing library
-	// Placeholder implementation
-	return ""
-}
-
 func (ms *MediaService) getFileSize(filePath string) int64 {
 	if info, err := os.Stat(filePath); err == nil {
 		return info.Size()
@@ -631,28 +1444,40 @@ func (ms *MediaService) getFileSize(filePath string) int64 {
 	return 0
 }
 
-func (ms *MediaService) updateMediaThumbnails(mediaID primitive.ObjectID, thumbnails []models.MediaVariant) {
+func (ms *MediaService) scheduleFileDeletion(media models.Media, delay time.Duration) {
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	update := bson.M{
-		"$set": bson.M{
-			"thumbnails": thumbnails,
-			"updated_at": time.Now(),
-		},
+	// Duplicate uploads may still use the file
+	if shared, err := ms.fileShared(ctx, &media); err != nil || shared {
+		return
 	}
 
-	ms.collection.UpdateOne(ctx, bson.M{"_id": mediaID}, update)
+	if err := os.Remove(media.FilePath); err != nil {
+		fmt.Printf("Failed to delete file %s: %v\n", media.FilePath, err)
+	}
 }
 
-func (ms *MediaService) scheduleFileDeletion(filePath string, delay time.Duration) {
-	if delay > 0 {
-		time.Sleep(delay)
+// fileShared checks if media other than the given one still use its stored
+// file, as duplicate uploads do
+func (ms *MediaService) fileShared(ctx context.Context, media *models.Media) (bool, error) {
+	if media.StorageKey == "" {
+		return false, nil
 	}
 
-	if err := os.Remove(filePath); err != nil {
-		fmt.Printf("Failed to delete file %s: %v\n", filePath, err)
+	count, err := ms.collection.CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"_id":         bson.M{"$ne": media.ID},
+		"storage_key": media.StorageKey,
+		"is_expired":  bson.M{"$ne": true},
+	}))
+	if err != nil {
+		return false, err
 	}
+	return count > 0, nil
 }
 
 // GetMediaURL returns the public URL for accessing media
@@ -676,3 +1501,332 @@ func (ms *MediaService) GetMediaURL(media *models.Media, variant string) string
 
 	return media.URL // Return original if variant not found
 }
+
+// mediaFormatPreference orders variant formats from most to least preferred
+var mediaFormatPreference = []string{ImageFormatAVIF, ImageFormatWebP, ImageFormatJPEG}
+
+// NegotiateVariant picks the stored copy of a variant in the best format the
+// client's Accept header lists: AVIF, then WebP, then the JPEG fallback. It
+// returns nil when the variant has no stored copies.
+func (ms *MediaService) NegotiateVariant(media *models.Media, variant, accept string) *models.MediaVariant {
+	var candidates []models.MediaVariant
+	for _, v := range append(append([]models.MediaVariant{}, media.Thumbnails...), media.Variants...) {
+		if v.Name == variant {
+			candidates = append(candidates, v)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	for _, format := range mediaFormatPreference {
+		if format != ImageFormatJPEG && !acceptsImageType(accept, "image/"+format) {
+			continue
+		}
+		for i := range candidates {
+			if candidates[i].Format == format {
+				return &candidates[i]
+			}
+		}
+	}
+
+	// Variants made before format negotiation carry the upload's format
+	return &candidates[0]
+}
+
+// acceptsImageType checks if an Accept header explicitly lists a MIME type.
+// Wildcards don't count: browsers send image/* without decoding every format.
+func acceptsImageType(accept, mimeType string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		fields := strings.Split(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(fields[0]), mimeType) {
+			continue
+		}
+		for _, param := range fields[1:] {
+			name, value, _ := strings.Cut(param, "=")
+			if strings.TrimSpace(name) != "q" {
+				continue
+			}
+			if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && q == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// RecordAccess counts a request for a media original. Accesses are kept in
+// memory and written in batches by the tiering loop, so serving a file never
+// waits on the database.
+func (ms *MediaService) RecordAccess(mediaID primitive.ObjectID) {
+	ms.accessMu.Lock()
+	ms.accesses[mediaID]++
+	ms.accessMu.Unlock()
+}
+
+// HotOriginalPath returns the local path of an original that is in the hot tier
+func (ms *MediaService) HotOriginalPath(media *models.Media) (string, bool) {
+	if media.EffectiveStorageTier() == models.MediaTierCold {
+		return "", false
+	}
+	if _, err := os.Stat(media.FilePath); err != nil {
+		return "", false
+	}
+	return media.FilePath, true
+}
+
+// OpenOriginal retrieves a media original from whichever tier holds it. The
+// other tier is tried too, since the tiering job may move the file between
+// loading the record and opening it.
+func (ms *MediaService) OpenOriginal(media *models.Media) (io.ReadCloser, error) {
+	if ms.tiers == nil {
+		return os.Open(media.FilePath)
+	}
+
+	tier, other := storage.TierHot, storage.TierCold
+	if media.EffectiveStorageTier() == models.MediaTierCold {
+		tier, other = other, tier
+	}
+
+	reader, err := ms.tiers.Open(tier, media.StorageKey)
+	if err != nil && storage.IsNotFoundError(err) {
+		return ms.tiers.Open(other, media.StorageKey)
+	}
+	return reader, err
+}
+
+// FlushAccesses writes recorded accesses to the database and restores cold
+// originals that reached the policy's daily request limit
+func (ms *MediaService) FlushAccesses(ctx context.Context) error {
+	ms.accessMu.Lock()
+	accesses := ms.accesses
+	ms.accesses = make(map[primitive.ObjectID]int)
+	ms.accessMu.Unlock()
+
+	now := time.Now()
+	today := now.Format("2006-01-02")
+	isCold := bson.M{"$eq": bson.A{"$storage_tier", models.MediaTierCold}}
+
+	for mediaID, count := range accesses {
+		// Cold originals count their requests per day; the counter restarts
+		// when the first request of a new day comes in
+		update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+			"last_accessed_at": now,
+			"cold_requests": bson.M{"$cond": bson.A{isCold,
+				bson.M{"$cond": bson.A{
+					bson.M{"$eq": bson.A{"$cold_requests_day", today}},
+					bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$cold_requests", 0}}, count}},
+					count,
+				}},
+				"$$REMOVE",
+			}},
+			"cold_requests_day": bson.M{"$cond": bson.A{isCold, today, "$$REMOVE"}},
+		}}}}
+
+		var media models.Media
+		err := ms.collection.FindOneAndUpdate(ctx, bson.M{"_id": mediaID}, update,
+			options.FindOneAndUpdate().
+				SetReturnDocument(options.After).
+				SetProjection(bson.M{"storage_key": 1, "storage_tier": 1, "cold_requests": 1}),
+		).Decode(&media)
+		if err == mongo.ErrNoDocuments {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if media.EffectiveStorageTier() == models.MediaTierCold &&
+			ms.tiering.RestoreRequests > 0 && media.ColdRequests >= ms.tiering.RestoreRequests {
+			if err := ms.restoreOriginal(ctx, &media); err != nil {
+				log.Printf("Failed to restore media %s to hot storage: %v", media.ID.Hex(), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ArchiveColdMedia moves originals nobody requested for the policy's
+// ColdAfter period to the cold tier. Thumbnails and other variants stay hot.
+func (ms *MediaService) ArchiveColdMedia(ctx context.Context) (int, error) {
+	if ms.tiers == nil {
+		return 0, errors.New("cold storage tier is not configured")
+	}
+
+	cutoff := time.Now().Add(-ms.tiering.ColdAfter)
+	filter := repository.NotDeleted(bson.M{
+		"storage_tier":     bson.M{"$ne": models.MediaTierCold},
+		"last_accessed_at": bson.M{"$lt": cutoff},
+		"storage_key":      bson.M{"$nin": bson.A{nil, ""}},
+	})
+	if len(ms.tiering.HotCategories) > 0 {
+		filter["category"] = bson.M{"$nin": ms.tiering.HotCategories}
+	}
+
+	opts := options.Find().
+		SetProjection(bson.M{"storage_key": 1}).
+		SetSort(bson.M{"last_accessed_at": 1}).
+		SetLimit(mediaTieringBatch)
+
+	cursor, err := ms.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var candidates []models.Media
+	if err := cursor.All(ctx, &candidates); err != nil {
+		return 0, err
+	}
+
+	archived := 0
+	for _, media := range candidates {
+		// A file shared by duplicate uploads stays hot while any of them is read
+		recent, err := ms.collection.CountDocuments(ctx, repository.NotDeleted(bson.M{
+			"storage_key":      media.StorageKey,
+			"last_accessed_at": bson.M{"$gte": cutoff},
+		}))
+		if err != nil {
+			return archived, err
+		}
+		if recent > 0 {
+			continue
+		}
+
+		if err := ms.tiers.Archive(media.StorageKey); err != nil {
+			log.Printf("Failed to archive media %s: %v", media.ID.Hex(), err)
+			continue
+		}
+
+		now := time.Now()
+		if _, err := ms.collection.UpdateMany(ctx, bson.M{"storage_key": media.StorageKey}, bson.M{
+			"$set":   bson.M{"storage_tier": models.MediaTierCold, "tiered_at": now},
+			"$unset": bson.M{"cold_requests": "", "cold_requests_day": ""},
+		}); err != nil {
+			return archived, err
+		}
+		archived++
+	}
+
+	return archived, nil
+}
+
+// GetStorageTierStats returns the bytes held in each tier with the estimated
+// monthly cost against keeping everything hot
+func (ms *MediaService) GetStorageTierStats() (*models.MediaTierStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"file_removed_at": bson.M{"$exists": false}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"$ifNull": bson.A{"$storage_tier", models.MediaTierHot}},
+			"files": bson.M{"$sum": 1},
+			"bytes": bson.M{"$sum": "$file_size"},
+		}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	}
+
+	cursor, err := ms.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var tiers []models.MediaTierUsage
+	if err := cursor.All(ctx, &tiers); err != nil {
+		return nil, err
+	}
+
+	const bytesPerGB = 1 << 30
+	stats := &models.MediaTierStats{Tiers: tiers}
+	for i := range stats.Tiers {
+		gb := float64(stats.Tiers[i].Bytes) / bytesPerGB
+		rate := ms.tiering.HotCostPerGBMonth
+		if stats.Tiers[i].Tier == models.MediaTierCold {
+			rate = ms.tiering.ColdCostPerGBMonth
+		}
+		stats.Tiers[i].EstimatedMonthlyCost = gb * rate
+		stats.EstimatedMonthlyCost += gb * rate
+		stats.AllHotMonthlyCost += gb * ms.tiering.HotCostPerGBMonth
+	}
+	stats.EstimatedSavings = stats.AllHotMonthlyCost - stats.EstimatedMonthlyCost
+
+	return stats, nil
+}
+
+// StartStorageTiering flushes recorded accesses every minute and, when archive
+// is set, moves unread originals to the cold tier every interval. Only one
+// instance should archive.
+func (ms *MediaService) StartStorageTiering(interval time.Duration, archive bool) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ms.stopTiering = cancel
+
+	go func() {
+		flushTicker := time.NewTicker(mediaAccessFlushInterval)
+		defer flushTicker.Stop()
+
+		var archiveTick <-chan time.Time
+		if archive && ms.tiers != nil {
+			archiveTicker := time.NewTicker(interval)
+			defer archiveTicker.Stop()
+			archiveTick = archiveTicker.C
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				// Write what was recorded before shutting down
+				flushCtx, flushCancel := context.WithTimeout(context.Background(), 10*time.Second)
+				if err := ms.FlushAccesses(flushCtx); err != nil {
+					log.Printf("Failed to flush media accesses: %v", err)
+				}
+				flushCancel()
+				return
+			case <-flushTicker.C:
+				if err := ms.FlushAccesses(ctx); err != nil && ctx.Err() == nil {
+					log.Printf("Failed to flush media accesses: %v", err)
+				}
+			case <-archiveTick:
+				archived, err := ms.ArchiveColdMedia(ctx)
+				if ctx.Err() == nil {
+					recordJobHeartbeat(ms.db, "media_tiering", interval, err)
+				}
+				if err != nil && ctx.Err() == nil {
+					log.Printf("Media tiering failed after %d files: %v", archived, err)
+					continue
+				}
+				if archived > 0 {
+					log.Printf("Moved %d media originals to cold storage", archived)
+				}
+			}
+		}
+	}()
+}
+
+// StopStorageTiering stops the tiering loop
+func (ms *MediaService) StopStorageTiering() {
+	if ms.stopTiering != nil {
+		ms.stopTiering()
+	}
+}
+
+// restoreOriginal moves a cold original back to the hot tier
+func (ms *MediaService) restoreOriginal(ctx context.Context, media *models.Media) error {
+	if ms.tiers == nil {
+		return errors.New("cold storage tier is not configured")
+	}
+	if err := ms.tiers.Restore(media.StorageKey); err != nil {
+		return err
+	}
+
+	// Duplicate uploads sharing the file move with it
+	now := time.Now()
+	_, err := ms.collection.UpdateMany(ctx, bson.M{"storage_key": media.StorageKey}, bson.M{
+		"$set":   bson.M{"storage_tier": models.MediaTierHot, "tiered_at": now, "last_accessed_at": now},
+		"$unset": bson.M{"cold_requests": "", "cold_requests_day": ""},
+	})
+	return err
+}
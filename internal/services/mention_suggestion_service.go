@@ -0,0 +1,396 @@
+// internal/services/mention_suggestion_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// MentionSuggestionLimit is how many users a suggestion lookup returns
+	MentionSuggestionLimit = 10
+
+	// The thread is the commenters among a post's newest
+	// mentionThreadSampleSize comments, kept for mentionThreadCacheTTL since
+	// everyone composing on a busy post asks for the same set
+	mentionThreadSampleSize = 300
+	mentionThreadCacheTTL   = 30 * time.Second
+
+	// Follows are drawn from the commenter's newest mentionFollowSampleSize
+	// accounts followed; users matched by username alone are capped at
+	// mentionGlobalCandidates
+	mentionFollowSampleSize = 1000
+	mentionGlobalCandidates = 30
+)
+
+// MentionSuggestionService suggests users to @-mention in a comment, ranked
+// by how they relate to the post and to the commenter
+type MentionSuggestionService struct {
+	db                *mongo.Database
+	postCollection    *mongo.Collection
+	commentCollection *mongo.Collection
+	userCollection    *mongo.Collection
+	followCollection  *mongo.Collection
+	postService       *PostService
+
+	mu      sync.Mutex
+	threads map[primitive.ObjectID]cachedThread
+}
+
+type cachedThread struct {
+	participants []primitive.ObjectID
+	loadedAt     time.Time
+}
+
+// mentionCandidate is a matching user and how they relate to the commenter
+type mentionCandidate struct {
+	user     models.User
+	author   bool
+	inThread bool
+	follows  bool // The commenter follows them
+	mutual   bool // ...and they follow the commenter back
+}
+
+// rank orders candidates: the thread first, then mutual follows, then
+// accounts the commenter follows, then everyone else
+func (mc *mentionCandidate) rank() int {
+	switch {
+	case mc.author:
+		return 0
+	case mc.inThread:
+		return 1
+	case mc.mutual:
+		return 2
+	case mc.follows:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func (mc *mentionCandidate) reason() string {
+	switch {
+	case mc.author:
+		return models.MentionReasonAuthor
+	case mc.inThread:
+		return models.MentionReasonInThread
+	case mc.follows:
+		return models.MentionReasonFollow
+	default:
+		return ""
+	}
+}
+
+func NewMentionSuggestionService(db *mongo.Database, postService *PostService) *MentionSuggestionService {
+	return &MentionSuggestionService{
+		db:                db,
+		postCollection:    db.Collection("posts"),
+		commentCollection: db.Collection("comments"),
+		userCollection:    db.Collection("users"),
+		followCollection:  db.Collection("follows"),
+		postService:       postService,
+		threads:           make(map[primitive.ObjectID]cachedThread),
+	}
+}
+
+// SuggestMentions returns up to MentionSuggestionLimit users whose username
+// or display name starts with query, for userID composing a comment on the
+// post. Users blocked in either direction are left out, as are private
+// accounts the commenter isn't an approved follower of.
+func (mss *MentionSuggestionService) SuggestMentions(postID, userID primitive.ObjectID, query string) ([]models.MentionSuggestion, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(query, "@")))
+	if query == "" {
+		return nil, errors.New("invalid query: at least 1 character is required")
+	}
+
+	var post models.Post
+	if err := mss.postCollection.FindOne(ctx, repository.ByID(postID)).Decode(&post); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("post not found")
+		}
+		return nil, err
+	}
+	if !mss.postService.CanUserViewPost(&post, userID) {
+		return nil, errors.New("post not found")
+	}
+
+	participants, err := mss.getThreadParticipants(ctx, post.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	followees, err := mss.followees(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := mss.findCandidates(ctx, query, post.UserID, participants, followees)
+	if err != nil {
+		return nil, err
+	}
+	delete(candidates, userID)
+	if len(candidates) == 0 {
+		return []models.MentionSuggestion{}, nil
+	}
+
+	if err := mss.applyRelationships(ctx, userID, candidates); err != nil {
+		return nil, err
+	}
+
+	ranked := make([]*mentionCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		// Private accounts are only suggested to their approved followers
+		if candidate.user.IsPrivate && !candidate.follows {
+			continue
+		}
+		ranked = append(ranked, candidate)
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].rank() != ranked[j].rank() {
+			return ranked[i].rank() < ranked[j].rank()
+		}
+		if ranked[i].user.FollowersCount != ranked[j].user.FollowersCount {
+			return ranked[i].user.FollowersCount > ranked[j].user.FollowersCount
+		}
+		return ranked[i].user.Username < ranked[j].user.Username
+	})
+	if len(ranked) > MentionSuggestionLimit {
+		ranked = ranked[:MentionSuggestionLimit]
+	}
+
+	suggestions := make([]models.MentionSuggestion, 0, len(ranked))
+	for _, candidate := range ranked {
+		suggestions = append(suggestions, models.MentionSuggestion{
+			UserID:      candidate.user.ID,
+			Username:    candidate.user.Username,
+			DisplayName: candidate.user.DisplayName,
+			ProfilePic:  candidate.user.ProfilePic,
+			IsVerified:  candidate.user.IsVerified,
+			Reason:      candidate.reason(),
+		})
+	}
+	return suggestions, nil
+}
+
+// getThreadParticipants returns the users among the post's newest comments,
+// served from the per-post cache when it is fresh
+func (mss *MentionSuggestionService) getThreadParticipants(ctx context.Context, postID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	mss.mu.Lock()
+	cached, ok := mss.threads[postID]
+	mss.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < mentionThreadCacheTTL {
+		return cached.participants, nil
+	}
+
+	// Served by the post_id/created_at index
+	opts := options.Find().
+		SetSort(bson.M{"created_at": -1}).
+		SetLimit(mentionThreadSampleSize).
+		SetProjection(bson.M{"user_id": 1})
+
+	cursor, err := mss.commentCollection.Find(ctx, repository.NotDeleted(bson.M{
+		"post_id":     postID,
+		"is_approved": true,
+		"is_hidden":   false,
+	}), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var comments []models.Comment
+	if err := cursor.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[primitive.ObjectID]bool)
+	participants := []primitive.ObjectID{}
+	for _, comment := range comments {
+		if !seen[comment.UserID] {
+			seen[comment.UserID] = true
+			participants = append(participants, comment.UserID)
+		}
+	}
+
+	mss.mu.Lock()
+	// Drop stale threads so posts nobody comments on anymore don't pile up
+	for id, thread := range mss.threads {
+		if time.Since(thread.loadedAt) >= mentionThreadCacheTTL {
+			delete(mss.threads, id)
+		}
+	}
+	mss.threads[postID] = cachedThread{participants: participants, loadedAt: time.Now()}
+	mss.mu.Unlock()
+
+	return participants, nil
+}
+
+// followees returns the accounts userID most recently followed
+func (mss *MentionSuggestionService) followees(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	opts := options.Find().
+		SetSort(bson.M{"created_at": -1}).
+		SetLimit(mentionFollowSampleSize).
+		SetProjection(bson.M{"followee_id": 1})
+
+	cursor, err := mss.followCollection.Find(ctx, repository.NotDeleted(bson.M{
+		"follower_id": userID,
+		"status":      models.FollowStatusAccepted,
+	}), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var follows []models.Follow
+	if err := cursor.All(ctx, &follows); err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(follows))
+	for _, follow := range follows {
+		ids = append(ids, follow.FolloweeID)
+	}
+	return ids, nil
+}
+
+// findCandidates loads the users matching query from the thread and the
+// commenter's follows, by username or display name, plus the most followed
+// users whose username matches
+func (mss *MentionSuggestionService) findCandidates(ctx context.Context, query string, authorID primitive.ObjectID, participants, followees []primitive.ObjectID) (map[primitive.ObjectID]*mentionCandidate, error) {
+	prefix := "^" + regexp.QuoteMeta(query)
+	projection := bson.M{
+		"username":        1,
+		"display_name":    1,
+		"profile_pic":     1,
+		"is_verified":     1,
+		"is_private":      1,
+		"followers_count": 1,
+	}
+
+	related := append([]primitive.ObjectID{authorID}, participants...)
+	related = append(related, followees...)
+
+	candidates := make(map[primitive.ObjectID]*mentionCandidate)
+	lookups := []struct {
+		filter bson.M
+		limit  int64
+	}{
+		{
+			filter: bson.M{
+				"_id": bson.M{"$in": related},
+				"$or": []bson.M{
+					{"username": bson.M{"$regex": prefix, "$options": "i"}},
+					{"display_name": bson.M{"$regex": prefix, "$options": "i"}},
+				},
+			},
+			limit: int64(len(related)),
+		},
+		{
+			// Anchored prefix match on username_lower can use its index
+			filter: bson.M{"username_lower": bson.M{"$regex": prefix}},
+			limit:  mentionGlobalCandidates,
+		},
+	}
+
+	for _, lookup := range lookups {
+		filter := repository.NotDeleted(lookup.filter)
+		filter["is_active"] = true
+		filter["is_suspended"] = bson.M{"$ne": true}
+
+		opts := options.Find().
+			SetSort(bson.M{"followers_count": -1}).
+			SetLimit(lookup.limit).
+			SetProjection(projection)
+
+		cursor, err := mss.userCollection.Find(ctx, filter, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		var users []models.User
+		err = cursor.All(ctx, &users)
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, user := range users {
+			if _, ok := candidates[user.ID]; !ok {
+				candidates[user.ID] = &mentionCandidate{user: user}
+			}
+		}
+	}
+
+	inThread := make(map[primitive.ObjectID]bool, len(participants))
+	for _, id := range participants {
+		inThread[id] = true
+	}
+	for id, candidate := range candidates {
+		candidate.author = id == authorID
+		candidate.inThread = inThread[id]
+	}
+	return candidates, nil
+}
+
+// applyRelationships records which candidates userID follows and which
+// follow back, and drops candidates blocked in either direction
+func (mss *MentionSuggestionService) applyRelationships(ctx context.Context, userID primitive.ObjectID, candidates map[primitive.ObjectID]*mentionCandidate) error {
+	ids := make([]primitive.ObjectID, 0, len(candidates))
+	for id := range candidates {
+		ids = append(ids, id)
+	}
+
+	blocked, err := blockedEitherWay(ctx, mss.db, userID, ids)
+	if err != nil {
+		return err
+	}
+	for id := range blocked {
+		delete(candidates, id)
+	}
+
+	following, err := mss.followCollection.Distinct(ctx, "followee_id", repository.NotDeleted(bson.M{
+		"follower_id": userID,
+		"followee_id": bson.M{"$in": ids},
+		"status":      models.FollowStatusAccepted,
+	}))
+	if err != nil {
+		return err
+	}
+	followers, err := mss.followCollection.Distinct(ctx, "follower_id", repository.NotDeleted(bson.M{
+		"follower_id": bson.M{"$in": ids},
+		"followee_id": userID,
+		"status":      models.FollowStatusAccepted,
+	}))
+	if err != nil {
+		return err
+	}
+
+	for _, id := range following {
+		if oid, ok := id.(primitive.ObjectID); ok && candidates[oid] != nil {
+			candidates[oid].follows = true
+		}
+	}
+	for _, id := range followers {
+		if oid, ok := id.(primitive.ObjectID); ok && candidates[oid] != nil {
+			candidates[oid].mutual = candidates[oid].follows
+		}
+	}
+	return nil
+}
@@ -4,10 +4,15 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"social-media-api/internal/config"
 	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+	"social-media-api/internal/utils"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -20,14 +25,50 @@ type MessageService struct {
 	conversationCollection *mongo.Collection
 	userCollection         *mongo.Collection
 	db                     *mongo.Database
+	limits                 *LimitsService
+	linkBlocklist          *LinkBlocklistService
+	mediaPolicy            MessageMediaPolicy
+	strangerMaturity       models.AccountMaturityPolicy // Held back from starting DMs with users who don't follow them
 }
 
-func NewMessageService() *MessageService {
+// MessageMediaPolicy bounds what messages may attach. AllowedTypes lists MIME
+// types, "image/*" allowing a whole family; executables and scripts are
+// refused even when listed.
+type MessageMediaPolicy struct {
+	AllowedTypes        []string
+	MaxPerMessage       int
+	MaxMessageSize      int64        // Bytes per message
+	MaxConversationSize int64        // Bytes one sender may send a conversation in any 24 hours; 0 disables
+	Scanner             MediaScanner // Optional malware scan of each attachment
+}
+
+// MediaScanner checks an attachment for malware before it is sent,
+// returning an error describing what it found
+type MediaScanner interface {
+	ScanMedia(ctx context.Context, media models.MediaInfo) error
+}
+
+// dangerousMediaExtensions can run code when opened, so messages never carry
+// them whatever type the client declares
+var dangerousMediaExtensions = map[string]bool{
+	".exe": true, ".msi": true, ".bat": true, ".cmd": true, ".com": true, ".scr": true,
+	".pif": true, ".cpl": true, ".dll": true, ".sys": true, ".vbs": true, ".vbe": true,
+	".js": true, ".jse": true, ".wsf": true, ".wsh": true, ".hta": true, ".ps1": true,
+	".psm1": true, ".sh": true, ".bash": true, ".jar": true, ".apk": true, ".app": true,
+	".dmg": true, ".pkg": true, ".deb": true, ".rpm": true, ".lnk": true, ".reg": true,
+	".iso": true, ".html": true, ".htm": true, ".svg": true,
+}
+
+func NewMessageService(limits *LimitsService, linkBlocklist *LinkBlocklistService, mediaPolicy MessageMediaPolicy, strangerMaturity models.AccountMaturityPolicy) *MessageService {
 	return &MessageService{
 		messageCollection:      config.DB.Collection("messages"),
 		conversationCollection: config.DB.Collection("conversations"),
 		userCollection:         config.DB.Collection("users"),
 		db:                     config.DB,
+		limits:                 limits,
+		linkBlocklist:          linkBlocklist,
+		mediaPolicy:            mediaPolicy,
+		strangerMaturity:       strangerMaturity,
 	}
 }
 
@@ -36,11 +77,14 @@ func (ms *MessageService) SendMessage(senderID, conversationID primitive.ObjectI
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Verify user is participant in conversation
-	if !ms.isUserInConversation(ctx, senderID, conversationID) {
-		return nil, errors.New("access denied: user not in conversation")
+	usageIDs, err := ms.prepareSend(ctx, senderID, conversationID, req.Content, req.Media)
+	if err != nil {
+		return nil, err
 	}
 
+	// Replying to a message request accepts it
+	ms.acceptPendingRequest(ctx, senderID, conversationID)
+
 	// Handle reply to message
 	var replyToMessageID *primitive.ObjectID
 	if req.ReplyToMessageID != "" {
@@ -56,6 +100,7 @@ func (ms *MessageService) SendMessage(senderID, conversationID primitive.ObjectI
 		Content:          req.Content,
 		ContentType:      req.ContentType,
 		Media:            req.Media, // Already []models.MediaInfo
+		Mentions:         ms.mentionedParticipants(ctx, conversationID, req.Content),
 		ReplyToMessageID: replyToMessageID,
 		Status:           models.MessageSent,
 		Source:           "api",
@@ -63,6 +108,7 @@ func (ms *MessageService) SendMessage(senderID, conversationID primitive.ObjectI
 		ReactionsCount:   make(map[models.ReactionType]int64), // Fixed: use ReactionType not string
 		Priority:         req.Priority,
 		ExpiresAt:        req.ExpiresAt,
+		SentByDelegate:   req.DelegateID,
 		IsEdited:         false,
 		IsForwarded:      false,
 		ForwardedFrom:    nil,
@@ -75,6 +121,9 @@ func (ms *MessageService) SendMessage(senderID, conversationID primitive.ObjectI
 	if message.Priority == "" {
 		message.Priority = "normal"
 	}
+	if err := models.CheckBounds(message); err != nil {
+		return nil, err
+	}
 
 	message.BeforeCreate()
 	now := time.Now()
@@ -83,6 +132,7 @@ func (ms *MessageService) SendMessage(senderID, conversationID primitive.ObjectI
 	// Insert message
 	result, err := ms.messageCollection.InsertOne(ctx, message)
 	if err != nil {
+		ms.releaseUsage(ctx, usageIDs)
 		return nil, err
 	}
 
@@ -97,6 +147,356 @@ func (ms *MessageService) SendMessage(senderID, conversationID primitive.ObjectI
 	return message, nil
 }
 
+// prepareSend runs the checks every new message in a conversation goes
+// through: send permission and blocks, the link blocklist, the media policy,
+// and the sender's daily media and non-follower DM limits. The returned
+// usage is released if sending then fails.
+func (ms *MessageService) prepareSend(ctx context.Context, senderID, conversationID primitive.ObjectID, content string, media []models.MediaInfo) ([]primitive.ObjectID, error) {
+	if err := ms.checkCanSend(ctx, senderID, conversationID); err != nil {
+		return nil, err
+	}
+
+	// Messages linking to blocklisted domains are refused
+	if _, err := ms.linkBlocklist.Screen(ctx, senderID, models.LinkBlockContentMessage, content); err != nil {
+		return nil, err
+	}
+
+	usageIDs, err := ms.screenMedia(ctx, senderID, conversationID, media)
+	if err != nil {
+		return nil, err
+	}
+
+	dmUsageID, err := ms.consumeNonFollowerDM(ctx, senderID, conversationID)
+	if err != nil {
+		ms.releaseUsage(ctx, usageIDs)
+		return nil, err
+	}
+
+	return append(usageIDs, dmUsageID), nil
+}
+
+// checkCanSend refuses senders who aren't participants or can't send in the
+// conversation, and direct messages between users where either blocked the other
+func (ms *MessageService) checkCanSend(ctx context.Context, senderID, conversationID primitive.ObjectID) error {
+	var conversation models.Conversation
+	err := ms.conversationCollection.FindOne(ctx, repository.NotDeleted(bson.M{
+		"_id":          conversationID,
+		"participants": senderID,
+	})).Decode(&conversation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.New("access denied: user not in conversation")
+		}
+		return err
+	}
+
+	if !conversation.CanSendMessages(senderID) {
+		return errors.New("access denied: user cannot send messages in this conversation")
+	}
+
+	if conversation.Type == "direct" {
+		var recipients []primitive.ObjectID
+		for _, participantID := range conversation.Participants {
+			if participantID != senderID {
+				recipients = append(recipients, participantID)
+			}
+		}
+		blocked, err := hasBlockBetween(ctx, ms.db, []primitive.ObjectID{senderID}, recipients)
+		if err != nil {
+			return err
+		}
+		if blocked {
+			return errors.New("access denied: user is blocked")
+		}
+	}
+
+	return nil
+}
+
+// screenMedia checks attachments against the media policy, scans them, and
+// counts them against the sender's daily attachment limit. The returned
+// usage is released if sending then fails.
+func (ms *MessageService) screenMedia(ctx context.Context, senderID, conversationID primitive.ObjectID, media []models.MediaInfo) ([]primitive.ObjectID, error) {
+	if len(media) == 0 {
+		return nil, nil
+	}
+
+	policy := ms.mediaPolicy
+	if len(media) > policy.MaxPerMessage {
+		return nil, &models.MediaRejectedError{Index: -1, Reason: fmt.Sprintf("a message can attach at most %d files", policy.MaxPerMessage)}
+	}
+
+	var total int64
+	for i, item := range media {
+		extension := mediaExtension(item.URL)
+		if dangerousMediaExtensions[extension] {
+			return nil, &models.MediaRejectedError{Index: i, Reason: fmt.Sprintf("has extension %s, which messages can't carry", extension)}
+		}
+
+		// Both the declared type and the one the extension implies must be allowed
+		mimeType := strings.ToLower(strings.TrimSpace(strings.SplitN(item.MimeType, ";", 2)[0]))
+		extensionType := utils.GetMimeType(extension)
+		if mimeType == "" {
+			mimeType = extensionType
+		}
+		if !policy.allows(mimeType) {
+			return nil, &models.MediaRejectedError{Index: i, MimeType: mimeType, Reason: fmt.Sprintf("has type %s, which messages can't carry", mimeType)}
+		}
+		if extensionType != "application/octet-stream" && !policy.allows(extensionType) {
+			return nil, &models.MediaRejectedError{Index: i, MimeType: extensionType, Reason: fmt.Sprintf("has type %s, which messages can't carry", extensionType)}
+		}
+
+		if item.Size < 0 {
+			return nil, &models.MediaRejectedError{Index: i, MimeType: mimeType, Reason: "has an invalid size"}
+		}
+		total += item.Size
+	}
+
+	if total > policy.MaxMessageSize {
+		return nil, &models.MediaRejectedError{Index: -1, Reason: fmt.Sprintf("attachments exceed %s per message", utils.FormatFileSize(policy.MaxMessageSize))}
+	}
+
+	if policy.MaxConversationSize > 0 {
+		sent, err := ms.conversationMediaSent(ctx, senderID, conversationID)
+		if err != nil {
+			return nil, err
+		}
+		if sent+total > policy.MaxConversationSize {
+			return nil, &models.MediaRejectedError{Index: -1, Reason: fmt.Sprintf("attachments exceed %s per conversation in 24 hours", utils.FormatFileSize(policy.MaxConversationSize))}
+		}
+	}
+
+	if policy.Scanner != nil {
+		for i, item := range media {
+			if err := policy.Scanner.ScanMedia(ctx, item); err != nil {
+				return nil, &models.MediaRejectedError{Index: i, MimeType: item.MimeType, Reason: "failed the malware scan: " + err.Error()}
+			}
+		}
+	}
+
+	limits, err := ms.limits.GetEffectiveLimits(ctx, senderID)
+	if err != nil {
+		return nil, err
+	}
+	var usageIDs []primitive.ObjectID
+	for range media {
+		usageID, err := ms.limits.Consume(ctx, limits, models.LimitDailyMessageMedia)
+		if err != nil {
+			ms.releaseUsage(ctx, usageIDs)
+			return nil, err
+		}
+		usageIDs = append(usageIDs, usageID)
+	}
+
+	return usageIDs, nil
+}
+
+// conversationMediaSent totals the bytes of media the sender attached in the
+// conversation over the last 24 hours
+func (ms *MessageService) conversationMediaSent(ctx context.Context, senderID, conversationID primitive.ObjectID) (int64, error) {
+	cursor, err := ms.messageCollection.Aggregate(ctx, []bson.M{
+		{"$match": bson.M{
+			"conversation_id": conversationID,
+			"sender_id":       senderID,
+			"created_at":      bson.M{"$gt": time.Now().Add(-24 * time.Hour)},
+			"media.0":         bson.M{"$exists": true},
+		}},
+		{"$unwind": "$media"},
+		{"$group": bson.M{"_id": nil, "total": bson.M{"$sum": "$media.size"}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []struct {
+		Total int64 `bson:"total"`
+	}
+	if err := cursor.All(ctx, &result); err != nil || len(result) == 0 {
+		return 0, err
+	}
+	return result[0].Total, nil
+}
+
+func (ms *MessageService) releaseUsage(ctx context.Context, usageIDs []primitive.ObjectID) {
+	for _, usageID := range usageIDs {
+		ms.limits.Release(ctx, usageID)
+	}
+}
+
+// allows checks a MIME type against the allowed types
+func (p MessageMediaPolicy) allows(mimeType string) bool {
+	for _, allowed := range p.AllowedTypes {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == mimeType || (strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mimeType, strings.TrimSuffix(allowed, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// mediaExtension returns the lowercased file extension of a media URL's path
+func mediaExtension(rawURL string) string {
+	if parsed, err := url.Parse(rawURL); err == nil {
+		rawURL = parsed.Path
+	}
+	return utils.GetFileExtension(rawURL)
+}
+
+// consumeNonFollowerDM counts a direct message against the sender's daily
+// non-follower quota when the recipient doesn't follow the sender. Accounts
+// too new to message strangers can't send one in a conversation they started.
+func (ms *MessageService) consumeNonFollowerDM(ctx context.Context, senderID, conversationID primitive.ObjectID) (primitive.ObjectID, error) {
+	var conversation models.Conversation
+	err := ms.conversationCollection.FindOne(ctx, bson.M{"_id": conversationID},
+		options.FindOne().SetProjection(bson.M{"type": 1, "participants": 1, "created_by": 1})).Decode(&conversation)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	if conversation.Type != "direct" {
+		return primitive.NilObjectID, nil
+	}
+
+	for _, recipientID := range conversation.Participants {
+		if recipientID == senderID {
+			continue
+		}
+		count, err := ms.db.Collection("follows").CountDocuments(ctx, repository.NotDeleted(bson.M{
+			"follower_id": recipientID,
+			"followee_id": senderID,
+			"status":      models.FollowStatusAccepted,
+		}))
+		if err != nil {
+			return primitive.NilObjectID, err
+		}
+		if count > 0 {
+			return primitive.NilObjectID, nil
+		}
+	}
+
+	// Replying to someone who started the conversation is always allowed
+	if conversation.CreatedBy == senderID {
+		if err := checkAccountMaturity(ctx, ms.userCollection, ms.strangerMaturity, senderID); err != nil {
+			return primitive.NilObjectID, err
+		}
+	}
+
+	limits, err := ms.limits.GetEffectiveLimits(ctx, senderID)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return ms.limits.Consume(ctx, limits, models.LimitDailyNonFollowerDMs)
+}
+
+// ForwardMessage copies a message's content and media into each target
+// conversation, attributed to the original message and its sender. Each
+// forward goes through the checks and limits sending a message does, but
+// unlike replying it doesn't accept a message request. delegateID is set when
+// a delegate forwards for the account.
+func (ms *MessageService) ForwardMessage(userID, messageID primitive.ObjectID, targetConversationIDs []primitive.ObjectID, delegateID *primitive.ObjectID) ([]models.Message, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Ignore repeated targets
+	var targets []primitive.ObjectID
+	seen := make(map[primitive.ObjectID]bool)
+	for _, targetID := range targetConversationIDs {
+		if !seen[targetID] {
+			seen[targetID] = true
+			targets = append(targets, targetID)
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil, errors.New("at least one target conversation is required")
+	}
+	if len(targets) > utils.MaxForwardTargets {
+		return nil, fmt.Errorf("cannot forward to more than %d conversations", utils.MaxForwardTargets)
+	}
+
+	var original models.Message
+	err := ms.messageCollection.FindOne(ctx, repository.ByID(messageID)).Decode(&original)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("message not found")
+		}
+		return nil, err
+	}
+
+	if !ms.isUserInConversation(ctx, userID, original.ConversationID) {
+		return nil, errors.New("access denied: user not in conversation")
+	}
+
+	if original.IsSystemMessage() {
+		return nil, errors.New("system messages cannot be forwarded")
+	}
+
+	original.CheckExpiration()
+	if original.IsExpired {
+		return nil, errors.New("message has expired")
+	}
+
+	// Media withheld from the user in a message request can't be passed on
+	sources := []models.Message{original}
+	ms.hideRequestMedia(ctx, userID, sources)
+	if sources[0].MediaHidden {
+		return nil, errors.New("cannot forward media from a message request before accepting it")
+	}
+
+	// Check every target as a new message before sending anything. Usage
+	// left here when forwarding stops is released.
+	usage := make(map[primitive.ObjectID][]primitive.ObjectID, len(targets))
+	defer func() {
+		for _, usageIDs := range usage {
+			ms.releaseUsage(ctx, usageIDs)
+		}
+	}()
+	for _, targetID := range targets {
+		usageIDs, err := ms.prepareSend(ctx, userID, targetID, original.Content, original.Media)
+		if err != nil {
+			return nil, err
+		}
+		usage[targetID] = usageIDs
+	}
+
+	forwarded := make([]models.Message, 0, len(targets))
+	for _, targetID := range targets {
+		message := models.Message{
+			ConversationID: targetID,
+			SenderID:       userID,
+			Content:        original.Content,
+			ContentType:    original.ContentType,
+			Media:          original.Media,
+			Duration:       original.Duration,
+			Status:         models.MessageSent,
+			Source:         "api",
+			ReadBy:         []models.MessageReadReceipt{},
+			ReactionsCount: make(map[models.ReactionType]int64),
+			Priority:       "normal",
+			SentByDelegate: delegateID,
+		}
+
+		message.BeforeCreate()
+		message.MarkAsForwarded(&original)
+		now := time.Now()
+		message.SentAt = &now
+
+		result, err := ms.messageCollection.InsertOne(ctx, message)
+		if err != nil {
+			return forwarded, err
+		}
+		message.ID = result.InsertedID.(primitive.ObjectID)
+		delete(usage, targetID)
+
+		go ms.updateConversationLastMessage(targetID, &message)
+
+		ms.populateMessageSender(ctx, &message)
+		forwarded = append(forwarded, message)
+	}
+
+	return forwarded, nil
+}
+
 // GetConversationMessages retrieves messages from a conversation
 func (ms *MessageService) GetConversationMessages(conversationID, userID primitive.ObjectID, limit, skip int) ([]models.Message, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -107,10 +507,9 @@ func (ms *MessageService) GetConversationMessages(conversationID, userID primiti
 		return nil, errors.New("access denied: user not in conversation")
 	}
 
-	filter := bson.M{
+	filter := repository.NotDeleted(bson.M{
 		"conversation_id": conversationID,
-		"deleted_at":      bson.M{"$exists": false},
-	}
+	})
 
 	opts := options.Find().
 		SetLimit(int64(limit)).
@@ -137,20 +536,110 @@ func (ms *MessageService) GetConversationMessages(conversationID, userID primiti
 			ms.populateReplyToMessage(ctx, &messages[i])
 		}
 	}
+	ms.hideRequestMedia(ctx, userID, messages)
 
 	return messages, nil
 }
 
+// GetMessagesAround returns up to before messages older than the target and
+// up to after newer ones, with the target in between, so clients can jump to
+// a message without loading the history in front of it
+func (ms *MessageService) GetMessagesAround(conversationID, userID, messageID primitive.ObjectID, before, after int) (*models.MessageWindow, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if !ms.isUserInConversation(ctx, userID, conversationID) {
+		return nil, errors.New("access denied: user not in conversation")
+	}
+
+	target, err := ms.getConversationMessage(ctx, conversationID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	older, hasOlder, err := ms.getMessagesBeside(ctx, target, true, clampWindowSize(before))
+	if err != nil {
+		return nil, err
+	}
+	newer, hasNewer, err := ms.getMessagesBeside(ctx, target, false, clampWindowSize(after))
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]models.Message, 0, len(older)+1+len(newer))
+	messages = append(messages, older...)
+	messages = append(messages, *target)
+	messages = append(messages, newer...)
+
+	for i := range messages {
+		ms.populateMessageSender(ctx, &messages[i])
+		if messages[i].ReplyToMessageID != nil {
+			ms.populateReplyToMessage(ctx, &messages[i])
+		}
+	}
+	ms.hideRequestMedia(ctx, userID, messages)
+
+	return &models.MessageWindow{
+		Messages: messages,
+		HasOlder: hasOlder,
+		HasNewer: hasNewer,
+	}, nil
+}
+
+// GetMessagesFromCursor pages on from a message window cursor: up to limit
+// messages older than the cursor message, or newer when older is false
+func (ms *MessageService) GetMessagesFromCursor(conversationID, userID, cursorID primitive.ObjectID, older bool, limit int) (*models.MessageWindow, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if !ms.isUserInConversation(ctx, userID, conversationID) {
+		return nil, errors.New("access denied: user not in conversation")
+	}
+
+	// The cursor message may have been deleted since the window was loaded;
+	// its position is all that's needed
+	var cursor models.Message
+	err := ms.messageCollection.FindOne(ctx, bson.M{
+		"_id":             cursorID,
+		"conversation_id": conversationID,
+	}).Decode(&cursor)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("cursor message not found in conversation")
+		}
+		return nil, err
+	}
+
+	messages, hasMore, err := ms.getMessagesBeside(ctx, &cursor, older, clampWindowSize(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range messages {
+		ms.populateMessageSender(ctx, &messages[i])
+		if messages[i].ReplyToMessageID != nil {
+			ms.populateReplyToMessage(ctx, &messages[i])
+		}
+	}
+	ms.hideRequestMedia(ctx, userID, messages)
+
+	// The other direction always has at least the cursor message
+	window := &models.MessageWindow{Messages: messages}
+	if older {
+		window.HasOlder, window.HasNewer = hasMore, true
+	} else {
+		window.HasOlder, window.HasNewer = true, hasMore
+	}
+	return window, nil
+}
+
 // GetMessageByID retrieves a specific message
 func (ms *MessageService) GetMessageByID(messageID, userID primitive.ObjectID) (*models.Message, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	var message models.Message
-	err := ms.messageCollection.FindOne(ctx, bson.M{
-		"_id":        messageID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&message)
+	err := ms.messageCollection.FindOne(ctx, repository.ByID(messageID)).Decode(&message)
 
 	if err != nil {
 		return nil, err
@@ -168,8 +657,10 @@ func (ms *MessageService) GetMessageByID(messageID, userID primitive.ObjectID) (
 	if message.ReplyToMessageID != nil {
 		ms.populateReplyToMessage(ctx, &message)
 	}
+	messages := []models.Message{message}
+	ms.hideRequestMedia(ctx, userID, messages)
 
-	return &message, nil
+	return &messages[0], nil
 }
 
 // UpdateMessage updates an existing message
@@ -179,11 +670,10 @@ func (ms *MessageService) UpdateMessage(messageID, userID primitive.ObjectID, re
 
 	// Get existing message
 	var message models.Message
-	err := ms.messageCollection.FindOne(ctx, bson.M{
-		"_id":        messageID,
-		"sender_id":  userID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&message)
+	err := ms.messageCollection.FindOne(ctx, repository.NotDeleted(bson.M{
+		"_id":       messageID,
+		"sender_id": userID,
+	})).Decode(&message)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -202,12 +692,25 @@ func (ms *MessageService) UpdateMessage(messageID, userID primitive.ObjectID, re
 	update := bson.M{"$set": bson.M{"updated_at": now}}
 
 	if req.Content != "" {
+		if _, err := ms.linkBlocklist.Screen(ctx, userID, models.LinkBlockContentMessage, req.Content); err != nil {
+			return nil, err
+		}
+		mentions := ms.mentionedParticipants(ctx, message.ConversationID, req.Content)
+		if err := models.CheckBounds(models.Message{Mentions: mentions}); err != nil {
+			return nil, err
+		}
 		update["$set"].(bson.M)["content"] = req.Content
+		update["$set"].(bson.M)["mentions"] = mentions
 		update["$set"].(bson.M)["is_edited"] = true
 		update["$set"].(bson.M)["edited_at"] = now
 	}
 
+	var mediaUsageIDs []primitive.ObjectID
 	if len(req.Media) > 0 {
+		mediaUsageIDs, err = ms.screenMedia(ctx, userID, message.ConversationID, req.Media)
+		if err != nil {
+			return nil, err
+		}
 		update["$set"].(bson.M)["media"] = req.Media
 		update["$set"].(bson.M)["is_edited"] = true
 		update["$set"].(bson.M)["edited_at"] = now
@@ -216,6 +719,7 @@ func (ms *MessageService) UpdateMessage(messageID, userID primitive.ObjectID, re
 	// Update message
 	_, err = ms.messageCollection.UpdateOne(ctx, bson.M{"_id": messageID}, update)
 	if err != nil {
+		ms.releaseUsage(ctx, mediaUsageIDs)
 		return nil, err
 	}
 
@@ -230,10 +734,7 @@ func (ms *MessageService) DeleteMessage(messageID, userID primitive.ObjectID) (*
 
 	// Get message to verify ownership and get conversation info
 	var message models.Message
-	err := ms.messageCollection.FindOne(ctx, bson.M{
-		"_id":        messageID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&message)
+	err := ms.messageCollection.FindOne(ctx, repository.ByID(messageID)).Decode(&message)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -265,14 +766,21 @@ func (ms *MessageService) DeleteMessage(messageID, userID primitive.ObjectID) (*
 	return &message, nil
 }
 
-// MarkMessagesAsRead marks messages as read for a user
-func (ms *MessageService) MarkMessagesAsRead(conversationID, userID, lastMessageID primitive.ObjectID) error {
+// MarkMessagesAsRead marks messages as read for a user, reporting whether a
+// read receipt was recorded. None is while the conversation is still a
+// message request for the user, and none should be broadcast.
+func (ms *MessageService) MarkMessagesAsRead(conversationID, userID, lastMessageID primitive.ObjectID) (bool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	// Verify user is in conversation
 	if !ms.isUserInConversation(ctx, userID, conversationID) {
-		return errors.New("access denied: user not in conversation")
+		return false, errors.New("access denied: user not in conversation")
+	}
+
+	// Senders must not see read receipts while the conversation is a message request
+	if ms.isPendingRequest(ctx, userID, conversationID) {
+		return false, nil
 	}
 
 	now := time.Now()
@@ -282,13 +790,12 @@ func (ms *MessageService) MarkMessagesAsRead(conversationID, userID, lastMessage
 	}
 
 	// Update messages as read
-	filter := bson.M{
+	filter := repository.NotDeleted(bson.M{
 		"conversation_id": conversationID,
 		"_id":             bson.M{"$lte": lastMessageID},
 		"sender_id":       bson.M{"$ne": userID}, // Don't mark own messages
 		"read_by.user_id": bson.M{"$ne": userID}, // Don't update if already read
-		"deleted_at":      bson.M{"$exists": false},
-	}
+	})
 
 	update := bson.M{
 		"$set": bson.M{
@@ -300,8 +807,10 @@ func (ms *MessageService) MarkMessagesAsRead(conversationID, userID, lastMessage
 		},
 	}
 
-	_, err := ms.messageCollection.UpdateMany(ctx, filter, update)
-	return err
+	if _, err := ms.messageCollection.UpdateMany(ctx, filter, update); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 // SearchMessages searches for messages
@@ -310,10 +819,9 @@ func (ms *MessageService) SearchMessages(userID primitive.ObjectID, query string
 	defer cancel()
 
 	// Build search filter
-	filter := bson.M{
-		"content":    bson.M{"$regex": query, "$options": "i"},
-		"deleted_at": bson.M{"$exists": false},
-	}
+	filter := repository.NotDeleted(bson.M{
+		"content": bson.M{"$regex": query, "$options": "i"},
+	})
 
 	// Add conversation filter if specified
 	if conversationID != nil {
@@ -351,6 +859,7 @@ func (ms *MessageService) SearchMessages(userID primitive.ObjectID, query string
 	for i := range messages {
 		ms.populateMessageSender(ctx, &messages[i])
 	}
+	ms.hideRequestMedia(ctx, userID, messages)
 
 	return messages, nil
 }
@@ -362,10 +871,7 @@ func (ms *MessageService) ReactToMessage(messageID, userID primitive.ObjectID, r
 
 	// Get message
 	var message models.Message
-	err := ms.messageCollection.FindOne(ctx, bson.M{
-		"_id":        messageID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&message)
+	err := ms.messageCollection.FindOne(ctx, repository.ByID(messageID)).Decode(&message)
 
 	if err != nil {
 		return err
@@ -378,6 +884,9 @@ func (ms *MessageService) ReactToMessage(messageID, userID primitive.ObjectID, r
 
 	// Update reaction count
 	if action == "add" {
+		if err := ensureReactionAvailable(ctx, ms.db, reactionType); err != nil {
+			return err
+		}
 		message.AddReaction(reactionType)
 	} else if action == "remove" {
 		message.RemoveReaction(reactionType)
@@ -398,32 +907,229 @@ func (ms *MessageService) ReactToMessage(messageID, userID primitive.ObjectID, r
 // Helper methods
 
 // isUserInConversation checks if user is participant in conversation
+// getConversationMessage loads a message that isn't deleted and belongs to the conversation
+func (ms *MessageService) getConversationMessage(ctx context.Context, conversationID, messageID primitive.ObjectID) (*models.Message, error) {
+	var message models.Message
+	err := ms.messageCollection.FindOne(ctx, repository.NotDeleted(bson.M{
+		"_id":             messageID,
+		"conversation_id": conversationID,
+	})).Decode(&message)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("message not found in conversation")
+		}
+		return nil, err
+	}
+	return &message, nil
+}
+
+// getMessagesBeside returns up to limit messages next to the anchor in its
+// conversation, oldest first, and whether there are more beyond them.
+// Messages are ordered by creation time, then ID for messages created in the
+// same instant.
+func (ms *MessageService) getMessagesBeside(ctx context.Context, anchor *models.Message, older bool, limit int) ([]models.Message, bool, error) {
+	if limit == 0 {
+		return []models.Message{}, ms.hasMessagesBeside(ctx, anchor, older), nil
+	}
+
+	cmp, order := "$gt", 1
+	if older {
+		cmp, order = "$lt", -1
+	}
+
+	filter := repository.NotDeleted(bson.M{
+		"conversation_id": anchor.ConversationID,
+		"$or": []bson.M{
+			{"created_at": bson.M{cmp: anchor.CreatedAt}},
+			{"created_at": anchor.CreatedAt, "_id": bson.M{cmp: anchor.ID}},
+		},
+	})
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: order}, {Key: "_id", Value: order}}).
+		SetLimit(int64(limit + 1))
+
+	cursor, err := ms.messageCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, false, err
+	}
+	defer cursor.Close(ctx)
+
+	messages := []models.Message{}
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(messages) > limit
+	if hasMore {
+		messages = messages[:limit]
+	}
+
+	if older {
+		for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+			messages[i], messages[j] = messages[j], messages[i]
+		}
+	}
+
+	return messages, hasMore, nil
+}
+
+// hasMessagesBeside checks if any message comes before (or after) the anchor
+func (ms *MessageService) hasMessagesBeside(ctx context.Context, anchor *models.Message, older bool) bool {
+	messages, hasMore, err := ms.getMessagesBeside(ctx, anchor, older, 1)
+	return err == nil && (hasMore || len(messages) > 0)
+}
+
+// clampWindowSize keeps a requested message window side within bounds
+func clampWindowSize(size int) int {
+	if size < 0 {
+		return 0
+	}
+	if size > utils.MaxMessagesAround {
+		return utils.MaxMessagesAround
+	}
+	return size
+}
+
 func (ms *MessageService) isUserInConversation(ctx context.Context, userID, conversationID primitive.ObjectID) bool {
-	count, err := ms.conversationCollection.CountDocuments(ctx, bson.M{
+	count, err := ms.conversationCollection.CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"_id":          conversationID,
 		"participants": userID,
-		"deleted_at":   bson.M{"$exists": false},
+	}))
+	return err == nil && count > 0
+}
+
+// mentionedParticipants resolves the users @-mentioned in content, limited to
+// participants of the conversation
+func (ms *MessageService) mentionedParticipants(ctx context.Context, conversationID primitive.ObjectID, content string) []primitive.ObjectID {
+	usernames := utils.ParseEntities(content).Usernames()
+	if len(usernames) == 0 {
+		return nil
+	}
+
+	var conversation models.Conversation
+	err := ms.conversationCollection.FindOne(ctx, bson.M{"_id": conversationID},
+		options.FindOne().SetProjection(bson.M{"participants": 1})).Decode(&conversation)
+	if err != nil {
+		return nil
+	}
+
+	cursor, err := ms.userCollection.Find(ctx, bson.M{
+		"_id":      bson.M{"$in": conversation.Participants},
+		"username": bson.M{"$in": usernames},
+	}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil
+	}
+
+	var mentions []primitive.ObjectID
+	for _, user := range users {
+		mentions = append(mentions, user.ID)
+	}
+	return mentions
+}
+
+// isPendingRequest checks if the conversation is still a message request for the user
+func (ms *MessageService) isPendingRequest(ctx context.Context, userID, conversationID primitive.ObjectID) bool {
+	count, err := ms.conversationCollection.CountDocuments(ctx, bson.M{
+		"_id": conversationID,
+		"participant_info": bson.M{"$elemMatch": bson.M{
+			"user_id":        userID,
+			"request_status": models.ConversationRequestPending,
+		}},
 	})
 	return err == nil && count > 0
 }
 
+// hideRequestMedia withholds media others sent in conversations that are
+// still message requests for the user; it shows once they accept
+func (ms *MessageService) hideRequestMedia(ctx context.Context, userID primitive.ObjectID, messages []models.Message) {
+	var conversationIDs []primitive.ObjectID
+	for _, message := range messages {
+		if message.SenderID != userID && (len(message.Media) > 0 || message.ReplyToMessage != nil) {
+			conversationIDs = append(conversationIDs, message.ConversationID)
+		}
+	}
+	if len(conversationIDs) == 0 {
+		return
+	}
+
+	filter := requestsFilter(userID)
+	filter["_id"] = bson.M{"$in": conversationIDs}
+	cursor, err := ms.conversationCollection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return
+	}
+	var requests []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &requests); err != nil || len(requests) == 0 {
+		return
+	}
+	pending := make(map[primitive.ObjectID]bool, len(requests))
+	for _, request := range requests {
+		pending[request.ID] = true
+	}
+
+	for i := range messages {
+		message := &messages[i]
+		if message.SenderID == userID || !pending[message.ConversationID] {
+			continue
+		}
+		if len(message.Media) > 0 {
+			message.Media = nil
+			message.MediaHidden = true
+		}
+		if reply := message.ReplyToMessage; reply != nil && reply.SenderID != userID.Hex() && len(reply.Media) > 0 {
+			reply.Media = nil
+			reply.MediaHidden = true
+		}
+	}
+}
+
+// acceptPendingRequest accepts a message request the user is sending into
+func (ms *MessageService) acceptPendingRequest(ctx context.Context, userID, conversationID primitive.ObjectID) {
+	if !ms.isPendingRequest(ctx, userID, conversationID) {
+		return
+	}
+
+	ms.conversationCollection.UpdateOne(ctx, bson.M{"_id": conversationID}, bson.M{
+		"$unset": bson.M{"participant_info.$[p].request_status": ""},
+	}, options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"p.user_id": userID}},
+	}))
+}
+
 // isConversationAdmin checks if user is admin of conversation
 func (ms *MessageService) isConversationAdmin(ctx context.Context, userID, conversationID primitive.ObjectID) bool {
-	count, err := ms.conversationCollection.CountDocuments(ctx, bson.M{
-		"_id":        conversationID,
-		"admin_ids":  userID,
-		"deleted_at": bson.M{"$exists": false},
-	})
+	count, err := ms.conversationCollection.CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"_id":       conversationID,
+		"admin_ids": userID,
+	}))
 	return err == nil && count > 0
 }
 
-// populateMessageSender populates sender information for message
+// populateMessageSender populates sender information for message, and the
+// original sender for forwarded messages
 func (ms *MessageService) populateMessageSender(ctx context.Context, message *models.Message) {
 	var user models.User
 	err := ms.userCollection.FindOne(ctx, bson.M{"_id": message.SenderID}).Decode(&user)
 	if err == nil {
 		message.Sender = user.ToUserResponse()
 	}
+
+	if message.ForwardedFromSenderID != nil {
+		var originalSender models.User
+		if err := ms.userCollection.FindOne(ctx, bson.M{"_id": *message.ForwardedFromSenderID}).Decode(&originalSender); err == nil {
+			response := originalSender.ToUserResponse()
+			message.ForwardedFromSender = &response
+		}
+	}
 }
 
 // populateReplyToMessage populates reply to message information
@@ -433,10 +1139,9 @@ func (ms *MessageService) populateReplyToMessage(ctx context.Context, message *m
 	}
 
 	var replyToMessage models.Message
-	err := ms.messageCollection.FindOne(ctx, bson.M{
-		"_id":        *message.ReplyToMessageID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&replyToMessage)
+	err := ms.messageCollection.FindOne(ctx, repository.NotDeleted(bson.M{
+		"_id": *message.ReplyToMessageID,
+	})).Decode(&replyToMessage)
 
 	if err == nil {
 		ms.populateMessageSender(ctx, &replyToMessage)
@@ -447,10 +1152,9 @@ func (ms *MessageService) populateReplyToMessage(ctx context.Context, message *m
 
 // getUserConversationIDs gets all conversation IDs for a user
 func (ms *MessageService) getUserConversationIDs(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error) {
-	filter := bson.M{
+	filter := repository.NotDeleted(bson.M{
 		"participants": userID,
-		"deleted_at":   bson.M{"$exists": false},
-	}
+	})
 
 	cursor, err := ms.conversationCollection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
 	if err != nil {
@@ -502,7 +1206,7 @@ func (ms *MessageService) GetMessageStats(conversationID *primitive.ObjectID, us
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	filter := bson.M{"deleted_at": bson.M{"$exists": false}}
+	filter := repository.NotDeleted()
 
 	if conversationID != nil {
 		filter["conversation_id"] = *conversationID
@@ -0,0 +1,248 @@
+package services_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newTestMessageService(h *testutil.Harness) *services.MessageService {
+	return services.NewMessageService(services.NewLimitsService(h.DB, time.Minute),
+		services.NewLinkBlocklistService(h.DB, services.LinkBlocklistPolicy{CacheTTL: time.Minute}),
+		services.MessageMediaPolicy{AllowedTypes: []string{"image/*"}, MaxPerMessage: 4, MaxMessageSize: 10 << 20},
+		models.AccountMaturityPolicy{})
+}
+
+func textMessage(content string) models.CreateMessageRequest {
+	return models.CreateMessageRequest{Content: content, ContentType: models.ContentTypeText}
+}
+
+func TestSendMessageRefusesBlockedAndMutedSenders(t *testing.T) {
+	h := testutil.NewHarness(t)
+	messages := newTestMessageService(h)
+	sender := h.CreateUser()
+	recipient := h.CreateUser()
+	outsider := h.CreateUser()
+
+	direct := h.CreateConversation(sender, []*models.User{recipient})
+	if _, err := messages.SendMessage(sender.ID, direct.ID, textMessage("hello")); err != nil {
+		t.Fatalf("SendMessage before any block: %v", err)
+	}
+
+	if _, err := messages.SendMessage(outsider.ID, direct.ID, textMessage("let me in")); err == nil {
+		t.Error("a user outside the conversation sent a message")
+	}
+
+	// Either side blocking stops the direct conversation both ways
+	if _, err := h.DB.Collection("blocked_users").InsertOne(h.Context(), bson.M{
+		"blocker_id": recipient.ID,
+		"blocked_id": sender.ID,
+		"is_active":  true,
+	}); err != nil {
+		t.Fatalf("inserting block: %v", err)
+	}
+	for _, user := range []*models.User{sender, recipient} {
+		if _, err := messages.SendMessage(user.ID, direct.ID, textMessage("still there?")); err == nil || !strings.Contains(err.Error(), "blocked") {
+			t.Errorf("SendMessage across a block: err = %v, want blocked", err)
+		}
+	}
+
+	// A participant whose sending rights were taken away can't send
+	group := h.CreateConversation(sender, []*models.User{recipient, outsider}, func(c *models.Conversation) {
+		for i := range c.ParticipantInfo {
+			if c.ParticipantInfo[i].UserID == outsider.ID {
+				c.ParticipantInfo[i].CanSendMessages = false
+			}
+		}
+	})
+	if _, err := messages.SendMessage(outsider.ID, group.ID, textMessage("hi all")); err == nil {
+		t.Error("a participant who can't send messages sent one")
+	}
+
+	if got := h.Count("messages", bson.M{}); got != 1 {
+		t.Errorf("messages stored = %d, want only the first", got)
+	}
+}
+
+func TestMessageRequestsWithholdReceiptsAndMedia(t *testing.T) {
+	h := testutil.NewHarness(t)
+	messages := newTestMessageService(h)
+	conversations := services.NewConversationService(10, services.ConversationActivityPolicy{}, models.AccountMaturityPolicy{}, nil)
+	sender := h.CreateUser()
+	recipient := h.CreateUser()
+
+	request := h.CreateConversation(sender, []*models.User{recipient}, testutil.WithRequestPending(recipient))
+	sent, err := messages.SendMessage(sender.ID, request.ID, models.CreateMessageRequest{
+		Content:     "see this",
+		ContentType: models.ContentTypeImage,
+		Media:       []models.MediaInfo{{URL: "https://cdn.example.com/photo.jpg", Type: "image", MimeType: "image/jpeg", Size: 2048}},
+	})
+	if err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	received, err := messages.GetConversationMessages(request.ID, recipient.ID, 20, 0)
+	if err != nil {
+		t.Fatalf("GetConversationMessages: %v", err)
+	}
+	if len(received) != 1 || len(received[0].Media) != 0 || !received[0].MediaHidden {
+		t.Errorf("recipient got %+v, want the message with its media hidden", received)
+	}
+
+	recorded, err := messages.MarkMessagesAsRead(request.ID, recipient.ID, sent.ID)
+	if err != nil {
+		t.Fatalf("MarkMessagesAsRead: %v", err)
+	}
+	if recorded {
+		t.Error("reading a message request recorded a receipt")
+	}
+	if got := h.Count("messages", bson.M{"read_by.user_id": recipient.ID}); got != 0 {
+		t.Errorf("messages with the recipient's receipt = %d, want 0", got)
+	}
+
+	data, _, err := conversations.ExportConversation(recipient.ID, request.ID, models.ConversationExportJSON)
+	if err != nil {
+		t.Fatalf("ExportConversation: %v", err)
+	}
+	if strings.Contains(string(data), "photo.jpg") || !strings.Contains(string(data), `"media_hidden": true`) {
+		t.Errorf("recipient's export = %s, want the media hidden", data)
+	}
+
+	// The sender still sees and exports what they sent
+	data, _, err = conversations.ExportConversation(sender.ID, request.ID, models.ConversationExportJSON)
+	if err != nil {
+		t.Fatalf("ExportConversation by sender: %v", err)
+	}
+	if !strings.Contains(string(data), "photo.jpg") {
+		t.Errorf("sender's export = %s, want the media", data)
+	}
+
+	// Once accepted, the receipt is recorded
+	if err := conversations.AcceptMessageRequest(request.ID, recipient.ID); err != nil {
+		t.Fatalf("AcceptMessageRequest: %v", err)
+	}
+	if recorded, err := messages.MarkMessagesAsRead(request.ID, recipient.ID, sent.ID); err != nil || !recorded {
+		t.Errorf("MarkMessagesAsRead after accepting = %v, %v, want a recorded receipt", recorded, err)
+	}
+}
+
+func TestForwardMessageScreensMedia(t *testing.T) {
+	h := testutil.NewHarness(t)
+	messages := newTestMessageService(h)
+	user := h.CreateUser()
+	source := h.CreateConversation(user, []*models.User{h.CreateUser()})
+	target := h.CreateConversation(user, []*models.User{h.CreateUser()})
+
+	// Stored directly, as messages sent before the media policy tightened were
+	stored := func(media models.MediaInfo) *models.Message {
+		message := &models.Message{
+			ConversationID: source.ID,
+			SenderID:       user.ID,
+			ContentType:    models.ContentTypeFile,
+			Media:          []models.MediaInfo{media},
+			Status:         models.MessageSent,
+		}
+		message.BeforeCreate()
+		result, err := h.DB.Collection("messages").InsertOne(h.Context(), message)
+		if err != nil {
+			t.Fatalf("inserting message: %v", err)
+		}
+		message.ID = result.InsertedID.(primitive.ObjectID)
+		return message
+	}
+
+	tests := []struct {
+		name  string
+		media models.MediaInfo
+	}{
+		{"executable", models.MediaInfo{URL: "https://cdn.example.com/setup.exe", Type: "file", Size: 1024}},
+		{"type outside the allowlist", models.MediaInfo{URL: "https://cdn.example.com/notes.pdf", Type: "file", MimeType: "application/pdf", Size: 1024}},
+		{"over the message size", models.MediaInfo{URL: "https://cdn.example.com/huge.png", Type: "image", MimeType: "image/png", Size: 11 << 20}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := stored(tt.media)
+			_, err := messages.ForwardMessage(user.ID, original.ID, []primitive.ObjectID{target.ID}, nil)
+			var mediaErr *models.MediaRejectedError
+			if !errors.As(err, &mediaErr) {
+				t.Errorf("ForwardMessage error = %v, want the media rejected", err)
+			}
+		})
+	}
+
+	if got := h.Count("messages", bson.M{"conversation_id": target.ID}); got != 0 {
+		t.Errorf("messages forwarded = %d, want 0", got)
+	}
+
+	original := stored(models.MediaInfo{URL: "https://cdn.example.com/photo.png", Type: "image", MimeType: "image/png", Size: 1024})
+	if _, err := messages.ForwardMessage(user.ID, original.ID, []primitive.ObjectID{target.ID}, nil); err != nil {
+		t.Errorf("ForwardMessage of an allowed image: %v", err)
+	}
+}
+
+func TestForwardMessageGoesThroughSendChecks(t *testing.T) {
+	h := testutil.NewHarness(t)
+	if err := services.NewLinkBlocklistService(h.DB, services.LinkBlocklistPolicy{CacheTTL: time.Minute}).SeedDomains([]string{"spam.example"}); err != nil {
+		t.Fatalf("SeedDomains: %v", err)
+	}
+	messages := newTestMessageService(h)
+	user := h.CreateUser()
+	friend := h.CreateUser()
+	stranger := h.CreateUser()
+
+	source := h.CreateConversation(user, []*models.User{friend})
+	original, err := messages.SendMessage(user.ID, source.ID, textMessage("worth a read"))
+	if err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	// Forwarding into a request the user hasn't accepted leaves it pending
+	request := h.CreateConversation(stranger, []*models.User{user}, testutil.WithRequestPending(user))
+	if _, err := messages.ForwardMessage(user.ID, original.ID, []primitive.ObjectID{request.ID}, nil); err != nil {
+		t.Fatalf("ForwardMessage into a request: %v", err)
+	}
+	var reloaded models.Conversation
+	if err := h.DB.Collection("conversations").FindOne(h.Context(), bson.M{"_id": request.ID}).Decode(&reloaded); err != nil {
+		t.Fatalf("reloading request: %v", err)
+	}
+	if !reloaded.IsPendingRequest(user.ID) {
+		t.Error("forwarding into a message request accepted it")
+	}
+
+	// A link blocklisted after the original was sent can't be forwarded
+	linked := &models.Message{ConversationID: source.ID, SenderID: friend.ID, Content: "https://spam.example/win", ContentType: models.ContentTypeText}
+	linked.BeforeCreate()
+	result, err := h.DB.Collection("messages").InsertOne(h.Context(), linked)
+	if err != nil {
+		t.Fatalf("inserting message: %v", err)
+	}
+	target := h.CreateConversation(user, []*models.User{h.CreateUser()})
+	if _, err := messages.ForwardMessage(user.ID, result.InsertedID.(primitive.ObjectID), []primitive.ObjectID{target.ID}, nil); err == nil || !strings.Contains(err.Error(), "blocked link") {
+		t.Errorf("forwarding a blocked link: err = %v, want blocked link", err)
+	}
+
+	// Nor can anything be forwarded across a block
+	if _, err := h.DB.Collection("blocked_users").InsertOne(h.Context(), bson.M{
+		"blocker_id": friend.ID,
+		"blocked_id": user.ID,
+		"is_active":  true,
+	}); err != nil {
+		t.Fatalf("inserting block: %v", err)
+	}
+	other := h.CreateConversation(user, []*models.User{h.CreateUser()})
+	if _, err := messages.ForwardMessage(user.ID, original.ID, []primitive.ObjectID{other.ID, source.ID}, nil); err == nil || !strings.Contains(err.Error(), "blocked") {
+		t.Errorf("forwarding into a blocked conversation: err = %v, want blocked", err)
+	}
+	if got := h.Count("messages", bson.M{"conversation_id": bson.M{"$in": []primitive.ObjectID{target.ID, other.ID}}}); got != 0 {
+		t.Errorf("messages forwarded after a refused target = %d, want 0", got)
+	}
+}
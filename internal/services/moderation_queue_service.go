@@ -0,0 +1,345 @@
+// internal/services/moderation_queue_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"social-media-api/internal/config"
+	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ModerationQueueService struct {
+	db              *mongo.Database
+	claimCollection *mongo.Collection
+}
+
+func NewModerationQueueService() *ModerationQueueService {
+	return &ModerationQueueService{
+		db:              config.DB,
+		claimCollection: config.DB.Collection("moderation_claims"),
+	}
+}
+
+// moderationQueueSource is one collection feeding the moderation queue: the
+// filter selecting its pending items and how they map onto a queue item
+type moderationQueueSource struct {
+	itemType   models.ModerationQueueItemType
+	collection string
+	match      bson.M
+	project    bson.M
+}
+
+// moderationQueueSources lists the queue sources in a fixed order. Held
+// comments already file an auto-detected report; the report is left out so
+// the comment shows up once, as a restricted comment. The same goes for posts
+// and comments hidden by the link blocklist, which show up as hidden content.
+func moderationQueueSources() []moderationQueueSource {
+	return []moderationQueueSource{
+		{
+			itemType:   models.QueueItemReport,
+			collection: "reports",
+			match: repository.NotDeleted(bson.M{
+				"status":   bson.M{"$in": []models.ReportStatus{models.ReportPending, models.ReportReviewing}},
+				"category": bson.M{"$nin": []string{"comment_hold", models.LinkBlockReportCategory}},
+			}),
+			project: bson.M{
+				"target_type": "$target_type",
+				"target_id":   "$target_id",
+				"priority":    bson.M{"$ifNull": []interface{}{"$priority", "medium"}},
+				"reason":      "$reason",
+				"preview":     "$description",
+				"queued_at":   "$created_at",
+			},
+		},
+		{
+			itemType:   models.QueueItemFlaggedMedia,
+			collection: "media",
+			match:      repository.NotDeleted(bson.M{"moderation_status": "flagged"}),
+			project: bson.M{
+				"target_type": bson.M{"$literal": "media"},
+				"target_id":   "$_id",
+				"priority":    bson.M{"$literal": "high"},
+				"reason":      "$moderation_reason",
+				"preview":     "$original_name",
+				"queued_at":   "$updated_at",
+			},
+		},
+		{
+			itemType:   models.QueueItemHiddenPost,
+			collection: "posts",
+			match: repository.NotDeleted(bson.M{
+				"is_hidden": true,
+				"hidden_by": bson.M{"$exists": false},
+			}),
+			project: bson.M{
+				"target_type": bson.M{"$literal": "post"},
+				"target_id":   "$_id",
+				"priority":    bson.M{"$literal": "medium"},
+				"reason":      bson.M{"$literal": "auto_hidden"},
+				"preview":     "$content",
+				"queued_at":   "$updated_at",
+			},
+		},
+		{
+			itemType:   models.QueueItemHiddenComment,
+			collection: "comments",
+			match: repository.NotDeleted(bson.M{
+				"is_hidden": true,
+				"hidden_by": bson.M{"$exists": false},
+			}),
+			project: bson.M{
+				"target_type": bson.M{"$literal": "comment"},
+				"target_id":   "$_id",
+				"priority":    bson.M{"$literal": "medium"},
+				"reason":      bson.M{"$literal": "auto_hidden"},
+				"preview":     "$content",
+				"queued_at":   "$updated_at",
+			},
+		},
+		{
+			itemType:   models.QueueItemRestrictedComment,
+			collection: "comments",
+			match:      repository.NotDeleted(bson.M{"hold_status": models.CommentHoldHeld}),
+			project: bson.M{
+				"target_type": bson.M{"$literal": "comment"},
+				"target_id":   "$_id",
+				"priority":    bson.M{"$literal": "low"},
+				"reason":      bson.M{"$literal": "comment_hold"},
+				"preview":     "$content",
+				"queued_at":   "$held_at",
+			},
+		},
+	}
+}
+
+// stages returns the pipeline stages that turn the source's pending
+// documents into queue items
+func (src moderationQueueSource) stages() []bson.M {
+	project := bson.M{
+		"_id":             0,
+		"queue_item_type": bson.M{"$literal": src.itemType},
+		"item_id":         "$_id",
+	}
+	for key, value := range src.project {
+		project[key] = value
+	}
+	return []bson.M{
+		{"$match": src.match},
+		{"$project": project},
+	}
+}
+
+// findModerationQueueSource returns the source of a queue item type
+func findModerationQueueSource(itemType models.ModerationQueueItemType) (moderationQueueSource, bool) {
+	for _, src := range moderationQueueSources() {
+		if src.itemType == itemType {
+			return src, true
+		}
+	}
+	return moderationQueueSource{}, false
+}
+
+// GetQueue merges every pending moderation item into one list, highest
+// priority first and oldest first within a priority. Live claims are
+// attached so moderators can see who is already working on an item.
+func (mqs *ModerationQueueService) GetQueue(moderatorID primitive.ObjectID, filter models.ModerationQueueFilter, limit, skip int) ([]models.ModerationQueueItem, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	sources := moderationQueueSources()
+	if filter.QueueItemType != "" {
+		src, ok := findModerationQueueSource(filter.QueueItemType)
+		if !ok {
+			return nil, 0, errors.New("invalid queue item type")
+		}
+		sources = []moderationQueueSource{src}
+	}
+
+	pipeline := sources[0].stages()
+	for _, src := range sources[1:] {
+		pipeline = append(pipeline, bson.M{
+			"$unionWith": bson.M{
+				"coll":     src.collection,
+				"pipeline": src.stages(),
+			},
+		})
+	}
+
+	now := time.Now()
+	pipeline = append(pipeline,
+		bson.M{
+			"$lookup": bson.M{
+				"from": "moderation_claims",
+				"let":  bson.M{"itemType": "$queue_item_type", "itemId": "$item_id"},
+				"pipeline": []bson.M{
+					{
+						"$match": bson.M{
+							"$expr": bson.M{"$and": []bson.M{
+								{"$eq": []interface{}{"$queue_item_type", "$$itemType"}},
+								{"$eq": []interface{}{"$item_id", "$$itemId"}},
+								{"$gt": []interface{}{"$expires_at", now}},
+							}},
+						},
+					},
+				},
+				"as": "claim",
+			},
+		},
+		bson.M{
+			"$addFields": bson.M{
+				"claimed_by":       bson.M{"$arrayElemAt": []interface{}{"$claim.moderator_id", 0}},
+				"claim_expires_at": bson.M{"$arrayElemAt": []interface{}{"$claim.expires_at", 0}},
+				"priority_rank":    bson.M{"$indexOfArray": []interface{}{models.ModerationPriorities, "$priority"}},
+			},
+		},
+	)
+
+	if filter.Unclaimed {
+		pipeline = append(pipeline, bson.M{
+			"$match": bson.M{"$or": []bson.M{
+				{"claimed_by": bson.M{"$exists": false}},
+				{"claimed_by": moderatorID},
+			}},
+		})
+	}
+
+	pipeline = append(pipeline,
+		bson.M{"$sort": bson.D{
+			{Key: "priority_rank", Value: -1},
+			{Key: "queued_at", Value: 1},
+			{Key: "item_id", Value: 1},
+		}},
+		bson.M{
+			"$facet": bson.M{
+				"items": []bson.M{{"$skip": skip}, {"$limit": limit}},
+				"total": []bson.M{{"$count": "count"}},
+			},
+		},
+	)
+
+	cursor, err := mqs.db.Collection(sources[0].collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []struct {
+		Items []models.ModerationQueueItem `bson:"items"`
+		Total []struct {
+			Count int64 `bson:"count"`
+		} `bson:"total"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, 0, err
+	}
+
+	items := []models.ModerationQueueItem{}
+	var total int64
+	if len(result) > 0 {
+		items = append(items, result[0].Items...)
+		if len(result[0].Total) > 0 {
+			total = result[0].Total[0].Count
+		}
+	}
+
+	return items, total, nil
+}
+
+// ClaimItem assigns a queue item to the moderator so two moderators don't
+// work the same thing. Claiming an item the moderator already holds renews
+// the claim; a live claim of someone else can't be taken over. Claiming a
+// report also assigns the report to the moderator.
+func (mqs *ModerationQueueService) ClaimItem(moderatorID primitive.ObjectID, itemType models.ModerationQueueItemType, itemID primitive.ObjectID) (*models.ModerationClaim, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	src, ok := findModerationQueueSource(itemType)
+	if !ok {
+		return nil, errors.New("invalid queue item type")
+	}
+
+	// Only items still waiting in the queue can be claimed
+	pending, err := mqs.db.Collection(src.collection).CountDocuments(ctx, repository.NotDeleted(src.match, bson.M{"_id": itemID}))
+	if err != nil {
+		return nil, err
+	}
+	if pending == 0 {
+		return nil, errors.New("queue item not found")
+	}
+
+	now := time.Now()
+	claim := &models.ModerationClaim{
+		QueueItemType: itemType,
+		ItemID:        itemID,
+		ModeratorID:   moderatorID,
+		ClaimedAt:     now,
+		ExpiresAt:     now.Add(models.ModerationClaimTTL),
+	}
+
+	// A live claim of another moderator matches neither branch, so the upsert
+	// tries to insert a second claim and hits the unique index
+	err = mqs.claimCollection.FindOneAndUpdate(ctx, bson.M{
+		"queue_item_type": itemType,
+		"item_id":         itemID,
+		"$or": []bson.M{
+			{"moderator_id": moderatorID},
+			{"expires_at": bson.M{"$lte": now}},
+		},
+	}, bson.M{
+		"$set": bson.M{
+			"moderator_id": moderatorID,
+			"claimed_at":   now,
+			"expires_at":   claim.ExpiresAt,
+		},
+	}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(claim)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, errors.New("item already claimed by another moderator")
+		}
+		return nil, err
+	}
+
+	if itemType == models.QueueItemReport {
+		_, err = mqs.db.Collection("reports").UpdateOne(ctx, bson.M{"_id": itemID}, bson.M{
+			"$set": bson.M{
+				"assigned_to": moderatorID,
+				"status":      models.ReportReviewing,
+				"updated_at":  now,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return claim, nil
+}
+
+// ReleaseItem gives up the moderator's claim on a queue item
+func (mqs *ModerationQueueService) ReleaseItem(moderatorID primitive.ObjectID, itemType models.ModerationQueueItemType, itemID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := mqs.claimCollection.DeleteOne(ctx, bson.M{
+		"queue_item_type": itemType,
+		"item_id":         itemID,
+		"moderator_id":    moderatorID,
+		"expires_at":      bson.M{"$gt": time.Now()},
+	})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return errors.New("claim not found")
+	}
+
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"social-media-api/internal/config"
 	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -59,6 +60,13 @@ func (ns *NotificationService) CreateNotification(req models.CreateNotificationR
 		}
 	}
 
+	var groupID *primitive.ObjectID
+	if req.GroupID != "" {
+		if gID, err := primitive.ObjectIDFromHex(req.GroupID); err == nil {
+			groupID = &gID
+		}
+	}
+
 	// Create notification
 	notification := &models.Notification{
 		RecipientID: recipientID,
@@ -70,6 +78,8 @@ func (ns *NotificationService) CreateNotification(req models.CreateNotificationR
 		TargetID:    targetID,
 		TargetType:  req.TargetType,
 		TargetURL:   req.TargetURL,
+		GroupID:     groupID,
+		GroupKey:    req.GroupKey,
 		Metadata:    req.Metadata,
 		Priority:    req.Priority,
 		ScheduledAt: req.ScheduledAt,
@@ -78,6 +88,19 @@ func (ns *NotificationService) CreateNotification(req models.CreateNotificationR
 
 	notification.BeforeCreate()
 
+	// Drop notifications from users the recipient has muted, but answer as
+	// usual so the mute stays invisible to the actor
+	if req.Type != models.NotificationMention && getActiveMute(ctx, ns.db, recipientID, actorID) != nil {
+		notification.ID = primitive.NewObjectID()
+		return notification, nil
+	}
+
+	// Same for group activity the recipient's group notification level filters out
+	if groupID != nil && len(ns.filterGroupRecipients(ctx, *groupID, actorID, req.Type, []primitive.ObjectID{recipientID})) == 0 {
+		notification.ID = primitive.NewObjectID()
+		return notification, nil
+	}
+
 	// Insert notification
 	result, err := ns.collection.InsertOne(ctx, notification)
 	if err != nil {
@@ -116,12 +139,38 @@ func (ns *NotificationService) CreateBulkNotifications(req models.BulkCreateNoti
 		}
 	}
 
-	var notifications []interface{}
+	var recipientIDs []primitive.ObjectID
 	for _, recipientIDStr := range req.RecipientIDs {
 		recipientID, err := primitive.ObjectIDFromHex(recipientIDStr)
 		if err != nil {
 			continue // Skip invalid IDs
 		}
+		recipientIDs = append(recipientIDs, recipientID)
+	}
+
+	if len(recipientIDs) == 0 {
+		return errors.New("no valid recipient IDs")
+	}
+
+	var groupID *primitive.ObjectID
+	if req.GroupID != "" {
+		if gID, err := primitive.ObjectIDFromHex(req.GroupID); err == nil {
+			groupID = &gID
+			recipientIDs = ns.filterGroupRecipients(ctx, gID, actorID, req.Type, recipientIDs)
+		}
+	}
+
+	// Skip recipients who have muted the actor
+	var muters map[primitive.ObjectID]bool
+	if req.Type != models.NotificationMention {
+		muters = getMutersOf(ctx, ns.db, actorID, recipientIDs)
+	}
+
+	var notifications []interface{}
+	for _, recipientID := range recipientIDs {
+		if muters[recipientID] {
+			continue
+		}
 
 		notification := &models.Notification{
 			RecipientID: recipientID,
@@ -133,6 +182,7 @@ func (ns *NotificationService) CreateBulkNotifications(req models.BulkCreateNoti
 			TargetID:    targetID,
 			TargetType:  req.TargetType,
 			TargetURL:   req.TargetURL,
+			GroupID:     groupID,
 			Metadata:    req.Metadata,
 			Priority:    req.Priority,
 			ScheduledAt: req.ScheduledAt,
@@ -143,7 +193,7 @@ func (ns *NotificationService) CreateBulkNotifications(req models.BulkCreateNoti
 	}
 
 	if len(notifications) == 0 {
-		return errors.New("no valid recipient IDs")
+		return nil
 	}
 
 	// Insert all notifications
@@ -158,6 +208,46 @@ func (ns *NotificationService) CreateBulkNotifications(req models.BulkCreateNoti
 	return nil
 }
 
+// GetNotificationsVersion returns when the user's notification list last
+// changed and how many entries it holds, for conditional list requests. The
+// count catches deletions, which leave no newer timestamp behind.
+func (ns *NotificationService) GetNotificationsVersion(userID primitive.ObjectID, unreadOnly bool) (time.Time, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"recipient_id": userID,
+		"$or": []bson.M{
+			{"expires_at": bson.M{"$exists": false}},
+			{"expires_at": bson.M{"$gt": time.Now()}},
+		},
+	}
+
+	if unreadOnly {
+		filter["is_read"] = false
+	}
+
+	count, err := ns.collection.CountDocuments(ctx, filter)
+	if err != nil || count == 0 {
+		return time.Time{}, count, err
+	}
+
+	var newest models.Notification
+	opts := options.FindOne().
+		SetSort(bson.M{"updated_at": -1}).
+		SetProjection(bson.M{"updated_at": 1, "created_at": 1})
+	if err := ns.collection.FindOne(ctx, filter, opts).Decode(&newest); err != nil {
+		return time.Time{}, count, err
+	}
+
+	lastModified := newest.UpdatedAt
+	if newest.CreatedAt.After(lastModified) {
+		lastModified = newest.CreatedAt
+	}
+
+	return lastModified, count, nil
+}
+
 // GetUserNotifications retrieves notifications for a user
 func (ns *NotificationService) GetUserNotifications(userID primitive.ObjectID, limit, skip int, unreadOnly bool) ([]models.NotificationResponse, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -449,6 +539,16 @@ func (ns *NotificationService) NotifyComment(actorID, recipientID, postID, comme
 		return nil
 	}
 
+	// Held comments notify the post author once they're approved
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if held, _ := ns.db.Collection("comments").CountDocuments(ctx, bson.M{
+		"_id":         commentID,
+		"hold_status": models.CommentHoldHeld,
+	}); held > 0 {
+		return nil
+	}
+
 	req := models.CreateNotificationRequest{
 		RecipientID: recipientID.Hex(),
 		ActorID:     actorID.Hex(),
@@ -459,6 +559,7 @@ func (ns *NotificationService) NotifyComment(actorID, recipientID, postID, comme
 		TargetID:    commentID.Hex(),
 		TargetType:  "comment",
 		TargetURL:   "/posts/" + postID.Hex() + "#comment-" + commentID.Hex(),
+		GroupID:     ns.postGroupID(ctx, postID),
 		Priority:    "medium",
 		SendViaPush: true,
 	}
@@ -467,6 +568,68 @@ func (ns *NotificationService) NotifyComment(actorID, recipientID, postID, comme
 	return err
 }
 
+// NotifyQuickReply notifies a post author about quick replies. Replies on the
+// same post share one notification until it is read, so a burst of "🔥" shows
+// as a single entry with a running count.
+func (ns *NotificationService) NotifyQuickReply(actorID, recipientID, postID primitive.ObjectID, content string) error {
+	if actorID == recipientID {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if getActiveMute(ctx, ns.db, recipientID, actorID) != nil {
+		return nil
+	}
+
+	groupKey := string(models.NotificationQuickReply) + "_" + postID.Hex()
+
+	// Fold into the unread notification for this post if there is one
+	var grouped models.Notification
+	err := ns.collection.FindOneAndUpdate(ctx, repository.NotDeleted(bson.M{
+		"recipient_id": recipientID,
+		"group_key":    groupKey,
+		"is_read":      false,
+	}), bson.M{
+		"$inc": bson.M{"group_count": 1},
+		"$set": bson.M{
+			"actor_id":              actorID,
+			"is_grouped":            true,
+			"metadata.latest_emoji": content,
+			"updated_at":            time.Now(),
+		},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&grouped)
+	if err == nil {
+		_, err = ns.collection.UpdateOne(ctx, bson.M{"_id": grouped.ID}, bson.M{
+			"$set": bson.M{"message": fmt.Sprintf("Your post got %d quick reactions", grouped.GroupCount)},
+		})
+		return err
+	}
+	if err != mongo.ErrNoDocuments {
+		return err
+	}
+
+	req := models.CreateNotificationRequest{
+		RecipientID: recipientID.Hex(),
+		ActorID:     actorID.Hex(),
+		Type:        models.NotificationQuickReply,
+		Title:       "New Reaction",
+		Message:     "Someone reacted " + content + " to your post",
+		ActionText:  "View Post",
+		TargetID:    postID.Hex(),
+		TargetType:  "post",
+		TargetURL:   "/posts/" + postID.Hex(),
+		GroupID:     ns.postGroupID(ctx, postID),
+		GroupKey:    groupKey,
+		Metadata:    map[string]interface{}{"latest_emoji": content},
+		Priority:    "low",
+	}
+
+	_, err = ns.CreateNotification(req)
+	return err
+}
+
 // NotifyFollow creates a follow notification
 func (ns *NotificationService) NotifyFollow(actorID, recipientID primitive.ObjectID) error {
 	if actorID == recipientID {
@@ -491,6 +654,55 @@ func (ns *NotificationService) NotifyFollow(actorID, recipientID primitive.Objec
 	return err
 }
 
+// NotifyFollowMany notifies the accounts a user just followed, and those whose
+// approval they requested, with one batch per kind
+func (ns *NotificationService) NotifyFollowMany(actorID primitive.ObjectID, followedIDs, requestedIDs []primitive.ObjectID) error {
+	batches := []struct {
+		recipients []primitive.ObjectID
+		title      string
+		message    string
+		actionText string
+	}{
+		{followedIDs, "New Follower", "Someone started following you", "View Profile"},
+		{requestedIDs, "Follow Request", "Someone requested to follow you", "View Request"},
+	}
+
+	for _, batch := range batches {
+		if len(batch.recipients) == 0 {
+			continue
+		}
+
+		recipients := make([]string, 0, len(batch.recipients))
+		for _, recipientID := range batch.recipients {
+			if recipientID != actorID {
+				recipients = append(recipients, recipientID.Hex())
+			}
+		}
+		if len(recipients) == 0 {
+			continue
+		}
+
+		err := ns.CreateBulkNotifications(models.BulkCreateNotificationRequest{
+			RecipientIDs: recipients,
+			ActorID:      actorID.Hex(),
+			Type:         models.NotificationFollow,
+			Title:        batch.title,
+			Message:      batch.message,
+			ActionText:   batch.actionText,
+			TargetID:     actorID.Hex(),
+			TargetType:   "user",
+			TargetURL:    "/users/" + actorID.Hex(),
+			Priority:     "medium",
+			SendViaPush:  true,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Add these methods to the existing NotificationService in internal/services/notification_service.go
 // Also add "fmt" import if not already present
 
@@ -519,6 +731,28 @@ func (ns *NotificationService) NotifyGroupInvite(actorID, recipientID, groupID p
 	return err
 }
 
+// NotifyDelegateInvite tells a user they were invited to help manage an account
+func (ns *NotificationService) NotifyDelegateInvite(actorID, recipientID, delegationID primitive.ObjectID, role models.DelegateRole) error {
+	req := models.CreateNotificationRequest{
+		RecipientID:  recipientID.Hex(),
+		ActorID:      actorID.Hex(),
+		Type:         models.NotificationDelegate,
+		Title:        "Account Access Invitation",
+		Message:      fmt.Sprintf("You've been invited to help manage an account as %s", role),
+		ActionText:   "View Invitation",
+		TargetID:     delegationID.Hex(),
+		TargetType:   "delegation",
+		TargetURL:    "/settings/delegations/" + delegationID.Hex(),
+		Metadata:     map[string]interface{}{"role": string(role)},
+		Priority:     "high",
+		SendViaPush:  true,
+		SendViaEmail: true,
+	}
+
+	_, err := ns.CreateNotification(req)
+	return err
+}
+
 // NotifyGroupJoinRequest creates a join request notification for group admins
 func (ns *NotificationService) NotifyGroupJoinRequest(actorID, recipientID, groupID primitive.ObjectID) error {
 	if actorID == recipientID {
@@ -614,6 +848,7 @@ func (ns *NotificationService) NotifyGroupPostCreated(actorID, groupID, postID p
 		TargetID:     postID.Hex(),
 		TargetType:   "post",
 		TargetURL:    "/posts/" + postID.Hex(),
+		GroupID:      groupID.Hex(),
 		Priority:     "low",
 		SendViaPush:  true,
 	}
@@ -621,12 +856,51 @@ func (ns *NotificationService) NotifyGroupPostCreated(actorID, groupID, postID p
 	return ns.CreateBulkNotifications(req)
 }
 
+// NotifyGroupPostReviewed tells an author whether moderators approved their group post
+func (ns *NotificationService) NotifyGroupPostReviewed(actorID, recipientID, groupID, postID primitive.ObjectID, approved bool, reason string) error {
+	title := "Post Approved"
+	message := "Your post in the group has been approved"
+	if !approved {
+		title = "Post Declined"
+		message = "Your post in the group was not approved"
+		if reason != "" {
+			message = fmt.Sprintf("Your post in the group was not approved. Reason: %s", reason)
+		}
+	}
+
+	req := models.CreateNotificationRequest{
+		RecipientID: recipientID.Hex(),
+		ActorID:     actorID.Hex(),
+		Type:        models.NotificationGroupPost,
+		Title:       title,
+		Message:     message,
+		ActionText:  "View Post",
+		TargetID:    postID.Hex(),
+		TargetType:  "post",
+		TargetURL:   "/posts/" + postID.Hex(),
+		Metadata:    map[string]interface{}{"group_id": groupID.Hex(), "approved": approved},
+		Priority:    "medium",
+		SendViaPush: true,
+	}
+
+	_, err := ns.CreateNotification(req)
+	return err
+}
+
 // NotifyMention creates a mention notification
 func (ns *NotificationService) NotifyMention(actorID, recipientID, contentID primitive.ObjectID, contentType string) error {
 	if actorID == recipientID {
 		return nil
 	}
 
+	// Mentions in group posts still reach members unless they muted the group
+	var groupID string
+	if contentType == "post" {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		groupID = ns.postGroupID(ctx, contentID)
+	}
+
 	req := models.CreateNotificationRequest{
 		RecipientID:  recipientID.Hex(),
 		ActorID:      actorID.Hex(),
@@ -637,6 +911,7 @@ func (ns *NotificationService) NotifyMention(actorID, recipientID, contentID pri
 		TargetID:     contentID.Hex(),
 		TargetType:   contentType,
 		TargetURL:    "/" + contentType + "s/" + contentID.Hex(),
+		GroupID:      groupID,
 		Priority:     "high",
 		SendViaPush:  true,
 		SendViaEmail: true,
@@ -646,6 +921,40 @@ func (ns *NotificationService) NotifyMention(actorID, recipientID, contentID pri
 	return err
 }
 
+// NotifyPhotoTag tells a user they were tagged on a post's media. pending
+// means the post waits for them to accept it onto their tagged tab.
+func (ns *NotificationService) NotifyPhotoTag(actorID, recipientID, postID primitive.ObjectID, pending bool) error {
+	if actorID == recipientID {
+		return nil
+	}
+
+	message := "Someone tagged you in a photo"
+	actionText := "View Post"
+	if pending {
+		message = "Someone tagged you in a photo. Review the tag to show it on your profile"
+		actionText = "Review Tag"
+	}
+
+	req := models.CreateNotificationRequest{
+		RecipientID:  recipientID.Hex(),
+		ActorID:      actorID.Hex(),
+		Type:         models.NotificationPhotoTag,
+		Title:        "You were tagged",
+		Message:      message,
+		ActionText:   actionText,
+		TargetID:     postID.Hex(),
+		TargetType:   "post",
+		TargetURL:    "/posts/" + postID.Hex(),
+		Metadata:     map[string]interface{}{"pending": pending},
+		Priority:     "medium",
+		SendViaPush:  true,
+		SendViaEmail: false,
+	}
+
+	_, err := ns.CreateNotification(req)
+	return err
+}
+
 // NotifyUserSuspension creates a user suspension notification
 func (ns *NotificationService) NotifyUserSuspension(userID primitive.ObjectID, reason, duration string) error {
 	message := "Your account has been suspended"
@@ -690,6 +999,90 @@ func (ns *NotificationService) NotifyUserSuspension(userID primitive.ObjectID, r
 	return err
 }
 
+// NotifyModerationAction tells a user that a moderator acted on a report
+// against them or their content
+func (ns *NotificationService) NotifyModerationAction(userID primitive.ObjectID, action models.ReportResolutionAction, targetType string, targetID primitive.ObjectID, reason string) error {
+	var title, message string
+	switch action {
+	case models.ResolutionActionHide:
+		title = "Content Hidden"
+		message = fmt.Sprintf("Your %s has been hidden for violating our community guidelines", targetType)
+	case models.ResolutionActionDelete:
+		title = "Content Removed"
+		message = fmt.Sprintf("Your %s has been removed for violating our community guidelines", targetType)
+	case models.ResolutionActionWarnUser:
+		title = "Community Guidelines Warning"
+		message = "You have received a warning for violating our community guidelines"
+	default:
+		return nil
+	}
+	if reason != "" {
+		message = fmt.Sprintf("%s. Reason: %s", message, reason)
+	}
+
+	// Create system admin ID for system notifications
+	systemAdminID := primitive.NewObjectID()
+
+	req := models.CreateNotificationRequest{
+		RecipientID: userID.Hex(),
+		ActorID:     systemAdminID.Hex(),
+		Type:        models.NotificationMessage, // Using existing notification type
+		Title:       title,
+		Message:     message,
+		ActionText:  "Appeal",
+		TargetType:  "system",
+		TargetURL:   "/support/appeal",
+		Priority:    "high",
+		SendViaPush: true,
+		Metadata: map[string]interface{}{
+			"moderation_action": string(action),
+			"content_type":      targetType,
+			"content_id":        targetID.Hex(),
+			"reason":            reason,
+			"notification_type": "moderation_action",
+			"is_system_message": true,
+		},
+	}
+
+	_, err := ns.CreateNotification(req)
+	return err
+}
+
+// NotifyUserWarning tells a user they received a warning and how many active
+// strikes they now have
+func (ns *NotificationService) NotifyUserWarning(userID, warningID primitive.ObjectID, reason string, strikeCount int, expiresAt *time.Time) error {
+	message := fmt.Sprintf("You have received a warning for violating our community guidelines. Reason: %s. You now have %d active strike(s).", reason, strikeCount)
+	if expiresAt != nil {
+		message = fmt.Sprintf("%s This strike expires on %s.", message, expiresAt.Format("January 2, 2006"))
+	}
+
+	systemAdminID := primitive.NewObjectID()
+
+	req := models.CreateNotificationRequest{
+		RecipientID:  userID.Hex(),
+		ActorID:      systemAdminID.Hex(),
+		Type:         models.NotificationMessage,
+		Title:        "Community Guidelines Warning",
+		Message:      message,
+		ActionText:   "View Warnings",
+		TargetType:   "system",
+		TargetURL:    "/settings/warnings",
+		Priority:     "high",
+		SendViaEmail: true,
+		SendViaPush:  true,
+		Metadata: map[string]interface{}{
+			"warning_id":        warningID.Hex(),
+			"reason":            reason,
+			"strike_count":      strikeCount,
+			"notification_type": "user_warning",
+			"is_system_message": true,
+		},
+	}
+
+	_, err := ns.CreateNotification(req)
+	return err
+}
+
 // NotifyUserUnsuspension creates a user unsuspension notification
 func (ns *NotificationService) NotifyUserUnsuspension(userID primitive.ObjectID, note string) error {
 	message := "Your account has been reactivated. You can now use all platform features."
@@ -746,6 +1139,171 @@ func (ns *NotificationService) NotifyMessage(actorID, recipientID, conversationI
 	return err
 }
 
+// NotifySavedSearchMatches tells a user new posts match one of their saved
+// searches. postID is the newest match, opened when there is only one.
+func (ns *NotificationService) NotifySavedSearchMatches(userID, searchID primitive.ObjectID, name string, matches int, postID string) error {
+	systemAdminID := primitive.NewObjectID()
+
+	message := fmt.Sprintf("%d new posts match your saved search \"%s\"", matches, name)
+	if matches == 1 {
+		message = fmt.Sprintf("A new post matches your saved search \"%s\"", name)
+	}
+
+	req := models.CreateNotificationRequest{
+		RecipientID: userID.Hex(),
+		ActorID:     systemAdminID.Hex(),
+		Type:        models.NotificationSavedSearch,
+		Title:       "New Search Results",
+		Message:     message,
+		TargetID:    searchID.Hex(),
+		TargetType:  "saved_search",
+		SendViaPush: true,
+		Metadata: map[string]interface{}{
+			"is_system_message": true,
+			"match_count":       matches,
+			"post_id":           postID,
+		},
+	}
+
+	_, err := ns.CreateNotification(req)
+	return err
+}
+
+// NotifySupportAccess tells a user a support admin has started a session
+// signed in as them
+func (ns *NotificationService) NotifySupportAccess(userID, sessionID primitive.ObjectID) error {
+	systemAdminID := primitive.NewObjectID()
+
+	req := models.CreateNotificationRequest{
+		RecipientID: userID.Hex(),
+		ActorID:     systemAdminID.Hex(),
+		Type:        models.NotificationSupportAccess,
+		Title:       "Support Access",
+		Message:     "Our support team signed in to your account to look into an issue. Their actions are logged.",
+		TargetID:    sessionID.Hex(),
+		TargetType:  "impersonation_session",
+		Priority:    "high",
+		SendViaPush: true,
+		Metadata: map[string]interface{}{
+			"is_system_message": true,
+		},
+	}
+
+	_, err := ns.CreateNotification(req)
+	return err
+}
+
+// NotifyMediaQuarantined tells a user a file they uploaded was blocked
+// because the malware scan flagged it
+func (ns *NotificationService) NotifyMediaQuarantined(uploaderID, mediaID primitive.ObjectID, fileName string) error {
+	systemAdminID := primitive.NewObjectID()
+
+	req := models.CreateNotificationRequest{
+		RecipientID: uploaderID.Hex(),
+		ActorID:     systemAdminID.Hex(),
+		Type:        models.NotificationMediaRejected,
+		Title:       "Upload Blocked",
+		Message:     fmt.Sprintf("%s was blocked because it may contain malware", fileName),
+		TargetID:    mediaID.Hex(),
+		TargetType:  "media",
+		Priority:    "high",
+		SendViaPush: true,
+		Metadata: map[string]interface{}{
+			"is_system_message": true,
+		},
+	}
+
+	_, err := ns.CreateNotification(req)
+	return err
+}
+
+// NotifyStoryScreenshot tells an author who disallowed screenshots that a
+// viewer took one of their story
+func (ns *NotificationService) NotifyStoryScreenshot(actorID, authorID, storyID primitive.ObjectID) error {
+	if actorID == authorID {
+		return nil
+	}
+
+	req := models.CreateNotificationRequest{
+		RecipientID: authorID.Hex(),
+		ActorID:     actorID.Hex(),
+		Type:        models.NotificationStoryScreenshot,
+		Title:       "Story Screenshot",
+		Message:     "Someone took a screenshot of your story",
+		ActionText:  "View Story",
+		TargetID:    storyID.Hex(),
+		TargetType:  "story",
+		TargetURL:   "/stories/" + storyID.Hex(),
+		Priority:    "medium",
+		SendViaPush: true,
+	}
+
+	_, err := ns.CreateNotification(req)
+	return err
+}
+
+// NotifyMessageRequest tells a user someone they haven't accepted messages
+// from wants to message them
+func (ns *NotificationService) NotifyMessageRequest(actorID, recipientID, conversationID primitive.ObjectID) error {
+	if actorID == recipientID {
+		return nil
+	}
+
+	req := models.CreateNotificationRequest{
+		RecipientID: recipientID.Hex(),
+		ActorID:     actorID.Hex(),
+		Type:        models.NotificationMessage,
+		Title:       "New Message Request",
+		Message:     "Someone wants to send you a message",
+		ActionText:  "View Request",
+		TargetID:    conversationID.Hex(),
+		TargetType:  "conversation",
+		TargetURL:   "/messages/requests",
+		Metadata:    map[string]interface{}{"message_request": true},
+		Priority:    "medium",
+		SendViaPush: true,
+	}
+
+	_, err := ns.CreateNotification(req)
+	return err
+}
+
+// NotifySurvey notifies survey recipients that a new survey is waiting for them
+func (ns *NotificationService) NotifySurvey(actorID, surveyID primitive.ObjectID, title string, recipientIDs []primitive.ObjectID) error {
+	const batchSize = 1000
+
+	for start := 0; start < len(recipientIDs); start += batchSize {
+		end := start + batchSize
+		if end > len(recipientIDs) {
+			end = len(recipientIDs)
+		}
+
+		recipients := make([]string, 0, end-start)
+		for _, recipientID := range recipientIDs[start:end] {
+			recipients = append(recipients, recipientID.Hex())
+		}
+
+		err := ns.CreateBulkNotifications(models.BulkCreateNotificationRequest{
+			RecipientIDs: recipients,
+			ActorID:      actorID.Hex(),
+			Type:         models.NotificationSurvey,
+			Title:        "We'd love your feedback",
+			Message:      title,
+			ActionText:   "Take Survey",
+			TargetID:     surveyID.Hex(),
+			TargetType:   "survey",
+			TargetURL:    "/surveys/" + surveyID.Hex(),
+			Priority:     "low",
+			SendViaPush:  true,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // CleanupExpiredNotifications removes expired notifications
 func (ns *NotificationService) CleanupExpiredNotifications() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -761,11 +1319,80 @@ func (ns *NotificationService) CleanupExpiredNotifications() error {
 
 // Helper methods
 
+// filterGroupRecipients keeps the recipients whose notification level for the
+// group lets this notification through. Posts and events from the group's
+// owner, admins and moderators count as highlights. Recipients who are not
+// members, such as a post author who has since left, are kept.
+func (ns *NotificationService) filterGroupRecipients(ctx context.Context, groupID, actorID primitive.ObjectID, notificationType models.NotificationType, recipientIDs []primitive.ObjectID) []primitive.ObjectID {
+	userIDs := append([]primitive.ObjectID{actorID}, recipientIDs...)
+	cursor, err := ns.db.Collection("group_members").Find(ctx, bson.M{
+		"group_id": groupID,
+		"user_id":  bson.M{"$in": userIDs},
+		"status":   "active",
+	})
+	if err != nil {
+		return recipientIDs
+	}
+	defer cursor.Close(ctx)
+
+	members := make(map[primitive.ObjectID]models.GroupMember)
+	for cursor.Next(ctx) {
+		var member models.GroupMember
+		if cursor.Decode(&member) == nil {
+			members[member.UserID] = member
+		}
+	}
+
+	actor := members[actorID]
+	fromStaff := actor.Role == models.GroupRoleOwner || actor.Role == models.GroupRoleAdmin || actor.Role == models.GroupRoleModerator
+
+	allowed := make([]primitive.ObjectID, 0, len(recipientIDs))
+	for _, recipientID := range recipientIDs {
+		member, isMember := members[recipientID]
+		if isMember && !member.EffectiveNotificationLevel().Allows(notificationType, fromStaff) {
+			continue
+		}
+		allowed = append(allowed, recipientID)
+	}
+	return allowed
+}
+
+// postGroupID returns the hex ID of the group a post was made in, or an empty
+// string for posts outside groups
+func (ns *NotificationService) postGroupID(ctx context.Context, postID primitive.ObjectID) string {
+	var post models.Post
+	err := ns.db.Collection("posts").FindOne(ctx, bson.M{"_id": postID},
+		options.FindOne().SetProjection(bson.M{"group_id": 1})).Decode(&post)
+	if err != nil || post.GroupID == nil {
+		return ""
+	}
+	return post.GroupID.Hex()
+}
+
+// isPushSuppressed reports whether the recipient muted the notification's
+// group after it was stored
+func (ns *NotificationService) isPushSuppressed(notificationID primitive.ObjectID) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	count, _ := ns.collection.CountDocuments(ctx, bson.M{
+		"_id":             notificationID,
+		"push_suppressed": true,
+	})
+	return count > 0
+}
+
 func (ns *NotificationService) sendNotificationChannels(notification *models.Notification, prefs models.NotificationPreferences, sendEmail, sendPush, sendSMS bool) {
+	if notification.GroupID != nil && ns.isPushSuppressed(notification.ID) {
+		notification.PushSuppressed = true
+	}
+
 	// Send via email
 	if sendEmail && notification.ShouldSendViaEmail(prefs) && ns.emailService != nil {
-		ns.emailService.SendNotificationEmail(notification)
-		ns.markAsSent(notification.ID, "email")
+		if recipient, err := ns.getUserByID(notification.RecipientID); err == nil {
+			ns.emailService.SendNotificationEmail(recipient, notification)
+			ns.markAsSent(notification.ID, "email")
+		}
 	}
 
 	// Send via push
@@ -836,11 +1463,10 @@ func (ns *NotificationService) getUserByID(userID primitive.ObjectID) (*models.U
 	defer cancel()
 
 	var user models.User
-	err := ns.userCollection.FindOne(ctx, bson.M{
-		"_id":        userID,
-		"is_active":  true,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&user)
+	err := ns.userCollection.FindOne(ctx, repository.NotDeleted(bson.M{
+		"_id":       userID,
+		"is_active": true,
+	})).Decode(&user)
 
 	return &user, err
 }
@@ -0,0 +1,216 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// cascadeFixture is a post with comments, likes, mentions and reports on it
+// and its comments, plus an unrelated post with children of its own that a
+// deletion must not touch. Counters are set to match the children.
+type cascadeFixture struct {
+	author, commenter, liker *models.User
+	post, unrelated          *models.Post
+	comments                 []primitive.ObjectID // Two counted comments and a hidden one
+	dismissedReport          primitive.ObjectID
+}
+
+func newCascadeFixture(h *testutil.Harness) cascadeFixture {
+	f := cascadeFixture{
+		author:    h.CreateUser(),
+		commenter: h.CreateUser(),
+		liker:     h.CreateUser(),
+	}
+	f.post = h.CreatePost(f.author)
+	f.unrelated = h.CreatePost(f.author)
+
+	insert := func(collection string, doc bson.M) primitive.ObjectID {
+		h.T.Helper()
+		now := time.Now()
+		doc["created_at"], doc["updated_at"] = now, now
+		result, err := h.DB.Collection(collection).InsertOne(h.Context(), doc)
+		if err != nil {
+			h.T.Fatalf("inserting into %s: %v", collection, err)
+		}
+		return result.InsertedID.(primitive.ObjectID)
+	}
+
+	for _, post := range []*models.Post{f.post, f.unrelated} {
+		comment := h.CreateComment(f.commenter, post, "Nice").ID
+		if post == f.post {
+			f.comments = append(f.comments, comment, h.CreateComment(f.commenter, post, "Again").ID)
+		}
+
+		insert("likes", bson.M{"user_id": f.liker.ID, "target_type": "post", "target_id": post.ID, "reaction_type": "like"})
+		insert("likes", bson.M{"user_id": f.author.ID, "target_type": "comment", "target_id": comment, "reaction_type": "like"})
+		insert("mentions", bson.M{"mentioner_id": f.author.ID, "mentioned_id": f.liker.ID, "content_type": "post", "content_id": post.ID, "is_active": true})
+		insert("mentions", bson.M{"mentioner_id": f.commenter.ID, "mentioned_id": f.liker.ID, "content_type": "comment", "content_id": comment, "is_active": true})
+		insert("reports", bson.M{"reporter_id": f.liker.ID, "target_type": "post", "target_id": post.ID, "status": models.ReportPending})
+		insert("reports", bson.M{"reporter_id": f.liker.ID, "target_type": "comment", "target_id": comment, "status": models.ReportReviewing})
+	}
+
+	// A hidden comment was never counted towards its author
+	hidden := h.CreateComment(f.commenter, f.post, "Hidden")
+	if _, err := h.DB.Collection("comments").UpdateOne(h.Context(), bson.M{"_id": hidden.ID}, bson.M{"$set": bson.M{"is_hidden": true}}); err != nil {
+		h.T.Fatalf("hiding comment: %v", err)
+	}
+	f.comments = append(f.comments, hidden.ID)
+
+	// A report that was already closed keeps its outcome
+	f.dismissedReport = insert("reports", bson.M{"reporter_id": f.liker.ID, "target_type": "post", "target_id": f.post.ID, "status": models.ReportRejected})
+
+	counters := []struct {
+		user *models.User
+		set  bson.M
+	}{
+		{f.author, bson.M{"posts_count": 2, "total_likes_received": 2}},
+		{f.commenter, bson.M{"comments_count": 3}}, // The post's two counted comments and the unrelated one
+	}
+	for _, counter := range counters {
+		if _, err := h.DB.Collection("users").UpdateOne(h.Context(), bson.M{"_id": counter.user.ID}, bson.M{"$set": counter.set}); err != nil {
+			h.T.Fatalf("setting counters: %v", err)
+		}
+	}
+
+	return f
+}
+
+// childrenOf filters the children of content in a collection keyed by a
+// target field pair
+func childrenOf(typeField, idField, contentType string, ids ...primitive.ObjectID) bson.M {
+	return bson.M{typeField: contentType, idField: bson.M{"$in": ids}}
+}
+
+// assertCascaded checks every child of the fixture's post was deleted or
+// closed, the unrelated post's children were left alone, and counters
+// dropped by what was deleted
+func (f cascadeFixture) assertCascaded(t *testing.T, h *testutil.Harness) {
+	t.Helper()
+
+	live := []struct {
+		name       string
+		collection string
+		filter     bson.M
+		want       int64
+	}{
+		{"comments on the post", "comments", repository.NotDeleted(bson.M{"post_id": f.post.ID}), 0},
+		{"likes on the post", "likes", repository.NotDeleted(childrenOf("target_type", "target_id", "post", f.post.ID)), 0},
+		{"likes on its comments", "likes", repository.NotDeleted(childrenOf("target_type", "target_id", "comment", f.comments...)), 0},
+		{"mentions in the post", "mentions", repository.NotDeleted(childrenOf("content_type", "content_id", "post", f.post.ID)), 0},
+		{"mentions in its comments", "mentions", repository.NotDeleted(childrenOf("content_type", "content_id", "comment", f.comments...)), 0},
+		{"active mentions", "mentions", bson.M{"content_id": bson.M{"$in": append(f.comments, f.post.ID)}, "is_active": true}, 0},
+		{"open reports", "reports", bson.M{
+			"target_id": bson.M{"$in": append(f.comments, f.post.ID)},
+			"status":    bson.M{"$in": []models.ReportStatus{models.ReportPending, models.ReportReviewing}},
+		}, 0},
+		{"reports closed by the deletion", "reports", bson.M{
+			"target_id":       bson.M{"$in": append(f.comments, f.post.ID)},
+			"status":          models.ReportResolved,
+			"resolution":      "post_deleted",
+			"content_removed": true,
+		}, 2},
+		{"dismissed report left as it was", "reports", bson.M{"_id": f.dismissedReport, "status": models.ReportRejected}, 1},
+
+		// Soft-deleted children are kept, not removed
+		{"stored comments", "comments", bson.M{"post_id": f.post.ID}, 3},
+		{"stored likes", "likes", bson.M{"target_id": bson.M{"$in": append(f.comments, f.post.ID)}}, 2},
+
+		{"unrelated comments", "comments", repository.NotDeleted(bson.M{"post_id": f.unrelated.ID}), 1},
+		{"unrelated likes", "likes", repository.NotDeleted(childrenOf("target_type", "target_id", "post", f.unrelated.ID)), 1},
+		{"unrelated mentions", "mentions", repository.NotDeleted(bson.M{"content_id": f.unrelated.ID, "is_active": true}), 1},
+		{"unrelated open reports", "reports", bson.M{"target_id": f.unrelated.ID, "status": models.ReportPending}, 1},
+	}
+	for _, check := range live {
+		if got := h.Count(check.collection, check.filter); got != check.want {
+			t.Errorf("%s = %d, want %d", check.name, got, check.want)
+		}
+	}
+
+	// comments_count is kept on the user document without a model field
+	var commenter struct {
+		CommentsCount int64 `bson:"comments_count"`
+	}
+	if err := h.DB.Collection("users").FindOne(h.Context(), bson.M{"_id": f.commenter.ID}).Decode(&commenter); err != nil {
+		t.Fatalf("reloading commenter: %v", err)
+	}
+	if got := commenter.CommentsCount; got != 1 {
+		t.Errorf("commenter comments_count = %d, want 1 after losing the post's two counted comments", got)
+	}
+	if got := h.ReloadUser(f.author.ID).TotalLikesReceived; got != 1 {
+		t.Errorf("author total_likes_received = %d, want 1 after losing the post's like", got)
+	}
+}
+
+func TestDeletePostCascadesToChildren(t *testing.T) {
+	h := testutil.NewHarness(t)
+	posts := newTestPostService(h)
+	f := newCascadeFixture(h)
+
+	if err := posts.DeletePost(f.post.ID, f.commenter.ID); err == nil || err.Error() != "post not found or access denied" {
+		t.Fatalf("DeletePost by another user: err = %v, want post not found or access denied", err)
+	}
+	if got := h.Count("comments", repository.NotDeleted(bson.M{"post_id": f.post.ID})); got != 3 {
+		t.Fatalf("comments after a refused delete = %d, want 3", got)
+	}
+
+	if err := posts.DeletePost(f.post.ID, f.author.ID); err != nil {
+		t.Fatalf("DeletePost: %v", err)
+	}
+	if got := h.Count("posts", repository.NotDeleted(bson.M{"_id": f.post.ID})); got != 0 {
+		t.Errorf("post still live after DeletePost")
+	}
+	f.assertCascaded(t, h)
+
+	h.Eventually(5*time.Second, func() bool {
+		return h.ReloadUser(f.author.ID).PostsCount == 1
+	}, "author posts_count wasn't decremented")
+
+	// Deleting again is refused and doesn't touch the counters twice
+	if err := posts.DeletePost(f.post.ID, f.author.ID); err == nil {
+		t.Error("deleting an already deleted post succeeded")
+	}
+	f.assertCascaded(t, h)
+}
+
+func TestOrphanCheckFinishesSkippedCascade(t *testing.T) {
+	h := testutil.NewHarness(t)
+	posts := newTestPostService(h)
+	f := newCascadeFixture(h)
+
+	// Delete the post without its cascade, as an older code path or a
+	// cascade that failed part way would
+	if _, err := h.DB.Collection("posts").UpdateOne(h.Context(), bson.M{"_id": f.post.ID}, bson.M{"$set": bson.M{"deleted_at": time.Now()}}); err != nil {
+		t.Fatalf("deleting post: %v", err)
+	}
+
+	report, err := posts.RepairOrphanedPostChildren(h.Context(), time.Time{})
+	if err != nil {
+		t.Fatalf("RepairOrphanedPostChildren: %v", err)
+	}
+	if report.PostsChecked != 1 || report.PostsRepaired != 1 {
+		t.Errorf("report = %+v, want one post checked and repaired", report)
+	}
+	want := services.PostCascadeResult{Comments: 3, Likes: 2, Mentions: 2, Reports: 2}
+	if report.Cleaned != want {
+		t.Errorf("cleaned = %+v, want %+v", report.Cleaned, want)
+	}
+	f.assertCascaded(t, h)
+
+	// A second pass finds nothing left and doesn't touch the counters again
+	report, err = posts.RepairOrphanedPostChildren(h.Context(), time.Time{})
+	if err != nil {
+		t.Fatalf("second RepairOrphanedPostChildren: %v", err)
+	}
+	if report.PostsRepaired != 0 {
+		t.Errorf("second pass repaired %d posts, want 0", report.PostsRepaired)
+	}
+	f.assertCascaded(t, h)
+}
@@ -4,11 +4,17 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"social-media-api/internal/config"
 	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+	"social-media-api/internal/translation"
+	"social-media-api/internal/utils"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -17,19 +23,86 @@ import (
 )
 
 type PostService struct {
-	collection     *mongo.Collection
-	userCollection *mongo.Collection
-	likeCollection *mongo.Collection
-	db             *mongo.Database
+	collection            *mongo.Collection
+	userCollection        *mongo.Collection
+	likeCollection        *mongo.Collection
+	fingerprintCollection *mongo.Collection
+	db                    *mongo.Database
+	duplicatePolicy       DuplicateContentPolicy
+	exemptContent         map[string]bool
+	limits                *LimitsService
+	hashtagCategorizer    *models.HashtagCategorizer
+	linkBlocklist         *LinkBlocklistService
+	notificationService   *NotificationService
+	linkPreviews          *LinkPreviewService
+	bulkDeletes           *bulkDeleter
+	stopAutoDelete        context.CancelFunc
+	stopOrphanCheck       context.CancelFunc
 }
 
-func NewPostService() *PostService {
-	return &PostService{
-		collection:     config.DB.Collection("posts"),
-		userCollection: config.DB.Collection("users"),
-		likeCollection: config.DB.Collection("likes"),
-		db:             config.DB,
+const (
+	// AutoDeleteCheckInterval is how often the auto-delete job removes posts
+	// past their authors' auto-delete period
+	AutoDeleteCheckInterval = time.Hour
+
+	// PostOrphanCheckInterval is how often deleted posts are checked for
+	// comments, likes, mentions and reports the deletion missed
+	PostOrphanCheckInterval = 6 * time.Hour
+
+	autoDeleteUserBatch  = 200
+	orphanCheckPostBatch = 200
+)
+
+// DuplicateContentPolicy decides when post content counts as spam. Authors may
+// not repeat content they posted within Window, and content posted by
+// CoordinatedAccounts different accounts within Window is reported for review.
+// Posts shorter than MinLength and the ExemptPhrases are never compared.
+type DuplicateContentPolicy struct {
+	Window              time.Duration // 0 disables duplicate detection
+	MinLength           int
+	ExemptPhrases       []string
+	CoordinatedAccounts int
+}
+
+func NewPostService(db *mongo.Database, duplicatePolicy DuplicateContentPolicy, limits *LimitsService, hashtagCategorizer *models.HashtagCategorizer, linkBlocklist *LinkBlocklistService) *PostService {
+	exemptContent := make(map[string]bool)
+	for _, phrase := range duplicatePolicy.ExemptPhrases {
+		if normalized := models.NormalizeContent(phrase); normalized != "" {
+			exemptContent[normalized] = true
+		}
 	}
+
+	ps := &PostService{
+		collection:            db.Collection("posts"),
+		userCollection:        db.Collection("users"),
+		likeCollection:        db.Collection("likes"),
+		fingerprintCollection: db.Collection("content_fingerprints"),
+		db:                    db,
+		duplicatePolicy:       duplicatePolicy,
+		exemptContent:         exemptContent,
+		limits:                limits,
+		hashtagCategorizer:    hashtagCategorizer,
+		linkBlocklist:         linkBlocklist,
+	}
+	ps.bulkDeletes = newBulkDeleter(db, models.BulkDeletePosts, ps.collection)
+	ps.bulkDeletes.deleteBatch = ps.deletePostBatch
+	return ps
+}
+
+// SetNotificationService sets the service photo tags notify tagged users
+// through; the notification service is built after the post service
+func (ps *PostService) SetNotificationService(notificationService *NotificationService) {
+	ps.notificationService = notificationService
+}
+
+// SetLinkPreviewService sets the service draft previews fetch links through
+func (ps *PostService) SetLinkPreviewService(linkPreviews *LinkPreviewService) {
+	ps.linkPreviews = linkPreviews
+}
+
+// SetBulkDeletePolicy sets the caps on users bulk deleting their posts
+func (ps *PostService) SetBulkDeletePolicy(policy BulkDeletePolicy) {
+	ps.bulkDeletes.policy = policy
 }
 
 // CreatePost creates a new post
@@ -37,6 +110,18 @@ func (ps *PostService) CreatePost(userID primitive.ObjectID, req models.CreatePo
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// Enforce the author's tier limits
+	limits, err := ps.limits.GetEffectiveLimits(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := limits.Check(models.LimitPostLength, utf8.RuneCountInString(req.Content)); err != nil {
+		return nil, err
+	}
+	if err := limits.Check(models.LimitMediaPerPost, len(req.Media)); err != nil {
+		return nil, err
+	}
+
 	// Convert group and event IDs if provided
 	var groupID, eventID *primitive.ObjectID
 	if req.GroupID != "" {
@@ -58,6 +143,19 @@ func (ps *PostService) CreatePost(userID primitive.ObjectID, req models.CreatePo
 		}
 	}
 
+	// Detect the content language unless the client supplied one; short or
+	// ambiguous text is left without a language rather than mislabelled
+	language := translation.NormalizeLanguage(req.Language)
+	if language == "" {
+		language = translation.DetectLanguage(req.Content)
+	}
+
+	// Posts without an explicit visibility use the author's default
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = getPrivacySettings(ctx, ps.userCollection, userID).DefaultPostVisibility
+	}
+
 	// Create post
 	post := &models.Post{
 		UserID:          userID,
@@ -65,12 +163,13 @@ func (ps *PostService) CreatePost(userID primitive.ObjectID, req models.CreatePo
 		ContentType:     req.ContentType,
 		Media:           req.Media,
 		Type:            req.Type,
-		Visibility:      req.Visibility,
-		Language:        req.Language,
+		Visibility:      visibility,
+		Language:        language,
 		Location:        req.Location,
 		Hashtags:        req.Hashtags,
 		Mentions:        mentions,
 		CommentsEnabled: req.CommentsEnabled,
+		CommentPolicy:   req.CommentPolicy,
 		LikesEnabled:    req.LikesEnabled,
 		SharesEnabled:   req.SharesEnabled,
 		GroupID:         groupID,
@@ -80,10 +179,21 @@ func (ps *PostService) CreatePost(userID primitive.ObjectID, req models.CreatePo
 		PollExpiresAt:   req.PollExpiresAt,
 		PollMultiple:    req.PollMultiple,
 		CustomFields:    req.CustomFields,
+		// A content warning always marks the post sensitive
+		IsSensitive:    req.IsSensitive || req.ContentWarning != "",
+		ContentWarning: req.ContentWarning,
+	}
+	post.PostedByDelegate = req.DelegateID
+	if err := models.CheckBounds(post); err != nil {
+		return nil, err
 	}
 
 	post.BeforeCreate()
 
+	// Attached media without a description picks up the alt text the author
+	// gave the uploaded file
+	ps.fillMediaAltText(ctx, userID, post.Media)
+
 	// Handle scheduled posts
 	if req.ScheduledFor != nil && req.ScheduledFor.After(time.Now()) {
 		post.IsScheduled = true
@@ -91,27 +201,75 @@ func (ps *PostService) CreatePost(userID primitive.ObjectID, req models.CreatePo
 		post.PublishedAt = nil
 	}
 
+	// Group posts need posting rights, and may wait for moderator approval
+	if groupID != nil {
+		pending, err := checkGroupPostPermission(ctx, ps.db, *groupID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if pending {
+			post.GroupReviewStatus = models.GroupPostPending
+			post.IsApproved = false
+			post.IsPublished = false
+			post.PublishedAt = nil
+		}
+	}
+
 	// Extract hashtags from content if not provided
 	if len(post.Hashtags) == 0 {
-		extractedHashtags := extractHashtagsFromText(post.Content)
-		post.Hashtags = extractedHashtags
+		post.Hashtags = utils.ParseEntities(post.Content).Tags()
+	}
+
+	// Reject content the author already posted within the duplicate window
+	contentHash := ps.duplicateContentHash(post.Content)
+	if contentHash != "" {
+		if err := ps.checkSelfDuplicate(ctx, userID, contentHash); err != nil {
+			return nil, err
+		}
+	}
+
+	// Links to blocklisted domains are refused, or the post is hidden for review
+	blockedLink, err := ps.linkBlocklist.Screen(ctx, userID, models.LinkBlockContentPost, post.Content)
+	if err != nil {
+		return nil, err
+	}
+	if blockedLink != nil {
+		post.IsHidden = true
+	}
+
+	usageID, err := ps.limits.Consume(ctx, limits, models.LimitDailyPosts)
+	if err != nil {
+		return nil, err
 	}
 
 	result, err := ps.collection.InsertOne(ctx, post)
 	if err != nil {
+		ps.limits.Release(ctx, usageID)
 		return nil, err
 	}
 
 	post.ID = result.InsertedID.(primitive.ObjectID)
+	repository.RecordWrite(userID)
+
+	if contentHash != "" {
+		ps.recordContentFingerprint(ctx, userID, post.ID, contentHash)
+		go ps.checkCoordinatedSpam(contentHash)
+	}
 
 	// Update user's post count if published
 	if post.IsPublished {
 		ps.updateUserPostCount(userID, true)
 	}
 
+	// A hidden post waits for review before it reaches hashtags and mentions
+	if blockedLink != nil {
+		ps.linkBlocklist.ReportHidden(ctx, userID, models.LinkBlockContentPost, post.ID, blockedLink)
+		return post, nil
+	}
+
 	// Create hashtag entries
 	if len(post.Hashtags) > 0 {
-		go ps.createHashtagEntries(post.Hashtags, post.ID)
+		go ps.createHashtagEntries(post.Hashtags, post.ID, post.UserID)
 	}
 
 	// Create mention notifications
@@ -122,16 +280,161 @@ func (ps *PostService) CreatePost(userID primitive.ObjectID, req models.CreatePo
 	return post, nil
 }
 
+// PreviewPost parses a draft the way CreatePost will and returns what it
+// renders to: mentions resolved to the accounts they will link, hashtags,
+// and previews of the links. Nothing is saved, and a draft over the
+// author's length limit is still previewed so the client can show by how
+// much.
+func (ps *PostService) PreviewPost(userID primitive.ObjectID, req models.PostPreviewRequest) (*models.PostPreview, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	limits, err := ps.limits.GetEffectiveLimits(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	entities := utils.ParseEntities(req.Content)
+	preview := &models.PostPreview{
+		Content:   req.Content,
+		Length:    utf8.RuneCountInString(req.Content),
+		MaxLength: limits.Ceiling(models.LimitPostLength),
+		Mentions:  make([]models.PreviewMention, 0, len(entities.Mentions)),
+		Hashtags:  make([]models.PreviewHashtag, 0, len(entities.Hashtags)),
+		Links:     []models.LinkPreview{},
+	}
+
+	mentionable, err := ps.mentionableUsers(ctx, userID, entities.Usernames())
+	if err != nil {
+		return nil, err
+	}
+	for _, mention := range entities.Mentions {
+		previewMention := models.PreviewMention{
+			Username: mention.Username,
+			Start:    mention.Start,
+			End:      mention.End,
+		}
+		if user, ok := mentionable[strings.ToLower(mention.Username)]; ok {
+			previewMention.Resolved = true
+			previewMention.UserID = &user.ID
+			previewMention.Username = user.Username
+			previewMention.DisplayName = user.DisplayName
+			previewMention.ProfilePic = user.ProfilePic
+			previewMention.IsVerified = user.IsVerified
+		}
+		preview.Mentions = append(preview.Mentions, previewMention)
+	}
+
+	for _, hashtag := range entities.Hashtags {
+		preview.Hashtags = append(preview.Hashtags, models.PreviewHashtag{
+			Tag:   hashtag.Tag,
+			Start: hashtag.Start,
+			End:   hashtag.End,
+		})
+	}
+
+	seen := make(map[string]bool)
+	for _, link := range utils.ExtractLinks(req.Content) {
+		parsed, err := utils.NormalizeLink(link)
+		if err != nil || seen[parsed.String()] {
+			continue
+		}
+		seen[parsed.String()] = true
+
+		linkPreview := models.LinkPreview{URL: parsed.String()}
+		if ps.linkBlocklist != nil {
+			// Blocked links are flagged, and never fetched
+			if match, err := ps.linkBlocklist.Check(ctx, link); err == nil && match != nil {
+				linkPreview.Blocked = true
+			}
+		}
+		preview.Links = append(preview.Links, linkPreview)
+	}
+	ps.linkPreviews.Fetch(ctx, preview.Links)
+
+	return preview, nil
+}
+
+// mentionableUsers looks up the active accounts with the given usernames,
+// keyed by lowercased username. Accounts blocked by or blocking the author
+// are left out, as they won't be linked when the post is published.
+func (ps *PostService) mentionableUsers(ctx context.Context, authorID primitive.ObjectID, usernames []string) (map[string]models.User, error) {
+	users := make(map[string]models.User)
+	if len(usernames) == 0 {
+		return users, nil
+	}
+
+	lowered := make([]string, len(usernames))
+	for i, username := range usernames {
+		lowered[i] = strings.ToLower(username)
+	}
+
+	filter := repository.NotDeleted(bson.M{
+		"$or": []bson.M{
+			{"username_lower": bson.M{"$in": lowered}},
+			{"username": bson.M{"$in": usernames}},
+		},
+	})
+	filter["is_active"] = true
+	filter["is_suspended"] = bson.M{"$ne": true}
+
+	opts := options.Find().SetProjection(bson.M{
+		"username":     1,
+		"display_name": 1,
+		"profile_pic":  1,
+		"is_verified":  1,
+	})
+	cursor, err := ps.userCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var found []models.User
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return users, nil
+	}
+
+	ids := make([]primitive.ObjectID, len(found))
+	for i, user := range found {
+		ids[i] = user.ID
+	}
+	excluded, err := blockedEitherWay(ctx, ps.db, authorID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range found {
+		if !excluded[user.ID] {
+			users[strings.ToLower(user.Username)] = user
+		}
+	}
+	return users, nil
+}
+
 // GetPostByID retrieves a post by ID
 func (ps *PostService) GetPostByID(postID primitive.ObjectID, currentUserID *primitive.ObjectID) (*models.Post, error) {
+	post, err := ps.GetViewablePost(postID, currentUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ps.PreparePostDetail(post, currentUserID); err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+// GetViewablePost fetches a post and checks the viewer may see it, without
+// populating the author
+func (ps *PostService) GetViewablePost(postID primitive.ObjectID, currentUserID *primitive.ObjectID) (*models.Post, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	var post models.Post
-	err := ps.collection.FindOne(ctx, bson.M{
-		"_id":        postID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&post)
+	err := ps.collection.FindOne(ctx, repository.ByID(postID)).Decode(&post)
 
 	if err != nil {
 		return nil, err
@@ -142,17 +445,63 @@ func (ps *PostService) GetPostByID(postID primitive.ObjectID, currentUserID *pri
 		return nil, errors.New("access denied")
 	}
 
+	return &post, nil
+}
+
+// PostETag returns the entity tag for a post as seen by the viewer. It covers
+// the post and its counters, the viewer's own reaction and the embedded
+// author, so a new reaction or a profile edit yields a new tag.
+func (ps *PostService) PostETag(post *models.Post, currentUserID *primitive.ObjectID) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	viewer := ""
+	var reaction models.ReactionType
+	if currentUserID != nil {
+		viewer = currentUserID.Hex()
+
+		var like models.Like
+		err := ps.likeCollection.FindOne(ctx, bson.M{
+			"user_id":     *currentUserID,
+			"target_id":   post.ID,
+			"target_type": "post",
+		}, options.FindOne().SetProjection(bson.M{"reaction_type": 1})).Decode(&like)
+		if err == nil {
+			reaction = like.ReactionType
+		}
+	}
+
+	var author struct {
+		UpdatedAt time.Time `bson:"updated_at"`
+	}
+	ps.userCollection.FindOne(ctx, bson.M{"_id": post.UserID},
+		options.FindOne().SetProjection(bson.M{"updated_at": 1})).Decode(&author)
+
+	return utils.WeakETag(
+		post.ID.Hex(), post.UpdatedAt, viewer, reaction, author.UpdatedAt,
+		post.LikesCount, post.CommentsCount, post.SharesCount, post.SavesCount,
+		post.ReactionCounts, post.QuickRepliesCount,
+	)
+}
+
+// PreparePostDetail populates the author and quick reply senders and records
+// the view for a post fetched with GetViewablePost
+func (ps *PostService) PreparePostDetail(post *models.Post, currentUserID *primitive.ObjectID) error {
 	// Populate author information
-	if err := ps.populatePostAuthor(&post); err != nil {
-		return nil, err
+	if err := ps.populatePostAuthor(post); err != nil {
+		return err
+	}
+
+	if post.QuickRepliesCount > 0 {
+		ps.populateQuickReplySenders(post)
 	}
 
 	// Increment view count
 	if currentUserID != nil && *currentUserID != post.UserID {
-		go ps.incrementViewCount(postID)
+		go ps.incrementViewCount(post.ID)
 	}
 
-	return &post, nil
+	return nil
 }
 
 // GetUserPosts retrieves posts by a specific user
@@ -160,11 +509,8 @@ func (ps *PostService) GetUserPosts(userID primitive.ObjectID, currentUserID *pr
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	filter := bson.M{
-		"user_id":      userID,
-		"is_published": true,
-		"deleted_at":   bson.M{"$exists": false},
-	}
+	// Posts hidden by moderation stay visible to their author
+	filter := repository.Where(bson.M{"user_id": userID}).Published().VisibleTo(currentUserID).Filter()
 
 	// Apply privacy filter if not viewing own posts
 	if currentUserID == nil || *currentUserID != userID {
@@ -177,7 +523,10 @@ func (ps *PostService) GetUserPosts(userID primitive.ObjectID, currentUserID *pr
 		SetSkip(int64(skip)).
 		SetSort(bson.M{"created_at": -1})
 
-	cursor, err := ps.collection.Find(ctx, filter, opts)
+	// Post lists may be served by a secondary; the author's own recent
+	// posts are read from the primary
+	ctx = repository.HeavyRead(ctx, currentUserID)
+	cursor, err := repository.ReadFrom(ctx, ps.collection).Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -204,9 +553,7 @@ func (ps *PostService) GetFeedPosts(userID primitive.ObjectID, limit, skip int)
 	// Complex aggregation pipeline for feed algorithm
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"is_published": true,
-				"deleted_at":   bson.M{"$exists": false},
+			"$match": repository.Where(bson.M{
 				"$or": []bson.M{
 					{"visibility": "public"},
 					{
@@ -216,7 +563,7 @@ func (ps *PostService) GetFeedPosts(userID primitive.ObjectID, limit, skip int)
 						},
 					},
 				},
-			},
+			}).Published().NotHidden().Filter(),
 		},
 		// Lookup author information
 		{
@@ -308,6 +655,23 @@ func (ps *PostService) UpdatePost(postID, userID primitive.ObjectID, req models.
 		return nil, errors.New("access denied")
 	}
 
+	var blockedLink *models.LinkBlockMatch
+	if req.Content != nil {
+		limits, err := ps.limits.GetEffectiveLimits(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if err := limits.Check(models.LimitPostLength, utf8.RuneCountInString(*req.Content)); err != nil {
+			return nil, err
+		}
+
+		// Editing in a blocklisted link is screened like posting one
+		blockedLink, err = ps.linkBlocklist.Screen(ctx, userID, models.LinkBlockContentPost, *req.Content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	update := bson.M{"$set": bson.M{"updated_at": time.Now()}}
 
 	// Update fields if provided
@@ -315,14 +679,18 @@ func (ps *PostService) UpdatePost(postID, userID primitive.ObjectID, req models.
 		update["$set"].(bson.M)["content"] = *req.Content
 		// Re-extract hashtags if content changed
 		if req.Hashtags == nil {
-			update["$set"].(bson.M)["hashtags"] = extractHashtagsFromText(*req.Content)
+			update["$set"].(bson.M)["hashtags"] = utils.ParseEntities(*req.Content).Tags()
+		}
+		// Re-detect language if content changed
+		if req.Language == nil {
+			update["$set"].(bson.M)["language"] = translation.DetectLanguage(*req.Content)
 		}
 	}
 	if req.Visibility != nil {
 		update["$set"].(bson.M)["visibility"] = *req.Visibility
 	}
 	if req.Language != nil {
-		update["$set"].(bson.M)["language"] = *req.Language
+		update["$set"].(bson.M)["language"] = translation.NormalizeLanguage(*req.Language)
 	}
 	if req.Location != nil {
 		update["$set"].(bson.M)["location"] = *req.Location
@@ -337,9 +705,22 @@ func (ps *PostService) UpdatePost(postID, userID primitive.ObjectID, req models.
 				mentions = append(mentions, mentionID)
 			}
 		}
+		if err := models.CheckBounds(models.Post{Mentions: mentions}); err != nil {
+			return nil, err
+		}
 		update["$set"].(bson.M)["mentions"] = mentions
 	}
-	if req.CommentsEnabled != nil {
+	// comments_enabled and comment_policy are kept in step; existing comments
+	// stay when the policy gets stricter
+	if req.CommentPolicy != nil {
+		update["$set"].(bson.M)["comment_policy"] = *req.CommentPolicy
+		update["$set"].(bson.M)["comments_enabled"] = *req.CommentPolicy != models.CommentPolicyOff
+	} else if req.CommentsEnabled != nil {
+		policy := models.CommentPolicyOff
+		if *req.CommentsEnabled {
+			policy = models.CommentPolicyEveryone
+		}
+		update["$set"].(bson.M)["comment_policy"] = policy
 		update["$set"].(bson.M)["comments_enabled"] = *req.CommentsEnabled
 	}
 	if req.LikesEnabled != nil {
@@ -351,142 +732,476 @@ func (ps *PostService) UpdatePost(postID, userID primitive.ObjectID, req models.
 	if req.IsPinned != nil {
 		update["$set"].(bson.M)["is_pinned"] = *req.IsPinned
 	}
+	if req.ContentWarning != nil {
+		update["$set"].(bson.M)["content_warning"] = *req.ContentWarning
+		if *req.ContentWarning != "" {
+			update["$set"].(bson.M)["is_sensitive"] = true
+		}
+	}
+	if req.IsSensitive != nil {
+		if !*req.IsSensitive && post.SensitiveByModerator {
+			return nil, errors.New("post was marked sensitive by a moderator")
+		}
+		update["$set"].(bson.M)["is_sensitive"] = *req.IsSensitive
+		if !*req.IsSensitive {
+			update["$set"].(bson.M)["content_warning"] = ""
+		}
+	}
 
-	// Mark as edited
+	// Mark as edited, attributed to whoever actually made the change
+	editedBy := userID
+	if req.DelegateID != nil {
+		editedBy = *req.DelegateID
+	}
 	update["$set"].(bson.M)["is_edited"] = true
 	update["$set"].(bson.M)["edited_at"] = time.Now()
+	update["$set"].(bson.M)["edited_by"] = editedBy
+	if blockedLink != nil {
+		update["$set"].(bson.M)["is_hidden"] = true
+	}
 
 	_, err = ps.collection.UpdateOne(ctx, bson.M{"_id": postID}, update)
 	if err != nil {
 		return nil, err
 	}
+	repository.RecordWrite(userID)
+
+	ps.recordPostEdit(ctx, post, editedBy)
+
+	if blockedLink != nil {
+		ps.linkBlocklist.ReportHidden(ctx, userID, models.LinkBlockContentPost, postID, blockedLink)
+	}
+
+	// Cached translations no longer match the edited content
+	if req.Content != nil {
+		invalidateTranslations(ctx, ps.db, "post", postID)
+	}
 
 	return ps.GetPostByID(postID, &userID)
 }
 
-// DeletePost soft deletes a post
-func (ps *PostService) DeletePost(postID, userID primitive.ObjectID) error {
+// SetPostSensitive marks a post sensitive or clears the flag. Authors can flag
+// their own posts; moderators can flag any post, and the author can't clear
+// a flag a moderator set.
+func (ps *PostService) SetPostSensitive(postID, userID primitive.ObjectID, isModerator bool, req models.MarkPostSensitiveRequest) (*models.Post, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Check if post exists and user owns it
-	var post models.Post
-	err := ps.collection.FindOne(ctx, bson.M{
-		"_id":     postID,
-		"user_id": userID,
-	}).Decode(&post)
-
+	post, err := ps.GetPostByID(postID, &userID)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return errors.New("post not found or access denied")
+		return nil, err
+	}
+
+	byModerator := post.SensitiveByModerator
+	if post.UserID != userID {
+		if !isModerator {
+			return nil, errors.New("access denied")
 		}
-		return err
+		byModerator = req.IsSensitive
+	} else if !req.IsSensitive && byModerator && !isModerator {
+		return nil, errors.New("post was marked sensitive by a moderator")
 	}
 
-	// Soft delete the post
-	now := time.Now()
-	update := bson.M{
-		"$set": bson.M{
-			"deleted_at":  now,
-			"updated_at":  now,
-			"is_hidden":   true,
-			"is_approved": false,
-		},
+	warning := req.ContentWarning
+	if !req.IsSensitive {
+		warning = ""
+		byModerator = false
 	}
 
-	_, err = ps.collection.UpdateOne(ctx, bson.M{"_id": postID}, update)
+	_, err = ps.collection.UpdateOne(ctx, bson.M{"_id": postID}, bson.M{
+		"$set": bson.M{
+			"is_sensitive":           req.IsSensitive,
+			"content_warning":        warning,
+			"sensitive_by_moderator": byModerator,
+			"updated_at":             time.Now(),
+		},
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Update user's post count
-	go ps.updateUserPostCount(userID, false)
-
-	return nil
+	return ps.GetPostByID(postID, &userID)
 }
 
-// LikePost adds or removes a like from a post
-func (ps *PostService) LikePost(postID, userID primitive.ObjectID, reactionType models.ReactionType) error {
+// GetPostEdits returns a post's edit history, newest first. The history names
+// the delegate behind each edit, so only the author and admins can see it.
+func (ps *PostService) GetPostEdits(postID, userID primitive.ObjectID, limit, skip int) ([]models.PostEdit, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Check if post exists
 	var post models.Post
-	err := ps.collection.FindOne(ctx, bson.M{
-		"_id":           postID,
-		"is_published":  true,
-		"likes_enabled": true,
-		"deleted_at":    bson.M{"$exists": false},
-	}).Decode(&post)
-
+	err := ps.collection.FindOne(ctx, repository.ByID(postID)).Decode(&post)
 	if err != nil {
-		return err
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("post not found")
+		}
+		return nil, err
 	}
 
-	// Check if user already liked this post
-	var existingLike models.Like
-	err = ps.likeCollection.FindOne(ctx, bson.M{
-		"user_id":     userID,
-		"target_id":   postID,
-		"target_type": "post",
-	}).Decode(&existingLike)
-
-	if err == nil {
-		// Update existing like
-		update := bson.M{
-			"$set": bson.M{
-				"reaction_type": reactionType,
-				"updated_at":    time.Now(),
-			},
+	if post.UserID != userID {
+		var user models.User
+		if err := ps.userCollection.FindOne(ctx, bson.M{"_id": userID}).Decode(&user); err != nil {
+			return nil, err
 		}
-		_, err = ps.likeCollection.UpdateOne(ctx, bson.M{"_id": existingLike.ID}, update)
-	} else if err == mongo.ErrNoDocuments {
-		// Create new like
-		like := &models.Like{
-			UserID:       userID,
-			TargetID:     postID,
-			TargetType:   "post",
-			ReactionType: reactionType,
+		if user.Role != models.RoleAdmin && user.Role != models.RoleSuperAdmin {
+			return nil, errors.New("access denied")
 		}
-		like.BeforeCreate()
+	}
 
-		_, err = ps.likeCollection.InsertOne(ctx, like)
-		if err != nil {
-			return err
-		}
+	opts := options.Find().
+		SetSort(bson.M{"created_at": -1}).
+		SetLimit(int64(limit)).
+		SetSkip(int64(skip))
 
-		// Increment post like count
-		ps.collection.UpdateOne(ctx, bson.M{"_id": postID}, bson.M{
-			"$inc": bson.M{"likes_count": 1},
-		})
+	cursor, err := ps.db.Collection("post_edits").Find(ctx, bson.M{"post_id": postID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
 
-		// Update user's total likes received
-		go ps.updateUserLikesCount(post.UserID, true)
+	var edits []models.PostEdit
+	if err := cursor.All(ctx, &edits); err != nil {
+		return nil, err
 	}
 
-	return err
+	for i := range edits {
+		var editor models.User
+		if err := ps.userCollection.FindOne(ctx, bson.M{"_id": edits[i].EditedBy}).Decode(&editor); err == nil {
+			edits[i].Editor = editor.ToUserResponse()
+		}
+	}
+
+	return edits, nil
 }
 
-// UnlikePost removes a like from a post
-func (ps *PostService) UnlikePost(postID, userID primitive.ObjectID) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// DeletePost soft deletes a post
+func (ps *PostService) DeletePost(postID, userID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	// Find and delete the like
-	result, err := ps.likeCollection.DeleteOne(ctx, bson.M{
+	// Check if post exists and user owns it
+	var post models.Post
+	err := ps.collection.FindOne(ctx, repository.NotDeleted(bson.M{
+		"_id":     postID,
+		"user_id": userID,
+	})).Decode(&post)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.New("post not found or access denied")
+		}
+		return err
+	}
+
+	// Soft delete the post along with its comments, likes, mentions and reports
+	now := time.Now()
+	_, err = softDeletePost(ctx, ps.db, &post, now, bson.M{
+		"deleted_at":  now,
+		"updated_at":  now,
+		"is_hidden":   true,
+		"is_approved": false,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Update user's post count
+	go ps.updateUserPostCount(userID, false)
+
+	return nil
+}
+
+// PreviewBulkDeleteMyPosts counts the user's posts a bulk delete filter
+// matches. The count is what BulkDeleteMyPosts must be given to confirm.
+func (ps *PostService) PreviewBulkDeleteMyPosts(userID primitive.ObjectID, filter models.BulkDeleteFilter) (*models.BulkDeletePreview, error) {
+	return ps.bulkDeletes.preview(userID, filter)
+}
+
+// BulkDeleteMyPosts soft deletes the user's posts matching the filter, with
+// their comments, likes, mentions and reports. The returned job is already
+// completed for small deletions; larger ones run in the background.
+func (ps *PostService) BulkDeleteMyPosts(userID primitive.ObjectID, req models.BulkDeleteRequest) (*models.BulkDeleteJob, error) {
+	return ps.bulkDeletes.start(userID, req)
+}
+
+// GetBulkDeleteJob retrieves one of the user's bulk post deletions
+func (ps *PostService) GetBulkDeleteJob(userID, jobID primitive.ObjectID) (*models.BulkDeleteJob, error) {
+	return ps.bulkDeletes.job(userID, jobID)
+}
+
+// ResumeBulkDeleteJobs restarts the bulk post deletions a previous process
+// left unfinished
+func (ps *PostService) ResumeBulkDeleteJobs() {
+	ps.bulkDeletes.resume()
+}
+
+// deletePostBatch soft deletes up to limit posts matching the filter the way
+// DeletePost does, taking them off their author's post count
+func (ps *PostService) deletePostBatch(ctx context.Context, filter bson.M, limit int64) (int64, error) {
+	cursor, err := ps.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(limit))
+	if err != nil {
+		return 0, err
+	}
+	var posts []models.Post
+	if err := cursor.All(ctx, &posts); err != nil {
+		return 0, err
+	}
+
+	deletedByUser := make(map[primitive.ObjectID]int64)
+	var deleted int64
+	var deleteErr error
+	for i := range posts {
+		now := time.Now()
+		if _, deleteErr = softDeletePost(ctx, ps.db, &posts[i], now, bson.M{
+			"deleted_at":  now,
+			"updated_at":  now,
+			"is_hidden":   true,
+			"is_approved": false,
+		}); deleteErr != nil {
+			break
+		}
+		deletedByUser[posts[i].UserID]++
+		deleted++
+	}
+
+	for userID, count := range deletedByUser {
+		ps.userCollection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+			"$inc": bson.M{"posts_count": -count},
+			"$set": bson.M{"updated_at": time.Now()},
+		})
+	}
+
+	return deleted, deleteErr
+}
+
+// PostCascadeResult counts the records a post deletion took with it
+type PostCascadeResult struct {
+	Comments int64 `json:"comments"`
+	Likes    int64 `json:"likes"` // Likes on the post and on its comments
+	Mentions int64 `json:"mentions"`
+	Reports  int64 `json:"reports"` // Open reports closed
+}
+
+// softDeletePost sets the post's soft delete fields and cascades the deletion
+// to its comments, likes, mentions and reports, in one transaction where the
+// deployment supports it. Without transactions a cascade that fails after the
+// post is deleted is logged and left for the orphan check to finish.
+func softDeletePost(ctx context.Context, db *mongo.Database, post *models.Post, now time.Time, set bson.M) (PostCascadeResult, error) {
+	var result PostCascadeResult
+	deleted := false
+
+	transactional, err := config.RunInTransaction(ctx, db, func(ctx context.Context) error {
+		if _, err := db.Collection("posts").UpdateOne(ctx, bson.M{"_id": post.ID}, bson.M{"$set": set}); err != nil {
+			return err
+		}
+		deleted = true
+
+		var err error
+		result, err = cascadePostDelete(ctx, db, post, now)
+		return err
+	})
+	if err != nil {
+		if !transactional && deleted {
+			log.Printf("Post %s deleted but its cascade failed; the orphan check will finish it: %v", post.ID.Hex(), err)
+			return result, nil
+		}
+		return result, err
+	}
+
+	return result, nil
+}
+
+// cascadePostDelete soft-deletes the comments, likes and mentions belonging
+// to a deleted post, takes them off their users' counters and closes the open
+// reports on the post and its comments. Records already deleted are left
+// alone, so running it again for the same post is harmless.
+func cascadePostDelete(ctx context.Context, db *mongo.Database, post *models.Post, now time.Time) (PostCascadeResult, error) {
+	var result PostCascadeResult
+	users := db.Collection("users")
+
+	comments := db.Collection("comments")
+	cursor, err := comments.Find(ctx, repository.NotDeleted(bson.M{"post_id": post.ID}),
+		options.Find().SetProjection(bson.M{"user_id": 1, "hold_status": 1, "is_hidden": 1}))
+	if err != nil {
+		return result, err
+	}
+	var postComments []models.Comment
+	if err := cursor.All(ctx, &postComments); err != nil {
+		return result, err
+	}
+
+	// Held and hidden comments were never counted towards their authors
+	commentIDs := make([]primitive.ObjectID, 0, len(postComments))
+	countedByUser := make(map[primitive.ObjectID]int64)
+	for _, comment := range postComments {
+		commentIDs = append(commentIDs, comment.ID)
+		if !comment.IsHeld() && !comment.IsHidden {
+			countedByUser[comment.UserID]++
+		}
+	}
+
+	if len(commentIDs) > 0 {
+		updated, err := comments.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": commentIDs}}, bson.M{"$set": bson.M{
+			"deleted_at":  now,
+			"updated_at":  now,
+			"is_hidden":   true,
+			"is_approved": false,
+		}})
+		if err != nil {
+			return result, err
+		}
+		result.Comments = updated.ModifiedCount
+
+		for userID, count := range countedByUser {
+			if _, err := users.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+				"$inc": bson.M{"comments_count": -count},
+				"$set": bson.M{"updated_at": now},
+			}); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	likes := db.Collection("likes")
+	postLikes, err := likes.CountDocuments(ctx, repository.NotDeleted(bson.M{"target_type": "post", "target_id": post.ID}))
+	if err != nil {
+		return result, err
+	}
+	updated, err := likes.UpdateMany(ctx, repository.NotDeleted(bson.M{"$or": []bson.M{
+		{"target_type": "post", "target_id": post.ID},
+		{"target_type": "comment", "target_id": bson.M{"$in": commentIDs}},
+	}}), bson.M{"$set": bson.M{"deleted_at": now, "updated_at": now}})
+	if err != nil {
+		return result, err
+	}
+	result.Likes = updated.ModifiedCount
+	if postLikes > 0 {
+		if _, err := users.UpdateOne(ctx, bson.M{"_id": post.UserID}, bson.M{
+			"$inc": bson.M{"total_likes_received": -postLikes},
+			"$set": bson.M{"updated_at": now},
+		}); err != nil {
+			return result, err
+		}
+	}
+
+	updated, err = db.Collection("mentions").UpdateMany(ctx, repository.NotDeleted(bson.M{"$or": []bson.M{
+		{"content_type": "post", "content_id": post.ID},
+		{"content_type": "comment", "content_id": bson.M{"$in": commentIDs}},
+	}}), bson.M{"$set": bson.M{"is_active": false, "deleted_at": now, "updated_at": now}})
+	if err != nil {
+		return result, err
+	}
+	result.Mentions = updated.ModifiedCount
+
+	updated, err = db.Collection("reports").UpdateMany(ctx, bson.M{
+		"$or": []bson.M{
+			{"target_type": "post", "target_id": post.ID},
+			{"target_type": "comment", "target_id": bson.M{"$in": commentIDs}},
+		},
+		"status": bson.M{"$in": []models.ReportStatus{models.ReportPending, models.ReportReviewing}},
+	}, bson.M{"$set": bson.M{
+		"status":          models.ReportResolved,
+		"resolution":      "post_deleted",
+		"content_removed": true,
+		"resolved_at":     now,
+		"updated_at":      now,
+	}})
+	if err != nil {
+		return result, err
+	}
+	result.Reports = updated.ModifiedCount
+
+	return result, nil
+}
+
+// LikePost adds or removes a like from a post
+func (ps *PostService) LikePost(postID, userID primitive.ObjectID, reactionType models.ReactionType) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ensureReactionAvailable(ctx, ps.db, reactionType); err != nil {
+		return err
+	}
+
+	// Check if post exists
+	var post models.Post
+	err := ps.collection.FindOne(ctx, repository.Where(bson.M{
+		"_id":           postID,
+		"likes_enabled": true,
+	}).Published().Filter()).Decode(&post)
+
+	if err != nil {
+		return err
+	}
+
+	// Check if user already liked this post
+	var existingLike models.Like
+	err = ps.likeCollection.FindOne(ctx, bson.M{
 		"user_id":     userID,
 		"target_id":   postID,
 		"target_type": "post",
-	})
+	}).Decode(&existingLike)
 
-	if err != nil {
+	if err == nil {
+		// Update existing like
+		update := bson.M{
+			"$set": bson.M{
+				"reaction_type": reactionType,
+				"updated_at":    time.Now(),
+			},
+		}
+		_, err = ps.likeCollection.UpdateOne(ctx, bson.M{"_id": existingLike.ID}, update)
+		if err == nil {
+			adjustReactionCounts(ctx, ps.collection, postID, existingLike.ReactionType, reactionType)
+		}
+	} else if err == mongo.ErrNoDocuments {
+		// Create new like
+		like := &models.Like{
+			UserID:       userID,
+			TargetID:     postID,
+			TargetType:   "post",
+			ReactionType: reactionType,
+		}
+		like.BeforeCreate()
+
+		_, err = ps.likeCollection.InsertOne(ctx, like)
+		if err != nil {
+			return err
+		}
+
+		// Increment post like and reaction counts
+		ps.collection.UpdateOne(ctx, bson.M{"_id": postID}, bson.M{
+			"$inc": bson.M{"likes_count": 1, "reaction_counts." + string(like.ReactionType): 1},
+		})
+
+		// Update user's total likes received
+		go ps.updateUserLikesCount(post.UserID, true)
+	}
+
+	return err
+}
+
+// UnlikePost removes a like from a post
+func (ps *PostService) UnlikePost(postID, userID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Find and delete the like
+	var like models.Like
+	err := ps.likeCollection.FindOneAndDelete(ctx, bson.M{
+		"user_id":     userID,
+		"target_id":   postID,
+		"target_type": "post",
+	}).Decode(&like)
+
+	if err != nil && err != mongo.ErrNoDocuments {
 		return err
 	}
 
-	if result.DeletedCount > 0 {
-		// Decrement post like count
+	if err == nil {
+		// Decrement post like and reaction counts
 		ps.collection.UpdateOne(ctx, bson.M{"_id": postID}, bson.M{
-			"$inc": bson.M{"likes_count": -1},
+			"$inc": bson.M{"likes_count": -1, "reaction_counts." + string(like.ReactionType): -1},
 		})
 
 		// Get post owner for updating their likes count
@@ -506,10 +1221,7 @@ func (ps *PostService) ReportPost(postID, reporterID primitive.ObjectID, reason
 
 	// Check if post exists
 	var post models.Post
-	err := ps.collection.FindOne(ctx, bson.M{
-		"_id":        postID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&post)
+	err := ps.collection.FindOne(ctx, repository.ByID(postID)).Decode(&post)
 
 	if err != nil {
 		return err
@@ -594,11 +1306,15 @@ func (ps *PostService) GetPostStats(postID primitive.ObjectID) (*models.PostStat
 	defer cancel()
 
 	var post models.Post
-	err := ps.collection.FindOne(ctx, bson.M{"_id": postID}).Decode(&post)
+	err := ps.collection.FindOne(ctx, repository.ByID(postID)).Decode(&post)
 	if err != nil {
 		return nil, err
 	}
 
+	if post.QuickRepliesCount > 0 {
+		ps.populateQuickReplySenders(&post)
+	}
+
 	return &models.PostStatsResponse{
 		PostID:          post.ID.Hex(),
 		LikesCount:      post.LikesCount,
@@ -609,6 +1325,7 @@ func (ps *PostService) GetPostStats(postID primitive.ObjectID) (*models.PostStat
 		EngagementRate:  post.EngagementRate,
 		ReachCount:      post.ReachCount,
 		ImpressionCount: post.ImpressionCount,
+		QuickReactions:  post.QuickReactionSummary(),
 	}, nil
 }
 
@@ -625,16 +1342,15 @@ func (ps *PostService) SearchPosts(query string, currentUserID *primitive.Object
 					{"hashtags": bson.M{"$in": []string{query}}},
 				},
 			},
-			{"is_published": true},
-			{"deleted_at": bson.M{"$exists": false}},
 			{"visibility": "public"}, // Only search public posts for now
 		},
 	}
+	filter = repository.Where(filter).Published().NotHidden().Filter()
 
 	opts := options.Find().
 		SetLimit(int64(limit)).
 		SetSkip(int64(skip)).
-		SetSort(bson.M{"engagement_rate": -1, "created_at": -1})
+		SetSort(bson.D{{Key: "engagement_rate", Value: -1}, {Key: "created_at", Value: -1}})
 
 	cursor, err := ps.collection.Find(ctx, filter, opts)
 	if err != nil {
@@ -673,20 +1389,18 @@ func (ps *PostService) GetTrendingPosts(limit, skip int, timeRange string) ([]mo
 		timeFilter = time.Now().Add(-24 * time.Hour)
 	}
 
-	filter := bson.M{
-		"is_published": true,
-		"visibility":   "public",
-		"deleted_at":   bson.M{"$exists": false},
-		"created_at":   bson.M{"$gte": timeFilter},
-	}
+	filter := repository.Where(bson.M{
+		"visibility": "public",
+		"created_at": bson.M{"$gte": timeFilter},
+	}).Published().NotHidden().Filter()
 
 	opts := options.Find().
 		SetLimit(int64(limit)).
 		SetSkip(int64(skip)).
-		SetSort(bson.M{
-			"engagement_rate": -1,
-			"likes_count":     -1,
-			"comments_count":  -1,
+		SetSort(bson.D{
+			{Key: "engagement_rate", Value: -1},
+			{Key: "likes_count", Value: -1},
+			{Key: "comments_count", Value: -1},
 		})
 
 	cursor, err := ps.collection.Find(ctx, filter, opts)
@@ -708,6 +1422,267 @@ func (ps *PostService) GetTrendingPosts(limit, skip int, timeRange string) ([]mo
 	return posts, nil
 }
 
+// TagUsers tags users on the media of the author's post. Tagging a user
+// again on the same media moves their tag. Newly tagged users are notified;
+// their tag is accepted straight away unless they review tags.
+func (ps *PostService) TagUsers(postID, userID primitive.ObjectID, req models.TagUsersRequest) ([]models.PhotoTag, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var post models.Post
+	if err := ps.collection.FindOne(ctx, repository.ByID(postID)).Decode(&post); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("post not found")
+		}
+		return nil, err
+	}
+	if post.UserID != userID {
+		return nil, errors.New("access denied")
+	}
+	if len(post.Media) == 0 {
+		return nil, errors.New("invalid tag: post has no media")
+	}
+
+	taggedIDs := make([]primitive.ObjectID, 0, len(req.Tags))
+	seen := make(map[primitive.ObjectID]bool)
+	for _, input := range req.Tags {
+		taggedID, err := primitive.ObjectIDFromHex(input.UserID)
+		if err != nil {
+			return nil, errors.New("invalid tag: invalid user ID")
+		}
+		if input.MediaIndex < 0 || input.MediaIndex >= len(post.Media) {
+			return nil, fmt.Errorf("invalid tag: media %d does not exist", input.MediaIndex)
+		}
+		if input.X < 0 || input.X > 1 || input.Y < 0 || input.Y > 1 {
+			return nil, errors.New("invalid tag: coordinates must be between 0 and 1")
+		}
+		if !seen[taggedID] {
+			seen[taggedID] = true
+			taggedIDs = append(taggedIDs, taggedID)
+		}
+	}
+
+	cursor, err := ps.userCollection.Find(ctx, repository.NotDeleted(bson.M{
+		"_id":       bson.M{"$in": taggedIDs},
+		"is_active": true,
+	}), options.Find().SetProjection(bson.M{"privacy_settings": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	if len(users) != len(taggedIDs) {
+		return nil, errors.New("user not found")
+	}
+
+	reviewsTags := make(map[primitive.ObjectID]bool, len(users))
+	for _, user := range users {
+		if user.ID != userID && !user.PrivacySettings.AllowTagging {
+			return nil, errors.New("user does not allow tagging")
+		}
+		reviewsTags[user.ID] = user.PrivacySettings.ReviewTags && user.ID != userID
+	}
+
+	// Users blocked in either direction can't be told apart from missing ones
+	blocked, err := hasBlockBetween(ctx, ps.db, []primitive.ObjectID{userID}, taggedIDs)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, errors.New("user not found")
+	}
+
+	tags := post.PhotoTags
+	alreadyTagged := make(map[primitive.ObjectID]bool)
+	for _, tag := range tags {
+		alreadyTagged[tag.UserID] = true
+	}
+
+	now := time.Now()
+	var newlyTagged []primitive.ObjectID
+	for _, input := range req.Tags {
+		taggedID, _ := primitive.ObjectIDFromHex(input.UserID)
+
+		moved := false
+		for i := range tags {
+			if tags[i].UserID == taggedID && tags[i].MediaIndex == input.MediaIndex {
+				tags[i].X, tags[i].Y = input.X, input.Y
+				moved = true
+				break
+			}
+		}
+		if moved {
+			continue
+		}
+
+		status := models.PhotoTagAccepted
+		if reviewsTags[taggedID] {
+			status = models.PhotoTagPending
+		}
+		// A user already on the post keeps the status they chose
+		for _, tag := range tags {
+			if tag.UserID == taggedID {
+				status = tag.Status
+				break
+			}
+		}
+
+		tags = append(tags, models.PhotoTag{
+			UserID:     taggedID,
+			MediaIndex: input.MediaIndex,
+			X:          input.X,
+			Y:          input.Y,
+			Status:     status,
+			CreatedAt:  now,
+		})
+		if !alreadyTagged[taggedID] {
+			alreadyTagged[taggedID] = true
+			newlyTagged = append(newlyTagged, taggedID)
+		}
+	}
+	if len(tags) > models.MaxPhotoTagsPerPost {
+		return nil, fmt.Errorf("invalid tag: a post can have at most %d tags", models.MaxPhotoTagsPerPost)
+	}
+
+	_, err = ps.collection.UpdateOne(ctx, bson.M{"_id": postID}, bson.M{
+		"$set": bson.M{"photo_tags": tags, "updated_at": now},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if ps.notificationService != nil {
+		for _, taggedID := range newlyTagged {
+			go ps.notificationService.NotifyPhotoTag(userID, taggedID, postID, reviewsTags[taggedID])
+		}
+	}
+
+	return tags, nil
+}
+
+// RemoveTag removes a user's tags from a post. The post's author can remove
+// anyone's tag; everyone else only their own.
+func (ps *PostService) RemoveTag(postID, requesterID, taggedUserID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var post models.Post
+	err := ps.collection.FindOne(ctx, repository.ByID(postID),
+		options.FindOne().SetProjection(bson.M{"user_id": 1}),
+	).Decode(&post)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.New("post not found")
+		}
+		return err
+	}
+	if requesterID != post.UserID && requesterID != taggedUserID {
+		return errors.New("access denied")
+	}
+
+	result, err := ps.collection.UpdateOne(ctx, bson.M{
+		"_id":                postID,
+		"photo_tags.user_id": taggedUserID,
+	}, bson.M{
+		"$pull": bson.M{"photo_tags": bson.M{"user_id": taggedUserID}},
+		"$set":  bson.M{"updated_at": time.Now()},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("tag not found")
+	}
+	return nil
+}
+
+// RespondToTag lets a tagged user accept a post onto their tagged tab or
+// hide it from there; the tag stays on the post either way
+func (ps *PostService) RespondToTag(postID, userID primitive.ObjectID, status models.PhotoTagStatus) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if status != models.PhotoTagAccepted && status != models.PhotoTagHidden {
+		return errors.New("invalid tag status")
+	}
+
+	result, err := ps.collection.UpdateOne(ctx, repository.NotDeleted(bson.M{
+		"_id":                postID,
+		"photo_tags.user_id": userID,
+	}), bson.M{
+		"$set": bson.M{"photo_tags.$[tag].status": status},
+	}, options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"tag.user_id": userID}},
+	}))
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("tag not found")
+	}
+	return nil
+}
+
+// GetTaggedPosts returns the posts on a user's tagged tab, newest first.
+// The user may list their pending or hidden tags instead by passing status;
+// everyone else sees accepted tags on public posts only.
+func (ps *PostService) GetTaggedPosts(userID primitive.ObjectID, viewerID *primitive.ObjectID, status models.PhotoTagStatus, limit, skip int) ([]models.Post, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	isOwner := viewerID != nil && *viewerID == userID
+	if status == "" || !isOwner {
+		status = models.PhotoTagAccepted
+	}
+
+	base := bson.M{
+		"photo_tags": bson.M{"$elemMatch": bson.M{"user_id": userID, "status": status}},
+	}
+	if viewerID == nil {
+		base["visibility"] = models.PrivacyPublic
+	} else {
+		base["$and"] = []bson.M{{"$or": []bson.M{
+			{"visibility": models.PrivacyPublic},
+			{"user_id": *viewerID},
+		}}}
+	}
+	filter := repository.Where(base).Published().VisibleTo(viewerID).Filter()
+
+	total, err := ps.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSkip(int64(skip)).
+		SetSort(bson.M{"created_at": -1})
+
+	cursor, err := ps.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var posts []models.Post
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, 0, err
+	}
+
+	for i := range posts {
+		ps.populatePostAuthor(&posts[i])
+	}
+
+	return posts, total, nil
+}
+
+// CanUserViewPost reports whether the user is allowed to view the post
+func (ps *PostService) CanUserViewPost(post *models.Post, userID primitive.ObjectID) bool {
+	return ps.canUserViewPost(post, userID)
+}
+
 // Helper methods
 
 func (ps *PostService) canUserViewPost(post *models.Post, userID primitive.ObjectID) bool {
@@ -764,6 +1739,288 @@ func (ps *PostService) populatePostAuthor(post *models.Post) error {
 	return nil
 }
 
+// populateQuickReplySenders loads the top quick reply senders for a post
+func (ps *PostService) populateQuickReplySenders(post *models.Post) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	senders, err := getQuickReplySenders(ctx, ps.db, post.ID, quickReplyTopSenders)
+	if err != nil {
+		log.Printf("Failed to load quick reply senders for post %s: %v", post.ID.Hex(), err)
+		return
+	}
+	post.QuickReplySenders = senders
+}
+
+// recordPostEdit adds an entry to the post's edit history
+func (ps *PostService) recordPostEdit(ctx context.Context, before *models.Post, editedBy primitive.ObjectID) {
+	edit := models.PostEdit{
+		PostID:          before.ID,
+		EditedBy:        editedBy,
+		PreviousContent: before.Content,
+	}
+	if editedBy != before.UserID {
+		edit.OnBehalfOf = &before.UserID
+	}
+	edit.BeforeCreate()
+
+	ps.db.Collection("post_edits").InsertOne(ctx, edit)
+}
+
+// DeleteExpiredPosts soft-deletes the posts of users with an auto-delete
+// period that are older than it. Pinned and unpublished posts are kept. It
+// returns how many posts were deleted.
+func (ps *PostService) DeleteExpiredPosts(ctx context.Context) (int64, error) {
+	var deleted int64
+	var lastID primitive.ObjectID
+
+	for {
+		filter := repository.NotDeleted(bson.M{"auto_delete_posts_after": bson.M{"$gt": 0}})
+		if !lastID.IsZero() {
+			filter["_id"] = bson.M{"$gt": lastID}
+		}
+		opts := options.Find().
+			SetProjection(bson.M{"auto_delete_posts_after": 1}).
+			SetSort(bson.D{{Key: "_id", Value: 1}}).
+			SetLimit(autoDeleteUserBatch)
+		cursor, err := ps.userCollection.Find(ctx, filter, opts)
+		if err != nil {
+			return deleted, err
+		}
+		var users []models.User
+		if err := cursor.All(ctx, &users); err != nil {
+			return deleted, err
+		}
+		if len(users) == 0 {
+			return deleted, nil
+		}
+
+		for _, user := range users {
+			if ctx.Err() != nil {
+				return deleted, ctx.Err()
+			}
+			now := time.Now()
+			result, err := ps.collection.UpdateMany(ctx, autoDeletablePostsFilter(user.ID, now.Add(-*user.AutoDeletePostsAfter)), bson.M{
+				"$set": bson.M{
+					"deleted_at":  now,
+					"updated_at":  now,
+					"is_hidden":   true,
+					"is_approved": false,
+				},
+			})
+			if err != nil {
+				return deleted, err
+			}
+			if result.ModifiedCount > 0 {
+				ps.userCollection.UpdateOne(ctx, bson.M{"_id": user.ID}, bson.M{
+					"$inc": bson.M{"posts_count": -result.ModifiedCount},
+					"$set": bson.M{"updated_at": now},
+				})
+				deleted += result.ModifiedCount
+			}
+		}
+		lastID = users[len(users)-1].ID
+	}
+}
+
+// StartAutoDeleteJob runs DeleteExpiredPosts every interval until
+// StopAutoDeleteJob is called
+func (ps *PostService) StartAutoDeleteJob(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ps.stopAutoDelete = cancel
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				deleted, err := ps.DeleteExpiredPosts(ctx)
+				if ctx.Err() != nil {
+					return
+				}
+				recordJobHeartbeat(ps.db, "post_auto_delete", interval, err)
+				if err != nil {
+					log.Printf("Post auto-delete failed after %d posts: %v", deleted, err)
+				} else if deleted > 0 {
+					log.Printf("Auto-deleted %d posts", deleted)
+				}
+			}
+		}
+	}()
+}
+
+// StopAutoDeleteJob stops the periodic post auto-delete
+func (ps *PostService) StopAutoDeleteJob() {
+	if ps.stopAutoDelete != nil {
+		ps.stopAutoDelete()
+	}
+}
+
+// PostOrphanReport summarizes one pass of the orphan check
+type PostOrphanReport struct {
+	PostsChecked  int64             `json:"posts_checked"`
+	PostsRepaired int64             `json:"posts_repaired"` // Deleted posts that still had live children
+	Cleaned       PostCascadeResult `json:"cleaned"`
+}
+
+// RepairOrphanedPostChildren finds posts deleted since the given time that
+// still have live comments, likes, mentions or open reports, left behind by
+// deletions that skipped the cascade or failed part way, and finishes their
+// cascade
+func (ps *PostService) RepairOrphanedPostChildren(ctx context.Context, since time.Time) (PostOrphanReport, error) {
+	var report PostOrphanReport
+	var lastID primitive.ObjectID
+
+	for {
+		filter := bson.M{repository.DeletedAtField: bson.M{"$gte": since}}
+		if !lastID.IsZero() {
+			filter["_id"] = bson.M{"$gt": lastID}
+		}
+		opts := options.Find().
+			SetProjection(bson.M{"user_id": 1}).
+			SetSort(bson.D{{Key: "_id", Value: 1}}).
+			SetLimit(orphanCheckPostBatch)
+		cursor, err := ps.collection.Find(ctx, filter, opts)
+		if err != nil {
+			return report, err
+		}
+		var posts []models.Post
+		if err := cursor.All(ctx, &posts); err != nil {
+			return report, err
+		}
+		if len(posts) == 0 {
+			return report, nil
+		}
+		lastID = posts[len(posts)-1].ID
+		report.PostsChecked += int64(len(posts))
+
+		orphaned, err := ps.postsWithLiveChildren(ctx, posts)
+		if err != nil {
+			return report, err
+		}
+		for i := range posts {
+			if !orphaned[posts[i].ID] {
+				continue
+			}
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+
+			var cleaned PostCascadeResult
+			_, err := config.RunInTransaction(ctx, ps.db, func(ctx context.Context) error {
+				var err error
+				cleaned, err = cascadePostDelete(ctx, ps.db, &posts[i], time.Now())
+				return err
+			})
+			if err != nil {
+				return report, err
+			}
+			report.PostsRepaired++
+			report.Cleaned.Comments += cleaned.Comments
+			report.Cleaned.Likes += cleaned.Likes
+			report.Cleaned.Mentions += cleaned.Mentions
+			report.Cleaned.Reports += cleaned.Reports
+		}
+	}
+}
+
+// postsWithLiveChildren returns which of the deleted posts still have live
+// comments, likes or mentions, or open reports
+func (ps *PostService) postsWithLiveChildren(ctx context.Context, posts []models.Post) (map[primitive.ObjectID]bool, error) {
+	postIDs := make([]primitive.ObjectID, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.ID
+	}
+
+	checks := []struct {
+		collection string
+		field      string
+		filter     bson.M
+	}{
+		{"comments", "post_id", repository.NotDeleted(bson.M{"post_id": bson.M{"$in": postIDs}})},
+		{"likes", "target_id", repository.NotDeleted(bson.M{"target_type": "post", "target_id": bson.M{"$in": postIDs}})},
+		{"mentions", "content_id", repository.NotDeleted(bson.M{"content_type": "post", "content_id": bson.M{"$in": postIDs}})},
+		{"reports", "target_id", bson.M{
+			"target_type": "post",
+			"target_id":   bson.M{"$in": postIDs},
+			"status":      bson.M{"$in": []models.ReportStatus{models.ReportPending, models.ReportReviewing}},
+		}},
+	}
+
+	orphaned := make(map[primitive.ObjectID]bool)
+	for _, check := range checks {
+		ids, err := ps.db.Collection(check.collection).Distinct(ctx, check.field, check.filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			if oid, ok := id.(primitive.ObjectID); ok {
+				orphaned[oid] = true
+			}
+		}
+	}
+	return orphaned, nil
+}
+
+// StartOrphanCheckJob runs RepairOrphanedPostChildren every interval until
+// StopOrphanCheckJob is called. The first pass checks every deleted post;
+// later passes check the posts deleted since the previous pass began.
+func (ps *PostService) StartOrphanCheckJob(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ps.stopOrphanCheck = cancel
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var since time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				started := time.Now()
+				report, err := ps.RepairOrphanedPostChildren(ctx, since)
+				if ctx.Err() != nil {
+					return
+				}
+				recordJobHeartbeat(ps.db, "post_orphan_check", interval, err)
+				if err != nil {
+					log.Printf("Post orphan check failed after %d posts: %v", report.PostsChecked, err)
+					continue
+				}
+				since = started
+				if report.PostsRepaired > 0 {
+					log.Printf("Orphan check finished the deletion of %d posts: %d comments, %d likes, %d mentions, %d reports",
+						report.PostsRepaired, report.Cleaned.Comments, report.Cleaned.Likes, report.Cleaned.Mentions, report.Cleaned.Reports)
+				}
+			}
+		}
+	}()
+}
+
+// StopOrphanCheckJob stops the periodic post orphan check
+func (ps *PostService) StopOrphanCheckJob() {
+	if ps.stopOrphanCheck != nil {
+		ps.stopOrphanCheck()
+	}
+}
+
+// autoDeletablePostsFilter matches the user's published, unpinned posts
+// created before cutoff
+func autoDeletablePostsFilter(userID primitive.ObjectID, cutoff time.Time) bson.M {
+	return repository.NotDeleted(bson.M{
+		"user_id":      userID,
+		"is_published": true,
+		"is_pinned":    bson.M{"$ne": true},
+		"created_at":   bson.M{"$lt": cutoff},
+	})
+}
+
 func (ps *PostService) updateUserPostCount(userID primitive.ObjectID, increment bool) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -803,9 +2060,43 @@ func (ps *PostService) incrementViewCount(postID primitive.ObjectID) {
 	})
 }
 
-func (ps *PostService) createHashtagEntries(hashtags []string, postID primitive.ObjectID) {
-	// This would integrate with hashtag service to create/update hashtag entries
-	// Implementation depends on hashtag tracking requirements
+// createHashtagEntries counts a new post towards its hashtags, creating and
+// categorizing the hashtags used for the first time
+func (ps *PostService) createHashtagEntries(hashtags []string, postID, userID primitive.ObjectID) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	seen := make(map[string]bool)
+	for _, tag := range hashtags {
+		hashtag := models.Hashtag{Tag: tag}
+		hashtag.NormalizeTag()
+		if seen[hashtag.NormalizedTag] || !models.IsValidHashtag(hashtag.NormalizedTag) {
+			continue
+		}
+		seen[hashtag.NormalizedTag] = true
+
+		now := time.Now()
+		_, err := ps.db.Collection("hashtags").UpdateOne(ctx, bson.M{
+			"normalized_tag": hashtag.NormalizedTag,
+		}, bson.M{
+			"$setOnInsert": bson.M{
+				"tag":              hashtag.Tag,
+				"display_tag":      hashtag.DisplayTag,
+				"is_blocked":       false,
+				"first_used_by":    userID,
+				"first_used_at":    now,
+				"first_used_in":    "post",
+				"first_used_in_id": postID,
+				"category":         ps.hashtagCategorizer.Categorize(hashtag.NormalizedTag),
+				"created_at":       now,
+			},
+			"$inc": bson.M{"posts_count": 1, "total_usage": 1},
+			"$set": bson.M{"updated_at": now},
+		}, options.Update().SetUpsert(true))
+		if err != nil {
+			log.Printf("Failed to record hashtag #%s for post %s: %v", hashtag.NormalizedTag, postID.Hex(), err)
+		}
+	}
 }
 
 func (ps *PostService) createMentionNotifications(authorID, postID primitive.ObjectID, mentionedUsers []primitive.ObjectID) {
@@ -813,23 +2104,6 @@ func (ps *PostService) createMentionNotifications(authorID, postID primitive.Obj
 	// Implementation depends on notification system
 }
 
-func extractHashtagsFromText(text string) []string {
-	var hashtags []string
-	words := strings.Fields(text)
-
-	for _, word := range words {
-		if strings.HasPrefix(word, "#") && len(word) > 1 {
-			hashtag := strings.TrimPrefix(word, "#")
-			hashtag = strings.TrimRight(hashtag, ".,!?;:")
-			if len(hashtag) > 0 {
-				hashtags = append(hashtags, hashtag)
-			}
-		}
-	}
-
-	return hashtags
-}
-
 func convertPollOptions(reqOptions []models.CreatePollOption) []models.PollOption {
 	var options []models.PollOption
 	for _, opt := range reqOptions {
@@ -843,5 +2117,171 @@ func convertPollOptions(reqOptions []models.CreatePollOption) []models.PollOptio
 	return options
 }
 func (us *PostService) GetCollection() *mongo.Collection {
-    return us.collection
-}
\ No newline at end of file
+	return us.collection
+}
+
+// duplicateContentHash returns the fingerprint hash compared for duplicate
+// detection, or "" when the content is too short, exempt or detection is off
+func (ps *PostService) duplicateContentHash(content string) string {
+	if ps.duplicatePolicy.Window <= 0 {
+		return ""
+	}
+
+	normalized := models.NormalizeContent(content)
+	if utf8.RuneCountInString(normalized) < ps.duplicatePolicy.MinLength || ps.exemptContent[normalized] {
+		return ""
+	}
+
+	return models.ContentHash(normalized)
+}
+
+// checkSelfDuplicate returns an error when the user published the same content within the window
+func (ps *PostService) checkSelfDuplicate(ctx context.Context, userID primitive.ObjectID, contentHash string) error {
+	var previous models.ContentFingerprint
+	err := ps.fingerprintCollection.FindOne(ctx, bson.M{
+		"hash":       contentHash,
+		"user_id":    userID,
+		"created_at": bson.M{"$gte": time.Now().Add(-ps.duplicatePolicy.Window)},
+	}, options.FindOne().SetSort(bson.M{"created_at": -1})).Decode(&previous)
+
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		// Don't block posting when the check itself fails
+		log.Printf("Failed to check for duplicate post content: %v", err)
+		return nil
+	}
+
+	retryAfter := time.Until(previous.CreatedAt.Add(ps.duplicatePolicy.Window))
+	return fmt.Errorf("duplicate content: you already posted this recently. Edit your earlier post or try again in %s", describeDuration(retryAfter))
+}
+
+// recordContentFingerprint stores the content hash of a new post for the duplicate window
+func (ps *PostService) recordContentFingerprint(ctx context.Context, userID, postID primitive.ObjectID, contentHash string) {
+	now := time.Now()
+	fingerprint := &models.ContentFingerprint{
+		Hash:      contentHash,
+		UserID:    userID,
+		PostID:    postID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ps.duplicatePolicy.Window),
+	}
+
+	if _, err := ps.fingerprintCollection.InsertOne(ctx, fingerprint); err != nil {
+		log.Printf("Failed to record content fingerprint for post %s: %v", postID.Hex(), err)
+	}
+}
+
+// checkCoordinatedSpam reports every post carrying the content once enough
+// different accounts have posted it within the window. Each post is claimed
+// before it is reported so concurrent checks don't file it twice.
+func (ps *PostService) checkCoordinatedSpam(contentHash string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	window := bson.M{
+		"hash":       contentHash,
+		"created_at": bson.M{"$gte": time.Now().Add(-ps.duplicatePolicy.Window)},
+	}
+
+	accounts, err := ps.fingerprintCollection.Distinct(ctx, "user_id", window)
+	if err != nil || len(accounts) < ps.duplicatePolicy.CoordinatedAccounts {
+		return
+	}
+
+	unreported := bson.M{"reported": false}
+	for key, value := range window {
+		unreported[key] = value
+	}
+
+	for {
+		var fingerprint models.ContentFingerprint
+		err := ps.fingerprintCollection.FindOneAndUpdate(ctx, unreported,
+			bson.M{"$set": bson.M{"reported": true}},
+		).Decode(&fingerprint)
+		if err != nil {
+			if err != mongo.ErrNoDocuments {
+				log.Printf("Failed to claim coordinated spam post: %v", err)
+			}
+			return
+		}
+
+		report := &models.Report{
+			TargetType:  "post",
+			TargetID:    fingerprint.PostID,
+			Reason:      models.ReportSpam,
+			Description: fmt.Sprintf("Same content posted by %d accounts within %s", len(accounts), describeDuration(ps.duplicatePolicy.Window)),
+			Category:    "coordinated_spam",
+			Source:      "auto",
+			Evidence: map[string]interface{}{
+				"content_hash": contentHash,
+				"accounts":     len(accounts),
+				"author_id":    fingerprint.UserID.Hex(),
+			},
+		}
+		report.BeforeCreate()
+		report.AutoDetected = true
+		report.Priority = "high"
+
+		if _, err := ps.db.Collection("reports").InsertOne(ctx, report); err != nil {
+			log.Printf("Failed to report coordinated spam post %s: %v", fingerprint.PostID.Hex(), err)
+		}
+	}
+}
+
+// describeDuration renders a duration in whole hours or minutes for messages
+func describeDuration(d time.Duration) string {
+	if d >= time.Hour {
+		hours := int(d.Round(time.Hour) / time.Hour)
+		if hours == 1 {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", hours)
+	}
+
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	if minutes <= 1 {
+		return "1 minute"
+	}
+	return fmt.Sprintf("%d minutes", minutes)
+}
+
+// fillMediaAltText sets empty alt text on post media from the author's media
+// records with the same URL
+func (ps *PostService) fillMediaAltText(ctx context.Context, userID primitive.ObjectID, media []models.MediaInfo) {
+	var urls []string
+	for _, item := range media {
+		if strings.TrimSpace(item.AltText) == "" && item.URL != "" {
+			urls = append(urls, item.URL)
+		}
+	}
+	if len(urls) == 0 {
+		return
+	}
+
+	cursor, err := ps.db.Collection("media").Find(ctx, repository.NotDeleted(bson.M{
+		"uploaded_by": userID,
+		"url":         bson.M{"$in": urls},
+		"alt_text":    bson.M{"$nin": []interface{}{nil, ""}},
+	}), options.Find().SetProjection(bson.M{"url": 1, "alt_text": 1}))
+	if err != nil {
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var records []models.Media
+	if err := cursor.All(ctx, &records); err != nil {
+		return
+	}
+
+	altTexts := make(map[string]string, len(records))
+	for _, record := range records {
+		altTexts[record.URL] = record.AltText
+	}
+	for i := range media {
+		if strings.TrimSpace(media[i].AltText) == "" {
+			media[i].AltText = altTexts[media[i].URL]
+		}
+	}
+}
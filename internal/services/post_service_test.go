@@ -0,0 +1,176 @@
+package services_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newTestPostService(h *testutil.Harness) *services.PostService {
+	return services.NewPostService(h.DB, services.DuplicateContentPolicy{}, services.NewLimitsService(h.DB, time.Minute),
+		models.NewHashtagCategorizer(nil), services.NewLinkBlocklistService(h.DB, services.LinkBlocklistPolicy{CacheTTL: time.Minute}))
+}
+
+func TestCreatePostUpdatesPostCount(t *testing.T) {
+	h := testutil.NewHarness(t)
+	posts := newTestPostService(h)
+	author := h.CreateUser()
+
+	post, err := posts.CreatePost(author.ID, models.CreatePostRequest{
+		Content:     "Hello from the test harness #testing",
+		ContentType: models.ContentTypeText,
+		Type:        "post",
+		Visibility:  models.PrivacyPublic,
+	})
+	if err != nil {
+		t.Fatalf("CreatePost: %v", err)
+	}
+
+	if post.UserID != author.ID || !post.IsPublished {
+		t.Errorf("post author = %s, published = %v, want %s and published", post.UserID.Hex(), post.IsPublished, author.ID.Hex())
+	}
+	if len(post.Hashtags) != 1 || post.Hashtags[0] != "testing" {
+		t.Errorf("hashtags = %v, want [testing]", post.Hashtags)
+	}
+	if got := h.Count("posts", bson.M{"_id": post.ID}); got != 1 {
+		t.Errorf("stored posts = %d, want 1", got)
+	}
+	if got := h.ReloadUser(author.ID).PostsCount; got != 1 {
+		t.Errorf("posts_count = %d, want 1", got)
+	}
+}
+
+func TestScheduledPostDoesNotCountUntilPublished(t *testing.T) {
+	h := testutil.NewHarness(t)
+	posts := newTestPostService(h)
+	author := h.CreateUser()
+
+	scheduledFor := time.Now().Add(time.Hour)
+	post, err := posts.CreatePost(author.ID, models.CreatePostRequest{
+		Content:      "Coming soon",
+		ContentType:  models.ContentTypeText,
+		Type:         "post",
+		ScheduledFor: &scheduledFor,
+	})
+	if err != nil {
+		t.Fatalf("CreatePost: %v", err)
+	}
+
+	if post.IsPublished || !post.IsScheduled {
+		t.Errorf("published = %v, scheduled = %v, want a scheduled unpublished post", post.IsPublished, post.IsScheduled)
+	}
+	if got := h.ReloadUser(author.ID).PostsCount; got != 0 {
+		t.Errorf("posts_count = %d, want 0", got)
+	}
+}
+
+func TestDeletePostUpdatesPostCount(t *testing.T) {
+	h := testutil.NewHarness(t)
+	posts := newTestPostService(h)
+	author := h.CreateUser()
+	other := h.CreateUser()
+
+	post, err := posts.CreatePost(author.ID, models.CreatePostRequest{
+		Content:     "Short-lived",
+		ContentType: models.ContentTypeText,
+		Type:        "post",
+	})
+	if err != nil {
+		t.Fatalf("CreatePost: %v", err)
+	}
+
+	if err := posts.DeletePost(post.ID, other.ID); err == nil {
+		t.Error("another user deleted the post")
+	}
+
+	if err := posts.DeletePost(post.ID, author.ID); err != nil {
+		t.Fatalf("DeletePost: %v", err)
+	}
+	if got := h.Count("posts", bson.M{"_id": post.ID, "deleted_at": bson.M{"$exists": true}}); got != 1 {
+		t.Errorf("soft-deleted posts = %d, want 1", got)
+	}
+	h.Eventually(5*time.Second, func() bool {
+		return h.ReloadUser(author.ID).PostsCount == 0
+	}, "posts_count stayed at %d after deleting the post", h.ReloadUser(author.ID).PostsCount)
+
+	if _, err := posts.GetPostByID(post.ID, &author.ID); err == nil {
+		t.Error("GetPostByID returned the deleted post")
+	}
+}
+
+func TestPostMentionsAreBounded(t *testing.T) {
+	h := testutil.NewHarness(t)
+	posts := newTestPostService(h)
+	author := h.CreateUser()
+
+	mentions := make([]string, 51)
+	for i := range mentions {
+		mentions[i] = primitive.NewObjectID().Hex()
+	}
+
+	var boundErr *models.BoundExceededError
+	_, err := posts.CreatePost(author.ID, models.CreatePostRequest{
+		Content:     "Tagging everyone",
+		ContentType: models.ContentTypeText,
+		Type:        "post",
+		Mentions:    mentions,
+	})
+	if !errors.As(err, &boundErr) || boundErr.Field != "mentions" {
+		t.Errorf("CreatePost with %d mentions: err = %v, want the mentions bound", len(mentions), err)
+	}
+	if got := h.Count("posts", bson.M{"user_id": author.ID}); got != 0 {
+		t.Errorf("posts stored = %d, want 0", got)
+	}
+
+	post := h.CreatePost(author)
+	if _, err := posts.UpdatePost(post.ID, author.ID, models.UpdatePostRequest{Mentions: mentions}); !errors.As(err, &boundErr) {
+		t.Errorf("UpdatePost with %d mentions: err = %v, want the mentions bound", len(mentions), err)
+	}
+	if _, err := posts.UpdatePost(post.ID, author.ID, models.UpdatePostRequest{Mentions: mentions[:50]}); err != nil {
+		t.Errorf("UpdatePost with 50 mentions: %v", err)
+	}
+}
+
+func TestPreviewLeavesOutMentionsBlockedEitherWay(t *testing.T) {
+	h := testutil.NewHarness(t)
+	posts := newTestPostService(h)
+	author := h.CreateUser()
+	blocked := h.CreateUser()
+	blocker := h.CreateUser()
+	friend := h.CreateUser()
+
+	// A block between two other users doesn't hide either from the author
+	for _, block := range [][2]*models.User{{author, blocked}, {blocker, author}, {blocker, friend}} {
+		if _, err := h.DB.Collection("blocked_users").InsertOne(h.Context(), bson.M{
+			"blocker_id": block[0].ID,
+			"blocked_id": block[1].ID,
+			"is_active":  true,
+		}); err != nil {
+			t.Fatalf("inserting block: %v", err)
+		}
+	}
+
+	preview, err := posts.PreviewPost(author.ID, models.PostPreviewRequest{
+		Content: "@" + blocked.Username + " @" + blocker.Username + " @" + friend.Username,
+	})
+	if err != nil {
+		t.Fatalf("PreviewPost: %v", err)
+	}
+
+	resolved := make(map[string]bool)
+	for _, mention := range preview.Mentions {
+		resolved[mention.Username] = mention.Resolved
+	}
+	want := map[string]bool{blocked.Username: false, blocker.Username: false, friend.Username: true}
+	if !reflect.DeepEqual(resolved, want) {
+		t.Errorf("resolved mentions = %v, want %v", resolved, want)
+	}
+}
@@ -0,0 +1,309 @@
+// internal/services/presence_service.go
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+	"social-media-api/internal/websocket"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Presence events pushed over the chat WebSocket to members of conversations
+// the user shares
+const (
+	PresenceEventOnline  = "participant.online"
+	PresenceEventOffline = "participant.offline"
+)
+
+// PresencePolicy decides how a user's presence is derived and announced. A
+// user with a chat WebSocket connection is online; otherwise LastActiveAt
+// within OnlineWindow counts as online and within AwayWindow as away.
+// Transitions are announced once they have held for Debounce, so a flapping
+// connection produces no events. IndexTTL bounds how long the cached
+// user → conversations index is trusted without an invalidation.
+type PresencePolicy struct {
+	OnlineWindow time.Duration
+	AwayWindow   time.Duration
+	Debounce     time.Duration
+	IndexTTL     time.Duration
+}
+
+// PresenceService resolves participant presence and fans presence transitions
+// out to the members of each user's conversations
+type PresenceService struct {
+	conversationCollection *mongo.Collection
+	userCollection         *mongo.Collection
+	hub                    *websocket.Hub
+	policy                 PresencePolicy
+
+	mu        sync.Mutex
+	pending   map[primitive.ObjectID]*time.Timer // Debounced transitions not yet announced
+	announced map[primitive.ObjectID]bool        // Users last announced online
+	index     map[primitive.ObjectID]*presenceIndexEntry
+	members   map[primitive.ObjectID][]primitive.ObjectID // Conversation ID → accepted members
+}
+
+// presenceIndexEntry caches the conversations a user is an accepted member of
+type presenceIndexEntry struct {
+	conversationIDs []primitive.ObjectID
+	loadedAt        time.Time
+}
+
+// NewPresenceService creates the presence service. hub may be nil, in which
+// case presence falls back to LastActiveAt and no events are pushed.
+func NewPresenceService(db *mongo.Database, hub *websocket.Hub, policy PresencePolicy) *PresenceService {
+	return &PresenceService{
+		conversationCollection: db.Collection("conversations"),
+		userCollection:         db.Collection("users"),
+		hub:                    hub,
+		policy:                 policy,
+		pending:                make(map[primitive.ObjectID]*time.Timer),
+		announced:              make(map[primitive.ObjectID]bool),
+		index:                  make(map[primitive.ObjectID]*presenceIndexEntry),
+		members:                make(map[primitive.ObjectID][]primitive.ObjectID),
+	}
+}
+
+// ResolveParticipants reports each user's presence as seen by the viewer,
+// leaving status and last seen out for users who hide their online status
+func (ps *PresenceService) ResolveParticipants(users []models.User, roles map[primitive.ObjectID]string, viewerID primitive.ObjectID) []models.ParticipantPresence {
+	now := time.Now()
+	participants := make([]models.ParticipantPresence, 0, len(users))
+	for i := range users {
+		user := &users[i]
+		participant := models.ParticipantPresence{
+			User: user.ToUserResponse(),
+			Role: roles[user.ID],
+		}
+		participant.User.OnlineStatus = ""
+		participant.User.LastActiveAt = nil
+
+		if user.PrivacySettings.ShowOnlineStatus || user.ID == viewerID {
+			participant.Status = ps.status(user, now)
+			participant.LastSeenAt = user.LastActiveAt
+		}
+		participants = append(participants, participant)
+	}
+	return participants
+}
+
+// status derives a user's presence, trusting the connection registry first
+func (ps *PresenceService) status(user *models.User, now time.Time) string {
+	if ps.hub != nil && ps.hub.IsUserOnline(user.ID.Hex()) {
+		return models.PresenceOnline
+	}
+	if user.LastActiveAt == nil {
+		return models.PresenceOffline
+	}
+
+	idle := now.Sub(*user.LastActiveAt)
+	switch {
+	case idle <= ps.policy.OnlineWindow:
+		return models.PresenceOnline
+	case idle <= ps.policy.AwayWindow:
+		return models.PresenceAway
+	default:
+		return models.PresenceOffline
+	}
+}
+
+// UserConnected is called by the hub when a user's first session connects
+func (ps *PresenceService) UserConnected(userID primitive.ObjectID) {
+	ps.touch(userID, models.PresenceOnline)
+	ps.schedule(userID, true)
+}
+
+// UserDisconnected is called by the hub when a user's last session disconnects
+func (ps *PresenceService) UserDisconnected(userID primitive.ObjectID) {
+	ps.touch(userID, models.PresenceOffline)
+	ps.schedule(userID, false)
+}
+
+// schedule announces the transition once it has held for the debounce window.
+// A transition back to the announced state before then cancels it.
+func (ps *PresenceService) schedule(userID primitive.ObjectID, online bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if timer, ok := ps.pending[userID]; ok {
+		timer.Stop()
+		delete(ps.pending, userID)
+	}
+	if ps.announced[userID] == online {
+		return
+	}
+
+	ps.pending[userID] = time.AfterFunc(ps.policy.Debounce, func() {
+		ps.announce(userID, online)
+	})
+}
+
+// announce pushes a settled transition to every member of the user's conversations
+func (ps *PresenceService) announce(userID primitive.ObjectID, online bool) {
+	ps.mu.Lock()
+	delete(ps.pending, userID)
+	if ps.announced[userID] == online {
+		ps.mu.Unlock()
+		return
+	}
+	if online {
+		ps.announced[userID] = true
+	} else {
+		delete(ps.announced, userID)
+	}
+	ps.mu.Unlock()
+
+	if ps.hub == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var user models.User
+	err := ps.userCollection.FindOne(ctx, bson.M{"_id": userID},
+		options.FindOne().SetProjection(bson.M{"privacy_settings": 1, "last_active_at": 1})).Decode(&user)
+	if err != nil || !user.PrivacySettings.ShowOnlineStatus {
+		return
+	}
+
+	audience, err := ps.audience(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to load presence audience for user %s: %v", userID.Hex(), err)
+		return
+	}
+	if len(audience) == 0 {
+		return
+	}
+
+	eventType, status := PresenceEventOffline, models.PresenceOffline
+	if online {
+		eventType, status = PresenceEventOnline, models.PresenceOnline
+	}
+
+	ps.hub.BroadcastToUsers(audience, websocket.WebSocketMessage{
+		Type: eventType,
+		Data: map[string]interface{}{
+			"user_id":      userID.Hex(),
+			"status":       status,
+			"last_seen_at": user.LastActiveAt,
+		},
+	}, userID.Hex())
+}
+
+// audience returns the members of every conversation the user shares, from
+// the cached index when it is fresh and with one query otherwise
+func (ps *PresenceService) audience(ctx context.Context, userID primitive.ObjectID) ([]string, error) {
+	ps.mu.Lock()
+	entry := ps.index[userID]
+	fresh := entry != nil && time.Since(entry.loadedAt) < ps.policy.IndexTTL
+	ps.mu.Unlock()
+
+	if !fresh {
+		if err := ps.loadIndex(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	seen := map[primitive.ObjectID]bool{userID: true}
+	var audience []string
+	if entry := ps.index[userID]; entry != nil {
+		for _, conversationID := range entry.conversationIDs {
+			for _, memberID := range ps.members[conversationID] {
+				if !seen[memberID] {
+					seen[memberID] = true
+					audience = append(audience, memberID.Hex())
+				}
+			}
+		}
+	}
+	return audience, nil
+}
+
+// loadIndex caches the user's conversations and their accepted members.
+// Members with a pending message request neither see nor reveal presence.
+func (ps *PresenceService) loadIndex(ctx context.Context, userID primitive.ObjectID) error {
+	cursor, err := ps.conversationCollection.Find(ctx, repository.NotDeleted(bson.M{
+		"participants": userID,
+		"is_active":    true,
+	}), options.Find().SetProjection(bson.M{"participants": 1, "participant_info": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var conversations []models.Conversation
+	if err := cursor.All(ctx, &conversations); err != nil {
+		return err
+	}
+
+	entry := &presenceIndexEntry{loadedAt: time.Now()}
+	members := make(map[primitive.ObjectID][]primitive.ObjectID, len(conversations))
+	for _, conversation := range conversations {
+		if conversation.IsPendingRequest(userID) {
+			continue
+		}
+		var accepted []primitive.ObjectID
+		for _, participantID := range conversation.Participants {
+			if !conversation.IsPendingRequest(participantID) {
+				accepted = append(accepted, participantID)
+			}
+		}
+		entry.conversationIDs = append(entry.conversationIDs, conversation.ID)
+		members[conversation.ID] = accepted
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.index[userID] = entry
+	for conversationID, accepted := range members {
+		ps.members[conversationID] = accepted
+	}
+	return nil
+}
+
+// InvalidateConversation drops cached membership for a conversation whose
+// members changed, along with the index entries of everyone in it before and
+// after the change
+func (ps *PresenceService) InvalidateConversation(conversationID primitive.ObjectID, userIDs ...primitive.ObjectID) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	for _, memberID := range ps.members[conversationID] {
+		delete(ps.index, memberID)
+	}
+	for _, userID := range userIDs {
+		delete(ps.index, userID)
+	}
+	delete(ps.members, conversationID)
+}
+
+// touch records the connection transition on the user so last seen stays
+// accurate between REST requests
+func (ps *PresenceService) touch(userID primitive.ObjectID, status string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	_, err := ps.userCollection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+		"$set": bson.M{
+			"online_status":  status,
+			"last_active_at": now,
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to update presence for user %s: %v", userID.Hex(), err)
+	}
+}
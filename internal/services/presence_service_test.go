@@ -0,0 +1,294 @@
+package services_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+	"social-media-api/internal/websocket"
+
+	gorilla "github.com/gorilla/websocket"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const testPresenceDebounce = 300 * time.Millisecond
+
+// presenceRig serves a chat hub over a test server so users connect real
+// WebSocket sessions, with a PresenceService listening on the hub
+type presenceRig struct {
+	h             *testutil.Harness
+	hub           *websocket.Hub
+	presence      *services.PresenceService
+	conversations *services.ConversationService
+	server        *httptest.Server
+}
+
+func newPresenceRig(h *testutil.Harness) *presenceRig {
+	hub := websocket.NewHub(nil)
+	go hub.Run()
+
+	presence := services.NewPresenceService(h.DB, hub, services.PresencePolicy{
+		OnlineWindow: time.Minute,
+		AwayWindow:   10 * time.Minute,
+		Debounce:     testPresenceDebounce,
+		IndexTTL:     time.Hour, // Long enough that only invalidation refreshes the index
+	})
+	hub.SetPresenceListener(presence)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, err := primitive.ObjectIDFromHex(r.URL.Query().Get("user"))
+		if err != nil {
+			http.Error(w, "bad user", http.StatusBadRequest)
+			return
+		}
+		websocket.ServeWS(hub, w, r, userID, userID.Hex(), "127.0.0.1", nil)
+	}))
+	h.T.Cleanup(func() {
+		hub.Shutdown()
+		server.Close()
+	})
+
+	return &presenceRig{
+		h:             h,
+		hub:           hub,
+		presence:      presence,
+		conversations: services.NewConversationService(10, services.ConversationActivityPolicy{}, models.AccountMaturityPolicy{}, presence),
+		server:        server,
+	}
+}
+
+// presenceSession is one user's chat WebSocket connection
+type presenceSession struct {
+	conn   *gorilla.Conn
+	events chan websocket.WebSocketMessage
+}
+
+// connect opens a session for the user and waits until the hub has it
+func (r *presenceRig) connect(user *models.User) *presenceSession {
+	r.h.T.Helper()
+
+	url := "ws" + strings.TrimPrefix(r.server.URL, "http") + "/?user=" + user.ID.Hex()
+	conn, _, err := gorilla.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		r.h.T.Fatalf("connecting %s: %v", user.Username, err)
+	}
+
+	session := &presenceSession{conn: conn, events: make(chan websocket.WebSocketMessage, 100)}
+	go func() {
+		defer close(session.events)
+		for {
+			_, frame, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			// The hub batches queued messages into one frame, newline separated
+			for _, line := range strings.Split(string(frame), "\n") {
+				var message websocket.WebSocketMessage
+				if json.Unmarshal([]byte(line), &message) == nil {
+					session.events <- message
+				}
+			}
+		}
+	}()
+
+	r.h.Eventually(5*time.Second, func() bool {
+		return r.hub.IsUserOnline(user.ID.Hex())
+	}, "%s never registered with the hub", user.Username)
+	return session
+}
+
+// disconnect closes the session and waits until the hub has dropped the user
+func (r *presenceRig) disconnect(user *models.User, session *presenceSession) {
+	r.h.T.Helper()
+
+	session.conn.Close()
+	r.h.Eventually(5*time.Second, func() bool {
+		return !r.hub.IsUserOnline(user.ID.Hex())
+	}, "%s never unregistered from the hub", user.Username)
+}
+
+// expectPresence waits for a presence event about the user
+func (s *presenceSession) expectPresence(t *testing.T, eventType string, user *models.User, timeout time.Duration) {
+	t.Helper()
+
+	deadline := time.After(timeout)
+	for {
+		select {
+		case message, ok := <-s.events:
+			if !ok {
+				t.Fatalf("connection closed waiting for %s of %s", eventType, user.Username)
+			}
+			if message.Data["user_id"] != user.ID.Hex() {
+				continue
+			}
+			if message.Type != eventType {
+				t.Fatalf("got %s of %s, want %s", message.Type, user.Username, eventType)
+			}
+			return
+		case <-deadline:
+			t.Fatalf("no %s of %s within %s", eventType, user.Username, timeout)
+		}
+	}
+}
+
+// expectNoPresence checks no presence event about the user arrives for a while
+func (s *presenceSession) expectNoPresence(t *testing.T, user *models.User, wait time.Duration) {
+	t.Helper()
+
+	deadline := time.After(wait)
+	for {
+		select {
+		case message, ok := <-s.events:
+			if !ok {
+				return
+			}
+			if message.Data["user_id"] == user.ID.Hex() && strings.HasPrefix(message.Type, "participant.") {
+				t.Errorf("got %s of %s, want no presence event", message.Type, user.Username)
+			}
+		case <-deadline:
+			return
+		}
+	}
+}
+
+// createGroup starts a group conversation between the creator and members
+func (r *presenceRig) createGroup(creator *models.User, members ...*models.User) *models.Conversation {
+	r.h.T.Helper()
+
+	var ids []string
+	for _, member := range members {
+		ids = append(ids, member.ID.Hex())
+	}
+	conversation, err := r.conversations.CreateConversation(creator.ID, models.CreateConversationRequest{
+		Type:           "group",
+		Title:          "Presence",
+		ParticipantIDs: ids,
+	})
+	if err != nil {
+		r.h.T.Fatalf("CreateConversation: %v", err)
+	}
+	return conversation
+}
+
+func TestPresenceDebouncesFlappingConnections(t *testing.T) {
+	h := testutil.NewHarness(t)
+	rig := newPresenceRig(h)
+
+	flapper := h.CreateUser()
+	observer := h.CreateUser()
+	rig.createGroup(flapper, observer)
+
+	watching := rig.connect(observer)
+
+	// Connections that drop within the debounce window announce nothing
+	for i := 0; i < 3; i++ {
+		rig.disconnect(flapper, rig.connect(flapper))
+	}
+	watching.expectNoPresence(t, flapper, 3*testPresenceDebounce)
+
+	connectedAt := time.Now()
+	session := rig.connect(flapper)
+	watching.expectPresence(t, services.PresenceEventOnline, flapper, 5*time.Second)
+	if elapsed := time.Since(connectedAt); elapsed < testPresenceDebounce {
+		t.Errorf("online announced after %s, before the %s debounce", elapsed, testPresenceDebounce)
+	}
+
+	// A reconnect within the window doesn't announce offline either
+	rig.disconnect(flapper, session)
+	session = rig.connect(flapper)
+	watching.expectNoPresence(t, flapper, 3*testPresenceDebounce)
+
+	rig.disconnect(flapper, session)
+	watching.expectPresence(t, services.PresenceEventOffline, flapper, 5*time.Second)
+}
+
+func TestPresenceRespectsShowOnlineStatus(t *testing.T) {
+	h := testutil.NewHarness(t)
+	rig := newPresenceRig(h)
+
+	hidden := h.CreateUser()
+	observer := h.CreateUser()
+	conversation := rig.createGroup(hidden, observer)
+
+	if _, err := h.DB.Collection("users").UpdateOne(h.Context(),
+		bson.M{"_id": hidden.ID},
+		bson.M{"$set": bson.M{"privacy_settings.show_online_status": false}},
+	); err != nil {
+		t.Fatalf("hiding online status: %v", err)
+	}
+
+	watching := rig.connect(observer)
+	rig.connect(hidden)
+	watching.expectNoPresence(t, hidden, 3*testPresenceDebounce)
+
+	participants := func(viewer *models.User) map[string]models.ParticipantPresence {
+		t.Helper()
+		list, err := rig.conversations.GetParticipantPresence(conversation.ID, viewer.ID)
+		if err != nil {
+			t.Fatalf("GetParticipantPresence: %v", err)
+		}
+		byID := make(map[string]models.ParticipantPresence, len(list))
+		for _, participant := range list {
+			if participant.User.OnlineStatus != "" || participant.User.LastActiveAt != nil {
+				t.Errorf("%s carries presence in the embedded user", participant.User.Username)
+			}
+			byID[participant.User.ID] = participant
+		}
+		return byID
+	}
+
+	seen := participants(observer)
+	if got := seen[hidden.ID.Hex()]; got.Status != "" || got.LastSeenAt != nil {
+		t.Errorf("hidden user seen by others = status %q, last seen %v, want neither", got.Status, got.LastSeenAt)
+	}
+	if got := seen[observer.ID.Hex()]; got.Status != models.PresenceOnline {
+		t.Errorf("connected observer status = %q, want online from the connection registry", got.Status)
+	}
+
+	if got := participants(hidden)[hidden.ID.Hex()]; got.Status != models.PresenceOnline || got.LastSeenAt == nil {
+		t.Errorf("hidden user's own entry = status %q, last seen %v, want their presence", got.Status, got.LastSeenAt)
+	}
+}
+
+func TestPresenceIndexFollowsMembershipChanges(t *testing.T) {
+	h := testutil.NewHarness(t)
+	rig := newPresenceRig(h)
+
+	subject := h.CreateUser()
+	member := h.CreateUser()
+	joiner := h.CreateUser()
+	conversation := rig.createGroup(subject, member)
+
+	memberSession := rig.connect(member)
+	joinerSession := rig.connect(joiner)
+
+	// The first announcement caches the subject's audience
+	subjectSession := rig.connect(subject)
+	memberSession.expectPresence(t, services.PresenceEventOnline, subject, 5*time.Second)
+	joinerSession.expectNoPresence(t, subject, 3*testPresenceDebounce)
+
+	if err := rig.conversations.AddParticipants(conversation.ID, subject.ID, models.AddParticipantsRequest{
+		ParticipantIDs: []string{joiner.ID.Hex()},
+	}); err != nil {
+		t.Fatalf("AddParticipants: %v", err)
+	}
+
+	rig.disconnect(subject, subjectSession)
+	memberSession.expectPresence(t, services.PresenceEventOffline, subject, 5*time.Second)
+	joinerSession.expectPresence(t, services.PresenceEventOffline, subject, 5*time.Second)
+
+	if err := rig.conversations.LeaveConversation(conversation.ID, joiner.ID); err != nil {
+		t.Fatalf("LeaveConversation: %v", err)
+	}
+
+	rig.connect(subject)
+	memberSession.expectPresence(t, services.PresenceEventOnline, subject, 5*time.Second)
+	joinerSession.expectNoPresence(t, subject, 3*testPresenceDebounce)
+}
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -16,6 +17,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type PushService struct {
@@ -66,6 +68,7 @@ type FCMResult struct {
 type PushToken struct {
 	models.BaseModel `bson:",inline"`
 	UserID           primitive.ObjectID `json:"user_id" bson:"user_id"`
+	DeviceGroupID    string             `json:"device_group_id,omitempty" bson:"device_group_id,omitempty"` // Device group of the session that registered the token
 	Token            string             `json:"token" bson:"token"`
 	Platform         string             `json:"platform" bson:"platform"` // ios, android, web
 	DeviceInfo       string             `json:"device_info" bson:"device_info"`
@@ -189,56 +192,89 @@ func (ps *PushService) SendBulkPushNotification(userIDs []primitive.ObjectID, ti
 	return nil
 }
 
-// RegisterPushToken registers a new push token for a user
-func (ps *PushService) RegisterPushToken(userID primitive.ObjectID, token, platform, deviceInfo string) error {
+// RegisterPushToken registers the push token of a device for one account.
+// Tokens are kept per account and device: every account signed in on the
+// device holds its own entry for the same token, tagged with the device group
+// of the session, so signing one account out leaves the others' pushes alone.
+// Entries of the token from an earlier device group are stale (the app was
+// reinstalled or signed out) and are switched off.
+func (ps *PushService) RegisterPushToken(userID primitive.ObjectID, sessionID, token, platform, deviceInfo string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Check if token already exists
-	var existingToken PushToken
-	err := ps.tokenCollection.FindOne(ctx, bson.M{
-		"token": token,
-	}).Decode(&existingToken)
-
-	if err == nil {
-		// Token exists, update it
-		update := bson.M{
-			"$set": bson.M{
-				"user_id":      userID,
-				"platform":     platform,
-				"device_info":  deviceInfo,
-				"is_active":    true,
-				"last_used_at": time.Now(),
-				"updated_at":   time.Now(),
-			},
+	var session Session
+	err := ps.db.Collection("sessions").FindOne(ctx, bson.M{
+		"session_id": sessionID,
+		"user_id":    userID,
+		"is_active":  true,
+	}).Decode(&session)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.New("invalid session")
 		}
-		_, err = ps.tokenCollection.UpdateOne(ctx, bson.M{"_id": existingToken.ID}, update)
-		return err
-	} else if err != mongo.ErrNoDocuments {
 		return err
 	}
+	deviceGroupID := session.DeviceGroupID
+	if deviceGroupID == "" {
+		deviceGroupID = session.SessionID
+	}
 
-	// Create new token
-	pushToken := &PushToken{
-		UserID:     userID,
-		Token:      token,
-		Platform:   platform,
-		DeviceInfo: deviceInfo,
-		IsActive:   true,
-		LastUsedAt: time.Now(),
+	err = deactivatePushTokens(ctx, ps.db, bson.M{
+		"token":           token,
+		"device_group_id": bson.M{"$ne": deviceGroupID},
+	})
+	if err != nil {
+		return err
 	}
-	pushToken.BeforeCreate()
 
-	_, err = ps.tokenCollection.InsertOne(ctx, pushToken)
+	now := time.Now()
+	_, err = ps.tokenCollection.UpdateOne(ctx, bson.M{
+		"token":   token,
+		"user_id": userID,
+	}, bson.M{
+		"$set": bson.M{
+			"device_group_id": deviceGroupID,
+			"platform":        platform,
+			"device_info":     deviceInfo,
+			"is_active":       true,
+			"last_used_at":    now,
+			"updated_at":      now,
+		},
+		"$setOnInsert": bson.M{
+			"created_at": now,
+		},
+	}, options.Update().SetUpsert(true))
+	return err
+}
+
+// deactivatePushTokens switches off the push tokens matching filter
+func deactivatePushTokens(ctx context.Context, db *mongo.Database, filter bson.M) error {
+	filter["is_active"] = true
+	_, err := db.Collection("push_tokens").UpdateMany(ctx, filter, bson.M{
+		"$set": bson.M{
+			"is_active":  false,
+			"updated_at": time.Now(),
+		},
+	})
 	return err
 }
 
-// RemovePushToken removes a push token
+// RemovePushToken removes a push token for every account on the device.
+// It is used when the push provider reports the token as invalid.
 func (ps *PushService) RemovePushToken(token string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	_, err := ps.tokenCollection.DeleteOne(ctx, bson.M{"token": token})
+	_, err := ps.tokenCollection.DeleteMany(ctx, bson.M{"token": token})
+	return err
+}
+
+// RemoveUserPushToken removes the device's push token for one account only
+func (ps *PushService) RemoveUserPushToken(userID primitive.ObjectID, token string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := ps.tokenCollection.DeleteOne(ctx, bson.M{"token": token, "user_id": userID})
 	return err
 }
 
@@ -458,6 +494,7 @@ func (ps *PushService) createPushData(notification *models.Notification) map[str
 		"body":  notification.Message,
 		"data": map[string]interface{}{
 			"notification_id": notification.ID.Hex(),
+			"recipient_id":    notification.RecipientID.Hex(), // Lets a multi-account device route the push to the right account
 			"type":            string(notification.Type),
 			"target_type":     notification.TargetType,
 			"target_url":      notification.TargetURL,
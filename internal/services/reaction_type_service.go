@@ -0,0 +1,290 @@
+// internal/services/reaction_type_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// reactionCatalogTTL bounds how long another instance's admin changes take to show up
+const reactionCatalogTTL = 5 * time.Minute
+
+// reactionCatalogState tracks when the in-memory reaction catalog was last loaded
+var reactionCatalogState struct {
+	sync.Mutex
+	loadedAt time.Time
+}
+
+type ReactionTypeService struct {
+	collection *mongo.Collection
+	db         *mongo.Database
+}
+
+func NewReactionTypeService(db *mongo.Database) *ReactionTypeService {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := loadReactionCatalog(ctx, db); err != nil {
+		log.Printf("Failed to load reaction types, using built-in set: %v", err)
+	}
+
+	return &ReactionTypeService{
+		collection: db.Collection("reaction_types"),
+		db:         db,
+	}
+}
+
+// GetAvailableReactions returns the reactions clients should offer in the picker
+func (rs *ReactionTypeService) GetAvailableReactions() ([]models.ReactionDefinition, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := loadReactionCatalog(ctx, rs.db); err != nil {
+		return nil, err
+	}
+
+	return models.GetAvailableReactions(time.Now()), nil
+}
+
+// GetReactionTypes retrieves all configured reactions for admins
+func (rs *ReactionTypeService) GetReactionTypes(includeRetired bool) ([]models.ReactionDefinition, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := repository.NotDeleted()
+	if !includeRetired {
+		filter["is_retired"] = false
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "display_order", Value: 1}, {Key: "created_at", Value: 1}})
+	cursor, err := rs.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	reactions := []models.ReactionDefinition{}
+	if err := cursor.All(ctx, &reactions); err != nil {
+		return nil, err
+	}
+
+	return reactions, nil
+}
+
+// CreateReactionType adds a new reaction to the picker
+func (rs *ReactionTypeService) CreateReactionType(adminID primitive.ObjectID, req models.CreateReactionTypeRequest) (*models.ReactionDefinition, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	key := strings.ToLower(strings.TrimSpace(req.Key))
+	if !models.ReactionKeyPattern.MatchString(key) {
+		return nil, errors.New("invalid reaction key: use lowercase letters, digits and underscores")
+	}
+
+	if req.Emoji == "" && req.AssetURL == "" {
+		return nil, errors.New("invalid reaction: an emoji or asset URL is required")
+	}
+
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return nil, errors.New("invalid reaction: end time must be after start time")
+	}
+
+	reaction := &models.ReactionDefinition{
+		Key:          models.ReactionType(key),
+		Name:         strings.TrimSpace(req.Name),
+		Emoji:        req.Emoji,
+		AssetURL:     req.AssetURL,
+		DisplayOrder: req.DisplayOrder,
+		StartsAt:     req.StartsAt,
+		EndsAt:       req.EndsAt,
+		CreatedBy:    &adminID,
+	}
+	reaction.BeforeCreate()
+
+	result, err := rs.collection.InsertOne(ctx, reaction)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, errors.New("reaction key already exists")
+		}
+		return nil, err
+	}
+
+	reaction.ID = result.InsertedID.(primitive.ObjectID)
+	invalidateReactionCatalog()
+
+	return reaction, nil
+}
+
+// UpdateReactionType updates the display settings and schedule of a reaction
+func (rs *ReactionTypeService) UpdateReactionType(reactionID primitive.ObjectID, req models.UpdateReactionTypeRequest) (*models.ReactionDefinition, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var reaction models.ReactionDefinition
+	if err := rs.collection.FindOne(ctx, repository.ByID(reactionID)).Decode(&reaction); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("reaction not found")
+		}
+		return nil, err
+	}
+
+	update := bson.M{"updated_at": time.Now()}
+	if req.Name != nil {
+		reaction.Name = strings.TrimSpace(*req.Name)
+		update["name"] = reaction.Name
+	}
+	if req.Emoji != nil {
+		reaction.Emoji = *req.Emoji
+		update["emoji"] = reaction.Emoji
+	}
+	if req.AssetURL != nil {
+		reaction.AssetURL = *req.AssetURL
+		update["asset_url"] = reaction.AssetURL
+	}
+	if req.DisplayOrder != nil {
+		reaction.DisplayOrder = *req.DisplayOrder
+		update["display_order"] = reaction.DisplayOrder
+	}
+	if req.StartsAt != nil {
+		reaction.StartsAt = req.StartsAt
+		update["starts_at"] = reaction.StartsAt
+	}
+	if req.EndsAt != nil {
+		reaction.EndsAt = req.EndsAt
+		update["ends_at"] = reaction.EndsAt
+	}
+
+	if reaction.Emoji == "" && reaction.AssetURL == "" {
+		return nil, errors.New("invalid reaction: an emoji or asset URL is required")
+	}
+	if reaction.StartsAt != nil && reaction.EndsAt != nil && !reaction.EndsAt.After(*reaction.StartsAt) {
+		return nil, errors.New("invalid reaction: end time must be after start time")
+	}
+
+	if _, err := rs.collection.UpdateOne(ctx, bson.M{"_id": reactionID}, bson.M{"$set": update}); err != nil {
+		return nil, err
+	}
+
+	invalidateReactionCatalog()
+	return &reaction, nil
+}
+
+// RetireReactionType removes a reaction from the picker. Existing likes keep it.
+func (rs *ReactionTypeService) RetireReactionType(reactionID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var reaction models.ReactionDefinition
+	if err := rs.collection.FindOne(ctx, repository.NotDeleted(bson.M{
+		"_id":        reactionID,
+		"is_retired": false,
+	})).Decode(&reaction); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.New("reaction not found or already retired")
+		}
+		return err
+	}
+
+	// Likes without an explicit reaction default to "like", so it must stay available
+	if reaction.Key == models.ReactionLike {
+		return errors.New("the default like reaction cannot be retired")
+	}
+
+	now := time.Now()
+	_, err := rs.collection.UpdateOne(ctx, bson.M{"_id": reactionID}, bson.M{
+		"$set": bson.M{
+			"is_retired": true,
+			"retired_at": now,
+			"updated_at": now,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	invalidateReactionCatalog()
+	return nil
+}
+
+// Helper functions
+
+// loadReactionCatalog refreshes the in-memory reaction catalog once it is older than the TTL
+func loadReactionCatalog(ctx context.Context, db *mongo.Database) error {
+	reactionCatalogState.Lock()
+	defer reactionCatalogState.Unlock()
+
+	if !reactionCatalogState.loadedAt.IsZero() && time.Since(reactionCatalogState.loadedAt) < reactionCatalogTTL {
+		return nil
+	}
+
+	cursor, err := db.Collection("reaction_types").Find(ctx, repository.NotDeleted())
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var definitions []models.ReactionDefinition
+	if err := cursor.All(ctx, &definitions); err != nil {
+		return err
+	}
+
+	// Until the collection is seeded the built-in set stays in effect
+	if len(definitions) > 0 {
+		models.SetReactionCatalog(definitions)
+	}
+
+	reactionCatalogState.loadedAt = time.Now()
+	return nil
+}
+
+// invalidateReactionCatalog forces the next lookup to reload reaction types
+func invalidateReactionCatalog() {
+	reactionCatalogState.Lock()
+	reactionCatalogState.loadedAt = time.Time{}
+	reactionCatalogState.Unlock()
+}
+
+// ensureReactionAvailable checks a reaction against the active set before it is written
+func ensureReactionAvailable(ctx context.Context, db *mongo.Database, reactionType models.ReactionType) error {
+	if err := loadReactionCatalog(ctx, db); err != nil {
+		log.Printf("Failed to refresh reaction types: %v", err)
+	}
+
+	if !models.IsReactionAvailable(reactionType, time.Now()) {
+		return fmt.Errorf("invalid reaction type: %s", reactionType)
+	}
+	return nil
+}
+
+// adjustReactionCounts moves a target's per-reaction counters from one reaction to another.
+// Either side may be empty when a like is added or removed.
+func adjustReactionCounts(ctx context.Context, collection *mongo.Collection, targetID primitive.ObjectID, removed, added models.ReactionType) {
+	if removed == added {
+		return
+	}
+
+	inc := bson.M{}
+	if removed != "" {
+		inc["reaction_counts."+string(removed)] = -1
+	}
+	if added != "" {
+		inc["reaction_counts."+string(added)] = 1
+	}
+
+	if _, err := collection.UpdateOne(ctx, bson.M{"_id": targetID}, bson.M{"$inc": inc}); err != nil {
+		log.Printf("Failed to update reaction counts for %s: %v", targetID.Hex(), err)
+	}
+}
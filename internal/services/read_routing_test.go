@@ -0,0 +1,89 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const testReadYourWritesWindow = 300 * time.Millisecond
+
+func TestAuthorReadsOwnPostWhileSecondaryLags(t *testing.T) {
+	h := testutil.NewHarness(t)
+	posts := newTestPostService(h)
+	author := h.CreateUser()
+	other := h.CreateUser()
+
+	h.AssertReadYourWrites(author, other, testReadYourWritesWindow,
+		func() primitive.ObjectID {
+			post, err := posts.CreatePost(author.ID, models.CreatePostRequest{
+				Content:     "Fresh off the primary",
+				ContentType: models.ContentTypeText,
+				Type:        "post",
+				Visibility:  models.PrivacyPublic,
+			})
+			if err != nil {
+				t.Fatalf("CreatePost: %v", err)
+			}
+			// Writes always go to the primary
+			if got := h.Count("posts", bson.M{"_id": post.ID}); got != 1 {
+				t.Errorf("posts on the primary = %d, want 1", got)
+			}
+			return post.ID
+		},
+		func(viewerID *primitive.ObjectID) []primitive.ObjectID {
+			list, err := posts.GetUserPosts(author.ID, viewerID, 20, 0)
+			if err != nil {
+				t.Fatalf("GetUserPosts: %v", err)
+			}
+			var ids []primitive.ObjectID
+			for _, post := range list {
+				ids = append(ids, post.ID)
+			}
+			return ids
+		},
+	)
+}
+
+func TestCommenterReadsOwnCommentWhileSecondaryLags(t *testing.T) {
+	h := testutil.NewHarness(t)
+	comments := services.NewCommentService(services.CommentHoldPolicy{}, services.CommentFloodPolicy{}, 0, 3,
+		services.NewNotificationService(nil, nil), services.NewLinkBlocklistService(h.DB, services.LinkBlocklistPolicy{CacheTTL: time.Minute}))
+	commenter := h.CreateUser()
+	other := h.CreateUser()
+	post := h.CreatePost(h.CreateUser())
+
+	h.AssertReadYourWrites(commenter, other, testReadYourWritesWindow,
+		func() primitive.ObjectID {
+			comment, err := comments.CreateComment(commenter.ID, models.CreateCommentRequest{
+				PostID:      post.ID.Hex(),
+				Content:     "First!",
+				ContentType: models.ContentTypeText,
+			})
+			if err != nil {
+				t.Fatalf("CreateComment: %v", err)
+			}
+			if got := h.Count("comments", bson.M{"_id": comment.ID}); got != 1 {
+				t.Errorf("comments on the primary = %d, want 1", got)
+			}
+			return comment.ID
+		},
+		func(viewerID *primitive.ObjectID) []primitive.ObjectID {
+			list, err := comments.GetPostComments(post.ID, viewerID, "newest", 20, 0)
+			if err != nil {
+				t.Fatalf("GetPostComments: %v", err)
+			}
+			var ids []primitive.ObjectID
+			for _, comment := range list {
+				ids = append(ids, comment.ID)
+			}
+			return ids
+		},
+	)
+}
@@ -4,10 +4,12 @@ package services
 import (
 	"context"
 	"errors"
+	"log"
 	"time"
 
 	"social-media-api/internal/config"
 	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -16,18 +18,22 @@ import (
 )
 
 type ReportService struct {
-	collection     *mongo.Collection
-	userCollection *mongo.Collection
-	postCollection *mongo.Collection
-	db             *mongo.Database
+	collection          *mongo.Collection
+	userCollection      *mongo.Collection
+	postCollection      *mongo.Collection
+	db                  *mongo.Database
+	notificationService *NotificationService
+	warningService      *WarningService
 }
 
-func NewReportService() *ReportService {
+func NewReportService(notificationService *NotificationService, warningService *WarningService) *ReportService {
 	return &ReportService{
-		collection:     config.DB.Collection("reports"),
-		userCollection: config.DB.Collection("users"),
-		postCollection: config.DB.Collection("posts"),
-		db:             config.DB,
+		collection:          config.DB.Collection("reports"),
+		userCollection:      config.DB.Collection("users"),
+		postCollection:      config.DB.Collection("posts"),
+		db:                  config.DB,
+		notificationService: notificationService,
+		warningService:      warningService,
 	}
 }
 
@@ -47,6 +53,11 @@ func (rs *ReportService) CreateReport(reporterID primitive.ObjectID, req models.
 		return nil, err
 	}
 
+	// Groups and events have no single owner to compare against
+	if ownerID, err := rs.getTargetOwner(ctx, req.TargetType, targetID); err == nil && ownerID == reporterID {
+		return nil, errors.New("cannot report your own content")
+	}
+
 	// Check if user already reported this target
 	existingCount, err := rs.collection.CountDocuments(ctx, bson.M{
 		"reporter_id": reporterID,
@@ -331,11 +342,16 @@ func (rs *ReportService) AssignReport(reportID, assignedBy, assignedTo primitive
 	return err
 }
 
-// ResolveReport resolves a report
-func (rs *ReportService) ResolveReport(reportID, resolvedBy primitive.ObjectID, resolution, note string) error {
+// ResolveReport resolves a report and applies its resolution action to the
+// target. The action and the resolution succeed or fail together.
+func (rs *ReportService) ResolveReport(reportID, resolvedBy primitive.ObjectID, resolution string, action models.ReportResolutionAction, note string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	if action == "" {
+		action = models.ResolutionActionNone
+	}
+
 	// Get report
 	var report models.Report
 	err := rs.collection.FindOne(ctx, bson.M{"_id": reportID}).Decode(&report)
@@ -355,29 +371,103 @@ func (rs *ReportService) ResolveReport(reportID, resolvedBy primitive.ObjectID,
 		return errors.New("access denied")
 	}
 
+	if report.IsResolved() {
+		return errors.New("report already resolved")
+	}
+
+	if !action.AppliesTo(report.TargetType) {
+		return errors.New("resolution action " + string(action) + " is not valid for " + report.TargetType + " reports")
+	}
+
 	// Resolve report
 	now := time.Now()
 	update := bson.M{
 		"$set": bson.M{
-			"status":          models.ReportResolved,
-			"resolution":      resolution,
-			"resolution_note": note,
-			"resolved_by":     resolvedBy,
-			"resolved_at":     now,
-			"updated_at":      now,
+			"status":            models.ReportResolved,
+			"resolution":        resolution,
+			"resolution_action": action,
+			"resolution_note":   note,
+			"resolved_by":       resolvedBy,
+			"resolved_at":       now,
+			"updated_at":        now,
+			"content_removed":   action.RemovesContent(),
+			"warning":           action == models.ResolutionActionWarnUser,
+			"user_suspended":    action == models.ResolutionActionSuspendUser,
 		},
 	}
 
-	_, err = rs.collection.UpdateOne(ctx, bson.M{"_id": reportID}, update)
+	var ownerID primitive.ObjectID
+	var claimed bool
+
+	transactional, err := config.RunInTransaction(ctx, rs.db, func(ctx context.Context) error {
+		// Claim the report first so two moderators can't both act on it
+		result, err := rs.collection.UpdateOne(ctx, bson.M{
+			"_id":    reportID,
+			"status": bson.M{"$nin": []models.ReportStatus{models.ReportResolved, models.ReportRejected}},
+		}, update)
+		if err != nil {
+			return err
+		}
+		if result.ModifiedCount == 0 {
+			return errors.New("report already resolved")
+		}
+		claimed = true
+
+		ownerID, err = rs.applyResolutionAction(ctx, &report, action, resolvedBy)
+		return err
+	})
 	if err != nil {
+		if !transactional && claimed {
+			// Compensate: the report was resolved but the action was not taken
+			rs.collection.UpdateOne(context.Background(), bson.M{"_id": reportID}, bson.M{
+				"$set": bson.M{
+					"status":          report.Status,
+					"content_removed": report.ContentRemoved,
+					"warning":         report.Warning,
+					"user_suspended":  report.UserSuspended,
+					"updated_at":      time.Now(),
+				},
+				"$unset": bson.M{
+					"resolution":        "",
+					"resolution_action": "",
+					"resolution_note":   "",
+					"resolved_by":       "",
+					"resolved_at":       "",
+				},
+			})
+		}
 		return err
 	}
 
-	// Create action record
-	go rs.addReportAction(reportID, "resolve", "Report resolved: "+resolution, resolvedBy, map[string]interface{}{
-		"resolution": resolution,
-		"note":       note,
-	})
+	// Create action records
+	go func() {
+		rs.addReportAction(reportID, "resolve", "Report resolved: "+resolution, resolvedBy, map[string]interface{}{
+			"resolution":        resolution,
+			"resolution_action": action,
+			"note":              note,
+		})
+		if actionType := resolutionActionType(action); actionType != "" {
+			rs.addReportAction(reportID, actionType, "Resolution action: "+string(action), resolvedBy, map[string]interface{}{
+				"target_type": report.TargetType,
+				"target_id":   report.TargetID,
+				"owner_id":    ownerID,
+			})
+		}
+	}()
+
+	// Tell the owner what happened to them or their content. Warnings become
+	// strikes, which notify the owner themselves.
+	if !ownerID.IsZero() {
+		if action == models.ResolutionActionWarnUser && rs.warningService != nil {
+			go func() {
+				if _, err := rs.warningService.IssueWarning(ownerID, resolvedBy, resolution, &reportID); err != nil {
+					log.Printf("Failed to issue warning for report %s: %v", reportID.Hex(), err)
+				}
+			}()
+		} else {
+			go rs.notifyResolutionAction(ownerID, &report, action, resolution)
+		}
+	}
 
 	// Notify reporter
 	go rs.notifyReporter(report.ReporterID, reportID, "resolved")
@@ -515,6 +605,12 @@ func (rs *ReportService) GetReportStats() (*models.ReportStatsResponse, error) {
 	})
 	stats.AutoDetectedReports = autoDetectedCount
 
+	holdStats, err := getCommentHoldStats(ctx, rs.db)
+	if err != nil {
+		return nil, err
+	}
+	stats.CommentHolds = *holdStats
+
 	return stats, nil
 }
 
@@ -697,6 +793,190 @@ func (rs *ReportService) validateTargetExists(targetType string, targetID primit
 	return nil
 }
 
+// applyResolutionAction takes the resolution action against the report target
+// and returns the ID of the user who owns it, if the action concerns them
+func (rs *ReportService) applyResolutionAction(ctx context.Context, report *models.Report, action models.ReportResolutionAction, moderatorID primitive.ObjectID) (primitive.ObjectID, error) {
+	if action == models.ResolutionActionNone {
+		return primitive.NilObjectID, nil
+	}
+
+	ownerID, err := rs.getTargetOwner(ctx, report.TargetType, report.TargetID)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	switch action {
+	case models.ResolutionActionHide, models.ResolutionActionDelete:
+		err = rs.removeTargetContent(ctx, report.TargetType, report.TargetID, action == models.ResolutionActionDelete, moderatorID)
+	case models.ResolutionActionSuspendUser:
+		var result *mongo.UpdateResult
+		result, err = rs.userCollection.UpdateOne(ctx, bson.M{"_id": ownerID}, bson.M{
+			"$set": bson.M{
+				"is_suspended": true,
+				"updated_at":   time.Now(),
+			},
+		})
+		if err == nil && result.MatchedCount == 0 {
+			err = errors.New("target owner not found")
+		}
+		if err == nil {
+			err = revokeAccountSessions(ctx, rs.db, ownerID)
+		}
+	}
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	return ownerID, nil
+}
+
+// getTargetOwner returns the user a report target belongs to
+func (rs *ReportService) getTargetOwner(ctx context.Context, targetType string, targetID primitive.ObjectID) (primitive.ObjectID, error) {
+	if targetType == "user" {
+		return targetID, nil
+	}
+
+	collection, ownerField := rs.targetContentCollection(targetType)
+	if collection == nil {
+		return primitive.NilObjectID, errors.New("invalid target type")
+	}
+
+	var owner bson.M
+	err := collection.FindOne(ctx, bson.M{"_id": targetID}, options.FindOne().SetProjection(bson.M{ownerField: 1})).Decode(&owner)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return primitive.NilObjectID, errors.New("target not found")
+		}
+		return primitive.NilObjectID, err
+	}
+
+	ownerID, ok := owner[ownerField].(primitive.ObjectID)
+	if !ok {
+		return primitive.NilObjectID, errors.New("target owner not found")
+	}
+	return ownerID, nil
+}
+
+// targetContentCollection returns the collection holding a content target and
+// the field naming its owner
+func (rs *ReportService) targetContentCollection(targetType string) (*mongo.Collection, string) {
+	switch targetType {
+	case "post":
+		return rs.postCollection, "user_id"
+	case "comment":
+		return rs.db.Collection("comments"), "user_id"
+	case "story":
+		return rs.db.Collection("stories"), "user_id"
+	case "message":
+		return rs.db.Collection("messages"), "sender_id"
+	}
+	return nil, ""
+}
+
+// removeTargetContent hides or soft deletes reported content and takes it out
+// of the counters it contributed to
+func (rs *ReportService) removeTargetContent(ctx context.Context, targetType string, targetID primitive.ObjectID, deleteContent bool, moderatorID primitive.ObjectID) error {
+	collection, _ := rs.targetContentCollection(targetType)
+	if collection == nil {
+		return errors.New("invalid target type")
+	}
+
+	now := time.Now()
+	set := bson.M{"updated_at": now}
+	if targetType != "message" {
+		set["is_hidden"] = true
+		set["hidden_by"] = moderatorID
+	}
+	if deleteContent {
+		set["deleted_at"] = now
+		if targetType == "post" || targetType == "comment" {
+			set["is_approved"] = false
+		}
+	}
+
+	// Read the content as it was so counters only change on the transition
+	var before bson.M
+	err := collection.FindOneAndUpdate(ctx, repository.ByID(targetID), bson.M{"$set": set}).Decode(&before)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.New("target not found")
+		}
+		return err
+	}
+
+	switch targetType {
+	case "post":
+		if deleteContent {
+			_, err = rs.userCollection.UpdateOne(ctx, bson.M{"_id": before["user_id"]}, bson.M{
+				"$inc": bson.M{"posts_count": -1},
+				"$set": bson.M{"updated_at": now},
+			})
+		}
+	case "comment":
+		var comment models.Comment
+		raw, _ := bson.Marshal(before)
+		if err := bson.Unmarshal(raw, &comment); err != nil {
+			return err
+		}
+		// Hidden, unapproved and held comments were never counted
+		if !comment.IsHidden && comment.IsApproved && !comment.IsHeld() {
+			err = rs.uncountComment(ctx, &comment)
+		}
+	}
+
+	return err
+}
+
+// uncountComment reverses the counter updates made when a comment was posted
+func (rs *ReportService) uncountComment(ctx context.Context, comment *models.Comment) error {
+	inc := bson.M{"comments_count": -1}
+	if comment.IsQuickReply() {
+		inc = quickReplyCountsUpdate(comment.Content, -1)
+	}
+	if _, err := rs.postCollection.UpdateOne(ctx, bson.M{"_id": comment.PostID}, bson.M{"$inc": inc}); err != nil {
+		return err
+	}
+
+	if comment.ParentCommentID != nil {
+		if _, err := rs.db.Collection("comments").UpdateOne(ctx, bson.M{"_id": comment.ParentCommentID}, bson.M{
+			"$inc": bson.M{"replies_count": -1},
+		}); err != nil {
+			return err
+		}
+	}
+
+	_, err := rs.userCollection.UpdateOne(ctx, bson.M{"_id": comment.UserID}, bson.M{
+		"$inc": bson.M{"comments_count": -1},
+		"$set": bson.M{"updated_at": time.Now()},
+	})
+	return err
+}
+
+// resolutionActionType maps a resolution action to the action record type
+func resolutionActionType(action models.ReportResolutionAction) string {
+	switch action {
+	case models.ResolutionActionHide, models.ResolutionActionDelete:
+		return "content_removal"
+	case models.ResolutionActionWarnUser:
+		return "warning"
+	case models.ResolutionActionSuspendUser:
+		return "suspension"
+	}
+	return ""
+}
+
+func (rs *ReportService) notifyResolutionAction(ownerID primitive.ObjectID, report *models.Report, action models.ReportResolutionAction, reason string) {
+	if rs.notificationService == nil {
+		return
+	}
+
+	if action == models.ResolutionActionSuspendUser {
+		rs.notificationService.NotifyUserSuspension(ownerID, reason, "")
+		return
+	}
+	rs.notificationService.NotifyModerationAction(ownerID, action, report.TargetType, report.TargetID, reason)
+}
+
 func (rs *ReportService) determinePriority(reason models.ReportReason, reportedBefore bool) string {
 	switch reason {
 	case models.ReportViolence, models.ReportHateSpeech:
@@ -0,0 +1,55 @@
+package services_test
+
+import (
+	"testing"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestCreateReportRejectsOwnContent(t *testing.T) {
+	h := testutil.NewHarness(t)
+	reports := services.NewReportService(services.NewNotificationService(nil, nil), nil)
+
+	author := h.CreateUser()
+	other := h.CreateUser()
+	post := h.CreatePost(author)
+	comment := h.CreateComment(author, post, "Nice post")
+
+	tests := []struct {
+		name       string
+		targetType string
+		targetID   string
+	}{
+		{"own profile", "user", author.ID.Hex()},
+		{"own post", "post", post.ID.Hex()},
+		{"own comment", "comment", comment.ID.Hex()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := reports.CreateReport(author.ID, models.CreateReportRequest{
+				TargetType: tt.targetType,
+				TargetID:   tt.targetID,
+				Reason:     models.ReportSpam,
+			})
+			if err == nil || err.Error() != "cannot report your own content" {
+				t.Errorf("CreateReport error = %v, want \"cannot report your own content\"", err)
+			}
+		})
+	}
+	if got := h.Count("reports", bson.M{}); got != 0 {
+		t.Fatalf("reports = %d after reporting own content, want 0", got)
+	}
+
+	if _, err := reports.CreateReport(other.ID, models.CreateReportRequest{
+		TargetType: "post",
+		TargetID:   post.ID.Hex(),
+		Reason:     models.ReportSpam,
+	}); err != nil {
+		t.Fatalf("reporting another user's post: %v", err)
+	}
+}
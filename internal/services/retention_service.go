@@ -0,0 +1,577 @@
+// internal/services/retention_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"social-media-api/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RetentionInterval is how often the retention worker runs
+const RetentionInterval = 24 * time.Hour
+
+// retentionHoldTTL is the TTL put on a collection while its category is on
+// legal hold, long enough that nothing expires
+const retentionHoldTTL = 100 * 365 * 24 * time.Hour
+
+// RetentionPolicy configures the retention worker. Periods holds each
+// category's default period; admins override them in the settings store.
+type RetentionPolicy struct {
+	Periods            map[string]time.Duration
+	MaxDeleteFraction  float64 // A run refuses to delete more than this fraction of a collection
+	SafetyMinDocuments int64   // Collections smaller than this are exempt from MaxDeleteFraction
+	BatchSize          int
+
+	// SyncTTL sets the TTL index of a collection's field to the period, so
+	// MongoDB keeps expiring documents between runs
+	SyncTTL func(ctx context.Context, collection, field string, period time.Duration) error
+}
+
+// retentionTarget is a collection a category deletes from. Documents whose
+// field is older than the period and that match filter are expired.
+type retentionTarget struct {
+	collection string
+	field      string
+	filter     bson.M
+	ttl        bool // The field carries a TTL index kept in sync with the period
+}
+
+// retentionCategory is an entry of the retention registry
+type retentionCategory struct {
+	name        string
+	description string
+	targets     []retentionTarget
+	minDays     int // Admins can't set a shorter period
+}
+
+// retentionRegistry lists every data category with a retention period
+var retentionRegistry = []retentionCategory{
+	{
+		name:        models.RetentionBehaviorSessions,
+		description: "Behavior tracking sessions",
+		targets:     []retentionTarget{{collection: "user_sessions", field: "created_at", ttl: true}},
+		minDays:     1,
+	},
+	{
+		name:        models.RetentionUserJourneys,
+		description: "Behavior tracking user journeys",
+		targets:     []retentionTarget{{collection: "user_journeys", field: "created_at"}},
+		minDays:     1,
+	},
+	{
+		name:        models.RetentionEngagementEvents,
+		description: "Content engagement events",
+		targets:     []retentionTarget{{collection: "content_engagements", field: "created_at", ttl: true}},
+		minDays:     1,
+	},
+	{
+		name:        models.RetentionRecommendationEvents,
+		description: "Recommendation impressions and interactions",
+		targets:     []retentionTarget{{collection: "recommendation_events", field: "created_at", ttl: true}},
+		minDays:     1,
+	},
+	{
+		name:        models.RetentionFeedCache,
+		description: "Cached feeds, kept this long past their expiry",
+		targets:     []retentionTarget{{collection: "feed_cache", field: "expires_at", ttl: true}},
+	},
+	{
+		name:        models.RetentionNotifications,
+		description: "Notifications",
+		targets:     []retentionTarget{{collection: "notifications", field: "created_at"}},
+		minDays:     1,
+	},
+	{
+		name:        models.RetentionAuditLogs,
+		description: "Admin and system audit logs",
+		targets:     []retentionTarget{{collection: "audit_logs", field: "created_at"}},
+		minDays:     30,
+	},
+	{
+		name:        models.RetentionResolvedReports,
+		description: "Resolved and rejected reports, counted from resolution",
+		targets: []retentionTarget{{
+			collection: "reports",
+			field:      "resolved_at",
+			filter:     bson.M{"status": bson.M{"$in": []models.ReportStatus{models.ReportResolved, models.ReportRejected}}},
+		}},
+		minDays: 30,
+	},
+	{
+		name:        models.RetentionLoginHistory,
+		description: "Login history",
+		targets:     []retentionTarget{{collection: "login_history", field: "created_at"}},
+		minDays:     1,
+	},
+	{
+		name:        models.RetentionIdempotencyKeys,
+		description: "Stored idempotency keys and responses",
+		targets:     []retentionTarget{{collection: "idempotency_keys", field: "created_at"}},
+		minDays:     1,
+	},
+	{
+		name:        models.RetentionStatusSamples,
+		description: "Status page health samples",
+		targets:     []retentionTarget{{collection: "status_samples", field: "recorded_at", ttl: true}},
+		minDays:     models.StatusHistoryDays, // The status page reports this much history
+	},
+	{
+		name:        models.RetentionFeedAlgorithmLogs,
+		description: "Records of the algorithm serving each feed request",
+		targets:     []retentionTarget{{collection: "feed_algorithm_logs", field: "created_at", ttl: true}},
+		minDays:     1,
+	},
+}
+
+// BehaviorRetentionCategories are the categories the cleanup-behavior
+// command runs
+var BehaviorRetentionCategories = []string{
+	models.RetentionBehaviorSessions,
+	models.RetentionUserJourneys,
+	models.RetentionEngagementEvents,
+	models.RetentionRecommendationEvents,
+	models.RetentionFeedCache,
+}
+
+// RetentionService deletes data past its retention period. Each category's
+// period comes from the settings store, falling back to the policy default.
+// A category on legal hold is never deleted from, and a run refuses to
+// delete more than MaxDeleteFraction of a collection.
+type RetentionService struct {
+	db                 *mongo.Database
+	settingsCollection *mongo.Collection
+	runsCollection     *mongo.Collection
+	policy             RetentionPolicy
+
+	runMu   sync.Mutex // One run at a time on this instance
+	stopJob context.CancelFunc
+}
+
+func NewRetentionService(db *mongo.Database, policy RetentionPolicy) *RetentionService {
+	return &RetentionService{
+		db:                 db,
+		settingsCollection: db.Collection("app_settings"),
+		runsCollection:     db.Collection("retention_runs"),
+		policy:             policy,
+	}
+}
+
+// Run applies retention to the given categories, or to every category when
+// none are given, and returns each category's run
+func (rs *RetentionService) Run(ctx context.Context, categories ...string) ([]models.RetentionRun, error) {
+	rs.runMu.Lock()
+	defer rs.runMu.Unlock()
+
+	// Without the settings a legal hold could be missed, so nothing is deleted
+	settings, err := rs.loadSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load retention settings: %w", err)
+	}
+
+	selected := make(map[string]bool, len(categories))
+	for _, name := range categories {
+		selected[name] = true
+	}
+
+	var runs []models.RetentionRun
+	for _, category := range retentionRegistry {
+		if len(selected) > 0 && !selected[category.name] {
+			continue
+		}
+		if ctx.Err() != nil {
+			return runs, ctx.Err()
+		}
+
+		run := rs.runCategory(ctx, category, settings[category.name])
+		if run.LastOutcome == models.RetentionOutcomeRefused || run.LastOutcome == models.RetentionOutcomeFailed {
+			log.Printf("Retention for %s %s: %s", category.name, run.LastOutcome, run.LastError)
+		}
+		if err := rs.recordRun(ctx, run); err != nil {
+			log.Printf("Failed to record retention run for %s: %v", category.name, err)
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// GetOverview returns every category with its period, legal hold and last run
+func (rs *RetentionService) GetOverview() (*models.RetentionOverview, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	settings, err := rs.loadSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load retention settings: %w", err)
+	}
+
+	cursor, err := rs.runsCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var runs []models.RetentionRun
+	if err := cursor.All(ctx, &runs); err != nil {
+		return nil, err
+	}
+	runsByCategory := make(map[string]models.RetentionRun, len(runs))
+	for _, run := range runs {
+		runsByCategory[run.Category] = run
+	}
+
+	overview := &models.RetentionOverview{
+		Categories:         make([]models.RetentionCategoryStatus, 0, len(retentionRegistry)),
+		MaxDeleteFraction:  rs.policy.MaxDeleteFraction,
+		SafetyMinDocuments: rs.policy.SafetyMinDocuments,
+	}
+	for _, category := range retentionRegistry {
+		run, ran := runsByCategory[category.name]
+		overview.Categories = append(overview.Categories, rs.categoryStatus(category, settings[category.name], run, ran))
+	}
+	return overview, nil
+}
+
+// UpdateCategory changes a category's period or legal hold. A new period
+// applies from the next run.
+func (rs *RetentionService) UpdateCategory(adminID primitive.ObjectID, name string, req models.UpdateRetentionRequest) (*models.RetentionCategoryStatus, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	category, ok := findRetentionCategory(name)
+	if !ok {
+		return nil, errors.New("unknown retention category")
+	}
+	if req.Days != nil && *req.Days < category.minDays {
+		return nil, fmt.Errorf("invalid retention period: %s must be kept at least %d days", name, category.minDays)
+	}
+
+	settings, err := rs.loadSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load retention settings: %w", err)
+	}
+	previous := settings[name]
+
+	setting := previous
+	if req.UseDefault {
+		setting.Days = nil
+	} else if req.Days != nil {
+		setting.Days = req.Days
+	}
+	if req.LegalHold != nil {
+		setting.LegalHold = *req.LegalHold
+		setting.HoldReason = req.HoldReason
+		if !setting.LegalHold {
+			setting.HoldReason = ""
+		}
+	}
+	setting.UpdatedBy = adminID
+	setting.UpdatedAt = time.Now()
+
+	_, err = rs.settingsCollection.UpdateOne(ctx,
+		bson.M{"_id": models.RetentionSettingsKey},
+		bson.M{"$set": bson.M{"value." + name: setting, "updated_at": setting.UpdatedAt}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save retention settings: %w", err)
+	}
+
+	createAuditLog(ctx, rs.db, &models.AuditLog{
+		Action:     "update_retention",
+		ActorID:    adminID,
+		ActorType:  "admin",
+		TargetType: "retention_category",
+		OldValues:  retentionSettingValues(previous),
+		NewValues:  retentionSettingValues(setting),
+		Reason:     name,
+	})
+
+	var run models.RetentionRun
+	ran := rs.runsCollection.FindOne(ctx, bson.M{"_id": name}).Decode(&run) == nil
+
+	status := rs.categoryStatus(category, setting, run, ran)
+	log.Printf("Retention for %s set to %.1f days (legal hold %t) by %s", name, status.PeriodDays, setting.LegalHold, adminID.Hex())
+	return &status, nil
+}
+
+// StartRetentionJob runs retention for every category every interval until
+// StopRetentionJob is called
+func (rs *RetentionService) StartRetentionJob(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	rs.stopJob = cancel
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				start := time.Now()
+				runs, err := rs.Run(ctx)
+				if ctx.Err() == nil {
+					recordJobHeartbeat(rs.db, "data_retention", interval, err)
+				}
+				if err != nil && ctx.Err() == nil {
+					log.Printf("Data retention run failed: %v", err)
+					continue
+				}
+
+				var deleted int64
+				for _, run := range runs {
+					deleted += run.LastDeleted
+				}
+				log.Printf("Data retention deleted %d documents across %d categories in %s", deleted, len(runs), time.Since(start).Round(time.Second))
+			}
+		}
+	}()
+}
+
+// StopRetentionJob stops the periodic retention run
+func (rs *RetentionService) StopRetentionJob() {
+	if rs.stopJob != nil {
+		rs.stopJob()
+	}
+}
+
+// Helper methods
+
+func (rs *RetentionService) runCategory(ctx context.Context, category retentionCategory, setting models.RetentionSetting) models.RetentionRun {
+	run := models.RetentionRun{Category: category.name, LastRunAt: time.Now()}
+
+	if setting.LegalHold {
+		// Keep MongoDB from expiring anything while the hold lasts
+		if err := rs.syncTTL(ctx, category, retentionHoldTTL); err != nil {
+			run.LastOutcome = models.RetentionOutcomeFailed
+			run.LastError = err.Error()
+			return run
+		}
+		run.LastOutcome = models.RetentionOutcomeLegalHold
+		return run
+	}
+
+	period, ok := rs.period(category, setting)
+	if !ok {
+		run.LastOutcome = models.RetentionOutcomeFailed
+		run.LastError = "no retention period configured"
+		return run
+	}
+	cutoff := time.Now().Add(-period)
+
+	// Check every collection against the safety valve before deleting any
+	expired := make([]int64, len(category.targets))
+	for i, target := range category.targets {
+		coll := rs.db.Collection(target.collection)
+		count, err := coll.CountDocuments(ctx, target.expiredFilter(cutoff))
+		if err != nil {
+			run.LastOutcome = models.RetentionOutcomeFailed
+			run.LastError = err.Error()
+			return run
+		}
+		total, err := coll.EstimatedDocumentCount(ctx)
+		if err != nil {
+			run.LastOutcome = models.RetentionOutcomeFailed
+			run.LastError = err.Error()
+			return run
+		}
+		if total >= rs.policy.SafetyMinDocuments && float64(count) > rs.policy.MaxDeleteFraction*float64(total) {
+			run.LastOutcome = models.RetentionOutcomeRefused
+			run.LastError = fmt.Sprintf("would delete %d of %d documents in %s, more than the %.0f%% allowed in one run",
+				count, total, target.collection, rs.policy.MaxDeleteFraction*100)
+			return run
+		}
+		expired[i] = count
+	}
+
+	for i, target := range category.targets {
+		deleted, err := rs.deleteExpired(ctx, target, cutoff, expired[i])
+		run.LastDeleted += deleted
+		if err != nil {
+			run.LastOutcome = models.RetentionOutcomeFailed
+			run.LastError = err.Error()
+			return run
+		}
+	}
+
+	if err := rs.syncTTL(ctx, category, period); err != nil {
+		run.LastOutcome = models.RetentionOutcomeFailed
+		run.LastError = err.Error()
+		return run
+	}
+
+	run.LastOutcome = models.RetentionOutcomeCompleted
+	return run
+}
+
+// deleteExpired deletes expired documents oldest first in batches, stopping
+// after limit documents so the run stays within what the safety valve checked
+func (rs *RetentionService) deleteExpired(ctx context.Context, target retentionTarget, cutoff time.Time, limit int64) (int64, error) {
+	coll := rs.db.Collection(target.collection)
+	filter := target.expiredFilter(cutoff)
+
+	var deleted int64
+	for deleted < limit {
+		batch := int64(rs.policy.BatchSize)
+		if remaining := limit - deleted; remaining < batch {
+			batch = remaining
+		}
+
+		opts := options.Find().
+			SetSort(bson.M{target.field: 1}).
+			SetLimit(batch).
+			SetProjection(bson.M{"_id": 1})
+		cursor, err := coll.Find(ctx, filter, opts)
+		if err != nil {
+			return deleted, err
+		}
+		var docs []struct {
+			ID interface{} `bson:"_id"`
+		}
+		if err := cursor.All(ctx, &docs); err != nil {
+			return deleted, err
+		}
+		if len(docs) == 0 {
+			break
+		}
+
+		ids := make([]interface{}, len(docs))
+		for i, doc := range docs {
+			ids[i] = doc.ID
+		}
+		result, err := coll.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+		if err != nil {
+			return deleted, err
+		}
+		deleted += result.DeletedCount
+	}
+	return deleted, nil
+}
+
+func (rs *RetentionService) syncTTL(ctx context.Context, category retentionCategory, period time.Duration) error {
+	if rs.policy.SyncTTL == nil {
+		return nil
+	}
+	for _, target := range category.targets {
+		if !target.ttl {
+			continue
+		}
+		if err := rs.policy.SyncTTL(ctx, target.collection, target.field, period); err != nil {
+			return fmt.Errorf("failed to sync TTL on %s.%s: %w", target.collection, target.field, err)
+		}
+	}
+	return nil
+}
+
+// period returns the retention period a run applies: the admin override or
+// the default, never below the category's minimum
+func (rs *RetentionService) period(category retentionCategory, setting models.RetentionSetting) (time.Duration, bool) {
+	period, ok := rs.policy.Periods[category.name]
+	if setting.Days != nil {
+		period, ok = time.Duration(*setting.Days)*24*time.Hour, true
+	}
+	if min := time.Duration(category.minDays) * 24 * time.Hour; period < min {
+		period = min
+	}
+	return period, ok
+}
+
+func (rs *RetentionService) categoryStatus(category retentionCategory, setting models.RetentionSetting, run models.RetentionRun, ran bool) models.RetentionCategoryStatus {
+	period, _ := rs.period(category, setting)
+	status := models.RetentionCategoryStatus{
+		Category:    category.name,
+		Description: category.description,
+		PeriodDays:  period.Hours() / 24,
+		DefaultDays: rs.policy.Periods[category.name].Hours() / 24,
+		MinDays:     category.minDays,
+		Overridden:  setting.Days != nil,
+		LegalHold:   setting.LegalHold,
+		HoldReason:  setting.HoldReason,
+	}
+	for _, target := range category.targets {
+		status.Collections = append(status.Collections, target.collection)
+	}
+	sort.Strings(status.Collections)
+
+	if ran {
+		status.LastRunAt = &run.LastRunAt
+		status.LastOutcome = run.LastOutcome
+		status.LastDeleted = run.LastDeleted
+		status.LastError = run.LastError
+		status.TotalDeleted = run.TotalDeleted
+	}
+	return status
+}
+
+func (rs *RetentionService) loadSettings(ctx context.Context) (models.RetentionSettings, error) {
+	var stored struct {
+		Value models.RetentionSettings `bson:"value"`
+	}
+	err := rs.settingsCollection.FindOne(ctx, bson.M{"_id": models.RetentionSettingsKey}).Decode(&stored)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+	if stored.Value == nil {
+		stored.Value = make(models.RetentionSettings)
+	}
+	return stored.Value, nil
+}
+
+func (rs *RetentionService) recordRun(ctx context.Context, run models.RetentionRun) error {
+	_, err := rs.runsCollection.UpdateOne(ctx,
+		bson.M{"_id": run.Category},
+		bson.M{
+			"$set": bson.M{
+				"last_run_at":  run.LastRunAt,
+				"last_outcome": run.LastOutcome,
+				"last_deleted": run.LastDeleted,
+				"last_error":   run.LastError,
+			},
+			"$inc": bson.M{"total_deleted": run.LastDeleted},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func (target retentionTarget) expiredFilter(cutoff time.Time) bson.M {
+	filter := bson.M{target.field: bson.M{"$lt": cutoff}}
+	for key, value := range target.filter {
+		filter[key] = value
+	}
+	return filter
+}
+
+func findRetentionCategory(name string) (retentionCategory, bool) {
+	for _, category := range retentionRegistry {
+		if category.name == name {
+			return category, true
+		}
+	}
+	return retentionCategory{}, false
+}
+
+func retentionSettingValues(setting models.RetentionSetting) map[string]interface{} {
+	values := map[string]interface{}{
+		"legal_hold": setting.LegalHold,
+	}
+	if setting.Days != nil {
+		values["days"] = *setting.Days
+	}
+	if setting.HoldReason != "" {
+		values["hold_reason"] = setting.HoldReason
+	}
+	return values
+}
@@ -0,0 +1,377 @@
+// internal/services/saved_search_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SavedSearchPolicy holds the saved search limits and alert cadence
+type SavedSearchPolicy struct {
+	MaxPerUser    int           // Saved searches a user may keep
+	AlertInterval time.Duration // How often a saved search with alerts is rerun
+}
+
+const (
+	// SavedSearchAlertCheckInterval is how often the alert job looks for
+	// saved searches that are due
+	SavedSearchAlertCheckInterval = 5 * time.Minute
+
+	savedSearchAlertBatch = 100
+)
+
+type SavedSearchService struct {
+	collection          *mongo.Collection
+	db                  *mongo.Database
+	searchService       *SearchService
+	notificationService *NotificationService
+	policy              SavedSearchPolicy
+	stopAlerts          context.CancelFunc
+}
+
+func NewSavedSearchService(db *mongo.Database, searchService *SearchService, notificationService *NotificationService, policy SavedSearchPolicy) *SavedSearchService {
+	return &SavedSearchService{
+		collection:          db.Collection("saved_searches"),
+		db:                  db,
+		searchService:       searchService,
+		notificationService: notificationService,
+		policy:              policy,
+	}
+}
+
+// CreateSavedSearch saves a search for the user. Alerts only report posts
+// created after the search was saved.
+func (sss *SavedSearchService) CreateSavedSearch(userID primitive.ObjectID, req models.CreateSavedSearchRequest) (*models.SavedSearch, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := strings.TrimSpace(req.Query)
+	if len(query) < 2 {
+		return nil, errors.New("invalid query: must be at least 2 characters")
+	}
+
+	count, err := sss.collection.CountDocuments(ctx, repository.NotDeleted(bson.M{"user_id": userID}))
+	if err != nil {
+		return nil, err
+	}
+	if sss.policy.MaxPerUser > 0 && count >= int64(sss.policy.MaxPerUser) {
+		return nil, fmt.Errorf("saved search limit reached: at most %d searches can be saved", sss.policy.MaxPerUser)
+	}
+
+	now := time.Now()
+	search := &models.SavedSearch{
+		UserID:        userID,
+		Name:          strings.TrimSpace(req.Name),
+		Query:         query,
+		Filters:       req.Filters,
+		AlertsEnabled: req.AlertsEnabled == nil || *req.AlertsEnabled,
+		LastRunAt:     &now,
+	}
+	search.BeforeCreate()
+
+	result, err := sss.collection.InsertOne(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+	search.ID = result.InsertedID.(primitive.ObjectID)
+
+	return search, nil
+}
+
+// GetSavedSearches lists the user's saved searches, newest first
+func (sss *SavedSearchService) GetSavedSearches(userID primitive.ObjectID, limit, skip int) ([]models.SavedSearch, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := repository.NotDeleted(bson.M{"user_id": userID})
+
+	total, err := sss.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit)).
+		SetSkip(int64(skip))
+	cursor, err := sss.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	searches := []models.SavedSearch{}
+	if err := cursor.All(ctx, &searches); err != nil {
+		return nil, 0, err
+	}
+
+	return searches, total, nil
+}
+
+// GetSavedSearch returns one of the user's saved searches
+func (sss *SavedSearchService) GetSavedSearch(searchID, userID primitive.ObjectID) (*models.SavedSearch, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var search models.SavedSearch
+	err := sss.collection.FindOne(ctx, repository.NotDeleted(bson.M{
+		"_id":     searchID,
+		"user_id": userID,
+	})).Decode(&search)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("saved search not found")
+		}
+		return nil, err
+	}
+
+	return &search, nil
+}
+
+// UpdateSavedSearch changes one of the user's saved searches
+func (sss *SavedSearchService) UpdateSavedSearch(searchID, userID primitive.ObjectID, req models.UpdateSavedSearchRequest) (*models.SavedSearch, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	set := bson.M{"updated_at": now}
+	if req.Name != nil {
+		set["name"] = strings.TrimSpace(*req.Name)
+	}
+	if req.Query != nil {
+		query := strings.TrimSpace(*req.Query)
+		if len(query) < 2 {
+			return nil, errors.New("invalid query: must be at least 2 characters")
+		}
+		set["query"] = query
+	}
+	if req.Filters != nil {
+		set["filters"] = *req.Filters
+	}
+	if req.AlertsEnabled != nil {
+		set["alerts_enabled"] = *req.AlertsEnabled
+	}
+	// A changed search would otherwise report every older post it now matches
+	if req.Query != nil || req.Filters != nil || (req.AlertsEnabled != nil && *req.AlertsEnabled) {
+		set["last_run_at"] = now
+	}
+
+	var search models.SavedSearch
+	err := sss.collection.FindOneAndUpdate(ctx, repository.NotDeleted(bson.M{
+		"_id":     searchID,
+		"user_id": userID,
+	}), bson.M{"$set": set}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&search)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("saved search not found")
+		}
+		return nil, err
+	}
+
+	return &search, nil
+}
+
+// DeleteSavedSearch removes one of the user's saved searches
+func (sss *SavedSearchService) DeleteSavedSearch(searchID, userID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	result, err := sss.collection.UpdateOne(ctx, repository.NotDeleted(bson.M{
+		"_id":     searchID,
+		"user_id": userID,
+	}), bson.M{"$set": bson.M{"deleted_at": now, "updated_at": now}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("saved search not found")
+	}
+
+	return nil
+}
+
+// RunSavedSearch runs one of the user's saved searches now, like a regular
+// post search
+func (sss *SavedSearchService) RunSavedSearch(searchID, userID primitive.ObjectID, limit, skip int) (*SearchResponse, error) {
+	search, err := sss.GetSavedSearch(searchID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return sss.searchService.Search(search.Query, &userID, savedSearchFilters(search), limit, skip)
+}
+
+// RunAlerts reruns the saved searches with alerts that are due and notifies
+// their owners of posts created since the previous run. It returns how many
+// searches were run.
+func (sss *SavedSearchService) RunAlerts(ctx context.Context) (int, error) {
+	runs := 0
+	var lastID primitive.ObjectID
+
+	for {
+		due := time.Now().Add(-sss.policy.AlertInterval)
+		filter := repository.NotDeleted(bson.M{
+			"alerts_enabled": true,
+			"$or": []bson.M{
+				{"last_run_at": bson.M{"$lte": due}},
+				{"last_run_at": bson.M{"$exists": false}},
+			},
+		})
+		if !lastID.IsZero() {
+			filter["_id"] = bson.M{"$gt": lastID}
+		}
+
+		opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(savedSearchAlertBatch)
+		cursor, err := sss.collection.Find(ctx, filter, opts)
+		if err != nil {
+			return runs, err
+		}
+		var searches []models.SavedSearch
+		if err := cursor.All(ctx, &searches); err != nil {
+			return runs, err
+		}
+		if len(searches) == 0 {
+			return runs, nil
+		}
+
+		for i := range searches {
+			if ctx.Err() != nil {
+				return runs, ctx.Err()
+			}
+			if err := sss.runAlert(ctx, &searches[i]); err != nil {
+				log.Printf("Saved search %s alert failed: %v", searches[i].ID.Hex(), err)
+				continue
+			}
+			runs++
+		}
+		lastID = searches[len(searches)-1].ID
+	}
+}
+
+// runAlert reruns one saved search for posts created since its last run.
+// The run time is taken before searching so posts created during the run are
+// picked up next time.
+func (sss *SavedSearchService) runAlert(ctx context.Context, search *models.SavedSearch) error {
+	runAt := time.Now()
+	filters := savedSearchFilters(search)
+	if search.LastRunAt != nil {
+		filters.Since = *search.LastRunAt
+	} else {
+		filters.Since = search.CreatedAt
+	}
+
+	results, err := sss.searchService.searchPosts(ctx, sss.searchService.cleanQuery(search.Query), &search.UserID, filters, models.SavedSearchAlertMaxMatches)
+	if err != nil {
+		return err
+	}
+
+	muted := getMutedUserIDs(ctx, sss.db, search.UserID)
+	blocked := make(map[string]bool)
+	matches := 0
+	var firstPostID string
+	for _, result := range results {
+		post, ok := result.Data.(models.PostResponse)
+		if !ok || post.UserID == search.UserID.Hex() {
+			continue
+		}
+		authorID, err := primitive.ObjectIDFromHex(post.UserID)
+		if err != nil || muted[authorID] {
+			continue
+		}
+		isBlocked, checked := blocked[post.UserID]
+		if !checked {
+			isBlocked = isUserBlocked(ctx, sss.db, search.UserID, authorID) || isUserBlocked(ctx, sss.db, authorID, search.UserID)
+			blocked[post.UserID] = isBlocked
+		}
+		if isBlocked {
+			continue
+		}
+		if firstPostID == "" {
+			firstPostID = post.ID
+		}
+		matches++
+	}
+
+	set := bson.M{"last_run_at": runAt, "last_match_count": matches}
+	update := bson.M{"$set": set}
+	if matches > 0 {
+		set["last_alerted_at"] = runAt
+		update["$inc"] = bson.M{"total_alert_count": 1}
+	}
+	// The alert is only sent if this run still owns the window, so an
+	// overlapping run on another instance doesn't notify twice
+	lastRun := bson.M{"$exists": false}
+	if search.LastRunAt != nil {
+		lastRun = bson.M{"$eq": *search.LastRunAt}
+	}
+	result, err := sss.collection.UpdateOne(ctx, bson.M{"_id": search.ID, "last_run_at": lastRun}, update)
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 || matches == 0 || sss.notificationService == nil {
+		return nil
+	}
+
+	if err := sss.notificationService.NotifySavedSearchMatches(search.UserID, search.ID, search.Name, matches, firstPostID); err != nil {
+		log.Printf("Failed to notify user %s of saved search matches: %v", search.UserID.Hex(), err)
+	}
+	return nil
+}
+
+// StartAlertJob runs RunAlerts every interval until StopAlertJob is called
+func (sss *SavedSearchService) StartAlertJob(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sss.stopAlerts = cancel
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				runs, err := sss.RunAlerts(ctx)
+				if ctx.Err() != nil {
+					return
+				}
+				if err != nil {
+					log.Printf("Saved search alerts failed after %d searches: %v", runs, err)
+				}
+				recordJobHeartbeat(sss.db, "saved_search_alerts", interval, err)
+			}
+		}
+	}()
+}
+
+// StopAlertJob stops the periodic saved search alerts
+func (sss *SavedSearchService) StopAlertJob() {
+	if sss.stopAlerts != nil {
+		sss.stopAlerts()
+	}
+}
+
+// savedSearchFilters turns a saved search into post search filters. Saved
+// searches match posts in any language unless they set one.
+func savedSearchFilters(search *models.SavedSearch) SearchFilters {
+	return SearchFilters{
+		Type:        "posts",
+		SortBy:      "recent",
+		ContentType: search.Filters.ContentType,
+		Language:    search.Filters.Language,
+		Languages:   []string{},
+	}
+}
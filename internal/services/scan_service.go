@@ -0,0 +1,113 @@
+// internal/services/scan_service.go
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// ScanService checks an uploaded file for malware. clean is false when the
+// file is infected, with detail naming what was found; an error means the
+// scan could not be completed.
+type ScanService interface {
+	Scan(ctx context.Context, file io.Reader) (clean bool, detail string, err error)
+}
+
+// NoopScanService passes every file, used when scanning is disabled
+type NoopScanService struct{}
+
+// Scan reports the file clean without reading it
+func (NoopScanService) Scan(ctx context.Context, file io.Reader) (bool, string, error) {
+	return true, "", nil
+}
+
+// clamAVChunkSize is how much of the file each INSTREAM chunk carries
+const clamAVChunkSize = 64 * 1024
+
+// ClamAVScanService scans files with a clamd daemon over TCP using its
+// INSTREAM command
+type ClamAVScanService struct {
+	address string
+	timeout time.Duration
+}
+
+func NewClamAVScanService(address string, timeout time.Duration) *ClamAVScanService {
+	return &ClamAVScanService{
+		address: address,
+		timeout: timeout,
+	}
+}
+
+// Scan streams the file to clamd and parses its verdict
+func (cs *ClamAVScanService) Scan(ctx context.Context, file io.Reader) (bool, string, error) {
+	dialer := net.Dialer{Timeout: cs.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", cs.address)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to connect to clamd: %v", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(cs.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return false, "", fmt.Errorf("failed to start clamd scan: %v", err)
+	}
+
+	// Each chunk is prefixed with its length; a zero length ends the stream
+	buf := make([]byte, clamAVChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := file.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(size); err != nil {
+				return false, "", fmt.Errorf("failed to send file to clamd: %v", err)
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				return false, "", fmt.Errorf("failed to send file to clamd: %v", err)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return false, "", fmt.Errorf("failed to read file: %v", readErr)
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return false, "", fmt.Errorf("failed to finish clamd scan: %v", err)
+	}
+
+	reply, err := io.ReadAll(conn)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to read clamd reply: %v", err)
+	}
+	return parseClamAVReply(string(bytes.TrimRight(reply, "\x00\n")))
+}
+
+// parseClamAVReply reads a clamd reply such as "stream: OK" or
+// "stream: Eicar-Test-Signature FOUND"
+func parseClamAVReply(reply string) (bool, string, error) {
+	result := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case result == "OK":
+		return true, "", nil
+	case strings.HasSuffix(result, " FOUND"):
+		return false, strings.TrimSuffix(result, " FOUND"), nil
+	case strings.HasSuffix(result, " ERROR"):
+		return false, "", errors.New("clamd error: " + strings.TrimSuffix(result, " ERROR"))
+	default:
+		return false, "", errors.New("unexpected clamd reply: " + reply)
+	}
+}
@@ -0,0 +1,235 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"mime/multipart"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// eicar is the EICAR anti-virus test file, which every scanner reports as
+// infected without it being harmful
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// fakeClamd serves clamd's INSTREAM command on a local port, reporting any
+// stream containing the EICAR string as infected. It returns the address.
+func fakeClamd(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening for clamd: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveClamdStream(conn)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func serveClamdStream(conn net.Conn) {
+	defer conn.Close()
+
+	command := make([]byte, len("zINSTREAM\x00"))
+	if _, err := io.ReadFull(conn, command); err != nil || string(command) != "zINSTREAM\x00" {
+		conn.Write([]byte("UNKNOWN COMMAND\x00"))
+		return
+	}
+
+	// Chunks are length-prefixed; a zero length ends the stream
+	var stream bytes.Buffer
+	size := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(conn, size); err != nil {
+			return
+		}
+		n := binary.BigEndian.Uint32(size)
+		if n == 0 {
+			break
+		}
+		if _, err := io.CopyN(&stream, conn, int64(n)); err != nil {
+			return
+		}
+	}
+
+	if bytes.Contains(stream.Bytes(), []byte(eicar)) {
+		conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+		return
+	}
+	conn.Write([]byte("stream: OK\x00"))
+}
+
+func TestClamAVScanServiceDetectsEICAR(t *testing.T) {
+	scanner := services.NewClamAVScanService(fakeClamd(t), 5*time.Second)
+
+	// Large enough to span several INSTREAM chunks
+	padding := strings.Repeat("lorem ipsum ", 20000)
+
+	tests := []struct {
+		name       string
+		content    string
+		wantClean  bool
+		wantDetail string
+	}{
+		{"EICAR test file", eicar, false, "Eicar-Test-Signature"},
+		{"EICAR after several chunks", padding + eicar, false, "Eicar-Test-Signature"},
+		{"clean file", "just some notes", true, ""},
+		{"large clean file", padding, true, ""},
+		{"empty file", "", true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clean, detail, err := scanner.Scan(context.Background(), strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			if clean != tt.wantClean || detail != tt.wantDetail {
+				t.Errorf("Scan = clean %v, detail %q, want clean %v, detail %q", clean, detail, tt.wantClean, tt.wantDetail)
+			}
+		})
+	}
+}
+
+func TestClamAVScanServiceUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	clean, _, err := services.NewClamAVScanService(address, time.Second).Scan(context.Background(), strings.NewReader(eicar))
+	if err == nil || clean {
+		t.Errorf("Scan with clamd down = clean %v, err %v, want an error and not clean", clean, err)
+	}
+}
+
+// scanUpload uploads content as a document through a MediaService scanning
+// with scanner, and waits for processing to settle
+func scanUpload(t *testing.T, h *testutil.Harness, scanner services.ScanService, uploader *models.User, content string) (*services.MediaService, models.Media) {
+	t.Helper()
+
+	uploadPath := t.TempDir()
+	media := services.NewMediaService(uploadPath, "http://localhost", false, nil, services.MediaTieringPolicy{}, services.MediaOutputPolicy{},
+		scanner, services.NewNotificationService(nil, nil))
+
+	source := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(source, []byte(content), 0600); err != nil {
+		t.Fatalf("writing upload: %v", err)
+	}
+	file, err := os.Open(source)
+	if err != nil {
+		t.Fatalf("opening upload: %v", err)
+	}
+	defer file.Close()
+
+	result, err := media.UploadMedia(uploader.ID, file, &multipart.FileHeader{Filename: "notes.txt", Size: int64(len(content))}, models.CreateMediaRequest{
+		Type:     "document",
+		IsPublic: true,
+	})
+	if err != nil {
+		t.Fatalf("UploadMedia: %v", err)
+	}
+
+	var stored models.Media
+	h.Eventually(10*time.Second, func() bool {
+		err := h.DB.Collection("media").FindOne(h.Context(), bson.M{"_id": result.Media.ID}).Decode(&stored)
+		return err == nil && stored.ProcessingStatus != "pending"
+	}, "upload %s was never scanned", result.Media.ID.Hex())
+	return media, stored
+}
+
+func TestUploadWithEICARIsQuarantined(t *testing.T) {
+	h := testutil.NewHarness(t)
+	uploader := h.CreateUser()
+	viewer := h.CreateUser()
+
+	media, stored := scanUpload(t, h, services.NewClamAVScanService(fakeClamd(t), 5*time.Second), uploader, eicar)
+
+	if stored.ProcessingStatus != "quarantined" || stored.ModerationStatus != "rejected" || stored.IsPublic {
+		t.Errorf("infected upload = status %q, moderation %q, public %v, want quarantined, rejected and private",
+			stored.ProcessingStatus, stored.ModerationStatus, stored.IsPublic)
+	}
+	if !strings.Contains(stored.ModerationNotes, "Eicar-Test-Signature") {
+		t.Errorf("moderation notes = %q, want the signature found", stored.ModerationNotes)
+	}
+
+	// The file is moved out of the served upload tree
+	if filepath.Base(filepath.Dir(stored.FilePath)) != ".quarantine" {
+		t.Errorf("infected file kept at %s, want it in the quarantine directory", stored.FilePath)
+	}
+	if _, err := os.Stat(stored.FilePath); err != nil {
+		t.Errorf("quarantined file: %v", err)
+	}
+
+	for _, user := range []*models.User{uploader, viewer} {
+		if _, err := media.GetMediaByID(stored.ID, &user.ID); err == nil || err.Error() != "media not found" {
+			t.Errorf("GetMediaByID of quarantined media: err = %v, want media not found", err)
+		}
+	}
+
+	h.Eventually(5*time.Second, func() bool {
+		return h.Count("notifications", bson.M{"recipient_id": uploader.ID, "type": models.NotificationMediaRejected}) == 1
+	}, "uploader wasn't notified of the quarantine")
+}
+
+func TestCleanUploadPassesScan(t *testing.T) {
+	h := testutil.NewHarness(t)
+	uploader := h.CreateUser()
+
+	media, stored := scanUpload(t, h, services.NewClamAVScanService(fakeClamd(t), 5*time.Second), uploader, "just some notes")
+
+	if stored.ProcessingStatus != "completed" || stored.ModerationStatus == "rejected" {
+		t.Errorf("clean upload = status %q, moderation %q, want completed and not rejected", stored.ProcessingStatus, stored.ModerationStatus)
+	}
+	if _, err := os.Stat(stored.FilePath); err != nil {
+		t.Errorf("clean file: %v", err)
+	}
+	if _, err := media.GetMediaByID(stored.ID, &uploader.ID); err != nil {
+		t.Errorf("GetMediaByID of clean media: %v", err)
+	}
+	if got := h.Count("notifications", bson.M{"recipient_id": uploader.ID}); got != 0 {
+		t.Errorf("notifications for a clean upload = %d, want 0", got)
+	}
+}
+
+func TestUploadScanFailureHoldsForModeration(t *testing.T) {
+	h := testutil.NewHarness(t)
+	uploader := h.CreateUser()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	_, stored := scanUpload(t, h, services.NewClamAVScanService(address, time.Second), uploader, "just some notes")
+
+	if stored.ProcessingStatus != "failed" || !stored.IsModerationRequired {
+		t.Errorf("upload with clamd down = status %q, moderation required %v, want failed and held for moderation",
+			stored.ProcessingStatus, stored.IsModerationRequired)
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"social-media-api/internal/config"
 	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -52,6 +53,14 @@ type SearchFilters struct {
 	Location    string `json:"location,omitempty"`
 	Language    string `json:"language,omitempty"`
 	ContentType string `json:"content_type,omitempty"` // "text", "image", "video"
+
+	// Languages restricts posts to these languages (plus undetected ones). Nil
+	// falls back to the user's preferred languages; empty disables filtering.
+	Languages []string `json:"languages,omitempty"`
+
+	// Since restricts posts to ones created after it; saved search alerts
+	// use it to find new posts since their last run
+	Since time.Time `json:"-"`
 }
 
 type SearchHistory struct {
@@ -106,6 +115,9 @@ func (ss *SearchService) Search(query string, userID *primitive.ObjectID, filter
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// Search may be served by a secondary
+	ctx = repository.HeavyRead(ctx, userID)
+
 	// Clean and prepare query
 	cleanQuery := ss.cleanQuery(query)
 	if cleanQuery == "" {
@@ -119,6 +131,10 @@ func (ss *SearchService) Search(query string, userID *primitive.ObjectID, filter
 		}, nil
 	}
 
+	if filters.Languages == nil && userID != nil {
+		filters.Languages = getPreferredLanguages(ctx, ss.userCollection, *userID)
+	}
+
 	var allResults []SearchResult
 	categories := make(map[string][]SearchResult)
 
@@ -196,10 +212,9 @@ func (ss *SearchService) Search(query string, userID *primitive.ObjectID, filter
 // searchPosts searches for posts
 func (ss *SearchService) searchPosts(ctx context.Context, query string, userID *primitive.ObjectID, filters SearchFilters, limit int) ([]SearchResult, error) {
 	// Build search filter
-	searchFilter := bson.M{
+	searchFilter := repository.NotDeleted(bson.M{
 		"is_published": true,
-		"deleted_at":   bson.M{"$exists": false},
-	}
+	})
 
 	// Add visibility filter
 	if userID == nil {
@@ -218,19 +233,31 @@ func (ss *SearchService) searchPosts(ctx context.Context, query string, userID *
 		}
 	}
 
-	// Add text search
+	// Add text search, keeping the visibility condition alongside it
 	searchTerms := ss.buildTextSearchQuery(query)
 	if len(searchTerms) > 0 {
-		searchFilter["$or"] = []bson.M{
+		textMatch := []bson.M{
 			{"content": bson.M{"$regex": searchTerms, "$options": "i"}},
 			{"hashtags": bson.M{"$in": ss.extractHashtags(query)}},
 		}
+		if visibility, ok := searchFilter["$or"]; ok {
+			delete(searchFilter, "$or")
+			searchFilter["$and"] = []bson.M{{"$or": visibility}, {"$or": textMatch}}
+		} else {
+			searchFilter["$or"] = textMatch
+		}
 	}
 
 	// Add date filter
+	createdAt := bson.M{}
 	if filters.DateRange != "" {
-		dateFilter := ss.getDateFilter(filters.DateRange)
-		searchFilter["created_at"] = bson.M{"$gte": dateFilter}
+		createdAt["$gte"] = ss.getDateFilter(filters.DateRange)
+	}
+	if !filters.Since.IsZero() {
+		createdAt["$gt"] = filters.Since
+	}
+	if len(createdAt) > 0 {
+		searchFilter["created_at"] = createdAt
 	}
 
 	// Add content type filter
@@ -241,6 +268,8 @@ func (ss *SearchService) searchPosts(ctx context.Context, query string, userID *
 	// Add language filter
 	if filters.Language != "" {
 		searchFilter["language"] = filters.Language
+	} else if len(filters.Languages) > 0 {
+		searchFilter["language"] = languageMatchFilter(filters.Languages)
 	}
 
 	// Build aggregation pipeline
@@ -268,7 +297,7 @@ func (ss *SearchService) searchPosts(ctx context.Context, query string, userID *
 
 	pipeline = append(pipeline, bson.M{"$limit": limit})
 
-	cursor, err := ss.postCollection.Aggregate(ctx, pipeline)
+	cursor, err := repository.ReadFrom(ctx, ss.postCollection).Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -303,10 +332,9 @@ func (ss *SearchService) searchPosts(ctx context.Context, query string, userID *
 
 // searchUsers searches for users
 func (ss *SearchService) searchUsers(ctx context.Context, query string, userID *primitive.ObjectID, filters SearchFilters, limit int) ([]SearchResult, error) {
-	searchFilter := bson.M{
-		"is_active":  true,
-		"deleted_at": bson.M{"$exists": false},
-	}
+	searchFilter := repository.NotDeleted(bson.M{
+		"is_active": true,
+	})
 
 	// Build text search for users
 	searchTerms := ss.buildTextSearchQuery(query)
@@ -338,7 +366,7 @@ func (ss *SearchService) searchUsers(ctx context.Context, query string, userID *
 
 	pipeline = append(pipeline, bson.M{"$limit": limit})
 
-	cursor, err := ss.userCollection.Aggregate(ctx, pipeline)
+	cursor, err := repository.ReadFrom(ctx, ss.userCollection).Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -390,9 +418,9 @@ func (ss *SearchService) searchHashtags(ctx context.Context, query string, filte
 
 	opts := options.Find().
 		SetLimit(int64(limit)).
-		SetSort(bson.M{"trending_score": -1, "post_count": -1})
+		SetSort(bson.D{{Key: "trending_score", Value: -1}, {Key: "post_count", Value: -1}})
 
-	cursor, err := ss.hashtagCollection.Find(ctx, searchFilter, opts)
+	cursor, err := repository.ReadFrom(ctx, ss.hashtagCollection).Find(ctx, searchFilter, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -425,20 +453,26 @@ func (ss *SearchService) searchHashtags(ctx context.Context, query string, filte
 	return results, nil
 }
 
-// GetTrendingHashtags returns trending hashtags
-func (ss *SearchService) GetTrendingHashtags(limit int, timeRange string) ([]HashtagInfo, error) {
+// GetTrendingHashtags returns trending hashtags, optionally of one category
+func (ss *SearchService) GetTrendingHashtags(limit int, timeRange, category string) ([]HashtagInfo, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
+	ctx = repository.HeavyRead(ctx, nil)
 
 	// Calculate trending score based on recent usage
 	dateFilter := ss.getDateFilter(timeRange)
 
+	match := bson.M{
+		"last_used":  bson.M{"$gte": dateFilter},
+		"is_blocked": false,
+	}
+	if category != "" {
+		match["category"] = category
+	}
+
 	pipeline := []bson.M{
 		{
-			"$match": bson.M{
-				"last_used":  bson.M{"$gte": dateFilter},
-				"is_blocked": false,
-			},
+			"$match": match,
 		},
 		{
 			"$addFields": bson.M{
@@ -481,7 +515,7 @@ func (ss *SearchService) GetTrendingHashtags(limit int, timeRange string) ([]Has
 		},
 	}
 
-	cursor, err := ss.hashtagCollection.Aggregate(ctx, pipeline)
+	cursor, err := repository.ReadFrom(ctx, ss.hashtagCollection).Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -777,9 +811,18 @@ func (ss *SearchService) getHashtagSuggestions(ctx context.Context, query string
 }
 
 func (ss *SearchService) getUserSuggestions(ctx context.Context, query string, limit int) []string {
+	// Anchored prefix match on username_lower can use its index. Users not yet
+	// covered by the username_lower backfill fall back to the old regex.
+	prefix := "^" + regexp.QuoteMeta(strings.ToLower(strings.TrimPrefix(query, "@")))
 	filter := bson.M{
-		"username":  bson.M{"$regex": query, "$options": "i"},
 		"is_active": true,
+		"$or": []bson.M{
+			{"username_lower": bson.M{"$regex": prefix}},
+			{
+				"username_lower": bson.M{"$exists": false},
+				"username":       bson.M{"$regex": prefix, "$options": "i"},
+			},
+		},
 	}
 
 	opts := options.Find().
@@ -0,0 +1,318 @@
+// internal/services/setup_checklist_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"social-media-api/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// setupChecklistConfigTTL bounds how long another instance's admin changes take to show up
+const setupChecklistConfigTTL = 5 * time.Minute
+
+// SetupChecklistCacheMaxAge is how long clients may reuse a checklist response
+const SetupChecklistCacheMaxAge = 3 * time.Minute
+
+// setupTrialPlan is the subscription plan recorded for the checklist completion trial
+const setupTrialPlan = "setup_trial"
+
+// setupChecklistState caches the checklist configuration loaded from app_settings
+var setupChecklistState struct {
+	sync.Mutex
+	config   models.SetupChecklistConfig
+	loadedAt time.Time
+}
+
+// SetupChecklistService computes the guided profile setup checklist from the
+// user's existing data. Completion of each step is recorded on the user once
+// so behavior analytics get a single event per step.
+type SetupChecklistService struct {
+	userCollection     *mongo.Collection
+	memberCollection   *mongo.Collection
+	settingsCollection *mongo.Collection
+	behaviorService    *UserBehaviorService
+	db                 *mongo.Database
+}
+
+func NewSetupChecklistService(db *mongo.Database, behaviorService *UserBehaviorService) *SetupChecklistService {
+	return &SetupChecklistService{
+		userCollection:     db.Collection("users"),
+		memberCollection:   db.Collection("group_members"),
+		settingsCollection: db.Collection("app_settings"),
+		behaviorService:    behaviorService,
+		db:                 db,
+	}
+}
+
+// GetChecklist returns the user's setup checklist. It reads the user document
+// and at most one group membership count.
+func (ss *SetupChecklistService) GetChecklist(userID primitive.ObjectID) (*models.SetupChecklistResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	config := ss.loadConfig(ctx)
+
+	var user models.User
+	err := ss.userCollection.FindOne(ctx, bson.M{"_id": userID}, options.FindOne().SetProjection(bson.M{
+		"profile_pic":            1,
+		"bio":                    1,
+		"email_verified":         1,
+		"phone":                  1,
+		"posts_count":            1,
+		"following_count":        1,
+		"notification_settings":  1,
+		"fcm_tokens":             1,
+		"is_premium":             1,
+		"premium_expiry":         1,
+		"setup_completed_items":  1,
+		"setup_trial_granted_at": 1,
+	})).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	recorded := make(map[models.SetupItemKey]bool, len(user.SetupCompletedItems))
+	for _, key := range user.SetupCompletedItems {
+		recorded[key] = true
+	}
+
+	response := &models.SetupChecklistResponse{
+		Items:            []models.SetupChecklistItem{},
+		CompletionReward: config.CompletionReward,
+	}
+	var newlyCompleted []models.SetupItemKey
+	scoredDone := 0
+
+	for _, item := range config.Items {
+		if !item.Enabled {
+			continue
+		}
+
+		done := ss.isItemDone(ctx, item.Key, &user, config)
+		status := "pending"
+		if done {
+			status = "done"
+			response.CompletedCount++
+			if !recorded[item.Key] {
+				newlyCompleted = append(newlyCompleted, item.Key)
+			}
+		}
+		if item.CountsTowardScore {
+			response.ScoredCount++
+			if done {
+				scoredDone++
+			}
+		}
+
+		response.Items = append(response.Items, models.SetupChecklistItem{
+			Key:               item.Key,
+			Title:             item.Title,
+			Description:       item.Description,
+			Reward:            item.Reward,
+			Status:            status,
+			CountsTowardScore: item.CountsTowardScore,
+		})
+	}
+
+	if response.ScoredCount > 0 {
+		response.Completeness = scoredDone * 100 / response.ScoredCount
+		response.IsComplete = scoredDone == response.ScoredCount
+	}
+
+	if len(newlyCompleted) > 0 {
+		ss.recordCompleted(ctx, userID, newlyCompleted)
+	}
+
+	if user.SetupTrialGrantedAt != nil {
+		response.TrialExpiresAt = user.PremiumExpiry
+	} else if response.IsComplete && config.TrialDays > 0 {
+		response.TrialExpiresAt = ss.grantTrial(ctx, &user, config.TrialDays)
+	}
+
+	return response, nil
+}
+
+// GetConfig returns the checklist configuration in effect
+func (ss *SetupChecklistService) GetConfig() models.SetupChecklistConfig {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return ss.loadConfig(ctx)
+}
+
+// UpdateConfig replaces the checklist configuration
+func (ss *SetupChecklistService) UpdateConfig(adminID primitive.ObjectID, req models.UpdateSetupChecklistConfigRequest) (*models.SetupChecklistConfig, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	items := append([]models.SetupChecklistItemConfig(nil), req.Items...)
+	for i := range items {
+		items[i].Title = strings.TrimSpace(items[i].Title)
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Order < items[j].Order })
+
+	config := models.SetupChecklistConfig{
+		Items:            items,
+		FollowTarget:     req.FollowTarget,
+		TrialDays:        req.TrialDays,
+		CompletionReward: strings.TrimSpace(req.CompletionReward),
+		UpdatedAt:        time.Now(),
+		UpdatedBy:        adminID.Hex(),
+	}
+
+	_, err := ss.settingsCollection.UpdateOne(ctx,
+		bson.M{"_id": models.SetupChecklistSettingsKey},
+		bson.M{"$set": bson.M{"value": config, "updated_at": config.UpdatedAt}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save checklist configuration: %w", err)
+	}
+
+	setupChecklistState.Lock()
+	setupChecklistState.config = config
+	setupChecklistState.loadedAt = time.Now()
+	setupChecklistState.Unlock()
+
+	return &config, nil
+}
+
+// Helper methods
+
+// isItemDone evaluates one checklist step against the user's data
+func (ss *SetupChecklistService) isItemDone(ctx context.Context, key models.SetupItemKey, user *models.User, config models.SetupChecklistConfig) bool {
+	switch key {
+	case models.SetupItemAvatar:
+		return user.ProfilePic != ""
+	case models.SetupItemBio:
+		return strings.TrimSpace(user.Bio) != ""
+	case models.SetupItemEmailVerified:
+		return user.EmailVerified
+	case models.SetupItemPhoneVerified:
+		return user.Phone != ""
+	case models.SetupItemFirstPost:
+		return user.PostsCount > 0
+	case models.SetupItemFollowing:
+		return user.FollowingCount >= config.FollowTarget
+	case models.SetupItemJoinGroup:
+		count, err := ss.memberCollection.CountDocuments(ctx, bson.M{
+			"user_id": user.ID,
+			"status":  "active",
+		}, options.Count().SetLimit(1))
+		return err == nil && count > 0
+	case models.SetupItemNotifications:
+		return user.NotificationSettings.PushNotifications && len(user.FCMTokens) > 0
+	}
+	return false
+}
+
+// recordCompleted marks steps as completed on the user and emits one
+// analytics event per step
+func (ss *SetupChecklistService) recordCompleted(ctx context.Context, userID primitive.ObjectID, keys []models.SetupItemKey) {
+	_, err := ss.userCollection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+		"$addToSet": bson.M{"setup_completed_items": bson.M{"$each": keys}},
+	})
+	if err != nil {
+		log.Printf("Failed to record setup checklist progress for user %s: %v", userID.Hex(), err)
+		return
+	}
+
+	if ss.behaviorService == nil {
+		return
+	}
+	go func() {
+		for _, key := range keys {
+			ss.behaviorService.RecordInteraction(userID, userID, "setup_checklist", "setup_item_completed", "setup_checklist", map[string]interface{}{
+				"item": string(key),
+			})
+		}
+	}()
+}
+
+// grantTrial gives the user a premium trial for finishing the checklist, once.
+// Users who are already premium keep their plan and don't use up the trial.
+func (ss *SetupChecklistService) grantTrial(ctx context.Context, user *models.User, trialDays int) *time.Time {
+	if user.IsPremium {
+		return nil
+	}
+
+	now := time.Now()
+	expiry := now.AddDate(0, 0, trialDays)
+	result, err := ss.userCollection.UpdateOne(ctx, bson.M{
+		"_id":                    user.ID,
+		"is_premium":             bson.M{"$ne": true},
+		"setup_trial_granted_at": bson.M{"$exists": false},
+	}, bson.M{
+		"$set": bson.M{
+			"is_premium":             true,
+			"premium_expiry":         expiry,
+			"subscription_plan":      setupTrialPlan,
+			"setup_trial_granted_at": now,
+			"updated_at":             now,
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to grant setup trial to user %s: %v", user.ID.Hex(), err)
+		return nil
+	}
+	if result.ModifiedCount == 0 {
+		return nil
+	}
+
+	if ss.behaviorService != nil {
+		go ss.behaviorService.RecordInteraction(user.ID, user.ID, "setup_checklist", "setup_trial_granted", "setup_checklist", map[string]interface{}{
+			"trial_days": trialDays,
+		})
+	}
+
+	return &expiry
+}
+
+// loadConfig returns the cached checklist configuration, reloading it once it
+// is older than the TTL. The built-in default applies until an admin saves one.
+func (ss *SetupChecklistService) loadConfig(ctx context.Context) models.SetupChecklistConfig {
+	setupChecklistState.Lock()
+	defer setupChecklistState.Unlock()
+
+	if !setupChecklistState.loadedAt.IsZero() && time.Since(setupChecklistState.loadedAt) < setupChecklistConfigTTL {
+		return setupChecklistState.config
+	}
+
+	var stored struct {
+		Value models.SetupChecklistConfig `bson:"value"`
+	}
+	err := ss.settingsCollection.FindOne(ctx, bson.M{"_id": models.SetupChecklistSettingsKey}).Decode(&stored)
+	switch {
+	case err == nil:
+		setupChecklistState.config = stored.Value
+	case err == mongo.ErrNoDocuments:
+		setupChecklistState.config = models.DefaultSetupChecklistConfig()
+	default:
+		log.Printf("Failed to load setup checklist configuration: %v", err)
+		if setupChecklistState.loadedAt.IsZero() {
+			setupChecklistState.config = models.DefaultSetupChecklistConfig()
+		}
+	}
+
+	setupChecklistState.loadedAt = time.Now()
+	return setupChecklistState.config
+}
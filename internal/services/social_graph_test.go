@@ -0,0 +1,280 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// hideSocialGraph turns on hide_social_graph through the privacy settings update
+func hideSocialGraph(t *testing.T, h *testutil.Harness, user *models.User) {
+	t.Helper()
+
+	settings := user.PrivacySettings
+	settings.HideSocialGraph = true
+	if err := newTestUserService(h).UpdateUserPrivacySettings(user.ID, settings); err != nil {
+		t.Fatalf("UpdateUserPrivacySettings: %v", err)
+	}
+}
+
+func TestHiddenSocialGraphListsOnlyForSelf(t *testing.T) {
+	h := testutil.NewHarness(t)
+	follows := newTestFollowService(h)
+
+	owner := h.CreateUser()
+	follower := h.CreateUser()
+	followee := h.CreateUser()
+	viewer := h.CreateUser()
+	h.CreateFollow(follower, owner, models.FollowStatusAccepted)
+	h.CreateFollow(owner, followee, models.FollowStatusAccepted)
+	h.CreateFollow(viewer, followee, models.FollowStatusAccepted)
+
+	hideSocialGraph(t, h, owner)
+
+	lists := []struct {
+		name string
+		list func(viewerID *primitive.ObjectID) error
+	}{
+		{"followers", func(viewerID *primitive.ObjectID) error {
+			_, err := follows.GetFollowers(owner.ID, viewerID, 20, 0)
+			return err
+		}},
+		{"following", func(viewerID *primitive.ObjectID) error {
+			_, err := follows.GetFollowing(owner.ID, viewerID, 20, 0)
+			return err
+		}},
+	}
+
+	for _, tt := range lists {
+		if err := tt.list(&viewer.ID); err == nil || err.Error() != "social graph hidden" {
+			t.Errorf("%s as another user: err = %v, want social graph hidden", tt.name, err)
+		}
+		if err := tt.list(nil); err == nil || err.Error() != "social graph hidden" {
+			t.Errorf("%s anonymously: err = %v, want social graph hidden", tt.name, err)
+		}
+		if err := tt.list(&owner.ID); err != nil {
+			t.Errorf("%s as the owner: %v", tt.name, err)
+		}
+	}
+
+	if _, err := follows.GetMutualFollows(viewer.ID, owner.ID, 20, 0); err == nil || err.Error() != "social graph hidden" {
+		t.Errorf("mutual follows with the owner: err = %v, want social graph hidden", err)
+	}
+
+	followers, err := follows.GetFollowers(owner.ID, &owner.ID, 20, 0)
+	if err != nil || len(followers) != 1 || followers[0].FollowerID != follower.ID.Hex() {
+		t.Errorf("owner's own followers = %v (err %v), want the one follower", followers, err)
+	}
+}
+
+func TestMutualFollowsLeaveOutHiddenGraphs(t *testing.T) {
+	h := testutil.NewHarness(t)
+	follows := newTestFollowService(h)
+
+	viewer := h.CreateUser()
+	other := h.CreateUser()
+	hidden := h.CreateUser()
+	visible := h.CreateUser()
+	for _, user := range []*models.User{viewer, other} {
+		h.CreateFollow(user, hidden, models.FollowStatusAccepted)
+		h.CreateFollow(user, visible, models.FollowStatusAccepted)
+	}
+
+	hideSocialGraph(t, h, hidden)
+
+	mutuals, err := follows.GetMutualFollows(viewer.ID, other.ID, 20, 0)
+	if err != nil {
+		t.Fatalf("GetMutualFollows: %v", err)
+	}
+	if len(mutuals) != 1 || mutuals[0].ID != visible.ID.Hex() {
+		t.Errorf("mutual follows = %v, want only the account that doesn't hide its graph", mutuals)
+	}
+}
+
+func TestProfileFlagsHiddenSocialGraph(t *testing.T) {
+	h := testutil.NewHarness(t)
+	users := newTestUserService(h)
+
+	owner := h.CreateUser()
+	viewer := h.CreateUser()
+	hideSocialGraph(t, h, owner)
+
+	profile, err := users.GetUserProfile(owner.ID, viewer.ID)
+	if err != nil {
+		t.Fatalf("GetUserProfile: %v", err)
+	}
+	if !profile.SocialGraphHidden {
+		t.Error("profile seen by another user doesn't flag the hidden social graph")
+	}
+
+	own, err := users.GetUserProfile(owner.ID, owner.ID)
+	if err != nil {
+		t.Fatalf("GetUserProfile of self: %v", err)
+	}
+	if own.SocialGraphHidden {
+		t.Error("owner's own profile flags their social graph as hidden")
+	}
+}
+
+// suggestionGraph is a viewer following two intermediaries, one of which
+// will hide their social graph. Each intermediary follows an account only
+// they lead to, and both follow a shared one.
+type suggestionGraph struct {
+	viewer, hiding, open        *models.User
+	viaHiding, viaOpen, viaBoth *models.User
+}
+
+func newSuggestionGraph(h *testutil.Harness) suggestionGraph {
+	g := suggestionGraph{
+		viewer:    h.CreateUser(),
+		hiding:    h.CreateUser(),
+		open:      h.CreateUser(),
+		viaHiding: h.CreateUser(),
+		viaOpen:   h.CreateUser(),
+		viaBoth:   h.CreateUser(),
+	}
+	h.CreateFollow(g.viewer, g.hiding, models.FollowStatusAccepted)
+	h.CreateFollow(g.viewer, g.open, models.FollowStatusAccepted)
+	h.CreateFollow(g.hiding, g.viaHiding, models.FollowStatusAccepted)
+	h.CreateFollow(g.hiding, g.viaBoth, models.FollowStatusAccepted)
+	h.CreateFollow(g.open, g.viaOpen, models.FollowStatusAccepted)
+	h.CreateFollow(g.open, g.viaBoth, models.FollowStatusAccepted)
+	return g
+}
+
+// assertNoLeak checks the suggestions neither come through the hiding user's
+// follows nor name them as a mutual connection
+func (g suggestionGraph) assertNoLeak(t *testing.T, suggestions []models.SuggestedUserResponse) {
+	t.Helper()
+
+	found := map[string]models.SuggestedUserResponse{}
+	for _, suggestion := range suggestions {
+		found[suggestion.User.ID] = suggestion
+		for _, username := range suggestion.MutualUsernames {
+			if username == g.hiding.Username {
+				t.Errorf("suggestion %s names the hiding user as a mutual connection", suggestion.User.Username)
+			}
+		}
+	}
+
+	if suggestion, ok := found[g.viaHiding.ID.Hex()]; ok && suggestion.Source == models.SuggestionSourceMutual {
+		t.Error("account reachable only through the hiding user is suggested as a mutual connection")
+	}
+	for _, want := range []*models.User{g.viaOpen, g.viaBoth} {
+		if suggestion, ok := found[want.ID.Hex()]; !ok || suggestion.Source != models.SuggestionSourceMutual {
+			t.Errorf("%s isn't suggested through the open user", want.Username)
+		}
+	}
+	if suggestion := found[g.viaBoth.ID.Hex()]; suggestion.MutualCount != 1 {
+		t.Errorf("shared account mutual_count = %d, want 1 once the hiding user's follow is left out", suggestion.MutualCount)
+	}
+}
+
+func TestSuggestionsDropHiddenGraphImmediately(t *testing.T) {
+	h := testutil.NewHarness(t)
+	suggestions := services.NewSuggestionService(h.DB)
+	g := newSuggestionGraph(h)
+
+	before, err := suggestions.GetSuggestions(g.viewer.ID, 10)
+	if err != nil {
+		t.Fatalf("GetSuggestions: %v", err)
+	}
+	var throughHiding bool
+	for _, suggestion := range before {
+		throughHiding = throughHiding || suggestion.User.ID == g.viaHiding.ID.Hex() && suggestion.Source == models.SuggestionSourceMutual
+	}
+	if !throughHiding {
+		t.Fatal("before hiding, the hiding user's follows don't produce suggestions")
+	}
+
+	hideSocialGraph(t, h, g.hiding)
+
+	// Stored lists built through the user are dropped and rebuilt without them
+	h.Eventually(5*time.Second, func() bool {
+		return h.Count("suggestions", bson.M{"user_id": g.viewer.ID}) == 0
+	}, "stored suggestions of the hiding user's followers weren't invalidated")
+
+	after, err := suggestions.GetSuggestions(g.viewer.ID, 10)
+	if err != nil {
+		t.Fatalf("GetSuggestions after hiding: %v", err)
+	}
+	g.assertNoLeak(t, after)
+}
+
+func TestStoredSuggestionsHideSocialProofAtRequestTime(t *testing.T) {
+	h := testutil.NewHarness(t)
+	suggestions := services.NewSuggestionService(h.DB)
+	g := newSuggestionGraph(h)
+
+	if _, err := suggestions.GetSuggestions(g.viewer.ID, 10); err != nil {
+		t.Fatalf("GetSuggestions: %v", err)
+	}
+
+	// Set the flag without the invalidation, as if the stored list predates it
+	if _, err := h.DB.Collection("users").UpdateOne(h.Context(),
+		bson.M{"_id": g.hiding.ID},
+		bson.M{"$set": bson.M{"privacy_settings.hide_social_graph": true}},
+	); err != nil {
+		t.Fatalf("hiding the social graph: %v", err)
+	}
+
+	after, err := suggestions.GetSuggestions(g.viewer.ID, 10)
+	if err != nil {
+		t.Fatalf("GetSuggestions after hiding: %v", err)
+	}
+	for _, suggestion := range after {
+		for _, username := range suggestion.MutualUsernames {
+			if username == g.hiding.Username {
+				t.Errorf("stored suggestion %s still names the hiding user", suggestion.User.Username)
+			}
+		}
+	}
+}
+
+func TestSuggestionJobSkipsHiddenGraphs(t *testing.T) {
+	h := testutil.NewHarness(t)
+	suggestions := services.NewSuggestionService(h.DB)
+	g := newSuggestionGraph(h)
+
+	hideSocialGraph(t, h, g.hiding)
+	if _, err := h.DB.Collection("users").UpdateOne(h.Context(),
+		bson.M{"_id": g.viewer.ID},
+		bson.M{"$set": bson.M{"last_active_at": time.Now()}},
+	); err != nil {
+		t.Fatalf("marking the viewer active: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(h.Context(), 30*time.Second)
+	defer cancel()
+	if _, err := suggestions.RefreshSuggestions(ctx); err != nil {
+		t.Fatalf("RefreshSuggestions: %v", err)
+	}
+
+	var stored models.UserSuggestions
+	if err := h.DB.Collection("suggestions").FindOne(h.Context(), bson.M{"user_id": g.viewer.ID}).Decode(&stored); err != nil {
+		t.Fatalf("the job stored no suggestions for the viewer: %v", err)
+	}
+	for _, candidate := range stored.Candidates {
+		if candidate.UserID == g.viaHiding.ID {
+			t.Error("the job generated a candidate through the hiding user's follows")
+		}
+		for _, mutualID := range candidate.MutualIDs {
+			if mutualID == g.hiding.ID {
+				t.Errorf("candidate %s lists the hiding user as a mutual connection", candidate.UserID.Hex())
+			}
+		}
+	}
+
+	built, err := suggestions.GetSuggestions(g.viewer.ID, 10)
+	if err != nil {
+		t.Fatalf("GetSuggestions: %v", err)
+	}
+	g.assertNoLeak(t, built)
+}
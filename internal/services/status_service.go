@@ -0,0 +1,693 @@
+// internal/services/status_service.go
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"social-media-api/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StatusPolicy configures the uptime recorder and the public status page
+type StatusPolicy struct {
+	SampleInterval time.Duration
+	SlowThreshold  time.Duration
+	CacheTTL       time.Duration
+	WebhookURL     string
+	WebhookSecret  string
+}
+
+const (
+	// pendingSampleLimit caps the samples kept in memory while the database
+	// can't be written to; the oldest are dropped first
+	pendingSampleLimit = 1440
+
+	// A job whose heartbeat is this many intervals overdue is reported degraded
+	missedHeartbeats = 2
+)
+
+// StatusService records health samples and serves the public status page.
+//
+// The page is rebuilt at most once per CacheTTL and the last snapshot is kept
+// in memory, so it keeps answering from that snapshot when MongoDB is slow or
+// unreachable. Current API and database status come from this instance's own
+// probes and never need the database.
+type StatusService struct {
+	samples   *mongo.Collection
+	incidents *mongo.Collection
+	db        *mongo.Database
+	policy    StatusPolicy
+	client    *http.Client
+
+	mu         sync.RWMutex
+	snapshot   *models.StatusPageResponse
+	snapshotAt time.Time
+	probes     map[string]models.ComponentStatus
+	pending    []models.StatusSample
+
+	refreshMu    sync.Mutex
+	stopRecorder context.CancelFunc
+}
+
+func NewStatusService(db *mongo.Database, policy StatusPolicy) *StatusService {
+	return &StatusService{
+		samples:   db.Collection("status_samples"),
+		incidents: db.Collection("incidents"),
+		db:        db,
+		policy:    policy,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		probes:    make(map[string]models.ComponentStatus),
+	}
+}
+
+// StartRecorder probes the API and database every SampleInterval until
+// StopRecorder is called
+func (ss *StatusService) StartRecorder() {
+	ctx, cancel := context.WithCancel(context.Background())
+	ss.stopRecorder = cancel
+
+	go func() {
+		ticker := time.NewTicker(ss.policy.SampleInterval)
+		defer ticker.Stop()
+
+		ss.recordProbes(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				ss.recordProbes(ctx)
+			}
+		}
+	}()
+}
+
+// StopRecorder stops the periodic probes
+func (ss *StatusService) StopRecorder() {
+	if ss.stopRecorder != nil {
+		ss.stopRecorder()
+	}
+}
+
+// GetStatusPage returns the public status page, rebuilding it when the cached
+// snapshot is older than CacheTTL. If the rebuild fails the last snapshot is
+// served marked stale.
+func (ss *StatusService) GetStatusPage() *models.StatusPageResponse {
+	if page := ss.cachedPage(); page != nil {
+		return page
+	}
+
+	// One rebuild at a time; everyone else waits for its result
+	ss.refreshMu.Lock()
+	defer ss.refreshMu.Unlock()
+	if page := ss.cachedPage(); page != nil {
+		return page
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	page, err := ss.buildStatusPage(ctx)
+	if err != nil {
+		log.Printf("Failed to rebuild status page: %v", err)
+		return ss.fallbackPage()
+	}
+
+	ss.mu.Lock()
+	ss.snapshot = page
+	ss.snapshotAt = time.Now()
+	ss.mu.Unlock()
+
+	return page
+}
+
+// CacheTTL is how long clients and proxies may cache the status page
+func (ss *StatusService) CacheTTL() time.Duration {
+	return ss.policy.CacheTTL
+}
+
+// Incident management
+
+// CreateIncident opens an incident on the status page
+func (ss *StatusService) CreateIncident(adminID primitive.ObjectID, req models.CreateIncidentRequest) (*models.Incident, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	status := req.Status
+	if status == "" {
+		status = models.IncidentInvestigating
+	}
+
+	now := time.Now()
+	incident := &models.Incident{
+		Title:              req.Title,
+		Severity:           req.Severity,
+		Status:             status,
+		AffectedComponents: req.AffectedComponents,
+		Updates: []models.IncidentUpdate{{
+			Status:    status,
+			Message:   req.Message,
+			CreatedBy: adminID,
+			CreatedAt: now,
+		}},
+		CreatedBy: adminID,
+	}
+	incident.BeforeCreate()
+
+	result, err := ss.incidents.InsertOne(ctx, incident)
+	if err != nil {
+		return nil, err
+	}
+	incident.ID = result.InsertedID.(primitive.ObjectID)
+
+	ss.incidentChanged(incident, "incident.created", req.Notify)
+	return incident, nil
+}
+
+// UpdateIncident corrects an incident's title, severity or affected components
+func (ss *StatusService) UpdateIncident(incidentID primitive.ObjectID, req models.UpdateIncidentRequest) (*models.Incident, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	set := bson.M{"updated_at": time.Now()}
+	if req.Title != nil {
+		set["title"] = *req.Title
+	}
+	if req.Severity != nil {
+		set["severity"] = *req.Severity
+	}
+	if len(req.AffectedComponents) > 0 {
+		set["affected_components"] = req.AffectedComponents
+	}
+
+	var incident models.Incident
+	err := ss.incidents.FindOneAndUpdate(ctx, bson.M{"_id": incidentID}, bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&incident)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("incident not found")
+		}
+		return nil, err
+	}
+
+	ss.incidentChanged(&incident, "incident.updated", req.Notify)
+	return &incident, nil
+}
+
+// AddIncidentUpdate posts to an incident's timeline and moves it to the
+// update's status. Resolved incidents can't be updated.
+func (ss *StatusService) AddIncidentUpdate(incidentID, adminID primitive.ObjectID, req models.AddIncidentUpdateRequest) (*models.Incident, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	set := bson.M{"status": req.Status, "updated_at": now}
+	if req.Status == models.IncidentResolved {
+		set["resolved_at"] = now
+	}
+
+	var incident models.Incident
+	err := ss.incidents.FindOneAndUpdate(ctx, bson.M{
+		"_id":    incidentID,
+		"status": bson.M{"$ne": models.IncidentResolved},
+		// Guard the timeline cap in the filter so concurrent updates can't exceed it
+		fmt.Sprintf("updates.%d", models.MaxIncidentUpdates-1): bson.M{"$exists": false},
+	}, bson.M{
+		"$set": set,
+		"$push": bson.M{"updates": models.IncidentUpdate{
+			Status:    req.Status,
+			Message:   req.Message,
+			CreatedBy: adminID,
+			CreatedAt: now,
+		}},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&incident)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ss.incidentUpdateError(ctx, incidentID)
+		}
+		return nil, err
+	}
+
+	event := "incident.updated"
+	if incident.IsResolved() {
+		event = "incident.resolved"
+	}
+	ss.incidentChanged(&incident, event, req.Notify)
+	return &incident, nil
+}
+
+// ResolveIncident closes an incident with a final timeline update
+func (ss *StatusService) ResolveIncident(incidentID, adminID primitive.ObjectID, req models.ResolveIncidentRequest) (*models.Incident, error) {
+	return ss.AddIncidentUpdate(incidentID, adminID, models.AddIncidentUpdateRequest{
+		Status:  models.IncidentResolved,
+		Message: req.Message,
+		Notify:  req.Notify,
+	})
+}
+
+// GetIncidents lists incidents, newest first, optionally filtered by status
+func (ss *StatusService) GetIncidents(status models.IncidentStatus, limit, skip int) ([]models.Incident, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"created_at": -1}).
+		SetLimit(int64(limit)).
+		SetSkip(int64(skip))
+
+	cursor, err := ss.incidents.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var incidents []models.Incident
+	if err := cursor.All(ctx, &incidents); err != nil {
+		return nil, err
+	}
+
+	return incidents, nil
+}
+
+// GetIncident retrieves an incident
+func (ss *StatusService) GetIncident(incidentID primitive.ObjectID) (*models.Incident, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var incident models.Incident
+	if err := ss.incidents.FindOne(ctx, bson.M{"_id": incidentID}).Decode(&incident); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("incident not found")
+		}
+		return nil, err
+	}
+
+	return &incident, nil
+}
+
+// Helper methods
+
+// recordProbes samples this instance's API and database health. Samples that
+// can't be written are kept and written with the next successful probe.
+func (ss *StatusService) recordProbes(ctx context.Context) {
+	now := time.Now()
+
+	pingCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	start := time.Now()
+	err := ss.db.Client().Ping(pingCtx, nil)
+	latency := time.Since(start)
+	cancel()
+
+	dbStatus := models.ComponentOperational
+	switch {
+	case err != nil:
+		dbStatus = models.ComponentOutage
+	case latency > ss.policy.SlowThreshold:
+		dbStatus = models.ComponentDegraded
+	}
+
+	// This instance answering at all is what the API sample records
+	samples := []models.StatusSample{
+		{Component: models.StatusComponentAPI, Status: models.ComponentOperational, RecordedAt: now},
+		{Component: models.StatusComponentDatabase, Status: dbStatus, LatencyMs: latency.Milliseconds(), RecordedAt: now},
+	}
+
+	ss.mu.Lock()
+	ss.probes[models.StatusComponentAPI] = models.ComponentOperational
+	ss.probes[models.StatusComponentDatabase] = dbStatus
+	pending := append(ss.pending, samples...)
+	if len(pending) > pendingSampleLimit {
+		pending = pending[len(pending)-pendingSampleLimit:]
+	}
+	ss.pending = nil
+	ss.mu.Unlock()
+
+	if dbStatus == models.ComponentOutage {
+		ss.requeueSamples(pending)
+		return
+	}
+
+	docs := make([]interface{}, len(pending))
+	for i := range pending {
+		docs[i] = pending[i]
+	}
+
+	writeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if _, err := ss.samples.InsertMany(writeCtx, docs); err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Failed to record status samples: %v", err)
+		}
+		ss.requeueSamples(pending)
+	}
+}
+
+func (ss *StatusService) requeueSamples(samples []models.StatusSample) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	pending := append(samples, ss.pending...)
+	if len(pending) > pendingSampleLimit {
+		pending = pending[len(pending)-pendingSampleLimit:]
+	}
+	ss.pending = pending
+}
+
+func (ss *StatusService) cachedPage() *models.StatusPageResponse {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	if ss.snapshot != nil && time.Since(ss.snapshotAt) < ss.policy.CacheTTL {
+		return ss.snapshot
+	}
+	return nil
+}
+
+// fallbackPage serves the last snapshot marked stale with current probe
+// results, or a page built from the probes alone when there is no snapshot
+func (ss *StatusService) fallbackPage() *models.StatusPageResponse {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	page := models.StatusPageResponse{Incidents: []models.PublicIncidentResponse{}}
+	if ss.snapshot != nil {
+		page = *ss.snapshot
+	}
+	page.Stale = true
+	page.UpdatedAt = time.Now()
+
+	components := make([]models.ComponentStatusResponse, 0, len(models.StatusComponents))
+	for _, name := range models.StatusComponents {
+		component := models.ComponentStatusResponse{Name: name, Status: models.ComponentOperational, Daily: []models.DailyUptime{}}
+		for _, previous := range page.Components {
+			if previous.Name == name {
+				component = previous
+			}
+		}
+		if status, ok := ss.probes[name]; ok {
+			component.Status = status
+		}
+		components = append(components, component)
+	}
+	page.Components = components
+	page.Status = worstStatus(components)
+
+	return &page
+}
+
+func (ss *StatusService) buildStatusPage(ctx context.Context) (*models.StatusPageResponse, error) {
+	daily, err := ss.dailyUptime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	jobsStatus, err := ss.jobsStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := ss.incidents.Find(ctx, bson.M{"status": bson.M{"$ne": models.IncidentResolved}},
+		options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+	var incidents []models.Incident
+	if err := cursor.All(ctx, &incidents); err != nil {
+		return nil, err
+	}
+
+	ss.mu.RLock()
+	current := map[string]models.ComponentStatus{
+		models.StatusComponentAPI:      ss.probes[models.StatusComponentAPI],
+		models.StatusComponentDatabase: ss.probes[models.StatusComponentDatabase],
+		models.StatusComponentJobs:     jobsStatus,
+	}
+	ss.mu.RUnlock()
+
+	// An open incident marks its components at least as bad as its severity
+	for _, incident := range incidents {
+		for _, name := range incident.AffectedComponents {
+			if incident.Severity.ComponentStatus().Severity() > current[name].Severity() {
+				current[name] = incident.Severity.ComponentStatus()
+			}
+		}
+	}
+
+	components := make([]models.ComponentStatusResponse, 0, len(models.StatusComponents))
+	for _, name := range models.StatusComponents {
+		status := current[name]
+		if status == "" {
+			status = models.ComponentOperational
+		}
+
+		component := models.ComponentStatusResponse{Name: name, Status: status, Daily: []models.DailyUptime{}}
+		var up, total int64
+		for _, day := range daily[name] {
+			component.Daily = append(component.Daily, models.DailyUptime{
+				Date:   day.Date,
+				Uptime: uptimePercent(day.Up, day.Total),
+			})
+			up += day.Up
+			total += day.Total
+		}
+		if total > 0 {
+			uptime := uptimePercent(up, total)
+			component.Uptime = &uptime
+		}
+		components = append(components, component)
+	}
+
+	page := &models.StatusPageResponse{
+		Status:     worstStatus(components),
+		Components: components,
+		Incidents:  make([]models.PublicIncidentResponse, len(incidents)),
+		UpdatedAt:  time.Now(),
+	}
+	for i := range incidents {
+		page.Incidents[i] = incidents[i].ToPublicIncidentResponse()
+	}
+
+	return page, nil
+}
+
+type dailySamples struct {
+	Date  string
+	Up    int64
+	Total int64
+}
+
+// dailyUptime counts each component's samples per UTC day over the history
+// window. Outage samples are downtime; degraded ones still count as up.
+func (ss *StatusService) dailyUptime(ctx context.Context) (map[string][]dailySamples, error) {
+	since := time.Now().AddDate(0, 0, -models.StatusHistoryDays)
+
+	pipeline := []bson.M{
+		{"$match": bson.M{"recorded_at": bson.M{"$gte": since}}},
+		{"$group": bson.M{
+			"_id": bson.M{
+				"component": "$component",
+				"date":      bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$recorded_at"}},
+			},
+			"total": bson.M{"$sum": 1},
+			"up": bson.M{"$sum": bson.M{
+				"$cond": []interface{}{bson.M{"$ne": []interface{}{"$status", models.ComponentOutage}}, 1, 0},
+			}},
+		}},
+		{"$sort": bson.M{"_id.date": 1}},
+	}
+
+	cursor, err := ss.samples.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	daily := make(map[string][]dailySamples)
+	for cursor.Next(ctx) {
+		var result struct {
+			ID struct {
+				Component string `bson:"component"`
+				Date      string `bson:"date"`
+			} `bson:"_id"`
+			Total int64 `bson:"total"`
+			Up    int64 `bson:"up"`
+		}
+		if err := cursor.Decode(&result); err != nil {
+			continue
+		}
+		daily[result.ID.Component] = append(daily[result.ID.Component], dailySamples{
+			Date:  result.ID.Date,
+			Up:    result.Up,
+			Total: result.Total,
+		})
+	}
+
+	return daily, cursor.Err()
+}
+
+// jobsStatus reports background jobs degraded when any job's last run failed
+// or its heartbeat is overdue. Jobs that haven't reported recently are assumed
+// disabled and ignored.
+func (ss *StatusService) jobsStatus(ctx context.Context) (models.ComponentStatus, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{
+			"component":   models.StatusComponentJobs,
+			"recorded_at": bson.M{"$gte": time.Now().AddDate(0, 0, -2)},
+		}},
+		{"$sort": bson.M{"recorded_at": -1}},
+		{"$group": bson.M{
+			"_id":         "$source",
+			"status":      bson.M{"$first": "$status"},
+			"interval":    bson.M{"$first": "$interval"},
+			"recorded_at": bson.M{"$first": "$recorded_at"},
+		}},
+	}
+
+	cursor, err := ss.samples.Aggregate(ctx, pipeline)
+	if err != nil {
+		return "", err
+	}
+	defer cursor.Close(ctx)
+
+	status := models.ComponentOperational
+	for cursor.Next(ctx) {
+		var job struct {
+			Status     models.ComponentStatus `bson:"status"`
+			Interval   int64                  `bson:"interval"`
+			RecordedAt time.Time              `bson:"recorded_at"`
+		}
+		if err := cursor.Decode(&job); err != nil {
+			continue
+		}
+
+		overdue := job.Interval > 0 && time.Since(job.RecordedAt) > missedHeartbeats*time.Duration(job.Interval)*time.Second
+		if overdue || job.Status != models.ComponentOperational {
+			status = models.ComponentDegraded
+		}
+	}
+
+	return status, cursor.Err()
+}
+
+// incidentUpdateError explains why an incident couldn't be updated
+func (ss *StatusService) incidentUpdateError(ctx context.Context, incidentID primitive.ObjectID) error {
+	var incident models.Incident
+	if err := ss.incidents.FindOne(ctx, bson.M{"_id": incidentID}).Decode(&incident); err != nil {
+		return errors.New("incident not found")
+	}
+	if incident.IsResolved() {
+		return errors.New("incident already resolved")
+	}
+	return fmt.Errorf("incident timeline is limited to %d updates", models.MaxIncidentUpdates)
+}
+
+// incidentChanged drops the cached page so the change shows at once and, when
+// asked, posts the change to the status webhook
+func (ss *StatusService) incidentChanged(incident *models.Incident, event string, notify bool) {
+	ss.mu.Lock()
+	ss.snapshotAt = time.Time{}
+	ss.mu.Unlock()
+
+	if notify && ss.policy.WebhookURL != "" {
+		go ss.postWebhook(event, incident.ToPublicIncidentResponse())
+	}
+}
+
+// postWebhook sends an incident change to the status webhook. The body is
+// signed with HMAC-SHA256 of the webhook secret.
+func (ss *StatusService) postWebhook(event string, incident models.PublicIncidentResponse) {
+	body, err := json.Marshal(map[string]interface{}{
+		"event":    event,
+		"incident": incident,
+		"sent_at":  time.Now(),
+	})
+	if err != nil {
+		log.Printf("Failed to encode status webhook: %v", err)
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, ss.policy.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		log.Printf("Failed to create status webhook request: %v", err)
+		return
+	}
+
+	mac := hmac.New(sha256.New, []byte(ss.policy.WebhookSecret))
+	mac.Write(body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", event)
+	req.Header.Set("X-Webhook-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+	resp, err := ss.client.Do(req)
+	if err != nil {
+		log.Printf("Failed to post status webhook for incident %s: %v", incident.ID, err)
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		log.Printf("Status webhook for incident %s returned %d", incident.ID, resp.StatusCode)
+	}
+}
+
+// recordJobHeartbeat records that a background job ran, and whether it
+// failed, so the status page can tell when jobs stop running. interval is how
+// long until the job's next run is due.
+func recordJobHeartbeat(db *mongo.Database, job string, interval time.Duration, runErr error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	status := models.ComponentOperational
+	if runErr != nil {
+		status = models.ComponentDegraded
+	}
+
+	_, err := db.Collection("status_samples").InsertOne(ctx, models.StatusSample{
+		Component:  models.StatusComponentJobs,
+		Source:     job,
+		Status:     status,
+		Interval:   int64(interval.Seconds()),
+		RecordedAt: time.Now(),
+	})
+	if err != nil {
+		log.Printf("Failed to record %s heartbeat: %v", job, err)
+	}
+}
+
+func uptimePercent(up, total int64) float64 {
+	if total == 0 {
+		return 100
+	}
+	// Two decimals are plenty for a status page
+	return float64(up*10000/total) / 100
+}
+
+func worstStatus(components []models.ComponentStatusResponse) models.ComponentStatus {
+	worst := models.ComponentOperational
+	for _, component := range components {
+		if component.Status.Severity() > worst.Severity() {
+			worst = component.Status
+		}
+	}
+	return worst
+}
@@ -4,10 +4,13 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"social-media-api/internal/config"
 	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -16,27 +19,38 @@ import (
 )
 
 type StoryService struct {
-	collection          *mongo.Collection
-	viewCollection      *mongo.Collection
-	highlightCollection *mongo.Collection
-	userCollection      *mongo.Collection
-	followCollection    *mongo.Collection
-	likeCollection      *mongo.Collection
-	db                  *mongo.Database
+	collection                *mongo.Collection
+	viewCollection            *mongo.Collection
+	highlightCollection       *mongo.Collection
+	stickerResponseCollection *mongo.Collection
+	userCollection            *mongo.Collection
+	followCollection          *mongo.Collection
+	likeCollection            *mongo.Collection
+	db                        *mongo.Database
+	limits                    *LimitsService
+	notificationService       *NotificationService
 }
 
-func NewStoryService() *StoryService {
+func NewStoryService(limits *LimitsService) *StoryService {
 	return &StoryService{
-		collection:          config.DB.Collection("stories"),
-		viewCollection:      config.DB.Collection("story_views"),
-		highlightCollection: config.DB.Collection("story_highlights"),
-		userCollection:      config.DB.Collection("users"),
-		followCollection:    config.DB.Collection("follows"),
-		likeCollection:      config.DB.Collection("likes"),
-		db:                  config.DB,
+		collection:                config.DB.Collection("stories"),
+		viewCollection:            config.DB.Collection("story_views"),
+		highlightCollection:       config.DB.Collection("story_highlights"),
+		stickerResponseCollection: config.DB.Collection("story_sticker_responses"),
+		userCollection:            config.DB.Collection("users"),
+		followCollection:          config.DB.Collection("follows"),
+		likeCollection:            config.DB.Collection("likes"),
+		db:                        config.DB,
+		limits:                    limits,
 	}
 }
 
+// SetNotificationService sets the service screenshot alerts go through; the
+// notification service is built after the story service
+func (ss *StoryService) SetNotificationService(notificationService *NotificationService) {
+	ss.notificationService = notificationService
+}
+
 // CreateStory creates a new story
 func (ss *StoryService) CreateStory(userID primitive.ObjectID, req models.CreateStoryRequest) (*models.Story, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -47,6 +61,21 @@ func (ss *StoryService) CreateStory(userID primitive.ObjectID, req models.Create
 		return nil, errors.New("invalid content type for story")
 	}
 
+	// Enforce the author's tier limits
+	limits, err := ss.limits.GetEffectiveLimits(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := limits.Check(models.LimitStoryDuration, req.Duration); err != nil {
+		return nil, err
+	}
+
+	for i := range req.Stickers {
+		if err := req.Stickers[i].Validate(); err != nil {
+			return nil, fmt.Errorf("invalid sticker: %w", err)
+		}
+	}
+
 	// Convert allowed and blocked viewers
 	var allowedViewers []primitive.ObjectID
 	for _, viewerID := range req.AllowedViewers {
@@ -62,6 +91,12 @@ func (ss *StoryService) CreateStory(userID primitive.ObjectID, req models.Create
 		}
 	}
 
+	// Stories without an explicit visibility use the author's default
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = getPrivacySettings(ctx, ss.userCollection, userID).DefaultStoryVisibility
+	}
+
 	// Create story
 	story := &models.Story{
 		UserID:          userID,
@@ -69,7 +104,7 @@ func (ss *StoryService) CreateStory(userID primitive.ObjectID, req models.Create
 		ContentType:     req.ContentType,
 		Media:           req.Media,
 		Duration:        req.Duration,
-		Visibility:      req.Visibility,
+		Visibility:      visibility,
 		AllowedViewers:  allowedViewers,
 		BlockedViewers:  blockedViewers,
 		AllowReplies:    req.AllowReplies,
@@ -85,6 +120,10 @@ func (ss *StoryService) CreateStory(userID primitive.ObjectID, req models.Create
 		Location:        req.Location,
 		Music:           req.Music,
 	}
+	story.PostedByDelegate = req.DelegateID
+	if err := models.CheckBounds(story); err != nil {
+		return nil, err
+	}
 
 	story.BeforeCreate()
 
@@ -109,10 +148,7 @@ func (ss *StoryService) GetStoryByID(storyID primitive.ObjectID, currentUserID *
 	defer cancel()
 
 	var story models.Story
-	err := ss.collection.FindOne(ctx, bson.M{
-		"_id":        storyID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&story)
+	err := ss.collection.FindOne(ctx, repository.ByID(storyID)).Decode(&story)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -166,11 +202,10 @@ func (ss *StoryService) GetUserStories(userID primitive.ObjectID, currentUserID
 		}
 	}
 
-	filter := bson.M{
-		"user_id":    userID,
-		"deleted_at": bson.M{"$exists": false},
-		"is_hidden":  false,
-	}
+	filter := repository.NotDeleted(bson.M{
+		"user_id":   userID,
+		"is_hidden": false,
+	})
 
 	// Add visibility filter based on relationship
 	if !isAuthor {
@@ -233,11 +268,10 @@ func (ss *StoryService) GetFollowingStories(userID primitive.ObjectID) ([]models
 	// Get list of users that current user follows
 	followingPipeline := []bson.M{
 		{
-			"$match": bson.M{
+			"$match": repository.NotDeleted(bson.M{
 				"follower_id": userID,
 				"status":      "accepted",
-				"deleted_at":  bson.M{"$exists": false},
-			},
+			}),
 		},
 		{
 			"$group": bson.M{
@@ -260,19 +294,25 @@ func (ss *StoryService) GetFollowingStories(userID primitive.ObjectID) ([]models
 		return nil, err
 	}
 
+	// Leave out muted users for as long as the mute lasts
+	muted := getMutedUserIDs(ctx, ss.db, userID)
+
 	var followingIDs []primitive.ObjectID
 	if len(followingResult) > 0 {
-		followingIDs = followingResult[0].FollowingIDs
+		for _, id := range followingResult[0].FollowingIDs {
+			if !muted[id] {
+				followingIDs = append(followingIDs, id)
+			}
+		}
 	}
 
 	// Add current user to see their own stories
 	followingIDs = append(followingIDs, userID)
 
 	// Get stories from followed users
-	filter := bson.M{
-		"user_id":    bson.M{"$in": followingIDs},
-		"deleted_at": bson.M{"$exists": false},
-		"is_hidden":  false,
+	filter := repository.NotDeleted(bson.M{
+		"user_id":   bson.M{"$in": followingIDs},
+		"is_hidden": false,
 		"$or": []bson.M{
 			{"visibility": models.PrivacyPublic},
 			{"visibility": models.PrivacyFriends},
@@ -282,7 +322,7 @@ func (ss *StoryService) GetFollowingStories(userID primitive.ObjectID) ([]models
 			},
 		},
 		"blocked_viewers": bson.M{"$nin": []primitive.ObjectID{userID}},
-	}
+	})
 
 	opts := options.Find().
 		SetSort(bson.M{"created_at": -1}).
@@ -384,10 +424,7 @@ func (ss *StoryService) DeleteStory(storyID, userID primitive.ObjectID) error {
 
 	// Check if story exists
 	var story models.Story
-	err = ss.collection.FindOne(ctx, bson.M{
-		"_id":        storyID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&story)
+	err = ss.collection.FindOne(ctx, repository.ByID(storyID)).Decode(&story)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -463,6 +500,52 @@ func (ss *StoryService) ViewStory(storyID, viewerID primitive.ObjectID) error {
 	return nil
 }
 
+// ReportScreenshot records a viewer's client reporting a screenshot of a
+// story. Screenshots can't be prevented, so when the author disallowed them
+// they are told instead, once per viewer.
+func (ss *StoryService) ReportScreenshot(storyID, viewerID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	story, err := ss.GetStoryByID(storyID, &viewerID)
+	if err != nil {
+		return err
+	}
+
+	if story.UserID == viewerID {
+		return nil
+	}
+
+	// A screenshot implies a view
+	if err := ss.ViewStory(storyID, viewerID); err != nil {
+		return err
+	}
+
+	result, err := ss.viewCollection.UpdateOne(ctx, bson.M{
+		"story_id":   storyID,
+		"user_id":    viewerID,
+		"screenshot": bson.M{"$ne": true},
+	}, bson.M{
+		"$set": bson.M{"screenshot": true, "updated_at": time.Now()},
+	})
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 {
+		return nil
+	}
+
+	ss.collection.UpdateOne(ctx, bson.M{"_id": storyID}, bson.M{
+		"$inc": bson.M{"screenshots_count": 1},
+	})
+
+	if !story.AllowScreenshot && ss.notificationService != nil {
+		ss.notificationService.NotifyStoryScreenshot(viewerID, story.UserID, storyID)
+	}
+
+	return nil
+}
+
 // GetStoryViews retrieves viewers of a story
 func (ss *StoryService) GetStoryViews(storyID, userID primitive.ObjectID, limit, skip int) ([]models.StoryViewResponse, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -546,6 +629,10 @@ func (ss *StoryService) ReactToStory(storyID, userID primitive.ObjectID, reactio
 		return errors.New("reactions not allowed on this story")
 	}
 
+	if err := ensureReactionAvailable(ctx, ss.db, reactionType); err != nil {
+		return err
+	}
+
 	// Check if user already reacted
 	var existingLike models.Like
 	err = ss.likeCollection.FindOne(ctx, bson.M{
@@ -699,6 +786,7 @@ func (ss *StoryService) GetStoryStats(storyID, userID primitive.ObjectID) (map[s
 		"likes_count":           story.LikesCount,
 		"replies_count":         story.RepliesCount,
 		"shares_count":          story.SharesCount,
+		"screenshots_count":     story.ScreenshotsCount,
 		"average_view_duration": story.AverageViewDuration,
 		"completion_rate":       story.CompletionRate,
 		"engagement_rate":       story.EngagementRate,
@@ -710,19 +798,258 @@ func (ss *StoryService) GetStoryStats(storyID, userID primitive.ObjectID) (map[s
 	return stats, nil
 }
 
+// RespondToSticker records a viewer's response to an interactive sticker.
+// Each viewer responds once per sticker, and expired stories stop accepting
+// responses even when highlighted.
+func (ss *StoryService) RespondToSticker(storyID, userID, stickerID primitive.ObjectID, req models.RespondToStickerRequest) (*models.StoryStickerResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	story, err := ss.GetStoryByID(storyID, &userID)
+	if err != nil {
+		return nil, err
+	}
+	if story.IsExpired {
+		return nil, errors.New("story has expired")
+	}
+	if story.UserID == userID {
+		return nil, errors.New("cannot respond to your own story")
+	}
+
+	sticker := story.FindSticker(stickerID)
+	if sticker == nil || !sticker.IsInteractive() {
+		return nil, errors.New("sticker not found")
+	}
+	if err := sticker.ValidateResponse(req); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+
+	response := &models.StoryStickerResponse{
+		StoryID:     storyID,
+		StickerID:   stickerID,
+		StickerType: sticker.Type,
+		UserID:      userID,
+		OptionIndex: req.OptionIndex,
+		Text:        strings.TrimSpace(req.Text),
+		Value:       req.Value,
+		CreatedAt:   time.Now(),
+	}
+	if sticker.Type == models.StickerTypeQuiz {
+		correct := *req.OptionIndex == *sticker.CorrectOption
+		response.IsCorrect = &correct
+	}
+
+	result, err := ss.stickerResponseCollection.InsertOne(ctx, response)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, errors.New("sticker already answered")
+		}
+		return nil, err
+	}
+	response.ID = result.InsertedID.(primitive.ObjectID)
+
+	if sticker.Type == models.StickerTypeQuiz {
+		response.CorrectOption = sticker.CorrectOption
+	}
+
+	return response, nil
+}
+
+// GetStickerInsights aggregates the responses to a story's interactive
+// stickers for its author
+func (ss *StoryService) GetStickerInsights(storyID, userID primitive.ObjectID) ([]models.StickerInsights, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	story, err := ss.getAuthorStory(ctx, storyID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := ss.stickerResponseCollection.Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"story_id": storyID}},
+		{"$group": bson.M{
+			"_id":       bson.M{"sticker": "$sticker_id", "option": "$option_index"},
+			"count":     bson.M{"$sum": 1},
+			"correct":   bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$is_correct", true}}, 1, 0}}},
+			"value_sum": bson.M{"$sum": bson.M{"$ifNull": bson.A{"$value", 0}}},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var groups []struct {
+		ID struct {
+			Sticker primitive.ObjectID `bson:"sticker"`
+			Option  *int               `bson:"option"`
+		} `bson:"_id"`
+		Count    int64   `bson:"count"`
+		Correct  int64   `bson:"correct"`
+		ValueSum float64 `bson:"value_sum"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	type stickerTotals struct {
+		responses int64
+		correct   int64
+		valueSum  float64
+		options   map[int]int64
+	}
+	totals := make(map[primitive.ObjectID]*stickerTotals)
+	for _, group := range groups {
+		t := totals[group.ID.Sticker]
+		if t == nil {
+			t = &stickerTotals{options: make(map[int]int64)}
+			totals[group.ID.Sticker] = t
+		}
+		t.responses += group.Count
+		t.correct += group.Correct
+		t.valueSum += group.ValueSum
+		if group.ID.Option != nil {
+			t.options[*group.ID.Option] += group.Count
+		}
+	}
+
+	insights := []models.StickerInsights{}
+	for _, sticker := range story.Stickers {
+		if !sticker.IsInteractive() {
+			continue
+		}
+		t := totals[sticker.ID]
+		if t == nil {
+			t = &stickerTotals{options: map[int]int64{}}
+		}
+
+		insight := models.StickerInsights{
+			StickerID:    sticker.ID.Hex(),
+			Type:         sticker.Type,
+			QuestionText: sticker.QuestionText,
+			Responses:    t.responses,
+		}
+
+		switch sticker.Type {
+		case models.StickerTypePoll, models.StickerTypeQuiz:
+			// Include options nobody picked so the breakdown is complete
+			for i, text := range sticker.PollOptions {
+				option := models.StickerOptionResult{Index: i, Text: text, Count: t.options[i]}
+				if t.responses > 0 {
+					option.Percentage = float64(option.Count) / float64(t.responses) * 100
+				}
+				insight.Options = append(insight.Options, option)
+			}
+			if sticker.Type == models.StickerTypeQuiz {
+				insight.CorrectOption = sticker.CorrectOption
+				if t.responses > 0 {
+					insight.CorrectRate = float64(t.correct) / float64(t.responses) * 100
+				}
+			}
+		case models.StickerTypeSlider:
+			if t.responses > 0 {
+				insight.AverageValue = t.valueSum / float64(t.responses)
+			}
+		}
+
+		insights = append(insights, insight)
+	}
+
+	return insights, nil
+}
+
+// GetStickerResponses lists the individual responses to one of the author's
+// stickers, newest first, so question replies can be read
+func (ss *StoryService) GetStickerResponses(storyID, userID, stickerID primitive.ObjectID, limit, skip int) ([]models.StoryStickerResponse, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	story, err := ss.getAuthorStory(ctx, storyID, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if sticker := story.FindSticker(stickerID); sticker == nil || !sticker.IsInteractive() {
+		return nil, 0, errors.New("sticker not found")
+	}
+
+	filter := bson.M{"story_id": storyID, "sticker_id": stickerID}
+	total, err := ss.stickerResponseCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"created_at": -1}).
+		SetLimit(int64(limit)).
+		SetSkip(int64(skip))
+	cursor, err := ss.stickerResponseCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	responses := []models.StoryStickerResponse{}
+	if err := cursor.All(ctx, &responses); err != nil {
+		return nil, 0, err
+	}
+
+	// Populate responders
+	userIDs := make([]primitive.ObjectID, 0, len(responses))
+	for _, response := range responses {
+		userIDs = append(userIDs, response.UserID)
+	}
+	if len(userIDs) > 0 {
+		userCursor, err := ss.userCollection.Find(ctx, bson.M{"_id": bson.M{"$in": userIDs}})
+		if err != nil {
+			return nil, 0, err
+		}
+		var users []models.User
+		if err := userCursor.All(ctx, &users); err != nil {
+			return nil, 0, err
+		}
+		byID := make(map[primitive.ObjectID]models.UserResponse, len(users))
+		for _, user := range users {
+			byID[user.ID] = user.ToUserResponse()
+		}
+		for i := range responses {
+			if user, ok := byID[responses[i].UserID]; ok {
+				responses[i].User = &user
+			}
+		}
+	}
+
+	return responses, total, nil
+}
+
+// getAuthorStory loads a story for its author, including expired ones
+func (ss *StoryService) getAuthorStory(ctx context.Context, storyID, userID primitive.ObjectID) (*models.Story, error) {
+	var story models.Story
+	err := ss.collection.FindOne(ctx, repository.ByID(storyID)).Decode(&story)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("story not found")
+		}
+		return nil, err
+	}
+	if story.UserID != userID {
+		return nil, errors.New("access denied")
+	}
+	return &story, nil
+}
+
 // GetActiveStories retrieves currently active stories from all users
 func (ss *StoryService) GetActiveStories(currentUserID *primitive.ObjectID, limit, skip int) ([]models.Story, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	filter := bson.M{
-		"deleted_at": bson.M{"$exists": false},
-		"is_hidden":  false,
+	filter := repository.NotDeleted(bson.M{
+		"is_hidden": false,
 		"$or": []bson.M{
 			{"is_expired": false},
 			{"is_highlighted": true},
 		},
-	}
+	})
 
 	// If user is authenticated, apply privacy filters
 	if currentUserID != nil {
@@ -745,6 +1072,15 @@ func (ss *StoryService) GetActiveStories(currentUserID *primitive.ObjectID, limi
 				"blocked_viewers": bson.M{"$nin": []primitive.ObjectID{*currentUserID}},
 			},
 		}
+
+		// Leave out muted users for as long as the mute lasts
+		if muted := getMutedUserIDs(ctx, ss.db, *currentUserID); len(muted) > 0 {
+			mutedIDs := make([]primitive.ObjectID, 0, len(muted))
+			for id := range muted {
+				mutedIDs = append(mutedIDs, id)
+			}
+			filter["$and"] = append(filter["$and"].([]bson.M), bson.M{"user_id": bson.M{"$nin": mutedIDs}})
+		}
 	} else {
 		// Only public stories for unauthenticated users
 		filter["visibility"] = models.PrivacyPublic
@@ -810,11 +1146,10 @@ func (ss *StoryService) GetArchivedStories(userID primitive.ObjectID, limit, ski
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	filter := bson.M{
-		"user_id":    userID,
-		"is_hidden":  true,
-		"deleted_at": bson.M{"$exists": false},
-	}
+	filter := repository.NotDeleted(bson.M{
+		"user_id":   userID,
+		"is_hidden": true,
+	})
 
 	opts := options.Find().
 		SetLimit(int64(limit)).
@@ -862,12 +1197,11 @@ func (ss *StoryService) isUserFollowing(followerID, followeeID primitive.ObjectI
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	count, err := ss.followCollection.CountDocuments(ctx, bson.M{
+	count, err := ss.followCollection.CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"follower_id": followerID,
 		"followee_id": followeeID,
 		"status":      "accepted",
-		"deleted_at":  bson.M{"$exists": false},
-	})
+	}))
 
 	return err == nil && count > 0
 }
@@ -877,11 +1211,10 @@ func (ss *StoryService) getFollowingUserIDs(userID primitive.ObjectID) []primiti
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	cursor, err := ss.followCollection.Find(ctx, bson.M{
+	cursor, err := ss.followCollection.Find(ctx, repository.NotDeleted(bson.M{
 		"follower_id": userID,
 		"status":      "accepted",
-		"deleted_at":  bson.M{"$exists": false},
-	})
+	}))
 
 	if err != nil {
 		return []primitive.ObjectID{}
@@ -919,13 +1252,15 @@ func (ss *StoryService) CreateStoryHighlight(userID primitive.ObjectID, req mode
 	if len(storyIDs) == 0 {
 		return nil, errors.New("at least one valid story ID is required")
 	}
+	if err := models.CheckBounds(models.StoryHighlight{StoryIDs: storyIDs}); err != nil {
+		return nil, err
+	}
 
 	// Verify user owns all the stories
-	count, err := ss.collection.CountDocuments(ctx, bson.M{
-		"_id":        bson.M{"$in": storyIDs},
-		"user_id":    userID,
-		"deleted_at": bson.M{"$exists": false},
-	})
+	count, err := ss.collection.CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"_id":     bson.M{"$in": storyIDs},
+		"user_id": userID,
+	}))
 
 	if err != nil {
 		return nil, err
@@ -968,13 +1303,12 @@ func (ss *StoryService) GetUserStoryHighlights(userID primitive.ObjectID) ([]mod
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	filter := bson.M{
-		"user_id":    userID,
-		"is_active":  true,
-		"deleted_at": bson.M{"$exists": false},
-	}
+	filter := repository.NotDeleted(bson.M{
+		"user_id":   userID,
+		"is_active": true,
+	})
 
-	opts := options.Find().SetSort(bson.M{"order": 1, "created_at": 1})
+	opts := options.Find().SetSort(bson.D{{Key: "order", Value: 1}, {Key: "created_at", Value: 1}})
 
 	cursor, err := ss.highlightCollection.Find(ctx, filter, opts)
 	if err != nil {
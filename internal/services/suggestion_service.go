@@ -0,0 +1,676 @@
+// internal/services/suggestion_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Suggestion algorithm tuning. Impressions and follow-through are recorded in
+// recommendation_events (recommendation_type "user") to tune these weights.
+const (
+	suggestionAlgorithm         = "friend_of_friend"
+	suggestionMaxIntermediaries = 500   // Followees used to generate candidates, strongest engagement first
+	suggestionMaxEdges          = 20000 // Follows of those followees scanned per user
+	suggestionRecencyHalfLife   = 30.0  // Days after which a follow counts half as much
+	suggestionEngagementCap     = 10.0  // Interaction score at which the engagement boost maxes out
+	suggestionMaxMutualIDs      = 3
+	suggestionActiveWindow      = 30 * 24 * time.Hour // Users refreshed by the batch job
+	SuggestionRefreshInterval   = 6 * time.Hour
+)
+
+type SuggestionService struct {
+	suggestionCollection     *mongo.Collection
+	dismissalCollection      *mongo.Collection
+	followCollection         *mongo.Collection
+	userCollection           *mongo.Collection
+	recommendationCollection *mongo.Collection
+	db                       *mongo.Database
+	stopRefresher            context.CancelFunc
+}
+
+func NewSuggestionService(db *mongo.Database) *SuggestionService {
+	return &SuggestionService{
+		suggestionCollection:     db.Collection("suggestions"),
+		dismissalCollection:      db.Collection("suggestion_dismissals"),
+		followCollection:         db.Collection("follows"),
+		userCollection:           db.Collection("users"),
+		recommendationCollection: db.Collection("recommendation_events"),
+		db:                       db,
+	}
+}
+
+// GetSuggestions returns follow suggestions for a user. The precomputed list is
+// re-filtered at request time so follows, blocks, dismissals and privacy
+// changes since the last batch run are respected; popular accounts fill any
+// remaining slots.
+func (ss *SuggestionService) GetSuggestions(userID primitive.ObjectID, limit int) ([]models.SuggestedUserResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	var stored models.UserSuggestions
+	err := ss.suggestionCollection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&stored)
+	if err == mongo.ErrNoDocuments {
+		// Not picked up by the batch job yet; generate now
+		stored.Candidates, err = ss.refreshUserSuggestions(ctx, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	excluded, err := getSuggestionExclusions(ctx, ss.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []models.SuggestionCandidate
+	for _, candidate := range stored.Candidates {
+		if !excluded[candidate.UserID] {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	suggestions, err := ss.buildSuggestions(ctx, candidates, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(suggestions) < limit {
+		for _, candidate := range candidates {
+			excluded[candidate.UserID] = true
+		}
+		popular, err := ss.getPopularSuggestions(ctx, excluded, limit-len(suggestions))
+		if err == nil {
+			suggestions = append(suggestions, popular...)
+		}
+	}
+
+	go ss.recordImpressions(userID, suggestions)
+
+	return suggestions, nil
+}
+
+// DismissSuggestion stops an account from ever being suggested to the user again
+func (ss *SuggestionService) DismissSuggestion(userID, dismissedUserID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if userID == dismissedUserID {
+		return errors.New("cannot dismiss yourself")
+	}
+
+	count, err := ss.userCollection.CountDocuments(ctx, bson.M{"_id": dismissedUserID})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("user not found")
+	}
+
+	now := time.Now()
+	_, err = ss.dismissalCollection.UpdateOne(ctx,
+		bson.M{"user_id": userID, "dismissed_user_id": dismissedUserID},
+		bson.M{"$setOnInsert": bson.M{
+			"user_id":           userID,
+			"dismissed_user_id": dismissedUserID,
+			"created_at":        now,
+			"updated_at":        now,
+		}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return err
+	}
+
+	// Drop it from the stored list right away
+	ss.suggestionCollection.UpdateOne(ctx,
+		bson.M{"user_id": userID},
+		bson.M{"$pull": bson.M{"candidates": bson.M{"user_id": dismissedUserID}}},
+	)
+
+	// Record the dismissal against the latest impression
+	ss.recommendationCollection.FindOneAndUpdate(ctx,
+		bson.M{"user_id": userID, "item_id": dismissedUserID, "recommendation_type": "user"},
+		bson.M{"$set": bson.M{"feedback": "dismissed", "updated_at": now}},
+		options.FindOneAndUpdate().SetSort(bson.D{{Key: "presented", Value: -1}}),
+	)
+
+	return nil
+}
+
+// RefreshSuggestions regenerates stored suggestions for recently active users
+func (ss *SuggestionService) RefreshSuggestions(ctx context.Context) (int, error) {
+	filter := repository.NotDeleted(bson.M{
+		"is_active":      true,
+		"last_active_at": bson.M{"$gte": time.Now().Add(-suggestionActiveWindow)},
+	})
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+
+	cursor, err := ss.userCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	refreshed := 0
+	for cursor.Next(ctx) {
+		var user struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&user); err != nil {
+			continue
+		}
+
+		if _, err := ss.refreshUserSuggestions(ctx, user.ID); err != nil {
+			if ctx.Err() != nil {
+				return refreshed, ctx.Err()
+			}
+			log.Printf("Failed to refresh suggestions for user %s: %v", user.ID.Hex(), err)
+			continue
+		}
+		refreshed++
+	}
+
+	return refreshed, cursor.Err()
+}
+
+// StartRefresher runs RefreshSuggestions every interval until StopRefresher is called
+func (ss *SuggestionService) StartRefresher(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ss.stopRefresher = cancel
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				start := time.Now()
+				refreshed, err := ss.RefreshSuggestions(ctx)
+				if ctx.Err() == nil {
+					recordJobHeartbeat(ss.db, "suggestion_refresh", interval, err)
+				}
+				if err != nil && ctx.Err() == nil {
+					log.Printf("Suggestion refresh failed after %d users: %v", refreshed, err)
+					continue
+				}
+				log.Printf("Refreshed suggestions for %d users in %s", refreshed, time.Since(start).Round(time.Second))
+			}
+		}
+	}()
+}
+
+// StopRefresher stops the periodic suggestion refresh
+func (ss *SuggestionService) StopRefresher() {
+	if ss.stopRefresher != nil {
+		ss.stopRefresher()
+	}
+}
+
+// Helper methods
+
+// refreshUserSuggestions generates and stores the top suggestions for one user
+func (ss *SuggestionService) refreshUserSuggestions(ctx context.Context, userID primitive.ObjectID) ([]models.SuggestionCandidate, error) {
+	candidates, err := ss.generateCandidates(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	_, err = ss.suggestionCollection.UpdateOne(ctx,
+		bson.M{"user_id": userID},
+		bson.M{
+			"$set": bson.M{
+				"candidates":   candidates,
+				"generated_at": now,
+				"updated_at":   now,
+			},
+			"$setOnInsert": bson.M{"created_at": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return candidates, nil
+}
+
+// generateCandidates scores accounts followed by the people the user follows
+// (triadic closure). Each follow counts more when it is recent and when the
+// user engages a lot with the followee who made it.
+func (ss *SuggestionService) generateCandidates(ctx context.Context, userID primitive.ObjectID) ([]models.SuggestionCandidate, error) {
+	followOpts := options.Find().
+		SetProjection(bson.M{"followee_id": 1, "interaction_score": 1}).
+		SetSort(bson.D{{Key: "interaction_score", Value: -1}}).
+		SetLimit(suggestionMaxIntermediaries)
+
+	cursor, err := ss.followCollection.Find(ctx, repository.NotDeleted(bson.M{
+		"follower_id": userID,
+		"status":      models.FollowStatusAccepted,
+	}), followOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	var following []struct {
+		FolloweeID       primitive.ObjectID `bson:"followee_id"`
+		InteractionScore float64            `bson:"interaction_score"`
+	}
+	if err := cursor.All(ctx, &following); err != nil {
+		return nil, err
+	}
+	if len(following) == 0 {
+		return []models.SuggestionCandidate{}, nil
+	}
+
+	followeeIDs := make([]primitive.ObjectID, 0, len(following))
+	for _, follow := range following {
+		followeeIDs = append(followeeIDs, follow.FolloweeID)
+	}
+
+	// Followees who hide their social graph don't lend their follows
+	hidden, err := getHiddenGraphUserIDs(ctx, ss.db, followeeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	engagement := make(map[primitive.ObjectID]float64, len(following))
+	intermediaries := make([]primitive.ObjectID, 0, len(following))
+	for _, follow := range following {
+		if hidden[follow.FolloweeID] {
+			continue
+		}
+		engagement[follow.FolloweeID] = 1 + math.Min(follow.InteractionScore, suggestionEngagementCap)/suggestionEngagementCap
+		intermediaries = append(intermediaries, follow.FolloweeID)
+	}
+	if len(intermediaries) == 0 {
+		return []models.SuggestionCandidate{}, nil
+	}
+
+	excluded, err := getSuggestionExclusions(ctx, ss.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	edgeOpts := options.Find().
+		SetProjection(bson.M{"follower_id": 1, "followee_id": 1, "created_at": 1}).
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(suggestionMaxEdges)
+
+	cursor, err = ss.followCollection.Find(ctx, repository.NotDeleted(bson.M{
+		"follower_id": bson.M{"$in": intermediaries},
+		"status":      models.FollowStatusAccepted,
+	}), edgeOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	type mutual struct {
+		id     primitive.ObjectID
+		weight float64
+	}
+	type scored struct {
+		candidate models.SuggestionCandidate
+		mutuals   []mutual
+	}
+
+	now := time.Now()
+	scores := make(map[primitive.ObjectID]*scored)
+
+	for cursor.Next(ctx) {
+		var edge struct {
+			FollowerID primitive.ObjectID `bson:"follower_id"`
+			FolloweeID primitive.ObjectID `bson:"followee_id"`
+			CreatedAt  time.Time          `bson:"created_at"`
+		}
+		if err := cursor.Decode(&edge); err != nil || excluded[edge.FolloweeID] {
+			continue
+		}
+
+		ageDays := now.Sub(edge.CreatedAt).Hours() / 24
+		weight := engagement[edge.FollowerID] / (1 + ageDays/suggestionRecencyHalfLife)
+
+		entry, ok := scores[edge.FolloweeID]
+		if !ok {
+			entry = &scored{candidate: models.SuggestionCandidate{UserID: edge.FolloweeID}}
+			scores[edge.FolloweeID] = entry
+		}
+		entry.candidate.Score += weight
+		entry.candidate.MutualCount++
+		entry.mutuals = append(entry.mutuals, mutual{id: edge.FollowerID, weight: weight})
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	ranked := make([]*scored, 0, len(scores))
+	for _, entry := range scores {
+		ranked = append(ranked, entry)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		return ranked[i].candidate.Score > ranked[j].candidate.Score
+	})
+
+	// Oversample so hidden accounts don't leave the stored list short
+	if len(ranked) > models.MaxStoredSuggestions*3 {
+		ranked = ranked[:models.MaxStoredSuggestions*3]
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(ranked))
+	for _, entry := range ranked {
+		ids = append(ids, entry.candidate.UserID)
+	}
+	visible, err := ss.getSuggestableUsers(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []models.SuggestionCandidate{}
+	for _, entry := range ranked {
+		if _, ok := visible[entry.candidate.UserID]; !ok {
+			continue
+		}
+
+		sort.Slice(entry.mutuals, func(i, j int) bool {
+			return entry.mutuals[i].weight > entry.mutuals[j].weight
+		})
+		for i := 0; i < len(entry.mutuals) && i < suggestionMaxMutualIDs; i++ {
+			entry.candidate.MutualIDs = append(entry.candidate.MutualIDs, entry.mutuals[i].id)
+		}
+
+		candidates = append(candidates, entry.candidate)
+		if len(candidates) == models.MaxStoredSuggestions {
+			break
+		}
+	}
+
+	return candidates, nil
+}
+
+// getSuggestableUsers loads the given users that may be suggested: active,
+// public accounts that haven't been deleted or suspended
+func (ss *SuggestionService) getSuggestableUsers(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]models.User, error) {
+	users := make(map[primitive.ObjectID]models.User)
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	cursor, err := ss.userCollection.Find(ctx, repository.NotDeleted(bson.M{
+		"_id":          bson.M{"$in": ids},
+		"is_active":    true,
+		"is_private":   false,
+		"is_suspended": bson.M{"$ne": true},
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	var results []models.User
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	for _, user := range results {
+		users[user.ID] = user
+	}
+	return users, nil
+}
+
+// buildSuggestions turns stored candidates into responses with mutual usernames
+func (ss *SuggestionService) buildSuggestions(ctx context.Context, candidates []models.SuggestionCandidate, limit int) ([]models.SuggestedUserResponse, error) {
+	suggestions := []models.SuggestedUserResponse{}
+	if len(candidates) == 0 {
+		return suggestions, nil
+	}
+
+	var ids, mutualIDs []primitive.ObjectID
+	for _, candidate := range candidates {
+		ids = append(ids, candidate.UserID)
+		mutualIDs = append(mutualIDs, candidate.MutualIDs...)
+	}
+
+	users, err := ss.getSuggestableUsers(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// Accounts that hid their social graph since the candidates were stored
+	// are dropped from the social proof
+	usernames := make(map[primitive.ObjectID]string)
+	if len(mutualIDs) > 0 {
+		cursor, err := ss.userCollection.Find(ctx,
+			repository.NotDeleted(bson.M{
+				"_id":                                bson.M{"$in": mutualIDs},
+				"privacy_settings.hide_social_graph": bson.M{"$ne": true},
+			}),
+			options.Find().SetProjection(bson.M{"username": 1}),
+		)
+		if err == nil {
+			var mutuals []struct {
+				ID       primitive.ObjectID `bson:"_id"`
+				Username string             `bson:"username"`
+			}
+			if cursor.All(ctx, &mutuals) == nil {
+				for _, mutual := range mutuals {
+					usernames[mutual.ID] = mutual.Username
+				}
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		user, ok := users[candidate.UserID]
+		if !ok {
+			continue
+		}
+
+		suggestion := models.SuggestedUserResponse{
+			User:        user.ToUserResponse(),
+			Score:       candidate.Score,
+			Source:      models.SuggestionSourceMutual,
+			MutualCount: candidate.MutualCount,
+		}
+		for _, mutualID := range candidate.MutualIDs {
+			if username, ok := usernames[mutualID]; ok {
+				suggestion.MutualUsernames = append(suggestion.MutualUsernames, username)
+			}
+		}
+
+		suggestions = append(suggestions, suggestion)
+		if len(suggestions) == limit {
+			break
+		}
+	}
+
+	return suggestions, nil
+}
+
+// getPopularSuggestions returns the most followed public accounts not excluded
+func (ss *SuggestionService) getPopularSuggestions(ctx context.Context, excluded map[primitive.ObjectID]bool, limit int) ([]models.SuggestedUserResponse, error) {
+	excludedIDs := make([]primitive.ObjectID, 0, len(excluded))
+	for id := range excluded {
+		excludedIDs = append(excludedIDs, id)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "followers_count", Value: -1}, {Key: "posts_count", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := ss.userCollection.Find(ctx, repository.NotDeleted(bson.M{
+		"_id":          bson.M{"$nin": excludedIDs},
+		"is_active":    true,
+		"is_private":   false,
+		"is_suspended": bson.M{"$ne": true},
+	}), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]models.SuggestedUserResponse, 0, len(users))
+	for _, user := range users {
+		suggestions = append(suggestions, models.SuggestedUserResponse{
+			User:   user.ToUserResponse(),
+			Source: models.SuggestionSourcePopular,
+		})
+	}
+	return suggestions, nil
+}
+
+// recordImpressions logs shown suggestions as recommendation events
+func (ss *SuggestionService) recordImpressions(userID primitive.ObjectID, suggestions []models.SuggestedUserResponse) {
+	if len(suggestions) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	events := make([]interface{}, 0, len(suggestions))
+	for i, suggestion := range suggestions {
+		itemID, err := primitive.ObjectIDFromHex(suggestion.User.ID)
+		if err != nil {
+			continue
+		}
+
+		algorithm := suggestionAlgorithm
+		if suggestion.Source == models.SuggestionSourcePopular {
+			algorithm = models.SuggestionSourcePopular
+		}
+
+		event := models.RecommendationEvent{
+			UserID:             userID,
+			RecommendationType: "user",
+			ItemID:             itemID,
+			Algorithm:          algorithm,
+			Score:              suggestion.Score,
+			Position:           i,
+			Presented:          now,
+		}
+		event.BeforeCreate()
+		events = append(events, event)
+	}
+
+	if _, err := ss.recommendationCollection.InsertMany(ctx, events); err != nil {
+		log.Printf("Failed to record suggestion impressions for user %s: %v", userID.Hex(), err)
+	}
+}
+
+// markSuggestionFollowed records follow-through on the latest impression of a
+// suggested account, if there was one
+func markSuggestionFollowed(ctx context.Context, db *mongo.Database, followerID, followeeID primitive.ObjectID) {
+	now := time.Now()
+	db.Collection("recommendation_events").FindOneAndUpdate(ctx,
+		bson.M{
+			"user_id":             followerID,
+			"item_id":             followeeID,
+			"recommendation_type": "user",
+			"converted":           bson.M{"$exists": false},
+		},
+		bson.M{"$set": bson.M{"converted": now, "updated_at": now}},
+		options.FindOneAndUpdate().SetSort(bson.D{{Key: "presented", Value: -1}}),
+	)
+}
+
+// invalidateSuggestionsThrough drops the stored suggestions of everyone who
+// follows the user, since the user's follows may have fed their candidates.
+// The next request regenerates them.
+func invalidateSuggestionsThrough(ctx context.Context, db *mongo.Database, userID primitive.ObjectID) error {
+	cursor, err := db.Collection("follows").Find(ctx,
+		repository.NotDeleted(bson.M{"followee_id": userID}),
+		options.Find().SetProjection(bson.M{"follower_id": 1}),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	const batchSize = 1000
+	batch := make([]primitive.ObjectID, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		_, err := db.Collection("suggestions").DeleteMany(ctx, bson.M{"user_id": bson.M{"$in": batch}})
+		batch = batch[:0]
+		return err
+	}
+
+	for cursor.Next(ctx) {
+		var follow struct {
+			FollowerID primitive.ObjectID `bson:"follower_id"`
+		}
+		if err := cursor.Decode(&follow); err != nil {
+			continue
+		}
+		batch = append(batch, follow.FollowerID)
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// getSuggestionExclusions returns accounts that must never be suggested to the
+// user: themselves, anyone they follow or have requested to follow, anyone
+// blocked in either direction, and dismissed suggestions
+func getSuggestionExclusions(ctx context.Context, db *mongo.Database, userID primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+	excluded := map[primitive.ObjectID]bool{userID: true}
+
+	followees, err := db.Collection("follows").Distinct(ctx, "followee_id", repository.NotDeleted(bson.M{
+		"follower_id": userID,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	blocked, err := blockedEitherWay(ctx, db, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	dismissed, err := db.Collection("suggestion_dismissals").Distinct(ctx, "dismissed_user_id", bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ids := range [][]interface{}{followees, dismissed} {
+		for _, id := range ids {
+			if oid, ok := id.(primitive.ObjectID); ok {
+				excluded[oid] = true
+			}
+		}
+	}
+	for id := range blocked {
+		excluded[id] = true
+	}
+
+	return excluded, nil
+}
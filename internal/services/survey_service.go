@@ -0,0 +1,635 @@
+// internal/services/survey_service.go
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const surveyResultsCacheTTL = time.Minute
+
+type SurveyService struct {
+	collection          *mongo.Collection
+	responseCollection  *mongo.Collection
+	cacheCollection     *mongo.Collection
+	userCollection      *mongo.Collection
+	notificationService *NotificationService
+}
+
+func NewSurveyService(db *mongo.Database, notificationService *NotificationService) *SurveyService {
+	return &SurveyService{
+		collection:          db.Collection("surveys"),
+		responseCollection:  db.Collection("survey_responses"),
+		cacheCollection:     db.Collection("survey_results_cache"),
+		userCollection:      db.Collection("users"),
+		notificationService: notificationService,
+	}
+}
+
+// CreateSurvey creates a survey and optionally notifies the targeted users
+func (ss *SurveyService) CreateSurvey(adminID primitive.ObjectID, req models.CreateSurveyRequest) (*models.Survey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	survey := &models.Survey{
+		Title:        strings.TrimSpace(req.Title),
+		Description:  strings.TrimSpace(req.Description),
+		EndsAt:       req.EndsAt,
+		MaxResponses: req.MaxResponses,
+		NotifyUsers:  req.NotifyUsers,
+		CreatedBy:    adminID,
+		Audience: models.SurveyAudience{
+			Roles:        req.Audience.Roles,
+			IsPremium:    req.Audience.IsPremium,
+			SignedUpFrom: req.Audience.SignedUpFrom,
+			SignedUpTo:   req.Audience.SignedUpTo,
+		},
+	}
+	if req.StartsAt != nil {
+		survey.StartsAt = *req.StartsAt
+	}
+
+	for _, country := range req.Audience.Countries {
+		survey.Audience.Countries = append(survey.Audience.Countries, strings.ToUpper(country))
+	}
+
+	for _, idStr := range req.Audience.UserIDs {
+		userID, err := primitive.ObjectIDFromHex(idStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid audience user ID: %s", idStr)
+		}
+		survey.Audience.UserIDs = append(survey.Audience.UserIDs, userID)
+	}
+
+	for _, question := range req.Questions {
+		switch question.Type {
+		case models.SurveyQuestionSingleChoice, models.SurveyQuestionMultiChoice:
+			if len(question.Options) < 2 {
+				return nil, errors.New("choice questions require at least 2 options")
+			}
+		default:
+			if len(question.Options) > 0 {
+				return nil, errors.New("options are only allowed on choice questions")
+			}
+		}
+
+		survey.Questions = append(survey.Questions, models.SurveyQuestion{
+			Type:     question.Type,
+			Text:     strings.TrimSpace(question.Text),
+			Options:  question.Options,
+			Required: question.Required,
+		})
+	}
+	if len(survey.Questions) > models.MaxSurveyQuestions {
+		return nil, fmt.Errorf("surveys can have at most %d questions", models.MaxSurveyQuestions)
+	}
+	if err := models.CheckBounds(survey); err != nil {
+		return nil, err
+	}
+
+	survey.BeforeCreate()
+
+	if !survey.EndsAt.After(survey.StartsAt) || !survey.EndsAt.After(time.Now()) {
+		return nil, errors.New("survey end time must be in the future and after the start time")
+	}
+
+	result, err := ss.collection.InsertOne(ctx, survey)
+	if err != nil {
+		return nil, err
+	}
+	survey.ID = result.InsertedID.(primitive.ObjectID)
+
+	if survey.NotifyUsers && ss.notificationService != nil {
+		go ss.notifyAudience(survey)
+	}
+
+	return survey, nil
+}
+
+// GetSurveys retrieves surveys for admins, optionally filtered by status
+func (ss *SurveyService) GetSurveys(status string, limit, skip int) ([]models.Survey, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ss.closeExpiredSurveys(ctx)
+
+	filter := repository.NotDeleted()
+	if status != "" {
+		filter["status"] = status
+	}
+
+	total, err := ss.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"created_at": -1}).
+		SetLimit(int64(limit)).
+		SetSkip(int64(skip))
+
+	cursor, err := ss.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	surveys := []models.Survey{}
+	if err := cursor.All(ctx, &surveys); err != nil {
+		return nil, 0, err
+	}
+
+	return surveys, total, nil
+}
+
+// GetSurveyByID retrieves a survey by ID
+func (ss *SurveyService) GetSurveyByID(surveyID primitive.ObjectID) (*models.Survey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ss.closeExpiredSurveys(ctx)
+
+	return ss.getSurvey(ctx, surveyID)
+}
+
+// CloseSurvey closes a survey before its window ends
+func (ss *SurveyService) CloseSurvey(surveyID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	result, err := ss.collection.UpdateOne(ctx, repository.NotDeleted(bson.M{
+		"_id":    surveyID,
+		"status": models.SurveyStatusActive,
+	}), bson.M{
+		"$set": bson.M{
+			"status":       models.SurveyStatusClosed,
+			"closed_at":    now,
+			"close_reason": models.SurveyCloseManual,
+			"updated_at":   now,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("survey not found or already closed")
+	}
+
+	return nil
+}
+
+// GetPendingSurveys retrieves open surveys the user is eligible for and hasn't answered
+func (ss *SurveyService) GetPendingSurveys(userID primitive.ObjectID) ([]models.PendingSurveyResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ss.closeExpiredSurveys(ctx)
+
+	user, err := ss.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	answered, err := ss.responseCollection.Distinct(ctx, "survey_id", bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	filter := repository.NotDeleted(bson.M{
+		"status":    models.SurveyStatusActive,
+		"starts_at": bson.M{"$lte": now},
+		"ends_at":   bson.M{"$gt": now},
+	})
+	if len(answered) > 0 {
+		filter["_id"] = bson.M{"$nin": answered}
+	}
+
+	cursor, err := ss.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"ends_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var surveys []models.Survey
+	if err := cursor.All(ctx, &surveys); err != nil {
+		return nil, err
+	}
+
+	pending := []models.PendingSurveyResponse{}
+	for _, survey := range surveys {
+		if survey.IsOpen(now) && survey.Audience.Matches(user) {
+			pending = append(pending, survey.ToPendingSurveyResponse())
+		}
+	}
+
+	return pending, nil
+}
+
+// SubmitResponse records a user's answers to a survey
+func (ss *SurveyService) SubmitResponse(surveyID, userID primitive.ObjectID, answers []models.SurveyAnswer) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	survey, err := ss.getSurvey(ctx, surveyID)
+	if err != nil {
+		return err
+	}
+
+	user, err := ss.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !survey.Audience.Matches(user) {
+		return errors.New("survey not found")
+	}
+	if !survey.IsOpen(time.Now()) {
+		return errors.New("survey is closed")
+	}
+	if err := survey.ValidateAnswers(answers); err != nil {
+		return err
+	}
+
+	// Reserve a slot under the response cap before storing the answers
+	if err := ss.reserveResponseSlot(ctx, survey); err != nil {
+		return err
+	}
+
+	submission := models.SurveySubmission{
+		SurveyID:  surveyID,
+		UserID:    &userID,
+		Answers:   answers,
+		CreatedAt: time.Now(),
+	}
+
+	if _, err := ss.responseCollection.InsertOne(ctx, submission); err != nil {
+		ss.collection.UpdateOne(ctx, bson.M{"_id": surveyID}, bson.M{"$inc": bson.M{"responses_count": -1}})
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.New("survey already answered")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetSurveyResults aggregates survey answers, cached for a minute
+func (ss *SurveyService) GetSurveyResults(surveyID primitive.ObjectID) (*models.SurveyResults, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	var cached models.SurveyResults
+	err := ss.cacheCollection.FindOne(ctx, bson.M{
+		"_id":        surveyID,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&cached)
+	if err == nil {
+		return &cached, nil
+	}
+
+	ss.closeExpiredSurveys(ctx)
+
+	survey, err := ss.getSurvey(ctx, surveyID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := activeSubmissionsFilter(surveyID)
+
+	total, err := ss.responseCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := []bson.M{
+		{"$match": filter},
+		{"$unwind": "$answers"},
+		{
+			"$facet": bson.M{
+				"answered": []bson.M{
+					{"$group": bson.M{"_id": "$answers.question_id", "count": bson.M{"$sum": 1}}},
+				},
+				"choices": []bson.M{
+					{"$unwind": "$answers.choices"},
+					{"$group": bson.M{
+						"_id":   bson.M{"question": "$answers.question_id", "value": "$answers.choices"},
+						"count": bson.M{"$sum": 1},
+					}},
+				},
+				"scales": []bson.M{
+					{"$match": bson.M{"answers.scale": bson.M{"$exists": true}}},
+					{"$group": bson.M{
+						"_id":   bson.M{"question": "$answers.question_id", "value": bson.M{"$toString": "$answers.scale"}},
+						"count": bson.M{"$sum": 1},
+					}},
+				},
+			},
+		},
+	}
+
+	cursor, err := ss.responseCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	type valueCount struct {
+		ID struct {
+			Question string `bson:"question"`
+			Value    string `bson:"value"`
+		} `bson:"_id"`
+		Count int64 `bson:"count"`
+	}
+
+	var facets []struct {
+		Answered []struct {
+			ID    string `bson:"_id"`
+			Count int64  `bson:"count"`
+		} `bson:"answered"`
+		Choices []valueCount `bson:"choices"`
+		Scales  []valueCount `bson:"scales"`
+	}
+	if err := cursor.All(ctx, &facets); err != nil {
+		return nil, err
+	}
+
+	answered := make(map[string]int64)
+	distributions := make(map[string]map[string]int64)
+	if len(facets) > 0 {
+		for _, item := range facets[0].Answered {
+			answered[item.ID] = item.Count
+		}
+		for _, item := range append(facets[0].Choices, facets[0].Scales...) {
+			if distributions[item.ID.Question] == nil {
+				distributions[item.ID.Question] = make(map[string]int64)
+			}
+			distributions[item.ID.Question][item.ID.Value] = item.Count
+		}
+	}
+
+	results := &models.SurveyResults{
+		SurveyID:       surveyID,
+		Status:         survey.Status,
+		TotalResponses: total,
+		Questions:      make([]models.SurveyQuestionResult, 0, len(survey.Questions)),
+		GeneratedAt:    time.Now(),
+		ExpiresAt:      time.Now().Add(surveyResultsCacheTTL),
+	}
+
+	for _, question := range survey.Questions {
+		result := models.SurveyQuestionResult{
+			QuestionID: question.ID,
+			Type:       question.Type,
+			Text:       question.Text,
+			Answered:   answered[question.ID],
+		}
+
+		switch question.Type {
+		case models.SurveyQuestionSingleChoice, models.SurveyQuestionMultiChoice:
+			// Include options nobody picked so the breakdown is complete
+			result.Distribution = make(map[string]int64, len(question.Options))
+			for _, option := range question.Options {
+				result.Distribution[option] = distributions[question.ID][option]
+			}
+		case models.SurveyQuestionScale:
+			result.Distribution = distributions[question.ID]
+			result.AverageScore = averageScaleScore(result.Distribution)
+		}
+
+		results.Questions = append(results.Questions, result)
+	}
+
+	opts := options.Replace().SetUpsert(true)
+	ss.cacheCollection.ReplaceOne(ctx, bson.M{"_id": surveyID}, results, opts)
+
+	return results, nil
+}
+
+// ExportFreeTextResponses exports free-text answers of a survey as CSV
+func (ss *SurveyService) ExportFreeTextResponses(surveyID primitive.ObjectID) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	survey, err := ss.getSurvey(ctx, surveyID)
+	if err != nil {
+		return nil, err
+	}
+
+	questions := make(map[string]string)
+	for _, question := range survey.Questions {
+		if question.Type == models.SurveyQuestionFreeText {
+			questions[question.ID] = question.Text
+		}
+	}
+
+	cursor, err := ss.responseCollection.Find(ctx, activeSubmissionsFilter(surveyID),
+		options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"response_id", "submitted_at", "question_id", "question", "answer"})
+
+	for cursor.Next(ctx) {
+		var submission models.SurveySubmission
+		if err := cursor.Decode(&submission); err != nil {
+			continue
+		}
+
+		for _, answer := range submission.Answers {
+			questionText, isFreeText := questions[answer.QuestionID]
+			if !isFreeText || answer.Text == "" {
+				continue
+			}
+			writer.Write([]string{
+				submission.ID.Hex(),
+				submission.CreatedAt.UTC().Format(time.RFC3339),
+				answer.QuestionID,
+				questionText,
+				answer.Text,
+			})
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Helper methods
+
+func (ss *SurveyService) getSurvey(ctx context.Context, surveyID primitive.ObjectID) (*models.Survey, error) {
+	var survey models.Survey
+	err := ss.collection.FindOne(ctx, repository.ByID(surveyID)).Decode(&survey)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("survey not found")
+		}
+		return nil, err
+	}
+	return &survey, nil
+}
+
+func (ss *SurveyService) getUser(ctx context.Context, userID primitive.ObjectID) (*models.User, error) {
+	var user models.User
+	err := ss.userCollection.FindOne(ctx, repository.ByID(userID)).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// reserveResponseSlot atomically counts a response against the cap, closing the survey once it's full
+func (ss *SurveyService) reserveResponseSlot(ctx context.Context, survey *models.Survey) error {
+	now := time.Now()
+	filter := bson.M{
+		"_id":       survey.ID,
+		"status":    models.SurveyStatusActive,
+		"starts_at": bson.M{"$lte": now},
+		"ends_at":   bson.M{"$gt": now},
+	}
+	if survey.MaxResponses > 0 {
+		filter["responses_count"] = bson.M{"$lt": survey.MaxResponses}
+	}
+
+	var updated models.Survey
+	err := ss.collection.FindOneAndUpdate(ctx, filter,
+		bson.M{"$inc": bson.M{"responses_count": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.New("survey is closed")
+		}
+		return err
+	}
+
+	if updated.MaxResponses > 0 && updated.ResponsesCount >= updated.MaxResponses {
+		ss.collection.UpdateOne(ctx, bson.M{"_id": survey.ID, "status": models.SurveyStatusActive}, bson.M{
+			"$set": bson.M{
+				"status":       models.SurveyStatusClosed,
+				"closed_at":    now,
+				"close_reason": models.SurveyCloseCapReached,
+				"updated_at":   now,
+			},
+		})
+	}
+
+	return nil
+}
+
+// closeExpiredSurveys closes active surveys whose window has ended
+func (ss *SurveyService) closeExpiredSurveys(ctx context.Context) {
+	now := time.Now()
+	ss.collection.UpdateMany(ctx, bson.M{
+		"status":  models.SurveyStatusActive,
+		"ends_at": bson.M{"$lte": now},
+	}, bson.M{
+		"$set": bson.M{
+			"status":       models.SurveyStatusClosed,
+			"closed_at":    now,
+			"close_reason": models.SurveyCloseWindowEnded,
+			"updated_at":   now,
+		},
+	})
+}
+
+// notifyAudience sends a notification to every user the survey targets
+func (ss *SurveyService) notifyAudience(survey *models.Survey) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	filter := repository.NotDeleted(bson.M{
+		"is_active": true,
+	})
+	if len(survey.Audience.UserIDs) > 0 {
+		filter["_id"] = bson.M{"$in": survey.Audience.UserIDs}
+	}
+
+	cursor, err := ss.userCollection.Find(ctx, filter, options.Find().SetProjection(bson.M{
+		"_id": 1, "role": 1, "country": 1, "is_premium": 1, "created_at": 1,
+	}))
+	if err != nil {
+		log.Printf("Failed to load survey audience for %s: %v", survey.ID.Hex(), err)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var recipients []primitive.ObjectID
+	for cursor.Next(ctx) {
+		var user models.User
+		if err := cursor.Decode(&user); err != nil {
+			continue
+		}
+		if survey.Audience.Matches(&user) {
+			recipients = append(recipients, user.ID)
+		}
+	}
+
+	if len(recipients) == 0 {
+		return
+	}
+
+	if err := ss.notificationService.NotifySurvey(survey.CreatedBy, survey.ID, survey.Title, recipients); err != nil {
+		log.Printf("Failed to notify survey audience for %s: %v", survey.ID.Hex(), err)
+	}
+}
+
+// activeSubmissionsFilter matches survey responses that haven't been anonymized
+func activeSubmissionsFilter(surveyID primitive.ObjectID) bson.M {
+	return bson.M{
+		"survey_id":  surveyID,
+		"anonymized": bson.M{"$ne": true},
+	}
+}
+
+func averageScaleScore(distribution map[string]int64) float64 {
+	var sum, count int64
+	for value, n := range distribution {
+		score, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		sum += int64(score) * n
+		count += n
+	}
+	if count == 0 {
+		return 0
+	}
+	return float64(sum) / float64(count)
+}
+
+// anonymizeSurveyResponses detaches a deleted user's survey responses from their account
+func anonymizeSurveyResponses(ctx context.Context, db *mongo.Database, userID primitive.ObjectID) error {
+	_, err := db.Collection("survey_responses").UpdateMany(ctx, bson.M{"user_id": userID}, bson.M{
+		"$unset": bson.M{"user_id": ""},
+		"$set":   bson.M{"anonymized": true},
+	})
+	return err
+}
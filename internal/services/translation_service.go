@@ -0,0 +1,221 @@
+// internal/services/translation_service.go
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+	"social-media-api/internal/translation"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultTranslationLanguage = "en"
+
+type TranslationService struct {
+	collection        *mongo.Collection
+	usageCollection   *mongo.Collection
+	postCollection    *mongo.Collection
+	commentCollection *mongo.Collection
+	userCollection    *mongo.Collection
+	postService       *PostService
+	provider          translation.Provider
+	maxTextLength     int
+}
+
+func NewTranslationService(db *mongo.Database, provider translation.Provider, postService *PostService, maxTextLength int) *TranslationService {
+	return &TranslationService{
+		collection:        db.Collection("translations"),
+		usageCollection:   db.Collection("translation_usage"),
+		postCollection:    db.Collection("posts"),
+		commentCollection: db.Collection("comments"),
+		userCollection:    db.Collection("users"),
+		postService:       postService,
+		provider:          provider,
+		maxTextLength:     maxTextLength,
+	}
+}
+
+// TranslatePost translates a post into the user's preferred language
+func (ts *TranslationService) TranslatePost(postID, userID primitive.ObjectID) (*models.TranslationResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	var post models.Post
+	err := ts.postCollection.FindOne(ctx, repository.ByID(postID)).Decode(&post)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("post not found")
+		}
+		return nil, err
+	}
+
+	if !ts.postService.CanUserViewPost(&post, userID) {
+		return nil, errors.New("access denied")
+	}
+
+	return ts.translate(ctx, userID, "post", post.ID, post.Content, post.Language)
+}
+
+// TranslateComment translates a comment into the user's preferred language
+func (ts *TranslationService) TranslateComment(commentID, userID primitive.ObjectID) (*models.TranslationResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	var comment models.Comment
+	err := ts.commentCollection.FindOne(ctx, repository.ByID(commentID)).Decode(&comment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("comment not found")
+		}
+		return nil, err
+	}
+
+	if !comment.CanViewComment() {
+		return nil, errors.New("access denied")
+	}
+
+	// Comments inherit the visibility of the post they belong to
+	var post models.Post
+	err = ts.postCollection.FindOne(ctx, repository.ByID(comment.PostID)).Decode(&post)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("comment not found")
+		}
+		return nil, err
+	}
+
+	if !ts.postService.CanUserViewPost(&post, userID) {
+		return nil, errors.New("access denied")
+	}
+
+	return ts.translate(ctx, userID, "comment", comment.ID, comment.Content, "")
+}
+
+// Helper methods
+
+func (ts *TranslationService) translate(ctx context.Context, userID primitive.ObjectID, contentType string, contentID primitive.ObjectID, text, sourceLang string) (*models.TranslationResponse, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.New("nothing to translate")
+	}
+
+	characters := utf8.RuneCountInString(text)
+	if ts.maxTextLength > 0 && characters > ts.maxTextLength {
+		return nil, errors.New("content too long to translate")
+	}
+
+	targetLang := ts.getPreferredLanguage(ctx, userID)
+	sourceHash := hashTranslationSource(text)
+
+	filter := bson.M{
+		"content_type":    contentType,
+		"content_id":      contentID,
+		"target_language": targetLang,
+	}
+
+	// Serve from cache when the source text hasn't changed
+	var cached models.Translation
+	err := ts.collection.FindOne(ctx, filter).Decode(&cached)
+	if err == nil && cached.SourceHash == sourceHash {
+		ts.recordUsage(userID, contentType, contentID, cached.SourceLanguage, targetLang, characters, true)
+		response := cached.ToTranslationResponse(true)
+		return &response, nil
+	}
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	result, err := ts.provider.Translate(ctx, text, translation.NormalizeLanguage(sourceLang), targetLang)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := models.Translation{
+		ContentID:      contentID,
+		ContentType:    contentType,
+		SourceHash:     sourceHash,
+		SourceLanguage: result.SourceLanguage,
+		TargetLanguage: targetLang,
+		TranslatedText: result.TranslatedText,
+		Provider:       ts.provider.Name(),
+		Attribution:    ts.provider.Attribution(),
+	}
+	entry.BeforeCreate()
+
+	_, err = ts.collection.UpdateOne(ctx, filter, bson.M{
+		"$set": bson.M{
+			"source_hash":     entry.SourceHash,
+			"source_language": entry.SourceLanguage,
+			"translated_text": entry.TranslatedText,
+			"provider":        entry.Provider,
+			"attribution":     entry.Attribution,
+			"updated_at":      entry.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{"created_at": entry.CreatedAt},
+	}, options.Update().SetUpsert(true))
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return nil, err
+	}
+
+	ts.recordUsage(userID, contentType, contentID, entry.SourceLanguage, targetLang, characters, false)
+
+	response := entry.ToTranslationResponse(false)
+	return &response, nil
+}
+
+func (ts *TranslationService) getPreferredLanguage(ctx context.Context, userID primitive.ObjectID) string {
+	var user models.User
+	err := ts.userCollection.FindOne(ctx, bson.M{"_id": userID},
+		options.FindOne().SetProjection(bson.M{"language": 1})).Decode(&user)
+	if err != nil {
+		return defaultTranslationLanguage
+	}
+
+	if lang := translation.NormalizeLanguage(user.Language); lang != "" {
+		return lang
+	}
+	return defaultTranslationLanguage
+}
+
+func (ts *TranslationService) recordUsage(userID primitive.ObjectID, contentType string, contentID primitive.ObjectID, sourceLang, targetLang string, characters int, cached bool) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		ts.usageCollection.InsertOne(ctx, models.TranslationUsage{
+			UserID:         userID,
+			ContentID:      contentID,
+			ContentType:    contentType,
+			SourceLanguage: sourceLang,
+			TargetLanguage: targetLang,
+			Characters:     characters,
+			Cached:         cached,
+			Provider:       ts.provider.Name(),
+			CreatedAt:      time.Now(),
+		})
+	}()
+}
+
+// invalidateTranslations drops cached translations of a post or comment
+func invalidateTranslations(ctx context.Context, db *mongo.Database, contentType string, contentID primitive.ObjectID) error {
+	_, err := db.Collection("translations").DeleteMany(ctx, bson.M{
+		"content_type": contentType,
+		"content_id":   contentID,
+	})
+	return err
+}
+
+func hashTranslationSource(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
@@ -3,10 +3,13 @@ package services
 
 import (
 	"context"
+	"log"
+	"math"
 	"time"
 
 	"social-media-api/internal/config"
 	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -20,6 +23,8 @@ type UserBehaviorService struct {
 	journeyCollection        *mongo.Collection
 	recommendationCollection *mongo.Collection
 	experimentCollection     *mongo.Collection
+	interactionCollection    *mongo.Collection
+	postCollection           *mongo.Collection
 	db                       *mongo.Database
 }
 
@@ -30,6 +35,8 @@ func NewUserBehaviorService() *UserBehaviorService {
 		journeyCollection:        config.DB.Collection("user_journeys"),
 		recommendationCollection: config.DB.Collection("recommendation_events"),
 		experimentCollection:     config.DB.Collection("experiments"),
+		interactionCollection:    config.DB.Collection("user_interactions"),
+		postCollection:           config.DB.Collection("posts"),
 		db:                       config.DB,
 	}
 }
@@ -49,6 +56,7 @@ func (ubs *UserBehaviorService) StartSession(userID primitive.ObjectID, sessionI
 		PagesVisited: []models.PageVisit{},
 		Actions:      []models.UserAction{},
 	}
+	session.BeforeCreate()
 
 	_, err := ubs.sessionCollection.InsertOne(ctx, session)
 	return err
@@ -123,10 +131,153 @@ func (ubs *UserBehaviorService) RecordContentEngagement(engagement models.Conten
 
 	engagement.ID = primitive.NewObjectID()
 
+	engagement.BeforeCreate()
 	_, err := ubs.engagementCollection.InsertOne(ctx, engagement)
 	return err
 }
 
+// RecordImpressions stores a batch of client-reported post impressions in
+// content_engagements and bumps each post's impression count. Engaged
+// impressions are also recorded as feed interactions so they feed into the
+// interest signals used for recommendations. Impressions for unknown or
+// deleted posts are skipped; the number actually recorded is returned.
+func (ubs *UserBehaviorService) RecordImpressions(userID primitive.ObjectID, sessionID string, impressions []models.PostImpression) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Collapse duplicates within the batch, keeping the longest view
+	byPost := make(map[primitive.ObjectID]models.PostImpression)
+	var order []primitive.ObjectID
+	for _, impression := range impressions {
+		postID, err := primitive.ObjectIDFromHex(impression.PostID)
+		if err != nil {
+			continue
+		}
+		if impression.VisibleDuration > models.MaxImpressionDurationMs {
+			impression.VisibleDuration = models.MaxImpressionDurationMs
+		}
+
+		existing, seen := byPost[postID]
+		if !seen {
+			order = append(order, postID)
+			byPost[postID] = impression
+			continue
+		}
+		if impression.VisibleDuration > existing.VisibleDuration {
+			existing.VisibleDuration = impression.VisibleDuration
+		}
+		existing.Engaged = existing.Engaged || impression.Engaged
+		if existing.BoostID == "" {
+			existing.BoostID = impression.BoostID
+		}
+		byPost[postID] = existing
+	}
+
+	if len(order) == 0 {
+		return 0, nil
+	}
+
+	// Only keep posts that exist
+	cursor, err := ubs.postCollection.Find(ctx, repository.NotDeleted(bson.M{
+		"_id": bson.M{"$in": order},
+	}), options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return 0, err
+	}
+	var existingPosts []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &existingPosts); err != nil {
+		return 0, err
+	}
+	valid := make(map[primitive.ObjectID]bool, len(existingPosts))
+	for _, post := range existingPosts {
+		valid[post.ID] = true
+	}
+
+	now := time.Now()
+	var engagements []interface{}
+	var interactions []interface{}
+	var postUpdates []mongo.WriteModel
+
+	for _, postID := range order {
+		if !valid[postID] {
+			continue
+		}
+		impression := byPost[postID]
+
+		source := impression.Source
+		if source == "" {
+			source = "feed"
+		}
+
+		engagement := models.ContentEngagement{
+			UserID:       userID,
+			ContentID:    postID,
+			ContentType:  "post",
+			ViewTime:     now,
+			ViewDuration: impression.VisibleDuration,
+			Interactions: []models.Interaction{},
+			Source:       source,
+			Context: map[string]interface{}{
+				"impression": true,
+				"engaged":    impression.Engaged,
+				"session_id": sessionID,
+			},
+		}
+		if impression.Engaged {
+			engagement.Interactions = append(engagement.Interactions, models.Interaction{
+				Type:      "engaged",
+				Timestamp: now,
+			})
+
+			interaction := &UserInteraction{
+				UserID:           userID,
+				PostID:           postID,
+				InteractionType:  "view",
+				InteractionScore: 1.0 + math.Min(float64(impression.VisibleDuration)/30000.0, 2.0),
+				TimeSpent:        impression.VisibleDuration / 1000,
+				Source:           source,
+			}
+			interaction.BeforeCreate()
+			interactions = append(interactions, interaction)
+		}
+		engagement.BeforeCreate()
+		engagements = append(engagements, engagement)
+
+		postUpdates = append(postUpdates, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": postID}).
+			SetUpdate(bson.M{"$inc": bson.M{"impression_count": 1}}))
+	}
+
+	// Sponsored impressions also count against their campaign's budget
+	for _, postID := range order {
+		boostID, err := primitive.ObjectIDFromHex(byPost[postID].BoostID)
+		if err != nil || !valid[postID] {
+			continue
+		}
+		if err := recordBoostImpression(ctx, ubs.db, boostID, postID, userID); err != nil {
+			log.Printf("Failed to record boosted impression for campaign %s: %v", boostID.Hex(), err)
+		}
+	}
+
+	if len(engagements) == 0 {
+		return 0, nil
+	}
+
+	if _, err := ubs.engagementCollection.InsertMany(ctx, engagements); err != nil {
+		return 0, err
+	}
+
+	if len(interactions) > 0 {
+		ubs.interactionCollection.InsertMany(ctx, interactions)
+	}
+
+	ubs.postCollection.BulkWrite(ctx, postUpdates, options.BulkWrite().SetOrdered(false))
+
+	return len(engagements), nil
+}
+
 // Post Interaction Tracking
 func (ubs *UserBehaviorService) AutoTrackPostInteraction(userID, postID primitive.ObjectID, interactionType, source string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -147,10 +298,19 @@ func (ubs *UserBehaviorService) AutoTrackPostInteraction(userID, postID primitiv
 		},
 	}
 
+	engagement.BeforeCreate()
 	_, err := ubs.engagementCollection.InsertOne(ctx, engagement)
 	return err
 }
 
+// RecordBoostTap records a user opening a boosted post from its sponsored feed slot
+func (ubs *UserBehaviorService) RecordBoostTap(userID, postID, boostID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return recordBoostTap(ctx, ubs.db, boostID, postID, userID)
+}
+
 // Story View Tracking
 func (ubs *UserBehaviorService) AutoTrackStoryView(userID, storyID primitive.ObjectID, source string, duration int64) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -172,6 +332,7 @@ func (ubs *UserBehaviorService) AutoTrackStoryView(userID, storyID primitive.Obj
 		},
 	}
 
+	engagement.BeforeCreate()
 	_, err := ubs.engagementCollection.InsertOne(ctx, engagement)
 	return err
 }
@@ -213,6 +374,7 @@ func (ubs *UserBehaviorService) AutoTrackSearch(userID primitive.ObjectID, query
 				"results_count": resultsCount,
 			},
 		}
+		engagement.BeforeCreate()
 		_, err = ubs.engagementCollection.InsertOne(ctx, engagement)
 		return err
 	}
@@ -241,6 +403,7 @@ func (ubs *UserBehaviorService) RecordInteraction(userID, contentID primitive.Ob
 		},
 	}
 
+	engagement.BeforeCreate()
 	_, err := ubs.engagementCollection.InsertOne(ctx, engagement)
 	return err
 }
@@ -261,6 +424,7 @@ func (ubs *UserBehaviorService) TrackRecommendation(userID primitive.ObjectID, e
 		event.Converted = &now
 	}
 
+	event.BeforeCreate()
 	_, err := ubs.recommendationCollection.InsertOne(ctx, event)
 	return err
 }
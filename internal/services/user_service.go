@@ -4,10 +4,16 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 
-	"social-media-api/internal/config"
 	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
 	"social-media-api/internal/utils"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -17,14 +23,57 @@ import (
 )
 
 type UserService struct {
-	collection *mongo.Collection
-	db         *mongo.Database
+	collection       *mongo.Collection
+	db               *mongo.Database
+	abusePolicy      AbuseScorePolicy
+	limits           *LimitsService
+	stopReactivation context.CancelFunc
+	stopAbuseScore   context.CancelFunc
 }
 
-func NewUserService() *UserService {
+// AbuseScorePolicy weighs the signals combined into a user's abuse score.
+// Over the trailing Window the score is
+//
+//	ReportWeight * sum of the most severe report from each distinct reporter
+//	               (low=1, medium=2, high=3, urgent=4)
+//	+ FollowChurnWeight * follows the user made and then undid
+//	+ DuplicateWeight * the user's posts flagged as coordinated spam
+//	+ MessageReportWeight * distinct users who reported the user's messages
+//
+// Rejected reports are ignored, so dismissing a report lowers the score on
+// the next recompute.
+type AbuseScorePolicy struct {
+	Window              time.Duration
+	ReportWeight        float64
+	FollowChurnWeight   float64
+	DuplicateWeight     float64
+	MessageReportWeight float64
+}
+
+const (
+	// MaxScheduledDeactivation is the furthest ahead an automatic reactivation can be scheduled
+	MaxScheduledDeactivation = 365 * 24 * time.Hour
+	// ReactivationCheckInterval is how often the reactivation job looks for accounts due back
+	ReactivationCheckInterval = 15 * time.Minute
+
+	reactivationBatch = 200
+)
+
+// reportSeverity maps report priority to its weight in the abuse score;
+// reports without a recognised priority count as low
+var reportSeverity = map[string]float64{
+	"low":    1,
+	"medium": 2,
+	"high":   3,
+	"urgent": 4,
+}
+
+func NewUserService(db *mongo.Database, abusePolicy AbuseScorePolicy, limits *LimitsService) *UserService {
 	return &UserService{
-		collection: config.DB.Collection("users"),
-		db:         config.DB,
+		collection:  db.Collection("users"),
+		db:          db,
+		abusePolicy: abusePolicy,
+		limits:      limits,
 	}
 }
 
@@ -79,11 +128,10 @@ func (us *UserService) GetUserByID(userID primitive.ObjectID) (*models.User, err
 	defer cancel()
 
 	var user models.User
-	err := us.collection.FindOne(ctx, bson.M{
-		"_id":        userID,
-		"is_active":  true,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&user)
+	err := us.collection.FindOne(ctx, repository.NotDeleted(bson.M{
+		"_id":       userID,
+		"is_active": true,
+	})).Decode(&user)
 
 	if err != nil {
 		return nil, err
@@ -98,11 +146,10 @@ func (us *UserService) GetUserByUsername(username string) (*models.User, error)
 	defer cancel()
 
 	var user models.User
-	err := us.collection.FindOne(ctx, bson.M{
-		"username":   username,
-		"is_active":  true,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&user)
+	err := us.collection.FindOne(ctx, repository.NotDeleted(bson.M{
+		"username":  username,
+		"is_active": true,
+	})).Decode(&user)
 
 	if err != nil {
 		return nil, err
@@ -117,11 +164,10 @@ func (us *UserService) GetUserByEmail(email string) (*models.User, error) {
 	defer cancel()
 
 	var user models.User
-	err := us.collection.FindOne(ctx, bson.M{
-		"email":      email,
-		"is_active":  true,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&user)
+	err := us.collection.FindOne(ctx, repository.NotDeleted(bson.M{
+		"email":     email,
+		"is_active": true,
+	})).Decode(&user)
 
 	if err != nil {
 		return nil, err
@@ -135,6 +181,34 @@ func (us *UserService) UpdateUser(userID primitive.ObjectID, req models.UpdatePr
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	if req.Bio != nil {
+		limits, err := us.limits.GetEffectiveLimits(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if err := limits.Check(models.LimitBioLength, utf8.RuneCountInString(*req.Bio)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Under-age accounts never see sensitive posts, so they can't opt in
+	if req.ShowSensitiveContent != nil && *req.ShowSensitiveContent {
+		dateOfBirth := req.DateOfBirth
+		if dateOfBirth == nil {
+			var user struct {
+				DateOfBirth *time.Time `bson:"date_of_birth"`
+			}
+			opts := options.FindOne().SetProjection(bson.M{"date_of_birth": 1})
+			if err := us.collection.FindOne(ctx, bson.M{"_id": userID}, opts).Decode(&user); err != nil {
+				return nil, err
+			}
+			dateOfBirth = user.DateOfBirth
+		}
+		if models.IsUnderAge(dateOfBirth) {
+			return nil, errors.New("sensitive content is not available for under-age accounts")
+		}
+	}
+
 	update := bson.M{"$set": bson.M{"updated_at": time.Now()}}
 
 	if req.FirstName != nil {
@@ -155,6 +229,9 @@ func (us *UserService) UpdateUser(userID primitive.ObjectID, req models.UpdatePr
 	if req.Location != nil {
 		update["$set"].(bson.M)["location"] = *req.Location
 	}
+	if req.Country != nil {
+		update["$set"].(bson.M)["country"] = strings.ToUpper(*req.Country)
+	}
 	if req.DateOfBirth != nil {
 		update["$set"].(bson.M)["date_of_birth"] = *req.DateOfBirth
 	}
@@ -167,6 +244,19 @@ func (us *UserService) UpdateUser(userID primitive.ObjectID, req models.UpdatePr
 	if req.SocialLinks != nil {
 		update["$set"].(bson.M)["social_links"] = req.SocialLinks
 	}
+	if req.PreferredLanguages != nil {
+		update["$set"].(bson.M)["preferred_languages"] = normalizeLanguages(req.PreferredLanguages)
+	}
+	if req.ShowSensitiveContent != nil {
+		update["$set"].(bson.M)["show_sensitive_content"] = *req.ShowSensitiveContent
+	}
+	if req.FeedAlgorithm != nil {
+		if *req.FeedAlgorithm == "default" {
+			update["$unset"] = bson.M{"feed_algorithm": ""}
+		} else {
+			update["$set"].(bson.M)["feed_algorithm"] = *req.FeedAlgorithm
+		}
+	}
 
 	_, err := us.collection.UpdateOne(ctx, bson.M{"_id": userID}, update)
 	if err != nil {
@@ -176,11 +266,245 @@ func (us *UserService) UpdateUser(userID primitive.ObjectID, req models.UpdatePr
 	return us.GetUserByID(userID)
 }
 
+// SetInterests replaces the user's interests and reseeds the hashtags that
+// personalize their feed until behavior data accumulates
+func (us *UserService) SetInterests(userID primitive.ObjectID, interests []string) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	categories, err := normalizeInterests(interests)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := us.collection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+		"$set": bson.M{
+			"interests":         categories,
+			"interest_hashtags": seedInterestHashtags(ctx, us.db, categories),
+			"updated_at":        time.Now(),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, errors.New("user not found")
+	}
+
+	return us.GetUserByID(userID)
+}
+
+// FeatureComment features a comment on the user's profile. Only comments on
+// the user's own posts can be featured, up to MaxFeaturedComments.
+func (us *UserService) FeatureComment(userID, commentID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var comment models.Comment
+	err := us.db.Collection("comments").FindOne(ctx, repository.ByID(commentID)).Decode(&comment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.New("comment not found")
+		}
+		return err
+	}
+	if comment.IsHidden || !comment.IsApproved {
+		return errors.New("comment not found")
+	}
+
+	var post models.Post
+	err = us.db.Collection("posts").FindOne(ctx, repository.ByID(comment.PostID),
+		options.FindOne().SetProjection(bson.M{"user_id": 1}),
+	).Decode(&post)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.New("comment not found")
+		}
+		return err
+	}
+	if post.UserID != userID {
+		return errors.New("access denied: only comments on your own posts can be featured")
+	}
+
+	// The filter keeps the list within the cap and free of duplicates
+	// without reading it first
+	result, err := us.collection.UpdateOne(ctx, bson.M{
+		"_id": userID,
+		fmt.Sprintf("featured_comments.%d", models.MaxFeaturedComments-1): bson.M{"$exists": false},
+		"featured_comments": bson.M{"$ne": commentID},
+	}, bson.M{
+		"$push": bson.M{"featured_comments": bson.M{"$each": []primitive.ObjectID{commentID}, "$position": 0}},
+		"$set":  bson.M{"updated_at": time.Now()},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount > 0 {
+		return nil
+	}
+
+	var user models.User
+	err = us.collection.FindOne(ctx, bson.M{"_id": userID},
+		options.FindOne().SetProjection(bson.M{"featured_comments": 1}),
+	).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.New("user not found")
+		}
+		return err
+	}
+	for _, featured := range user.FeaturedComments {
+		if featured == commentID {
+			return errors.New("comment already featured")
+		}
+	}
+	return fmt.Errorf("featured comment limit reached: at most %d comments can be featured", models.MaxFeaturedComments)
+}
+
+// UnfeatureComment removes a comment from the user's featured comments
+func (us *UserService) UnfeatureComment(userID, commentID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := us.collection.UpdateOne(ctx,
+		bson.M{"_id": userID, "featured_comments": commentID},
+		bson.M{
+			"$pull": bson.M{"featured_comments": commentID},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("comment not featured")
+	}
+	return nil
+}
+
+// GetFeaturedComments returns the comments a user features on their profile,
+// newest first. Comments since deleted or hidden, or on posts that aren't
+// public, are left out.
+func (us *UserService) GetFeaturedComments(userID primitive.ObjectID) ([]models.Comment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var user models.User
+	err := us.collection.FindOne(ctx, repository.ByID(userID),
+		options.FindOne().SetProjection(bson.M{"featured_comments": 1}),
+	).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	if len(user.FeaturedComments) == 0 {
+		return []models.Comment{}, nil
+	}
+
+	cursor, err := us.db.Collection("comments").Find(ctx, repository.NotDeleted(bson.M{
+		"_id":         bson.M{"$in": user.FeaturedComments},
+		"is_hidden":   false,
+		"is_approved": true,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var comments []models.Comment
+	if err := cursor.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+
+	// Drop comments deleted or hidden since they were featured, so they
+	// don't use up the cap where the user can't see them to remove them
+	if len(comments) < len(user.FeaturedComments) {
+		found := make(map[primitive.ObjectID]bool, len(comments))
+		for _, comment := range comments {
+			found[comment.ID] = true
+		}
+		var gone []primitive.ObjectID
+		for _, id := range user.FeaturedComments {
+			if !found[id] {
+				gone = append(gone, id)
+			}
+		}
+		if _, err := us.collection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+			"$pullAll": bson.M{"featured_comments": gone},
+		}); err != nil {
+			log.Printf("Failed to prune featured comments of user %s: %v", userID.Hex(), err)
+		}
+	}
+
+	postIDs := make([]primitive.ObjectID, 0, len(comments))
+	authorIDs := make([]primitive.ObjectID, 0, len(comments))
+	for _, comment := range comments {
+		postIDs = append(postIDs, comment.PostID)
+		authorIDs = append(authorIDs, comment.UserID)
+	}
+
+	publicPosts := make(map[primitive.ObjectID]bool, len(postIDs))
+	postCursor, err := us.db.Collection("posts").Find(ctx, repository.NotDeleted(bson.M{
+		"_id":        bson.M{"$in": postIDs},
+		"user_id":    userID,
+		"visibility": models.PrivacyPublic,
+	}), options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var posts []models.Post
+	if err := postCursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+	for _, post := range posts {
+		publicPosts[post.ID] = true
+	}
+
+	authors := make(map[primitive.ObjectID]models.UserResponse, len(authorIDs))
+	authorCursor, err := us.collection.Find(ctx, bson.M{"_id": bson.M{"$in": authorIDs}})
+	if err != nil {
+		return nil, err
+	}
+	var users []models.User
+	if err := authorCursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	for _, author := range users {
+		authors[author.ID] = author.ToUserResponse()
+	}
+
+	byID := make(map[primitive.ObjectID]models.Comment, len(comments))
+	for _, comment := range comments {
+		if publicPosts[comment.PostID] {
+			comment.Author = authors[comment.UserID]
+			byID[comment.ID] = comment
+		}
+	}
+
+	featured := make([]models.Comment, 0, len(byID))
+	for _, id := range user.FeaturedComments {
+		if comment, ok := byID[id]; ok {
+			featured = append(featured, comment)
+		}
+	}
+	return featured, nil
+}
+
 // UpdateUserPrivacySettings updates user privacy settings
 func (us *UserService) UpdateUserPrivacySettings(userID primitive.ObjectID, settings models.PrivacySettings) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	var current models.User
+	err := us.collection.FindOne(ctx, bson.M{"_id": userID},
+		options.FindOne().SetProjection(bson.M{"privacy_settings": 1}),
+	).Decode(&current)
+	if err != nil {
+		return err
+	}
+
 	update := bson.M{
 		"$set": bson.M{
 			"privacy_settings": settings,
@@ -188,8 +512,23 @@ func (us *UserService) UpdateUserPrivacySettings(userID primitive.ObjectID, sett
 		},
 	}
 
-	_, err := us.collection.UpdateOne(ctx, bson.M{"_id": userID}, update)
-	return err
+	if _, err := us.collection.UpdateOne(ctx, bson.M{"_id": userID}, update); err != nil {
+		return err
+	}
+
+	// Suggestions already generated through the user's follows are rebuilt
+	// without them
+	if settings.HideSocialGraph && !current.PrivacySettings.HideSocialGraph {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			if err := invalidateSuggestionsThrough(ctx, us.db, userID); err != nil {
+				log.Printf("Failed to invalidate suggestions through %s: %v", userID.Hex(), err)
+			}
+		}()
+	}
+
+	return nil
 }
 
 // UpdateNotificationSettings updates user notification settings
@@ -263,7 +602,7 @@ func (us *UserService) SearchUsers(query string, limit, skip int) ([]models.User
 				},
 			},
 			{"is_active": true},
-			{"deleted_at": bson.M{"$exists": false}},
+			repository.NotDeleted(),
 		},
 	}
 
@@ -309,62 +648,6 @@ func (us *UserService) GetUserStats(userID primitive.ObjectID) (*models.Stats, e
 	return stats, nil
 }
 
-// GetSuggestedUsers gets suggested users for a user
-func (us *UserService) GetSuggestedUsers(userID primitive.ObjectID, limit int) ([]models.User, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	// Get users that the current user is not following
-	// and have high engagement or mutual connections
-	pipeline := []bson.M{
-		{
-			"$match": bson.M{
-				"_id":        bson.M{"$ne": userID},
-				"is_active":  true,
-				"deleted_at": bson.M{"$exists": false},
-			},
-		},
-		{
-			"$lookup": bson.M{
-				"from":         "follows",
-				"localField":   "_id",
-				"foreignField": "followee_id",
-				"as":           "followers",
-				"pipeline": []bson.M{
-					{"$match": bson.M{"follower_id": userID}},
-				},
-			},
-		},
-		{
-			"$match": bson.M{
-				"followers": bson.M{"$size": 0}, // Not already following
-			},
-		},
-		{
-			"$sort": bson.M{
-				"followers_count": -1,
-				"posts_count":     -1,
-			},
-		},
-		{
-			"$limit": limit,
-		},
-	}
-
-	cursor, err := us.collection.Aggregate(ctx, pipeline)
-	if err != nil {
-		return nil, err
-	}
-	defer cursor.Close(ctx)
-
-	var users []models.User
-	if err := cursor.All(ctx, &users); err != nil {
-		return nil, err
-	}
-
-	return users, nil
-}
-
 // UpdateUserActivity updates user's last activity
 func (us *UserService) UpdateUserActivity(userID primitive.ObjectID, status string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -423,13 +706,7 @@ func (us *UserService) BlockUser(userID, blockedUserID primitive.ObjectID) error
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	update := bson.M{
-		"$addToSet": bson.M{"blocked_users": blockedUserID},
-		"$set":      bson.M{"updated_at": time.Now()},
-	}
-
-	_, err := us.collection.UpdateOne(ctx, bson.M{"_id": userID}, update)
-	return err
+	return blockUser(ctx, us.db, userID, blockedUserID)
 }
 
 // UnblockUser unblocks a user
@@ -437,45 +714,196 @@ func (us *UserService) UnblockUser(userID, blockedUserID primitive.ObjectID) err
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	_, err := us.db.Collection("blocked_users").DeleteOne(ctx, bson.M{
+		"blocker_id": userID,
+		"blocked_id": blockedUserID,
+	})
+	return err
+}
+
+// GetBlockedUsers gets a page of the users blocked by the user
+func (us *UserService) GetBlockedUsers(userID primitive.ObjectID, limit, skip int) ([]models.User, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	blocks := us.db.Collection("blocked_users")
+	filter := bson.M{
+		"blocker_id": userID,
+		"is_active":  true,
+	}
+
+	total, err := blocks.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"created_at": -1}).
+		SetLimit(int64(limit)).
+		SetSkip(int64(skip)).
+		SetProjection(bson.M{"blocked_id": 1})
+
+	cursor, err := blocks.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []models.BlockedUser
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, 0, err
+	}
+
+	if len(entries) == 0 {
+		return []models.User{}, total, nil
+	}
+
+	blockedIDs := make([]primitive.ObjectID, 0, len(entries))
+	for _, entry := range entries {
+		blockedIDs = append(blockedIDs, entry.BlockedID)
+	}
+
+	userCursor, err := us.collection.Find(ctx, bson.M{"_id": bson.M{"$in": blockedIDs}})
+	if err != nil {
+		return nil, 0, err
+	}
+	defer userCursor.Close(ctx)
+
+	var found []models.User
+	if err := userCursor.All(ctx, &found); err != nil {
+		return nil, 0, err
+	}
+
+	// Keep the most-recently-blocked-first order of the page
+	byID := make(map[primitive.ObjectID]models.User, len(found))
+	for _, user := range found {
+		byID[user.ID] = user
+	}
+
+	users := make([]models.User, 0, len(found))
+	for _, id := range blockedIDs {
+		if user, ok := byID[id]; ok {
+			users = append(users, user)
+		}
+	}
+
+	return users, total, nil
+}
+
+// MuteUser mutes a user for the given length, or forever when it is zero.
+// Muting again replaces the previous expiry.
+func (us *UserService) MuteUser(userID, mutedUserID primitive.ObjectID, length time.Duration) (*models.MutedUser, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := us.GetUserByID(mutedUserID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	now := time.Now()
 	update := bson.M{
-		"$pull": bson.M{"blocked_users": blockedUserID},
-		"$set":  bson.M{"updated_at": time.Now()},
+		"$set":         bson.M{"updated_at": now},
+		"$setOnInsert": bson.M{"created_at": now},
 	}
 
-	_, err := us.collection.UpdateOne(ctx, bson.M{"_id": userID}, update)
+	mute := &models.MutedUser{MuterID: userID, MutedID: mutedUserID}
+	if length > 0 {
+		expiresAt := now.Add(length)
+		mute.ExpiresAt = &expiresAt
+		update["$set"].(bson.M)["expires_at"] = expiresAt
+	} else {
+		update["$unset"] = bson.M{"expires_at": ""}
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := us.db.Collection("user_mutes").FindOneAndUpdate(ctx, bson.M{
+		"muter_id": userID,
+		"muted_id": mutedUserID,
+	}, update, opts).Decode(mute)
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return nil, err
+	}
+
+	return mute, nil
+}
+
+// UnmuteUser unmutes a user
+func (us *UserService) UnmuteUser(userID, mutedUserID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := us.db.Collection("user_mutes").DeleteOne(ctx, bson.M{
+		"muter_id": userID,
+		"muted_id": mutedUserID,
+	})
 	return err
 }
 
-// GetBlockedUsers gets list of blocked users
-func (us *UserService) GetBlockedUsers(userID primitive.ObjectID) ([]models.User, error) {
+// GetMutedUsers gets a page of the user's active mutes, most recent first
+func (us *UserService) GetMutedUsers(userID primitive.ObjectID, limit, skip int) ([]models.MutedUserResponse, int64, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	user, err := us.GetUserByID(userID)
+	mutes := us.db.Collection("user_mutes")
+	filter := activeMuteFilter(bson.M{"muter_id": userID})
+
+	total, err := mutes.CountDocuments(ctx, filter)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"updated_at": -1}).
+		SetLimit(int64(limit)).
+		SetSkip(int64(skip))
+
+	cursor, err := mutes.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
 	}
+	defer cursor.Close(ctx)
 
-	if len(user.BlockedUsers) == 0 {
-		return []models.User{}, nil
+	var entries []models.MutedUser
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, 0, err
 	}
 
-	filter := bson.M{
-		"_id": bson.M{"$in": user.BlockedUsers},
+	if len(entries) == 0 {
+		return []models.MutedUserResponse{}, total, nil
+	}
+
+	mutedIDs := make([]primitive.ObjectID, 0, len(entries))
+	for _, entry := range entries {
+		mutedIDs = append(mutedIDs, entry.MutedID)
 	}
 
-	cursor, err := us.collection.Find(ctx, filter)
+	userCursor, err := us.collection.Find(ctx, bson.M{"_id": bson.M{"$in": mutedIDs}})
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	defer cursor.Close(ctx)
+	defer userCursor.Close(ctx)
 
-	var users []models.User
-	if err := cursor.All(ctx, &users); err != nil {
-		return nil, err
+	var found []models.User
+	if err := userCursor.All(ctx, &found); err != nil {
+		return nil, 0, err
 	}
 
-	return users, nil
+	byID := make(map[primitive.ObjectID]models.User, len(found))
+	for _, user := range found {
+		byID[user.ID] = user
+	}
+
+	responses := make([]models.MutedUserResponse, 0, len(entries))
+	for _, entry := range entries {
+		if user, ok := byID[entry.MutedID]; ok {
+			responses = append(responses, entry.ToMutedUserResponse(user.ToUserResponse()))
+		}
+	}
+
+	return responses, total, nil
 }
 
 // SuspendUser suspends a user account
@@ -490,8 +918,11 @@ func (us *UserService) SuspendUser(userID primitive.ObjectID, reason string) err
 		},
 	}
 
-	_, err := us.collection.UpdateOne(ctx, bson.M{"_id": userID}, update)
-	return err
+	if _, err := us.collection.UpdateOne(ctx, bson.M{"_id": userID}, update); err != nil {
+		return err
+	}
+
+	return revokeAccountSessions(ctx, us.db, userID)
 }
 
 // UnsuspendUser unsuspends a user account
@@ -552,13 +983,12 @@ func (us *UserService) CheckUserExists(username, email string) (bool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	filter := bson.M{
+	filter := repository.NotDeleted(bson.M{
 		"$or": []bson.M{
 			{"username": username},
 			{"email": email},
 		},
-		"deleted_at": bson.M{"$exists": false},
-	}
+	})
 
 	count, err := us.collection.CountDocuments(ctx, filter)
 	if err != nil {
@@ -576,16 +1006,406 @@ func (us *UserService) SoftDeleteUser(userID primitive.ObjectID) error {
 	now := time.Now()
 	update := bson.M{
 		"$set": bson.M{
-			"deleted_at": now,
-			"is_active":  false,
-			"updated_at": now,
-			"username":   "deleted_" + userID.Hex(),
-			"email":      "deleted_" + userID.Hex() + "@deleted.com",
+			"deleted_at":     now,
+			"is_active":      false,
+			"updated_at":     now,
+			"username":       "deleted_" + userID.Hex(),
+			"username_lower": "deleted_" + userID.Hex(),
+			"email":          "deleted_" + userID.Hex() + "@deleted.com",
 		},
 	}
 
 	_, err := us.collection.UpdateOne(ctx, bson.M{"_id": userID}, update)
-	return err
+	if err != nil {
+		return err
+	}
+
+	return anonymizeSurveyResponses(ctx, us.db, userID)
+}
+
+// DeactivateTemporarily hides the account without removing it. The user can
+// reactivate by logging in; reactivateAt, when set, restores it automatically.
+func (us *UserService) DeactivateTemporarily(userID primitive.ObjectID, reactivateAt *time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	if reactivateAt != nil {
+		if !reactivateAt.After(now) {
+			return errors.New("invalid reactivation date: must be in the future")
+		}
+		if reactivateAt.Sub(now) > MaxScheduledDeactivation {
+			return errors.New("invalid reactivation date: must be within a year")
+		}
+	}
+
+	set := bson.M{
+		"is_active":      false,
+		"deactivated_at": now,
+		"online_status":  "offline",
+		"updated_at":     now,
+	}
+	update := bson.M{"$set": set}
+	if reactivateAt != nil {
+		set["reactivate_at"] = *reactivateAt
+	} else {
+		update["$unset"] = bson.M{"reactivate_at": ""}
+	}
+
+	result, err := us.collection.UpdateOne(ctx, repository.NotDeleted(bson.M{
+		"_id":       userID,
+		"is_active": true,
+	}), update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+// ReactivateDueAccounts restores temporarily deactivated accounts whose
+// scheduled reactivation has passed and returns them
+func (us *UserService) ReactivateDueAccounts(ctx context.Context) ([]models.User, error) {
+	cursor, err := us.collection.Find(ctx, repository.NotDeleted(bson.M{
+		"deactivated_at": bson.M{"$exists": true},
+		"reactivate_at":  bson.M{"$lte": time.Now()},
+	}), options.Find().SetLimit(reactivationBatch))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var due []models.User
+	if err := cursor.All(ctx, &due); err != nil {
+		return nil, err
+	}
+
+	var reactivated []models.User
+	for _, user := range due {
+		ok, err := reactivateUser(ctx, us.collection, user.ID)
+		if err != nil {
+			return reactivated, err
+		}
+		if ok {
+			reactivated = append(reactivated, user)
+		}
+	}
+
+	return reactivated, nil
+}
+
+// UpdateAutoDeletePolicy sets how long the user's posts are kept. When
+// unpinned posts are already older than the new period, they would be
+// deleted on the next auto-delete run, so the change is refused with an
+// AutoDeleteConfirmationError unless the request confirms it.
+func (us *UserService) UpdateAutoDeletePolicy(userID primitive.ObjectID, req models.UpdateAutoDeleteRequest) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{"updated_at": time.Now()}}
+	if req.AfterDays == 0 {
+		update["$unset"] = bson.M{"auto_delete_posts_after": ""}
+	} else {
+		after := time.Duration(req.AfterDays) * 24 * time.Hour
+		if !req.Confirm {
+			affected, err := us.db.Collection("posts").CountDocuments(ctx, autoDeletablePostsFilter(userID, time.Now().Add(-after)))
+			if err != nil {
+				return nil, err
+			}
+			if affected > 0 {
+				return nil, &models.AutoDeleteConfirmationError{AfterDays: req.AfterDays, AffectedPosts: affected}
+			}
+		}
+		update["$set"].(bson.M)["auto_delete_posts_after"] = after
+	}
+
+	result, err := us.collection.UpdateOne(ctx, repository.NotDeleted(bson.M{"_id": userID}), update)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, errors.New("user not found")
+	}
+
+	return us.GetUserByID(userID)
+}
+
+// StartReactivationJob runs ReactivateDueAccounts every interval until
+// StopReactivationJob is called, welcoming each user back by email
+func (us *UserService) StartReactivationJob(interval time.Duration, emailService *EmailService) {
+	ctx, cancel := context.WithCancel(context.Background())
+	us.stopReactivation = cancel
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				reactivated, err := us.ReactivateDueAccounts(ctx)
+				if ctx.Err() == nil {
+					recordJobHeartbeat(us.db, "scheduled_reactivation", interval, err)
+				}
+				if err != nil && ctx.Err() == nil {
+					log.Printf("Scheduled reactivation failed after %d accounts: %v", len(reactivated), err)
+				}
+				for i := range reactivated {
+					if emailService == nil {
+						break
+					}
+					if err := emailService.SendAccountReactivatedEmail(&reactivated[i]); err != nil {
+						log.Printf("Failed to send welcome back email to user %s: %v", reactivated[i].ID.Hex(), err)
+					}
+				}
+				if len(reactivated) > 0 {
+					log.Printf("Reactivated %d accounts on schedule", len(reactivated))
+				}
+			}
+		}
+	}()
+}
+
+// StopReactivationJob stops the periodic scheduled reactivation
+func (us *UserService) StopReactivationJob() {
+	if us.stopReactivation != nil {
+		us.stopReactivation()
+	}
+}
+
+// reactivateUser restores a temporarily deactivated account. It reports false
+// when the account was not deactivated, e.g. because it was reactivated already.
+func reactivateUser(ctx context.Context, users *mongo.Collection, userID primitive.ObjectID) (bool, error) {
+	result, err := users.UpdateOne(ctx, repository.NotDeleted(bson.M{
+		"_id":            userID,
+		"deactivated_at": bson.M{"$exists": true},
+	}), bson.M{
+		"$set":   bson.M{"is_active": true, "updated_at": time.Now()},
+		"$unset": bson.M{"deactivated_at": "", "reactivate_at": ""},
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// ComputeAbuseScore recomputes userID's abuse score from reports, follow
+// churn and spam flags as described on AbuseScorePolicy, stores it on the
+// user and returns it
+func (us *UserService) ComputeAbuseScore(userID primitive.ObjectID) (float64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	return us.computeAbuseScore(ctx, userID)
+}
+
+func (us *UserService) computeAbuseScore(ctx context.Context, userID primitive.ObjectID) (float64, error) {
+	since := time.Now().Add(-us.abusePolicy.Window)
+	reports := us.db.Collection("reports")
+
+	// Each reporter counts once, at the severity of their worst report
+	cursor, err := reports.Find(ctx, bson.M{
+		"target_type": "user",
+		"target_id":   userID,
+		"status":      bson.M{"$ne": models.ReportRejected},
+		"created_at":  bson.M{"$gte": since},
+	}, options.Find().SetProjection(bson.M{"reporter_id": 1, "priority": 1}))
+	if err != nil {
+		return 0, err
+	}
+	var userReports []models.Report
+	if err := cursor.All(ctx, &userReports); err != nil {
+		return 0, err
+	}
+	worst := make(map[primitive.ObjectID]float64)
+	for _, report := range userReports {
+		severity, ok := reportSeverity[report.Priority]
+		if !ok {
+			severity = reportSeverity["low"]
+		}
+		if severity > worst[report.ReporterID] {
+			worst[report.ReporterID] = severity
+		}
+	}
+	var reportSignal float64
+	for _, severity := range worst {
+		reportSignal += severity
+	}
+
+	churn, err := us.db.Collection("follows").CountDocuments(ctx, bson.M{
+		"follower_id": userID,
+		"created_at":  bson.M{"$gte": since},
+		"deleted_at":  bson.M{"$exists": true},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	duplicates, err := us.db.Collection("content_fingerprints").CountDocuments(ctx, bson.M{
+		"user_id":    userID,
+		"reported":   true,
+		"created_at": bson.M{"$gte": since},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	messageReporters, err := countMessageReporters(ctx, reports, userID, since)
+	if err != nil {
+		return 0, err
+	}
+
+	score := us.abusePolicy.ReportWeight*reportSignal +
+		us.abusePolicy.FollowChurnWeight*float64(churn) +
+		us.abusePolicy.DuplicateWeight*float64(duplicates) +
+		us.abusePolicy.MessageReportWeight*float64(messageReporters)
+	score = math.Round(score*100) / 100
+
+	now := time.Now()
+	if _, err := us.collection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+		"$set": bson.M{"abuse_score": score, "abuse_score_updated_at": now},
+	}); err != nil {
+		return 0, err
+	}
+
+	return score, nil
+}
+
+// countMessageReporters counts the distinct users who reported messages sent
+// by userID since the given time
+func countMessageReporters(ctx context.Context, reports *mongo.Collection, userID primitive.ObjectID, since time.Time) (int, error) {
+	cursor, err := reports.Aggregate(ctx, []bson.M{
+		{"$match": bson.M{
+			"target_type": "message",
+			"status":      bson.M{"$ne": models.ReportRejected},
+			"created_at":  bson.M{"$gte": since},
+		}},
+		{"$lookup": bson.M{
+			"from":         "messages",
+			"localField":   "target_id",
+			"foreignField": "_id",
+			"as":           "message",
+		}},
+		{"$match": bson.M{"message.sender_id": userID}},
+		{"$group": bson.M{"_id": "$reporter_id"}},
+		{"$count": "reporters"},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var result struct {
+		Reporters int `bson:"reporters"`
+	}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&result); err != nil {
+			return 0, err
+		}
+	}
+	return result.Reporters, cursor.Err()
+}
+
+// RecomputeAbuseScores refreshes the score of every user with a signal in
+// the window, plus users still carrying a score so it decays once their
+// signals age out. It returns how many scores were recomputed.
+func (us *UserService) RecomputeAbuseScores(ctx context.Context) (int, error) {
+	since := time.Now().Add(-us.abusePolicy.Window)
+	candidates := make(map[primitive.ObjectID]struct{})
+
+	sources := []struct {
+		collection string
+		field      string
+		filter     bson.M
+	}{
+		{"reports", "target_id", bson.M{"target_type": "user", "created_at": bson.M{"$gte": since}}},
+		{"follows", "follower_id", bson.M{"created_at": bson.M{"$gte": since}, "deleted_at": bson.M{"$exists": true}}},
+		{"content_fingerprints", "user_id", bson.M{"reported": true, "created_at": bson.M{"$gte": since}}},
+		{"users", "_id", bson.M{"abuse_score": bson.M{"$gt": 0}}},
+	}
+	for _, source := range sources {
+		ids, err := us.db.Collection(source.collection).Distinct(ctx, source.field, source.filter)
+		if err != nil {
+			return 0, err
+		}
+		for _, id := range ids {
+			if oid, ok := id.(primitive.ObjectID); ok {
+				candidates[oid] = struct{}{}
+			}
+		}
+	}
+
+	// Message reports point at messages, so collect their senders
+	messageIDs, err := us.db.Collection("reports").Distinct(ctx, "target_id", bson.M{
+		"target_type": "message",
+		"created_at":  bson.M{"$gte": since},
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(messageIDs) > 0 {
+		senders, err := us.db.Collection("messages").Distinct(ctx, "sender_id", bson.M{"_id": bson.M{"$in": messageIDs}})
+		if err != nil {
+			return 0, err
+		}
+		for _, id := range senders {
+			if oid, ok := id.(primitive.ObjectID); ok {
+				candidates[oid] = struct{}{}
+			}
+		}
+	}
+
+	recomputed := 0
+	for userID := range candidates {
+		if ctx.Err() != nil {
+			return recomputed, ctx.Err()
+		}
+		if _, err := us.computeAbuseScore(ctx, userID); err != nil {
+			return recomputed, err
+		}
+		recomputed++
+	}
+
+	return recomputed, nil
+}
+
+// StartAbuseScoreJob runs RecomputeAbuseScores every interval until
+// StopAbuseScoreJob is called
+func (us *UserService) StartAbuseScoreJob(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	us.stopAbuseScore = cancel
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				recomputed, err := us.RecomputeAbuseScores(ctx)
+				if ctx.Err() == nil {
+					recordJobHeartbeat(us.db, "abuse_score", interval, err)
+				}
+				if err != nil && ctx.Err() == nil {
+					log.Printf("Abuse score recompute failed after %d users: %v", recomputed, err)
+				}
+			}
+		}
+	}()
+}
+
+// StopAbuseScoreJob stops the periodic abuse score recompute
+func (us *UserService) StopAbuseScoreJob() {
+	if us.stopAbuseScore != nil {
+		us.stopAbuseScore()
+	}
 }
 
 // GetUserProfile gets complete user profile with context
@@ -595,6 +1415,38 @@ func (us *UserService) GetUserProfile(userID, currentUserID primitive.ObjectID)
 		return nil, err
 	}
 
+	return us.BuildUserProfile(user, currentUserID), nil
+}
+
+// ProfileETag returns the entity tag for user's profile as seen by the viewer.
+// It covers the profile document and counters plus the viewer's block and
+// mute state, so a change on either side yields a new tag.
+func (us *UserService) ProfileETag(user *models.User, currentUserID primitive.ObjectID) string {
+	var blocked bool
+	var mutedUntil *time.Time
+	var muted bool
+
+	if user.ID != currentUserID {
+		ctx := context.Background()
+		blocked = isUserBlocked(ctx, us.db, currentUserID, user.ID)
+		if mute := getActiveMute(ctx, us.db, currentUserID, user.ID); mute != nil {
+			muted = true
+			mutedUntil = mute.ExpiresAt
+		}
+	}
+
+	return utils.WeakETag(
+		user.ID.Hex(), user.UpdatedAt, currentUserID.Hex(),
+		user.FollowersCount, user.FollowingCount, user.PostsCount, user.FriendsCount,
+		user.TotalLikesReceived, user.TotalCommentsReceived, user.ProfileViews,
+		user.OnlineStatus, user.LastActiveAt, blocked, muted, mutedUntil,
+	)
+}
+
+// BuildUserProfile builds the profile response for an already fetched user
+func (us *UserService) BuildUserProfile(user *models.User, currentUserID primitive.ObjectID) *models.ProfileResponse {
+	userID := user.ID
+
 	// Get relationship context if different users
 	var isFollowing, isFollowedBy, isFriend, isBlocked bool
 	var mutualFriends int64
@@ -605,7 +1457,7 @@ func (us *UserService) GetUserProfile(userID, currentUserID primitive.ObjectID)
 		isFollowing = false
 		isFollowedBy = false
 		isFriend = false
-		isBlocked = false
+		isBlocked = isUserBlocked(context.Background(), us.db, currentUserID, userID)
 		mutualFriends = 0
 	}
 
@@ -616,7 +1468,462 @@ func (us *UserService) GetUserProfile(userID, currentUserID primitive.ObjectID)
 		TotalLikesReceived:    user.TotalLikesReceived,
 		TotalCommentsReceived: user.TotalCommentsReceived,
 		ProfileViews:          user.ProfileViews,
+		SocialGraphHidden:     userID != currentUserID && user.PrivacySettings.HideSocialGraph,
+	}
+
+	// Only the muter learns about a mute
+	if userID != currentUserID {
+		if mute := getActiveMute(context.Background(), us.db, currentUserID, userID); mute != nil {
+			profile.IsMuted = true
+			profile.MutedUntil = mute.ExpiresAt
+		}
+	}
+
+	return profile
+}
+
+// blockUser records that blocker has blocked the other user
+func blockUser(ctx context.Context, db *mongo.Database, blockerID, blockedID primitive.ObjectID) error {
+	if blockerID == blockedID {
+		return errors.New("cannot block yourself")
+	}
+
+	now := time.Now()
+	_, err := db.Collection("blocked_users").UpdateOne(ctx, bson.M{
+		"blocker_id": blockerID,
+		"blocked_id": blockedID,
+	}, bson.M{
+		"$set":         bson.M{"is_active": true, "updated_at": now},
+		"$setOnInsert": bson.M{"created_at": now},
+	}, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+// hasBlockBetween checks if any user in one group has blocked any user in the
+// other, in either direction. Callers refuse the action on an error rather
+// than let it through unchecked.
+func hasBlockBetween(ctx context.Context, db *mongo.Database, userIDs, otherIDs []primitive.ObjectID) (bool, error) {
+	count, err := db.Collection("blocked_users").CountDocuments(ctx, bson.M{
+		"is_active": true,
+		"$or": []bson.M{
+			{"blocker_id": bson.M{"$in": userIDs}, "blocked_id": bson.M{"$in": otherIDs}},
+			{"blocker_id": bson.M{"$in": otherIDs}, "blocked_id": bson.M{"$in": userIDs}},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// blockedEitherWay returns the users userID has blocked or been blocked by.
+// A non-nil among limits the lookup to those users.
+func blockedEitherWay(ctx context.Context, db *mongo.Database, userID primitive.ObjectID, among []primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+	blocks := db.Collection("blocked_users")
+	blockedFilter := bson.M{"blocker_id": userID, "is_active": true}
+	blockerFilter := bson.M{"blocked_id": userID, "is_active": true}
+	if among != nil {
+		blockedFilter["blocked_id"] = bson.M{"$in": among}
+		blockerFilter["blocker_id"] = bson.M{"$in": among}
+	}
+
+	blocked, err := blocks.Distinct(ctx, "blocked_id", blockedFilter)
+	if err != nil {
+		return nil, err
+	}
+	blockers, err := blocks.Distinct(ctx, "blocker_id", blockerFilter)
+	if err != nil {
+		return nil, err
 	}
 
-	return profile, nil
+	result := make(map[primitive.ObjectID]bool, len(blocked)+len(blockers))
+	for _, ids := range [][]interface{}{blocked, blockers} {
+		for _, id := range ids {
+			if oid, ok := id.(primitive.ObjectID); ok {
+				result[oid] = true
+			}
+		}
+	}
+	return result, nil
+}
+
+// getPrivacySettings returns a user's privacy settings, or the defaults when
+// the user can't be loaded
+func getPrivacySettings(ctx context.Context, userCollection *mongo.Collection, userID primitive.ObjectID) models.PrivacySettings {
+	var user models.User
+	opts := options.FindOne().SetProjection(bson.M{"privacy_settings": 1})
+	if err := userCollection.FindOne(ctx, bson.M{"_id": userID}, opts).Decode(&user); err != nil {
+		return models.DefaultPrivacySettings()
+	}
+	return user.PrivacySettings
+}
+
+// checkAccountMaturity returns an AccountTooNewError if the user doesn't meet
+// the policy yet
+func checkAccountMaturity(ctx context.Context, users *mongo.Collection, policy models.AccountMaturityPolicy, userID primitive.ObjectID) error {
+	if !policy.Enabled() {
+		return nil
+	}
+
+	var user models.User
+	err := users.FindOne(ctx, bson.M{"_id": userID}, options.FindOne().SetProjection(bson.M{
+		"created_at": 1, "role": 1, "is_verified": 1, "email_verified": 1, "is_premium": 1, "premium_expiry": 1,
+	})).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.New("user not found")
+		}
+		return err
+	}
+	return policy.Check(&user, time.Now())
+}
+
+// isUserBlocked checks if blocker has blocked the other user
+func isUserBlocked(ctx context.Context, db *mongo.Database, blockerID, blockedID primitive.ObjectID) bool {
+	count, err := db.Collection("blocked_users").CountDocuments(ctx, bson.M{
+		"blocker_id": blockerID,
+		"blocked_id": blockedID,
+		"is_active":  true,
+	})
+	return err == nil && count > 0
+}
+
+// activeMuteFilter adds the conditions for a mute that hasn't expired yet to
+// filter. Expired mutes are ignored here and removed by the TTL index.
+func activeMuteFilter(filter bson.M) bson.M {
+	filter["$or"] = []bson.M{
+		{"expires_at": bson.M{"$exists": false}},
+		{"expires_at": bson.M{"$gt": time.Now()}},
+	}
+	return filter
+}
+
+// getActiveMute returns muter's active mute of the other user, or nil
+func getActiveMute(ctx context.Context, db *mongo.Database, muterID, mutedID primitive.ObjectID) *models.MutedUser {
+	var mute models.MutedUser
+	err := db.Collection("user_mutes").FindOne(ctx, activeMuteFilter(bson.M{
+		"muter_id": muterID,
+		"muted_id": mutedID,
+	})).Decode(&mute)
+	if err != nil {
+		return nil
+	}
+	return &mute
+}
+
+// getMutedUserIDs returns the users the muter currently has muted
+func getMutedUserIDs(ctx context.Context, db *mongo.Database, muterID primitive.ObjectID) map[primitive.ObjectID]bool {
+	muted := make(map[primitive.ObjectID]bool)
+
+	opts := options.Find().SetProjection(bson.M{"muted_id": 1})
+	cursor, err := db.Collection("user_mutes").Find(ctx, activeMuteFilter(bson.M{"muter_id": muterID}), opts)
+	if err != nil {
+		return muted
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var mute models.MutedUser
+		if cursor.Decode(&mute) == nil {
+			muted[mute.MutedID] = true
+		}
+	}
+	return muted
+}
+
+// getMutersOf returns which of the given users currently have the muted user muted
+func getMutersOf(ctx context.Context, db *mongo.Database, mutedID primitive.ObjectID, userIDs []primitive.ObjectID) map[primitive.ObjectID]bool {
+	muters := make(map[primitive.ObjectID]bool)
+
+	opts := options.Find().SetProjection(bson.M{"muter_id": 1})
+	cursor, err := db.Collection("user_mutes").Find(ctx, activeMuteFilter(bson.M{
+		"muted_id": mutedID,
+		"muter_id": bson.M{"$in": userIDs},
+	}), opts)
+	if err != nil {
+		return muters
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var mute models.MutedUser
+		if cursor.Decode(&mute) == nil {
+			muters[mute.MuterID] = true
+		}
+	}
+	return muters
+}
+
+// activityPreviewLength caps the post and comment text shown in the activity log
+const activityPreviewLength = 100
+
+// activitySource describes how one collection contributes to the activity log
+type activitySource struct {
+	collection string
+	filter     bson.M
+	sortField  string
+	projection bson.M
+	build      func(cursor *mongo.Cursor) (models.ActivityLogEntry, error)
+}
+
+// GetActivityLog returns the user's own posts, comments, likes, follows and
+// group joins as one reverse-chronological timeline, including private actions
+// that never show on the public profile. Callers must restrict it to the user
+// themselves and admins.
+func (us *UserService) GetActivityLog(userID primitive.ObjectID, page, limit int) ([]models.ActivityLogEntry, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < utils.MinPageSize || limit > utils.MaxPageSize {
+		limit = utils.DefaultPageSize
+	}
+
+	offset := (page - 1) * limit
+	depth := offset + limit
+	if depth > utils.MaxActivityLogDepth {
+		depth = utils.MaxActivityLogDepth
+	}
+
+	// Each source only needs its newest depth entries for the merged page to be exact
+	var entries []models.ActivityLogEntry
+	var total int64
+	for _, source := range activitySources(userID) {
+		found, count, err := us.collectActivity(ctx, source, depth)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, found...)
+		total += count
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].ID > entries[j].ID
+		}
+		return entries[i].CreatedAt.After(entries[j].CreatedAt)
+	})
+
+	if offset >= len(entries) {
+		return []models.ActivityLogEntry{}, total, nil
+	}
+	end := offset + limit
+	if end > len(entries) {
+		end = len(entries)
+	}
+
+	return entries[offset:end], total, nil
+}
+
+// collectActivity counts a source's entries and loads the newest n of them
+func (us *UserService) collectActivity(ctx context.Context, source activitySource, n int) ([]models.ActivityLogEntry, int64, error) {
+	collection := us.db.Collection(source.collection)
+
+	count, err := collection.CountDocuments(ctx, source.filter)
+	if err != nil || count == 0 || n <= 0 {
+		return nil, count, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: source.sortField, Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(n)).
+		SetProjection(source.projection)
+
+	cursor, err := collection.Find(ctx, source.filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []models.ActivityLogEntry
+	for cursor.Next(ctx) {
+		entry, err := source.build(cursor)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, count, cursor.Err()
+}
+
+// activitySources lists the collections that make up a user's activity log
+func activitySources(userID primitive.ObjectID) []activitySource {
+	return []activitySource{
+		{
+			collection: "posts",
+			filter:     repository.NotDeleted(bson.M{"user_id": userID}),
+			sortField:  "created_at",
+			projection: bson.M{"content": 1, "created_at": 1},
+			build: func(cursor *mongo.Cursor) (models.ActivityLogEntry, error) {
+				var post models.Post
+				if err := cursor.Decode(&post); err != nil {
+					return models.ActivityLogEntry{}, err
+				}
+				return models.ActivityLogEntry{
+					Type:      models.ActivityPost,
+					ID:        post.ID.Hex(),
+					Preview:   activityPreview(post.Content),
+					CreatedAt: post.CreatedAt,
+				}, nil
+			},
+		},
+		{
+			collection: "comments",
+			filter:     repository.NotDeleted(bson.M{"user_id": userID}),
+			sortField:  "created_at",
+			projection: bson.M{"post_id": 1, "content": 1, "created_at": 1},
+			build: func(cursor *mongo.Cursor) (models.ActivityLogEntry, error) {
+				var comment models.Comment
+				if err := cursor.Decode(&comment); err != nil {
+					return models.ActivityLogEntry{}, err
+				}
+				return models.ActivityLogEntry{
+					Type:       models.ActivityComment,
+					ID:         comment.ID.Hex(),
+					TargetID:   comment.PostID.Hex(),
+					TargetType: "post",
+					Preview:    activityPreview(comment.Content),
+					CreatedAt:  comment.CreatedAt,
+				}, nil
+			},
+		},
+		{
+			collection: "likes",
+			filter:     bson.M{"user_id": userID},
+			sortField:  "created_at",
+			projection: bson.M{"target_id": 1, "target_type": 1, "reaction_type": 1, "created_at": 1},
+			build: func(cursor *mongo.Cursor) (models.ActivityLogEntry, error) {
+				var like models.Like
+				if err := cursor.Decode(&like); err != nil {
+					return models.ActivityLogEntry{}, err
+				}
+				return models.ActivityLogEntry{
+					Type:         models.ActivityLike,
+					ID:           like.ID.Hex(),
+					TargetID:     like.TargetID.Hex(),
+					TargetType:   like.TargetType,
+					ReactionType: like.ReactionType,
+					CreatedAt:    like.CreatedAt,
+				}, nil
+			},
+		},
+		{
+			collection: "follows",
+			filter: repository.NotDeleted(bson.M{
+				"follower_id": userID,
+				"status":      bson.M{"$in": []models.FollowStatus{models.FollowStatusAccepted, models.FollowStatusPending}},
+			}),
+			sortField:  "created_at",
+			projection: bson.M{"followee_id": 1, "status": 1, "created_at": 1},
+			build: func(cursor *mongo.Cursor) (models.ActivityLogEntry, error) {
+				var follow models.Follow
+				if err := cursor.Decode(&follow); err != nil {
+					return models.ActivityLogEntry{}, err
+				}
+				return models.ActivityLogEntry{
+					Type:       models.ActivityFollow,
+					ID:         follow.ID.Hex(),
+					TargetID:   follow.FolloweeID.Hex(),
+					TargetType: "user",
+					Status:     string(follow.Status),
+					CreatedAt:  follow.CreatedAt,
+				}, nil
+			},
+		},
+		{
+			collection: "group_members",
+			filter:     bson.M{"user_id": userID, "status": bson.M{"$in": []string{"active", "pending"}}},
+			sortField:  "joined_at",
+			projection: bson.M{"group_id": 1, "status": 1, "joined_at": 1},
+			build: func(cursor *mongo.Cursor) (models.ActivityLogEntry, error) {
+				var member models.GroupMember
+				if err := cursor.Decode(&member); err != nil {
+					return models.ActivityLogEntry{}, err
+				}
+				return models.ActivityLogEntry{
+					Type:       models.ActivityGroupJoin,
+					ID:         member.ID.Hex(),
+					TargetID:   member.GroupID.Hex(),
+					TargetType: "group",
+					Status:     member.Status,
+					CreatedAt:  member.JoinedAt,
+				}, nil
+			},
+		},
+	}
+}
+
+// activityPreview shortens post or comment text for the activity log
+func activityPreview(content string) string {
+	runes := []rune(content)
+	if len(runes) <= activityPreviewLength {
+		return content
+	}
+	return string(runes[:activityPreviewLength-3]) + "..."
+}
+
+// interestHashtagsPerCategory is how many hashtags each picked interest seeds
+const interestHashtagsPerCategory = 5
+
+// normalizeInterests lowercases and dedupes interests, rejecting anything
+// outside the hashtag category set
+func normalizeInterests(interests []string) ([]string, error) {
+	categories := []string{}
+	seen := make(map[string]bool, len(interests))
+	for _, interest := range interests {
+		category := strings.ToLower(strings.TrimSpace(interest))
+		if !models.IsValidHashtagCategory(category) {
+			return nil, fmt.Errorf("invalid interest: %s", interest)
+		}
+		if !seen[category] {
+			seen[category] = true
+			categories = append(categories, category)
+		}
+	}
+	if len(categories) > models.MaxUserInterests {
+		return nil, fmt.Errorf("invalid interest: at most %d interests are allowed", models.MaxUserInterests)
+	}
+	return categories, nil
+}
+
+// seedInterestHashtags picks the most used unblocked hashtags of each category.
+// Seeding is best effort, so lookup failures leave the user without seeds.
+func seedInterestHashtags(ctx context.Context, db *mongo.Database, categories []string) []string {
+	hashtags := []string{}
+	if len(categories) == 0 {
+		return hashtags
+	}
+
+	pipeline := []bson.M{
+		{"$match": repository.NotDeleted(bson.M{
+			"category":   bson.M{"$in": categories},
+			"is_blocked": bson.M{"$ne": true},
+		})},
+		{"$sort": bson.D{{Key: "trending_score", Value: -1}, {Key: "posts_count", Value: -1}}},
+		{"$group": bson.M{"_id": "$category", "tags": bson.M{"$push": "$normalized_tag"}}},
+		{"$project": bson.M{"tags": bson.M{"$slice": bson.A{"$tags", interestHashtagsPerCategory}}}},
+	}
+
+	cursor, err := db.Collection("hashtags").Aggregate(ctx, pipeline)
+	if err != nil {
+		return hashtags
+	}
+
+	var groups []struct {
+		Tags []string `bson:"tags"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return hashtags
+	}
+
+	for _, group := range groups {
+		hashtags = append(hashtags, normalizeBoostHashtags(group.Tags)...)
+	}
+	return hashtags
 }
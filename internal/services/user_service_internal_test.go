@@ -0,0 +1,27 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestHasBlockBetweenReturnsLookupErrors(t *testing.T) {
+	// Connect doesn't reach the server; the cancelled context fails the count
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("mongo.Connect: %v", err)
+	}
+	defer client.Disconnect(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ids := []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID()}
+	if blocked, err := hasBlockBetween(ctx, client.Database("test"), ids, ids); err == nil {
+		t.Errorf("hasBlockBetween on a failed lookup = %v with no error, want the error", blocked)
+	}
+}
@@ -0,0 +1,112 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+	"social-media-api/internal/testutil"
+	"social-media-api/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func newTestUserService(h *testutil.Harness) *services.UserService {
+	return services.NewUserService(h.DB, services.AbuseScorePolicy{}, services.NewLimitsService(h.DB, time.Minute))
+}
+
+func TestCreateUser(t *testing.T) {
+	h := testutil.NewHarness(t)
+	users := newTestUserService(h)
+
+	user, err := users.CreateUser(models.RegisterRequest{
+		Username:  "newcomer",
+		Email:     "newcomer@example.com",
+		Password:  "Secret123!",
+		FirstName: "New",
+		LastName:  "Comer",
+	})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	stored := h.ReloadUser(user.ID)
+	if stored.Username != "newcomer" || stored.Email != "newcomer@example.com" {
+		t.Errorf("stored user = %q <%s>, want newcomer <newcomer@example.com>", stored.Username, stored.Email)
+	}
+	if stored.Password == "Secret123!" || !utils.CheckPasswordHash("Secret123!", stored.Password) {
+		t.Error("password wasn't stored as a hash of the given password")
+	}
+	if !stored.IsActive || stored.Role != models.RoleUser {
+		t.Errorf("new user active = %v, role = %q, want active with role %q", stored.IsActive, stored.Role, models.RoleUser)
+	}
+	if stored.FollowersCount != 0 || stored.FollowingCount != 0 || stored.PostsCount != 0 {
+		t.Errorf("new user counters = %d/%d/%d, want zero", stored.FollowersCount, stored.FollowingCount, stored.PostsCount)
+	}
+}
+
+func TestCreateUserRejectsDuplicates(t *testing.T) {
+	h := testutil.NewHarness(t)
+	users := newTestUserService(h)
+	existing := h.CreateUser()
+	before := h.Count("users", bson.M{})
+
+	tests := []struct {
+		name     string
+		username string
+		email    string
+	}{
+		{"username taken", existing.Username, "other@example.com"},
+		{"email taken", "someoneelse", existing.Email},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := users.CreateUser(models.RegisterRequest{
+				Username: tt.username,
+				Email:    tt.email,
+				Password: "Secret123!",
+			})
+			if err == nil {
+				t.Fatal("CreateUser succeeded, want an error")
+			}
+		})
+	}
+
+	if got := h.Count("users", bson.M{}); got != before {
+		t.Errorf("users = %d after rejected registrations, want %d", got, before)
+	}
+}
+
+func TestUpdateUserCounts(t *testing.T) {
+	h := testutil.NewHarness(t)
+	users := newTestUserService(h)
+	user := h.CreateUser()
+
+	for i := 0; i < 3; i++ {
+		if err := users.UpdateUserCounts(user.ID, "posts", true); err != nil {
+			t.Fatalf("UpdateUserCounts: %v", err)
+		}
+	}
+	if err := users.UpdateUserCounts(user.ID, "posts", false); err != nil {
+		t.Fatalf("UpdateUserCounts: %v", err)
+	}
+
+	if got := h.ReloadUser(user.ID).PostsCount; got != 2 {
+		t.Errorf("posts_count = %d, want 2", got)
+	}
+}
+
+func TestBlockUserRejectsSelf(t *testing.T) {
+	h := testutil.NewHarness(t)
+	users := newTestUserService(h)
+	user := h.CreateUser()
+
+	if err := users.BlockUser(user.ID, user.ID); err == nil || err.Error() != "cannot block yourself" {
+		t.Errorf("BlockUser(self) error = %v, want \"cannot block yourself\"", err)
+	}
+	if got := h.Count("blocked_users", bson.M{}); got != 0 {
+		t.Errorf("blocked_users = %d, want 0", got)
+	}
+}
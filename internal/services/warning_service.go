@@ -0,0 +1,387 @@
+// internal/services/warning_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"social-media-api/internal/config"
+	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WarningPolicy decides when strikes escalate to a suspension and how long
+// they count. A zero threshold disables that escalation and a zero expiry
+// keeps strikes forever.
+type WarningPolicy struct {
+	SuspendStrikes  int
+	SuspendDuration time.Duration
+	BanStrikes      int
+	StrikeExpiry    time.Duration
+}
+
+const (
+	// StrikeExpiryCheckInterval is how often the strike expiry job runs
+	StrikeExpiryCheckInterval = 15 * time.Minute
+
+	strikeExpiryBatch = 200
+)
+
+type WarningService struct {
+	collection          *mongo.Collection
+	userCollection      *mongo.Collection
+	db                  *mongo.Database
+	notificationService *NotificationService
+	policy              WarningPolicy
+	stopExpiry          context.CancelFunc
+}
+
+func NewWarningService(db *mongo.Database, notificationService *NotificationService, policy WarningPolicy) *WarningService {
+	return &WarningService{
+		collection:          db.Collection("user_warnings"),
+		userCollection:      db.Collection("users"),
+		db:                  db,
+		notificationService: notificationService,
+		policy:              policy,
+	}
+}
+
+// IssueWarning gives a user a strike and suspends them when their active
+// strikes reach one of the policy thresholds
+func (ws *WarningService) IssueWarning(userID, issuedBy primitive.ObjectID, reason string, reportID *primitive.ObjectID) (*models.UserWarning, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if userID == issuedBy {
+		return nil, errors.New("cannot warn yourself")
+	}
+
+	if reportID != nil {
+		count, err := ws.db.Collection("reports").CountDocuments(ctx, bson.M{"_id": *reportID})
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, errors.New("report not found")
+		}
+	}
+
+	now := time.Now()
+	warning := &models.UserWarning{
+		UserID:   userID,
+		Reason:   reason,
+		IssuedBy: issuedBy,
+		ReportID: reportID,
+	}
+	warning.ID = primitive.NewObjectID()
+	warning.BeforeCreate()
+	if ws.policy.StrikeExpiry > 0 {
+		expiresAt := now.Add(ws.policy.StrikeExpiry)
+		warning.ExpiresAt = &expiresAt
+	}
+
+	var struck, inserted bool
+
+	transactional, err := config.RunInTransaction(ctx, ws.db, func(ctx context.Context) error {
+		var user models.User
+		err := ws.userCollection.FindOneAndUpdate(ctx, repository.ByID(userID), bson.M{
+			"$inc": bson.M{"strike_count": 1},
+			"$set": bson.M{"updated_at": now},
+		}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&user)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return errors.New("user not found")
+			}
+			return err
+		}
+		struck = true
+
+		warning.StrikeNumber = user.StrikeCount
+		warning.Escalation = ws.escalationFor(user.StrikeCount)
+		if warning.Escalation == models.EscalationTempSuspension {
+			until := now.Add(ws.policy.SuspendDuration)
+			warning.SuspendedUntil = &until
+		}
+
+		if _, err := ws.collection.InsertOne(ctx, warning); err != nil {
+			return err
+		}
+		inserted = true
+
+		return ws.applyEscalation(ctx, userID, warning)
+	})
+	if err != nil {
+		if !transactional {
+			// Compensate: undo whatever part of the strike was written
+			if inserted {
+				ws.collection.DeleteOne(context.Background(), bson.M{"_id": warning.ID})
+			}
+			if struck {
+				ws.userCollection.UpdateOne(context.Background(), bson.M{"_id": userID}, bson.M{
+					"$inc": bson.M{"strike_count": -1},
+				})
+			}
+		}
+		return nil, err
+	}
+
+	if ws.notificationService != nil {
+		go ws.notifyWarning(warning)
+	}
+
+	return warning, nil
+}
+
+// GetUserWarnings lists a user's warnings, newest first. Admin views name the
+// moderator who issued each one.
+func (ws *WarningService) GetUserWarnings(userID primitive.ObjectID, withIssuer bool, limit, skip int) ([]models.UserWarning, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.M{"created_at": -1}).
+		SetLimit(int64(limit)).
+		SetSkip(int64(skip))
+
+	cursor, err := ws.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var warnings []models.UserWarning
+	if err := cursor.All(ctx, &warnings); err != nil {
+		return nil, err
+	}
+
+	if withIssuer {
+		for i := range warnings {
+			var issuer models.User
+			if err := ws.userCollection.FindOne(ctx, bson.M{"_id": warnings[i].IssuedBy}).Decode(&issuer); err == nil {
+				warnings[i].Issuer = issuer.ToUserResponse()
+			}
+		}
+	}
+
+	return warnings, nil
+}
+
+// ExpireStrikes marks warnings past their expiry as expired and takes them
+// off their users' strike counts. It returns how many strikes expired.
+func (ws *WarningService) ExpireStrikes(ctx context.Context) (int, error) {
+	cursor, err := ws.collection.Find(ctx, bson.M{
+		"expired":    false,
+		"expires_at": bson.M{"$lte": time.Now()},
+	}, options.Find().SetLimit(strikeExpiryBatch))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var due []models.UserWarning
+	if err := cursor.All(ctx, &due); err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, warning := range due {
+		// Guard on expired so two instances can't both decrement
+		result, err := ws.collection.UpdateOne(ctx, bson.M{
+			"_id":     warning.ID,
+			"expired": false,
+		}, bson.M{
+			"$set": bson.M{"expired": true, "updated_at": time.Now()},
+		})
+		if err != nil {
+			return expired, err
+		}
+		if result.ModifiedCount == 0 {
+			continue
+		}
+
+		_, err = ws.userCollection.UpdateOne(ctx, bson.M{
+			"_id":          warning.UserID,
+			"strike_count": bson.M{"$gt": 0},
+		}, bson.M{
+			"$inc": bson.M{"strike_count": -1},
+		})
+		if err != nil {
+			return expired, err
+		}
+		expired++
+	}
+
+	return expired, nil
+}
+
+// LiftEndedSuspensions restores accounts whose strike suspension has run its
+// course and returns their IDs
+func (ws *WarningService) LiftEndedSuspensions(ctx context.Context) ([]primitive.ObjectID, error) {
+	cursor, err := ws.userCollection.Find(ctx, bson.M{
+		"is_suspended":    true,
+		"suspended_until": bson.M{"$lte": time.Now()},
+	}, options.Find().SetLimit(strikeExpiryBatch).SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var due []models.User
+	if err := cursor.All(ctx, &due); err != nil {
+		return nil, err
+	}
+
+	var lifted []primitive.ObjectID
+	for _, user := range due {
+		result, err := ws.userCollection.UpdateOne(ctx, bson.M{
+			"_id":             user.ID,
+			"is_suspended":    true,
+			"suspended_until": bson.M{"$lte": time.Now()},
+		}, bson.M{
+			"$set":   bson.M{"is_suspended": false, "updated_at": time.Now()},
+			"$unset": bson.M{"suspended_until": ""},
+		})
+		if err != nil {
+			return lifted, err
+		}
+		if result.ModifiedCount > 0 {
+			lifted = append(lifted, user.ID)
+		}
+	}
+
+	return lifted, nil
+}
+
+// StartStrikeExpiryJob runs ExpireStrikes and LiftEndedSuspensions every
+// interval until StopStrikeExpiryJob is called
+func (ws *WarningService) StartStrikeExpiryJob(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ws.stopExpiry = cancel
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				expired, err := ws.ExpireStrikes(ctx)
+				if err != nil && ctx.Err() == nil {
+					log.Printf("Strike expiry failed after %d strikes: %v", expired, err)
+				}
+
+				lifted, liftErr := ws.LiftEndedSuspensions(ctx)
+				if liftErr != nil && ctx.Err() == nil {
+					log.Printf("Lifting strike suspensions failed after %d accounts: %v", len(lifted), liftErr)
+				}
+				if ctx.Err() == nil {
+					if err == nil {
+						err = liftErr
+					}
+					recordJobHeartbeat(ws.db, "strike_expiry", interval, err)
+				}
+				for _, userID := range lifted {
+					if ws.notificationService == nil {
+						break
+					}
+					ws.notificationService.NotifyUserUnsuspension(userID, "Your temporary suspension has ended")
+				}
+
+				if expired > 0 || len(lifted) > 0 {
+					log.Printf("Expired %d strikes and lifted %d suspensions", expired, len(lifted))
+				}
+			}
+		}
+	}()
+}
+
+// StopStrikeExpiryJob stops the periodic strike expiry
+func (ws *WarningService) StopStrikeExpiryJob() {
+	if ws.stopExpiry != nil {
+		ws.stopExpiry()
+	}
+}
+
+// Helper methods
+
+// escalationFor returns what reaching strikeCount active strikes triggers.
+// Every strike at or beyond a threshold escalates again.
+func (ws *WarningService) escalationFor(strikeCount int) models.WarningEscalation {
+	if ws.policy.BanStrikes > 0 && strikeCount >= ws.policy.BanStrikes {
+		return models.EscalationPermanentSuspend
+	}
+	if ws.policy.SuspendStrikes > 0 && strikeCount >= ws.policy.SuspendStrikes {
+		return models.EscalationTempSuspension
+	}
+	return models.EscalationNone
+}
+
+// applyEscalation suspends the warned user and signs them out. A temporary
+// suspension never shortens or replaces a permanent one.
+func (ws *WarningService) applyEscalation(ctx context.Context, userID primitive.ObjectID, warning *models.UserWarning) error {
+	var err error
+	switch warning.Escalation {
+	case models.EscalationPermanentSuspend:
+		_, err = ws.userCollection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+			"$set":   bson.M{"is_suspended": true, "updated_at": time.Now()},
+			"$unset": bson.M{"suspended_until": ""},
+		})
+	case models.EscalationTempSuspension:
+		_, err = ws.userCollection.UpdateOne(ctx, bson.M{
+			"_id": userID,
+			"$or": []bson.M{
+				{"is_suspended": bson.M{"$ne": true}},
+				{"suspended_until": bson.M{"$lt": *warning.SuspendedUntil}},
+			},
+		}, bson.M{
+			"$set": bson.M{
+				"is_suspended":    true,
+				"suspended_until": *warning.SuspendedUntil,
+				"updated_at":      time.Now(),
+			},
+		})
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return revokeAccountSessions(ctx, ws.db, userID)
+}
+
+func (ws *WarningService) notifyWarning(warning *models.UserWarning) {
+	ws.notificationService.NotifyUserWarning(warning.UserID, warning.ID, warning.Reason, warning.StrikeNumber, warning.ExpiresAt)
+
+	switch warning.Escalation {
+	case models.EscalationPermanentSuspend:
+		ws.notificationService.NotifyUserSuspension(warning.UserID, warning.Reason, "permanent")
+	case models.EscalationTempSuspension:
+		ws.notificationService.NotifyUserSuspension(warning.UserID, warning.Reason, formatSuspensionDuration(ws.policy.SuspendDuration))
+	}
+}
+
+// formatSuspensionDuration describes a suspension length the way users read it
+func formatSuspensionDuration(d time.Duration) string {
+	if days := int(d.Hours() / 24); days >= 1 && d%(24*time.Hour) == 0 {
+		if days == 1 {
+			return "1 day"
+		}
+		return fmt.Sprintf("%d days", days)
+	}
+	hours := int(d.Hours())
+	if hours == 1 {
+		return "1 hour"
+	}
+	return fmt.Sprintf("%d hours", hours)
+}
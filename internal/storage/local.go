@@ -427,6 +427,8 @@ func getContentTypeFromExtension(ext string) string {
 		return "image/gif"
 	case ".webp":
 		return "image/webp"
+	case ".avif":
+		return "image/avif"
 	case ".bmp":
 		return "image/bmp"
 	case ".tiff", ".tif":
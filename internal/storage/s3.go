@@ -26,6 +26,8 @@ type S3Provider struct {
 	region     string
 	cdnDomain  string
 	baseURL    string
+
+	storageClass string // S3 storage class for uploads, bucket default when empty
 }
 
 // NewS3Provider creates a new S3 storage provider
@@ -65,6 +67,8 @@ func NewS3Provider(config StorageConfig) (*S3Provider, error) {
 		region:     config.Region,
 		cdnDomain:  config.CDNDomain,
 		baseURL:    baseURL,
+
+		storageClass: config.Options["storage_class"],
 	}, nil
 }
 
@@ -121,6 +125,10 @@ func (s *S3Provider) Upload(key string, data io.Reader, contentType string, size
 		uploadInput.ACL = aws.String("public-read")
 	}
 
+	if s.storageClass != "" {
+		uploadInput.StorageClass = aws.String(s.storageClass)
+	}
+
 	// Set cache control for media files
 	if isMediaContentType(contentType) {
 		uploadInput.CacheControl = aws.String("public, max-age=31536000") // 1 year
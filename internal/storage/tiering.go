@@ -0,0 +1,95 @@
+// tiering.go
+package storage
+
+import (
+	"fmt"
+	"io"
+	"path"
+)
+
+// Storage tiers
+const (
+	TierHot  = "hot"  // Fast storage serving everyday traffic
+	TierCold = "cold" // Cheaper storage for files that are rarely read
+)
+
+// coldKeyPrefix keeps archived files apart from hot ones when both tiers
+// share a bucket, e.g. an S3 bucket with an infrequent-access storage class
+const coldKeyPrefix = "cold"
+
+// TieredStorage moves files between a hot and a cold provider. Files keep
+// their hot key; the cold copy lives under coldKeyPrefix.
+type TieredStorage struct {
+	hot  StorageProvider
+	cold StorageProvider
+}
+
+// NewTieredStorage creates tiered storage over the two providers
+func NewTieredStorage(hot, cold StorageProvider) *TieredStorage {
+	return &TieredStorage{hot: hot, cold: cold}
+}
+
+// Open retrieves a file from the tier it lives in
+func (t *TieredStorage) Open(tier, key string) (io.ReadCloser, error) {
+	if tier == TierCold {
+		return t.cold.Download(coldKey(key))
+	}
+	return t.hot.Download(key)
+}
+
+// Delete removes a file from the tier it lives in
+func (t *TieredStorage) Delete(tier, key string) error {
+	if tier == TierCold {
+		return t.cold.Delete(coldKey(key))
+	}
+	return t.hot.Delete(key)
+}
+
+// Archive moves a file from the hot to the cold tier
+func (t *TieredStorage) Archive(key string) error {
+	return transfer(t.hot, key, t.cold, coldKey(key))
+}
+
+// Restore moves a file from the cold back to the hot tier
+func (t *TieredStorage) Restore(key string) error {
+	return transfer(t.cold, coldKey(key), t.hot, key)
+}
+
+// GetStorageInfo returns information about both tiers
+func (t *TieredStorage) GetStorageInfo() map[string]StorageInfo {
+	return map[string]StorageInfo{
+		TierHot:  t.hot.GetStorageInfo(),
+		TierCold: t.cold.GetStorageInfo(),
+	}
+}
+
+// transfer copies a file between providers and removes the source only once
+// the destination holds a complete copy
+func transfer(src StorageProvider, srcKey string, dst StorageProvider, dstKey string) error {
+	metadata, err := src.GetMetadata(srcKey)
+	if err != nil {
+		return err
+	}
+
+	reader, err := src.Download(srcKey)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	result, err := dst.Upload(dstKey, reader, metadata.ContentType, metadata.Size)
+	if err != nil {
+		return err
+	}
+	if result.Size != metadata.Size {
+		dst.Delete(dstKey)
+		return NewStorageErrorWithKey(ErrCodeInternal,
+			fmt.Sprintf("incomplete copy: expected %d bytes, got %d", metadata.Size, result.Size), srcKey)
+	}
+
+	return src.Delete(srcKey)
+}
+
+func coldKey(key string) string {
+	return path.Join(coldKeyPrefix, key)
+}
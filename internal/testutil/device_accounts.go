@@ -0,0 +1,48 @@
+// internal/testutil/device_accounts.go
+package testutil
+
+import (
+	"social-media-api/internal/models"
+	"social-media-api/internal/services"
+)
+
+// SignIn logs user in through AuthService, starting a new device group
+func (h *Harness) SignIn(user *models.User) *services.LoginResponse {
+	h.T.Helper()
+
+	response, err := h.NewAuthService().Login(models.LoginRequest{
+		EmailOrUsername: user.Username,
+		Password:        DefaultPassword,
+		DeviceInfo:      "test",
+	})
+	if err != nil {
+		h.T.Fatalf("failed to sign in %s: %v", user.Username, err)
+	}
+	return response
+}
+
+// SessionID returns the session an access token was issued for
+func (h *Harness) SessionID(response *services.LoginResponse) string {
+	h.T.Helper()
+
+	claims, err := h.NewAuthService().ValidateAccessToken(response.AccessToken)
+	if err != nil {
+		h.T.Fatalf("failed to read access token: %v", err)
+	}
+	sessionID, _ := claims["session_id"].(string)
+	return sessionID
+}
+
+// AddAccount signs user in as an additional account on the device of sessionID
+func (h *Harness) AddAccount(sessionID string, user *models.User) *services.LoginResponse {
+	h.T.Helper()
+
+	response, err := h.NewAuthService().AddAccount(sessionID, models.AddAccountRequest{
+		EmailOrUsername: user.Username,
+		Password:        DefaultPassword,
+	})
+	if err != nil {
+		h.T.Fatalf("failed to add account %s: %v", user.Username, err)
+	}
+	return response
+}
@@ -0,0 +1,214 @@
+// internal/testutil/factories.go
+package testutil
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultPassword is the plain-text password of every user created by CreateUser
+const DefaultPassword = "Password123!"
+
+// sequence keeps generated usernames and emails unique within a test run
+var sequence int64
+
+func nextSequence() int64 {
+	return atomic.AddInt64(&sequence, 1)
+}
+
+// UserOption customises a user built by CreateUser. Options run after
+// BeforeCreate, so they can override the defaults it sets.
+type UserOption func(*models.User)
+
+// WithPrivateAccount makes the user's profile private
+func WithPrivateAccount() UserOption {
+	return func(u *models.User) { u.IsPrivate = true }
+}
+
+// WithRole sets the user's role
+func WithRole(role models.UserRole) UserOption {
+	return func(u *models.User) { u.Role = role }
+}
+
+// WithUsername overrides the generated username
+func WithUsername(username string) UserOption {
+	return func(u *models.User) { u.Username = username }
+}
+
+// CreateUser inserts an active, email-verified user
+func (h *Harness) CreateUser(opts ...UserOption) *models.User {
+	h.T.Helper()
+
+	hashedPassword, err := utils.HashPassword(DefaultPassword)
+	if err != nil {
+		h.T.Fatalf("failed to hash password: %v", err)
+	}
+
+	n := nextSequence()
+	user := &models.User{
+		Username:  fmt.Sprintf("testuser%d", n),
+		Email:     fmt.Sprintf("testuser%d@example.com", n),
+		Password:  hashedPassword,
+		FirstName: "Test",
+		LastName:  fmt.Sprintf("User%d", n),
+	}
+	user.DisplayName = user.FirstName + " " + user.LastName
+
+	user.BeforeCreate()
+	user.EmailVerified = true
+
+	for _, opt := range opts {
+		opt(user)
+	}
+
+	result, err := h.DB.Collection("users").InsertOne(h.Context(), user)
+	if err != nil {
+		h.T.Fatalf("failed to create user: %v", err)
+	}
+	user.ID = result.InsertedID.(primitive.ObjectID)
+
+	return user
+}
+
+// PostOption customises a post built by CreatePost
+type PostOption func(*models.Post)
+
+// WithVisibility sets the post's visibility
+func WithVisibility(visibility models.PrivacyLevel) PostOption {
+	return func(p *models.Post) { p.Visibility = visibility }
+}
+
+// WithContent overrides the generated post content
+func WithContent(content string) PostOption {
+	return func(p *models.Post) { p.Content = content }
+}
+
+// CreatePost inserts a published public text post authored by user
+func (h *Harness) CreatePost(author *models.User, opts ...PostOption) *models.Post {
+	h.T.Helper()
+
+	post := &models.Post{
+		UserID:      author.ID,
+		Content:     fmt.Sprintf("Test post %d", nextSequence()),
+		ContentType: models.ContentTypeText,
+		Type:        "post",
+		Visibility:  models.PrivacyPublic,
+		Language:    "en",
+		IsPublished: true,
+	}
+
+	post.BeforeCreate()
+	now := time.Now()
+	post.PublishedAt = &now
+
+	for _, opt := range opts {
+		opt(post)
+	}
+
+	result, err := h.DB.Collection("posts").InsertOne(h.Context(), post)
+	if err != nil {
+		h.T.Fatalf("failed to create post: %v", err)
+	}
+	post.ID = result.InsertedID.(primitive.ObjectID)
+
+	return post
+}
+
+// CreateComment inserts a top-level text comment on post
+func (h *Harness) CreateComment(author *models.User, post *models.Post, content string) *models.Comment {
+	h.T.Helper()
+
+	comment := &models.Comment{
+		UserID:      author.ID,
+		PostID:      post.ID,
+		Content:     content,
+		ContentType: models.ContentTypeText,
+		Level:       0,
+	}
+	comment.BeforeCreate()
+
+	result, err := h.DB.Collection("comments").InsertOne(h.Context(), comment)
+	if err != nil {
+		h.T.Fatalf("failed to create comment: %v", err)
+	}
+	comment.ID = result.InsertedID.(primitive.ObjectID)
+
+	return comment
+}
+
+// CreateFollow inserts a follow relationship with the given status. Counters
+// are not touched, mirroring how the seeder inserts follows in bulk.
+func (h *Harness) CreateFollow(follower, followee *models.User, status models.FollowStatus) *models.Follow {
+	h.T.Helper()
+
+	follow := &models.Follow{
+		FollowerID: follower.ID,
+		FolloweeID: followee.ID,
+		Status:     status,
+	}
+	follow.BeforeCreate()
+
+	result, err := h.DB.Collection("follows").InsertOne(h.Context(), follow)
+	if err != nil {
+		h.T.Fatalf("failed to create follow: %v", err)
+	}
+	follow.ID = result.InsertedID.(primitive.ObjectID)
+
+	return follow
+}
+
+// ConversationOption customises a conversation built by CreateConversation
+type ConversationOption func(*models.Conversation)
+
+// WithRequestPending puts the conversation in the user's message requests
+func WithRequestPending(user *models.User) ConversationOption {
+	return func(c *models.Conversation) { c.SetRequestPending(user.ID) }
+}
+
+// CreateConversation inserts a conversation started by creator with the
+// others, direct for a single other participant and a group otherwise
+func (h *Harness) CreateConversation(creator *models.User, others []*models.User, opts ...ConversationOption) *models.Conversation {
+	h.T.Helper()
+
+	conversation := &models.Conversation{
+		Type:         "direct",
+		Participants: []primitive.ObjectID{creator.ID},
+		CreatedBy:    creator.ID,
+	}
+	if len(others) > 1 {
+		conversation.Type = "group"
+	}
+	for _, other := range others {
+		conversation.Participants = append(conversation.Participants, other.ID)
+	}
+	conversation.BeforeCreate()
+	for _, opt := range opts {
+		opt(conversation)
+	}
+
+	result, err := h.DB.Collection("conversations").InsertOne(h.Context(), conversation)
+	if err != nil {
+		h.T.Fatalf("failed to create conversation: %v", err)
+	}
+	conversation.ID = result.InsertedID.(primitive.ObjectID)
+
+	return conversation
+}
+
+// ReloadUser reads a user back from the database, for checking counters
+func (h *Harness) ReloadUser(id primitive.ObjectID) *models.User {
+	h.T.Helper()
+
+	var user models.User
+	if err := h.DB.Collection("users").FindOne(h.Context(), bson.M{"_id": id}).Decode(&user); err != nil {
+		h.T.Fatalf("failed to reload user %s: %v", id.Hex(), err)
+	}
+	return &user
+}
@@ -0,0 +1,72 @@
+// internal/testutil/read_routing.go
+package testutil
+
+import (
+	"context"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SimulateReplicaLag turns read routing on with secondary reads sent to an
+// empty copy of the test database, as if the secondaries had not replicated
+// anything yet. window is the read-your-writes window. Routing is turned off
+// again when the test finishes.
+func (h *Harness) SimulateReplicaLag(window time.Duration) {
+	h.T.Helper()
+
+	lagged := h.Client.Database(h.DB.Name() + "_lagged")
+	repository.ConfigureReadRouting(repository.ReadRoutingPolicy{
+		Enabled:              true,
+		MaxStaleness:         90 * time.Second,
+		ReadYourWritesWindow: window,
+		Secondary: func(coll *mongo.Collection) *mongo.Collection {
+			return lagged.Collection(coll.Name())
+		},
+	})
+
+	h.T.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		repository.ConfigureReadRouting(repository.ReadRoutingPolicy{})
+		lagged.Drop(ctx)
+	})
+}
+
+// AssertReadYourWrites checks a heavy read against simulated replica lag.
+// write creates content as author and returns its ID; read lists content as
+// the given viewer and returns the IDs it saw. The author must see their
+// write straight away, other must not while the secondary lags, and the
+// author falls back to the secondary once window has passed.
+func (h *Harness) AssertReadYourWrites(author, other *models.User, window time.Duration, write func() primitive.ObjectID, read func(viewerID *primitive.ObjectID) []primitive.ObjectID) {
+	h.T.Helper()
+
+	h.SimulateReplicaLag(window)
+	written := write()
+
+	if !containsID(read(&author.ID), written) {
+		h.T.Errorf("author did not read their own write %s within the read-your-writes window", written.Hex())
+	}
+	if containsID(read(&other.ID), written) {
+		h.T.Errorf("another user read %s from the primary; heavy reads should go to the lagging secondary", written.Hex())
+	}
+
+	time.Sleep(window + 50*time.Millisecond)
+	if containsID(read(&author.ID), written) {
+		h.T.Errorf("author still read %s from the primary after the read-your-writes window", written.Hex())
+	}
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
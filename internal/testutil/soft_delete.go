@@ -0,0 +1,196 @@
+// internal/testutil/soft_delete.go
+package testutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// softDeleteCheckText is the text of the seeded documents, sent as the search
+// query so search endpoints match them too
+const softDeleteCheckText = "soft delete check"
+
+// SoftDeletedPair is a live document and its soft-deleted twin
+type SoftDeletedPair struct {
+	Collection string
+	Live       primitive.ObjectID
+	Deleted    primitive.ObjectID
+	Refs       []primitive.ObjectID // IDs the documents reference, such as their owner or post
+}
+
+// SeedSoftDeleted inserts two copies of doc into collection and soft-deletes
+// one of them
+func (h *Harness) SeedSoftDeleted(collection string, doc bson.M) SoftDeletedPair {
+	h.T.Helper()
+
+	pair := SoftDeletedPair{
+		Collection: collection,
+		Live:       primitive.NewObjectID(),
+		Deleted:    primitive.NewObjectID(),
+	}
+	now := time.Now()
+
+	live := bson.M{"created_at": now, "updated_at": now}
+	deleted := bson.M{"created_at": now, "updated_at": now}
+	for key, value := range doc {
+		live[key] = value
+		deleted[key] = value
+	}
+	for _, value := range doc {
+		if id, ok := value.(primitive.ObjectID); ok {
+			pair.Refs = append(pair.Refs, id)
+		}
+	}
+	live["_id"] = pair.Live
+	deleted["_id"] = pair.Deleted
+	deleted[repository.DeletedAtField] = now
+
+	if _, err := h.DB.Collection(collection).InsertMany(h.Context(), []interface{}{live, deleted}); err != nil {
+		h.T.Fatalf("failed to seed %s: %v", collection, err)
+	}
+	return pair
+}
+
+// SeedSoftDeletedContent seeds a live and a deleted document owned by user in
+// each collection the list endpoints read, shaped so every filter other than
+// the soft-delete one would match them
+func (h *Harness) SeedSoftDeletedContent(owner *models.User) []SoftDeletedPair {
+	h.T.Helper()
+
+	post := h.CreatePost(owner)
+	return []SoftDeletedPair{
+		h.SeedSoftDeleted("posts", bson.M{
+			"user_id":      owner.ID,
+			"content":      softDeleteCheckText,
+			"content_type": models.ContentTypeText,
+			"type":         "post",
+			"visibility":   models.PrivacyPublic,
+			"is_published": true,
+			"published_at": time.Now(),
+		}),
+		h.SeedSoftDeleted("comments", bson.M{
+			"user_id":      owner.ID,
+			"post_id":      post.ID,
+			"content":      softDeleteCheckText,
+			"content_type": models.ContentTypeText,
+			"level":        0,
+			"is_approved":  true,
+			"is_hidden":    false,
+		}),
+		h.SeedSoftDeleted("stories", bson.M{
+			"user_id":    owner.ID,
+			"content":    softDeleteCheckText,
+			"visibility": models.PrivacyPublic,
+			"expires_at": time.Now().Add(24 * time.Hour),
+		}),
+		h.SeedSoftDeleted("groups", bson.M{
+			"name":       softDeleteCheckText,
+			"created_by": owner.ID,
+			"privacy":    "public",
+		}),
+		h.SeedSoftDeleted("reports", bson.M{
+			"reporter_id": owner.ID,
+			"status":      models.ReportPending,
+		}),
+	}
+}
+
+// pathParam matches a parameter or wildcard segment of a route path
+var pathParam = regexp.MustCompile(`[:*][^/]+`)
+
+// AssertListEndpointsExcludeDeleted calls every GET route of router as user,
+// searching for the seeded text, and fails naming each route whose response
+// contains one of the deleted documents. Path parameters are filled with
+// each ID the seeded documents have or reference, so nested lists such as a
+// post's comments or a user's posts are read for the seeded owner and
+// parents. Routes in skip are left out; give the reason as the value so the
+// exemption stays reviewed.
+//
+// A new list endpoint that builds its filter without the repository helpers
+// shows up here as soon as it returns a deleted document.
+func (h *Harness) AssertListEndpointsExcludeDeleted(router *gin.Engine, user *models.User, pairs []SoftDeletedPair, skip map[string]string) {
+	h.T.Helper()
+
+	token := h.AccessToken(user)
+	values := pathValues(user, pairs)
+	for _, route := range router.Routes() {
+		if route.Method != http.MethodGet {
+			continue
+		}
+		if _, skipped := skip[route.Path]; skipped {
+			continue
+		}
+
+		for _, path := range expandPath(route.Path, values) {
+			req := httptest.NewRequest(http.MethodGet, path+"?q="+url.QueryEscape(softDeleteCheckText), nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			body := recorder.Body.String()
+			for _, pair := range pairs {
+				if strings.Contains(body, pair.Deleted.Hex()) {
+					h.T.Errorf("GET %s (%s) returned soft-deleted %s document %s; build its filter with the repository helpers",
+						route.Path, path, pair.Collection, pair.Deleted.Hex())
+				}
+			}
+		}
+	}
+}
+
+// pathValues returns the values tried for each path parameter. Parameters
+// naming a type, tag or username get values of that kind; every other one
+// is tried with the user's ID and the IDs of the live seeded documents and
+// of what they reference.
+func pathValues(user *models.User, pairs []SoftDeletedPair) map[string][]string {
+	seen := map[primitive.ObjectID]bool{user.ID: true}
+	ids := []string{user.ID.Hex()}
+	for _, pair := range pairs {
+		for _, id := range append([]primitive.ObjectID{pair.Live}, pair.Refs...) {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id.Hex())
+			}
+		}
+	}
+
+	text := url.PathEscape(softDeleteCheckText)
+	return map[string][]string{
+		"":           ids,
+		"targetType": {"post", "comment", "story"},
+		"type":       {"post", "comment", "story"},
+		"tag":        {text},
+		"name":       {text},
+		"username":   {user.Username},
+	}
+}
+
+// expandPath fills each parameter of path with every value for it, giving
+// one path per combination. A path without parameters is returned as is.
+func expandPath(path string, values map[string][]string) []string {
+	param := pathParam.FindString(path)
+	if param == "" {
+		return []string{path}
+	}
+
+	candidates, ok := values[param[1:]]
+	if !ok {
+		candidates = values[""]
+	}
+	var paths []string
+	for _, value := range candidates {
+		paths = append(paths, expandPath(strings.Replace(path, param, value, 1), values)...)
+	}
+	return paths
+}
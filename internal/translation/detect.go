@@ -0,0 +1,153 @@
+// internal/translation/detect.go
+package translation
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Detection thresholds. Text that doesn't clear them is left undetected
+// rather than guessed, since a wrong language hides a post from the feeds
+// of people who could read it.
+const (
+	minDetectLetters    = 12  // letters needed before any guess is made
+	minScriptShare      = 0.6 // share of letters a non-Latin script must hold
+	minStopwordHits     = 2   // stopword matches needed for Latin-script text
+	minStopwordMarginPc = 50  // lead in percent the best Latin language needs over the runner-up
+)
+
+var (
+	detectURLRegex    = regexp.MustCompile(`https?://\S+|www\.\S+`)
+	detectHandleRegex = regexp.MustCompile(`[@#][\p{L}\p{N}_]+`)
+)
+
+// scriptLanguages maps Unicode scripts that identify a single language to its code
+var scriptLanguages = []struct {
+	table *unicode.RangeTable
+	lang  string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Thai, "th"},
+	{unicode.Greek, "el"},
+	{unicode.Hebrew, "he"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Arabic, "ar"},
+	{unicode.Cyrillic, "ru"},
+}
+
+// stopwords holds very common function words for Latin-script languages
+var stopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "was", "of", "to", "in", "that", "it", "for", "with", "this", "you", "have", "not", "but", "be", "on", "my", "what", "just"},
+	"es": {"el", "la", "los", "las", "que", "de", "y", "en", "es", "por", "para", "con", "una", "pero", "como", "muy", "está", "del", "yo", "mi"},
+	"fr": {"le", "la", "les", "et", "est", "des", "une", "que", "pour", "dans", "pas", "avec", "sur", "je", "mais", "très", "du", "ce", "c'est", "vous"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ich", "mit", "ein", "eine", "auf", "für", "auch", "sich", "den", "dem", "sehr", "aber", "wir", "zu"},
+	"pt": {"o", "os", "as", "que", "de", "e", "não", "é", "em", "um", "uma", "para", "com", "por", "mas", "muito", "você", "meu", "isso", "do"},
+	"it": {"il", "lo", "gli", "che", "di", "e", "è", "non", "per", "una", "con", "sono", "ma", "molto", "questo", "della", "del", "mi", "anche", "io"},
+	"nl": {"de", "het", "een", "en", "is", "niet", "van", "ik", "dat", "op", "met", "voor", "zijn", "maar", "ook", "heel", "wat", "je", "er", "te"},
+	"id": {"yang", "dan", "di", "ini", "itu", "dengan", "untuk", "tidak", "ada", "saya", "akan", "dari", "ke", "juga", "sudah", "kita", "aku", "bisa", "sangat", "karena"},
+	"tr": {"ve", "bir", "bu", "da", "de", "için", "çok", "ne", "ama", "ile", "gibi", "daha", "ben", "sen", "mi", "var", "yok", "olan", "değil", "şu"},
+}
+
+var stopwordIndex = buildStopwordIndex()
+
+func buildStopwordIndex() map[string][]string {
+	index := make(map[string][]string)
+	for lang, words := range stopwords {
+		for _, word := range words {
+			index[word] = append(index[word], lang)
+		}
+	}
+	return index
+}
+
+// DetectLanguage returns the base language code of text, or an empty string
+// when the text is too short or ambiguous to tell
+func DetectLanguage(text string) string {
+	text = detectURLRegex.ReplaceAllString(text, " ")
+	text = detectHandleRegex.ReplaceAllString(text, " ")
+
+	var letters, latin, han, kana int
+	scriptCounts := make(map[string]int)
+
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		default:
+			for _, script := range scriptLanguages {
+				if unicode.Is(script.table, r) {
+					scriptCounts[script.lang]++
+					break
+				}
+			}
+		}
+	}
+
+	// CJK packs far more meaning per character, so it needs fewer of them
+	if kana+han >= minDetectLetters/3 && float64(kana+han) >= minScriptShare*float64(letters) {
+		if kana > 0 {
+			return "ja"
+		}
+		return "zh"
+	}
+
+	if letters < minDetectLetters {
+		return ""
+	}
+
+	for lang, count := range scriptCounts {
+		if float64(count) >= minScriptShare*float64(letters) {
+			return lang
+		}
+	}
+
+	if float64(latin) < minScriptShare*float64(letters) {
+		return ""
+	}
+
+	return detectLatinLanguage(text)
+}
+
+// detectLatinLanguage scores Latin-script text by stopword frequency
+func detectLatinLanguage(text string) string {
+	scores := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+
+	for _, word := range words {
+		for _, lang := range stopwordIndex[strings.Trim(word, "'")] {
+			scores[lang]++
+		}
+	}
+
+	best, bestScore, runnerUp := "", 0, 0
+	for lang, score := range scores {
+		switch {
+		case score > bestScore:
+			runnerUp = bestScore
+			best, bestScore = lang, score
+		case score > runnerUp:
+			runnerUp = score
+		}
+	}
+
+	if bestScore < minStopwordHits {
+		return ""
+	}
+	if bestScore*100 < runnerUp*(100+minStopwordMarginPc) {
+		return ""
+	}
+
+	return best
+}
@@ -0,0 +1,118 @@
+// internal/translation/libretranslate.go
+package translation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// LibreTranslateProvider implements Provider against a LibreTranslate server
+type LibreTranslateProvider struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+type libreTranslateRequest struct {
+	Q      string `json:"q"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Format string `json:"format"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+type libreTranslateResponse struct {
+	TranslatedText   string `json:"translatedText"`
+	DetectedLanguage *struct {
+		Language   string  `json:"language"`
+		Confidence float64 `json:"confidence"`
+	} `json:"detectedLanguage,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// NewLibreTranslateProvider creates a new LibreTranslate provider
+func NewLibreTranslateProvider(config Config) (*LibreTranslateProvider, error) {
+	if config.URL == "" {
+		return nil, errors.New("translation provider URL is required")
+	}
+
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	return &LibreTranslateProvider{
+		baseURL: strings.TrimRight(config.URL, "/"),
+		apiKey:  config.APIKey,
+		client:  &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Translate sends the text to the LibreTranslate /translate endpoint
+func (p *LibreTranslateProvider) Translate(ctx context.Context, text, sourceLang, targetLang string) (*Result, error) {
+	if sourceLang == "" {
+		sourceLang = "auto"
+	}
+
+	payload, err := json.Marshal(libreTranslateRequest{
+		Q:      text,
+		Source: sourceLang,
+		Target: targetLang,
+		Format: "text",
+		APIKey: p.apiKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/translate", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("translation request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body libreTranslateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode translation response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if body.Error != "" {
+			return nil, fmt.Errorf("translation provider error: %s", body.Error)
+		}
+		return nil, fmt.Errorf("translation provider returned status %d", resp.StatusCode)
+	}
+
+	detected := sourceLang
+	if body.DetectedLanguage != nil && body.DetectedLanguage.Language != "" {
+		detected = body.DetectedLanguage.Language
+	}
+
+	return &Result{
+		TranslatedText: body.TranslatedText,
+		SourceLanguage: NormalizeLanguage(detected),
+		TargetLanguage: targetLang,
+	}, nil
+}
+
+// Name returns the provider identifier
+func (p *LibreTranslateProvider) Name() string {
+	return ProviderLibreTranslate
+}
+
+// Attribution returns the attribution string
+func (p *LibreTranslateProvider) Attribution() string {
+	return "Translated by LibreTranslate"
+}
@@ -0,0 +1,36 @@
+// internal/translation/noop.go
+package translation
+
+import "context"
+
+// NoopProvider returns the original text unchanged. It is meant for
+// development environments where no translation backend is available.
+type NoopProvider struct{}
+
+// NewNoopProvider creates a new no-op translation provider
+func NewNoopProvider() *NoopProvider {
+	return &NoopProvider{}
+}
+
+// Translate echoes the text back, assuming the source language when unknown
+func (p *NoopProvider) Translate(ctx context.Context, text, sourceLang, targetLang string) (*Result, error) {
+	if sourceLang == "" {
+		sourceLang = "und"
+	}
+
+	return &Result{
+		TranslatedText: text,
+		SourceLanguage: sourceLang,
+		TargetLanguage: targetLang,
+	}, nil
+}
+
+// Name returns the provider identifier
+func (p *NoopProvider) Name() string {
+	return ProviderNoop
+}
+
+// Attribution returns the attribution string
+func (p *NoopProvider) Attribution() string {
+	return "Not translated (development provider)"
+}
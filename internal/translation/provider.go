@@ -0,0 +1,64 @@
+// internal/translation/provider.go
+package translation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Provider defines the interface for different machine translation backends
+type Provider interface {
+	// Translate translates text into targetLang. An empty sourceLang asks the
+	// provider to detect the source language.
+	Translate(ctx context.Context, text, sourceLang, targetLang string) (*Result, error)
+
+	// Name returns the provider identifier ("noop", "libretranslate", ...)
+	Name() string
+
+	// Attribution returns the attribution string to show next to translated content
+	Attribution() string
+}
+
+// Result contains the outcome of a translation
+type Result struct {
+	TranslatedText string `json:"translated_text"`
+	SourceLanguage string `json:"source_language"` // Detected or given source language
+	TargetLanguage string `json:"target_language"`
+}
+
+// Config contains configuration for translation providers
+type Config struct {
+	Provider string        `json:"provider"` // "noop", "libretranslate"
+	URL      string        `json:"url"`
+	APIKey   string        `json:"api_key"`
+	Timeout  time.Duration `json:"timeout"`
+}
+
+// Provider names
+const (
+	ProviderNoop           = "noop"
+	ProviderLibreTranslate = "libretranslate"
+)
+
+// NewProvider creates the provider selected by config
+func NewProvider(config Config) (Provider, error) {
+	switch strings.ToLower(config.Provider) {
+	case "", ProviderNoop:
+		return NewNoopProvider(), nil
+	case ProviderLibreTranslate:
+		return NewLibreTranslateProvider(config)
+	default:
+		return nil, fmt.Errorf("unsupported translation provider: %s", config.Provider)
+	}
+}
+
+// NormalizeLanguage reduces a language tag such as "en-US" to its base code
+func NormalizeLanguage(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		lang = lang[:i]
+	}
+	return lang
+}
@@ -0,0 +1,120 @@
+// utils/client_ip.go
+package utils
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientIPResolver finds the address a request really came from. Forwarding
+// headers are only believed when the connection comes from a trusted proxy,
+// so clients can't pick their own IP by sending X-Forwarded-For.
+type ClientIPResolver struct {
+	trusted []*net.IPNet
+}
+
+// NewClientIPResolver builds a resolver trusting the given proxies, each an IP
+// address or a CIDR range. With no proxies every forwarding header is ignored.
+func NewClientIPResolver(trustedProxies []string) (*ClientIPResolver, error) {
+	resolver := &ClientIPResolver{}
+	for _, proxy := range trustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			resolver.trusted = append(resolver.trusted, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+		}
+		resolver.trusted = append(resolver.trusted, network)
+	}
+	return resolver, nil
+}
+
+// Resolve returns the client IP of the request. X-Forwarded-For is read from
+// the right, skipping trusted proxies, and the first hop that isn't one is the
+// client; everything to its left was written by the client and can't be
+// believed. X-Real-IP is used when a trusted proxy sent no X-Forwarded-For.
+func (r *ClientIPResolver) Resolve(req *http.Request) string {
+	remote := remoteIP(req.RemoteAddr)
+	if remote == nil {
+		return strings.TrimSpace(req.RemoteAddr)
+	}
+	if !r.isTrusted(remote) {
+		return remote.String()
+	}
+
+	var hops []string
+	for _, header := range req.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	if len(hops) > 0 {
+		client := remote
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				// A malformed hop means the chain can't be followed any further
+				break
+			}
+			client = ip
+			if !r.isTrusted(ip) {
+				break
+			}
+		}
+		return client.String()
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(req.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	return remote.String()
+}
+
+func (r *ClientIPResolver) isTrusted(ip net.IP) bool {
+	for _, network := range r.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP parses the IP of a connection's remote address
+func remoteIP(remoteAddr string) net.IP {
+	host, _, err := net.SplitHostPort(strings.TrimSpace(remoteAddr))
+	if err != nil {
+		host = strings.TrimSpace(remoteAddr)
+	}
+	return net.ParseIP(host)
+}
+
+// ClientIP returns the real client IP stored by the client IP middleware.
+// Without it, the connection's own address is used and forwarding headers are
+// ignored.
+func ClientIP(c *gin.Context) string {
+	if ip := c.GetString(ContextClientIP); ip != "" {
+		return ip
+	}
+	if remote := remoteIP(c.Request.RemoteAddr); remote != nil {
+		return remote.String()
+	}
+	return c.Request.RemoteAddr
+}
@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIPResolverResolve(t *testing.T) {
+	resolver, err := NewClientIPResolver([]string{"10.0.0.0/8", "192.168.1.5", "fd00::/8"})
+	if err != nil {
+		t.Fatalf("NewClientIPResolver: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		realIP     string
+		want       string
+	}{
+		{
+			name:       "untrusted peer with a spoofed X-Forwarded-For",
+			remoteAddr: "203.0.113.7:51000",
+			forwarded:  []string{"1.2.3.4"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "untrusted peer with a spoofed X-Real-IP",
+			remoteAddr: "203.0.113.7:51000",
+			realIP:     "1.2.3.4",
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "trusted proxy forwarding a client",
+			remoteAddr: "10.0.0.1:443",
+			forwarded:  []string{"198.51.100.20"},
+			want:       "198.51.100.20",
+		},
+		{
+			name:       "multi-hop chain with trusted proxies on the right",
+			remoteAddr: "10.0.0.1:443",
+			forwarded:  []string{"1.2.3.4, 198.51.100.20, 192.168.1.5, 10.1.2.3"},
+			want:       "198.51.100.20",
+		},
+		{
+			name:       "chain split across headers",
+			remoteAddr: "10.0.0.1:443",
+			forwarded:  []string{"1.2.3.4, 198.51.100.20", "10.1.2.3"},
+			want:       "198.51.100.20",
+		},
+		{
+			name:       "all-trusted chain resolves to the leftmost hop",
+			remoteAddr: "10.0.0.1:443",
+			forwarded:  []string{"10.9.9.9, 192.168.1.5, 10.1.2.3"},
+			want:       "10.9.9.9",
+		},
+		{
+			name:       "malformed hop stops the walk at the last good hop",
+			remoteAddr: "10.0.0.1:443",
+			forwarded:  []string{"198.51.100.20, not-an-ip, 10.1.2.3"},
+			want:       "10.1.2.3",
+		},
+		{
+			name:       "malformed only hop leaves the peer",
+			remoteAddr: "10.0.0.1:443",
+			forwarded:  []string{"unknown"},
+			want:       "10.0.0.1",
+		},
+		{
+			name:       "IPv6 client behind a trusted IPv6 proxy",
+			remoteAddr: "[fd00::1]:443",
+			forwarded:  []string{"2001:db8::42, fd00::2"},
+			want:       "2001:db8::42",
+		},
+		{
+			name:       "untrusted IPv6 peer",
+			remoteAddr: "[2001:db8::9]:443",
+			forwarded:  []string{"1.2.3.4"},
+			want:       "2001:db8::9",
+		},
+		{
+			name:       "X-Real-IP fallback from a trusted proxy",
+			remoteAddr: "10.0.0.1:443",
+			realIP:     "198.51.100.20",
+			want:       "198.51.100.20",
+		},
+		{
+			name:       "malformed X-Real-IP falls back to the peer",
+			remoteAddr: "10.0.0.1:443",
+			realIP:     "198.51.100",
+			want:       "10.0.0.1",
+		},
+		{
+			name:       "X-Forwarded-For wins over X-Real-IP",
+			remoteAddr: "10.0.0.1:443",
+			forwarded:  []string{"198.51.100.20"},
+			realIP:     "1.2.3.4",
+			want:       "198.51.100.20",
+		},
+		{
+			name:       "remote address without a port",
+			remoteAddr: "203.0.113.7",
+			want:       "203.0.113.7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			if got := resolver.Resolve(req); got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientIPResolverWithoutProxiesIgnoresHeaders(t *testing.T) {
+	resolver, err := NewClientIPResolver(nil)
+	if err != nil {
+		t.Fatalf("NewClientIPResolver: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "127.0.0.1:8080"
+	req.Header.Set("X-Forwarded-For", "1.2.3.4")
+	req.Header.Set("X-Real-IP", "5.6.7.8")
+
+	if got := resolver.Resolve(req); got != "127.0.0.1" {
+		t.Errorf("Resolve() = %q, want %q", got, "127.0.0.1")
+	}
+}
+
+func TestNewClientIPResolverRejectsInvalidProxies(t *testing.T) {
+	for _, proxy := range []string{"10.0.0", "10.0.0.0/33", "proxy.internal"} {
+		if _, err := NewClientIPResolver([]string{proxy}); err == nil {
+			t.Errorf("NewClientIPResolver(%q) succeeded, want an error", proxy)
+		}
+	}
+	if _, err := NewClientIPResolver([]string{" 10.0.0.1 ", "", "::1"}); err != nil {
+		t.Errorf("NewClientIPResolver with padded and IPv6 entries: %v", err)
+	}
+}
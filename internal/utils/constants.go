@@ -11,11 +11,12 @@ const (
 	APIVersion = "v1"
 
 	// Default pagination
-	DefaultPageSize               = 20
-	MaxPageSize                   = 100
 	MinPageSize                   = 1
 	MaxBulkNotificationRecipients = 10
 	MaxMessageContentLength       = 5000
+	MaxForwardTargets             = 10
+	MaxMessagesAround             = 50   // Per side of a jump-to-message window
+	MaxActivityLogDepth           = 1000 // Newest entries reachable by paging the activity log
 	MaxPostContentLength          = 5000
 	MaxCommentContentLength       = 2000
 	MaxStoryContentLength         = 2000
@@ -178,6 +179,7 @@ const (
 	ContextUser      = "user"
 	ContextUserRole  = "user_role"
 	ContextSessionID = "session_id"
+	ContextClientIP  = "client_ip"
 )
 
 // Default Values
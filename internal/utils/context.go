@@ -0,0 +1,91 @@
+// utils/context.go
+package utils
+
+import (
+	"social-media-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SetCurrentUser stores the signed-in user in the request context. The auth
+// middleware calls it once per request; everything else reads the user back
+// with the helpers below.
+func SetCurrentUser(c *gin.Context, user *models.User) {
+	c.Set(ContextUserID, user.ID)
+	c.Set(ContextUser, user)
+	c.Set(ContextUserRole, user.Role)
+}
+
+// CurrentUser returns the signed-in user. When there is none it answers 401
+// and returns false, so handlers only have to return.
+func CurrentUser(c *gin.Context) (*models.User, bool) {
+	user, ok := LookupUser(c)
+	if !ok {
+		UnauthorizedResponse(c, "User not authenticated")
+	}
+	return user, ok
+}
+
+// CurrentUserID returns the signed-in user's ID. When there is none it
+// answers 401 and returns false, so handlers only have to return.
+func CurrentUserID(c *gin.Context) (primitive.ObjectID, bool) {
+	userID, ok := LookupUserID(c)
+	if !ok {
+		UnauthorizedResponse(c, "User not authenticated")
+	}
+	return userID, ok
+}
+
+// CurrentRole returns the signed-in user's role. When there is none it
+// answers 401 and returns false, so handlers only have to return.
+func CurrentRole(c *gin.Context) (models.UserRole, bool) {
+	role, ok := LookupRole(c)
+	if !ok {
+		UnauthorizedResponse(c, "User not authenticated")
+	}
+	return role, ok
+}
+
+// OptionalUserID returns the signed-in user's ID, or nil for anonymous
+// requests on routes where signing in is optional
+func OptionalUserID(c *gin.Context) *primitive.ObjectID {
+	userID, ok := LookupUserID(c)
+	if !ok {
+		return nil
+	}
+	return &userID
+}
+
+// LookupUser returns the signed-in user without responding, for middleware
+// and code that handles anonymous requests itself
+func LookupUser(c *gin.Context) (*models.User, bool) {
+	value, exists := c.Get(ContextUser)
+	if !exists {
+		return nil, false
+	}
+	user, ok := value.(*models.User)
+	return user, ok && user != nil
+}
+
+// LookupUserID returns the signed-in user's ID without responding, for
+// middleware and code that handles anonymous requests itself
+func LookupUserID(c *gin.Context) (primitive.ObjectID, bool) {
+	value, exists := c.Get(ContextUserID)
+	if !exists {
+		return primitive.NilObjectID, false
+	}
+	userID, ok := value.(primitive.ObjectID)
+	return userID, ok && !userID.IsZero()
+}
+
+// LookupRole returns the signed-in user's role without responding, for
+// middleware and code that handles anonymous requests itself
+func LookupRole(c *gin.Context) (models.UserRole, bool) {
+	value, exists := c.Get(ContextUserRole)
+	if !exists {
+		return "", false
+	}
+	role, ok := value.(models.UserRole)
+	return role, ok && role != ""
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"social-media-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLookupRole(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  interface{}
+		set    bool
+		want   models.UserRole
+		wantOK bool
+	}{
+		{"not signed in", nil, false, "", false},
+		{"moderator", models.RoleModerator, true, models.RoleModerator, true},
+		{"empty role", models.UserRole(""), true, "", false},
+		{"plain string", "admin", true, "", false},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			if tt.set {
+				c.Set(ContextUserRole, tt.value)
+			}
+
+			role, ok := LookupRole(c)
+			if role != tt.want || ok != tt.wantOK {
+				t.Errorf("LookupRole() = %q, %v, want %q, %v", role, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCurrentRoleRespondsUnauthorized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+
+	if _, ok := CurrentRole(c); ok {
+		t.Fatal("CurrentRole() ok without a signed-in user")
+	}
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,128 @@
+package utils
+
+import "unicode"
+
+// Emoji parsing for quick replies. Emoji are split into the sequences users
+// see as a single glyph: a base pictograph with its variation selector, skin
+// tone and tag modifiers, zero-width-joiner sequences ("👩‍💻"), keycaps ("1️⃣")
+// and regional indicator flags ("🇯🇵").
+
+const (
+	zeroWidthJoiner   = '\u200d'
+	variationSelector = '\ufe0f'
+	keycapCombiner    = '\u20e3'
+)
+
+// SplitEmoji splits text into its emoji, ignoring whitespace between them. It
+// reports false if the text contains anything other than emoji.
+func SplitEmoji(text string) ([]string, bool) {
+	runes := []rune(text)
+	var emoji []string
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		end := emojiSequenceEnd(runes, i)
+		if end == i {
+			return nil, false
+		}
+		emoji = append(emoji, string(runes[i:end]))
+		i = end
+	}
+
+	return emoji, true
+}
+
+// IsEmojiOnly checks if text consists of between 1 and max emoji
+func IsEmojiOnly(text string, max int) bool {
+	emoji, ok := SplitEmoji(text)
+	return ok && len(emoji) > 0 && len(emoji) <= max
+}
+
+// emojiSequenceEnd returns the index just past the emoji sequence starting at
+// start, or start if no emoji starts there
+func emojiSequenceEnd(runes []rune, start int) int {
+	r := runes[start]
+
+	// Keycaps: a digit, # or * followed by an optional variation selector and
+	// the combining keycap
+	if (r >= '0' && r <= '9') || r == '#' || r == '*' {
+		i := start + 1
+		if i < len(runes) && runes[i] == variationSelector {
+			i++
+		}
+		if i < len(runes) && runes[i] == keycapCombiner {
+			return i + 1
+		}
+		return start
+	}
+
+	// Flags are pairs of regional indicators
+	if isRegionalIndicator(r) {
+		if start+1 < len(runes) && isRegionalIndicator(runes[start+1]) {
+			return start + 2
+		}
+		return start
+	}
+
+	if !isEmojiBase(r) {
+		return start
+	}
+
+	i := start + 1
+	for i < len(runes) {
+		switch next := runes[i]; {
+		case next == variationSelector, next == keycapCombiner, isSkinToneModifier(next), isTagCharacter(next):
+			i++
+		case next == zeroWidthJoiner && i+1 < len(runes) && isEmojiBase(runes[i+1]):
+			i += 2
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// isEmojiBase reports whether r can start an emoji presentation sequence
+func isEmojiBase(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1F0FF: // Mahjong and playing cards
+		return true
+	case r >= 0x1F100 && r <= 0x1F1E5: // Enclosed alphanumerics (before regional indicators)
+		return true
+	case r >= 0x1F200 && r <= 0x1FAFF: // Pictographs, emoticons, transport, supplemental symbols
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // Miscellaneous symbols and dingbats
+		return true
+	case r >= 0x2300 && r <= 0x23FF: // Miscellaneous technical (⌚, ⏰, ⏩)
+		return true
+	case r >= 0x2190 && r <= 0x21FF: // Arrows
+		return true
+	case r >= 0x25A0 && r <= 0x25FF: // Geometric shapes
+		return true
+	case r >= 0x2B00 && r <= 0x2BFF: // Miscellaneous symbols and arrows (⭐, ⭕)
+		return true
+	}
+
+	switch r {
+	case 0x00A9, 0x00AE, 0x203C, 0x2049, 0x2122, 0x2139, 0x24C2, 0x2934, 0x2935, 0x3030, 0x303D, 0x3297, 0x3299:
+		return true
+	}
+	return false
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+func isSkinToneModifier(r rune) bool {
+	return r >= 0x1F3FB && r <= 0x1F3FF
+}
+
+// isTagCharacter covers the tag sequences used by subdivision flags (🏴󠁧󠁢󠁳󠁣󠁴󠁿)
+func isTagCharacter(r rune) bool {
+	return r >= 0xE0020 && r <= 0xE007F
+}
@@ -0,0 +1,175 @@
+// utils/entities.go
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Mentions and hashtags are parsed in one pass over the text. A marker (@ or
+// #) only starts an entity at the start of the text or after whitespace or
+// punctuation, so tags inside words ("foo#bar"), URL fragments ("/#top") and
+// email addresses ("name@example.com") don't parse. A backslash escapes a
+// marker ("\@name"). Entities end at the first character that isn't a
+// letter, digit, mark or underscore, which leaves trailing punctuation out.
+// Tokens longer than MaxUsernameLength or MaxHashtagLength are ignored
+// rather than truncated.
+
+// hashtagStopWords are words that aren't treated as hashtags, set from
+// configuration at startup by ConfigureHashtagStopWords
+var hashtagStopWords = stopWordSet([]string{
+	"a", "an", "and", "are", "as", "at", "be", "but", "by", "for", "if", "in",
+	"is", "it", "of", "on", "or", "so", "the", "to", "was", "we", "with",
+})
+
+// ConfigureHashtagStopWords replaces the words that aren't parsed as hashtags
+func ConfigureHashtagStopWords(words []string) {
+	hashtagStopWords = stopWordSet(words)
+}
+
+func stopWordSet(words []string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[strings.ToLower(strings.TrimSpace(word))] = true
+	}
+	return set
+}
+
+// MentionEntity is an @-mention in text. Start and End are character (rune)
+// offsets of the whole mention including the @, End exclusive.
+type MentionEntity struct {
+	Username string `json:"username"`
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+}
+
+// HashtagEntity is a hashtag in text, with offsets like MentionEntity
+type HashtagEntity struct {
+	Tag   string `json:"tag"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// Entities are the mentions and hashtags found in text, in order of
+// appearance. Repeated entities are listed at each position.
+type Entities struct {
+	Mentions []MentionEntity `json:"mentions"`
+	Hashtags []HashtagEntity `json:"hashtags"`
+}
+
+// ParseEntities finds the mentions and hashtags in content
+func ParseEntities(content string) Entities {
+	var entities Entities
+	runes := []rune(content)
+
+	for i := 0; i < len(runes); i++ {
+		marker := runes[i]
+		if marker != '@' && marker != '#' {
+			continue
+		}
+		if !isEntityBoundary(runes, i) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isEntityRune(runes[end]) {
+			end++
+		}
+		if end == i+1 {
+			continue
+		}
+
+		// The token runs on into another marker, as in an email address
+		// ("@john.doe@example.com") or chained tags ("#one#two")
+		if next := entityRunOn(runes, end); next < len(runes) && (runes[next] == '@' || runes[next] == '#') {
+			i = next - 1
+			continue
+		}
+
+		text := string(runes[i+1 : end])
+		length := end - i - 1
+		if marker == '@' {
+			if length <= MaxUsernameLength && unicode.IsLetter(runes[i+1]) {
+				entities.Mentions = append(entities.Mentions, MentionEntity{Username: text, Start: i, End: end})
+			}
+		} else if length <= MaxHashtagLength && containsLetter(runes[i+1:end]) && !hashtagStopWords[strings.ToLower(text)] {
+			entities.Hashtags = append(entities.Hashtags, HashtagEntity{Tag: text, Start: i, End: end})
+		}
+		i = end - 1
+	}
+
+	return entities
+}
+
+// Usernames returns the mentioned usernames, each once. Repeats are matched
+// case-insensitively and the first spelling is kept.
+func (e Entities) Usernames() []string {
+	var usernames []string
+	seen := make(map[string]bool)
+	for _, mention := range e.Mentions {
+		key := strings.ToLower(mention.Username)
+		if !seen[key] {
+			seen[key] = true
+			usernames = append(usernames, mention.Username)
+		}
+	}
+	return usernames
+}
+
+// Tags returns the hashtags, each once, matched like Usernames
+func (e Entities) Tags() []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, hashtag := range e.Hashtags {
+		key := strings.ToLower(hashtag.Tag)
+		if !seen[key] {
+			seen[key] = true
+			tags = append(tags, hashtag.Tag)
+		}
+	}
+	return tags
+}
+
+// isEntityRune reports whether r can be part of a username or hashtag.
+// Marks are included so tags in scripts with combining vowels stay whole.
+func isEntityRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '_'
+}
+
+// isEntityBoundary reports whether the marker at i can start an entity
+func isEntityBoundary(runes []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+
+	prev := runes[i-1]
+	if unicode.IsSpace(prev) {
+		return true
+	}
+	if isEntityRune(prev) {
+		return false
+	}
+	switch prev {
+	case '\\', '/', '@', '#', '&', '=', '.', '-', '+':
+		return false
+	}
+	return unicode.IsPunct(prev) || unicode.IsSymbol(prev)
+}
+
+// entityRunOn skips the dots, dashes and plus signs that join the token
+// ending at end to more word characters, returning where the run stops
+func entityRunOn(runes []rune, end int) int {
+	for end < len(runes) && (isEntityRune(runes[end]) || runes[end] == '.' || runes[end] == '-' || runes[end] == '+') {
+		end++
+	}
+	return end
+}
+
+func containsLetter(runes []rune) bool {
+	for _, r := range runes {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
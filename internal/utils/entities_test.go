@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseEntities(t *testing.T) {
+	longUsername := strings.Repeat("a", MaxUsernameLength)
+	longHashtag := strings.Repeat("t", MaxHashtagLength)
+
+	tests := []struct {
+		name     string
+		content  string
+		mentions []MentionEntity
+		hashtags []HashtagEntity
+	}{
+		// Empty and malformed input
+		{name: "empty", content: ""},
+		{name: "whitespace only", content: " \n\t "},
+		{name: "bare markers", content: "@ # @\n#"},
+		{name: "marker at the end", content: "hello @"},
+		{name: "doubled mention marker", content: "@@alice"},
+		{name: "doubled hashtag marker", content: "##golang"},
+		{name: "mention starting with a digit", content: "@123abc"},
+		{name: "hashtag of digits only", content: "#2024"},
+		{name: "hashtag of underscores only", content: "#___"},
+		{name: "marker followed by punctuation", content: "@! #?"},
+		{name: "username too long", content: "@" + longUsername + "a"},
+		{name: "hashtag too long", content: "#" + longHashtag + "t"},
+
+		// Boundaries
+		{
+			name:     "mention with trailing punctuation",
+			content:  "hello @alice!",
+			mentions: []MentionEntity{{Username: "alice", Start: 6, End: 12}},
+		},
+		{
+			name:     "hashtag in parentheses",
+			content:  "(#golang),",
+			hashtags: []HashtagEntity{{Tag: "golang", Start: 1, End: 8}},
+		},
+		{
+			name:     "entities at the start and after a newline",
+			content:  "@bob\n#news",
+			mentions: []MentionEntity{{Username: "bob", Start: 0, End: 4}},
+			hashtags: []HashtagEntity{{Tag: "news", Start: 5, End: 10}},
+		},
+		{
+			name:     "underscores and digits",
+			content:  "@alice_b2. #go_lang2",
+			mentions: []MentionEntity{{Username: "alice_b2", Start: 0, End: 9}},
+			hashtags: []HashtagEntity{{Tag: "go_lang2", Start: 11, End: 20}},
+		},
+		{
+			name:     "hashtag starting with digits",
+			content:  "#2024goals",
+			hashtags: []HashtagEntity{{Tag: "2024goals", Start: 0, End: 10}},
+		},
+		{name: "hashtag inside a word", content: "foo#bar"},
+		{name: "mention inside a word", content: "foo@bar"},
+		{name: "chained hashtags", content: "#one#two"},
+		{name: "URL fragment", content: "https://example.com/#top"},
+		{name: "HTML entity", content: "it&#39;s"},
+		{name: "query string", content: "?a=1&b=#x"},
+
+		// Escapes and email addresses
+		{name: "escaped markers", content: `\@alice \#golang`},
+		{name: "email address", content: "mail john.doe@example.com"},
+		{name: "email address starting with a marker", content: "@john.doe@example.com"},
+		{name: "plus-addressed email", content: "@jane+news@example.com"},
+		{
+			name:     "mention next to an email address",
+			content:  "@carol: a@b.co",
+			mentions: []MentionEntity{{Username: "carol", Start: 0, End: 6}},
+		},
+
+		// Lengths at the limit
+		{
+			name:     "username at the length limit",
+			content:  "@" + longUsername,
+			mentions: []MentionEntity{{Username: longUsername, Start: 0, End: MaxUsernameLength + 1}},
+		},
+		{
+			name:     "hashtag at the length limit",
+			content:  "#" + longHashtag,
+			hashtags: []HashtagEntity{{Tag: longHashtag, Start: 0, End: MaxHashtagLength + 1}},
+		},
+
+		// Stop words
+		{name: "stop word", content: "#the"},
+		{name: "stop word in another case", content: "#The #AND"},
+		{
+			name:     "stop word prefix",
+			content:  "#them",
+			hashtags: []HashtagEntity{{Tag: "them", Start: 0, End: 5}},
+		},
+
+		// Unicode
+		{
+			name:     "Cyrillic",
+			content:  "Привет @наташа #москва",
+			mentions: []MentionEntity{{Username: "наташа", Start: 7, End: 14}},
+			hashtags: []HashtagEntity{{Tag: "москва", Start: 15, End: 22}},
+		},
+		{
+			name:     "CJK hashtag",
+			content:  "東京 #日本語",
+			hashtags: []HashtagEntity{{Tag: "日本語", Start: 3, End: 7}},
+		},
+		{
+			name:     "combining marks stay in the tag",
+			content:  "#नमस्ते",
+			hashtags: []HashtagEntity{{Tag: "नमस्ते", Start: 0, End: 7}},
+		},
+		{
+			name:     "accented letters",
+			content:  "café @zoë",
+			mentions: []MentionEntity{{Username: "zoë", Start: 5, End: 9}},
+		},
+		{
+			name:     "offsets count runes, not bytes",
+			content:  "🎉🎉 @bob",
+			mentions: []MentionEntity{{Username: "bob", Start: 3, End: 7}},
+		},
+		{
+			name:     "emoji before and after a hashtag",
+			content:  "🎉#party🎉",
+			hashtags: []HashtagEntity{{Tag: "party", Start: 1, End: 7}},
+		},
+		{
+			name:     "full-width punctuation",
+			content:  "「#東京」",
+			hashtags: []HashtagEntity{{Tag: "東京", Start: 1, End: 4}},
+		},
+		{name: "hashtag inside a Cyrillic word", content: "слово#тег"},
+
+		// Repeats are listed at each position
+		{
+			name:    "repeated entities",
+			content: "@Bob #Go @bob #go",
+			mentions: []MentionEntity{
+				{Username: "Bob", Start: 0, End: 4},
+				{Username: "bob", Start: 9, End: 13},
+			},
+			hashtags: []HashtagEntity{
+				{Tag: "Go", Start: 5, End: 8},
+				{Tag: "go", Start: 14, End: 17},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseEntities(tt.content)
+			if !reflect.DeepEqual(got.Mentions, tt.mentions) {
+				t.Errorf("mentions = %+v, want %+v", got.Mentions, tt.mentions)
+			}
+			if !reflect.DeepEqual(got.Hashtags, tt.hashtags) {
+				t.Errorf("hashtags = %+v, want %+v", got.Hashtags, tt.hashtags)
+			}
+		})
+	}
+}
+
+func TestEntitiesDeduplicate(t *testing.T) {
+	entities := ParseEntities("@Bob #Go @bob @carol #go #GO")
+
+	if got, want := entities.Usernames(), []string{"Bob", "carol"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Usernames = %v, want %v", got, want)
+	}
+	if got, want := entities.Tags(), []string{"Go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Tags = %v, want %v", got, want)
+	}
+
+	if got := ParseEntities("").Usernames(); got != nil {
+		t.Errorf("Usernames of empty text = %v, want none", got)
+	}
+}
+
+func TestConfigureHashtagStopWords(t *testing.T) {
+	defaults := hashtagStopWords
+	t.Cleanup(func() { hashtagStopWords = defaults })
+
+	ConfigureHashtagStopWords([]string{" Spam ", "news"})
+
+	got := ParseEntities("#spam #SPAM #news #the").Tags()
+	if want := []string{"the"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Tags with configured stop words = %v, want %v", got, want)
+	}
+}
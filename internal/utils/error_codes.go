@@ -0,0 +1,105 @@
+// utils/error_codes.go
+package utils
+
+import "net/http"
+
+// ErrorCode is a stable, machine-readable identifier returned with every
+// error response. Clients should branch on the code rather than the
+// human-readable message, which may change.
+//
+// Codes:
+//
+//	BAD_REQUEST                 malformed request or invalid parameters
+//	INVALID_JSON                request body is not valid JSON
+//	VALIDATION_FAILED           request body failed field validation (see error.details)
+//	AUTH_REQUIRED               no credentials were supplied
+//	AUTH_INVALID_TOKEN          token is malformed, expired or of the wrong type
+//	AUTH_USER_NOT_FOUND         token is valid but its user no longer exists
+//	ACCOUNT_SUSPENDED           account is suspended or inactive
+//	ACCOUNT_DEACTIVATED         the user deactivated the account; log in with reactivate set to restore it
+//	CREDENTIALS_REQUIRED        the account has no session on this device; retry the switch with its password
+//	EMAIL_NOT_VERIFIED          the action requires a verified email address
+//	ACCOUNT_TOO_NEW             the account is too new or unverified for the action (see error.details for the requirement)
+//	FORBIDDEN                   authenticated but not allowed to perform the action
+//	INSUFFICIENT_PERMISSIONS    the user's role does not grant the action
+//	NOT_FOUND                   the requested resource does not exist
+//	ROUTE_NOT_FOUND             no route matches the request path
+//	METHOD_NOT_ALLOWED          the route exists but not for this HTTP method
+//	CONFLICT                    the resource already exists or is in a conflicting state
+//	REQUEST_TIMEOUT             the operation did not complete in time
+//	QUERY_TIMEOUT               an admin or analytics query hit its time limit; narrow the range or use an export
+//	RATE_LIMITED                too many requests, retry later
+//	LIMIT_EXCEEDED              the user's tier limit was hit (see error.details for the limit, tier and ceiling)
+//	CHALLENGE_REQUIRED          solve the proof-of-work challenge in error.details and retry with the solution
+//	BLOCKED_LINK                the content links to a blocklisted domain
+//	MEDIA_REJECTED              a message attachment's type or size isn't allowed (see error.details)
+//	CONFIRMATION_REQUIRED       the change has consequences the user must confirm; resend it confirmed (see error.details)
+//	INTERNAL_ERROR              unexpected server error
+//	NOT_IMPLEMENTED             the endpoint is not implemented yet
+//	SERVICE_UNAVAILABLE         a dependency is temporarily unavailable
+type ErrorCode string
+
+const (
+	ErrorCodeBadRequest              ErrorCode = "BAD_REQUEST"
+	ErrorCodeInvalidJSON             ErrorCode = "INVALID_JSON"
+	ErrorCodeValidationFailed        ErrorCode = "VALIDATION_FAILED"
+	ErrorCodeAuthRequired            ErrorCode = "AUTH_REQUIRED"
+	ErrorCodeAuthInvalidToken        ErrorCode = "AUTH_INVALID_TOKEN"
+	ErrorCodeAuthUserNotFound        ErrorCode = "AUTH_USER_NOT_FOUND"
+	ErrorCodeAccountSuspended        ErrorCode = "ACCOUNT_SUSPENDED"
+	ErrorCodeAccountDeactivated      ErrorCode = "ACCOUNT_DEACTIVATED"
+	ErrorCodeCredentialsRequired     ErrorCode = "CREDENTIALS_REQUIRED"
+	ErrorCodeEmailNotVerified        ErrorCode = "EMAIL_NOT_VERIFIED"
+	ErrorCodeAccountTooNew           ErrorCode = "ACCOUNT_TOO_NEW"
+	ErrorCodeForbidden               ErrorCode = "FORBIDDEN"
+	ErrorCodeInsufficientPermissions ErrorCode = "INSUFFICIENT_PERMISSIONS"
+	ErrorCodeNotFound                ErrorCode = "NOT_FOUND"
+	ErrorCodeRouteNotFound           ErrorCode = "ROUTE_NOT_FOUND"
+	ErrorCodeMethodNotAllowed        ErrorCode = "METHOD_NOT_ALLOWED"
+	ErrorCodeConflict                ErrorCode = "CONFLICT"
+	ErrorCodeRequestTimeout          ErrorCode = "REQUEST_TIMEOUT"
+	ErrorCodeQueryTimeout            ErrorCode = "QUERY_TIMEOUT"
+	ErrorCodeRateLimited             ErrorCode = "RATE_LIMITED"
+	ErrorCodeLimitExceeded           ErrorCode = "LIMIT_EXCEEDED"
+	ErrorCodeChallengeRequired       ErrorCode = "CHALLENGE_REQUIRED"
+	ErrorCodeBlockedLink             ErrorCode = "BLOCKED_LINK"
+	ErrorCodeMediaRejected           ErrorCode = "MEDIA_REJECTED"
+	ErrorCodeConfirmationRequired    ErrorCode = "CONFIRMATION_REQUIRED"
+	ErrorCodeInternal                ErrorCode = "INTERNAL_ERROR"
+	ErrorCodeNotImplemented          ErrorCode = "NOT_IMPLEMENTED"
+	ErrorCodeServiceUnavailable      ErrorCode = "SERVICE_UNAVAILABLE"
+)
+
+// DefaultErrorCode returns the error code used when a response is sent
+// without an explicit one
+func DefaultErrorCode(statusCode int) ErrorCode {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return ErrorCodeBadRequest
+	case http.StatusUnauthorized:
+		return ErrorCodeAuthRequired
+	case http.StatusForbidden:
+		return ErrorCodeForbidden
+	case http.StatusNotFound:
+		return ErrorCodeNotFound
+	case http.StatusMethodNotAllowed:
+		return ErrorCodeMethodNotAllowed
+	case http.StatusRequestTimeout:
+		return ErrorCodeRequestTimeout
+	case http.StatusConflict:
+		return ErrorCodeConflict
+	case http.StatusPreconditionRequired:
+		return ErrorCodeChallengeRequired
+	case http.StatusTooManyRequests:
+		return ErrorCodeRateLimited
+	case http.StatusNotImplemented:
+		return ErrorCodeNotImplemented
+	case http.StatusServiceUnavailable:
+		return ErrorCodeServiceUnavailable
+	}
+
+	if statusCode >= 500 {
+		return ErrorCodeInternal
+	}
+	return ErrorCodeBadRequest
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WeakETag builds a weak entity tag from the values that determine a response,
+// typically a document's updated_at, its counters and the viewer ID when the
+// response is personalized
+func WeakETag(parts ...interface{}) string {
+	h := sha1.New()
+	for _, part := range parts {
+		switch v := part.(type) {
+		case time.Time:
+			fmt.Fprintf(h, "%d|", v.UnixNano())
+		case *time.Time:
+			if v != nil {
+				fmt.Fprintf(h, "%d", v.UnixNano())
+			}
+			h.Write([]byte("|"))
+		default:
+			fmt.Fprintf(h, "%v|", v)
+		}
+	}
+	return `W/"` + hex.EncodeToString(h.Sum(nil))[:20] + `"`
+}
+
+// CheckETag sets the ETag header and replies 304 Not Modified when the
+// request's If-None-Match matches it. Handlers call it before building the
+// response body and return straight away when it reports true.
+func CheckETag(c *gin.Context, etag string) bool {
+	setRevalidate(c)
+	c.Header("ETag", etag)
+
+	match := c.GetHeader("If-None-Match")
+	if match == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(match, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || weakCompare(candidate, etag) {
+			c.Status(http.StatusNotModified)
+			c.Writer.WriteHeaderNow()
+			return true
+		}
+	}
+	return false
+}
+
+// CheckModifiedSince sets the Last-Modified header and replies 304 Not
+// Modified when nothing changed after the request's If-Modified-Since. It is
+// ignored when the request also carries If-None-Match, which takes precedence.
+func CheckModifiedSince(c *gin.Context, lastModified time.Time) bool {
+	if lastModified.IsZero() {
+		return false
+	}
+
+	setRevalidate(c)
+	lastModified = lastModified.UTC().Truncate(time.Second)
+	c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+
+	if c.GetHeader("If-None-Match") != "" {
+		return false
+	}
+
+	since, err := http.ParseTime(c.GetHeader("If-Modified-Since"))
+	if err != nil || lastModified.After(since) {
+		return false
+	}
+
+	c.Status(http.StatusNotModified)
+	c.Writer.WriteHeaderNow()
+	return true
+}
+
+// setRevalidate marks the response as private to the caller and forces
+// clients to revalidate before reusing it
+func setRevalidate(c *gin.Context) {
+	c.Header("Cache-Control", "private, no-cache")
+	c.Header("Vary", "Authorization")
+}
+
+// weakCompare compares two entity tags ignoring the weak indicator
+func weakCompare(a, b string) bool {
+	return strings.TrimPrefix(a, "W/") == strings.TrimPrefix(b, "W/")
+}
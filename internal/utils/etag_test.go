@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestWeakETag(t *testing.T) {
+	updatedAt := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	etag := WeakETag(updatedAt, 10, "viewer-a")
+	if etag != WeakETag(updatedAt, 10, "viewer-a") {
+		t.Error("WeakETag isn't stable for the same parts")
+	}
+	if len(etag) < 5 || etag[:3] != `W/"` || etag[len(etag)-1] != '"' {
+		t.Errorf("WeakETag = %s, want a weak entity tag", etag)
+	}
+
+	changed := map[string]string{
+		"updated_at": WeakETag(updatedAt.Add(time.Millisecond), 10, "viewer-a"),
+		"counter":    WeakETag(updatedAt, 11, "viewer-a"),
+		"viewer":     WeakETag(updatedAt, 10, "viewer-b"),
+		"anonymous":  WeakETag(updatedAt, 10, ""),
+	}
+	for name, other := range changed {
+		if other == etag {
+			t.Errorf("WeakETag didn't change with the %s", name)
+		}
+	}
+
+	var unset *time.Time
+	if WeakETag(unset, 1) == WeakETag(&updatedAt, 1) {
+		t.Error("WeakETag ignores a *time.Time part")
+	}
+}
+
+func TestCheckETag(t *testing.T) {
+	etag := WeakETag("post", 1)
+	other := WeakETag("post", 2)
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        int
+	}{
+		{"no condition", "", http.StatusOK},
+		{"matching tag", etag, http.StatusNotModified},
+		{"strong form of the tag", etag[2:], http.StatusNotModified},
+		{"tag in a list", other + ", " + etag, http.StatusNotModified},
+		{"wildcard", "*", http.StatusNotModified},
+		{"stale tag", other, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.ifNoneMatch != "" {
+				c.Request.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+
+			if !CheckETag(c, etag) {
+				c.String(http.StatusOK, "body")
+			}
+
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.want)
+			}
+			if got := recorder.Header().Get("ETag"); got != etag {
+				t.Errorf("ETag = %q, want %q", got, etag)
+			}
+			if recorder.Header().Get("Cache-Control") != "private, no-cache" {
+				t.Errorf("Cache-Control = %q, want private, no-cache", recorder.Header().Get("Cache-Control"))
+			}
+			if tt.want == http.StatusNotModified && recorder.Body.Len() != 0 {
+				t.Errorf("304 carries a body: %q", recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestCheckModifiedSince(t *testing.T) {
+	lastModified := time.Date(2026, 1, 2, 15, 4, 5, 500, time.UTC)
+
+	tests := []struct {
+		name            string
+		ifModifiedSince string
+		ifNoneMatch     string
+		want            int
+	}{
+		{"no condition", "", "", http.StatusOK},
+		{"not modified since", lastModified.Format(http.TimeFormat), "", http.StatusNotModified},
+		{"modified since", lastModified.Add(-time.Second).Format(http.TimeFormat), "", http.StatusOK},
+		{"later date", lastModified.Add(time.Hour).Format(http.TimeFormat), "", http.StatusNotModified},
+		{"malformed date", "yesterday", "", http.StatusOK},
+		{"If-None-Match takes precedence", lastModified.Format(http.TimeFormat), `W/"other"`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.ifModifiedSince != "" {
+				c.Request.Header.Set("If-Modified-Since", tt.ifModifiedSince)
+			}
+			if tt.ifNoneMatch != "" {
+				c.Request.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+
+			if !CheckModifiedSince(c, lastModified) {
+				c.String(http.StatusOK, "body")
+			}
+
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.want)
+			}
+			if got := recorder.Header().Get("Last-Modified"); got != lastModified.Format(http.TimeFormat) {
+				t.Errorf("Last-Modified = %q, want %q", got, lastModified.Format(http.TimeFormat))
+			}
+		})
+	}
+}
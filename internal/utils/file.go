@@ -80,6 +80,7 @@ func GetMimeType(filename string) string {
 		".png":  "image/png",
 		".gif":  "image/gif",
 		".webp": "image/webp",
+		".avif": "image/avif",
 		".bmp":  "image/bmp",
 		".tiff": "image/tiff",
 		".svg":  "image/svg+xml",
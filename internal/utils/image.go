@@ -4,9 +4,11 @@ package utils
 import (
 	"fmt"
 	"image"
+	"image/color"
 	"image/gif"
 	"image/jpeg"
 	"image/png"
+	"math/bits"
 	"os"
 	"path/filepath"
 	"strings"
@@ -155,8 +157,9 @@ func GenerateThumbnails(originalPath string, sizes []ThumbnailSize) ([]string, e
 	return thumbnailPaths, nil
 }
 
-// createThumbnail creates a single thumbnail
-func createThumbnail(img image.Image, originalPath string, size ThumbnailSize, format string) (string, error) {
+// ResizeToFit scales an image to fit within the given size, keeping its
+// aspect ratio
+func ResizeToFit(img image.Image, maxWidth, maxHeight int) image.Image {
 	// Calculate thumbnail dimensions maintaining aspect ratio
 	bounds := img.Bounds()
 	originalWidth := bounds.Dx()
@@ -166,11 +169,11 @@ func createThumbnail(img image.Image, originalPath string, size ThumbnailSize, f
 
 	// Calculate dimensions to fit within the specified size
 	if originalWidth > originalHeight {
-		newWidth = size.Width
-		newHeight = int(float64(originalHeight) * float64(size.Width) / float64(originalWidth))
+		newWidth = maxWidth
+		newHeight = int(float64(originalHeight) * float64(maxWidth) / float64(originalWidth))
 	} else {
-		newHeight = size.Height
-		newWidth = int(float64(originalWidth) * float64(size.Height) / float64(originalHeight))
+		newHeight = maxHeight
+		newWidth = int(float64(originalWidth) * float64(maxHeight) / float64(originalHeight))
 	}
 
 	// Ensure minimum dimensions
@@ -187,18 +190,81 @@ func createThumbnail(img image.Image, originalPath string, size ThumbnailSize, f
 	// Simple nearest neighbor scaling (for demo purposes)
 	for y := 0; y < newHeight; y++ {
 		for x := 0; x < newWidth; x++ {
-			srcX := x * originalWidth / newWidth
-			srcY := y * originalHeight / newHeight
-			if srcX >= originalWidth {
-				srcX = originalWidth - 1
+			srcX := bounds.Min.X + x*originalWidth/newWidth
+			srcY := bounds.Min.Y + y*originalHeight/newHeight
+			if srcX >= bounds.Max.X {
+				srcX = bounds.Max.X - 1
 			}
-			if srcY >= originalHeight {
-				srcY = originalHeight - 1
+			if srcY >= bounds.Max.Y {
+				srcY = bounds.Max.Y - 1
 			}
 			resized.Set(x, y, img.At(srcX, srcY))
 		}
 	}
 
+	return resized
+}
+
+// DifferenceHash computes a 64-bit perceptual hash (dHash) of an image from
+// the brightness gradient of a 9x8 grid. Resized or recompressed copies of
+// an image hash a few bits apart; compare hashes with HammingDistance.
+func DifferenceHash(img image.Image) uint64 {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	var cells [8][9]float64
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 9; col++ {
+			cells[row][col] = averageGray(img, image.Rect(
+				bounds.Min.X+col*width/9, bounds.Min.Y+row*height/8,
+				bounds.Min.X+(col+1)*width/9, bounds.Min.Y+(row+1)*height/8,
+			))
+		}
+	}
+
+	var hash uint64
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			hash <<= 1
+			if cells[row][col] > cells[row][col+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// averageGray averages the brightness of up to 16x16 evenly spaced pixels
+// in a region
+func averageGray(img image.Image, region image.Rectangle) float64 {
+	if region.Dx() < 1 {
+		region.Max.X = region.Min.X + 1
+	}
+	if region.Dy() < 1 {
+		region.Max.Y = region.Min.Y + 1
+	}
+	stepX := max(1, region.Dx()/16)
+	stepY := max(1, region.Dy()/16)
+
+	var sum, count float64
+	for y := region.Min.Y; y < region.Max.Y; y += stepY {
+		for x := region.Min.X; x < region.Max.X; x += stepX {
+			sum += float64(color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y)
+			count++
+		}
+	}
+	return sum / count
+}
+
+// HammingDistance counts the bits two hashes differ in
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// createThumbnail creates a single thumbnail
+func createThumbnail(img image.Image, originalPath string, size ThumbnailSize, format string) (string, error) {
+	resized := ResizeToFit(img, size.Width, size.Height)
+
 	// Generate thumbnail filename
 	dir := filepath.Dir(originalPath)
 	filename := filepath.Base(originalPath)
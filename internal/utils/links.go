@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Link detection for spam screening. Spammers obfuscate URLs so they slip
+// past naive matching ("example . com", "example[.]com", "example。com"), so
+// text is normalized back to a plain domain before matching.
+
+// dotLookalikes are characters that render as, or are used in place of, a dot
+var dotLookalikes = strings.NewReplacer(
+	"\u3002", ".", // ideographic full stop
+	"\uff0e", ".", // fullwidth full stop
+	"\uff61", ".", // halfwidth ideographic full stop
+	"\u2024", ".", // one dot leader
+	"\u2027", ".", // hyphenation point
+	"\u00b7", ".", // middle dot
+	"\u2219", ".", // bullet operator
+	"\u22c5", ".", // dot operator
+	"\ufe52", ".", // small full stop
+	"\u200b", "", // zero width space
+	"\u200c", "", // zero width non-joiner
+	"\u200d", "", // zero width joiner
+	"\u2060", "", // word joiner
+	"\ufeff", "", // zero width no-break space
+	"\u00ad", "", // soft hyphen
+)
+
+var (
+	// "[.]", "(.)", "{dot}", " dot ", " d0t " and friends
+	spelledDot = regexp.MustCompile(`(?i)\s*[\[\(\{<]\s*(?:\.|dot|d0t)\s*[\]\)\}>]\s*|\s+(?:dot|d0t)\s+`)
+	// Whitespace before a dot between word characters: "example . com". A
+	// sentence end ("agree. Me") only has whitespace after the dot.
+	spacedDot = regexp.MustCompile(`(\w)\s+\.\s*(\w)`)
+
+	schemeOrWWW = regexp.MustCompile(`(?i)\b(?:https?|ftp)\s*:\s*/\s*/|\bwww\s*\.`)
+	// A domain label followed by a TLD that spam links commonly use. Generic
+	// two letter country codes are left out to keep sentence punctuation such
+	// as "done.it" from matching.
+	bareDomain = regexp.MustCompile(`(?i)\b[a-z0-9][a-z0-9-]*\.(?:com|net|org|info|biz|io|co|me|tv|cc|ly|gg|ws|to|ru|cn|tk|ml|ga|cf|gq|xyz|top|site|online|store|shop|club|live|link|click|win|bid|loan|vip|app|dev|pro|fun|space|website|icu|buzz|cam|work|uk|de|us|ca|au|in|br|fr|nl|pl)\b`)
+
+	// "https : / /" with the spacing taken out
+	spacedScheme = regexp.MustCompile(`(?i)\b(https?|ftp)\s*:\s*/\s*/`)
+	// A URL or bare host name with any TLD, plus its path. Matching is loose
+	// on purpose: candidates are only acted on when a blocklist covers them.
+	linkCandidate = regexp.MustCompile(`(?i)\b(?:(?:https?|ftp)://)?(?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\.)+[a-z]{2,63}\b(?::\d{1,5})?(?:/[^\s<>"']*)?`)
+)
+
+// trackingParams are query parameters that only identify the campaign or
+// click, never the page
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"dclid":   true,
+	"msclkid": true,
+	"yclid":   true,
+	"igshid":  true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"_hsenc":  true,
+	"_hsmi":   true,
+	"ref_src": true,
+}
+
+// ContainsLink reports whether text contains a URL or domain name, including
+// common obfuscations such as spaced-out dots and unicode dot lookalikes
+func ContainsLink(text string) bool {
+	if schemeOrWWW.MatchString(text) {
+		return true
+	}
+
+	normalized := dotLookalikes.Replace(text)
+	normalized = spelledDot.ReplaceAllString(normalized, ".")
+	normalized = spacedDot.ReplaceAllString(normalized, "$1.$2")
+
+	return schemeOrWWW.MatchString(normalized) || bareDomain.MatchString(normalized)
+}
+
+// ExtractLinks returns the URLs and domain names in text, undoing the same
+// obfuscations ContainsLink sees through
+func ExtractLinks(text string) []string {
+	normalized := dotLookalikes.Replace(text)
+	normalized = spelledDot.ReplaceAllString(normalized, ".")
+	normalized = spacedDot.ReplaceAllString(normalized, "$1.$2")
+	normalized = spacedScheme.ReplaceAllString(normalized, "$1://")
+
+	seen := make(map[string]bool)
+	var links []string
+	for _, link := range linkCandidate.FindAllString(normalized, -1) {
+		link = strings.TrimRight(link, ".,;:!?)]}")
+		if !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// NormalizeLink parses a link found in text, adding a scheme when it has none,
+// and strips the fragment and tracking parameters (utm_*, fbclid, gclid...)
+func NormalizeLink(link string) (*url.URL, error) {
+	if !strings.Contains(link, "://") {
+		link = "http://" + link
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = strings.ToLower(parsed.Host)
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+
+	query := parsed.Query()
+	for key := range query {
+		lower := strings.ToLower(key)
+		if strings.HasPrefix(lower, "utm_") || trackingParams[lower] {
+			query.Del(key)
+		}
+	}
+	parsed.RawQuery = query.Encode()
+
+	return parsed, nil
+}
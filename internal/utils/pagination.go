@@ -4,7 +4,9 @@ package utils
 import (
 	"fmt"
 	"math"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
@@ -59,63 +61,92 @@ type CursorPaginatedResult struct {
 	Pagination CursorPaginationMeta `json:"pagination"`
 }
 
-// GetPaginationParams extracts pagination parameters from request
-func GetPaginationParams(c *gin.Context) PaginationParams {
-	page := 1
-	limit := DefaultPageSize
+// Page sizes used when a list endpoint sets none of its own, set from
+// configuration at startup by ConfigurePagination
+var (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
 
-	// Get page parameter
-	if pageStr := c.Query("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
-	}
+// ConfigurePagination sets the default and maximum page sizes
+func ConfigurePagination(defaultPageSize, maxPageSize int) {
+	DefaultPageSize = defaultPageSize
+	MaxPageSize = maxPageSize
+}
 
-	// Get limit parameter
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			if l > MaxPageSize {
-				limit = MaxPageSize
-			} else if l < MinPageSize {
-				limit = MinPageSize
-			} else {
-				limit = l
-			}
-		}
+// ParsePagination reads the page and limit query parameters. limit defaults
+// to defaultLimit and is clamped to maxLimit, which is itself capped at
+// MaxPageSize. Values that aren't positive integers are an error, so
+// handlers can answer 400 instead of guessing.
+func ParsePagination(c *gin.Context, defaultLimit, maxLimit int) (PaginationParams, error) {
+	page, err := ParseIntQuery(c, "page", 1, 1)
+	if err != nil {
+		return PaginationParams{}, err
 	}
 
-	// Calculate offset
-	offset := (page - 1) * limit
+	limit, err := ParseLimit(c, defaultLimit, maxLimit)
+	if err != nil {
+		return PaginationParams{}, err
+	}
 
 	return PaginationParams{
 		Page:   page,
 		Limit:  limit,
-		Offset: offset,
-	}
+		Offset: (page - 1) * limit,
+	}, nil
 }
 
-// GetCursorPaginationParams extracts cursor pagination parameters from request
-func GetCursorPaginationParams(c *gin.Context) CursorPaginationParams {
-	cursor := c.Query("cursor")
-	limit := DefaultPageSize
-
-	// Get limit parameter
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			if l > MaxPageSize {
-				limit = MaxPageSize
-			} else if l < MinPageSize {
-				limit = MinPageSize
-			} else {
-				limit = l
-			}
-		}
+// ParseCursorPagination reads the cursor and limit query parameters, with
+// limit checked as in ParsePagination
+func ParseCursorPagination(c *gin.Context, defaultLimit, maxLimit int) (CursorPaginationParams, error) {
+	limit, err := ParseLimit(c, defaultLimit, maxLimit)
+	if err != nil {
+		return CursorPaginationParams{}, err
 	}
 
 	return CursorPaginationParams{
-		Cursor: cursor,
+		Cursor: c.Query("cursor"),
 		Limit:  limit,
+	}, nil
+}
+
+// ParseLimit reads the limit query parameter of an endpoint that returns a
+// single page, with the same checks as ParsePagination
+func ParseLimit(c *gin.Context, defaultLimit, maxLimit int) (int, error) {
+	if maxLimit <= 0 || maxLimit > MaxPageSize {
+		maxLimit = MaxPageSize
 	}
+	if defaultLimit <= 0 || defaultLimit > maxLimit {
+		defaultLimit = maxLimit
+	}
+
+	limit, err := ParseIntQuery(c, "limit", defaultLimit, 1)
+	if err != nil {
+		return 0, err
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	return limit, nil
+}
+
+// ParseIntQuery reads an integer query parameter of at least min, returning
+// fallback when it is absent. Other values are an error, so handlers can
+// answer 400 instead of guessing.
+func ParseIntQuery(c *gin.Context, name string, fallback, min int) (int, error) {
+	raw := strings.TrimSpace(c.Query(name))
+	if raw == "" {
+		return fallback, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < min {
+		if min == 1 {
+			return 0, fmt.Errorf("invalid %s: must be a positive integer", name)
+		}
+		return 0, fmt.Errorf("invalid %s: must be an integer of at least %d", name, min)
+	}
+	return value, nil
 }
 
 // CreatePaginationMeta creates pagination metadata
@@ -213,6 +244,33 @@ func GetSortOrder(c *gin.Context, defaultField string, defaultOrder int) (string
 	return sortField, sortOrder
 }
 
+// GetLanguageFilter returns the content languages requested via the "lang"
+// query parameter (comma-separated). It returns nil when the parameter is
+// absent so callers can fall back to the user's preferred languages, and an
+// empty slice for "lang=all" to disable language filtering.
+func GetLanguageFilter(c *gin.Context) []string {
+	value, ok := c.GetQuery("lang")
+	if !ok {
+		return nil
+	}
+
+	languages := []string{}
+	for _, lang := range strings.Split(value, ",") {
+		lang = strings.ToLower(strings.TrimSpace(lang))
+		if i := strings.IndexAny(lang, "-_"); i > 0 {
+			lang = lang[:i]
+		}
+		if lang == "all" {
+			return []string{}
+		}
+		if lang != "" {
+			languages = append(languages, lang)
+		}
+	}
+
+	return languages
+}
+
 // GetSortOrderFromString converts string sort order to int
 func GetSortOrderFromString(order string) int {
 	switch order {
@@ -316,15 +374,18 @@ type SearchPaginationParams struct {
 }
 
 // GetSearchPaginationParams extracts search pagination parameters
-func GetSearchPaginationParams(c *gin.Context) SearchPaginationParams {
-	params := GetPaginationParams(c)
+func GetSearchPaginationParams(c *gin.Context) (SearchPaginationParams, error) {
+	params, err := ParsePagination(c, DefaultPageSize, MaxPageSize)
+	if err != nil {
+		return SearchPaginationParams{}, err
+	}
 
 	return SearchPaginationParams{
 		PaginationParams: params,
 		Query:            c.Query("q"),
 		Category:         c.Query("category"),
 		Type:             c.Query("type"),
-	}
+	}, nil
 }
 
 // TimeBasedPaginationParams represents time-based pagination
@@ -436,33 +497,66 @@ type PaginationLinks struct {
 
 // GeneratePaginationLinks generates pagination links
 func GeneratePaginationLinks(baseURL string, params PaginationParams, meta PaginationMeta) PaginationLinks {
+	return GeneratePaginationLinksWithQuery(baseURL, url.Values{}, params, meta)
+}
+
+// GeneratePaginationLinksWithQuery generates pagination links that carry forward
+// every query parameter (search, filters, sort), replacing only page and limit
+func GeneratePaginationLinksWithQuery(baseURL string, query url.Values, params PaginationParams, meta PaginationMeta) PaginationLinks {
+	pageURL := func(page int) string {
+		values := url.Values{}
+		for key, vals := range query {
+			values[key] = append([]string(nil), vals...)
+		}
+		values.Set("page", strconv.Itoa(page))
+		values.Set("limit", strconv.Itoa(params.Limit))
+		return baseURL + "?" + values.Encode()
+	}
+
 	links := PaginationLinks{
-		Self: fmt.Sprintf("%s?page=%d&limit=%d", baseURL, params.Page, params.Limit),
+		Self: pageURL(params.Page),
 	}
 
 	// First page link
 	if meta.TotalPages > 0 {
-		links.First = fmt.Sprintf("%s?page=1&limit=%d", baseURL, params.Limit)
+		links.First = pageURL(1)
 	}
 
 	// Previous page link
 	if meta.HasPrevious {
-		links.Previous = fmt.Sprintf("%s?page=%d&limit=%d", baseURL, params.Page-1, params.Limit)
+		links.Previous = pageURL(params.Page - 1)
 	}
 
 	// Next page link
 	if meta.HasNext {
-		links.Next = fmt.Sprintf("%s?page=%d&limit=%d", baseURL, params.Page+1, params.Limit)
+		links.Next = pageURL(params.Page + 1)
 	}
 
 	// Last page link
 	if meta.TotalPages > 0 {
-		links.Last = fmt.Sprintf("%s?page=%d&limit=%d", baseURL, meta.TotalPages, params.Limit)
+		links.Last = pageURL(meta.TotalPages)
 	}
 
 	return links
 }
 
+// CreatePaginationLinks generates pagination links for the current request, keeping its query parameters
+func CreatePaginationLinks(c *gin.Context, meta PaginationMeta) *PaginationLinks {
+	params := PaginationParams{
+		Page:  meta.CurrentPage,
+		Limit: meta.PerPage,
+	}
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = DefaultPageSize
+	}
+
+	links := GeneratePaginationLinksWithQuery(c.Request.URL.Path, c.Request.URL.Query(), params, meta)
+	return &links
+}
+
 // PaginatedResultWithLinks represents paginated result with navigation links
 type PaginatedResultWithLinks struct {
 	Data       interface{}     `json:"data"`
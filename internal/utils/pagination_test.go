@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newQueryContext(target string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	return c
+}
+
+func TestParseIntQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		min     int
+		want    int
+		wantErr bool
+	}{
+		{"", 1, 7, false},
+		{"?n=3", 1, 3, false},
+		{"?n=+3", 1, 3, false},
+		{"?n=0", 0, 0, false},
+		{"?n=0", 1, 0, true},
+		{"?n=-1", 0, 0, true},
+		{"?n=abc", 1, 0, true},
+		{"?n=2.5", 1, 0, true},
+		{"?n=99999999999999999999", 1, 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseIntQuery(newQueryContext("/"+tt.query), "n", 7, tt.min)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseIntQuery(%q, min %d) = %d, %v, want %d, error %v", tt.query, tt.min, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParsePaginationRejectsInvalidValues(t *testing.T) {
+	for _, query := range []string{"?page=0", "?page=x", "?limit=-5", "?limit=ten"} {
+		if _, err := ParsePagination(newQueryContext("/"+query), 20, 100); err == nil {
+			t.Errorf("ParsePagination(%q) succeeded, want an error", query)
+		}
+	}
+
+	params, err := ParsePagination(newQueryContext("/?page=3&limit=500"), 20, 50)
+	if err != nil {
+		t.Fatalf("ParsePagination: %v", err)
+	}
+	if params.Page != 3 || params.Limit != 50 || params.Offset != 100 {
+		t.Errorf("ParsePagination() = %+v, want page 3, limit 50, offset 100", params)
+	}
+}
+
+func TestGeneratePaginationLinksWithQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		page  int
+		limit int
+		total int64
+		// Page each link points to, 0 when the link is omitted
+		self, first, previous, next, last int
+	}{
+		{"middle page keeps filters", "q=john&status=active&status=pending&sort=-created_at&page=2&limit=10", 2, 10, 45, 2, 1, 1, 3, 5},
+		{"first page", "q=john&role=admin", 1, 10, 45, 1, 1, 0, 2, 5},
+		{"last page", "q=john&role=admin&page=5", 5, 10, 45, 5, 1, 4, 0, 5},
+		{"single page", "role=admin", 1, 10, 5, 1, 1, 0, 0, 1},
+		{"no results", "q=nobody&page=1", 1, 10, 0, 1, 0, 0, 0, 0},
+		{"duplicated page param", "page=2&page=7&limit=10&limit=50&role=admin", 2, 10, 45, 2, 1, 1, 3, 5},
+		{"escaped values", "q=" + url.QueryEscape("a b&c=d") + "&tag=%23go", 1, 20, 45, 1, 1, 0, 2, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("bad test query: %v", err)
+			}
+			params := PaginationParams{Page: tt.page, Limit: tt.limit, Offset: (tt.page - 1) * tt.limit}
+			links := GeneratePaginationLinksWithQuery("/api/v1/admin/users", query, params, CreatePaginationMeta(params, tt.total))
+
+			// Every link carries the request's other params unchanged, with
+			// page and limit replaced rather than repeated
+			filters := url.Values{}
+			for key, values := range query {
+				if key != "page" && key != "limit" {
+					filters[key] = values
+				}
+			}
+
+			check := func(name, link string, wantPage int) {
+				t.Helper()
+				if wantPage == 0 {
+					if link != "" {
+						t.Errorf("%s = %q, want no link", name, link)
+					}
+					return
+				}
+
+				parsed, err := url.Parse(link)
+				if err != nil {
+					t.Fatalf("%s = %q isn't a valid URL: %v", name, link, err)
+				}
+				if parsed.Path != "/api/v1/admin/users" {
+					t.Errorf("%s path = %q, want /api/v1/admin/users", name, parsed.Path)
+				}
+
+				values := parsed.Query()
+				if got := values["page"]; len(got) != 1 || got[0] != strconv.Itoa(wantPage) {
+					t.Errorf("%s page = %v, want [%d]", name, got, wantPage)
+				}
+				if got := values["limit"]; len(got) != 1 || got[0] != strconv.Itoa(tt.limit) {
+					t.Errorf("%s limit = %v, want [%d]", name, got, tt.limit)
+				}
+				values.Del("page")
+				values.Del("limit")
+				if !reflect.DeepEqual(values, filters) {
+					t.Errorf("%s params = %v, want %v", name, values, filters)
+				}
+			}
+
+			check("self", links.Self, tt.self)
+			check("first", links.First, tt.first)
+			check("previous", links.Previous, tt.previous)
+			check("next", links.Next, tt.next)
+			check("last", links.Last, tt.last)
+		})
+	}
+}
+
+func TestCreatePaginationLinksKeepsRequestQuery(t *testing.T) {
+	c := newQueryContext("/api/v1/admin/reports?status=pending&type=spam&page=3&limit=25")
+	params := PaginationParams{Page: 3, Limit: 25, Offset: 50}
+
+	links := CreatePaginationLinks(c, CreatePaginationMeta(params, 100))
+
+	want := "/api/v1/admin/reports?limit=25&page=4&status=pending&type=spam"
+	if links.Next != want {
+		t.Errorf("next = %q, want %q", links.Next, want)
+	}
+}
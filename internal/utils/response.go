@@ -3,6 +3,7 @@ package utils
 
 import (
 	"net/http"
+	"reflect"
 	"strings"
 	"time"
 
@@ -15,28 +16,33 @@ type Response struct {
 	Success   bool        `json:"success"`
 	Message   string      `json:"message"`
 	Data      interface{} `json:"data,omitempty"`
+	ErrorCode ErrorCode   `json:"error_code,omitempty"`
 	Error     *ErrorInfo  `json:"error,omitempty"`
+	Fields    FieldErrors `json:"fields,omitempty"`
 	Meta      interface{} `json:"meta,omitempty"`
 	Timestamp int64       `json:"timestamp"`
 }
 
 // ErrorInfo represents detailed error information
 type ErrorInfo struct {
-	Code    string      `json:"code,omitempty"`
+	Code    ErrorCode   `json:"code,omitempty"`
 	Message string      `json:"message"`
 	Details interface{} `json:"details,omitempty"`
 	Field   string      `json:"field,omitempty"`
 	Value   interface{} `json:"value,omitempty"`
 }
 
-// ValidationError represents validation error details
+// ValidationError represents validation error details. The submitted value is
+// deliberately left out so passwords and personal data are never echoed back.
 type ValidationError struct {
-	Field   string      `json:"field"`
-	Tag     string      `json:"tag"`
-	Message string      `json:"message"`
-	Value   interface{} `json:"value,omitempty"`
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
+	Message string `json:"message"`
 }
 
+// FieldErrors maps a JSON field path (e.g. "questions[0].options") to its validation messages
+type FieldErrors map[string][]string
+
 // PaginatedResponse represents paginated data response
 type PaginatedResponse struct {
 	Success    bool             `json:"success"`
@@ -70,27 +76,13 @@ func SuccessResponseWithMeta(c *gin.Context, statusCode int, message string, dat
 	c.JSON(statusCode, response)
 }
 
-// ErrorResponse sends an error response
+// ErrorResponse sends an error response with the default code for the status
 func ErrorResponse(c *gin.Context, statusCode int, message string, err error) {
-	var errorInfo *ErrorInfo
-
-	if err != nil {
-		errorInfo = &ErrorInfo{
-			Message: err.Error(),
-		}
-	}
-
-	response := Response{
-		Success:   false,
-		Message:   message,
-		Error:     errorInfo,
-		Timestamp: getCurrentTimestamp(),
-	}
-	c.JSON(statusCode, response)
+	ErrorResponseWithCode(c, statusCode, message, DefaultErrorCode(statusCode), err)
 }
 
 // ErrorResponseWithCode sends an error response with error code
-func ErrorResponseWithCode(c *gin.Context, statusCode int, message string, errorCode string, err error) {
+func ErrorResponseWithCode(c *gin.Context, statusCode int, message string, errorCode ErrorCode, err error) {
 	var errorInfo *ErrorInfo
 
 	if err != nil {
@@ -108,6 +100,7 @@ func ErrorResponseWithCode(c *gin.Context, statusCode int, message string, error
 	response := Response{
 		Success:   false,
 		Message:   message,
+		ErrorCode: errorCode,
 		Error:     errorInfo,
 		Timestamp: getCurrentTimestamp(),
 	}
@@ -115,7 +108,7 @@ func ErrorResponseWithCode(c *gin.Context, statusCode int, message string, error
 }
 
 // ErrorResponseWithDetails sends an error response with detailed error information
-func ErrorResponseWithDetails(c *gin.Context, statusCode int, message string, errorCode string, details interface{}) {
+func ErrorResponseWithDetails(c *gin.Context, statusCode int, message string, errorCode ErrorCode, details interface{}) {
 	errorInfo := &ErrorInfo{
 		Code:    errorCode,
 		Message: message,
@@ -125,6 +118,7 @@ func ErrorResponseWithDetails(c *gin.Context, statusCode int, message string, er
 	response := Response{
 		Success:   false,
 		Message:   message,
+		ErrorCode: errorCode,
 		Error:     errorInfo,
 		Timestamp: getCurrentTimestamp(),
 	}
@@ -134,15 +128,19 @@ func ErrorResponseWithDetails(c *gin.Context, statusCode int, message string, er
 // ValidationErrorResponse sends validation error response
 func ValidationErrorResponse(c *gin.Context, err error) {
 	var validationErrors []ValidationError
+	fields := FieldErrors{}
 
 	if validationErr, ok := err.(validator.ValidationErrors); ok {
 		for _, fieldErr := range validationErr {
+			field := getJSONFieldPath(fieldErr)
+			message := getValidationErrorMessage(fieldErr)
+
 			validationErrors = append(validationErrors, ValidationError{
-				Field:   getJSONFieldName(fieldErr),
+				Field:   field,
 				Tag:     fieldErr.Tag(),
-				Message: getValidationErrorMessage(fieldErr),
-				Value:   fieldErr.Value(),
+				Message: message,
 			})
+			fields[field] = append(fields[field], message)
 		}
 	} else {
 		// Handle other types of validation errors
@@ -152,7 +150,7 @@ func ValidationErrorResponse(c *gin.Context, err error) {
 	}
 
 	errorInfo := &ErrorInfo{
-		Code:    "VALIDATION_ERROR",
+		Code:    ErrorCodeValidationFailed,
 		Message: "Validation failed",
 		Details: validationErrors,
 	}
@@ -160,7 +158,9 @@ func ValidationErrorResponse(c *gin.Context, err error) {
 	response := Response{
 		Success:   false,
 		Message:   "Validation failed",
+		ErrorCode: ErrorCodeValidationFailed,
 		Error:     errorInfo,
+		Fields:    fields,
 		Timestamp: getCurrentTimestamp(),
 	}
 
@@ -182,42 +182,53 @@ func PaginatedSuccessResponse(c *gin.Context, message string, data interface{},
 
 // NotFoundResponse sends a 404 not found response
 func NotFoundResponse(c *gin.Context, message string) {
-	ErrorResponseWithCode(c, http.StatusNotFound, message, "NOT_FOUND", nil)
+	ErrorResponseWithCode(c, http.StatusNotFound, message, ErrorCodeNotFound, nil)
 }
 
 // UnauthorizedResponse sends a 401 unauthorized response
 func UnauthorizedResponse(c *gin.Context, message string) {
-	ErrorResponseWithCode(c, http.StatusUnauthorized, message, "UNAUTHORIZED", nil)
+	ErrorResponseWithCode(c, http.StatusUnauthorized, message, ErrorCodeAuthRequired, nil)
 }
 
 // ForbiddenResponse sends a 403 forbidden response
 func ForbiddenResponse(c *gin.Context, message string) {
-	ErrorResponseWithCode(c, http.StatusForbidden, message, "FORBIDDEN", nil)
+	ErrorResponseWithCode(c, http.StatusForbidden, message, ErrorCodeForbidden, nil)
 }
 
-// BadRequestResponse sends a 400 bad request response
+// BadRequestResponse sends a 400 bad request response. Binding errors raised by
+// validator tags are reported per field like ValidationErrorResponse.
 func BadRequestResponse(c *gin.Context, message string, err error) {
-	ErrorResponseWithCode(c, http.StatusBadRequest, message, "BAD_REQUEST", err)
+	if _, ok := err.(validator.ValidationErrors); ok {
+		ValidationErrorResponse(c, err)
+		return
+	}
+	ErrorResponseWithCode(c, http.StatusBadRequest, message, ErrorCodeBadRequest, err)
 }
 
 // InternalServerErrorResponse sends a 500 internal server error response
 func InternalServerErrorResponse(c *gin.Context, message string, err error) {
-	ErrorResponseWithCode(c, http.StatusInternalServerError, message, "INTERNAL_ERROR", err)
+	ErrorResponseWithCode(c, http.StatusInternalServerError, message, ErrorCodeInternal, err)
 }
 
 // ConflictResponse sends a 409 conflict response
 func ConflictResponse(c *gin.Context, message string, err error) {
-	ErrorResponseWithCode(c, http.StatusConflict, message, "CONFLICT", err)
+	ErrorResponseWithCode(c, http.StatusConflict, message, ErrorCodeConflict, err)
 }
 
 // TooManyRequestsResponse sends a 429 too many requests response
 func TooManyRequestsResponse(c *gin.Context, message string) {
-	ErrorResponseWithCode(c, http.StatusTooManyRequests, message, "RATE_LIMIT_EXCEEDED", nil)
+	ErrorResponseWithCode(c, http.StatusTooManyRequests, message, ErrorCodeRateLimited, nil)
+}
+
+// QueryTimeoutResponse sends a 504 response for an admin or analytics query
+// that hit its server-side time limit
+func QueryTimeoutResponse(c *gin.Context, err error) {
+	ErrorResponseWithCode(c, http.StatusGatewayTimeout, "Query took too long; narrow the date range or filters, or request an export instead", ErrorCodeQueryTimeout, err)
 }
 
 // ServiceUnavailableResponse sends a 503 service unavailable response
 func ServiceUnavailableResponse(c *gin.Context, message string) {
-	ErrorResponseWithCode(c, http.StatusServiceUnavailable, message, "SERVICE_UNAVAILABLE", nil)
+	ErrorResponseWithCode(c, http.StatusServiceUnavailable, message, ErrorCodeServiceUnavailable, nil)
 }
 
 // CreatedResponse sends a 201 created response
@@ -290,13 +301,45 @@ func getCurrentTimestamp() int64 {
 
 // getJSONFieldName extracts JSON field name from validation error
 func getJSONFieldName(fe validator.FieldError) string {
-	field := fe.Field()
+	return toSnakeCase(fe.Field())
+}
+
+// getJSONFieldPath returns the full JSON path of the failing field, so nested
+// structs and slice elements are reported as e.g. "audience.user_ids[2]"
+func getJSONFieldPath(fe validator.FieldError) string {
+	namespace := fe.StructNamespace()
+
+	// Drop the top-level struct name
+	if i := strings.Index(namespace, "."); i >= 0 {
+		namespace = namespace[i+1:]
+	}
+
+	segments := strings.Split(namespace, ".")
+	for i, segment := range segments {
+		name, index := segment, ""
+		if j := strings.Index(segment, "["); j >= 0 {
+			name, index = segment[:j], segment[j:]
+		}
+		segments[i] = toSnakeCase(name) + index
+	}
 
-	// Convert struct field name to JSON field name (snake_case)
+	return strings.Join(segments, ".")
+}
+
+// toSnakeCase converts a Go field name to its snake_case JSON name
+func toSnakeCase(field string) string {
 	var result strings.Builder
-	for i, r := range field {
+	runes := []rune(field)
+	for i, r := range runes {
 		if i > 0 && 'A' <= r && r <= 'Z' {
-			result.WriteRune('_')
+			// Keep acronyms such as "ID" or "URL" together, plurals like
+			// "IDs" included
+			prevUpper := 'A' <= runes[i-1] && runes[i-1] <= 'Z'
+			nextLower := i+1 < len(runes) && 'a' <= runes[i+1] && runes[i+1] <= 'z'
+			plural := i+1 < len(runes) && runes[i+1] == 's' && (i+2 == len(runes) || runes[i+2] < 'a' || runes[i+2] > 'z')
+			if !prevUpper || (nextLower && !plural) {
+				result.WriteRune('_')
+			}
 		}
 		result.WriteRune(r)
 	}
@@ -314,11 +357,15 @@ func getValidationErrorMessage(fe validator.FieldError) string {
 	case "email":
 		return field + " must be a valid email address"
 	case "min":
-		return field + " must be at least " + fe.Param() + " characters long"
+		return field + " must be at least " + fe.Param() + lengthUnit(fe)
 	case "max":
-		return field + " must be at most " + fe.Param() + " characters long"
+		return field + " must be at most " + fe.Param() + lengthUnit(fe)
 	case "len":
-		return field + " must be exactly " + fe.Param() + " characters long"
+		return field + " must be exactly " + fe.Param() + lengthUnit(fe)
+	case "required_if", "required_with", "required_without":
+		return field + " is required"
+	case "dive":
+		return field + " contains an invalid item"
 	case "numeric":
 		return field + " must be a number"
 	case "alpha":
@@ -347,10 +394,26 @@ func getValidationErrorMessage(fe validator.FieldError) string {
 		return field + " must not be equal to " + fe.Param()
 	case "unique":
 		return field + " must be unique"
+	case "datetime":
+		return field + " must be a valid date/time in the format " + fe.Param()
+	case "hexadecimal":
+		return field + " must be a valid ID"
 	default:
 		return field + " is invalid"
 	}
 }
+
+// lengthUnit describes what min/max/len count for the field's kind
+func lengthUnit(fe validator.FieldError) string {
+	switch fe.Kind() {
+	case reflect.String:
+		return " characters long"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return " items"
+	default:
+		return ""
+	}
+}
 func ValidateStruct(data interface{}) error {
 	validate := validator.New()
 	return validate.Struct(data)
@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type testAddress struct {
+	City       string `validate:"required"`
+	PostalCode string `validate:"len=5"`
+}
+
+type testPollOption struct {
+	Label string `validate:"required,max=10"`
+}
+
+type testSignup struct {
+	Email          string           `validate:"required,email"`
+	HomeAddress    testAddress      `validate:"required"`
+	BillingAddress *testAddress     `validate:"omitempty"`
+	Tags           []string         `validate:"max=3,dive,min=3"`
+	PollOptions    []testPollOption `validate:"min=1,dive"`
+	InviteIDs      []string         `validate:"dive,len=24"`
+}
+
+// validationResponse validates v and decodes the response
+// ValidationErrorResponse sends for the failures
+func validationResponse(t *testing.T, v interface{}) (int, Response) {
+	t.Helper()
+
+	err := validator.New().Struct(v)
+	if err == nil {
+		t.Fatal("expected validation to fail")
+	}
+
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	ValidationErrorResponse(c, err)
+
+	var response Response
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if strings.Contains(recorder.Body.String(), "not-an-email") {
+		t.Errorf("response echoes the submitted value: %s", recorder.Body.String())
+	}
+	return recorder.Code, response
+}
+
+func TestValidationErrorResponseFields(t *testing.T) {
+	tests := []struct {
+		name  string
+		input testSignup
+		want  FieldErrors
+	}{
+		{
+			name: "top-level fields",
+			input: testSignup{
+				Email:       "not-an-email",
+				HomeAddress: testAddress{City: "Oslo", PostalCode: "01500"},
+				PollOptions: []testPollOption{{Label: "Yes"}},
+			},
+			want: FieldErrors{
+				"email": {"email must be a valid email address"},
+			},
+		},
+		{
+			name: "nested struct",
+			input: testSignup{
+				Email:          "user@example.com",
+				HomeAddress:    testAddress{PostalCode: "123"},
+				BillingAddress: &testAddress{City: "Oslo", PostalCode: "1234567"},
+				PollOptions:    []testPollOption{{Label: "Yes"}},
+			},
+			want: FieldErrors{
+				"home_address.city":           {"city is required"},
+				"home_address.postal_code":    {"postal_code must be exactly 5 characters long"},
+				"billing_address.postal_code": {"postal_code must be exactly 5 characters long"},
+			},
+		},
+		{
+			name: "slice elements",
+			input: testSignup{
+				Email:       "user@example.com",
+				HomeAddress: testAddress{City: "Oslo", PostalCode: "01500"},
+				Tags:        []string{"golang", "go"},
+				PollOptions: []testPollOption{{Label: "Yes"}, {Label: ""}, {Label: "Absolutely not"}},
+				InviteIDs:   []string{"507f1f77bcf86cd799439011", "short"},
+			},
+			want: FieldErrors{
+				"tags[1]":               {"tags[1] must be at least 3 characters long"},
+				"poll_options[1].label": {"label is required"},
+				"poll_options[2].label": {"label must be at most 10 characters long"},
+				"invite_ids[1]":         {"invite_ids[1] must be exactly 24 characters long"},
+			},
+		},
+		{
+			name: "slice length",
+			input: testSignup{
+				Email:       "user@example.com",
+				HomeAddress: testAddress{City: "Oslo", PostalCode: "01500"},
+				Tags:        []string{"one", "two", "three", "four"},
+			},
+			want: FieldErrors{
+				"tags":         {"tags must be at most 3 items"},
+				"poll_options": {"poll_options must be at least 1 items"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, response := validationResponse(t, tt.input)
+
+			if code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", code, http.StatusBadRequest)
+			}
+			if response.ErrorCode != ErrorCodeValidationFailed {
+				t.Errorf("error_code = %q, want %q", response.ErrorCode, ErrorCodeValidationFailed)
+			}
+			if !reflect.DeepEqual(response.Fields, tt.want) {
+				t.Errorf("fields = %v, want %v", response.Fields, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidationErrorResponseWithoutValidatorErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+
+	ValidationErrorResponse(c, &json.SyntaxError{})
+
+	var response Response
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if recorder.Code != http.StatusBadRequest || len(response.Fields) != 0 {
+		t.Errorf("status = %d, fields = %v, want 400 without fields", recorder.Code, response.Fields)
+	}
+}
+
+func TestToSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"Email":          "email",
+		"PostalCode":     "postal_code",
+		"UserID":         "user_id",
+		"ImageURL":       "image_url",
+		"HTTPStatus":     "http_status",
+		"ParticipantIDs": "participant_ids",
+		"MediaURLs":      "media_urls",
+		"IDsSeen":        "ids_seen",
+		"UserIDs[2]":     "user_ids[2]",
+		"Address":        "address",
+	}
+
+	for field, want := range tests {
+		if got := toSnakeCase(field); got != want {
+			t.Errorf("toSnakeCase(%q) = %q, want %q", field, got, want)
+		}
+	}
+}
@@ -29,14 +29,10 @@ const (
 	channelBufferSize = 256
 )
 
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  4096,
-	WriteBufferSize: 4096,
-	CheckOrigin: func(r *http.Request) bool {
-		// TODO: Implement proper origin checking in production
-		return true
-	},
-}
+const (
+	readBufferSize  = 4096
+	writeBufferSize = 4096
+)
 
 // Client represents a WebSocket client connection
 type Client struct {
@@ -48,6 +44,9 @@ type Client struct {
 	Username string             `json:"username"`
 	IsActive bool               `json:"is_active"`
 
+	// The person behind the connection when a delegate connected for the user
+	DelegateID *primitive.ObjectID `json:"-"`
+
 	// Connection metadata
 	SessionID   string    `json:"session_id"`
 	ConnectedAt time.Time `json:"connected_at"`
@@ -97,7 +96,7 @@ type ClientInfo struct {
 }
 
 // NewClient creates a new WebSocket client
-func NewClient(hub *Hub, conn *websocket.Conn, userID primitive.ObjectID, username string, r *http.Request) *Client {
+func NewClient(hub *Hub, conn *websocket.Conn, userID primitive.ObjectID, username, clientIP string, r *http.Request) *Client {
 	client := &Client{
 		conn:          conn,
 		UserID:        userID,
@@ -107,7 +106,7 @@ func NewClient(hub *Hub, conn *websocket.Conn, userID primitive.ObjectID, userna
 		ConnectedAt:   time.Now(),
 		LastPingAt:    time.Now(),
 		UserAgent:     r.Header.Get("User-Agent"),
-		IPAddress:     getClientIP(r),
+		IPAddress:     clientIP,
 		send:          make(chan []byte, channelBufferSize),
 		hub:           hub,
 		subscriptions: make(map[string]bool),
@@ -117,6 +116,28 @@ func NewClient(hub *Hub, conn *websocket.Conn, userID primitive.ObjectID, userna
 	return client
 }
 
+// ServeWS upgrades an authenticated HTTP request to a WebSocket connection
+// and registers it with the hub. clientIP is the request's real client IP as
+// resolved by the HTTP middleware. delegateID is set when a delegate connects
+// for the user, so what they send is attributed to them.
+func ServeWS(hub *Hub, w http.ResponseWriter, r *http.Request, userID primitive.ObjectID, username, clientIP string, delegateID *primitive.ObjectID) error {
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  readBufferSize,
+		WriteBufferSize: writeBufferSize,
+		CheckOrigin:     hub.checkOrigin,
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return err
+	}
+
+	client := NewClient(hub, conn, userID, username, clientIP, r)
+	client.DelegateID = delegateID
+	client.Start()
+	return nil
+}
+
 // Start begins the client's read and write loops
 func (c *Client) Start() {
 	go c.writePump()
@@ -524,24 +545,6 @@ func generateSessionID() string {
 	return primitive.NewObjectID().Hex()
 }
 
-// getClientIP extracts client IP from HTTP request
-func getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header
-	forwarded := r.Header.Get("X-Forwarded-For")
-	if forwarded != "" {
-		return forwarded
-	}
-
-	// Check X-Real-IP header
-	realIP := r.Header.Get("X-Real-IP")
-	if realIP != "" {
-		return realIP
-	}
-
-	// Fall back to RemoteAddr
-	return r.RemoteAddr
-}
-
 // Custom errors
 var (
 	ErrClientClosed        = &WebSocketError{Code: "CLIENT_CLOSED", Message: "Client connection is closed"}
@@ -4,9 +4,14 @@ package websocket
 import (
 	"encoding/json"
 	"log"
+	"net/http"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
+	"social-media-api/internal/models"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -37,6 +42,9 @@ type Hub struct {
 	messageHandler      MessageHandlerInterface
 	notificationHandler NotificationHandlerInterface
 
+	// Notified when a user's first session connects or last session disconnects
+	presenceListener PresenceListener
+
 	// Statistics
 	stats *HubStats
 
@@ -53,6 +61,13 @@ type Hub struct {
 	shutdown chan struct{}
 }
 
+// PresenceListener receives connection transitions for a user. It is only
+// called when the user goes from no sessions to one and back, not per session.
+type PresenceListener interface {
+	UserConnected(userID primitive.ObjectID)
+	UserDisconnected(userID primitive.ObjectID)
+}
+
 // BroadcastMessage represents a message to be broadcast
 type BroadcastMessage struct {
 	Type      string               `json:"type"`
@@ -101,6 +116,11 @@ type HubConfig struct {
 	StatsInterval     time.Duration `json:"stats_interval"`
 	EnableMetrics     bool          `json:"enable_metrics"`
 	MaxChannels       int           `json:"max_channels"`
+
+	// Browser origins allowed to connect besides the API's own host; "*"
+	// allows any. Clients that send no Origin, such as mobile apps, are
+	// always allowed.
+	AllowedOrigins []string `json:"allowed_origins"`
 }
 
 // DefaultHubConfig returns default hub configuration
@@ -141,6 +161,30 @@ func NewHub(config *HubConfig) *Hub {
 	return hub
 }
 
+// checkOrigin allows handshakes without an Origin header, from the API's
+// own host, or from an allowed origin
+func (h *Hub) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(parsed.Host, r.Host) {
+		return true
+	}
+
+	for _, allowed := range h.config.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
 // SetMessageHandler sets the message handler
 func (h *Hub) SetMessageHandler(handler MessageHandlerInterface) {
 	h.messageHandler = handler
@@ -151,6 +195,11 @@ func (h *Hub) SetNotificationHandler(handler NotificationHandlerInterface) {
 	h.notificationHandler = handler
 }
 
+// SetPresenceListener sets the listener for user connection transitions
+func (h *Hub) SetPresenceListener(listener PresenceListener) {
+	h.presenceListener = listener
+}
+
 // Run starts the hub's main loop
 func (h *Hub) Run() {
 	log.Println("WebSocket Hub started")
@@ -243,11 +292,16 @@ func (h *Hub) registerClient(client *Client) {
 	h.clients[client] = true
 
 	// Add to user clients map
+	firstSession := len(h.userClients[client.UserID]) == 0
 	if h.userClients[client.UserID] == nil {
 		h.userClients[client.UserID] = make(map[*Client]bool)
 	}
 	h.userClients[client.UserID][client] = true
 
+	if firstSession && h.presenceListener != nil {
+		go h.presenceListener.UserConnected(client.UserID)
+	}
+
 	// Update stats
 	h.stats.mutex.Lock()
 	h.stats.ConnectedClients++
@@ -288,6 +342,9 @@ func (h *Hub) unregisterClient(client *Client) {
 			delete(userClientMap, client)
 			if len(userClientMap) == 0 {
 				delete(h.userClients, client.UserID)
+				if h.presenceListener != nil {
+					go h.presenceListener.UserDisconnected(client.UserID)
+				}
 			}
 		}
 
@@ -439,6 +496,40 @@ func (h *Hub) BroadcastToChannel(channel string, message WebSocketMessage, exclu
 	h.broadcast <- broadcastMsg
 }
 
+// BroadcastNewMessage sends a new message to the conversation's participants
+// other than its sender. Those who haven't accepted the conversation's
+// message request get it with its media withheld and media_hidden set.
+func (h *Hub) BroadcastNewMessage(conversation *models.Conversation, senderID primitive.ObjectID, message WebSocketMessage) {
+	var recipients, requested []string
+	for _, participantID := range conversation.Participants {
+		switch {
+		case participantID == senderID:
+		case conversation.IsPendingRequest(participantID):
+			requested = append(requested, participantID.Hex())
+		default:
+			recipients = append(recipients, participantID.Hex())
+		}
+	}
+
+	if len(recipients) > 0 {
+		h.BroadcastToUsers(recipients, message, senderID.Hex())
+	}
+	if len(requested) == 0 {
+		return
+	}
+
+	withheld := message
+	withheld.Data = make(map[string]interface{}, len(message.Data)+1)
+	for key, value := range message.Data {
+		withheld.Data[key] = value
+	}
+	if media, ok := message.Data["media"].([]models.MediaInfo); ok && len(media) > 0 {
+		withheld.Data["media"] = nil
+		withheld.Data["media_hidden"] = true
+	}
+	h.BroadcastToUsers(requested, withheld, senderID.Hex())
+}
+
 // Internal broadcasting methods
 
 // broadcastToAll broadcasts to all clients
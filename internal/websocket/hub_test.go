@@ -0,0 +1,89 @@
+package websocket
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"social-media-api/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHubCheckOrigin(t *testing.T) {
+	config := DefaultHubConfig()
+	config.AllowedOrigins = []string{"https://app.example.com/"}
+	hub := NewHub(config)
+
+	tests := []struct {
+		name   string
+		origin string
+		want   bool
+	}{
+		{"no origin", "", true},
+		{"same host", "https://api.example.com", true},
+		{"allowed origin", "https://app.example.com", true},
+		{"allowed origin in another case", "https://APP.example.com", true},
+		{"other site", "https://evil.example.net", false},
+		{"allowed host on another scheme", "http://app.example.com", false},
+		{"malformed origin", "://", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "https://api.example.com/api/v1/messages/ws", nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if got := hub.checkOrigin(r); got != tt.want {
+				t.Errorf("checkOrigin(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+
+	r := httptest.NewRequest("GET", "/ws", nil)
+	r.Header.Set("Origin", "https://evil.example.net")
+	if !NewHub(&HubConfig{AllowedOrigins: []string{"*"}}).checkOrigin(r) {
+		t.Error("a hub allowing any origin refused one")
+	}
+}
+
+func TestBroadcastNewMessageWithholdsRequestMedia(t *testing.T) {
+	hub := NewHub(nil)
+	sender, member, requested := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	conversation := &models.Conversation{
+		Participants: []primitive.ObjectID{sender, member, requested},
+		ParticipantInfo: []models.ConversationParticipant{
+			{UserID: sender},
+			{UserID: member},
+			{UserID: requested, RequestStatus: models.ConversationRequestPending},
+		},
+	}
+	media := []models.MediaInfo{{URL: "https://cdn.example.com/photo.jpg", Type: "image"}}
+
+	hub.BroadcastNewMessage(conversation, sender, WebSocketMessage{
+		Type:   "message",
+		Action: "new",
+		Data:   map[string]interface{}{"content": "look", "media": media},
+	})
+
+	got := make(map[primitive.ObjectID]WebSocketMessage)
+	for len(hub.broadcast) > 0 {
+		broadcast := <-hub.broadcast
+		for _, userID := range broadcast.UserIDs {
+			got[userID] = broadcast.Message
+		}
+	}
+
+	if _, ok := got[sender]; ok {
+		t.Error("the sender was sent their own message")
+	}
+	if full, ok := got[member]; !ok || full.Data["media"] == nil || full.Data["media_hidden"] == true {
+		t.Errorf("member got %+v, want the media", full.Data)
+	}
+	if withheld, ok := got[requested]; !ok || withheld.Data["media"] != nil || withheld.Data["media_hidden"] != true {
+		t.Errorf("pending recipient got %+v, want the media hidden", withheld.Data)
+	}
+	if withheld := got[requested]; withheld.Data["content"] != "look" {
+		t.Errorf("pending recipient got content %v, want the text", withheld.Data["content"])
+	}
+}
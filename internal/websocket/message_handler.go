@@ -3,12 +3,18 @@ package websocket
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"social-media-api/internal/models"
+	"social-media-api/internal/repository"
+	"social-media-api/internal/utils"
 
+	"github.com/go-playground/validator/v10"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -26,10 +32,28 @@ type MessageHandler struct {
 	conversationsColl *mongo.Collection
 	messagesColl      *mongo.Collection
 	hub               *Hub
+	linkScreener      LinkScreener
+	messageService    MessageService
+	validator         *validator.Validate
 	typingIndicators  map[string]map[primitive.ObjectID]time.Time // conversationID -> userID -> lastTypingTime
 	typingMutex       map[string]*typingMutex
 }
 
+// LinkScreener refuses message content that links to blocklisted domains
+type LinkScreener interface {
+	ScreenMessage(ctx context.Context, senderID primitive.ObjectID, content string) error
+}
+
+// MessageService sends, forwards, reads and marks messages with the checks
+// the REST API applies: blocks, send permissions, message requests, media
+// and link screening, and daily limits
+type MessageService interface {
+	SendMessage(senderID, conversationID primitive.ObjectID, req models.CreateMessageRequest) (*models.Message, error)
+	ForwardMessage(userID, messageID primitive.ObjectID, targetConversationIDs []primitive.ObjectID, delegateID *primitive.ObjectID) ([]models.Message, error)
+	MarkMessagesAsRead(conversationID, userID, lastMessageID primitive.ObjectID) (bool, error)
+	GetConversationMessages(conversationID, userID primitive.ObjectID, limit, skip int) ([]models.Message, error)
+}
+
 type typingMutex struct {
 	indicators map[primitive.ObjectID]time.Time
 }
@@ -41,11 +65,35 @@ func NewMessageHandler(db *mongo.Database, hub *Hub) *MessageHandler {
 		conversationsColl: db.Collection("conversations"),
 		messagesColl:      db.Collection("messages"),
 		hub:               hub,
+		validator:         validator.New(),
 		typingIndicators:  make(map[string]map[primitive.ObjectID]time.Time),
 		typingMutex:       make(map[string]*typingMutex),
 	}
 }
 
+// SetLinkScreener sets the screener sent and edited messages go through
+func (h *MessageHandler) SetLinkScreener(screener LinkScreener) {
+	h.linkScreener = screener
+}
+
+// SetMessageService sets the service sending, forwarding, reading and
+// marking messages goes through. Those actions are refused until it is set.
+func (h *MessageHandler) SetMessageService(service MessageService) {
+	h.messageService = service
+}
+
+// screenLinks refuses content linking to blocklisted domains
+func (h *MessageHandler) screenLinks(senderID primitive.ObjectID, content string) error {
+	if h.linkScreener == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return h.linkScreener.ScreenMessage(ctx, senderID, content)
+}
+
 // HandleMessage handles incoming WebSocket messages related to messaging
 func (h *MessageHandler) HandleMessage(client *Client, message WebSocketMessage) error {
 	switch message.Action {
@@ -76,86 +124,44 @@ func (h *MessageHandler) HandleMessage(client *Client, message WebSocketMessage)
 
 // handleSendMessage handles sending a new message
 func (h *MessageHandler) handleSendMessage(client *Client, wsMessage WebSocketMessage) error {
+	if h.messageService == nil {
+		return h.sendError(client, wsMessage.RequestID, "SERVICE_UNAVAILABLE", "Sending messages over WebSocket is not available")
+	}
+
 	// Parse message data
 	conversationID, ok := wsMessage.Data["conversation_id"].(string)
 	if !ok {
 		return h.sendError(client, wsMessage.RequestID, "INVALID_DATA", "Missing conversation_id")
 	}
 
-	content, ok := wsMessage.Data["content"].(string)
-	if !ok || content == "" {
-		return h.sendError(client, wsMessage.RequestID, "INVALID_DATA", "Missing content")
-	}
-
-	contentType, ok := wsMessage.Data["content_type"].(string)
-	if !ok {
-		contentType = "text"
-	}
-
 	// Validate conversation ID
 	conversationObjectID, err := primitive.ObjectIDFromHex(conversationID)
 	if err != nil {
 		return h.sendError(client, wsMessage.RequestID, "INVALID_CONVERSATION", "Invalid conversation ID")
 	}
 
-	// Check if user is participant in the conversation
-	if !h.isUserInConversation(client.UserID, conversationObjectID) {
-		return h.sendError(client, wsMessage.RequestID, "UNAUTHORIZED", "User not in conversation")
+	var req models.CreateMessageRequest
+	if err := decodeData(wsMessage.Data, &req); err != nil {
+		return h.sendError(client, wsMessage.RequestID, "INVALID_DATA", "Invalid message data")
 	}
-
-	// Create message
-	newMessage := models.Message{
-		ConversationID: conversationObjectID,
-		SenderID:       client.UserID,
-		Content:        content,
-		ContentType:    models.ContentType(contentType),
-		Status:         models.MessageSent,
-		Source:         "websocket",
-	}
-
-	// Handle media if present
-	if mediaData, exists := wsMessage.Data["media"]; exists {
-		if mediaArray, ok := mediaData.([]interface{}); ok {
-			media := make([]models.MediaInfo, 0, len(mediaArray))
-			for _, item := range mediaArray {
-				if mediaMap, ok := item.(map[string]interface{}); ok {
-					mediaInfo := models.MediaInfo{
-						URL:  getStringFromMap(mediaMap, "url"),
-						Type: getStringFromMap(mediaMap, "type"),
-					}
-					media = append(media, mediaInfo)
-				}
-			}
-			newMessage.Media = media
-		}
+	if req.ContentType == "" {
+		req.ContentType = models.ContentTypeText
 	}
-
-	// Handle reply
-	if replyToID, exists := wsMessage.Data["reply_to_message_id"].(string); exists && replyToID != "" {
-		if replyObjectID, err := primitive.ObjectIDFromHex(replyToID); err == nil {
-			newMessage.ReplyToMessageID = &replyObjectID
-		}
+	if err := h.validator.Struct(req); err != nil {
+		return h.sendError(client, wsMessage.RequestID, "INVALID_DATA", "Invalid message data")
 	}
-
-	// Set timestamps
-	newMessage.BeforeCreate()
-	now := time.Now()
-	newMessage.SentAt = &now
-
-	// Insert message into database
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	result, err := h.messagesColl.InsertOne(ctx, newMessage)
-	if err != nil {
-		return h.sendError(client, wsMessage.RequestID, "DATABASE_ERROR", "Failed to save message")
+	if strings.TrimSpace(req.Content) == "" && len(req.Media) == 0 {
+		return h.sendError(client, wsMessage.RequestID, "INVALID_DATA", "Message content or media is required")
+	}
+	if len(req.Content) > utils.MaxPostContentLength {
+		return h.sendError(client, wsMessage.RequestID, "INVALID_DATA", "Message content exceeds maximum length")
 	}
 
-	newMessage.ID = result.InsertedID.(primitive.ObjectID)
-
-	// Update conversation last message
-	if err := h.updateConversationLastMessage(conversationObjectID, newMessage); err != nil {
-		log.Printf("Failed to update conversation last message: %v", err)
+	// The service applies the same checks as sending over the REST API
+	req.DelegateID = client.DelegateID
+	newMessage, err := h.messageService.SendMessage(client.UserID, conversationObjectID, req)
+	if err != nil {
+		return h.sendServiceError(client, wsMessage.RequestID, err, "Failed to save message")
 	}
 
 	// Create WebSocket message for broadcast
@@ -186,7 +192,7 @@ func (h *MessageHandler) handleSendMessage(client *Client, wsMessage WebSocketMe
 	}
 
 	// Broadcast to conversation participants
-	h.broadcastToConversationParticipants(conversationObjectID, broadcastMessage, client.UserID)
+	h.broadcastNewMessage(conversationObjectID, broadcastMessage, client.UserID)
 
 	// Send confirmation to sender
 	confirmMessage := WebSocketMessage{
@@ -220,6 +226,10 @@ func (h *MessageHandler) handleEditMessage(client *Client, wsMessage WebSocketMe
 		return h.sendError(client, wsMessage.RequestID, "INVALID_DATA", "Missing content")
 	}
 
+	if err := h.screenLinks(client.UserID, newContent); err != nil {
+		return h.sendError(client, wsMessage.RequestID, "BLOCKED_LINK", "Message contains a blocked link")
+	}
+
 	// Validate message ID
 	messageObjectID, err := primitive.ObjectIDFromHex(messageID)
 	if err != nil {
@@ -231,11 +241,10 @@ func (h *MessageHandler) handleEditMessage(client *Client, wsMessage WebSocketMe
 	defer cancel()
 
 	var message models.Message
-	err = h.messagesColl.FindOne(ctx, bson.M{
-		"_id":        messageObjectID,
-		"sender_id":  client.UserID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&message)
+	err = h.messagesColl.FindOne(ctx, repository.NotDeleted(bson.M{
+		"_id":       messageObjectID,
+		"sender_id": client.UserID,
+	})).Decode(&message)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -297,10 +306,7 @@ func (h *MessageHandler) handleDeleteMessage(client *Client, wsMessage WebSocket
 	defer cancel()
 
 	var message models.Message
-	err = h.messagesColl.FindOne(ctx, bson.M{
-		"_id":        messageObjectID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&message)
+	err = h.messagesColl.FindOne(ctx, repository.ByID(messageObjectID)).Decode(&message)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -362,6 +368,14 @@ func (h *MessageHandler) handleReactToMessage(client *Client, wsMessage WebSocke
 		action = "add"
 	}
 
+	// Removing a retired reaction is allowed, adding one is not
+	if action == "add" && !models.IsReactionAvailable(models.ReactionType(reactionType), time.Now()) {
+		return h.sendError(client, wsMessage.RequestID, "INVALID_DATA", "Invalid reaction_type")
+	}
+	if action != "add" && !models.IsValidReactionType(models.ReactionType(reactionType)) {
+		return h.sendError(client, wsMessage.RequestID, "INVALID_DATA", "Invalid reaction_type")
+	}
+
 	// Validate message ID
 	messageObjectID, err := primitive.ObjectIDFromHex(messageID)
 	if err != nil {
@@ -373,10 +387,7 @@ func (h *MessageHandler) handleReactToMessage(client *Client, wsMessage WebSocke
 	defer cancel()
 
 	var message models.Message
-	err = h.messagesColl.FindOne(ctx, bson.M{
-		"_id":        messageObjectID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&message)
+	err = h.messagesColl.FindOne(ctx, repository.ByID(messageObjectID)).Decode(&message)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -433,6 +444,10 @@ func (h *MessageHandler) handleReactToMessage(client *Client, wsMessage WebSocke
 
 // handleMarkAsRead handles marking messages as read
 func (h *MessageHandler) handleMarkAsRead(client *Client, wsMessage WebSocketMessage) error {
+	if h.messageService == nil {
+		return h.sendError(client, wsMessage.RequestID, "SERVICE_UNAVAILABLE", "Read receipts over WebSocket are not available")
+	}
+
 	conversationID, ok := wsMessage.Data["conversation_id"].(string)
 	if !ok {
 		return h.sendError(client, wsMessage.RequestID, "INVALID_DATA", "Missing conversation_id")
@@ -454,43 +469,14 @@ func (h *MessageHandler) handleMarkAsRead(client *Client, wsMessage WebSocketMes
 		return h.sendError(client, wsMessage.RequestID, "INVALID_MESSAGE", "Invalid message ID")
 	}
 
-	// Check if user is in conversation
-	if !h.isUserInConversation(client.UserID, conversationObjectID) {
-		return h.sendError(client, wsMessage.RequestID, "UNAUTHORIZED", "User not in conversation")
-	}
-
-	// Mark messages as read
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	now := time.Now()
-	readReceipt := models.MessageReadReceipt{
-		UserID: client.UserID,
-		ReadAt: now,
-	}
-
-	// Update messages with read receipt
-	filter := bson.M{
-		"conversation_id": conversationObjectID,
-		"_id":             bson.M{"$lte": lastMessageObjectID},
-		"sender_id":       bson.M{"$ne": client.UserID}, // Don't mark own messages
-		"status":          bson.M{"$ne": models.MessageRead},
-	}
-
-	update := bson.M{
-		"$set": bson.M{
-			"status":     models.MessageRead,
-			"read_at":    now,
-			"updated_at": now,
-		},
-		"$addToSet": bson.M{
-			"read_by": readReceipt,
-		},
+	recorded, err := h.messageService.MarkMessagesAsRead(conversationObjectID, client.UserID, lastMessageObjectID)
+	if err != nil {
+		return h.sendServiceError(client, wsMessage.RequestID, err, "Failed to mark messages as read")
 	}
 
-	_, err = h.messagesColl.UpdateMany(ctx, filter, update)
-	if err != nil {
-		return h.sendError(client, wsMessage.RequestID, "DATABASE_ERROR", "Failed to mark messages as read")
+	// Senders of a message request don't see it read until it's accepted
+	if !recorded {
+		return nil
 	}
 
 	// Broadcast read receipt to conversation participants
@@ -503,7 +489,7 @@ func (h *MessageHandler) handleMarkAsRead(client *Client, wsMessage WebSocketMes
 			"last_message_id": lastMessageID,
 			"user_id":         client.UserID.Hex(),
 			"username":        client.Username,
-			"read_at":         now,
+			"read_at":         time.Now(),
 		},
 	}
 
@@ -585,9 +571,12 @@ func (h *MessageHandler) handleTypingStop(client *Client, wsMessage WebSocketMes
 	return nil
 }
 
-// handleGetMessages handles fetching messages for a conversation
 // handleGetMessages handles fetching messages for a conversation
 func (h *MessageHandler) handleGetMessages(client *Client, wsMessage WebSocketMessage) error {
+	if h.messageService == nil {
+		return h.sendError(client, wsMessage.RequestID, "SERVICE_UNAVAILABLE", "Fetching messages over WebSocket is not available")
+	}
+
 	conversationID, ok := wsMessage.Data["conversation_id"].(string)
 	if !ok {
 		return h.sendError(client, wsMessage.RequestID, "INVALID_DATA", "Missing conversation_id")
@@ -599,48 +588,25 @@ func (h *MessageHandler) handleGetMessages(client *Client, wsMessage WebSocketMe
 		return h.sendError(client, wsMessage.RequestID, "INVALID_CONVERSATION", "Invalid conversation ID")
 	}
 
-	// Check if user is in conversation
-	if !h.isUserInConversation(client.UserID, conversationObjectID) {
-		return h.sendError(client, wsMessage.RequestID, "UNAUTHORIZED", "User not in conversation")
-	}
-
 	// Get pagination parameters
-	page := int64(1)
-	if pageData, exists := wsMessage.Data["page"]; exists {
-		if pageFloat, ok := pageData.(float64); ok {
-			page = int64(pageFloat)
-		}
+	page := 1
+	if pageFloat, ok := wsMessage.Data["page"].(float64); ok && pageFloat >= 1 {
+		page = int(pageFloat)
 	}
 
-	limit := int64(20)
-	if limitData, exists := wsMessage.Data["limit"]; exists {
-		if limitFloat, ok := limitData.(float64); ok {
-			limit = int64(limitFloat)
-		}
+	limit := utils.DefaultPageSize
+	if limitFloat, ok := wsMessage.Data["limit"].(float64); ok && limitFloat >= 1 {
+		limit = int(limitFloat)
 	}
-
-	// Fetch messages
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	skip := (page - 1) * limit
-	cursor, err := h.messagesColl.Find(ctx, bson.M{
-		"conversation_id": conversationObjectID,
-		"deleted_at":      bson.M{"$exists": false},
-	}, &options.FindOptions{
-		Sort:  bson.D{{Key: "created_at", Value: -1}},
-		Skip:  &skip,
-		Limit: &limit,
-	})
-
-	if err != nil {
-		return h.sendError(client, wsMessage.RequestID, "DATABASE_ERROR", "Failed to fetch messages")
+	if limit > utils.MaxPageSize {
+		limit = utils.MaxPageSize
 	}
-	defer cursor.Close(ctx)
 
-	var messages []models.Message
-	if err := cursor.All(ctx, &messages); err != nil {
-		return h.sendError(client, wsMessage.RequestID, "DATABASE_ERROR", "Failed to decode messages")
+	// The service withholds what the user may not see yet, such as the
+	// media of message requests
+	messages, err := h.messageService.GetConversationMessages(conversationObjectID, client.UserID, limit, (page-1)*limit)
+	if err != nil {
+		return h.sendServiceError(client, wsMessage.RequestID, err, "Failed to fetch messages")
 	}
 
 	// Convert to response format
@@ -653,6 +619,7 @@ func (h *MessageHandler) handleGetMessages(client *Client, wsMessage WebSocketMe
 			"content":         msg.Content,
 			"content_type":    msg.ContentType,
 			"media":           msg.Media,
+			"media_hidden":    msg.MediaHidden,
 			"status":          msg.Status,
 			"sent_at":         msg.SentAt,
 			"created_at":      msg.CreatedAt,
@@ -675,7 +642,7 @@ func (h *MessageHandler) handleGetMessages(client *Client, wsMessage WebSocketMe
 			"messages":        messageResponses,
 			"page":            page,
 			"limit":           limit,
-			"has_more":        len(messages) == int(limit),
+			"has_more":        len(messages) == limit,
 		},
 	}
 
@@ -760,6 +727,10 @@ func (h *MessageHandler) handleSearchMessages(client *Client, wsMessage WebSocke
 
 // handleForwardMessage handles forwarding a message to other conversations
 func (h *MessageHandler) handleForwardMessage(client *Client, wsMessage WebSocketMessage) error {
+	if h.messageService == nil {
+		return h.sendError(client, wsMessage.RequestID, "SERVICE_UNAVAILABLE", "Forwarding messages over WebSocket is not available")
+	}
+
 	messageID, ok := wsMessage.Data["message_id"].(string)
 	if !ok {
 		return h.sendError(client, wsMessage.RequestID, "INVALID_DATA", "Missing message_id")
@@ -769,88 +740,55 @@ func (h *MessageHandler) handleForwardMessage(client *Client, wsMessage WebSocke
 	if !ok || len(targetConversationIDs) == 0 {
 		return h.sendError(client, wsMessage.RequestID, "INVALID_DATA", "Missing target conversations")
 	}
+	if len(targetConversationIDs) > utils.MaxForwardTargets {
+		return h.sendError(client, wsMessage.RequestID, "INVALID_DATA", fmt.Sprintf("Cannot forward to more than %d conversations", utils.MaxForwardTargets))
+	}
 
-	// Validate message ID
+	// Validate IDs
 	messageObjectID, err := primitive.ObjectIDFromHex(messageID)
 	if err != nil {
 		return h.sendError(client, wsMessage.RequestID, "INVALID_MESSAGE", "Invalid message ID")
 	}
 
-	// Get original message
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	var originalMessage models.Message
-	err = h.messagesColl.FindOne(ctx, bson.M{
-		"_id":        messageObjectID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&originalMessage)
-
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return h.sendError(client, wsMessage.RequestID, "MESSAGE_NOT_FOUND", "Message not found")
+	targets := make([]primitive.ObjectID, 0, len(targetConversationIDs))
+	for _, targetID := range targetConversationIDs {
+		targetIDStr, _ := targetID.(string)
+		targetObjectID, err := primitive.ObjectIDFromHex(targetIDStr)
+		if err != nil {
+			return h.sendError(client, wsMessage.RequestID, "INVALID_CONVERSATION", "Invalid conversation ID")
 		}
-		return h.sendError(client, wsMessage.RequestID, "DATABASE_ERROR", "Failed to fetch message")
+		targets = append(targets, targetObjectID)
 	}
 
-	// Check if user can access original message
-	if !h.isUserInConversation(client.UserID, originalMessage.ConversationID) {
-		return h.sendError(client, wsMessage.RequestID, "UNAUTHORIZED", "Cannot access original message")
-	}
-
-	// Forward to each target conversation
-	successCount := 0
-	for _, targetID := range targetConversationIDs {
-		if targetIDStr, ok := targetID.(string); ok {
-			if targetObjectID, err := primitive.ObjectIDFromHex(targetIDStr); err == nil {
-				// Check if user is in target conversation
-				if h.isUserInConversation(client.UserID, targetObjectID) {
-					// Create forwarded message
-					forwardedMessage := models.Message{
-						ConversationID: targetObjectID,
-						SenderID:       client.UserID,
-						Content:        originalMessage.Content,
-						ContentType:    originalMessage.ContentType,
-						Media:          originalMessage.Media,
-						IsForwarded:    true,
-						ForwardedFrom:  &originalMessage.ID,
-						Status:         models.MessageSent,
-						Source:         "websocket",
-					}
-
-					forwardedMessage.BeforeCreate()
-					now := time.Now()
-					forwardedMessage.SentAt = &now
-
-					// Insert forwarded message
-					if result, err := h.messagesColl.InsertOne(ctx, forwardedMessage); err == nil {
-						forwardedMessage.ID = result.InsertedID.(primitive.ObjectID)
-						successCount++
-
-						// Broadcast to target conversation
-						broadcastMessage := WebSocketMessage{
-							Type:    "message",
-							Action:  "new",
-							Channel: "conversation:" + targetIDStr,
-							Data: map[string]interface{}{
-								"id":              forwardedMessage.ID.Hex(),
-								"conversation_id": targetIDStr,
-								"sender_id":       client.UserID.Hex(),
-								"content":         forwardedMessage.Content,
-								"content_type":    forwardedMessage.ContentType,
-								"media":           forwardedMessage.Media,
-								"is_forwarded":    true,
-								"forwarded_from":  originalMessage.ID.Hex(),
-								"sent_at":         forwardedMessage.SentAt,
-								"created_at":      forwardedMessage.CreatedAt,
-							},
-						}
-
-						h.broadcastToConversationParticipants(targetObjectID, broadcastMessage, client.UserID)
-					}
-				}
-			}
+	// The service checks access to the message and every target
+	forwarded, err := h.messageService.ForwardMessage(client.UserID, messageObjectID, targets, client.DelegateID)
+	if err != nil {
+		return h.sendServiceError(client, wsMessage.RequestID, err, "Failed to forward message")
+	}
+
+	for _, forwardedMessage := range forwarded {
+		targetIDStr := forwardedMessage.ConversationID.Hex()
+
+		// Broadcast to target conversation
+		broadcastMessage := WebSocketMessage{
+			Type:    "message",
+			Action:  "new",
+			Channel: "conversation:" + targetIDStr,
+			Data: map[string]interface{}{
+				"id":              forwardedMessage.ID.Hex(),
+				"conversation_id": targetIDStr,
+				"sender_id":       client.UserID.Hex(),
+				"content":         forwardedMessage.Content,
+				"content_type":    forwardedMessage.ContentType,
+				"media":           forwardedMessage.Media,
+				"is_forwarded":    true,
+				"forwarded_from":  forwardedMessage.ForwardedFrom.Hex(),
+				"sent_at":         forwardedMessage.SentAt,
+				"created_at":      forwardedMessage.CreatedAt,
+			},
 		}
+
+		h.broadcastNewMessage(forwardedMessage.ConversationID, broadcastMessage, client.UserID)
 	}
 
 	// Send response
@@ -860,7 +798,7 @@ func (h *MessageHandler) handleForwardMessage(client *Client, wsMessage WebSocke
 		RequestID: wsMessage.RequestID,
 		Data: map[string]interface{}{
 			"message_id":    messageID,
-			"success_count": successCount,
+			"success_count": len(forwarded),
 			"total_targets": len(targetConversationIDs),
 		},
 	}
@@ -883,16 +821,40 @@ func (h *MessageHandler) sendError(client *Client, requestID, errorCode, message
 	return client.SendMessage(errorMessage)
 }
 
+// sendServiceError reports an error from the message service with the code
+// the REST API would answer it with, falling back to message
+func (h *MessageHandler) sendServiceError(client *Client, requestID string, err error, message string) error {
+	var mediaErr *models.MediaRejectedError
+	var limitErr *models.LimitExceededError
+	var maturityErr *models.AccountTooNewError
+
+	switch {
+	case errors.As(err, &mediaErr):
+		return h.sendError(client, requestID, "MEDIA_REJECTED", mediaErr.Error())
+	case errors.As(err, &limitErr):
+		return h.sendError(client, requestID, "LIMIT_EXCEEDED", limitErr.Error())
+	case errors.As(err, &maturityErr):
+		return h.sendError(client, requestID, "ACCOUNT_TOO_NEW", maturityErr.Error())
+	case strings.Contains(err.Error(), "blocked link"):
+		return h.sendError(client, requestID, "BLOCKED_LINK", "Message contains a blocked link")
+	case strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "access denied"):
+		return h.sendError(client, requestID, "UNAUTHORIZED", "Conversation not found or access denied")
+	case strings.Contains(err.Error(), "cannot") || strings.Contains(err.Error(), "required") || strings.Contains(err.Error(), "expired"):
+		return h.sendError(client, requestID, "INVALID_DATA", err.Error())
+	default:
+		return h.sendError(client, requestID, "DATABASE_ERROR", message)
+	}
+}
+
 // isUserInConversation checks if a user is a participant in a conversation
 func (h *MessageHandler) isUserInConversation(userID, conversationID primitive.ObjectID) bool {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	count, err := h.conversationsColl.CountDocuments(ctx, bson.M{
+	count, err := h.conversationsColl.CountDocuments(ctx, repository.NotDeleted(bson.M{
 		"_id":          conversationID,
 		"participants": userID,
-		"deleted_at":   bson.M{"$exists": false},
-	})
+	}))
 
 	return err == nil && count > 0
 }
@@ -902,11 +864,10 @@ func (h *MessageHandler) isConversationAdmin(userID, conversationID primitive.Ob
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	count, err := h.conversationsColl.CountDocuments(ctx, bson.M{
-		"_id":        conversationID,
-		"admin_ids":  userID,
-		"deleted_at": bson.M{"$exists": false},
-	})
+	count, err := h.conversationsColl.CountDocuments(ctx, repository.NotDeleted(bson.M{
+		"_id":       conversationID,
+		"admin_ids": userID,
+	}))
 
 	return err == nil && count > 0
 }
@@ -917,10 +878,7 @@ func (h *MessageHandler) getConversationParticipants(conversationID primitive.Ob
 	defer cancel()
 
 	var conversation models.Conversation
-	err := h.conversationsColl.FindOne(ctx, bson.M{
-		"_id":        conversationID,
-		"deleted_at": bson.M{"$exists": false},
-	}).Decode(&conversation)
+	err := h.conversationsColl.FindOne(ctx, repository.ByID(conversationID)).Decode(&conversation)
 
 	if err != nil {
 		return nil, err
@@ -949,31 +907,20 @@ func (h *MessageHandler) broadcastToConversationParticipants(conversationID prim
 	}
 }
 
-// updateConversationLastMessage updates the last message info in conversation
-func (h *MessageHandler) updateConversationLastMessage(conversationID primitive.ObjectID, message models.Message) error {
+// broadcastNewMessage broadcasts a new message to the other participants,
+// withholding its media from those yet to accept the message request
+func (h *MessageHandler) broadcastNewMessage(conversationID primitive.ObjectID, message WebSocketMessage, senderID primitive.ObjectID) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	preview := message.Content
-	if len(preview) > 100 {
-		preview = preview[:97] + "..."
-	}
-
-	update := bson.M{
-		"$set": bson.M{
-			"last_message_id":      message.ID,
-			"last_message_at":      message.CreatedAt,
-			"last_message_preview": preview,
-			"last_activity_at":     message.CreatedAt,
-			"updated_at":           time.Now(),
-		},
-		"$inc": bson.M{
-			"messages_count": 1,
-		},
+	var conversation models.Conversation
+	opts := options.FindOne().SetProjection(bson.M{"participants": 1, "participant_info": 1})
+	if err := h.conversationsColl.FindOne(ctx, repository.ByID(conversationID), opts).Decode(&conversation); err != nil {
+		log.Printf("Failed to get conversation participants: %v", err)
+		return
 	}
 
-	_, err := h.conversationsColl.UpdateOne(ctx, bson.M{"_id": conversationID}, update)
-	return err
+	h.hub.BroadcastNewMessage(&conversation, senderID, message)
 }
 
 // Typing indicator management
@@ -1023,12 +970,11 @@ func (h *MessageHandler) CleanupExpiredTypingIndicators() {
 
 // Utility functions
 
-// getStringFromMap safely gets a string value from a map
-func getStringFromMap(m map[string]interface{}, key string) string {
-	if val, exists := m[key]; exists {
-		if str, ok := val.(string); ok {
-			return str
-		}
+// decodeData decodes a message's data into a request struct by its JSON tags
+func decodeData(data map[string]interface{}, v interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
 	}
-	return ""
+	return json.Unmarshal(raw, v)
 }
@@ -667,6 +667,8 @@ func (h *NotificationHandler) getDisplayProperties(notifType models.Notification
 		return "❤️", "#E41E3F"
 	case models.NotificationComment:
 		return "💬", "#42B883"
+	case models.NotificationQuickReply:
+		return "🔥", "#F43F5E"
 	case models.NotificationFollow:
 		return "👤", "#8B5CF6"
 	case models.NotificationMessage:
@@ -687,6 +689,18 @@ func (h *NotificationHandler) getDisplayProperties(notifType models.Notification
 		return "📝", "#F97316"
 	case models.NotificationEventReminder:
 		return "⏰", "#D97706"
+	case models.NotificationDelegate:
+		return "🔑", "#0EA5E9"
+	case models.NotificationPhotoTag:
+		return "🏷️", "#EC4899"
+	case models.NotificationStoryScreenshot:
+		return "📸", "#EC4899"
+	case models.NotificationMediaRejected:
+		return "🛡️", "#DC2626"
+	case models.NotificationSavedSearch:
+		return "🔍", "#3B82F6"
+	case models.NotificationSupportAccess:
+		return "🛟", "#6366F1"
 	default:
 		return "🔔", "#6B7280"
 	}
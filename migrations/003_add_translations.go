@@ -0,0 +1,59 @@
+// migrations/003_add_translations.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetTranslationsMigration returns the translations migration
+func GetTranslationsMigration() Migration {
+	return Migration{
+		ID:          "003_add_translations",
+		Description: "Add indexes for the translation cache and usage collections",
+		Up:          addTranslations,
+		Down:        removeTranslations,
+	}
+}
+
+func addTranslations(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding translation collections...")
+
+	translations := db.Collection("translations")
+
+	// One cached translation per content and target language
+	if err := EnsureUniqueIndex(ctx, translations, bson.D{{Key: "content_type", Value: 1}, {Key: "content_id", Value: 1}, {Key: "target_language", Value: 1}}); err != nil {
+		return err
+	}
+
+	usage := db.Collection("translation_usage")
+
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "source_language", Value: 1}, {Key: "target_language", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	}
+
+	if err := CreateIndexesSafely(ctx, usage, indexes); err != nil {
+		return err
+	}
+
+	log.Println("Translation collections added successfully")
+	return nil
+}
+
+func removeTranslations(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing translation collections...")
+
+	for _, collectionName := range []string{"translations", "translation_usage"} {
+		if _, err := db.Collection(collectionName).Indexes().DropAll(ctx); err != nil {
+			log.Printf("Warning: Failed to drop indexes for collection %s: %v", collectionName, err)
+		}
+	}
+
+	log.Println("Translation collections removed")
+	return nil
+}
@@ -0,0 +1,83 @@
+// migrations/004_add_surveys.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetSurveysMigration returns the surveys migration
+func GetSurveysMigration() Migration {
+	return Migration{
+		ID:          "004_add_surveys",
+		Description: "Add indexes for surveys and survey responses",
+		Up:          addSurveys,
+		Down:        removeSurveys,
+	}
+}
+
+func addSurveys(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding survey collections...")
+
+	surveys := db.Collection("surveys")
+
+	surveyIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "ends_at", Value: 1}}},
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+	}
+
+	if err := CreateIndexesSafely(ctx, surveys, surveyIndexes); err != nil {
+		return err
+	}
+
+	responses := db.Collection("survey_responses")
+
+	// One response per user; anonymized responses no longer carry a user ID
+	responseIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "survey_id", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"user_id": bson.M{"$exists": true}}),
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		{Keys: bson.D{{Key: "survey_id", Value: 1}, {Key: "created_at", Value: 1}}},
+	}
+
+	if err := CreateIndexesSafely(ctx, responses, responseIndexes); err != nil {
+		return err
+	}
+
+	cache := db.Collection("survey_results_cache")
+
+	cacheIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	if err := CreateIndexesSafely(ctx, cache, cacheIndexes); err != nil {
+		return err
+	}
+
+	log.Println("Survey collections added successfully")
+	return nil
+}
+
+func removeSurveys(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing survey collections...")
+
+	for _, collectionName := range []string{"surveys", "survey_responses", "survey_results_cache"} {
+		if _, err := db.Collection(collectionName).Indexes().DropAll(ctx); err != nil {
+			log.Printf("Warning: Failed to drop indexes for collection %s: %v", collectionName, err)
+		}
+	}
+
+	log.Println("Survey collections removed")
+	return nil
+}
@@ -0,0 +1,126 @@
+// migrations/005_move_embedded_arrays.go
+package migrations
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxPinnedMessages mirrors models.MaxPinnedMessages
+const maxPinnedMessages = 50
+
+// GetMoveEmbeddedArraysMigration returns the migration that moves unbounded arrays off primary documents
+func GetMoveEmbeddedArraysMigration() Migration {
+	return Migration{
+		ID:          "005_move_embedded_arrays",
+		Description: "Move blocked users from user documents into the blocked_users collection and cap pinned messages",
+		Up:          moveEmbeddedArrays,
+		Down:        restoreEmbeddedArrays,
+	}
+}
+
+func moveEmbeddedArrays(ctx context.Context, db *mongo.Database) error {
+	log.Println("Moving embedded arrays into dedicated collections...")
+
+	users := db.Collection("users")
+	blocks := db.Collection("blocked_users")
+
+	cursor, err := users.Find(ctx,
+		bson.M{"blocked_users.0": bson.M{"$exists": true}},
+		options.Find().SetProjection(bson.M{"blocked_users": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	moved := 0
+	for cursor.Next(ctx) {
+		var user struct {
+			ID           primitive.ObjectID   `bson:"_id"`
+			BlockedUsers []primitive.ObjectID `bson:"blocked_users"`
+		}
+		if err := cursor.Decode(&user); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		writes := make([]mongo.WriteModel, 0, len(user.BlockedUsers))
+		for _, blockedID := range user.BlockedUsers {
+			writes = append(writes, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"blocker_id": user.ID, "blocked_id": blockedID}).
+				SetUpdate(bson.M{
+					"$set":         bson.M{"is_active": true, "updated_at": now},
+					"$setOnInsert": bson.M{"created_at": now},
+				}).
+				SetUpsert(true))
+		}
+
+		if _, err := blocks.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+			return err
+		}
+
+		if _, err := users.UpdateOne(ctx, bson.M{"_id": user.ID}, bson.M{"$unset": bson.M{"blocked_users": ""}}); err != nil {
+			return err
+		}
+		moved += len(writes)
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	log.Printf("Moved %d blocked user entries into blocked_users", moved)
+
+	// Keep only the most recent pinned messages on oversized conversations
+	result, err := db.Collection("conversations").UpdateMany(ctx,
+		bson.M{"pinned_messages." + strconv.Itoa(maxPinnedMessages): bson.M{"$exists": true}},
+		bson.M{"$push": bson.M{"pinned_messages": bson.M{"$each": bson.A{}, "$slice": -maxPinnedMessages}}})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Capped pinned messages on %d conversations", result.ModifiedCount)
+	return nil
+}
+
+func restoreEmbeddedArrays(ctx context.Context, db *mongo.Database) error {
+	log.Println("Restoring blocked users onto user documents...")
+
+	pipeline := []bson.M{
+		{"$match": bson.M{"is_active": true}},
+		{"$group": bson.M{"_id": "$blocker_id", "blocked": bson.M{"$push": "$blocked_id"}}},
+	}
+
+	cursor, err := db.Collection("blocked_users").Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	users := db.Collection("users")
+	for cursor.Next(ctx) {
+		var entry struct {
+			ID      primitive.ObjectID   `bson:"_id"`
+			Blocked []primitive.ObjectID `bson:"blocked"`
+		}
+		if err := cursor.Decode(&entry); err != nil {
+			return err
+		}
+
+		_, err := users.UpdateOne(ctx, bson.M{"_id": entry.ID}, bson.M{
+			"$addToSet": bson.M{"blocked_users": bson.M{"$each": entry.Blocked}},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Println("Blocked users restored")
+	return cursor.Err()
+}
@@ -0,0 +1,163 @@
+// migrations/006_add_reaction_types.go
+package migrations
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"social-media-api/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetReactionTypesMigration returns the migration that makes reactions data-driven
+func GetReactionTypesMigration() Migration {
+	return Migration{
+		ID:          "006_add_reaction_types",
+		Description: "Seed the reaction_types collection and backfill per-reaction counters on posts and comments",
+		Up:          addReactionTypes,
+		Down:        removeReactionTypes,
+	}
+}
+
+func addReactionTypes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding reaction types...")
+
+	reactionTypes := db.Collection("reaction_types")
+
+	if err := EnsureUniqueIndex(ctx, reactionTypes, bson.D{{Key: "key", Value: 1}}); err != nil {
+		return err
+	}
+
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "is_retired", Value: 1}, {Key: "display_order", Value: 1}}},
+	}
+	if err := CreateIndexesSafely(ctx, reactionTypes, indexes); err != nil {
+		return err
+	}
+
+	// Seed the built-in reactions without overwriting admin edits
+	now := time.Now()
+	var writes []mongo.WriteModel
+	for _, reaction := range models.DefaultReactionDefinitions() {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"key": reaction.Key}).
+			SetUpdate(bson.M{"$setOnInsert": bson.M{
+				"key":           reaction.Key,
+				"name":          reaction.Name,
+				"emoji":         reaction.Emoji,
+				"display_order": reaction.DisplayOrder,
+				"is_built_in":   true,
+				"is_retired":    false,
+				"created_at":    now,
+				"updated_at":    now,
+			}}).
+			SetUpsert(true))
+	}
+	if _, err := reactionTypes.BulkWrite(ctx, writes); err != nil {
+		return err
+	}
+
+	for _, targetType := range []string{"post", "comment"} {
+		if err := backfillReactionCounts(ctx, db, targetType); err != nil {
+			return err
+		}
+	}
+
+	log.Println("Reaction types added successfully")
+	return nil
+}
+
+// backfillReactionCounts rebuilds reaction_counts on posts or comments from the likes collection
+func backfillReactionCounts(ctx context.Context, db *mongo.Database, targetType string) error {
+	pipeline := []bson.M{
+		{"$match": bson.M{"target_type": targetType}},
+		{"$group": bson.M{
+			"_id":   bson.M{"target_id": "$target_id", "reaction_type": "$reaction_type"},
+			"count": bson.M{"$sum": 1},
+		}},
+		{"$group": bson.M{
+			"_id":    "$_id.target_id",
+			"counts": bson.M{"$push": bson.M{"k": "$_id.reaction_type", "v": "$count"}},
+		}},
+	}
+
+	cursor, err := db.Collection("likes").Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	collection := db.Collection(targetType + "s")
+	var writes []mongo.WriteModel
+	updated := 0
+
+	for cursor.Next(ctx) {
+		var result struct {
+			ID     primitive.ObjectID `bson:"_id"`
+			Counts []struct {
+				Key   string `bson:"k"`
+				Value int64  `bson:"v"`
+			} `bson:"counts"`
+		}
+		if err := cursor.Decode(&result); err != nil {
+			return err
+		}
+
+		counts := bson.M{}
+		for _, count := range result.Counts {
+			if count.Key == "" {
+				continue
+			}
+			counts[count.Key] = count.Value
+		}
+
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": result.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"reaction_counts": counts}}))
+
+		if len(writes) == 1000 {
+			if _, err := collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+				return err
+			}
+			updated += len(writes)
+			writes = writes[:0]
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	if len(writes) > 0 {
+		if _, err := collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+			return err
+		}
+		updated += len(writes)
+	}
+
+	log.Printf("Backfilled reaction counts on %d %ss", updated, targetType)
+	return nil
+}
+
+func removeReactionTypes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing reaction types...")
+
+	if err := db.Collection("reaction_types").Drop(ctx); err != nil {
+		log.Printf("Warning: Failed to drop reaction_types collection: %v", err)
+	}
+
+	for _, collectionName := range []string{"posts", "comments"} {
+		if _, err := db.Collection(collectionName).UpdateMany(ctx,
+			bson.M{"reaction_counts": bson.M{"$exists": true}},
+			bson.M{"$unset": bson.M{"reaction_counts": ""}}); err != nil {
+			return err
+		}
+	}
+
+	log.Println("Reaction types removed")
+	return nil
+}
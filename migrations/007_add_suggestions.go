@@ -0,0 +1,67 @@
+// migrations/007_add_suggestions.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetSuggestionsMigration returns the migration for follow suggestions
+func GetSuggestionsMigration() Migration {
+	return Migration{
+		ID:          "007_add_suggestions",
+		Description: "Add indexes for precomputed follow suggestions, dismissals and suggestion impressions",
+		Up:          addSuggestions,
+		Down:        removeSuggestions,
+	}
+}
+
+func addSuggestions(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding suggestion collections...")
+
+	if err := EnsureUniqueIndex(ctx, db.Collection("suggestions"), bson.D{{Key: "user_id", Value: 1}}); err != nil {
+		return err
+	}
+
+	// One dismissal per pair; dismissals are permanent
+	if err := EnsureUniqueIndex(ctx, db.Collection("suggestion_dismissals"),
+		bson.D{{Key: "user_id", Value: 1}, {Key: "dismissed_user_id", Value: 1}}); err != nil {
+		return err
+	}
+
+	// Candidate generation reads followees by engagement and their follows by recency
+	followIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "follower_id", Value: 1}, {Key: "status", Value: 1}, {Key: "interaction_score", Value: -1}}},
+		{Keys: bson.D{{Key: "follower_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: -1}}},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("follows"), followIndexes); err != nil {
+		return err
+	}
+
+	// Impressions are matched back to follows and dismissals
+	recommendationIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "item_id", Value: 1}, {Key: "presented", Value: -1}}},
+		{Keys: bson.D{{Key: "recommendation_type", Value: 1}, {Key: "algorithm", Value: 1}, {Key: "created_at", Value: -1}}},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("recommendation_events"), recommendationIndexes); err != nil {
+		return err
+	}
+
+	log.Println("Suggestion collections added successfully")
+	return nil
+}
+
+func removeSuggestions(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing suggestion collections...")
+
+	// Dismissals are user choices and are kept; the suggestion lists are regenerated
+	if err := db.Collection("suggestions").Drop(ctx); err != nil {
+		log.Printf("Warning: Failed to drop suggestions collection: %v", err)
+	}
+
+	log.Println("Suggestion collections removed")
+	return nil
+}
@@ -0,0 +1,61 @@
+// migrations/008_add_comment_holds.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetCommentHoldsMigration returns the migration for the comment spam hold
+func GetCommentHoldsMigration() Migration {
+	return Migration{
+		ID:          "008_add_comment_holds",
+		Description: "Add indexes for the comment spam hold review queue and per-author releases",
+		Up:          addCommentHolds,
+		Down:        removeCommentHolds,
+	}
+}
+
+func addCommentHolds(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding comment hold indexes...")
+
+	// Only comments that went through the hold carry hold_status
+	holdIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "hold_status", Value: 1}, {Key: "held_at", Value: 1}},
+			Options: options.Index().
+				SetName("hold_status_held_at").
+				SetPartialFilterExpression(bson.M{"hold_status": bson.M{"$exists": true}}),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "hold_status", Value: 1}},
+			Options: options.Index().
+				SetName("user_id_hold_status").
+				SetPartialFilterExpression(bson.M{"hold_status": bson.M{"$exists": true}}),
+		},
+	}
+
+	if err := CreateIndexesSafely(ctx, db.Collection("comments"), holdIndexes); err != nil {
+		return err
+	}
+
+	log.Println("Comment hold indexes added successfully")
+	return nil
+}
+
+func removeCommentHolds(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing comment hold indexes...")
+
+	for _, indexName := range []string{"hold_status_held_at", "user_id_hold_status"} {
+		if err := DropIndexIfExists(ctx, db.Collection("comments"), indexName); err != nil {
+			log.Printf("Warning: Failed to drop index %s: %v", indexName, err)
+		}
+	}
+
+	log.Println("Comment hold indexes removed")
+	return nil
+}
@@ -0,0 +1,62 @@
+// migrations/009_add_user_mutes.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetUserMutesMigration returns the migration for time-boxed user mutes
+func GetUserMutesMigration() Migration {
+	return Migration{
+		ID:          "009_add_user_mutes",
+		Description: "Add the user_mutes collection with a TTL index that removes expired mutes",
+		Up:          addUserMutes,
+		Down:        removeUserMutes,
+	}
+}
+
+func addUserMutes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding user mutes collection...")
+
+	mutes := db.Collection("user_mutes")
+
+	// One mute per pair; muting again replaces the expiry
+	if err := EnsureUniqueIndex(ctx, mutes, bson.D{{Key: "muter_id", Value: 1}, {Key: "muted_id", Value: 1}}); err != nil {
+		return err
+	}
+
+	muteIndexes := []mongo.IndexModel{
+		// The muted users list and the bulk notification check
+		{Keys: bson.D{{Key: "muter_id", Value: 1}, {Key: "updated_at", Value: -1}}},
+		{Keys: bson.D{{Key: "muted_id", Value: 1}, {Key: "muter_id", Value: 1}}},
+		// Mutes are filtered by expiry at read time; this cleans up the
+		// expired records. Mutes that never expire have no expires_at.
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, mutes, muteIndexes); err != nil {
+		return err
+	}
+
+	log.Println("User mutes collection added successfully")
+	return nil
+}
+
+func removeUserMutes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing user mutes indexes...")
+
+	// Mutes are user choices and are kept
+	if _, err := db.Collection("user_mutes").Indexes().DropAll(ctx); err != nil {
+		log.Printf("Warning: Failed to drop indexes for collection user_mutes: %v", err)
+	}
+
+	log.Println("User mutes indexes removed")
+	return nil
+}
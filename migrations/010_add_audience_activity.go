@@ -0,0 +1,59 @@
+// migrations/010_add_audience_activity.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetAudienceActivityMigration returns the migration for creator audience activity summaries
+func GetAudienceActivityMigration() Migration {
+	return Migration{
+		ID:          "010_add_audience_activity",
+		Description: "Add the audience_activity collection and indexes for the nightly follower activity job",
+		Up:          addAudienceActivity,
+		Down:        removeAudienceActivity,
+	}
+}
+
+func addAudienceActivity(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding audience activity collection...")
+
+	// One summary per creator, replaced nightly
+	if err := EnsureUniqueIndex(ctx, db.Collection("audience_activity"), bson.D{{Key: "user_id", Value: 1}}); err != nil {
+		return err
+	}
+
+	// The job reads a sample of followers' recent sessions and post views
+	sessionIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "start_time", Value: -1}}},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("user_sessions"), sessionIndexes); err != nil {
+		return err
+	}
+
+	engagementIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "view_time", Value: -1}}},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("content_engagements"), engagementIndexes); err != nil {
+		return err
+	}
+
+	log.Println("Audience activity collection added successfully")
+	return nil
+}
+
+func removeAudienceActivity(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing audience activity collection...")
+
+	// Summaries are regenerated by the nightly job
+	if err := db.Collection("audience_activity").Drop(ctx); err != nil {
+		log.Printf("Warning: Failed to drop collection audience_activity: %v", err)
+	}
+
+	log.Println("Audience activity collection removed")
+	return nil
+}
@@ -0,0 +1,156 @@
+// migrations/011_add_retention_ttl.go
+package migrations
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"social-media-api/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ttlIndex is a TTL index that expires a collection's documents after a retention period
+type ttlIndex struct {
+	Collection string
+	Field      string
+	Retention  time.Duration
+	// Field to copy into Field on documents written without it, so they
+	// aren't expired as soon as the index exists
+	BackfillFrom string
+}
+
+// retentionIndexes lists the TTL indexes for the configured retention periods
+func retentionIndexes(retention config.RetentionConfig) []ttlIndex {
+	day := 24 * time.Hour
+	return []ttlIndex{
+		{Collection: "user_sessions", Field: "created_at", Retention: time.Duration(retention.UserSessionsDays) * day, BackfillFrom: "start_time"},
+		{Collection: "content_engagements", Field: "created_at", Retention: time.Duration(retention.ContentEngagementsDays) * day, BackfillFrom: "view_time"},
+		{Collection: "recommendation_events", Field: "created_at", Retention: time.Duration(retention.RecommendationEventsDays) * day, BackfillFrom: "presented"},
+		// Feed cache entries carry their own expiry
+		{Collection: "feed_cache", Field: "expires_at", Retention: time.Duration(retention.FeedCacheGraceHours) * time.Hour},
+	}
+}
+
+// GetRetentionTTLMigration returns the migration that adds TTL indexes to behavior and cache collections
+func GetRetentionTTLMigration() Migration {
+	return Migration{
+		ID:          "011_add_retention_ttl",
+		Description: "Expire behavior tracking and feed cache documents with TTL indexes",
+		Up:          addRetentionTTL,
+		Down:        removeRetentionTTL,
+	}
+}
+
+func addRetentionTTL(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding retention TTL indexes...")
+
+	indexes := retentionIndexes(config.GetConfig().Retention)
+
+	// Some writers didn't set created_at, which stores year 1 and would be
+	// expired at once; give those documents the time they were recorded instead
+	unset := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, index := range indexes {
+		if index.BackfillFrom == "" {
+			continue
+		}
+		filter := bson.M{
+			"$or": []bson.M{
+				{index.Field: bson.M{"$lt": unset}},
+				{index.Field: bson.M{"$exists": false}},
+			},
+			index.BackfillFrom: bson.M{"$type": "date"},
+		}
+		result, err := db.Collection(index.Collection).UpdateMany(ctx, filter,
+			mongo.Pipeline{{{Key: "$set", Value: bson.M{index.Field: "$" + index.BackfillFrom}}}},
+		)
+		if err != nil {
+			return err
+		}
+		if result.ModifiedCount > 0 {
+			log.Printf("Set %s on %d documents in %s", index.Field, result.ModifiedCount, index.Collection)
+		}
+	}
+
+	if err := syncTTLIndexes(ctx, db, indexes); err != nil {
+		return err
+	}
+
+	log.Println("Retention TTL indexes added successfully")
+	return nil
+}
+
+func removeRetentionTTL(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing retention TTL indexes...")
+
+	// Without the TTL indexes, the cleanup-behavior command removes old data
+	for _, index := range retentionIndexes(config.GetConfig().Retention) {
+		if _, err := db.Collection(index.Collection).Indexes().DropOne(ctx, index.Field+"_1"); err != nil {
+			log.Printf("Warning: Failed to drop TTL index on %s.%s: %v", index.Collection, index.Field, err)
+		}
+	}
+
+	log.Println("Retention TTL indexes removed")
+	return nil
+}
+
+// SyncTTLIndex sets how long the TTL index on a collection's field keeps
+// documents, creating the index if needed. The migration creates the
+// retention indexes; the retention worker uses this to apply later period
+// changes and legal holds without another migration.
+func SyncTTLIndex(ctx context.Context, db *mongo.Database, collection, field string, retention time.Duration) error {
+	return ensureTTLIndex(ctx, db.Collection(collection), field, int32(retention.Seconds()))
+}
+
+func syncTTLIndexes(ctx context.Context, db *mongo.Database, indexes []ttlIndex) error {
+	for _, index := range indexes {
+		if err := ensureTTLIndex(ctx, db.Collection(index.Collection), index.Field, int32(index.Retention.Seconds())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureTTLIndex creates an ascending TTL index on the field, or changes the
+// expiry of an existing one in place
+func ensureTTLIndex(ctx context.Context, collection *mongo.Collection, field string, expireAfterSeconds int32) error {
+	specs, err := collection.Indexes().ListSpecifications(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, spec := range specs {
+		if spec.Name != field+"_1" {
+			continue
+		}
+		if spec.ExpireAfterSeconds != nil && *spec.ExpireAfterSeconds == expireAfterSeconds {
+			return nil
+		}
+		if spec.ExpireAfterSeconds != nil {
+			err := collection.Database().RunCommand(ctx, bson.D{
+				{Key: "collMod", Value: collection.Name()},
+				{Key: "index", Value: bson.D{
+					{Key: "keyPattern", Value: bson.D{{Key: field, Value: 1}}},
+					{Key: "expireAfterSeconds", Value: expireAfterSeconds},
+				}},
+			}).Err()
+			if err == nil {
+				log.Printf("Updated TTL on %s.%s to %ds", collection.Name(), field, expireAfterSeconds)
+				return nil
+			}
+			log.Printf("Failed to update TTL on %s.%s, rebuilding the index: %v", collection.Name(), field, err)
+		}
+		break
+	}
+
+	// A plain index on the field conflicts and is dropped and rebuilt
+	return CreateIndexesSafely(ctx, collection, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: field, Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(expireAfterSeconds),
+		},
+	})
+}
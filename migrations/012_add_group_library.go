@@ -0,0 +1,63 @@
+// migrations/012_add_group_library.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetGroupLibraryMigration returns the migration for group resource libraries
+func GetGroupLibraryMigration() Migration {
+	return Migration{
+		ID:          "012_add_group_library",
+		Description: "Add the group_files collection and the index used by the orphaned media cleanup job",
+		Up:          addGroupLibrary,
+		Down:        removeGroupLibrary,
+	}
+}
+
+func addGroupLibrary(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding group library collection...")
+
+	fileIndexes := []mongo.IndexModel{
+		// Folder listings and the folder summary
+		{Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "folder", Value: 1}, {Key: "created_at", Value: -1}}},
+		// Tag filters
+		{Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "tags", Value: 1}}},
+		// Whole-library listings and group deletion
+		{Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "deleted_at", Value: 1}, {Key: "created_at", Value: -1}}},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("group_files"), fileIndexes); err != nil {
+		return err
+	}
+
+	// Deleted media whose files haven't been removed yet
+	mediaIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "file_removed_at", Value: 1}, {Key: "deleted_at", Value: 1}}},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("media"), mediaIndexes); err != nil {
+		return err
+	}
+
+	log.Println("Group library collection added successfully")
+	return nil
+}
+
+func removeGroupLibrary(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing group library indexes...")
+
+	// Library entries point at uploaded files and are kept
+	if _, err := db.Collection("group_files").Indexes().DropAll(ctx); err != nil {
+		log.Printf("Warning: Failed to drop indexes for collection group_files: %v", err)
+	}
+
+	if err := DropIndexIfExists(ctx, db.Collection("media"), "file_removed_at_1_deleted_at_1"); err != nil {
+		log.Printf("Warning: Failed to drop orphan cleanup index on media: %v", err)
+	}
+
+	log.Println("Group library indexes removed")
+	return nil
+}
@@ -0,0 +1,55 @@
+// migrations/013_add_content_fingerprints.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetContentFingerprintsMigration returns the migration for duplicate post detection
+func GetContentFingerprintsMigration() Migration {
+	return Migration{
+		ID:          "013_add_content_fingerprints",
+		Description: "Add the content_fingerprints collection with a TTL index that ends each duplicate window",
+		Up:          addContentFingerprints,
+		Down:        removeContentFingerprints,
+	}
+}
+
+func addContentFingerprints(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding content fingerprints collection...")
+
+	fingerprintIndexes := []mongo.IndexModel{
+		// The author's own duplicate check
+		{Keys: bson.D{{Key: "hash", Value: 1}, {Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		// Coordinated spam: accounts and unreported posts sharing a hash
+		{Keys: bson.D{{Key: "hash", Value: 1}, {Key: "reported", Value: 1}, {Key: "created_at", Value: -1}}},
+		// Fingerprints are only needed for the duplicate window
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("content_fingerprints"), fingerprintIndexes); err != nil {
+		return err
+	}
+
+	log.Println("Content fingerprints collection added successfully")
+	return nil
+}
+
+func removeContentFingerprints(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing content fingerprints collection...")
+
+	// Fingerprints are short-lived and rebuilt as users post
+	if err := db.Collection("content_fingerprints").Drop(ctx); err != nil {
+		log.Printf("Warning: Failed to drop collection content_fingerprints: %v", err)
+	}
+
+	log.Println("Content fingerprints collection removed")
+	return nil
+}
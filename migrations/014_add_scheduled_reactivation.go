@@ -0,0 +1,50 @@
+// migrations/014_add_scheduled_reactivation.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetScheduledReactivationMigration returns the migration for scheduled account reactivation
+func GetScheduledReactivationMigration() Migration {
+	return Migration{
+		ID:          "014_add_scheduled_reactivation",
+		Description: "Index temporarily deactivated users by their scheduled reactivation date",
+		Up:          addScheduledReactivation,
+		Down:        removeScheduledReactivation,
+	}
+}
+
+func addScheduledReactivation(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding scheduled reactivation index...")
+
+	// Only deactivated users carry reactivate_at, so the index stays small
+	userIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "reactivate_at", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("users"), userIndexes); err != nil {
+		return err
+	}
+
+	log.Println("Scheduled reactivation index added successfully")
+	return nil
+}
+
+func removeScheduledReactivation(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing scheduled reactivation index...")
+
+	if err := DropIndexIfExists(ctx, db.Collection("users"), "reactivate_at_1"); err != nil {
+		log.Printf("Warning: Failed to drop scheduled reactivation index: %v", err)
+	}
+
+	log.Println("Scheduled reactivation index removed")
+	return nil
+}
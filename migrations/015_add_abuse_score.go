@@ -0,0 +1,67 @@
+// migrations/015_add_abuse_score.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetAbuseScoreMigration returns the migration for moderator abuse scores
+func GetAbuseScoreMigration() Migration {
+	return Migration{
+		ID:          "015_add_abuse_score",
+		Description: "Index users by abuse score and reports by reporter for score recomputes",
+		Up:          addAbuseScore,
+		Down:        removeAbuseScore,
+	}
+}
+
+func addAbuseScore(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding abuse score indexes...")
+
+	// Only users with a signal carry abuse_score, so the index stays small
+	userIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "abuse_score", Value: -1}},
+			Options: options.Index().SetSparse(true),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("users"), userIndexes); err != nil {
+		return err
+	}
+
+	// Scores are recomputed from recent reports against each target
+	reportIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "target_type", Value: 1},
+				{Key: "target_id", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("reports"), reportIndexes); err != nil {
+		return err
+	}
+
+	log.Println("Abuse score indexes added successfully")
+	return nil
+}
+
+func removeAbuseScore(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing abuse score indexes...")
+
+	if err := DropIndexIfExists(ctx, db.Collection("users"), "abuse_score_-1"); err != nil {
+		log.Printf("Warning: Failed to drop abuse score index: %v", err)
+	}
+	if err := DropIndexIfExists(ctx, db.Collection("reports"), "target_type_1_target_id_1_created_at_-1"); err != nil {
+		log.Printf("Warning: Failed to drop report target index: %v", err)
+	}
+
+	log.Println("Abuse score indexes removed")
+	return nil
+}
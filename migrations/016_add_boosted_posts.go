@@ -0,0 +1,76 @@
+// migrations/016_add_boosted_posts.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetBoostedPostsMigration returns the migration for boosted post campaigns
+func GetBoostedPostsMigration() Migration {
+	return Migration{
+		ID:          "016_add_boosted_posts",
+		Description: "Create boosted post campaign and per-user delivery indexes",
+		Up:          addBoostedPosts,
+		Down:        removeBoostedPosts,
+	}
+}
+
+func addBoostedPosts(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding boosted post indexes...")
+
+	// Feed delivery looks up live campaigns on every home feed page
+	boostIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "starts_at", Value: 1},
+				{Key: "ends_at", Value: 1},
+			},
+		},
+		{Keys: bson.D{{Key: "post_id", Value: 1}}},
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("boosted_posts"), boostIndexes); err != nil {
+		return err
+	}
+
+	// One delivery record per campaign, user and day; the unique index is
+	// what enforces the daily frequency cap
+	deliveryIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "boost_id", Value: 1},
+				{Key: "user_id", Value: 1},
+				{Key: "day", Value: -1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("boost_deliveries"), deliveryIndexes); err != nil {
+		return err
+	}
+
+	log.Println("Boosted post indexes added successfully")
+	return nil
+}
+
+func removeBoostedPosts(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing boosted post indexes...")
+
+	for _, name := range []string{"status_1_starts_at_1_ends_at_1", "post_id_1", "created_at_-1"} {
+		if err := DropIndexIfExists(ctx, db.Collection("boosted_posts"), name); err != nil {
+			log.Printf("Warning: Failed to drop boosted post index %s: %v", name, err)
+		}
+	}
+	if err := DropIndexIfExists(ctx, db.Collection("boost_deliveries"), "boost_id_1_user_id_1_day_-1"); err != nil {
+		log.Printf("Warning: Failed to drop boost delivery index: %v", err)
+	}
+
+	log.Println("Boosted post indexes removed")
+	return nil
+}
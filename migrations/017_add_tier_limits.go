@@ -0,0 +1,73 @@
+// migrations/017_add_tier_limits.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetTierLimitsMigration returns the migration for per-tier limits
+func GetTierLimitsMigration() Migration {
+	return Migration{
+		ID:          "017_add_tier_limits",
+		Description: "Create tier limit and rolling limit usage indexes",
+		Up:          addTierLimits,
+		Down:        removeTierLimits,
+	}
+}
+
+func addTierLimits(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding tier limit indexes...")
+
+	limitIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tier", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("tier_limits"), limitIndexes); err != nil {
+		return err
+	}
+
+	// Daily quotas count a user's uses in the trailing 24 hours; the TTL
+	// index drops uses once they have fallen out of the window
+	usageIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "limit", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+		{
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(86400),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("limit_usage"), usageIndexes); err != nil {
+		return err
+	}
+
+	log.Println("Tier limit indexes added successfully")
+	return nil
+}
+
+func removeTierLimits(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing tier limit indexes...")
+
+	if err := DropIndexIfExists(ctx, db.Collection("tier_limits"), "tier_1"); err != nil {
+		log.Printf("Warning: Failed to drop tier limit index: %v", err)
+	}
+	for _, name := range []string{"user_id_1_limit_1_created_at_-1", "created_at_1"} {
+		if err := DropIndexIfExists(ctx, db.Collection("limit_usage"), name); err != nil {
+			log.Printf("Warning: Failed to drop limit usage index %s: %v", name, err)
+		}
+	}
+
+	log.Println("Tier limit indexes removed")
+	return nil
+}
@@ -0,0 +1,64 @@
+// migrations/018_add_story_sticker_responses.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetStoryStickerResponsesMigration returns the migration for interactive story sticker responses
+func GetStoryStickerResponsesMigration() Migration {
+	return Migration{
+		ID:          "018_add_story_sticker_responses",
+		Description: "Create story sticker response indexes",
+		Up:          addStoryStickerResponses,
+		Down:        removeStoryStickerResponses,
+	}
+}
+
+func addStoryStickerResponses(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding story sticker response indexes...")
+
+	// The unique index is what limits a viewer to one response per sticker;
+	// its story_id prefix also serves the author's insights aggregation
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "story_id", Value: 1},
+				{Key: "sticker_id", Value: 1},
+				{Key: "user_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "story_id", Value: 1},
+				{Key: "sticker_id", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("story_sticker_responses"), indexes); err != nil {
+		return err
+	}
+
+	log.Println("Story sticker response indexes added successfully")
+	return nil
+}
+
+func removeStoryStickerResponses(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing story sticker response indexes...")
+
+	for _, name := range []string{"story_id_1_sticker_id_1_user_id_1", "story_id_1_sticker_id_1_created_at_-1"} {
+		if err := DropIndexIfExists(ctx, db.Collection("story_sticker_responses"), name); err != nil {
+			log.Printf("Warning: Failed to drop story sticker response index %s: %v", name, err)
+		}
+	}
+
+	log.Println("Story sticker response indexes removed")
+	return nil
+}
@@ -0,0 +1,53 @@
+// migrations/019_add_group_notification_levels.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetGroupNotificationLevelsMigration returns the migration for per-group notification levels
+func GetGroupNotificationLevelsMigration() Migration {
+	return Migration{
+		ID:          "019_add_group_notification_levels",
+		Description: "Create indexes for group notification levels",
+		Up:          addGroupNotificationLevels,
+		Down:        removeGroupNotificationLevels,
+	}
+}
+
+func addGroupNotificationLevels(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding group notification level indexes...")
+
+	// Muting a group suppresses the member's undelivered notifications for it.
+	// Members without a level keep their old mute setting, so no backfill is needed.
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "recipient_id", Value: 1},
+				{Key: "group_id", Value: 1},
+				{Key: "is_delivered", Value: 1},
+			},
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("notifications"), indexes); err != nil {
+		return err
+	}
+
+	log.Println("Group notification level indexes added successfully")
+	return nil
+}
+
+func removeGroupNotificationLevels(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing group notification level indexes...")
+
+	if err := DropIndexIfExists(ctx, db.Collection("notifications"), "recipient_id_1_group_id_1_is_delivered_1"); err != nil {
+		log.Printf("Warning: Failed to drop group notification index: %v", err)
+	}
+
+	log.Println("Group notification level indexes removed")
+	return nil
+}
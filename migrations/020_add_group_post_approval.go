@@ -0,0 +1,59 @@
+// migrations/020_add_group_post_approval.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetGroupPostApprovalMigration returns the migration for group post feeds and approval queues
+func GetGroupPostApprovalMigration() Migration {
+	return Migration{
+		ID:          "020_add_group_post_approval",
+		Description: "Create indexes for group post feeds and approval queues",
+		Up:          addGroupPostApproval,
+		Down:        removeGroupPostApproval,
+	}
+}
+
+func addGroupPostApproval(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding group post approval indexes...")
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "group_id", Value: 1},
+				{Key: "published_at", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "group_id", Value: 1},
+				{Key: "group_review_status", Value: 1},
+				{Key: "created_at", Value: 1},
+			},
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("posts"), indexes); err != nil {
+		return err
+	}
+
+	log.Println("Group post approval indexes added successfully")
+	return nil
+}
+
+func removeGroupPostApproval(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing group post approval indexes...")
+
+	for _, name := range []string{"group_id_1_published_at_-1", "group_id_1_group_review_status_1_created_at_1"} {
+		if err := DropIndexIfExists(ctx, db.Collection("posts"), name); err != nil {
+			log.Printf("Warning: Failed to drop group post index %s: %v", name, err)
+		}
+	}
+
+	log.Println("Group post approval indexes removed")
+	return nil
+}
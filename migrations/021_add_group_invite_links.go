@@ -0,0 +1,58 @@
+// migrations/021_add_group_invite_links.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetGroupInviteLinksMigration returns the migration for group invite links
+func GetGroupInviteLinksMigration() Migration {
+	return Migration{
+		ID:          "021_add_group_invite_links",
+		Description: "Create indexes for group invite links",
+		Up:          addGroupInviteLinks,
+		Down:        removeGroupInviteLinks,
+	}
+}
+
+func addGroupInviteLinks(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding group invite link indexes...")
+
+	// Joining looks links up by token; admins list a group's links newest first
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "group_id", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("group_invite_links"), indexes); err != nil {
+		return err
+	}
+
+	log.Println("Group invite link indexes added successfully")
+	return nil
+}
+
+func removeGroupInviteLinks(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing group invite link indexes...")
+
+	for _, name := range []string{"token_1", "group_id_1_created_at_-1"} {
+		if err := DropIndexIfExists(ctx, db.Collection("group_invite_links"), name); err != nil {
+			log.Printf("Warning: Failed to drop group invite link index %s: %v", name, err)
+		}
+	}
+
+	log.Println("Group invite link indexes removed")
+	return nil
+}
@@ -0,0 +1,62 @@
+// migrations/022_add_quick_replies.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetQuickRepliesMigration returns the migration for quick reply comments
+func GetQuickRepliesMigration() Migration {
+	return Migration{
+		ID:          "022_add_quick_replies",
+		Description: "Create indexes for quick reply comments",
+		Up:          addQuickReplies,
+		Down:        removeQuickReplies,
+	}
+}
+
+func addQuickReplies(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding quick reply indexes...")
+
+	// Quick replies are listed per post and capped per user per post. Existing
+	// emoji-only comments are reclassified by the 003_quick_reply_comments backfill.
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "post_id", Value: 1},
+				{Key: "kind", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "post_id", Value: 1},
+				{Key: "user_id", Value: 1},
+				{Key: "kind", Value: 1},
+			},
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("comments"), indexes); err != nil {
+		return err
+	}
+
+	log.Println("Quick reply indexes added successfully")
+	return nil
+}
+
+func removeQuickReplies(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing quick reply indexes...")
+
+	for _, name := range []string{"post_id_1_kind_1_created_at_-1", "post_id_1_user_id_1_kind_1"} {
+		if err := DropIndexIfExists(ctx, db.Collection("comments"), name); err != nil {
+			log.Printf("Warning: Failed to drop quick reply index %s: %v", name, err)
+		}
+	}
+
+	log.Println("Quick reply indexes removed")
+	return nil
+}
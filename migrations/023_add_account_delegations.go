@@ -0,0 +1,74 @@
+// migrations/023_add_account_delegations.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetAccountDelegationsMigration returns the migration for delegated account access
+func GetAccountDelegationsMigration() Migration {
+	return Migration{
+		ID:          "023_add_account_delegations",
+		Description: "Create account delegation and post edit history indexes",
+		Up:          addAccountDelegations,
+		Down:        removeAccountDelegations,
+	}
+}
+
+func addAccountDelegations(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding account delegation indexes...")
+
+	// One open delegation per account and delegate; declined and revoked ones
+	// stay as history and don't block a new invitation
+	delegationIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "account_id", Value: 1}, {Key: "delegate_id", Value: 1}},
+			Options: options.Index().
+				SetName("account_id_delegate_id_open").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": bson.M{"$in": []string{"pending", "active"}}}),
+		},
+		{
+			Keys: bson.D{{Key: "account_id", Value: 1}, {Key: "status", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "delegate_id", Value: 1}, {Key: "status", Value: 1}},
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("account_delegations"), delegationIndexes); err != nil {
+		return err
+	}
+
+	editIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "post_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("post_edits"), editIndexes); err != nil {
+		return err
+	}
+
+	log.Println("Account delegation indexes added successfully")
+	return nil
+}
+
+func removeAccountDelegations(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing account delegation indexes...")
+
+	for _, name := range []string{"account_id_delegate_id_open", "account_id_1_status_1", "delegate_id_1_status_1"} {
+		if err := DropIndexIfExists(ctx, db.Collection("account_delegations"), name); err != nil {
+			log.Printf("Warning: Failed to drop account delegation index %s: %v", name, err)
+		}
+	}
+	if err := DropIndexIfExists(ctx, db.Collection("post_edits"), "post_id_1_created_at_-1"); err != nil {
+		log.Printf("Warning: Failed to drop post edit index: %v", err)
+	}
+
+	log.Println("Account delegation indexes removed")
+	return nil
+}
@@ -0,0 +1,70 @@
+// migrations/024_add_user_warnings.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetUserWarningsMigration returns the migration for user warnings and strikes
+func GetUserWarningsMigration() Migration {
+	return Migration{
+		ID:          "024_add_user_warnings",
+		Description: "Create user warning indexes and strike suspension index",
+		Up:          addUserWarnings,
+		Down:        removeUserWarnings,
+	}
+}
+
+func addUserWarnings(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding user warning indexes...")
+
+	// Warning history per user, and the strike expiry job's scan
+	warningIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "expired", Value: 1}, {Key: "expires_at", Value: 1}},
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("user_warnings"), warningIndexes); err != nil {
+		return err
+	}
+
+	// Only users serving a strike suspension carry suspended_until
+	userIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "suspended_until", Value: 1}},
+			Options: options.Index().
+				SetName("suspended_until").
+				SetPartialFilterExpression(bson.M{"suspended_until": bson.M{"$exists": true}}),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("users"), userIndexes); err != nil {
+		return err
+	}
+
+	log.Println("User warning indexes added successfully")
+	return nil
+}
+
+func removeUserWarnings(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing user warning indexes...")
+
+	for _, name := range []string{"user_id_1_created_at_-1", "expired_1_expires_at_1"} {
+		if err := DropIndexIfExists(ctx, db.Collection("user_warnings"), name); err != nil {
+			log.Printf("Warning: Failed to drop user warning index %s: %v", name, err)
+		}
+	}
+	if err := DropIndexIfExists(ctx, db.Collection("users"), "suspended_until"); err != nil {
+		log.Printf("Warning: Failed to drop suspended_until index: %v", err)
+	}
+
+	log.Println("User warning indexes removed")
+	return nil
+}
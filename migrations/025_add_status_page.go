@@ -0,0 +1,77 @@
+// migrations/025_add_status_page.go
+package migrations
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"social-media-api/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetStatusPageMigration returns the migration for status samples and incidents
+func GetStatusPageMigration() Migration {
+	return Migration{
+		ID:          "025_add_status_page",
+		Description: "Create status sample TTL and incident indexes",
+		Up:          addStatusPage,
+		Down:        removeStatusPage,
+	}
+}
+
+func addStatusPage(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding status page indexes...")
+
+	samples := db.Collection("status_samples")
+
+	// Samples are kept exactly as long as the status page reports history
+	retention := time.Duration(models.StatusHistoryDays) * 24 * time.Hour
+	if err := ensureTTLIndex(ctx, samples, "recorded_at", int32(retention.Seconds())); err != nil {
+		return err
+	}
+
+	sampleIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "component", Value: 1}, {Key: "recorded_at", Value: 1}},
+		},
+	}
+	if err := CreateIndexesSafely(ctx, samples, sampleIndexes); err != nil {
+		return err
+	}
+
+	incidentIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "created_at", Value: -1}},
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("incidents"), incidentIndexes); err != nil {
+		return err
+	}
+
+	log.Println("Status page indexes added successfully")
+	return nil
+}
+
+func removeStatusPage(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing status page indexes...")
+
+	for _, name := range []string{"recorded_at_1", "component_1_recorded_at_1"} {
+		if err := DropIndexIfExists(ctx, db.Collection("status_samples"), name); err != nil {
+			log.Printf("Warning: Failed to drop status sample index %s: %v", name, err)
+		}
+	}
+	for _, name := range []string{"status_1_created_at_-1", "created_at_-1"} {
+		if err := DropIndexIfExists(ctx, db.Collection("incidents"), name); err != nil {
+			log.Printf("Warning: Failed to drop incident index %s: %v", name, err)
+		}
+	}
+
+	log.Println("Status page indexes removed")
+	return nil
+}
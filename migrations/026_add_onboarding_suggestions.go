@@ -0,0 +1,52 @@
+// migrations/026_add_onboarding_suggestions.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetOnboardingSuggestionsMigration returns the migration for curated onboarding suggestions
+func GetOnboardingSuggestionsMigration() Migration {
+	return Migration{
+		ID:          "026_add_onboarding_suggestions",
+		Description: "Create curated onboarding suggestion index",
+		Up:          addOnboardingSuggestions,
+		Down:        removeOnboardingSuggestions,
+	}
+}
+
+func addOnboardingSuggestions(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding onboarding suggestion index...")
+
+	// Only the few curated accounts carry the flag
+	userIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "is_onboarding_suggested", Value: 1}, {Key: "followers_count", Value: -1}},
+			Options: options.Index().
+				SetName("onboarding_suggested").
+				SetPartialFilterExpression(bson.M{"is_onboarding_suggested": true}),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("users"), userIndexes); err != nil {
+		return err
+	}
+
+	log.Println("Onboarding suggestion index added successfully")
+	return nil
+}
+
+func removeOnboardingSuggestions(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing onboarding suggestion index...")
+
+	if err := DropIndexIfExists(ctx, db.Collection("users"), "onboarding_suggested"); err != nil {
+		log.Printf("Warning: Failed to drop onboarding_suggested index: %v", err)
+	}
+
+	log.Println("Onboarding suggestion index removed")
+	return nil
+}
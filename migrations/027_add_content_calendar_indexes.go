@@ -0,0 +1,77 @@
+// migrations/027_add_content_calendar_indexes.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetContentCalendarMigration returns the migration for the creator content calendar
+func GetContentCalendarMigration() Migration {
+	return Migration{
+		ID:          "027_add_content_calendar_indexes",
+		Description: "Create per-state post date indexes for the content calendar",
+		Up:          addContentCalendarIndexes,
+		Down:        removeContentCalendarIndexes,
+	}
+}
+
+func addContentCalendarIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding content calendar indexes...")
+
+	// One index per calendar state, each ranging over the date that places the post
+	postIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "is_published", Value: 1}, {Key: "published_at", Value: 1}},
+			Options: options.Index().SetName("calendar_published"),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "is_scheduled", Value: 1}, {Key: "scheduled_for", Value: 1}},
+			Options: options.Index().
+				SetName("calendar_scheduled").
+				SetPartialFilterExpression(bson.M{"is_scheduled": true}),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "group_review_status", Value: 1}, {Key: "created_at", Value: 1}},
+			Options: options.Index().
+				SetName("calendar_pending_approval").
+				SetPartialFilterExpression(bson.M{"group_review_status": "pending"}),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("posts"), postIndexes); err != nil {
+		return err
+	}
+
+	highlightIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: 1}},
+			Options: options.Index().SetName("calendar_highlights"),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("story_highlights"), highlightIndexes); err != nil {
+		return err
+	}
+
+	log.Println("Content calendar indexes added successfully")
+	return nil
+}
+
+func removeContentCalendarIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing content calendar indexes...")
+
+	for _, name := range []string{"calendar_published", "calendar_scheduled", "calendar_pending_approval"} {
+		if err := DropIndexIfExists(ctx, db.Collection("posts"), name); err != nil {
+			log.Printf("Warning: Failed to drop %s index: %v", name, err)
+		}
+	}
+	if err := DropIndexIfExists(ctx, db.Collection("story_highlights"), "calendar_highlights"); err != nil {
+		log.Printf("Warning: Failed to drop calendar_highlights index: %v", err)
+	}
+
+	log.Println("Content calendar indexes removed")
+	return nil
+}
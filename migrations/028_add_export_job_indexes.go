@@ -0,0 +1,59 @@
+// migrations/028_add_export_job_indexes.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetExportJobMigration returns the migration for background export jobs
+func GetExportJobMigration() Migration {
+	return Migration{
+		ID:          "028_add_export_job_indexes",
+		Description: "Create indexes for listing, resuming and expiring export jobs",
+		Up:          addExportJobIndexes,
+		Down:        removeExportJobIndexes,
+	}
+}
+
+func addExportJobIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding export job indexes...")
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "export_id", Value: 1}},
+			Options: options.Index().SetName("export_id_unique").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "data_type", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("data_type_created_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "data_type", Value: 1}, {Key: "status", Value: 1}, {Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("data_type_status_expires_at"),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("export_jobs"), indexes); err != nil {
+		return err
+	}
+
+	log.Println("Export job indexes added successfully")
+	return nil
+}
+
+func removeExportJobIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing export job indexes...")
+
+	for _, name := range []string{"export_id_unique", "data_type_created_at", "data_type_status_expires_at"} {
+		if err := DropIndexIfExists(ctx, db.Collection("export_jobs"), name); err != nil {
+			log.Printf("Warning: Failed to drop %s index: %v", name, err)
+		}
+	}
+
+	log.Println("Export job indexes removed")
+	return nil
+}
@@ -0,0 +1,88 @@
+// migrations/029_add_moderation_queue.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetModerationQueueMigration returns the migration for the unified moderation queue
+func GetModerationQueueMigration() Migration {
+	return Migration{
+		ID:          "029_add_moderation_queue",
+		Description: "Create moderation claim indexes and indexes for the moderation queue sources",
+		Up:          addModerationQueueIndexes,
+		Down:        removeModerationQueueIndexes,
+	}
+}
+
+func addModerationQueueIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding moderation queue indexes...")
+
+	// One claim per item; expired claims are cleaned up by the TTL index
+	claimIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "queue_item_type", Value: 1}, {Key: "item_id", Value: 1}},
+			Options: options.Index().SetName("queue_item_unique").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("expires_at_ttl").SetExpireAfterSeconds(0),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("moderation_claims"), claimIndexes); err != nil {
+		return err
+	}
+
+	mediaIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "moderation_status", Value: 1}, {Key: "updated_at", Value: 1}},
+			Options: options.Index().SetName("moderation_status_updated_at"),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("media"), mediaIndexes); err != nil {
+		return err
+	}
+
+	// Only hidden content is indexed; visible content never enters the queue
+	hiddenIndex := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "updated_at", Value: 1}},
+			Options: options.Index().SetName("hidden_updated_at").
+				SetPartialFilterExpression(bson.M{"is_hidden": true}),
+		},
+	}
+	for _, collection := range []string{"posts", "comments"} {
+		if err := CreateIndexesSafely(ctx, db.Collection(collection), hiddenIndex); err != nil {
+			return err
+		}
+	}
+
+	log.Println("Moderation queue indexes added successfully")
+	return nil
+}
+
+func removeModerationQueueIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing moderation queue indexes...")
+
+	drops := map[string][]string{
+		"moderation_claims": {"queue_item_unique", "expires_at_ttl"},
+		"media":             {"moderation_status_updated_at"},
+		"posts":             {"hidden_updated_at"},
+		"comments":          {"hidden_updated_at"},
+	}
+	for collection, names := range drops {
+		for _, name := range names {
+			if err := DropIndexIfExists(ctx, db.Collection(collection), name); err != nil {
+				log.Printf("Warning: Failed to drop %s index on %s: %v", name, collection, err)
+			}
+		}
+	}
+
+	log.Println("Moderation queue indexes removed")
+	return nil
+}
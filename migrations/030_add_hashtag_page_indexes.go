@@ -0,0 +1,63 @@
+// migrations/030_add_hashtag_page_indexes.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetHashtagPageMigration returns the migration for the hashtag page
+func GetHashtagPageMigration() Migration {
+	return Migration{
+		ID:          "030_add_hashtag_page_indexes",
+		Description: "Create indexes for paging hashtag posts and refreshing related hashtags",
+		Up:          addHashtagPageIndexes,
+		Down:        removeHashtagPageIndexes,
+	}
+}
+
+func addHashtagPageIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding hashtag page indexes...")
+
+	// Serves the recent tab's cursor and the related job's newest-first sample
+	postIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "hashtags", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("hashtags_created_at_id"),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("posts"), postIndexes); err != nil {
+		return err
+	}
+
+	hashtagIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "is_blocked", Value: 1}, {Key: "posts_count", Value: 1}},
+			Options: options.Index().SetName("is_blocked_posts_count"),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("hashtags"), hashtagIndexes); err != nil {
+		return err
+	}
+
+	log.Println("Hashtag page indexes added successfully")
+	return nil
+}
+
+func removeHashtagPageIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing hashtag page indexes...")
+
+	if err := DropIndexIfExists(ctx, db.Collection("posts"), "hashtags_created_at_id"); err != nil {
+		log.Printf("Warning: Failed to drop hashtags_created_at_id index: %v", err)
+	}
+	if err := DropIndexIfExists(ctx, db.Collection("hashtags"), "is_blocked_posts_count"); err != nil {
+		log.Printf("Warning: Failed to drop is_blocked_posts_count index: %v", err)
+	}
+
+	log.Println("Hashtag page indexes removed")
+	return nil
+}
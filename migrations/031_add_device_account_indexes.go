@@ -0,0 +1,84 @@
+// migrations/031_add_device_account_indexes.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetDeviceAccountMigration returns the migration for the account switcher
+func GetDeviceAccountMigration() Migration {
+	return Migration{
+		ID:          "031_add_device_account_indexes",
+		Description: "Create indexes for device account groups and per-account push tokens",
+		Up:          addDeviceAccountIndexes,
+		Down:        removeDeviceAccountIndexes,
+	}
+}
+
+func addDeviceAccountIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding device account indexes...")
+
+	sessionIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "session_id", Value: 1}},
+			Options: options.Index().SetName("session_id"),
+		},
+		// Serves the account list and finding an account's session on a device
+		{
+			Keys:    bson.D{{Key: "device_group_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "is_active", Value: 1}},
+			Options: options.Index().SetName("device_group_id_user_id_is_active"),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "is_active", Value: 1}},
+			Options: options.Index().SetName("user_id_is_active"),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("sessions"), sessionIndexes); err != nil {
+		return err
+	}
+
+	// Each account on a device holds its own entry for the device's token
+	pushTokenIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetName("token_user_id").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "is_active", Value: 1}},
+			Options: options.Index().SetName("user_id_is_active"),
+		},
+		{
+			Keys:    bson.D{{Key: "device_group_id", Value: 1}},
+			Options: options.Index().SetName("device_group_id"),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("push_tokens"), pushTokenIndexes); err != nil {
+		return err
+	}
+
+	log.Println("Device account indexes added successfully")
+	return nil
+}
+
+func removeDeviceAccountIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing device account indexes...")
+
+	for _, name := range []string{"session_id", "device_group_id_user_id_is_active", "user_id_is_active"} {
+		if err := DropIndexIfExists(ctx, db.Collection("sessions"), name); err != nil {
+			log.Printf("Warning: Failed to drop sessions %s index: %v", name, err)
+		}
+	}
+	for _, name := range []string{"token_user_id", "user_id_is_active", "device_group_id"} {
+		if err := DropIndexIfExists(ctx, db.Collection("push_tokens"), name); err != nil {
+			log.Printf("Warning: Failed to drop push_tokens %s index: %v", name, err)
+		}
+	}
+
+	log.Println("Device account indexes removed")
+	return nil
+}
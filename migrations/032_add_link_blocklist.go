@@ -0,0 +1,79 @@
+// migrations/032_add_link_blocklist.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetLinkBlocklistMigration returns the migration for the link blocklist
+func GetLinkBlocklistMigration() Migration {
+	return Migration{
+		ID:          "032_add_link_blocklist",
+		Description: "Create indexes for the link blocklist and its block log",
+		Up:          addLinkBlocklistIndexes,
+		Down:        removeLinkBlocklistIndexes,
+	}
+}
+
+func addLinkBlocklistIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding link blocklist indexes...")
+
+	blockedDomainIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "domain", Value: 1}},
+			Options: options.Index().SetName("domain").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("created_at"),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("blocked_domains"), blockedDomainIndexes); err != nil {
+		return err
+	}
+
+	// The block log is reviewed newest first, overall or per blocklist entry
+	linkBlockIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("created_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "domain", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("domain_created_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("user_id_created_at"),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("link_blocks"), linkBlockIndexes); err != nil {
+		return err
+	}
+
+	log.Println("Link blocklist indexes added successfully")
+	return nil
+}
+
+func removeLinkBlocklistIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing link blocklist indexes...")
+
+	for _, name := range []string{"domain", "created_at"} {
+		if err := DropIndexIfExists(ctx, db.Collection("blocked_domains"), name); err != nil {
+			log.Printf("Warning: Failed to drop blocked_domains %s index: %v", name, err)
+		}
+	}
+	for _, name := range []string{"created_at", "domain_created_at", "user_id_created_at"} {
+		if err := DropIndexIfExists(ctx, db.Collection("link_blocks"), name); err != nil {
+			log.Printf("Warning: Failed to drop link_blocks %s index: %v", name, err)
+		}
+	}
+
+	log.Println("Link blocklist indexes removed")
+	return nil
+}
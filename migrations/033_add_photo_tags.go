@@ -0,0 +1,54 @@
+// migrations/033_add_photo_tags.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetPhotoTagsMigration returns the migration for photo tags on posts
+func GetPhotoTagsMigration() Migration {
+	return Migration{
+		ID:          "033_add_photo_tags",
+		Description: "Create the index behind users' tagged posts tab",
+		Up:          addPhotoTagIndexes,
+		Down:        removePhotoTagIndexes,
+	}
+}
+
+func addPhotoTagIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding photo tag indexes...")
+
+	// The tagged tab lists a user's tags in one status, newest post first
+	postIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "photo_tags.user_id", Value: 1},
+				{Key: "photo_tags.status", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+			Options: options.Index().SetName("photo_tags_user_status_created_at"),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("posts"), postIndexes); err != nil {
+		return err
+	}
+
+	log.Println("Photo tag indexes added successfully")
+	return nil
+}
+
+func removePhotoTagIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing photo tag indexes...")
+
+	if err := DropIndexIfExists(ctx, db.Collection("posts"), "photo_tags_user_status_created_at"); err != nil {
+		log.Printf("Warning: Failed to drop posts photo_tags_user_status_created_at index: %v", err)
+	}
+
+	log.Println("Photo tag indexes removed")
+	return nil
+}
@@ -0,0 +1,56 @@
+// migrations/034_add_feed_injections.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetFeedInjectionsMigration returns the migration for feed injection records
+func GetFeedInjectionsMigration() Migration {
+	return Migration{
+		ID:          "034_add_feed_injections",
+		Description: "Create indexes for the posts injected into users' feeds",
+		Up:          addFeedInjectionIndexes,
+		Down:        removeFeedInjectionIndexes,
+	}
+}
+
+func addFeedInjectionIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding feed injection indexes...")
+
+	// Records are read per user and expire with the session window
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("user_expires_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("expires_at_ttl").SetExpireAfterSeconds(0),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("feed_injections"), indexes); err != nil {
+		return err
+	}
+
+	log.Println("Feed injection indexes added successfully")
+	return nil
+}
+
+func removeFeedInjectionIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing feed injection indexes...")
+
+	for _, name := range []string{"user_expires_at", "expires_at_ttl"} {
+		if err := DropIndexIfExists(ctx, db.Collection("feed_injections"), name); err != nil {
+			log.Printf("Warning: Failed to drop %s index on feed_injections: %v", name, err)
+		}
+	}
+
+	log.Println("Feed injection indexes removed")
+	return nil
+}
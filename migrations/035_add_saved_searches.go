@@ -0,0 +1,57 @@
+// migrations/035_add_saved_searches.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetSavedSearchesMigration returns the migration for saved searches
+func GetSavedSearchesMigration() Migration {
+	return Migration{
+		ID:          "035_add_saved_searches",
+		Description: "Create indexes for saved searches and the search alert job",
+		Up:          addSavedSearchIndexes,
+		Down:        removeSavedSearchIndexes,
+	}
+}
+
+func addSavedSearchIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding saved search indexes...")
+
+	// Users list their own searches; the alert job looks for searches due to run
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("user_created_at"),
+		},
+		{
+			Keys: bson.D{{Key: "last_run_at", Value: 1}},
+			Options: options.Index().SetName("alerts_last_run_at").
+				SetPartialFilterExpression(bson.M{"alerts_enabled": true}),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("saved_searches"), indexes); err != nil {
+		return err
+	}
+
+	log.Println("Saved search indexes added successfully")
+	return nil
+}
+
+func removeSavedSearchIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing saved search indexes...")
+
+	for _, name := range []string{"user_created_at", "alerts_last_run_at"} {
+		if err := DropIndexIfExists(ctx, db.Collection("saved_searches"), name); err != nil {
+			log.Printf("Warning: Failed to drop %s index on saved_searches: %v", name, err)
+		}
+	}
+
+	log.Println("Saved search indexes removed")
+	return nil
+}
@@ -0,0 +1,51 @@
+// migrations/036_add_post_auto_delete.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetPostAutoDeleteMigration returns the migration for per-user post auto-delete
+func GetPostAutoDeleteMigration() Migration {
+	return Migration{
+		ID:          "036_add_post_auto_delete",
+		Description: "Create the index the post auto-delete job finds users with",
+		Up:          addPostAutoDeleteIndexes,
+		Down:        removePostAutoDeleteIndexes,
+	}
+}
+
+func addPostAutoDeleteIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding post auto-delete indexes...")
+
+	// Only users who turned auto-delete on are indexed
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "auto_delete_posts_after", Value: 1}},
+			Options: options.Index().SetName("auto_delete_posts_after").
+				SetPartialFilterExpression(bson.M{"auto_delete_posts_after": bson.M{"$exists": true}}),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("users"), indexes); err != nil {
+		return err
+	}
+
+	log.Println("Post auto-delete indexes added successfully")
+	return nil
+}
+
+func removePostAutoDeleteIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing post auto-delete indexes...")
+
+	if err := DropIndexIfExists(ctx, db.Collection("users"), "auto_delete_posts_after"); err != nil {
+		log.Printf("Warning: Failed to drop auto_delete_posts_after index on users: %v", err)
+	}
+
+	log.Println("Post auto-delete indexes removed")
+	return nil
+}
@@ -0,0 +1,55 @@
+// migrations/037_add_comment_reply_paging.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetCommentReplyPagingMigration returns the migration for paging comment replies
+func GetCommentReplyPagingMigration() Migration {
+	return Migration{
+		ID:          "037_add_comment_reply_paging",
+		Description: "Create the index reply previews and reply cursors page through",
+		Up:          addCommentReplyPagingIndexes,
+		Down:        removeCommentReplyPagingIndexes,
+	}
+}
+
+func addCommentReplyPagingIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding comment reply paging indexes...")
+
+	// Replies are listed oldest first, with _id breaking ties for the cursor
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "parent_comment_id", Value: 1},
+				{Key: "created_at", Value: 1},
+				{Key: "_id", Value: 1},
+			},
+			Options: options.Index().SetName("parent_comment_id_created_at").
+				SetPartialFilterExpression(bson.M{"parent_comment_id": bson.M{"$exists": true}}),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("comments"), indexes); err != nil {
+		return err
+	}
+
+	log.Println("Comment reply paging indexes added successfully")
+	return nil
+}
+
+func removeCommentReplyPagingIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing comment reply paging indexes...")
+
+	if err := DropIndexIfExists(ctx, db.Collection("comments"), "parent_comment_id_created_at"); err != nil {
+		log.Printf("Warning: Failed to drop parent_comment_id_created_at index on comments: %v", err)
+	}
+
+	log.Println("Comment reply paging indexes removed")
+	return nil
+}
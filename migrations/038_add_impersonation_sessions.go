@@ -0,0 +1,70 @@
+// migrations/038_add_impersonation_sessions.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetImpersonationSessionsMigration returns the migration for support impersonation
+func GetImpersonationSessionsMigration() Migration {
+	return Migration{
+		ID:          "038_add_impersonation_sessions",
+		Description: "Create indexes for impersonation sessions and the audit entries made under them",
+		Up:          addImpersonationSessionIndexes,
+		Down:        removeImpersonationSessionIndexes,
+	}
+}
+
+func addImpersonationSessionIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding impersonation session indexes...")
+
+	sessionIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("user_id_created_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "admin_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("admin_id_created_at"),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("impersonation_sessions"), sessionIndexes); err != nil {
+		return err
+	}
+
+	// Only audit entries made while impersonating are indexed
+	auditIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "impersonated_by", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("impersonated_by_created_at").
+				SetPartialFilterExpression(bson.M{"impersonated_by": bson.M{"$exists": true}}),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("audit_logs"), auditIndexes); err != nil {
+		return err
+	}
+
+	log.Println("Impersonation session indexes added successfully")
+	return nil
+}
+
+func removeImpersonationSessionIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing impersonation session indexes...")
+
+	for _, name := range []string{"user_id_created_at", "admin_id_created_at"} {
+		if err := DropIndexIfExists(ctx, db.Collection("impersonation_sessions"), name); err != nil {
+			log.Printf("Warning: Failed to drop %s index on impersonation_sessions: %v", name, err)
+		}
+	}
+	if err := DropIndexIfExists(ctx, db.Collection("audit_logs"), "impersonated_by_created_at"); err != nil {
+		log.Printf("Warning: Failed to drop impersonated_by_created_at index on audit_logs: %v", err)
+	}
+
+	log.Println("Impersonation session indexes removed")
+	return nil
+}
@@ -0,0 +1,61 @@
+// migrations/039_add_media_content_hashes.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetMediaContentHashMigration returns the migration for duplicate media detection
+func GetMediaContentHashMigration() Migration {
+	return Migration{
+		ID:          "039_add_media_content_hashes",
+		Description: "Create indexes for finding duplicate uploads and media sharing a file",
+		Up:          addMediaContentHashIndexes,
+		Down:        removeMediaContentHashIndexes,
+	}
+}
+
+func addMediaContentHashIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding media content hash indexes...")
+
+	// Uploads are matched against the uploader's images by content hash;
+	// duplicate uploads share a storage key, checked before a file is removed
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "uploaded_by", Value: 1},
+				{Key: "content_hash", Value: 1},
+			},
+			Options: options.Index().SetName("uploaded_by_content_hash").
+				SetPartialFilterExpression(bson.M{"content_hash": bson.M{"$exists": true}}),
+		},
+		{
+			Keys:    bson.D{{Key: "storage_key", Value: 1}},
+			Options: options.Index().SetName("storage_key"),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("media"), indexes); err != nil {
+		return err
+	}
+
+	log.Println("Media content hash indexes added successfully")
+	return nil
+}
+
+func removeMediaContentHashIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing media content hash indexes...")
+
+	for _, name := range []string{"uploaded_by_content_hash", "storage_key"} {
+		if err := DropIndexIfExists(ctx, db.Collection("media"), name); err != nil {
+			log.Printf("Warning: Failed to drop %s index on media: %v", name, err)
+		}
+	}
+
+	log.Println("Media content hash indexes removed")
+	return nil
+}
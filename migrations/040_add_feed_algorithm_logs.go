@@ -0,0 +1,56 @@
+// migrations/040_add_feed_algorithm_logs.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetFeedAlgorithmLogsMigration returns the migration for feed algorithm logs
+func GetFeedAlgorithmLogsMigration() Migration {
+	return Migration{
+		ID:          "040_add_feed_algorithm_logs",
+		Description: "Create indexes for reporting which algorithm served each feed",
+		Up:          addFeedAlgorithmLogIndexes,
+		Down:        removeFeedAlgorithmLogIndexes,
+	}
+}
+
+func addFeedAlgorithmLogIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding feed algorithm log indexes...")
+
+	// Expiry is left to the retention service, which owns the TTL index
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "algorithm", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("algorithm_created_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("user_id_created_at"),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("feed_algorithm_logs"), indexes); err != nil {
+		return err
+	}
+
+	log.Println("Feed algorithm log indexes added successfully")
+	return nil
+}
+
+func removeFeedAlgorithmLogIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing feed algorithm log indexes...")
+
+	for _, name := range []string{"algorithm_created_at", "user_id_created_at"} {
+		if err := DropIndexIfExists(ctx, db.Collection("feed_algorithm_logs"), name); err != nil {
+			log.Printf("Warning: Failed to drop %s index on feed_algorithm_logs: %v", name, err)
+		}
+	}
+
+	log.Println("Feed algorithm log indexes removed")
+	return nil
+}
@@ -0,0 +1,56 @@
+// migrations/041_add_creator_summary_cache.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetCreatorSummaryCacheMigration returns the migration for cached creator summaries
+func GetCreatorSummaryCacheMigration() Migration {
+	return Migration{
+		ID:          "041_add_creator_summary_cache",
+		Description: "Create creator summary cache indexes with expiry",
+		Up:          addCreatorSummaryCacheIndexes,
+		Down:        removeCreatorSummaryCacheIndexes,
+	}
+}
+
+func addCreatorSummaryCacheIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding creator summary cache indexes...")
+
+	// One summary per user and period, removed once it expires
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "period", Value: 1}},
+			Options: options.Index().SetName("user_id_period").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("expires_at_ttl").SetExpireAfterSeconds(0),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("creator_summary_cache"), indexes); err != nil {
+		return err
+	}
+
+	log.Println("Creator summary cache indexes added successfully")
+	return nil
+}
+
+func removeCreatorSummaryCacheIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing creator summary cache indexes...")
+
+	for _, name := range []string{"user_id_period", "expires_at_ttl"} {
+		if err := DropIndexIfExists(ctx, db.Collection("creator_summary_cache"), name); err != nil {
+			log.Printf("Warning: Failed to drop %s index on creator_summary_cache: %v", name, err)
+		}
+	}
+
+	log.Println("Creator summary cache indexes removed")
+	return nil
+}
@@ -0,0 +1,65 @@
+// migrations/042_add_comment_flood_limits.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetCommentFloodLimitsMigration returns the migration for comment flood limits
+func GetCommentFloodLimitsMigration() Migration {
+	return Migration{
+		ID:          "042_add_comment_flood_limits",
+		Description: "Create indexes for per-post comment rate limits and repeated comment detection",
+		Up:          addCommentFloodIndexes,
+		Down:        removeCommentFloodIndexes,
+	}
+}
+
+func addCommentFloodIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding comment flood limit indexes...")
+
+	// The global limit uses the existing user_id/created_at index
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "post_id", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+			Options: options.Index().SetName("user_id_post_id_created_at"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "content_hash", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+			Options: options.Index().SetName("user_id_content_hash_created_at").
+				SetPartialFilterExpression(bson.M{"content_hash": bson.M{"$exists": true}}),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("comments"), indexes); err != nil {
+		return err
+	}
+
+	log.Println("Comment flood limit indexes added successfully")
+	return nil
+}
+
+func removeCommentFloodIndexes(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing comment flood limit indexes...")
+
+	for _, name := range []string{"user_id_post_id_created_at", "user_id_content_hash_created_at"} {
+		if err := DropIndexIfExists(ctx, db.Collection("comments"), name); err != nil {
+			log.Printf("Warning: Failed to drop %s index on comments: %v", name, err)
+		}
+	}
+
+	log.Println("Comment flood limit indexes removed")
+	return nil
+}
@@ -0,0 +1,73 @@
+// migrations/043_add_email_suppressions.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetEmailSuppressionsMigration returns the migration for the email suppression list and send log
+func GetEmailSuppressionsMigration() Migration {
+	return Migration{
+		ID:          "043_add_email_suppressions",
+		Description: "Add the email_suppressions collection and the email_sends log used for email rate limits",
+		Up:          addEmailSuppressions,
+		Down:        removeEmailSuppressions,
+	}
+}
+
+func addEmailSuppressions(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding email suppressions collection...")
+
+	suppressions := db.Collection("email_suppressions")
+
+	// An address is suppressed at most once per reason; suppressing again updates it
+	if err := EnsureUniqueIndex(ctx, suppressions, bson.D{{Key: "email", Value: 1}, {Key: "reason", Value: 1}}); err != nil {
+		return err
+	}
+
+	suppressionIndexes := []mongo.IndexModel{
+		// The admin listing, newest first and by reason
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "reason", Value: 1}, {Key: "created_at", Value: -1}}},
+	}
+	if err := CreateIndexesSafely(ctx, suppressions, suppressionIndexes); err != nil {
+		return err
+	}
+
+	sendIndexes := []mongo.IndexModel{
+		// Per-recipient and global counts within the rate limit window
+		{Keys: bson.D{{Key: "email", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+		// Sends are only needed for the length of the window
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("email_sends"), sendIndexes); err != nil {
+		return err
+	}
+
+	log.Println("Email suppressions collection added successfully")
+	return nil
+}
+
+func removeEmailSuppressions(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing email suppressions indexes...")
+
+	// Suppressions record bounces and opt-outs and are kept; the send log is disposable
+	if _, err := db.Collection("email_suppressions").Indexes().DropAll(ctx); err != nil {
+		log.Printf("Warning: Failed to drop indexes for collection email_suppressions: %v", err)
+	}
+	if err := db.Collection("email_sends").Drop(ctx); err != nil {
+		log.Printf("Warning: Failed to drop collection email_sends: %v", err)
+	}
+
+	log.Println("Email suppressions indexes removed")
+	return nil
+}
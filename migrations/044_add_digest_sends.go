@@ -0,0 +1,60 @@
+// migrations/044_add_digest_sends.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetDigestSendsMigration returns the migration for activity digest tracking
+func GetDigestSendsMigration() Migration {
+	return Migration{
+		ID:          "044_add_digest_sends",
+		Description: "Add the digest_sends collection recording when each user last got the activity digest",
+		Up:          addDigestSends,
+		Down:        removeDigestSends,
+	}
+}
+
+func addDigestSends(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding digest sends collection...")
+
+	// One record per user; the unique index is what stops two job runs
+	// sending the same digest
+	sends := db.Collection("digest_sends")
+	if err := EnsureUniqueIndex(ctx, sends, bson.D{{Key: "user_id", Value: 1}}); err != nil {
+		return err
+	}
+	if err := CreateIndexesSafely(ctx, sends, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "last_sent_at", Value: -1}}},
+	}); err != nil {
+		return err
+	}
+
+	// The job walks the users who opted into digests
+	if err := CreateIndexesSafely(ctx, db.Collection("notification_preferences"), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "digest_frequency", Value: 1}, {Key: "email_enabled", Value: 1}}},
+	}); err != nil {
+		return err
+	}
+
+	log.Println("Digest sends collection added successfully")
+	return nil
+}
+
+func removeDigestSends(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing digest sends collection...")
+
+	if err := db.Collection("digest_sends").Drop(ctx); err != nil {
+		log.Printf("Warning: Failed to drop collection digest_sends: %v", err)
+	}
+	if err := DropIndexIfExists(ctx, db.Collection("notification_preferences"), "digest_frequency_1_email_enabled_1"); err != nil {
+		log.Printf("Warning: Failed to drop digest_frequency_1_email_enabled_1 index on notification_preferences: %v", err)
+	}
+
+	log.Println("Digest sends collection removed")
+	return nil
+}
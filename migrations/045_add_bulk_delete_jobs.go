@@ -0,0 +1,58 @@
+// migrations/045_add_bulk_delete_jobs.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetBulkDeleteJobsMigration returns the migration for bulk delete jobs
+func GetBulkDeleteJobsMigration() Migration {
+	return Migration{
+		ID:          "045_add_bulk_delete_jobs",
+		Description: "Add the bulk_delete_jobs collection tracking users bulk deleting their posts and comments",
+		Up:          addBulkDeleteJobs,
+		Down:        removeBulkDeleteJobs,
+	}
+}
+
+func addBulkDeleteJobs(ctx context.Context, db *mongo.Database) error {
+	log.Println("Adding bulk delete jobs collection...")
+
+	// Jobs are resumed by status on startup and expire once their TTL passes
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "content_type", Value: 1}, {Key: "status", Value: 1}},
+			Options: options.Index().SetName("content_type_status"),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("user_created_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("expires_at_ttl").SetExpireAfterSeconds(0),
+		},
+	}
+	if err := CreateIndexesSafely(ctx, db.Collection("bulk_delete_jobs"), indexes); err != nil {
+		return err
+	}
+
+	log.Println("Bulk delete jobs collection added successfully")
+	return nil
+}
+
+func removeBulkDeleteJobs(ctx context.Context, db *mongo.Database) error {
+	log.Println("Removing bulk delete jobs collection...")
+
+	if err := db.Collection("bulk_delete_jobs").Drop(ctx); err != nil {
+		log.Printf("Warning: Failed to drop collection bulk_delete_jobs: %v", err)
+	}
+
+	log.Println("Bulk delete jobs collection removed")
+	return nil
+}
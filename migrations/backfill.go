@@ -0,0 +1,425 @@
+// migrations/backfill.go
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Backfill statuses recorded in migration_progress
+const (
+	BackfillStatusRunning     = "running"
+	BackfillStatusCompleted   = "completed"
+	BackfillStatusFailed      = "failed"
+	BackfillStatusInterrupted = "interrupted"
+	BackfillStatusPending     = "pending"
+)
+
+const (
+	defaultBackfillBatchSize = 500
+	backfillProgressLogEvery = 10 // batches
+)
+
+// Backfill is a long-running data migration. Unlike Migration it never runs at
+// startup: it is started from the CLI, walks the collection in _id order in
+// batches and persists its position so an interrupted run resumes where it
+// stopped. Transform must be idempotent because the last batch before a crash
+// may be processed twice.
+type Backfill struct {
+	ID          string
+	Description string
+	Collection  string
+	// Filter selects documents that still need the backfill
+	Filter bson.M
+	// Projection limits the fields loaded for each document
+	Projection bson.M
+	// Setup runs before the first batch, e.g. to create supporting indexes
+	Setup func(ctx context.Context, db *mongo.Database) error
+	// Transform returns the write for a document, or nil to skip it
+	Transform func(doc bson.Raw) (mongo.WriteModel, error)
+	// Finalize runs once after the last batch, e.g. to recompute counters that
+	// depend on the transformed documents. It must be idempotent too.
+	Finalize func(ctx context.Context, db *mongo.Database) error
+}
+
+// BackfillOptions controls a single backfill run
+type BackfillOptions struct {
+	BatchSize int
+	// MaxPerSecond throttles the run to avoid starving production traffic; 0 disables it
+	MaxPerSecond int
+	// DryRun counts the affected documents without writing anything
+	DryRun bool
+	// Restart ignores any saved position and starts from the beginning
+	Restart bool
+}
+
+// BackfillProgress is the persisted state of a backfill in migration_progress
+type BackfillProgress struct {
+	ID            string             `json:"id" bson:"_id"`
+	Description   string             `json:"description" bson:"description"`
+	Status        string             `json:"status" bson:"status"`
+	LastID        primitive.ObjectID `json:"last_id,omitempty" bson:"last_id,omitempty"`
+	Processed     int64              `json:"processed" bson:"processed"`
+	Modified      int64              `json:"modified" bson:"modified"`
+	TotalEstimate int64              `json:"total_estimate" bson:"total_estimate"`
+	RatePerSecond float64            `json:"rate_per_second" bson:"rate_per_second"`
+	ETASeconds    int64              `json:"eta_seconds" bson:"eta_seconds"`
+	Error         string             `json:"error,omitempty" bson:"error,omitempty"`
+	StartedAt     time.Time          `json:"started_at" bson:"started_at"`
+	UpdatedAt     time.Time          `json:"updated_at" bson:"updated_at"`
+	CompletedAt   *time.Time         `json:"completed_at,omitempty" bson:"completed_at,omitempty"`
+}
+
+// BackfillRunner runs registered backfills and tracks their progress
+type BackfillRunner struct {
+	db        *mongo.Database
+	backfills []Backfill
+}
+
+// NewBackfillRunner creates a new backfill runner
+func NewBackfillRunner(db *mongo.Database) *BackfillRunner {
+	return &BackfillRunner{
+		db:        db,
+		backfills: []Backfill{},
+	}
+}
+
+// RegisterBackfills registers multiple backfills
+func (br *BackfillRunner) RegisterBackfills(backfills []Backfill) {
+	br.backfills = append(br.backfills, backfills...)
+}
+
+// RunBackfill runs a single backfill, resuming from its saved position
+func (br *BackfillRunner) RunBackfill(ctx context.Context, backfillID string, opts BackfillOptions) (*BackfillProgress, error) {
+	backfill, err := br.find(backfillID)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBackfillBatchSize
+	}
+
+	collection := br.db.Collection(backfill.Collection)
+
+	if opts.DryRun {
+		count, err := collection.CountDocuments(ctx, backfill.Filter)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("[dry-run] Backfill %s would process %d documents in %s", backfill.ID, count, backfill.Collection)
+		return &BackfillProgress{
+			ID:            backfill.ID,
+			Description:   backfill.Description,
+			Status:        BackfillStatusPending,
+			TotalEstimate: count,
+		}, nil
+	}
+
+	progress, err := br.loadProgress(ctx, backfill, opts.Restart)
+	if err != nil {
+		return nil, err
+	}
+	if progress.Status == BackfillStatusCompleted {
+		log.Printf("Backfill %s already completed, use --restart to run it again", backfill.ID)
+		return progress, nil
+	}
+
+	if backfill.Setup != nil {
+		if err := backfill.Setup(ctx, br.db); err != nil {
+			return nil, fmt.Errorf("backfill setup failed: %w", err)
+		}
+	}
+
+	remaining, err := collection.CountDocuments(ctx, br.batchFilter(backfill, progress.LastID))
+	if err != nil {
+		return nil, err
+	}
+	progress.TotalEstimate = progress.Processed + remaining
+	progress.Status = BackfillStatusRunning
+	progress.Error = ""
+	if err := br.saveProgress(ctx, progress); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Running backfill %s: %s (~%d documents remaining)", backfill.ID, backfill.Description, remaining)
+
+	runStart := time.Now()
+	var runProcessed int64
+	batches := 0
+
+	for {
+		batchStart := time.Now()
+
+		processed, modified, lastID, err := br.runBatch(ctx, backfill, collection, progress.LastID, opts.BatchSize)
+		if err != nil {
+			return progress, br.fail(progress, err)
+		}
+		if processed == 0 {
+			break
+		}
+
+		runProcessed += int64(processed)
+		progress.LastID = lastID
+		progress.Processed += int64(processed)
+		progress.Modified += modified
+		if progress.Processed > progress.TotalEstimate {
+			progress.TotalEstimate = progress.Processed
+		}
+
+		elapsed := time.Since(runStart).Seconds()
+		if elapsed > 0 {
+			progress.RatePerSecond = float64(runProcessed) / elapsed
+		}
+		if progress.RatePerSecond > 0 {
+			progress.ETASeconds = int64(float64(progress.TotalEstimate-progress.Processed) / progress.RatePerSecond)
+		}
+
+		// Save after every batch so a crash loses at most one batch of work
+		if err := br.saveProgress(ctx, progress); err != nil {
+			return progress, err
+		}
+
+		batches++
+		if batches%backfillProgressLogEvery == 0 {
+			log.Printf("Backfill %s: %d/%d processed (%.0f/s, ETA %s)",
+				backfill.ID, progress.Processed, progress.TotalEstimate, progress.RatePerSecond,
+				time.Duration(progress.ETASeconds)*time.Second)
+		}
+
+		if processed < opts.BatchSize {
+			break
+		}
+
+		if err := throttleBackfill(ctx, processed, opts.MaxPerSecond, time.Since(batchStart)); err != nil {
+			return progress, br.interrupt(progress, err)
+		}
+	}
+
+	if backfill.Finalize != nil {
+		if err := backfill.Finalize(ctx, br.db); err != nil {
+			return progress, br.fail(progress, fmt.Errorf("backfill finalize failed: %w", err))
+		}
+	}
+
+	now := time.Now()
+	progress.Status = BackfillStatusCompleted
+	progress.CompletedAt = &now
+	progress.ETASeconds = 0
+	if err := br.saveProgress(ctx, progress); err != nil {
+		return progress, err
+	}
+
+	log.Printf("Backfill %s completed: %d processed, %d modified", backfill.ID, progress.Processed, progress.Modified)
+	return progress, nil
+}
+
+// GetBackfillStatus returns the progress of every registered backfill
+func (br *BackfillRunner) GetBackfillStatus(ctx context.Context) ([]BackfillProgress, error) {
+	cursor, err := br.db.Collection("migration_progress").Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []BackfillProgress
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]BackfillProgress, len(records))
+	for _, record := range records {
+		byID[record.ID] = record
+	}
+
+	statuses := make([]BackfillProgress, 0, len(br.backfills))
+	for _, backfill := range br.backfills {
+		status, exists := byID[backfill.ID]
+		if !exists {
+			status = BackfillProgress{
+				ID:          backfill.ID,
+				Description: backfill.Description,
+				Status:      BackfillStatusPending,
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Private helper methods
+
+func (br *BackfillRunner) find(backfillID string) (*Backfill, error) {
+	for i := range br.backfills {
+		if br.backfills[i].ID == backfillID {
+			return &br.backfills[i], nil
+		}
+	}
+	return nil, fmt.Errorf("backfill %s not found", backfillID)
+}
+
+func (br *BackfillRunner) batchFilter(backfill *Backfill, lastID primitive.ObjectID) bson.M {
+	filter := bson.M{}
+	for key, value := range backfill.Filter {
+		filter[key] = value
+	}
+	if !lastID.IsZero() {
+		filter["_id"] = bson.M{"$gt": lastID}
+	}
+	return filter
+}
+
+func (br *BackfillRunner) runBatch(ctx context.Context, backfill *Backfill, collection *mongo.Collection, lastID primitive.ObjectID, batchSize int) (int, int64, primitive.ObjectID, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(batchSize))
+	if backfill.Projection != nil {
+		opts.SetProjection(backfill.Projection)
+	}
+
+	cursor, err := collection.Find(ctx, br.batchFilter(backfill, lastID), opts)
+	if err != nil {
+		return 0, 0, lastID, err
+	}
+	defer cursor.Close(ctx)
+
+	processed := 0
+	writes := make([]mongo.WriteModel, 0, batchSize)
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return 0, 0, lastID, err
+		}
+
+		write, err := backfill.Transform(cursor.Current)
+		if err != nil {
+			return 0, 0, lastID, fmt.Errorf("transform %s: %w", doc.ID.Hex(), err)
+		}
+		if write != nil {
+			writes = append(writes, write)
+		}
+
+		lastID = doc.ID
+		processed++
+	}
+	if err := cursor.Err(); err != nil {
+		return 0, 0, lastID, err
+	}
+
+	var modified int64
+	if len(writes) > 0 {
+		result, err := collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			return 0, 0, lastID, err
+		}
+		modified = result.ModifiedCount + result.UpsertedCount
+	}
+
+	return processed, modified, lastID, nil
+}
+
+func (br *BackfillRunner) loadProgress(ctx context.Context, backfill *Backfill, restart bool) (*BackfillProgress, error) {
+	now := time.Now()
+	fresh := &BackfillProgress{
+		ID:          backfill.ID,
+		Description: backfill.Description,
+		Status:      BackfillStatusPending,
+		StartedAt:   now,
+		UpdatedAt:   now,
+	}
+	if restart {
+		return fresh, nil
+	}
+
+	var progress BackfillProgress
+	err := br.db.Collection("migration_progress").FindOne(ctx, bson.M{"_id": backfill.ID}).Decode(&progress)
+	if err == mongo.ErrNoDocuments {
+		return fresh, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if progress.Status != BackfillStatusCompleted {
+		log.Printf("Resuming backfill %s after %d processed documents", backfill.ID, progress.Processed)
+	}
+	return &progress, nil
+}
+
+func (br *BackfillRunner) saveProgress(ctx context.Context, progress *BackfillProgress) error {
+	progress.UpdatedAt = time.Now()
+	_, err := br.db.Collection("migration_progress").ReplaceOne(ctx,
+		bson.M{"_id": progress.ID}, progress, options.Replace().SetUpsert(true))
+	return err
+}
+
+// fail records the error with a fresh context, since the run's context may be gone
+func (br *BackfillRunner) fail(progress *BackfillProgress, cause error) error {
+	if errors.Is(cause, context.Canceled) || errors.Is(cause, context.DeadlineExceeded) {
+		return br.interrupt(progress, cause)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	progress.Status = BackfillStatusFailed
+	progress.Error = cause.Error()
+	if err := br.saveProgress(ctx, progress); err != nil {
+		log.Printf("Failed to record backfill failure: %v", err)
+	}
+	return cause
+}
+
+func (br *BackfillRunner) interrupt(progress *BackfillProgress, cause error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	progress.Status = BackfillStatusInterrupted
+	if err := br.saveProgress(ctx, progress); err != nil {
+		log.Printf("Failed to record backfill interruption: %v", err)
+	}
+	return fmt.Errorf("backfill %s interrupted after %d documents: %w", progress.ID, progress.Processed, cause)
+}
+
+// throttleBackfill sleeps long enough to keep the run under maxPerSecond
+func throttleBackfill(ctx context.Context, processed, maxPerSecond int, spent time.Duration) error {
+	if maxPerSecond <= 0 {
+		return ctx.Err()
+	}
+
+	wait := time.Duration(float64(processed)/float64(maxPerSecond)*float64(time.Second)) - spent
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// InitializeBackfills returns all available backfills
+func InitializeBackfills() []Backfill {
+	return []Backfill{
+		GetUsernameLowerBackfill(),
+		GetMediaStorageTierBackfill(),
+		GetQuickReplyCommentsBackfill(),
+		GetHashtagCategoriesBackfill(),
+	}
+}
@@ -0,0 +1,36 @@
+// migrations/backfill_001_username_lower.go
+package migrations
+
+import (
+	"context"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetUsernameLowerBackfill returns the backfill that populates username_lower for search suggestions
+func GetUsernameLowerBackfill() Backfill {
+	return Backfill{
+		ID:          "001_username_lower",
+		Description: "Populate users.username_lower for case-insensitive username suggestions",
+		Collection:  "users",
+		Filter:      bson.M{"username_lower": bson.M{"$exists": false}},
+		Projection:  bson.M{"username": 1},
+		Setup: func(ctx context.Context, db *mongo.Database) error {
+			return CreateIndexesSafely(ctx, db.Collection("users"), []mongo.IndexModel{
+				{Keys: bson.D{{Key: "username_lower", Value: 1}}},
+			})
+		},
+		Transform: func(doc bson.Raw) (mongo.WriteModel, error) {
+			username, ok := doc.Lookup("username").StringValueOK()
+			if !ok {
+				return nil, nil
+			}
+
+			return mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": doc.Lookup("_id").ObjectID()}).
+				SetUpdate(bson.M{"$set": bson.M{"username_lower": strings.ToLower(username)}}), nil
+		},
+	}
+}
@@ -0,0 +1,43 @@
+// migrations/backfill_002_media_storage_tier.go
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetMediaStorageTierBackfill returns the backfill that places existing media in the hot tier
+func GetMediaStorageTierBackfill() Backfill {
+	return Backfill{
+		ID:          "002_media_storage_tier",
+		Description: "Set media.storage_tier and last_accessed_at so existing media can move to cold storage",
+		Collection:  "media",
+		Filter:      bson.M{"storage_tier": bson.M{"$exists": false}},
+		Projection:  bson.M{"created_at": 1, "updated_at": 1},
+		Setup: func(ctx context.Context, db *mongo.Database) error {
+			return CreateIndexesSafely(ctx, db.Collection("media"), []mongo.IndexModel{
+				{Keys: bson.D{{Key: "storage_tier", Value: 1}, {Key: "last_accessed_at", Value: 1}}},
+			})
+		},
+		Transform: func(doc bson.Raw) (mongo.WriteModel, error) {
+			// Nothing records reads before this point; the last update is the
+			// closest stand-in for the last access
+			lastAccessed, ok := doc.Lookup("updated_at").DateTimeOK()
+			if !ok {
+				lastAccessed, ok = doc.Lookup("created_at").DateTimeOK()
+			}
+
+			set := bson.M{"storage_tier": "hot"}
+			if ok {
+				set["last_accessed_at"] = primitive.DateTime(lastAccessed)
+			}
+
+			return mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": doc.Lookup("_id").ObjectID()}).
+				SetUpdate(bson.M{"$set": set}), nil
+		},
+	}
+}
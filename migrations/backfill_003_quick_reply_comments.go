@@ -0,0 +1,130 @@
+// migrations/backfill_003_quick_reply_comments.go
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"social-media-api/internal/models"
+	"social-media-api/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetQuickReplyCommentsBackfill returns the backfill that reclassifies existing
+// emoji-only comments as quick replies. It is opt-in: run it with
+// "migrate --backfill 003_quick_reply_comments" once product signs off.
+func GetQuickReplyCommentsBackfill() Backfill {
+	return Backfill{
+		ID:          "003_quick_reply_comments",
+		Description: "Reclassify emoji-only top-level comments as quick replies and recompute post comment counters",
+		Collection:  "comments",
+		// Comments with replies or media stay in the thread
+		Filter: bson.M{
+			"kind":          bson.M{"$exists": false},
+			"level":         0,
+			"replies_count": bson.M{"$lte": 0},
+			"media.0":       bson.M{"$exists": false},
+			"deleted_at":    bson.M{"$exists": false},
+		},
+		Projection: bson.M{"content": 1},
+		Transform: func(doc bson.Raw) (mongo.WriteModel, error) {
+			content, ok := doc.Lookup("content").StringValueOK()
+			if !ok || !utils.IsEmojiOnly(content, models.MaxQuickReplyEmoji) {
+				return nil, nil
+			}
+
+			return mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": doc.Lookup("_id").ObjectID()}).
+				SetUpdate(bson.M{"$set": bson.M{"kind": models.CommentKindQuick}}), nil
+		},
+		Finalize: recomputeQuickReplyCounters,
+	}
+}
+
+// recomputeQuickReplyCounters rebuilds comments_count and the quick reaction
+// summary for every post that has quick replies
+func recomputeQuickReplyCounters(ctx context.Context, db *mongo.Database) error {
+	comments := db.Collection("comments")
+	posts := db.Collection("posts")
+
+	postIDs, err := comments.Distinct(ctx, "post_id", bson.M{"kind": models.CommentKindQuick})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Recomputing comment counters for %d posts with quick replies", len(postIDs))
+
+	for _, value := range postIDs {
+		postID, ok := value.(primitive.ObjectID)
+		if !ok {
+			continue
+		}
+
+		visible := bson.M{
+			"post_id":     postID,
+			"is_approved": true,
+			"deleted_at":  bson.M{"$exists": false},
+		}
+
+		standardFilter := bson.M{"kind": bson.M{"$ne": models.CommentKindQuick}}
+		for key, value := range visible {
+			standardFilter[key] = value
+		}
+		standard, err := comments.CountDocuments(ctx, standardFilter)
+		if err != nil {
+			return err
+		}
+
+		quickFilter := bson.M{"kind": models.CommentKindQuick}
+		for key, value := range visible {
+			quickFilter[key] = value
+		}
+		cursor, err := comments.Find(ctx, quickFilter, options.Find().SetProjection(bson.M{"content": 1}))
+		if err != nil {
+			return err
+		}
+
+		var quickTotal int64
+		reactionCounts := map[string]int64{}
+		for cursor.Next(ctx) {
+			var comment struct {
+				Content string `bson:"content"`
+			}
+			if err := cursor.Decode(&comment); err != nil {
+				cursor.Close(ctx)
+				return err
+			}
+
+			// Each distinct emoji counts once per reply, as in the comment service
+			emoji, _ := utils.SplitEmoji(comment.Content)
+			seen := map[string]bool{}
+			for _, e := range emoji {
+				if !seen[e] {
+					seen[e] = true
+					reactionCounts[e]++
+				}
+			}
+			quickTotal++
+		}
+		err = cursor.Err()
+		cursor.Close(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = posts.UpdateOne(ctx, bson.M{"_id": postID}, bson.M{"$set": bson.M{
+			"comments_count":        standard,
+			"quick_replies_count":   quickTotal,
+			"quick_reaction_counts": reactionCounts,
+		}})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+// migrations/backfill_004_hashtag_categories.go
+package migrations
+
+import (
+	"social-media-api/internal/config"
+	"social-media-api/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetHashtagCategoriesBackfill returns the backfill that files existing
+// hashtags under the category their keywords map to. Categories set by an
+// admin are left alone. Rerunning it with --restart after changing
+// HASHTAG_CATEGORY_KEYWORDS does the same as the admin recategorize endpoint.
+func GetHashtagCategoriesBackfill() Backfill {
+	categorizer := models.NewHashtagCategorizer(config.GetConfig().Hashtags.CategoryKeywords)
+
+	return Backfill{
+		ID:          "004_hashtag_categories",
+		Description: "Set hashtags.category from the configured category keywords",
+		Collection:  "hashtags",
+		Filter: bson.M{
+			"category_locked": bson.M{"$ne": true},
+			"deleted_at":      bson.M{"$exists": false},
+		},
+		Projection: bson.M{"normalized_tag": 1, "category": 1},
+		Transform: func(doc bson.Raw) (mongo.WriteModel, error) {
+			tag, ok := doc.Lookup("normalized_tag").StringValueOK()
+			if !ok {
+				return nil, nil
+			}
+
+			category := categorizer.Categorize(tag)
+			if current, _ := doc.Lookup("category").StringValueOK(); current == category {
+				return nil, nil
+			}
+
+			return mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": doc.Lookup("_id").ObjectID(), "category_locked": bson.M{"$ne": true}}).
+				SetUpdate(bson.M{"$set": bson.M{"category": category}}), nil
+		},
+	}
+}
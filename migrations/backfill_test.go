@@ -0,0 +1,168 @@
+package migrations_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"social-media-api/internal/testutil"
+	"social-media-api/migrations"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// countingBackfill increments a per-document counter, so a document written
+// by two runs shows up as applied twice. Its filter matches every document:
+// only the saved position keeps a resumed run from redoing finished batches.
+// stopAfter, when set, is called with each document and may cancel the run.
+func countingBackfill(transformed map[primitive.ObjectID]int, stopAfter func(id primitive.ObjectID)) migrations.Backfill {
+	return migrations.Backfill{
+		ID:          "test_counting",
+		Description: "Count how often each document is written",
+		Collection:  "backfill_items",
+		Filter:      bson.M{},
+		Transform: func(doc bson.Raw) (mongo.WriteModel, error) {
+			id := doc.Lookup("_id").ObjectID()
+			transformed[id]++
+			if stopAfter != nil {
+				stopAfter(id)
+			}
+			return mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": id}).
+				SetUpdate(bson.M{"$inc": bson.M{"applied": 1}}), nil
+		},
+	}
+}
+
+func TestBackfillResumesAfterInterruption(t *testing.T) {
+	h := testutil.NewHarness(t)
+
+	const total, batchSize, stopAt = 10, 3, 5
+	ids := make([]primitive.ObjectID, total)
+	docs := make([]interface{}, total)
+	for i := range ids {
+		ids[i] = primitive.NewObjectID()
+		docs[i] = bson.M{"_id": ids[i], "n": i}
+	}
+	if _, err := h.DB.Collection("backfill_items").InsertMany(h.Context(), docs); err != nil {
+		t.Fatalf("failed to seed documents: %v", err)
+	}
+
+	// Crash partway through the second batch: its documents are transformed
+	// but never written
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	firstRun := map[primitive.ObjectID]int{}
+	seen := 0
+	runner := migrations.NewBackfillRunner(h.DB)
+	runner.RegisterBackfills([]migrations.Backfill{countingBackfill(firstRun, func(primitive.ObjectID) {
+		if seen++; seen == stopAt {
+			cancel()
+		}
+	})})
+
+	progress, err := runner.RunBackfill(ctx, "test_counting", migrations.BackfillOptions{BatchSize: batchSize})
+	if err == nil || !errors.Is(err, context.Canceled) {
+		t.Fatalf("interrupted run error = %v, want context.Canceled", err)
+	}
+	if progress.Status != migrations.BackfillStatusInterrupted || progress.Processed != batchSize || progress.LastID != ids[batchSize-1] {
+		t.Fatalf("interrupted progress = %s after %d at %s, want %s after %d at %s",
+			progress.Status, progress.Processed, progress.LastID.Hex(), migrations.BackfillStatusInterrupted, batchSize, ids[batchSize-1].Hex())
+	}
+	if got := h.Count("backfill_items", bson.M{"applied": bson.M{"$exists": true}}); got != batchSize {
+		t.Fatalf("documents written before the interruption = %d, want %d", got, batchSize)
+	}
+
+	// Resume with a fresh runner, as a second CLI invocation would
+	secondRun := map[primitive.ObjectID]int{}
+	runner = migrations.NewBackfillRunner(h.DB)
+	runner.RegisterBackfills([]migrations.Backfill{countingBackfill(secondRun, nil)})
+
+	progress, err = runner.RunBackfill(context.Background(), "test_counting", migrations.BackfillOptions{BatchSize: batchSize})
+	if err != nil {
+		t.Fatalf("resumed run: %v", err)
+	}
+	if progress.Status != migrations.BackfillStatusCompleted || progress.Processed != total {
+		t.Errorf("resumed progress = %s after %d, want %s after %d", progress.Status, progress.Processed, migrations.BackfillStatusCompleted, total)
+	}
+
+	for i, id := range ids[:batchSize] {
+		if secondRun[id] != 0 {
+			t.Errorf("document %d from a saved batch was transformed again on resume", i)
+		}
+	}
+	for i, id := range ids[batchSize:] {
+		if secondRun[id] != 1 {
+			t.Errorf("document %d transformed %d times on resume, want 1", i+batchSize, secondRun[id])
+		}
+	}
+	if got := h.Count("backfill_items", bson.M{"applied": 1}); got != total {
+		t.Errorf("documents written exactly once = %d, want %d", got, total)
+	}
+
+	// A completed backfill doesn't run again without --restart
+	thirdRun := map[primitive.ObjectID]int{}
+	runner = migrations.NewBackfillRunner(h.DB)
+	runner.RegisterBackfills([]migrations.Backfill{countingBackfill(thirdRun, nil)})
+	if _, err := runner.RunBackfill(context.Background(), "test_counting", migrations.BackfillOptions{BatchSize: batchSize}); err != nil {
+		t.Fatalf("rerun of a completed backfill: %v", err)
+	}
+	if len(thirdRun) != 0 {
+		t.Errorf("rerun of a completed backfill transformed %d documents, want 0", len(thirdRun))
+	}
+}
+
+func TestUsernameLowerBackfill(t *testing.T) {
+	h := testutil.NewHarness(t)
+
+	for _, username := range []string{"Alice", "BOB", "carol"} {
+		h.CreateUser(testutil.WithUsername(username))
+	}
+	users := h.DB.Collection("users")
+	if _, err := users.UpdateMany(h.Context(), bson.M{}, bson.M{"$unset": bson.M{"username_lower": ""}}); err != nil {
+		t.Fatalf("failed to clear username_lower: %v", err)
+	}
+	pending := h.Count("users", bson.M{"username_lower": bson.M{"$exists": false}})
+
+	runner := migrations.NewBackfillRunner(h.DB)
+	runner.RegisterBackfills(migrations.InitializeBackfills())
+
+	dryRun, err := runner.RunBackfill(h.Context(), "001_username_lower", migrations.BackfillOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if dryRun.TotalEstimate != pending {
+		t.Errorf("dry run estimate = %d, want %d", dryRun.TotalEstimate, pending)
+	}
+	if got := h.Count("users", bson.M{"username_lower": bson.M{"$exists": true}}); got != 0 {
+		t.Fatalf("dry run wrote username_lower to %d users", got)
+	}
+
+	progress, err := runner.RunBackfill(h.Context(), "001_username_lower", migrations.BackfillOptions{BatchSize: 2})
+	if err != nil {
+		t.Fatalf("backfill: %v", err)
+	}
+	if progress.Status != migrations.BackfillStatusCompleted || progress.Processed != pending {
+		t.Errorf("progress = %s after %d, want %s after %d", progress.Status, progress.Processed, migrations.BackfillStatusCompleted, pending)
+	}
+
+	cursor, err := users.Find(h.Context(), bson.M{})
+	if err != nil {
+		t.Fatalf("failed to list users: %v", err)
+	}
+	var backfilled []struct {
+		Username      string `bson:"username"`
+		UsernameLower string `bson:"username_lower"`
+	}
+	if err := cursor.All(h.Context(), &backfilled); err != nil {
+		t.Fatalf("failed to decode users: %v", err)
+	}
+	for _, user := range backfilled {
+		if user.UsernameLower != strings.ToLower(user.Username) {
+			t.Errorf("username_lower of %q = %q", user.Username, user.UsernameLower)
+		}
+	}
+}
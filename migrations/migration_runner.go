@@ -245,6 +245,49 @@ func InitializeMigrations() []Migration {
 	return []Migration{
 		GetInitialIndexesMigration(),
 		GetSocialFeaturesMigration(),
+		GetTranslationsMigration(),
+		GetSurveysMigration(),
+		GetMoveEmbeddedArraysMigration(),
+		GetReactionTypesMigration(),
+		GetSuggestionsMigration(),
+		GetCommentHoldsMigration(),
+		GetUserMutesMigration(),
+		GetAudienceActivityMigration(),
+		GetRetentionTTLMigration(),
+		GetGroupLibraryMigration(),
+		GetContentFingerprintsMigration(),
+		GetScheduledReactivationMigration(),
+		GetAbuseScoreMigration(),
+		GetBoostedPostsMigration(),
+		GetTierLimitsMigration(),
+		GetStoryStickerResponsesMigration(),
+		GetGroupNotificationLevelsMigration(),
+		GetGroupPostApprovalMigration(),
+		GetGroupInviteLinksMigration(),
+		GetQuickRepliesMigration(),
+		GetAccountDelegationsMigration(),
+		GetUserWarningsMigration(),
+		GetStatusPageMigration(),
+		GetOnboardingSuggestionsMigration(),
+		GetContentCalendarMigration(),
+		GetExportJobMigration(),
+		GetModerationQueueMigration(),
+		GetHashtagPageMigration(),
+		GetDeviceAccountMigration(),
+		GetLinkBlocklistMigration(),
+		GetPhotoTagsMigration(),
+		GetFeedInjectionsMigration(),
+		GetSavedSearchesMigration(),
+		GetPostAutoDeleteMigration(),
+		GetCommentReplyPagingMigration(),
+		GetImpersonationSessionsMigration(),
+		GetMediaContentHashMigration(),
+		GetFeedAlgorithmLogsMigration(),
+		GetCreatorSummaryCacheMigration(),
+		GetCommentFloodLimitsMigration(),
+		GetEmailSuppressionsMigration(),
+		GetDigestSendsMigration(),
+		GetBulkDeleteJobsMigration(),
 		CreateAdminUser001(),
 	}
 }
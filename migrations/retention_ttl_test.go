@@ -0,0 +1,124 @@
+package migrations_test
+
+import (
+	"testing"
+	"time"
+
+	"social-media-api/internal/testutil"
+	"social-media-api/migrations"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ttlOf returns the expireAfterSeconds of the ascending index on field, nil
+// when the index has no TTL. It fails the test when there is no such index.
+func ttlOf(t *testing.T, h *testutil.Harness, collection, field string) *int32 {
+	t.Helper()
+
+	specs, err := h.DB.Collection(collection).Indexes().ListSpecifications(h.Context())
+	if err != nil {
+		t.Fatalf("failed to list indexes on %s: %v", collection, err)
+	}
+
+	for _, spec := range specs {
+		if spec.Name == field+"_1" {
+			return spec.ExpireAfterSeconds
+		}
+	}
+	t.Fatalf("%s has no %s_1 index", collection, field)
+	return nil
+}
+
+func assertTTL(t *testing.T, h *testutil.Harness, collection, field string, want int32) {
+	t.Helper()
+
+	if ttl := ttlOf(t, h, collection, field); ttl == nil || *ttl != want {
+		t.Fatalf("expireAfterSeconds on %s.%s = %v, want %d", collection, field, ttl, want)
+	}
+}
+
+func TestRetentionMigrationCreatesTTLIndexes(t *testing.T) {
+	h := testutil.NewHarness(t)
+
+	for _, index := range []struct{ collection, field string }{
+		{"user_sessions", "created_at"},
+		{"content_engagements", "created_at"},
+		{"recommendation_events", "created_at"},
+		{"feed_cache", "expires_at"},
+	} {
+		if ttlOf(t, h, index.collection, index.field) == nil {
+			t.Errorf("%s.%s has no TTL after the migrations", index.collection, index.field)
+		}
+	}
+}
+
+func TestSyncTTLIndexChangesExpiry(t *testing.T) {
+	h := testutil.NewHarness(t)
+
+	if err := migrations.SyncTTLIndex(h.Context(), h.DB, "feed_cache", "expires_at", 2*time.Hour); err != nil {
+		t.Fatalf("SyncTTLIndex: %v", err)
+	}
+	assertTTL(t, h, "feed_cache", "expires_at", 7200)
+
+	// A changed retention period updates the existing index in place
+	if err := migrations.SyncTTLIndex(h.Context(), h.DB, "feed_cache", "expires_at", 30*time.Minute); err != nil {
+		t.Fatalf("SyncTTLIndex with a new period: %v", err)
+	}
+	assertTTL(t, h, "feed_cache", "expires_at", 1800)
+
+	// An unchanged period is a no-op
+	if err := migrations.SyncTTLIndex(h.Context(), h.DB, "feed_cache", "expires_at", 30*time.Minute); err != nil {
+		t.Fatalf("SyncTTLIndex with the same period: %v", err)
+	}
+	assertTTL(t, h, "feed_cache", "expires_at", 1800)
+}
+
+func TestSyncTTLIndexReplacesPlainIndex(t *testing.T) {
+	h := testutil.NewHarness(t)
+
+	collection := h.DB.Collection("ttl_scratch")
+	if _, err := collection.Indexes().CreateOne(h.Context(), mongo.IndexModel{Keys: bson.D{{Key: "created_at", Value: 1}}}); err != nil {
+		t.Fatalf("failed to create plain index: %v", err)
+	}
+
+	if err := migrations.SyncTTLIndex(h.Context(), h.DB, "ttl_scratch", "created_at", 24*time.Hour); err != nil {
+		t.Fatalf("SyncTTLIndex: %v", err)
+	}
+	assertTTL(t, h, "ttl_scratch", "created_at", 86400)
+}
+
+func TestRetentionMigrationBackfillsCreatedAt(t *testing.T) {
+	h := testutil.NewHarness(t)
+
+	started := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	sessions := h.DB.Collection("user_sessions")
+	if _, err := sessions.InsertMany(h.Context(), []interface{}{
+		bson.M{"session_id": "missing", "start_time": started},
+		bson.M{"session_id": "zero", "start_time": started, "created_at": time.Time{}},
+		bson.M{"session_id": "set", "start_time": started, "created_at": started.Add(time.Minute)},
+	}); err != nil {
+		t.Fatalf("failed to seed sessions: %v", err)
+	}
+
+	if err := migrations.GetRetentionTTLMigration().Up(h.Context(), h.DB); err != nil {
+		t.Fatalf("retention migration: %v", err)
+	}
+
+	want := map[string]time.Time{
+		"missing": started,
+		"zero":    started,
+		"set":     started.Add(time.Minute),
+	}
+	for sessionID, createdAt := range want {
+		var session struct {
+			CreatedAt time.Time `bson:"created_at"`
+		}
+		if err := sessions.FindOne(h.Context(), bson.M{"session_id": sessionID}).Decode(&session); err != nil {
+			t.Fatalf("failed to load session %s: %v", sessionID, err)
+		}
+		if !session.CreatedAt.Equal(createdAt) {
+			t.Errorf("created_at of %s = %v, want %v", sessionID, session.CreatedAt, createdAt)
+		}
+	}
+}